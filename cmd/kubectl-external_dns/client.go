@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"k8s.io/client-go/rest"
+)
+
+// statusClient talks to the status API of ExternalDNS.
+type statusClient interface {
+	get(ctx context.Context, path string, query url.Values) ([]byte, error)
+	post(ctx context.Context, path string) error
+}
+
+// getJSON decodes the response of a GET request of the status API.
+func getJSON(ctx context.Context, client statusClient, path string, query url.Values, v any) error {
+	data, err := client.get(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decoding response of %s: %w", path, err)
+	}
+	return nil
+}
+
+// directClient talks to the status API at a URL, e.g. through a port forwarding.
+type directClient struct {
+	base   string
+	client *http.Client
+}
+
+func newDirectClient(base string) *directClient {
+	return &directClient{base: strings.TrimSuffix(base, "/"), client: http.DefaultClient}
+}
+
+func (c *directClient) do(ctx context.Context, method, path string, query url.Values) ([]byte, error) {
+	u := c.base + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+func (c *directClient) get(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, path, query)
+}
+
+func (c *directClient) post(ctx context.Context, path string) error {
+	_, err := c.do(ctx, http.MethodPost, path, nil)
+	return err
+}
+
+// proxyClient talks to the status API of the ExternalDNS service through the proxy of the Kubernetes API server.
+type proxyClient struct {
+	rest      rest.Interface
+	namespace string
+	service   string
+	port      string
+}
+
+func (c *proxyClient) request(req *rest.Request, path string) *rest.Request {
+	return req.Namespace(c.namespace).Resource("services").Name(c.service + ":" + c.port).SubResource("proxy").Suffix(path)
+}
+
+func (c *proxyClient) get(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	req := c.request(c.rest.Get(), path)
+	for key, values := range query {
+		for _, value := range values {
+			req = req.Param(key, value)
+		}
+	}
+	return req.DoRaw(ctx)
+}
+
+func (c *proxyClient) post(ctx context.Context, path string) error {
+	_, err := c.request(c.rest.Post(), path).DoRaw(ctx)
+	return err
+}
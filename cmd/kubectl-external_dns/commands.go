@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/external-dns/controller"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// runRecords prints the managed records, only those of the object if given as KIND/NAME.
+func runRecords(ctx context.Context, client statusClient, w io.Writer, object, namespace string) error {
+	query := url.Values{}
+	if object != "" {
+		kind, name, ok := strings.Cut(object, "/")
+		if !ok || kind == "" || name == "" {
+			return fmt.Errorf("invalid object %q, expected KIND/NAME", object)
+		}
+		query.Set("resource", fmt.Sprintf("%s/%s/%s", strings.ToLower(kind), namespace, name))
+	}
+	var records []*endpoint.Endpoint
+	if err := getJSON(ctx, client, "/api/v1/records", query, &records); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTYPE\tTTL\tTARGETS\tRESOURCE")
+	for _, ep := range records {
+		name := ep.DNSName
+		if unicode := ep.Labels[endpoint.UnicodeHostnameLabelKey]; unicode != "" {
+			name = fmt.Sprintf("%s (%s)", name, unicode)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", name, ep.RecordType, ep.RecordTTL, strings.Join(ep.Targets, ","), ep.Labels[endpoint.ResourceLabelKey])
+	}
+	return tw.Flush()
+}
+
+// runPlan prints the changes of the last synchronization.
+func runPlan(ctx context.Context, client statusClient, w io.Writer) error {
+	changes := &plan.Changes{}
+	if err := getJSON(ctx, client, "/api/v1/plan", nil, changes); err != nil {
+		return err
+	}
+	if !changes.HasChanges() {
+		_, err := fmt.Fprintln(w, "No changes")
+		return err
+	}
+	renderer := &controller.ChangesRenderer{Writer: w}
+	return renderer.Render(ctx, changes)
+}
+
+// runExplain prints the decisions taken for a hostname during a synchronization.
+func runExplain(ctx context.Context, client statusClient, w io.Writer, hostname string) error {
+	var explanation controller.Explanation
+	if err := getJSON(ctx, client, "/api/v1/explain", url.Values{"hostname": {hostname}}, &explanation); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "STAGE\tDECISION")
+	for _, step := range explanation.Steps {
+		fmt.Fprintf(tw, "%s\t%s\n", step.Stage, step.Decision)
+	}
+	return tw.Flush()
+}
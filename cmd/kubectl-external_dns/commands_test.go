@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/controller"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, *[]string) {
+	record := endpoint.NewEndpointWithTTL("foo.example.com", endpoint.RecordTypeA, 300, "1.2.3.4")
+	record.Labels[endpoint.ResourceLabelKey] = "ingress/default/foo"
+	var requests []string
+
+	mux := http.NewServeMux()
+	respond := func(w http.ResponseWriter, v any) {
+		require.NoError(t, json.NewEncoder(w).Encode(v))
+	}
+	mux.HandleFunc("GET /api/v1/explain", func(w http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.URL.String())
+		respond(w, controller.Explanation{
+			Hostname: req.URL.Query().Get("hostname"),
+			Steps: []controller.ExplainStep{
+				{Stage: controller.ExplainStageProvider, Decision: "the provider has no records of the hostname"},
+				{Stage: controller.ExplainStageSource, Decision: "no source produces the hostname"},
+			},
+		})
+	})
+	mux.HandleFunc("GET /api/v1/records", func(w http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.URL.String())
+		if hostname := req.URL.Query().Get("hostname"); hostname != "" && hostname != "foo.example.com" {
+			respond(w, []*endpoint.Endpoint{})
+			return
+		}
+		respond(w, []*endpoint.Endpoint{record})
+	})
+	mux.HandleFunc("GET /api/v1/plan", func(w http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.URL.String())
+		respond(w, &plan.Changes{Create: []*endpoint.Endpoint{record}})
+	})
+	mux.HandleFunc("POST /api/v1/reconcile", func(w http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.URL.String())
+		w.WriteHeader(http.StatusAccepted)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestRunRecords(t *testing.T) {
+	server, requests := newTestServer(t)
+	var out strings.Builder
+	require.NoError(t, runRecords(context.Background(), newDirectClient(server.URL+"/"), &out, "Ingress/foo", "default"))
+	assert.Equal(t, []string{"/api/v1/records?resource=ingress%2Fdefault%2Ffoo"}, *requests)
+	assert.Equal(t, `NAME             TYPE  TTL  TARGETS  RESOURCE
+foo.example.com  A     300  1.2.3.4  ingress/default/foo
+`, out.String())
+
+	assert.Error(t, runRecords(context.Background(), newDirectClient(server.URL), &out, "foo", "default"))
+}
+
+func TestRunPlan(t *testing.T) {
+	server, _ := newTestServer(t)
+	var out strings.Builder
+	require.NoError(t, runPlan(context.Background(), newDirectClient(server.URL), &out))
+	assert.Equal(t, "foo.example.com\n  + A 300 1.2.3.4\n", out.String())
+}
+
+func TestRunReconcile(t *testing.T) {
+	server, requests := newTestServer(t)
+	var out strings.Builder
+	require.NoError(t, runReconcile(context.Background(), newDirectClient(server.URL), &out))
+	assert.Equal(t, []string{"/api/v1/reconcile"}, *requests)
+
+	assert.Error(t, newDirectClient(server.URL).post(context.Background(), "/api/v1/missing"))
+}
+
+func TestRunExplain(t *testing.T) {
+	server, requests := newTestServer(t)
+	var out strings.Builder
+	require.NoError(t, runExplain(context.Background(), newDirectClient(server.URL), &out, "foo.example.com"))
+	assert.Equal(t, []string{"/api/v1/explain?hostname=foo.example.com"}, *requests)
+	assert.Equal(t, `STAGE     DECISION
+provider  the provider has no records of the hostname
+source    no source produces the hostname
+`, out.String())
+}
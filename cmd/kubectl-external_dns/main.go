@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-external_dns is a kubectl plugin talking to the status API of ExternalDNS,
+// invoked as `kubectl external-dns`.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alecthomas/kingpin/v2"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func main() {
+	app := kingpin.New("kubectl external-dns", "Interacts with ExternalDNS through its status API, enabled with --status-api.")
+	server := app.Flag("server", "The URL of the status API of ExternalDNS, e.g. http://localhost:7979 (default: the service through the Kubernetes API server proxy)").String()
+	kubeconfig := app.Flag("kubeconfig", "The kubeconfig file (default: the kubectl default)").String()
+	serviceNamespace := app.Flag("external-dns-namespace", "The namespace of the ExternalDNS service").Default("default").String()
+	service := app.Flag("external-dns-service", "The name of the ExternalDNS service").Default("external-dns").String()
+	port := app.Flag("external-dns-port", "The port of the ExternalDNS service serving the status API").Default("7979").String()
+
+	records := app.Command("records", "Shows the managed records, optionally of an object.")
+	object := records.Arg("object", "The object of the records, as KIND/NAME, e.g. ingress/foo").String()
+	namespace := records.Flag("namespace", "The namespace of the object").Short('n').Default("default").String()
+	reconcile := app.Command("reconcile", "Triggers a synchronization.")
+	showPlan := app.Command("plan", "Shows the changes of the last synchronization.")
+	explain := app.Command("explain", "Explains why a hostname is or is not managed.")
+	hostname := explain.Arg("hostname", "The hostname").Required().String()
+
+	command := kingpin.MustParse(app.Parse(os.Args[1:]))
+
+	var client statusClient
+	if *server != "" {
+		client = newDirectClient(*server)
+	} else {
+		rules := clientcmd.NewDefaultClientConfigLoadingRules()
+		rules.ExplicitPath = *kubeconfig
+		config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).ClientConfig()
+		app.FatalIfError(err, "loading kubeconfig")
+		clientset, err := kubernetes.NewForConfig(config)
+		app.FatalIfError(err, "creating Kubernetes client")
+		client = &proxyClient{rest: clientset.CoreV1().RESTClient(), namespace: *serviceNamespace, service: *service, port: *port}
+	}
+
+	ctx := context.Background()
+	var err error
+	switch command {
+	case records.FullCommand():
+		err = runRecords(ctx, client, os.Stdout, *object, *namespace)
+	case reconcile.FullCommand():
+		err = runReconcile(ctx, client, os.Stdout)
+	case showPlan.FullCommand():
+		err = runPlan(ctx, client, os.Stdout)
+	case explain.FullCommand():
+		err = runExplain(ctx, client, os.Stdout, *hostname)
+	}
+	app.FatalIfError(err, "")
+}
+
+func runReconcile(ctx context.Context, client statusClient, w io.Writer) error {
+	if err := client.post(ctx, "/api/v1/reconcile"); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w, "Synchronization scheduled")
+	return err
+}
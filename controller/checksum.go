@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// zoneChecksum is the state of a zone the last time it was found in sync.
+type zoneChecksum struct {
+	// desired is the checksum of the desired endpoints of the zone
+	desired string
+	// snapshot is the version of the zone returned by the provider, or the checksum of its records
+	snapshot string
+	// records are the records of the zone
+	records []*endpoint.Endpoint
+}
+
+// unchangedZones remembers the zones found in sync, so that the synchronization of a zone
+// can be skipped as long as neither its desired endpoints nor its records change.
+type unchangedZones map[string]zoneChecksum
+
+// unchanged returns the state of the zone if it was found in sync with the same desired endpoints and snapshot.
+func (u unchangedZones) unchanged(zone, desired, snapshot string) (zoneChecksum, bool) {
+	sum, ok := u[zone]
+	return sum, ok && sum.desired == desired && sum.snapshot == snapshot
+}
+
+// observe remembers the zone if it is in sync. Zones with changes are forgotten,
+// since the changes alter their snapshot.
+func (u unchangedZones) observe(zone string, sum zoneChecksum, hasChanges bool) {
+	if hasChanges {
+		delete(u, zone)
+		return
+	}
+	u[zone] = sum
+}
+
+// zoneRecords lists the records of the zone, unless SkipUnchangedZones is set and neither the desired endpoints
+// nor the version of the zone changed since it was found in sync. It returns the state of the zone to remember
+// if it is in sync, and whether the zone is unchanged, in which case the records found in sync are returned.
+func (c *Controller) zoneRecords(ctx context.Context, zone string, desired []*endpoint.Endpoint) ([]*endpoint.Endpoint, zoneChecksum, bool, error) {
+	if !c.SkipUnchangedZones {
+		records, err := c.Registry.Records(ctx)
+		return records, zoneChecksum{}, false, err
+	}
+	if c.unchanged == nil {
+		c.unchanged = unchangedZones{}
+	}
+
+	sum := zoneChecksum{desired: endpointsChecksum(desired)}
+	if c.ZoneVersioner != nil {
+		version, err := c.ZoneVersioner.ZoneVersion(ctx, zone)
+		if err != nil {
+			log.Warnf("Failed to get the version of zone %s, listing its records: %v", zone, err)
+		} else if version != "" {
+			sum.snapshot = version
+			if last, ok := c.unchanged.unchanged(zone, sum.desired, sum.snapshot); ok {
+				return last.records, last, true, nil
+			}
+		}
+	}
+
+	records, err := c.Registry.Records(ctx)
+	if err != nil {
+		return nil, sum, false, err
+	}
+	sum.records = records
+	if sum.snapshot == "" {
+		sum.snapshot = endpointsChecksum(records)
+		if _, ok := c.unchanged.unchanged(zone, sum.desired, sum.snapshot); ok {
+			return records, sum, true, nil
+		}
+	}
+	return records, sum, false, nil
+}
+
+// endpointsChecksum returns a checksum of the endpoints that doesn't depend on their order,
+// nor on the order of their targets, provider-specific properties and labels.
+func endpointsChecksum(endpoints []*endpoint.Endpoint) string {
+	lines := make([]string, 0, len(endpoints))
+	for _, ep := range endpoints {
+		targets := slices.Clone(ep.Targets)
+		sort.Strings(targets)
+		properties := slices.Clone(ep.ProviderSpecific)
+		slices.SortFunc(properties, func(a, b endpoint.ProviderSpecificProperty) int {
+			return cmp.Or(strings.Compare(a.Name, b.Name), strings.Compare(a.Value, b.Value))
+		})
+		keys := make([]string, 0, len(ep.Labels))
+		for key := range ep.Labels {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		labels := make([]string, 0, len(keys))
+		for _, key := range keys {
+			labels = append(labels, key+"="+ep.Labels[key])
+		}
+		lines = append(lines, fmt.Sprintf("%q %q %q %d %q %q %q", ep.DNSName, ep.RecordType, ep.SetIdentifier, ep.RecordTTL, targets, properties, labels))
+	}
+	sort.Strings(lines)
+
+	h := sha256.New()
+	for _, line := range lines {
+		_, _ = io.WriteString(h, line+"\n")
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func withOwner(ep *endpoint.Endpoint, owner string) *endpoint.Endpoint {
+	ep.Labels[endpoint.OwnerLabelKey] = owner
+	return ep
+}
+
+func TestEndpointsChecksum(t *testing.T) {
+	endpoints := []*endpoint.Endpoint{
+		withOwner(endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.1.1.1", "2.2.2.2").
+			WithProviderSpecific("b", "2").WithProviderSpecific("a", "1"), "default"),
+		endpoint.NewEndpoint("bar.example.com", endpoint.RecordTypeCNAME, "foo.example.com"),
+	}
+	reordered := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("bar.example.com", endpoint.RecordTypeCNAME, "foo.example.com"),
+		withOwner(endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "2.2.2.2", "1.1.1.1").
+			WithProviderSpecific("a", "1").WithProviderSpecific("b", "2"), "default"),
+	}
+	assert.Equal(t, endpointsChecksum(endpoints), endpointsChecksum(reordered))
+
+	for name, changed := range map[string]*endpoint.Endpoint{
+		"target":   endpoint.NewEndpoint("bar.example.com", endpoint.RecordTypeCNAME, "baz.example.com"),
+		"ttl":      endpoint.NewEndpointWithTTL("bar.example.com", endpoint.RecordTypeCNAME, 300, "foo.example.com"),
+		"property": endpoint.NewEndpoint("bar.example.com", endpoint.RecordTypeCNAME, "foo.example.com").WithProviderSpecific("a", "1"),
+		"label":    withOwner(endpoint.NewEndpoint("bar.example.com", endpoint.RecordTypeCNAME, "foo.example.com"), "other"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.NotEqual(t, endpointsChecksum(endpoints), endpointsChecksum([]*endpoint.Endpoint{endpoints[0], changed}))
+		})
+	}
+	assert.NotEqual(t, endpointsChecksum(endpoints), endpointsChecksum(endpoints[:1]))
+}
+
+func TestUnchangedZones(t *testing.T) {
+	u := unchangedZones{}
+	_, ok := u.unchanged("example.com", "desired", "1")
+	assert.False(t, ok)
+
+	u.observe("example.com", zoneChecksum{desired: "desired", snapshot: "1"}, false)
+	_, ok = u.unchanged("example.com", "desired", "1")
+	assert.True(t, ok)
+	_, ok = u.unchanged("example.com", "other", "1")
+	assert.False(t, ok, "changed desired endpoints")
+	_, ok = u.unchanged("example.com", "desired", "2")
+	assert.False(t, ok, "changed snapshot")
+
+	u.observe("example.com", zoneChecksum{desired: "desired", snapshot: "1"}, true)
+	_, ok = u.unchanged("example.com", "desired", "1")
+	assert.False(t, ok, "zones with changes are forgotten")
+}
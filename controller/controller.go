@@ -20,19 +20,25 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/util/workqueue"
 
 	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/pkg/profiling"
 	"sigs.k8s.io/external-dns/plan"
 	"sigs.k8s.io/external-dns/provider"
 	"sigs.k8s.io/external-dns/registry"
 	"sigs.k8s.io/external-dns/source"
 )
 
+// retryBaseDelay is the delay before the first retry of a failed synchronization.
+var retryBaseDelay = 5 * time.Second
+
 var (
 	registryErrorsTotal = prometheus.NewCounter(
 		prometheus.CounterOpts{
@@ -82,6 +88,99 @@ var (
 			Help:      "Timestamp of last attempted sync with the DNS provider",
 		},
 	)
+	recordsOutOfSync = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "records_out_of_sync",
+			Help:      "Number of records differing from their desired state for more than --out-of-sync-cycles synchronizations, by reason.",
+		},
+		[]string{"reason"},
+	)
+	propagationLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "propagation_latency_seconds",
+			Help:      "Duration from the changes being applied to the records being answered by a resolver of the propagation checks, by resolver.",
+			Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600, 1800},
+		},
+		[]string{"resolver"},
+	)
+	propagationTimeoutsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "propagation_timeouts_total",
+			Help:      "Number of records not answered by a resolver of the propagation checks before the timeout, by resolver.",
+		},
+		[]string{"resolver"},
+	)
+	delegatedHostnames = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "delegated_hostnames",
+			Help:      "Number of desired hostnames delegated to another zone by NS records of the provider.",
+		},
+	)
+	unroutableHostnames = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "unroutable_hostnames",
+			Help:      "Number of desired hostnames matching no zone of the provider.",
+		},
+	)
+	deferredZones = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "deferred_zones",
+			Help:      "Number of zones deferred to the next synchronization by the provider API budget.",
+		},
+	)
+	unchangedZonesSkipped = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "unchanged_zones",
+			Help:      "Number of zones skipped by the last synchronization because neither their desired endpoints nor their records changed.",
+		},
+	)
+	retriesTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "retries_total",
+			Help:      "Number of synchronizations retried after a soft error.",
+		},
+	)
+	ttlRepairsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "ttl_repairs_total",
+			Help:      "Number of records whose TTL was repaired separately from the other changes.",
+		},
+	)
+	garbageCollectedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "registry",
+			Name:      "garbage_collected_total",
+			Help:      "Number of orphaned ownership records removed and missing ownership records repaired by the garbage collection, by action.",
+		},
+		[]string{"action"},
+	)
+	migrationRemainingRecords = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "registry",
+			Name:      "migration_remaining_records",
+			Help:      "Number of records whose ownership records remain to be migrated from the previous naming scheme.",
+		},
+	)
 	controllerNoChangesTotal = prometheus.NewCounter(
 		prometheus.CounterOpts{
 			Namespace: "external_dns",
@@ -170,6 +269,17 @@ func init() {
 	prometheus.MustRegister(sourceAAAARecords)
 	prometheus.MustRegister(verifiedARecords)
 	prometheus.MustRegister(verifiedAAAARecords)
+	prometheus.MustRegister(retriesTotal)
+	prometheus.MustRegister(deferredZones)
+	prometheus.MustRegister(unchangedZonesSkipped)
+	prometheus.MustRegister(unroutableHostnames)
+	prometheus.MustRegister(delegatedHostnames)
+	prometheus.MustRegister(propagationLatency)
+	prometheus.MustRegister(propagationTimeoutsTotal)
+	prometheus.MustRegister(recordsOutOfSync)
+	prometheus.MustRegister(ttlRepairsTotal)
+	prometheus.MustRegister(garbageCollectedTotal)
+	prometheus.MustRegister(migrationRemainingRecords)
 }
 
 // Controller is responsible for orchestrating the different components.
@@ -199,16 +309,124 @@ type Controller struct {
 	ExcludeRecordTypes []string
 	// MinEventSyncInterval is used as window for batching events
 	MinEventSyncInterval time.Duration
+	// Exporters receive the records managed by this instance after every successful synchronization
+	Exporters []RecordsExporter
+	// MaxMemoryEndpoints is the number of current and desired endpoints above which they are spilled to disk
+	// and synchronized in partitions. Zero keeps all endpoints in memory.
+	MaxMemoryEndpoints int
+	// ZoneLister lists the zones to synchronize one by one. If nil, all zones are synchronized at once.
+	ZoneLister provider.ZoneLister
+	// APIBudgetPerCycle caps the provider API requests of a synchronization one zone at a time: it is checked at the
+	// zone boundaries, so the requests of a single zone may exceed it.
+	// The zones left when the budget is exhausted are deferred to the next synchronization. Zero is unlimited.
+	APIBudgetPerCycle int64
+	// nextZone is the first zone of the next synchronization, after zones have been deferred
+	nextZone string
+	// Unroutable drops the desired endpoints matching no zone of the provider. If nil, all endpoints are planned.
+	Unroutable *UnroutableFilter
+	// Delegations drops the desired endpoints delegated to other zones by NS records. If nil, all endpoints are planned.
+	Delegations *DelegationGuard
+	// Propagation checks the propagation of the changes applied to a pool of resolvers. If nil, it is not checked.
+	Propagation *PropagationChecker
+	// OutOfSyncCycles is the number of synchronizations after which records differing from their desired state
+	// are reported as out of sync. If 0, the records out of sync are not tracked.
+	OutOfSyncCycles int
+	// drift tracks the records differing from their desired state
+	drift *driftTracker
+	// TTLRepairInterval is the interval between repairs of the records differing from their desired state only by their TTL,
+	// which are applied separately from the other changes and regardless of the policy. If 0, the TTLs are updated with the other changes.
+	TTLRepairInterval time.Duration
+	// lastTTLRepair is the time of the last synchronization repairing the TTLs
+	lastTTLRepair time.Time
+	// Renderer renders the changes before they are applied. If nil, the changes are not rendered.
+	Renderer *ChangesRenderer
+	// StatusAPI keeps the outcome of the last synchronizations for the status API of StatusHandler
+	StatusAPI bool
+	status    statusStore
+	// ReconcileTokenFile is the file of the bearer token required to trigger a synchronization through the status API,
+	// read on every request so that the token can be rotated. If empty, the trigger is forbidden.
+	ReconcileTokenFile string
+	// requestedHostnames are the hostnames whose zones the next synchronization handles first, guarded by runAtMutex
+	requestedHostnames []string
+	// queue is the work queue of the running controller and wake wakes up its scheduling, guarded by runAtMutex.
+	// Both are nil unless the controller is running.
+	queue workqueue.TypedRateLimitingInterface[reconcileRequest]
+	wake  chan struct{}
+	// PropertyComparator compares the provider-specific properties of the desired endpoints and the current records.
+	// If nil, the values must be equal.
+	PropertyComparator plan.PropertyComparator
+	// Lease of this instance on the record sets it manages, shared with the other instances through the registry.
+	// If nil, the record sets are managed regardless of the leases of the other instances.
+	Lease *plan.Lease
+	// SkipUnchangedZones skips listing the records, calculating the plan and applying the changes of the zones
+	// synchronized one by one whose desired endpoints and records didn't change since they were found in sync.
+	SkipUnchangedZones bool
+	// ZoneVersioner tells whether the records of a zone changed without listing them. If nil, the records
+	// are listed and compared to the records found in sync.
+	ZoneVersioner provider.ZoneVersioner
+	// unchanged are the zones found in sync
+	unchanged unchangedZones
+	// runMutex serializes the synchronizations and the explanations, which share the caches of the registry
+	runMutex sync.Mutex
+	// SlowCycleProfiler captures the profiles of the synchronizations lasting too long. If nil, no profile is captured.
+	SlowCycleProfiler *profiling.SlowCycleRecorder
+	// GarbageCollector removes the orphaned ownership records of the registry after a synchronization, at most once
+	// per GarbageCollectionInterval. If nil, the ownership records are changed with their records only.
+	GarbageCollector          registry.GarbageCollector
+	GarbageCollectionInterval time.Duration
+	// lastGarbageCollection is the time of the last garbage collection
+	lastGarbageCollection time.Time
+	// Migrator migrates the ownership records of the registry to its naming scheme after a synchronization.
+	// If nil, the ownership records are not migrated.
+	Migrator registry.Migrator
+	// migrationCompleted is set once the completion of the migration has been reported
+	migrationCompleted bool
+	// ZoneMaintainer maintains the resources of the zones besides their records after each synchronization of all
+	// zones. If nil, only the records are managed.
+	ZoneMaintainer provider.ZoneMaintainer
+}
+
+// logSkippedSummary logs the number of endpoints and resources skipped during the synchronization, by reason code.
+func logSkippedSummary() {
+	summary := endpoint.TakeSkippedSummary()
+	if len(summary) == 0 {
+		return
+	}
+	fields := make(log.Fields, len(summary))
+	total := 0
+	for reason, count := range summary {
+		fields[reason] = count
+		total += count
+	}
+	log.WithFields(fields).Debugf("Skipped %d endpoints and resources during the synchronization", total)
+}
+
+// RecordsExporter writes the managed records to an external system, e.g. the config of another DNS tool.
+type RecordsExporter interface {
+	Export(records []*endpoint.Endpoint) error
 }
 
 // RunOnce runs a single iteration of a reconciliation loop.
 func (c *Controller) RunOnce(ctx context.Context) error {
+	c.runMutex.Lock()
+	defer c.runMutex.Unlock()
 	lastReconcileTimestamp.SetToCurrentTime()
+	stopProfiling := c.SlowCycleProfiler.Watch()
+	defer stopProfiling()
+	// the summary covers this synchronization only
+	endpoint.TakeSkippedSummary()
+	defer logSkippedSummary()
 
 	c.runAtMutex.Lock()
 	c.lastRunAt = time.Now()
+	requested := c.requestedHostnames
+	c.requestedHostnames = nil
 	c.runAtMutex.Unlock()
 
+	if c.ZoneLister != nil {
+		return c.runOncePerZone(ctx, requested, false)
+	}
+
 	records, err := c.Registry.Records(ctx)
 	if err != nil {
 		registryErrorsTotal.Inc()
@@ -220,54 +438,257 @@ func (c *Controller) RunOnce(ctx context.Context) error {
 	regARecords, regAAAARecords := countAddressRecords(records)
 	registryARecords.Set(float64(regARecords))
 	registryAAAARecords.Set(float64(regAAAARecords))
-	ctx = context.WithValue(ctx, provider.RecordsContextKey, records)
 
-	endpoints, err := c.Source.Endpoints(ctx)
+	// spill the records before listing the sources, so that both are never held in memory at the same time
+	var spill *endpointSpill
+	if c.MaxMemoryEndpoints > 0 && len(records) > c.MaxMemoryEndpoints {
+		if spill, records, err = c.spillRecords(records); err != nil {
+			return err
+		}
+		defer spill.Close()
+	}
+	recordsCtx := ctx
+	if spill == nil {
+		recordsCtx = context.WithValue(ctx, provider.RecordsContextKey, records)
+	}
+
+	endpoints, err := c.Source.Endpoints(recordsCtx)
 	if err != nil {
 		sourceErrorsTotal.Inc()
 		deprecatedSourceErrors.Inc()
 		return err
 	}
 	sourceEndpointsTotal.Set(float64(len(endpoints)))
+	c.scheduleRefresh(endpoints)
 	srcARecords, srcAAAARecords := countAddressRecords(endpoints)
 	sourceARecords.Set(float64(srcARecords))
 	sourceAAAARecords.Set(float64(srcAAAARecords))
-	vARecords, vAAAARecords := countMatchingAddressRecords(endpoints, records)
-	verifiedARecords.Set(float64(vARecords))
-	verifiedAAAARecords.Set(float64(vAAAARecords))
+	if spill == nil {
+		vARecords, vAAAARecords := countMatchingAddressRecords(endpoints, records)
+		verifiedARecords.Set(float64(vARecords))
+		verifiedAAAARecords.Set(float64(vAAAARecords))
+	}
 	endpoints, err = c.Registry.AdjustEndpoints(endpoints)
 	if err != nil {
 		return fmt.Errorf("adjusting endpoints: %w", err)
 	}
-	registryFilter := c.Registry.GetDomainFilter()
+	if c.Unroutable != nil {
+		endpoints = c.Unroutable.Filter(ctx, endpoints)
+	}
+	if c.Delegations != nil {
+		endpoints = c.Delegations.Filter(ctx, records, endpoints)
+	}
 
-	plan := &plan.Plan{
+	if spill == nil && c.MaxMemoryEndpoints > 0 && len(records)+len(endpoints) > c.MaxMemoryEndpoints {
+		if spill, err = newEndpointSpill(); err != nil {
+			return err
+		}
+		defer spill.Close()
+		if err := spill.write("current", records); err != nil {
+			return err
+		}
+		records = nil
+	}
+
+	var changes *plan.Changes
+	var managed []*endpoint.Endpoint
+	if spill != nil {
+		err = spill.write("desired", endpoints)
+		endpoints = nil
+		if err != nil {
+			return err
+		}
+		if changes, managed, err = c.syncPartitioned(ctx, spill); err != nil {
+			return err
+		}
+	} else {
+		p := c.newPlan(records, endpoints)
+		calculated := p.Calculate()
+		changes = calculated.Changes
+		if len(c.Exporters) > 0 || c.StatusAPI {
+			managed = managedRecords(records, changes, c.Registry.OwnerID())
+		}
+
+		if changes.HasChanges() {
+			c.render(ctx, changes)
+			err = c.Registry.ApplyChanges(recordsCtx, changes)
+			c.observeDrift(p, changes, err, nil)
+			if err != nil {
+				registryErrorsTotal.Inc()
+				deprecatedRegistryErrors.Inc()
+				return err
+			}
+			if c.Propagation != nil {
+				c.Propagation.Check(ctx, changes)
+			}
+		} else {
+			c.observeDrift(p, changes, nil, nil)
+			controllerNoChangesTotal.Inc()
+			log.Info("All records are already up to date")
+		}
+
+		if c.ttlRepairDue() {
+			c.repairTTLs(recordsCtx, calculated.TTLRepairs)
+			c.lastTTLRepair = time.Now()
+		}
+	}
+	c.migrate(ctx)
+	c.collectGarbage(ctx)
+	c.maintainZones(ctx)
+
+	lastSyncTimestamp.SetToCurrentTime()
+	c.status.succeeded(managed, changes)
+
+	for _, exporter := range c.Exporters {
+		if err := exporter.Export(managed); err != nil {
+			log.Errorf("Failed to export records: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// maintainZones maintains the resources of the zones besides their records, if enabled. A failure is logged and
+// retried by the next synchronization rather than failing the synchronization of the records.
+func (c *Controller) maintainZones(ctx context.Context) {
+	if c.ZoneMaintainer == nil {
+		return
+	}
+	if err := c.ZoneMaintainer.MaintainZones(ctx); err != nil {
+		log.Warnf("Failed to maintain the zones: %v", err)
+	}
+}
+
+// render renders the changes, if enabled.
+func (c *Controller) render(ctx context.Context, changes *plan.Changes) {
+	if c.Renderer == nil {
+		return
+	}
+	if err := c.Renderer.Render(ctx, changes); err != nil {
+		log.Errorf("Failed to render changes: %v", err)
+	}
+}
+
+// observeDrift tracks the records of the plan differing from their desired state, if enabled.
+// Only the records matching the filter were synchronized, a nil filter matches all records.
+func (c *Controller) observeDrift(p *plan.Plan, changes *plan.Changes, err error, filter endpoint.DomainFilterInterface) {
+	if c.OutOfSyncCycles <= 0 {
+		return
+	}
+	if c.drift == nil {
+		c.drift = newDriftTracker(c.OutOfSyncCycles)
+	}
+	c.drift.observe(driftOf(p, changes, err), filter)
+}
+
+func (c *Controller) newPlan(current, desired []*endpoint.Endpoint) *plan.Plan {
+	return &plan.Plan{
 		Policies:       []plan.Policy{c.Policy},
-		Current:        records,
-		Desired:        endpoints,
-		DomainFilter:   endpoint.MatchAllDomainFilters{c.DomainFilter, registryFilter},
+		Current:        current,
+		Desired:        desired,
+		DomainFilter:   endpoint.MatchAllDomainFilters{c.DomainFilter, c.Registry.GetDomainFilter()},
 		ManagedRecords: c.ManagedRecordTypes,
 		ExcludeRecords: c.ExcludeRecordTypes,
 		OwnerID:        c.Registry.OwnerID(),
+
+		SeparateTTLRepairs: c.TTLRepairInterval > 0,
+
+		PropertyComparator: c.PropertyComparator,
+		Lease:              c.Lease,
 	}
+}
 
-	plan = plan.Calculate()
+// spillRecords spills the records to disk. It returns the NS records, which are kept in memory
+// for the delegation guard.
+func (c *Controller) spillRecords(records []*endpoint.Endpoint) (*endpointSpill, []*endpoint.Endpoint, error) {
+	spill, err := newEndpointSpill()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := spill.write("current", records); err != nil {
+		spill.Close()
+		return nil, nil, err
+	}
+	var delegations []*endpoint.Endpoint
+	if c.Delegations != nil {
+		for _, record := range records {
+			if record.RecordType == endpoint.RecordTypeNS {
+				delegations = append(delegations, record)
+			}
+		}
+	}
+	return spill, delegations, nil
+}
+
+// syncPartitioned calculates and applies the plan of the spilled current and desired endpoints one partition
+// at a time, so that at most MaxMemoryEndpoints endpoints and the changes of a single partition are held in memory.
+// It returns the applied changes if StatusAPI is enabled, and the records managed after the changes are applied
+// if they are exported or StatusAPI is enabled.
+func (c *Controller) syncPartitioned(ctx context.Context, spill *endpointSpill) (*plan.Changes, []*endpoint.Endpoint, error) {
+	partitions := spill.partitions(c.MaxMemoryEndpoints)
+	log.Infof("Synchronizing %d endpoints in %d partitions", spill.size(), len(partitions))
 
-	if plan.Changes.HasChanges() {
-		err = c.Registry.ApplyChanges(ctx, plan.Changes)
+	repairTTLs := c.ttlRepairDue()
+	keepManaged := len(c.Exporters) > 0 || c.StatusAPI
+	var applied *plan.Changes
+	if c.StatusAPI {
+		applied = &plan.Changes{}
+	}
+	var managed []*endpoint.Endpoint
+	hasChanges := false
+	vARecords, vAAAARecords := 0, 0
+	for _, buckets := range partitions {
+		current, err := spill.read("current", buckets)
 		if err != nil {
-			registryErrorsTotal.Inc()
-			deprecatedRegistryErrors.Inc()
-			return err
+			return nil, nil, err
 		}
-	} else {
+		desired, err := spill.read("desired", buckets)
+		if err != nil {
+			return nil, nil, err
+		}
+		// unlike the in-memory synchronization, the desired endpoints are counted after they are adjusted
+		a, aaaa := countMatchingAddressRecords(desired, current)
+		vARecords += a
+		vAAAARecords += aaaa
+
+		calculated := c.newPlan(current, desired).Calculate()
+		changes := calculated.Changes
+		if keepManaged {
+			managed = append(managed, managedRecords(current, changes, c.Registry.OwnerID())...)
+		}
+		if changes.HasChanges() {
+			hasChanges = true
+			c.render(ctx, changes)
+			if err := c.Registry.ApplyChanges(ctx, changes); err != nil {
+				registryErrorsTotal.Inc()
+				deprecatedRegistryErrors.Inc()
+				return nil, nil, err
+			}
+			if c.Propagation != nil {
+				c.Propagation.Check(ctx, changes)
+			}
+			if applied != nil {
+				applied.Create = append(applied.Create, changes.Create...)
+				applied.UpdateOld = append(applied.UpdateOld, changes.UpdateOld...)
+				applied.UpdateNew = append(applied.UpdateNew, changes.UpdateNew...)
+				applied.Delete = append(applied.Delete, changes.Delete...)
+			}
+		}
+		if repairTTLs {
+			c.repairTTLs(ctx, calculated.TTLRepairs)
+		}
+	}
+	verifiedARecords.Set(float64(vARecords))
+	verifiedAAAARecords.Set(float64(vAAAARecords))
+
+	if !hasChanges {
 		controllerNoChangesTotal.Inc()
 		log.Info("All records are already up to date")
 	}
-
-	lastSyncTimestamp.SetToCurrentTime()
-
-	return nil
+	if repairTTLs {
+		c.lastTTLRepair = time.Now()
+	}
+	return applied, managed, nil
 }
 
 func earliest(r time.Time, times ...time.Time) time.Time {
@@ -288,6 +709,25 @@ func latest(r time.Time, times ...time.Time) time.Time {
 	return r
 }
 
+// managedRecords returns the records owned by ownerID after the changes have been applied to current.
+func managedRecords(current []*endpoint.Endpoint, changes *plan.Changes, ownerID string) []*endpoint.Endpoint {
+	removed := map[endpoint.EndpointKey]struct{}{}
+	for _, eps := range [][]*endpoint.Endpoint{changes.Delete, changes.UpdateOld} {
+		for _, ep := range eps {
+			removed[ep.Key()] = struct{}{}
+		}
+	}
+	var records []*endpoint.Endpoint
+	for _, ep := range current {
+		if _, ok := removed[ep.Key()]; ok || !ep.IsOwnedBy(ownerID) {
+			continue
+		}
+		records = append(records, ep)
+	}
+	records = append(records, changes.Create...)
+	return append(records, changes.UpdateNew...)
+}
+
 // Counts the intersections of A and AAAA records in endpoint and registry.
 func countMatchingAddressRecords(endpoints []*endpoint.Endpoint, registryRecords []*endpoint.Endpoint) (int, int) {
 	recordsMap := make(map[string]map[string]struct{})
@@ -339,6 +779,42 @@ func (c *Controller) ScheduleRunOnce(now time.Time) {
 			c.nextRunAt,
 		),
 	)
+	c.wakeUp()
+}
+
+// ScheduleRunOnceFor schedules the synchronization of the zone of the hostname. When the zones are synchronized
+// one by one and the controller is running, the zone is synchronized on its own, as an item of the work queue
+// retried independently of the other zones. Otherwise, the next synchronization handles the zone first.
+func (c *Controller) ScheduleRunOnceFor(now time.Time, hostname string) {
+	c.runAtMutex.Lock()
+	if c.requestFor(hostname, c.lastRunAt.Add(c.MinEventSyncInterval).Sub(now)) {
+		c.runAtMutex.Unlock()
+		return
+	}
+	if !slices.Contains(c.requestedHostnames, hostname) {
+		c.requestedHostnames = append(c.requestedHostnames, hostname)
+	}
+	c.runAtMutex.Unlock()
+	c.ScheduleRunOnce(now)
+}
+
+// requestFor adds the synchronization of the zone of the hostname to the work queue after the delay. It returns false
+// if the zones are not synchronized one by one or the controller is not running. It must be called with runAtMutex held.
+func (c *Controller) requestFor(hostname string, delay time.Duration) bool {
+	if c.queue == nil || c.ZoneLister == nil {
+		return false
+	}
+	c.queue.AddAfter(reconcileRequest{Hostname: hostname}, delay)
+	return true
+}
+
+// wakeUp makes the running controller check when the next synchronization is due, after nextRunAt moved.
+// It must be called with runAtMutex held.
+func (c *Controller) wakeUp() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
 }
 
 func (c *Controller) ShouldRunOnce(now time.Time) bool {
@@ -351,25 +827,131 @@ func (c *Controller) ShouldRunOnce(now time.Time) bool {
 	return true
 }
 
-// Run runs RunOnce in a loop with a delay until context is canceled
-func (c *Controller) Run(ctx context.Context) {
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
+// untilNextRun returns the duration until the next synchronization is due.
+func (c *Controller) untilNextRun(now time.Time) time.Duration {
+	c.runAtMutex.Lock()
+	defer c.runAtMutex.Unlock()
+	return max(c.nextRunAt.Sub(now), 0)
+}
+
+// reconcileRequest is an item of the work queue: the synchronization of all zones, resynchronized every interval,
+// or, when the zones are synchronized one by one, of the zone of a hostname only. Each item is rate limited on its own:
+// a failing zone is retried with its own backoff, without delaying the synchronization of the other zones.
+type reconcileRequest struct {
+	// Hostname is the hostname whose zone is synchronized, or empty for all zones.
+	Hostname string
+}
+
+func (r reconcileRequest) String() string {
+	if r.Hostname == "" {
+		return "all zones"
+	}
+	return "the zone of " + r.Hostname
+}
+
+// Run runs RunOnce in a loop with a delay until context is canceled.
+// Synchronizations are queued on a rate limited work queue, which deduplicates requests arriving
+// while a synchronization is in progress and retries soft errors with an exponential backoff
+// instead of waiting for the next interval. It returns the first error that is not a soft error.
+func (c *Controller) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	queue := workqueue.NewTypedRateLimitingQueueWithConfig(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[reconcileRequest](retryBaseDelay, c.retryMaxDelay()),
+		workqueue.TypedRateLimitingQueueConfig[reconcileRequest]{Name: "external-dns", MetricsProvider: queueMetricsProvider{}},
+	)
+	wake := make(chan struct{}, 1)
+	c.runAtMutex.Lock()
+	c.queue, c.wake = queue, wake
+	c.runAtMutex.Unlock()
+	defer func() {
+		c.runAtMutex.Lock()
+		c.queue, c.wake = nil, nil
+		c.runAtMutex.Unlock()
+	}()
+
+	go c.enqueue(ctx, queue, wake)
 	for {
-		if c.ShouldRunOnce(time.Now()) {
-			if err := c.RunOnce(ctx); err != nil {
-				if errors.Is(err, provider.SoftError) {
-					log.Errorf("Failed to do run once: %v", err)
-				} else {
-					log.Fatalf("Failed to do run once: %v", err)
-				}
-			}
+		more, err := c.processNextItem(ctx, queue)
+		if err != nil {
+			return err
+		}
+		if !more {
+			break
 		}
+	}
+	log.Info("Terminating main controller loop")
+	return nil
+}
+
+// enqueue adds the synchronization of all zones to the queue whenever it is due, either after the interval or
+// earlier when woken up by an event, and shuts the queue down when the context is canceled.
+func (c *Controller) enqueue(ctx context.Context, queue workqueue.TypedRateLimitingInterface[reconcileRequest], wake <-chan struct{}) {
+	defer queue.ShutDown()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
 		select {
-		case <-ticker.C:
+		case <-timer.C:
+		case <-wake:
 		case <-ctx.Done():
-			log.Info("Terminating main controller loop")
 			return
 		}
+		now := time.Now()
+		if c.ShouldRunOnce(now) {
+			queue.Add(reconcileRequest{})
+		}
+		timer.Reset(c.untilNextRun(now))
+	}
+}
+
+// processNextItem runs a synchronization for the next item of the queue and returns false once the queue is shut down.
+// Soft errors are retried with the backoff of the item, other errors are returned.
+func (c *Controller) processNextItem(ctx context.Context, queue workqueue.TypedRateLimitingInterface[reconcileRequest]) (bool, error) {
+	req, shutdown := queue.Get()
+	if shutdown {
+		return false, nil
+	}
+	defer queue.Done(req)
+
+	var err error
+	if req.Hostname == "" {
+		err = c.RunOnce(ctx)
+	} else {
+		err = c.runOnceFor(ctx, req.Hostname)
+	}
+	if err == nil {
+		queue.Forget(req)
+		return true, nil
+	}
+	c.status.failed(err)
+	if ctx.Err() != nil {
+		// the synchronization was interrupted by the shutdown
+		return true, nil
+	}
+	if !errors.Is(err, provider.SoftError) {
+		return false, fmt.Errorf("failed to synchronize %s: %w", req, err)
+	}
+	retriesTotal.Inc()
+	log.Errorf("Failed to synchronize %s, retry %d: %v", req, queue.NumRequeues(req)+1, err)
+	queue.AddRateLimited(req)
+	return true, nil
+}
+
+// runOnceFor synchronizes the zone of the hostname only, see ScheduleRunOnceFor.
+func (c *Controller) runOnceFor(ctx context.Context, hostname string) error {
+	c.runMutex.Lock()
+	defer c.runMutex.Unlock()
+	lastReconcileTimestamp.SetToCurrentTime()
+	endpoint.TakeSkippedSummary()
+	defer logSkippedSummary()
+	return c.runOncePerZone(ctx, []string{hostname}, true)
+}
+
+// retryMaxDelay caps the backoff at the interval, as the next regular synchronization retries anyway.
+func (c *Controller) retryMaxDelay() time.Duration {
+	if c.Interval < retryBaseDelay {
+		return retryBaseDelay
 	}
+	return c.Interval
 }
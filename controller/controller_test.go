@@ -258,7 +258,7 @@ func TestRun(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	stopped := make(chan struct{})
 	go func() {
-		ctrl.Run(ctx)
+		assert.NoError(t, ctrl.Run(ctx))
 		close(stopped)
 	}()
 	time.Sleep(1500 * time.Millisecond)
@@ -272,6 +272,73 @@ func TestRun(t *testing.T) {
 	assert.Equal(t, math.Float64bits(1), valueFromMetric(verifiedAAAARecords))
 }
 
+// flakyMockProvider fails the first calls to Records with a soft error.
+type flakyMockProvider struct {
+	provider.BaseProvider
+	failures     int
+	recordsCalls int
+}
+
+func (p *flakyMockProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	p.recordsCalls++
+	if p.recordsCalls <= p.failures {
+		return nil, provider.NewSoftError(errors.New("provider unavailable"))
+	}
+	return nil, nil
+}
+
+func (p *flakyMockProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	return nil
+}
+
+// TestRunRetriesSoftErrors tests that Run retries soft errors without waiting for the next interval
+func TestRunRetriesSoftErrors(t *testing.T) {
+	defer func(delay time.Duration) { retryBaseDelay = delay }(retryBaseDelay)
+	retryBaseDelay = 10 * time.Millisecond
+
+	p := &flakyMockProvider{failures: 2}
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{}, nil)
+
+	ctrl := &Controller{
+		Source:   source,
+		Registry: r,
+		Policy:   &plan.SyncPolicy{},
+		Interval: time.Hour,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		assert.NoError(t, ctrl.Run(ctx))
+		close(stopped)
+	}()
+	time.Sleep(500 * time.Millisecond)
+	cancel()
+	<-stopped
+
+	assert.Equal(t, 3, p.recordsCalls)
+}
+
+// TestRunReturnsErrors tests that Run stops and returns the errors that are not soft errors
+func TestRunReturnsErrors(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return(nil, errors.New("source unavailable"))
+	r, err := registry.NewNoopRegistry(&flakyMockProvider{})
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:   source,
+		Registry: r,
+		Policy:   &plan.SyncPolicy{},
+		Interval: time.Hour,
+	}
+	err = ctrl.Run(context.Background())
+	assert.ErrorContains(t, err, "failed to synchronize all zones: source unavailable")
+}
+
 func valueFromMetric(metric prometheus.Gauge) uint64 {
 	ref := reflect.ValueOf(metric)
 	return reflect.Indirect(ref).FieldByName("valBits").Uint()
@@ -739,3 +806,61 @@ func TestAAAARecords(t *testing.T) {
 	assert.Equal(t, math.Float64bits(2), valueFromMetric(sourceAAAARecords))
 	assert.Equal(t, math.Float64bits(1), valueFromMetric(registryAAAARecords))
 }
+
+type fakeExporter struct {
+	exported [][]*endpoint.Endpoint
+}
+
+func (e *fakeExporter) Export(records []*endpoint.Endpoint) error {
+	e.exported = append(e.exported, records)
+	return nil
+}
+
+func TestControllerExportsManagedRecords(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("create-record.used.tld", endpoint.RecordTypeA, "1.2.3.4"),
+	}, nil)
+	r, err := registry.NewNoopRegistry(&filteredMockProvider{})
+	require.NoError(t, err)
+
+	exporter := &fakeExporter{}
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		DomainFilter:       endpoint.NewDomainFilter([]string{"used.tld"}),
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Exporters:          []RecordsExporter{exporter},
+	}
+
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	require.Len(t, exporter.exported, 1)
+	require.Len(t, exporter.exported[0], 1)
+	assert.Equal(t, "create-record.used.tld", exporter.exported[0][0].DNSName)
+}
+
+func TestManagedRecords(t *testing.T) {
+	owned := func(ep *endpoint.Endpoint, owner string) *endpoint.Endpoint {
+		ep.Labels = endpoint.Labels{endpoint.OwnerLabelKey: owner}
+		return ep
+	}
+	current := []*endpoint.Endpoint{
+		owned(endpoint.NewEndpoint("keep.example.com", endpoint.RecordTypeA, "1.1.1.1"), "owner"),
+		owned(endpoint.NewEndpoint("update.example.com", endpoint.RecordTypeA, "2.2.2.2"), "owner"),
+		owned(endpoint.NewEndpoint("delete.example.com", endpoint.RecordTypeA, "3.3.3.3"), "owner"),
+		owned(endpoint.NewEndpoint("foreign.example.com", endpoint.RecordTypeA, "4.4.4.4"), "other"),
+	}
+	changes := &plan.Changes{
+		Create:    []*endpoint.Endpoint{endpoint.NewEndpoint("create.example.com", endpoint.RecordTypeA, "5.5.5.5")},
+		UpdateOld: []*endpoint.Endpoint{current[1]},
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpoint("update.example.com", endpoint.RecordTypeA, "6.6.6.6")},
+		Delete:    []*endpoint.Endpoint{current[2]},
+	}
+
+	var names []string
+	for _, ep := range managedRecords(current, changes, "owner") {
+		names = append(names, ep.DNSName+" "+ep.Targets.String())
+	}
+	assert.Equal(t, []string{"keep.example.com 1.1.1.1", "create.example.com 5.5.5.5", "update.example.com 6.6.6.6"}, names)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// DelegationGuard drops the desired endpoints at or below a name that a zone of the provider delegates to another
+// zone with NS records, which resolvers never ask the delegating zone for. The NS records at the apex of the zones of
+// the provider are not delegations, and the NS and DS records at the delegated name itself are kept, since they are
+// served by the delegating zone. The hostnames skipped are reported once when they change instead of on every
+// synchronization.
+type DelegationGuard struct {
+	lister provider.ZoneLister
+	// reported are the hostnames skipped by the last synchronization
+	reported []string
+}
+
+// NewDelegationGuard creates a DelegationGuard telling the apexes of the zones of the provider from delegations.
+func NewDelegationGuard(lister provider.ZoneLister) *DelegationGuard {
+	return &DelegationGuard{lister: lister}
+}
+
+// Filter returns the endpoints not delegated by the NS records of the records. If the zones cannot be listed,
+// all endpoints are returned.
+func (g *DelegationGuard) Filter(ctx context.Context, records, endpoints []*endpoint.Endpoint) []*endpoint.Endpoint {
+	zones, err := g.lister.ZoneNames(ctx)
+	if err != nil {
+		log.Warnf("Failed to list zones, not skipping hostnames delegated to other zones: %v", err)
+		return endpoints
+	}
+	filtered, skipped := g.filter(zoneSet(zones), records, endpoints)
+	g.report(skipped)
+	return filtered
+}
+
+// filter returns the endpoints not delegated by the NS records of the records, and the hostnames skipped
+// with the name delegating them.
+func (g *DelegationGuard) filter(zones map[string]bool, records, endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, map[string]string) {
+	delegated := map[string]bool{}
+	for _, record := range records {
+		name := normalizeHostname(record.DNSName)
+		if record.RecordType == endpoint.RecordTypeNS && !zones[name] {
+			delegated[name] = true
+		}
+	}
+	skipped := map[string]string{}
+	if len(delegated) == 0 {
+		return endpoints, skipped
+	}
+
+	filtered := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		hostname := normalizeHostname(ep.DNSName)
+		if at := delegationOf(zones, delegated, hostname); at != "" &&
+			(at != hostname || (ep.RecordType != endpoint.RecordTypeNS && ep.RecordType != "DS")) {
+			skipped[hostname] = at
+			continue
+		}
+		filtered = append(filtered, ep)
+	}
+	return filtered, skipped
+}
+
+// report logs the hostnames skipped if they differ from the last synchronization.
+func (g *DelegationGuard) report(skipped map[string]string) {
+	hostnames := slices.Sorted(maps.Keys(skipped))
+	delegatedHostnames.Set(float64(len(hostnames)))
+	if slices.Equal(hostnames, g.reported) {
+		return
+	}
+	if len(hostnames) > 0 {
+		described := make([]string, 0, len(hostnames))
+		for _, hostname := range hostnames {
+			described = append(described, fmt.Sprintf("%s (delegated at %s)", hostname, skipped[hostname]))
+		}
+		log.Warnf("Skipping %d hostnames delegated to other zones with NS records: %s", len(hostnames), strings.Join(described, ", "))
+	} else {
+		log.Info("No hostname is delegated to another zone")
+	}
+	g.reported = hostnames
+}
+
+// delegationOf returns the delegated name the hostname is at or below, or an empty string. The names below the apex
+// of a zone of the provider are served by that zone, whatever the zones above delegate.
+func delegationOf(zones, delegated map[string]bool, hostname string) string {
+	for name := hostname; name != ""; {
+		if delegated[name] {
+			return name
+		}
+		if zones[name] {
+			return ""
+		}
+		_, parent, ok := strings.Cut(name, ".")
+		if !ok {
+			break
+		}
+		name = parent
+	}
+	return ""
+}
+
+// zoneSet returns the set of the zone names, lowercase without trailing dot.
+func zoneSet(zones []string) map[string]bool {
+	set := make(map[string]bool, len(zones))
+	for _, zone := range zones {
+		set[normalizeHostname(zone)] = true
+	}
+	return set
+}
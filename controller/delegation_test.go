@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestDelegationGuard(t *testing.T) {
+	lister := &countingZoneLister{zones: []string{"example.com", "hosted.team.example.com"}}
+	guard := NewDelegationGuard(lister)
+
+	records := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("example.com", endpoint.RecordTypeNS, "ns1.example.net"),
+		endpoint.NewEndpoint("Team.Example.com.", endpoint.RecordTypeNS, "ns1.example.org"),
+		endpoint.NewEndpoint("hosted.team.example.com", endpoint.RecordTypeNS, "ns1.example.net"),
+		endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+	}
+	endpoints := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("team.example.com", endpoint.RecordTypeNS, "ns2.example.org"),
+		endpoint.NewEndpoint("team.example.com", "DS", "12345 13 2 abcdef"),
+		endpoint.NewEndpoint("team.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("*.team.example.com", endpoint.RecordTypeCNAME, "www.example.com"),
+		endpoint.NewEndpoint("app.hosted.team.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+	}
+
+	for i := 0; i < 2; i++ {
+		filtered := guard.Filter(context.Background(), records, endpoints)
+		assert.Equal(t, []*endpoint.Endpoint{endpoints[0], endpoints[1], endpoints[2], endpoints[5]}, filtered)
+		assert.Equal(t, 2.0, testutil.ToFloat64(delegatedHostnames))
+	}
+	assert.Equal(t, []string{"*.team.example.com", "team.example.com"}, guard.reported)
+
+	// the hostnames are kept once the delegation is removed
+	assert.Equal(t, endpoints, guard.Filter(context.Background(), records[2:], endpoints))
+	assert.Equal(t, 0.0, testutil.ToFloat64(delegatedHostnames))
+	assert.Empty(t, guard.reported)
+}
+
+func TestDelegationGuardListError(t *testing.T) {
+	guard := NewDelegationGuard(&countingZoneLister{err: errors.New("failed")})
+
+	records := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("team.example.com", endpoint.RecordTypeNS, "ns1.example.org"),
+	}
+	endpoints := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("www.team.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+	}
+	assert.Equal(t, endpoints, guard.Filter(context.Background(), records, endpoints))
+}
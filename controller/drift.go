@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// Reasons why a record is not brought in sync with its desired state.
+const (
+	// driftProviderError means applying the changes failed
+	driftProviderError = "provider_error"
+	// driftPolicy means the policy does not allow the change
+	driftPolicy = "policy"
+	// driftConflict means the record is owned by another owner
+	driftConflict = "conflict"
+	// driftPending means the changes were applied, but the record still differs
+	driftPending = "pending"
+)
+
+var driftReasons = []string{driftProviderError, driftPolicy, driftConflict, driftPending}
+
+type driftKey struct {
+	dnsName       string
+	setIdentifier string
+	recordType    string
+}
+
+func newDriftKey(ep *endpoint.Endpoint) driftKey {
+	return driftKey{
+		dnsName:       strings.TrimSuffix(strings.ToLower(ep.DNSName), "."),
+		setIdentifier: ep.SetIdentifier,
+		recordType:    ep.RecordType,
+	}
+}
+
+type driftRecord struct {
+	cycles int
+	reason string
+}
+
+// driftTracker counts the consecutive synchronizations in which records differ from their desired state,
+// and reports the records differing for more than a number of synchronizations.
+type driftTracker struct {
+	cycles  int
+	records map[driftKey]driftRecord
+}
+
+func newDriftTracker(cycles int) *driftTracker {
+	return &driftTracker{cycles: cycles, records: map[driftKey]driftRecord{}}
+}
+
+// observe records the differing records of a synchronization. Only the records whose DNS name matches
+// the domain filter were synchronized, the others keep their count. A nil filter matches all DNS names.
+func (t *driftTracker) observe(drift map[driftKey]string, filter endpoint.DomainFilterInterface) {
+	for key := range t.records {
+		if _, ok := drift[key]; !ok && (filter == nil || filter.Match(key.dnsName)) {
+			delete(t.records, key)
+		}
+	}
+	for key, reason := range drift {
+		t.records[key] = driftRecord{cycles: t.records[key].cycles + 1, reason: reason}
+	}
+
+	counts := map[string]int{}
+	for _, record := range t.records {
+		if record.cycles > t.cycles {
+			counts[record.reason]++
+		}
+	}
+	for _, reason := range driftReasons {
+		recordsOutOfSync.WithLabelValues(reason).Set(float64(counts[reason]))
+	}
+}
+
+// driftOf returns the records of a plan differing from their desired state, with the reason they are not
+// brought in sync. The changes are the calculated changes of the plan, and err is the error applying them.
+func driftOf(p *plan.Plan, changes *plan.Changes, err error) map[driftKey]string {
+	drift := map[driftKey]string{}
+	add := func(reason string, endpoints ...[]*endpoint.Endpoint) {
+		for _, eps := range endpoints {
+			for _, ep := range eps {
+				if _, ok := drift[newDriftKey(ep)]; !ok {
+					drift[newDriftKey(ep)] = reason
+				}
+			}
+		}
+	}
+
+	reason := driftPending
+	if err != nil {
+		reason = driftProviderError
+	}
+	add(reason, changes.Create, changes.UpdateNew, changes.Delete)
+
+	// the changes of the owned records without the policy
+	unrestricted := *p
+	unrestricted.Policies = []plan.Policy{&plan.SyncPolicy{}}
+	owned := unrestricted.Calculate().Changes
+	add(driftPolicy, owned.Create, owned.UpdateNew, owned.Delete)
+
+	// the desired records of other owners
+	if unrestricted.OwnerID != "" {
+		unrestricted.OwnerID = ""
+		all := unrestricted.Calculate().Changes
+		add(driftConflict, all.Create, all.UpdateNew)
+	}
+	return drift
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func ownedEndpoint(dnsName, target, owner string) *endpoint.Endpoint {
+	ep := endpoint.NewEndpoint(dnsName, endpoint.RecordTypeA, target)
+	ep.Labels[endpoint.OwnerLabelKey] = owner
+	return ep
+}
+
+func TestDriftOf(t *testing.T) {
+	newTestPlan := func() *plan.Plan {
+		return &plan.Plan{
+			Policies: []plan.Policy{&plan.UpsertOnlyPolicy{}},
+			Current: []*endpoint.Endpoint{
+				ownedEndpoint("update.example.com", "1.1.1.1", "owner"),
+				ownedEndpoint("conflict.example.com", "1.1.1.1", "other"),
+				ownedEndpoint("delete.example.com", "1.1.1.1", "owner"),
+				ownedEndpoint("foreign.example.com", "1.1.1.1", "other"),
+			},
+			Desired: []*endpoint.Endpoint{
+				endpoint.NewEndpoint("Update.example.com", endpoint.RecordTypeA, "2.2.2.2"),
+				endpoint.NewEndpoint("conflict.example.com", endpoint.RecordTypeA, "2.2.2.2"),
+				endpoint.NewEndpoint("create.example.com", endpoint.RecordTypeA, "2.2.2.2"),
+			},
+			ManagedRecords: []string{endpoint.RecordTypeA},
+			OwnerID:        "owner",
+		}
+	}
+
+	p := newTestPlan()
+	changes := p.Calculate().Changes
+	assert.Equal(t, map[driftKey]string{
+		{dnsName: "update.example.com", recordType: endpoint.RecordTypeA}:   driftPending,
+		{dnsName: "create.example.com", recordType: endpoint.RecordTypeA}:   driftPending,
+		{dnsName: "delete.example.com", recordType: endpoint.RecordTypeA}:   driftPolicy,
+		{dnsName: "conflict.example.com", recordType: endpoint.RecordTypeA}: driftConflict,
+	}, driftOf(p, changes, nil))
+
+	p = newTestPlan()
+	changes = p.Calculate().Changes
+	assert.Equal(t, map[driftKey]string{
+		{dnsName: "update.example.com", recordType: endpoint.RecordTypeA}:   driftProviderError,
+		{dnsName: "create.example.com", recordType: endpoint.RecordTypeA}:   driftProviderError,
+		{dnsName: "delete.example.com", recordType: endpoint.RecordTypeA}:   driftPolicy,
+		{dnsName: "conflict.example.com", recordType: endpoint.RecordTypeA}: driftConflict,
+	}, driftOf(p, changes, errors.New("failed")))
+}
+
+func TestDriftTracker(t *testing.T) {
+	tracker := newDriftTracker(2)
+	a := driftKey{dnsName: "a.example.com", recordType: endpoint.RecordTypeA}
+	b := driftKey{dnsName: "b.example.org", recordType: endpoint.RecordTypeA}
+
+	for i := 0; i < 2; i++ {
+		tracker.observe(map[driftKey]string{a: driftPolicy, b: driftPending}, nil)
+		assert.Equal(t, 0.0, testutil.ToFloat64(recordsOutOfSync.WithLabelValues(driftPolicy)))
+	}
+	tracker.observe(map[driftKey]string{a: driftPolicy, b: driftProviderError}, nil)
+	assert.Equal(t, 1.0, testutil.ToFloat64(recordsOutOfSync.WithLabelValues(driftPolicy)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(recordsOutOfSync.WithLabelValues(driftProviderError)))
+	assert.Equal(t, 0.0, testutil.ToFloat64(recordsOutOfSync.WithLabelValues(driftPending)))
+
+	// records outside of the synchronized domains keep their count
+	tracker.observe(map[driftKey]string{}, endpoint.NewDomainFilter([]string{"example.com"}))
+	assert.Equal(t, 0.0, testutil.ToFloat64(recordsOutOfSync.WithLabelValues(driftPolicy)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(recordsOutOfSync.WithLabelValues(driftProviderError)))
+
+	tracker.observe(map[driftKey]string{}, nil)
+	assert.Equal(t, 0.0, testutil.ToFloat64(recordsOutOfSync.WithLabelValues(driftProviderError)))
+}
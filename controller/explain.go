@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// Stages of the synchronization traced by Explain.
+const (
+	ExplainStageProvider = "provider"
+	ExplainStageSource   = "source"
+	ExplainStageFilter   = "filter"
+	ExplainStagePlan     = "plan"
+	ExplainStagePolicy   = "policy"
+	ExplainStageOwner    = "ownership"
+)
+
+// ExplainStep is a decision taken for a hostname during the synchronization.
+type ExplainStep struct {
+	Stage    string             `json:"stage"`
+	Decision string             `json:"decision"`
+	Endpoint *endpoint.Endpoint `json:"endpoint,omitempty"`
+}
+
+// Explanation traces a hostname through the sources, the filters, the plan, the policy,
+// the ownership of the registry and the records of the provider.
+type Explanation struct {
+	Hostname string        `json:"hostname"`
+	Steps    []ExplainStep `json:"steps"`
+	// Changes are the changes a synchronization would apply to the records of the hostname
+	Changes *plan.Changes `json:"changes"`
+}
+
+func (e *Explanation) add(stage string, ep *endpoint.Endpoint, format string, args ...any) {
+	e.Steps = append(e.Steps, ExplainStep{Stage: stage, Decision: fmt.Sprintf(format, args...), Endpoint: ep})
+}
+
+// Explain calculates the changes of a synchronization for the records of a hostname, without applying them,
+// and returns the decisions leading to them.
+func (c *Controller) Explain(ctx context.Context, hostname string) (*Explanation, error) {
+	c.runMutex.Lock()
+	defer c.runMutex.Unlock()
+
+	hostname = normalizeHostname(hostname)
+	e := &Explanation{Hostname: hostname, Steps: []ExplainStep{}}
+	ownerID := c.Registry.OwnerID()
+
+	records, err := c.Registry.Records(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing records: %w", err)
+	}
+	current := matchingHostname(records, hostname)
+	if len(current) == 0 {
+		e.add(ExplainStageProvider, nil, "the provider has no records of the hostname")
+	}
+	for _, ep := range current {
+		switch owner, ok := ep.Labels[endpoint.OwnerLabelKey]; {
+		case !ok || owner == "":
+			e.add(ExplainStageProvider, ep, "the provider has a %s record without owner", ep.RecordType)
+		case owner == ownerID:
+			e.add(ExplainStageProvider, ep, "the provider has a %s record owned by this instance", ep.RecordType)
+		default:
+			e.add(ExplainStageProvider, ep, "the provider has a %s record owned by %s", ep.RecordType, owner)
+		}
+	}
+
+	endpoints, err := c.Source.Endpoints(context.WithValue(ctx, provider.RecordsContextKey, records))
+	if err != nil {
+		return nil, fmt.Errorf("listing endpoints: %w", err)
+	}
+	takeRefreshIntervals(endpoints)
+	endpoints, err = c.Registry.AdjustEndpoints(matchingHostname(endpoints, hostname))
+	if err != nil {
+		return nil, fmt.Errorf("adjusting endpoints: %w", err)
+	}
+	if len(endpoints) == 0 {
+		e.add(ExplainStageSource, nil, "no source produces the hostname")
+	}
+	for _, ep := range endpoints {
+		if resource := ep.Labels[endpoint.ResourceLabelKey]; resource != "" {
+			e.add(ExplainStageSource, ep, "%s produces a %s record", resource, ep.RecordType)
+		} else {
+			e.add(ExplainStageSource, ep, "a source produces a %s record", ep.RecordType)
+		}
+	}
+
+	domainFilter := endpoint.MatchAllDomainFilters{c.DomainFilter, c.Registry.GetDomainFilter()}
+	var desired []*endpoint.Endpoint
+	for _, ep := range endpoints {
+		switch {
+		case !domainFilter.Match(ep.DNSName):
+			e.add(ExplainStageFilter, ep, "the hostname does not match the domain filters")
+		case !plan.IsManagedRecord(ep.RecordType, c.ManagedRecordTypes, c.ExcludeRecordTypes):
+			e.add(ExplainStageFilter, ep, "the %s record type is not managed", ep.RecordType)
+		case c.Unroutable != nil && c.Unroutable.refresh(ctx) == nil && c.Unroutable.hasNoZone(hostname):
+			e.add(ExplainStageFilter, ep, "the hostname matches no zone of the provider")
+		default:
+			e.add(ExplainStageFilter, ep, "the %s record passes the filters", ep.RecordType)
+			desired = append(desired, ep)
+		}
+	}
+
+	p := c.newPlan(current, desired)
+	e.Changes = p.Calculate().Changes
+	for _, ep := range e.Changes.Create {
+		e.add(ExplainStagePlan, ep, "the %s record is created", ep.RecordType)
+	}
+	for _, ep := range e.Changes.UpdateNew {
+		e.add(ExplainStagePlan, ep, "the %s record is updated", ep.RecordType)
+	}
+	for _, ep := range e.Changes.Delete {
+		e.add(ExplainStagePlan, ep, "the %s record is deleted", ep.RecordType)
+	}
+
+	blocked := false
+	drift := driftOf(p, e.Changes, nil)
+	keys := slices.SortedFunc(maps.Keys(drift), func(a, b driftKey) int {
+		return cmp.Or(cmp.Compare(a.recordType, b.recordType), cmp.Compare(a.setIdentifier, b.setIdentifier))
+	})
+	for _, key := range keys {
+		switch drift[key] {
+		case driftPolicy:
+			blocked = true
+			e.add(ExplainStagePolicy, nil, "the %s policy does not allow changing the %s record", policyName(c.Policy), key.recordType)
+		case driftConflict:
+			blocked = true
+			e.add(ExplainStageOwner, nil, "the %s record is owned by another owner than %q", key.recordType, ownerID)
+		}
+	}
+	if !e.Changes.HasChanges() && !blocked && len(desired) > 0 {
+		e.add(ExplainStagePlan, nil, "the records are up to date")
+	}
+	return e, nil
+}
+
+func matchingHostname(endpoints []*endpoint.Endpoint, hostname string) []*endpoint.Endpoint {
+	var matching []*endpoint.Endpoint
+	for _, ep := range endpoints {
+		if normalizeHostname(ep.DNSName) == hostname {
+			matching = append(matching, ep)
+		}
+	}
+	return matching
+}
+
+func policyName(policy plan.Policy) string {
+	for name, p := range plan.Policies {
+		if fmt.Sprintf("%T", p) == fmt.Sprintf("%T", policy) {
+			return name
+		}
+	}
+	return fmt.Sprintf("%T", policy)
+}
+
+func (c *Controller) explainHandler(w http.ResponseWriter, req *http.Request) {
+	hostname := req.URL.Query().Get("hostname")
+	if hostname == "" {
+		http.Error(w, "missing hostname query parameter", http.StatusBadRequest)
+		return
+	}
+	e, err := c.Explain(req.Context(), hostname)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, e)
+}
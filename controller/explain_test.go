@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+func TestExplain(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider(inmemory.InMemoryInitZones([]string{"example.com"}))
+	newRegistry := func(ownerID string) registry.Registry {
+		r, err := registry.NewTXTRegistry(p, registry.TXTConfig{
+			OwnerID:            ownerID,
+			ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		})
+		require.NoError(t, err)
+		return r
+	}
+	require.NoError(t, newRegistry("other").ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("conflict.example.com", endpoint.RecordTypeA, "1.1.1.1"),
+	}}))
+	r := newRegistry("owner")
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("keep.example.com", endpoint.RecordTypeA, "1.1.1.1"),
+		endpoint.NewEndpoint("old.example.com", endpoint.RecordTypeA, "1.1.1.1"),
+	}}))
+
+	keep := endpoint.NewEndpoint("keep.example.com", endpoint.RecordTypeA, "1.1.1.1")
+	keep.Labels[endpoint.ResourceLabelKey] = "ingress/default/keep"
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		keep,
+		endpoint.NewEndpoint("conflict.example.com", endpoint.RecordTypeA, "2.2.2.2"),
+		endpoint.NewEndpoint("new.example.com", endpoint.RecordTypeA, "3.3.3.3"),
+		endpoint.NewEndpoint("new.example.com", endpoint.RecordTypeAAAA, "2001:db8::1"),
+		endpoint.NewEndpoint("filtered.example.org", endpoint.RecordTypeA, "4.4.4.4"),
+	}, nil)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.UpsertOnlyPolicy{},
+		DomainFilter:       endpoint.NewDomainFilter([]string{"example.com"}),
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+	}
+
+	for _, tc := range []struct {
+		hostname string
+		steps    [][2]string
+	}{
+		{
+			hostname: "Keep.example.com.",
+			steps: [][2]string{
+				{ExplainStageProvider, "the provider has a A record owned by this instance"},
+				{ExplainStageSource, "ingress/default/keep produces a A record"},
+				{ExplainStageFilter, "the A record passes the filters"},
+				{ExplainStagePlan, "the records are up to date"},
+			},
+		},
+		{
+			hostname: "conflict.example.com",
+			steps: [][2]string{
+				{ExplainStageProvider, "the provider has a A record owned by other"},
+				{ExplainStageSource, "a source produces a A record"},
+				{ExplainStageFilter, "the A record passes the filters"},
+				{ExplainStageOwner, `the A record is owned by another owner than "owner"`},
+			},
+		},
+		{
+			hostname: "old.example.com",
+			steps: [][2]string{
+				{ExplainStageProvider, "the provider has a A record owned by this instance"},
+				{ExplainStageSource, "no source produces the hostname"},
+				{ExplainStagePolicy, "the upsert-only policy does not allow changing the A record"},
+			},
+		},
+		{
+			hostname: "new.example.com",
+			steps: [][2]string{
+				{ExplainStageProvider, "the provider has no records of the hostname"},
+				{ExplainStageSource, "a source produces a A record"},
+				{ExplainStageSource, "a source produces a AAAA record"},
+				{ExplainStageFilter, "the A record passes the filters"},
+				{ExplainStageFilter, "the AAAA record type is not managed"},
+				{ExplainStagePlan, "the A record is created"},
+			},
+		},
+		{
+			hostname: "filtered.example.org",
+			steps: [][2]string{
+				{ExplainStageProvider, "the provider has no records of the hostname"},
+				{ExplainStageSource, "a source produces a A record"},
+				{ExplainStageFilter, "the hostname does not match the domain filters"},
+			},
+		},
+	} {
+		t.Run(tc.hostname, func(t *testing.T) {
+			e, err := ctrl.Explain(ctx, tc.hostname)
+			require.NoError(t, err)
+			var steps [][2]string
+			for _, step := range e.Steps {
+				steps = append(steps, [2]string{step.Stage, step.Decision})
+			}
+			assert.Equal(t, tc.steps, steps)
+		})
+	}
+
+	// nothing is applied
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	assert.Len(t, records, 9)
+}
+
+func TestExplainHandler(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{}, nil)
+	r, err := registry.NewNoopRegistry(&filteredMockProvider{})
+	require.NoError(t, err)
+	ctrl := &Controller{Source: source, Registry: r, Policy: &plan.SyncPolicy{}}
+
+	server := httptest.NewServer(ctrl.StatusHandler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/explain")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/api/v1/explain?hostname=foo.example.com")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
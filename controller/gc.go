@@ -0,0 +1,49 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// collectGarbage removes the orphaned ownership records of the registry if GarbageCollectionInterval has passed
+// since the last collection. The orphaned ownership records don't affect the resolution of the records,
+// so a failure is logged and the garbage is collected by the next collection rather than failing the synchronization.
+func (c *Controller) collectGarbage(ctx context.Context) {
+	if c.GarbageCollector == nil || time.Since(c.lastGarbageCollection) < c.GarbageCollectionInterval {
+		return
+	}
+	c.lastGarbageCollection = time.Now()
+	report, err := c.GarbageCollector.CollectGarbage(ctx)
+	if err != nil {
+		log.Warnf("Failed to collect the orphaned ownership records: %v", err)
+		return
+	}
+	garbageCollectedTotal.WithLabelValues("removed").Add(float64(len(report.Removed)))
+	garbageCollectedTotal.WithLabelValues("repaired").Add(float64(len(report.Repaired)))
+	if len(report.Removed) == 0 && len(report.Repaired) == 0 {
+		log.Debug("No orphaned ownership records found")
+		return
+	}
+	log.WithFields(log.Fields{
+		"removed":  len(report.Removed),
+		"repaired": len(report.Repaired),
+	}).Info("Collected the orphaned ownership records")
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+type fakeGarbageCollector struct {
+	calls  int
+	report *registry.GarbageReport
+	err    error
+}
+
+func (f *fakeGarbageCollector) CollectGarbage(context.Context) (*registry.GarbageReport, error) {
+	f.calls++
+	return f.report, f.err
+}
+
+func TestCollectGarbage(t *testing.T) {
+	p := inmemory.NewInMemoryProvider(inmemory.InMemoryInitZones([]string{"example.com"}))
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{}, nil)
+
+	gc := &fakeGarbageCollector{report: &registry.GarbageReport{
+		Removed: []*endpoint.Endpoint{endpoint.NewEndpoint("a-gone.example.com", endpoint.RecordTypeTXT, "owner")},
+	}}
+	ctrl := &Controller{
+		Source:                    source,
+		Registry:                  r,
+		Policy:                    &plan.SyncPolicy{},
+		ManagedRecordTypes:        []string{endpoint.RecordTypeA},
+		GarbageCollector:          gc,
+		GarbageCollectionInterval: time.Hour,
+	}
+	removed := testutil.ToFloat64(garbageCollectedTotal.WithLabelValues("removed"))
+
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 1, gc.calls)
+	assert.Equal(t, removed+1, testutil.ToFloat64(garbageCollectedTotal.WithLabelValues("removed")))
+
+	// the garbage is collected once per interval
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 1, gc.calls)
+
+	// a failed collection doesn't fail the synchronization
+	ctrl.lastGarbageCollection = time.Time{}
+	gc.err = errors.New("failed")
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 2, gc.calls)
+
+	// the garbage is collected after the synchronization of the zones one by one as well
+	ctrl.ZoneLister = p
+	ctrl.lastGarbageCollection = time.Time{}
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 3, gc.calls)
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// migrate migrates the next ownership records of the registry to its naming scheme or schema. The records stay
+// owned under the naming scheme or schema migrated from until they are migrated, so a failure is logged and
+// retried by the next synchronization rather than failing the synchronization.
+func (c *Controller) migrate(ctx context.Context) {
+	if c.Migrator == nil {
+		return
+	}
+	migrated, remaining, err := c.Migrator.Migrate(ctx)
+	if err != nil {
+		log.Warnf("Failed to migrate the ownership records: %v", err)
+		return
+	}
+	migrationRemainingRecords.Set(float64(remaining))
+	if remaining > 0 {
+		c.migrationCompleted = false
+		log.WithFields(log.Fields{
+			"migrated":  migrated,
+			"remaining": remaining,
+		}).Info("Migrated the ownership records")
+		return
+	}
+	if !c.migrationCompleted {
+		c.migrationCompleted = true
+		log.WithField("migrated", migrated).Info("Migration of the ownership records completed")
+	}
+}
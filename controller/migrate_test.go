@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+type fakeMigrator struct {
+	calls     int
+	remaining []int
+	err       error
+}
+
+func (f *fakeMigrator) Migrate(context.Context) (int, int, error) {
+	remaining := f.remaining[f.calls]
+	f.calls++
+	return 1, remaining, f.err
+}
+
+func TestMigrate(t *testing.T) {
+	p := inmemory.NewInMemoryProvider(inmemory.InMemoryInitZones([]string{"example.com"}))
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{}, nil)
+
+	migrator := &fakeMigrator{remaining: []int{2, 0, 0, 0}}
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Migrator:           migrator,
+	}
+
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 1, migrator.calls)
+	assert.Equal(t, float64(2), testutil.ToFloat64(migrationRemainingRecords))
+	assert.False(t, ctrl.migrationCompleted)
+
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, float64(0), testutil.ToFloat64(migrationRemainingRecords))
+	assert.True(t, ctrl.migrationCompleted)
+
+	// a failed migration doesn't fail the synchronization
+	migrator.err = errors.New("failed")
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 3, migrator.calls)
+
+	// the ownership records are migrated after the synchronization of the zones one by one as well
+	migrator.err = nil
+	ctrl.ZoneLister = p
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 4, migrator.calls)
+}
+
+type fakeZoneMaintainer struct {
+	calls int
+	err   error
+}
+
+func (f *fakeZoneMaintainer) MaintainZones(context.Context) error {
+	f.calls++
+	return f.err
+}
+
+func TestMaintainZones(t *testing.T) {
+	p := inmemory.NewInMemoryProvider(inmemory.InMemoryInitZones([]string{"example.com"}))
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{}, nil)
+
+	maintainer := &fakeZoneMaintainer{err: errors.New("failed")}
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		ZoneMaintainer:     maintainer,
+	}
+
+	// a failure doesn't fail the synchronization
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 1, maintainer.calls)
+
+	// the zones are maintained after the synchronization of all zones one by one, not of a requested zone
+	ctrl.ZoneLister = p
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 2, maintainer.calls)
+	require.NoError(t, ctrl.runOnceFor(context.Background(), "app.example.com"))
+	assert.Equal(t, 2, maintainer.calls)
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// propagationCheckedTypes are the record types whose propagation is checked.
+var propagationCheckedTypes = map[string]uint16{
+	endpoint.RecordTypeA:     dns.TypeA,
+	endpoint.RecordTypeAAAA:  dns.TypeAAAA,
+	endpoint.RecordTypeCNAME: dns.TypeCNAME,
+	endpoint.RecordTypeTXT:   dns.TypeTXT,
+}
+
+// lookupFunc returns the values of the records of the type of the name answered by the resolver.
+type lookupFunc func(ctx context.Context, resolver, name string, qtype uint16) ([]string, error)
+
+// PropagationChecker queries a pool of resolvers for the names created and updated by the synchronizations
+// until they answer the desired targets, and observes the latency of each resolver from the changes being applied,
+// which detects providers whose edge servers are not in sync. The checks run in the background, so that the
+// synchronizations are not delayed.
+type PropagationChecker struct {
+	resolvers []string
+	interval  time.Duration
+	timeout   time.Duration
+	lookup    lookupFunc
+	// wg tracks the checks running in the background
+	wg sync.WaitGroup
+}
+
+// NewPropagationChecker creates a PropagationChecker querying the resolvers, given as host or host:port,
+// every interval until they answer the desired targets or the timeout expires.
+func NewPropagationChecker(resolvers []string, interval, timeout time.Duration) *PropagationChecker {
+	addresses := make([]string, 0, len(resolvers))
+	for _, resolver := range resolvers {
+		if _, _, err := net.SplitHostPort(resolver); err != nil {
+			resolver = net.JoinHostPort(resolver, "53")
+		}
+		addresses = append(addresses, resolver)
+	}
+	return &PropagationChecker{resolvers: addresses, interval: interval, timeout: timeout, lookup: lookupDNS}
+}
+
+// Check starts checking the propagation of the names created and updated by the changes to every resolver.
+func (c *PropagationChecker) Check(ctx context.Context, changes *plan.Changes) {
+	var checked []*endpoint.Endpoint
+	for _, ep := range slices.Concat(changes.Create, changes.UpdateNew) {
+		if _, ok := propagationCheckedTypes[ep.RecordType]; ok && len(ep.Targets) > 0 && !isAlias(ep) {
+			checked = append(checked, ep)
+		}
+	}
+	if len(checked) == 0 {
+		return
+	}
+	appliedAt := time.Now()
+	for _, resolver := range c.resolvers {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.check(ctx, resolver, checked, appliedAt)
+		}()
+	}
+}
+
+// Wait waits for the checks running in the background.
+func (c *PropagationChecker) Wait() {
+	c.wg.Wait()
+}
+
+// check queries the resolver for the endpoints until all of them are propagated or the timeout expires.
+func (c *PropagationChecker) check(ctx context.Context, resolver string, pending []*endpoint.Endpoint, appliedAt time.Time) {
+	ctx, cancel := context.WithDeadline(ctx, appliedAt.Add(c.timeout))
+	defer cancel()
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		remaining := pending[:0:0]
+		for _, ep := range pending {
+			if c.propagated(ctx, resolver, ep) {
+				propagationLatency.WithLabelValues(resolver).Observe(time.Since(appliedAt).Seconds())
+				continue
+			}
+			remaining = append(remaining, ep)
+		}
+		pending = remaining
+		if len(pending) == 0 {
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				propagationTimeoutsTotal.WithLabelValues(resolver).Add(float64(len(pending)))
+				names := make([]string, 0, len(pending))
+				for _, ep := range pending {
+					names = append(names, ep.DNSName)
+				}
+				log.Warnf("The changes of %d records were not propagated to the resolver %s after %s: %s", len(pending), resolver, c.timeout, strings.Join(names, ", "))
+			}
+			return
+		}
+	}
+}
+
+// propagated returns true if the resolver answers the desired targets of the endpoint. The targets of records with a
+// set identifier are answered depending on their routing policy, so that one of them is enough.
+func (c *PropagationChecker) propagated(ctx context.Context, resolver string, ep *endpoint.Endpoint) bool {
+	answered, err := c.lookup(ctx, resolver, ep.DNSName, propagationCheckedTypes[ep.RecordType])
+	if err != nil {
+		log.Debugf("Failed to query the resolver %s for %s %s: %v", resolver, ep.RecordType, ep.DNSName, err)
+		return false
+	}
+	values := map[string]bool{}
+	for _, value := range answered {
+		values[normalizeTarget(value)] = true
+	}
+	if ep.SetIdentifier != "" {
+		return slices.ContainsFunc(ep.Targets, func(target string) bool { return values[normalizeTarget(target)] })
+	}
+	for _, target := range ep.Targets {
+		if !values[normalizeTarget(target)] {
+			return false
+		}
+	}
+	return true
+}
+
+// isAlias returns true if the A or AAAA endpoint targets hostnames, e.g. of an alias record, which are answered
+// with the addresses they resolve to.
+func isAlias(ep *endpoint.Endpoint) bool {
+	if ep.RecordType != endpoint.RecordTypeA && ep.RecordType != endpoint.RecordTypeAAAA {
+		return false
+	}
+	return slices.ContainsFunc(ep.Targets, func(target string) bool { return net.ParseIP(target) == nil })
+}
+
+// normalizeTarget returns the target lowercase without trailing dot and quotes.
+func normalizeTarget(target string) string {
+	return strings.Trim(strings.TrimSuffix(strings.ToLower(target), "."), `"`)
+}
+
+// lookupDNS queries the resolver for the records of the type of the name, following no CNAME.
+func lookupDNS(ctx context.Context, resolver, name string, qtype uint16) ([]string, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+	response, _, err := new(dns.Client).ExchangeContext(ctx, msg, resolver)
+	if err != nil {
+		return nil, err
+	}
+	if response.Rcode != dns.RcodeSuccess && response.Rcode != dns.RcodeNameError {
+		return nil, fmt.Errorf("query failed: %s", dns.RcodeToString[response.Rcode])
+	}
+	var values []string
+	for _, rr := range response.Answer {
+		if rr.Header().Rrtype != qtype {
+			continue
+		}
+		switch record := rr.(type) {
+		case *dns.A:
+			values = append(values, record.A.String())
+		case *dns.AAAA:
+			values = append(values, record.AAAA.String())
+		case *dns.CNAME:
+			values = append(values, record.Target)
+		case *dns.TXT:
+			values = append(values, strings.Join(record.Txt, ""))
+		}
+	}
+	return values, nil
+}
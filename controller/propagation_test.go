@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// fakeResolvers answers the values of the names by resolver once the resolver has been queried the given number of times.
+type fakeResolvers struct {
+	mu      sync.Mutex
+	answers map[string]map[string][]string
+	delay   map[string]int
+	queries map[string]int
+}
+
+func (f *fakeResolvers) lookup(_ context.Context, resolver, name string, _ uint16) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queries[resolver]++
+	if f.queries[resolver] <= f.delay[resolver] {
+		return nil, nil
+	}
+	return f.answers[resolver][name], nil
+}
+
+func TestPropagationChecker(t *testing.T) {
+	propagationLatency.Reset()
+	propagationTimeoutsTotal.Reset()
+	resolvers := &fakeResolvers{
+		answers: map[string]map[string][]string{
+			"1.1.1.1:53": {
+				"a.example.com":     {"1.2.3.4"},
+				"cname.example.com": {"Target.Example.org."},
+				"txt.example.com":   {"heritage=external-dns"},
+				"set.example.com":   {"5.6.7.8"},
+			},
+			"10.0.0.53:5353": {
+				"a.example.com": {"1.2.3.4"},
+			},
+		},
+		delay:   map[string]int{"1.1.1.1:53": 4},
+		queries: map[string]int{},
+	}
+	checker := NewPropagationChecker([]string{"1.1.1.1", "10.0.0.53:5353"}, time.Millisecond, 100*time.Millisecond)
+	checker.lookup = resolvers.lookup
+	assert.Equal(t, []string{"1.1.1.1:53", "10.0.0.53:5353"}, checker.resolvers)
+
+	set := endpoint.NewEndpoint("set.example.com", endpoint.RecordTypeA, "1.1.1.1", "5.6.7.8")
+	set.SetIdentifier = "eu"
+	checker.Check(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+			endpoint.NewEndpoint("cname.example.com", endpoint.RecordTypeCNAME, "target.example.org"),
+			endpoint.NewEndpoint("alias.example.com", endpoint.RecordTypeA, "lb.example.org"),
+			endpoint.NewEndpoint("ns.example.com", endpoint.RecordTypeNS, "ns1.example.org"),
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("txt.example.com", endpoint.RecordTypeTXT, `"heritage=external-dns"`),
+			set,
+		},
+		Delete: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("deleted.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+		},
+	})
+	checker.Wait()
+	formatted, err := testutil.CollectAndFormat(propagationLatency, expfmt.TypeTextPlain, "external_dns_controller_propagation_latency_seconds")
+	require.NoError(t, err)
+	latencies := string(formatted)
+
+	// every record is answered by the first resolver after a few queries
+	assert.Contains(t, latencies, `external_dns_controller_propagation_latency_seconds_count{resolver="1.1.1.1:53"} 4`)
+	assert.Equal(t, 0.0, testutil.ToFloat64(propagationTimeoutsTotal.WithLabelValues("1.1.1.1:53")))
+	// only the A record is answered by the second resolver
+	assert.Contains(t, latencies, `external_dns_controller_propagation_latency_seconds_count{resolver="10.0.0.53:5353"} 1`)
+	assert.Equal(t, 3.0, testutil.ToFloat64(propagationTimeoutsTotal.WithLabelValues("10.0.0.53:5353")))
+}
+
+func TestPropagationCheckerNoChecked(t *testing.T) {
+	checker := NewPropagationChecker([]string{"1.1.1.1"}, time.Millisecond, time.Second)
+	checker.lookup = func(context.Context, string, string, uint16) ([]string, error) {
+		t.Fatal("no record is checked")
+		return nil, nil
+	}
+	checker.Check(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("alias.example.com", endpoint.RecordTypeA, "lb.example.org")},
+	})
+	checker.Wait()
+}
+
+func TestLookupDNS(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &dns.Server{PacketConn: conn, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		msg := new(dns.Msg)
+		msg.SetReply(req)
+		if req.Question[0].Name == "www.example.com." {
+			cname, _ := dns.NewRR("www.example.com. 300 IN CNAME app.example.com.")
+			a, _ := dns.NewRR("app.example.com. 300 IN A 1.2.3.4")
+			msg.Answer = append(msg.Answer, cname, a)
+		} else {
+			msg.Rcode = dns.RcodeNameError
+		}
+		_ = w.WriteMsg(msg)
+	})}
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	values, err := lookupDNS(context.Background(), conn.LocalAddr().String(), "www.example.com", dns.TypeCNAME)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app.example.com."}, values)
+
+	values, err = lookupDNS(context.Background(), conn.LocalAddr().String(), "www.example.com", dns.TypeA)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.2.3.4"}, values)
+
+	values, err = lookupDNS(context.Background(), conn.LocalAddr().String(), "missing.example.com", dns.TypeA)
+	require.NoError(t, err)
+	assert.Empty(t, values)
+}
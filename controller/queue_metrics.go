@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var (
+	queueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "workqueue",
+			Name:      "depth",
+			Help:      "Number of synchronizations waiting in the work queue.",
+		},
+		[]string{"name"},
+	)
+	queueAddsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "workqueue",
+			Name:      "adds_total",
+			Help:      "Number of synchronizations added to the work queue.",
+		},
+		[]string{"name"},
+	)
+	queueLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "external_dns",
+			Subsystem: "workqueue",
+			Name:      "queue_duration_seconds",
+			Help:      "Duration in seconds a synchronization waits in the work queue before it starts.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10),
+		},
+		[]string{"name"},
+	)
+	queueWorkDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "external_dns",
+			Subsystem: "workqueue",
+			Name:      "work_duration_seconds",
+			Help:      "Duration in seconds of the synchronizations of the work queue.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10),
+		},
+		[]string{"name"},
+	)
+	queueUnfinishedWork = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "workqueue",
+			Name:      "unfinished_work_seconds",
+			Help:      "Duration in seconds of the synchronizations in progress.",
+		},
+		[]string{"name"},
+	)
+	queueLongestRunning = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "workqueue",
+			Name:      "longest_running_processor_seconds",
+			Help:      "Duration in seconds of the longest synchronization in progress.",
+		},
+		[]string{"name"},
+	)
+	queueRetriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "workqueue",
+			Name:      "retries_total",
+			Help:      "Number of synchronizations added back to the work queue with a backoff.",
+		},
+		[]string{"name"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(queueDepth)
+	prometheus.MustRegister(queueAddsTotal)
+	prometheus.MustRegister(queueLatency)
+	prometheus.MustRegister(queueWorkDuration)
+	prometheus.MustRegister(queueUnfinishedWork)
+	prometheus.MustRegister(queueLongestRunning)
+	prometheus.MustRegister(queueRetriesTotal)
+}
+
+// queueMetricsProvider exposes the metrics of the work queue of the controller, see workqueue.MetricsProvider.
+type queueMetricsProvider struct{}
+
+func (queueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return queueDepth.WithLabelValues(name)
+}
+
+func (queueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return queueAddsTotal.WithLabelValues(name)
+}
+
+func (queueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return queueLatency.WithLabelValues(name)
+}
+
+func (queueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return queueWorkDuration.WithLabelValues(name)
+}
+
+func (queueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return queueUnfinishedWork.WithLabelValues(name)
+}
+
+func (queueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return queueLongestRunning.WithLabelValues(name)
+}
+
+func (queueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return queueRetriesTotal.WithLabelValues(name)
+}
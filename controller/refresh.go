@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"slices"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/source"
+)
+
+// takeRefreshIntervals removes the refresh-interval annotation from the endpoints, so that it is not planned as a
+// property of their records, and returns the shortest refresh interval and the hostnames of the annotated endpoints.
+func takeRefreshIntervals(endpoints []*endpoint.Endpoint) (time.Duration, []string) {
+	var shortest time.Duration
+	var hostnames []string
+	for _, ep := range endpoints {
+		value, ok := ep.GetProviderSpecificProperty(source.RefreshIntervalKey)
+		if !ok {
+			continue
+		}
+		ep.DeleteProviderSpecificProperty(source.RefreshIntervalKey)
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			log.Warnf("Ignoring the invalid refresh interval %q of %s", value, ep.DNSName)
+			continue
+		}
+		if shortest == 0 || interval < shortest {
+			shortest = interval
+		}
+		if !slices.Contains(hostnames, ep.DNSName) {
+			hostnames = append(hostnames, ep.DNSName)
+		}
+	}
+	return shortest, hostnames
+}
+
+// scheduleRefresh schedules the next synchronization after the shortest refresh interval of the endpoints of the
+// current synchronization, if it is due before the next regular one, handling the zones of their hostnames first.
+// When the zones are synchronized one by one and the controller is running, only the zones of the hostnames are
+// synchronized, see ScheduleRunOnceFor. It is never due before the MinEventSyncInterval.
+func (c *Controller) scheduleRefresh(endpoints []*endpoint.Endpoint) {
+	interval, hostnames := takeRefreshIntervals(endpoints)
+	if interval == 0 {
+		return
+	}
+	c.runAtMutex.Lock()
+	defer c.runAtMutex.Unlock()
+	delay := max(interval, c.MinEventSyncInterval)
+	queued := true
+	for _, hostname := range hostnames {
+		if !c.requestFor(hostname, delay) {
+			queued = false
+			if !slices.Contains(c.requestedHostnames, hostname) {
+				c.requestedHostnames = append(c.requestedHostnames, hostname)
+			}
+		}
+	}
+	if queued {
+		// the zones of the hostnames are synchronized on their own
+		return
+	}
+	if at := c.lastRunAt.Add(delay); at.Before(c.nextRunAt) {
+		c.nextRunAt = at
+		c.wakeUp()
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/source"
+)
+
+func TestTakeRefreshIntervals(t *testing.T) {
+	endpoints := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(source.RefreshIntervalKey, "2m"),
+		endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeAAAA, "::1").WithProviderSpecific(source.RefreshIntervalKey, "30s"),
+		endpoint.NewEndpoint("b.example.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(source.RefreshIntervalKey, "1m"),
+		endpoint.NewEndpoint("c.example.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(source.RefreshIntervalKey, "soon"),
+		endpoint.NewEndpoint("d.example.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(source.RefreshIntervalKey, "-1m"),
+		endpoint.NewEndpoint("e.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+	}
+
+	interval, hostnames := takeRefreshIntervals(endpoints)
+	assert.Equal(t, 30*time.Second, interval)
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, hostnames)
+	for _, ep := range endpoints {
+		assert.Empty(t, ep.ProviderSpecific, ep.DNSName)
+	}
+}
+
+func TestScheduleRefresh(t *testing.T) {
+	now := time.Now()
+	ctrl := &Controller{Interval: 10 * time.Minute, MinEventSyncInterval: 15 * time.Second}
+	assert.True(t, ctrl.ShouldRunOnce(now))
+	ctrl.lastRunAt = now
+
+	// no refresh interval keeps the regular interval
+	ctrl.scheduleRefresh([]*endpoint.Endpoint{endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeA, "1.2.3.4")})
+	assert.Equal(t, now.Add(10*time.Minute), ctrl.nextRunAt)
+	assert.Empty(t, ctrl.requestedHostnames)
+
+	ctrl.scheduleRefresh([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(source.RefreshIntervalKey, "1m"),
+	})
+	assert.Equal(t, now.Add(time.Minute), ctrl.nextRunAt)
+	assert.Equal(t, []string{"a.example.com"}, ctrl.requestedHostnames)
+	assert.False(t, ctrl.ShouldRunOnce(now.Add(59*time.Second)))
+	assert.True(t, ctrl.ShouldRunOnce(now.Add(time.Minute)))
+
+	// the refresh interval is not shorter than the minimum event sync interval
+	ctrl.lastRunAt = now.Add(time.Minute)
+	ctrl.scheduleRefresh([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(source.RefreshIntervalKey, "1s"),
+		endpoint.NewEndpoint("b.example.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(source.RefreshIntervalKey, "5m"),
+	})
+	assert.Equal(t, now.Add(time.Minute+15*time.Second), ctrl.nextRunAt)
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, ctrl.requestedHostnames)
+}
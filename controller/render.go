@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	colorReset  = "\033[0m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorRed    = "\033[31m"
+)
+
+// ChangesRenderer renders the planned changes for review, grouped by zone and DNS name,
+// with a line per created (+), updated (~) and deleted (-) record.
+type ChangesRenderer struct {
+	Writer io.Writer
+	// Color enables the coloring of the changes with ANSI escape sequences
+	Color bool
+	// ZoneLister groups the changes by zone. If nil, the changes are grouped by DNS name only.
+	ZoneLister provider.ZoneLister
+}
+
+type renderedChange struct {
+	action string
+	color  string
+	text   string
+}
+
+// Render writes the changes to the writer.
+func (r *ChangesRenderer) Render(ctx context.Context, changes *plan.Changes) error {
+	zones := provider.ZoneIDName{}
+	if r.ZoneLister != nil {
+		names, err := r.ZoneLister.ZoneNames(ctx)
+		if err != nil {
+			log.Warnf("Failed to list zones, not grouping the changes by zone: %v", err)
+		}
+		for _, name := range names {
+			zones.Add(name, name)
+		}
+	}
+
+	// zone -> DNS name -> changes
+	tree := map[string]map[string][]renderedChange{}
+	add := func(ep *endpoint.Endpoint, change renderedChange) {
+		dnsName := strings.TrimSuffix(ep.DNSName, ".")
+		_, zone := zones.FindZone(strings.ToLower(dnsName))
+		if tree[zone] == nil {
+			tree[zone] = map[string][]renderedChange{}
+		}
+		tree[zone][dnsName] = append(tree[zone][dnsName], change)
+	}
+	for _, ep := range changes.Create {
+		add(ep, renderedChange{action: "+", color: colorGreen, text: describeRecord(ep)})
+	}
+	for i, ep := range changes.UpdateNew {
+		var old *endpoint.Endpoint
+		if i < len(changes.UpdateOld) {
+			old = changes.UpdateOld[i]
+		}
+		add(ep, renderedChange{action: "~", color: colorYellow, text: describeUpdate(old, ep)})
+	}
+	for _, ep := range changes.Delete {
+		add(ep, renderedChange{action: "-", color: colorRed, text: describeRecord(ep)})
+	}
+
+	var b strings.Builder
+	for _, zone := range sortedKeys(tree) {
+		indent := ""
+		if zone != "" {
+			fmt.Fprintf(&b, "zone %s\n", zone)
+			indent = "  "
+		}
+		for _, dnsName := range sortedKeys(tree[zone]) {
+			fmt.Fprintf(&b, "%s%s\n", indent, dnsName)
+			for _, change := range tree[zone][dnsName] {
+				line := fmt.Sprintf("%s  %s %s", indent, change.action, change.text)
+				if r.Color {
+					line = change.color + line + colorReset
+				}
+				b.WriteString(line + "\n")
+			}
+		}
+	}
+	_, err := io.WriteString(r.Writer, b.String())
+	return err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// describeRecord returns the record type, set identifier, TTL, targets and provider specific properties of a record.
+func describeRecord(ep *endpoint.Endpoint) string {
+	var b strings.Builder
+	b.WriteString(ep.RecordType)
+	if ep.SetIdentifier != "" {
+		fmt.Fprintf(&b, " [%s]", ep.SetIdentifier)
+	}
+	if ep.RecordTTL.IsConfigured() {
+		fmt.Fprintf(&b, " %d", ep.RecordTTL)
+	}
+	fmt.Fprintf(&b, " %s", strings.Join(ep.Targets, " "))
+	for _, property := range ep.ProviderSpecific {
+		fmt.Fprintf(&b, " %s=%s", property.Name, property.Value)
+	}
+	return b.String()
+}
+
+// describeUpdate returns the record type and set identifier of a record, followed by the properties changed by the update.
+func describeUpdate(old, ep *endpoint.Endpoint) string {
+	if old == nil {
+		return describeRecord(ep)
+	}
+	var b strings.Builder
+	b.WriteString(ep.RecordType)
+	if ep.SetIdentifier != "" {
+		fmt.Fprintf(&b, " [%s]", ep.SetIdentifier)
+	}
+	if old.RecordTTL != ep.RecordTTL {
+		fmt.Fprintf(&b, " ttl: %d -> %d", old.RecordTTL, ep.RecordTTL)
+	}
+	if !old.Targets.Same(ep.Targets) {
+		fmt.Fprintf(&b, " targets: %s -> %s", strings.Join(old.Targets, " "), strings.Join(ep.Targets, " "))
+	}
+	for _, property := range ep.ProviderSpecific {
+		if value, ok := old.GetProviderSpecificProperty(property.Name); !ok || value != property.Value {
+			fmt.Fprintf(&b, " %s: %s -> %s", property.Name, value, property.Value)
+		}
+	}
+	for _, property := range old.ProviderSpecific {
+		if _, ok := ep.GetProviderSpecificProperty(property.Name); !ok {
+			fmt.Fprintf(&b, " %s: %s -> ", property.Name, property.Value)
+		}
+	}
+	for _, key := range sortedKeys(ep.Labels) {
+		if old.Labels[key] != ep.Labels[key] {
+			fmt.Fprintf(&b, " %s: %s -> %s", key, old.Labels[key], ep.Labels[key])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func TestChangesRenderer(t *testing.T) {
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("new.example.com", endpoint.RecordTypeA, 300, "1.2.3.4"),
+		},
+		UpdateOld: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("www.example.org", endpoint.RecordTypeCNAME, "old.example.org").
+				WithSetIdentifier("eu").
+				WithProviderSpecific("aws/weight", "10"),
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("www.example.org", endpoint.RecordTypeCNAME, 60, "new.example.org").
+				WithSetIdentifier("eu").
+				WithProviderSpecific("aws/weight", "20"),
+		},
+		Delete: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("new.example.com.", endpoint.RecordTypeTXT, "\"heritage=external-dns\""),
+			endpoint.NewEndpoint("other.example.net", endpoint.RecordTypeA, "5.6.7.8"),
+		},
+	}
+
+	var out strings.Builder
+	r := &ChangesRenderer{Writer: &out, ZoneLister: &countingZoneLister{zones: []string{"example.com", "example.org"}}}
+	require.NoError(t, r.Render(context.Background(), changes))
+	assert.Equal(t, `other.example.net
+  - A 5.6.7.8
+zone example.com
+  new.example.com
+    + A 300 1.2.3.4
+    - TXT "heritage=external-dns"
+zone example.org
+  www.example.org
+    ~ CNAME [eu] ttl: 0 -> 60 targets: old.example.org -> new.example.org aws/weight: 10 -> 20
+`, out.String())
+
+	out.Reset()
+	r = &ChangesRenderer{Writer: &out, Color: true}
+	require.NoError(t, r.Render(context.Background(), &plan.Changes{Create: changes.Create}))
+	assert.Equal(t, "new.example.com\n"+colorGreen+"  + A 300 1.2.3.4"+colorReset+"\n", out.String())
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// spillBuckets is the number of files per kind of endpoints. Partitions are made of consecutive buckets.
+const spillBuckets = 256
+
+// endpointSpill stores endpoints in temporary files, bucketed by DNS name.
+// All records of a DNS name end up in the same bucket, so that the plan
+// can be calculated one partition of buckets at a time with a bounded number of endpoints in memory.
+type endpointSpill struct {
+	dir    string
+	counts [spillBuckets]int
+}
+
+func newEndpointSpill() (*endpointSpill, error) {
+	dir, err := os.MkdirTemp("", "external-dns-spill-")
+	if err != nil {
+		return nil, fmt.Errorf("creating spill directory: %w", err)
+	}
+	return &endpointSpill{dir: dir}, nil
+}
+
+func (s *endpointSpill) path(kind string, bucket int) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%s-%d.json", kind, bucket))
+}
+
+func (s *endpointSpill) bucket(ep *endpoint.Endpoint) int {
+	h := fnv.New32a()
+	h.Write([]byte(strings.TrimSuffix(strings.TrimSpace(strings.ToLower(ep.DNSName)), ".")))
+	return int(h.Sum32() % spillBuckets)
+}
+
+// size returns the number of spilled endpoints.
+func (s *endpointSpill) size() int {
+	total := 0
+	for _, count := range s.counts {
+		total += count
+	}
+	return total
+}
+
+// partitions groups consecutive buckets into partitions of at most limit endpoints of all kinds.
+// A bucket exceeding the limit on its own is a partition of its own.
+func (s *endpointSpill) partitions(limit int) [][]int {
+	var partitions [][]int
+	var buckets []int
+	size := 0
+	for i, count := range s.counts {
+		if count == 0 {
+			continue
+		}
+		if len(buckets) > 0 && size+count > limit {
+			partitions = append(partitions, buckets)
+			buckets, size = nil, 0
+		}
+		buckets = append(buckets, i)
+		size += count
+	}
+	if len(buckets) > 0 {
+		partitions = append(partitions, buckets)
+	}
+	return partitions
+}
+
+// write appends the endpoints as JSON lines to the files of their buckets.
+func (s *endpointSpill) write(kind string, endpoints []*endpoint.Endpoint) (err error) {
+	var files [spillBuckets]*os.File
+	var writers [spillBuckets]*bufio.Writer
+	defer func() {
+		for i, f := range files {
+			if f == nil {
+				continue
+			}
+			err = errors.Join(err, writers[i].Flush(), f.Close())
+		}
+	}()
+
+	for _, ep := range endpoints {
+		i := s.bucket(ep)
+		if files[i] == nil {
+			if files[i], err = os.OpenFile(s.path(kind, i), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600); err != nil {
+				return fmt.Errorf("opening spill file: %w", err)
+			}
+			writers[i] = bufio.NewWriter(files[i])
+		}
+		data, err := json.Marshal(ep)
+		if err != nil {
+			return fmt.Errorf("encoding endpoint %s: %w", ep.DNSName, err)
+		}
+		if _, err := writers[i].Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("writing spill file: %w", err)
+		}
+		s.counts[i]++
+	}
+	return nil
+}
+
+// read returns the endpoints of the buckets.
+func (s *endpointSpill) read(kind string, buckets []int) ([]*endpoint.Endpoint, error) {
+	var endpoints []*endpoint.Endpoint
+	for _, bucket := range buckets {
+		eps, err := s.readBucket(kind, bucket)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, eps...)
+	}
+	return endpoints, nil
+}
+
+func (s *endpointSpill) readBucket(kind string, bucket int) ([]*endpoint.Endpoint, error) {
+	f, err := os.Open(s.path(kind, bucket))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening spill file: %w", err)
+	}
+	defer f.Close()
+
+	var endpoints []*endpoint.Endpoint
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		ep := &endpoint.Endpoint{}
+		if err := json.Unmarshal(scanner.Bytes(), ep); err != nil {
+			return nil, fmt.Errorf("decoding spill file: %w", err)
+		}
+		if ep.Labels == nil {
+			ep.Labels = endpoint.NewLabels()
+		}
+		endpoints = append(endpoints, ep)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading spill file: %w", err)
+	}
+	return endpoints, nil
+}
+
+// Close removes the spill files.
+func (s *endpointSpill) Close() error {
+	return os.RemoveAll(s.dir)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+func TestEndpointSpill(t *testing.T) {
+	spill, err := newEndpointSpill()
+	require.NoError(t, err)
+
+	endpoints := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("FOO.example.com.", endpoint.RecordTypeAAAA, "2001:db8::1"),
+		endpoint.NewEndpointWithTTL("bar.example.com", endpoint.RecordTypeCNAME, 300, "foo.example.com").
+			WithSetIdentifier("eu").
+			WithProviderSpecific("aws/weight", "10"),
+	}
+	endpoints[2].Labels[endpoint.OwnerLabelKey] = "owner"
+	require.NoError(t, spill.write("current", endpoints))
+	assert.Equal(t, 3, spill.size())
+
+	// the records of a DNS name share a bucket regardless of case and trailing dot
+	assert.Equal(t, spill.bucket(endpoints[0]), spill.bucket(endpoints[1]))
+
+	var read []*endpoint.Endpoint
+	for _, buckets := range spill.partitions(1) {
+		eps, err := spill.read("current", buckets)
+		require.NoError(t, err)
+		read = append(read, eps...)
+	}
+	assert.True(t, testutils.SameEndpoints(endpoints, read), "expected %v, got %v", endpoints, read)
+
+	eps, err := spill.read("desired", []int{spill.bucket(endpoints[0])})
+	require.NoError(t, err)
+	assert.Empty(t, eps)
+
+	require.NoError(t, spill.Close())
+	_, err = os.Stat(spill.dir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestEndpointSpillPartitions(t *testing.T) {
+	spill := &endpointSpill{}
+	spill.counts[1] = 2
+	spill.counts[2] = 2
+	spill.counts[5] = 5
+	spill.counts[7] = 1
+	spill.counts[9] = 3
+
+	assert.Equal(t, [][]int{{1, 2}, {5}, {7, 9}}, spill.partitions(4))
+	assert.Equal(t, [][]int{{1, 2, 5, 7, 9}}, spill.partitions(13))
+	assert.Empty(t, (&endpointSpill{}).partitions(4))
+}
+
+func TestRunOnceSpillsToDisk(t *testing.T) {
+	var current, desired []*endpoint.Endpoint
+	for i := 0; i < 50; i++ {
+		current = append(current, endpoint.NewEndpoint(fmt.Sprintf("update-%d.used.tld", i), endpoint.RecordTypeA, "1.1.1.1"))
+		current = append(current, endpoint.NewEndpoint(fmt.Sprintf("delete-%d.used.tld", i), endpoint.RecordTypeA, "2.2.2.2"))
+		desired = append(desired, endpoint.NewEndpoint(fmt.Sprintf("update-%d.used.tld", i), endpoint.RecordTypeA, "3.3.3.3"))
+		desired = append(desired, endpoint.NewEndpoint(fmt.Sprintf("create-%d.used.tld", i), endpoint.RecordTypeA, "4.4.4.4"))
+	}
+
+	for _, tt := range []struct {
+		name      string
+		statusAPI bool
+		exporters bool
+	}{
+		{name: "exporters", exporters: true},
+		{name: "status API", statusAPI: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			calculate := func(maxMemoryEndpoints int) (*plan.Changes, []*endpoint.Endpoint, Status) {
+				source := new(testutils.MockSource)
+				source.On("Endpoints").Return(desired, nil)
+				provider := &filteredMockProvider{RecordsStore: current}
+				r, err := registry.NewNoopRegistry(provider)
+				require.NoError(t, err)
+				exporter := &fakeExporter{}
+
+				ctrl := &Controller{
+					Source:             source,
+					Registry:           r,
+					Policy:             &plan.SyncPolicy{},
+					DomainFilter:       endpoint.NewDomainFilter([]string{"used.tld"}),
+					ManagedRecordTypes: []string{endpoint.RecordTypeA},
+					MaxMemoryEndpoints: maxMemoryEndpoints,
+					StatusAPI:          tt.statusAPI,
+				}
+				if tt.exporters {
+					ctrl.Exporters = []RecordsExporter{exporter}
+				}
+				require.NoError(t, ctrl.RunOnce(context.Background()))
+
+				// the partitions are applied one after another
+				changes := &plan.Changes{}
+				for _, applied := range provider.ApplyChangesCalls {
+					changes.Create = append(changes.Create, applied.Create...)
+					changes.UpdateOld = append(changes.UpdateOld, applied.UpdateOld...)
+					changes.UpdateNew = append(changes.UpdateNew, applied.UpdateNew...)
+					changes.Delete = append(changes.Delete, applied.Delete...)
+				}
+				status := ctrl.status.get()
+				if !tt.exporters {
+					return changes, status.Records, status
+				}
+				require.Len(t, exporter.exported, 1)
+				return changes, exporter.exported[0], status
+			}
+
+			expected, expectedManaged, _ := calculate(0)
+			// the records alone exceed the threshold and are spilled before the sources are listed
+			changes, managed, status := calculate(30)
+
+			assert.Len(t, changes.Create, 50)
+			assert.True(t, testutils.SameEndpoints(expected.Create, changes.Create))
+			assert.True(t, testutils.SameEndpoints(expected.UpdateOld, changes.UpdateOld))
+			assert.True(t, testutils.SameEndpoints(expected.UpdateNew, changes.UpdateNew))
+			assert.True(t, testutils.SameEndpoints(expected.Delete, changes.Delete))
+			assert.NotEmpty(t, managed)
+			assert.True(t, testutils.SameEndpoints(expectedManaged, managed))
+			if tt.statusAPI {
+				require.NotNil(t, status.Changes)
+				assert.True(t, testutils.SameEndpoints(expected.Create, status.Changes.Create))
+				assert.True(t, testutils.SameEndpoints(expected.Delete, status.Changes.Delete))
+			} else {
+				assert.Nil(t, status.Changes)
+			}
+
+			// the records and endpoints exceed the threshold together
+			changes, managed, _ = calculate(150)
+			assert.True(t, testutils.SameEndpoints(expected.Create, changes.Create))
+			assert.True(t, testutils.SameEndpoints(expected.Delete, changes.Delete))
+			assert.True(t, testutils.SameEndpoints(expectedManaged, managed))
+		})
+	}
+}
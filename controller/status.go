@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/idna"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// Status is the outcome of the last synchronizations, served by the status API.
+type Status struct {
+	// LastAttempt is the time of the last synchronization
+	LastAttempt time.Time `json:"lastAttempt,omitempty"`
+	// LastSuccess is the time of the last successful synchronization
+	LastSuccess time.Time `json:"lastSuccess,omitempty"`
+	// Error is the error of the last synchronization, if it failed
+	Error string `json:"error,omitempty"`
+	// Records are the records managed after the last successful synchronization
+	Records []*endpoint.Endpoint `json:"records"`
+	// Changes are the changes applied by the last successful synchronization
+	Changes *plan.Changes `json:"changes"`
+}
+
+// statusStore holds the status of the controller, which is written by the synchronizations and read by the status API.
+type statusStore struct {
+	mu     sync.RWMutex
+	status Status
+}
+
+func (s *statusStore) succeeded(records []*endpoint.Endpoint, changes *plan.Changes) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.status = Status{LastAttempt: now, LastSuccess: now, Records: records, Changes: changes}
+}
+
+func (s *statusStore) failed(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.LastAttempt = time.Now()
+	s.status.Error = err.Error()
+}
+
+func (s *statusStore) get() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status
+}
+
+// StatusHandler returns the handler of the status API:
+//
+//   - GET /api/v1/status returns the status of the last synchronization without the records
+//   - GET /api/v1/records returns the managed records, filtered by the hostname and resource query parameters
+//   - GET /api/v1/plan returns the changes of the last successful synchronization
+//   - GET /api/v1/explain traces the hostname query parameter through a synchronization without applying the changes
+//   - POST /api/v1/reconcile schedules a synchronization, handling the zone of the hostname query parameter first,
+//     with the bearer token of ReconcileTokenFile, and is forbidden without it
+//
+// The status is only kept if StatusAPI is enabled.
+func (c *Controller) StatusHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/status", func(w http.ResponseWriter, _ *http.Request) {
+		status := c.status.get()
+		status.Records = nil
+		status.Changes = nil
+		writeJSON(w, status)
+	})
+	mux.HandleFunc("GET /api/v1/records", func(w http.ResponseWriter, req *http.Request) {
+		hostname := normalizeHostname(req.URL.Query().Get("hostname"))
+		resource := req.URL.Query().Get("resource")
+		records := []*endpoint.Endpoint{}
+		for _, ep := range withUnicodeHostnames(c.status.get().Records) {
+			if hostname != "" && normalizeHostname(ep.DNSName) != hostname {
+				continue
+			}
+			if resource != "" && ep.Labels[endpoint.ResourceLabelKey] != resource {
+				continue
+			}
+			records = append(records, ep)
+		}
+		writeJSON(w, records)
+	})
+	mux.HandleFunc("GET /api/v1/plan", func(w http.ResponseWriter, _ *http.Request) {
+		changes := c.status.get().Changes
+		if changes == nil {
+			changes = &plan.Changes{}
+		}
+		writeJSON(w, changes)
+	})
+	mux.HandleFunc("GET /api/v1/explain", c.explainHandler)
+	mux.HandleFunc("POST /api/v1/reconcile", c.reconcileHandler)
+	return mux
+}
+
+// reconcileHandler schedules a synchronization once the bearer token of the request is checked.
+// Without ReconcileTokenFile the request is forbidden, since the status API is served on the metrics address.
+func (c *Controller) reconcileHandler(w http.ResponseWriter, req *http.Request) {
+	if c.ReconcileTokenFile == "" {
+		http.Error(w, "the reconcile trigger requires --status-api-reconcile-token-file", http.StatusForbidden)
+		return
+	}
+	token, err := os.ReadFile(c.ReconcileTokenFile)
+	if err != nil {
+		log.Errorf("Failed to read the reconcile token: %v", err)
+		http.Error(w, "the reconcile token cannot be read", http.StatusInternalServerError)
+		return
+	}
+	expected := strings.TrimSpace(string(token))
+	given, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok || expected == "" || subtle.ConstantTimeCompare([]byte(given), []byte(expected)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="external-dns"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if hostname := normalizeHostname(req.URL.Query().Get("hostname")); hostname != "" {
+		log.Infof("Reconcile of %s requested through the status API", hostname)
+		c.ScheduleRunOnceFor(time.Now(), hostname)
+	} else {
+		log.Info("Reconcile requested through the status API")
+		c.ScheduleRunOnce(time.Now())
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// normalizeHostname returns the hostname in lower case without trailing dot, and in punycode if it is internationalized.
+func normalizeHostname(hostname string) string {
+	hostname = strings.TrimSuffix(strings.ToLower(hostname), ".")
+	if ascii, err := idna.Lookup.ToASCII(hostname); err == nil {
+		return ascii
+	}
+	return hostname
+}
+
+// withUnicodeHostnames returns the records with the unicode-hostname label set on the records in punycode,
+// which lack it when read from the registry. The labeled records are copies.
+func withUnicodeHostnames(records []*endpoint.Endpoint) []*endpoint.Endpoint {
+	result := make([]*endpoint.Endpoint, 0, len(records))
+	for _, ep := range records {
+		if ep.Labels[endpoint.UnicodeHostnameLabelKey] == "" && strings.Contains(ep.DNSName, "xn--") {
+			if unicode, err := idna.Lookup.ToUnicode(ep.DNSName); err == nil && unicode != ep.DNSName {
+				ep = ep.DeepCopy()
+				if ep.Labels == nil {
+					ep.Labels = endpoint.NewLabels()
+				}
+				ep.Labels[endpoint.UnicodeHostnameLabelKey] = unicode
+			}
+		}
+		result = append(result, ep)
+	}
+	return result
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Errorf("Failed to write status API response: %v", err)
+	}
+}
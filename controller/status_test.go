@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+func TestStatusHandler(t *testing.T) {
+	kept := endpoint.NewEndpoint("keep.used.tld", endpoint.RecordTypeA, "1.1.1.1")
+	kept.Labels[endpoint.OwnerLabelKey] = ""
+	kept.Labels[endpoint.ResourceLabelKey] = "ingress/default/keep"
+	created := endpoint.NewEndpoint("create.used.tld", endpoint.RecordTypeA, "2.2.2.2")
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("keep.used.tld", endpoint.RecordTypeA, "1.1.1.1"),
+		created,
+	}, nil)
+	r, err := registry.NewNoopRegistry(&filteredMockProvider{RecordsStore: []*endpoint.Endpoint{kept}})
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		DomainFilter:       endpoint.NewDomainFilter([]string{"used.tld"}),
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		StatusAPI:          true,
+	}
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	ctrl.status.failed(errors.New("failed"))
+
+	server := httptest.NewServer(ctrl.StatusHandler())
+	defer server.Close()
+
+	get := func(path string, v any) {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+
+	var status Status
+	get("/api/v1/status", &status)
+	assert.False(t, status.LastSuccess.IsZero())
+	assert.Equal(t, "failed", status.Error)
+	assert.Nil(t, status.Records)
+
+	for path, expected := range map[string][]*endpoint.Endpoint{
+		"/api/v1/records": {kept, created},
+		"/api/v1/records?resource=ingress/default/keep": {kept},
+		"/api/v1/records?hostname=Create.used.tld.":     {created},
+		"/api/v1/records?hostname=missing.used.tld":     {},
+	} {
+		var records []*endpoint.Endpoint
+		get(path, &records)
+		assert.True(t, testutils.SameEndpoints(expected, records), "%s: expected %v, got %v", path, expected, records)
+	}
+
+	var changes plan.Changes
+	get("/api/v1/plan", &changes)
+	assert.True(t, testutils.SameEndpoints([]*endpoint.Endpoint{created}, changes.Create))
+
+	// without a token file the trigger is forbidden, even with a bearer token
+	resp, err := http.Post(server.URL+"/api/v1/reconcile", "", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/api/v1/reconcile", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Empty(t, ctrl.requestedHostnames)
+	assert.True(t, ctrl.nextRunAt.IsZero())
+
+	resp, err = http.Get(server.URL + "/api/v1/reconcile")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestReconcileToken(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("secret\n"), 0o600))
+	ctrl := &Controller{ReconcileTokenFile: tokenFile}
+	server := httptest.NewServer(ctrl.StatusHandler())
+	defer server.Close()
+
+	reconcile := func(path, token string) int {
+		req, err := http.NewRequest(http.MethodPost, server.URL+path, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusUnauthorized, reconcile("/api/v1/reconcile", ""))
+	assert.Equal(t, http.StatusUnauthorized, reconcile("/api/v1/reconcile", "other"))
+	assert.Equal(t, http.StatusAccepted, reconcile("/api/v1/reconcile", "secret"))
+	assert.Empty(t, ctrl.requestedHostnames)
+
+	assert.Equal(t, http.StatusAccepted, reconcile("/api/v1/reconcile?hostname=App.example.com.", "secret"))
+	assert.Equal(t, http.StatusAccepted, reconcile("/api/v1/reconcile?hostname=app.example.com", "secret"))
+	assert.Equal(t, []string{"app.example.com"}, ctrl.requestedHostnames)
+
+	// the rotated token is used without a restart
+	require.NoError(t, os.WriteFile(tokenFile, []byte("rotated"), 0o600))
+	assert.Equal(t, http.StatusUnauthorized, reconcile("/api/v1/reconcile", "secret"))
+	assert.Equal(t, http.StatusAccepted, reconcile("/api/v1/reconcile", "rotated"))
+
+	require.NoError(t, os.Remove(tokenFile))
+	assert.Equal(t, http.StatusInternalServerError, reconcile("/api/v1/reconcile", "rotated"))
+}
+
+func TestNormalizeHostname(t *testing.T) {
+	assert.Equal(t, "app.example.com", normalizeHostname("App.example.com."))
+	assert.Equal(t, "xn--bcher-kva.example.com", normalizeHostname("Bücher.example.com"))
+	assert.Equal(t, "xn--bcher-kva.example.com", normalizeHostname("xn--bcher-kva.example.com"))
+}
+
+func TestWithUnicodeHostnames(t *testing.T) {
+	ascii := endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.1.1.1")
+	punycode := endpoint.NewEndpoint("xn--bcher-kva.example.com", endpoint.RecordTypeA, "2.2.2.2")
+
+	records := withUnicodeHostnames([]*endpoint.Endpoint{ascii, punycode})
+	require.Len(t, records, 2)
+	assert.Same(t, ascii, records[0])
+	assert.Equal(t, "bücher.example.com", records[1].Labels[endpoint.UnicodeHostnameLabelKey])
+	assert.NotContains(t, punycode.Labels, endpoint.UnicodeHostnameLabelKey, "the records must not be modified")
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// ttlRepairDue returns true if the TTLs are repaired separately and TTLRepairInterval has passed since the last repair.
+func (c *Controller) ttlRepairDue() bool {
+	return c.TTLRepairInterval > 0 && time.Since(c.lastTTLRepair) >= c.TTLRepairInterval
+}
+
+// repairTTLs applies the updates only changing the TTL of records, after the other changes.
+// The TTLs don't affect the resolution of the records, so a failure is logged and the TTLs are repaired
+// by the next repair rather than failing the synchronization.
+func (c *Controller) repairTTLs(ctx context.Context, repairs *plan.Changes) {
+	if repairs == nil || !repairs.HasChanges() {
+		return
+	}
+	log.Infof("Repairing the TTL of %d records", len(repairs.UpdateNew))
+	c.render(ctx, repairs)
+	if err := c.Registry.ApplyChanges(ctx, repairs); err != nil {
+		log.Warnf("Failed to repair the TTLs: %v", err)
+		return
+	}
+	ttlRepairsTotal.Add(float64(len(repairs.UpdateNew)))
+}
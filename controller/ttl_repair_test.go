@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+func TestRepairTTLs(t *testing.T) {
+	for _, perZone := range []bool{false, true} {
+		t.Run(map[bool]string{false: "all zones", true: "per zone"}[perZone], func(t *testing.T) {
+			p := inmemory.NewInMemoryProvider(inmemory.InMemoryInitZones([]string{"example.com"}))
+			require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{
+				Create: []*endpoint.Endpoint{
+					endpoint.NewEndpointWithTTL("ttl.example.com", endpoint.RecordTypeA, 300, "1.1.1.1"),
+					endpoint.NewEndpointWithTTL("target.example.com", endpoint.RecordTypeA, 300, "2.2.2.2"),
+				},
+			}))
+			r, err := registry.NewNoopRegistry(p)
+			require.NoError(t, err)
+
+			source := new(testutils.MockSource)
+			source.On("Endpoints").Return([]*endpoint.Endpoint{
+				endpoint.NewEndpointWithTTL("ttl.example.com", endpoint.RecordTypeA, 60, "1.1.1.1"),
+				endpoint.NewEndpointWithTTL("target.example.com", endpoint.RecordTypeA, 60, "3.3.3.3"),
+			}, nil)
+
+			ctrl := &Controller{
+				Source:             source,
+				Registry:           r,
+				Policy:             &plan.CreateOnlyPolicy{},
+				ManagedRecordTypes: []string{endpoint.RecordTypeA},
+				TTLRepairInterval:  time.Hour,
+			}
+			if perZone {
+				ctrl.ZoneLister = p
+			}
+			ttls := func() map[string]endpoint.TTL {
+				records, err := p.Records(context.Background())
+				require.NoError(t, err)
+				ttls := map[string]endpoint.TTL{}
+				for _, record := range records {
+					ttls[record.DNSName] = record.RecordTTL
+				}
+				return ttls
+			}
+
+			// the TTL is repaired despite the policy, the update of the target is not allowed by the policy
+			require.NoError(t, ctrl.RunOnce(context.Background()))
+			assert.Equal(t, map[string]endpoint.TTL{"ttl.example.com": 60, "target.example.com": 300}, ttls())
+
+			// the TTL edited again is repaired after the interval only
+			require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{
+				UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("ttl.example.com", endpoint.RecordTypeA, 60, "1.1.1.1")},
+				UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("ttl.example.com", endpoint.RecordTypeA, 600, "1.1.1.1")},
+			}))
+			require.NoError(t, ctrl.RunOnce(context.Background()))
+			assert.Equal(t, endpoint.TTL(600), ttls()["ttl.example.com"])
+
+			ctrl.lastTTLRepair = time.Now().Add(-time.Hour)
+			require.NoError(t, ctrl.RunOnce(context.Background()))
+			assert.Equal(t, endpoint.TTL(60), ttls()["ttl.example.com"])
+		})
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// UnroutableFilter drops the desired endpoints whose hostname matches none of the provider's zones,
+// which the provider would skip on every synchronization. The zones are listed at most once per TTL,
+// and the hostnames matching no zone are reported once when they change instead of on every synchronization.
+type UnroutableFilter struct {
+	lister   provider.ZoneLister
+	ttl      time.Duration
+	zones    provider.ZoneIDName
+	listedAt time.Time
+	// noZone caches the hostnames matching no zone until the zones are listed again
+	noZone map[string]bool
+	// reported are the hostnames matching no zone of the last synchronization
+	reported []string
+}
+
+// NewUnroutableFilter creates an UnroutableFilter listing the zones of the provider at most once per ttl.
+func NewUnroutableFilter(lister provider.ZoneLister, ttl time.Duration) *UnroutableFilter {
+	return &UnroutableFilter{lister: lister, ttl: ttl}
+}
+
+// refresh lists the zones if they were not listed within the TTL.
+func (f *UnroutableFilter) refresh(ctx context.Context) error {
+	if f.zones != nil && time.Since(f.listedAt) < f.ttl {
+		return nil
+	}
+	zones, err := f.lister.ZoneNames(ctx)
+	if err != nil {
+		return err
+	}
+	f.zones = provider.ZoneIDName{}
+	for _, zone := range zones {
+		f.zones.Add(zone, zone)
+	}
+	f.listedAt = time.Now()
+	f.noZone = map[string]bool{}
+	return nil
+}
+
+// hasNoZone returns true if the hostname, lowercase without trailing dot, matches no zone.
+func (f *UnroutableFilter) hasNoZone(hostname string) bool {
+	noZone, ok := f.noZone[hostname]
+	if !ok {
+		_, zone := f.zones.FindZone(hostname)
+		noZone = zone == ""
+		f.noZone[hostname] = noZone
+	}
+	return noZone
+}
+
+// Filter returns the endpoints whose hostname matches a zone. If the zones cannot be listed, all endpoints are returned.
+func (f *UnroutableFilter) Filter(ctx context.Context, endpoints []*endpoint.Endpoint) []*endpoint.Endpoint {
+	if err := f.refresh(ctx); err != nil {
+		log.Warnf("Failed to list zones, not skipping hostnames matching no zone: %v", err)
+		return endpoints
+	}
+
+	filtered := make([]*endpoint.Endpoint, 0, len(endpoints))
+	unroutable := map[string]bool{}
+	for _, ep := range endpoints {
+		hostname := strings.TrimSuffix(strings.ToLower(ep.DNSName), ".")
+		if f.hasNoZone(hostname) {
+			unroutable[hostname] = true
+			continue
+		}
+		filtered = append(filtered, ep)
+	}
+
+	hostnames := slices.Sorted(maps.Keys(unroutable))
+	unroutableHostnames.Set(float64(len(hostnames)))
+	if !slices.Equal(hostnames, f.reported) {
+		if len(hostnames) > 0 {
+			log.Warnf("Skipping %d hostnames matching no zone of the provider: %s", len(hostnames), strings.Join(hostnames, ", "))
+		} else {
+			log.Info("All hostnames match a zone of the provider")
+		}
+		f.reported = hostnames
+	}
+	return filtered
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+type countingZoneLister struct {
+	zones []string
+	err   error
+	calls int
+}
+
+func (l *countingZoneLister) ZoneNames(context.Context) ([]string, error) {
+	l.calls++
+	return l.zones, l.err
+}
+
+func TestUnroutableFilter(t *testing.T) {
+	lister := &countingZoneLister{zones: []string{"example.com", "example.org"}}
+	filter := NewUnroutableFilter(lister, time.Hour)
+
+	endpoints := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("Bar.Example.Org.", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("foo.example.net", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("notexample.com", endpoint.RecordTypeA, "1.2.3.4"),
+	}
+
+	for i := 0; i < 3; i++ {
+		filtered := filter.Filter(context.Background(), endpoints)
+		assert.Equal(t, endpoints[:2], filtered)
+		assert.Equal(t, 2.0, testutil.ToFloat64(unroutableHostnames))
+	}
+	// the zones are listed once per TTL
+	assert.Equal(t, 1, lister.calls)
+	assert.Equal(t, []string{"foo.example.net", "notexample.com"}, filter.reported)
+
+	// the zones are listed again once the TTL expired
+	lister.zones = append(lister.zones, "example.net")
+	filter.listedAt = time.Now().Add(-time.Hour)
+	assert.Equal(t, []*endpoint.Endpoint{endpoints[0], endpoints[1], endpoints[2]}, filter.Filter(context.Background(), endpoints))
+	assert.Equal(t, 2, lister.calls)
+	assert.Equal(t, 1.0, testutil.ToFloat64(unroutableHostnames))
+}
+
+func TestUnroutableFilterListError(t *testing.T) {
+	lister := &countingZoneLister{err: errors.New("failed")}
+	filter := NewUnroutableFilter(lister, time.Hour)
+
+	endpoints := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("foo.example.net", endpoint.RecordTypeA, "1.2.3.4"),
+	}
+	assert.Equal(t, endpoints, filter.Filter(context.Background(), endpoints))
+}
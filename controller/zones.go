@@ -0,0 +1,254 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// zoneFilter matches the DNS names that belong to a zone rather than to one of its sub zones.
+type zoneFilter struct {
+	zone  string
+	zones provider.ZoneIDName
+}
+
+func (f zoneFilter) Match(domain string) bool {
+	_, zone := f.zones.FindZone(strings.TrimSuffix(strings.ToLower(domain), "."))
+	return zone == f.zone
+}
+
+// runOncePerZone runs a single iteration of the reconciliation loop, listing the records, calculating
+// the plan and applying the changes for one zone after the other. The desired endpoints are collected once.
+// The zones of the requested hostnames are handled first, even if they are unchanged.
+// If scoped, only the zones of the requested hostnames are handled, and the records of the other zones,
+// e.g. for the status API, the exporters and the garbage collection, are left to the next full synchronization.
+func (c *Controller) runOncePerZone(ctx context.Context, requested []string, scoped bool) error {
+	apiCallsAtStart := provider.APICalls()
+	zones, err := c.ZoneLister.ZoneNames(ctx)
+	if err != nil {
+		registryErrorsTotal.Inc()
+		deprecatedRegistryErrors.Inc()
+		return err
+	}
+	zoneNames := provider.ZoneIDName{}
+	for _, zone := range zones {
+		zoneNames.Add(zone, zone)
+	}
+
+	endpoints, err := c.Source.Endpoints(ctx)
+	if err != nil {
+		sourceErrorsTotal.Inc()
+		deprecatedSourceErrors.Inc()
+		return err
+	}
+	sourceEndpointsTotal.Set(float64(len(endpoints)))
+	c.scheduleRefresh(endpoints)
+	srcARecords, srcAAAARecords := countAddressRecords(endpoints)
+	sourceARecords.Set(float64(srcARecords))
+	sourceAAAARecords.Set(float64(srcAAAARecords))
+	endpoints, err = c.Registry.AdjustEndpoints(endpoints)
+	if err != nil {
+		return fmt.Errorf("adjusting endpoints: %w", err)
+	}
+	if c.Unroutable != nil {
+		endpoints = c.Unroutable.Filter(ctx, endpoints)
+	}
+
+	desired := map[string][]*endpoint.Endpoint{}
+	for _, ep := range endpoints {
+		if _, zone := zoneNames.FindZone(strings.TrimSuffix(strings.ToLower(ep.DNSName), ".")); zone != "" {
+			desired[zone] = append(desired[zone], ep)
+		}
+	}
+
+	apexes, delegated := zoneSet(zones), map[string]string{}
+	var total, regARecords, regAAAARecords, vARecords, vAAAARecords, deferred, skipped int
+	var managed []*endpoint.Endpoint
+	applied := &plan.Changes{}
+	hasChanges := false
+	repairDue := c.ttlRepairDue() && !scoped
+	if scoped {
+		zones = c.requestedZones(zoneNames, requested)
+	} else {
+		zones = rotateZones(zones, c.nextZone)
+		c.nextZone = ""
+		zones = c.prioritizeZones(zones, zoneNames, requested)
+	}
+	for i, zone := range zones {
+		// every synchronization handles at least one zone, so that all zones are handled eventually
+		if c.APIBudgetPerCycle > 0 && i > 0 && provider.APICalls()-apiCallsAtStart >= c.APIBudgetPerCycle {
+			c.nextZone = zone
+			deferred = len(zones) - i
+			log.Warnf("Provider API budget of %d requests per cycle exhausted, deferring %d zones starting with %s to the next cycle", c.APIBudgetPerCycle, deferred, zone)
+			break
+		}
+		zoneCtx := context.WithValue(ctx, provider.ZoneScopeContextKey, zone)
+		records, sum, unchanged, err := c.zoneRecords(zoneCtx, zone, desired[zone])
+		if err != nil {
+			registryErrorsTotal.Inc()
+			deprecatedRegistryErrors.Inc()
+			return fmt.Errorf("listing records of zone %s: %w", zone, err)
+		}
+		zoneDesired := desired[zone]
+		if c.Delegations != nil {
+			var zoneDelegated map[string]string
+			zoneDesired, zoneDelegated = c.Delegations.filter(apexes, records, zoneDesired)
+			maps.Copy(delegated, zoneDelegated)
+		}
+		total += len(records)
+		a, aaaa := countAddressRecords(records)
+		regARecords, regAAAARecords = regARecords+a, regAAAARecords+aaaa
+		a, aaaa = countMatchingAddressRecords(desired[zone], records)
+		vARecords, vAAAARecords = vARecords+a, vAAAARecords+aaaa
+		if unchanged {
+			skipped++
+			log.Debugf("Skipping zone %s, neither its desired endpoints nor its records changed", zone)
+			if len(c.Exporters) > 0 || c.StatusAPI {
+				managed = append(managed, managedRecords(records, &plan.Changes{}, c.Registry.OwnerID())...)
+			}
+			continue
+		}
+
+		p := c.newPlan(records, zoneDesired)
+		p.DomainFilter = endpoint.MatchAllDomainFilters{p.DomainFilter, zoneFilter{zone: zone, zones: zoneNames}}
+		calculated := p.Calculate()
+		changes := calculated.Changes
+
+		var applyErr error
+		if changes.HasChanges() {
+			hasChanges = true
+			log.Infof("Applying changes to zone %s", zone)
+			c.render(ctx, changes)
+			applyErr = c.Registry.ApplyChanges(context.WithValue(zoneCtx, provider.RecordsContextKey, records), changes)
+		}
+		c.observeDrift(p, changes, applyErr, zoneFilter{zone: zone, zones: zoneNames})
+		if applyErr != nil {
+			registryErrorsTotal.Inc()
+			deprecatedRegistryErrors.Inc()
+			return fmt.Errorf("applying changes to zone %s: %w", zone, applyErr)
+		}
+		if c.Propagation != nil && changes.HasChanges() {
+			c.Propagation.Check(ctx, changes)
+		}
+		if repairDue {
+			c.repairTTLs(context.WithValue(zoneCtx, provider.RecordsContextKey, records), calculated.TTLRepairs)
+		}
+		if c.SkipUnchangedZones {
+			// the repaired TTLs alter the records, and the TTLs not repaired yet are not in sync
+			c.unchanged.observe(zone, sum, changes.HasChanges() || calculated.TTLRepairs != nil && calculated.TTLRepairs.HasChanges())
+		}
+		applied.Create = append(applied.Create, changes.Create...)
+		applied.UpdateOld = append(applied.UpdateOld, changes.UpdateOld...)
+		applied.UpdateNew = append(applied.UpdateNew, changes.UpdateNew...)
+		applied.Delete = append(applied.Delete, changes.Delete...)
+		if len(c.Exporters) > 0 || c.StatusAPI {
+			managed = append(managed, managedRecords(records, changes, c.Registry.OwnerID())...)
+		}
+	}
+
+	if scoped {
+		if !hasChanges {
+			log.Infof("All records of the zones %s are already up to date", strings.Join(zones, ", "))
+		}
+		return nil
+	}
+
+	registryEndpointsTotal.Set(float64(total))
+	registryARecords.Set(float64(regARecords))
+	registryAAAARecords.Set(float64(regAAAARecords))
+	verifiedARecords.Set(float64(vARecords))
+	verifiedAAAARecords.Set(float64(vAAAARecords))
+	deferredZones.Set(float64(deferred))
+	unchangedZonesSkipped.Set(float64(skipped))
+	if c.Delegations != nil {
+		c.Delegations.report(delegated)
+	}
+	if repairDue {
+		c.lastTTLRepair = time.Now()
+	}
+	c.migrate(ctx)
+	c.collectGarbage(ctx)
+	c.maintainZones(ctx)
+	if !hasChanges {
+		controllerNoChangesTotal.Inc()
+		log.Info("All records are already up to date")
+	}
+
+	lastSyncTimestamp.SetToCurrentTime()
+
+	if deferred > 0 {
+		// the managed records of the deferred zones are unknown
+		return nil
+	}
+	c.status.succeeded(managed, applied)
+	for _, exporter := range c.Exporters {
+		if err := exporter.Export(managed); err != nil {
+			log.Errorf("Failed to export records: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// requestedZones returns the zones of the requested hostnames, and forgets that they were found in sync.
+func (c *Controller) requestedZones(zoneNames provider.ZoneIDName, requested []string) []string {
+	var zones []string
+	for _, hostname := range requested {
+		_, zone := zoneNames.FindZone(hostname)
+		if zone == "" {
+			log.Warnf("No zone of the requested hostname %s", hostname)
+			continue
+		}
+		if !slices.Contains(zones, zone) {
+			zones = append(zones, zone)
+			delete(c.unchanged, zone)
+		}
+	}
+	return zones
+}
+
+// prioritizeZones moves the zones of the requested hostnames first, and forgets that they were found in sync.
+func (c *Controller) prioritizeZones(zones []string, zoneNames provider.ZoneIDName, requested []string) []string {
+	first := c.requestedZones(zoneNames, requested)
+	if len(first) == 0 {
+		return zones
+	}
+	log.Infof("Synchronizing the requested zones %s first", strings.Join(first, ", "))
+	rest := slices.DeleteFunc(slices.Clone(zones), func(zone string) bool {
+		return slices.Contains(first, zone)
+	})
+	return append(first, rest...)
+}
+
+// rotateZones returns the zones in alphabetical order, starting with the first zone not before next.
+func rotateZones(zones []string, next string) []string {
+	sorted := slices.Clone(zones)
+	slices.Sort(sorted)
+	i, _ := slices.BinarySearch(sorted, next)
+	return append(sorted[i:], sorted[:i]...)
+}
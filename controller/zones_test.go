@@ -0,0 +1,409 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+// scopeRecordingProvider records the zone scopes Records is called with.
+type scopeRecordingProvider struct {
+	*inmemory.InMemoryProvider
+	mu     sync.Mutex
+	scopes []string
+}
+
+func (p *scopeRecordingProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	scope, _ := provider.ZoneScope(ctx)
+	p.mu.Lock()
+	p.scopes = append(p.scopes, scope)
+	p.mu.Unlock()
+	// every listing costs one provider API request
+	req, err := http.NewRequest(http.MethodGet, "https://dns.example.org/records", nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := provider.NewAPICallCounter(roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})).RoundTrip(req); err != nil {
+		return nil, err
+	}
+	return p.InMemoryProvider.Records(ctx)
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRunOncePerZone(t *testing.T) {
+	p := &scopeRecordingProvider{InMemoryProvider: inmemory.NewInMemoryProvider(inmemory.InMemoryInitZones([]string{"example.com", "sub.example.com"}))}
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("stale.example.com", endpoint.RecordTypeA, "1.1.1.1"),
+			endpoint.NewEndpoint("update.sub.example.com", endpoint.RecordTypeA, "2.2.2.2"),
+		},
+	}))
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("create.example.com", endpoint.RecordTypeA, "3.3.3.3"),
+		endpoint.NewEndpoint("update.sub.example.com", endpoint.RecordTypeA, "4.4.4.4"),
+		endpoint.NewEndpoint("unknown.example.org", endpoint.RecordTypeA, "5.5.5.5"),
+	}, nil)
+
+	exporter := &fakeExporter{}
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Exporters:          []RecordsExporter{exporter},
+		ZoneLister:         p,
+	}
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+
+	assert.Equal(t, []string{"example.com", "sub.example.com"}, p.scopes)
+	records, err := p.InMemoryProvider.Records(context.Background())
+	require.NoError(t, err)
+	expected := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("create.example.com", endpoint.RecordTypeA, "3.3.3.3"),
+		endpoint.NewEndpoint("update.sub.example.com", endpoint.RecordTypeA, "4.4.4.4"),
+	}
+	assert.True(t, testutils.SameEndpoints(expected, records), "expected %v, got %v", expected, records)
+	require.Len(t, exporter.exported, 1)
+	assert.True(t, testutils.SameEndpoints(expected, exporter.exported[0]))
+}
+
+func TestRunOncePerZoneSkipsDelegatedHostnames(t *testing.T) {
+	p := &scopeRecordingProvider{InMemoryProvider: inmemory.NewInMemoryProvider(inmemory.InMemoryInitZones([]string{"example.com"}))}
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("team.example.com", endpoint.RecordTypeNS, "ns1.example.org"),
+		},
+	}))
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeA, "1.1.1.1"),
+		endpoint.NewEndpoint("app.team.example.com", endpoint.RecordTypeA, "2.2.2.2"),
+	}, nil)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		ZoneLister:         p,
+		Delegations:        NewDelegationGuard(p),
+	}
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+
+	records, err := p.InMemoryProvider.Records(context.Background())
+	require.NoError(t, err)
+	expected := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("team.example.com", endpoint.RecordTypeNS, "ns1.example.org"),
+		endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeA, "1.1.1.1"),
+	}
+	assert.True(t, testutils.SameEndpoints(expected, records), "expected %v, got %v", expected, records)
+	assert.Equal(t, []string{"app.team.example.com"}, ctrl.Delegations.reported)
+}
+
+func TestRunOncePerZoneDefersZonesOverBudget(t *testing.T) {
+	p := &scopeRecordingProvider{InMemoryProvider: inmemory.NewInMemoryProvider(inmemory.InMemoryInitZones([]string{"c.com", "a.com", "b.com"}))}
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{}, nil)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		ZoneLister:         p,
+		APIBudgetPerCycle:  2,
+	}
+
+	for _, expected := range [][]string{{"a.com", "b.com"}, {"c.com", "a.com"}, {"b.com", "c.com"}} {
+		p.scopes = nil
+		require.NoError(t, ctrl.RunOnce(context.Background()))
+		assert.Equal(t, expected, p.scopes)
+		assert.Equal(t, math.Float64bits(1), valueFromMetric(deferredZones))
+	}
+
+	ctrl.APIBudgetPerCycle = 0
+	p.scopes = nil
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, []string{"a.com", "b.com", "c.com"}, p.scopes)
+	assert.Equal(t, math.Float64bits(0), valueFromMetric(deferredZones))
+}
+
+func TestRunOncePerZoneRequestedHostnames(t *testing.T) {
+	p := &scopeRecordingProvider{InMemoryProvider: inmemory.NewInMemoryProvider(inmemory.InMemoryInitZones([]string{"c.com", "a.com", "b.com"}))}
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{}, nil)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		ZoneLister:         p,
+		APIBudgetPerCycle:  1,
+	}
+
+	// the zone of the hostname is synchronized first, within the budget
+	ctrl.ScheduleRunOnceFor(time.Now(), "app.c.com")
+	ctrl.ScheduleRunOnceFor(time.Now(), "app.missing.com")
+	p.scopes = nil
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, []string{"c.com"}, p.scopes)
+
+	// the request is consumed, and the deferred zones continue
+	p.scopes = nil
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, []string{"a.com"}, p.scopes)
+}
+
+func TestRunSynchronizesRequestedZoneOnItsOwn(t *testing.T) {
+	p := &scopeRecordingProvider{InMemoryProvider: inmemory.NewInMemoryProvider(inmemory.InMemoryInitZones([]string{"c.com", "a.com", "b.com"}))}
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{}, nil)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		ZoneLister:         p,
+		Interval:           time.Hour,
+	}
+	scopes := func() []string {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return slices.Clone(p.scopes)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		assert.NoError(t, ctrl.Run(ctx))
+		close(stopped)
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	// the first synchronization handles all zones
+	require.Eventually(t, func() bool { return len(scopes()) == 3 }, time.Second, 10*time.Millisecond)
+
+	// the requested zone is synchronized on its own, without waiting for the interval
+	ctrl.ScheduleRunOnceFor(time.Now(), "app.b.com")
+	require.Eventually(t, func() bool { return len(scopes()) == 4 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "b.com", scopes()[3])
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, scopes(), 4)
+}
+
+// failingZoneProvider fails to list the records of a zone with a soft error.
+type failingZoneProvider struct {
+	*inmemory.InMemoryProvider
+	zone string
+}
+
+func (p *failingZoneProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	if scope, _ := provider.ZoneScope(ctx); scope == p.zone {
+		return nil, provider.NewSoftError(errors.New("zone unavailable"))
+	}
+	return p.InMemoryProvider.Records(ctx)
+}
+
+func TestProcessNextItemRetriesRequestsOnTheirOwn(t *testing.T) {
+	p := &failingZoneProvider{InMemoryProvider: inmemory.NewInMemoryProvider(inmemory.InMemoryInitZones([]string{"a.com", "b.com"})), zone: "b.com"}
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{}, nil)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		ZoneLister:         p,
+	}
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.NewTypedItemExponentialFailureRateLimiter[reconcileRequest](time.Hour, time.Hour))
+	defer queue.ShutDown()
+
+	failing, healthy := reconcileRequest{Hostname: "app.b.com"}, reconcileRequest{Hostname: "app.a.com"}
+	queue.Add(failing)
+	queue.Add(healthy)
+	for range 2 {
+		more, err := ctrl.processNextItem(context.Background(), queue)
+		require.NoError(t, err)
+		require.True(t, more)
+	}
+
+	// the failing zone backs off on its own, the other zone is not delayed
+	assert.Equal(t, 1, queue.NumRequeues(failing))
+	assert.Equal(t, 0, queue.NumRequeues(healthy))
+	assert.Equal(t, 0, queue.Len())
+}
+
+func TestPrioritizeZones(t *testing.T) {
+	zoneNames := provider.ZoneIDName{}
+	for _, zone := range []string{"a.com", "b.com", "sub.b.com", "c.com"} {
+		zoneNames.Add(zone, zone)
+	}
+	ctrl := &Controller{unchanged: unchangedZones{"c.com": {}, "a.com": {}}}
+	zones := []string{"a.com", "b.com", "sub.b.com", "c.com"}
+	assert.Equal(t, zones, ctrl.prioritizeZones(zones, zoneNames, nil))
+	assert.Equal(t, []string{"c.com", "sub.b.com", "a.com", "b.com"}, ctrl.prioritizeZones(zones, zoneNames, []string{"c.com", "x.sub.b.com", "y.c.com"}))
+	assert.Equal(t, []string{"a.com", "b.com", "sub.b.com", "c.com"}, zones)
+	assert.Equal(t, unchangedZones{"a.com": {}}, ctrl.unchanged, "the requested zones must be synchronized even if unchanged")
+}
+
+func TestRunOncePerZoneSkipsUnchangedZones(t *testing.T) {
+	p := &scopeRecordingProvider{InMemoryProvider: inmemory.NewInMemoryProvider(inmemory.InMemoryInitZones([]string{"a.com", "b.com"}))}
+	r, err := registry.NewTXTRegistry(p, registry.TXTConfig{
+		OwnerID:            "owner",
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+	})
+	require.NoError(t, err)
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("foo.a.com", endpoint.RecordTypeA, "1.1.1.1"),
+	}, nil)
+
+	exporter := &fakeExporter{}
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Exporters:          []RecordsExporter{exporter},
+		ZoneLister:         p,
+		SkipUnchangedZones: true,
+		ZoneVersioner:      p,
+	}
+
+	for _, expected := range [][]string{
+		// a.com is changed, b.com is found in sync
+		{"a.com", "b.com"},
+		// a.com is found in sync
+		{"a.com"},
+		{},
+	} {
+		p.scopes = []string{}
+		require.NoError(t, ctrl.RunOnce(context.Background()))
+		assert.Equal(t, expected, p.scopes)
+	}
+	assert.Equal(t, math.Float64bits(2), valueFromMetric(unchangedZonesSkipped))
+	require.Len(t, exporter.exported, 3)
+	assert.Len(t, exporter.exported[2], 1)
+	assert.True(t, testutils.SameEndpoints(exporter.exported[1], exporter.exported[2]), "the records of skipped zones must be exported")
+
+	// the records of b.com are listed again once they changed
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("bar.b.com", endpoint.RecordTypeA, "2.2.2.2")},
+	}))
+	p.scopes = []string{}
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, []string{"b.com"}, p.scopes)
+	assert.Equal(t, math.Float64bits(1), valueFromMetric(unchangedZonesSkipped))
+}
+
+func TestRunOncePerZoneSkipsUnchangedZonesWithoutVersion(t *testing.T) {
+	p := &scopeRecordingProvider{InMemoryProvider: inmemory.NewInMemoryProvider(inmemory.InMemoryInitZones([]string{"a.com", "b.com"}))}
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("foo.a.com", endpoint.RecordTypeA, "1.1.1.1"),
+	}, nil)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		ZoneLister:         p,
+		SkipUnchangedZones: true,
+	}
+
+	for _, skipped := range []float64{0, 1, 2} {
+		p.scopes = nil
+		require.NoError(t, ctrl.RunOnce(context.Background()))
+		assert.Equal(t, []string{"a.com", "b.com"}, p.scopes, "the records must be listed to tell whether they changed")
+		assert.Equal(t, math.Float64bits(skipped), valueFromMetric(unchangedZonesSkipped))
+	}
+}
+
+func TestRotateZones(t *testing.T) {
+	zones := []string{"c.com", "a.com", "b.com"}
+	assert.Equal(t, []string{"a.com", "b.com", "c.com"}, rotateZones(zones, ""))
+	assert.Equal(t, []string{"b.com", "c.com", "a.com"}, rotateZones(zones, "b.com"))
+	// a deferred zone that no longer exists continues with the next zone
+	assert.Equal(t, []string{"c.com", "a.com", "b.com"}, rotateZones(zones, "bb.com"))
+	assert.Equal(t, []string{"c.com", "a.com", "b.com"}, zones)
+}
+
+func TestZoneFilter(t *testing.T) {
+	zones := provider.ZoneIDName{}
+	zones.Add("example.com", "example.com")
+	zones.Add("sub.example.com", "sub.example.com")
+	filter := zoneFilter{zone: "example.com", zones: zones}
+
+	assert.True(t, filter.Match("example.com"))
+	assert.True(t, filter.Match("foo.Example.com."))
+	assert.False(t, filter.Match("foo.sub.example.com"))
+	assert.False(t, filter.Match("example.org"))
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CanonicalDNSName returns the canonical form of a DNS name, used to compare the names of desired endpoints,
+// provider records and registry records, which providers return in different forms:
+// in lower case, without surrounding spaces and trailing dot, and with the octal escape codes decoded.
+func CanonicalDNSName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(UnescapeDNSName(strings.TrimSpace(name))), ".")
+}
+
+// UnescapeDNSName decodes the three-digit octal escape codes of a DNS name, e.g. \052 for a wildcard, with which
+// some providers, e.g. Route53, return the characters other than letters, digits, hyphens, underscores and dots.
+// A backslash not followed by an octal escape code is kept.
+func UnescapeDNSName(name string) string {
+	if !strings.Contains(name, `\`) {
+		return name
+	}
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '\\' && i+3 < len(name) && isOctalEscape(name[i+1:i+4]) {
+			b.WriteByte((name[i+1]-'0')<<6 | (name[i+2]-'0')<<3 | (name[i+3] - '0'))
+			i += 3
+			continue
+		}
+		b.WriteByte(name[i])
+	}
+	return b.String()
+}
+
+// isOctalEscape returns whether the three characters are the digits of an octal escape code of a byte.
+func isOctalEscape(digits string) bool {
+	return digits[0] >= '0' && digits[0] <= '3' &&
+		digits[1] >= '0' && digits[1] <= '7' &&
+		digits[2] >= '0' && digits[2] <= '7'
+}
+
+// EscapeDNSName encodes the bytes of a DNS name other than letters, digits, hyphens, underscores and dots with
+// three-digit octal escape codes, the form in which Route53 expects them; UnescapeDNSName decodes them back.
+func EscapeDNSName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "\\%03o", c)
+	}
+	return b.String()
+}
+
+// CanonicalTarget returns the canonical form of a target of a record type, used to compare the targets
+// of desired endpoints and provider records, see Target.Canonical. Invalid targets are returned unchanged.
+func CanonicalTarget(recordType, target string) string {
+	typed, err := ParseTarget(recordType, target)
+	if err != nil {
+		return target
+	}
+	return typed.Canonical()
+}
+
+// CanonicalTargets returns the targets of the endpoint in canonical form.
+func (e *Endpoint) CanonicalTargets() Targets {
+	targets := make(Targets, len(e.Targets))
+	for i, target := range e.Targets {
+		targets[i] = CanonicalTarget(e.RecordType, target)
+	}
+	return targets
+}
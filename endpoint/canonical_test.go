@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalDNSName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"app.example.com":        "app.example.com",
+		" App.Example.COM. ":     "app.example.com",
+		`\052.example.com.`:      "*.example.com",
+		"*.example.com":          "*.example.com",
+		`app.\052.example.com`:   "app.*.example.com",
+		`Txt-\041\046.example.`:  "txt-!&.example",
+		`caf\303\251.example`:    "caf\u00e9.example",
+		`back\slash.example`:     `back\slash.example`,
+		"xn--bcher-kva.example.": "xn--bcher-kva.example",
+	} {
+		if got := CanonicalDNSName(name); got != expected {
+			t.Errorf("CanonicalDNSName(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestEscapeDNSName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"app.example.com":        "app.example.com",
+		"*.example.com":          `\052.example.com`,
+		"_sip._tcp.Example.com.": "_sip._tcp.Example.com.",
+		"a b@c.example":          `a\040b\100c.example`,
+		"caf\u00e9.example":      `caf\303\251.example`,
+		`back\slash`:             `back\134slash`,
+	} {
+		if got := EscapeDNSName(name); got != expected {
+			t.Errorf("EscapeDNSName(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestUnescapeDNSName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"app.example.com":       "app.example.com",
+		`\052.example.com`:      "*.example.com",
+		`\052.\052.example.com`: "*.*.example.com",
+		`txt-\041\042\043\044\045\046\047\050\051\052\053\054-\057\072\073-test.example.com`: `txt-!"#$%&'()*+,-/:;-test.example.com`,
+		`txt-\074\075\076\077\100\133\134\135\136_\140\173\174\175\176-test2.example.com`:    "txt-<=>?@[\\]^_`{|}~-test2.example.com",
+		`not\8escaped`:   `not\8escaped`,
+		`not\477escaped`: `not\477escaped`,
+		`trailing\05`:    `trailing\05`,
+		`trailing\`:      `trailing\`,
+	} {
+		if got := UnescapeDNSName(name); got != expected {
+			t.Errorf("UnescapeDNSName(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+
+	// every byte survives the round trip, and the escaped names only contain letters, digits, hyphens,
+	// underscores, dots and escape codes
+	for c := 0; c < 256; c++ {
+		name := "a" + string([]byte{byte(c)}) + ".example.com"
+		escaped := EscapeDNSName(name)
+		if got := UnescapeDNSName(escaped); got != name {
+			t.Errorf("UnescapeDNSName(EscapeDNSName(%q)) = %q", name, got)
+		}
+		for _, r := range escaped {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' || r == '\\') {
+				t.Errorf("EscapeDNSName(%q) = %q contains %q", name, escaped, r)
+			}
+		}
+	}
+}
+
+func TestCanonicalTarget(t *testing.T) {
+	for _, tc := range []struct {
+		recordType string
+		target     string
+		expected   string
+	}{
+		{RecordTypeA, "1.2.3.4", "1.2.3.4"},
+		{RecordTypeAAAA, "2001:DB8:0:0:0:0:0:1", "2001:db8::1"},
+		{RecordTypeCNAME, "LB.example.com.", "lb.example.com"},
+		{RecordTypeNS, "ns1.example.com.", "ns1.example.com"},
+		{RecordTypeMX, "10  Mail.example.com.", "10 mail.example.com"},
+		{RecordTypeSRV, "10 5 443 App.example.com.", "10 5 443 app.example.com"},
+		{RecordTypeTXT, "Heritage=external-dns.", "Heritage=external-dns."},
+		{RecordTypeNAPTR, `100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`, `100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`},
+	} {
+		if got := CanonicalTarget(tc.recordType, tc.target); got != tc.expected {
+			t.Errorf("CanonicalTarget(%s, %q) = %q, expected %q", tc.recordType, tc.target, got, tc.expected)
+		}
+	}
+}
+
+func TestCanonicalTargets(t *testing.T) {
+	ep := NewEndpoint("app.example.com", RecordTypeCNAME, "LB.example.com")
+	if got := ep.CanonicalTargets(); !reflect.DeepEqual(got, Targets{"lb.example.com"}) {
+		t.Errorf("CanonicalTargets() = %v", got)
+	}
+	if !reflect.DeepEqual(ep.Targets, Targets{"LB.example.com"}) {
+		t.Errorf("CanonicalTargets() modified the targets: %v", ep.Targets)
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import "strings"
+
+// recordCommentReference is the start of the reference to the resource of a record comment, see NewRecordComment.
+const recordCommentReference = "(external-dns"
+
+// NewRecordComment returns the comment of the records of a resource, the text followed by a reference to the resource,
+// e.g. "Frontend of the shop (external-dns: service/shop/frontend)". The reference tells DNS admins where the records
+// come from, and providers which comments ExternalDNS manages, see IsRecordComment.
+func NewRecordComment(text, resource string) string {
+	reference := recordCommentReference + ")"
+	if resource != "" {
+		reference = recordCommentReference + ": " + resource + ")"
+	}
+	if text == "" {
+		return reference
+	}
+	return text + " " + reference
+}
+
+// IsRecordComment returns true if the comment of a record was set by ExternalDNS, see NewRecordComment. Providers
+// reporting record comments report only these, so that the other comments are neither compared nor overwritten.
+func IsRecordComment(comment string) bool {
+	if !strings.HasSuffix(comment, ")") {
+		return false
+	}
+	return strings.HasPrefix(comment, recordCommentReference) || strings.Contains(comment, " "+recordCommentReference)
+}
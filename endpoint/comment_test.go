@@ -0,0 +1,43 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRecordComment(t *testing.T) {
+	assert.Equal(t, "Frontend of the shop (external-dns: service/shop/frontend)", NewRecordComment("Frontend of the shop", "service/shop/frontend"))
+	assert.Equal(t, "(external-dns: service/shop/frontend)", NewRecordComment("", "service/shop/frontend"))
+	assert.Equal(t, "Frontend of the shop (external-dns)", NewRecordComment("Frontend of the shop", ""))
+}
+
+func TestIsRecordComment(t *testing.T) {
+	for comment, expected := range map[string]bool{
+		"Frontend of the shop (external-dns: service/shop/frontend)":        true,
+		"(external-dns: service/shop/frontend)":                             true,
+		"Frontend of the shop (external-dns)":                               true,
+		"Frontend of the shop":                                              false,
+		"Frontend of the shop (external-dns: service/shop/frontend) edited": false,
+		"ownership record":                                                  false,
+		"":                                                                  false,
+	} {
+		assert.Equal(t, expected, IsRecordComment(comment), comment)
+	}
+}
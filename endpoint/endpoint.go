@@ -195,6 +195,10 @@ type ProviderSpecificProperty struct {
 // ProviderSpecific holds configuration which is specific to individual DNS providers
 type ProviderSpecific []ProviderSpecificProperty
 
+// ProviderSpecificRecordComment is the provider-specific property holding the comment of a record,
+// set by the providers supporting record comments
+const ProviderSpecificRecordComment = "record-comment"
+
 // EndpointKey is the type of a map key for separating endpoints or targets.
 type EndpointKey struct {
 	DNSName       string
@@ -316,6 +320,18 @@ func (e *Endpoint) IsOwnedBy(ownerID string) bool {
 	return ok && endpointOwner == ownerID
 }
 
+// IsReleasedTo returns true if the current owner hands the endpoint over to the given ownerID, false otherwise
+func (e *Endpoint) IsReleasedTo(ownerID string) bool {
+	releaseTo, ok := e.Labels[ReleaseToLabelKey]
+	return ok && releaseTo != "" && releaseTo == ownerID
+}
+
+// IsAdoptedBy returns true if the endpoint has no owner and is adopted by the given ownerID, false otherwise
+func (e *Endpoint) IsAdoptedBy(ownerID string) bool {
+	adopter, ok := e.Labels[AdoptLabelKey]
+	return ok && adopter != "" && adopter == ownerID && e.Labels[OwnerLabelKey] == ""
+}
+
 func (e *Endpoint) String() string {
 	return fmt.Sprintf("%s %d IN %s %s %s %s", e.DNSName, e.RecordTTL, e.RecordType, e.SetIdentifier, e.Targets, e.ProviderSpecific)
 }
@@ -326,7 +342,11 @@ func FilterEndpointsByOwnerID(ownerID string, eps []*Endpoint) []*Endpoint {
 	filtered := []*Endpoint{}
 	for _, ep := range eps {
 		if endpointOwner, ok := ep.Labels[OwnerLabelKey]; !ok || endpointOwner != ownerID {
-			log.Debugf(`Skipping endpoint %v because owner id does not match, found: "%s", required: "%s"`, ep, endpointOwner, ownerID)
+			Skipped(SkipReasonOwnerMismatch).WithFields(log.Fields{
+				"filter":   OwnerLabelKey,
+				"found":    endpointOwner,
+				"required": ownerID,
+			}).Debugf(`Skipping endpoint %v because owner id does not match`, ep)
 		} else {
 			filtered = append(filtered, ep)
 		}
@@ -335,6 +355,24 @@ func FilterEndpointsByOwnerID(ownerID string, eps []*Endpoint) []*Endpoint {
 	return filtered
 }
 
+// FilterEndpointsClaimableBy returns the endpoints that are owned by, released to or adopted by the given ownerID.
+func FilterEndpointsClaimableBy(ownerID string, eps []*Endpoint) []*Endpoint {
+	filtered := []*Endpoint{}
+	for _, ep := range eps {
+		if ep.IsOwnedBy(ownerID) || ep.IsReleasedTo(ownerID) || ep.IsAdoptedBy(ownerID) {
+			filtered = append(filtered, ep)
+		} else {
+			Skipped(SkipReasonNotClaimable).WithFields(log.Fields{
+				"filter":   OwnerLabelKey,
+				"found":    ep.Labels[OwnerLabelKey],
+				"required": ownerID,
+			}).Debugf(`Skipping endpoint %v because it is neither owned by, released to nor adopted by "%s"`, ep, ownerID)
+		}
+	}
+
+	return filtered
+}
+
 // DNSEndpointSpec defines the desired state of DNSEndpoint
 type DNSEndpointSpec struct {
 	Endpoints []*Endpoint `json:"endpoints,omitempty"`
@@ -390,7 +428,7 @@ func RemoveDuplicates(endpoints []*Endpoint) []*Endpoint {
 			result = append(result, ep)
 			visited[key] = struct{}{}
 		} else {
-			log.Debugf(`Skipping duplicated endpoint: %v`, ep)
+			Skipped(SkipReasonDuplicate).Debugf(`Skipping duplicated endpoint: %v`, ep)
 		}
 	}
 
@@ -441,3 +441,19 @@ func TestDuplicatedEndpointsWithOverlappingZones(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterEndpointsClaimableBy(t *testing.T) {
+	owned := &Endpoint{DNSName: "owned.com", RecordType: RecordTypeA, Labels: Labels{OwnerLabelKey: "foo"}}
+	released := &Endpoint{DNSName: "released.com", RecordType: RecordTypeA, Labels: Labels{OwnerLabelKey: "bar", ReleaseToLabelKey: "foo"}}
+	releasedElsewhere := &Endpoint{DNSName: "elsewhere.com", RecordType: RecordTypeA, Labels: Labels{OwnerLabelKey: "bar", ReleaseToLabelKey: "baz"}}
+	foreign := &Endpoint{DNSName: "foreign.com", RecordType: RecordTypeA, Labels: Labels{OwnerLabelKey: "bar"}}
+
+	if !released.IsReleasedTo("foo") || releasedElsewhere.IsReleasedTo("foo") || foreign.IsReleasedTo("") {
+		t.Error("IsReleasedTo() returned an unexpected result")
+	}
+
+	got := FilterEndpointsClaimableBy("foo", []*Endpoint{owned, released, releasedElsewhere, foreign})
+	if want := []*Endpoint{owned, released}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterEndpointsClaimableBy() = %v, want %v", got, want)
+	}
+}
@@ -44,6 +44,21 @@ const (
 	// DualstackLabelKey is the name of the label that identifies dualstack endpoints
 	DualstackLabelKey = "dualstack"
 
+	// ReleaseToLabelKey is the name of the label that names the owner an Endpoint is handed over to
+	ReleaseToLabelKey = "release-to"
+
+	// AdoptLabelKey is the name of the label that requests adopting an unowned record on a desired Endpoint,
+	// and names the adopting owner on the adopted record
+	AdoptLabelKey = "adopt"
+
+	// UnicodeHostnameLabelKey is the name of the label that holds the original Unicode hostname of an Endpoint
+	// whose DNSName was converted to punycode. It is not stored in the registry, as it can be derived from the DNSName.
+	UnicodeHostnameLabelKey = "unicode-hostname"
+
+	// LeaseLabelKey is the name of the label that holds the lease of the instance managing an Endpoint,
+	// as the instance name and the Unix time the lease expires, separated by "@"
+	LeaseLabelKey = "lease"
+
 	// txtEncryptionNonce label for keep same nonce for same txt records, for prevent different result of encryption for same txt record, it can cause issues for some providers
 	txtEncryptionNonce = "txt-encryption-nonce"
 )
@@ -119,7 +134,7 @@ func (l Labels) SerializePlain(withQuotes bool) string {
 	sort.Strings(keys) // sort for consistency
 
 	for _, key := range keys {
-		if key == txtEncryptionNonce {
+		if key == txtEncryptionNonce || key == UnicodeHostnameLabelKey {
 			continue
 		}
 		tokens = append(tokens, fmt.Sprintf("%s/%s=%s", heritage, key, l[key]))
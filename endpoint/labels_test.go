@@ -72,6 +72,14 @@ func (suite *LabelsSuite) TestSerialize() {
 	suite.NotEqual(suite.fooAsTextWithQuotes, suite.foo.Serialize(true, true, suite.aesKey), "should serializeLabel and encrypt")
 }
 
+func (suite *LabelsSuite) TestSerializeSkipsUnicodeHostname() {
+	foo := Labels{UnicodeHostnameLabelKey: "bücher.example.com"}
+	for key, value := range suite.foo {
+		foo[key] = value
+	}
+	suite.Equal(suite.fooAsText, foo.SerializePlain(false), "should not serialize the unicode hostname")
+}
+
 func (suite *LabelsSuite) TestEncryptionNonceReUsage() {
 	foo, err := NewLabelsFromString(suite.fooAsTextEncrypted, suite.aesKey)
 	suite.NoError(err, "should succeed for valid label text")
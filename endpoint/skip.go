@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// The reason codes of the endpoints and resources skipped by the sources, the registry and the plan, in the
+// reason field of their debug log entries.
+const (
+	// SkipReasonNoHostname is the reason for resources producing no endpoint, without hostname or targets
+	SkipReasonNoHostname = "no-hostname"
+	// SkipReasonFiltered is the reason for endpoints whose targets are all excluded by the target filter
+	SkipReasonFiltered = "filtered"
+	// SkipReasonInvalidHostname is the reason for endpoints whose hostname is not a valid domain name
+	SkipReasonInvalidHostname = "invalid-hostname"
+	// SkipReasonInvalidTarget is the reason for endpoints with a target that is not a valid domain name
+	SkipReasonInvalidTarget = "invalid-target"
+	// SkipReasonControllerMismatch is the reason for resources whose controller annotation is not ExternalDNS
+	SkipReasonControllerMismatch = "controller-mismatch"
+	// SkipReasonNotHostNetwork is the reason for pods not in the host network
+	SkipReasonNotHostNetwork = "not-host-network"
+	// SkipReasonNotPod is the reason for the addresses of a service whose target is not a pod
+	SkipReasonNotPod = "not-pod"
+	// SkipReasonOwnerMismatch is the reason for records owned by another owner ID
+	SkipReasonOwnerMismatch = "owner-mismatch"
+	// SkipReasonNotClaimable is the reason for records neither owned by, released to nor adopted by the owner ID
+	SkipReasonNotClaimable = "not-claimable"
+	// SkipReasonDuplicate is the reason for endpoints with the same key as a previous endpoint
+	SkipReasonDuplicate = "duplicate"
+	// SkipReasonDomainFilter is the reason for endpoints and records not matching the domain filter
+	SkipReasonDomainFilter = "domain-filter"
+	// SkipReasonLeased is the reason for the record sets leased by another instance
+	SkipReasonLeased = "leased"
+)
+
+// skipped counts the skipped endpoints and resources by reason since the last summary.
+var skipped = struct {
+	sync.Mutex
+	counts map[string]int
+}{counts: map[string]int{}}
+
+// Skipped counts an endpoint or resource skipped for the reason in the summary of the synchronization, and
+// returns the log entry to tell why, with the reason field. The fields of the entry name the filter and the
+// compared values, e.g. filter, found and required, so that the logs can be analyzed with scripts.
+func Skipped(reason string) *log.Entry {
+	skipped.Lock()
+	skipped.counts[reason]++
+	skipped.Unlock()
+	return log.WithField("reason", reason)
+}
+
+// TakeSkippedSummary returns the number of endpoints and resources skipped by reason since the last summary,
+// and starts the next summary.
+func TakeSkippedSummary() map[string]int {
+	skipped.Lock()
+	defer skipped.Unlock()
+	summary := skipped.counts
+	skipped.counts = map[string]int{}
+	return summary
+}
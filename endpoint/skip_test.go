@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipped(t *testing.T) {
+	TakeSkippedSummary()
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+	level := log.GetLevel()
+	defer log.SetLevel(level)
+	log.SetLevel(log.DebugLevel)
+
+	owned := NewEndpoint("owned.example.org", RecordTypeA, "1.2.3.4")
+	owned.Labels[OwnerLabelKey] = "owner"
+	other := NewEndpoint("other.example.org", RecordTypeA, "1.2.3.4")
+	other.Labels[OwnerLabelKey] = "other"
+	FilterEndpointsByOwnerID("owner", []*Endpoint{owned, other, NewEndpoint("none.example.org", RecordTypeA, "1.2.3.4")})
+	RemoveDuplicates([]*Endpoint{
+		NewEndpoint("dup.example.org", RecordTypeA, "1.2.3.4"),
+		NewEndpoint("dup.example.org", RecordTypeA, "1.2.3.4"),
+	})
+
+	require.Len(t, hook.AllEntries(), 3)
+	assert.Equal(t, log.Fields{
+		"reason":   SkipReasonOwnerMismatch,
+		"filter":   OwnerLabelKey,
+		"found":    "other",
+		"required": "owner",
+	}, hook.AllEntries()[0].Data)
+	assert.Equal(t, log.Fields{"reason": SkipReasonDuplicate}, hook.LastEntry().Data)
+
+	assert.Equal(t, map[string]int{SkipReasonOwnerMismatch: 2, SkipReasonDuplicate: 1}, TakeSkippedSummary())
+	assert.Empty(t, TakeSkippedSummary())
+}
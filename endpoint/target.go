@@ -0,0 +1,356 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxTXTChunkLength is the maximum length in bytes of a character string of a TXT record.
+const MaxTXTChunkLength = 255
+
+// Target is the typed value of a target of an endpoint, parsed from its string form in Targets according to the
+// record type of the endpoint, so that the targets are compared and validated per record type instead of as strings.
+type Target interface {
+	// String returns the string form of the target, as kept in Targets
+	String() string
+	// Canonical returns the canonical form of the target, used to compare the targets of desired endpoints and
+	// provider records
+	Canonical() string
+}
+
+// IPTarget is the target of an A or AAAA record.
+type IPTarget struct {
+	Addr netip.Addr
+}
+
+func (t IPTarget) String() string {
+	return t.Addr.String()
+}
+
+// Canonical returns the address in its shortest form, e.g. 2001:db8::1 for 2001:DB8:0:0:0:0:0:1.
+func (t IPTarget) Canonical() string {
+	return t.Addr.String()
+}
+
+// HostnameTarget is the target of a CNAME, NS or PTR record.
+type HostnameTarget struct {
+	Hostname string
+}
+
+func (t HostnameTarget) String() string {
+	return t.Hostname
+}
+
+// Canonical returns the canonical form of the hostname, see CanonicalDNSName.
+func (t HostnameTarget) Canonical() string {
+	return CanonicalDNSName(t.Hostname)
+}
+
+// MXTarget is the target of an MX record, e.g. "10 mail.example.com".
+type MXTarget struct {
+	Preference uint16
+	Host       string
+}
+
+func (t MXTarget) String() string {
+	return fmt.Sprintf("%d %s", t.Preference, t.Host)
+}
+
+// Canonical returns the target with the host in canonical form.
+func (t MXTarget) Canonical() string {
+	return fmt.Sprintf("%d %s", t.Preference, CanonicalDNSName(t.Host))
+}
+
+// SRVTarget is the target of an SRV record, e.g. "10 5 443 app.example.com".
+type SRVTarget struct {
+	Priority uint16
+	Weight   uint16
+	Port     uint16
+	Host     string
+}
+
+func (t SRVTarget) String() string {
+	return fmt.Sprintf("%d %d %d %s", t.Priority, t.Weight, t.Port, t.Host)
+}
+
+// Canonical returns the target with the host in canonical form.
+func (t SRVTarget) Canonical() string {
+	return fmt.Sprintf("%d %d %d %s", t.Priority, t.Weight, t.Port, CanonicalDNSName(t.Host))
+}
+
+// TXTTarget is the target of a TXT record, made of one or more character strings of at most 255 bytes each, which
+// are concatenated into the text of the record. A target made of quoted strings, e.g. `"v=spf1 " "-all"`, has one
+// chunk per string; any other target is a single chunk of text.
+type TXTTarget struct {
+	Chunks []string
+	// Quoted is true if the chunks are quoted in the string form of the target
+	Quoted bool
+}
+
+// Text returns the text of the record, the concatenation of its chunks.
+func (t TXTTarget) Text() string {
+	return strings.Join(t.Chunks, "")
+}
+
+// String returns the text of the target, or its chunks quoted and escaped as in zone files, e.g. `"v=spf1 " "-all"`.
+func (t TXTTarget) String() string {
+	if !t.Quoted {
+		return t.Text()
+	}
+	quoted := make([]string, len(t.Chunks))
+	for i, chunk := range t.Chunks {
+		quoted[i] = quoteTXTChunk(chunk)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Canonical returns the text of the record, so that the same text split into different character strings, or
+// not quoted at all, compares equal. The text of TXT records is case-sensitive.
+func (t TXTTarget) Canonical() string {
+	return t.Text()
+}
+
+// Chunked returns the target quoted, with its chunks longer than MaxTXTChunkLength split, see SplitTXT.
+func (t TXTTarget) Chunked() TXTTarget {
+	var chunks []string
+	for _, chunk := range t.Chunks {
+		chunks = append(chunks, SplitTXT(chunk)...)
+	}
+	return TXTTarget{Chunks: chunks, Quoted: true}
+}
+
+// Oversized returns true if a chunk of the target is longer than MaxTXTChunkLength.
+func (t TXTTarget) Oversized() bool {
+	for _, chunk := range t.Chunks {
+		if len(chunk) > MaxTXTChunkLength {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitTXT splits text into character strings of at most MaxTXTChunkLength bytes, without splitting UTF-8
+// encoded characters.
+func SplitTXT(text string) []string {
+	if len(text) <= MaxTXTChunkLength {
+		return []string{text}
+	}
+	var chunks []string
+	for len(text) > MaxTXTChunkLength {
+		end := MaxTXTChunkLength
+		for end > 0 && !utf8.RuneStart(text[end]) {
+			end--
+		}
+		if end == 0 {
+			end = MaxTXTChunkLength
+		}
+		chunks = append(chunks, text[:end])
+		text = text[end:]
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
+// ChunkTXTTarget returns the string form of a TXT target split into quoted character strings of at most
+// MaxTXTChunkLength bytes, for providers which take TXT records in zone file format. Targets without longer
+// character strings are returned unchanged.
+func ChunkTXTTarget(value string) string {
+	target := ParseTXTTarget(value)
+	if !target.Oversized() {
+		return value
+	}
+	return target.Chunked().String()
+}
+
+// quoteTXTChunk quotes a character string of a TXT record, escaping quotes, backslashes and non-printable bytes
+// as in zone files.
+func quoteTXTChunk(chunk string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(chunk); i++ {
+		switch c := chunk[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < ' ' || c == 0x7f:
+			fmt.Fprintf(&b, "\\%03d", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// unquoteTXTChunk returns the character string quoted at the start of s, and the rest of s, or false if s does not
+// start with a terminated quoted string.
+func unquoteTXTChunk(s string) (string, string, bool) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", "", false
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return b.String(), s[i+1:], true
+		case '\\':
+			if i+3 < len(s) && isDigit(s[i+1]) && isDigit(s[i+2]) && isDigit(s[i+3]) {
+				n, _ := strconv.Atoi(s[i+1 : i+4])
+				if n > 255 {
+					return "", "", false
+				}
+				b.WriteByte(byte(n))
+				i += 3
+			} else if i+1 < len(s) {
+				b.WriteByte(s[i+1])
+				i++
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", false
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// RawTarget is the target of a record type without typed targets, e.g. NAPTR, kept as is.
+type RawTarget string
+
+func (t RawTarget) String() string {
+	return string(t)
+}
+
+// Canonical returns the target unchanged.
+func (t RawTarget) Canonical() string {
+	return string(t)
+}
+
+// ParseTarget parses the string form of a target of a record type.
+func ParseTarget(recordType, value string) (Target, error) {
+	switch recordType {
+	case RecordTypeA, RecordTypeAAAA:
+		addr, err := netip.ParseAddr(value)
+		// IPv4-mapped IPv6 addresses are valid targets of A records, but remain IPv6 addresses in AAAA records
+		if recordType == RecordTypeA && addr.Is4In6() {
+			addr = addr.Unmap()
+		}
+		if err != nil || addr.Is4() != (recordType == RecordTypeA) || addr.Zone() != "" {
+			if recordType == RecordTypeA {
+				return nil, errors.New("A records require an IPv4 address")
+			}
+			return nil, errors.New("AAAA records require an IPv6 address")
+		}
+		return IPTarget{Addr: addr}, nil
+	case RecordTypeCNAME, RecordTypeNS, RecordTypePTR:
+		if strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("%s records require a hostname", recordType)
+		}
+		return HostnameTarget{Hostname: value}, nil
+	case RecordTypeMX:
+		return ParseMXTarget(value)
+	case RecordTypeSRV:
+		return ParseSRVTarget(value)
+	case RecordTypeTXT:
+		return ParseTXTTarget(value), nil
+	default:
+		return RawTarget(value), nil
+	}
+}
+
+// ParseMXTarget parses the target of an MX record, a preference and a host separated by spaces.
+func ParseMXTarget(value string) (MXTarget, error) {
+	invalid := errors.New("MX records require a preference and a hostname, e.g. \"10 mail.example.com\"")
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return MXTarget{}, invalid
+	}
+	preference, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return MXTarget{}, invalid
+	}
+	return MXTarget{Preference: uint16(preference), Host: fields[1]}, nil
+}
+
+// ParseSRVTarget parses the target of an SRV record, a priority, a weight, a port and a host separated by spaces.
+func ParseSRVTarget(value string) (SRVTarget, error) {
+	invalid := errors.New("SRV records require a priority, a weight, a port and a hostname, e.g. \"10 5 443 app.example.com\"")
+	fields := strings.Fields(value)
+	if len(fields) != 4 {
+		return SRVTarget{}, invalid
+	}
+	var numbers [3]uint16
+	for i := range numbers {
+		n, err := strconv.ParseUint(fields[i], 10, 16)
+		if err != nil {
+			return SRVTarget{}, invalid
+		}
+		numbers[i] = uint16(n)
+	}
+	return SRVTarget{Priority: numbers[0], Weight: numbers[1], Port: numbers[2], Host: fields[3]}, nil
+}
+
+// ParseTXTTarget parses the target of a TXT record, split into its quoted strings if it is made of quoted strings
+// only, and a single chunk of text otherwise. Quoted strings are unescaped as in zone files, e.g. \" and \059.
+func ParseTXTTarget(value string) TXTTarget {
+	var chunks []string
+	rest := strings.TrimSpace(value)
+	for rest != "" {
+		chunk, next, ok := unquoteTXTChunk(rest)
+		if !ok {
+			return TXTTarget{Chunks: []string{value}}
+		}
+		chunks = append(chunks, chunk)
+		rest = strings.TrimLeft(next, " ")
+	}
+	if len(chunks) == 0 {
+		return TXTTarget{Chunks: []string{value}}
+	}
+	return TXTTarget{Chunks: chunks, Quoted: true}
+}
+
+// NewTypedTargets returns the string forms of typed targets.
+func NewTypedTargets(targets ...Target) Targets {
+	t := make(Targets, len(targets))
+	for i, target := range targets {
+		t[i] = target.String()
+	}
+	return t
+}
+
+// TypedTargets returns the targets of the endpoint parsed according to its record type.
+func (e *Endpoint) TypedTargets() ([]Target, error) {
+	targets := make([]Target, len(e.Targets))
+	for i, value := range e.Targets {
+		target, err := ParseTarget(e.RecordType, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target %q of %s record %s: %w", value, e.RecordType, e.DNSName, err)
+		}
+		targets[i] = target
+	}
+	return targets, nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTarget(t *testing.T) {
+	for _, tc := range []struct {
+		recordType string
+		value      string
+		expected   Target
+		canonical  string
+	}{
+		{RecordTypeA, "1.2.3.4", IPTarget{Addr: netip.MustParseAddr("1.2.3.4")}, "1.2.3.4"},
+		{RecordTypeA, "::ffff:1.2.3.4", IPTarget{Addr: netip.MustParseAddr("1.2.3.4")}, "1.2.3.4"},
+		{RecordTypeAAAA, "2001:DB8:0:0:0:0:0:1", IPTarget{Addr: netip.MustParseAddr("2001:db8::1")}, "2001:db8::1"},
+		{RecordTypeAAAA, "::ffff:1.2.3.4", IPTarget{Addr: netip.MustParseAddr("::ffff:1.2.3.4")}, "::ffff:1.2.3.4"},
+		{RecordTypeCNAME, "LB.example.com.", HostnameTarget{Hostname: "LB.example.com."}, "lb.example.com"},
+		{RecordTypeMX, "10  Mail.example.com.", MXTarget{Preference: 10, Host: "Mail.example.com."}, "10 mail.example.com"},
+		{RecordTypeSRV, "10 5 443 App.example.com", SRVTarget{Priority: 10, Weight: 5, Port: 443, Host: "App.example.com"}, "10 5 443 app.example.com"},
+		{RecordTypeTXT, "v=spf1 -all", TXTTarget{Chunks: []string{"v=spf1 -all"}}, "v=spf1 -all"},
+		{RecordTypeTXT, `"v=spf1 "  "-all"`, TXTTarget{Chunks: []string{"v=spf1 ", "-all"}, Quoted: true}, "v=spf1 -all"},
+		{RecordTypeTXT, `"a \"quoted\" \\ text\059"`, TXTTarget{Chunks: []string{`a "quoted" \ text;`}, Quoted: true}, `a "quoted" \ text;`},
+		{RecordTypeTXT, `"unterminated`, TXTTarget{Chunks: []string{`"unterminated`}}, `"unterminated`},
+		{RecordTypeNAPTR, `100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`, RawTarget(`100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`), `100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`},
+	} {
+		t.Run(tc.recordType+" "+tc.value, func(t *testing.T) {
+			target, err := ParseTarget(tc.recordType, tc.value)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, target)
+			assert.Equal(t, tc.canonical, target.Canonical())
+		})
+	}
+}
+
+func TestParseTargetErrors(t *testing.T) {
+	for _, tc := range []struct {
+		recordType string
+		value      string
+		expected   string
+	}{
+		{RecordTypeA, "2001:db8::1", "A records require an IPv4 address"},
+		{RecordTypeA, "lb.example.com", "A records require an IPv4 address"},
+		{RecordTypeAAAA, "1.2.3.4", "AAAA records require an IPv6 address"},
+		{RecordTypeCNAME, " ", "CNAME records require a hostname"},
+		{RecordTypeMX, "mail.example.com", `MX records require a preference and a hostname, e.g. "10 mail.example.com"`},
+		{RecordTypeMX, "70000 mail.example.com", `MX records require a preference and a hostname, e.g. "10 mail.example.com"`},
+		{RecordTypeSRV, "10 5 app.example.com", `SRV records require a priority, a weight, a port and a hostname, e.g. "10 5 443 app.example.com"`},
+		{RecordTypeSRV, "10 5 https app.example.com", `SRV records require a priority, a weight, a port and a hostname, e.g. "10 5 443 app.example.com"`},
+	} {
+		_, err := ParseTarget(tc.recordType, tc.value)
+		assert.EqualError(t, err, tc.expected, "%s %q", tc.recordType, tc.value)
+	}
+}
+
+func TestTXTTarget(t *testing.T) {
+	target := ParseTXTTarget(`"v=spf1 include:_spf.example.com " "-all"`)
+	assert.Equal(t, "v=spf1 include:_spf.example.com -all", target.Text())
+	assert.Equal(t, `"v=spf1 include:_spf.example.com " "-all"`, target.String())
+
+	target = ParseTXTTarget(`"heritage=external-dns,external-dns/owner=default"`)
+	assert.Equal(t, []string{"heritage=external-dns,external-dns/owner=default"}, target.Chunks)
+	assert.Equal(t, `"heritage=external-dns,external-dns/owner=default"`, target.String())
+
+	target = TXTTarget{Chunks: []string{"a \"b\"\t\\"}, Quoted: true}
+	assert.Equal(t, `"a \"b\"\009\\"`, target.String())
+	assert.Equal(t, target, ParseTXTTarget(target.String()))
+}
+
+func TestSplitTXT(t *testing.T) {
+	assert.Equal(t, []string{""}, SplitTXT(""))
+	assert.Equal(t, []string{strings.Repeat("a", 255)}, SplitTXT(strings.Repeat("a", 255)))
+	assert.Equal(t, []string{strings.Repeat("a", 255), strings.Repeat("a", 255), "a"}, SplitTXT(strings.Repeat("a", 511)))
+
+	// multi-byte characters are not split
+	chunks := SplitTXT(strings.Repeat("a", 254) + "é" + "b")
+	assert.Equal(t, []string{strings.Repeat("a", 254), "éb"}, chunks)
+}
+
+func TestChunkTXTTarget(t *testing.T) {
+	key := "v=DKIM1; k=rsa; p=" + strings.Repeat("A", 392)
+
+	chunked := ChunkTXTTarget(key)
+	assert.Equal(t, `"`+key[:255]+`" "`+key[255:]+`"`, chunked)
+	// the chunked target compares equal to the original one
+	assert.Equal(t, CanonicalTarget(RecordTypeTXT, key), CanonicalTarget(RecordTypeTXT, chunked))
+	// chunking is idempotent
+	assert.Equal(t, chunked, ChunkTXTTarget(chunked))
+
+	// quoted strings longer than 255 bytes are split, shorter ones are kept
+	assert.Equal(t, `"v=DKIM1; " "`+key[:255]+`" "`+key[255:]+`"`, ChunkTXTTarget(`"v=DKIM1; " "`+key+`"`))
+
+	// targets without long strings are returned unchanged
+	assert.Equal(t, "v=spf1 -all", ChunkTXTTarget("v=spf1 -all"))
+	assert.Equal(t, `"heritage=external-dns"`, ChunkTXTTarget(`"heritage=external-dns"`))
+}
+
+func TestTypedTargets(t *testing.T) {
+	ep := NewEndpoint("example.com", RecordTypeMX, "10 mx1.example.com", "20 mx2.example.com")
+	targets, err := ep.TypedTargets()
+	require.NoError(t, err)
+	assert.Equal(t, []Target{MXTarget{Preference: 10, Host: "mx1.example.com"}, MXTarget{Preference: 20, Host: "mx2.example.com"}}, targets)
+	assert.Equal(t, ep.Targets, NewTypedTargets(targets...))
+
+	ep = NewEndpoint("app.example.com", RecordTypeA, "1.2.3.4", "lb.example.com")
+	_, err = ep.TypedTargets()
+	assert.EqualError(t, err, `invalid target "lb.example.com" of A record app.example.com: A records require an IPv4 address`)
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation lints the hostnames, targets and TTLs of endpoints, so that records the DNS provider would
+// reject are reported by the sources with a reason instead of failing the provider's changes.
+package validation
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/idna"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// Reasons of the warnings.
+const (
+	ReasonHostnameTooLong = "hostname_too_long"
+	ReasonLabelTooLong    = "label_too_long"
+	ReasonEmptyLabel      = "empty_label"
+	ReasonLabelCharset    = "label_charset"
+	ReasonTrailingDot     = "trailing_dot"
+	ReasonUnicode         = "unicode"
+	ReasonInvalidTarget   = "invalid_target"
+	ReasonTTLOutOfRange   = "ttl_out_of_range"
+)
+
+// Fields of an endpoint a warning is about.
+const (
+	FieldHostname = "hostname"
+	FieldTarget   = "target"
+	FieldTTL      = "ttl"
+)
+
+const (
+	maxHostnameLength = 253
+	maxLabelLength    = 63
+)
+
+// Warning describes a value of an endpoint that is likely rejected by the DNS provider.
+type Warning struct {
+	Reason  string
+	Field   string
+	Value   string
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s %q: %s", w.Field, w.Value, w.Message)
+}
+
+// ttlRange is the range of TTLs accepted by a provider, 0 meaning no limit.
+// The auto TTL is accepted in addition to the range, e.g. 1 for the automatic TTL of Cloudflare.
+type ttlRange struct {
+	min, max, auto int64
+}
+
+// providerTTLRanges holds the TTL limits of the providers that reject TTLs out of their range.
+var providerTTLRanges = map[string]ttlRange{
+	"cloudflare":   {min: 60, max: 86400, auto: 1},
+	"digitalocean": {min: 30},
+}
+
+// Linter lints endpoints against the rules of DNS and of a provider.
+type Linter struct {
+	provider string
+	ttl      ttlRange
+}
+
+// NewLinter returns a Linter for the given provider, e.g. "aws".
+func NewLinter(provider string) *Linter {
+	return &Linter{provider: provider, ttl: providerTTLRanges[provider]}
+}
+
+// Provider returns the provider of the linter.
+func (l *Linter) Provider() string {
+	return l.provider
+}
+
+// Lint returns the warnings of the hostname, the targets and the TTL of the endpoint.
+func (l *Linter) Lint(ep *endpoint.Endpoint) []Warning {
+	warnings := LintHostname(ep.DNSName)
+	for _, target := range ep.Targets {
+		warnings = append(warnings, LintTarget(ep.RecordType, target)...)
+	}
+	if ttl := int64(ep.RecordTTL); ep.RecordTTL.IsConfigured() && ttl != l.ttl.auto {
+		if (l.ttl.min > 0 && ttl < l.ttl.min) || (l.ttl.max > 0 && ttl > l.ttl.max) {
+			warnings = append(warnings, Warning{
+				Reason:  ReasonTTLOutOfRange,
+				Field:   FieldTTL,
+				Value:   strconv.FormatInt(ttl, 10),
+				Message: fmt.Sprintf("the %s provider accepts TTLs %s", l.provider, l.ttl),
+			})
+		}
+	}
+	return warnings
+}
+
+func (r ttlRange) String() string {
+	if r.max == 0 {
+		return fmt.Sprintf("of at least %d seconds", r.min)
+	}
+	return fmt.Sprintf("from %d to %d seconds", r.min, r.max)
+}
+
+// LintHostname returns the warnings of a hostname: its length, the length and characters of its labels,
+// a trailing dot and Unicode characters, which must be converted to punycode.
+func LintHostname(hostname string) []Warning {
+	var warnings []Warning
+	warn := func(reason, message string) {
+		warnings = append(warnings, Warning{Reason: reason, Field: FieldHostname, Value: hostname, Message: message})
+	}
+
+	if strings.HasSuffix(hostname, ".") {
+		warn(ReasonTrailingDot, "hostnames are expected without trailing dot")
+	}
+	name := strings.TrimSuffix(hostname, ".")
+	if !isASCII(name) {
+		ascii, err := idna.Lookup.ToASCII(name)
+		if err != nil {
+			warn(ReasonUnicode, fmt.Sprintf("not a valid internationalized domain name: %v", err))
+			return warnings
+		}
+		warn(ReasonUnicode, fmt.Sprintf("internationalized domain names must be given in punycode, i.e. %s", ascii))
+		name = ascii
+	}
+	if len(name) > maxHostnameLength {
+		warn(ReasonHostnameTooLong, fmt.Sprintf("hostnames must not be longer than %d characters", maxHostnameLength))
+	}
+	for i, label := range strings.Split(name, ".") {
+		switch {
+		case label == "":
+			warn(ReasonEmptyLabel, "hostnames must not contain empty labels")
+		case len(label) > maxLabelLength:
+			warn(ReasonLabelTooLong, fmt.Sprintf("label %q is longer than %d characters", label, maxLabelLength))
+		case label == "*":
+			if i != 0 {
+				warn(ReasonLabelCharset, "a wildcard must be the leftmost label")
+			}
+		case !isValidLabel(label):
+			warn(ReasonLabelCharset, fmt.Sprintf("label %q must consist of letters, digits, hyphens and underscores, and must not start or end with a hyphen", label))
+		}
+	}
+	return warnings
+}
+
+// LintTarget returns the warnings of a target of a record type.
+func LintTarget(recordType, target string) []Warning {
+	invalid := func(message string) []Warning {
+		return []Warning{{Reason: ReasonInvalidTarget, Field: FieldTarget, Value: target, Message: message}}
+	}
+
+	typed, err := endpoint.ParseTarget(recordType, target)
+	if err != nil {
+		return invalid(err.Error())
+	}
+	switch t := typed.(type) {
+	case endpoint.HostnameTarget:
+		if _, err := netip.ParseAddr(t.Hostname); err == nil {
+			return invalid(fmt.Sprintf("%s records require a hostname, not an IP address", recordType))
+		}
+		return targetHostnameWarnings(t.Hostname)
+	case endpoint.MXTarget:
+		return targetHostnameWarnings(t.Host)
+	case endpoint.SRVTarget:
+		return targetHostnameWarnings(t.Host)
+	}
+	return nil
+}
+
+// targetHostnameWarnings lints a hostname in a target, which may be fully qualified.
+func targetHostnameWarnings(hostname string) []Warning {
+	var warnings []Warning
+	for _, w := range LintHostname(strings.TrimSuffix(hostname, ".")) {
+		w.Field = FieldTarget
+		w.Value = hostname
+		warnings = append(warnings, w)
+	}
+	return warnings
+}
+
+func isValidLabel(label string) bool {
+	if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+		return false
+	}
+	for _, r := range label {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func reasons(warnings []Warning) []string {
+	var result []string
+	for _, w := range warnings {
+		result = append(result, w.Reason)
+	}
+	return result
+}
+
+func TestLintHostname(t *testing.T) {
+	for _, tc := range []struct {
+		hostname string
+		reasons  []string
+	}{
+		{hostname: "app.example.com"},
+		{hostname: "*.example.com"},
+		{hostname: "_sip._tcp.example.com"},
+		{hostname: "xn--bcher-kva.example.com"},
+		{hostname: "app.example.com.", reasons: []string{ReasonTrailingDot}},
+		{hostname: "app..example.com", reasons: []string{ReasonEmptyLabel}},
+		{hostname: "app.*.example.com", reasons: []string{ReasonLabelCharset}},
+		{hostname: "-app.example.com", reasons: []string{ReasonLabelCharset}},
+		{hostname: "app!.example.com", reasons: []string{ReasonLabelCharset}},
+		{hostname: strings.Repeat("a", 64) + ".example.com", reasons: []string{ReasonLabelTooLong}},
+		{hostname: strings.Repeat("a.", 127) + "com", reasons: []string{ReasonHostnameTooLong}},
+		{hostname: "bücher.example.com", reasons: []string{ReasonUnicode}},
+	} {
+		t.Run(tc.hostname, func(t *testing.T) {
+			assert.Equal(t, tc.reasons, reasons(LintHostname(tc.hostname)))
+		})
+	}
+}
+
+func TestLintHostnameSuggestsPunycode(t *testing.T) {
+	warnings := LintHostname("bücher.example.com")
+	if assert.Len(t, warnings, 1) {
+		assert.Contains(t, warnings[0].Message, "xn--bcher-kva.example.com")
+	}
+}
+
+func TestLintTarget(t *testing.T) {
+	for _, tc := range []struct {
+		recordType string
+		target     string
+		reasons    []string
+	}{
+		{recordType: endpoint.RecordTypeA, target: "192.0.2.1"},
+		{recordType: endpoint.RecordTypeA, target: "2001:db8::1", reasons: []string{ReasonInvalidTarget}},
+		{recordType: endpoint.RecordTypeA, target: "lb.example.com", reasons: []string{ReasonInvalidTarget}},
+		{recordType: endpoint.RecordTypeAAAA, target: "2001:db8::1"},
+		{recordType: endpoint.RecordTypeAAAA, target: "192.0.2.1", reasons: []string{ReasonInvalidTarget}},
+		{recordType: endpoint.RecordTypeCNAME, target: "lb.example.com"},
+		{recordType: endpoint.RecordTypeCNAME, target: "lb.example.com."},
+		{recordType: endpoint.RecordTypeCNAME, target: "192.0.2.1", reasons: []string{ReasonInvalidTarget}},
+		{recordType: endpoint.RecordTypeCNAME, target: "lb_.example..com", reasons: []string{ReasonEmptyLabel}},
+		{recordType: endpoint.RecordTypeMX, target: "10 mail.example.com"},
+		{recordType: endpoint.RecordTypeMX, target: "mail.example.com", reasons: []string{ReasonInvalidTarget}},
+		{recordType: endpoint.RecordTypeSRV, target: "10 5 443 app.example.com"},
+		{recordType: endpoint.RecordTypeSRV, target: "10 5 app.example.com", reasons: []string{ReasonInvalidTarget}},
+		{recordType: endpoint.RecordTypeSRV, target: "10 5 70000 app.example.com", reasons: []string{ReasonInvalidTarget}},
+		{recordType: endpoint.RecordTypeTXT, target: "heritage=external-dns"},
+	} {
+		t.Run(tc.recordType+" "+tc.target, func(t *testing.T) {
+			assert.Equal(t, tc.reasons, reasons(LintTarget(tc.recordType, tc.target)))
+		})
+	}
+}
+
+func TestLinterTTL(t *testing.T) {
+	for _, tc := range []struct {
+		provider string
+		ttl      endpoint.TTL
+		reasons  []string
+	}{
+		{provider: "aws", ttl: 1},
+		{provider: "cloudflare"},
+		{provider: "cloudflare", ttl: 1},
+		{provider: "cloudflare", ttl: 300},
+		{provider: "cloudflare", ttl: 30, reasons: []string{ReasonTTLOutOfRange}},
+		{provider: "cloudflare", ttl: 172800, reasons: []string{ReasonTTLOutOfRange}},
+		{provider: "digitalocean", ttl: 10, reasons: []string{ReasonTTLOutOfRange}},
+		{provider: "digitalocean", ttl: 172800},
+	} {
+		t.Run(tc.provider, func(t *testing.T) {
+			ep := endpoint.NewEndpointWithTTL("app.example.com", endpoint.RecordTypeA, tc.ttl, "192.0.2.1")
+			assert.Equal(t, tc.reasons, reasons(NewLinter(tc.provider).Lint(ep)))
+		})
+	}
+}
@@ -41,6 +41,9 @@ const (
 // Version is the current version of the app, generated at build time
 var Version = "unknown"
 
+// Providers are the names of the DNS providers of the --provider flag.
+var Providers = []string{"akamai", "alibabacloud", "aws", "aws-sd", "azure", "azure-dns", "azure-private-dns", "civo", "cloudflare", "coredns", "desec", "designate", "digitalocean", "dnsimple", "exoscale", "gandi", "git", "godaddy", "google", "hetzner", "ibmcloud", "inmemory", "linode", "ns1", "oci", "ovh", "pdns", "pihole", "plural", "rdns", "rfc2136", "scaleway", "skydns", "tencentcloud", "transip", "ultradns", "webhook"}
+
 // Config is a project-wide configuration
 type Config struct {
 	APIServerURL                       string
@@ -52,6 +55,7 @@ type Config struct {
 	Sources                            []string
 	Namespace                          string
 	AnnotationFilter                   string
+	SourceAnnotationFilters            []string
 	LabelFilter                        string
 	IngressClassNames                  []string
 	FQDNTemplate                       string
@@ -69,11 +73,15 @@ type Config struct {
 	Provider                           string
 	ProviderCacheTime                  time.Duration
 	GoogleProject                      string
+	GoogleCredentialsFile              string
+	GoogleCredentialsJSON              string
 	GoogleBatchChangeSize              int
 	GoogleBatchChangeInterval          time.Duration
 	GoogleZoneVisibility               string
 	DomainFilter                       []string
 	ExcludeDomains                     []string
+	FilterProfiles                     []string
+	SourceFilterProfiles               []string
 	RegexDomainFilter                  *regexp.Regexp
 	RegexDomainExclusion               *regexp.Regexp
 	ZoneNameFilter                     []string
@@ -87,23 +95,45 @@ type Config struct {
 	AWSAssumeRole                      string
 	AWSProfiles                        []string
 	AWSAssumeRoleExternalID            string
+	AWSAssumeRoleSessionDuration       time.Duration
+	AWSAssumeRoleSessionName           string
+	AWSAssumeRoleSessionTags           []string
+	AWSAssumeRoleSourceIdentity        string
+	AWSAssumeRoleChain                 []string
+	AWSZoneRoles                       []string
+	AWSProfileRoles                    []string
+	AWSZoneMatchParentVPCs             []string
+	AWSCredentialsRefreshInterval      time.Duration
+	AWSEndpointURL                     string
+	AWSPartition                       string
+	AWSProxyURL                        string
+	AWSCABundle                        string
 	AWSBatchChangeSize                 int
 	AWSBatchChangeSizeBytes            int
 	AWSBatchChangeSizeValues           int
 	AWSBatchChangeInterval             time.Duration
 	AWSEvaluateTargetHealth            bool
 	AWSAPIRetries                      int
+	AWSAPIRateLimits                   []string
+	AWSAPIDefaultRateLimit             float64
 	AWSPreferCNAME                     bool
+	AWSManageHealthChecks              bool
+	AWSManageTrafficPolicies           bool
 	AWSZoneCacheDuration               time.Duration
 	AWSSDServiceCleanup                bool
+	AWSSDCreateNamespaces              bool
+	AWSSDNamespaceVPC                  string
 	AWSZoneMatchParent                 bool
 	AWSDynamoDBRegion                  string
 	AWSDynamoDBTable                   string
 	AzureConfigFile                    string
 	AzureResourceGroup                 string
 	AzureSubscriptionID                string
+	AzureSubscriptionIDs               []string
+	AzureManagementGroup               string
 	AzureUserAssignedIdentityClientID  string
 	AzureActiveDirectoryAuthorityHost  string
+	AzurePrivateDNSVirtualNetworks     []string
 	CloudflareProxied                  bool
 	CloudflareDNSRecordsPerPage        int
 	CoreDNSPrefix                      string
@@ -119,6 +149,11 @@ type Config struct {
 	OCIZoneScope                       string
 	OCIZoneCacheDuration               time.Duration
 	InMemoryZones                      []string
+	InMemoryFile                       string
+	InMemorySimulation                 []string
+	DNSimpleBatchSize                  int
+	DNSimpleAPIRateLimit               float64
+	LinodeAPIRateLimit                 float64
 	OVHEndpoint                        string
 	OVHApiRateLimit                    int
 	PDNSServer                         string
@@ -133,6 +168,13 @@ type Config struct {
 	TXTOwnerID                         string
 	TXTPrefix                          string
 	TXTSuffix                          string
+	TXTPrefixMigrateFrom               string
+	TXTSuffixMigrateFrom               string
+	TXTMigrate                         bool // set if the naming scheme migrated from is given
+	TXTRecordTTL                       int64
+	TXTRecordComment                   string
+	TXTOwnerDomains                    []string
+	TXTApexRecordName                  string
 	TXTEncryptEnabled                  bool
 	TXTEncryptAESKey                   string `secure:"yes"`
 	Interval                           time.Duration
@@ -142,6 +184,23 @@ type Config struct {
 	UpdateEvents                       bool
 	LogFormat                          string
 	MetricsAddress                     string
+	MetricsTLSCertFile                 string
+	MetricsTLSKeyFile                  string
+	MetricsTLSClientCAFile             string
+	MetricsBasicAuthFile               string
+	HealthzAddress                     string
+	HealthzTLSCertFile                 string
+	HealthzTLSKeyFile                  string
+	HealthzTLSClientCAFile             string
+	HealthzBasicAuthFile               string
+	DebugAddress                       string
+	DebugTLSCertFile                   string
+	DebugTLSKeyFile                    string
+	DebugTLSClientCAFile               string
+	DebugBasicAuthFile                 string
+	SlowCycleProfileThreshold          time.Duration
+	SlowCycleProfileDir                string
+	SlowCycleProfileMaxCaptures        int
 	LogLevel                           string
 	TXTCacheInterval                   time.Duration
 	TXTWildcardReplacement             string
@@ -177,6 +236,7 @@ type Config struct {
 	NS1Endpoint                        string
 	NS1IgnoreSSL                       bool
 	NS1MinTTLSeconds                   int
+	NS1DataSourceID                    string
 	TransIPAccountName                 string
 	TransIPPrivateKeyFile              string
 	DigitalOceanAPIPageSize            int
@@ -186,6 +246,15 @@ type Config struct {
 	GoDaddySecretKey                   string `secure:"yes"`
 	GoDaddyTTL                         int64
 	GoDaddyOTE                         bool
+	GitRepositoryURL                   string
+	GitBranch                          string
+	GitMode                            string
+	GitReviewBranch                    string
+	GitPath                            string
+	GitWorkDir                         string
+	GitZones                           []string
+	GitAuthorName                      string
+	GitAuthorEmail                     string
 	OCPRouterName                      string
 	IBMCloudProxied                    bool
 	IBMCloudConfigFile                 string
@@ -203,156 +272,229 @@ type Config struct {
 	TraefikDisableLegacy               bool
 	TraefikDisableNew                  bool
 	NAT64Networks                      []string
+	OctoDNSExportDir                   string
+	OctoDNSExportZones                 []string
+	TerraformStates                    []string
+	FailoverPrometheusURL              string
+	HealthCheck                        bool
+	HealthCheckInterval                time.Duration
+	HealthCheckTimeout                 time.Duration
+	HealthCheckHealthyThreshold        int
+	HealthCheckUnhealthyThreshold      int
+	GSLBCluster                        string
+	GSLBLeaseDuration                  time.Duration
+	GSLBWeightProperty                 string
+	MaxMemoryEndpoints                 int
+	SyncPerZone                        bool
+	ProviderAPIBudgetPerCycle          int
+	ProviderAPIQuotaHeadroom           float64
+	PreflightCheck                     bool
+	PreflightCheckWrite                bool
+	CreateMissingZones                 bool
+	DeleteEmptyZones                   bool
+	ClusterName                        string
+	UserAgentAttribution               bool
+	ProviderTimeout                    time.Duration
+	ProviderFaultInjection             string
+	UnroutableHostnameCacheTTL         time.Duration
+	SkipDelegatedHostnames             bool
+	PropagationCheckResolvers          []string
+	PropagationCheckInterval           time.Duration
+	PropagationCheckTimeout            time.Duration
+	OutOfSyncCycles                    int
+	TTLRepairInterval                  time.Duration
+	DryRunOutput                       string
+	StatusAPI                          bool
+	StatusAPIReconcileTokenFile        string
+	InternalTargets                    []string
+	SkipUnchangedZones                 bool
+	CloudflareExportListingThreshold   int
+	CloudflareZoneTokensFile           string
+	CloudflareLoadBalancerAccountID    string
+	CloudflareCustomHostnamesZone      string
+	CloudflareRegionalServices         bool
+	CloudflareRegionKey                string
+	CloudflareListingWorkers           int
+	CloudflareCheckTokenScope          bool
+	AWSBoundedListing                  bool
+	SnapshotFile                       string
+	SnapshotMaxAge                     time.Duration
+	RegistryLeaseDuration              time.Duration
+	RegistryLeaseInstance              string
+	RegistryGCInterval                 time.Duration
 }
 
 var defaultConfig = &Config{
-	APIServerURL:                "",
-	KubeConfig:                  "",
-	RequestTimeout:              time.Second * 30,
-	DefaultTargets:              []string{},
-	GlooNamespaces:              []string{"gloo-system"},
-	SkipperRouteGroupVersion:    "zalando.org/v1",
-	Sources:                     nil,
-	Namespace:                   "",
-	AnnotationFilter:            "",
-	LabelFilter:                 labels.Everything().String(),
-	IngressClassNames:           nil,
-	FQDNTemplate:                "",
-	CombineFQDNAndAnnotation:    false,
-	IgnoreHostnameAnnotation:    false,
-	IgnoreIngressTLSSpec:        false,
-	IgnoreIngressRulesSpec:      false,
-	GatewayNamespace:            "",
-	GatewayLabelFilter:          "",
-	Compatibility:               "",
-	PublishInternal:             false,
-	PublishHostIP:               false,
-	ConnectorSourceServer:       "localhost:8080",
-	Provider:                    "",
-	ProviderCacheTime:           0,
-	GoogleProject:               "",
-	GoogleBatchChangeSize:       1000,
-	GoogleBatchChangeInterval:   time.Second,
-	GoogleZoneVisibility:        "",
-	DomainFilter:                []string{},
-	ZoneIDFilter:                []string{},
-	ExcludeDomains:              []string{},
-	RegexDomainFilter:           regexp.MustCompile(""),
-	RegexDomainExclusion:        regexp.MustCompile(""),
-	TargetNetFilter:             []string{},
-	ExcludeTargetNets:           []string{},
-	AlibabaCloudConfigFile:      "/etc/kubernetes/alibaba-cloud.json",
-	AWSZoneType:                 "",
-	AWSZoneTagFilter:            []string{},
-	AWSZoneMatchParent:          false,
-	AWSAssumeRole:               "",
-	AWSAssumeRoleExternalID:     "",
-	AWSBatchChangeSize:          1000,
-	AWSBatchChangeSizeBytes:     32000,
-	AWSBatchChangeSizeValues:    1000,
-	AWSBatchChangeInterval:      time.Second,
-	AWSEvaluateTargetHealth:     true,
-	AWSAPIRetries:               3,
-	AWSPreferCNAME:              false,
-	AWSZoneCacheDuration:        0 * time.Second,
-	AWSSDServiceCleanup:         false,
-	AWSDynamoDBRegion:           "",
-	AWSDynamoDBTable:            "external-dns",
-	AzureConfigFile:             "/etc/kubernetes/azure.json",
-	AzureResourceGroup:          "",
-	AzureSubscriptionID:         "",
-	CloudflareProxied:           false,
-	CloudflareDNSRecordsPerPage: 100,
-	CoreDNSPrefix:               "/skydns/",
-	AkamaiServiceConsumerDomain: "",
-	AkamaiClientToken:           "",
-	AkamaiClientSecret:          "",
-	AkamaiAccessToken:           "",
-	AkamaiEdgercSection:         "",
-	AkamaiEdgercPath:            "",
-	OCIConfigFile:               "/etc/kubernetes/oci.yaml",
-	OCIZoneScope:                "GLOBAL",
-	OCIZoneCacheDuration:        0 * time.Second,
-	InMemoryZones:               []string{},
-	OVHEndpoint:                 "ovh-eu",
-	OVHApiRateLimit:             20,
-	PDNSServer:                  "http://localhost:8081",
-	PDNSServerID:                "localhost",
-	PDNSAPIKey:                  "",
-	PDNSSkipTLSVerify:           false,
-	TLSCA:                       "",
-	TLSClientCert:               "",
-	TLSClientCertKey:            "",
-	Policy:                      "sync",
-	Registry:                    "txt",
-	TXTOwnerID:                  "default",
-	TXTPrefix:                   "",
-	TXTSuffix:                   "",
-	TXTCacheInterval:            0,
-	TXTWildcardReplacement:      "",
-	MinEventSyncInterval:        5 * time.Second,
-	TXTEncryptEnabled:           false,
-	TXTEncryptAESKey:            "",
-	Interval:                    time.Minute,
-	Once:                        false,
-	DryRun:                      false,
-	UpdateEvents:                false,
-	LogFormat:                   "text",
-	MetricsAddress:              ":7979",
-	LogLevel:                    logrus.InfoLevel.String(),
-	ExoscaleAPIEnvironment:      "api",
-	ExoscaleAPIZone:             "ch-gva-2",
-	ExoscaleAPIKey:              "",
-	ExoscaleAPISecret:           "",
-	CRDSourceAPIVersion:         "externaldns.k8s.io/v1alpha1",
-	CRDSourceKind:               "DNSEndpoint",
-	ServiceTypeFilter:           []string{},
-	CFAPIEndpoint:               "",
-	CFUsername:                  "",
-	CFPassword:                  "",
-	RFC2136Host:                 "",
-	RFC2136Port:                 0,
-	RFC2136Zone:                 []string{},
-	RFC2136Insecure:             false,
-	RFC2136GSSTSIG:              false,
-	RFC2136KerberosRealm:        "",
-	RFC2136KerberosUsername:     "",
-	RFC2136KerberosPassword:     "",
-	RFC2136TSIGKeyName:          "",
-	RFC2136TSIGSecret:           "",
-	RFC2136TSIGSecretAlg:        "",
-	RFC2136TAXFR:                true,
-	RFC2136MinTTL:               0,
-	RFC2136BatchChangeSize:      50,
-	RFC2136UseTLS:               false,
-	RFC2136SkipTLSVerify:        false,
-	NS1Endpoint:                 "",
-	NS1IgnoreSSL:                false,
-	TransIPAccountName:          "",
-	TransIPPrivateKeyFile:       "",
-	DigitalOceanAPIPageSize:     50,
-	ManagedDNSRecordTypes:       []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
-	ExcludeDNSRecordTypes:       []string{},
-	GoDaddyAPIKey:               "",
-	GoDaddySecretKey:            "",
-	GoDaddyTTL:                  600,
-	GoDaddyOTE:                  false,
-	IBMCloudProxied:             false,
-	IBMCloudConfigFile:          "/etc/kubernetes/ibmcloud.json",
-	TencentCloudConfigFile:      "/etc/kubernetes/tencent-cloud.json",
-	TencentCloudZoneType:        "",
-	PiholeServer:                "",
-	PiholePassword:              "",
-	PiholeTLSInsecureSkipVerify: false,
-	PluralCluster:               "",
-	PluralProvider:              "",
-	WebhookProviderURL:          "http://localhost:8888",
-	WebhookProviderReadTimeout:  5 * time.Second,
-	WebhookProviderWriteTimeout: 10 * time.Second,
-	WebhookServer:               false,
-	TraefikDisableLegacy:        false,
-	TraefikDisableNew:           false,
-	NAT64Networks:               []string{},
+	APIServerURL:                  "",
+	KubeConfig:                    "",
+	RequestTimeout:                time.Second * 30,
+	DefaultTargets:                []string{},
+	GlooNamespaces:                []string{"gloo-system"},
+	SkipperRouteGroupVersion:      "zalando.org/v1",
+	Sources:                       nil,
+	Namespace:                     "",
+	AnnotationFilter:              "",
+	LabelFilter:                   labels.Everything().String(),
+	IngressClassNames:             nil,
+	FQDNTemplate:                  "",
+	CombineFQDNAndAnnotation:      false,
+	IgnoreHostnameAnnotation:      false,
+	IgnoreIngressTLSSpec:          false,
+	IgnoreIngressRulesSpec:        false,
+	GatewayNamespace:              "",
+	GatewayLabelFilter:            "",
+	Compatibility:                 "",
+	PublishInternal:               false,
+	PublishHostIP:                 false,
+	ConnectorSourceServer:         "localhost:8080",
+	Provider:                      "",
+	ProviderCacheTime:             0,
+	GoogleProject:                 "",
+	GoogleCredentialsFile:         "",
+	GoogleCredentialsJSON:         "",
+	GoogleBatchChangeSize:         1000,
+	GoogleBatchChangeInterval:     time.Second,
+	GoogleZoneVisibility:          "",
+	DomainFilter:                  []string{},
+	ZoneIDFilter:                  []string{},
+	ExcludeDomains:                []string{},
+	RegexDomainFilter:             regexp.MustCompile(""),
+	RegexDomainExclusion:          regexp.MustCompile(""),
+	TargetNetFilter:               []string{},
+	ExcludeTargetNets:             []string{},
+	AlibabaCloudConfigFile:        "/etc/kubernetes/alibaba-cloud.json",
+	AWSZoneType:                   "",
+	AWSZoneTagFilter:              []string{},
+	AWSZoneMatchParent:            false,
+	AWSAssumeRole:                 "",
+	AWSAssumeRoleExternalID:       "",
+	AWSBatchChangeSize:            1000,
+	AWSBatchChangeSizeBytes:       32000,
+	AWSBatchChangeSizeValues:      1000,
+	AWSBatchChangeInterval:        time.Second,
+	AWSEvaluateTargetHealth:       true,
+	AWSAPIRetries:                 3,
+	AWSAPIDefaultRateLimit:        5,
+	ProviderAPIQuotaHeadroom:      0.1,
+	AWSPreferCNAME:                false,
+	AWSZoneCacheDuration:          0 * time.Second,
+	AWSSDServiceCleanup:           false,
+	AWSDynamoDBRegion:             "",
+	AWSDynamoDBTable:              "external-dns",
+	AzureConfigFile:               "/etc/kubernetes/azure.json",
+	AzureResourceGroup:            "",
+	AzureSubscriptionID:           "",
+	AzureManagementGroup:          "",
+	CloudflareProxied:             false,
+	CloudflareDNSRecordsPerPage:   100,
+	CloudflareListingWorkers:      4,
+	CoreDNSPrefix:                 "/skydns/",
+	AkamaiServiceConsumerDomain:   "",
+	AkamaiClientToken:             "",
+	AkamaiClientSecret:            "",
+	AkamaiAccessToken:             "",
+	AkamaiEdgercSection:           "",
+	AkamaiEdgercPath:              "",
+	OCIConfigFile:                 "/etc/kubernetes/oci.yaml",
+	OCIZoneScope:                  "GLOBAL",
+	OCIZoneCacheDuration:          0 * time.Second,
+	InMemoryZones:                 []string{},
+	OVHEndpoint:                   "ovh-eu",
+	OVHApiRateLimit:               20,
+	PDNSServer:                    "http://localhost:8081",
+	PDNSServerID:                  "localhost",
+	PDNSAPIKey:                    "",
+	PDNSSkipTLSVerify:             false,
+	TLSCA:                         "",
+	TLSClientCert:                 "",
+	TLSClientCertKey:              "",
+	Policy:                        "sync",
+	Registry:                      "txt",
+	TXTOwnerID:                    "default",
+	TXTPrefix:                     "",
+	TXTSuffix:                     "",
+	TXTCacheInterval:              0,
+	TXTWildcardReplacement:        "",
+	TXTApexRecordName:             "_external-dns",
+	MinEventSyncInterval:          5 * time.Second,
+	TXTEncryptEnabled:             false,
+	TXTEncryptAESKey:              "",
+	Interval:                      time.Minute,
+	Once:                          false,
+	DryRun:                        false,
+	DryRunOutput:                  "log",
+	UpdateEvents:                  false,
+	LogFormat:                     "text",
+	MetricsAddress:                ":7979",
+	LogLevel:                      logrus.InfoLevel.String(),
+	ExoscaleAPIEnvironment:        "api",
+	ExoscaleAPIZone:               "ch-gva-2",
+	ExoscaleAPIKey:                "",
+	ExoscaleAPISecret:             "",
+	CRDSourceAPIVersion:           "externaldns.k8s.io/v1alpha1",
+	CRDSourceKind:                 "DNSEndpoint",
+	ServiceTypeFilter:             []string{},
+	CFAPIEndpoint:                 "",
+	CFUsername:                    "",
+	CFPassword:                    "",
+	RFC2136Host:                   "",
+	RFC2136Port:                   0,
+	RFC2136Zone:                   []string{},
+	RFC2136Insecure:               false,
+	RFC2136GSSTSIG:                false,
+	RFC2136KerberosRealm:          "",
+	RFC2136KerberosUsername:       "",
+	RFC2136KerberosPassword:       "",
+	RFC2136TSIGKeyName:            "",
+	RFC2136TSIGSecret:             "",
+	RFC2136TSIGSecretAlg:          "",
+	RFC2136TAXFR:                  true,
+	RFC2136MinTTL:                 0,
+	RFC2136BatchChangeSize:        50,
+	RFC2136UseTLS:                 false,
+	RFC2136SkipTLSVerify:          false,
+	NS1Endpoint:                   "",
+	NS1IgnoreSSL:                  false,
+	TransIPAccountName:            "",
+	TransIPPrivateKeyFile:         "",
+	DigitalOceanAPIPageSize:       50,
+	ManagedDNSRecordTypes:         []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
+	ExcludeDNSRecordTypes:         []string{},
+	GoDaddyAPIKey:                 "",
+	GoDaddySecretKey:              "",
+	GoDaddyTTL:                    600,
+	GoDaddyOTE:                    false,
+	GitRepositoryURL:              "",
+	GitBranch:                     "main",
+	GitMode:                       "branch",
+	GitReviewBranch:               "external-dns/changes",
+	GitPath:                       "zones",
+	GitWorkDir:                    "/tmp/external-dns-git",
+	GitZones:                      []string{},
+	GitAuthorName:                 "external-dns",
+	GitAuthorEmail:                "external-dns@localhost",
+	IBMCloudProxied:               false,
+	IBMCloudConfigFile:            "/etc/kubernetes/ibmcloud.json",
+	TencentCloudConfigFile:        "/etc/kubernetes/tencent-cloud.json",
+	TencentCloudZoneType:          "",
+	PiholeServer:                  "",
+	PiholePassword:                "",
+	PiholeTLSInsecureSkipVerify:   false,
+	PluralCluster:                 "",
+	PluralProvider:                "",
+	WebhookProviderURL:            "http://localhost:8888",
+	WebhookProviderReadTimeout:    5 * time.Second,
+	WebhookProviderWriteTimeout:   10 * time.Second,
+	WebhookServer:                 false,
+	TraefikDisableLegacy:          false,
+	TraefikDisableNew:             false,
+	NAT64Networks:                 []string{},
+	HealthCheckInterval:           10 * time.Second,
+	HealthCheckTimeout:            5 * time.Second,
+	HealthCheckHealthyThreshold:   2,
+	HealthCheckUnhealthyThreshold: 3,
+	GSLBLeaseDuration:             5 * time.Minute,
+	GSLBWeightProperty:            "aws/weight",
 }
 
 // NewConfig returns new Config object
@@ -414,10 +556,11 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("skipper-routegroup-groupversion", "The resource version for skipper routegroup").Default(source.DefaultRoutegroupVersion).StringVar(&cfg.SkipperRouteGroupVersion)
 
 	// Flags related to processing source
-	app.Flag("source", "The resource types that are queried for endpoints; specify multiple times for multiple sources (required, options: service, ingress, node, pod, fake, connector, gateway-httproute, gateway-grpcroute, gateway-tlsroute, gateway-tcproute, gateway-udproute, istio-gateway, istio-virtualservice, cloudfoundry, contour-httpproxy, gloo-proxy, crd, empty, skipper-routegroup, openshift-route, ambassador-host, kong-tcpingress, f5-virtualserver, traefik-proxy)").Required().PlaceHolder("source").EnumsVar(&cfg.Sources, "service", "ingress", "node", "pod", "gateway-httproute", "gateway-grpcroute", "gateway-tlsroute", "gateway-tcproute", "gateway-udproute", "istio-gateway", "istio-virtualservice", "cloudfoundry", "contour-httpproxy", "gloo-proxy", "fake", "connector", "crd", "empty", "skipper-routegroup", "openshift-route", "ambassador-host", "kong-tcpingress", "f5-virtualserver", "traefik-proxy")
+	app.Flag("source", "The resource types that are queried for endpoints; specify multiple times for multiple sources (required, options: service, ingress, node, pod, fake, connector, gateway-httproute, gateway-grpcroute, gateway-tlsroute, gateway-tcproute, gateway-udproute, istio-gateway, istio-virtualservice, cloudfoundry, contour-httpproxy, gloo-proxy, crd, empty, skipper-routegroup, openshift-route, ambassador-host, kong-tcpingress, f5-virtualserver, traefik-proxy, traffic-policy)").Required().PlaceHolder("source").EnumsVar(&cfg.Sources, "service", "ingress", "node", "pod", "gateway-httproute", "gateway-grpcroute", "gateway-tlsroute", "gateway-tcproute", "gateway-udproute", "istio-gateway", "istio-virtualservice", "cloudfoundry", "contour-httpproxy", "gloo-proxy", "fake", "connector", "crd", "empty", "skipper-routegroup", "openshift-route", "ambassador-host", "kong-tcpingress", "f5-virtualserver", "traefik-proxy", "traffic-policy")
 	app.Flag("openshift-router-name", "if source is openshift-route then you can pass the ingress controller name. Based on this name external-dns will select the respective router from the route status and map that routerCanonicalHostname to the route host while creating a CNAME record.").StringVar(&cfg.OCPRouterName)
 	app.Flag("namespace", "Limit resources queried for endpoints to a specific namespace (default: all namespaces)").Default(defaultConfig.Namespace).StringVar(&cfg.Namespace)
 	app.Flag("annotation-filter", "Filter resources queried for endpoints by annotation, using label selector semantics").Default(defaultConfig.AnnotationFilter).StringVar(&cfg.AnnotationFilter)
+	app.Flag("source-annotation-filter", "Override the --annotation-filter of a source, as source=selector, e.g. `ingress=kubernetes.io/ingress.class in (public,dmz)`; specify multiple times for multiple sources (optional)").StringsVar(&cfg.SourceAnnotationFilters)
 	app.Flag("label-filter", "Filter resources queried for endpoints by label selector; currently supported by source types crd, gateway-httproute, gateway-grpcroute, gateway-tlsroute, gateway-tcproute, gateway-udproute, ingress, node, openshift-route, service and ambassador-host").Default(defaultConfig.LabelFilter).StringVar(&cfg.LabelFilter)
 	app.Flag("ingress-class", "Require an Ingress to have this class name (defaults to any class; specify multiple times to allow more than one class)").StringsVar(&cfg.IngressClassNames)
 	app.Flag("fqdn-template", "A templated string that's used to generate DNS names from sources that don't define a hostname themselves, or to add a hostname suffix when paired with the fake source (optional). Accepts comma separated list for multiple global FQDN.").Default(defaultConfig.FQDNTemplate).StringVar(&cfg.FQDNTemplate)
@@ -436,25 +579,38 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("crd-source-kind", "Kind of the CRD for the crd source in API group and version specified by crd-source-apiversion").Default(defaultConfig.CRDSourceKind).StringVar(&cfg.CRDSourceKind)
 	app.Flag("service-type-filter", "The service types to take care about (default: all, expected: ClusterIP, NodePort, LoadBalancer or ExternalName)").StringsVar(&cfg.ServiceTypeFilter)
 	app.Flag("managed-record-types", "Record types to manage; specify multiple times to include many; (default: A, AAAA, CNAME) (supported records: A, AAAA, CNAME, NS, SRV, TXT)").Default("A", "AAAA", "CNAME").StringsVar(&cfg.ManagedDNSRecordTypes)
-	app.Flag("exclude-record-types", "Record types to exclude from management; specify multiple times to exclude many; (optional)").Default().StringsVar(&cfg.ExcludeDNSRecordTypes)
+	app.Flag("exclude-record-types", "Record types to exclude from management, which are also hidden from the registry and, with the azure provider, not listed; specify multiple times to exclude many; (optional)").Default().StringsVar(&cfg.ExcludeDNSRecordTypes)
 	app.Flag("default-targets", "Set globally default host/IP that will apply as a target instead of source addresses. Specify multiple times for multiple targets (optional)").StringsVar(&cfg.DefaultTargets)
+	app.Flag("internal-target", "Set the target, e.g. an internal load balancer, of the hostnames of the internal-hostname annotation of ingresses, gateway routes and DNSEndpoints instead of the cluster IPs of their backend services. Specify multiple times for multiple targets (optional)").StringsVar(&cfg.InternalTargets)
 	app.Flag("target-net-filter", "Limit possible targets by a net filter; specify multiple times for multiple possible nets (optional)").StringsVar(&cfg.TargetNetFilter)
 	app.Flag("exclude-target-net", "Exclude target nets (optional)").StringsVar(&cfg.ExcludeTargetNets)
 	app.Flag("traefik-disable-legacy", "Disable listeners on Resources under the traefik.containo.us API Group").Default(strconv.FormatBool(defaultConfig.TraefikDisableLegacy)).BoolVar(&cfg.TraefikDisableLegacy)
 	app.Flag("traefik-disable-new", "Disable listeners on Resources under the traefik.io API Group").Default(strconv.FormatBool(defaultConfig.TraefikDisableNew)).BoolVar(&cfg.TraefikDisableNew)
+	app.Flag("failover-prometheus-url", "When set, evaluates the PromQL expressions of the failover-query annotation against this Prometheus server and removes unhealthy targets (optional)").Default(defaultConfig.FailoverPrometheusURL).StringVar(&cfg.FailoverPrometheusURL)
+	app.Flag("health-check", "When enabled, actively probes the targets of resources with the health-check annotation and withholds unhealthy targets (default: disabled)").BoolVar(&cfg.HealthCheck)
+	app.Flag("health-check-interval", "The interval between two probes of a target in duration format (default: 10s)").Default(defaultConfig.HealthCheckInterval.String()).DurationVar(&cfg.HealthCheckInterval)
+	app.Flag("health-check-timeout", "The timeout of a single probe in duration format (default: 5s)").Default(defaultConfig.HealthCheckTimeout.String()).DurationVar(&cfg.HealthCheckTimeout)
+	app.Flag("health-check-healthy-threshold", "The number of consecutive successful probes after which an unhealthy target is published again (default: 2)").Default(strconv.Itoa(defaultConfig.HealthCheckHealthyThreshold)).IntVar(&cfg.HealthCheckHealthyThreshold)
+	app.Flag("health-check-unhealthy-threshold", "The number of consecutive failed probes after which a target is withheld (default: 3)").Default(strconv.Itoa(defaultConfig.HealthCheckUnhealthyThreshold)).IntVar(&cfg.HealthCheckUnhealthyThreshold)
+	app.Flag("gslb-cluster", "When set, enables the GSLB mode: records of resources with the gslb annotation are weighted across all clusters running ExternalDNS with the same owner ID; the unique name of this cluster (optional)").Default(defaultConfig.GSLBCluster).StringVar(&cfg.GSLBCluster)
+	app.Flag("gslb-lease-duration", "When using the GSLB mode, the time after which the hints of a cluster that stopped publishing them are ignored (default: 5m)").Default(defaultConfig.GSLBLeaseDuration.String()).DurationVar(&cfg.GSLBLeaseDuration)
+	app.Flag("gslb-weight-property", "When using the GSLB mode, the provider specific property holding the weight of a record (default: aws/weight)").Default(defaultConfig.GSLBWeightProperty).StringVar(&cfg.GSLBWeightProperty)
 	app.Flag("nat64-networks", "Adding an A record for each AAAA record in NAT64-enabled networks; specify multiple times for multiple possible nets (optional)").StringsVar(&cfg.NAT64Networks)
 
 	// Flags related to providers
-	providers := []string{"akamai", "alibabacloud", "aws", "aws-sd", "azure", "azure-dns", "azure-private-dns", "civo", "cloudflare", "coredns", "designate", "digitalocean", "dnsimple", "exoscale", "gandi", "godaddy", "google", "ibmcloud", "inmemory", "linode", "ns1", "oci", "ovh", "pdns", "pihole", "plural", "rdns", "rfc2136", "scaleway", "skydns", "tencentcloud", "transip", "ultradns", "webhook"}
-	app.Flag("provider", "The DNS provider where the DNS records will be created (required, options: "+strings.Join(providers, ", ")+")").Required().PlaceHolder("provider").EnumVar(&cfg.Provider, providers...)
+	app.Flag("provider", "The DNS provider where the DNS records will be created (required, options: "+strings.Join(Providers, ", ")+")").Required().PlaceHolder("provider").EnumVar(&cfg.Provider, Providers...)
 	app.Flag("provider-cache-time", "The time to cache the DNS provider record list requests.").Default(defaultConfig.ProviderCacheTime.String()).DurationVar(&cfg.ProviderCacheTime)
 	app.Flag("domain-filter", "Limit possible target zones by a domain suffix; specify multiple times for multiple domains (optional)").Default("").StringsVar(&cfg.DomainFilter)
 	app.Flag("exclude-domains", "Exclude subdomains (optional)").Default("").StringsVar(&cfg.ExcludeDomains)
+	app.Flag("filter-profile", "Define a named filter profile restricting the endpoints of the sources bound to it, as name:key=value[,key=value...] with the keys domain, exclude-domain and record-type, e.g. `infra:domain=infra.example.com,record-type=A`; specify multiple times for multiple profiles (optional)").StringsVar(&cfg.FilterProfiles)
+	app.Flag("source-filter-profile", "Bind a source to a filter profile, as a comma-separated list of source=profile pairs, e.g. `node=infra`; specify multiple times for multiple lists (optional)").StringsVar(&cfg.SourceFilterProfiles)
 	app.Flag("regex-domain-filter", "Limit possible domains and target zones by a Regex filter; Overrides domain-filter (optional)").Default(defaultConfig.RegexDomainFilter.String()).RegexpVar(&cfg.RegexDomainFilter)
 	app.Flag("regex-domain-exclusion", "Regex filter that excludes domains and target zones matched by regex-domain-filter (optional)").Default(defaultConfig.RegexDomainExclusion.String()).RegexpVar(&cfg.RegexDomainExclusion)
 	app.Flag("zone-name-filter", "Filter target zones by zone domain (For now, only AzureDNS provider is using this flag); specify multiple times for multiple zones (optional)").Default("").StringsVar(&cfg.ZoneNameFilter)
 	app.Flag("zone-id-filter", "Filter target zones by hosted zone id; specify multiple times for multiple zones (optional)").Default("").StringsVar(&cfg.ZoneIDFilter)
 	app.Flag("google-project", "When using the Google provider, current project is auto-detected, when running on GCP. Specify other project with this. Must be specified when running outside GCP.").Default(defaultConfig.GoogleProject).StringVar(&cfg.GoogleProject)
+	app.Flag("google-credentials-file", "When using the Google provider, load the credentials from this file, a service account key or the external account configuration of a workload identity federation, e.g. to authenticate with AWS or OIDC tokens outside GCP (default: the application default credentials)").Default(defaultConfig.GoogleCredentialsFile).StringVar(&cfg.GoogleCredentialsFile)
+	app.Flag("google-credentials-json", "When using the Google provider, use these credentials instead of a credentials file, e.g. from the EXTERNAL_DNS_GOOGLE_CREDENTIALS_JSON environment variable").Default(defaultConfig.GoogleCredentialsJSON).StringVar(&cfg.GoogleCredentialsJSON)
 	app.Flag("google-batch-change-size", "When using the Google provider, set the maximum number of changes that will be applied in each batch.").Default(strconv.Itoa(defaultConfig.GoogleBatchChangeSize)).IntVar(&cfg.GoogleBatchChangeSize)
 	app.Flag("google-batch-change-interval", "When using the Google provider, set the interval between batch changes.").Default(defaultConfig.GoogleBatchChangeInterval.String()).DurationVar(&cfg.GoogleBatchChangeInterval)
 	app.Flag("google-zone-visibility", "When using the Google provider, filter for zones with this visibility (optional, options: public, private)").Default(defaultConfig.GoogleZoneVisibility).EnumVar(&cfg.GoogleZoneVisibility, "", "public", "private")
@@ -465,25 +621,56 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("aws-profile", "When using the AWS provider, name of the profile to use").Default("").StringsVar(&cfg.AWSProfiles)
 	app.Flag("aws-assume-role", "When using the AWS API, assume this IAM role. Useful for hosted zones in another AWS account. Specify the full ARN, e.g. `arn:aws:iam::123455567:role/external-dns` (optional)").Default(defaultConfig.AWSAssumeRole).StringVar(&cfg.AWSAssumeRole)
 	app.Flag("aws-assume-role-external-id", "When using the AWS API and assuming a role then specify this external ID` (optional)").Default(defaultConfig.AWSAssumeRoleExternalID).StringVar(&cfg.AWSAssumeRoleExternalID)
+	app.Flag("aws-assume-role-session-duration", "When using the AWS API and assuming a role, the duration of the role sessions, between 15m and the maximum session duration of the role (default: 15m)").Default("0s").DurationVar(&cfg.AWSAssumeRoleSessionDuration)
+	app.Flag("aws-assume-role-session-name", "When using the AWS API and assuming a role, the name of the role sessions, e.g. to identify ExternalDNS in CloudTrail; {cluster}, {owner} and {pod} are replaced with the cluster name, the owner ID and the hostname, e.g. `external-dns-{owner}` (default: generated by the SDK)").Default("").StringVar(&cfg.AWSAssumeRoleSessionName)
+	app.Flag("aws-assume-role-session-tag", "When using the AWS API and assuming a role, tag the role sessions, e.g. for the attribute-based access control of the policies of the role, as a comma-separated list of key=value pairs, e.g. `team=dns,cluster=prod`; specify multiple times for multiple lists (optional)").StringsVar(&cfg.AWSAssumeRoleSessionTags)
+	app.Flag("aws-assume-role-source-identity", "When using the AWS API and assuming a role, the source identity of the role sessions, attributing their actions in CloudTrail, which is kept by the sessions of the roles assumed with their credentials (optional)").Default("").StringVar(&cfg.AWSAssumeRoleSourceIdentity)
+	app.Flag("aws-assume-role-chain", "When using the AWS API, assume these IAM roles one after the other, each with the credentials of the previous one, before assuming the other roles with the credentials of the last one, e.g. to hop through an intermediate account, as a comma-separated list of role ARNs; specify multiple times to extend the chain (optional)").StringsVar(&cfg.AWSAssumeRoleChain)
+	app.Flag("aws-zone-role", "When using the AWS provider, manage the hosted zone with the IAM role assumed with the default credentials instead of the other credentials, as a comma-separated list of zone ID=role ARN pairs, e.g. `Z123=arn:aws:iam::111:role/dns,Z456=arn:aws:iam::222:role/dns`. Useful for hosted zones in several other AWS accounts; specify multiple times for multiple lists (optional)").StringsVar(&cfg.AWSZoneRoles)
+	app.Flag("aws-profile-role", "When using the AWS provider, assume an IAM role with the credentials of an AWS profile instead of the role of --aws-assume-role, as a comma-separated list of profile=role ARN pairs, e.g. `prod=arn:aws:iam::111:role/dns,staging=arn:aws:iam::222:role/dns`; specify multiple times for multiple lists (optional)").StringsVar(&cfg.AWSProfileRoles)
+	app.Flag("aws-credentials-refresh-interval", "When using the AWS API, re-load the credentials at this interval at the latest, e.g. to use the rotated static credentials of a credentials file mounted from a Secret without restarting (default: disabled)").Default("0s").DurationVar(&cfg.AWSCredentialsRefreshInterval)
+	app.Flag("aws-endpoint-url", "When using the AWS API, send the Route53 and STS requests to this URL instead of the one of the region, e.g. of a Route53-compatible emulator such as LocalStack (optional)").Default("").StringVar(&cfg.AWSEndpointURL)
+	app.Flag("aws-partition", "When using the AWS API, the partition of the region, which defaults to the main region of the partition, e.g. us-gov-west-1 for aws-us-gov (optional, options: aws, aws-cn, aws-us-gov, aws-iso, aws-iso-b)").Default("").EnumVar(&cfg.AWSPartition, "", "aws", "aws-cn", "aws-us-gov", "aws-iso", "aws-iso-b")
+	app.Flag("aws-proxy-url", "When using the AWS API, send the requests through the HTTP proxy of this URL, e.g. `http://proxy.example.com:3128` (default: the proxy of the HTTPS_PROXY and NO_PROXY environment variables)").Default("").StringVar(&cfg.AWSProxyURL)
+	app.Flag("aws-ca-bundle", "When using the AWS API, a PEM file of the CA certificates trusted in addition to the system ones, e.g. the CA of a TLS-inspecting proxy (optional)").Default("").StringVar(&cfg.AWSCABundle)
 	app.Flag("aws-batch-change-size", "When using the AWS provider, set the maximum number of changes that will be applied in each batch.").Default(strconv.Itoa(defaultConfig.AWSBatchChangeSize)).IntVar(&cfg.AWSBatchChangeSize)
 	app.Flag("aws-batch-change-size-bytes", "When using the AWS provider, set the maximum byte size that will be applied in each batch.").Default(strconv.Itoa(defaultConfig.AWSBatchChangeSizeBytes)).IntVar(&cfg.AWSBatchChangeSizeBytes)
 	app.Flag("aws-batch-change-size-values", "When using the AWS provider, set the maximum total record values that will be applied in each batch.").Default(strconv.Itoa(defaultConfig.AWSBatchChangeSizeValues)).IntVar(&cfg.AWSBatchChangeSizeValues)
 	app.Flag("aws-batch-change-interval", "When using the AWS provider, set the interval between batch changes.").Default(defaultConfig.AWSBatchChangeInterval.String()).DurationVar(&cfg.AWSBatchChangeInterval)
 	app.Flag("aws-evaluate-target-health", "When using the AWS provider, set whether to evaluate the health of a DNS target (default: enabled, disable with --no-aws-evaluate-target-health)").Default(strconv.FormatBool(defaultConfig.AWSEvaluateTargetHealth)).BoolVar(&cfg.AWSEvaluateTargetHealth)
 	app.Flag("aws-api-retries", "When using the AWS API, set the maximum number of retries before giving up.").Default(strconv.Itoa(defaultConfig.AWSAPIRetries)).IntVar(&cfg.AWSAPIRetries)
+	app.Flag("aws-api-rate-limit", "When using the AWS API, limit the requests per second of the operation, as a comma-separated list of operation=rate pairs, e.g. `ListHostedZones=2,ChangeResourceRecordSets=4`; the rate is halved when a request is throttled and increased back to the limit by the following requests; specify multiple times for multiple lists (optional)").StringsVar(&cfg.AWSAPIRateLimits)
+	app.Flag("aws-api-default-rate-limit", "When using the AWS API, limit the requests per second of the operations without --aws-api-rate-limit, the rate being adapted to throttling the same way; 0 leaves them unpaced").Default(strconv.FormatFloat(defaultConfig.AWSAPIDefaultRateLimit, 'f', -1, 64)).Float64Var(&cfg.AWSAPIDefaultRateLimit)
 	app.Flag("aws-prefer-cname", "When using the AWS provider, prefer using CNAME instead of ALIAS (default: disabled)").BoolVar(&cfg.AWSPreferCNAME)
+	app.Flag("aws-manage-health-checks", "When using the AWS provider, create, update and delete the health checks of the records annotated with a health check protocol (default: disabled)").BoolVar(&cfg.AWSManageHealthChecks)
+	app.Flag("aws-manage-traffic-policies", "When using the AWS provider, create, update and delete the traffic policies and policy instances of the records with a traffic policy document (default: disabled)").BoolVar(&cfg.AWSManageTrafficPolicies)
 	app.Flag("aws-zones-cache-duration", "When using the AWS provider, set the zones list cache TTL (0s to disable).").Default(defaultConfig.AWSZoneCacheDuration.String()).DurationVar(&cfg.AWSZoneCacheDuration)
+	app.Flag("aws-bounded-listing", "When using the AWS provider, list only the records under the domain filters that are subdomains of a zone instead of the whole zone; with the txt registry, requires a --txt-prefix ending with a dot (default: disabled)").BoolVar(&cfg.AWSBoundedListing)
 	app.Flag("aws-zone-match-parent", "Expand limit possible target by sub-domains (default: disabled)").BoolVar(&cfg.AWSZoneMatchParent)
+	app.Flag("aws-zone-match-parent-vpc", "When using the AWS provider, only consider the private hosted zones associated with one of these VPCs, including those of other accounts associated through Route53 Profiles or cross-account VPC associations, as a comma-separated list of VPC IDs optionally prefixed with their region, e.g. `vpc-111,eu-west-1/vpc-222`, or auto for the VPC of the instance looked up in the instance metadata; specify multiple times for multiple lists (optional)").StringsVar(&cfg.AWSZoneMatchParentVPCs)
 	app.Flag("aws-sd-service-cleanup", "When using the AWS CloudMap provider, delete empty Services without endpoints (default: disabled)").BoolVar(&cfg.AWSSDServiceCleanup)
+	app.Flag("aws-sd-create-namespaces", "When using the AWS CloudMap provider, create the namespaces of the domain filter which the records require and which don't exist, tagged with the owner ID (default: disabled)").BoolVar(&cfg.AWSSDCreateNamespaces)
+	app.Flag("aws-sd-namespace-vpc", "When using the AWS CloudMap provider, create private namespaces in this VPC with --aws-sd-create-namespaces instead of public ones (optional)").StringVar(&cfg.AWSSDNamespaceVPC)
 	app.Flag("azure-config-file", "When using the Azure provider, specify the Azure configuration file (required when --provider=azure)").Default(defaultConfig.AzureConfigFile).StringVar(&cfg.AzureConfigFile)
 	app.Flag("azure-resource-group", "When using the Azure provider, override the Azure resource group to use (optional)").Default(defaultConfig.AzureResourceGroup).StringVar(&cfg.AzureResourceGroup)
 	app.Flag("azure-subscription-id", "When using the Azure provider, override the Azure subscription to use (optional)").Default(defaultConfig.AzureSubscriptionID).StringVar(&cfg.AzureSubscriptionID)
+	app.Flag("azure-subscription-ids", "When using the Azure or Azure Private DNS provider, manage the zones of these subscriptions in one run, routing the changes to the subscription of their zone, as a comma-separated list of subscription IDs; specify multiple times for multiple lists (optional)").StringsVar(&cfg.AzureSubscriptionIDs)
+	app.Flag("azure-management-group", "When using the Azure or Azure Private DNS provider, manage the zones of the subscriptions of this management group and of its descendant management groups (optional)").Default(defaultConfig.AzureManagementGroup).StringVar(&cfg.AzureManagementGroup)
 	app.Flag("azure-user-assigned-identity-client-id", "When using the Azure provider, override the client id of user assigned identity in config file (optional)").Default("").StringVar(&cfg.AzureUserAssignedIdentityClientID)
+	app.Flag("azure-private-dns-virtual-network", "When using the Azure Private DNS provider, link the private zones to these virtual networks, e.g. zones created out of band, and delete the links created to the virtual networks no longer specified, after each synchronization and not in dry-run, as a comma-separated list of virtual network resource IDs; specify multiple times for multiple lists (optional)").StringsVar(&cfg.AzurePrivateDNSVirtualNetworks)
 	app.Flag("tencent-cloud-config-file", "When using the Tencent Cloud provider, specify the Tencent Cloud configuration file (required when --provider=tencentcloud)").Default(defaultConfig.TencentCloudConfigFile).StringVar(&cfg.TencentCloudConfigFile)
 	app.Flag("tencent-cloud-zone-type", "When using the Tencent Cloud provider, filter for zones with visibility (optional, options: public, private)").Default(defaultConfig.TencentCloudZoneType).EnumVar(&cfg.TencentCloudZoneType, "", "public", "private")
 
 	app.Flag("cloudflare-proxied", "When using the Cloudflare provider, specify if the proxy mode must be enabled (default: disabled)").BoolVar(&cfg.CloudflareProxied)
 	app.Flag("cloudflare-dns-records-per-page", "When using the Cloudflare provider, specify how many DNS records listed per page, max possible 5,000 (default: 100)").Default(strconv.Itoa(defaultConfig.CloudflareDNSRecordsPerPage)).IntVar(&cfg.CloudflareDNSRecordsPerPage)
+	app.Flag("cloudflare-export-listing-threshold", "When using the Cloudflare provider, list the zones with at least this many records with the zone export in a single request instead of one request per page, and look up the records to change by name; 0 disables the export (default: 0)").Default("0").IntVar(&cfg.CloudflareExportListingThreshold)
+	app.Flag("cloudflare-zone-tokens-file", "When using the Cloudflare provider, the path of a YAML file listing API tokens with the names of the zones each is used for; the scopes of the tokens are checked at startup and the other zones use CF_API_TOKEN, if set (optional)").Default("").StringVar(&cfg.CloudflareZoneTokensFile)
+	app.Flag("cloudflare-load-balancer-account-id", "When using the Cloudflare provider, the ID of the account of the origin pools and monitors of the load balancers serving the targets of the endpoints annotated with external-dns.alpha.kubernetes.io/cloudflare-load-balancer; requires CF_API_TOKEN or CF_API_KEY (optional, disabled by default)").Default("").StringVar(&cfg.CloudflareLoadBalancerAccountID)
+	app.Flag("cloudflare-custom-hostnames-zone", "When using the Cloudflare provider, the zone whose custom hostnames, also known as Cloudflare for SaaS, serve the hostnames outside of the Cloudflare zones annotated with external-dns.alpha.kubernetes.io/cloudflare-custom-hostname; requires CF_API_TOKEN or CF_API_KEY (optional, disabled by default)").Default("").StringVar(&cfg.CloudflareCustomHostnamesZone)
+	app.Flag("cloudflare-regional-services", "When using the Cloudflare provider, restrict the hostnames annotated with external-dns.alpha.kubernetes.io/cloudflare-region-key to the data centers of the region with regional hostnames, also known as Cloudflare Regional Services; requires CF_API_TOKEN or CF_API_KEY (default: disabled)").BoolVar(&cfg.CloudflareRegionalServices)
+	app.Flag("cloudflare-region-key", "When using the Cloudflare provider with --cloudflare-regional-services, the region key of the hostnames without annotation, e.g. eu (default: all regions)").Default("").StringVar(&cfg.CloudflareRegionKey)
+	app.Flag("cloudflare-listing-workers", "When using the Cloudflare provider, the number of concurrent requests listing the pages of zones and the records of the zones (default: 4)").Default(strconv.Itoa(defaultConfig.CloudflareListingWorkers)).IntVar(&cfg.CloudflareListingWorkers)
+	app.Flag("cloudflare-check-token-scope", "When using the Cloudflare provider, check at startup that the API token is active and can read the records of the zones of the domain filter, failing otherwise (default: disabled)").BoolVar(&cfg.CloudflareCheckTokenScope)
 	app.Flag("coredns-prefix", "When using the CoreDNS provider, specify the prefix name").Default(defaultConfig.CoreDNSPrefix).StringVar(&cfg.CoreDNSPrefix)
 	app.Flag("akamai-serviceconsumerdomain", "When using the Akamai provider, specify the base URL (required when --provider=akamai and edgerc-path not specified)").Default(defaultConfig.AkamaiServiceConsumerDomain).StringVar(&cfg.AkamaiServiceConsumerDomain)
 	app.Flag("akamai-client-token", "When using the Akamai provider, specify the client token (required when --provider=akamai and edgerc-path not specified)").Default(defaultConfig.AkamaiClientToken).StringVar(&cfg.AkamaiClientToken)
@@ -497,6 +684,11 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("oci-auth-instance-principal", "When using the OCI provider, specify whether OCI IAM instance principal authentication should be used (instead of key-based auth via the OCI config file).").Default(strconv.FormatBool(defaultConfig.OCIAuthInstancePrincipal)).BoolVar(&cfg.OCIAuthInstancePrincipal)
 	app.Flag("oci-zones-cache-duration", "When using the OCI provider, set the zones list cache TTL (0s to disable).").Default(defaultConfig.OCIZoneCacheDuration.String()).DurationVar(&cfg.OCIZoneCacheDuration)
 	app.Flag("inmemory-zone", "Provide a list of pre-configured zones for the inmemory provider; specify multiple times for multiple zones (optional)").Default("").StringsVar(&cfg.InMemoryZones)
+	app.Flag("inmemory-file", "Persist the zones and records of the inmemory provider to this JSON file, restoring them at startup (optional)").Default("").StringVar(&cfg.InMemoryFile)
+	app.Flag("inmemory-simulation", "For testing only, simulate the behavior of the API of the zones of the inmemory provider as a comma-separated list of latency:<duration>, page-size:<records>, throttle-rate:<rate> and propagation-delay:<duration>, optionally prefixed with <zone>= for a single zone, e.g. latency:20ms,page-size:100; specify multiple times for multiple zones (default: disabled)").StringsVar(&cfg.InMemorySimulation)
+	app.Flag("dnsimple-batch-size", "When using the DNSimple provider, submit the changes of a zone with the batch change API of DNSimple in requests of at most this many changes, instead of one request per record; requires a DNSimple subscription including batch changes; 0 disables batches (default: 0)").Default("0").IntVar(&cfg.DNSimpleBatchSize)
+	app.Flag("dnsimple-api-rate-limit", "When using the DNSimple provider, limit the requests to the API to this many per second, e.g. 0.5 for 1800 requests per hour; 0 leaves them unpaced (default: 0)").Default("0").Float64Var(&cfg.DNSimpleAPIRateLimit)
+	app.Flag("linode-api-rate-limit", "When using the Linode provider, limit the requests to the API to this many per second, e.g. 0.5 for one request every two seconds; 0 leaves them unpaced (default: 0)").Default("0").Float64Var(&cfg.LinodeAPIRateLimit)
 	app.Flag("ovh-endpoint", "When using the OVH provider, specify the endpoint (default: ovh-eu)").Default(defaultConfig.OVHEndpoint).StringVar(&cfg.OVHEndpoint)
 	app.Flag("ovh-api-rate-limit", "When using the OVH provider, specify the API request rate limit, X operations by seconds (default: 20)").Default(strconv.Itoa(defaultConfig.OVHApiRateLimit)).IntVar(&cfg.OVHApiRateLimit)
 	app.Flag("pdns-server", "When using the PowerDNS/PDNS provider, specify the URL to the pdns server (required when --provider=pdns)").Default(defaultConfig.PDNSServer).StringVar(&cfg.PDNSServer)
@@ -506,6 +698,7 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("ns1-endpoint", "When using the NS1 provider, specify the URL of the API endpoint to target (default: https://api.nsone.net/v1/)").Default(defaultConfig.NS1Endpoint).StringVar(&cfg.NS1Endpoint)
 	app.Flag("ns1-ignoressl", "When using the NS1 provider, specify whether to verify the SSL certificate (default: false)").Default(strconv.FormatBool(defaultConfig.NS1IgnoreSSL)).BoolVar(&cfg.NS1IgnoreSSL)
 	app.Flag("ns1-min-ttl", "Minimal TTL (in seconds) for records. This value will be used if the provided TTL for a service/ingress is lower than this.").IntVar(&cfg.NS1MinTTLSeconds)
+	app.Flag("ns1-data-source-id", "When using the NS1 provider, specify the ID of the API data source to create the data feeds of the answers with the ns1-up annotation set to feed in (optional)").Default(defaultConfig.NS1DataSourceID).StringVar(&cfg.NS1DataSourceID)
 	app.Flag("digitalocean-api-page-size", "Configure the page size used when querying the DigitalOcean API.").Default(strconv.Itoa(defaultConfig.DigitalOceanAPIPageSize)).IntVar(&cfg.DigitalOceanAPIPageSize)
 	app.Flag("ibmcloud-config-file", "When using the IBM Cloud provider, specify the IBM Cloud configuration file (required when --provider=ibmcloud").Default(defaultConfig.IBMCloudConfigFile).StringVar(&cfg.IBMCloudConfigFile)
 	app.Flag("ibmcloud-proxied", "When using the IBM provider, specify if the proxy mode must be enabled (default: disabled)").BoolVar(&cfg.IBMCloudProxied)
@@ -515,6 +708,17 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("godaddy-api-ttl", "TTL (in seconds) for records. This value will be used if the provided TTL for a service/ingress is not provided.").Int64Var(&cfg.GoDaddyTTL)
 	app.Flag("godaddy-api-ote", "When using the GoDaddy provider, use OTE api (optional, default: false, when --provider=godaddy)").BoolVar(&cfg.GoDaddyOTE)
 
+	// Flags related to the Git provider
+	app.Flag("git-repository", "When using the Git provider, the URL of the repository to store zone files in (required when --provider=git)").Default(defaultConfig.GitRepositoryURL).StringVar(&cfg.GitRepositoryURL)
+	app.Flag("git-branch", "When using the Git provider, the branch holding the applied state of the zones (default: main)").Default(defaultConfig.GitBranch).StringVar(&cfg.GitBranch)
+	app.Flag("git-mode", "When using the Git provider, commit directly to the branch or push to a review branch (default: branch, options: branch, pr)").Default(defaultConfig.GitMode).EnumVar(&cfg.GitMode, "branch", "pr")
+	app.Flag("git-review-branch", "When using the Git provider in pr mode, the branch pending changes are pushed to (default: external-dns/changes)").Default(defaultConfig.GitReviewBranch).StringVar(&cfg.GitReviewBranch)
+	app.Flag("git-path", "When using the Git provider, the directory inside the repository holding the zone files (default: zones)").Default(defaultConfig.GitPath).StringVar(&cfg.GitPath)
+	app.Flag("git-work-dir", "When using the Git provider, the local directory used for the clone (default: /tmp/external-dns-git)").Default(defaultConfig.GitWorkDir).StringVar(&cfg.GitWorkDir)
+	app.Flag("git-zone", "When using the Git provider, a zone to manage; specify multiple times for multiple zones (required when --provider=git)").StringsVar(&cfg.GitZones)
+	app.Flag("git-author-name", "When using the Git provider, the author name used for commits (default: external-dns)").Default(defaultConfig.GitAuthorName).StringVar(&cfg.GitAuthorName)
+	app.Flag("git-author-email", "When using the Git provider, the author email used for commits (default: external-dns@localhost)").Default(defaultConfig.GitAuthorEmail).StringVar(&cfg.GitAuthorEmail)
+
 	// Flags related to TLS communication
 	app.Flag("tls-ca", "When using TLS communication, the path to the certificate authority to verify server communications (optionally specify --tls-client-cert for two-way TLS)").Default(defaultConfig.TLSCA).StringVar(&cfg.TLSCA)
 	app.Flag("tls-client-cert", "When using TLS communication, the path to the certificate to present as a client (not required for TLS)").Default(defaultConfig.TLSClientCert).StringVar(&cfg.TLSClientCert)
@@ -562,10 +766,16 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("policy", "Modify how DNS records are synchronized between sources and providers (default: sync, options: sync, upsert-only, create-only)").Default(defaultConfig.Policy).EnumVar(&cfg.Policy, "sync", "upsert-only", "create-only")
 
 	// Flags related to the registry
-	app.Flag("registry", "The registry implementation to use to keep track of DNS record ownership (default: txt, options: txt, noop, dynamodb, aws-sd)").Default(defaultConfig.Registry).EnumVar(&cfg.Registry, "txt", "noop", "dynamodb", "aws-sd")
-	app.Flag("txt-owner-id", "When using the TXT or DynamoDB registry, a name that identifies this instance of ExternalDNS (default: default)").Default(defaultConfig.TXTOwnerID).StringVar(&cfg.TXTOwnerID)
-	app.Flag("txt-prefix", "When using the TXT registry, a custom string that's prefixed to each ownership DNS record (optional). Could contain record type template like '%{record_type}-prefix-'. Mutual exclusive with txt-suffix!").Default(defaultConfig.TXTPrefix).StringVar(&cfg.TXTPrefix)
-	app.Flag("txt-suffix", "When using the TXT registry, a custom string that's suffixed to the host portion of each ownership DNS record (optional). Could contain record type template like '-%{record_type}-suffix'. Mutual exclusive with txt-prefix!").Default(defaultConfig.TXTSuffix).StringVar(&cfg.TXTSuffix)
+	app.Flag("registry", "The registry implementation to use to keep track of DNS record ownership (default: txt, options: txt, txt-apex, noop, dynamodb, aws-sd)").Default(defaultConfig.Registry).EnumVar(&cfg.Registry, "txt", "txt-apex", "noop", "dynamodb", "aws-sd")
+	app.Flag("txt-owner-id", "When using the TXT or DynamoDB registry, a name that identifies this instance of ExternalDNS; \"auto\" derives it from the identity of the cluster (default: default)").Default(defaultConfig.TXTOwnerID).StringVar(&cfg.TXTOwnerID)
+	app.Flag("txt-prefix", "When using the TXT registry, a custom string that's prefixed to each ownership DNS record (optional). Could contain record type template like '%{record_type}-prefix-' and name hash template like '%{hash}-', in upper case to render them in upper case. Mutual exclusive with txt-suffix!").Default(defaultConfig.TXTPrefix).StringVar(&cfg.TXTPrefix)
+	app.Flag("txt-suffix", "When using the TXT registry, a custom string that's suffixed to the host portion of each ownership DNS record (optional). Could contain record type template like '-%{record_type}-suffix' and name hash template like '-%{hash}', in upper case to render them in upper case. Mutual exclusive with txt-prefix!").Default(defaultConfig.TXTSuffix).StringVar(&cfg.TXTSuffix)
+	app.Flag("txt-prefix-migrate-from", "When using the TXT registry, the prefix of the ownership DNS records before changing --txt-prefix or --txt-suffix, possibly empty: the records owned under it are recognized, and their ownership records rewritten to the new naming scheme over successive synchronizations (optional). Mutual exclusive with txt-suffix-migrate-from!").Default("").IsSetByUser(&cfg.TXTMigrate).StringVar(&cfg.TXTPrefixMigrateFrom)
+	app.Flag("txt-suffix-migrate-from", "When using the TXT registry, the suffix of the ownership DNS records before changing --txt-prefix or --txt-suffix, possibly empty, see --txt-prefix-migrate-from (optional). Mutual exclusive with txt-prefix-migrate-from!").Default("").IsSetByUser(&cfg.TXTMigrate).StringVar(&cfg.TXTSuffixMigrateFrom)
+	app.Flag("txt-record-ttl", "When using the TXT registry, the TTL in seconds of the ownership DNS records, independently of the TTL of the records they own (default: the default TTL of the provider)").Default("0").Int64Var(&cfg.TXTRecordTTL)
+	app.Flag("txt-record-comment", "When using the TXT registry, the comment of the ownership DNS records, on the providers supporting record comments, e.g. cloudflare (optional)").Default("").StringVar(&cfg.TXTRecordComment)
+	app.Flag("txt-owner-domain", "When using the TXT registry, consolidate the ownership DNS records of the records of a domain in a subdomain, e.g. _owner.example.com for the records of example.com; specify multiple times for multiple domains (optional)").StringsVar(&cfg.TXTOwnerDomains)
+	app.Flag("txt-apex-record-name", "When using the txt-apex registry, the name under each zone of the ownership DNS records, named <owner ID>.<name>.<zone> (default: _external-dns)").Default(defaultConfig.TXTApexRecordName).StringVar(&cfg.TXTApexRecordName)
 	app.Flag("txt-wildcard-replacement", "When using the TXT registry, a custom string that's used instead of an asterisk for TXT records corresponding to wildcard DNS records (optional)").Default(defaultConfig.TXTWildcardReplacement).StringVar(&cfg.TXTWildcardReplacement)
 	app.Flag("txt-encrypt-enabled", "When using the TXT registry, set if TXT records should be encrypted before stored (default: disabled)").BoolVar(&cfg.TXTEncryptEnabled)
 	app.Flag("txt-encrypt-aes-key", "When using the TXT registry, set TXT record decryption and encryption 32 byte aes key (required when --txt-encrypt=true)").Default(defaultConfig.TXTEncryptAESKey).StringVar(&cfg.TXTEncryptAESKey)
@@ -574,15 +784,63 @@ func (cfg *Config) ParseFlags(args []string) error {
 
 	// Flags related to the main control loop
 	app.Flag("txt-cache-interval", "The interval between cache synchronizations in duration format (default: disabled)").Default(defaultConfig.TXTCacheInterval.String()).DurationVar(&cfg.TXTCacheInterval)
+	app.Flag("snapshot-file", "Persist the records of the registry to this file after every listing, e.g. on a persistent volume; after a restart, the first synchronization uses the records of the file while they are listed in the background (default: disabled)").Default("").StringVar(&cfg.SnapshotFile)
+	app.Flag("snapshot-max-age", "When using --snapshot-file, ignore the file at startup if it is older than this duration; 0 accepts any age (default: 1h)").Default("1h").DurationVar(&cfg.SnapshotMaxAge)
+	app.Flag("registry-lease-duration", "When using the TXT or DynamoDB registry, let several instances sharing an owner ID manage each record set from one instance at a time: the instance managing a record set renews its lease on it, and the other instances leave it alone until the lease expires; must be greater than --interval (default: disabled)").Default("0s").DurationVar(&cfg.RegistryLeaseDuration)
+	app.Flag("registry-lease-instance", "When using --registry-lease-duration, the unique name of this instance holding the leases, e.g. the pod name (default: the hostname)").Default("").StringVar(&cfg.RegistryLeaseInstance)
+	app.Flag("registry-gc-interval", "When using the TXT or DynamoDB registry, delete the ownership records of this owner ID whose records no longer exist and create the missing ownership records of its records, at most once per interval, e.g. after a crash between two changes (default: disabled)").Default("0s").DurationVar(&cfg.RegistryGCInterval)
 	app.Flag("interval", "The interval between two consecutive synchronizations in duration format (default: 1m)").Default(defaultConfig.Interval.String()).DurationVar(&cfg.Interval)
 	app.Flag("min-event-sync-interval", "The minimum interval between two consecutive synchronizations triggered from kubernetes events in duration format (default: 5s)").Default(defaultConfig.MinEventSyncInterval.String()).DurationVar(&cfg.MinEventSyncInterval)
+	app.Flag("max-memory-endpoints", "When the number of current and desired endpoints exceeds this threshold, they are spilled to a temporary directory and synchronized in partitions to bound the memory usage; 0 disables spilling (default: 0)").Default("0").IntVar(&cfg.MaxMemoryEndpoints)
+	app.Flag("sync-per-zone", "Synchronize one zone after the other, listing the records, calculating and applying the changes per zone instead of waiting for the listing of all zones; only supported by the aws and inmemory providers (default: disabled)").BoolVar(&cfg.SyncPerZone)
+	app.Flag("provider-api-budget-per-cycle", "When using --sync-per-zone, the maximum number of provider API requests per synchronization; the budget is checked before each zone, so a single zone may exceed it, and the remaining zones are deferred to the next synchronization in round-robin order; only supported by the aws provider; 0 is unlimited (default: 0)").Default("0").IntVar(&cfg.ProviderAPIBudgetPerCycle)
+	app.Flag("provider-api-quota-headroom", "When the provider API reports its rate-limit quota, the fraction of the quota below which the requests are spread over the rest of the rate-limit window; only supported by the cloudflare, digitalocean and godaddy providers; 0 disables the pacing (default: 0.1)").Default(strconv.FormatFloat(defaultConfig.ProviderAPIQuotaHeadroom, 'f', -1, 64)).Float64Var(&cfg.ProviderAPIQuotaHeadroom)
+	app.Flag("skip-unchanged-zones", "When using --sync-per-zone, skip calculating the plan and applying the changes of the zones whose desired endpoints and records didn't change since they were found in sync; the inmemory provider tells whether the records of a zone changed without listing them (default: disabled)").BoolVar(&cfg.SkipUnchangedZones)
+	app.Flag("preflight-check", "When enabled, checks at startup that the provider credentials can list the zones and read the records, and exits with an error otherwise (default: disabled)").BoolVar(&cfg.PreflightCheck)
+	app.Flag("preflight-check-write", "When using --preflight-check, also checks that a TXT record can be created and deleted in every zone; only supported by providers listing their zones (default: disabled)").BoolVar(&cfg.PreflightCheckWrite)
+	app.Flag("create-missing-zones", "Create the zones of the --domain-filter which the created records require and which don't exist, recording the owner ID as their owner; only supported by the aws and inmemory providers (default: disabled)").BoolVar(&cfg.CreateMissingZones)
+	app.Flag("delete-empty-zones", "When using --create-missing-zones, delete the zones created with the owner ID once their records are deleted (default: disabled)").BoolVar(&cfg.DeleteEmptyZones)
+	app.Flag("cluster-name", "The name of the cluster, used to attribute the requests to the DNS provider APIs (default: the --gslb-cluster)").Default("").StringVar(&cfg.ClusterName)
+	app.Flag("user-agent-attribution", "When enabled, the User-Agent of the requests to the DNS provider APIs includes the cluster name and the owner ID; not supported by the gandi provider, which sends the User-Agent of its SDK (default: disabled)").BoolVar(&cfg.UserAgentAttribution)
+	app.Flag("provider-timeout", "The maximum duration of listing the records and of applying the changes by the provider, after which the synchronization is aborted and retried; the changes are interrupted through the context, but never abandoned in flight; 0 disables the timeout (default: disabled)").Default("0s").DurationVar(&cfg.ProviderTimeout)
+	app.Flag("provider-fault-injection", "For testing only, inject faults into the calls to the provider to rehearse a degraded provider API, as a comma-separated list of latency:<duration>, error-rate:<rate>, throttle-rate:<rate> and partial-failure-rate:<rate>, e.g. latency:500ms,error-rate:0.1 (default: disabled)").Default("").StringVar(&cfg.ProviderFaultInjection)
+	app.Flag("unroutable-hostname-cache-ttl", "Skip the hostnames matching no zone of the provider before planning, listing the zones at most once per this duration and reporting the skipped hostnames once; 0 disables skipping (default: disabled)").Default("0s").DurationVar(&cfg.UnroutableHostnameCacheTTL)
+	app.Flag("skip-delegated-hostnames", "Skip the hostnames at or below a name that a zone of the provider delegates to another zone with NS records before planning, since the records would never be served, and report the skipped hostnames once (default: disabled)").BoolVar(&cfg.SkipDelegatedHostnames)
+	app.Flag("propagation-check-resolver", "Query this resolver, given as host or host:port, e.g. 1.1.1.1 or 8.8.8.8:53, for the records created and updated by the synchronizations, and report its propagation latency in the external_dns_controller_propagation_latency_seconds metric; specify multiple times for a pool of resolvers (default: disabled)").StringsVar(&cfg.PropagationCheckResolvers)
+	app.Flag("propagation-check-interval", "The interval of the queries of the propagation checks (default: 10s)").Default("10s").DurationVar(&cfg.PropagationCheckInterval)
+	app.Flag("propagation-check-timeout", "The duration after which the records not answered by a resolver of the propagation checks are reported as not propagated (default: 10m)").Default("10m").DurationVar(&cfg.PropagationCheckTimeout)
+	app.Flag("out-of-sync-cycles", "Report the records differing from their desired state for more than this number of synchronizations in the external_dns_controller_records_out_of_sync metric; 0 disables the tracking (default: disabled)").Default("0").IntVar(&cfg.OutOfSyncCycles)
+	app.Flag("ttl-repair-interval", "Update the records differing from their desired state only by their TTL separately from the other changes, regardless of the policy, at most once per interval; 0 updates the TTLs with the other changes (default: disabled)").Default("0s").DurationVar(&cfg.TTLRepairInterval)
+	app.Flag("status-api", "Serve the managed records, the last changes and a reconcile trigger under /api/v1/ on the metrics address, e.g. for the kubectl external-dns plugin (default: disabled)").BoolVar(&cfg.StatusAPI)
+	app.Flag("status-api-reconcile-token-file", "Require the bearer token of this file to trigger a synchronization with POST /api/v1/reconcile of the status API, e.g. from a CI/CD pipeline; the file is read on every request; without it the trigger is forbidden (default: disabled)").Default("").StringVar(&cfg.StatusAPIReconcileTokenFile)
 	app.Flag("once", "When enabled, exits the synchronization loop after the first iteration (default: disabled)").BoolVar(&cfg.Once)
 	app.Flag("dry-run", "When enabled, prints DNS record changes rather than actually performing them (default: disabled)").BoolVar(&cfg.DryRun)
+	app.Flag("dry-run-output", "How the changes are printed in dry-run mode; log logs them from the provider, tree also renders them grouped by zone and hostname, colored on a terminal (default: log, options: log, tree)").Default(defaultConfig.DryRunOutput).EnumVar(&cfg.DryRunOutput, "log", "tree")
+	app.Flag("octodns-export-dir", "When set, writes the records managed by this instance as octoDNS YAML config into this directory after every synchronization (default: disabled)").Default(defaultConfig.OctoDNSExportDir).StringVar(&cfg.OctoDNSExportDir)
+	app.Flag("octodns-export-zone", "Zone to export in octoDNS format, one file per zone; specify multiple times for multiple zones (default: the domains of --domain-filter)").StringsVar(&cfg.OctoDNSExportZones)
+	app.Flag("terraform-state", "Terraform state or plan file in JSON format; reports records managed by both Terraform and ExternalDNS after every synchronization; specify multiple times for multiple files (optional)").StringsVar(&cfg.TerraformStates)
 	app.Flag("events", "When enabled, in addition to running every interval, the reconciliation loop will get triggered when supported sources change (default: disabled)").BoolVar(&cfg.UpdateEvents)
 
 	// Miscellaneous flags
 	app.Flag("log-format", "The format in which log messages are printed (default: text, options: text, json)").Default(defaultConfig.LogFormat).EnumVar(&cfg.LogFormat, "text", "json")
 	app.Flag("metrics-address", "Specify where to serve the metrics and health check endpoint (default: :7979)").Default(defaultConfig.MetricsAddress).StringVar(&cfg.MetricsAddress)
+	app.Flag("metrics-tls-cert-file", "The certificate to serve the metrics endpoint over HTTPS, with --metrics-tls-key-file; re-loaded when it changes, e.g. when mounted from a renewed Secret (optional)").Default("").StringVar(&cfg.MetricsTLSCertFile)
+	app.Flag("metrics-tls-key-file", "The key of --metrics-tls-cert-file (optional)").Default("").StringVar(&cfg.MetricsTLSKeyFile)
+	app.Flag("metrics-tls-client-ca-file", "The CA bundle verifying the certificate the clients of the metrics endpoint must present over HTTPS (default: no client certificate)").Default("").StringVar(&cfg.MetricsTLSClientCAFile)
+	app.Flag("metrics-basic-auth-file", "A file with the username:password lines of the users allowed to query the metrics endpoint (default: no authentication)").Default("").StringVar(&cfg.MetricsBasicAuthFile)
+	app.Flag("healthz-address", "Specify where to serve the health check endpoint instead of the metrics address (optional)").Default("").StringVar(&cfg.HealthzAddress)
+	app.Flag("healthz-tls-cert-file", "The certificate to serve the health check endpoint over HTTPS on --healthz-address, with --healthz-tls-key-file (optional)").Default("").StringVar(&cfg.HealthzTLSCertFile)
+	app.Flag("healthz-tls-key-file", "The key of --healthz-tls-cert-file (optional)").Default("").StringVar(&cfg.HealthzTLSKeyFile)
+	app.Flag("healthz-tls-client-ca-file", "The CA bundle verifying the certificate the clients of the health check endpoint must present over HTTPS on --healthz-address (default: no client certificate)").Default("").StringVar(&cfg.HealthzTLSClientCAFile)
+	app.Flag("healthz-basic-auth-file", "A file with the username:password lines of the users allowed to query the health check endpoint on --healthz-address (default: no authentication)").Default("").StringVar(&cfg.HealthzBasicAuthFile)
+	app.Flag("debug-address", "Specify where to serve the pprof profiling endpoints under /debug/pprof/ (default: disabled)").Default("").StringVar(&cfg.DebugAddress)
+	app.Flag("debug-tls-cert-file", "The certificate to serve the profiling endpoints over HTTPS, with --debug-tls-key-file (optional)").Default("").StringVar(&cfg.DebugTLSCertFile)
+	app.Flag("debug-tls-key-file", "The key of --debug-tls-cert-file (optional)").Default("").StringVar(&cfg.DebugTLSKeyFile)
+	app.Flag("debug-tls-client-ca-file", "The CA bundle verifying the certificate the clients of the profiling endpoints must present over HTTPS (default: no client certificate)").Default("").StringVar(&cfg.DebugTLSClientCAFile)
+	app.Flag("debug-basic-auth-file", "A file with the username:password lines of the users allowed to query the profiling endpoints (default: no authentication)").Default("").StringVar(&cfg.DebugBasicAuthFile)
+	app.Flag("slow-cycle-profile-threshold", "Capture a CPU and a heap profile of the synchronizations lasting longer than this duration, served under /debug/slow-cycles/ on --debug-address; 0s disables the capture (default: 0s)").Default("0s").DurationVar(&cfg.SlowCycleProfileThreshold)
+	app.Flag("slow-cycle-profile-dir", "The directory of the profiles of the slow synchronizations (default: external-dns-profiles in the temporary directory)").Default("").StringVar(&cfg.SlowCycleProfileDir)
+	app.Flag("slow-cycle-profile-max-captures", "The number of slow synchronizations whose profiles are kept, the oldest are removed (default: 10)").Default("10").IntVar(&cfg.SlowCycleProfileMaxCaptures)
 	app.Flag("log-level", "Set the level of logging. (default: info, options: panic, debug, info, warning, error, fatal)").Default(defaultConfig.LogLevel).EnumVar(&cfg.LogLevel, allLogLevelsAsStrings()...)
 
 	// Webhook provider
@@ -32,201 +32,253 @@ import (
 
 var (
 	minimalConfig = &Config{
-		APIServerURL:                "",
-		KubeConfig:                  "",
-		RequestTimeout:              time.Second * 30,
-		GlooNamespaces:              []string{"gloo-system"},
-		SkipperRouteGroupVersion:    "zalando.org/v1",
-		Sources:                     []string{"service"},
-		Namespace:                   "",
-		FQDNTemplate:                "",
-		Compatibility:               "",
-		Provider:                    "google",
-		GoogleProject:               "",
-		GoogleBatchChangeSize:       1000,
-		GoogleBatchChangeInterval:   time.Second,
-		GoogleZoneVisibility:        "",
-		DomainFilter:                []string{""},
-		ExcludeDomains:              []string{""},
-		RegexDomainFilter:           regexp.MustCompile(""),
-		RegexDomainExclusion:        regexp.MustCompile(""),
-		ZoneNameFilter:              []string{""},
-		ZoneIDFilter:                []string{""},
-		AlibabaCloudConfigFile:      "/etc/kubernetes/alibaba-cloud.json",
-		AWSZoneType:                 "",
-		AWSZoneTagFilter:            []string{""},
-		AWSZoneMatchParent:          false,
-		AWSAssumeRole:               "",
-		AWSAssumeRoleExternalID:     "",
-		AWSBatchChangeSize:          1000,
-		AWSBatchChangeSizeBytes:     32000,
-		AWSBatchChangeSizeValues:    1000,
-		AWSBatchChangeInterval:      time.Second,
-		AWSEvaluateTargetHealth:     true,
-		AWSAPIRetries:               3,
-		AWSPreferCNAME:              false,
-		AWSProfiles:                 []string{""},
-		AWSZoneCacheDuration:        0 * time.Second,
-		AWSSDServiceCleanup:         false,
-		AWSDynamoDBTable:            "external-dns",
-		AzureConfigFile:             "/etc/kubernetes/azure.json",
-		AzureResourceGroup:          "",
-		AzureSubscriptionID:         "",
-		CloudflareProxied:           false,
-		CloudflareDNSRecordsPerPage: 100,
-		CoreDNSPrefix:               "/skydns/",
-		AkamaiServiceConsumerDomain: "",
-		AkamaiClientToken:           "",
-		AkamaiClientSecret:          "",
-		AkamaiAccessToken:           "",
-		AkamaiEdgercPath:            "",
-		AkamaiEdgercSection:         "",
-		OCIConfigFile:               "/etc/kubernetes/oci.yaml",
-		OCIZoneScope:                "GLOBAL",
-		OCIZoneCacheDuration:        0 * time.Second,
-		InMemoryZones:               []string{""},
-		OVHEndpoint:                 "ovh-eu",
-		OVHApiRateLimit:             20,
-		PDNSServer:                  "http://localhost:8081",
-		PDNSServerID:                "localhost",
-		PDNSAPIKey:                  "",
-		Policy:                      "sync",
-		Registry:                    "txt",
-		TXTOwnerID:                  "default",
-		TXTPrefix:                   "",
-		TXTCacheInterval:            0,
-		Interval:                    time.Minute,
-		MinEventSyncInterval:        5 * time.Second,
-		Once:                        false,
-		DryRun:                      false,
-		UpdateEvents:                false,
-		LogFormat:                   "text",
-		MetricsAddress:              ":7979",
-		LogLevel:                    logrus.InfoLevel.String(),
-		ConnectorSourceServer:       "localhost:8080",
-		ExoscaleAPIEnvironment:      "api",
-		ExoscaleAPIZone:             "ch-gva-2",
-		ExoscaleAPIKey:              "",
-		ExoscaleAPISecret:           "",
-		CRDSourceAPIVersion:         "externaldns.k8s.io/v1alpha1",
-		CRDSourceKind:               "DNSEndpoint",
-		TransIPAccountName:          "",
-		TransIPPrivateKeyFile:       "",
-		DigitalOceanAPIPageSize:     50,
-		ManagedDNSRecordTypes:       []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
-		RFC2136BatchChangeSize:      50,
-		OCPRouterName:               "default",
-		IBMCloudProxied:             false,
-		IBMCloudConfigFile:          "/etc/kubernetes/ibmcloud.json",
-		TencentCloudConfigFile:      "/etc/kubernetes/tencent-cloud.json",
-		TencentCloudZoneType:        "",
-		WebhookProviderURL:          "http://localhost:8888",
-		WebhookProviderReadTimeout:  5 * time.Second,
-		WebhookProviderWriteTimeout: 10 * time.Second,
+		APIServerURL:                  "",
+		KubeConfig:                    "",
+		RequestTimeout:                time.Second * 30,
+		GlooNamespaces:                []string{"gloo-system"},
+		SkipperRouteGroupVersion:      "zalando.org/v1",
+		Sources:                       []string{"service"},
+		Namespace:                     "",
+		FQDNTemplate:                  "",
+		Compatibility:                 "",
+		Provider:                      "google",
+		GoogleProject:                 "",
+		GoogleCredentialsFile:         "",
+		GoogleCredentialsJSON:         "",
+		GoogleBatchChangeSize:         1000,
+		GoogleBatchChangeInterval:     time.Second,
+		GoogleZoneVisibility:          "",
+		DomainFilter:                  []string{""},
+		ExcludeDomains:                []string{""},
+		RegexDomainFilter:             regexp.MustCompile(""),
+		RegexDomainExclusion:          regexp.MustCompile(""),
+		ZoneNameFilter:                []string{""},
+		ZoneIDFilter:                  []string{""},
+		AlibabaCloudConfigFile:        "/etc/kubernetes/alibaba-cloud.json",
+		AWSZoneType:                   "",
+		AWSZoneTagFilter:              []string{""},
+		AWSZoneMatchParent:            false,
+		AWSAssumeRole:                 "",
+		AWSAssumeRoleExternalID:       "",
+		AWSBatchChangeSize:            1000,
+		AWSBatchChangeSizeBytes:       32000,
+		AWSBatchChangeSizeValues:      1000,
+		AWSBatchChangeInterval:        time.Second,
+		AWSEvaluateTargetHealth:       true,
+		AWSAPIRetries:                 3,
+		AWSAPIDefaultRateLimit:        5,
+		AWSPreferCNAME:                false,
+		AWSProfiles:                   []string{""},
+		AWSZoneCacheDuration:          0 * time.Second,
+		AWSSDServiceCleanup:           false,
+		AWSDynamoDBTable:              "external-dns",
+		AzureConfigFile:               "/etc/kubernetes/azure.json",
+		AzureResourceGroup:            "",
+		AzureSubscriptionID:           "",
+		AzureManagementGroup:          "",
+		CloudflareProxied:             false,
+		CloudflareDNSRecordsPerPage:   100,
+		CloudflareListingWorkers:      4,
+		CoreDNSPrefix:                 "/skydns/",
+		AkamaiServiceConsumerDomain:   "",
+		AkamaiClientToken:             "",
+		AkamaiClientSecret:            "",
+		AkamaiAccessToken:             "",
+		AkamaiEdgercPath:              "",
+		AkamaiEdgercSection:           "",
+		OCIConfigFile:                 "/etc/kubernetes/oci.yaml",
+		OCIZoneScope:                  "GLOBAL",
+		OCIZoneCacheDuration:          0 * time.Second,
+		InMemoryZones:                 []string{""},
+		OVHEndpoint:                   "ovh-eu",
+		OVHApiRateLimit:               20,
+		PDNSServer:                    "http://localhost:8081",
+		PDNSServerID:                  "localhost",
+		PDNSAPIKey:                    "",
+		Policy:                        "sync",
+		Registry:                      "txt",
+		TXTOwnerID:                    "default",
+		TXTPrefix:                     "",
+		TXTCacheInterval:              0,
+		TXTApexRecordName:             "_external-dns",
+		Interval:                      time.Minute,
+		MinEventSyncInterval:          5 * time.Second,
+		Once:                          false,
+		DryRun:                        false,
+		DryRunOutput:                  "log",
+		UpdateEvents:                  false,
+		LogFormat:                     "text",
+		MetricsAddress:                ":7979",
+		LogLevel:                      logrus.InfoLevel.String(),
+		ConnectorSourceServer:         "localhost:8080",
+		ExoscaleAPIEnvironment:        "api",
+		ExoscaleAPIZone:               "ch-gva-2",
+		ExoscaleAPIKey:                "",
+		ExoscaleAPISecret:             "",
+		CRDSourceAPIVersion:           "externaldns.k8s.io/v1alpha1",
+		CRDSourceKind:                 "DNSEndpoint",
+		TransIPAccountName:            "",
+		TransIPPrivateKeyFile:         "",
+		DigitalOceanAPIPageSize:       50,
+		ProviderAPIQuotaHeadroom:      0.1,
+		ManagedDNSRecordTypes:         []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
+		RFC2136BatchChangeSize:        50,
+		OCPRouterName:                 "default",
+		IBMCloudProxied:               false,
+		IBMCloudConfigFile:            "/etc/kubernetes/ibmcloud.json",
+		TencentCloudConfigFile:        "/etc/kubernetes/tencent-cloud.json",
+		TencentCloudZoneType:          "",
+		GitBranch:                     "main",
+		GitMode:                       "branch",
+		GitReviewBranch:               "external-dns/changes",
+		GitPath:                       "zones",
+		GitWorkDir:                    "/tmp/external-dns-git",
+		GitAuthorName:                 "external-dns",
+		GitAuthorEmail:                "external-dns@localhost",
+		WebhookProviderURL:            "http://localhost:8888",
+		WebhookProviderReadTimeout:    5 * time.Second,
+		WebhookProviderWriteTimeout:   10 * time.Second,
+		HealthCheckInterval:           10 * time.Second,
+		HealthCheckTimeout:            5 * time.Second,
+		HealthCheckHealthyThreshold:   2,
+		HealthCheckUnhealthyThreshold: 3,
+		GSLBLeaseDuration:             5 * time.Minute,
+		GSLBWeightProperty:            "aws/weight",
+		SnapshotMaxAge:                time.Hour,
+		SlowCycleProfileMaxCaptures:   10,
+		PropagationCheckInterval:      10 * time.Second,
+		PropagationCheckTimeout:       10 * time.Minute,
 	}
 
 	overriddenConfig = &Config{
-		APIServerURL:                "http://127.0.0.1:8080",
-		KubeConfig:                  "/some/path",
-		RequestTimeout:              time.Second * 77,
-		GlooNamespaces:              []string{"gloo-not-system", "gloo-second-system"},
-		SkipperRouteGroupVersion:    "zalando.org/v2",
-		Sources:                     []string{"service", "ingress", "connector"},
-		Namespace:                   "namespace",
-		IgnoreHostnameAnnotation:    true,
-		IgnoreIngressTLSSpec:        true,
-		IgnoreIngressRulesSpec:      true,
-		FQDNTemplate:                "{{.Name}}.service.example.com",
-		Compatibility:               "mate",
-		Provider:                    "google",
-		GoogleProject:               "project",
-		GoogleBatchChangeSize:       100,
-		GoogleBatchChangeInterval:   time.Second * 2,
-		GoogleZoneVisibility:        "private",
-		DomainFilter:                []string{"example.org", "company.com"},
-		ExcludeDomains:              []string{"xapi.example.org", "xapi.company.com"},
-		RegexDomainFilter:           regexp.MustCompile("(example\\.org|company\\.com)$"),
-		RegexDomainExclusion:        regexp.MustCompile("xapi\\.(example\\.org|company\\.com)$"),
-		ZoneNameFilter:              []string{"yapi.example.org", "yapi.company.com"},
-		ZoneIDFilter:                []string{"/hostedzone/ZTST1", "/hostedzone/ZTST2"},
-		TargetNetFilter:             []string{"10.0.0.0/9", "10.1.0.0/9"},
-		ExcludeTargetNets:           []string{"1.0.0.0/9", "1.1.0.0/9"},
-		AlibabaCloudConfigFile:      "/etc/kubernetes/alibaba-cloud.json",
-		AWSZoneType:                 "private",
-		AWSZoneTagFilter:            []string{"tag=foo"},
-		AWSZoneMatchParent:          true,
-		AWSAssumeRole:               "some-other-role",
-		AWSAssumeRoleExternalID:     "pg2000",
-		AWSBatchChangeSize:          100,
-		AWSBatchChangeSizeBytes:     16000,
-		AWSBatchChangeSizeValues:    100,
-		AWSBatchChangeInterval:      time.Second * 2,
-		AWSEvaluateTargetHealth:     false,
-		AWSAPIRetries:               13,
-		AWSPreferCNAME:              true,
-		AWSProfiles:                 []string{"profile1", "profile2"},
-		AWSZoneCacheDuration:        10 * time.Second,
-		AWSSDServiceCleanup:         true,
-		AWSDynamoDBTable:            "custom-table",
-		AzureConfigFile:             "azure.json",
-		AzureResourceGroup:          "arg",
-		AzureSubscriptionID:         "arg",
-		CloudflareProxied:           true,
-		CloudflareDNSRecordsPerPage: 5000,
-		CoreDNSPrefix:               "/coredns/",
-		AkamaiServiceConsumerDomain: "oooo-xxxxxxxxxxxxxxxx-xxxxxxxxxxxxxxxx.luna.akamaiapis.net",
-		AkamaiClientToken:           "o184671d5307a388180fbf7f11dbdf46",
-		AkamaiClientSecret:          "o184671d5307a388180fbf7f11dbdf46",
-		AkamaiAccessToken:           "o184671d5307a388180fbf7f11dbdf46",
-		AkamaiEdgercPath:            "/home/test/.edgerc",
-		AkamaiEdgercSection:         "default",
-		OCIConfigFile:               "oci.yaml",
-		OCIZoneScope:                "PRIVATE",
-		OCIZoneCacheDuration:        30 * time.Second,
-		InMemoryZones:               []string{"example.org", "company.com"},
-		OVHEndpoint:                 "ovh-ca",
-		OVHApiRateLimit:             42,
-		PDNSServer:                  "http://ns.example.com:8081",
-		PDNSServerID:                "localhost",
-		PDNSAPIKey:                  "some-secret-key",
-		PDNSSkipTLSVerify:           true,
-		TLSCA:                       "/path/to/ca.crt",
-		TLSClientCert:               "/path/to/cert.pem",
-		TLSClientCertKey:            "/path/to/key.pem",
-		Policy:                      "upsert-only",
-		Registry:                    "noop",
-		TXTOwnerID:                  "owner-1",
-		TXTPrefix:                   "associated-txt-record",
-		TXTCacheInterval:            12 * time.Hour,
-		Interval:                    10 * time.Minute,
-		MinEventSyncInterval:        50 * time.Second,
-		Once:                        true,
-		DryRun:                      true,
-		UpdateEvents:                true,
-		LogFormat:                   "json",
-		MetricsAddress:              "127.0.0.1:9099",
-		LogLevel:                    logrus.DebugLevel.String(),
-		ConnectorSourceServer:       "localhost:8081",
-		ExoscaleAPIEnvironment:      "api1",
-		ExoscaleAPIZone:             "zone1",
-		ExoscaleAPIKey:              "1",
-		ExoscaleAPISecret:           "2",
-		CRDSourceAPIVersion:         "test.k8s.io/v1alpha1",
-		CRDSourceKind:               "Endpoint",
-		NS1Endpoint:                 "https://api.example.com/v1",
-		NS1IgnoreSSL:                true,
-		TransIPAccountName:          "transip",
-		TransIPPrivateKeyFile:       "/path/to/transip.key",
-		DigitalOceanAPIPageSize:     100,
-		ManagedDNSRecordTypes:       []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME, endpoint.RecordTypeNS},
-		RFC2136BatchChangeSize:      100,
-		IBMCloudProxied:             true,
-		IBMCloudConfigFile:          "ibmcloud.json",
-		TencentCloudConfigFile:      "tencent-cloud.json",
-		TencentCloudZoneType:        "private",
-		WebhookProviderURL:          "http://localhost:8888",
-		WebhookProviderReadTimeout:  5 * time.Second,
-		WebhookProviderWriteTimeout: 10 * time.Second,
+		APIServerURL:                  "http://127.0.0.1:8080",
+		KubeConfig:                    "/some/path",
+		RequestTimeout:                time.Second * 77,
+		GlooNamespaces:                []string{"gloo-not-system", "gloo-second-system"},
+		SkipperRouteGroupVersion:      "zalando.org/v2",
+		Sources:                       []string{"service", "ingress", "connector"},
+		Namespace:                     "namespace",
+		IgnoreHostnameAnnotation:      true,
+		IgnoreIngressTLSSpec:          true,
+		IgnoreIngressRulesSpec:        true,
+		FQDNTemplate:                  "{{.Name}}.service.example.com",
+		Compatibility:                 "mate",
+		Provider:                      "google",
+		GoogleProject:                 "project",
+		GoogleCredentialsFile:         "/etc/google/credentials.json",
+		GoogleBatchChangeSize:         100,
+		GoogleBatchChangeInterval:     time.Second * 2,
+		GoogleZoneVisibility:          "private",
+		DomainFilter:                  []string{"example.org", "company.com"},
+		ExcludeDomains:                []string{"xapi.example.org", "xapi.company.com"},
+		RegexDomainFilter:             regexp.MustCompile("(example\\.org|company\\.com)$"),
+		RegexDomainExclusion:          regexp.MustCompile("xapi\\.(example\\.org|company\\.com)$"),
+		ZoneNameFilter:                []string{"yapi.example.org", "yapi.company.com"},
+		ZoneIDFilter:                  []string{"/hostedzone/ZTST1", "/hostedzone/ZTST2"},
+		TargetNetFilter:               []string{"10.0.0.0/9", "10.1.0.0/9"},
+		ExcludeTargetNets:             []string{"1.0.0.0/9", "1.1.0.0/9"},
+		AlibabaCloudConfigFile:        "/etc/kubernetes/alibaba-cloud.json",
+		AWSZoneType:                   "private",
+		AWSZoneTagFilter:              []string{"tag=foo"},
+		AWSZoneMatchParent:            true,
+		AWSAssumeRole:                 "some-other-role",
+		AWSAssumeRoleExternalID:       "pg2000",
+		AWSBatchChangeSize:            100,
+		AWSBatchChangeSizeBytes:       16000,
+		AWSBatchChangeSizeValues:      100,
+		AWSBatchChangeInterval:        time.Second * 2,
+		AWSEvaluateTargetHealth:       false,
+		AWSAPIRetries:                 13,
+		AWSAPIRateLimits:              []string{"ListHostedZones=2"},
+		AWSAPIDefaultRateLimit:        2.5,
+		AWSPreferCNAME:                true,
+		AWSProfiles:                   []string{"profile1", "profile2"},
+		AWSZoneCacheDuration:          10 * time.Second,
+		AWSSDServiceCleanup:           true,
+		AWSDynamoDBTable:              "custom-table",
+		AzureConfigFile:               "azure.json",
+		AzureResourceGroup:            "arg",
+		AzureSubscriptionID:           "arg",
+		AzureSubscriptionIDs:          []string{"sub1,sub2", "sub3"},
+		AzureManagementGroup:          "arg",
+		CloudflareProxied:             true,
+		CloudflareDNSRecordsPerPage:   5000,
+		CloudflareListingWorkers:      8,
+		CoreDNSPrefix:                 "/coredns/",
+		AkamaiServiceConsumerDomain:   "oooo-xxxxxxxxxxxxxxxx-xxxxxxxxxxxxxxxx.luna.akamaiapis.net",
+		AkamaiClientToken:             "o184671d5307a388180fbf7f11dbdf46",
+		AkamaiClientSecret:            "o184671d5307a388180fbf7f11dbdf46",
+		AkamaiAccessToken:             "o184671d5307a388180fbf7f11dbdf46",
+		AkamaiEdgercPath:              "/home/test/.edgerc",
+		AkamaiEdgercSection:           "default",
+		OCIConfigFile:                 "oci.yaml",
+		OCIZoneScope:                  "PRIVATE",
+		OCIZoneCacheDuration:          30 * time.Second,
+		InMemoryZones:                 []string{"example.org", "company.com"},
+		OVHEndpoint:                   "ovh-ca",
+		OVHApiRateLimit:               42,
+		PDNSServer:                    "http://ns.example.com:8081",
+		PDNSServerID:                  "localhost",
+		PDNSAPIKey:                    "some-secret-key",
+		PDNSSkipTLSVerify:             true,
+		TLSCA:                         "/path/to/ca.crt",
+		TLSClientCert:                 "/path/to/cert.pem",
+		TLSClientCertKey:              "/path/to/key.pem",
+		Policy:                        "upsert-only",
+		Registry:                      "noop",
+		TXTOwnerID:                    "owner-1",
+		TXTPrefix:                     "associated-txt-record",
+		TXTCacheInterval:              12 * time.Hour,
+		TXTApexRecordName:             "_external-dns",
+		Interval:                      10 * time.Minute,
+		MinEventSyncInterval:          50 * time.Second,
+		Once:                          true,
+		DryRun:                        true,
+		DryRunOutput:                  "tree",
+		UpdateEvents:                  true,
+		LogFormat:                     "json",
+		MetricsAddress:                "127.0.0.1:9099",
+		LogLevel:                      logrus.DebugLevel.String(),
+		ConnectorSourceServer:         "localhost:8081",
+		ExoscaleAPIEnvironment:        "api1",
+		ExoscaleAPIZone:               "zone1",
+		ExoscaleAPIKey:                "1",
+		ExoscaleAPISecret:             "2",
+		CRDSourceAPIVersion:           "test.k8s.io/v1alpha1",
+		CRDSourceKind:                 "Endpoint",
+		NS1Endpoint:                   "https://api.example.com/v1",
+		NS1IgnoreSSL:                  true,
+		NS1DataSourceID:               "5ba1c1b5e7a9be00015a6ee5",
+		TransIPAccountName:            "transip",
+		TransIPPrivateKeyFile:         "/path/to/transip.key",
+		DigitalOceanAPIPageSize:       100,
+		ProviderAPIQuotaHeadroom:      0.25,
+		ManagedDNSRecordTypes:         []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME, endpoint.RecordTypeNS},
+		RFC2136BatchChangeSize:        100,
+		IBMCloudProxied:               true,
+		IBMCloudConfigFile:            "ibmcloud.json",
+		TencentCloudConfigFile:        "tencent-cloud.json",
+		TencentCloudZoneType:          "private",
+		GitBranch:                     "main",
+		GitMode:                       "branch",
+		GitReviewBranch:               "external-dns/changes",
+		GitPath:                       "zones",
+		GitWorkDir:                    "/tmp/external-dns-git",
+		GitAuthorName:                 "external-dns",
+		GitAuthorEmail:                "external-dns@localhost",
+		WebhookProviderURL:            "http://localhost:8888",
+		WebhookProviderReadTimeout:    5 * time.Second,
+		WebhookProviderWriteTimeout:   10 * time.Second,
+		HealthCheckInterval:           10 * time.Second,
+		HealthCheckTimeout:            5 * time.Second,
+		HealthCheckHealthyThreshold:   2,
+		HealthCheckUnhealthyThreshold: 3,
+		GSLBLeaseDuration:             5 * time.Minute,
+		GSLBWeightProperty:            "aws/weight",
+		SnapshotMaxAge:                time.Hour,
+		SlowCycleProfileMaxCaptures:   10,
+		PropagationCheckInterval:      10 * time.Second,
+		PropagationCheckTimeout:       10 * time.Minute,
 	}
 )
 
@@ -267,14 +319,19 @@ func TestParseFlags(t *testing.T) {
 				"--compatibility=mate",
 				"--provider=google",
 				"--google-project=project",
+				"--google-credentials-file=/etc/google/credentials.json",
 				"--google-batch-change-size=100",
 				"--google-batch-change-interval=2s",
 				"--google-zone-visibility=private",
 				"--azure-config-file=azure.json",
 				"--azure-resource-group=arg",
 				"--azure-subscription-id=arg",
+				"--azure-subscription-ids=sub1,sub2",
+				"--azure-subscription-ids=sub3",
+				"--azure-management-group=arg",
 				"--cloudflare-proxied",
 				"--cloudflare-dns-records-per-page=5000",
+				"--cloudflare-listing-workers=8",
 				"--coredns-prefix=/coredns/",
 				"--akamai-serviceconsumerdomain=oooo-xxxxxxxxxxxxxxxx-xxxxxxxxxxxxxxxx.luna.akamaiapis.net",
 				"--akamai-client-token=o184671d5307a388180fbf7f11dbdf46",
@@ -320,6 +377,8 @@ func TestParseFlags(t *testing.T) {
 				"--aws-batch-change-size-values=100",
 				"--aws-batch-change-interval=2s",
 				"--aws-api-retries=13",
+				"--aws-api-rate-limit=ListHostedZones=2",
+				"--aws-api-default-rate-limit=2.5",
 				"--aws-prefer-cname",
 				"--aws-profile=profile1",
 				"--aws-profile=profile2",
@@ -336,6 +395,7 @@ func TestParseFlags(t *testing.T) {
 				"--min-event-sync-interval=50s",
 				"--once",
 				"--dry-run",
+				"--dry-run-output=tree",
 				"--events",
 				"--log-format=json",
 				"--metrics-address=127.0.0.1:9099",
@@ -349,9 +409,11 @@ func TestParseFlags(t *testing.T) {
 				"--crd-source-kind=Endpoint",
 				"--ns1-endpoint=https://api.example.com/v1",
 				"--ns1-ignoressl",
+				"--ns1-data-source-id=5ba1c1b5e7a9be00015a6ee5",
 				"--transip-account=transip",
 				"--transip-keyfile=/path/to/transip.key",
 				"--digitalocean-api-page-size=100",
+				"--provider-api-quota-headroom=0.25",
 				"--managed-record-types=A",
 				"--managed-record-types=AAAA",
 				"--managed-record-types=CNAME",
@@ -384,14 +446,18 @@ func TestParseFlags(t *testing.T) {
 				"EXTERNAL_DNS_COMPATIBILITY":                   "mate",
 				"EXTERNAL_DNS_PROVIDER":                        "google",
 				"EXTERNAL_DNS_GOOGLE_PROJECT":                  "project",
+				"EXTERNAL_DNS_GOOGLE_CREDENTIALS_FILE":         "/etc/google/credentials.json",
 				"EXTERNAL_DNS_GOOGLE_BATCH_CHANGE_SIZE":        "100",
 				"EXTERNAL_DNS_GOOGLE_BATCH_CHANGE_INTERVAL":    "2s",
 				"EXTERNAL_DNS_GOOGLE_ZONE_VISIBILITY":          "private",
 				"EXTERNAL_DNS_AZURE_CONFIG_FILE":               "azure.json",
 				"EXTERNAL_DNS_AZURE_RESOURCE_GROUP":            "arg",
 				"EXTERNAL_DNS_AZURE_SUBSCRIPTION_ID":           "arg",
+				"EXTERNAL_DNS_AZURE_SUBSCRIPTION_IDS":          "sub1,sub2\nsub3",
+				"EXTERNAL_DNS_AZURE_MANAGEMENT_GROUP":          "arg",
 				"EXTERNAL_DNS_CLOUDFLARE_PROXIED":              "1",
 				"EXTERNAL_DNS_CLOUDFLARE_DNS_RECORDS_PER_PAGE": "5000",
+				"EXTERNAL_DNS_CLOUDFLARE_LISTING_WORKERS":      "8",
 				"EXTERNAL_DNS_COREDNS_PREFIX":                  "/coredns/",
 				"EXTERNAL_DNS_AKAMAI_SERVICECONSUMERDOMAIN":    "oooo-xxxxxxxxxxxxxxxx-xxxxxxxxxxxxxxxx.luna.akamaiapis.net",
 				"EXTERNAL_DNS_AKAMAI_CLIENT_TOKEN":             "o184671d5307a388180fbf7f11dbdf46",
@@ -432,6 +498,8 @@ func TestParseFlags(t *testing.T) {
 				"EXTERNAL_DNS_AWS_BATCH_CHANGE_INTERVAL":       "2s",
 				"EXTERNAL_DNS_AWS_EVALUATE_TARGET_HEALTH":      "0",
 				"EXTERNAL_DNS_AWS_API_RETRIES":                 "13",
+				"EXTERNAL_DNS_AWS_API_RATE_LIMIT":              "ListHostedZones=2",
+				"EXTERNAL_DNS_AWS_API_DEFAULT_RATE_LIMIT":      "2.5",
 				"EXTERNAL_DNS_AWS_PREFER_CNAME":                "true",
 				"EXTERNAL_DNS_AWS_PROFILE":                     "profile1\nprofile2",
 				"EXTERNAL_DNS_AWS_ZONES_CACHE_DURATION":        "10s",
@@ -446,6 +514,7 @@ func TestParseFlags(t *testing.T) {
 				"EXTERNAL_DNS_MIN_EVENT_SYNC_INTERVAL":         "50s",
 				"EXTERNAL_DNS_ONCE":                            "1",
 				"EXTERNAL_DNS_DRY_RUN":                         "1",
+				"EXTERNAL_DNS_DRY_RUN_OUTPUT":                  "tree",
 				"EXTERNAL_DNS_EVENTS":                          "1",
 				"EXTERNAL_DNS_LOG_FORMAT":                      "json",
 				"EXTERNAL_DNS_METRICS_ADDRESS":                 "127.0.0.1:9099",
@@ -459,9 +528,11 @@ func TestParseFlags(t *testing.T) {
 				"EXTERNAL_DNS_CRD_SOURCE_KIND":                 "Endpoint",
 				"EXTERNAL_DNS_NS1_ENDPOINT":                    "https://api.example.com/v1",
 				"EXTERNAL_DNS_NS1_IGNORESSL":                   "1",
+				"EXTERNAL_DNS_NS1_DATA_SOURCE_ID":              "5ba1c1b5e7a9be00015a6ee5",
 				"EXTERNAL_DNS_TRANSIP_ACCOUNT":                 "transip",
 				"EXTERNAL_DNS_TRANSIP_KEYFILE":                 "/path/to/transip.key",
 				"EXTERNAL_DNS_DIGITALOCEAN_API_PAGE_SIZE":      "100",
+				"EXTERNAL_DNS_PROVIDER_API_QUOTA_HEADROOM":     "0.25",
 				"EXTERNAL_DNS_MANAGED_RECORD_TYPES":            "A\nAAAA\nCNAME\nNS",
 				"EXTERNAL_DNS_RFC2136_BATCH_CHANGE_SIZE":       "100",
 				"EXTERNAL_DNS_IBMCLOUD_PROXIED":                "1",
@@ -504,8 +575,8 @@ func restoreEnv(t *testing.T, originalEnv map[string]string) {
 
 func TestPasswordsNotLogged(t *testing.T) {
 	cfg := Config{
-		PDNSAPIKey:           "pdns-api-key",
-		RFC2136TSIGSecret:    "tsig-secret",
+		PDNSAPIKey:        "pdns-api-key",
+		RFC2136TSIGSecret: "tsig-secret",
 	}
 
 	s := cfg.String()
@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/source"
+)
+
+// zoneListingProviders are the providers listing their zones, which is required
+// to synchronize and check them one at a time.
+var zoneListingProviders = []string{"aws", "inmemory"}
+
+// txtRegistries are the registries storing the ownership in TXT records, whose names the TXT flags configure.
+var txtRegistries = []string{"txt", "dynamodb"}
+
+// providerFlag is a provider-specific flag, which is ignored by the other providers.
+type providerFlag struct {
+	flag      string
+	providers []string
+	set       func(cfg *externaldns.Config) bool
+}
+
+var providerFlags = []providerFlag{
+	{flag: "--aws-bounded-listing", providers: []string{"aws"}, set: func(cfg *externaldns.Config) bool { return cfg.AWSBoundedListing }},
+	{flag: "--aws-sd-create-namespaces", providers: []string{"aws-sd"}, set: func(cfg *externaldns.Config) bool { return cfg.AWSSDCreateNamespaces }},
+	{flag: "--aws-sd-namespace-vpc", providers: []string{"aws-sd"}, set: func(cfg *externaldns.Config) bool { return cfg.AWSSDNamespaceVPC != "" }},
+	{flag: "--aws-zone-match-parent", providers: []string{"aws"}, set: func(cfg *externaldns.Config) bool { return cfg.AWSZoneMatchParent }},
+	{flag: "--aws-zone-match-parent-vpc", providers: []string{"aws"}, set: func(cfg *externaldns.Config) bool { return hasValues(cfg.AWSZoneMatchParentVPCs) }},
+	{flag: "--aws-zone-tags", providers: []string{"aws"}, set: func(cfg *externaldns.Config) bool { return hasValues(cfg.AWSZoneTagFilter) }},
+	{flag: "--cloudflare-proxied", providers: []string{"cloudflare"}, set: func(cfg *externaldns.Config) bool { return cfg.CloudflareProxied }},
+	{flag: "--cloudflare-export-listing-threshold", providers: []string{"cloudflare"}, set: func(cfg *externaldns.Config) bool { return cfg.CloudflareExportListingThreshold > 0 }},
+	{flag: "--cloudflare-zone-tokens-file", providers: []string{"cloudflare"}, set: func(cfg *externaldns.Config) bool { return cfg.CloudflareZoneTokensFile != "" }},
+	{flag: "--inmemory-file", providers: []string{"inmemory"}, set: func(cfg *externaldns.Config) bool { return cfg.InMemoryFile != "" }},
+	{flag: "--inmemory-zone", providers: []string{"inmemory"}, set: func(cfg *externaldns.Config) bool { return hasValues(cfg.InMemoryZones) }},
+}
+
+// CheckCompatibility returns the combinations of flags that ExternalDNS accepts, but which don't have the intended effect:
+// registries unsupported by the provider, and flags ignored by the provider or the registry.
+func CheckCompatibility(cfg *externaldns.Config) []error {
+	var errs []error
+
+	switch {
+	case cfg.Provider == "aws-sd" && cfg.Registry != "aws-sd" && cfg.Registry != "noop":
+		errs = append(errs, fmt.Errorf("the aws-sd provider requires --registry=aws-sd or --registry=noop, --registry=%s is replaced with aws-sd", cfg.Registry))
+	case cfg.Registry == "aws-sd" && cfg.Provider != "aws-sd":
+		errs = append(errs, fmt.Errorf("--registry=aws-sd requires the aws-sd provider, not %s", cfg.Provider))
+	}
+
+	if !slices.Contains(zoneListingProviders, cfg.Provider) {
+		for _, flag := range []struct {
+			name string
+			set  bool
+		}{
+			{name: "--sync-per-zone", set: cfg.SyncPerZone},
+			{name: "--unroutable-hostname-cache-ttl", set: cfg.UnroutableHostnameCacheTTL > 0},
+			{name: "--preflight-check-write", set: cfg.PreflightCheckWrite},
+			{name: "--create-missing-zones", set: cfg.CreateMissingZones},
+			{name: "--registry=txt-apex", set: cfg.Registry == "txt-apex"},
+		} {
+			if flag.set {
+				errs = append(errs, fmt.Errorf("%s requires a provider listing its zones (%s), not %s", flag.name, strings.Join(zoneListingProviders, ", "), cfg.Provider))
+			}
+		}
+	}
+
+	for _, flag := range providerFlags {
+		if flag.set(cfg) && !slices.Contains(flag.providers, cfg.Provider) {
+			errs = append(errs, fmt.Errorf("%s is ignored by the %s provider, it is only supported by %s", flag.flag, cfg.Provider, strings.Join(flag.providers, ", ")))
+		}
+	}
+
+	if !slices.Contains(txtRegistries, cfg.Registry) {
+		for _, flag := range []struct {
+			name string
+			set  bool
+		}{
+			{name: "--txt-prefix", set: cfg.TXTPrefix != ""},
+			{name: "--txt-suffix", set: cfg.TXTSuffix != ""},
+			{name: "--txt-wildcard-replacement", set: cfg.TXTWildcardReplacement != ""},
+			{name: "--txt-encrypt-enabled", set: cfg.TXTEncryptEnabled},
+			{name: "--registry-lease-duration", set: cfg.RegistryLeaseDuration > 0},
+		} {
+			if flag.set {
+				errs = append(errs, fmt.Errorf("%s is ignored by --registry=%s, it is only supported by the %s registries", flag.name, cfg.Registry, strings.Join(txtRegistries, " and ")))
+			}
+		}
+	}
+
+	if cfg.AnnotationFilter != "" {
+		if _, err := labels.Parse(cfg.AnnotationFilter); err != nil {
+			errs = append(errs, fmt.Errorf("--annotation-filter does not specify a valid label selector: %w", err))
+		}
+	}
+	if filters, err := source.ParseSourceAnnotationFilters(cfg.SourceAnnotationFilters); err != nil {
+		errs = append(errs, fmt.Errorf("--source-annotation-filter: %w", err))
+	} else {
+		for name := range filters {
+			if !slices.Contains(cfg.Sources, name) {
+				errs = append(errs, fmt.Errorf("--source-annotation-filter overrides the annotation filter of the source %q, which is not enabled by --source", name))
+			}
+		}
+	}
+	return errs
+}
+
+// CheckConfig returns all problems of the configuration: the error of ValidateConfig, if any,
+// followed by the errors of CheckCompatibility.
+func CheckConfig(cfg *externaldns.Config) []error {
+	var errs []error
+	if err := ValidateConfig(cfg); err != nil {
+		errs = append(errs, err)
+	}
+	return append(errs, CheckCompatibility(cfg)...)
+}
+
+// hasValues returns true if a repeatable flag has a non-empty value, since their default is a single empty value.
+func hasValues(values []string) bool {
+	return slices.ContainsFunc(values, func(value string) bool { return value != "" })
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		update   func(cfg *externaldns.Config)
+		expected []string
+	}{
+		{
+			name: "compatible",
+			update: func(cfg *externaldns.Config) {
+				cfg.Provider = "aws"
+				cfg.SyncPerZone = true
+				cfg.AWSZoneTagFilter = []string{""}
+			},
+		},
+		{
+			name:     "aws-sd provider with txt registry",
+			update:   func(cfg *externaldns.Config) { cfg.Provider = "aws-sd"; cfg.Registry = "txt" },
+			expected: []string{"the aws-sd provider requires --registry=aws-sd or --registry=noop, --registry=txt is replaced with aws-sd"},
+		},
+		{
+			name:     "aws-sd registry with other provider",
+			update:   func(cfg *externaldns.Config) { cfg.Provider = "google"; cfg.Registry = "aws-sd" },
+			expected: []string{"--registry=aws-sd requires the aws-sd provider, not google"},
+		},
+		{
+			name: "zone listing",
+			update: func(cfg *externaldns.Config) {
+				cfg.Provider = "cloudflare"
+				cfg.SyncPerZone = true
+				cfg.PreflightCheckWrite = true
+				cfg.Registry = "txt-apex"
+			},
+			expected: []string{
+				"--sync-per-zone requires a provider listing its zones (aws, inmemory), not cloudflare",
+				"--preflight-check-write requires a provider listing its zones (aws, inmemory), not cloudflare",
+				"--registry=txt-apex requires a provider listing its zones (aws, inmemory), not cloudflare",
+			},
+		},
+		{
+			name: "provider flags",
+			update: func(cfg *externaldns.Config) {
+				cfg.Provider = "aws"
+				cfg.CloudflareProxied = true
+				cfg.InMemoryZones = []string{"example.org"}
+			},
+			expected: []string{
+				"--cloudflare-proxied is ignored by the aws provider, it is only supported by cloudflare",
+				"--inmemory-zone is ignored by the aws provider, it is only supported by inmemory",
+			},
+		},
+		{
+			name:     "txt flags",
+			update:   func(cfg *externaldns.Config) { cfg.Registry = "noop"; cfg.TXTPrefix = "prefix-" },
+			expected: []string{"--txt-prefix is ignored by --registry=noop, it is only supported by the txt and dynamodb registries"},
+		},
+		{
+			name:     "registry lease",
+			update:   func(cfg *externaldns.Config) { cfg.Registry = "noop"; cfg.RegistryLeaseDuration = 5 * time.Minute },
+			expected: []string{"--registry-lease-duration is ignored by --registry=noop, it is only supported by the txt and dynamodb registries"},
+		},
+		{
+			name:     "annotation filter",
+			update:   func(cfg *externaldns.Config) { cfg.AnnotationFilter = "kubernetes.io/ingress.class in (" },
+			expected: []string{"--annotation-filter does not specify a valid label selector"},
+		},
+		{
+			name:     "source annotation filter",
+			update:   func(cfg *externaldns.Config) { cfg.SourceAnnotationFilters = []string{"ingress=team in ("} },
+			expected: []string{"--source-annotation-filter: annotation filter \"team in (\" of source \"ingress\" is not a valid label selector"},
+		},
+		{
+			name:     "source annotation filter of a disabled source",
+			update:   func(cfg *externaldns.Config) { cfg.SourceAnnotationFilters = []string{"node=team notin (infra)"} },
+			expected: []string{"--source-annotation-filter overrides the annotation filter of the source \"node\", which is not enabled by --source"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := newValidConfig(t)
+			tc.update(cfg)
+			errs := CheckCompatibility(cfg)
+			if assert.Len(t, errs, len(tc.expected)) {
+				for i, expected := range tc.expected {
+					assert.ErrorContains(t, errs[i], expected)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	cfg := newValidConfig(t)
+	assert.Empty(t, CheckConfig(cfg))
+
+	cfg.TXTPrefix = "prefix-"
+	cfg.TXTSuffix = "-suffix"
+	cfg.Registry = "noop"
+	errs := CheckConfig(cfg)
+	if assert.Len(t, errs, 3) {
+		assert.EqualError(t, errs[0], "txt-prefix and txt-suffix are mutual exclusive")
+	}
+}
@@ -19,12 +19,34 @@ package validation
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/labels"
 
+	"sigs.k8s.io/external-dns/endpoint"
 	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+	"sigs.k8s.io/external-dns/source"
 )
 
+// apiBudgetProviders are the providers counting their API requests and listing the records of a single zone, as
+// --sync-per-zone requires, see --provider-api-budget-per-cycle.
+var apiBudgetProviders = []string{"aws"}
+
+// providerValidators validate the flags parsed by the packages of the providers. They are registered by the files of
+// the providers in package externaldnsrun, which are built with the providers only, so that this package does not
+// link the providers and their SDKs.
+var providerValidators []func(cfg *externaldns.Config) error
+
+// RegisterProviderValidator adds the validation of the flags of a provider to ValidateConfig.
+func RegisterProviderValidator(validate func(cfg *externaldns.Config) error) {
+	providerValidators = append(providerValidators, validate)
+}
+
 // ValidateConfig performs validation on the Config object
 func ValidateConfig(cfg *externaldns.Config) error {
 	// TODO: Should probably return field.ErrorList
@@ -34,6 +56,19 @@ func ValidateConfig(cfg *externaldns.Config) error {
 	if len(cfg.Sources) == 0 {
 		return errors.New("no sources specified")
 	}
+	profiles, err := source.ParseFilterProfiles(cfg.FilterProfiles)
+	if err != nil {
+		return fmt.Errorf("--filter-profile: %w", err)
+	}
+	bindings, err := source.ParseSourceFilterProfiles(cfg.SourceFilterProfiles, profiles)
+	if err != nil {
+		return fmt.Errorf("--source-filter-profile: %w", err)
+	}
+	for name := range bindings {
+		if !slices.Contains(cfg.Sources, name) {
+			return fmt.Errorf("--source-filter-profile: the source %q is not enabled by --source", name)
+		}
+	}
 	if cfg.Provider == "" {
 		return errors.New("no provider specified")
 	}
@@ -45,6 +80,26 @@ func ValidateConfig(cfg *externaldns.Config) error {
 		}
 	}
 
+	if len(cfg.AzureSubscriptionIDs) > 0 || cfg.AzureManagementGroup != "" {
+		switch cfg.Provider {
+		case "azure", "azure-dns", "azure-private-dns":
+		default:
+			return errors.New("--azure-subscription-ids and --azure-management-group require --provider=azure or --provider=azure-private-dns")
+		}
+		if cfg.AzureSubscriptionID != "" {
+			return errors.New("--azure-subscription-id cannot be combined with --azure-subscription-ids or --azure-management-group")
+		}
+		if len(cfg.AzureSubscriptionIDs) > 0 && cfg.AzureManagementGroup != "" {
+			return errors.New("--azure-subscription-ids and --azure-management-group are mutually exclusive")
+		}
+	}
+
+	if len(cfg.AzurePrivateDNSVirtualNetworks) > 0 {
+		if cfg.Provider != "azure-private-dns" {
+			return errors.New("--azure-private-dns-virtual-network requires --provider=azure-private-dns")
+		}
+	}
+
 	// Akamai provider specific validations
 	if cfg.Provider == "akamai" {
 		if cfg.AkamaiServiceConsumerDomain == "" && cfg.AkamaiEdgercPath != "" {
@@ -61,6 +116,10 @@ func ValidateConfig(cfg *externaldns.Config) error {
 		}
 	}
 
+	if cfg.GoogleCredentialsFile != "" && cfg.GoogleCredentialsJSON != "" {
+		return errors.New("--google-credentials-file and --google-credentials-json are mutually exclusive")
+	}
+
 	if cfg.Provider == "rfc2136" {
 		if cfg.RFC2136MinTTL < 0 {
 			return errors.New("TTL specified for rfc2136 is negative")
@@ -81,6 +140,183 @@ func ValidateConfig(cfg *externaldns.Config) error {
 		}
 	}
 
+	if cfg.Provider == "git" {
+		if cfg.GitRepositoryURL == "" {
+			return errors.New("--git-repository is required when using the git provider")
+		}
+		if len(cfg.GitZones) == 0 {
+			return errors.New("at least one --git-zone is required when using the git provider")
+		}
+	}
+
+	if cfg.HealthCheck {
+		if cfg.HealthCheckInterval <= 0 || cfg.HealthCheckTimeout <= 0 {
+			return errors.New("--health-check-interval and --health-check-timeout must be positive")
+		}
+		if cfg.HealthCheckHealthyThreshold < 1 || cfg.HealthCheckUnhealthyThreshold < 1 {
+			return errors.New("--health-check-healthy-threshold and --health-check-unhealthy-threshold must be at least 1")
+		}
+	}
+
+	if cfg.GSLBCluster != "" {
+		if strings.ContainsAny(cfg.GSLBCluster, ".,=;") {
+			return errors.New("--gslb-cluster must be a single DNS label")
+		}
+		if cfg.GSLBLeaseDuration <= cfg.Interval {
+			return errors.New("--gslb-lease-duration must be longer than --interval")
+		}
+		if !slices.Contains(cfg.ManagedDNSRecordTypes, endpoint.RecordTypeTXT) {
+			return errors.New("--managed-record-types must include TXT to publish the hints of the GSLB mode")
+		}
+	}
+
+	if slices.Contains(cfg.ExcludeDNSRecordTypes, endpoint.RecordTypeTXT) {
+		// the excluded record types are not listed
+		if cfg.Registry == "txt" {
+			return errors.New("--exclude-record-types must not include TXT with the txt registry, which stores the ownership in TXT records")
+		}
+		if cfg.GSLBCluster != "" {
+			return errors.New("--exclude-record-types must not include TXT to publish the hints of the GSLB mode")
+		}
+	}
+
+	if cfg.CreateMissingZones {
+		if cfg.SyncPerZone {
+			return errors.New("--create-missing-zones cannot be used with --sync-per-zone, which synchronizes the existing zones only")
+		}
+		if cfg.UnroutableHostnameCacheTTL > 0 {
+			return errors.New("--create-missing-zones cannot be used with --unroutable-hostname-cache-ttl, which drops the endpoints of missing zones")
+		}
+		if cfg.Provider == "aws" && cfg.AWSZoneType == "private" && len(cfg.AWSZoneMatchParentVPCs) == 0 {
+			return errors.New("--create-missing-zones requires --aws-zone-match-parent-vpc with --aws-zone-type=private to associate the created zones with a VPC")
+		}
+	}
+	if cfg.DeleteEmptyZones && !cfg.CreateMissingZones {
+		return errors.New("--delete-empty-zones requires --create-missing-zones")
+	}
+
+	if cfg.SyncPerZone && cfg.GSLBCluster != "" {
+		return errors.New("--sync-per-zone cannot be used with --gslb-cluster, which requires the records of all zones")
+	}
+
+	if cfg.CloudflareExportListingThreshold < 0 {
+		return errors.New("--cloudflare-export-listing-threshold must not be negative")
+	}
+	if cfg.CloudflareRegionKey != "" && !cfg.CloudflareRegionalServices {
+		return errors.New("--cloudflare-region-key requires --cloudflare-regional-services")
+	}
+	if cfg.DNSimpleBatchSize < 0 {
+		return errors.New("--dnsimple-batch-size must not be negative")
+	}
+	if cfg.DNSimpleAPIRateLimit < 0 {
+		return errors.New("--dnsimple-api-rate-limit must not be negative")
+	}
+	if cfg.LinodeAPIRateLimit < 0 {
+		return errors.New("--linode-api-rate-limit must not be negative")
+	}
+	if cfg.Provider == "cloudflare" && cfg.CloudflareListingWorkers < 1 {
+		return errors.New("--cloudflare-listing-workers must be at least 1")
+	}
+
+	if cfg.ProviderTimeout < 0 {
+		return errors.New("--provider-timeout must not be negative")
+	}
+	if cfg.ProviderFaultInjection != "" {
+		if _, err := provider.ParseFaults(cfg.ProviderFaultInjection); err != nil {
+			return fmt.Errorf("--provider-fault-injection: %w", err)
+		}
+	}
+	if len(cfg.InMemorySimulation) > 0 {
+		if cfg.Provider != "inmemory" {
+			return errors.New("--inmemory-simulation is only supported by the inmemory provider")
+		}
+		if _, err := inmemory.ParseSimulation(cfg.InMemorySimulation); err != nil {
+			return fmt.Errorf("--inmemory-simulation: %w", err)
+		}
+	}
+	if cfg.AWSCredentialsRefreshInterval < 0 {
+		return errors.New("--aws-credentials-refresh-interval must not be negative")
+	}
+	if cfg.AWSEndpointURL != "" {
+		if u, err := url.Parse(cfg.AWSEndpointURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("--aws-endpoint-url must be an http or https URL, got %q", cfg.AWSEndpointURL)
+		}
+	}
+	if cfg.AWSProxyURL != "" {
+		if u, err := url.Parse(cfg.AWSProxyURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("--aws-proxy-url must be an http or https URL, got %q", cfg.AWSProxyURL)
+		}
+	}
+	if d := cfg.AWSAssumeRoleSessionDuration; d != 0 && (d < 15*time.Minute || d > 12*time.Hour) {
+		return fmt.Errorf("--aws-assume-role-session-duration must be between 15m and 12h, got %s", d)
+	}
+	if cfg.AWSSDCreateNamespaces {
+		if cfg.AWSZoneType == "private" && cfg.AWSSDNamespaceVPC == "" {
+			return errors.New("--aws-sd-create-namespaces requires --aws-sd-namespace-vpc with --aws-zone-type=private")
+		}
+		if cfg.AWSZoneType == "public" && cfg.AWSSDNamespaceVPC != "" {
+			return errors.New("--aws-sd-namespace-vpc creates private namespaces, which --aws-zone-type=public ignores")
+		}
+	}
+	if cfg.AWSAPIDefaultRateLimit < 0 {
+		return errors.New("--aws-api-default-rate-limit must not be negative")
+	}
+	for _, validate := range providerValidators {
+		if err := validate(cfg); err != nil {
+			return err
+		}
+	}
+
+	if len(cfg.PropagationCheckResolvers) > 0 && (cfg.PropagationCheckInterval <= 0 || cfg.PropagationCheckTimeout <= 0) {
+		return errors.New("--propagation-check-interval and --propagation-check-timeout must be positive")
+	}
+
+	if cfg.StatusAPIReconcileTokenFile != "" && !cfg.StatusAPI {
+		return errors.New("--status-api-reconcile-token-file requires --status-api")
+	}
+
+	if cfg.UnroutableHostnameCacheTTL < 0 {
+		return errors.New("--unroutable-hostname-cache-ttl must not be negative")
+	}
+
+	if cfg.OutOfSyncCycles < 0 {
+		return errors.New("--out-of-sync-cycles must not be negative")
+	}
+
+	if cfg.TTLRepairInterval < 0 {
+		return errors.New("--ttl-repair-interval must not be negative")
+	}
+
+	if cfg.PreflightCheckWrite && !cfg.PreflightCheck {
+		return errors.New("--preflight-check-write requires --preflight-check")
+	}
+	if cfg.PreflightCheckWrite && cfg.DryRun {
+		return errors.New("--preflight-check-write changes records, it cannot be used with --dry-run")
+	}
+
+	if cfg.ProviderAPIQuotaHeadroom < 0 || cfg.ProviderAPIQuotaHeadroom >= 1 {
+		return errors.New("--provider-api-quota-headroom must be at least 0 and less than 1")
+	}
+	if cfg.ProviderAPIBudgetPerCycle < 0 {
+		return errors.New("--provider-api-budget-per-cycle must not be negative")
+	}
+	if cfg.ProviderAPIBudgetPerCycle > 0 && !cfg.SyncPerZone {
+		return errors.New("--provider-api-budget-per-cycle requires --sync-per-zone")
+	}
+	if cfg.ProviderAPIBudgetPerCycle > 0 && !slices.Contains(apiBudgetProviders, cfg.Provider) {
+		return fmt.Errorf("--provider-api-budget-per-cycle is not supported by the %s provider, only by the providers counting their API requests and listing the records of a single zone: %s", cfg.Provider, strings.Join(apiBudgetProviders, ", "))
+	}
+	if cfg.SkipUnchangedZones && !cfg.SyncPerZone {
+		return errors.New("--skip-unchanged-zones requires --sync-per-zone")
+	}
+
+	if cfg.MaxMemoryEndpoints < 0 {
+		return errors.New("--max-memory-endpoints must not be negative")
+	}
+	if cfg.MaxMemoryEndpoints > 0 && cfg.GSLBCluster != "" {
+		return errors.New("--max-memory-endpoints cannot be used with --gslb-cluster, which requires the records of all zones in memory")
+	}
+
 	if cfg.IgnoreHostnameAnnotation && cfg.FQDNTemplate == "" {
 		return errors.New("FQDN Template must be set if ignoring annotations")
 	}
@@ -88,10 +324,94 @@ func ValidateConfig(cfg *externaldns.Config) error {
 	if len(cfg.TXTPrefix) > 0 && len(cfg.TXTSuffix) > 0 {
 		return errors.New("txt-prefix and txt-suffix are mutual exclusive")
 	}
+	if cfg.TXTRecordTTL < 0 {
+		return errors.New("--txt-record-ttl must not be negative")
+	}
+	for _, domain := range cfg.TXTOwnerDomains {
+		if label, parent, _ := strings.Cut(strings.TrimSuffix(domain, "."), "."); label == "" || !strings.Contains(parent, ".") {
+			return fmt.Errorf("--txt-owner-domain %q must be a subdomain of a domain, e.g. _owner.example.com", domain)
+		}
+	}
+	if cfg.TXTMigrate {
+		if cfg.Registry != "txt" {
+			return errors.New("--txt-prefix-migrate-from and --txt-suffix-migrate-from require the txt registry")
+		}
+		if len(cfg.TXTPrefixMigrateFrom) > 0 && len(cfg.TXTSuffixMigrateFrom) > 0 {
+			return errors.New("txt-prefix-migrate-from and txt-suffix-migrate-from are mutual exclusive")
+		}
+		if strings.EqualFold(cfg.TXTPrefixMigrateFrom, cfg.TXTPrefix) && strings.EqualFold(cfg.TXTSuffixMigrateFrom, cfg.TXTSuffix) && len(cfg.TXTOwnerDomains) == 0 {
+			return errors.New("the naming scheme migrated from must differ from --txt-prefix and --txt-suffix")
+		}
+	}
+
+	if cfg.AWSBoundedListing && cfg.Registry == "txt" && !strings.HasSuffix(cfg.TXTPrefix, ".") {
+		// the TXT records of a domain filter are named after it in its parent domain otherwise, out of the listed names
+		return errors.New("--aws-bounded-listing requires a --txt-prefix ending with a dot, e.g. _externaldns.")
+	}
+
+	if cfg.RegistryLeaseDuration < 0 {
+		return errors.New("--registry-lease-duration must not be negative")
+	}
+	if cfg.RegistryLeaseDuration > 0 && cfg.RegistryLeaseDuration <= cfg.Interval {
+		// the lease would expire between two renewals
+		return fmt.Errorf("--registry-lease-duration must be greater than --interval (%s)", cfg.Interval)
+	}
+
+	if cfg.RegistryGCInterval < 0 {
+		return errors.New("--registry-gc-interval must not be negative")
+	}
+	if cfg.RegistryGCInterval > 0 && cfg.Registry != "txt" && cfg.Registry != "dynamodb" {
+		return fmt.Errorf("--registry-gc-interval is not supported by the %s registry", cfg.Registry)
+	}
 
-	_, err := labels.Parse(cfg.LabelFilter)
+	if cfg.SlowCycleProfileThreshold < 0 {
+		return errors.New("--slow-cycle-profile-threshold must not be negative")
+	}
+	if cfg.SlowCycleProfileThreshold > 0 && cfg.SlowCycleProfileMaxCaptures < 1 {
+		return errors.New("--slow-cycle-profile-max-captures must be at least 1")
+	}
+
+	if err := validateListeners(cfg); err != nil {
+		return err
+	}
+
+	_, err = labels.Parse(cfg.LabelFilter)
 	if err != nil {
 		return errors.New("--label-filter does not specify a valid label selector")
 	}
 	return nil
 }
+
+// validateListeners checks the flags of the metrics, health check and profiling listeners.
+func validateListeners(cfg *externaldns.Config) error {
+	listeners := []struct {
+		name                       string
+		address, certFile, keyFile string
+		clientCAFile               string
+		basicAuthFile              string
+	}{
+		{"metrics", cfg.MetricsAddress, cfg.MetricsTLSCertFile, cfg.MetricsTLSKeyFile, cfg.MetricsTLSClientCAFile, cfg.MetricsBasicAuthFile},
+		{"healthz", cfg.HealthzAddress, cfg.HealthzTLSCertFile, cfg.HealthzTLSKeyFile, cfg.HealthzTLSClientCAFile, cfg.HealthzBasicAuthFile},
+		{"debug", cfg.DebugAddress, cfg.DebugTLSCertFile, cfg.DebugTLSKeyFile, cfg.DebugTLSClientCAFile, cfg.DebugBasicAuthFile},
+	}
+	addresses := map[string]string{}
+	for _, l := range listeners {
+		if (l.certFile == "") != (l.keyFile == "") {
+			return fmt.Errorf("--%s-tls-cert-file and --%s-tls-key-file must be set together", l.name, l.name)
+		}
+		if l.clientCAFile != "" && l.certFile == "" {
+			return fmt.Errorf("--%s-tls-client-ca-file requires --%s-tls-cert-file", l.name, l.name)
+		}
+		if l.address == "" {
+			if l.certFile != "" || l.basicAuthFile != "" {
+				return fmt.Errorf("the TLS and basic auth flags of the %s listener require --%s-address", l.name, l.name)
+			}
+			continue
+		}
+		if other, ok := addresses[l.address]; ok {
+			return fmt.Errorf("--%s-address and --%s-address must be different", other, l.name)
+		}
+		addresses[l.address] = l.name
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ package validation
 
 import (
 	"testing"
+	"time"
 
 	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
 
@@ -227,3 +228,498 @@ func TestValidateGoodRfc2136GssTsigConfig(t *testing.T) {
 		assert.Nil(t, err)
 	}
 }
+
+func TestValidateGitConfig(t *testing.T) {
+	cfg := externaldns.NewConfig()
+	cfg.LogFormat = "json"
+	cfg.Sources = []string{"test-source"}
+	cfg.Provider = "git"
+
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.GitRepositoryURL = "https://example.com/dns.git"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.GitZones = []string{"example.com"}
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateHealthCheckConfig(t *testing.T) {
+	cfg := externaldns.NewConfig()
+	cfg.LogFormat = "json"
+	cfg.Sources = []string{"test-source"}
+	cfg.Provider = "test-provider"
+	cfg.HealthCheck = true
+
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.HealthCheckInterval = 10 * time.Second
+	cfg.HealthCheckTimeout = 5 * time.Second
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.HealthCheckHealthyThreshold = 2
+	cfg.HealthCheckUnhealthyThreshold = 3
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateGSLBConfig(t *testing.T) {
+	cfg := externaldns.NewConfig()
+	cfg.LogFormat = "json"
+	cfg.Sources = []string{"test-source"}
+	cfg.Provider = "test-provider"
+	cfg.Interval = time.Minute
+	cfg.GSLBLeaseDuration = 5 * time.Minute
+	cfg.ManagedDNSRecordTypes = []string{"A", "CNAME"}
+
+	cfg.GSLBCluster = "eu.west"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.GSLBCluster = "eu-west"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.ManagedDNSRecordTypes = append(cfg.ManagedDNSRecordTypes, "TXT")
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.GSLBLeaseDuration = time.Minute
+	assert.Error(t, ValidateConfig(cfg))
+}
+
+func TestValidateMaxMemoryEndpoints(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.MaxMemoryEndpoints = -1
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.MaxMemoryEndpoints = 250000
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.GSLBCluster = "eu-west"
+	cfg.Interval = time.Minute
+	cfg.GSLBLeaseDuration = 5 * time.Minute
+	cfg.ManagedDNSRecordTypes = append(cfg.ManagedDNSRecordTypes, "TXT")
+	assert.EqualError(t, ValidateConfig(cfg), "--max-memory-endpoints cannot be used with --gslb-cluster, which requires the records of all zones in memory")
+}
+
+func TestValidateSyncPerZone(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.SyncPerZone = true
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.GSLBCluster = "eu-west"
+	cfg.ManagedDNSRecordTypes = append(cfg.ManagedDNSRecordTypes, "TXT")
+	assert.Error(t, ValidateConfig(cfg))
+}
+
+func TestValidateProviderAPIBudgetPerCycle(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Provider = "aws"
+	cfg.ProviderAPIBudgetPerCycle = 100
+	assert.EqualError(t, ValidateConfig(cfg), "--provider-api-budget-per-cycle requires --sync-per-zone")
+
+	cfg.SyncPerZone = true
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.ProviderAPIBudgetPerCycle = -1
+	assert.Error(t, ValidateConfig(cfg))
+
+	// these providers count their API requests, but do not list the records of a single zone
+	for _, p := range []string{"cloudflare", "digitalocean", "dnsimple", "godaddy", "linode", "inmemory"} {
+		t.Run(p, func(t *testing.T) {
+			cfg := externaldns.NewConfig()
+			require.NoError(t, cfg.ParseFlags([]string{"--source=service", "--provider=" + p, "--sync-per-zone", "--provider-api-budget-per-cycle=100"}))
+			assert.EqualError(t, ValidateConfig(cfg), "--provider-api-budget-per-cycle is not supported by the "+p+" provider, only by the providers counting their API requests and listing the records of a single zone: aws")
+		})
+	}
+}
+
+func TestValidateProviderAPIQuotaHeadroom(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.ProviderAPIQuotaHeadroom = 0
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.ProviderAPIQuotaHeadroom = 1
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.ProviderAPIQuotaHeadroom = -0.1
+	assert.Error(t, ValidateConfig(cfg))
+}
+
+func TestValidateSkipUnchangedZones(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.SkipUnchangedZones = true
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.SyncPerZone = true
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateAWSBoundedListing(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.AWSBoundedListing = true
+	cfg.Registry = "txt"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.TXTPrefix = "_externaldns."
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.TXTPrefix = ""
+	cfg.Registry = "dynamodb"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidatePreflightCheck(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.PreflightCheckWrite = true
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.PreflightCheck = true
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.DryRun = true
+	assert.Error(t, ValidateConfig(cfg))
+}
+
+func TestValidateProviderTimeout(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.ProviderTimeout = -time.Second
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.ProviderTimeout = 5 * time.Minute
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateProviderFaultInjection(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.ProviderFaultInjection = "error-rate:2"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.ProviderFaultInjection = "latency:500ms,error-rate:0.1"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateCloudflareRegionKey(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.CloudflareRegionKey = "eu"
+	assert.EqualError(t, ValidateConfig(cfg), "--cloudflare-region-key requires --cloudflare-regional-services")
+
+	cfg.CloudflareRegionalServices = true
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateProviderBatchAndRateLimits(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.DNSimpleBatchSize = -1
+	assert.EqualError(t, ValidateConfig(cfg), "--dnsimple-batch-size must not be negative")
+
+	cfg = newValidConfig(t)
+	cfg.LinodeAPIRateLimit = -1
+	assert.EqualError(t, ValidateConfig(cfg), "--linode-api-rate-limit must not be negative")
+
+	cfg = newValidConfig(t)
+	cfg.DNSimpleBatchSize = 100
+	cfg.DNSimpleAPIRateLimit = 2400
+	cfg.LinodeAPIRateLimit = 10
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateCloudflareListingWorkers(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Provider = "cloudflare"
+	cfg.CloudflareListingWorkers = 0
+	assert.EqualError(t, ValidateConfig(cfg), "--cloudflare-listing-workers must be at least 1")
+
+	cfg.CloudflareListingWorkers = 16
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateInMemorySimulation(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.InMemorySimulation = []string{"latency:20ms"}
+	assert.EqualError(t, ValidateConfig(cfg), "--inmemory-simulation is only supported by the inmemory provider")
+
+	cfg.Provider = "inmemory"
+	cfg.InMemorySimulation = []string{"example.org=page-size:-1"}
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.InMemorySimulation = []string{"latency:20ms,page-size:100", "example.org=throttle-rate:0.1,propagation-delay:30s"}
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateAWSEndpointURL(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.AWSEndpointURL = "localhost:4566"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.AWSEndpointURL = "http://localhost:4566"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateAWSProxyURL(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.AWSProxyURL = "proxy.example.com:3128"
+	assert.EqualError(t, ValidateConfig(cfg), `--aws-proxy-url must be an http or https URL, got "proxy.example.com:3128"`)
+
+	cfg.AWSProxyURL = "http://proxy.example.com:3128"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateStatusAPIReconcileTokenFile(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.StatusAPIReconcileTokenFile = "/etc/external-dns/reconcile-token"
+	assert.EqualError(t, ValidateConfig(cfg), "--status-api-reconcile-token-file requires --status-api")
+
+	cfg.StatusAPI = true
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateGoogleCredentials(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Provider = "google"
+	cfg.GoogleCredentialsFile = "/etc/google/credentials.json"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.GoogleCredentialsJSON = `{"type": "external_account"}`
+	assert.EqualError(t, ValidateConfig(cfg), "--google-credentials-file and --google-credentials-json are mutually exclusive")
+
+	cfg.GoogleCredentialsFile = ""
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateAzureProviders(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Provider = "azure-private-dns"
+	cfg.AzureSubscriptionIDs = []string{"sub1"}
+	cfg.AzureManagementGroup = "platform"
+	assert.EqualError(t, ValidateConfig(cfg), "--azure-subscription-ids and --azure-management-group are mutually exclusive")
+
+	cfg.AzureSubscriptionIDs = nil
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.AzureSubscriptionID = "sub1"
+	assert.EqualError(t, ValidateConfig(cfg), "--azure-subscription-id cannot be combined with --azure-subscription-ids or --azure-management-group")
+
+	cfg.AzureSubscriptionID = ""
+	cfg.Provider = "aws"
+	assert.EqualError(t, ValidateConfig(cfg), "--azure-subscription-ids and --azure-management-group require --provider=azure or --provider=azure-private-dns")
+
+	cfg.AzureManagementGroup = ""
+	cfg.AzurePrivateDNSVirtualNetworks = []string{"prod"}
+	assert.EqualError(t, ValidateConfig(cfg), "--azure-private-dns-virtual-network requires --provider=azure-private-dns")
+}
+
+func TestValidateAWSSDCreateNamespaces(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Provider = "aws-sd"
+	cfg.AWSSDCreateNamespaces = true
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.AWSZoneType = "private"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.AWSSDNamespaceVPC = "vpc-111"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.AWSZoneType = "public"
+	assert.Error(t, ValidateConfig(cfg))
+}
+
+func TestValidateCreateMissingZones(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.DeleteEmptyZones = true
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.CreateMissingZones = true
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.SyncPerZone = true
+	assert.Error(t, ValidateConfig(cfg))
+	cfg.SyncPerZone = false
+
+	cfg.UnroutableHostnameCacheTTL = time.Minute
+	assert.Error(t, ValidateConfig(cfg))
+	cfg.UnroutableHostnameCacheTTL = 0
+
+	cfg.Provider = "aws"
+	cfg.AWSZoneType = "private"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.AWSZoneMatchParentVPCs = []string{"vpc-111"}
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateFilterProfiles(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Sources = []string{"node", "ingress"}
+	cfg.FilterProfiles = []string{"infra"}
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.FilterProfiles = []string{"infra:domain=infra.example.com", "apps:domain=example.com,exclude-domain=infra.example.com"}
+	cfg.SourceFilterProfiles = []string{"node=other"}
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.SourceFilterProfiles = []string{"service=apps"}
+	assert.EqualError(t, ValidateConfig(cfg), `--source-filter-profile: the source "service" is not enabled by --source`)
+
+	cfg.SourceFilterProfiles = []string{"node=infra,ingress=apps"}
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateAWSCredentialsRefreshInterval(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.AWSCredentialsRefreshInterval = -time.Minute
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.AWSCredentialsRefreshInterval = 5 * time.Minute
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidatePropagationCheck(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.PropagationCheckResolvers = []string{"1.1.1.1"}
+	cfg.PropagationCheckInterval = 0
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.PropagationCheckInterval = 10 * time.Second
+	cfg.PropagationCheckTimeout = 10 * time.Minute
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateUnroutableHostnameCacheTTL(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.UnroutableHostnameCacheTTL = -time.Second
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.UnroutableHostnameCacheTTL = 10 * time.Minute
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateOutOfSyncCycles(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.OutOfSyncCycles = -1
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.OutOfSyncCycles = 3
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateRegistryLeaseDuration(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Interval = time.Minute
+	cfg.RegistryLeaseDuration = -time.Second
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.RegistryLeaseDuration = time.Minute
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.RegistryLeaseDuration = 5 * time.Minute
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateTTLRepairInterval(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.TTLRepairInterval = -time.Minute
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.TTLRepairInterval = time.Hour
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateTXTMigrate(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Registry = "txt"
+	cfg.TXTPrefix = "_externaldns."
+	cfg.TXTMigrate = true
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.TXTPrefixMigrateFrom = "_EXTERNALDNS."
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.TXTPrefixMigrateFrom = "txt-"
+	cfg.TXTSuffixMigrateFrom = "-txt"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.TXTSuffixMigrateFrom = ""
+	cfg.Registry = "noop"
+	assert.Error(t, ValidateConfig(cfg))
+}
+
+func TestValidateTXTRecordOptions(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.TXTRecordTTL = -1
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.TXTRecordTTL = 3600
+	cfg.TXTOwnerDomains = []string{"example.com"}
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.TXTOwnerDomains = []string{"_owner.example.com", "_owner.example.org."}
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateRegistryGCInterval(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.RegistryGCInterval = -time.Minute
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.RegistryGCInterval = time.Hour
+	cfg.Registry = "noop"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.Registry = "txt"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateExcludeRecordTypes(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.ExcludeDNSRecordTypes = []string{"NS", "TXT"}
+	cfg.Registry = "txt"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.Registry = "noop"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.ExcludeDNSRecordTypes = []string{"NS"}
+	cfg.Registry = "txt"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateListeners(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.MetricsAddress = ":7979"
+	cfg.HealthzAddress = ":8080"
+	cfg.DebugAddress = "127.0.0.1:6060"
+	cfg.DebugTLSCertFile = "/etc/tls/tls.crt"
+	cfg.DebugTLSKeyFile = "/etc/tls/tls.key"
+	cfg.DebugBasicAuthFile = "/etc/auth/users"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.DebugTLSKeyFile = ""
+	assert.EqualError(t, ValidateConfig(cfg), "--debug-tls-cert-file and --debug-tls-key-file must be set together")
+
+	cfg.DebugTLSKeyFile = "/etc/tls/tls.key"
+	cfg.MetricsTLSClientCAFile = "/etc/tls/ca.crt"
+	assert.EqualError(t, ValidateConfig(cfg), "--metrics-tls-client-ca-file requires --metrics-tls-cert-file")
+
+	cfg.MetricsTLSClientCAFile = ""
+	cfg.DebugTLSClientCAFile = "/etc/tls/ca.crt"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.HealthzAddress = ":7979"
+	assert.EqualError(t, ValidateConfig(cfg), "--metrics-address and --healthz-address must be different")
+
+	cfg.HealthzAddress = ""
+	cfg.HealthzBasicAuthFile = "/etc/auth/users"
+	assert.EqualError(t, ValidateConfig(cfg), "the TLS and basic auth flags of the healthz listener require --healthz-address")
+}
+
+func TestValidateSlowCycleProfile(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.SlowCycleProfileThreshold = -time.Second
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.SlowCycleProfileThreshold = time.Minute
+	cfg.SlowCycleProfileMaxCaptures = 0
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.SlowCycleProfileMaxCaptures = 10
+	assert.NoError(t, ValidateConfig(cfg))
+}
@@ -0,0 +1,112 @@
+//go:build !select_providers || provider_aws || provider_awssd
+
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaldnsrun
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns/validation"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/provider/aws"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+// The AWS flags are shared by the aws and aws-sd providers and the DynamoDB registry, this file is built with either
+// of the providers.
+func init() {
+	validation.RegisterProviderValidator(validateAWSConfig)
+	registerRegistry(buildDynamoDBRegistry, "dynamodb")
+}
+
+// awsRoleSessionName matches the names of the role sessions allowed by STS.
+var awsRoleSessionName = regexp.MustCompile(`^[\w+=,.@-]{2,64}$`)
+
+func validateAWSConfig(cfg *externaldns.Config) error {
+	if name := cfg.AWSAssumeRoleSessionName; name != "" && !awsRoleSessionName.MatchString(aws.ExpandSessionName(name, "cluster", "owner", "pod")) {
+		return fmt.Errorf("--aws-assume-role-session-name must be 2 to 64 letters, digits or characters of _+=,.@- or placeholders, got %q", name)
+	}
+	if id := cfg.AWSAssumeRoleSourceIdentity; id != "" && (!awsRoleSessionName.MatchString(id) || strings.HasPrefix(strings.ToLower(id), "aws:")) {
+		return fmt.Errorf("--aws-assume-role-source-identity must be 2 to 64 letters, digits or characters of _+=,.@- not starting with aws:, got %q", id)
+	}
+	if len(cfg.AWSAssumeRoleChain) > 0 {
+		if _, err := aws.ParseRoleChain(cfg.AWSAssumeRoleChain); err != nil {
+			return fmt.Errorf("--aws-assume-role-chain: %w", err)
+		}
+		// the sessions of the roles assumed with the credentials of other roles last one hour at most
+		if cfg.AWSAssumeRoleSessionDuration > time.Hour {
+			return fmt.Errorf("--aws-assume-role-session-duration must be 1h at most with --aws-assume-role-chain, got %s", cfg.AWSAssumeRoleSessionDuration)
+		}
+	}
+	if len(cfg.AWSAssumeRoleSessionTags) > 0 {
+		tags, err := aws.ParseSessionTags(cfg.AWSAssumeRoleSessionTags)
+		if err != nil {
+			return fmt.Errorf("--aws-assume-role-session-tag: %w", err)
+		}
+		if len(tags) > 50 {
+			return fmt.Errorf("--aws-assume-role-session-tag: at most 50 session tags are allowed, got %d", len(tags))
+		}
+		for key, value := range tags {
+			if len(key) > 128 || len(value) > 256 {
+				return fmt.Errorf("--aws-assume-role-session-tag: the key %q or its value is too long", key)
+			}
+		}
+	}
+	if len(cfg.AWSZoneRoles) > 0 {
+		if _, err := aws.ParseZoneRoles(cfg.AWSZoneRoles); err != nil {
+			return fmt.Errorf("--aws-zone-role: %w", err)
+		}
+	}
+	if len(cfg.AWSProfileRoles) > 0 {
+		profileRoles, err := aws.ParseProfileRoles(cfg.AWSProfileRoles)
+		if err != nil {
+			return fmt.Errorf("--aws-profile-role: %w", err)
+		}
+		for profile := range profileRoles {
+			if !slices.Contains(cfg.AWSProfiles, profile) {
+				return fmt.Errorf("--aws-profile-role: the profile %q is not enabled by --aws-profile", profile)
+			}
+		}
+	}
+	if _, err := aws.ParseZoneVPCs(cfg.AWSZoneMatchParentVPCs); err != nil {
+		return fmt.Errorf("--aws-zone-match-parent-vpc: %w", err)
+	}
+	if _, err := aws.ParseAPIRateLimits(cfg.AWSAPIRateLimits); err != nil {
+		return fmt.Errorf("--aws-api-rate-limit: %w", err)
+	}
+	return nil
+}
+
+func buildDynamoDBRegistry(cfg *externaldns.Config, p provider.Provider) (registry.Registry, error) {
+	var dynamodbOpts []func(*dynamodb.Options)
+	if cfg.AWSDynamoDBRegion != "" {
+		dynamodbOpts = []func(*dynamodb.Options){
+			func(opts *dynamodb.Options) {
+				opts.Region = cfg.AWSDynamoDBRegion
+			},
+		}
+	}
+	return registry.NewDynamoDBRegistry(p, cfg.TXTOwnerID, dynamodb.NewFromConfig(aws.CreateDefaultV2Config(cfg), dynamodbOpts...), cfg.AWSDynamoDBTable, cfg.TXTPrefix, cfg.TXTSuffix, cfg.TXTWildcardReplacement, cfg.ManagedDNSRecordTypes, cfg.ExcludeDNSRecordTypes, []byte(cfg.TXTEncryptAESKey), cfg.TXTCacheInterval)
+}
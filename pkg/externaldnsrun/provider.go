@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaldnsrun
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/registry"
+	"sigs.k8s.io/external-dns/source"
+)
+
+// providerFilters are the filters of the zones and the records of the configuration, shared by the providers.
+type providerFilters struct {
+	domainFilter   endpoint.DomainFilter
+	zoneNameFilter endpoint.DomainFilter
+	zoneIDFilter   provider.ZoneIDFilter
+	zoneTypeFilter provider.ZoneTypeFilter
+	zoneTagFilter  provider.ZoneTagFilter
+}
+
+// providerBuilder creates a DNS provider of the configuration.
+type providerBuilder func(ctx context.Context, cfg *externaldns.Config, f providerFilters, endpointsSource source.Source) (provider.Provider, error)
+
+// providerBuilders are the builders of the providers compiled in, by name. The providers register themselves in files
+// built unless the select_providers build tag is set, or with their provider_<package> build tag, so that a binary
+// can be built with only some of the providers, e.g. with -tags select_providers,provider_aws.
+var providerBuilders = map[string]providerBuilder{}
+
+func registerProvider(builder providerBuilder, names ...string) {
+	for _, name := range names {
+		providerBuilders[name] = builder
+	}
+}
+
+// registryBuilder creates a registry storing the ownership outside of the provider.
+type registryBuilder func(cfg *externaldns.Config, p provider.Provider) (registry.Registry, error)
+
+// registryBuilders are the builders of the registries compiled in with the providers of their stores, by name,
+// e.g. the DynamoDB registry with the AWS providers.
+var registryBuilders = map[string]registryBuilder{}
+
+func registerRegistry(builder registryBuilder, name string) {
+	registryBuilders[name] = builder
+}
+
+// startWebhookServer serves the provider with the webhook API for --webhook-server. It is set with the webhook provider,
+// and nil if the webhook provider is not compiled in.
+var startWebhookServer func(p provider.Provider, cfg *externaldns.Config)
+
+// BuildDomainFilter creates the domain filter of the configuration.
+func BuildDomainFilter(cfg *externaldns.Config) endpoint.DomainFilter {
+	// RegexDomainFilter overrides DomainFilter
+	if cfg.RegexDomainFilter.String() != "" {
+		return endpoint.NewRegexDomainFilter(cfg.RegexDomainFilter, cfg.RegexDomainExclusion)
+	}
+	return endpoint.NewDomainFilterWithExclusions(cfg.DomainFilter, cfg.ExcludeDomains)
+}
+
+// BuildProvider creates the DNS provider selected by the configuration. The source is only used by the
+// providers publishing the endpoints of the sources, and may be nil otherwise.
+func BuildProvider(ctx context.Context, cfg *externaldns.Config, domainFilter endpoint.DomainFilter, endpointsSource source.Source) (provider.Provider, error) {
+	build, ok := providerBuilders[cfg.Provider]
+	if !ok {
+		return nil, fmt.Errorf("dns provider %s is unknown or not compiled in this binary", cfg.Provider)
+	}
+	return build(ctx, cfg, providerFilters{
+		domainFilter:   domainFilter,
+		zoneNameFilter: endpoint.NewDomainFilter(cfg.ZoneNameFilter),
+		zoneIDFilter:   provider.NewZoneIDFilter(cfg.ZoneIDFilter),
+		zoneTypeFilter: provider.NewZoneTypeFilter(cfg.AWSZoneType),
+		zoneTagFilter:  provider.NewZoneTagFilter(cfg.AWSZoneTagFilter),
+	}, endpointsSource)
+}
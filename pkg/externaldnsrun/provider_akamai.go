@@ -0,0 +1,47 @@
+//go:build !select_providers || provider_akamai
+
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaldnsrun
+
+import (
+	"context"
+
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/provider/akamai"
+	"sigs.k8s.io/external-dns/source"
+)
+
+func init() {
+	registerProvider(buildAkamaiProvider, "akamai")
+}
+
+func buildAkamaiProvider(ctx context.Context, cfg *externaldns.Config, f providerFilters, endpointsSource source.Source) (provider.Provider, error) {
+	return akamai.NewAkamaiProvider(
+		akamai.AkamaiConfig{
+			DomainFilter:          f.domainFilter,
+			ZoneIDFilter:          f.zoneIDFilter,
+			ServiceConsumerDomain: cfg.AkamaiServiceConsumerDomain,
+			ClientToken:           cfg.AkamaiClientToken,
+			ClientSecret:          cfg.AkamaiClientSecret,
+			AccessToken:           cfg.AkamaiAccessToken,
+			EdgercPath:            cfg.AkamaiEdgercPath,
+			EdgercSection:         cfg.AkamaiEdgercSection,
+			DryRun:                cfg.DryRun,
+		}, nil)
+}
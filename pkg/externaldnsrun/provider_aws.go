@@ -0,0 +1,79 @@
+//go:build !select_providers || provider_aws
+
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaldnsrun
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/provider/aws"
+	"sigs.k8s.io/external-dns/source"
+)
+
+func init() {
+	registerProvider(buildAWSProvider, "aws")
+}
+
+func buildAWSProvider(ctx context.Context, cfg *externaldns.Config, f providerFilters, endpointsSource source.Source) (provider.Provider, error) {
+	configs := aws.CreateV2Configs(cfg)
+	clients := make(map[string]aws.Route53API, len(configs))
+	for profile, config := range configs {
+		clients[profile] = route53.NewFromConfig(config, aws.Route53Options(cfg.AWSEndpointURL)...)
+	}
+	zoneRoles, zoneRolesErr := aws.ParseZoneRoles(cfg.AWSZoneRoles)
+	if zoneRolesErr != nil {
+		return nil, zoneRolesErr
+	}
+	zoneVPCs, zoneVPCsErr := aws.ParseZoneVPCs(cfg.AWSZoneMatchParentVPCs)
+	if zoneVPCsErr != nil {
+		return nil, zoneVPCsErr
+	}
+	zoneVPCs, zoneVPCsErr = aws.ResolveZoneVPCs(ctx, imds.New(imds.Options{}), zoneVPCs)
+	if zoneVPCsErr != nil {
+		return nil, zoneVPCsErr
+	}
+
+	return aws.NewAWSProvider(
+		aws.AWSConfig{
+			DomainFilter:          f.domainFilter,
+			ZoneIDFilter:          f.zoneIDFilter,
+			ZoneTypeFilter:        f.zoneTypeFilter,
+			ZoneTagFilter:         f.zoneTagFilter,
+			ZoneMatchParent:       cfg.AWSZoneMatchParent,
+			BatchChangeSize:       cfg.AWSBatchChangeSize,
+			BatchChangeSizeBytes:  cfg.AWSBatchChangeSizeBytes,
+			BatchChangeSizeValues: cfg.AWSBatchChangeSizeValues,
+			BatchChangeInterval:   cfg.AWSBatchChangeInterval,
+			EvaluateTargetHealth:  cfg.AWSEvaluateTargetHealth,
+			PreferCNAME:           cfg.AWSPreferCNAME,
+			DryRun:                cfg.DryRun,
+			ZoneCacheDuration:     cfg.AWSZoneCacheDuration,
+			BoundedListing:        cfg.AWSBoundedListing,
+			ZoneRoles:             zoneRoles,
+			ManageHealthChecks:    cfg.AWSManageHealthChecks,
+			ManageTrafficPolicies: cfg.AWSManageTrafficPolicies,
+			ZoneVPCs:              zoneVPCs,
+		},
+		clients,
+	)
+}
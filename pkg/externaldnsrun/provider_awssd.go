@@ -0,0 +1,45 @@
+//go:build !select_providers || provider_awssd
+
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaldnsrun
+
+import (
+	"context"
+
+	sd "github.com/aws/aws-sdk-go-v2/service/servicediscovery"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/provider/aws"
+	"sigs.k8s.io/external-dns/provider/awssd"
+	"sigs.k8s.io/external-dns/source"
+)
+
+func init() {
+	registerProvider(buildAWSSDProvider, "aws-sd")
+}
+
+func buildAWSSDProvider(ctx context.Context, cfg *externaldns.Config, f providerFilters, endpointsSource source.Source) (provider.Provider, error) {
+	// Check that only compatible Registry is used with AWS-SD
+	if cfg.Registry != "noop" && cfg.Registry != "aws-sd" {
+		log.Infof("Registry \"%s\" cannot be used with AWS Cloud Map. Switching to \"aws-sd\".", cfg.Registry)
+		cfg.Registry = "aws-sd"
+	}
+	return awssd.NewAWSSDProvider(f.domainFilter, cfg.AWSZoneType, cfg.DryRun, cfg.AWSSDServiceCleanup, cfg.AWSSDCreateNamespaces, cfg.AWSSDNamespaceVPC, cfg.TXTOwnerID, sd.NewFromConfig(aws.CreateDefaultV2Config(cfg)))
+}
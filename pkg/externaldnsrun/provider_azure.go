@@ -0,0 +1,82 @@
+//go:build !select_providers || provider_azure
+
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaldnsrun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns/validation"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/provider/azure"
+	"sigs.k8s.io/external-dns/source"
+)
+
+func init() {
+	registerProvider(buildAzureProvider, "azure-dns", "azure")
+	registerProvider(buildAzurePrivateDNSProvider, "azure-private-dns")
+	validation.RegisterProviderValidator(validateAzureConfig)
+}
+
+// validateAzureConfig validates the Azure flags parsed by the provider, the providers they require are checked by
+// ValidateConfig.
+func validateAzureConfig(cfg *externaldns.Config) error {
+	if len(cfg.AzureSubscriptionIDs) > 0 && len(azure.ParseSubscriptionIDs(cfg.AzureSubscriptionIDs)) == 0 {
+		return errors.New("--azure-subscription-ids: no subscription specified")
+	}
+	if _, err := azure.ParseVirtualNetworkIDs(cfg.AzurePrivateDNSVirtualNetworks); err != nil {
+		return fmt.Errorf("--azure-private-dns-virtual-network: %w", err)
+	}
+	return nil
+}
+
+func buildAzureProvider(ctx context.Context, cfg *externaldns.Config, f providerFilters, endpointsSource source.Source) (provider.Provider, error) {
+	return buildAzureSubscriptionsProvider(ctx, cfg, func(subscriptionID string) (provider.Provider, error) {
+		return azure.NewAzureProvider(cfg.AzureConfigFile, f.domainFilter, f.zoneNameFilter, f.zoneIDFilter, subscriptionID, cfg.AzureResourceGroup, cfg.AzureUserAssignedIdentityClientID, cfg.AzureActiveDirectoryAuthorityHost, cfg.DryRun)
+	})
+}
+
+func buildAzurePrivateDNSProvider(ctx context.Context, cfg *externaldns.Config, f providerFilters, endpointsSource source.Source) (provider.Provider, error) {
+	virtualNetworks, err := azure.ParseVirtualNetworkIDs(cfg.AzurePrivateDNSVirtualNetworks)
+	if err != nil {
+		return nil, err
+	}
+	return buildAzureSubscriptionsProvider(ctx, cfg, func(subscriptionID string) (provider.Provider, error) {
+		return azure.NewAzurePrivateDNSProvider(cfg.AzureConfigFile, f.domainFilter, f.zoneNameFilter, f.zoneIDFilter, subscriptionID, cfg.AzureResourceGroup, cfg.AzureUserAssignedIdentityClientID, cfg.AzureActiveDirectoryAuthorityHost, virtualNetworks, cfg.DryRun)
+	})
+}
+
+// buildAzureSubscriptionsProvider builds the provider of the subscription of the configuration, or the provider of
+// the zones of the subscriptions of --azure-subscription-ids or --azure-management-group.
+func buildAzureSubscriptionsProvider(ctx context.Context, cfg *externaldns.Config, newProvider func(subscriptionID string) (provider.Provider, error)) (provider.Provider, error) {
+	subscriptionIDs := azure.ParseSubscriptionIDs(cfg.AzureSubscriptionIDs)
+	if cfg.AzureManagementGroup != "" {
+		var err error
+		subscriptionIDs, err = azure.DiscoverSubscriptions(ctx, cfg.AzureConfigFile, cfg.AzureManagementGroup, cfg.AzureUserAssignedIdentityClientID, cfg.AzureActiveDirectoryAuthorityHost)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(subscriptionIDs) == 0 {
+		return newProvider(cfg.AzureSubscriptionID)
+	}
+	return azure.NewMultiSubscriptionProvider(subscriptionIDs, newProvider)
+}
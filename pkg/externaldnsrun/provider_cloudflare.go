@@ -0,0 +1,58 @@
+//go:build !select_providers || provider_cloudflare
+
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaldnsrun
+
+import (
+	"context"
+
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/provider/cloudflare"
+	"sigs.k8s.io/external-dns/source"
+)
+
+func init() {
+	registerProvider(buildCloudflareProvider, "cloudflare")
+}
+
+func buildCloudflareProvider(ctx context.Context, cfg *externaldns.Config, f providerFilters, endpointsSource source.Source) (provider.Provider, error) {
+	p, err := cloudflare.NewCloudFlareProvider(cloudflare.CloudFlareConfig{
+		DomainFilter:           f.domainFilter,
+		ZoneIDFilter:           f.zoneIDFilter,
+		ProxiedByDefault:       cfg.CloudflareProxied,
+		DryRun:                 cfg.DryRun,
+		DNSRecordsPerPage:      cfg.CloudflareDNSRecordsPerPage,
+		ExportListingThreshold: cfg.CloudflareExportListingThreshold,
+		ZoneTokensFile:         cfg.CloudflareZoneTokensFile,
+		LoadBalancerAccountID:  cfg.CloudflareLoadBalancerAccountID,
+		CustomHostnamesZone:    cfg.CloudflareCustomHostnamesZone,
+		RegionalServices:       cfg.CloudflareRegionalServices,
+		RegionKey:              cfg.CloudflareRegionKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.ListingWorkers = cfg.CloudflareListingWorkers
+	if cfg.CloudflareCheckTokenScope {
+		if err := p.CheckTokenScope(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
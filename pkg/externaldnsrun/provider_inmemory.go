@@ -0,0 +1,47 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaldnsrun
+
+import (
+	"context"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+	"sigs.k8s.io/external-dns/source"
+)
+
+func init() {
+	registerProvider(buildInMemoryProvider, "inmemory")
+}
+
+func buildInMemoryProvider(ctx context.Context, cfg *externaldns.Config, f providerFilters, endpointsSource source.Source) (provider.Provider, error) {
+	opts := []inmemory.InMemoryOption{inmemory.InMemoryInitZones(cfg.InMemoryZones), inmemory.InMemoryWithDomain(f.domainFilter), inmemory.InMemoryWithLogging(), inmemory.InMemoryWithFile(cfg.InMemoryFile)}
+	if len(cfg.InMemorySimulation) > 0 {
+		sim, err := inmemory.ParseSimulation(cfg.InMemorySimulation)
+		if err != nil {
+			return nil, err
+		}
+		log.Warnf("Simulating the behavior of the API of the zones: %s", strings.Join(cfg.InMemorySimulation, " "))
+		opts = append(opts, inmemory.InMemoryWithSimulation(sim))
+	}
+	im := inmemory.NewInMemoryProvider(opts...)
+	return im, im.Restore()
+}
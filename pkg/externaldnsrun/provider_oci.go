@@ -0,0 +1,53 @@
+//go:build !select_providers || provider_oci
+
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaldnsrun
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/provider/oci"
+	"sigs.k8s.io/external-dns/source"
+)
+
+func init() {
+	registerProvider(buildOCIProvider, "oci")
+}
+
+func buildOCIProvider(ctx context.Context, cfg *externaldns.Config, f providerFilters, endpointsSource source.Source) (provider.Provider, error) {
+	var config *oci.OCIConfig
+	// if the instance-principals flag was set, and a compartment OCID was provided, then ignore the
+	// OCI config file, and provide a config that uses instance principal authentication.
+	if cfg.OCIAuthInstancePrincipal {
+		if len(cfg.OCICompartmentOCID) == 0 {
+			return nil, fmt.Errorf("instance principal authentication requested, but no compartment OCID provided")
+		}
+		authConfig := oci.OCIAuthConfig{UseInstancePrincipal: true}
+		config = &oci.OCIConfig{Auth: authConfig, CompartmentID: cfg.OCICompartmentOCID}
+	} else {
+		var err error
+		if config, err = oci.LoadOCIConfig(cfg.OCIConfigFile); err != nil {
+			return nil, err
+		}
+	}
+	config.ZoneCacheDuration = cfg.OCIZoneCacheDuration
+	return oci.NewOCIProvider(*config, f.domainFilter, f.zoneIDFilter, cfg.OCIZoneScope, cfg.DryRun)
+}
@@ -0,0 +1,41 @@
+//go:build !select_providers || provider_rdns
+
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaldnsrun
+
+import (
+	"context"
+
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/provider/rdns"
+	"sigs.k8s.io/external-dns/source"
+)
+
+func init() {
+	registerProvider(buildRDNSProvider, "rdns")
+}
+
+func buildRDNSProvider(ctx context.Context, cfg *externaldns.Config, f providerFilters, endpointsSource source.Source) (provider.Provider, error) {
+	return rdns.NewRDNSProvider(
+		rdns.RDNSConfig{
+			DomainFilter: f.domainFilter,
+			DryRun:       cfg.DryRun,
+		},
+	)
+}
@@ -0,0 +1,177 @@
+//go:build !select_providers
+
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaldnsrun
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns/validation"
+)
+
+func TestProvidersRegistered(t *testing.T) {
+	for _, name := range externaldns.Providers {
+		assert.Contains(t, providerBuilders, name, "the %s provider must be built by default", name)
+	}
+	assert.Len(t, providerBuilders, len(externaldns.Providers))
+	assert.Contains(t, registryBuilders, "dynamodb")
+	assert.NotNil(t, startWebhookServer)
+}
+
+func TestBuildProvider(t *testing.T) {
+	cfg := newConfig(t, "--inmemory-zone=example.org")
+	p, err := BuildProvider(context.Background(), cfg, BuildDomainFilter(cfg), nil)
+	require.NoError(t, err)
+	assert.NotNil(t, p)
+
+	cfg.Provider = "unknown"
+	_, err = BuildProvider(context.Background(), cfg, BuildDomainFilter(cfg), nil)
+	assert.EqualError(t, err, "dns provider unknown is unknown or not compiled in this binary")
+}
+
+func TestValidateAWSZoneRoles(t *testing.T) {
+	cfg := newConfig(t)
+	cfg.AWSZoneRoles = []string{"Z123"}
+	assert.Error(t, validation.ValidateConfig(cfg))
+
+	cfg.AWSZoneRoles = []string{"Z123=arn:aws:iam::111:role/dns,Z456=arn:aws:iam::222:role/dns"}
+	assert.NoError(t, validation.ValidateConfig(cfg))
+}
+
+func TestValidateAWSProfileRoles(t *testing.T) {
+	cfg := newConfig(t)
+	cfg.AWSProfiles = []string{"prod", "staging"}
+	cfg.AWSProfileRoles = []string{"prod"}
+	assert.Error(t, validation.ValidateConfig(cfg))
+
+	cfg.AWSProfileRoles = []string{"dev=arn:aws:iam::333:role/dns"}
+	assert.ErrorContains(t, validation.ValidateConfig(cfg), `the profile "dev" is not enabled by --aws-profile`)
+
+	cfg.AWSProfileRoles = []string{"prod=arn:aws:iam::111:role/dns,staging=arn:aws:iam::222:role/dns"}
+	assert.NoError(t, validation.ValidateConfig(cfg))
+}
+
+func TestValidateAWSZoneMatchParentVPCs(t *testing.T) {
+	cfg := newConfig(t)
+	cfg.AWSZoneMatchParentVPCs = []string{"eu-west-1/"}
+	assert.Error(t, validation.ValidateConfig(cfg))
+
+	cfg.AWSZoneMatchParentVPCs = []string{"vpc-111,eu-west-1/vpc-222"}
+	assert.NoError(t, validation.ValidateConfig(cfg))
+
+	cfg.AWSZoneMatchParentVPCs = []string{"auto"}
+	assert.NoError(t, validation.ValidateConfig(cfg))
+}
+
+func TestValidateAWSAssumeRoleSession(t *testing.T) {
+	cfg := newConfig(t)
+	cfg.AWSAssumeRoleSessionDuration = time.Hour
+	cfg.AWSAssumeRoleSessionName = "external-dns@prod"
+	cfg.AWSAssumeRoleSessionTags = []string{"team=dns,cluster=prod"}
+	assert.NoError(t, validation.ValidateConfig(cfg))
+
+	cfg.AWSAssumeRoleSessionDuration = 5 * time.Minute
+	assert.EqualError(t, validation.ValidateConfig(cfg), "--aws-assume-role-session-duration must be between 15m and 12h, got 5m0s")
+	cfg.AWSAssumeRoleSessionDuration = 0
+
+	cfg.AWSAssumeRoleSessionName = "external dns"
+	assert.ErrorContains(t, validation.ValidateConfig(cfg), "--aws-assume-role-session-name")
+	cfg.AWSAssumeRoleSessionName = ""
+
+	cfg.AWSAssumeRoleSessionTags = []string{"team"}
+	assert.ErrorContains(t, validation.ValidateConfig(cfg), "--aws-assume-role-session-tag: invalid session tag")
+	cfg.AWSAssumeRoleSessionTags = []string{"team=" + strings.Repeat("a", 257)}
+	assert.ErrorContains(t, validation.ValidateConfig(cfg), "too long")
+	cfg.AWSAssumeRoleSessionTags = nil
+
+	cfg.AWSAssumeRoleSessionName = "external-dns-{cluster}-{owner}-{pod}"
+	assert.NoError(t, validation.ValidateConfig(cfg))
+	cfg.AWSAssumeRoleSessionName = "external dns-{owner}"
+	assert.ErrorContains(t, validation.ValidateConfig(cfg), "--aws-assume-role-session-name")
+	cfg.AWSAssumeRoleSessionName = ""
+
+	cfg.AWSAssumeRoleSourceIdentity = "external-dns"
+	assert.NoError(t, validation.ValidateConfig(cfg))
+	cfg.AWSAssumeRoleSourceIdentity = "aws:external-dns"
+	assert.ErrorContains(t, validation.ValidateConfig(cfg), "--aws-assume-role-source-identity")
+	cfg.AWSAssumeRoleSourceIdentity = ""
+
+	cfg.AWSAssumeRoleChain = []string{"arn:aws:iam::111:role/hop"}
+	cfg.AWSAssumeRoleSessionDuration = time.Hour
+	assert.NoError(t, validation.ValidateConfig(cfg))
+	cfg.AWSAssumeRoleSessionDuration = 2 * time.Hour
+	assert.EqualError(t, validation.ValidateConfig(cfg), "--aws-assume-role-session-duration must be 1h at most with --aws-assume-role-chain, got 2h0m0s")
+	cfg.AWSAssumeRoleChain = []string{"hop"}
+	assert.ErrorContains(t, validation.ValidateConfig(cfg), "--aws-assume-role-chain: invalid role")
+}
+
+func TestValidateAzurePrivateDNSVirtualNetworks(t *testing.T) {
+	cfg := newConfig(t)
+	cfg.Provider = "azure-private-dns"
+	cfg.AzurePrivateDNSVirtualNetworks = []string{"/subscriptions/sub/resourceGroups/network/providers/Microsoft.Network/virtualNetworks/prod"}
+	assert.NoError(t, validation.ValidateConfig(cfg))
+
+	cfg.AzurePrivateDNSVirtualNetworks = []string{"prod"}
+	assert.ErrorContains(t, validation.ValidateConfig(cfg), "--azure-private-dns-virtual-network: invalid virtual network")
+
+	cfg.Provider = "aws"
+	assert.EqualError(t, validation.ValidateConfig(cfg), "--azure-private-dns-virtual-network requires --provider=azure-private-dns")
+}
+
+func TestValidateAWSAPIRateLimits(t *testing.T) {
+	cfg := newConfig(t)
+	cfg.AWSAPIRateLimits = []string{"ListHostedZones=0"}
+	assert.Error(t, validation.ValidateConfig(cfg))
+
+	cfg.AWSAPIRateLimits = []string{"ListHostedZones=2,ChangeResourceRecordSets=0.5"}
+	assert.NoError(t, validation.ValidateConfig(cfg))
+
+	cfg.AWSAPIDefaultRateLimit = -1
+	assert.Error(t, validation.ValidateConfig(cfg))
+}
+
+func TestValidateAzureSubscriptions(t *testing.T) {
+	cfg := newConfig(t)
+	cfg.Provider = "azure-private-dns"
+	cfg.AzureSubscriptionIDs = []string{"sub1,sub2"}
+	assert.NoError(t, validation.ValidateConfig(cfg))
+
+	cfg.AzureSubscriptionIDs = []string{" , "}
+	assert.EqualError(t, validation.ValidateConfig(cfg), "--azure-subscription-ids: no subscription specified")
+
+	cfg.AzureSubscriptionIDs = []string{"sub1"}
+	cfg.AzureManagementGroup = "platform"
+	assert.EqualError(t, validation.ValidateConfig(cfg), "--azure-subscription-ids and --azure-management-group are mutually exclusive")
+
+	cfg.AzureSubscriptionIDs = nil
+	assert.NoError(t, validation.ValidateConfig(cfg))
+
+	cfg.AzureSubscriptionID = "sub1"
+	assert.EqualError(t, validation.ValidateConfig(cfg), "--azure-subscription-id cannot be combined with --azure-subscription-ids or --azure-management-group")
+
+	cfg.AzureSubscriptionID = ""
+	cfg.Provider = "aws"
+	assert.EqualError(t, validation.ValidateConfig(cfg), "--azure-subscription-ids and --azure-management-group require --provider=azure or --provider=azure-private-dns")
+}
@@ -0,0 +1,549 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package externaldnsrun runs ExternalDNS, as its binary does, so that other binaries can embed it.
+package externaldnsrun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/term"
+	"k8s.io/apimachinery/pkg/labels"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+
+	"sigs.k8s.io/external-dns/controller"
+	"sigs.k8s.io/external-dns/endpoint"
+	endpointvalidation "sigs.k8s.io/external-dns/endpoint/validation"
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns/validation"
+	"sigs.k8s.io/external-dns/pkg/healthcheck"
+	"sigs.k8s.io/external-dns/pkg/octodns"
+	"sigs.k8s.io/external-dns/pkg/preflight"
+	"sigs.k8s.io/external-dns/pkg/profiling"
+	"sigs.k8s.io/external-dns/pkg/server"
+	"sigs.k8s.io/external-dns/pkg/terraform"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+	"sigs.k8s.io/external-dns/registry"
+	"sigs.k8s.io/external-dns/source"
+)
+
+// Options are the dependencies ExternalDNS shares with the binary embedding it.
+type Options struct {
+	// ClientGenerator provides the Kubernetes clients of the sources, e.g. the clients of the embedding operator.
+	// Defaults to the clients of the kubeconfig and API server URL of the configuration.
+	ClientGenerator source.ClientGenerator
+	// Mux receives the HTTP endpoints of ExternalDNS, e.g. the status API, instead of the listeners of the
+	// configuration, which are then not started; the health and metrics endpoints are left to the embedding binary.
+	// The metrics are registered in prometheus.DefaultRegisterer.
+	Mux *http.ServeMux
+}
+
+// Run runs ExternalDNS with the configuration until the context is done, or once with --once. It returns an
+// error if the configuration is invalid, a component cannot be created, a synchronization fails with an error
+// that is not retried, or a listener fails.
+//
+// Some settings are global to the process rather than to a Run: the User-Agent and the API quota headroom of the
+// provider clients, see provider.SetUserAgent and provider.SetAPIQuotaHeadroom, and the metrics, registered once in
+// prometheus.DefaultRegisterer. Instances run concurrently in a process therefore share their metrics, and the
+// clients of an instance may use the User-Agent and headroom of another; running a single instance is supported.
+func Run(ctx context.Context, cfg *externaldns.Config, opts Options) error {
+	if err := validation.ValidateConfig(cfg); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+	for _, err := range validation.CheckCompatibility(cfg) {
+		log.Warn(err)
+	}
+
+	if cfg.DryRun {
+		log.Info("running in dry-run mode. No changes to DNS records will be made.")
+	}
+
+	mux := opts.Mux
+	if mux == nil {
+		// the endpoints are served on a mux of their own, not on http.DefaultServeMux where net/http/pprof
+		// registers the profiling endpoints
+		mux = http.NewServeMux()
+	}
+	var slowCycles *profiling.SlowCycleRecorder
+	if cfg.SlowCycleProfileThreshold > 0 {
+		slowCycles = &profiling.SlowCycleRecorder{
+			Threshold:   cfg.SlowCycleProfileThreshold,
+			Dir:         cfg.SlowCycleProfileDir,
+			MaxCaptures: cfg.SlowCycleProfileMaxCaptures,
+		}
+		if slowCycles.Dir == "" {
+			slowCycles.Dir = filepath.Join(os.TempDir(), "external-dns-profiles")
+		}
+	}
+	// a listener failing stops ExternalDNS with its error, and the listeners are closed when Run returns
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	if opts.Mux == nil {
+		serveMetrics(ctx, cancel, cfg, mux, slowCycles)
+	}
+
+	// error is explicitly ignored because the filter is already validated in validation.ValidateConfig
+	labelSelector, _ := labels.Parse(cfg.LabelFilter)
+	annotationFilters, err := source.ParseSourceAnnotationFilters(cfg.SourceAnnotationFilters)
+	if err != nil {
+		return fmt.Errorf("--source-annotation-filter: %w", err)
+	}
+
+	// Create a source.Config from the flags passed by the user.
+	sourceCfg := &source.Config{
+		Namespace:                      cfg.Namespace,
+		AnnotationFilter:               cfg.AnnotationFilter,
+		SourceAnnotationFilters:        annotationFilters,
+		LabelFilter:                    labelSelector,
+		IngressClassNames:              cfg.IngressClassNames,
+		FQDNTemplate:                   cfg.FQDNTemplate,
+		CombineFQDNAndAnnotation:       cfg.CombineFQDNAndAnnotation,
+		IgnoreHostnameAnnotation:       cfg.IgnoreHostnameAnnotation,
+		IgnoreIngressTLSSpec:           cfg.IgnoreIngressTLSSpec,
+		IgnoreIngressRulesSpec:         cfg.IgnoreIngressRulesSpec,
+		GatewayNamespace:               cfg.GatewayNamespace,
+		GatewayLabelFilter:             cfg.GatewayLabelFilter,
+		Compatibility:                  cfg.Compatibility,
+		PublishInternal:                cfg.PublishInternal,
+		PublishHostIP:                  cfg.PublishHostIP,
+		AlwaysPublishNotReadyAddresses: cfg.AlwaysPublishNotReadyAddresses,
+		ConnectorServer:                cfg.ConnectorSourceServer,
+		CRDSourceAPIVersion:            cfg.CRDSourceAPIVersion,
+		CRDSourceKind:                  cfg.CRDSourceKind,
+		KubeConfig:                     cfg.KubeConfig,
+		APIServerURL:                   cfg.APIServerURL,
+		ServiceTypeFilter:              cfg.ServiceTypeFilter,
+		CFAPIEndpoint:                  cfg.CFAPIEndpoint,
+		CFUsername:                     cfg.CFUsername,
+		CFPassword:                     cfg.CFPassword,
+		GlooNamespaces:                 cfg.GlooNamespaces,
+		SkipperRouteGroupVersion:       cfg.SkipperRouteGroupVersion,
+		RequestTimeout:                 cfg.RequestTimeout,
+		DefaultTargets:                 cfg.DefaultTargets,
+		InternalTargets:                cfg.InternalTargets,
+		OCPRouterName:                  cfg.OCPRouterName,
+		UpdateEvents:                   cfg.UpdateEvents,
+		ResolveLoadBalancerHostname:    cfg.ResolveServiceLoadBalancerHostname,
+		TraefikDisableLegacy:           cfg.TraefikDisableLegacy,
+		TraefikDisableNew:              cfg.TraefikDisableNew,
+	}
+
+	// Lookup all the selected sources by names and pass them the desired configuration.
+	clientGenerator := opts.ClientGenerator
+	if clientGenerator == nil {
+		clientGenerator = &source.SingletonClientGenerator{
+			KubeConfig:   cfg.KubeConfig,
+			APIServerURL: cfg.APIServerURL,
+			// If update events are enabled, disable timeout.
+			RequestTimeout: func() time.Duration {
+				if cfg.UpdateEvents {
+					return 0
+				}
+				return cfg.RequestTimeout
+			}(),
+		}
+	}
+	sources, err := source.ByNames(ctx, clientGenerator, cfg.Sources, sourceCfg)
+	if err != nil {
+		return err
+	}
+	profiles, err := source.ParseFilterProfiles(cfg.FilterProfiles)
+	if err != nil {
+		return err
+	}
+	bindings, err := source.ParseSourceFilterProfiles(cfg.SourceFilterProfiles, profiles)
+	if err != nil {
+		return err
+	}
+	for i, name := range cfg.Sources {
+		sources[i] = source.NewMetricsSource(name, sources[i])
+		if profile, ok := bindings[name]; ok {
+			sources[i] = source.NewProfileSource(sources[i], profile, profiles[profile])
+		}
+	}
+
+	if cfg.TXTOwnerID == source.OwnerIDAuto {
+		kubeClient, err := clientGenerator.KubeClient()
+		if err != nil {
+			return err
+		}
+		if cfg.TXTOwnerID, err = source.ClusterOwnerID(ctx, kubeClient); err != nil {
+			return err
+		}
+		log.Infof("Using owner ID %s detected from the cluster identity", cfg.TXTOwnerID)
+	} else if cfg.TXTOwnerID == "default" && cfg.Registry != "noop" {
+		log.Warn("Using the default owner ID; ExternalDNS instances in other clusters sharing it may take over each other's records, consider --txt-owner-id=auto")
+	}
+
+	// Filter targets
+	targetFilter := endpoint.NewTargetNetFilterWithExclusions(cfg.TargetNetFilter, cfg.ExcludeTargetNets)
+
+	// Combine multiple sources into a single, deduplicated source.
+	endpointsSource := source.NewDedupSource(source.NewRecordCommentSource(source.NewIDNSource(source.NewHostnameTemplateSource(source.NewMultiSource(sources, sourceCfg.DefaultTargets)))))
+	endpointsSource = source.NewNAT64Source(endpointsSource, cfg.NAT64Networks)
+	endpointsSource = source.NewTargetFilterSource(endpointsSource, targetFilter)
+	endpointsSource = source.NewLintSource(endpointsSource, endpointvalidation.NewLinter(cfg.Provider))
+	endpointsSource = source.NewReleaseSource(endpointsSource)
+
+	var healthProber *healthcheck.Prober
+	if cfg.HealthCheck {
+		healthProber = healthcheck.NewProber(healthcheck.Config{
+			Interval:           cfg.HealthCheckInterval,
+			Timeout:            cfg.HealthCheckTimeout,
+			HealthyThreshold:   cfg.HealthCheckHealthyThreshold,
+			UnhealthyThreshold: cfg.HealthCheckUnhealthyThreshold,
+		})
+		go healthProber.Run(ctx)
+		endpointsSource = source.NewHealthCheckSource(endpointsSource, healthProber)
+	} else {
+		endpointsSource = source.NewHealthCheckSource(endpointsSource, nil)
+	}
+
+	// Failover queries are evaluated after filtering, so that failover targets are not filtered out.
+	var failoverEvaluator source.FailoverEvaluator
+	if cfg.FailoverPrometheusURL != "" {
+		failoverEvaluator, err = source.NewPrometheusEvaluator(cfg.FailoverPrometheusURL)
+		if err != nil {
+			return err
+		}
+	}
+	endpointsSource = source.NewFailoverSource(endpointsSource, failoverEvaluator)
+
+	// GSLB hints are published with the targets left after health checks and failover.
+	endpointsSource = source.NewGSLBSource(endpointsSource, source.GSLBConfig{
+		Cluster:        cfg.GSLBCluster,
+		LeaseDuration:  cfg.GSLBLeaseDuration,
+		WeightProperty: cfg.GSLBWeightProperty,
+	})
+
+	domainFilter := BuildDomainFilter(cfg)
+
+	if cfg.UserAgentAttribution {
+		cluster := cfg.ClusterName
+		if cluster == "" {
+			cluster = cfg.GSLBCluster
+		}
+		provider.SetUserAgent("ExternalDNS/"+externaldns.Version, cluster, cfg.TXTOwnerID)
+	} else {
+		provider.SetUserAgent("ExternalDNS/"+externaldns.Version, "", "")
+	}
+	provider.SetAPIQuotaHeadroom(cfg.ProviderAPIQuotaHeadroom)
+
+	p, err := BuildProvider(ctx, cfg, domainFilter, endpointsSource)
+	if err != nil {
+		return err
+	}
+
+	if cfg.WebhookServer {
+		if startWebhookServer == nil {
+			return errors.New("--webhook-server requires the webhook provider, which is not compiled in this binary")
+		}
+		if cfg.DryRun {
+			p = provider.NewReadOnlyProvider(p)
+		}
+		startWebhookServer(p, cfg)
+		return nil
+	}
+
+	if cfg.PreflightCheck {
+		if err := preflight.Check(ctx, p, cfg.PreflightCheckWrite); err != nil {
+			return err
+		}
+	}
+
+	if im, ok := p.(*inmemory.InMemoryProvider); ok {
+		mux.Handle("/inmemory/state", im.StateHandler())
+	}
+
+	zoneLister, _ := p.(provider.ZoneLister)
+	if cfg.SyncPerZone && zoneLister == nil {
+		return fmt.Errorf("--sync-per-zone is not supported by the %s provider", cfg.Provider)
+	}
+	if cfg.UnroutableHostnameCacheTTL > 0 && zoneLister == nil {
+		return fmt.Errorf("--unroutable-hostname-cache-ttl is not supported by the %s provider", cfg.Provider)
+	}
+	if cfg.SkipDelegatedHostnames && zoneLister == nil {
+		return fmt.Errorf("--skip-delegated-hostnames is not supported by the %s provider", cfg.Provider)
+	}
+
+	if cfg.CreateMissingZones {
+		creator, ok := p.(provider.ZoneCreator)
+		if !ok || zoneLister == nil {
+			return fmt.Errorf("--create-missing-zones is not supported by the %s provider", cfg.Provider)
+		}
+		p = provider.NewZoneCreatingProvider(p, creator, zoneLister, domainFilter, cfg.TXTOwnerID, cfg.DeleteEmptyZones)
+	}
+
+	if cfg.DryRun {
+		// the changes never reach the provider, whatever its own dry-run mode does
+		p = provider.NewReadOnlyProvider(p)
+	}
+
+	if cfg.ProviderFaultInjection != "" {
+		faults, err := provider.ParseFaults(cfg.ProviderFaultInjection)
+		if err != nil {
+			return err
+		}
+		log.Warnf("Injecting faults into the calls to the provider: %s", cfg.ProviderFaultInjection)
+		p = provider.NewFaultInjectionProvider(p, faults)
+	}
+
+	if len(cfg.ExcludeDNSRecordTypes) > 0 {
+		p = provider.NewRecordTypeExclusionProvider(p, cfg.ExcludeDNSRecordTypes)
+	}
+
+	if cfg.ProviderTimeout > 0 {
+		p = provider.NewTimeoutProvider(p, cfg.ProviderTimeout)
+	}
+
+	if cfg.ProviderCacheTime > 0 {
+		p = provider.NewCachedProvider(
+			p,
+			cfg.ProviderCacheTime,
+		)
+	}
+
+	var migrateFrom *registry.TXTMigration
+	if cfg.TXTMigrate {
+		migrateFrom = &registry.TXTMigration{Prefix: cfg.TXTPrefixMigrateFrom, Suffix: cfg.TXTSuffixMigrateFrom}
+	}
+
+	var r registry.Registry
+	switch cfg.Registry {
+	case "noop":
+		r, err = registry.NewNoopRegistry(p)
+	case "txt":
+		r, err = registry.NewTXTRegistry(p, registry.TXTConfig{
+			Prefix:              cfg.TXTPrefix,
+			Suffix:              cfg.TXTSuffix,
+			OwnerID:             cfg.TXTOwnerID,
+			CacheInterval:       cfg.TXTCacheInterval,
+			WildcardReplacement: cfg.TXTWildcardReplacement,
+			ManagedRecordTypes:  cfg.ManagedDNSRecordTypes,
+			ExcludeRecordTypes:  cfg.ExcludeDNSRecordTypes,
+			EncryptEnabled:      cfg.TXTEncryptEnabled,
+			EncryptAESKey:       []byte(cfg.TXTEncryptAESKey),
+			MigrateFrom:         migrateFrom,
+			TTL:                 endpoint.TTL(cfg.TXTRecordTTL),
+			Comment:             cfg.TXTRecordComment,
+			OwnerDomains:        cfg.TXTOwnerDomains,
+		})
+	case "txt-apex":
+		if zoneLister == nil {
+			return fmt.Errorf("--registry=txt-apex is not supported by the %s provider", cfg.Provider)
+		}
+		r, err = registry.NewApexTXTRegistry(p, zoneLister, cfg.TXTOwnerID, cfg.TXTApexRecordName)
+	case "aws-sd":
+		r, err = registry.NewAWSSDRegistry(p, cfg.TXTOwnerID)
+	default:
+		build, ok := registryBuilders[cfg.Registry]
+		if !ok {
+			return fmt.Errorf("registry %s is unknown or not compiled in this binary", cfg.Registry)
+		}
+		r, err = build(cfg, p)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// the snapshot registry neither collects the garbage nor migrates the ownership records of the registry it wraps
+	garbageCollector, _ := r.(registry.GarbageCollector)
+	migrator, _ := r.(registry.Migrator)
+	if cfg.SnapshotFile != "" {
+		r = registry.NewSnapshotRegistry(r, cfg.SnapshotFile, cfg.SnapshotMaxAge)
+	}
+
+	policy, exists := plan.Policies[cfg.Policy]
+	if !exists {
+		return fmt.Errorf("unknown policy: %s", cfg.Policy)
+	}
+
+	ctrl := controller.Controller{
+		Source:               endpointsSource,
+		Registry:             r,
+		Policy:               policy,
+		Interval:             cfg.Interval,
+		DomainFilter:         domainFilter,
+		ManagedRecordTypes:   cfg.ManagedDNSRecordTypes,
+		ExcludeRecordTypes:   cfg.ExcludeDNSRecordTypes,
+		MinEventSyncInterval: cfg.MinEventSyncInterval,
+		MaxMemoryEndpoints:   cfg.MaxMemoryEndpoints,
+		OutOfSyncCycles:      cfg.OutOfSyncCycles,
+		TTLRepairInterval:    cfg.TTLRepairInterval,
+		StatusAPI:            cfg.StatusAPI,
+		SlowCycleProfiler:    slowCycles,
+		ReconcileTokenFile:   cfg.StatusAPIReconcileTokenFile,
+	}
+	if cfg.StatusAPI {
+		mux.Handle("/api/v1/", ctrl.StatusHandler())
+	}
+	if cfg.DryRun && cfg.DryRunOutput == "tree" {
+		ctrl.Renderer = &controller.ChangesRenderer{
+			Writer:     os.Stdout,
+			Color:      term.IsTerminal(int(os.Stdout.Fd())) && os.Getenv("NO_COLOR") == "",
+			ZoneLister: zoneLister,
+		}
+	}
+	// the wrappers of the provider, e.g. for --dry-run, do not implement the optional interfaces of the provider
+	if comparer, ok := provider.As[provider.PropertyComparer](p); ok {
+		ctrl.PropertyComparator = comparer.PropertyValuesEqual
+	}
+	if cfg.RegistryLeaseDuration > 0 {
+		instance := cfg.RegistryLeaseInstance
+		if instance == "" {
+			if instance, err = os.Hostname(); err != nil {
+				return fmt.Errorf("failed to determine the lease instance: %w", err)
+			}
+		}
+		ctrl.Lease = &plan.Lease{Instance: instance, Duration: cfg.RegistryLeaseDuration}
+	}
+	if cfg.RegistryGCInterval > 0 {
+		ctrl.GarbageCollector = garbageCollector
+		ctrl.GarbageCollectionInterval = cfg.RegistryGCInterval
+	}
+	if cfg.TXTMigrate || cfg.Registry == "dynamodb" {
+		ctrl.Migrator = migrator
+	}
+	if !cfg.DryRun {
+		// the resources of the zones besides the records are not changed in dry-run
+		ctrl.ZoneMaintainer, _ = provider.As[provider.ZoneMaintainer](p)
+	}
+	if cfg.SyncPerZone {
+		ctrl.ZoneLister = zoneLister
+		ctrl.SkipUnchangedZones = cfg.SkipUnchangedZones
+		ctrl.ZoneVersioner, _ = provider.As[provider.ZoneVersioner](p)
+		ctrl.APIBudgetPerCycle = int64(cfg.ProviderAPIBudgetPerCycle)
+	}
+	if cfg.UnroutableHostnameCacheTTL > 0 {
+		ctrl.Unroutable = controller.NewUnroutableFilter(zoneLister, cfg.UnroutableHostnameCacheTTL)
+	}
+	if cfg.SkipDelegatedHostnames {
+		ctrl.Delegations = controller.NewDelegationGuard(zoneLister)
+	}
+	if len(cfg.PropagationCheckResolvers) > 0 {
+		ctrl.Propagation = controller.NewPropagationChecker(cfg.PropagationCheckResolvers, cfg.PropagationCheckInterval, cfg.PropagationCheckTimeout)
+	}
+
+	if cfg.OctoDNSExportDir != "" {
+		zones := cfg.OctoDNSExportZones
+		if len(zones) == 0 {
+			zones = cfg.DomainFilter
+		}
+		ctrl.Exporters = append(ctrl.Exporters, octodns.NewExporter(cfg.OctoDNSExportDir, zones))
+	}
+	if healthProber != nil {
+		// publish health changes without waiting for the next interval
+		healthProber.SetHandler(func() { ctrl.ScheduleRunOnce(time.Now()) })
+	}
+	if len(cfg.TerraformStates) > 0 {
+		ctrl.Exporters = append(ctrl.Exporters, terraform.NewReporter(cfg.TerraformStates))
+	}
+
+	if cfg.Once {
+		err = ctrl.RunOnce(ctx)
+		if listenerErr := listenerError(ctx); listenerErr != nil {
+			return listenerErr
+		}
+		return err
+	}
+
+	if cfg.UpdateEvents {
+		// Add RunOnce as the handler function that will be called when ingress/service sources have changed.
+		// Note that k8s Informers will perform an initial list operation, which results in the handler
+		// function initially being called for every Service/Ingress that exists
+		ctrl.Source.AddEventHandler(ctx, func() { ctrl.ScheduleRunOnce(time.Now()) })
+	}
+
+	ctrl.ScheduleRunOnce(time.Now())
+	err = ctrl.Run(ctx)
+	if listenerErr := listenerError(ctx); listenerErr != nil {
+		return listenerErr
+	}
+	return err
+}
+
+// errListener marks the errors of the listeners among the causes of the cancellation of the context of Run.
+var errListener = errors.New("failed to serve")
+
+// listenerError returns the error of the listener which stopped ExternalDNS, if any.
+func listenerError(ctx context.Context) error {
+	if err := context.Cause(ctx); errors.Is(err, errListener) {
+		return err
+	}
+	return nil
+}
+
+// serveMetrics serves the metrics and the other endpoints of the mux, the health endpoint and the
+// profiling endpoints with the profiles of the slow synchronizations, each on the listener configured for it,
+// until the context is done. A listener failing cancels the context with its error.
+func serveMetrics(ctx context.Context, cancel context.CancelCauseFunc, cfg *externaldns.Config, mux *http.ServeMux, slowCycles *profiling.SlowCycleRecorder) {
+	serve := func(listener server.Listener, handler http.Handler) {
+		if err := listener.ListenAndServe(ctx, handler); err != nil {
+			cancel(fmt.Errorf("%w on %s: %w", errListener, listener.Address, err))
+		}
+	}
+
+	healthMux := mux
+	if cfg.HealthzAddress != "" {
+		healthMux = http.NewServeMux()
+		go serve(server.Listener{
+			Address:         cfg.HealthzAddress,
+			TLSCertFile:     cfg.HealthzTLSCertFile,
+			TLSKeyFile:      cfg.HealthzTLSKeyFile,
+			TLSClientCAFile: cfg.HealthzTLSClientCAFile,
+			BasicAuthFile:   cfg.HealthzBasicAuthFile,
+		}, healthMux)
+	}
+	healthMux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+
+	if cfg.DebugAddress != "" {
+		debug := server.DebugHandler()
+		if slowCycles != nil {
+			debug.Handle("/debug/slow-cycles/", slowCycles.Handler("/debug/slow-cycles/"))
+		}
+		go serve(server.Listener{
+			Address:         cfg.DebugAddress,
+			TLSCertFile:     cfg.DebugTLSCertFile,
+			TLSKeyFile:      cfg.DebugTLSKeyFile,
+			TLSClientCAFile: cfg.DebugTLSClientCAFile,
+			BasicAuthFile:   cfg.DebugBasicAuthFile,
+		}, debug)
+	}
+
+	mux.Handle("/metrics", promhttp.Handler())
+
+	go serve(server.Listener{
+		Address:         cfg.MetricsAddress,
+		TLSCertFile:     cfg.MetricsTLSCertFile,
+		TLSKeyFile:      cfg.MetricsTLSKeyFile,
+		TLSClientCAFile: cfg.MetricsTLSClientCAFile,
+		BasicAuthFile:   cfg.MetricsBasicAuthFile,
+	}, mux)
+}
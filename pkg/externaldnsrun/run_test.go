@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaldnsrun
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+)
+
+func newConfig(t *testing.T, args ...string) *externaldns.Config {
+	cfg := externaldns.NewConfig()
+	require.NoError(t, cfg.ParseFlags(append([]string{"--source=fake", "--provider=inmemory"}, args...)))
+	return cfg
+}
+
+func TestRunOnce(t *testing.T) {
+	mux := http.NewServeMux()
+	require.NoError(t, Run(context.Background(), newConfig(t, "--once", "--status-api"), Options{Mux: mux}))
+
+	// the endpoints are registered on the mux of the embedding binary
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/inmemory/state", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRunUntilDone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, Run(ctx, newConfig(t, "--interval=10ms"), Options{Mux: http.NewServeMux()}))
+}
+
+func TestRunListenerFailure(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer busy.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = Run(ctx, newConfig(t, "--interval=10ms", "--metrics-address="+busy.Addr().String()), Options{})
+	assert.ErrorContains(t, err, "failed to serve on "+busy.Addr().String())
+	assert.NoError(t, ctx.Err())
+}
+
+func TestRunInvalidConfig(t *testing.T) {
+	cfg := newConfig(t)
+	cfg.Provider = ""
+	assert.ErrorContains(t, Run(context.Background(), cfg, Options{Mux: http.NewServeMux()}), "config validation failed")
+
+	cfg = newConfig(t)
+	cfg.Registry = "unknown"
+	assert.Error(t, Run(context.Background(), cfg, Options{Mux: http.NewServeMux()}))
+}
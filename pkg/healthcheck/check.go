@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// Check describes how a target is probed.
+type Check struct {
+	// Scheme is one of http, https, tcp or tls.
+	Scheme string
+	Port   string
+	// Path is the request path of http and https checks.
+	Path string
+}
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"tls":   "443",
+	"tcp":   "",
+}
+
+// ParseCheck parses a check in URL form without host, e.g. "https://:8443/healthz" or "tcp://:5432".
+func ParseCheck(s string) (Check, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return Check{}, err
+	}
+	defaultPort, ok := defaultPorts[u.Scheme]
+	if !ok {
+		return Check{}, fmt.Errorf("unsupported health check scheme %q", u.Scheme)
+	}
+	if u.Hostname() != "" {
+		return Check{}, fmt.Errorf("health check %q must not contain a host, the target is probed", s)
+	}
+	c := Check{Scheme: u.Scheme, Port: u.Port(), Path: u.RequestURI()}
+	if c.Port == "" {
+		c.Port = defaultPort
+	}
+	if c.Port == "" {
+		return Check{}, fmt.Errorf("health check %q requires a port", s)
+	}
+	return c, nil
+}
+
+// probe checks the target. The hostname is used as HTTP host and TLS server name,
+// so that the target is checked for serving the hostname with a valid certificate.
+func (c Check) probe(ctx context.Context, hostname, target string) error {
+	address := net.JoinHostPort(target, c.Port)
+	switch c.Scheme {
+	case "tcp":
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case "tls":
+		conn, err := (&tls.Dialer{Config: &tls.Config{ServerName: hostname}}).DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	default:
+		return c.probeHTTP(ctx, hostname, address)
+	}
+}
+
+func (c Check) probeHTTP(ctx context.Context, hostname, address string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s://%s%s", c.Scheme, hostname, c.Path), nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			// connect to the target while keeping the hostname for the Host header and SNI
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, address)
+			},
+			TLSClientConfig:   &tls.Config{ServerName: hostname},
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
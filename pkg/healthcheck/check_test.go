@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthcheck
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCheck(t *testing.T) {
+	for _, tc := range []struct {
+		check    string
+		expected Check
+		err      bool
+	}{
+		{check: "http://", expected: Check{Scheme: "http", Port: "80", Path: "/"}},
+		{check: "https://:8443/healthz?ready=1", expected: Check{Scheme: "https", Port: "8443", Path: "/healthz?ready=1"}},
+		{check: "tls://", expected: Check{Scheme: "tls", Port: "443", Path: "/"}},
+		{check: "tcp://:5432", expected: Check{Scheme: "tcp", Port: "5432", Path: "/"}},
+		{check: "tcp://", err: true},
+		{check: "udp://:53", err: true},
+		{check: "http://example.com/healthz", err: true},
+		{check: "://", err: true},
+	} {
+		t.Run(tc.check, func(t *testing.T) {
+			c, err := ParseCheck(tc.check)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, c)
+		})
+	}
+}
+
+func splitHostPort(t *testing.T, address string) (string, string) {
+	host, port, err := net.SplitHostPort(address)
+	require.NoError(t, err)
+	return host, port
+}
+
+func TestProbeHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "app.example.com", r.Host)
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	host, port := splitHostPort(t, server.Listener.Addr().String())
+
+	ctx := context.Background()
+	assert.NoError(t, Check{Scheme: "http", Port: port, Path: "/healthz"}.probe(ctx, "app.example.com", host))
+	assert.Error(t, Check{Scheme: "http", Port: port, Path: "/"}.probe(ctx, "app.example.com", host))
+}
+
+func TestProbeTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	host, port := splitHostPort(t, listener.Addr().String())
+
+	ctx := context.Background()
+	assert.NoError(t, Check{Scheme: "tcp", Port: port}.probe(ctx, "app.example.com", host))
+	require.NoError(t, listener.Close())
+	assert.Error(t, Check{Scheme: "tcp", Port: port}.probe(ctx, "app.example.com", host))
+}
+
+func TestProbeTLSRejectsUntrustedCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	host, port := splitHostPort(t, server.Listener.Addr().String())
+
+	ctx := context.Background()
+	assert.Error(t, Check{Scheme: "tls", Port: port}.probe(ctx, "example.com", host))
+	assert.Error(t, Check{Scheme: "https", Port: port, Path: "/"}.probe(ctx, "example.com", host))
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// staleTimeout is the time after which targets that are no longer requested are not probed anymore.
+const staleTimeout = time.Hour
+
+var unhealthyTargets = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "external_dns",
+		Subsystem: "source",
+		Name:      "health_check_unhealthy_targets",
+		Help:      "Number of probed targets that are currently considered unhealthy.",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(unhealthyTargets)
+}
+
+// Config is used for configuring a Prober.
+type Config struct {
+	// Interval between two probes of a target.
+	Interval time.Duration
+	// Timeout of a single probe.
+	Timeout time.Duration
+	// HealthyThreshold is the number of consecutive successful probes after which an unhealthy target is healthy again.
+	HealthyThreshold int
+	// UnhealthyThreshold is the number of consecutive failed probes after which a healthy target is unhealthy.
+	UnhealthyThreshold int
+}
+
+type targetKey struct {
+	check    Check
+	hostname string
+	target   string
+}
+
+type targetState struct {
+	healthy   bool
+	successes int
+	failures  int
+	lastSeen  time.Time
+}
+
+// record adds the result of a probe and returns true if the health of the target changed.
+// The thresholds avoid flapping of targets that fail only occasionally.
+func (s *targetState) record(err error, healthyThreshold, unhealthyThreshold int) bool {
+	if err == nil {
+		s.successes++
+		s.failures = 0
+		if !s.healthy && s.successes >= healthyThreshold {
+			s.healthy = true
+			return true
+		}
+		return false
+	}
+	s.failures++
+	s.successes = 0
+	if s.healthy && s.failures >= unhealthyThreshold {
+		s.healthy = false
+		return true
+	}
+	return false
+}
+
+// Prober actively probes the targets it has been asked about and tracks their health.
+type Prober struct {
+	cfg      Config
+	mutex    sync.Mutex
+	targets  map[targetKey]*targetState
+	onChange func()
+}
+
+// NewProber creates a new Prober.
+func NewProber(cfg Config) *Prober {
+	return &Prober{cfg: cfg, targets: map[targetKey]*targetState{}}
+}
+
+// SetHandler sets a function that is called whenever the health of a target changes.
+func (p *Prober) SetHandler(handler func()) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.onChange = handler
+}
+
+// Healthy returns whether the target passed the check for the given hostname.
+// Targets that are not known yet are registered for probing and considered healthy.
+func (p *Prober) Healthy(check Check, hostname, target string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	key := targetKey{check: check, hostname: hostname, target: target}
+	state, ok := p.targets[key]
+	if !ok {
+		state = &targetState{healthy: true}
+		p.targets[key] = state
+	}
+	state.lastSeen = time.Now()
+	return state.healthy
+}
+
+// Run probes all known targets every interval until the context is cancelled.
+func (p *Prober) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.probeAll(ctx)
+		}
+	}
+}
+
+func (p *Prober) probeAll(ctx context.Context) {
+	p.mutex.Lock()
+	keys := make([]targetKey, 0, len(p.targets))
+	for key, state := range p.targets {
+		if time.Since(state.lastSeen) > staleTimeout {
+			delete(p.targets, key)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	p.mutex.Unlock()
+
+	results := make([]error, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i int, key targetKey) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+			defer cancel()
+			results[i] = key.check.probe(ctx, key.hostname, key.target)
+		}(i, key)
+	}
+	wg.Wait()
+
+	p.mutex.Lock()
+	changed := false
+	unhealthy := 0
+	for i, key := range keys {
+		state, ok := p.targets[key]
+		if !ok {
+			continue
+		}
+		if state.record(results[i], p.cfg.HealthyThreshold, p.cfg.UnhealthyThreshold) {
+			changed = true
+			if state.healthy {
+				log.Infof("Target %s of %s is healthy again", key.target, key.hostname)
+			} else {
+				log.Warnf("Target %s of %s is unhealthy: %v", key.target, key.hostname, results[i])
+			}
+		}
+		if !state.healthy {
+			unhealthy++
+		}
+	}
+	onChange := p.onChange
+	p.mutex.Unlock()
+
+	unhealthyTargets.Set(float64(unhealthy))
+	if changed && onChange != nil {
+		onChange()
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetStateHysteresis(t *testing.T) {
+	failure := errors.New("failed")
+	state := &targetState{healthy: true}
+
+	assert.False(t, state.record(failure, 2, 3))
+	assert.False(t, state.record(nil, 2, 3))
+	assert.False(t, state.record(failure, 2, 3))
+	assert.False(t, state.record(failure, 2, 3))
+	assert.True(t, state.record(failure, 2, 3))
+	assert.False(t, state.healthy)
+
+	assert.False(t, state.record(nil, 2, 3))
+	assert.False(t, state.record(failure, 2, 3))
+	assert.False(t, state.record(nil, 2, 3))
+	assert.True(t, state.record(nil, 2, 3))
+	assert.True(t, state.healthy)
+}
+
+func TestProber(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	host, port := splitHostPort(t, listener.Addr().String())
+	check := Check{Scheme: "tcp", Port: port}
+
+	changes := 0
+	prober := NewProber(Config{Interval: time.Second, Timeout: time.Second, HealthyThreshold: 1, UnhealthyThreshold: 2})
+	prober.SetHandler(func() { changes++ })
+
+	// unknown targets are healthy until probed
+	assert.True(t, prober.Healthy(check, "app.example.com", host))
+
+	ctx := context.Background()
+	prober.probeAll(ctx)
+	assert.True(t, prober.Healthy(check, "app.example.com", host))
+
+	require.NoError(t, listener.Close())
+	prober.probeAll(ctx)
+	assert.True(t, prober.Healthy(check, "app.example.com", host))
+	prober.probeAll(ctx)
+	assert.False(t, prober.Healthy(check, "app.example.com", host))
+	assert.Equal(t, 1, changes)
+
+	// targets that are no longer requested are forgotten
+	prober.targets[targetKey{check: check, hostname: "app.example.com", target: host}].lastSeen = time.Now().Add(-2 * staleTimeout)
+	prober.probeAll(ctx)
+	assert.Empty(t, prober.targets)
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package octodns
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// Exporter writes records in the octoDNS YAML config format, one file per zone.
+// See https://github.com/octodns/octodns/blob/main/docs/records.md for the format.
+type Exporter struct {
+	dir   string
+	zones provider.ZoneIDName
+}
+
+// NewExporter returns an Exporter writing zone files for the given zones into dir.
+func NewExporter(dir string, zones []string) *Exporter {
+	zoneIDName := provider.ZoneIDName{}
+	for _, z := range zones {
+		z = strings.TrimSuffix(z, ".")
+		if z != "" {
+			zoneIDName.Add(z, z)
+		}
+	}
+	return &Exporter{dir: dir, zones: zoneIDName}
+}
+
+// Export writes the given records into one <zone>.yaml file per zone.
+// Zones without records are written as empty files, so that stale records do not survive.
+func (e *Exporter) Export(records []*endpoint.Endpoint) error {
+	perZone := map[string][]*endpoint.Endpoint{}
+	for _, zone := range e.zones {
+		perZone[zone] = nil
+	}
+	for _, ep := range records {
+		if ep.SetIdentifier != "" {
+			log.Debugf("Skipping octoDNS export of %s: set identifiers are not supported", ep.DNSName)
+			continue
+		}
+		_, zone := e.zones.FindZone(ep.DNSName)
+		if zone == "" {
+			log.Debugf("Skipping octoDNS export of %s: no matching zone", ep.DNSName)
+			continue
+		}
+		perZone[zone] = append(perZone[zone], ep)
+	}
+
+	if err := os.MkdirAll(e.dir, 0o755); err != nil {
+		return err
+	}
+	for zone, eps := range perZone {
+		data, err := yaml.Marshal(zoneConfig(zone, eps))
+		if err != nil {
+			return err
+		}
+		// write atomically so octoDNS never reads a partial file
+		path := filepath.Join(e.dir, zone+".yaml")
+		if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+			return err
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// zoneConfig builds the octoDNS representation of a zone, keyed by the name relative to the zone.
+func zoneConfig(zone string, endpoints []*endpoint.Endpoint) yaml.MapSlice {
+	byName := map[string][]yaml.MapSlice{}
+	for _, ep := range endpoints {
+		name := strings.TrimSuffix(strings.TrimSuffix(ep.DNSName, zone), ".")
+		byName[name] = append(byName[name], record(ep))
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	config := yaml.MapSlice{}
+	for _, name := range names {
+		records := byName[name]
+		sort.SliceStable(records, func(i, j int) bool {
+			return fmt.Sprint(records[i][0].Value) < fmt.Sprint(records[j][0].Value)
+		})
+		if len(records) == 1 {
+			config = append(config, yaml.MapItem{Key: name, Value: records[0]})
+		} else {
+			config = append(config, yaml.MapItem{Key: name, Value: records})
+		}
+	}
+	return config
+}
+
+func record(ep *endpoint.Endpoint) yaml.MapSlice {
+	r := yaml.MapSlice{{Key: "type", Value: ep.RecordType}}
+	if ep.RecordTTL.IsConfigured() {
+		r = append(r, yaml.MapItem{Key: "ttl", Value: int64(ep.RecordTTL)})
+	}
+
+	targets := append(endpoint.Targets(nil), ep.Targets...)
+	sort.Sort(targets)
+
+	values := make([]interface{}, 0, len(targets))
+	for _, t := range targets {
+		values = append(values, value(ep.RecordType, t))
+	}
+
+	// CNAME records can only have a single value in octoDNS
+	if ep.RecordType == endpoint.RecordTypeCNAME && len(values) > 0 {
+		return append(r, yaml.MapItem{Key: "value", Value: values[0]})
+	}
+	return append(r, yaml.MapItem{Key: "values", Value: values})
+}
+
+// value converts a target into the octoDNS value representation of the record type.
+func value(recordType, target string) interface{} {
+	switch recordType {
+	case endpoint.RecordTypeCNAME, endpoint.RecordTypeNS, endpoint.RecordTypePTR:
+		return fqdn(target)
+	case endpoint.RecordTypeTXT:
+		// octoDNS requires semicolons to be escaped
+		return strings.ReplaceAll(strings.Trim(target, "\""), ";", "\\;")
+	case endpoint.RecordTypeMX:
+		fields := strings.Fields(target)
+		if len(fields) != 2 {
+			return target
+		}
+		return yaml.MapSlice{
+			{Key: "preference", Value: atoi(fields[0])},
+			{Key: "exchange", Value: fqdn(fields[1])},
+		}
+	case endpoint.RecordTypeSRV:
+		fields := strings.Fields(target)
+		if len(fields) != 4 {
+			return target
+		}
+		return yaml.MapSlice{
+			{Key: "priority", Value: atoi(fields[0])},
+			{Key: "weight", Value: atoi(fields[1])},
+			{Key: "port", Value: atoi(fields[2])},
+			{Key: "target", Value: fqdn(fields[3])},
+		}
+	}
+	return target
+}
+
+func fqdn(name string) string {
+	return strings.TrimSuffix(name, ".") + "."
+}
+
+func atoi(s string) interface{} {
+	if i, err := strconv.Atoi(s); err == nil {
+		return i
+	}
+	return s
+}
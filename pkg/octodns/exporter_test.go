@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package octodns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestExport(t *testing.T) {
+	dir := t.TempDir()
+	exporter := NewExporter(dir, []string{"example.com.", "sub.example.com", "example.org"})
+
+	err := exporter.Export([]*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("example.com", endpoint.RecordTypeA, 300, "1.2.3.5", "1.2.3.4"),
+		endpoint.NewEndpoint("example.com", endpoint.RecordTypeTXT, "\"v=spf1 -all; x\""),
+		endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeCNAME, "example.com"),
+		endpoint.NewEndpoint("example.com", endpoint.RecordTypeMX, "10 mail.example.com"),
+		endpoint.NewEndpoint("_sip._tcp.example.com", endpoint.RecordTypeSRV, "1 2 5060 sip.example.com."),
+		endpoint.NewEndpoint("foo.sub.example.com", endpoint.RecordTypeA, "1.1.1.1"),
+		endpoint.NewEndpoint("weighted.example.com", endpoint.RecordTypeA, "2.2.2.2").WithSetIdentifier("a"),
+		endpoint.NewEndpoint("foo.example.net", endpoint.RecordTypeA, "3.3.3.3"),
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, "example.com.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `"":
+- type: A
+  ttl: 300
+  values:
+  - 1.2.3.4
+  - 1.2.3.5
+- type: MX
+  values:
+  - preference: 10
+    exchange: mail.example.com.
+- type: TXT
+  values:
+  - v=spf1 -all\; x
+_sip._tcp:
+  type: SRV
+  values:
+  - priority: 1
+    weight: 2
+    port: 5060
+    target: sip.example.com.
+www:
+  type: CNAME
+  value: example.com.
+`, string(data))
+
+	data, err = os.ReadFile(filepath.Join(dir, "sub.example.com.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "foo:\n  type: A\n  values:\n  - 1.1.1.1\n", string(data))
+
+	// zones without records are still written so that removed records disappear
+	data, err = os.ReadFile(filepath.Join(dir, "example.org.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "{}\n", string(data))
+
+	assert.NoFileExists(t, filepath.Join(dir, "example.net.yaml"))
+}
+
+func TestExportOverwrites(t *testing.T) {
+	dir := t.TempDir()
+	exporter := NewExporter(dir, []string{"example.com"})
+
+	require.NoError(t, exporter.Export([]*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.1.1.1")}))
+	require.NoError(t, exporter.Export(nil))
+
+	data, err := os.ReadFile(filepath.Join(dir, "example.com.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "{}\n", string(data))
+}
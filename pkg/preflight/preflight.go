@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// recordPrefix is the first label of the TXT record created and deleted in every zone by the write check.
+const recordPrefix = "external-dns-preflight-"
+
+// Check validates at startup that the credentials of the provider can list the zones and read the records
+// and, if write is true, create and delete a TXT record in every zone. Zones can only be checked
+// individually if the provider implements provider.ZoneLister.
+func Check(ctx context.Context, p provider.Provider, write bool) error {
+	var zones []string
+	if lister, ok := p.(provider.ZoneLister); ok {
+		var err error
+		zones, err = lister.ZoneNames(ctx)
+		if err != nil {
+			return fmt.Errorf("preflight check failed to list the zones, check that the credentials allow listing the zones: %w", err)
+		}
+		if len(zones) == 0 {
+			return errors.New("preflight check found no zones, check the credentials and the --domain-filter, --zone-id-filter and --aws-zone-type flags")
+		}
+		log.Infof("Preflight check listed %d zones", len(zones))
+	}
+
+	records, err := p.Records(ctx)
+	if err != nil {
+		return fmt.Errorf("preflight check failed to read the records, check that the credentials allow reading the records of all zones: %w", err)
+	}
+	log.Infof("Preflight check read %d records", len(records))
+
+	if !write {
+		return nil
+	}
+	if zones == nil {
+		log.Warn("Preflight check cannot check writing records, as the provider does not list its zones")
+		return nil
+	}
+
+	var errs []error
+	for _, zone := range zones {
+		if err := checkWrite(ctx, p, zone); err != nil {
+			errs = append(errs, fmt.Errorf("zone %s: %w", zone, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("preflight check failed to write records, check that the credentials allow changing the records of these zones: %w", errors.Join(errs...))
+	}
+	log.Infof("Preflight check wrote records to %d zones", len(zones))
+	return nil
+}
+
+// checkWrite creates and deletes a TXT record in the zone, so that the zone is left unchanged.
+func checkWrite(ctx context.Context, p provider.Provider, zone string) error {
+	record := endpoint.NewEndpoint(recordPrefix+strconv.FormatInt(time.Now().Unix(), 10)+"."+zone, endpoint.RecordTypeTXT, "\"external-dns preflight check\"")
+	if err := p.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{record}}); err != nil {
+		return fmt.Errorf("creating %s: %w", record.DNSName, err)
+	}
+	if err := p.ApplyChanges(ctx, &plan.Changes{Delete: []*endpoint.Endpoint{record}}); err != nil {
+		return fmt.Errorf("deleting %s, which must be deleted manually: %w", record.DNSName, err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+)
+
+// readOnlyProvider fails all changes, like a provider with read-only credentials.
+type readOnlyProvider struct {
+	*inmemory.InMemoryProvider
+}
+
+func (p *readOnlyProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	return errors.New("access denied")
+}
+
+// unreadableProvider fails to list the records.
+type unreadableProvider struct {
+	provider.BaseProvider
+}
+
+func (p *unreadableProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	return nil, errors.New("access denied")
+}
+
+func (p *unreadableProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	return nil
+}
+
+func TestCheck(t *testing.T) {
+	im := inmemory.NewInMemoryProvider(inmemory.InMemoryInitZones([]string{"example.com", "example.org"}))
+	require.NoError(t, Check(context.Background(), im, true))
+
+	// the zones are left unchanged
+	records, err := im.Records(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	readOnly := &readOnlyProvider{InMemoryProvider: im}
+	assert.NoError(t, Check(context.Background(), readOnly, false))
+	err = Check(context.Background(), readOnly, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "zone example.com")
+	assert.Contains(t, err.Error(), "zone example.org")
+
+	err = Check(context.Background(), inmemory.NewInMemoryProvider(), false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "found no zones")
+
+	err = Check(context.Background(), &unreadableProvider{}, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read the records")
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profiling
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// cpuSuffix and heapSuffix end the names of the profiles of a capture, named after the start of the cycle
+	cpuSuffix  = "-cpu.pprof"
+	heapSuffix = "-heap.pprof"
+	// timeFormat sorts the captures by time
+	timeFormat = "20060102T150405.000Z"
+)
+
+// SlowCycleRecorder captures the profiles of the reconcile cycles lasting longer than a threshold: a CPU profile
+// from the threshold to the end of the cycle, and a heap profile at the end. The profiles are written to a
+// directory holding the last captures only.
+type SlowCycleRecorder struct {
+	// Threshold is the duration of a cycle from which it is profiled
+	Threshold time.Duration
+	// Dir is the directory of the profiles
+	Dir string
+	// MaxCaptures is the number of captures kept in the directory, the oldest are removed
+	MaxCaptures int
+}
+
+// Watch starts watching a cycle, the returned function ends it. A nil recorder watches nothing.
+func (r *SlowCycleRecorder) Watch() func() {
+	if r == nil {
+		return func() {}
+	}
+	start := time.Now()
+
+	var (
+		mu        sync.Mutex
+		done      bool
+		slow      bool
+		profiling bool
+		cpu       bytes.Buffer
+	)
+	timer := time.AfterFunc(r.Threshold, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if done {
+			return
+		}
+		slow = true
+		// fails when a CPU profile is already running, e.g. requested on the debug endpoint
+		if err := pprof.StartCPUProfile(&cpu); err != nil {
+			log.Warnf("Failed to start the CPU profile of a slow cycle: %v", err)
+			return
+		}
+		profiling = true
+	})
+
+	return func() {
+		timer.Stop()
+		mu.Lock()
+		defer mu.Unlock()
+		done = true
+		if !slow {
+			return
+		}
+		if profiling {
+			pprof.StopCPUProfile()
+		}
+		duration := time.Since(start)
+		if err := r.save(start, cpu.Bytes()); err != nil {
+			log.Warnf("Failed to save the profiles of a slow cycle: %v", err)
+			return
+		}
+		log.Infof("The cycle took %s, longer than %s: its profiles were saved in %s", duration.Round(time.Millisecond), r.Threshold, r.Dir)
+	}
+}
+
+// save writes the profiles of a cycle and removes the oldest captures.
+func (r *SlowCycleRecorder) save(start time.Time, cpu []byte) error {
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+		return err
+	}
+	prefix := filepath.Join(r.Dir, start.UTC().Format(timeFormat))
+	if len(cpu) > 0 {
+		if err := os.WriteFile(prefix+cpuSuffix, cpu, 0o644); err != nil {
+			return err
+		}
+	}
+	heap, err := os.Create(prefix + heapSuffix)
+	if err != nil {
+		return err
+	}
+	if err := pprof.Lookup("heap").WriteTo(heap, 0); err != nil {
+		heap.Close()
+		return err
+	}
+	if err := heap.Close(); err != nil {
+		return err
+	}
+	return r.prune()
+}
+
+// prune removes the oldest captures beyond MaxCaptures.
+func (r *SlowCycleRecorder) prune() error {
+	entries, err := os.ReadDir(r.Dir)
+	if err != nil {
+		return err
+	}
+	captures := []string{}
+	for _, entry := range entries {
+		if capture, ok := strings.CutSuffix(entry.Name(), heapSuffix); ok {
+			captures = append(captures, capture)
+		}
+	}
+	sort.Strings(captures)
+	for len(captures) > r.MaxCaptures {
+		for _, suffix := range []string{cpuSuffix, heapSuffix} {
+			if err := os.Remove(filepath.Join(r.Dir, captures[0]+suffix)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		captures = captures[1:]
+	}
+	return nil
+}
+
+// Handler serves the list of the captured profiles and the profiles, to be mounted under a path prefix.
+func (r *SlowCycleRecorder) Handler(prefix string) http.Handler {
+	files := http.StripPrefix(prefix, http.FileServer(http.Dir(r.Dir)))
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != prefix {
+			files.ServeHTTP(w, req)
+			return
+		}
+		entries, err := os.ReadDir(r.Dir)
+		if err != nil && !os.IsNotExist(err) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		// the newest first
+		for i := len(entries) - 1; i >= 0; i-- {
+			if name := entries[i].Name(); strings.HasSuffix(name, ".pprof") {
+				fmt.Fprintln(w, prefix+name)
+			}
+		}
+	})
+}
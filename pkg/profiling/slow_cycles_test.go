@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profiling
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func profiles(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	require.NoError(t, err)
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestSlowCycleRecorderFastCycle(t *testing.T) {
+	r := &SlowCycleRecorder{Threshold: time.Hour, Dir: filepath.Join(t.TempDir(), "profiles"), MaxCaptures: 2}
+	r.Watch()()
+	assert.Empty(t, profiles(t, r.Dir))
+}
+
+func TestSlowCycleRecorderSlowCycle(t *testing.T) {
+	r := &SlowCycleRecorder{Threshold: time.Millisecond, Dir: filepath.Join(t.TempDir(), "profiles"), MaxCaptures: 2}
+	stop := r.Watch()
+	time.Sleep(50 * time.Millisecond)
+	stop()
+
+	names := profiles(t, r.Dir)
+	require.Len(t, names, 2)
+	assert.True(t, strings.HasSuffix(names[0], cpuSuffix), names[0])
+	assert.True(t, strings.HasSuffix(names[1], heapSuffix), names[1])
+}
+
+func TestSlowCycleRecorderKeepsLastCaptures(t *testing.T) {
+	r := &SlowCycleRecorder{Threshold: time.Millisecond, Dir: t.TempDir(), MaxCaptures: 2}
+	for _, capture := range []string{"20240101T000000.000Z", "20240102T000000.000Z"} {
+		for _, suffix := range []string{cpuSuffix, heapSuffix} {
+			require.NoError(t, os.WriteFile(filepath.Join(r.Dir, capture+suffix), []byte("profile"), 0o644))
+		}
+	}
+	// a capture without CPU profile, e.g. while another CPU profile was running
+	require.NoError(t, os.WriteFile(filepath.Join(r.Dir, "20240103T000000.000Z"+heapSuffix), []byte("profile"), 0o644))
+
+	require.NoError(t, r.prune())
+	assert.Equal(t, []string{
+		"20240102T000000.000Z" + cpuSuffix,
+		"20240102T000000.000Z" + heapSuffix,
+		"20240103T000000.000Z" + heapSuffix,
+	}, profiles(t, r.Dir))
+}
+
+func TestSlowCycleRecorderHandler(t *testing.T) {
+	r := &SlowCycleRecorder{Dir: t.TempDir()}
+	handler := r.Handler("/debug/slow-cycles/")
+	require.NoError(t, os.WriteFile(filepath.Join(r.Dir, "20240101T000000.000Z"+heapSuffix), []byte("old"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(r.Dir, "20240102T000000.000Z"+heapSuffix), []byte("new"), 0o644))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/slow-cycles/", nil))
+	assert.Equal(t, "/debug/slow-cycles/20240102T000000.000Z-heap.pprof\n/debug/slow-cycles/20240101T000000.000Z-heap.pprof\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/slow-cycles/20240102T000000.000Z-heap.pprof", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "new", rec.Body.String())
+
+	r.Dir = filepath.Join(r.Dir, "missing")
+	rec = httptest.NewRecorder()
+	r.Handler("/debug/slow-cycles/").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/slow-cycles/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// certificateReloader serves the certificate of a certificate and a key file, re-loaded when the files change,
+// e.g. when the Secret they are mounted from is updated with a renewed certificate.
+type certificateReloader struct {
+	certFile, keyFile string
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modTimes [2]time.Time
+}
+
+func newCertificateReloader(certFile, keyFile string) (*certificateReloader, error) {
+	r := &certificateReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// fileModTimes returns the modification times of the files, following the symbolic links of the mounted Secrets.
+func (r *certificateReloader) fileModTimes() ([2]time.Time, error) {
+	var modTimes [2]time.Time
+	for i, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return modTimes, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+func (r *certificateReloader) load() error {
+	modTimes, err := r.fileModTimes()
+	if err != nil {
+		return fmt.Errorf("could not load TLS cert: %w", err)
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("could not load TLS cert: %w", err)
+	}
+	r.cert, r.modTimes = &cert, modTimes
+	return nil
+}
+
+// GetCertificate returns the certificate, re-loaded first if its files changed since it was loaded. The previous
+// certificate is served while the files can't be loaded, e.g. while they are being replaced.
+func (r *certificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if modTimes, err := r.fileModTimes(); err == nil && modTimes != r.modTimes {
+		if err := r.load(); err != nil {
+			log.Warnf("Keeping the previous certificate of %s: %v", r.certFile, err)
+		} else {
+			log.Infof("Re-loaded the certificate of %s", r.certFile)
+		}
+	}
+	return r.cert, nil
+}
+
+// loadClientCAs loads the CA bundle verifying the certificates of the clients.
+func loadClientCAs(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate in the client CA file %s", caFile)
+	}
+	return pool, nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"strings"
+	"time"
+)
+
+// Listener is the configuration of an HTTP listener serving some of the endpoints of ExternalDNS.
+type Listener struct {
+	// Address is the address to listen on
+	Address string
+	// TLSCertFile and TLSKeyFile are the certificate and key to serve HTTPS, HTTP is served when they are empty.
+	// They are re-loaded when they change.
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSClientCAFile is the CA bundle verifying the certificate each client must present over HTTPS,
+	// no client certificate is required when it is empty
+	TLSClientCAFile string
+	// BasicAuthFile is a file with a username:password line per user allowed to send requests,
+	// no authentication is required when it is empty
+	BasicAuthFile string
+}
+
+// ListenAndServe serves the handler on the listener until it fails, or until the context is done,
+// in which case the server is closed and nil is returned.
+func (l Listener) ListenAndServe(ctx context.Context, handler http.Handler) error {
+	server, err := l.server(handler)
+	if err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { server.Close() })
+	defer stop()
+	if server.TLSConfig == nil {
+		err = server.ListenAndServe()
+	} else {
+		err = server.ListenAndServeTLS("", "")
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// server returns the HTTP server of the listener, with a TLS config when it serves HTTPS.
+func (l Listener) server(handler http.Handler) (*http.Server, error) {
+	handler, err := l.authenticate(handler)
+	if err != nil {
+		return nil, err
+	}
+	server := &http.Server{
+		Addr:              l.Address,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if l.TLSCertFile == "" {
+		return server, nil
+	}
+	certificate, err := newCertificateReloader(l.TLSCertFile, l.TLSKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	server.TLSConfig = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certificate.GetCertificate,
+	}
+	if l.TLSClientCAFile != "" {
+		server.TLSConfig.ClientCAs, err = loadClientCAs(l.TLSClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		server.TLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return server, nil
+}
+
+// authenticate wraps the handler with the basic authentication of the users of the basic auth file.
+func (l Listener) authenticate(handler http.Handler) (http.Handler, error) {
+	if l.BasicAuthFile == "" {
+		return handler, nil
+	}
+	content, err := os.ReadFile(l.BasicAuthFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the basic auth file: %w", err)
+	}
+	// the passwords are compared by hash, so that the comparison takes the same time whatever their length
+	users := map[string][sha256.Size]byte{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		username, password, ok := strings.Cut(line, ":")
+		if !ok || username == "" || password == "" {
+			return nil, fmt.Errorf("invalid line in the basic auth file %s, expected username:password", l.BasicAuthFile)
+		}
+		users[username] = sha256.Sum256([]byte(password))
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("no user in the basic auth file %s", l.BasicAuthFile)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if ok {
+			expected, known := users[username]
+			hash := sha256.Sum256([]byte(password))
+			if subtle.ConstantTimeCompare(hash[:], expected[:]) == 1 && known {
+				handler.ServeHTTP(w, r)
+				return
+			}
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="external-dns"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	}), nil
+}
+
+// DebugHandler returns the mux of the profiling endpoints under /debug/pprof/.
+func DebugHandler() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenerBasicAuth(t *testing.T) {
+	usersFile := filepath.Join(t.TempDir(), "users")
+	require.NoError(t, os.WriteFile(usersFile, []byte("# monitoring\nprometheus:s3cr3t\n\nadmin:p4ss:word\n"), 0o600))
+
+	handler, err := Listener{BasicAuthFile: usersFile}.authenticate(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		username, password string
+		expected           int
+	}{
+		{"prometheus", "s3cr3t", http.StatusOK},
+		{"admin", "p4ss:word", http.StatusOK},
+		{"prometheus", "wrong", http.StatusUnauthorized},
+		{"unknown", "s3cr3t", http.StatusUnauthorized},
+		{"", "", http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if tc.username != "" {
+			req.SetBasicAuth(tc.username, tc.password)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, tc.expected, rec.Code, tc.username)
+		if tc.expected == http.StatusUnauthorized {
+			assert.Equal(t, `Basic realm="external-dns"`, rec.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
+
+func TestListenerInvalidBasicAuthFile(t *testing.T) {
+	dir := t.TempDir()
+	for content, expected := range map[string]string{
+		"prometheus\n":  "invalid line",
+		"prometheus:\n": "invalid line",
+		"# nobody\n":    "no user",
+	} {
+		usersFile := filepath.Join(dir, "users")
+		require.NoError(t, os.WriteFile(usersFile, []byte(content), 0o600))
+		_, err := Listener{BasicAuthFile: usersFile}.authenticate(http.NotFoundHandler())
+		assert.ErrorContains(t, err, expected, content)
+	}
+
+	err := Listener{Address: "127.0.0.1:0", BasicAuthFile: filepath.Join(dir, "missing")}.ListenAndServe(context.Background(), http.NotFoundHandler())
+	assert.ErrorContains(t, err, "failed to read the basic auth file")
+}
+
+func TestListenerInvalidCertificate(t *testing.T) {
+	err := Listener{Address: "127.0.0.1:0", TLSCertFile: "/nonexistent/tls.crt", TLSKeyFile: "/nonexistent/tls.key"}.ListenAndServe(context.Background(), http.NotFoundHandler())
+	assert.ErrorContains(t, err, "could not load TLS cert")
+}
+
+func TestListenerClosedWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- Listener{Address: "127.0.0.1:0"}.ListenAndServe(ctx, http.NotFoundHandler()) }()
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestDebugHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "goroutine")
+
+	rec = httptest.NewRecorder()
+	DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+var conflictsGauge = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "external_dns",
+		Subsystem: "controller",
+		Name:      "terraform_conflicts",
+		Help:      "Number of records managed by both Terraform and ExternalDNS.",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(conflictsGauge)
+}
+
+// Conflict is a record managed by ExternalDNS that is also managed by a Terraform resource.
+type Conflict struct {
+	Endpoint  *endpoint.Endpoint
+	Terraform Record
+}
+
+// Reporter compares the records managed by ExternalDNS with the records in Terraform states or plans.
+type Reporter struct {
+	paths []string
+}
+
+// NewReporter returns a Reporter reading the given Terraform state or plan files.
+func NewReporter(paths []string) *Reporter {
+	return &Reporter{paths: paths}
+}
+
+// Export logs every record that is managed by both ExternalDNS and Terraform.
+// The files are read on every call, so that changes to the Terraform state are picked up.
+func (r *Reporter) Export(records []*endpoint.Endpoint) error {
+	var tfRecords []Record
+	for _, path := range r.paths {
+		loaded, err := LoadRecords(path)
+		if err != nil {
+			return err
+		}
+		tfRecords = append(tfRecords, loaded...)
+	}
+
+	conflicts := Conflicts(tfRecords, records)
+	for _, c := range conflicts {
+		log.Warnf("Record %s %s is managed by ExternalDNS and by Terraform resource %s", c.Endpoint.DNSName, c.Endpoint.RecordType, c.Terraform.Address)
+	}
+	conflictsGauge.Set(float64(len(conflicts)))
+	return nil
+}
+
+// Conflicts returns the endpoints that overlap with a Terraform managed record.
+// Records of the same name and type overlap unless their set identifiers differ,
+// a CNAME overlaps with any other record of the same name.
+func Conflicts(tfRecords []Record, endpoints []*endpoint.Endpoint) []Conflict {
+	byName := map[string][]Record{}
+	for _, r := range tfRecords {
+		byName[r.DNSName] = append(byName[r.DNSName], r)
+	}
+
+	var conflicts []Conflict
+	for _, ep := range endpoints {
+		name := strings.ToLower(strings.TrimSuffix(ep.DNSName, "."))
+		for _, r := range byName[name] {
+			sameRecord := r.RecordType == ep.RecordType && r.SetIdentifier == ep.SetIdentifier
+			cname := r.RecordType != ep.RecordType && (r.RecordType == endpoint.RecordTypeCNAME || ep.RecordType == endpoint.RecordTypeCNAME)
+			if sameRecord || cname {
+				conflicts = append(conflicts, Conflict{Endpoint: ep, Terraform: r})
+			}
+		}
+	}
+	return conflicts
+}
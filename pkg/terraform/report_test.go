@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestConflicts(t *testing.T) {
+	tfRecords := []Record{
+		{Address: "aws_route53_record.www", DNSName: "www.example.com", RecordType: endpoint.RecordTypeA},
+		{Address: "aws_route53_record.weighted", DNSName: "weighted.example.com", RecordType: endpoint.RecordTypeA, SetIdentifier: "tf"},
+		{Address: "aws_route53_record.api", DNSName: "api.example.com", RecordType: endpoint.RecordTypeCNAME},
+	}
+	endpoints := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("WWW.example.com.", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeAAAA, "::1"),
+		endpoint.NewEndpoint("weighted.example.com", endpoint.RecordTypeA, "1.2.3.4").WithSetIdentifier("external-dns"),
+		endpoint.NewEndpoint("api.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("other.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+	}
+
+	conflicts := Conflicts(tfRecords, endpoints)
+	require.Len(t, conflicts, 2)
+	assert.Equal(t, endpoints[0], conflicts[0].Endpoint)
+	assert.Equal(t, "aws_route53_record.www", conflicts[0].Terraform.Address)
+	assert.Equal(t, endpoints[3], conflicts[1].Endpoint)
+	assert.Equal(t, "aws_route53_record.api", conflicts[1].Terraform.Address)
+}
+
+func TestReporterExport(t *testing.T) {
+	reporter := NewReporter([]string{"testdata/terraform.tfstate", "testdata/plan.json"})
+	err := reporter.Export([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("api.example.com", endpoint.RecordTypeCNAME, "lb.example.com"),
+		endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+	})
+	require.NoError(t, err)
+	// www.example.com is managed by the state and by the plan
+	assert.Equal(t, 3.0, testutil.ToFloat64(conflictsGauge))
+
+	assert.Error(t, NewReporter([]string{"testdata/missing.tfstate"}).Export(nil))
+}
@@ -0,0 +1,320 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Record is a DNS record managed by Terraform.
+type Record struct {
+	// Address of the Terraform resource, e.g. module.dns.aws_route53_record.www
+	Address       string
+	DNSName       string
+	RecordType    string
+	SetIdentifier string
+}
+
+// resource is the provider independent view of a Terraform resource.
+type resource struct {
+	address    string
+	mode       string
+	typ        string
+	attributes map[string]interface{}
+}
+
+// stateFile covers the raw state (terraform.tfstate) as well as
+// the JSON output of `terraform show -json` for states and plans.
+type stateFile struct {
+	// raw state, version 4
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey   interface{}            `json:"index_key"`
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+	// terraform show -json <state>
+	Values *struct {
+		RootModule module `json:"root_module"`
+	} `json:"values"`
+	// terraform show -json <plan>
+	PlannedValues *struct {
+		RootModule module `json:"root_module"`
+	} `json:"planned_values"`
+	// the state the plan was made from, with the data sources
+	PriorState *struct {
+		Values struct {
+			RootModule module `json:"root_module"`
+		} `json:"values"`
+	} `json:"prior_state"`
+	// the configuration of the plan, with the references of the expressions
+	Configuration *struct {
+		RootModule configModule `json:"root_module"`
+	} `json:"configuration"`
+}
+
+type module struct {
+	Resources []struct {
+		Address string                 `json:"address"`
+		Mode    string                 `json:"mode"`
+		Type    string                 `json:"type"`
+		Values  map[string]interface{} `json:"values"`
+	} `json:"resources"`
+	ChildModules []module `json:"child_modules"`
+}
+
+type configModule struct {
+	Resources []struct {
+		Address string `json:"address"`
+		// the expressions are objects, or arrays of objects for the nested blocks
+		Expressions map[string]json.RawMessage `json:"expressions"`
+	} `json:"resources"`
+	ModuleCalls map[string]struct {
+		Module configModule `json:"module"`
+	} `json:"module_calls"`
+}
+
+// zoneAttributes are the attributes of the record resources identifying their zone.
+var zoneAttributes = []string{"zone_id", "zone_name", "domain"}
+
+// indexKey matches the index keys of the resource and module instance addresses, e.g. [0] or ["blue"].
+var indexKey = regexp.MustCompile(`\[[^\]]*\]`)
+
+// zones maps the zones of a state or plan to their names. The records of a plan only have a relative name and the ID
+// of their zone, their fully qualified name is computed once applied.
+type zones struct {
+	// byID maps the IDs of the zones, e.g. the zone_id of an aws_route53_zone, to their names
+	byID map[string]string
+	// byAddress maps the addresses of the zone resources, without index keys, to their names, for the zones whose
+	// ID is only known once applied
+	byAddress map[string]string
+	// references maps the addresses of the record resources, without index keys, to the resources referenced by
+	// the expressions of their zone attributes
+	references map[string][]string
+}
+
+// LoadRecords reads the DNS records from a Terraform state file or a plan in JSON format.
+func LoadRecords(path string) ([]Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var state stateFile
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse terraform state %s: %w", path, err)
+	}
+
+	resources := state.resources()
+	zones := state.zones(resources)
+	var records []Record
+	for _, res := range resources {
+		if res.mode != "managed" {
+			continue
+		}
+		if r, ok := toRecord(res, zones); ok {
+			records = append(records, r)
+		}
+	}
+	return records, nil
+}
+
+func (s *stateFile) resources() []resource {
+	var resources []resource
+	switch {
+	case s.PlannedValues != nil:
+		resources = s.PlannedValues.RootModule.resources(resources)
+		if s.PriorState != nil {
+			// the data sources are only part of the prior state
+			for _, res := range s.PriorState.Values.RootModule.resources(nil) {
+				if res.mode == "data" {
+					resources = append(resources, res)
+				}
+			}
+		}
+	case s.Values != nil:
+		resources = s.Values.RootModule.resources(resources)
+	default:
+		for _, r := range s.Resources {
+			address := r.Type + "." + r.Name
+			if r.Mode == "data" {
+				address = "data." + address
+			}
+			if r.Module != "" {
+				address = r.Module + "." + address
+			}
+			for _, i := range r.Instances {
+				a := address
+				switch key := i.IndexKey.(type) {
+				case string:
+					a = fmt.Sprintf("%s[%q]", address, key)
+				case float64:
+					a = fmt.Sprintf("%s[%d]", address, int(key))
+				}
+				resources = append(resources, resource{address: a, mode: r.Mode, typ: r.Type, attributes: i.Attributes})
+			}
+		}
+	}
+	return resources
+}
+
+func (m module) resources(resources []resource) []resource {
+	for _, r := range m.Resources {
+		resources = append(resources, resource{address: r.Address, mode: r.Mode, typ: r.Type, attributes: r.Values})
+	}
+	for _, child := range m.ChildModules {
+		resources = child.resources(resources)
+	}
+	return resources
+}
+
+// zones returns the zones of the resources, and the references to them of the configuration of a plan.
+func (s *stateFile) zones(resources []resource) zones {
+	z := zones{byID: map[string]string{}, byAddress: map[string]string{}, references: map[string][]string{}}
+	for _, res := range resources {
+		var id, name string
+		switch res.typ {
+		case "aws_route53_zone":
+			id, name = res.attr("zone_id", "id"), res.attr("name")
+		case "cloudflare_zone":
+			id, name = res.attr("id"), res.attr("zone", "name")
+		default:
+			continue
+		}
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		if name == "" {
+			continue
+		}
+		if id != "" {
+			z.byID[id] = name
+		}
+		z.byAddress[indexKey.ReplaceAllString(res.address, "")] = name
+	}
+	if s.Configuration != nil {
+		s.Configuration.RootModule.references("", z.references)
+	}
+	return z
+}
+
+// references collects the resources referenced by the zone attributes of the resources of the module.
+func (m configModule) references(prefix string, references map[string][]string) {
+	for _, r := range m.Resources {
+		for _, key := range zoneAttributes {
+			var expression struct {
+				References []string `json:"references"`
+			}
+			if err := json.Unmarshal(r.Expressions[key], &expression); err != nil {
+				continue
+			}
+			for _, reference := range expression.References {
+				references[prefix+r.Address] = append(references[prefix+r.Address], prefix+reference)
+			}
+		}
+	}
+	for name, call := range m.ModuleCalls {
+		call.Module.references(prefix+"module."+name+".", references)
+	}
+}
+
+// name returns the name of the zone of a record resource with the ID of the zone, or referencing it in the
+// configuration, empty if unknown.
+func (z zones) name(res resource, id string) string {
+	if name, ok := z.byID[id]; ok {
+		return name
+	}
+	for _, reference := range z.references[indexKey.ReplaceAllString(res.address, "")] {
+		if name, ok := z.byAddress[reference]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// attr returns the first non-empty string attribute of the keys.
+func (res resource) attr(keys ...string) string {
+	for _, k := range keys {
+		if v, ok := res.attributes[k].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// toRecord extracts the record identity from the known DNS resource types. The fully qualified names computed by
+// the providers, e.g. fqdn, are unknown in a plan, the name relative to the zone is joined with the zone then.
+func toRecord(res resource, zones zones) (Record, bool) {
+	r := Record{Address: res.address}
+	switch {
+	case res.typ == "aws_route53_record":
+		r.DNSName, r.RecordType, r.SetIdentifier = res.attr("fqdn"), res.attr("type"), res.attr("set_identifier")
+		if r.DNSName == "" {
+			r.DNSName = joinName(res.attr("name"), zones.name(res, res.attr("zone_id")))
+		}
+	case res.typ == "google_dns_record_set":
+		// the name is fully qualified
+		r.DNSName, r.RecordType = res.attr("name"), res.attr("type")
+	case res.typ == "cloudflare_record":
+		r.DNSName, r.RecordType = res.attr("hostname"), res.attr("type")
+		if r.DNSName == "" {
+			r.DNSName = joinName(res.attr("name"), zones.name(res, res.attr("zone_id")))
+		}
+	case res.typ == "digitalocean_record":
+		r.DNSName, r.RecordType = res.attr("fqdn"), res.attr("type")
+		if r.DNSName == "" {
+			r.DNSName = joinName(res.attr("name"), res.attr("domain"))
+		}
+	case strings.HasPrefix(res.typ, "azurerm_dns_") && strings.HasSuffix(res.typ, "_record"),
+		strings.HasPrefix(res.typ, "azurerm_private_dns_") && strings.HasSuffix(res.typ, "_record"):
+		// the record type is part of the resource type, e.g. azurerm_dns_cname_record
+		parts := strings.Split(res.typ, "_")
+		r.DNSName, r.RecordType = res.attr("fqdn"), strings.ToUpper(parts[len(parts)-2])
+		if r.DNSName == "" {
+			r.DNSName = joinName(res.attr("name"), res.attr("zone_name"))
+		}
+	default:
+		return Record{}, false
+	}
+	if r.DNSName == "" || r.RecordType == "" {
+		return Record{}, false
+	}
+	r.DNSName = strings.ToLower(strings.TrimSuffix(r.DNSName, "."))
+	return r, true
+}
+
+// joinName returns the fully qualified name of a record name relative to its zone, @ or empty for the apex. Names
+// already within the zone are kept, as the providers accept fully qualified names too. Without zone, the name is
+// returned as is.
+func joinName(name, zone string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+	switch {
+	case zone == "":
+		return name
+	case name == "" || name == "@" || name == zone:
+		return zone
+	case strings.HasSuffix(name, "."+zone):
+		return name
+	}
+	return name + "." + zone
+}
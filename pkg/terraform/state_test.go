@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRecordsFromState(t *testing.T) {
+	records, err := LoadRecords("testdata/terraform.tfstate")
+	require.NoError(t, err)
+	assert.Equal(t, []Record{
+		{Address: "aws_route53_record.www", DNSName: "www.example.com", RecordType: "A"},
+		{Address: "module.mail.azurerm_dns_mx_record.mx[0]", DNSName: "example.com", RecordType: "MX"},
+	}, records)
+}
+
+func TestLoadRecordsFromPlan(t *testing.T) {
+	records, err := LoadRecords("testdata/plan.json")
+	require.NoError(t, err)
+	// the fully qualified names are unknown in a plan, the names are joined with the zones: of the data sources of
+	// the prior state, of the zones created by the plan referenced in the configuration, or of the zone attributes
+	assert.Equal(t, []Record{
+		{Address: "aws_route53_record.api", DNSName: "api.internal.example.net", RecordType: "A"},
+		{Address: "aws_route53_record.www", DNSName: "www.example.com", RecordType: "A"},
+		{Address: "azurerm_dns_cname_record.docs", DNSName: "docs.example.dev", RecordType: "CNAME"},
+		{Address: "digitalocean_record.mail", DNSName: "mail.example.io", RecordType: "MX"},
+		{Address: "google_dns_record_set.api", DNSName: "api.example.com", RecordType: "CNAME"},
+		{Address: "module.cf.cloudflare_record.app[\"blue\"]", DNSName: "app.example.org", RecordType: "TXT"},
+	}, records)
+}
+
+func TestJoinName(t *testing.T) {
+	for _, tc := range []struct {
+		name, zone, expected string
+	}{
+		{"www", "example.com", "www.example.com"},
+		{"WWW", "Example.com.", "www.example.com"},
+		{"www.example.com", "example.com", "www.example.com"},
+		{"www.example.com.", "example.com", "www.example.com"},
+		{"@", "example.com", "example.com"},
+		{"", "example.com", "example.com"},
+		{"example.com", "example.com", "example.com"},
+		{"www", "", "www"},
+	} {
+		assert.Equal(t, tc.expected, joinName(tc.name, tc.zone), "%s in %s", tc.name, tc.zone)
+	}
+}
+
+func TestLoadRecordsErrors(t *testing.T) {
+	_, err := LoadRecords("testdata/missing.tfstate")
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "invalid.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o644))
+	_, err = LoadRecords(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bench generates planning workloads of known changes, to validate the correctness and measure the speed
+// of the algorithms calculating the changes of a plan, e.g. before replacing the current one.
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// Algorithm calculates the changes of the plan.
+type Algorithm func(p *plan.Plan) *plan.Changes
+
+// Algorithms is a registry of the algorithms compared by the harness, by name.
+var Algorithms = map[string]Algorithm{
+	"table": func(p *plan.Plan) *plan.Changes { return p.Calculate().Changes },
+}
+
+// Workload describes the records of a generated planning workload.
+type Workload struct {
+	// Endpoints is the number of current records
+	Endpoints int
+	// Churn is the rate of the current records changed between 0 and 1, a third of them being updated, a third
+	// deleted and a third replaced by created records
+	Churn float64
+	// Zones is the number of zones the records are spread over, 1 if zero
+	Zones int
+	// OwnerID is the owner of the current records, "default" if empty
+	OwnerID string
+	// Seed is the seed of the random choice of the changed records
+	Seed int64
+}
+
+// Case is a generated planning workload with the changes it expects.
+type Case struct {
+	Workload Workload
+	Current  []*endpoint.Endpoint
+	Desired  []*endpoint.Endpoint
+	Expected *plan.Changes
+}
+
+// Generate generates the current and desired records of the workload, in which each DNS name has a single record
+// of type A, AAAA or CNAME, every tenth of them with a set identifier.
+func Generate(w Workload) *Case {
+	if w.Zones <= 0 {
+		w.Zones = 1
+	}
+	if w.OwnerID == "" {
+		w.OwnerID = "default"
+	}
+	c := &Case{Workload: w, Expected: &plan.Changes{}}
+	churned := map[int]int{}
+	for k, i := range rand.New(rand.NewSource(w.Seed)).Perm(w.Endpoints)[:int(float64(w.Endpoints)*w.Churn)] {
+		churned[i] = k % 3
+	}
+	for i := 0; i < w.Endpoints; i++ {
+		current := newEndpoint(w, "app", i, 0)
+		current.Labels[endpoint.OwnerLabelKey] = w.OwnerID
+		c.Current = append(c.Current, current)
+
+		change, ok := churned[i]
+		switch {
+		case !ok:
+			c.Desired = append(c.Desired, newEndpoint(w, "app", i, 0))
+		case change == 0:
+			desired := newEndpoint(w, "app", i, 1)
+			c.Desired = append(c.Desired, desired)
+			c.Expected.UpdateOld = append(c.Expected.UpdateOld, current)
+			c.Expected.UpdateNew = append(c.Expected.UpdateNew, desired)
+		case change == 1:
+			c.Expected.Delete = append(c.Expected.Delete, current)
+		default:
+			c.Expected.Delete = append(c.Expected.Delete, current)
+			created := newEndpoint(w, "new", i, 0)
+			c.Desired = append(c.Desired, created)
+			c.Expected.Create = append(c.Expected.Create, created)
+		}
+	}
+	return c
+}
+
+// newEndpoint returns the i-th endpoint of the workload with the given prefix, whose targets change with version.
+func newEndpoint(w Workload, prefix string, i, version int) *endpoint.Endpoint {
+	name := fmt.Sprintf("%s-%d.zone-%d.example.com", prefix, i, i%w.Zones)
+	var ep *endpoint.Endpoint
+	switch i % 3 {
+	case 0:
+		ep = endpoint.NewEndpointWithTTL(name, endpoint.RecordTypeA, 300, fmt.Sprintf("10.%d.%d.%d", version, i/256%256, i%256))
+	case 1:
+		ep = endpoint.NewEndpointWithTTL(name, endpoint.RecordTypeAAAA, 300, fmt.Sprintf("2001:db8::%x:%x", version, i%0x10000))
+	default:
+		ep = endpoint.NewEndpointWithTTL(name, endpoint.RecordTypeCNAME, 300, fmt.Sprintf("lb-%d-%d.elb.example.net", i%100, version))
+	}
+	if i%10 == 0 {
+		ep.SetIdentifier = "primary"
+	}
+	return ep
+}
+
+// Plan returns the plan of the case, with the owner of its current records.
+func (c *Case) Plan() *plan.Plan {
+	return &plan.Plan{
+		Current:        c.Current,
+		Desired:        c.Desired,
+		Policies:       []plan.Policy{&plan.SyncPolicy{}},
+		ManagedRecords: []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
+		OwnerID:        c.Workload.OwnerID,
+	}
+}
+
+// Result is the outcome of running an algorithm on a case.
+type Result struct {
+	Changes  *plan.Changes
+	Duration time.Duration
+	// Mismatches describe the changes differing from the expected changes of the case
+	Mismatches []string
+}
+
+// Run calculates the changes of the case with the algorithm and compares them to the expected changes.
+func Run(c *Case, algorithm Algorithm) Result {
+	start := time.Now()
+	changes := algorithm(c.Plan())
+	duration := time.Since(start)
+	return Result{Changes: changes, Duration: duration, Mismatches: Diff(c.Expected, changes)}
+}
+
+// Diff describes the differences between the expected and actual changes, regardless of their order. The updates
+// are compared as pairs of current and desired records.
+func Diff(expected, actual *plan.Changes) []string {
+	var diff []string
+	compare := func(kind string, expected, actual []string) {
+		slices.Sort(expected)
+		slices.Sort(actual)
+		i, j := 0, 0
+		for i < len(expected) || j < len(actual) {
+			switch {
+			case j == len(actual) || (i < len(expected) && expected[i] < actual[j]):
+				diff = append(diff, fmt.Sprintf("missing %s %s", kind, expected[i]))
+				i++
+			case i == len(expected) || actual[j] < expected[i]:
+				diff = append(diff, fmt.Sprintf("unexpected %s %s", kind, actual[j]))
+				j++
+			default:
+				i++
+				j++
+			}
+		}
+	}
+	compare("create", describe(expected.Create), describe(actual.Create))
+	compare("update", describeUpdates(expected), describeUpdates(actual))
+	compare("delete", describe(expected.Delete), describe(actual.Delete))
+	return diff
+}
+
+func describe(endpoints []*endpoint.Endpoint) []string {
+	described := make([]string, 0, len(endpoints))
+	for _, ep := range endpoints {
+		described = append(described, ep.String())
+	}
+	return described
+}
+
+func describeUpdates(changes *plan.Changes) []string {
+	described := make([]string, 0, len(changes.UpdateNew))
+	for i, ep := range changes.UpdateNew {
+		previous := "<none>"
+		if i < len(changes.UpdateOld) {
+			previous = changes.UpdateOld[i].String()
+		}
+		described = append(described, previous+" -> "+ep.String())
+	}
+	return described
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bench
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+var (
+	algorithmsFlag = flag.String("plan-algorithms", "", "comma-separated names of the algorithms to benchmark (default: all)")
+	endpointsFlag  = flag.String("plan-endpoints", "10000,100000,500000", "comma-separated numbers of endpoints of the benchmarked workloads")
+	churnFlag      = flag.Float64("plan-churn", 0.05, "rate of the endpoints changed in the benchmarked workloads")
+)
+
+// selectedAlgorithms returns the names of the algorithms selected with -plan-algorithms, sorted.
+func selectedAlgorithms(t testing.TB) []string {
+	var names []string
+	for name := range Algorithms {
+		names = append(names, name)
+	}
+	if *algorithmsFlag != "" {
+		names = strings.Split(*algorithmsFlag, ",")
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		require.Contains(t, Algorithms, name, "unknown plan algorithm")
+	}
+	return names
+}
+
+func TestGenerate(t *testing.T) {
+	c := Generate(Workload{Endpoints: 300, Churn: 0.1, Zones: 4, Seed: 1})
+	assert.Len(t, c.Current, 300)
+	assert.Len(t, c.Desired, 300-10)
+	assert.Len(t, c.Expected.UpdateOld, 10)
+	assert.Len(t, c.Expected.UpdateNew, 10)
+	assert.Len(t, c.Expected.Create, 10)
+	assert.Len(t, c.Expected.Delete, 20)
+	for _, ep := range c.Current {
+		assert.Equal(t, "default", ep.Labels[endpoint.OwnerLabelKey])
+	}
+
+	// the workload is reproducible from its seed
+	assert.Equal(t, describe(c.Desired), describe(Generate(Workload{Endpoints: 300, Churn: 0.1, Zones: 4, Seed: 1}).Desired))
+}
+
+func TestAlgorithms(t *testing.T) {
+	for _, name := range selectedAlgorithms(t) {
+		for _, w := range []Workload{
+			{Endpoints: 1000},
+			{Endpoints: 1000, Churn: 0.1, Zones: 10, Seed: 2},
+			{Endpoints: 999, Churn: 1, OwnerID: "cluster-a", Seed: 3},
+		} {
+			t.Run(fmt.Sprintf("%s/%d/%g", name, w.Endpoints, w.Churn), func(t *testing.T) {
+				result := Run(Generate(w), Algorithms[name])
+				assert.Empty(t, result.Mismatches)
+			})
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeA, "1.2.3.4")
+	b := endpoint.NewEndpoint("b.example.com", endpoint.RecordTypeA, "1.2.3.4")
+	b2 := endpoint.NewEndpoint("b.example.com", endpoint.RecordTypeA, "5.6.7.8")
+
+	assert.Empty(t, Diff(
+		&plan.Changes{Create: []*endpoint.Endpoint{a, b}, UpdateOld: []*endpoint.Endpoint{b}, UpdateNew: []*endpoint.Endpoint{b2}},
+		&plan.Changes{Create: []*endpoint.Endpoint{b, a}, UpdateOld: []*endpoint.Endpoint{b}, UpdateNew: []*endpoint.Endpoint{b2}},
+	))
+	assert.Equal(t, []string{
+		"missing create " + b.String(),
+		"unexpected update " + b2.String() + " -> " + b.String(),
+		"unexpected delete " + a.String(),
+	}, Diff(
+		&plan.Changes{Create: []*endpoint.Endpoint{a, b}},
+		&plan.Changes{Create: []*endpoint.Endpoint{a}, UpdateOld: []*endpoint.Endpoint{b2}, UpdateNew: []*endpoint.Endpoint{b}, Delete: []*endpoint.Endpoint{a}},
+	))
+}
+
+// BenchmarkPlan benchmarks the selected algorithms on workloads of the selected sizes and churn, e.g.
+// go test ./plan/bench -run '^$' -bench . -benchmem -args -plan-algorithms=table -plan-endpoints=10000 -plan-churn=0.2
+func BenchmarkPlan(b *testing.B) {
+	for _, size := range strings.Split(*endpointsFlag, ",") {
+		endpoints, err := strconv.Atoi(size)
+		require.NoError(b, err)
+		c := Generate(Workload{Endpoints: endpoints, Churn: *churnFlag, Zones: 100, Seed: 1})
+		for _, name := range selectedAlgorithms(b) {
+			b.Run(fmt.Sprintf("%s/endpoints=%d", name, endpoints), func(b *testing.B) {
+				require.Empty(b, Run(c, Algorithms[name]).Mismatches)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					Algorithms[name](c.Plan())
+				}
+			})
+		}
+	}
+}
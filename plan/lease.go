@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// Lease lets one of several instances sharing an owner ID manage a record set, so that instances with
+// overlapping filters don't undo each other's changes. The instance managing a record set holds a lease
+// on it in the lease label, which it renews while it desires the record set. The other instances leave
+// the record set alone until the lease expires.
+type Lease struct {
+	// Instance is the unique name of this instance, e.g. its pod name.
+	Instance string
+	// Duration is the time after which the lease of an instance that stopped renewing it expires.
+	Duration time.Duration
+	// Now returns the current time, defaults to time.Now.
+	Now func() time.Time
+}
+
+// leaseValue is the value of the lease label.
+type leaseValue struct {
+	instance string
+	expires  time.Time
+}
+
+func (l leaseValue) String() string {
+	return fmt.Sprintf("%s@%d", l.instance, l.expires.Unix())
+}
+
+func parseLease(value string) (leaseValue, bool) {
+	instance, expires, ok := strings.Cut(value, "@")
+	if !ok || instance == "" {
+		return leaseValue{}, false
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return leaseValue{}, false
+	}
+	return leaseValue{instance: instance, expires: time.Unix(unix, 0)}, true
+}
+
+func (l *Lease) now() time.Time {
+	if l.Now != nil {
+		return l.Now()
+	}
+	return time.Now()
+}
+
+// enabled returns true if the leases are taken and respected.
+func (l *Lease) enabled() bool {
+	return l != nil && l.Instance != "" && l.Duration > 0
+}
+
+// heldByOther returns the instance holding an unexpired lease on one of the records, if it is not this instance.
+func (l *Lease) heldByOther(records []*endpoint.Endpoint) (string, bool) {
+	if !l.enabled() {
+		return "", false
+	}
+	now := l.now()
+	for _, record := range records {
+		lease, ok := parseLease(record.Labels[endpoint.LeaseLabelKey])
+		if ok && lease.instance != l.Instance && lease.expires.After(now) {
+			return lease.instance, true
+		}
+	}
+	return "", false
+}
+
+// needsRenewal returns true if the lease of this instance on the record has passed half its duration,
+// or if the record has no valid lease. The lease is renewed only then, to avoid updating the record
+// on every synchronization.
+func (l *Lease) needsRenewal(record *endpoint.Endpoint) bool {
+	if !l.enabled() {
+		return false
+	}
+	lease, ok := parseLease(record.Labels[endpoint.LeaseLabelKey])
+	return !ok || lease.instance != l.Instance || lease.expires.Sub(l.now()) <= l.Duration/2
+}
+
+// take sets the lease label of this instance on the endpoint, keeping the unexpired lease of the current record
+// unless it needs renewal.
+func (l *Lease) take(ep, current *endpoint.Endpoint) {
+	if !l.enabled() {
+		return
+	}
+	if ep.Labels == nil {
+		ep.Labels = endpoint.NewLabels()
+	}
+	if current != nil && !l.needsRenewal(current) {
+		ep.Labels[endpoint.LeaseLabelKey] = current.Labels[endpoint.LeaseLabelKey]
+		return
+	}
+	ep.Labels[endpoint.LeaseLabelKey] = leaseValue{instance: l.Instance, expires: l.now().Add(l.Duration)}.String()
+}
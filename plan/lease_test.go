@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func leasedEndpoint(dnsName, target, instance string, expires time.Time) *endpoint.Endpoint {
+	ep := endpoint.NewEndpoint(dnsName, endpoint.RecordTypeA, target)
+	ep.Labels[endpoint.OwnerLabelKey] = "owner"
+	if instance != "" {
+		ep.Labels[endpoint.LeaseLabelKey] = fmt.Sprintf("%s@%d", instance, expires.Unix())
+	}
+	return ep
+}
+
+func leasePlan(now time.Time, current, desired []*endpoint.Endpoint) *Plan {
+	return &Plan{
+		Policies:       []Policy{&SyncPolicy{}},
+		Current:        current,
+		Desired:        desired,
+		ManagedRecords: []string{endpoint.RecordTypeA},
+		OwnerID:        "owner",
+		Lease: &Lease{
+			Instance: "a",
+			Duration: 10 * time.Minute,
+			Now:      func() time.Time { return now },
+		},
+	}
+}
+
+func TestParseLease(t *testing.T) {
+	lease, ok := parseLease("pod-a@1700000000")
+	assert.True(t, ok)
+	assert.Equal(t, "pod-a", lease.instance)
+	assert.Equal(t, time.Unix(1700000000, 0), lease.expires)
+	assert.Equal(t, "pod-a@1700000000", lease.String())
+
+	for _, value := range []string{"", "pod-a", "@1700000000", "pod-a@soon"} {
+		_, ok := parseLease(value)
+		assert.False(t, ok, value)
+	}
+}
+
+func TestLeaseHeldByOtherInstanceIsRespected(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	current := []*endpoint.Endpoint{
+		leasedEndpoint("update.example.com", "1.2.3.4", "b", now.Add(time.Minute)),
+		leasedEndpoint("delete.example.com", "1.2.3.4", "b", now.Add(time.Minute)),
+	}
+	desired := []*endpoint.Endpoint{endpoint.NewEndpoint("update.example.com", endpoint.RecordTypeA, "5.6.7.8")}
+
+	changes := leasePlan(now, current, desired).Calculate().Changes
+	assert.False(t, changes.HasChanges())
+}
+
+func TestExpiredLeaseIsTakenOver(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	current := []*endpoint.Endpoint{leasedEndpoint("foo.example.com", "1.2.3.4", "b", now.Add(-time.Second))}
+	desired := []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4")}
+
+	changes := leasePlan(now, current, desired).Calculate().Changes
+	if assert.Len(t, changes.UpdateNew, 1) {
+		assert.Equal(t, fmt.Sprintf("a@%d", now.Add(10*time.Minute).Unix()), changes.UpdateNew[0].Labels[endpoint.LeaseLabelKey])
+		assert.Equal(t, "owner", changes.UpdateNew[0].Labels[endpoint.OwnerLabelKey])
+	}
+}
+
+func TestLeaseIsRenewedAfterHalfItsDuration(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	desired := []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4")}
+
+	// the lease is recent, the record is left alone
+	current := []*endpoint.Endpoint{leasedEndpoint("foo.example.com", "1.2.3.4", "a", now.Add(9*time.Minute))}
+	changes := leasePlan(now, current, desired).Calculate().Changes
+	assert.False(t, changes.HasChanges())
+
+	// the lease is kept on the records updated for other reasons
+	desired[0].Targets = endpoint.NewTargets("5.6.7.8")
+	changes = leasePlan(now, current, desired).Calculate().Changes
+	if assert.Len(t, changes.UpdateNew, 1) {
+		assert.Equal(t, current[0].Labels[endpoint.LeaseLabelKey], changes.UpdateNew[0].Labels[endpoint.LeaseLabelKey])
+	}
+
+	// the lease expires within half its duration, it is renewed
+	desired[0].Targets = endpoint.NewTargets("1.2.3.4")
+	current = []*endpoint.Endpoint{leasedEndpoint("foo.example.com", "1.2.3.4", "a", now.Add(4*time.Minute))}
+	changes = leasePlan(now, current, desired).Calculate().Changes
+	if assert.Len(t, changes.UpdateNew, 1) {
+		assert.Equal(t, fmt.Sprintf("a@%d", now.Add(10*time.Minute).Unix()), changes.UpdateNew[0].Labels[endpoint.LeaseLabelKey])
+	}
+}
+
+func TestLeaseIsTakenOnCreate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	desired := []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4")}
+
+	changes := leasePlan(now, nil, desired).Calculate().Changes
+	if assert.Len(t, changes.Create, 1) {
+		assert.Equal(t, fmt.Sprintf("a@%d", now.Add(10*time.Minute).Unix()), changes.Create[0].Labels[endpoint.LeaseLabelKey])
+	}
+}
+
+func TestRecordsWithoutLeaseAreUnchangedWithoutLease(t *testing.T) {
+	current := []*endpoint.Endpoint{leasedEndpoint("foo.example.com", "1.2.3.4", "", time.Time{})}
+	desired := []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4")}
+
+	p := leasePlan(time.Now(), current, desired)
+	p.Lease = nil
+	assert.False(t, p.Calculate().Changes.HasChanges())
+}
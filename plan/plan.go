@@ -18,7 +18,6 @@ package plan
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	log "github.com/sirupsen/logrus"
@@ -41,6 +40,11 @@ type Plan struct {
 	// List of changes necessary to move towards desired state
 	// Populated after calling Calculate()
 	Changes *Changes
+	// SeparateTTLRepairs moves the updates only changing the TTL of records out of Changes into TTLRepairs
+	SeparateTTLRepairs bool
+	// TTLRepairs are the updates only changing the TTL of records, to which the policies don't apply
+	// Populated after calling Calculate() if SeparateTTLRepairs is set
+	TTLRepairs *Changes
 	// DomainFilter matches DNS names
 	DomainFilter endpoint.MatchAllDomainFilters
 	// ManagedRecords are DNS record types that will be considered for management.
@@ -49,6 +53,11 @@ type Plan struct {
 	ExcludeRecords []string
 	// OwnerID of records to manage
 	OwnerID string
+	// PropertyComparator compares the desired and current values of provider-specific properties,
+	// a property missing on either side having the empty value. If nil, the values must be equal.
+	PropertyComparator PropertyComparator
+	// Lease of this instance on the record sets it manages. If nil, record sets are managed regardless of leases.
+	Lease *Lease
 }
 
 // Changes holds lists of actions to be executed by dns providers
@@ -179,8 +188,18 @@ func (p *Plan) Calculate() *Plan {
 	}
 
 	changes := &Changes{}
+	repairs := &Changes{}
 
 	for key, row := range t.rows {
+		// dns name managed by another instance
+		if holder, ok := p.Lease.heldByOther(row.current); ok {
+			endpoint.Skipped(endpoint.SkipReasonLeased).WithFields(log.Fields{
+				"filter": endpoint.LeaseLabelKey,
+				"found":  holder,
+			}).Debugf("Skipping %s, leased by instance %s", key.dnsName, holder)
+			continue
+		}
+
 		// dns name not taken
 		if len(row.current) == 0 {
 			recordsByType := t.resolver.ResolveRecordTypes(key, row)
@@ -220,11 +239,31 @@ func (p *Plan) Calculate() *Plan {
 				// update existing record
 				if records.current != nil && len(records.candidates) > 0 {
 					update := t.resolver.ResolveUpdate(records.current, records.candidates)
+					adopt := p.shouldAdopt(update, records.current)
+					otherChanged := targetChanged(update, records.current) || p.shouldUpdateProviderSpecific(update, records.current) || releaseChanged(update, records.current) || adopt || p.Lease.needsRenewal(records.current)
+					onlyTTLChanged := !otherChanged && shouldUpdateTTL(update, records.current)
 
-					if shouldUpdateTTL(update, records.current) || targetChanged(update, records.current) || p.shouldUpdateProviderSpecific(update, records.current) {
+					if onlyTTLChanged || otherChanged {
 						inheritOwner(records.current, update)
+						p.Lease.take(update, records.current)
+						current := records.current
+						if p.OwnerID != "" && records.current.IsReleasedTo(p.OwnerID) {
+							// the current owner hands the record over, claim it
+							update.Labels[endpoint.OwnerLabelKey] = p.OwnerID
+						}
+						if adopt {
+							// the record has no owner, claim it and mark it as adopted for the registry
+							update.Labels[endpoint.OwnerLabelKey] = p.OwnerID
+							current = records.current.DeepCopy()
+							current.Labels[endpoint.AdoptLabelKey] = p.OwnerID
+						}
+						if p.SeparateTTLRepairs && onlyTTLChanged {
+							repairs.UpdateNew = append(repairs.UpdateNew, update)
+							repairs.UpdateOld = append(repairs.UpdateOld, current)
+							continue
+						}
 						changes.UpdateNew = append(changes.UpdateNew, update)
-						changes.UpdateOld = append(changes.UpdateOld, records.current)
+						changes.UpdateOld = append(changes.UpdateOld, current)
 					}
 				}
 			}
@@ -249,12 +288,25 @@ func (p *Plan) Calculate() *Plan {
 		changes = pol.Apply(changes)
 	}
 
+	// the adopt request is not persisted with the records
+	for _, eps := range [][]*endpoint.Endpoint{changes.Create, changes.UpdateNew} {
+		for _, ep := range eps {
+			delete(ep.Labels, endpoint.AdoptLabelKey)
+		}
+	}
+
+	for _, ep := range changes.Create {
+		p.Lease.take(ep, nil)
+	}
+
 	// filter out updates this external dns does not have ownership claim over
 	if p.OwnerID != "" {
 		changes.Delete = endpoint.FilterEndpointsByOwnerID(p.OwnerID, changes.Delete)
 		changes.Delete = endpoint.RemoveDuplicates(changes.Delete)
-		changes.UpdateOld = endpoint.FilterEndpointsByOwnerID(p.OwnerID, changes.UpdateOld)
+		changes.UpdateOld = endpoint.FilterEndpointsClaimableBy(p.OwnerID, changes.UpdateOld)
 		changes.UpdateNew = endpoint.FilterEndpointsByOwnerID(p.OwnerID, changes.UpdateNew)
+		repairs.UpdateOld = endpoint.FilterEndpointsClaimableBy(p.OwnerID, repairs.UpdateOld)
+		repairs.UpdateNew = endpoint.FilterEndpointsByOwnerID(p.OwnerID, repairs.UpdateNew)
 	}
 
 	plan := &Plan{
@@ -263,6 +315,9 @@ func (p *Plan) Calculate() *Plan {
 		Changes:        changes,
 		ManagedRecords: []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
 	}
+	if p.SeparateTTLRepairs {
+		plan.TTLRepairs = repairs
+	}
 
 	return plan
 }
@@ -277,8 +332,18 @@ func inheritOwner(from, to *endpoint.Endpoint) {
 	to.Labels[endpoint.OwnerLabelKey] = from.Labels[endpoint.OwnerLabelKey]
 }
 
+// releaseChanged returns true if the desired endpoint releases the record to a different owner, or stops releasing it.
+func releaseChanged(desired, current *endpoint.Endpoint) bool {
+	return desired.Labels[endpoint.ReleaseToLabelKey] != current.Labels[endpoint.ReleaseToLabelKey]
+}
+
+// shouldAdopt returns true if the desired endpoint requests adopting the current record, which has no owner.
+func (p *Plan) shouldAdopt(desired, current *endpoint.Endpoint) bool {
+	return p.OwnerID != "" && current.Labels[endpoint.OwnerLabelKey] == "" && desired.Labels[endpoint.AdoptLabelKey] == "true"
+}
+
 func targetChanged(desired, current *endpoint.Endpoint) bool {
-	return !desired.Targets.Same(current.Targets)
+	return !desired.CanonicalTargets().Same(current.CanonicalTargets())
 }
 
 func shouldUpdateTTL(desired, current *endpoint.Endpoint) bool {
@@ -295,17 +360,28 @@ func (p *Plan) shouldUpdateProviderSpecific(desired, current *endpoint.Endpoint)
 		desiredProperties[d.Name] = d
 	}
 	for _, c := range current.ProviderSpecific {
-		if d, ok := desiredProperties[c.Name]; ok {
-			if c.Value != d.Value {
-				return true
-			}
+		d, ok := desiredProperties[c.Name]
+		if !p.propertyValuesEqual(c.Name, d.Value, c.Value) {
+			return true
+		}
+		if ok {
 			delete(desiredProperties, c.Name)
-		} else {
+		}
+	}
+	for _, d := range desiredProperties {
+		if !p.propertyValuesEqual(d.Name, d.Value, "") {
 			return true
 		}
 	}
 
-	return len(desiredProperties) > 0
+	return false
+}
+
+func (p *Plan) propertyValuesEqual(name, desired, current string) bool {
+	if p.PropertyComparator != nil {
+		return p.PropertyComparator(name, desired, current)
+	}
+	return desired == current
 }
 
 // filterRecordsForPlan removes records that are not relevant to the planner.
@@ -321,7 +397,10 @@ func filterRecordsForPlan(records []*endpoint.Endpoint, domainFilter endpoint.Ma
 	for _, record := range records {
 		// Ignore records that do not match the domain filter provided
 		if !domainFilter.Match(record.DNSName) {
-			log.Debugf("ignoring record %s that does not match domain filter", record.DNSName)
+			endpoint.Skipped(endpoint.SkipReasonDomainFilter).WithFields(log.Fields{
+				"filter": "domain-filter",
+				"found":  record.DNSName,
+			}).Debugf("ignoring record %s that does not match domain filter", record.DNSName)
 			continue
 		}
 		if IsManagedRecord(record.RecordType, managedRecords, excludeRecords) {
@@ -333,13 +412,9 @@ func filterRecordsForPlan(records []*endpoint.Endpoint, domainFilter endpoint.Ma
 }
 
 // normalizeDNSName converts a DNS name to a canonical form, so that we can use string equality
-// it: removes space, converts to lower case, ensures there is a trailing dot
+// it: removes space, converts to lower case, unescapes a leading wildcard, ensures there is a trailing dot
 func normalizeDNSName(dnsName string) string {
-	s := strings.TrimSpace(strings.ToLower(dnsName))
-	if !strings.HasSuffix(s, ".") {
-		s += "."
-	}
-	return s
+	return endpoint.CanonicalDNSName(dnsName) + "."
 }
 
 func IsManagedRecord(record string, managedRecords, excludeRecords []string) bool {
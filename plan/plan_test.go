@@ -1018,6 +1018,10 @@ func TestNormalizeDNSName(t *testing.T) {
 			"my-example-my-example-1214.FOO-1235.BAR-foo.COM",
 			"my-example-my-example-1214.foo-1235.bar-foo.com.",
 		},
+		{
+			`\052.foo.com`,
+			"*.foo.com.",
+		},
 	}
 	for _, r := range records {
 		gotName := normalizeDNSName(r.dnsName)
@@ -1102,3 +1106,243 @@ func TestShouldUpdateProviderSpecific(tt *testing.T) {
 		})
 	}
 }
+
+func TestShouldUpdateProviderSpecificWithComparator(t *testing.T) {
+	// proxied defaults to false, so a missing or false value is equivalent
+	comparator := func(name, desired, current string) bool {
+		if name == "proxied" {
+			return (desired == "true") == (current == "true")
+		}
+		return desired == current
+	}
+	for _, test := range []struct {
+		name         string
+		current      []endpoint.ProviderSpecificProperty
+		desired      []endpoint.ProviderSpecificProperty
+		shouldUpdate bool
+	}{
+		{
+			name:    "default populated by the provider",
+			current: []endpoint.ProviderSpecificProperty{{Name: "proxied", Value: "false"}},
+		},
+		{
+			name:    "default set by the desired endpoint",
+			desired: []endpoint.ProviderSpecificProperty{{Name: "proxied", Value: "false"}},
+		},
+		{
+			name:         "value changed",
+			current:      []endpoint.ProviderSpecificProperty{{Name: "proxied", Value: "false"}},
+			desired:      []endpoint.ProviderSpecificProperty{{Name: "proxied", Value: "true"}},
+			shouldUpdate: true,
+		},
+		{
+			name:         "non-default populated by the provider",
+			current:      []endpoint.ProviderSpecificProperty{{Name: "proxied", Value: "true"}},
+			shouldUpdate: true,
+		},
+		{
+			name:         "other property",
+			desired:      []endpoint.ProviderSpecificProperty{{Name: "custom/property", Value: "false"}},
+			shouldUpdate: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			current := &endpoint.Endpoint{DNSName: "foo.com", ProviderSpecific: test.current}
+			desired := &endpoint.Endpoint{DNSName: "foo.com", ProviderSpecific: test.desired}
+			plan := &Plan{PropertyComparator: comparator}
+			assert.Equal(t, test.shouldUpdate, plan.shouldUpdateProviderSpecific(desired, current))
+		})
+	}
+}
+
+func TestReleaseAndClaim(t *testing.T) {
+	owned := func(owner, releaseTo string) *endpoint.Endpoint {
+		ep := endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4")
+		ep.Labels[endpoint.OwnerLabelKey] = owner
+		if releaseTo != "" {
+			ep.Labels[endpoint.ReleaseToLabelKey] = releaseTo
+		}
+		return ep
+	}
+	desired := func(releaseTo string) *endpoint.Endpoint {
+		ep := endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4")
+		if releaseTo != "" {
+			ep.Labels[endpoint.ReleaseToLabelKey] = releaseTo
+		}
+		return ep
+	}
+
+	for _, tt := range []struct {
+		name            string
+		ownerID         string
+		current         *endpoint.Endpoint
+		desired         *endpoint.Endpoint
+		expectedOwner   string
+		expectedRelease string
+	}{
+		{
+			name:            "owner releases the record",
+			ownerID:         "blue",
+			current:         owned("blue", ""),
+			desired:         desired("green"),
+			expectedOwner:   "blue",
+			expectedRelease: "green",
+		},
+		{
+			name:          "new owner claims the released record",
+			ownerID:       "green",
+			current:       owned("blue", "green"),
+			desired:       desired(""),
+			expectedOwner: "green",
+		},
+		{
+			name:          "owner revokes the release",
+			ownerID:       "blue",
+			current:       owned("blue", "green"),
+			desired:       desired(""),
+			expectedOwner: "blue",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plan{
+				Policies:       []Policy{&SyncPolicy{}},
+				Current:        []*endpoint.Endpoint{tt.current},
+				Desired:        []*endpoint.Endpoint{tt.desired},
+				ManagedRecords: []string{endpoint.RecordTypeA},
+				OwnerID:        tt.ownerID,
+			}
+
+			changes := p.Calculate().Changes
+			assert.Equal(t, []*endpoint.Endpoint{tt.current}, changes.UpdateOld)
+			if assert.Len(t, changes.UpdateNew, 1) {
+				assert.Equal(t, tt.expectedOwner, changes.UpdateNew[0].Labels[endpoint.OwnerLabelKey])
+				assert.Equal(t, tt.expectedRelease, changes.UpdateNew[0].Labels[endpoint.ReleaseToLabelKey])
+			}
+		})
+	}
+}
+
+func TestCanonicalRecordsAreNotUpdated(t *testing.T) {
+	current := []*endpoint.Endpoint{
+		endpoint.NewEndpoint(`\052.Example.com.`, endpoint.RecordTypeCNAME, "LB.example.com."),
+		endpoint.NewEndpoint("mail.example.com", endpoint.RecordTypeMX, "10 MX.example.com."),
+		endpoint.NewEndpoint(`\052.\052.nested.example.com.`, endpoint.RecordTypeCNAME, "lb.example.com."),
+		endpoint.NewEndpoint(`a\046b\100c.example.com.`, endpoint.RecordTypeCNAME, "lb.example.com."),
+	}
+	desired := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("*.example.com", endpoint.RecordTypeCNAME, "lb.example.com"),
+		endpoint.NewEndpoint("mail.example.com", endpoint.RecordTypeMX, "10 mx.example.com"),
+		endpoint.NewEndpoint("*.*.nested.example.com", endpoint.RecordTypeCNAME, "lb.example.com"),
+		endpoint.NewEndpoint("a&b@c.example.com", endpoint.RecordTypeCNAME, "lb.example.com"),
+	}
+	for _, ep := range current {
+		// providers return targets with trailing dot, which NewEndpoint strips
+		ep.Targets[0] += "."
+	}
+
+	p := &Plan{
+		Policies:       []Policy{&SyncPolicy{}},
+		Current:        current,
+		Desired:        desired,
+		ManagedRecords: []string{endpoint.RecordTypeCNAME, endpoint.RecordTypeMX},
+	}
+
+	changes := p.Calculate().Changes
+	assert.False(t, changes.HasChanges(), "expected no changes, got %v", changes)
+}
+
+func TestRecordNotReleasedIsNotClaimed(t *testing.T) {
+	current := endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4")
+	current.Labels[endpoint.OwnerLabelKey] = "blue"
+	current.Labels[endpoint.ReleaseToLabelKey] = "red"
+
+	p := &Plan{
+		Policies:       []Policy{&SyncPolicy{}},
+		Current:        []*endpoint.Endpoint{current},
+		Desired:        []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4")},
+		ManagedRecords: []string{endpoint.RecordTypeA},
+		OwnerID:        "green",
+	}
+
+	changes := p.Calculate().Changes
+	assert.Empty(t, changes.UpdateOld)
+	assert.Empty(t, changes.UpdateNew)
+}
+
+func TestAdopt(t *testing.T) {
+	unowned := endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4")
+	owned := endpoint.NewEndpoint("bar.example.com", endpoint.RecordTypeA, "1.2.3.4")
+	owned.Labels[endpoint.OwnerLabelKey] = "blue"
+	adopting := func(dnsName string) *endpoint.Endpoint {
+		ep := endpoint.NewEndpoint(dnsName, endpoint.RecordTypeA, "1.2.3.4")
+		ep.Labels[endpoint.AdoptLabelKey] = "true"
+		return ep
+	}
+
+	p := &Plan{
+		Policies:       []Policy{&SyncPolicy{}},
+		Current:        []*endpoint.Endpoint{unowned, owned},
+		Desired:        []*endpoint.Endpoint{adopting("foo.example.com"), adopting("bar.example.com"), adopting("new.example.com")},
+		ManagedRecords: []string{endpoint.RecordTypeA},
+		OwnerID:        "green",
+	}
+
+	changes := p.Calculate().Changes
+	// the record owned by another owner is not adopted
+	assert.Len(t, changes.UpdateOld, 1)
+	if !assert.Len(t, changes.UpdateNew, 1) {
+		return
+	}
+	assert.Equal(t, "foo.example.com", changes.UpdateOld[0].DNSName)
+	assert.True(t, changes.UpdateOld[0].IsAdoptedBy("green"))
+	assert.NotContains(t, unowned.Labels, endpoint.AdoptLabelKey)
+	assert.Equal(t, endpoint.Labels{endpoint.OwnerLabelKey: "green"}, changes.UpdateNew[0].Labels)
+
+	if !assert.Len(t, changes.Create, 1) {
+		return
+	}
+	assert.NotContains(t, changes.Create[0].Labels, endpoint.AdoptLabelKey)
+}
+
+func TestUnownedRecordIsNotAdoptedWithoutRequest(t *testing.T) {
+	p := &Plan{
+		Policies:       []Policy{&SyncPolicy{}},
+		Current:        []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4")},
+		Desired:        []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4")},
+		ManagedRecords: []string{endpoint.RecordTypeA},
+		OwnerID:        "green",
+	}
+
+	changes := p.Calculate().Changes
+	assert.False(t, changes.HasChanges())
+}
+
+func TestSeparateTTLRepairs(t *testing.T) {
+	current := []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("ttl.example.com", endpoint.RecordTypeA, 300, "1.2.3.4"),
+		endpoint.NewEndpointWithTTL("target.example.com", endpoint.RecordTypeA, 300, "1.2.3.4"),
+	}
+	desired := []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("ttl.example.com", endpoint.RecordTypeA, 60, "1.2.3.4"),
+		endpoint.NewEndpointWithTTL("target.example.com", endpoint.RecordTypeA, 60, "5.6.7.8"),
+	}
+	p := &Plan{
+		Policies:           []Policy{&SyncPolicy{}},
+		Current:            current,
+		Desired:            desired,
+		ManagedRecords:     []string{endpoint.RecordTypeA},
+		SeparateTTLRepairs: true,
+	}
+
+	calculated := p.Calculate()
+	// the TTL of the record updated for another reason is updated with it
+	validateEntries(t, calculated.Changes.UpdateOld, []*endpoint.Endpoint{current[1]})
+	validateEntries(t, calculated.Changes.UpdateNew, []*endpoint.Endpoint{desired[1]})
+	validateEntries(t, calculated.TTLRepairs.UpdateOld, []*endpoint.Endpoint{current[0]})
+	validateEntries(t, calculated.TTLRepairs.UpdateNew, []*endpoint.Endpoint{desired[0]})
+
+	p.SeparateTTLRepairs = false
+	calculated = p.Calculate()
+	assert.Len(t, calculated.Changes.UpdateNew, 2)
+	assert.Nil(t, calculated.TTLRepairs)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"slices"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// RRSetKey identifies a resource record set, i.e. the records sharing a name, a type and a set identifier.
+// The name is in canonical form.
+type RRSetKey struct {
+	DNSName       string
+	RecordType    string
+	SetIdentifier string
+}
+
+// RRSetChange is the change of a resource record set. Old is nil if the record set is created,
+// New is nil if it is deleted, and both are set if it is updated.
+type RRSetChange struct {
+	Key RRSetKey
+	Old *endpoint.Endpoint
+	New *endpoint.Endpoint
+}
+
+// IsCreate returns true if the record set is created.
+func (c *RRSetChange) IsCreate() bool {
+	return c.Old == nil
+}
+
+// IsDelete returns true if the record set is deleted.
+func (c *RRSetChange) IsDelete() bool {
+	return c.New == nil
+}
+
+// IsUpdate returns true if the record set is replaced.
+func (c *RRSetChange) IsUpdate() bool {
+	return c.Old != nil && c.New != nil
+}
+
+// OldTargets returns the targets of the record set before the change.
+func (c *RRSetChange) OldTargets() endpoint.Targets {
+	if c.Old == nil {
+		return nil
+	}
+	return c.Old.Targets
+}
+
+// NewTargets returns the targets of the record set after the change.
+func (c *RRSetChange) NewTargets() endpoint.Targets {
+	if c.New == nil {
+		return nil
+	}
+	return c.New.Targets
+}
+
+// RRSets returns the changes grouped by resource record set, so that providers with record set semantics
+// can apply each of them as a single operation. The current and desired endpoints of a record set,
+// from UpdateOld and UpdateNew or from Delete and Create, are combined into an update,
+// and endpoints of the same record set within a list are merged into one with all their targets.
+// The changes are returned in the order their record sets first appear in Delete, UpdateOld, UpdateNew and Create.
+func (c *Changes) RRSets() []*RRSetChange {
+	var result []*RRSetChange
+	byKey := map[RRSetKey]*RRSetChange{}
+	add := func(endpoints []*endpoint.Endpoint, old bool) {
+		for _, ep := range endpoints {
+			key := RRSetKey{
+				DNSName:       endpoint.CanonicalDNSName(ep.DNSName),
+				RecordType:    ep.RecordType,
+				SetIdentifier: ep.SetIdentifier,
+			}
+			change, ok := byKey[key]
+			if !ok {
+				change = &RRSetChange{Key: key}
+				byKey[key] = change
+				result = append(result, change)
+			}
+			if old {
+				change.Old = mergeRRSetEndpoint(change.Old, ep)
+			} else {
+				change.New = mergeRRSetEndpoint(change.New, ep)
+			}
+		}
+	}
+	add(c.Delete, true)
+	add(c.UpdateOld, true)
+	add(c.UpdateNew, false)
+	add(c.Create, false)
+	return result
+}
+
+// mergeRRSetEndpoint returns the endpoint of a record set with the targets of ep added.
+// The endpoints of the changes are not modified.
+func mergeRRSetEndpoint(merged, ep *endpoint.Endpoint) *endpoint.Endpoint {
+	if merged == nil {
+		return ep
+	}
+	targets := append(endpoint.Targets{}, merged.Targets...)
+	for _, target := range ep.Targets {
+		if !slices.Contains(targets, target) {
+			targets = append(targets, target)
+		}
+	}
+	copied := *merged
+	copied.Targets = targets
+	return &copied
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestChangesRRSets(t *testing.T) {
+	deleted := endpoint.NewEndpoint("deleted.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	updateOld := endpoint.NewEndpoint("Updated.example.org.", endpoint.RecordTypeA, "1.1.1.1", "2.2.2.2")
+	updateNew := endpoint.NewEndpoint("updated.example.org", endpoint.RecordTypeA, "2.2.2.2", "3.3.3.3")
+	weightedOld := endpoint.NewEndpoint("updated.example.org", endpoint.RecordTypeA, "4.4.4.4").WithSetIdentifier("blue")
+	weightedNew := endpoint.NewEndpoint("updated.example.org", endpoint.RecordTypeA, "5.5.5.5").WithSetIdentifier("blue")
+	typeChanged := endpoint.NewEndpoint("updated.example.org", endpoint.RecordTypeAAAA, "::1")
+	replacedOld := endpoint.NewEndpoint("replaced.example.org", endpoint.RecordTypeCNAME, "old.example.org")
+	replacedNew := endpoint.NewEndpoint("replaced.example.org", endpoint.RecordTypeCNAME, "new.example.org")
+	createdFirst := endpoint.NewEndpoint("created.example.org", endpoint.RecordTypeA, "6.6.6.6")
+	createdSecond := endpoint.NewEndpoint("created.example.org", endpoint.RecordTypeA, "6.6.6.6", "7.7.7.7")
+
+	changes := &Changes{
+		Delete:    []*endpoint.Endpoint{deleted, replacedOld},
+		UpdateOld: []*endpoint.Endpoint{updateOld, weightedOld},
+		UpdateNew: []*endpoint.Endpoint{updateNew, weightedNew, typeChanged},
+		Create:    []*endpoint.Endpoint{replacedNew, createdFirst, createdSecond},
+	}
+	rrsets := changes.RRSets()
+	require.Len(t, rrsets, 6)
+
+	assert.Equal(t, RRSetKey{DNSName: "deleted.example.org", RecordType: endpoint.RecordTypeA}, rrsets[0].Key)
+	assert.True(t, rrsets[0].IsDelete())
+	assert.Equal(t, endpoint.Targets{"1.1.1.1"}, rrsets[0].OldTargets())
+	assert.Nil(t, rrsets[0].NewTargets())
+
+	// a deletion and a creation of the same record set are combined into an update
+	assert.Equal(t, RRSetKey{DNSName: "replaced.example.org", RecordType: endpoint.RecordTypeCNAME}, rrsets[1].Key)
+	assert.True(t, rrsets[1].IsUpdate())
+	assert.Same(t, replacedOld, rrsets[1].Old)
+	assert.Same(t, replacedNew, rrsets[1].New)
+
+	assert.Equal(t, RRSetKey{DNSName: "updated.example.org", RecordType: endpoint.RecordTypeA}, rrsets[2].Key)
+	assert.True(t, rrsets[2].IsUpdate())
+	assert.Equal(t, endpoint.Targets{"1.1.1.1", "2.2.2.2"}, rrsets[2].OldTargets())
+	assert.Equal(t, endpoint.Targets{"2.2.2.2", "3.3.3.3"}, rrsets[2].NewTargets())
+
+	assert.Equal(t, RRSetKey{DNSName: "updated.example.org", RecordType: endpoint.RecordTypeA, SetIdentifier: "blue"}, rrsets[3].Key)
+	assert.True(t, rrsets[3].IsUpdate())
+
+	assert.Equal(t, RRSetKey{DNSName: "updated.example.org", RecordType: endpoint.RecordTypeAAAA}, rrsets[4].Key)
+	assert.True(t, rrsets[4].IsCreate())
+	assert.Nil(t, rrsets[4].OldTargets())
+
+	// the endpoints of the same record set are merged
+	assert.Equal(t, RRSetKey{DNSName: "created.example.org", RecordType: endpoint.RecordTypeA}, rrsets[5].Key)
+	assert.True(t, rrsets[5].IsCreate())
+	assert.Equal(t, endpoint.Targets{"6.6.6.6", "7.7.7.7"}, rrsets[5].NewTargets())
+	assert.Equal(t, endpoint.Targets{"6.6.6.6"}, createdFirst.Targets)
+}
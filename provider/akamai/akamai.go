@@ -19,10 +19,12 @@ package akamai
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
 
+	client "github.com/akamai/AkamaiOPEN-edgegrid-golang/client-v1"
 	dns "github.com/akamai/AkamaiOPEN-edgegrid-golang/configdns-v2"
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/edgegrid"
 	log "github.com/sirupsen/logrus"
@@ -141,6 +143,9 @@ func NewAkamaiProvider(akamaiConfig AkamaiConfig, akaService AkamaiDNSService) (
 		}
 	}
 
+	// the edgegrid package sends the requests with its package-level client
+	client.Client = &http.Client{Transport: provider.NewUserAgentTransport(nil)}
+
 	provider := &AkamaiProvider{
 		domainFilter: akamaiConfig.DomainFilter,
 		zoneIDFilter: akamaiConfig.ZoneIDFilter,
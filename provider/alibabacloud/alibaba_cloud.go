@@ -117,7 +117,7 @@ func NewAlibabaCloudProvider(configFile string, domainFilter endpoint.DomainFilt
 	}
 
 	// Public DNS service
-	var dnsClient AlibabaCloudDNSAPI
+	var dnsClient *alidns.Client
 	var err error
 
 	if cfg.RoleName == "" {
@@ -138,9 +138,10 @@ func NewAlibabaCloudProvider(configFile string, domainFilter endpoint.DomainFilt
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Alibaba Cloud DNS client: %v", err)
 	}
+	dnsClient.SetTransport(provider.NewUserAgentTransport(nil))
 
 	// Private DNS service
-	var pvtzClient AlibabaCloudPrivateZoneAPI
+	var pvtzClient *pvtz.Client
 	if cfg.RoleName == "" {
 		pvtzClient, err = pvtz.NewClientWithAccessKey(
 			"cn-hangzhou", // The Private Zone location is fixed
@@ -159,6 +160,7 @@ func NewAlibabaCloudProvider(configFile string, domainFilter endpoint.DomainFilt
 	if err != nil {
 		return nil, err
 	}
+	pvtzClient.SetTransport(provider.NewUserAgentTransport(nil))
 
 	provider := &AlibabaCloudProvider{
 		domainFilter: domainFilter,
@@ -262,6 +264,7 @@ func (p *AlibabaCloudProvider) refreshStsToken(sleepTime time.Duration) {
 			log.Errorf("Failed to new client with sts token %v", err)
 			continue
 		}
+		dnsClient.SetTransport(provider.NewUserAgentTransport(nil))
 		pvtzClient, err := pvtz.NewClientWithStsToken(
 			cfg.RegionID,
 			cfg.AccessKeyID,
@@ -272,6 +275,7 @@ func (p *AlibabaCloudProvider) refreshStsToken(sleepTime time.Duration) {
 			log.Errorf("Failed to new client with sts token %v", err)
 			continue
 		}
+		pvtzClient.SetTransport(provider.NewUserAgentTransport(nil))
 		log.Infof("Refresh client from sts token, next expire time %v", cfg.ExpireTime)
 		p.clientLock.Lock()
 		p.dnsClient = dnsClient
@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// apiCalls is the number of requests sent to the DNS provider API by clients using an APICallCounter.
+var apiCalls atomic.Int64
+
+// APICalls returns the number of requests sent to the DNS provider API since the start.
+// It is used to keep the requests of a synchronization within --provider-api-budget-per-cycle.
+func APICalls() int64 {
+	return apiCalls.Load()
+}
+
+// APICallCounter is a http.RoundTripper counting the requests sent to the DNS provider API.
+type APICallCounter struct {
+	http.RoundTripper
+}
+
+// NewAPICallCounter wraps the transport of a provider's API client.
+// If transport is nil, http.DefaultTransport is used.
+func NewAPICallCounter(transport http.RoundTripper) *APICallCounter {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &APICallCounter{RoundTripper: transport}
+}
+
+func (c *APICallCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	apiCalls.Add(1)
+	return c.RoundTripper.RoundTrip(req)
+}
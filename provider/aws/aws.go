@@ -20,7 +20,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"regexp"
+	"maps"
+	"net/netip"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -29,6 +31,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
 	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/aws/smithy-go"
 	log "github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/external-dns/endpoint"
@@ -61,7 +64,18 @@ const (
 	providerSpecificGeolocationSubdivisionCode = "aws/geolocation-subdivision-code"
 	providerSpecificMultiValueAnswer           = "aws/multi-value-answer"
 	providerSpecificHealthCheckID              = "aws/health-check-id"
-	sameZoneAlias                              = "same-zone"
+	// providerSpecificFailoverSecondaryTargets makes an endpoint the PRIMARY record of a failover pair,
+	// with a SECONDARY record to these comma-separated targets.
+	providerSpecificFailoverSecondaryTargets = "aws/failover-secondary-targets"
+	// providerSpecificFailoverSecondaryHealthCheckID is the health check of the SECONDARY record of a failover pair.
+	providerSpecificFailoverSecondaryHealthCheckID = "aws/failover-secondary-health-check-id"
+	// providerSpecificFailoverSecondarySetIdentifier is the set identifier of the SECONDARY record of a failover pair.
+	providerSpecificFailoverSecondarySetIdentifier = "aws/failover-secondary-set-identifier"
+	sameZoneAlias                                  = "same-zone"
+
+	// providerSpecificDualstack specifies whether an AWS ALIAS record has a AAAA ALIAS record alongside its A one.
+	// Present iff the value is `true`, it is also set by the dualstack label of the sources.
+	providerSpecificDualstack = "aws/dualstack"
 )
 
 // see: https://docs.aws.amazon.com/general/latest/gr/elb.html
@@ -202,8 +216,24 @@ type Route53API interface {
 	ListResourceRecordSets(ctx context.Context, input *route53.ListResourceRecordSetsInput, optFns ...func(options *route53.Options)) (*route53.ListResourceRecordSetsOutput, error)
 	ChangeResourceRecordSets(ctx context.Context, input *route53.ChangeResourceRecordSetsInput, optFns ...func(options *route53.Options)) (*route53.ChangeResourceRecordSetsOutput, error)
 	CreateHostedZone(ctx context.Context, input *route53.CreateHostedZoneInput, optFns ...func(*route53.Options)) (*route53.CreateHostedZoneOutput, error)
+	DeleteHostedZone(ctx context.Context, input *route53.DeleteHostedZoneInput, optFns ...func(*route53.Options)) (*route53.DeleteHostedZoneOutput, error)
+	ListHealthChecks(ctx context.Context, input *route53.ListHealthChecksInput, optFns ...func(*route53.Options)) (*route53.ListHealthChecksOutput, error)
+	CreateHealthCheck(ctx context.Context, input *route53.CreateHealthCheckInput, optFns ...func(*route53.Options)) (*route53.CreateHealthCheckOutput, error)
+	UpdateHealthCheck(ctx context.Context, input *route53.UpdateHealthCheckInput, optFns ...func(*route53.Options)) (*route53.UpdateHealthCheckOutput, error)
+	DeleteHealthCheck(ctx context.Context, input *route53.DeleteHealthCheckInput, optFns ...func(*route53.Options)) (*route53.DeleteHealthCheckOutput, error)
+	ListTrafficPolicyInstancesByHostedZone(ctx context.Context, input *route53.ListTrafficPolicyInstancesByHostedZoneInput, optFns ...func(*route53.Options)) (*route53.ListTrafficPolicyInstancesByHostedZoneOutput, error)
+	GetTrafficPolicy(ctx context.Context, input *route53.GetTrafficPolicyInput, optFns ...func(*route53.Options)) (*route53.GetTrafficPolicyOutput, error)
+	ListTrafficPolicies(ctx context.Context, input *route53.ListTrafficPoliciesInput, optFns ...func(*route53.Options)) (*route53.ListTrafficPoliciesOutput, error)
+	CreateTrafficPolicy(ctx context.Context, input *route53.CreateTrafficPolicyInput, optFns ...func(*route53.Options)) (*route53.CreateTrafficPolicyOutput, error)
+	CreateTrafficPolicyVersion(ctx context.Context, input *route53.CreateTrafficPolicyVersionInput, optFns ...func(*route53.Options)) (*route53.CreateTrafficPolicyVersionOutput, error)
+	DeleteTrafficPolicy(ctx context.Context, input *route53.DeleteTrafficPolicyInput, optFns ...func(*route53.Options)) (*route53.DeleteTrafficPolicyOutput, error)
+	CreateTrafficPolicyInstance(ctx context.Context, input *route53.CreateTrafficPolicyInstanceInput, optFns ...func(*route53.Options)) (*route53.CreateTrafficPolicyInstanceOutput, error)
+	UpdateTrafficPolicyInstance(ctx context.Context, input *route53.UpdateTrafficPolicyInstanceInput, optFns ...func(*route53.Options)) (*route53.UpdateTrafficPolicyInstanceOutput, error)
+	DeleteTrafficPolicyInstance(ctx context.Context, input *route53.DeleteTrafficPolicyInstanceInput, optFns ...func(*route53.Options)) (*route53.DeleteTrafficPolicyInstanceOutput, error)
 	ListHostedZones(ctx context.Context, input *route53.ListHostedZonesInput, optFns ...func(options *route53.Options)) (*route53.ListHostedZonesOutput, error)
+	ListHostedZonesByVPC(ctx context.Context, input *route53.ListHostedZonesByVPCInput, optFns ...func(options *route53.Options)) (*route53.ListHostedZonesByVPCOutput, error)
 	ListTagsForResource(ctx context.Context, input *route53.ListTagsForResourceInput, optFns ...func(options *route53.Options)) (*route53.ListTagsForResourceOutput, error)
+	ChangeTagsForResource(ctx context.Context, input *route53.ChangeTagsForResourceInput, optFns ...func(options *route53.Options)) (*route53.ChangeTagsForResourceOutput, error)
 }
 
 // wrapper to handle ownership relation throughout the provider implementation
@@ -235,6 +265,17 @@ type zonesListCache struct {
 	zones    map[string]*profiledZone
 }
 
+// invalidate drops the cached zones list, so that it is refreshed the next time the zones are listed.
+func (c *zonesListCache) invalidate() {
+	c.zones = nil
+}
+
+// isNoSuchHostedZone returns true if the request failed because the hosted zone doesn't exist anymore.
+func isNoSuchHostedZone(err error) bool {
+	var noSuchHostedZone *route53types.NoSuchHostedZone
+	return errors.As(err, &noSuchHostedZone)
+}
+
 // AWSProvider is an implementation of Provider for AWS Route53.
 type AWSProvider struct {
 	provider.BaseProvider
@@ -259,6 +300,18 @@ type AWSProvider struct {
 	zonesCache      *zonesListCache
 	// queue for collecting changes to submit them in the next iteration, but after all other changes
 	failedChangesQueue map[string]Route53Changes
+	// list only the record sets under the domain filters that are subdomains of a zone
+	boundedListing bool
+	// the IAM roles managing hosted zones by zone ID, the roles being the keys of their clients
+	zoneRoles map[string]string
+	// create, update and delete the health checks described by the properties of the records
+	manageHealthChecks bool
+	healthChecks       healthChecks
+	// create, update and delete the traffic policies and policy instances of the records with a traffic policy document
+	manageTrafficPolicies bool
+	trafficPolicies       trafficPolicies
+	// only consider the private hosted zones associated with one of these VPCs
+	zoneVPCs []ZoneVPC
 }
 
 // AWSConfig contains configuration to create a new AWS provider.
@@ -276,6 +329,17 @@ type AWSConfig struct {
 	PreferCNAME           bool
 	DryRun                bool
 	ZoneCacheDuration     time.Duration
+	BoundedListing        bool
+	// ZoneRoles are the IAM roles managing hosted zones by zone ID. The clients of the roles are keyed by
+	// their ARN, and manage the zones mapped to them only, while the other clients skip these zones.
+	ZoneRoles map[string]string
+	// ManageHealthChecks creates, updates and deletes the health checks described by the health check properties of the records.
+	ManageHealthChecks bool
+	// ManageTrafficPolicies creates, updates and deletes the traffic policies and policy instances of the records with a traffic policy document.
+	ManageTrafficPolicies bool
+	// ZoneVPCs restricts the private hosted zones to those associated with one of the VPCs, including the zones
+	// of other accounts associated through Route53 Profiles or cross-account VPC associations.
+	ZoneVPCs []ZoneVPC
 }
 
 // NewAWSProvider initializes a new AWS Route53 based Provider.
@@ -295,7 +359,12 @@ func NewAWSProvider(awsConfig AWSConfig, clients map[string]Route53API) (*AWSPro
 		preferCNAME:           awsConfig.PreferCNAME,
 		dryRun:                awsConfig.DryRun,
 		zonesCache:            &zonesListCache{duration: awsConfig.ZoneCacheDuration},
+		boundedListing:        awsConfig.BoundedListing,
+		zoneRoles:             awsConfig.ZoneRoles,
+		manageHealthChecks:    awsConfig.ManageHealthChecks,
+		manageTrafficPolicies: awsConfig.ManageTrafficPolicies,
 		failedChangesQueue:    make(map[string]Route53Changes),
+		zoneVPCs:              awsConfig.ZoneVPCs,
 	}
 
 	return provider, nil
@@ -325,6 +394,14 @@ func (p *AWSProvider) zones(ctx context.Context) (map[string]*profiledZone, erro
 
 	zones := make(map[string]*profiledZone)
 
+	var associated map[string]route53types.HostedZoneSummary
+	if len(p.zoneVPCs) > 0 {
+		var err error
+		if associated, err = p.vpcAssociatedZones(ctx); err != nil {
+			return nil, err
+		}
+	}
+
 	for profile, client := range p.clients {
 		var tagErr error
 		paginator := route53.NewListHostedZonesPaginator(client, &route53.ListHostedZonesInput{})
@@ -345,10 +422,20 @@ func (p *AWSProvider) zones(ctx context.Context) (map[string]*profiledZone, erro
 					continue
 				}
 
+				if !p.managesZone(profile, *zone.Id) {
+					continue
+				}
+
 				if !p.zoneTypeFilter.Match(zone) {
 					continue
 				}
 
+				if associated != nil && zone.Config != nil && zone.Config.PrivateZone {
+					if _, ok := associated[cleanZoneID(*zone.Id)]; !ok {
+						continue
+					}
+				}
+
 				if !p.domainFilter.Match(*zone.Name) {
 					if !p.zoneMatchParent {
 						continue
@@ -381,8 +468,15 @@ func (p *AWSProvider) zones(ctx context.Context) (map[string]*profiledZone, erro
 		}
 	}
 
+	considered := make(map[string]bool, len(zones))
 	for _, zone := range zones {
 		log.Debugf("Considering zone: %s (domain: %s)", *zone.zone.Id, *zone.zone.Name)
+		considered[cleanZoneID(*zone.zone.Id)] = true
+	}
+	for id, summary := range associated {
+		if !considered[id] && summary.Owner != nil && summary.Owner.OwningAccount != nil {
+			log.Debugf("Not considering zone %s (domain: %s) associated with the VPCs and owned by account %s: filtered out, or not listed by any profile", id, aws.ToString(summary.Name), *summary.Owner.OwningAccount)
+		}
 	}
 
 	if p.zonesCache.duration > time.Duration(0) {
@@ -393,32 +487,59 @@ func (p *AWSProvider) zones(ctx context.Context) (map[string]*profiledZone, erro
 	return zones, nil
 }
 
-// wildcardUnescape converts \\052.abc back to *.abc
-// Route53 stores wildcards escaped: http://docs.aws.amazon.com/Route53/latest/DeveloperGuide/DomainNameFormat.html?shortFooter=true#domain-name-format-asterisk
-func wildcardUnescape(s string) string {
-	return strings.Replace(s, "\\052", "*", 1)
+// vpcAssociatedZones returns the hosted zones associated with the VPCs by clean zone ID. The associations are
+// listed with every client, since the VPCs and the zones may belong to different accounts, and a VPC none of the
+// clients can list the associations of is an error.
+func (p *AWSProvider) vpcAssociatedZones(ctx context.Context) (map[string]route53types.HostedZoneSummary, error) {
+	zones := make(map[string]route53types.HostedZoneSummary)
+	for _, vpc := range p.zoneVPCs {
+		var lastErr error
+		listed := false
+		for profile, client := range p.clients {
+			input := &route53.ListHostedZonesByVPCInput{VPCId: aws.String(vpc.ID), VPCRegion: route53types.VPCRegion(vpcRegion(vpc, client))}
+			for {
+				resp, err := client.ListHostedZonesByVPC(ctx, input)
+				if err != nil {
+					log.Debugf("Failed to list the hosted zones associated with VPC %s with AWS profile %q: %v", vpc.ID, profile, err)
+					lastErr = err
+					break
+				}
+				for _, summary := range resp.HostedZoneSummaries {
+					zones[cleanZoneID(aws.ToString(summary.HostedZoneId))] = summary
+				}
+				if resp.NextToken == nil {
+					listed = true
+					break
+				}
+				input.NextToken = resp.NextToken
+			}
+		}
+		if !listed {
+			return nil, provider.NewSoftError(fmt.Errorf("failed to list the hosted zones associated with VPC %s: %w", vpc.ID, lastErr))
+		}
+	}
+	return zones, nil
 }
 
-// See https://docs.aws.amazon.com/Route53/latest/DeveloperGuide/DomainNameFormat.html
-// convertOctalToAscii decodes inputs that contain octal escape sequences into their original ASCII characters.
-// The function returns converted string where any octal escape sequences have been replaced with their corresponding ASCII characters.
-func convertOctalToAscii(input string) string {
-	if !containsOctalSequence(input) {
-		return input
+// managesZone returns whether the client of the profile manages the zone: the zone is mapped to the role of
+// the client, or neither is mapped.
+func (p *AWSProvider) managesZone(profile, zoneID string) bool {
+	if role, ok := p.zoneRoles[cleanZoneID(zoneID)]; ok {
+		return role == profile
 	}
-	result, err := strconv.Unquote("\"" + input + "\"")
-	if err != nil {
-		return input
+	for _, role := range p.zoneRoles {
+		if role == profile {
+			return false
+		}
 	}
-	return result
+	return true
 }
 
-// validateDomainName checks if the domain name contains valid octal escape sequences.
-func containsOctalSequence(domain string) bool {
-	// Pattern to match valid octal escape sequences
-	octalEscapePattern := `\\[0-3][0-7]{2}`
-	octalEscapeRegex := regexp.MustCompile(octalEscapePattern)
-	return octalEscapeRegex.MatchString(domain)
+// See https://docs.aws.amazon.com/Route53/latest/DeveloperGuide/DomainNameFormat.html
+// convertOctalToAscii decodes the octal escape codes with which Route53 returns the wildcards and the
+// special characters of the record names.
+func convertOctalToAscii(input string) string {
+	return endpoint.UnescapeDNSName(input)
 }
 
 // Records returns the list of records in a given hosted zone.
@@ -428,7 +549,56 @@ func (p *AWSProvider) Records(ctx context.Context) (endpoints []*endpoint.Endpoi
 		return nil, provider.NewSoftError(fmt.Errorf("records retrieval failed: %w", err))
 	}
 
-	return p.records(ctx, zones)
+	if scope, ok := provider.ZoneScope(ctx); ok {
+		scoped := make(map[string]*profiledZone)
+		for id, zone := range zones {
+			if strings.TrimSuffix(*zone.zone.Name, ".") == scope {
+				scoped[id] = zone
+			}
+		}
+		zones = scoped
+	}
+
+	if p.manageHealthChecks {
+		if err := p.listHealthChecks(ctx, zones); err != nil {
+			return nil, provider.NewSoftError(fmt.Errorf("records retrieval failed: %w", err))
+		}
+	}
+
+	if !p.manageTrafficPolicies {
+		return p.records(ctx, zones)
+	}
+	// the policy instances are listed first, so that the record sets they created are skipped
+	policyEndpoints, err := p.listTrafficPolicyInstances(ctx, zones)
+	if err != nil {
+		return nil, provider.NewSoftError(fmt.Errorf("records retrieval failed: %w", err))
+	}
+	endpoints, err = p.records(ctx, zones)
+	if err != nil {
+		return nil, err
+	}
+	return append(endpoints, policyEndpoints...), nil
+}
+
+// ZoneNames returns the names of the hosted zones, see provider.ZoneLister.
+// Public and private hosted zones of the same name are listed together.
+func (p *AWSProvider) ZoneNames(ctx context.Context) ([]string, error) {
+	zones, err := p.zones(ctx)
+	if err != nil {
+		return nil, provider.NewSoftError(fmt.Errorf("zones retrieval failed: %w", err))
+	}
+
+	seen := map[string]bool{}
+	names := make([]string, 0, len(zones))
+	for _, zone := range zones {
+		name := strings.TrimSuffix(*zone.zone.Name, ".")
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
 }
 
 func (p *AWSProvider) records(ctx context.Context, zones map[string]*profiledZone) ([]*endpoint.Endpoint, error) {
@@ -436,96 +606,193 @@ func (p *AWSProvider) records(ctx context.Context, zones map[string]*profiledZon
 
 	for _, z := range zones {
 		client := p.clients[z.profile]
+		var zoneEndpoints []*endpoint.Endpoint
 
-		paginator := route53.NewListResourceRecordSetsPaginator(client, &route53.ListResourceRecordSetsInput{
-			HostedZoneId: z.zone.Id,
-			MaxItems:     aws.Int32(route53PageSize),
-		})
+		for _, root := range p.listingRoots(*z.zone.Name) {
+			input := &route53.ListResourceRecordSetsInput{
+				HostedZoneId: z.zone.Id,
+				MaxItems:     aws.Int32(route53PageSize),
+			}
+			if root != "" {
+				input.StartRecordName = aws.String(root)
+			}
+			paginator := route53.NewListResourceRecordSetsPaginator(client, input)
+
+		pages:
+			for paginator.HasMorePages() {
+				resp, err := paginator.NextPage(ctx)
+				if err != nil {
+					if isNoSuchHostedZone(err) {
+						log.Warnf("Hosted zone %s was deleted, refreshing the zones list", *z.zone.Id)
+						p.zonesCache.invalidate()
+					}
+					return nil, fmt.Errorf("failed to list resource records sets for zone %s using aws profile %q: %w", *z.zone.Id, z.profile, err)
+				}
 
-		for paginator.HasMorePages() {
-			resp, err := paginator.NextPage(ctx)
-			if err != nil {
-				return nil, fmt.Errorf("failed to list resource records sets for zone %s using aws profile %q: %w", *z.zone.Id, z.profile, err)
+				for _, r := range resp.ResourceRecordSets {
+					// the record sets are sorted by their name with the labels reversed, so the names under the root are listed together
+					if root != "" && !isUnderRoot(*r.Name, root) {
+						break pages
+					}
+					if r.TrafficPolicyInstanceId != nil && p.manageTrafficPolicies && p.trafficPolicies.managesInstance(*r.TrafficPolicyInstanceId) {
+						continue
+					}
+					zoneEndpoints = append(zoneEndpoints, p.recordSetEndpoints(r)...)
+				}
 			}
+		}
+		endpoints = append(endpoints, mergeDualstackAliases(zoneEndpoints)...)
+	}
 
-			for _, r := range resp.ResourceRecordSets {
-				newEndpoints := make([]*endpoint.Endpoint, 0)
+	return endpoints, nil
+}
 
-				if !p.SupportedRecordType(r.Type) {
-					continue
-				}
+// mergeDualstackAliases merges the AAAA ALIAS records into the A ALIAS records of the same name and set
+// identifier, which are then dualstack. A AAAA ALIAS record without A one is reported as a dualstack A record.
+func mergeDualstackAliases(endpoints []*endpoint.Endpoint) []*endpoint.Endpoint {
+	aliases := map[endpoint.EndpointKey]*endpoint.Endpoint{}
+	for _, ep := range endpoints {
+		if alias, _ := ep.GetProviderSpecificProperty(providerSpecificAlias); alias == "true" && ep.RecordType == endpoint.RecordTypeA {
+			aliases[ep.Key()] = ep
+		}
+	}
+	merged := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if alias, _ := ep.GetProviderSpecificProperty(providerSpecificAlias); alias == "true" && ep.RecordType == endpoint.RecordTypeAAAA {
+			ep.RecordType = endpoint.RecordTypeA
+			if a, ok := aliases[ep.Key()]; ok {
+				a.SetProviderSpecificProperty(providerSpecificDualstack, "true")
+				continue
+			}
+			ep.SetProviderSpecificProperty(providerSpecificDualstack, "true")
+		}
+		merged = append(merged, ep)
+	}
+	return merged
+}
 
-				name := convertOctalToAscii(wildcardUnescape(*r.Name))
+// recordSetEndpoints converts a resource record set to endpoints.
+func (p *AWSProvider) recordSetEndpoints(r route53types.ResourceRecordSet) []*endpoint.Endpoint {
+	newEndpoints := make([]*endpoint.Endpoint, 0)
 
-				var ttl endpoint.TTL
-				if r.TTL != nil {
-					ttl = endpoint.TTL(*r.TTL)
-				}
+	if !p.SupportedRecordType(r.Type) {
+		return nil
+	}
 
-				if len(r.ResourceRecords) > 0 {
-					targets := make([]string, len(r.ResourceRecords))
-					for idx, rr := range r.ResourceRecords {
-						targets[idx] = *rr.Value
-					}
+	name := convertOctalToAscii(*r.Name)
 
-					ep := endpoint.NewEndpointWithTTL(name, string(r.Type), ttl, targets...)
-					if r.Type == endpoint.RecordTypeCNAME {
-						ep = ep.WithProviderSpecific(providerSpecificAlias, "false")
-					}
-					newEndpoints = append(newEndpoints, ep)
-				}
+	var ttl endpoint.TTL
+	if r.TTL != nil {
+		ttl = endpoint.TTL(*r.TTL)
+	}
 
-				if r.AliasTarget != nil {
-					// Alias records don't have TTLs so provide the default to match the TXT generation
-					if ttl == 0 {
-						ttl = recordTTL
-					}
-					ep := endpoint.
-						NewEndpointWithTTL(name, endpoint.RecordTypeA, ttl, *r.AliasTarget.DNSName).
-						WithProviderSpecific(providerSpecificEvaluateTargetHealth, fmt.Sprintf("%t", r.AliasTarget.EvaluateTargetHealth)).
-						WithProviderSpecific(providerSpecificAlias, "true")
-					newEndpoints = append(newEndpoints, ep)
-				}
+	if len(r.ResourceRecords) > 0 {
+		targets := make([]string, len(r.ResourceRecords))
+		for idx, rr := range r.ResourceRecords {
+			targets[idx] = *rr.Value
+		}
 
-				for _, ep := range newEndpoints {
-					if r.SetIdentifier != nil {
-						ep.SetIdentifier = *r.SetIdentifier
-						switch {
-						case r.Weight != nil:
-							ep.WithProviderSpecific(providerSpecificWeight, fmt.Sprintf("%d", *r.Weight))
-						case r.Region != "":
-							ep.WithProviderSpecific(providerSpecificRegion, string(r.Region))
-						case r.Failover != "":
-							ep.WithProviderSpecific(providerSpecificFailover, string(r.Failover))
-						case r.MultiValueAnswer != nil && *r.MultiValueAnswer:
-							ep.WithProviderSpecific(providerSpecificMultiValueAnswer, "")
-						case r.GeoLocation != nil:
-							if r.GeoLocation.ContinentCode != nil {
-								ep.WithProviderSpecific(providerSpecificGeolocationContinentCode, *r.GeoLocation.ContinentCode)
-							} else {
-								if r.GeoLocation.CountryCode != nil {
-									ep.WithProviderSpecific(providerSpecificGeolocationCountryCode, *r.GeoLocation.CountryCode)
-								}
-								if r.GeoLocation.SubdivisionCode != nil {
-									ep.WithProviderSpecific(providerSpecificGeolocationSubdivisionCode, *r.GeoLocation.SubdivisionCode)
-								}
-							}
-						default:
-							// one of the above needs to be set, otherwise SetIdentifier doesn't make sense
-						}
-					}
+		ep := endpoint.NewEndpointWithTTL(name, string(r.Type), ttl, targets...)
+		if r.Type == endpoint.RecordTypeCNAME {
+			ep = ep.WithProviderSpecific(providerSpecificAlias, "false")
+		}
+		newEndpoints = append(newEndpoints, ep)
+	}
 
-					if r.HealthCheckId != nil {
-						ep.WithProviderSpecific(providerSpecificHealthCheckID, *r.HealthCheckId)
-					}
+	if r.AliasTarget != nil {
+		// Alias records don't have TTLs so provide the default to match the TXT generation
+		if ttl == 0 {
+			ttl = recordTTL
+		}
+		recordType := endpoint.RecordTypeA
+		if r.Type == route53types.RRTypeAaaa {
+			// merged into the A record by mergeDualstackAliases
+			recordType = endpoint.RecordTypeAAAA
+		}
+		ep := endpoint.
+			NewEndpointWithTTL(name, recordType, ttl, *r.AliasTarget.DNSName).
+			WithProviderSpecific(providerSpecificEvaluateTargetHealth, fmt.Sprintf("%t", r.AliasTarget.EvaluateTargetHealth)).
+			WithProviderSpecific(providerSpecificAlias, "true")
+		newEndpoints = append(newEndpoints, ep)
+	}
 
-					endpoints = append(endpoints, ep)
+	for _, ep := range newEndpoints {
+		if r.SetIdentifier != nil {
+			ep.SetIdentifier = *r.SetIdentifier
+			switch {
+			case r.Weight != nil:
+				ep.WithProviderSpecific(providerSpecificWeight, fmt.Sprintf("%d", *r.Weight))
+			case r.Region != "":
+				ep.WithProviderSpecific(providerSpecificRegion, string(r.Region))
+			case r.Failover != "":
+				ep.WithProviderSpecific(providerSpecificFailover, string(r.Failover))
+			case r.MultiValueAnswer != nil && *r.MultiValueAnswer:
+				ep.WithProviderSpecific(providerSpecificMultiValueAnswer, "")
+			case r.GeoLocation != nil:
+				if r.GeoLocation.ContinentCode != nil {
+					ep.WithProviderSpecific(providerSpecificGeolocationContinentCode, *r.GeoLocation.ContinentCode)
+				} else {
+					if r.GeoLocation.CountryCode != nil {
+						ep.WithProviderSpecific(providerSpecificGeolocationCountryCode, *r.GeoLocation.CountryCode)
+					}
+					if r.GeoLocation.SubdivisionCode != nil {
+						ep.WithProviderSpecific(providerSpecificGeolocationSubdivisionCode, *r.GeoLocation.SubdivisionCode)
+					}
 				}
+			default:
+				// one of the above needs to be set, otherwise SetIdentifier doesn't make sense
+			}
+		}
+
+		if r.HealthCheckId != nil {
+			ep.WithProviderSpecific(providerSpecificHealthCheckID, *r.HealthCheckId)
+			if p.manageHealthChecks {
+				p.recordHealthCheck(ep, *r.HealthCheckId)
 			}
 		}
 	}
+	return newEndpoints
+}
 
-	return endpoints, nil
+// listingRoots returns the names under which the records of a zone are listed. With bounded listing, these are
+// the domain filters below the zone name, so that only the record sets under them are listed instead of the whole zone.
+// Otherwise, or if a domain filter covers the whole zone, the root is empty and the whole zone is listed.
+func (p *AWSProvider) listingRoots(zoneName string) []string {
+	zoneName = strings.ToLower(strings.TrimSuffix(zoneName, "."))
+	if !p.boundedListing || len(p.domainFilter.Filters) == 0 {
+		return []string{""}
+	}
+
+	var roots []string
+	for _, filter := range p.domainFilter.Filters {
+		filter = strings.ToLower(strings.Trim(strings.TrimSpace(filter), "."))
+		if filter == "" || filter == zoneName || strings.HasSuffix(zoneName, "."+filter) {
+			return []string{""}
+		}
+		if strings.HasSuffix(filter, "."+zoneName) {
+			roots = append(roots, filter)
+		}
+	}
+	if len(roots) == 0 {
+		// none of the filters is in the zone
+		return []string{""}
+	}
+
+	// drop the roots under other roots, which are listed with them
+	sort.Slice(roots, func(i, j int) bool { return len(roots[i]) < len(roots[j]) })
+	var result []string
+	for _, root := range roots {
+		if !slices.ContainsFunc(result, func(parent string) bool { return root == parent || isUnderRoot(root, parent) }) {
+			result = append(result, root)
+		}
+	}
+	return result
+}
+
+// isUnderRoot returns true if the name of a record set is the root or one of its subdomains.
+func isUnderRoot(name, root string) bool {
+	name = strings.ToLower(strings.TrimSuffix(convertOctalToAscii(name), "."))
+	return name == root || strings.HasSuffix(name, "."+root)
 }
 
 // Identify if old and new endpoints require DELETE/CREATE instead of UPDATE.
@@ -552,7 +819,7 @@ func (p *AWSProvider) requiresDeleteCreate(old *endpoint.Endpoint, new *endpoint
 	// a change of routing policy
 	// default to true for geolocation properties if any geolocation property exists in old/new but not the other
 	for _, propType := range [7]string{providerSpecificWeight, providerSpecificRegion, providerSpecificFailover,
-		providerSpecificFailover, providerSpecificGeolocationContinentCode, providerSpecificGeolocationCountryCode,
+		providerSpecificMultiValueAnswer, providerSpecificGeolocationContinentCode, providerSpecificGeolocationCountryCode,
 		providerSpecificGeolocationSubdivisionCode} {
 		_, oldPolicy := old.GetProviderSpecificProperty(propType)
 		_, newPolicy := new.GetProviderSpecificProperty(propType)
@@ -569,6 +836,8 @@ func (p *AWSProvider) createUpdateChanges(newEndpoints, oldEndpoints []*endpoint
 	var creates []*endpoint.Endpoint
 	var updates []*endpoint.Endpoint
 
+	var staleAAAAs Route53Changes
+
 	for i, new := range newEndpoints {
 		old := oldEndpoints[i]
 		if p.requiresDeleteCreate(old, new) {
@@ -577,13 +846,21 @@ func (p *AWSProvider) createUpdateChanges(newEndpoints, oldEndpoints []*endpoint
 		} else {
 			// Safe to perform an UPSERT.
 			updates = append(updates, new)
+			if isAWSAlias(old) != "" && isDualstack(old) && (isAWSAlias(new) == "" || !isDualstack(new)) {
+				// the AAAA ALIAS record of the old dualstack record is no longer upserted
+				change, _ := p.newChange(route53types.ChangeActionDelete, old)
+				change.ResourceRecordSet.Type = route53types.RRTypeAaaa
+				change.OwnedRecord = ""
+				staleAAAAs = append(staleAAAAs, change)
+			}
 		}
 	}
 
-	combined := make(Route53Changes, 0, len(deletes)+len(creates)+len(updates))
+	combined := make(Route53Changes, 0, len(deletes)+len(creates)+len(updates)+len(staleAAAAs))
 	combined = append(combined, p.newChanges(route53types.ChangeActionCreate, creates)...)
 	combined = append(combined, p.newChanges(route53types.ChangeActionUpsert, updates)...)
 	combined = append(combined, p.newChanges(route53types.ChangeActionDelete, deletes)...)
+	combined = append(combined, staleAAAAs...)
 	return combined
 }
 
@@ -609,14 +886,39 @@ func (p *AWSProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) e
 		return provider.NewSoftError(fmt.Errorf("failed to list zones, not applying changes: %w", err))
 	}
 
-	updateChanges := p.createUpdateChanges(changes.UpdateNew, changes.UpdateOld)
+	// the policy instances are deleted before the records taking their names are created, and created after the
+	// records whose names they take are deleted
+	var deletedPolicies, ensuredPolicies []*endpoint.Endpoint
+	var policiesFailed bool
+	if p.manageTrafficPolicies {
+		deletedPolicies, ensuredPolicies, changes = splitTrafficPolicyChanges(changes)
+		policiesFailed = p.deleteTrafficPolicyInstances(ctx, zones, deletedPolicies)
+	}
+
+	creates, updates := changes.Create, changes.UpdateNew
+	if p.manageHealthChecks {
+		creates = p.attachHealthChecks(ctx, zones, creates)
+		updates = p.attachHealthChecks(ctx, zones, updates)
+	}
+
+	updateChanges := p.createUpdateChanges(updates, changes.UpdateOld)
 
-	combinedChanges := make(Route53Changes, 0, len(changes.Delete)+len(changes.Create)+len(updateChanges))
-	combinedChanges = append(combinedChanges, p.newChanges(route53types.ChangeActionCreate, changes.Create)...)
+	combinedChanges := make(Route53Changes, 0, len(changes.Delete)+len(creates)+len(updateChanges))
+	combinedChanges = append(combinedChanges, p.newChanges(route53types.ChangeActionCreate, creates)...)
 	combinedChanges = append(combinedChanges, p.newChanges(route53types.ChangeActionDelete, changes.Delete)...)
 	combinedChanges = append(combinedChanges, updateChanges...)
 
-	return p.submitChanges(ctx, combinedChanges, zones)
+	err = p.submitChanges(ctx, combinedChanges, zones)
+	if p.manageHealthChecks && err == nil {
+		p.collectHealthChecks(ctx, zones)
+	}
+	if p.manageTrafficPolicies {
+		policiesFailed = p.ensureTrafficPolicyInstances(ctx, zones, ensuredPolicies) || policiesFailed
+	}
+	if err == nil && policiesFailed {
+		return provider.NewSoftError(errors.New("failed to apply all the traffic policy changes"))
+	}
+	return err
 }
 
 // submitChanges takes a zone and a collection of Changes and sends them as a single transaction.
@@ -659,45 +961,8 @@ func (p *AWSProvider) submitChanges(ctx context.Context, changes Route53Changes,
 			}
 
 			if !p.dryRun {
-				params := &route53.ChangeResourceRecordSetsInput{
-					HostedZoneId: aws.String(z),
-					ChangeBatch: &route53types.ChangeBatch{
-						Changes: b.Route53Changes(),
-					},
-				}
-
-				successfulChanges := 0
-
-				client := p.clients[zones[z].profile]
-				if _, err := client.ChangeResourceRecordSets(ctx, params); err != nil {
-					log.Errorf("Failure in zone %s when submitting change batch: %v", *zones[z].zone.Name, err)
-
-					changesByOwnership := groupChangesByNameAndOwnershipRelation(b)
-
-					if len(changesByOwnership) > 1 {
-						log.Debug("Trying to submit change sets one-by-one instead")
-
-						for _, changes := range changesByOwnership {
-							for _, c := range changes {
-								log.Debugf("Desired change: %s %s %s", c.Action, *c.ResourceRecordSet.Name, c.ResourceRecordSet.Type)
-							}
-							params.ChangeBatch = &route53types.ChangeBatch{
-								Changes: changes.Route53Changes(),
-							}
-							if _, err := client.ChangeResourceRecordSets(ctx, params); err != nil {
-								failedUpdate = true
-								log.Errorf("Failed submitting change (error: %v), it will be retried in a separate change batch in the next iteration", err)
-								p.failedChangesQueue[z] = append(p.failedChangesQueue[z], changes...)
-							} else {
-								successfulChanges = successfulChanges + len(changes)
-							}
-						}
-					} else {
-						failedUpdate = true
-					}
-				} else {
-					successfulChanges = len(b)
-				}
+				successfulChanges, failed := p.submitChangeBatch(ctx, log, zones[z], b)
+				failedUpdate = failedUpdate || failed
 
 				if successfulChanges > 0 {
 					// z is the R53 Hosted Zone ID already as aws.StringValue
@@ -722,6 +987,98 @@ func (p *AWSProvider) submitChanges(ctx context.Context, changes Route53Changes,
 	return nil
 }
 
+// submitChangeBatch submits the batch of changes to the zone, and returns the number of changes submitted and whether
+// some failed. A batch rejected for exceeding the limits of Route53 is split in halves submitted separately, so that
+// only the halves still too large are split again. The ownership groups of a batch failing otherwise are submitted
+// one by one, and those failing again are retried in a separate batch in the next iteration.
+func (p *AWSProvider) submitChangeBatch(ctx context.Context, log *log.Entry, zone *profiledZone, b Route53Changes) (int, bool) {
+	zoneID := *zone.zone.Id
+	params := &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(zoneID),
+		ChangeBatch: &route53types.ChangeBatch{
+			Changes: b.Route53Changes(),
+		},
+	}
+
+	client := p.clients[zone.profile]
+	_, err := client.ChangeResourceRecordSets(ctx, params)
+	if err == nil {
+		return len(b), false
+	}
+
+	// the changes of a deleted zone are dropped, the zone being gone from the refreshed zones list
+	if isNoSuchHostedZone(err) {
+		log.Warnf("Hosted zone %s was deleted, refreshing the zones list: %v", *zone.zone.Name, err)
+		p.zonesCache.invalidate()
+		return 0, true
+	}
+
+	changesByOwnership := groupChangesByNameAndOwnershipRelation(b)
+	if isChangeBatchTooLarge(err) && len(changesByOwnership) > 1 {
+		first, second := splitChangeBatch(changesByOwnership)
+		log.Warnf("Change batch of %d changes exceeds the limits of Route53, submitting it as batches of %d and %d changes: %v", len(b), len(first), len(second), err)
+		firstSuccessful, firstFailed := p.submitChangeBatch(ctx, log, zone, first)
+		secondSuccessful, secondFailed := p.submitChangeBatch(ctx, log, zone, second)
+		return firstSuccessful + secondSuccessful, firstFailed || secondFailed
+	}
+
+	log.Errorf("Failure in zone %s when submitting change batch: %v", *zone.zone.Name, err)
+	if len(changesByOwnership) <= 1 {
+		return 0, true
+	}
+
+	log.Debug("Trying to submit change sets one-by-one instead")
+	successfulChanges, failed := 0, false
+	for _, changes := range changesByOwnership {
+		for _, c := range changes {
+			log.Debugf("Desired change: %s %s %s", c.Action, *c.ResourceRecordSet.Name, c.ResourceRecordSet.Type)
+		}
+		params.ChangeBatch = &route53types.ChangeBatch{
+			Changes: changes.Route53Changes(),
+		}
+		if _, err := client.ChangeResourceRecordSets(ctx, params); err != nil {
+			failed = true
+			log.Errorf("Failed submitting change (error: %v), it will be retried in a separate change batch in the next iteration", err)
+			p.failedChangesQueue[zoneID] = append(p.failedChangesQueue[zoneID], changes...)
+		} else {
+			successfulChanges = successfulChanges + len(changes)
+		}
+	}
+	return successfulChanges, failed
+}
+
+// isChangeBatchTooLarge returns true if the change batch was rejected for exceeding the limits of Route53
+// on the number of changes, of values or of characters of a request.
+func isChangeBatchTooLarge(err error) bool {
+	var invalid *route53types.InvalidChangeBatch
+	if errors.As(err, &invalid) {
+		message := strings.ToLower(invalid.ErrorMessage())
+		return strings.Contains(message, "limit") && strings.Contains(message, "exceeded")
+	}
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "RequestEntityTooLarge"
+}
+
+// splitChangeBatch splits the ownership groups of a change batch in two batches of about the same number of changes.
+func splitChangeBatch(changesByOwnership map[string]Route53Changes) (Route53Changes, Route53Changes) {
+	names := slices.Sorted(maps.Keys(changesByOwnership))
+	total := 0
+	for _, changes := range changesByOwnership {
+		total += len(changes)
+	}
+	var first, second Route53Changes
+	for _, name := range names {
+		// the first batch gets at least one group, the second one the groups after half of the changes
+		changes := changesByOwnership[name]
+		if len(second) == 0 && (len(first) == 0 || len(first)+len(changes) <= total/2) {
+			first = append(first, changes...)
+		} else {
+			second = append(second, changes...)
+		}
+	}
+	return sortChangesByActionNameType(first), sortChangesByActionNameType(second)
+}
+
 // newChanges returns a collection of Changes based on the given records and action.
 func (p *AWSProvider) newChanges(action route53types.ChangeAction, endpoints []*endpoint.Endpoint) Route53Changes {
 	changes := make(Route53Changes, 0, len(endpoints))
@@ -749,7 +1106,19 @@ func (p *AWSProvider) newChanges(action route53types.ChangeAction, endpoints []*
 // Example: CNAME endpoints pointing to ELBs will have a `alias` provider-specific property
 // added to match the endpoints generated from existing alias records in Route53.
 func (p *AWSProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	endpoints = expandFailoverPairs(endpoints, p.preferCNAME)
+	adjusted := make([]*endpoint.Endpoint, 0, len(endpoints))
 	for _, ep := range endpoints {
+		if !p.adjustTrafficPolicy(ep) {
+			continue
+		}
+		adjusted = append(adjusted, ep)
+		if _, ok := ep.GetProviderSpecificProperty(providerSpecificTrafficPolicyDocument); ok {
+			// the routing of the record is described by the document
+			continue
+		}
+		adjustRoutingPolicy(ep)
+		p.adjustHealthCheck(ep)
 		alias := false
 
 		if aliasString, ok := ep.GetProviderSpecificProperty(providerSpecificAlias); ok {
@@ -786,11 +1155,157 @@ func (p *AWSProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoi
 			} else {
 				ep.SetProviderSpecificProperty(providerSpecificEvaluateTargetHealth, strconv.FormatBool(p.evaluateTargetHealth))
 			}
+			if isDualstack(ep) {
+				ep.SetProviderSpecificProperty(providerSpecificDualstack, "true")
+			} else {
+				ep.DeleteProviderSpecificProperty(providerSpecificDualstack)
+			}
 		} else {
 			ep.DeleteProviderSpecificProperty(providerSpecificEvaluateTargetHealth)
+			ep.DeleteProviderSpecificProperty(providerSpecificDualstack)
 		}
 	}
-	return endpoints, nil
+	return adjusted, nil
+}
+
+// expandFailoverPairs appends the SECONDARY record of each endpoint with failover secondary targets, which
+// becomes the PRIMARY record of the pair. Without set identifiers, the records are identified as primary
+// and secondary, otherwise the secondary set identifier defaults to the primary one suffixed with -secondary.
+// The type of the SECONDARY record is inferred from its targets, see failoverSecondaryType.
+func expandFailoverPairs(endpoints []*endpoint.Endpoint, preferCNAME bool) []*endpoint.Endpoint {
+	var secondaries []*endpoint.Endpoint
+	for _, ep := range endpoints {
+		targets, ok := ep.GetProviderSpecificProperty(providerSpecificFailoverSecondaryTargets)
+		healthCheckID, _ := ep.GetProviderSpecificProperty(providerSpecificFailoverSecondaryHealthCheckID)
+		setIdentifier, _ := ep.GetProviderSpecificProperty(providerSpecificFailoverSecondarySetIdentifier)
+		// the properties only describe the secondary record and are not properties of the primary one
+		ep.DeleteProviderSpecificProperty(providerSpecificFailoverSecondaryTargets)
+		ep.DeleteProviderSpecificProperty(providerSpecificFailoverSecondaryHealthCheckID)
+		ep.DeleteProviderSpecificProperty(providerSpecificFailoverSecondarySetIdentifier)
+		if !ok {
+			continue
+		}
+		if failover, ok := ep.GetProviderSpecificProperty(providerSpecificFailover); ok && failover != string(route53types.ResourceRecordSetFailoverPrimary) {
+			log.Warnf("Ignoring the failover secondary targets of %s, which is the %s record of its failover pair", ep.DNSName, failover)
+			continue
+		}
+		var secondaryTargets endpoint.Targets
+		for _, target := range strings.Split(targets, ",") {
+			if target = strings.TrimSpace(target); target != "" {
+				secondaryTargets = append(secondaryTargets, strings.TrimSuffix(target, "."))
+			}
+		}
+		if len(secondaryTargets) == 0 {
+			log.Warnf("Ignoring the empty failover secondary targets of %s", ep.DNSName)
+			continue
+		}
+		recordType, alias, ok := failoverSecondaryType(ep, secondaryTargets, preferCNAME)
+		if !ok {
+			log.Warnf("Ignoring the failover secondary targets %s of %s, which can't be a record of the type of the %s record of the pair", secondaryTargets, ep.DNSName, ep.RecordType)
+			continue
+		}
+
+		if setIdentifier == "" {
+			if ep.SetIdentifier == "" {
+				setIdentifier = "secondary"
+			} else {
+				setIdentifier = ep.SetIdentifier + "-secondary"
+			}
+		}
+		if ep.SetIdentifier == "" {
+			ep.SetIdentifier = "primary"
+		}
+		ep.SetProviderSpecificProperty(providerSpecificFailover, string(route53types.ResourceRecordSetFailoverPrimary))
+
+		secondary := endpoint.NewEndpointWithTTL(ep.DNSName, recordType, ep.RecordTTL, secondaryTargets...).
+			WithSetIdentifier(setIdentifier).
+			WithProviderSpecific(providerSpecificFailover, string(route53types.ResourceRecordSetFailoverSecondary))
+		if alias != "" {
+			secondary.WithProviderSpecific(providerSpecificAlias, alias)
+		}
+		if healthCheckID != "" {
+			secondary.WithProviderSpecific(providerSpecificHealthCheckID, healthCheckID)
+		}
+		for key, value := range ep.Labels {
+			secondary.Labels[key] = value
+		}
+		secondaries = append(secondaries, secondary)
+	}
+	return append(endpoints, secondaries...)
+}
+
+// failoverSecondaryType returns the type of the SECONDARY record of the targets, which Route 53 requires to be the
+// type of the PRIMARY record: IP addresses are A or AAAA records, and hostnames CNAME records, or alias A records if
+// the PRIMARY record is an A record. alias is the alias property of the SECONDARY record, if any, so that it is an
+// alias record exactly when the PRIMARY record is. ok is false if the targets can't be a record of that type, e.g.
+// IP addresses behind a CNAME record, or hostnames other than AWS resources behind an A record.
+func failoverSecondaryType(primary *endpoint.Endpoint, targets endpoint.Targets, preferCNAME bool) (recordType, alias string, ok bool) {
+	for _, target := range targets {
+		targetType := endpoint.RecordTypeCNAME
+		if addr, err := netip.ParseAddr(target); err == nil {
+			targetType = endpoint.RecordTypeA
+			if !addr.Is4() {
+				targetType = endpoint.RecordTypeAAAA
+			}
+		}
+		if recordType != "" && recordType != targetType {
+			return "", "", false
+		}
+		recordType = targetType
+	}
+
+	primaryType := primary.RecordType
+	if primaryType == endpoint.RecordTypeCNAME {
+		if aliasString, ok := primary.GetProviderSpecificProperty(providerSpecificAlias); ok && aliasString == "true" || !ok && useAlias(primary, preferCNAME) {
+			primaryType = endpoint.RecordTypeA
+		}
+	}
+	switch {
+	case recordType != endpoint.RecordTypeCNAME:
+		return recordType, "", recordType == primaryType
+	case primaryType == endpoint.RecordTypeCNAME:
+		return recordType, "false", true
+	case primaryType == endpoint.RecordTypeA:
+		for _, target := range targets {
+			if canonicalHostedZone(target) == "" {
+				return "", "", false
+			}
+		}
+		return recordType, "true", true
+	}
+	return "", "", false
+}
+
+// PropertyValuesEqual compares the evaluate-target-health property as a boolean, missing values defaulting to
+// --aws-evaluate-target-health, and other provider-specific properties verbatim.
+func (p *AWSProvider) PropertyValuesEqual(name, desired, current string) bool {
+	if name == providerSpecificHealthCheckID && desired == "" && p.manageHealthChecks {
+		// the ID of a managed health check is not desired but derived from the health check properties
+		return current == "" || p.healthChecks.isManaged(current)
+	}
+	if name == providerSpecificTrafficPolicyDocument {
+		return trafficPolicyDocumentsEqual(desired, current)
+	}
+	if name != providerSpecificEvaluateTargetHealth {
+		return desired == current
+	}
+	return p.parseEvaluateTargetHealth(desired) == p.parseEvaluateTargetHealth(current)
+}
+
+func (p *AWSProvider) parseEvaluateTargetHealth(value string) bool {
+	if value == "" {
+		return p.evaluateTargetHealth
+	}
+	return value == "true"
+}
+
+// isDualstack returns whether the ALIAS record of the endpoint is dualstack: its dualstack property, or
+// else its dualstack label, is true.
+func isDualstack(ep *endpoint.Endpoint) bool {
+	if prop, ok := ep.GetProviderSpecificProperty(providerSpecificDualstack); ok {
+		return prop == "true"
+	}
+	return ep.Labels[endpoint.DualstackLabelKey] == "true"
 }
 
 // newChange returns a route53 Change and a boolean indicating if there should also be a change to a AAAA record
@@ -802,7 +1317,8 @@ func (p *AWSProvider) newChange(action route53types.ChangeAction, ep *endpoint.E
 		Change: route53types.Change{
 			Action: action,
 			ResourceRecordSet: &route53types.ResourceRecordSet{
-				Name: aws.String(ep.DNSName),
+				// Route53 expects the wildcards and the special characters escaped
+				Name: aws.String(endpoint.EscapeDNSName(ep.DNSName)),
 			},
 		},
 	}
@@ -812,10 +1328,8 @@ func (p *AWSProvider) newChange(action route53types.ChangeAction, ep *endpoint.E
 		if prop, ok := ep.GetProviderSpecificProperty(providerSpecificEvaluateTargetHealth); ok {
 			evalTargetHealth = prop == "true"
 		}
-		// If the endpoint has a Dualstack label, append a change for AAAA record as well.
-		if val, ok := ep.Labels[endpoint.DualstackLabelKey]; ok {
-			dualstack = val == "true"
-		}
+		// If the endpoint is dualstack, append a change for AAAA record as well.
+		dualstack = isDualstack(ep)
 		change.ResourceRecordSet.Type = route53types.RRTypeA
 		change.ResourceRecordSet.AliasTarget = &route53types.AliasTarget{
 			DNSName:              aws.String(ep.Targets[0]),
@@ -833,6 +1347,10 @@ func (p *AWSProvider) newChange(action route53types.ChangeAction, ep *endpoint.E
 		}
 		change.ResourceRecordSet.ResourceRecords = make([]route53types.ResourceRecord, len(ep.Targets))
 		for idx, val := range ep.Targets {
+			if ep.RecordType == endpoint.RecordTypeTXT {
+				// Route 53 takes TXT records as quoted character strings of at most 255 bytes each
+				val = endpoint.ChunkTXTTarget(val)
+			}
 			change.ResourceRecordSet.ResourceRecords[idx] = route53types.ResourceRecord{
 				Value: aws.String(val),
 			}
@@ -929,7 +1447,8 @@ func findChangesInQueue(changes Route53Changes, queue Route53Changes) (foundChan
 func groupChangesByNameAndOwnershipRelation(cs Route53Changes) map[string]Route53Changes {
 	changesByOwnership := make(map[string]Route53Changes)
 	for _, v := range cs {
-		key := v.OwnedRecord
+		// the owned record is named as in the endpoints, while the changes are named escaped
+		key := endpoint.EscapeDNSName(v.OwnedRecord)
 		if key == "" {
 			key = *v.ResourceRecordSet.Name
 		}
@@ -21,7 +21,9 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -29,6 +31,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
 	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/aws/smithy-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -58,8 +61,20 @@ type Route53APIStub struct {
 	zones      map[string]*route53types.HostedZone
 	recordSets map[string]map[string][]route53types.ResourceRecordSet
 	zoneTags   map[string][]route53types.Tag
-	m          dynamicMock
-	t          *testing.T
+	// health checks by ID
+	healthChecks        map[string]route53types.HealthCheck
+	healthChecksCreated int
+	m                   dynamicMock
+	t                   *testing.T
+
+	// versions of the traffic policies by ID, and policy instances by ID
+	trafficPolicies         map[string][]route53types.TrafficPolicy
+	trafficPolicyInstances  map[string]route53types.TrafficPolicyInstance
+	trafficPoliciesCreated  int
+	trafficInstancesCreated int
+
+	// hosted zones associated with the VPCs by VPC ID, including the zones of other accounts
+	vpcZones map[string][]route53types.HostedZoneSummary
 }
 
 // MockMethod starts a description of an expectation of the specified method
@@ -73,10 +88,15 @@ func (r *Route53APIStub) MockMethod(method string, args ...interface{}) *mock.Ca
 // NewRoute53APIStub returns an initialized Route53APIStub
 func NewRoute53APIStub(t *testing.T) *Route53APIStub {
 	return &Route53APIStub{
-		zones:      make(map[string]*route53types.HostedZone),
-		recordSets: make(map[string]map[string][]route53types.ResourceRecordSet),
-		zoneTags:   make(map[string][]route53types.Tag),
-		t:          t,
+		zones:        make(map[string]*route53types.HostedZone),
+		recordSets:   make(map[string]map[string][]route53types.ResourceRecordSet),
+		zoneTags:     make(map[string][]route53types.Tag),
+		healthChecks: make(map[string]route53types.HealthCheck),
+		t:            t,
+
+		trafficPolicies:        make(map[string][]route53types.TrafficPolicy),
+		trafficPolicyInstances: make(map[string]route53types.TrafficPolicyInstance),
+		vpcZones:               make(map[string][]route53types.HostedZoneSummary),
 	}
 }
 
@@ -93,9 +113,25 @@ func (r *Route53APIStub) ListResourceRecordSets(ctx context.Context, input *rout
 			output.ResourceRecordSets = append(output.ResourceRecordSets, rrsets...)
 		}
 	}
+	if input.StartRecordName != nil {
+		// like Route53, list the record sets in the order of their names with the labels reversed, starting with the given name
+		slices.SortFunc(output.ResourceRecordSets, func(a, b route53types.ResourceRecordSet) int {
+			return slices.Compare(reversedLabels(*a.Name), reversedLabels(*b.Name))
+		})
+		start := reversedLabels(*input.StartRecordName)
+		output.ResourceRecordSets = slices.DeleteFunc(output.ResourceRecordSets, func(r route53types.ResourceRecordSet) bool {
+			return slices.Compare(reversedLabels(*r.Name), start) < 0
+		})
+	}
 	return output, nil
 }
 
+func reversedLabels(name string) []string {
+	labels := strings.Split(strings.TrimSuffix(name, "."), ".")
+	slices.Reverse(labels)
+	return labels
+}
+
 type Route53APICounter struct {
 	wrapped Route53API
 	calls   map[string]int
@@ -123,16 +159,96 @@ func (c *Route53APICounter) CreateHostedZone(ctx context.Context, input *route53
 	return c.wrapped.CreateHostedZone(ctx, input, optFns...)
 }
 
+func (c *Route53APICounter) DeleteHostedZone(ctx context.Context, input *route53.DeleteHostedZoneInput, optFns ...func(*route53.Options)) (*route53.DeleteHostedZoneOutput, error) {
+	c.calls["DeleteHostedZone"]++
+	return c.wrapped.DeleteHostedZone(ctx, input, optFns...)
+}
+
 func (c *Route53APICounter) ListHostedZones(ctx context.Context, input *route53.ListHostedZonesInput, optFns ...func(options *route53.Options)) (*route53.ListHostedZonesOutput, error) {
 	c.calls["ListHostedZonesPages"]++
 	return c.wrapped.ListHostedZones(ctx, input, optFns...)
 }
 
+func (c *Route53APICounter) ListHostedZonesByVPC(ctx context.Context, input *route53.ListHostedZonesByVPCInput, optFns ...func(options *route53.Options)) (*route53.ListHostedZonesByVPCOutput, error) {
+	c.calls["ListHostedZonesByVPC"]++
+	return c.wrapped.ListHostedZonesByVPC(ctx, input, optFns...)
+}
+
+func (c *Route53APICounter) ListHealthChecks(ctx context.Context, input *route53.ListHealthChecksInput, optFns ...func(*route53.Options)) (*route53.ListHealthChecksOutput, error) {
+	c.calls["ListHealthChecks"]++
+	return c.wrapped.ListHealthChecks(ctx, input, optFns...)
+}
+
+func (c *Route53APICounter) CreateHealthCheck(ctx context.Context, input *route53.CreateHealthCheckInput, optFns ...func(*route53.Options)) (*route53.CreateHealthCheckOutput, error) {
+	c.calls["CreateHealthCheck"]++
+	return c.wrapped.CreateHealthCheck(ctx, input, optFns...)
+}
+
+func (c *Route53APICounter) UpdateHealthCheck(ctx context.Context, input *route53.UpdateHealthCheckInput, optFns ...func(*route53.Options)) (*route53.UpdateHealthCheckOutput, error) {
+	c.calls["UpdateHealthCheck"]++
+	return c.wrapped.UpdateHealthCheck(ctx, input, optFns...)
+}
+
+func (c *Route53APICounter) DeleteHealthCheck(ctx context.Context, input *route53.DeleteHealthCheckInput, optFns ...func(*route53.Options)) (*route53.DeleteHealthCheckOutput, error) {
+	c.calls["DeleteHealthCheck"]++
+	return c.wrapped.DeleteHealthCheck(ctx, input, optFns...)
+}
+
 func (c *Route53APICounter) ListTagsForResource(ctx context.Context, input *route53.ListTagsForResourceInput, optFns ...func(options *route53.Options)) (*route53.ListTagsForResourceOutput, error) {
 	c.calls["ListTagsForResource"]++
 	return c.wrapped.ListTagsForResource(ctx, input, optFns...)
 }
 
+func (c *Route53APICounter) ChangeTagsForResource(ctx context.Context, input *route53.ChangeTagsForResourceInput, optFns ...func(options *route53.Options)) (*route53.ChangeTagsForResourceOutput, error) {
+	c.calls["ChangeTagsForResource"]++
+	return c.wrapped.ChangeTagsForResource(ctx, input, optFns...)
+}
+
+func (c *Route53APICounter) ListTrafficPolicyInstancesByHostedZone(ctx context.Context, input *route53.ListTrafficPolicyInstancesByHostedZoneInput, optFns ...func(*route53.Options)) (*route53.ListTrafficPolicyInstancesByHostedZoneOutput, error) {
+	c.calls["ListTrafficPolicyInstancesByHostedZone"]++
+	return c.wrapped.ListTrafficPolicyInstancesByHostedZone(ctx, input, optFns...)
+}
+
+func (c *Route53APICounter) GetTrafficPolicy(ctx context.Context, input *route53.GetTrafficPolicyInput, optFns ...func(*route53.Options)) (*route53.GetTrafficPolicyOutput, error) {
+	c.calls["GetTrafficPolicy"]++
+	return c.wrapped.GetTrafficPolicy(ctx, input, optFns...)
+}
+
+func (c *Route53APICounter) ListTrafficPolicies(ctx context.Context, input *route53.ListTrafficPoliciesInput, optFns ...func(*route53.Options)) (*route53.ListTrafficPoliciesOutput, error) {
+	c.calls["ListTrafficPolicies"]++
+	return c.wrapped.ListTrafficPolicies(ctx, input, optFns...)
+}
+
+func (c *Route53APICounter) CreateTrafficPolicy(ctx context.Context, input *route53.CreateTrafficPolicyInput, optFns ...func(*route53.Options)) (*route53.CreateTrafficPolicyOutput, error) {
+	c.calls["CreateTrafficPolicy"]++
+	return c.wrapped.CreateTrafficPolicy(ctx, input, optFns...)
+}
+
+func (c *Route53APICounter) CreateTrafficPolicyVersion(ctx context.Context, input *route53.CreateTrafficPolicyVersionInput, optFns ...func(*route53.Options)) (*route53.CreateTrafficPolicyVersionOutput, error) {
+	c.calls["CreateTrafficPolicyVersion"]++
+	return c.wrapped.CreateTrafficPolicyVersion(ctx, input, optFns...)
+}
+
+func (c *Route53APICounter) DeleteTrafficPolicy(ctx context.Context, input *route53.DeleteTrafficPolicyInput, optFns ...func(*route53.Options)) (*route53.DeleteTrafficPolicyOutput, error) {
+	c.calls["DeleteTrafficPolicy"]++
+	return c.wrapped.DeleteTrafficPolicy(ctx, input, optFns...)
+}
+
+func (c *Route53APICounter) CreateTrafficPolicyInstance(ctx context.Context, input *route53.CreateTrafficPolicyInstanceInput, optFns ...func(*route53.Options)) (*route53.CreateTrafficPolicyInstanceOutput, error) {
+	c.calls["CreateTrafficPolicyInstance"]++
+	return c.wrapped.CreateTrafficPolicyInstance(ctx, input, optFns...)
+}
+
+func (c *Route53APICounter) UpdateTrafficPolicyInstance(ctx context.Context, input *route53.UpdateTrafficPolicyInstanceInput, optFns ...func(*route53.Options)) (*route53.UpdateTrafficPolicyInstanceOutput, error) {
+	c.calls["UpdateTrafficPolicyInstance"]++
+	return c.wrapped.UpdateTrafficPolicyInstance(ctx, input, optFns...)
+}
+
+func (c *Route53APICounter) DeleteTrafficPolicyInstance(ctx context.Context, input *route53.DeleteTrafficPolicyInstanceInput, optFns ...func(*route53.Options)) (*route53.DeleteTrafficPolicyInstanceOutput, error) {
+	c.calls["DeleteTrafficPolicyInstance"]++
+	return c.wrapped.DeleteTrafficPolicyInstance(ctx, input, optFns...)
+}
+
 // Route53 stores wildcards escaped: http://docs.aws.amazon.com/Route53/latest/DeveloperGuide/DomainNameFormat.html?shortFooter=true#domain-name-format-asterisk
 func wildcardEscape(s string) string {
 	if strings.Contains(s, "*") {
@@ -236,6 +352,27 @@ func (r *Route53APIStub) ListHostedZones(ctx context.Context, input *route53.Lis
 	return output, nil
 }
 
+func (r *Route53APIStub) ListHostedZonesByVPC(ctx context.Context, input *route53.ListHostedZonesByVPCInput, optFns ...func(options *route53.Options)) (*route53.ListHostedZonesByVPCOutput, error) {
+	require.NotEmpty(r.t, input.VPCRegion)
+	zones, ok := r.vpcZones[*input.VPCId]
+	if !ok {
+		return nil, &route53types.InvalidVPCId{Message: aws.String("no VPC " + *input.VPCId)}
+	}
+	// one zone per page, to exercise the pagination
+	page := 0
+	if input.NextToken != nil {
+		page, _ = strconv.Atoi(*input.NextToken)
+	}
+	output := &route53.ListHostedZonesByVPCOutput{}
+	if page < len(zones) {
+		output.HostedZoneSummaries = zones[page : page+1]
+	}
+	if page+1 < len(zones) {
+		output.NextToken = aws.String(strconv.Itoa(page + 1))
+	}
+	return output, nil
+}
+
 func (r *Route53APIStub) CreateHostedZone(ctx context.Context, input *route53.CreateHostedZoneInput, optFns ...func(options *route53.Options)) (*route53.CreateHostedZoneOutput, error) {
 	name := *input.Name
 	id := "/hostedzone/" + name
@@ -250,6 +387,227 @@ func (r *Route53APIStub) CreateHostedZone(ctx context.Context, input *route53.Cr
 	return &route53.CreateHostedZoneOutput{HostedZone: r.zones[id]}, nil
 }
 
+func (r *Route53APIStub) DeleteHostedZone(ctx context.Context, input *route53.DeleteHostedZoneInput, optFns ...func(options *route53.Options)) (*route53.DeleteHostedZoneOutput, error) {
+	if _, ok := r.zones[*input.Id]; !ok {
+		return nil, &route53types.NoSuchHostedZone{Message: aws.String("No hosted zone found")}
+	}
+	delete(r.zones, *input.Id)
+	delete(r.recordSets, *input.Id)
+	return &route53.DeleteHostedZoneOutput{}, nil
+}
+
+func (r *Route53APIStub) ChangeTagsForResource(ctx context.Context, input *route53.ChangeTagsForResourceInput, optFns ...func(options *route53.Options)) (*route53.ChangeTagsForResourceOutput, error) {
+	if input.ResourceType == route53types.TagResourceTypeHostedzone {
+		r.zoneTags[*input.ResourceId] = append(r.zoneTags[*input.ResourceId], input.AddTags...)
+	}
+	return &route53.ChangeTagsForResourceOutput{}, nil
+}
+
+func (r *Route53APIStub) ListHealthChecks(ctx context.Context, input *route53.ListHealthChecksInput, optFns ...func(*route53.Options)) (*route53.ListHealthChecksOutput, error) {
+	output := &route53.ListHealthChecksOutput{}
+	for _, check := range r.healthChecks {
+		output.HealthChecks = append(output.HealthChecks, check)
+	}
+	slices.SortFunc(output.HealthChecks, func(a, b route53types.HealthCheck) int {
+		return strings.Compare(*a.Id, *b.Id)
+	})
+	return output, nil
+}
+
+func (r *Route53APIStub) CreateHealthCheck(ctx context.Context, input *route53.CreateHealthCheckInput, optFns ...func(*route53.Options)) (*route53.CreateHealthCheckOutput, error) {
+	for _, check := range r.healthChecks {
+		if *check.CallerReference == *input.CallerReference {
+			return nil, fmt.Errorf("health check with caller reference %s already exists", *input.CallerReference)
+		}
+	}
+	r.healthChecksCreated++
+	check := route53types.HealthCheck{
+		Id:                 aws.String(fmt.Sprintf("hc-%d", r.healthChecksCreated)),
+		CallerReference:    input.CallerReference,
+		HealthCheckConfig:  input.HealthCheckConfig,
+		HealthCheckVersion: aws.Int64(1),
+	}
+	r.healthChecks[*check.Id] = check
+	return &route53.CreateHealthCheckOutput{HealthCheck: &check}, nil
+}
+
+func (r *Route53APIStub) UpdateHealthCheck(ctx context.Context, input *route53.UpdateHealthCheckInput, optFns ...func(*route53.Options)) (*route53.UpdateHealthCheckOutput, error) {
+	check, ok := r.healthChecks[*input.HealthCheckId]
+	if !ok {
+		return nil, fmt.Errorf("health check %s doesn't exist", *input.HealthCheckId)
+	}
+	if input.HealthCheckVersion != nil && *input.HealthCheckVersion != *check.HealthCheckVersion {
+		return nil, fmt.Errorf("health check %s version mismatch", *input.HealthCheckId)
+	}
+	config := *check.HealthCheckConfig
+	config.Port = input.Port
+	config.ResourcePath = input.ResourcePath
+	config.FailureThreshold = input.FailureThreshold
+	config.IPAddress = input.IPAddress
+	config.FullyQualifiedDomainName = input.FullyQualifiedDomainName
+	check.HealthCheckConfig = &config
+	check.HealthCheckVersion = aws.Int64(*check.HealthCheckVersion + 1)
+	r.healthChecks[*check.Id] = check
+	return &route53.UpdateHealthCheckOutput{HealthCheck: &check}, nil
+}
+
+func (r *Route53APIStub) DeleteHealthCheck(ctx context.Context, input *route53.DeleteHealthCheckInput, optFns ...func(*route53.Options)) (*route53.DeleteHealthCheckOutput, error) {
+	if _, ok := r.healthChecks[*input.HealthCheckId]; !ok {
+		return nil, fmt.Errorf("health check %s doesn't exist", *input.HealthCheckId)
+	}
+	delete(r.healthChecks, *input.HealthCheckId)
+	return &route53.DeleteHealthCheckOutput{}, nil
+}
+
+func (r *Route53APIStub) ListTrafficPolicyInstancesByHostedZone(ctx context.Context, input *route53.ListTrafficPolicyInstancesByHostedZoneInput, optFns ...func(*route53.Options)) (*route53.ListTrafficPolicyInstancesByHostedZoneOutput, error) {
+	output := &route53.ListTrafficPolicyInstancesByHostedZoneOutput{}
+	for _, instance := range r.trafficPolicyInstances {
+		if *instance.HostedZoneId == *input.HostedZoneId {
+			output.TrafficPolicyInstances = append(output.TrafficPolicyInstances, instance)
+		}
+	}
+	if len(output.TrafficPolicyInstances) == 0 {
+		return nil, &route53types.NoSuchTrafficPolicyInstance{Message: aws.String("No traffic policy instance found")}
+	}
+	return output, nil
+}
+
+func (r *Route53APIStub) GetTrafficPolicy(ctx context.Context, input *route53.GetTrafficPolicyInput, optFns ...func(*route53.Options)) (*route53.GetTrafficPolicyOutput, error) {
+	for _, policy := range r.trafficPolicies[*input.Id] {
+		if *policy.Version == *input.Version {
+			return &route53.GetTrafficPolicyOutput{TrafficPolicy: &policy}, nil
+		}
+	}
+	return nil, &route53types.NoSuchTrafficPolicy{Message: aws.String("No traffic policy found")}
+}
+
+func (r *Route53APIStub) ListTrafficPolicies(ctx context.Context, input *route53.ListTrafficPoliciesInput, optFns ...func(*route53.Options)) (*route53.ListTrafficPoliciesOutput, error) {
+	output := &route53.ListTrafficPoliciesOutput{}
+	for id, versions := range r.trafficPolicies {
+		latest := versions[len(versions)-1]
+		output.TrafficPolicySummaries = append(output.TrafficPolicySummaries, route53types.TrafficPolicySummary{
+			Id: aws.String(id), Name: latest.Name, LatestVersion: latest.Version, Type: latest.Type,
+		})
+	}
+	return output, nil
+}
+
+func (r *Route53APIStub) CreateTrafficPolicy(ctx context.Context, input *route53.CreateTrafficPolicyInput, optFns ...func(*route53.Options)) (*route53.CreateTrafficPolicyOutput, error) {
+	for _, versions := range r.trafficPolicies {
+		if *versions[0].Name == *input.Name {
+			return nil, &route53types.TrafficPolicyAlreadyExists{Message: aws.String("Traffic policy already exists")}
+		}
+	}
+	r.trafficPoliciesCreated++
+	policy := route53types.TrafficPolicy{
+		Id:       aws.String(fmt.Sprintf("tp-%d", r.trafficPoliciesCreated)),
+		Name:     input.Name,
+		Document: input.Document,
+		Version:  aws.Int32(1),
+	}
+	r.trafficPolicies[*policy.Id] = []route53types.TrafficPolicy{policy}
+	return &route53.CreateTrafficPolicyOutput{TrafficPolicy: &policy}, nil
+}
+
+func (r *Route53APIStub) CreateTrafficPolicyVersion(ctx context.Context, input *route53.CreateTrafficPolicyVersionInput, optFns ...func(*route53.Options)) (*route53.CreateTrafficPolicyVersionOutput, error) {
+	versions, ok := r.trafficPolicies[*input.Id]
+	if !ok {
+		return nil, &route53types.NoSuchTrafficPolicy{Message: aws.String("No traffic policy found")}
+	}
+	policy := versions[len(versions)-1]
+	policy.Document = input.Document
+	policy.Version = aws.Int32(*policy.Version + 1)
+	r.trafficPolicies[*input.Id] = append(versions, policy)
+	return &route53.CreateTrafficPolicyVersionOutput{TrafficPolicy: &policy}, nil
+}
+
+func (r *Route53APIStub) DeleteTrafficPolicy(ctx context.Context, input *route53.DeleteTrafficPolicyInput, optFns ...func(*route53.Options)) (*route53.DeleteTrafficPolicyOutput, error) {
+	for _, instance := range r.trafficPolicyInstances {
+		if *instance.TrafficPolicyId == *input.Id && *instance.TrafficPolicyVersion == *input.Version {
+			return nil, &route53types.TrafficPolicyInUse{Message: aws.String("Traffic policy in use")}
+		}
+	}
+	versions := slices.DeleteFunc(r.trafficPolicies[*input.Id], func(policy route53types.TrafficPolicy) bool {
+		return *policy.Version == *input.Version
+	})
+	if len(versions) == 0 {
+		delete(r.trafficPolicies, *input.Id)
+	} else {
+		r.trafficPolicies[*input.Id] = versions
+	}
+	return &route53.DeleteTrafficPolicyOutput{}, nil
+}
+
+func (r *Route53APIStub) CreateTrafficPolicyInstance(ctx context.Context, input *route53.CreateTrafficPolicyInstanceInput, optFns ...func(*route53.Options)) (*route53.CreateTrafficPolicyInstanceOutput, error) {
+	r.trafficInstancesCreated++
+	instance := route53types.TrafficPolicyInstance{
+		Id:           aws.String(fmt.Sprintf("tpi-%d", r.trafficInstancesCreated)),
+		HostedZoneId: input.HostedZoneId,
+		Name:         input.Name,
+		TTL:          input.TTL,
+	}
+	if err := r.applyTrafficPolicyInstance(&instance, *input.TrafficPolicyId, *input.TrafficPolicyVersion); err != nil {
+		return nil, err
+	}
+	return &route53.CreateTrafficPolicyInstanceOutput{TrafficPolicyInstance: &instance}, nil
+}
+
+func (r *Route53APIStub) UpdateTrafficPolicyInstance(ctx context.Context, input *route53.UpdateTrafficPolicyInstanceInput, optFns ...func(*route53.Options)) (*route53.UpdateTrafficPolicyInstanceOutput, error) {
+	instance, ok := r.trafficPolicyInstances[*input.Id]
+	if !ok {
+		return nil, &route53types.NoSuchTrafficPolicyInstance{Message: aws.String("No traffic policy instance found")}
+	}
+	instance.TTL = input.TTL
+	if err := r.applyTrafficPolicyInstance(&instance, *input.TrafficPolicyId, *input.TrafficPolicyVersion); err != nil {
+		return nil, err
+	}
+	return &route53.UpdateTrafficPolicyInstanceOutput{TrafficPolicyInstance: &instance}, nil
+}
+
+func (r *Route53APIStub) DeleteTrafficPolicyInstance(ctx context.Context, input *route53.DeleteTrafficPolicyInstanceInput, optFns ...func(*route53.Options)) (*route53.DeleteTrafficPolicyInstanceOutput, error) {
+	instance, ok := r.trafficPolicyInstances[*input.Id]
+	if !ok {
+		return nil, &route53types.NoSuchTrafficPolicyInstance{Message: aws.String("No traffic policy instance found")}
+	}
+	delete(r.recordSets[*instance.HostedZoneId], *instance.Name+"::"+string(instance.TrafficPolicyType)+"::")
+	delete(r.trafficPolicyInstances, *input.Id)
+	return &route53.DeleteTrafficPolicyInstanceOutput{}, nil
+}
+
+// applyTrafficPolicyInstance sets the version of the traffic policy of the instance, and replaces the record set
+// of the instance by a record set with the values of the endpoints of the document.
+func (r *Route53APIStub) applyTrafficPolicyInstance(instance *route53types.TrafficPolicyInstance, id string, version int32) error {
+	get, err := r.GetTrafficPolicy(context.Background(), &route53.GetTrafficPolicyInput{Id: aws.String(id), Version: aws.Int32(version)})
+	if err != nil {
+		return err
+	}
+	recordType, values, err := parseTrafficPolicyDocument(*get.TrafficPolicy.Document)
+	if err != nil {
+		return &route53types.InvalidTrafficPolicyDocument{Message: aws.String(err.Error())}
+	}
+	if r.recordSets[*instance.HostedZoneId] == nil {
+		r.recordSets[*instance.HostedZoneId] = make(map[string][]route53types.ResourceRecordSet)
+	}
+	if instance.TrafficPolicyType != "" {
+		delete(r.recordSets[*instance.HostedZoneId], *instance.Name+"::"+string(instance.TrafficPolicyType)+"::")
+	}
+	instance.TrafficPolicyId = aws.String(id)
+	instance.TrafficPolicyVersion = aws.Int32(version)
+	instance.TrafficPolicyType = route53types.RRType(recordType)
+	recordSet := route53types.ResourceRecordSet{
+		Name:                    instance.Name,
+		Type:                    instance.TrafficPolicyType,
+		TTL:                     instance.TTL,
+		TrafficPolicyInstanceId: instance.Id,
+	}
+	for _, value := range values {
+		recordSet.ResourceRecords = append(recordSet.ResourceRecords, route53types.ResourceRecord{Value: aws.String(value)})
+	}
+	r.recordSets[*instance.HostedZoneId][*instance.Name+"::"+recordType+"::"] = []route53types.ResourceRecordSet{recordSet}
+	r.trafficPolicyInstances[*instance.Id] = *instance
+	return nil
+}
+
 type dynamicMock struct {
 	mock.Mock
 }
@@ -328,6 +686,75 @@ func TestAWSZones(t *testing.T) {
 	}
 }
 
+func TestAWSZonesWithZoneRoles(t *testing.T) {
+	provider, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+	roleClient := NewRoute53APIStub(t)
+	role := "arn:aws:iam::111:role/dns"
+	provider.clients[role] = roleClient
+	provider.zoneRoles = map[string]string{"zone-2.ext-dns-test-2.teapot.zalan.do.": role}
+	provider.zonesCache = &zonesListCache{}
+	// the zone of the role is listed by both clients, the role client lists another zone of its account
+	_, err := roleClient.CreateHostedZone(context.Background(), &route53.CreateHostedZoneInput{Name: aws.String("zone-2.ext-dns-test-2.teapot.zalan.do.")})
+	require.NoError(t, err)
+	_, err = roleClient.CreateHostedZone(context.Background(), &route53.CreateHostedZoneInput{Name: aws.String("zone-4.ext-dns-test-2.teapot.zalan.do.")})
+	require.NoError(t, err)
+
+	zones, err := provider.zones(context.Background())
+	require.NoError(t, err)
+	profiles := map[string]string{}
+	for id, zone := range zones {
+		profiles[id] = zone.profile
+	}
+	assert.Equal(t, map[string]string{
+		"/hostedzone/zone-1.ext-dns-test-2.teapot.zalan.do.": defaultAWSProfile,
+		"/hostedzone/zone-2.ext-dns-test-2.teapot.zalan.do.": role,
+		"/hostedzone/zone-3.ext-dns-test-2.teapot.zalan.do.": defaultAWSProfile,
+	}, profiles)
+}
+
+func TestAWSZonesWithZoneVPCs(t *testing.T) {
+	provider, client := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+	roleClient := NewRoute53APIStub(t)
+	role := "arn:aws:iam::222:role/dns"
+	provider.clients[role] = roleClient
+	provider.zonesCache = &zonesListCache{}
+	provider.zoneVPCs = []ZoneVPC{{Region: "eu-west-1", ID: "vpc-111"}}
+	// the private zones of the other account are listed by the role client, one of them is associated with the VPC
+	for _, name := range []string{"zone-5.ext-dns-test-2.teapot.zalan.do.", "zone-6.ext-dns-test-2.teapot.zalan.do."} {
+		_, err := roleClient.CreateHostedZone(context.Background(), &route53.CreateHostedZoneInput{
+			Name:             aws.String(name),
+			HostedZoneConfig: &route53types.HostedZoneConfig{PrivateZone: true},
+		})
+		require.NoError(t, err)
+	}
+	client.vpcZones["vpc-111"] = []route53types.HostedZoneSummary{
+		{HostedZoneId: aws.String("zone-3.ext-dns-test-2.teapot.zalan.do."), Name: aws.String("zone-3.ext-dns-test-2.teapot.zalan.do.")},
+		{
+			HostedZoneId: aws.String("zone-5.ext-dns-test-2.teapot.zalan.do."),
+			Name:         aws.String("zone-5.ext-dns-test-2.teapot.zalan.do."),
+			Owner:        &route53types.HostedZoneOwner{OwningAccount: aws.String("222")},
+		},
+	}
+
+	zones, err := provider.zones(context.Background())
+	require.NoError(t, err)
+	profiles := map[string]string{}
+	for id, zone := range zones {
+		profiles[id] = zone.profile
+	}
+	// the public zones are not filtered
+	assert.Equal(t, map[string]string{
+		"/hostedzone/zone-1.ext-dns-test-2.teapot.zalan.do.": defaultAWSProfile,
+		"/hostedzone/zone-2.ext-dns-test-2.teapot.zalan.do.": defaultAWSProfile,
+		"/hostedzone/zone-3.ext-dns-test-2.teapot.zalan.do.": defaultAWSProfile,
+		"/hostedzone/zone-5.ext-dns-test-2.teapot.zalan.do.": role,
+	}, profiles)
+
+	provider.zoneVPCs = []ZoneVPC{{Region: "eu-west-1", ID: "vpc-unknown"}}
+	_, err = provider.zones(context.Background())
+	assert.Error(t, err)
+}
+
 func TestAWSRecordsFilter(t *testing.T) {
 	provider, _ := newAWSProvider(t, endpoint.DomainFilter{}, provider.ZoneIDFilter{}, provider.ZoneTypeFilter{}, false, false, nil)
 	domainFilter := provider.GetDomainFilter()
@@ -557,6 +984,77 @@ func TestAWSRecords(t *testing.T) {
 	})
 }
 
+func TestAWSPropertyValuesEqual(t *testing.T) {
+	for _, test := range []struct {
+		evaluateTargetHealth bool
+		name                 string
+		desired              string
+		current              string
+		equal                bool
+	}{
+		{evaluateTargetHealth: true, name: providerSpecificEvaluateTargetHealth, desired: "", current: "true", equal: true},
+		{evaluateTargetHealth: true, name: providerSpecificEvaluateTargetHealth, desired: "", current: "false"},
+		{evaluateTargetHealth: false, name: providerSpecificEvaluateTargetHealth, desired: "", current: "false", equal: true},
+		{evaluateTargetHealth: false, name: providerSpecificEvaluateTargetHealth, desired: "true", current: "false"},
+		{name: providerSpecificAlias, desired: "", current: "false"},
+		{name: providerSpecificAlias, desired: "true", current: "true", equal: true},
+	} {
+		t.Run(fmt.Sprintf("%s %q %q", test.name, test.desired, test.current), func(t *testing.T) {
+			provider, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), test.evaluateTargetHealth, false, nil)
+			assert.Equal(t, test.equal, provider.PropertyValuesEqual(test.name, test.desired, test.current))
+		})
+	}
+}
+
+func TestAWSRecordsBoundedListing(t *testing.T) {
+	records := []route53types.ResourceRecordSet{
+		{Name: aws.String("zone-1.ext-dns-test-2.teapot.zalan.do."), Type: route53types.RRTypeA, TTL: aws.Int64(recordTTL), ResourceRecords: []route53types.ResourceRecord{{Value: aws.String("1.1.1.1")}}},
+		{Name: aws.String("sub.zone-1.ext-dns-test-2.teapot.zalan.do."), Type: route53types.RRTypeA, TTL: aws.Int64(recordTTL), ResourceRecords: []route53types.ResourceRecord{{Value: aws.String("2.2.2.2")}}},
+		{Name: aws.String("\\052.sub.zone-1.ext-dns-test-2.teapot.zalan.do."), Type: route53types.RRTypeA, TTL: aws.Int64(recordTTL), ResourceRecords: []route53types.ResourceRecord{{Value: aws.String("3.3.3.3")}}},
+		{Name: aws.String("_externaldns.a.sub.zone-1.ext-dns-test-2.teapot.zalan.do."), Type: route53types.RRTypeTxt, TTL: aws.Int64(recordTTL), ResourceRecords: []route53types.ResourceRecord{{Value: aws.String("\"heritage=external-dns\"")}}},
+		{Name: aws.String("sub-other.zone-1.ext-dns-test-2.teapot.zalan.do."), Type: route53types.RRTypeA, TTL: aws.Int64(recordTTL), ResourceRecords: []route53types.ResourceRecord{{Value: aws.String("4.4.4.4")}}},
+		{Name: aws.String("zzz.zone-1.ext-dns-test-2.teapot.zalan.do."), Type: route53types.RRTypeA, TTL: aws.Int64(recordTTL), ResourceRecords: []route53types.ResourceRecord{{Value: aws.String("5.5.5.5")}}},
+		{Name: aws.String("zone-2.ext-dns-test-2.teapot.zalan.do."), Type: route53types.RRTypeA, TTL: aws.Int64(recordTTL), ResourceRecords: []route53types.ResourceRecord{{Value: aws.String("6.6.6.6")}}},
+	}
+	provider, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, records)
+	provider.domainFilter = endpoint.NewDomainFilter([]string{"sub.zone-1.ext-dns-test-2.teapot.zalan.do", "a.sub.zone-1.ext-dns-test-2.teapot.zalan.do", "zone-2.ext-dns-test-2.teapot.zalan.do"})
+	provider.zoneMatchParent = true
+	provider.zonesCache = &zonesListCache{duration: 0 * time.Minute}
+	provider.boundedListing = true
+
+	endpoints, err := provider.Records(context.Background())
+	require.NoError(t, err)
+	validateEndpoints(t, provider, endpoints, []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("sub.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "2.2.2.2"),
+		endpoint.NewEndpointWithTTL("*.sub.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "3.3.3.3"),
+		endpoint.NewEndpointWithTTL("_externaldns.a.sub.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeTXT, endpoint.TTL(recordTTL), "\"heritage=external-dns\""),
+		// zone-2 is listed as a whole
+		endpoint.NewEndpointWithTTL("zone-2.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "6.6.6.6"),
+	})
+}
+
+func TestAWSListingRoots(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		bounded  bool
+		filters  []string
+		expected []string
+	}{
+		{name: "disabled", filters: []string{"sub.example.com"}, expected: []string{""}},
+		{name: "no filter", bounded: true, expected: []string{""}},
+		{name: "zone", bounded: true, filters: []string{"sub.example.com", "example.com"}, expected: []string{""}},
+		{name: "parent of the zone", bounded: true, filters: []string{"com"}, expected: []string{""}},
+		{name: "subdomains", bounded: true, filters: []string{"b.example.com", "x.a.example.com.", "A.example.com", "other.org"}, expected: []string{"a.example.com", "b.example.com"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &AWSProvider{domainFilter: endpoint.NewDomainFilter(tc.filters), boundedListing: tc.bounded}
+			roots := p.listingRoots("example.com.")
+			sort.Strings(roots)
+			assert.Equal(t, tc.expected, roots)
+		})
+	}
+}
+
 func TestAWSAdjustEndpoints(t *testing.T) {
 	provider, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
 
@@ -584,6 +1082,162 @@ func TestAWSAdjustEndpoints(t *testing.T) {
 	})
 }
 
+func TestAWSAdjustEndpointsFailoverPairs(t *testing.T) {
+	provider, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+
+	records := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("pair.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificHealthCheckID, "primary-check").
+			WithProviderSpecific(providerSpecificFailoverSecondaryTargets, "5.6.7.8, 9.9.9.9").
+			WithProviderSpecific(providerSpecificFailoverSecondaryHealthCheckID, "secondary-check"),
+		endpoint.NewEndpoint("blue.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithSetIdentifier("blue").
+			WithProviderSpecific(providerSpecificFailover, "PRIMARY").
+			WithProviderSpecific(providerSpecificFailoverSecondaryTargets, "5.6.7.8"),
+		endpoint.NewEndpoint("green.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithSetIdentifier("blue").
+			WithProviderSpecific(providerSpecificFailoverSecondaryTargets, "5.6.7.8").
+			WithProviderSpecific(providerSpecificFailoverSecondarySetIdentifier, "green"),
+		endpoint.NewEndpoint("secondary.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithSetIdentifier("secondary").
+			WithProviderSpecific(providerSpecificFailover, "SECONDARY").
+			WithProviderSpecific(providerSpecificFailoverSecondaryTargets, "5.6.7.8"),
+	}
+	records[0].Labels[endpoint.ResourceLabelKey] = "service/default/pair"
+
+	records, err := provider.AdjustEndpoints(records)
+	require.NoError(t, err)
+
+	expected := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("pair.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithSetIdentifier("primary").
+			WithProviderSpecific(providerSpecificHealthCheckID, "primary-check").
+			WithProviderSpecific(providerSpecificFailover, "PRIMARY"),
+		endpoint.NewEndpoint("pair.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "5.6.7.8", "9.9.9.9").
+			WithSetIdentifier("secondary").
+			WithProviderSpecific(providerSpecificFailover, "SECONDARY").
+			WithProviderSpecific(providerSpecificHealthCheckID, "secondary-check"),
+		endpoint.NewEndpoint("blue.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithSetIdentifier("blue").
+			WithProviderSpecific(providerSpecificFailover, "PRIMARY"),
+		endpoint.NewEndpoint("blue.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "5.6.7.8").
+			WithSetIdentifier("blue-secondary").
+			WithProviderSpecific(providerSpecificFailover, "SECONDARY"),
+		endpoint.NewEndpoint("green.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithSetIdentifier("blue").
+			WithProviderSpecific(providerSpecificFailover, "PRIMARY"),
+		endpoint.NewEndpoint("green.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "5.6.7.8").
+			WithSetIdentifier("green").
+			WithProviderSpecific(providerSpecificFailover, "SECONDARY"),
+		endpoint.NewEndpoint("secondary.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithSetIdentifier("secondary").
+			WithProviderSpecific(providerSpecificFailover, "SECONDARY"),
+	}
+	// the secondary record has the labels of the primary one
+	expected[0].Labels[endpoint.ResourceLabelKey] = "service/default/pair"
+	expected[1].Labels[endpoint.ResourceLabelKey] = "service/default/pair"
+	validateEndpoints(t, provider, records, expected)
+
+	var pair []*endpoint.Endpoint
+	for _, record := range records {
+		if record.DNSName == "pair.zone-1.ext-dns-test-2.teapot.zalan.do" {
+			pair = append(pair, record)
+		}
+	}
+
+	require.NoError(t, provider.ApplyChanges(context.Background(), &plan.Changes{
+		Create: pair,
+	}))
+	validateRecords(t, listAWSRecords(t, provider.clients[defaultAWSProfile], "/hostedzone/zone-1.ext-dns-test-2.teapot.zalan.do."), []route53types.ResourceRecordSet{
+		{
+			Name:            aws.String("pair.zone-1.ext-dns-test-2.teapot.zalan.do."),
+			Type:            route53types.RRTypeA,
+			TTL:             aws.Int64(recordTTL),
+			ResourceRecords: []route53types.ResourceRecord{{Value: aws.String("1.2.3.4")}},
+			SetIdentifier:   aws.String("primary"),
+			Failover:        route53types.ResourceRecordSetFailoverPrimary,
+			HealthCheckId:   aws.String("primary-check"),
+		},
+		{
+			Name:            aws.String("pair.zone-1.ext-dns-test-2.teapot.zalan.do."),
+			Type:            route53types.RRTypeA,
+			TTL:             aws.Int64(recordTTL),
+			ResourceRecords: []route53types.ResourceRecord{{Value: aws.String("5.6.7.8")}, {Value: aws.String("9.9.9.9")}},
+			SetIdentifier:   aws.String("secondary"),
+			Failover:        route53types.ResourceRecordSetFailoverSecondary,
+			HealthCheckId:   aws.String("secondary-check"),
+		},
+	})
+}
+
+func TestAWSAdjustEndpointsFailoverSecondaryType(t *testing.T) {
+	provider, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+
+	records := []*endpoint.Endpoint{
+		// a load balancer behind an A record is an alias A record
+		endpoint.NewEndpoint("elb.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificFailoverSecondaryTargets, "backup-123.us-east-1.elb.amazonaws.com"),
+		// a hostname behind a CNAME record is a CNAME record, even if it could be an alias
+		endpoint.NewEndpoint("cname.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "primary.example.org").
+			WithProviderSpecific(providerSpecificFailoverSecondaryTargets, "backup-123.us-east-1.elb.amazonaws.com"),
+		// IP addresses behind an alias record are an A record
+		endpoint.NewEndpoint("alias.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "primary-123.us-east-1.elb.amazonaws.com").
+			WithProviderSpecific(providerSpecificFailoverSecondaryTargets, "5.6.7.8"),
+		// a hostname other than an AWS resource can't be behind an A record
+		endpoint.NewEndpoint("host.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificHealthCheckID, "primary-check").
+			WithProviderSpecific(providerSpecificFailoverSecondaryTargets, "backup.example.org"),
+		// IP addresses can't be behind a CNAME record
+		endpoint.NewEndpoint("ip.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "primary.example.org").
+			WithProviderSpecific(providerSpecificFailoverSecondaryTargets, "5.6.7.8"),
+		// nor IPv6 addresses behind an A record, nor targets of mixed types
+		endpoint.NewEndpoint("ipv6.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificHealthCheckID, "primary-check").
+			WithProviderSpecific(providerSpecificFailoverSecondaryTargets, "2001:db8::1"),
+		endpoint.NewEndpoint("mixed.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificHealthCheckID, "primary-check").
+			WithProviderSpecific(providerSpecificFailoverSecondaryTargets, "5.6.7.8, backup-123.us-east-1.elb.amazonaws.com"),
+	}
+
+	records, err := provider.AdjustEndpoints(records)
+	require.NoError(t, err)
+
+	validateEndpoints(t, provider, records, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("elb.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithSetIdentifier("primary").
+			WithProviderSpecific(providerSpecificFailover, "PRIMARY"),
+		endpoint.NewEndpoint("cname.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "primary.example.org").
+			WithSetIdentifier("primary").
+			WithProviderSpecific(providerSpecificFailover, "PRIMARY").
+			WithProviderSpecific(providerSpecificAlias, "false"),
+		endpoint.NewEndpoint("alias.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "primary-123.us-east-1.elb.amazonaws.com").
+			WithSetIdentifier("primary").
+			WithProviderSpecific(providerSpecificFailover, "PRIMARY").
+			WithProviderSpecific(providerSpecificAlias, "true").
+			WithProviderSpecific(providerSpecificEvaluateTargetHealth, strconv.FormatBool(defaultEvaluateTargetHealth)),
+		endpoint.NewEndpoint("host.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificHealthCheckID, "primary-check"),
+		endpoint.NewEndpoint("ip.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "primary.example.org").
+			WithProviderSpecific(providerSpecificAlias, "false"),
+		endpoint.NewEndpoint("ipv6.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificHealthCheckID, "primary-check"),
+		endpoint.NewEndpoint("mixed.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificHealthCheckID, "primary-check"),
+		endpoint.NewEndpoint("elb.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "backup-123.us-east-1.elb.amazonaws.com").
+			WithSetIdentifier("secondary").
+			WithProviderSpecific(providerSpecificFailover, "SECONDARY").
+			WithProviderSpecific(providerSpecificAlias, "true").
+			WithProviderSpecific(providerSpecificEvaluateTargetHealth, strconv.FormatBool(defaultEvaluateTargetHealth)),
+		endpoint.NewEndpoint("cname.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "backup-123.us-east-1.elb.amazonaws.com").
+			WithSetIdentifier("secondary").
+			WithProviderSpecific(providerSpecificFailover, "SECONDARY").
+			WithProviderSpecific(providerSpecificAlias, "false"),
+		endpoint.NewEndpoint("alias.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "5.6.7.8").
+			WithSetIdentifier("secondary").
+			WithProviderSpecific(providerSpecificFailover, "SECONDARY"),
+	})
+}
+
 func TestAWSApplyChanges(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -1313,6 +1967,87 @@ func TestAWSsubmitChangesRetryOnError(t *testing.T) {
 	require.True(t, containsRecordWithDNSName(records, "fail__edns_housekeeping.zone-1.ext-dns-test-2.teapot.zalan.do"))
 }
 
+func TestAWSsubmitChangesSplitsBatchTooLarge(t *testing.T) {
+	provider, clientStub := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+	// Route53 rejects the batches of more than 3 changes, although they are within the configured batch size
+	clientStub.MockMethod("ChangeResourceRecordSets", mock.MatchedBy(func(input *route53.ChangeResourceRecordSetsInput) bool {
+		return len(input.ChangeBatch.Changes) > 3
+	})).Return(nil, &route53types.InvalidChangeBatch{Message: aws.String("Number of records limit of 3 exceeded.")})
+
+	ctx := context.Background()
+	zones, err := provider.zones(ctx)
+	require.NoError(t, err)
+
+	var endpoints []*endpoint.Endpoint
+	for i := 0; i < 10; i++ {
+		endpoints = append(endpoints, endpoint.NewEndpointWithTTL(fmt.Sprintf("host%d.zone-1.ext-dns-test-2.teapot.zalan.do", i), endpoint.RecordTypeA, endpoint.TTL(recordTTL), "1.0.0.1"))
+	}
+	require.NoError(t, provider.submitChanges(ctx, provider.newChanges(route53types.ChangeActionCreate, endpoints), zones))
+	assert.Empty(t, provider.failedChangesQueue["/hostedzone/zone-1.ext-dns-test-2.teapot.zalan.do."])
+
+	records, err := provider.Records(ctx)
+	require.NoError(t, err)
+	validateEndpoints(t, provider, records, endpoints)
+}
+
+func TestAWSsubmitChangesInvalidatesZonesCacheOnNoSuchHostedZone(t *testing.T) {
+	provider, clientStub := newAWSProviderWithTagFilter(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), provider.NewZoneTagFilter([]string{}), defaultEvaluateTargetHealth, false, nil)
+	clientStub.MockMethod("ChangeResourceRecordSets", mock.Anything).Return(nil, &route53types.NoSuchHostedZone{Message: aws.String("No hosted zone found with ID")})
+
+	ctx := context.Background()
+	zones, err := provider.zones(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, provider.zonesCache.zones)
+
+	ep1 := endpoint.NewEndpointWithTTL("a.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "1.0.0.1")
+	ep2 := endpoint.NewEndpointWithTTL("b.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "1.0.0.2")
+	require.Error(t, provider.submitChanges(ctx, provider.newChanges(route53types.ChangeActionCreate, []*endpoint.Endpoint{ep1, ep2}), zones))
+
+	// the changes are not retried one by one nor queued, and the zones are listed again
+	assert.Nil(t, provider.zonesCache.zones)
+	assert.Empty(t, provider.failedChangesQueue["/hostedzone/zone-1.ext-dns-test-2.teapot.zalan.do."])
+	_, err = provider.zones(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, provider.zonesCache.zones)
+}
+
+func TestIsNoSuchHostedZone(t *testing.T) {
+	assert.True(t, isNoSuchHostedZone(fmt.Errorf("operation error: %w", &route53types.NoSuchHostedZone{})))
+	assert.False(t, isNoSuchHostedZone(&route53types.InvalidChangeBatch{}))
+	assert.False(t, isNoSuchHostedZone(fmt.Errorf("Mock route53 failure")))
+}
+
+func TestIsChangeBatchTooLarge(t *testing.T) {
+	assert.True(t, isChangeBatchTooLarge(&route53types.InvalidChangeBatch{Message: aws.String("Number of records limit of 1000 exceeded.")}))
+	assert.True(t, isChangeBatchTooLarge(fmt.Errorf("operation error: %w", &route53types.InvalidChangeBatch{Message: aws.String("Number of characters limit of 32000 exceeded")})))
+	assert.True(t, isChangeBatchTooLarge(&smithy.GenericAPIError{Code: "RequestEntityTooLarge"}))
+	assert.False(t, isChangeBatchTooLarge(&route53types.InvalidChangeBatch{Message: aws.String("Tried to create resource record set but it already exists")}))
+	assert.False(t, isChangeBatchTooLarge(fmt.Errorf("Mock route53 failure")))
+}
+
+func TestSplitChangeBatch(t *testing.T) {
+	change := func(name string, rrType route53types.RRType) *Route53Change {
+		return &Route53Change{Change: route53types.Change{
+			Action:            route53types.ChangeActionCreate,
+			ResourceRecordSet: &route53types.ResourceRecordSet{Name: aws.String(name), Type: rrType},
+		}}
+	}
+	cs := Route53Changes{
+		change("a", route53types.RRTypeA), change("a", route53types.RRTypeTxt),
+		change("b", route53types.RRTypeA),
+		change("c", route53types.RRTypeA), change("c", route53types.RRTypeTxt),
+	}
+
+	first, second := splitChangeBatch(groupChangesByNameAndOwnershipRelation(cs))
+	assert.Equal(t, Route53Changes{cs[0], cs[1]}, first)
+	assert.Equal(t, Route53Changes{cs[2], cs[3], cs[4]}, second)
+
+	// the first batch gets at least one group
+	first, second = splitChangeBatch(groupChangesByNameAndOwnershipRelation(Route53Changes{cs[0], cs[1], cs[2]}))
+	assert.Equal(t, Route53Changes{cs[0], cs[1]}, first)
+	assert.Equal(t, Route53Changes{cs[2]}, second)
+}
+
 func TestAWSBatchChangeSet(t *testing.T) {
 	var cs Route53Changes
 
@@ -1800,6 +2535,58 @@ func TestAWSCreateRecordsWithALIAS(t *testing.T) {
 	}
 }
 
+func TestAWSDualstackALIAS(t *testing.T) {
+	aliasTarget := &route53types.AliasTarget{
+		DNSName:              aws.String("bar.eu-central-1.elb.amazonaws.com."),
+		EvaluateTargetHealth: false,
+		HostedZoneId:         aws.String("Z215JYRZR1TBD5"),
+	}
+	provider, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), false, false, []route53types.ResourceRecordSet{
+		{Name: aws.String("dualstack.zone-1.ext-dns-test-2.teapot.zalan.do."), Type: route53types.RRTypeA, AliasTarget: aliasTarget},
+		{Name: aws.String("dualstack.zone-1.ext-dns-test-2.teapot.zalan.do."), Type: route53types.RRTypeAaaa, AliasTarget: aliasTarget},
+		{Name: aws.String("aaaa-only.zone-1.ext-dns-test-2.teapot.zalan.do."), Type: route53types.RRTypeAaaa, AliasTarget: aliasTarget},
+	})
+
+	records, err := provider.Records(context.Background())
+	require.NoError(t, err)
+	dualstack := endpoint.NewEndpointWithTTL("dualstack.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "bar.eu-central-1.elb.amazonaws.com").
+		WithProviderSpecific(providerSpecificEvaluateTargetHealth, "false").
+		WithProviderSpecific(providerSpecificAlias, "true").
+		WithProviderSpecific(providerSpecificDualstack, "true")
+	validateEndpoints(t, provider, records, []*endpoint.Endpoint{
+		dualstack,
+		endpoint.NewEndpointWithTTL("aaaa-only.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "bar.eu-central-1.elb.amazonaws.com").
+			WithProviderSpecific(providerSpecificEvaluateTargetHealth, "false").
+			WithProviderSpecific(providerSpecificAlias, "true").
+			WithProviderSpecific(providerSpecificDualstack, "true"),
+	})
+
+	// the dualstack label of the sources sets the property
+	labeled := endpoint.NewEndpointWithTTL("dualstack.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, endpoint.TTL(recordTTL), "bar.eu-central-1.elb.amazonaws.com")
+	labeled.Labels[endpoint.DualstackLabelKey] = "true"
+	adjusted, err := provider.AdjustEndpoints([]*endpoint.Endpoint{labeled})
+	require.NoError(t, err)
+	assert.True(t, testutils.SameEndpoints([]*endpoint.Endpoint{dualstack}, adjusted), "%+v", adjusted)
+
+	// a record no longer dualstack loses its AAAA ALIAS record
+	single, err := provider.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("dualstack.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "bar.eu-central-1.elb.amazonaws.com"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, provider.ApplyChanges(context.Background(), &plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{dualstack},
+		UpdateNew: single,
+	}))
+	recordSets := listAWSRecords(t, provider.clients[defaultAWSProfile], "/hostedzone/zone-1.ext-dns-test-2.teapot.zalan.do.")
+	var types []route53types.RRType
+	for _, recordSet := range recordSets {
+		if *recordSet.Name == "dualstack.zone-1.ext-dns-test-2.teapot.zalan.do." {
+			types = append(types, recordSet.Type)
+		}
+	}
+	assert.Equal(t, []route53types.RRType{route53types.RRTypeA}, types)
+}
+
 func TestAWSisLoadBalancer(t *testing.T) {
 	for _, tc := range []struct {
 		target      string
@@ -1949,6 +2736,33 @@ func listAWSRecords(t *testing.T, client Route53API, zone string) []route53types
 	return resp.ResourceRecordSets
 }
 
+func TestAWSRecordsZoneScope(t *testing.T) {
+	p, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), false, false, []route53types.ResourceRecordSet{
+		{
+			Name:            aws.String("list-test.zone-1.ext-dns-test-2.teapot.zalan.do."),
+			Type:            route53types.RRTypeA,
+			TTL:             aws.Int64(recordTTL),
+			ResourceRecords: []route53types.ResourceRecord{{Value: aws.String("1.2.3.4")}},
+		},
+		{
+			Name:            aws.String("list-test.zone-2.ext-dns-test-2.teapot.zalan.do."),
+			Type:            route53types.RRTypeA,
+			TTL:             aws.Int64(recordTTL),
+			ResourceRecords: []route53types.ResourceRecord{{Value: aws.String("8.8.8.8")}},
+		},
+	})
+
+	zones, err := p.ZoneNames(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zone-1.ext-dns-test-2.teapot.zalan.do", "zone-2.ext-dns-test-2.teapot.zalan.do", "zone-3.ext-dns-test-2.teapot.zalan.do"}, zones)
+
+	records, err := p.Records(context.WithValue(context.Background(), provider.ZoneScopeContextKey, "zone-2.ext-dns-test-2.teapot.zalan.do"))
+	require.NoError(t, err)
+	validateEndpoints(t, p, records, []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("list-test.zone-2.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "8.8.8.8"),
+	})
+}
+
 func newAWSProvider(t *testing.T, domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, zoneTypeFilter provider.ZoneTypeFilter, evaluateTargetHealth, dryRun bool, records []route53types.ResourceRecordSet) (*AWSProvider, *Route53APIStub) {
 	return newAWSProviderWithTagFilter(t, domainFilter, zoneIDFilter, zoneTypeFilter, provider.NewZoneTagFilter([]string{}), evaluateTargetHealth, dryRun, records)
 }
@@ -2102,6 +2916,16 @@ func TestConvertOctalToAscii(t *testing.T) {
 			input:    "txt-awesome-test3.example.com",
 			expected: "txt-awesome-test3.example.com",
 		},
+		{
+			name:     "Wildcards escaped",
+			input:    "\\052.\\052.example.com",
+			expected: "*.*.example.com",
+		},
+		{
+			name:     "Double quote and backslash escaped",
+			input:    "a\\042b\\134c.example.com",
+			expected: "a\"b\\c.example.com",
+		},
 	}
 
 	for _, tt := range tests {
@@ -2111,3 +2935,40 @@ func TestConvertOctalToAscii(t *testing.T) {
 		})
 	}
 }
+
+func TestAWSGroupChangesOfEscapedNames(t *testing.T) {
+	p := &AWSProvider{}
+	txt := endpoint.NewEndpoint("a-*.example.com", endpoint.RecordTypeTXT, "heritage=external-dns")
+	txt.Labels[endpoint.OwnedRecordLabelKey] = "*.example.com"
+	changes := p.newChanges(route53types.ChangeActionCreate, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("*.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+		txt,
+	})
+
+	groups := groupChangesByNameAndOwnershipRelation(changes)
+	assert.Len(t, groups, 1)
+	assert.Len(t, groups["\\052.example.com"], 2)
+}
+
+func TestAWSNewChangeEscapesName(t *testing.T) {
+	p := &AWSProvider{}
+	for name, expected := range map[string]string{
+		"app.example.com":   "app.example.com",
+		"*.example.com":     "\\052.example.com",
+		"a&b@c.example.com": "a\\046b\\100c.example.com",
+	} {
+		change, _ := p.newChange(route53types.ChangeActionCreate, endpoint.NewEndpoint(name, endpoint.RecordTypeA, "1.2.3.4"))
+		assert.Equal(t, expected, *change.ResourceRecordSet.Name)
+		assert.Equal(t, name, convertOctalToAscii(*change.ResourceRecordSet.Name))
+	}
+}
+
+func TestAWSNewChangeChunksLongTXT(t *testing.T) {
+	p := &AWSProvider{}
+	key := "v=DKIM1; k=rsa; p=" + strings.Repeat("A", 392)
+
+	change, _ := p.newChange(route53types.ChangeActionCreate, endpoint.NewEndpoint("dkim._domainkey.example.com", endpoint.RecordTypeTXT, key, "v=spf1 -all"))
+	require.Len(t, change.ResourceRecordSet.ResourceRecords, 2)
+	assert.Equal(t, `"`+key[:255]+`" "`+key[255:]+`"`, *change.ResourceRecordSet.ResourceRecords[0].Value)
+	assert.Equal(t, "v=spf1 -all", *change.ResourceRecordSet.ResourceRecords[1].Value)
+}
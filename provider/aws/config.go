@@ -18,19 +18,33 @@ package aws
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"maps"
 	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
 	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	"github.com/aws/aws-sdk-go-v2/config"
 	stscredsv2 "github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/aws/smithy-go/middleware"
 	"github.com/linki/instrumented_http"
 	"github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/provider"
 )
 
 // AWSSessionConfig contains configuration to create a new AWS provider.
@@ -39,14 +53,104 @@ type AWSSessionConfig struct {
 	AssumeRoleExternalID string
 	APIRetries           int
 	Profile              string
+	// AssumeRoleSessionDuration, AssumeRoleSessionName and AssumeRoleSessionTags are the duration, the name and the
+	// tags of the sessions of the assumed role, e.g. for the attribute-based access control of its policies. The SDK
+	// defaults to sessions of 15 minutes with a generated name, without tags.
+	AssumeRoleSessionDuration time.Duration
+	AssumeRoleSessionName     string
+	AssumeRoleSessionTags     map[string]string
+	// AssumeRoleSourceIdentity is the source identity of the sessions of the assumed roles, attributing the actions
+	// of the sessions in CloudTrail, which is kept by the sessions of the roles assumed with their credentials.
+	AssumeRoleSourceIdentity string
+	// AssumeRoleChain are the roles assumed one after the other, each with the credentials of the previous one,
+	// before assuming AssumeRole with the credentials of the last one, e.g. to hop through an intermediate account.
+	AssumeRoleChain []string
+	// CredentialsRefreshInterval is the interval the credentials are re-loaded at, e.g. from a rotated
+	// credentials file. If zero, the credentials are loaded once, unless they expire.
+	CredentialsRefreshInterval time.Duration
+	// EndpointURL is the URL of the STS API replacing the one resolved from the region, e.g. of an emulator.
+	EndpointURL string
+	// Partition is the AWS partition of the region, which defaults to the main region of the partition.
+	Partition string
+	// ProxyURL is the URL of the HTTP proxy of the requests, which defaults to the proxy of the environment.
+	ProxyURL string
+	// CABundle is a PEM file of the CA certificates trusted in addition to the system ones.
+	CABundle string
+	// RateLimiter paces the attempts of the requests, which are retried up to APIRetries times. If nil,
+	// the requests are not paced.
+	RateLimiter *AdaptiveRateLimiter
+}
+
+var (
+	sharedRateLimiter     *AdaptiveRateLimiter
+	sharedRateLimiterOnce sync.Once
+)
+
+// apiRateLimiter returns the rate limiter shared by the AWS configs, so that the requests with all the credentials
+// are paced together.
+func apiRateLimiter(cfg *externaldns.Config) *AdaptiveRateLimiter {
+	sharedRateLimiterOnce.Do(func() {
+		limits, err := ParseAPIRateLimits(cfg.AWSAPIRateLimits)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		sharedRateLimiter = NewAdaptiveRateLimiter(limits, cfg.AWSAPIDefaultRateLimit)
+	})
+	return sharedRateLimiter
+}
+
+// partitionRegions are the default regions of the AWS partitions, where the Route53 API of the partition is served.
+var partitionRegions = map[string]string{
+	"aws":        "us-east-1",
+	"aws-cn":     "cn-northwest-1",
+	"aws-us-gov": "us-gov-west-1",
+	"aws-iso":    "us-iso-east-1",
+	"aws-iso-b":  "us-isob-east-1",
+}
+
+// regionPartition returns the AWS partition of the region.
+func regionPartition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	case strings.HasPrefix(region, "us-isob-"):
+		return "aws-iso-b"
+	case strings.HasPrefix(region, "us-iso-"):
+		return "aws-iso"
+	default:
+		return "aws"
+	}
+}
+
+// Route53Options returns the options of the Route53 clients, using the endpoint URL if not empty.
+func Route53Options(endpointURL string) []func(*route53.Options) {
+	if endpointURL == "" {
+		return nil
+	}
+	return []func(*route53.Options){func(o *route53.Options) {
+		o.BaseEndpoint = awsv2.String(endpointURL)
+	}}
 }
 
 func CreateDefaultV2Config(cfg *externaldns.Config) awsv2.Config {
 	result, err := newV2Config(
 		AWSSessionConfig{
-			AssumeRole:           cfg.AWSAssumeRole,
-			AssumeRoleExternalID: cfg.AWSAssumeRoleExternalID,
-			APIRetries:           cfg.AWSAPIRetries,
+			AssumeRole:                 cfg.AWSAssumeRole,
+			AssumeRoleExternalID:       cfg.AWSAssumeRoleExternalID,
+			AssumeRoleSessionDuration:  cfg.AWSAssumeRoleSessionDuration,
+			AssumeRoleSessionName:      sessionName(cfg),
+			AssumeRoleSessionTags:      sessionTags(cfg),
+			AssumeRoleSourceIdentity:   cfg.AWSAssumeRoleSourceIdentity,
+			AssumeRoleChain:            roleChain(cfg),
+			APIRetries:                 cfg.AWSAPIRetries,
+			RateLimiter:                apiRateLimiter(cfg),
+			CredentialsRefreshInterval: cfg.AWSCredentialsRefreshInterval,
+			EndpointURL:                cfg.AWSEndpointURL,
+			Partition:                  cfg.AWSPartition,
+			ProxyURL:                   cfg.AWSProxyURL,
+			CABundle:                   cfg.AWSCABundle,
 		},
 	)
 	if err != nil {
@@ -55,19 +159,207 @@ func CreateDefaultV2Config(cfg *externaldns.Config) awsv2.Config {
 	return result
 }
 
+// ParseZoneRoles parses the IAM roles managing hosted zones of the comma-separated lists of
+// zone ID=role ARN pairs, e.g. "Z123=arn:aws:iam::111:role/dns,Z456=arn:aws:iam::222:role/dns".
+func ParseZoneRoles(specs []string) (map[string]string, error) {
+	roles := map[string]string{}
+	for _, spec := range specs {
+		for _, pair := range strings.Split(spec, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			zoneID, role, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			zoneID = cleanZoneID(zoneID)
+			if !ok || zoneID == "" || !strings.HasPrefix(role, "arn:") {
+				return nil, fmt.Errorf("invalid zone role %q, expected <zone ID>=<role ARN>", pair)
+			}
+			if previous, ok := roles[zoneID]; ok && previous != role {
+				return nil, fmt.Errorf("conflicting roles %q and %q for zone %s", previous, role, zoneID)
+			}
+			roles[zoneID] = role
+		}
+	}
+	return roles, nil
+}
+
+// ParseProfileRoles parses the IAM roles assumed with the credentials of AWS profiles of the comma-separated lists
+// of profile=role ARN pairs, e.g. "prod=arn:aws:iam::111:role/dns,staging=arn:aws:iam::222:role/dns".
+func ParseProfileRoles(specs []string) (map[string]string, error) {
+	roles := map[string]string{}
+	for _, spec := range specs {
+		for _, pair := range strings.Split(spec, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			profile, role, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			profile = strings.TrimSpace(profile)
+			if !ok || profile == "" || !strings.HasPrefix(role, "arn:") {
+				return nil, fmt.Errorf("invalid profile role %q, expected <profile>=<role ARN>", pair)
+			}
+			if previous, ok := roles[profile]; ok && previous != role {
+				return nil, fmt.Errorf("conflicting roles %q and %q for profile %s", previous, role, profile)
+			}
+			roles[profile] = role
+		}
+	}
+	return roles, nil
+}
+
+// ParseSessionTags parses the tags of the sessions of the assumed roles of the comma-separated lists of key=value
+// pairs, e.g. "team=dns,cluster=prod".
+func ParseSessionTags(specs []string) (map[string]string, error) {
+	tags := map[string]string{}
+	for _, spec := range specs {
+		for _, pair := range strings.Split(spec, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || key == "" {
+				return nil, fmt.Errorf("invalid session tag %q, expected <key>=<value>", pair)
+			}
+			if previous, ok := tags[key]; ok && previous != value {
+				return nil, fmt.Errorf("conflicting values %q and %q for session tag %s", previous, value, key)
+			}
+			tags[key] = value
+		}
+	}
+	return tags, nil
+}
+
+// sessionTags returns the tags of the sessions of the assumed roles of the configuration.
+func sessionTags(cfg *externaldns.Config) map[string]string {
+	tags, err := ParseSessionTags(cfg.AWSAssumeRoleSessionTags)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	return tags
+}
+
+// ParseRoleChain parses the IAM roles assumed one after the other of the comma-separated lists of role ARNs,
+// e.g. "arn:aws:iam::111:role/hop,arn:aws:iam::222:role/dns".
+func ParseRoleChain(specs []string) ([]string, error) {
+	var roles []string
+	for _, spec := range specs {
+		for _, role := range strings.Split(spec, ",") {
+			role = strings.TrimSpace(role)
+			if role == "" {
+				continue
+			}
+			if !strings.HasPrefix(role, "arn:") {
+				return nil, fmt.Errorf("invalid role %q of the role chain, expected a role ARN", role)
+			}
+			roles = append(roles, role)
+		}
+	}
+	return roles, nil
+}
+
+// roleChain returns the roles assumed one after the other of the configuration.
+func roleChain(cfg *externaldns.Config) []string {
+	roles, err := ParseRoleChain(cfg.AWSAssumeRoleChain)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	return roles
+}
+
+// invalidSessionNameChars matches the characters not allowed by STS in the names of the role sessions.
+var invalidSessionNameChars = regexp.MustCompile(`[^\w+=,.@-]`)
+
+// ExpandSessionName returns the name of the role sessions of the template, replacing the placeholders {cluster} and
+// {owner} with the cluster name and the owner ID of the instance, and {pod} with its hostname. The characters of the
+// values not allowed in session names are replaced with "-", and the name is truncated to the 64 characters allowed.
+func ExpandSessionName(template, cluster, ownerID, pod string) string {
+	sanitize := func(value string) string { return invalidSessionNameChars.ReplaceAllString(value, "-") }
+	name := strings.NewReplacer("{cluster}", sanitize(cluster), "{owner}", sanitize(ownerID), "{pod}", sanitize(pod)).Replace(template)
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// sessionName returns the name of the role sessions of the configuration, expanding its placeholders.
+func sessionName(cfg *externaldns.Config) string {
+	if cfg.AWSAssumeRoleSessionName == "" {
+		return ""
+	}
+	cluster := cfg.ClusterName
+	if cluster == "" {
+		cluster = cfg.GSLBCluster
+	}
+	pod, _ := os.Hostname()
+	return ExpandSessionName(cfg.AWSAssumeRoleSessionName, cluster, cfg.TXTOwnerID, pod)
+}
+
+// ZoneVPC is a VPC the private hosted zones must be associated with. An empty region stands for the region of
+// the client listing the zones associated with the VPC.
+type ZoneVPC struct {
+	Region string
+	ID     string
+}
+
+// ParseZoneVPCs parses the comma-separated lists of VPC IDs, optionally prefixed with the region of the VPC,
+// e.g. "vpc-111,eu-west-1/vpc-222", or auto for the VPC of the instance, which ResolveZoneVPCs looks up.
+func ParseZoneVPCs(specs []string) ([]ZoneVPC, error) {
+	var vpcs []ZoneVPC
+	for _, spec := range specs {
+		for _, item := range strings.Split(spec, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			if item == ZoneVPCAuto {
+				vpcs = append(vpcs, ZoneVPC{ID: ZoneVPCAuto})
+				continue
+			}
+			vpc := ZoneVPC{ID: item}
+			if region, id, ok := strings.Cut(item, "/"); ok {
+				vpc = ZoneVPC{Region: region, ID: id}
+			}
+			if !strings.HasPrefix(vpc.ID, "vpc-") || (strings.Contains(item, "/") && vpc.Region == "") {
+				return nil, fmt.Errorf("invalid VPC %q, expected [<region>/]<VPC ID> or %s", item, ZoneVPCAuto)
+			}
+			vpcs = append(vpcs, vpc)
+		}
+	}
+	return vpcs, nil
+}
+
+// CreateV2Configs returns the configs of the AWS profiles, and of the roles managing hosted zones keyed by their ARN.
+// A profile assumes its role of --aws-profile-role, if any, and the role of --aws-assume-role otherwise.
 func CreateV2Configs(cfg *externaldns.Config) map[string]awsv2.Config {
 	result := make(map[string]awsv2.Config)
 	if len(cfg.AWSProfiles) == 0 || (len(cfg.AWSProfiles) == 1 && cfg.AWSProfiles[0] == "") {
 		cfg := CreateDefaultV2Config(cfg)
 		result[defaultAWSProfile] = cfg
 	} else {
+		profileRoles, err := ParseProfileRoles(cfg.AWSProfileRoles)
+		if err != nil {
+			logrus.Fatal(err)
+		}
 		for _, profile := range cfg.AWSProfiles {
+			role := cfg.AWSAssumeRole
+			if profileRole, ok := profileRoles[profile]; ok {
+				role = profileRole
+			}
 			cfg, err := newV2Config(
 				AWSSessionConfig{
-					AssumeRole:           cfg.AWSAssumeRole,
-					AssumeRoleExternalID: cfg.AWSAssumeRoleExternalID,
-					APIRetries:           cfg.AWSAPIRetries,
-					Profile:              profile,
+					AssumeRole:                 role,
+					AssumeRoleExternalID:       cfg.AWSAssumeRoleExternalID,
+					AssumeRoleSessionDuration:  cfg.AWSAssumeRoleSessionDuration,
+					AssumeRoleSessionName:      sessionName(cfg),
+					AssumeRoleSessionTags:      sessionTags(cfg),
+					AssumeRoleSourceIdentity:   cfg.AWSAssumeRoleSourceIdentity,
+					AssumeRoleChain:            roleChain(cfg),
+					APIRetries:                 cfg.AWSAPIRetries,
+					RateLimiter:                apiRateLimiter(cfg),
+					Profile:                    profile,
+					CredentialsRefreshInterval: cfg.AWSCredentialsRefreshInterval,
+					EndpointURL:                cfg.AWSEndpointURL,
+					Partition:                  cfg.AWSPartition,
+					ProxyURL:                   cfg.AWSProxyURL,
+					CABundle:                   cfg.AWSCABundle,
 				},
 			)
 			if err != nil {
@@ -76,21 +368,131 @@ func CreateV2Configs(cfg *externaldns.Config) map[string]awsv2.Config {
 			result[profile] = cfg
 		}
 	}
+
+	zoneRoles, err := ParseZoneRoles(cfg.AWSZoneRoles)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	for _, role := range zoneRoles {
+		if _, ok := result[role]; ok {
+			continue
+		}
+		roleCfg, err := newV2Config(
+			AWSSessionConfig{
+				AssumeRole:                 role,
+				AssumeRoleExternalID:       cfg.AWSAssumeRoleExternalID,
+				AssumeRoleSessionDuration:  cfg.AWSAssumeRoleSessionDuration,
+				AssumeRoleSessionName:      sessionName(cfg),
+				AssumeRoleSessionTags:      sessionTags(cfg),
+				AssumeRoleSourceIdentity:   cfg.AWSAssumeRoleSourceIdentity,
+				AssumeRoleChain:            roleChain(cfg),
+				APIRetries:                 cfg.AWSAPIRetries,
+				RateLimiter:                apiRateLimiter(cfg),
+				CredentialsRefreshInterval: cfg.AWSCredentialsRefreshInterval,
+				EndpointURL:                cfg.AWSEndpointURL,
+				Partition:                  cfg.AWSPartition,
+				ProxyURL:                   cfg.AWSProxyURL,
+				CABundle:                   cfg.AWSCABundle,
+			},
+		)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		result[role] = roleCfg
+	}
 	return result
 }
 
+// reloadingCredentials is a credentials provider re-loading the credentials, e.g. from a credentials file mounted
+// from a Secret, when they expire after the refresh interval at the latest, so that rotated credentials are used
+// without a restart.
+type reloadingCredentials struct {
+	profile  string
+	interval time.Duration
+}
+
+func (c *reloadingCredentials) Retrieve(ctx context.Context) (awsv2.Credentials, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithSharedConfigProfile(c.profile))
+	if err != nil {
+		return awsv2.Credentials{}, fmt.Errorf("re-loading AWS credentials: %w", err)
+	}
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return awsv2.Credentials{}, fmt.Errorf("re-loading AWS credentials: %w", err)
+	}
+	if expires := time.Now().Add(c.interval); !creds.CanExpire || creds.Expires.After(expires) {
+		creds.CanExpire = true
+		creds.Expires = expires
+	}
+	logrus.Debugf("Re-loaded the AWS credentials of %s", creds.Source)
+	return creds, nil
+}
+
+// userAgentOptions add ExternalDNS and the cluster name and owner ID attributing the requests to the User-Agent of the AWS SDK.
+func userAgentOptions() []func(*middleware.Stack) error {
+	options := []func(*middleware.Stack) error{awsmiddleware.AddUserAgentKeyValue("ExternalDNS", externaldns.Version)}
+	cluster, ownerID := provider.UserAgentAttribution()
+	if cluster != "" {
+		options = append(options, awsmiddleware.AddUserAgentKeyValue("cluster", cluster))
+	}
+	if ownerID != "" {
+		options = append(options, awsmiddleware.AddUserAgentKeyValue("owner", ownerID))
+	}
+	return options
+}
+
+// newTransport returns the transport of the requests through the proxy of the URL, trusting the CA certificates of
+// the bundle in addition to the system ones, or nil for the default transport when neither is set.
+func newTransport(proxyURL, caBundle string) (http.RoundTripper, error) {
+	if proxyURL == "" && caBundle == "" {
+		return nil, nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %q: %w", proxyURL, err)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+	if caBundle != "" {
+		pem, err := os.ReadFile(caBundle)
+		if err != nil {
+			return nil, fmt.Errorf("reading the CA bundle: %w", err)
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate in the CA bundle %s", caBundle)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	}
+	return transport, nil
+}
+
 func newV2Config(awsConfig AWSSessionConfig) (awsv2.Config, error) {
+	transport, err := newTransport(awsConfig.ProxyURL, awsConfig.CABundle)
+	if err != nil {
+		return awsv2.Config{}, err
+	}
 	defaultOpts := []func(*config.LoadOptions) error{
 		config.WithRetryer(func() awsv2.Retryer {
-			return retry.AddWithMaxAttempts(retry.NewStandard(), awsConfig.APIRetries)
+			retryer := retry.AddWithMaxAttempts(retry.NewStandard(), awsConfig.APIRetries)
+			if awsConfig.RateLimiter != nil {
+				return awsConfig.RateLimiter.Retryer(retryer)
+			}
+			return retryer
 		}),
-		config.WithHTTPClient(instrumented_http.NewClient(&http.Client{}, &instrumented_http.Callbacks{
+		config.WithHTTPClient(instrumented_http.NewClient(&http.Client{Transport: provider.NewAPICallCounter(transport)}, &instrumented_http.Callbacks{
 			PathProcessor: func(path string) string {
 				parts := strings.Split(path, "/")
 				return parts[len(parts)-1]
 			},
 		})),
 		config.WithSharedConfigProfile(awsConfig.Profile),
+		config.WithAPIOptions(userAgentOptions()),
 	}
 
 	cfg, err := config.LoadDefaultConfig(context.Background(), defaultOpts...)
@@ -98,8 +500,51 @@ func newV2Config(awsConfig AWSSessionConfig) (awsv2.Config, error) {
 		return awsv2.Config{}, fmt.Errorf("instantiating AWS config: %w", err)
 	}
 
+	if awsConfig.Partition != "" {
+		if cfg.Region == "" {
+			cfg.Region = partitionRegions[awsConfig.Partition]
+		} else if partition := regionPartition(cfg.Region); partition != awsConfig.Partition {
+			return awsv2.Config{}, fmt.Errorf("the region %s is in the partition %s, not %s", cfg.Region, partition, awsConfig.Partition)
+		}
+	}
+
+	if awsConfig.CredentialsRefreshInterval > 0 {
+		logrus.Infof("Re-loading the AWS credentials every %s", awsConfig.CredentialsRefreshInterval)
+		cfg.Credentials = awsv2.NewCredentialsCache(&reloadingCredentials{
+			profile:  awsConfig.Profile,
+			interval: awsConfig.CredentialsRefreshInterval,
+		})
+	}
+
+	// the STS client assumes a role with the credentials of the config when it is created
+	stsClient := func() *sts.Client {
+		return sts.NewFromConfig(cfg, func(o *sts.Options) {
+			if awsConfig.EndpointURL != "" {
+				o.BaseEndpoint = awsv2.String(awsConfig.EndpointURL)
+			}
+		})
+	}
+	sessionOpts := func(opts *stscredsv2.AssumeRoleOptions) {
+		if awsConfig.AssumeRoleSessionDuration > 0 {
+			opts.Duration = awsConfig.AssumeRoleSessionDuration
+		}
+		if awsConfig.AssumeRoleSessionName != "" {
+			opts.RoleSessionName = awsConfig.AssumeRoleSessionName
+		}
+		if awsConfig.AssumeRoleSourceIdentity != "" {
+			opts.SourceIdentity = awsv2.String(awsConfig.AssumeRoleSourceIdentity)
+		}
+		for _, key := range slices.Sorted(maps.Keys(awsConfig.AssumeRoleSessionTags)) {
+			opts.Tags = append(opts.Tags, ststypes.Tag{Key: awsv2.String(key), Value: awsv2.String(awsConfig.AssumeRoleSessionTags[key])})
+		}
+	}
+
+	for _, role := range awsConfig.AssumeRoleChain {
+		logrus.Infof("Assuming role of the role chain: %s", role)
+		cfg.Credentials = awsv2.NewCredentialsCache(stscredsv2.NewAssumeRoleProvider(stsClient(), role, sessionOpts))
+	}
+
 	if awsConfig.AssumeRole != "" {
-		stsSvc := sts.NewFromConfig(cfg)
 		var assumeRoleOpts []func(*stscredsv2.AssumeRoleOptions)
 		if awsConfig.AssumeRoleExternalID != "" {
 			logrus.Infof("Assuming role: %s with external id %s", awsConfig.AssumeRole, awsConfig.AssumeRoleExternalID)
@@ -111,7 +556,8 @@ func newV2Config(awsConfig AWSSessionConfig) (awsv2.Config, error) {
 		} else {
 			logrus.Infof("Assuming role: %s", awsConfig.AssumeRole)
 		}
-		creds := stscredsv2.NewAssumeRoleProvider(stsSvc, awsConfig.AssumeRole, assumeRoleOpts...)
+		assumeRoleOpts = append(assumeRoleOpts, sessionOpts)
+		creds := stscredsv2.NewAssumeRoleProvider(stsClient(), awsConfig.AssumeRole, assumeRoleOpts...)
 		cfg.Credentials = awsv2.NewCredentialsCache(creds)
 	}
 
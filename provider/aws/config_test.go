@@ -18,11 +18,24 @@ package aws
 
 import (
 	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/provider"
 )
 
 func Test_newV2Config(t *testing.T) {
@@ -64,6 +77,31 @@ func Test_newV2Config(t *testing.T) {
 	})
 }
 
+func TestNewV2ConfigPartition(t *testing.T) {
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_CA_BUNDLE", "")
+	t.Setenv("AWS_REGION", "")
+
+	cfg, err := newV2Config(AWSSessionConfig{Partition: "aws-us-gov"})
+	require.NoError(t, err)
+	assert.Equal(t, "us-gov-west-1", cfg.Region)
+
+	t.Setenv("AWS_REGION", "cn-north-1")
+	cfg, err = newV2Config(AWSSessionConfig{Partition: "aws-cn"})
+	require.NoError(t, err)
+	assert.Equal(t, "cn-north-1", cfg.Region)
+
+	_, err = newV2Config(AWSSessionConfig{Partition: "aws-us-gov"})
+	assert.EqualError(t, err, "the region cn-north-1 is in the partition aws-cn, not aws-us-gov")
+}
+
+func TestRoute53Options(t *testing.T) {
+	assert.Empty(t, Route53Options(""))
+
+	client := route53.NewFromConfig(awsv2.Config{Region: "us-east-1"}, Route53Options("http://localhost:4566")...)
+	assert.Equal(t, "http://localhost:4566", awsv2.ToString(client.Options().BaseEndpoint))
+}
+
 func prepareCredentialsFile(t *testing.T) (*os.File, error) {
 	credsFile, err := os.CreateTemp("", "aws-*.creds")
 	require.NoError(t, err)
@@ -73,3 +111,299 @@ func prepareCredentialsFile(t *testing.T) (*os.File, error) {
 	require.NoError(t, err)
 	return credsFile, err
 }
+
+func TestReloadingCredentials(t *testing.T) {
+	credsFile := filepath.Join(t.TempDir(), "credentials")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credsFile)
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	require.NoError(t, os.WriteFile(credsFile, []byte("[default]\naws_access_key_id=AKID1234\naws_secret_access_key=SECRET1\n"), 0o600))
+
+	provider := &reloadingCredentials{interval: time.Hour}
+	creds, err := provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKID1234", creds.AccessKeyID)
+	assert.True(t, creds.CanExpire)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), creds.Expires, time.Minute)
+
+	// the rotated credentials are loaded once the credentials expire
+	require.NoError(t, os.WriteFile(credsFile, []byte("[default]\naws_access_key_id=AKID2345\naws_secret_access_key=SECRET2\n"), 0o600))
+	creds, err = provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKID2345", creds.AccessKeyID)
+	assert.Equal(t, "SECRET2", creds.SecretAccessKey)
+}
+
+func TestParseZoneRoles(t *testing.T) {
+	roles, err := ParseZoneRoles([]string{"Z123=arn:aws:iam::111:role/dns, /hostedzone/Z456=arn:aws:iam::222:role/dns", "Z789=arn:aws:iam::111:role/dns"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"Z123": "arn:aws:iam::111:role/dns",
+		"Z456": "arn:aws:iam::222:role/dns",
+		"Z789": "arn:aws:iam::111:role/dns",
+	}, roles)
+
+	for _, specs := range [][]string{
+		{"Z123"},
+		{"=arn:aws:iam::111:role/dns"},
+		{"Z123=dns"},
+		{"Z123=arn:aws:iam::111:role/dns", "Z123=arn:aws:iam::222:role/dns"},
+	} {
+		_, err := ParseZoneRoles(specs)
+		assert.Error(t, err, specs)
+	}
+}
+
+func TestParseProfileRoles(t *testing.T) {
+	roles, err := ParseProfileRoles([]string{"prod=arn:aws:iam::111:role/dns, staging=arn:aws:iam::222:role/dns", "", "dev=arn:aws:iam::111:role/dns"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"prod":    "arn:aws:iam::111:role/dns",
+		"staging": "arn:aws:iam::222:role/dns",
+		"dev":     "arn:aws:iam::111:role/dns",
+	}, roles)
+
+	for _, specs := range [][]string{
+		{"prod"},
+		{"=arn:aws:iam::111:role/dns"},
+		{"prod=dns"},
+		{"prod=arn:aws:iam::111:role/dns", "prod=arn:aws:iam::222:role/dns"},
+	} {
+		_, err := ParseProfileRoles(specs)
+		assert.Error(t, err, specs)
+	}
+}
+
+func TestParseSessionTags(t *testing.T) {
+	tags, err := ParseSessionTags([]string{"team=dns, cluster=prod", "", "empty="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "dns", "cluster": "prod", "empty": ""}, tags)
+
+	for _, specs := range [][]string{
+		{"team"},
+		{"=dns"},
+		{"team=dns", "team=network"},
+	} {
+		_, err := ParseSessionTags(specs)
+		assert.Error(t, err, specs)
+	}
+}
+
+func TestParseRoleChain(t *testing.T) {
+	roles, err := ParseRoleChain([]string{"arn:aws:iam::111:role/hop, arn:aws:iam::222:role/hop", "", "arn:aws:iam::333:role/dns"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"arn:aws:iam::111:role/hop", "arn:aws:iam::222:role/hop", "arn:aws:iam::333:role/dns"}, roles)
+
+	_, err = ParseRoleChain([]string{"arn:aws:iam::111:role/hop,hop"})
+	assert.Error(t, err)
+}
+
+func TestExpandSessionName(t *testing.T) {
+	assert.Equal(t, "external-dns", ExpandSessionName("external-dns", "prod", "owner", "pod"))
+	assert.Equal(t, "external-dns-prod-eu.owner-external-dns-5d9c", ExpandSessionName("external-dns-{cluster}.{owner}-{pod}", "prod eu", "owner", "external-dns-5d9c"))
+	assert.Equal(t, "external-dns-", ExpandSessionName("external-dns-{owner}", "", "", ""))
+	assert.Equal(t, "external-dns-"+strings.Repeat("x", 51), ExpandSessionName("external-dns-{owner}", "", strings.Repeat("x", 100), ""))
+}
+
+// assumeRoleHandler is an STS API issuing credentials whose access key ID is the name of the assumed role.
+func assumeRoleHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		role := r.PostForm.Get("RoleArn")
+		fmt.Fprintf(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><AssumeRoleResult><Credentials>`+
+			`<AccessKeyId>%s</AccessKeyId><SecretAccessKey>SECRET</SecretAccessKey><SessionToken>TOKEN</SessionToken>`+
+			`<Expiration>2100-01-01T00:00:00Z</Expiration></Credentials></AssumeRoleResult></AssumeRoleResponse>`, role[strings.LastIndex(role, "/")+1:])
+	}
+}
+
+func TestCreateV2ConfigsProfileRoles(t *testing.T) {
+	credsFile, err := prepareCredentialsFile(t)
+	defer os.Remove(credsFile.Name())
+	require.NoError(t, err)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credsFile.Name())
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_CA_BUNDLE", "")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	sts := httptest.NewServer(assumeRoleHandler(t))
+	defer sts.Close()
+
+	configs := CreateV2Configs(&externaldns.Config{
+		AWSProfiles:     []string{"profile1", "profile2"},
+		AWSAssumeRole:   "arn:aws:iam::111:role/shared",
+		AWSProfileRoles: []string{"profile2=arn:aws:iam::222:role/profile2"},
+		AWSAPIRetries:   1,
+		AWSEndpointURL:  sts.URL,
+	})
+	require.Len(t, configs, 2)
+	for profile, expected := range map[string]string{"profile1": "shared", "profile2": "profile2"} {
+		creds, err := configs[profile].Credentials.Retrieve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, expected, creds.AccessKeyID, profile)
+	}
+}
+
+func TestCreateV2ConfigsAssumeRoleSession(t *testing.T) {
+	credsFile, err := prepareCredentialsFile(t)
+	defer os.Remove(credsFile.Name())
+	require.NoError(t, err)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credsFile.Name())
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_CA_BUNDLE", "")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	var form url.Values
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assumeRoleHandler(t)(w, r)
+		form = r.PostForm
+	}))
+	defer sts.Close()
+
+	configs := CreateV2Configs(&externaldns.Config{
+		AWSProfiles:                  []string{"profile1"},
+		AWSAssumeRole:                "arn:aws:iam::111:role/dns",
+		AWSAssumeRoleSessionDuration: time.Hour,
+		AWSAssumeRoleSessionName:     "external-dns",
+		AWSAssumeRoleSessionTags:     []string{"team=dns,cluster=prod"},
+		AWSAPIRetries:                1,
+		AWSEndpointURL:               sts.URL,
+	})
+	_, err = configs["profile1"].Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "3600", form.Get("DurationSeconds"))
+	assert.Equal(t, "external-dns", form.Get("RoleSessionName"))
+	assert.Equal(t, "cluster", form.Get("Tags.member.1.Key"))
+	assert.Equal(t, "prod", form.Get("Tags.member.1.Value"))
+	assert.Equal(t, "team", form.Get("Tags.member.2.Key"))
+	assert.Equal(t, "dns", form.Get("Tags.member.2.Value"))
+}
+
+func TestCreateV2ConfigsRoleChain(t *testing.T) {
+	credsFile, err := prepareCredentialsFile(t)
+	defer os.Remove(credsFile.Name())
+	require.NoError(t, err)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credsFile.Name())
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_CA_BUNDLE", "")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	// the roles assumed, with the access key ID of the credentials assuming them
+	var assumed []string
+	var forms []url.Values
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		credential := strings.TrimPrefix(strings.Fields(r.Header.Get("Authorization"))[1], "Credential=")
+		assumeRoleHandler(t)(w, r)
+		assumed = append(assumed, strings.Split(credential, "/")[0]+">"+r.PostForm.Get("RoleArn"))
+		forms = append(forms, r.PostForm)
+	}))
+	defer sts.Close()
+
+	configs := CreateV2Configs(&externaldns.Config{
+		AWSProfiles:                 []string{"profile1"},
+		AWSAssumeRole:               "arn:aws:iam::333:role/dns",
+		AWSAssumeRoleChain:          []string{"arn:aws:iam::111:role/hop1,arn:aws:iam::222:role/hop2"},
+		AWSAssumeRoleSessionName:    "external-dns-{owner}",
+		AWSAssumeRoleSourceIdentity: "external-dns",
+		TXTOwnerID:                  "prod",
+		AWSAPIRetries:               1,
+		AWSEndpointURL:              sts.URL,
+	})
+	creds, err := configs["profile1"].Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "dns", creds.AccessKeyID)
+	assert.Equal(t, []string{
+		"AKID1234>arn:aws:iam::111:role/hop1",
+		"hop1>arn:aws:iam::222:role/hop2",
+		"hop2>arn:aws:iam::333:role/dns",
+	}, assumed)
+	for _, form := range forms {
+		assert.Equal(t, "external-dns-prod", form.Get("RoleSessionName"))
+		assert.Equal(t, "external-dns", form.Get("SourceIdentity"))
+	}
+}
+
+func TestNewV2ConfigProxyAndCABundle(t *testing.T) {
+	credsFile, err := prepareCredentialsFile(t)
+	defer os.Remove(credsFile.Name())
+	require.NoError(t, err)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credsFile.Name())
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_CA_BUNDLE", "")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	assumeRole := func(awsConfig AWSSessionConfig) (string, error) {
+		awsConfig.Profile, awsConfig.APIRetries, awsConfig.AssumeRole = "profile1", 1, "arn:aws:iam::111:role/dns"
+		cfg, err := newV2Config(awsConfig)
+		if err != nil {
+			return "", err
+		}
+		creds, err := cfg.Credentials.Retrieve(context.Background())
+		return creds.AccessKeyID, err
+	}
+
+	t.Run("proxy", func(t *testing.T) {
+		var proxied string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied = r.URL.Host
+			assumeRoleHandler(t)(w, r)
+		}))
+		defer proxy.Close()
+
+		role, err := assumeRole(AWSSessionConfig{EndpointURL: "http://sts.example.invalid", ProxyURL: proxy.URL})
+		require.NoError(t, err)
+		assert.Equal(t, "dns", role)
+		assert.Equal(t, "sts.example.invalid", proxied)
+	})
+
+	t.Run("CA bundle", func(t *testing.T) {
+		sts := httptest.NewTLSServer(assumeRoleHandler(t))
+		defer sts.Close()
+		caBundle := filepath.Join(t.TempDir(), "ca.pem")
+		require.NoError(t, os.WriteFile(caBundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: sts.Certificate().Raw}), 0o600))
+
+		_, err := assumeRole(AWSSessionConfig{EndpointURL: sts.URL})
+		assert.ErrorContains(t, err, "certificate", "the certificate of the STS API must not be trusted without the bundle")
+
+		role, err := assumeRole(AWSSessionConfig{EndpointURL: sts.URL, CABundle: caBundle})
+		require.NoError(t, err)
+		assert.Equal(t, "dns", role)
+	})
+}
+
+func TestNewTransport(t *testing.T) {
+	transport, err := newTransport("", "")
+	require.NoError(t, err)
+	assert.Nil(t, transport)
+
+	dir := t.TempDir()
+	_, err = newTransport("", filepath.Join(dir, "missing.pem"))
+	assert.ErrorContains(t, err, "reading the CA bundle")
+
+	invalid := filepath.Join(dir, "invalid.pem")
+	require.NoError(t, os.WriteFile(invalid, []byte("invalid"), 0o600))
+	_, err = newTransport("", invalid)
+	assert.ErrorContains(t, err, "no certificate in the CA bundle")
+}
+
+func TestParseZoneVPCs(t *testing.T) {
+	vpcs, err := ParseZoneVPCs([]string{"vpc-111, eu-west-1/vpc-222", "vpc-333"})
+	require.NoError(t, err)
+	assert.Equal(t, []ZoneVPC{{ID: "vpc-111"}, {Region: "eu-west-1", ID: "vpc-222"}, {ID: "vpc-333"}}, vpcs)
+
+	vpcs, err = ParseZoneVPCs([]string{"auto,vpc-111"})
+	require.NoError(t, err)
+	assert.Equal(t, []ZoneVPC{{ID: ZoneVPCAuto}, {ID: "vpc-111"}}, vpcs)
+
+	for _, spec := range []string{"111", "/vpc-111", "eu-west-1/111", "eu-west-1/auto"} {
+		_, err := ParseZoneVPCs([]string{spec})
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestUserAgentOptions(t *testing.T) {
+	defer provider.SetUserAgent("ExternalDNS", "", "")
+
+	assert.Len(t, userAgentOptions(), 1)
+
+	provider.SetUserAgent("ExternalDNS/v1.0.0", "prod", "prod-eu")
+	assert.Len(t, userAgentOptions(), 3)
+}
@@ -0,0 +1,455 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	// providerSpecificHealthCheckProtocol makes ExternalDNS manage the health check of the record, one of HTTP, HTTPS and TCP.
+	providerSpecificHealthCheckProtocol = "aws/health-check-protocol"
+	// providerSpecificHealthCheckPort is the port checked, 80 for HTTP and 443 for HTTPS by default.
+	providerSpecificHealthCheckPort = "aws/health-check-port"
+	// providerSpecificHealthCheckPath is the path requested by HTTP and HTTPS health checks, / by default.
+	providerSpecificHealthCheckPath = "aws/health-check-path"
+	// providerSpecificHealthCheckFailureThreshold is the number of consecutive failed checks before the target is unhealthy, 3 by default.
+	providerSpecificHealthCheckFailureThreshold = "aws/health-check-failure-threshold"
+	// providerSpecificHealthCheckRequestInterval is the number of seconds between two checks, 10 or 30, 30 by default.
+	providerSpecificHealthCheckRequestInterval = "aws/health-check-request-interval"
+
+	// healthCheckCallerReferencePrefix starts the caller references of the managed health checks, followed by the hashes
+	// of the zone name and of the record the health check is attached to, and a unique suffix.
+	healthCheckCallerReferencePrefix = "external-dns-"
+
+	defaultHealthCheckFailureThreshold = 3
+	defaultHealthCheckRequestInterval  = 30
+)
+
+// healthCheckSpec is the health check of a record described by its provider specific properties.
+type healthCheckSpec struct {
+	protocol         route53types.HealthCheckType
+	port             int32
+	path             string
+	failureThreshold int32
+	requestInterval  int32
+}
+
+// parseHealthCheckSpec returns the health check described by the properties of the endpoint, with the defaults applied.
+// It returns false if the endpoint has no managed health check.
+func parseHealthCheckSpec(ep *endpoint.Endpoint) (healthCheckSpec, bool, error) {
+	protocol, ok := ep.GetProviderSpecificProperty(providerSpecificHealthCheckProtocol)
+	if !ok {
+		return healthCheckSpec{}, false, nil
+	}
+	spec := healthCheckSpec{
+		protocol:         route53types.HealthCheckType(strings.ToUpper(protocol)),
+		failureThreshold: defaultHealthCheckFailureThreshold,
+		requestInterval:  defaultHealthCheckRequestInterval,
+	}
+	switch spec.protocol {
+	case route53types.HealthCheckTypeHttp:
+		spec.port, spec.path = 80, "/"
+	case route53types.HealthCheckTypeHttps:
+		spec.port, spec.path = 443, "/"
+	case route53types.HealthCheckTypeTcp:
+	default:
+		return healthCheckSpec{}, false, fmt.Errorf("unsupported health check protocol %q, expected HTTP, HTTPS or TCP", protocol)
+	}
+
+	if value, ok := ep.GetProviderSpecificProperty(providerSpecificHealthCheckPort); ok {
+		port, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || port < 1 || port > 65535 {
+			return healthCheckSpec{}, false, fmt.Errorf("invalid health check port %q", value)
+		}
+		spec.port = int32(port)
+	} else if spec.port == 0 {
+		return healthCheckSpec{}, false, fmt.Errorf("the port of TCP health checks is required")
+	}
+	if value, ok := ep.GetProviderSpecificProperty(providerSpecificHealthCheckPath); ok && spec.protocol != route53types.HealthCheckTypeTcp {
+		if !strings.HasPrefix(value, "/") {
+			value = "/" + value
+		}
+		spec.path = value
+	}
+	if value, ok := ep.GetProviderSpecificProperty(providerSpecificHealthCheckFailureThreshold); ok {
+		threshold, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || threshold < 1 || threshold > 10 {
+			return healthCheckSpec{}, false, fmt.Errorf("invalid health check failure threshold %q, expected 1 to 10", value)
+		}
+		spec.failureThreshold = int32(threshold)
+	}
+	if value, ok := ep.GetProviderSpecificProperty(providerSpecificHealthCheckRequestInterval); ok {
+		interval, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || (interval != 10 && interval != 30) {
+			return healthCheckSpec{}, false, fmt.Errorf("invalid health check request interval %q, expected 10 or 30", value)
+		}
+		spec.requestInterval = int32(interval)
+	}
+	return spec, true, nil
+}
+
+// healthCheckSpecFromConfig returns the health check described by a health check configuration.
+func healthCheckSpecFromConfig(config *route53types.HealthCheckConfig) healthCheckSpec {
+	spec := healthCheckSpec{
+		protocol:         config.Type,
+		path:             aws.ToString(config.ResourcePath),
+		port:             aws.ToInt32(config.Port),
+		failureThreshold: aws.ToInt32(config.FailureThreshold),
+		requestInterval:  aws.ToInt32(config.RequestInterval),
+	}
+	if spec.protocol == route53types.HealthCheckTypeTcp {
+		spec.path = ""
+	}
+	return spec
+}
+
+// setProperties replaces the health check properties of the endpoint with the ones of the spec.
+func (s healthCheckSpec) setProperties(ep *endpoint.Endpoint) {
+	ep.DeleteProviderSpecificProperty(providerSpecificHealthCheckPath)
+	ep.SetProviderSpecificProperty(providerSpecificHealthCheckProtocol, string(s.protocol))
+	ep.SetProviderSpecificProperty(providerSpecificHealthCheckPort, strconv.Itoa(int(s.port)))
+	if s.path != "" {
+		ep.SetProviderSpecificProperty(providerSpecificHealthCheckPath, s.path)
+	}
+	ep.SetProviderSpecificProperty(providerSpecificHealthCheckFailureThreshold, strconv.Itoa(int(s.failureThreshold)))
+	ep.SetProviderSpecificProperty(providerSpecificHealthCheckRequestInterval, strconv.Itoa(int(s.requestInterval)))
+}
+
+// config returns the health check configuration checking the target.
+func (s healthCheckSpec) config(target string) *route53types.HealthCheckConfig {
+	config := &route53types.HealthCheckConfig{
+		Type:             s.protocol,
+		Port:             aws.Int32(s.port),
+		FailureThreshold: aws.Int32(s.failureThreshold),
+		RequestInterval:  aws.Int32(s.requestInterval),
+	}
+	if s.path != "" {
+		config.ResourcePath = aws.String(s.path)
+	}
+	if net.ParseIP(target) != nil {
+		config.IPAddress = aws.String(target)
+	} else {
+		config.FullyQualifiedDomainName = aws.String(target)
+	}
+	return config
+}
+
+// adjustHealthCheck sets the properties of the managed health check of the endpoint with the defaults applied,
+// or removes them if the health checks are not managed, the endpoint refers to a health check by ID or they are invalid.
+func (p *AWSProvider) adjustHealthCheck(ep *endpoint.Endpoint) {
+	if _, ok := ep.GetProviderSpecificProperty(providerSpecificHealthCheckProtocol); !ok {
+		return
+	}
+	if !p.manageHealthChecks {
+		log.Debugf("Ignoring the health check properties of %s, health checks are not managed", ep.DNSName)
+		deleteHealthCheckProperties(ep)
+		return
+	}
+	if id, ok := ep.GetProviderSpecificProperty(providerSpecificHealthCheckID); ok {
+		log.Warnf("Ignoring the health check properties of %s, which refers to the health check %s by ID", ep.DNSName, id)
+		deleteHealthCheckProperties(ep)
+		return
+	}
+	spec, _, err := parseHealthCheckSpec(ep)
+	if err != nil {
+		log.Warnf("Ignoring the health check properties of %s: %v", ep.DNSName, err)
+		deleteHealthCheckProperties(ep)
+		return
+	}
+	spec.setProperties(ep)
+}
+
+// deleteHealthCheckProperties removes the health check properties of the endpoint.
+func deleteHealthCheckProperties(ep *endpoint.Endpoint) {
+	for _, name := range []string{providerSpecificHealthCheckProtocol, providerSpecificHealthCheckPort, providerSpecificHealthCheckPath,
+		providerSpecificHealthCheckFailureThreshold, providerSpecificHealthCheckRequestInterval} {
+		ep.DeleteProviderSpecificProperty(name)
+	}
+}
+
+// healthCheckKey returns the start of the caller references of the health checks of a record in a zone.
+func healthCheckKey(zoneName string, ep *endpoint.Endpoint) string {
+	recordHash := sha256.Sum256([]byte(strings.Join([]string{ep.DNSName, ep.RecordType, ep.SetIdentifier}, "#")))
+	return healthCheckZoneKey(zoneName) + hex.EncodeToString(recordHash[:8]) + "-"
+}
+
+// healthCheckZoneKey returns the start of the caller references of the health checks of the records in a zone.
+func healthCheckZoneKey(zoneName string) string {
+	zoneHash := sha256.Sum256([]byte(strings.TrimSuffix(zoneName, ".")))
+	return healthCheckCallerReferencePrefix + hex.EncodeToString(zoneHash[:4]) + "-"
+}
+
+// healthChecks caches the health checks managed by ExternalDNS, listed with the records,
+// and the health checks attached to the records listed or changed since.
+type healthChecks struct {
+	mu sync.Mutex
+	// managed are the health checks created by ExternalDNS by ID
+	managed map[string]route53types.HealthCheck
+	// referenced are the IDs of the health checks attached to the records
+	referenced map[string]bool
+	// listedZones are the names of the zones whose records were listed
+	listedZones map[string]bool
+}
+
+// isManaged returns true if the health check was created by ExternalDNS.
+func (hc *healthChecks) isManaged(id string) bool {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	_, ok := hc.managed[id]
+	return ok
+}
+
+// listHealthChecks lists the health checks created by ExternalDNS with the clients of the zones, and resets the references.
+func (p *AWSProvider) listHealthChecks(ctx context.Context, zones map[string]*profiledZone) error {
+	managed := map[string]route53types.HealthCheck{}
+	listedZones := map[string]bool{}
+	listedProfiles := map[string]bool{}
+	for _, z := range zones {
+		listedZones[strings.TrimSuffix(*z.zone.Name, ".")] = true
+		if listedProfiles[z.profile] {
+			continue
+		}
+		listedProfiles[z.profile] = true
+		paginator := route53.NewListHealthChecksPaginator(p.clients[z.profile], &route53.ListHealthChecksInput{})
+		for paginator.HasMorePages() {
+			resp, err := paginator.NextPage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list health checks using aws profile %q: %w", z.profile, err)
+			}
+			for _, check := range resp.HealthChecks {
+				if strings.HasPrefix(aws.ToString(check.CallerReference), healthCheckCallerReferencePrefix) && check.HealthCheckConfig != nil {
+					managed[*check.Id] = check
+				}
+			}
+		}
+	}
+
+	p.healthChecks.mu.Lock()
+	defer p.healthChecks.mu.Unlock()
+	p.healthChecks.managed = managed
+	p.healthChecks.referenced = map[string]bool{}
+	p.healthChecks.listedZones = listedZones
+	return nil
+}
+
+// recordHealthCheck replaces the ID of a health check managed by ExternalDNS with the properties of its configuration,
+// so that the record compares to the desired endpoint.
+func (p *AWSProvider) recordHealthCheck(ep *endpoint.Endpoint, id string) {
+	p.healthChecks.mu.Lock()
+	defer p.healthChecks.mu.Unlock()
+	if p.healthChecks.referenced != nil {
+		p.healthChecks.referenced[id] = true
+	}
+	if check, ok := p.healthChecks.managed[id]; ok {
+		healthCheckSpecFromConfig(check.HealthCheckConfig).setProperties(ep)
+	}
+}
+
+// attachHealthChecks returns copies of the endpoints with a managed health check, with the ID of their health check
+// created or updated to match their properties. Without a suitable zone, the endpoint is returned as is.
+func (p *AWSProvider) attachHealthChecks(ctx context.Context, zones map[string]*profiledZone, endpoints []*endpoint.Endpoint) []*endpoint.Endpoint {
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		spec, ok, err := parseHealthCheckSpec(ep)
+		if err != nil || !ok || len(ep.Targets) == 0 {
+			result = append(result, ep)
+			continue
+		}
+		matching := suitableZones(provider.EnsureTrailingDot(ep.DNSName), zones)
+		if len(matching) == 0 {
+			result = append(result, ep)
+			continue
+		}
+		id, err := p.ensureHealthCheck(ctx, matching[0], ep, spec.config(ep.Targets[0]))
+		if err != nil {
+			log.Errorf("Failed to create or update the health check of %s: %v", ep.DNSName, err)
+			result = append(result, ep)
+			continue
+		}
+		attached := ep.DeepCopy()
+		if id != "" {
+			attached.SetProviderSpecificProperty(providerSpecificHealthCheckID, id)
+		}
+		result = append(result, attached)
+	}
+	return result
+}
+
+// ensureHealthCheck returns the ID of the health check of the record with the configuration, updating the health check
+// of the record if only its mutable settings differ, and otherwise creating a new one.
+func (p *AWSProvider) ensureHealthCheck(ctx context.Context, zone *profiledZone, ep *endpoint.Endpoint, config *route53types.HealthCheckConfig) (string, error) {
+	key := healthCheckKey(*zone.zone.Name, ep)
+
+	p.healthChecks.mu.Lock()
+	var existing *route53types.HealthCheck
+	for _, check := range p.healthChecks.managed {
+		if !strings.HasPrefix(*check.CallerReference, key) {
+			continue
+		}
+		if healthCheckConfigsEqual(check.HealthCheckConfig, config) {
+			p.healthChecks.mu.Unlock()
+			p.referenceHealthCheck(*check.Id)
+			return *check.Id, nil
+		}
+		if healthCheckUpdatable(check.HealthCheckConfig, config) {
+			existing = &check
+		}
+	}
+	p.healthChecks.mu.Unlock()
+
+	client := p.clients[zone.profile]
+	if existing != nil {
+		log.Infof("Updating the health check %s of %s", *existing.Id, ep.DNSName)
+		if p.dryRun {
+			return *existing.Id, nil
+		}
+		resp, err := client.UpdateHealthCheck(ctx, &route53.UpdateHealthCheckInput{
+			HealthCheckId:            existing.Id,
+			HealthCheckVersion:       existing.HealthCheckVersion,
+			Port:                     config.Port,
+			ResourcePath:             config.ResourcePath,
+			FailureThreshold:         config.FailureThreshold,
+			IPAddress:                config.IPAddress,
+			FullyQualifiedDomainName: config.FullyQualifiedDomainName,
+		})
+		if err != nil {
+			return "", err
+		}
+		p.cacheHealthCheck(*resp.HealthCheck)
+		return *resp.HealthCheck.Id, nil
+	}
+
+	log.Infof("Creating a %s health check for %s", config.Type, ep.DNSName)
+	if p.dryRun {
+		return "", nil
+	}
+	resp, err := client.CreateHealthCheck(ctx, &route53.CreateHealthCheckInput{
+		CallerReference:   aws.String(key + strconv.FormatInt(time.Now().UnixNano(), 36)),
+		HealthCheckConfig: config,
+	})
+	if err != nil {
+		return "", err
+	}
+	p.cacheHealthCheck(*resp.HealthCheck)
+	return *resp.HealthCheck.Id, nil
+}
+
+func (p *AWSProvider) cacheHealthCheck(check route53types.HealthCheck) {
+	p.healthChecks.mu.Lock()
+	if p.healthChecks.managed == nil {
+		p.healthChecks.managed = map[string]route53types.HealthCheck{}
+	}
+	p.healthChecks.managed[*check.Id] = check
+	p.healthChecks.mu.Unlock()
+	p.referenceHealthCheck(*check.Id)
+}
+
+// referenceHealthCheck keeps the health check from the garbage collection until the records are listed again.
+func (p *AWSProvider) referenceHealthCheck(id string) {
+	p.healthChecks.mu.Lock()
+	defer p.healthChecks.mu.Unlock()
+	if p.healthChecks.referenced == nil {
+		p.healthChecks.referenced = map[string]bool{}
+	}
+	p.healthChecks.referenced[id] = true
+}
+
+// collectHealthChecks deletes the health checks created by ExternalDNS for the records of the zones last listed
+// which are attached to none of their records. With bounded listing, the records of the zones are partially listed
+// and the health checks are left in place.
+func (p *AWSProvider) collectHealthChecks(ctx context.Context, zones map[string]*profiledZone) {
+	if p.boundedListing {
+		return
+	}
+	p.healthChecks.mu.Lock()
+	var garbage []route53types.HealthCheck
+	for id, check := range p.healthChecks.managed {
+		if p.healthChecks.referenced[id] {
+			continue
+		}
+		for zoneName := range p.healthChecks.listedZones {
+			if strings.HasPrefix(*check.CallerReference, healthCheckZoneKey(zoneName)) {
+				garbage = append(garbage, check)
+				break
+			}
+		}
+	}
+	p.healthChecks.mu.Unlock()
+
+	for _, check := range garbage {
+		client := p.healthCheckClient(check, zones)
+		if client == nil {
+			continue
+		}
+		log.Infof("Deleting the health check %s attached to no record", *check.Id)
+		if p.dryRun {
+			continue
+		}
+		if _, err := client.DeleteHealthCheck(ctx, &route53.DeleteHealthCheckInput{HealthCheckId: check.Id}); err != nil {
+			log.Warnf("Failed to delete the health check %s: %v", *check.Id, err)
+			continue
+		}
+		p.healthChecks.mu.Lock()
+		delete(p.healthChecks.managed, *check.Id)
+		p.healthChecks.mu.Unlock()
+	}
+}
+
+// healthCheckClient returns the client of the zone the health check was created for.
+func (p *AWSProvider) healthCheckClient(check route53types.HealthCheck, zones map[string]*profiledZone) Route53API {
+	for _, z := range zones {
+		if strings.HasPrefix(*check.CallerReference, healthCheckZoneKey(*z.zone.Name)) {
+			return p.clients[z.profile]
+		}
+	}
+	return nil
+}
+
+// healthCheckConfigsEqual compares the settings of the health check configurations set by ExternalDNS.
+func healthCheckConfigsEqual(a, b *route53types.HealthCheckConfig) bool {
+	return healthCheckUpdatable(a, b) &&
+		aws.ToInt32(a.Port) == aws.ToInt32(b.Port) &&
+		aws.ToString(a.ResourcePath) == aws.ToString(b.ResourcePath) &&
+		aws.ToInt32(a.FailureThreshold) == aws.ToInt32(b.FailureThreshold) &&
+		aws.ToString(a.IPAddress) == aws.ToString(b.IPAddress) &&
+		aws.ToString(a.FullyQualifiedDomainName) == aws.ToString(b.FullyQualifiedDomainName)
+}
+
+// healthCheckUpdatable returns true if a health check can be updated from one configuration to the other,
+// which requires the same type, request interval and kind of target.
+func healthCheckUpdatable(from, to *route53types.HealthCheckConfig) bool {
+	return from.Type == to.Type &&
+		aws.ToInt32(from.RequestInterval) == aws.ToInt32(to.RequestInterval) &&
+		(from.IPAddress == nil) == (to.IPAddress == nil)
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+func TestParseHealthCheckSpec(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		properties map[string]string
+		expected   healthCheckSpec
+		err        string
+	}{
+		{
+			name:       "HTTP defaults",
+			properties: map[string]string{providerSpecificHealthCheckProtocol: "http"},
+			expected:   healthCheckSpec{protocol: route53types.HealthCheckTypeHttp, port: 80, path: "/", failureThreshold: 3, requestInterval: 30},
+		},
+		{
+			name: "HTTPS",
+			properties: map[string]string{
+				providerSpecificHealthCheckProtocol:         "HTTPS",
+				providerSpecificHealthCheckPort:             "8443",
+				providerSpecificHealthCheckPath:             "healthz",
+				providerSpecificHealthCheckFailureThreshold: "5",
+				providerSpecificHealthCheckRequestInterval:  "10",
+			},
+			expected: healthCheckSpec{protocol: route53types.HealthCheckTypeHttps, port: 8443, path: "/healthz", failureThreshold: 5, requestInterval: 10},
+		},
+		{
+			name:       "TCP without path",
+			properties: map[string]string{providerSpecificHealthCheckProtocol: "TCP", providerSpecificHealthCheckPort: "5432", providerSpecificHealthCheckPath: "/"},
+			expected:   healthCheckSpec{protocol: route53types.HealthCheckTypeTcp, port: 5432, failureThreshold: 3, requestInterval: 30},
+		},
+		{
+			name:       "TCP without port",
+			properties: map[string]string{providerSpecificHealthCheckProtocol: "TCP"},
+			err:        "the port of TCP health checks is required",
+		},
+		{
+			name:       "unsupported protocol",
+			properties: map[string]string{providerSpecificHealthCheckProtocol: "ICMP"},
+			err:        `unsupported health check protocol "ICMP", expected HTTP, HTTPS or TCP`,
+		},
+		{
+			name:       "invalid port",
+			properties: map[string]string{providerSpecificHealthCheckProtocol: "HTTP", providerSpecificHealthCheckPort: "0"},
+			err:        `invalid health check port "0"`,
+		},
+		{
+			name:       "invalid failure threshold",
+			properties: map[string]string{providerSpecificHealthCheckProtocol: "HTTP", providerSpecificHealthCheckFailureThreshold: "11"},
+			err:        `invalid health check failure threshold "11", expected 1 to 10`,
+		},
+		{
+			name:       "invalid request interval",
+			properties: map[string]string{providerSpecificHealthCheckProtocol: "HTTP", providerSpecificHealthCheckRequestInterval: "20"},
+			err:        `invalid health check request interval "20", expected 10 or 30`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ep := endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4")
+			for name, value := range tc.properties {
+				ep.WithProviderSpecific(name, value)
+			}
+			spec, ok, err := parseHealthCheckSpec(ep)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, tc.expected, spec)
+			assert.Equal(t, spec, healthCheckSpecFromConfig(spec.config("1.2.3.4")))
+		})
+	}
+
+	_, ok, err := parseHealthCheckSpec(endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4"))
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestAWSAdjustEndpointsHealthChecks(t *testing.T) {
+	p, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+
+	records := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("app.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificHealthCheckProtocol, "http"),
+	}
+	records, err := p.AdjustEndpoints(records)
+	require.NoError(t, err)
+	assert.Empty(t, records[0].ProviderSpecific, "the health checks are not managed")
+
+	p.manageHealthChecks = true
+	records = []*endpoint.Endpoint{
+		endpoint.NewEndpoint("app.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificHealthCheckProtocol, "http"),
+		endpoint.NewEndpoint("by-id.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificHealthCheckID, "existing").
+			WithProviderSpecific(providerSpecificHealthCheckProtocol, "http"),
+		endpoint.NewEndpoint("invalid.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificHealthCheckProtocol, "tcp"),
+	}
+	records, err = p.AdjustEndpoints(records)
+	require.NoError(t, err)
+	assert.Empty(t, records[2].ProviderSpecific, "the invalid health check properties are removed")
+	validateEndpoints(t, p, records[:2], []*endpoint.Endpoint{
+		endpoint.NewEndpoint("app.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificHealthCheckProtocol, "HTTP").
+			WithProviderSpecific(providerSpecificHealthCheckPort, "80").
+			WithProviderSpecific(providerSpecificHealthCheckPath, "/").
+			WithProviderSpecific(providerSpecificHealthCheckFailureThreshold, "3").
+			WithProviderSpecific(providerSpecificHealthCheckRequestInterval, "30"),
+		endpoint.NewEndpoint("by-id.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificHealthCheckID, "existing"),
+	})
+}
+
+func TestAWSHealthCheckLifecycle(t *testing.T) {
+	ctx := context.Background()
+	p, stub := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+	p.manageHealthChecks = true
+
+	// an unrelated health check is left in place
+	stub.healthChecks["other"] = route53types.HealthCheck{
+		Id:                aws.String("other"),
+		CallerReference:   aws.String("other"),
+		HealthCheckConfig: &route53types.HealthCheckConfig{Type: route53types.HealthCheckTypeTcp},
+	}
+
+	sync := func(desired ...*endpoint.Endpoint) *plan.Changes {
+		t.Helper()
+		current, err := p.Records(ctx)
+		require.NoError(t, err)
+		desired, err = p.AdjustEndpoints(desired)
+		require.NoError(t, err)
+		changes := (&plan.Plan{
+			Current:            current,
+			Desired:            desired,
+			Policies:           []plan.Policy{&plan.SyncPolicy{}},
+			ManagedRecords:     []string{endpoint.RecordTypeA},
+			PropertyComparator: p.PropertyValuesEqual,
+		}).Calculate().Changes
+		require.NoError(t, p.ApplyChanges(ctx, changes))
+		return changes
+	}
+	recordHealthCheck := func() *string {
+		t.Helper()
+		records := listAWSRecords(t, p.clients[defaultAWSProfile], "/hostedzone/zone-1.ext-dns-test-2.teapot.zalan.do.")
+		require.Len(t, records, 1)
+		return records[0].HealthCheckId
+	}
+	app := func(properties ...string) *endpoint.Endpoint {
+		ep := endpoint.NewEndpoint("app.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificHealthCheckProtocol, "HTTP")
+		for i := 0; i < len(properties); i += 2 {
+			ep.WithProviderSpecific(properties[i], properties[i+1])
+		}
+		return ep
+	}
+
+	// the health check is created with the record
+	changes := sync(app(providerSpecificHealthCheckPath, "/healthz"))
+	require.Len(t, changes.Create, 1)
+	require.Len(t, stub.healthChecks, 2)
+	id := aws.ToString(recordHealthCheck())
+	require.NotEmpty(t, id)
+	config := stub.healthChecks[id].HealthCheckConfig
+	assert.Equal(t, route53types.HealthCheckTypeHttp, config.Type)
+	assert.Equal(t, "1.2.3.4", aws.ToString(config.IPAddress))
+	assert.Equal(t, int32(80), aws.ToInt32(config.Port))
+	assert.Equal(t, "/healthz", aws.ToString(config.ResourcePath))
+
+	// the record matches the desired endpoint without its health check ID
+	changes = sync(app(providerSpecificHealthCheckPath, "/healthz"))
+	assert.False(t, changes.HasChanges())
+
+	// the mutable settings are updated in place
+	changes = sync(app(providerSpecificHealthCheckPath, "/ready"))
+	require.Len(t, changes.UpdateNew, 1)
+	assert.Equal(t, id, aws.ToString(recordHealthCheck()))
+	assert.Equal(t, "/ready", aws.ToString(stub.healthChecks[id].HealthCheckConfig.ResourcePath))
+
+	// a new health check replaces the one with other immutable settings, which is then collected
+	changes = sync(app(providerSpecificHealthCheckPath, "/ready", providerSpecificHealthCheckRequestInterval, "10"))
+	require.Len(t, changes.UpdateNew, 1)
+	replaced := aws.ToString(recordHealthCheck())
+	assert.NotEqual(t, id, replaced)
+	assert.Contains(t, stub.healthChecks, id, "the health check replaced is attached to the record until the records are listed again")
+	sync(app(providerSpecificHealthCheckPath, "/ready", providerSpecificHealthCheckRequestInterval, "10"))
+	assert.NotContains(t, stub.healthChecks, id)
+	assert.Contains(t, stub.healthChecks, replaced)
+
+	// the health check is detached from the record without health check properties, and then collected
+	sync(endpoint.NewEndpoint("app.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4"))
+	assert.Nil(t, recordHealthCheck())
+	sync(endpoint.NewEndpoint("app.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4"))
+	assert.NotContains(t, stub.healthChecks, replaced)
+	assert.Contains(t, stub.healthChecks, "other")
+}
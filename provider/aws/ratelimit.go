@@ -0,0 +1,221 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// minAPIRate is the rate of requests per second the rate of an operation is never decreased below.
+	minAPIRate = 0.1
+	// apiRateIncrease is the fraction of the rate limit of an operation its rate is increased by after a request
+	// that was not throttled.
+	apiRateIncrease = 0.05
+)
+
+var (
+	apiRate = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "aws",
+			Name:      "api_rate_limit",
+			Help:      "Current number of requests per second allowed to the AWS API operation by the adaptive rate limiter.",
+		},
+		[]string{"operation"},
+	)
+	apiThrottledTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "aws",
+			Name:      "api_throttled_requests_total",
+			Help:      "Number of requests to the AWS API operation that were throttled.",
+		},
+		[]string{"operation"},
+	)
+
+	registerRateLimiterMetrics = sync.Once{}
+)
+
+// ParseAPIRateLimits parses the rate limits in requests per second of the AWS API operations of the comma-separated
+// lists of operation=rate pairs, e.g. "ListHostedZones=2,ChangeResourceRecordSets=4".
+func ParseAPIRateLimits(specs []string) (map[string]float64, error) {
+	limits := map[string]float64{}
+	for _, spec := range specs {
+		for _, pair := range strings.Split(spec, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			operation, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			limit, err := strconv.ParseFloat(value, 64)
+			if !ok || operation == "" || err != nil || limit < minAPIRate || math.IsInf(limit, 0) {
+				return nil, fmt.Errorf("invalid API rate limit %q, expected <operation>=<requests per second of at least %g>", pair, minAPIRate)
+			}
+			limits[operation] = limit
+		}
+	}
+	return limits, nil
+}
+
+// AdaptiveRateLimiter paces the requests to each operation of the AWS API, so that a single reconciliation does not
+// exhaust the quotas of the account shared with other clients. The rate of an operation starts at its limit, is halved
+// whenever a request is throttled, e.g. with Throttling or PriorRequestNotComplete errors, and is increased back to
+// its limit by the requests that are not throttled.
+type AdaptiveRateLimiter struct {
+	limits       map[string]float64
+	defaultLimit float64
+
+	mu         sync.Mutex
+	operations map[string]*operationRate
+	now        func() time.Time
+}
+
+// operationRate is the current rate of an operation and the time its next request is allowed at.
+type operationRate struct {
+	limit float64
+	rate  float64
+	next  time.Time
+}
+
+// NewAdaptiveRateLimiter creates an AdaptiveRateLimiter limiting the operations to their rate limit in requests per
+// second, or to the default limit. If the default limit is zero, the other operations are not paced.
+func NewAdaptiveRateLimiter(limits map[string]float64, defaultLimit float64) *AdaptiveRateLimiter {
+	registerRateLimiterMetrics.Do(func() {
+		prometheus.MustRegister(apiRate, apiThrottledTotal)
+	})
+	return &AdaptiveRateLimiter{
+		limits:       limits,
+		defaultLimit: defaultLimit,
+		operations:   map[string]*operationRate{},
+		now:          time.Now,
+	}
+}
+
+// Retryer returns the retryer pacing the attempts of the retryer, including its retries.
+func (l *AdaptiveRateLimiter) Retryer(retryer awsv2.Retryer) awsv2.Retryer {
+	v2, ok := retryer.(awsv2.RetryerV2)
+	if !ok {
+		v2 = retry.AddWithMaxAttempts(retryer, retryer.MaxAttempts()).(awsv2.RetryerV2)
+	}
+	return &rateLimitedRetryer{RetryerV2: v2, limiter: l}
+}
+
+// operation returns the rate of the operation, the caller holding the lock.
+func (l *AdaptiveRateLimiter) operation(name string) *operationRate {
+	op, ok := l.operations[name]
+	if !ok {
+		limit, ok := l.limits[name]
+		if !ok {
+			limit = l.defaultLimit
+		}
+		op = &operationRate{limit: limit, rate: limit}
+		l.operations[name] = op
+		if limit > 0 {
+			apiRate.WithLabelValues(name).Set(op.rate)
+		}
+	}
+	return op
+}
+
+// Wait waits until a request to the operation is allowed, or the context is done.
+func (l *AdaptiveRateLimiter) Wait(ctx context.Context, operation string) error {
+	l.mu.Lock()
+	op := l.operation(operation)
+	if op.limit <= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	now := l.now()
+	at := op.next
+	if at.Before(now) {
+		at = now
+	}
+	op.next = at.Add(time.Duration(float64(time.Second) / op.rate))
+	l.mu.Unlock()
+
+	delay := at.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Observe adapts the rate of the operation to the result of a request.
+func (l *AdaptiveRateLimiter) Observe(operation string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	op := l.operation(operation)
+	previous := op.rate
+	throttled := err != nil && retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err) == awsv2.TrueTernary
+	if throttled {
+		apiThrottledTotal.WithLabelValues(operation).Inc()
+	}
+	switch {
+	case op.limit <= 0:
+		return
+	case throttled:
+		op.rate = math.Max(op.rate/2, minAPIRate)
+		logrus.Debugf("Decreased the rate of the AWS API operation %s to %.2f requests per second after throttling", operation, op.rate)
+	default:
+		op.rate = math.Min(op.rate+op.limit*apiRateIncrease, op.limit)
+	}
+	if op.rate != previous {
+		apiRate.WithLabelValues(operation).Set(op.rate)
+	}
+}
+
+// rateLimitedRetryer paces the attempts of the operations with the rate limiter.
+type rateLimitedRetryer struct {
+	awsv2.RetryerV2
+	limiter *AdaptiveRateLimiter
+}
+
+// GetAttemptToken waits until the attempt of the operation of the context is allowed, and returns the release of the
+// attempt adapting the rate of the operation to its result.
+func (r *rateLimitedRetryer) GetAttemptToken(ctx context.Context) (func(error) error, error) {
+	operation := awsmiddleware.GetOperationName(ctx)
+	if err := r.limiter.Wait(ctx, operation); err != nil {
+		return nil, err
+	}
+	release, err := r.RetryerV2.GetAttemptToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(err error) error {
+		r.limiter.Observe(operation, err)
+		return release(err)
+	}, nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/smithy-go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAPIRateLimits(t *testing.T) {
+	limits, err := ParseAPIRateLimits([]string{"ListHostedZones=2, ChangeResourceRecordSets=0.5", "", "GetChange=10"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"ListHostedZones": 2, "ChangeResourceRecordSets": 0.5, "GetChange": 10}, limits)
+
+	for _, spec := range []string{"ListHostedZones", "=2", "ListHostedZones=fast", "ListHostedZones=0", "ListHostedZones=0.01", "ListHostedZones=+Inf"} {
+		_, err := ParseAPIRateLimits([]string{spec})
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestAdaptiveRateLimiterObserve(t *testing.T) {
+	apiRate.Reset()
+	apiThrottledTotal.Reset()
+	limiter := NewAdaptiveRateLimiter(map[string]float64{"ChangeResourceRecordSets": 4}, 8)
+	throttling := &smithy.GenericAPIError{Code: "Throttling", Message: "Rate exceeded"}
+	priorRequest := &smithy.GenericAPIError{Code: "PriorRequestNotComplete"}
+
+	limiter.Observe("ChangeResourceRecordSets", nil)
+	assert.Equal(t, 4.0, testutil.ToFloat64(apiRate.WithLabelValues("ChangeResourceRecordSets")))
+
+	limiter.Observe("ChangeResourceRecordSets", throttling)
+	limiter.Observe("ChangeResourceRecordSets", priorRequest)
+	assert.Equal(t, 1.0, testutil.ToFloat64(apiRate.WithLabelValues("ChangeResourceRecordSets")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(apiThrottledTotal.WithLabelValues("ChangeResourceRecordSets")))
+
+	// the other errors and the successes increase the rate back to the limit
+	limiter.Observe("ChangeResourceRecordSets", errors.New("failed"))
+	limiter.Observe("ChangeResourceRecordSets", nil)
+	assert.InDelta(t, 1.4, testutil.ToFloat64(apiRate.WithLabelValues("ChangeResourceRecordSets")), 1e-9)
+	for i := 0; i < 20; i++ {
+		limiter.Observe("ChangeResourceRecordSets", nil)
+	}
+	assert.Equal(t, 4.0, testutil.ToFloat64(apiRate.WithLabelValues("ChangeResourceRecordSets")))
+
+	// the rate is never decreased below the minimum
+	for i := 0; i < 10; i++ {
+		limiter.Observe("ListHostedZones", throttling)
+	}
+	assert.Equal(t, minAPIRate, testutil.ToFloat64(apiRate.WithLabelValues("ListHostedZones")))
+	assert.Equal(t, 4.0, testutil.ToFloat64(apiRate.WithLabelValues("ChangeResourceRecordSets")))
+}
+
+func TestAdaptiveRateLimiterWait(t *testing.T) {
+	now := time.Now()
+	limiter := NewAdaptiveRateLimiter(map[string]float64{"ListHostedZones": 0.5}, 0)
+	limiter.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// the first request is allowed, the following one 2s later
+	require.NoError(t, limiter.Wait(ctx, "ListHostedZones"))
+	assert.Equal(t, now.Add(2*time.Second), limiter.operations["ListHostedZones"].next)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, limiter.Wait(canceled, "ListHostedZones"), context.Canceled)
+
+	// the operations without limit are not paced, even when throttled
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.Wait(canceled, "GetChange"))
+		limiter.Observe("GetChange", &smithy.GenericAPIError{Code: "Throttling"})
+	}
+	assert.Equal(t, 0.0, limiter.operations["GetChange"].rate)
+}
+
+func TestAdaptiveRateLimiterRetryer(t *testing.T) {
+	apiRate.Reset()
+	apiThrottledTotal.Reset()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`<?xml version="1.0"?>
+<ErrorResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/"><Error><Type>Sender</Type><Code>Throttling</Code><Message>Rate exceeded</Message></Error><RequestId>1</RequestId></ErrorResponse>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<?xml version="1.0"?>
+<ListHostedZonesResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/"><HostedZones></HostedZones><IsTruncated>false</IsTruncated><MaxItems>100</MaxItems></ListHostedZonesResponse>`))
+	}))
+	defer server.Close()
+
+	limiter := NewAdaptiveRateLimiter(map[string]float64{"ListHostedZones": 100}, 0)
+	client := route53.NewFromConfig(awsv2.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		Retryer: func() awsv2.Retryer {
+			return limiter.Retryer(retry.AddWithMaxAttempts(retry.NewStandard(func(o *retry.StandardOptions) {
+				o.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) { return 0, nil })
+			}), 3))
+		},
+	}, Route53Options(server.URL)...)
+
+	_, err := client.ListHostedZones(context.Background(), &route53.ListHostedZonesInput{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+	assert.Equal(t, 2.0, testutil.ToFloat64(apiThrottledTotal.WithLabelValues("ListHostedZones")))
+	// halved twice, then increased by the successful attempt
+	assert.Equal(t, 30.0, testutil.ToFloat64(apiRate.WithLabelValues("ListHostedZones")))
+}
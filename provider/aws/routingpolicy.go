@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// routingPolicy is a routing policy of Route53 and the properties of the endpoints setting it.
+type routingPolicy struct {
+	name       string
+	properties []string
+}
+
+// routingPolicies are the routing policies in the order in which they are read from the records.
+var routingPolicies = []routingPolicy{
+	{name: "weighted", properties: []string{providerSpecificWeight}},
+	{name: "latency", properties: []string{providerSpecificRegion}},
+	{name: "failover", properties: []string{providerSpecificFailover}},
+	{name: "multivalue answer", properties: []string{providerSpecificMultiValueAnswer}},
+	{name: "geolocation", properties: []string{providerSpecificGeolocationContinentCode, providerSpecificGeolocationCountryCode,
+		providerSpecificGeolocationSubdivisionCode}},
+}
+
+// set returns whether the endpoint has any of the properties of the routing policy.
+func (r routingPolicy) set(ep *endpoint.Endpoint) bool {
+	for _, name := range r.properties {
+		if _, ok := ep.GetProviderSpecificProperty(name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// adjustRoutingPolicy keeps the first routing policy of the endpoint, as a record has at most one, and normalizes
+// its properties as Route53 returns them, so that the endpoint equals its record once created. The routing policy
+// of an endpoint without set identifier is ignored, as Route53 requires one.
+func adjustRoutingPolicy(ep *endpoint.Endpoint) {
+	var policy *routingPolicy
+	for i, r := range routingPolicies {
+		if !r.set(ep) {
+			continue
+		}
+		switch {
+		case ep.SetIdentifier == "":
+			log.Warnf("Ignoring the %s routing policy of %s, which has no set identifier", r.name, ep.DNSName)
+		case policy != nil:
+			log.Warnf("Ignoring the %s routing policy of %s, which has the %s routing policy", r.name, ep.DNSName, policy.name)
+		default:
+			policy = &routingPolicies[i]
+			continue
+		}
+		for _, name := range r.properties {
+			ep.DeleteProviderSpecificProperty(name)
+		}
+	}
+
+	if region, ok := ep.GetProviderSpecificProperty(providerSpecificRegion); ok {
+		ep.SetProviderSpecificProperty(providerSpecificRegion, strings.ToLower(region))
+	}
+	for _, name := range []string{providerSpecificGeolocationContinentCode, providerSpecificGeolocationCountryCode,
+		providerSpecificGeolocationSubdivisionCode} {
+		if code, ok := ep.GetProviderSpecificProperty(name); ok {
+			ep.SetProviderSpecificProperty(name, strings.ToUpper(code))
+		}
+	}
+	if _, ok := ep.GetProviderSpecificProperty(providerSpecificGeolocationContinentCode); ok {
+		// a location is either a continent or a country, optionally with a subdivision
+		for _, name := range []string{providerSpecificGeolocationCountryCode, providerSpecificGeolocationSubdivisionCode} {
+			if _, ok := ep.GetProviderSpecificProperty(name); ok {
+				log.Warnf("Ignoring the %s property of %s, which has a continent code", name, ep.DNSName)
+				ep.DeleteProviderSpecificProperty(name)
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+func TestAdjustRoutingPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		endpoint *endpoint.Endpoint
+		expected *endpoint.Endpoint
+	}{
+		{
+			name: "latency",
+			endpoint: endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4").WithSetIdentifier("eu").
+				WithProviderSpecific(providerSpecificRegion, "EU-West-1"),
+			expected: endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4").WithSetIdentifier("eu").
+				WithProviderSpecific(providerSpecificRegion, "eu-west-1"),
+		},
+		{
+			name: "geolocation country and subdivision",
+			endpoint: endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4").WithSetIdentifier("us-ca").
+				WithProviderSpecific(providerSpecificGeolocationCountryCode, "us").
+				WithProviderSpecific(providerSpecificGeolocationSubdivisionCode, "ca"),
+			expected: endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4").WithSetIdentifier("us-ca").
+				WithProviderSpecific(providerSpecificGeolocationCountryCode, "US").
+				WithProviderSpecific(providerSpecificGeolocationSubdivisionCode, "CA"),
+		},
+		{
+			name: "geolocation default location",
+			endpoint: endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4").WithSetIdentifier("default").
+				WithProviderSpecific(providerSpecificGeolocationCountryCode, "*"),
+			expected: endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4").WithSetIdentifier("default").
+				WithProviderSpecific(providerSpecificGeolocationCountryCode, "*"),
+		},
+		{
+			name: "geolocation continent and country",
+			endpoint: endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4").WithSetIdentifier("eu").
+				WithProviderSpecific(providerSpecificGeolocationContinentCode, "eu").
+				WithProviderSpecific(providerSpecificGeolocationCountryCode, "DE"),
+			expected: endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4").WithSetIdentifier("eu").
+				WithProviderSpecific(providerSpecificGeolocationContinentCode, "EU"),
+		},
+		{
+			name: "several routing policies",
+			endpoint: endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4").WithSetIdentifier("eu").
+				WithProviderSpecific(providerSpecificGeolocationCountryCode, "DE").
+				WithProviderSpecific(providerSpecificRegion, "eu-central-1").
+				WithProviderSpecific(providerSpecificHealthCheckID, "check"),
+			expected: endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4").WithSetIdentifier("eu").
+				WithProviderSpecific(providerSpecificRegion, "eu-central-1").
+				WithProviderSpecific(providerSpecificHealthCheckID, "check"),
+		},
+		{
+			name: "no set identifier",
+			endpoint: endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4").
+				WithProviderSpecific(providerSpecificRegion, "eu-central-1").
+				WithProviderSpecific(providerSpecificGeolocationContinentCode, "EU"),
+			expected: endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			adjustRoutingPolicy(tc.endpoint)
+			assert.ElementsMatch(t, tc.expected.ProviderSpecific, tc.endpoint.ProviderSpecific)
+		})
+	}
+}
+
+func TestAWSRoutingPoliciesOfClusters(t *testing.T) {
+	ctx := context.Background()
+	p, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+
+	// each cluster manages the records of its own set identifier
+	sync := func(setIdentifier string, desired ...*endpoint.Endpoint) *plan.Changes {
+		t.Helper()
+		records, err := p.Records(ctx)
+		require.NoError(t, err)
+		var current []*endpoint.Endpoint
+		for _, r := range records {
+			if r.SetIdentifier == setIdentifier {
+				current = append(current, r)
+			}
+		}
+		desired, err = p.AdjustEndpoints(desired)
+		require.NoError(t, err)
+		changes := (&plan.Plan{
+			Current:            current,
+			Desired:            desired,
+			Policies:           []plan.Policy{&plan.SyncPolicy{}},
+			ManagedRecords:     []string{endpoint.RecordTypeA},
+			PropertyComparator: p.PropertyValuesEqual,
+		}).Calculate().Changes
+		require.NoError(t, p.ApplyChanges(ctx, changes))
+		return changes
+	}
+	latency := func(region, target string) *endpoint.Endpoint {
+		return endpoint.NewEndpoint("latency.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, target).
+			WithSetIdentifier(region).
+			WithProviderSpecific(providerSpecificRegion, region)
+	}
+	geolocation := func(setIdentifier, target string, properties ...string) *endpoint.Endpoint {
+		ep := endpoint.NewEndpoint("geo.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, target).
+			WithSetIdentifier(setIdentifier)
+		for i := 0; i < len(properties); i += 2 {
+			ep.WithProviderSpecific(properties[i], properties[i+1])
+		}
+		return ep
+	}
+
+	assert.Len(t, sync("us-east-1", latency("us-east-1", "1.1.1.1"),
+		geolocation("us-east-1", "1.1.1.1", providerSpecificGeolocationCountryCode, "us")).Create, 2)
+	assert.Len(t, sync("eu-west-1", latency("eu-west-1", "2.2.2.2"),
+		geolocation("eu-west-1", "2.2.2.2", providerSpecificGeolocationContinentCode, "EU", providerSpecificGeolocationCountryCode, "FR")).Create, 2)
+
+	records := listAWSRecords(t, p.clients[defaultAWSProfile], "/hostedzone/zone-1.ext-dns-test-2.teapot.zalan.do.")
+	routing := map[string]string{}
+	for _, r := range records {
+		if r.SetIdentifier == nil {
+			continue
+		}
+		key := aws.ToString(r.Name) + " " + aws.ToString(r.SetIdentifier)
+		switch {
+		case r.Region != "":
+			routing[key] = string(r.Region)
+		case r.GeoLocation != nil:
+			routing[key] = aws.ToString(r.GeoLocation.ContinentCode) + "/" + aws.ToString(r.GeoLocation.CountryCode)
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"latency.zone-1.ext-dns-test-2.teapot.zalan.do. us-east-1": string(route53types.ResourceRecordSetRegionUsEast1),
+		"latency.zone-1.ext-dns-test-2.teapot.zalan.do. eu-west-1": string(route53types.ResourceRecordSetRegionEuWest1),
+		"geo.zone-1.ext-dns-test-2.teapot.zalan.do. us-east-1":     "/US",
+		"geo.zone-1.ext-dns-test-2.teapot.zalan.do. eu-west-1":     "EU/",
+	}, routing)
+
+	// the records match the desired endpoints of the clusters
+	assert.False(t, sync("us-east-1", latency("us-east-1", "1.1.1.1"),
+		geolocation("us-east-1", "1.1.1.1", providerSpecificGeolocationCountryCode, "us")).HasChanges())
+	assert.False(t, sync("eu-west-1", latency("eu-west-1", "2.2.2.2"),
+		geolocation("eu-west-1", "2.2.2.2", providerSpecificGeolocationContinentCode, "EU", providerSpecificGeolocationCountryCode, "FR")).HasChanges())
+
+	// a change of location replaces the record of the cluster
+	changes := sync("eu-west-1", latency("eu-west-1", "2.2.2.2"),
+		geolocation("eu-west-1", "2.2.2.2", providerSpecificGeolocationCountryCode, "FR"))
+	assert.Len(t, changes.UpdateNew, 1)
+	assert.False(t, sync("eu-west-1", latency("eu-west-1", "2.2.2.2"),
+		geolocation("eu-west-1", "2.2.2.2", providerSpecificGeolocationCountryCode, "FR")).HasChanges())
+}
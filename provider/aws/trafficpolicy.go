@@ -0,0 +1,476 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	// providerSpecificTrafficPolicyDocument is the traffic policy document routing the record, which ExternalDNS
+	// creates a traffic policy and a policy instance for instead of the record sets.
+	providerSpecificTrafficPolicyDocument = "aws/traffic-policy-document"
+
+	// trafficPolicyNamePrefix starts the names of the traffic policies managed by ExternalDNS, followed by the ID of the
+	// hosted zone, the name and the type of the record.
+	trafficPolicyNamePrefix = "external-dns-"
+)
+
+// trafficPolicyDocument is the part of a traffic policy document read by ExternalDNS.
+type trafficPolicyDocument struct {
+	RecordType string `json:"RecordType"`
+	Endpoints  map[string]struct {
+		Value string `json:"Value"`
+	} `json:"Endpoints"`
+}
+
+// parseTrafficPolicyDocument returns the record type of the document and the values of its endpoints, sorted.
+func parseTrafficPolicyDocument(document string) (string, []string, error) {
+	var doc trafficPolicyDocument
+	if err := json.Unmarshal([]byte(document), &doc); err != nil {
+		return "", nil, fmt.Errorf("invalid traffic policy document: %w", err)
+	}
+	if doc.RecordType == "" {
+		return "", nil, errors.New("the traffic policy document has no RecordType")
+	}
+	var values []string
+	for _, ep := range doc.Endpoints {
+		if ep.Value != "" && !slices.Contains(values, ep.Value) {
+			values = append(values, ep.Value)
+		}
+	}
+	if len(values) == 0 {
+		return "", nil, errors.New("the traffic policy document has no endpoint value")
+	}
+	slices.Sort(values)
+	return doc.RecordType, values, nil
+}
+
+// trafficPolicyDocumentsEqual returns true if the documents are the same JSON values, regardless of the formatting
+// and of the order of the keys.
+func trafficPolicyDocumentsEqual(a, b string) bool {
+	var va, vb interface{}
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return a == b
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return bytes.Equal(ja, jb)
+}
+
+// trafficPolicyName returns the name of the traffic policy of a record in a zone.
+func trafficPolicyName(zoneID string, ep *endpoint.Endpoint) string {
+	return trafficPolicyNamePrefix + cleanZoneID(zoneID) + "-" + strings.TrimSuffix(ep.DNSName, ".") + "-" + ep.RecordType
+}
+
+// trafficPolicyInstanceKey identifies the policy instance of a record in a zone.
+func trafficPolicyInstanceKey(zoneID, name, recordType string) string {
+	return cleanZoneID(zoneID) + "#" + strings.ToLower(provider.EnsureTrailingDot(name)) + "#" + recordType
+}
+
+// adjustTrafficPolicy sets the targets and the type of the endpoint with a traffic policy document to the endpoint
+// values and the record type of the document, and returns false if the endpoint is to be ignored because the traffic
+// policies are not managed or the document is invalid.
+func (p *AWSProvider) adjustTrafficPolicy(ep *endpoint.Endpoint) bool {
+	document, ok := ep.GetProviderSpecificProperty(providerSpecificTrafficPolicyDocument)
+	if !ok {
+		return true
+	}
+	if !p.manageTrafficPolicies {
+		log.Warnf("Ignoring %s, which has a traffic policy document while traffic policies are not managed", ep.DNSName)
+		return false
+	}
+	recordType, values, err := parseTrafficPolicyDocument(document)
+	if err != nil {
+		log.Warnf("Ignoring %s: %v", ep.DNSName, err)
+		return false
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(document)); err == nil {
+		ep.SetProviderSpecificProperty(providerSpecificTrafficPolicyDocument, compact.String())
+	}
+	ep.RecordType = recordType
+	ep.Targets = values
+	return true
+}
+
+// trafficPolicies caches the traffic policy instances managed by ExternalDNS, listed with the records,
+// and the documents of the versions of their traffic policies.
+type trafficPolicies struct {
+	mu sync.Mutex
+	// instances are the policy instances by hosted zone ID, name and type
+	instances map[string]route53types.TrafficPolicyInstance
+	// documents are the versions of the traffic policies by ID and version, which are immutable
+	documents map[string]route53types.TrafficPolicy
+}
+
+// managesInstance returns true if the policy instance was created by ExternalDNS.
+func (tp *trafficPolicies) managesInstance(id string) bool {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	for _, instance := range tp.instances {
+		if aws.ToString(instance.Id) == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (tp *trafficPolicies) instance(key string) (route53types.TrafficPolicyInstance, bool) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	instance, ok := tp.instances[key]
+	return instance, ok
+}
+
+func (tp *trafficPolicies) setInstance(key string, instance *route53types.TrafficPolicyInstance) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	if tp.instances == nil {
+		tp.instances = map[string]route53types.TrafficPolicyInstance{}
+	}
+	if instance == nil {
+		delete(tp.instances, key)
+	} else {
+		tp.instances[key] = *instance
+	}
+}
+
+// trafficPolicyVersion returns the version of the traffic policy, fetched once.
+func (p *AWSProvider) trafficPolicyVersion(ctx context.Context, client Route53API, id string, version int32) (route53types.TrafficPolicy, error) {
+	key := fmt.Sprintf("%s/%d", id, version)
+	p.trafficPolicies.mu.Lock()
+	policy, ok := p.trafficPolicies.documents[key]
+	p.trafficPolicies.mu.Unlock()
+	if ok {
+		return policy, nil
+	}
+	resp, err := client.GetTrafficPolicy(ctx, &route53.GetTrafficPolicyInput{Id: aws.String(id), Version: aws.Int32(version)})
+	if err != nil {
+		return route53types.TrafficPolicy{}, err
+	}
+	p.cacheTrafficPolicyVersion(*resp.TrafficPolicy)
+	return *resp.TrafficPolicy, nil
+}
+
+func (p *AWSProvider) cacheTrafficPolicyVersion(policy route53types.TrafficPolicy) {
+	p.trafficPolicies.mu.Lock()
+	defer p.trafficPolicies.mu.Unlock()
+	if p.trafficPolicies.documents == nil {
+		p.trafficPolicies.documents = map[string]route53types.TrafficPolicy{}
+	}
+	p.trafficPolicies.documents[fmt.Sprintf("%s/%d", aws.ToString(policy.Id), aws.ToInt32(policy.Version))] = policy
+}
+
+// listTrafficPolicyInstances lists the policy instances of the traffic policies managed by ExternalDNS in the zones,
+// and returns their endpoints, with the document of their traffic policy version.
+func (p *AWSProvider) listTrafficPolicyInstances(ctx context.Context, zones map[string]*profiledZone) ([]*endpoint.Endpoint, error) {
+	instances := map[string]route53types.TrafficPolicyInstance{}
+	var endpoints []*endpoint.Endpoint
+	for _, z := range zones {
+		client := p.clients[z.profile]
+		input := &route53.ListTrafficPolicyInstancesByHostedZoneInput{HostedZoneId: z.zone.Id}
+		for {
+			resp, err := client.ListTrafficPolicyInstancesByHostedZone(ctx, input)
+			var noInstance *route53types.NoSuchTrafficPolicyInstance
+			if errors.As(err, &noInstance) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to list traffic policy instances for zone %s using aws profile %q: %w", *z.zone.Id, z.profile, err)
+			}
+			for _, instance := range resp.TrafficPolicyInstances {
+				policy, err := p.trafficPolicyVersion(ctx, client, aws.ToString(instance.TrafficPolicyId), aws.ToInt32(instance.TrafficPolicyVersion))
+				if err != nil {
+					return nil, fmt.Errorf("failed to get traffic policy %s: %w", aws.ToString(instance.TrafficPolicyId), err)
+				}
+				if !strings.HasPrefix(aws.ToString(policy.Name), trafficPolicyNamePrefix) {
+					continue
+				}
+				instances[trafficPolicyInstanceKey(*z.zone.Id, aws.ToString(instance.Name), string(instance.TrafficPolicyType))] = instance
+
+				_, values, err := parseTrafficPolicyDocument(aws.ToString(policy.Document))
+				if err != nil {
+					log.Warnf("Ignoring the traffic policy instance %s: %v", aws.ToString(instance.Id), err)
+					continue
+				}
+				endpoints = append(endpoints, endpoint.NewEndpointWithTTL(
+					strings.TrimSuffix(aws.ToString(instance.Name), "."),
+					string(instance.TrafficPolicyType),
+					endpoint.TTL(aws.ToInt64(instance.TTL)),
+					values...,
+				).WithProviderSpecific(providerSpecificTrafficPolicyDocument, aws.ToString(policy.Document)))
+			}
+			if !resp.IsTruncated {
+				break
+			}
+			input.TrafficPolicyInstanceNameMarker = resp.TrafficPolicyInstanceNameMarker
+			input.TrafficPolicyInstanceTypeMarker = resp.TrafficPolicyInstanceTypeMarker
+		}
+	}
+
+	p.trafficPolicies.mu.Lock()
+	defer p.trafficPolicies.mu.Unlock()
+	p.trafficPolicies.instances = instances
+	return endpoints, nil
+}
+
+// splitTrafficPolicyChanges separates the endpoints with a traffic policy document from the changes: the policy
+// instances of the deleted and of the updated endpoints no longer having a document are deleted, and the instances of
+// the created and updated endpoints with a document are created or updated. An endpoint changed from or to a document
+// is otherwise created or deleted as a record.
+func splitTrafficPolicyChanges(changes *plan.Changes) (deleted, ensured []*endpoint.Endpoint, remaining *plan.Changes) {
+	hasDocument := func(ep *endpoint.Endpoint) bool {
+		_, ok := ep.GetProviderSpecificProperty(providerSpecificTrafficPolicyDocument)
+		return ok
+	}
+	remaining = &plan.Changes{}
+	for _, ep := range changes.Create {
+		if hasDocument(ep) {
+			ensured = append(ensured, ep)
+		} else {
+			remaining.Create = append(remaining.Create, ep)
+		}
+	}
+	for i, ep := range changes.UpdateNew {
+		var old *endpoint.Endpoint
+		if i < len(changes.UpdateOld) {
+			old = changes.UpdateOld[i]
+		}
+		switch {
+		case old != nil && hasDocument(old) && hasDocument(ep):
+			ensured = append(ensured, ep)
+		case old != nil && hasDocument(old):
+			deleted = append(deleted, old)
+			remaining.Create = append(remaining.Create, ep)
+		case hasDocument(ep):
+			if old != nil {
+				remaining.Delete = append(remaining.Delete, old)
+			}
+			ensured = append(ensured, ep)
+		default:
+			remaining.UpdateNew = append(remaining.UpdateNew, ep)
+			if old != nil {
+				remaining.UpdateOld = append(remaining.UpdateOld, old)
+			}
+		}
+	}
+	for _, ep := range changes.Delete {
+		if hasDocument(ep) {
+			deleted = append(deleted, ep)
+		} else {
+			remaining.Delete = append(remaining.Delete, ep)
+		}
+	}
+	return deleted, ensured, remaining
+}
+
+// deleteTrafficPolicyInstances deletes the policy instances of the endpoints and the versions of their traffic
+// policies, and returns whether some failed.
+func (p *AWSProvider) deleteTrafficPolicyInstances(ctx context.Context, zones map[string]*profiledZone, endpoints []*endpoint.Endpoint) bool {
+	failed := false
+	for _, ep := range endpoints {
+		for _, zone := range suitableZones(provider.EnsureTrailingDot(ep.DNSName), zones) {
+			key := trafficPolicyInstanceKey(*zone.zone.Id, ep.DNSName, ep.RecordType)
+			instance, ok := p.trafficPolicies.instance(key)
+			if !ok {
+				continue
+			}
+			log.Infof("Deleting the traffic policy instance %s of %s %s", aws.ToString(instance.Id), ep.DNSName, ep.RecordType)
+			if p.dryRun {
+				continue
+			}
+			client := p.clients[zone.profile]
+			if _, err := client.DeleteTrafficPolicyInstance(ctx, &route53.DeleteTrafficPolicyInstanceInput{Id: instance.Id}); err != nil {
+				log.Errorf("Failed to delete the traffic policy instance %s of %s: %v", aws.ToString(instance.Id), ep.DNSName, err)
+				failed = true
+				continue
+			}
+			p.trafficPolicies.setInstance(key, nil)
+			p.deleteTrafficPolicyVersion(ctx, client, aws.ToString(instance.TrafficPolicyId), aws.ToInt32(instance.TrafficPolicyVersion))
+		}
+	}
+	return failed
+}
+
+// ensureTrafficPolicyInstances creates or updates the policy instances of the endpoints in the first suitable zone,
+// and returns whether some failed.
+func (p *AWSProvider) ensureTrafficPolicyInstances(ctx context.Context, zones map[string]*profiledZone, endpoints []*endpoint.Endpoint) bool {
+	failed := false
+	for _, ep := range endpoints {
+		matching := suitableZones(provider.EnsureTrailingDot(ep.DNSName), zones)
+		if len(matching) == 0 {
+			log.Debugf("Skipping the traffic policy of %s because no hosted zone matching record DNS Name was detected", ep.DNSName)
+			continue
+		}
+		if err := p.ensureTrafficPolicyInstance(ctx, matching[0], ep); err != nil {
+			log.Errorf("Failed to create or update the traffic policy instance of %s: %v", ep.DNSName, err)
+			failed = true
+		}
+	}
+	return failed
+}
+
+// ensureTrafficPolicyInstance creates the traffic policy and the policy instance of the endpoint, or updates the
+// instance to a new version of its traffic policy when the document changed, deleting the previous version.
+func (p *AWSProvider) ensureTrafficPolicyInstance(ctx context.Context, zone *profiledZone, ep *endpoint.Endpoint) error {
+	document, _ := ep.GetProviderSpecificProperty(providerSpecificTrafficPolicyDocument)
+	ttl := int64(recordTTL)
+	if ep.RecordTTL.IsConfigured() {
+		ttl = int64(ep.RecordTTL)
+	}
+	client := p.clients[zone.profile]
+	key := trafficPolicyInstanceKey(*zone.zone.Id, ep.DNSName, ep.RecordType)
+
+	instance, ok := p.trafficPolicies.instance(key)
+	if ok {
+		policyID, version := aws.ToString(instance.TrafficPolicyId), aws.ToInt32(instance.TrafficPolicyVersion)
+		current, err := p.trafficPolicyVersion(ctx, client, policyID, version)
+		if err != nil {
+			return err
+		}
+		newVersion := version
+		if !trafficPolicyDocumentsEqual(aws.ToString(current.Document), document) {
+			log.Infof("Creating a new version of the traffic policy %s of %s", policyID, ep.DNSName)
+			if p.dryRun {
+				return nil
+			}
+			resp, err := client.CreateTrafficPolicyVersion(ctx, &route53.CreateTrafficPolicyVersionInput{
+				Id:       aws.String(policyID),
+				Document: aws.String(document),
+			})
+			if err != nil {
+				return err
+			}
+			p.cacheTrafficPolicyVersion(*resp.TrafficPolicy)
+			newVersion = aws.ToInt32(resp.TrafficPolicy.Version)
+		} else if aws.ToInt64(instance.TTL) == ttl {
+			return nil
+		}
+
+		log.Infof("Updating the traffic policy instance %s of %s to version %d", aws.ToString(instance.Id), ep.DNSName, newVersion)
+		if p.dryRun {
+			return nil
+		}
+		resp, err := client.UpdateTrafficPolicyInstance(ctx, &route53.UpdateTrafficPolicyInstanceInput{
+			Id:                   instance.Id,
+			TTL:                  aws.Int64(ttl),
+			TrafficPolicyId:      aws.String(policyID),
+			TrafficPolicyVersion: aws.Int32(newVersion),
+		})
+		if err != nil {
+			return err
+		}
+		p.trafficPolicies.setInstance(key, resp.TrafficPolicyInstance)
+		if newVersion != version {
+			p.deleteTrafficPolicyVersion(ctx, client, policyID, version)
+		}
+		return nil
+	}
+
+	name := trafficPolicyName(*zone.zone.Id, ep)
+	log.Infof("Creating the traffic policy %s", name)
+	if p.dryRun {
+		return nil
+	}
+	policy, err := p.createTrafficPolicy(ctx, client, name, document)
+	if err != nil {
+		return err
+	}
+	log.Infof("Creating the traffic policy instance of %s %s", ep.DNSName, ep.RecordType)
+	resp, err := client.CreateTrafficPolicyInstance(ctx, &route53.CreateTrafficPolicyInstanceInput{
+		HostedZoneId:         zone.zone.Id,
+		Name:                 aws.String(provider.EnsureTrailingDot(ep.DNSName)),
+		TTL:                  aws.Int64(ttl),
+		TrafficPolicyId:      policy.Id,
+		TrafficPolicyVersion: policy.Version,
+	})
+	if err != nil {
+		return err
+	}
+	p.trafficPolicies.setInstance(key, resp.TrafficPolicyInstance)
+	return nil
+}
+
+// createTrafficPolicy creates the traffic policy with the document, or a new version of the traffic policy of the
+// same name left behind without instance.
+func (p *AWSProvider) createTrafficPolicy(ctx context.Context, client Route53API, name, document string) (*route53types.TrafficPolicy, error) {
+	resp, err := client.CreateTrafficPolicy(ctx, &route53.CreateTrafficPolicyInput{
+		Name:     aws.String(name),
+		Document: aws.String(document),
+	})
+	if err == nil {
+		p.cacheTrafficPolicyVersion(*resp.TrafficPolicy)
+		return resp.TrafficPolicy, nil
+	}
+	var exists *route53types.TrafficPolicyAlreadyExists
+	if !errors.As(err, &exists) {
+		return nil, err
+	}
+	createErr := err
+
+	input := &route53.ListTrafficPoliciesInput{}
+	for {
+		list, err := client.ListTrafficPolicies(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, summary := range list.TrafficPolicySummaries {
+			if aws.ToString(summary.Name) != name {
+				continue
+			}
+			resp, err := client.CreateTrafficPolicyVersion(ctx, &route53.CreateTrafficPolicyVersionInput{
+				Id:       summary.Id,
+				Document: aws.String(document),
+			})
+			if err != nil {
+				return nil, err
+			}
+			p.cacheTrafficPolicyVersion(*resp.TrafficPolicy)
+			return resp.TrafficPolicy, nil
+		}
+		if !list.IsTruncated {
+			return nil, createErr
+		}
+		input.TrafficPolicyIdMarker = list.TrafficPolicyIdMarker
+	}
+}
+
+// deleteTrafficPolicyVersion deletes the version of the traffic policy no longer used by its instance. A failure is
+// only logged, the version being left behind.
+func (p *AWSProvider) deleteTrafficPolicyVersion(ctx context.Context, client Route53API, id string, version int32) {
+	log.Infof("Deleting the version %d of the traffic policy %s", version, id)
+	if _, err := client.DeleteTrafficPolicy(ctx, &route53.DeleteTrafficPolicyInput{Id: aws.String(id), Version: aws.Int32(version)}); err != nil {
+		log.Warnf("Failed to delete the version %d of the traffic policy %s: %v", version, id, err)
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// failoverDocument returns a traffic policy document failing over from the primary to the secondary value.
+func failoverDocument(primary, secondary string) string {
+	return fmt.Sprintf(`{
+  "AWSPolicyFormatVersion": "2015-10-01",
+  "RecordType": "A",
+  "StartRule": "failover",
+  "Endpoints": {
+    "primary": {"Type": "value", "Value": %q},
+    "secondary": {"Type": "value", "Value": %q}
+  },
+  "Rules": {
+    "failover": {
+      "RuleType": "failover",
+      "Primary": {"EndpointReference": "primary"},
+      "Secondary": {"EndpointReference": "secondary"}
+    }
+  }
+}`, primary, secondary)
+}
+
+func TestParseTrafficPolicyDocument(t *testing.T) {
+	recordType, values, err := parseTrafficPolicyDocument(failoverDocument("1.2.3.5", "1.2.3.4"))
+	require.NoError(t, err)
+	assert.Equal(t, "A", recordType)
+	assert.Equal(t, []string{"1.2.3.4", "1.2.3.5"}, values)
+
+	for _, document := range []string{
+		`{"RecordType": "A"`,
+		`{"Endpoints": {"a": {"Type": "value", "Value": "1.2.3.4"}}}`,
+		`{"RecordType": "A", "Endpoints": {}}`,
+	} {
+		_, _, err := parseTrafficPolicyDocument(document)
+		assert.Error(t, err, document)
+	}
+}
+
+func TestTrafficPolicyDocumentsEqual(t *testing.T) {
+	assert.True(t, trafficPolicyDocumentsEqual(`{"RecordType":"A","StartEndpoint":"a"}`, "{\n  \"StartEndpoint\": \"a\",\n  \"RecordType\": \"A\"\n}"))
+	assert.False(t, trafficPolicyDocumentsEqual(`{"RecordType":"A"}`, `{"RecordType":"AAAA"}`))
+}
+
+func TestAWSAdjustEndpointsTrafficPolicy(t *testing.T) {
+	p, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+	p.manageTrafficPolicies = true
+
+	endpoints, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("policy.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "lb.example.com").
+			WithProviderSpecific(providerSpecificTrafficPolicyDocument, failoverDocument("1.2.3.4", "1.2.3.5")),
+		endpoint.NewEndpoint("invalid.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificTrafficPolicyDocument, `{"RecordType": "A"}`),
+		endpoint.NewEndpoint("record.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4"),
+	})
+	require.NoError(t, err)
+	require.Len(t, endpoints, 2)
+	// the type and targets are the ones of the document, which is compacted, and the record is not an alias
+	assert.Equal(t, endpoint.RecordTypeA, endpoints[0].RecordType)
+	assert.Equal(t, endpoint.Targets{"1.2.3.4", "1.2.3.5"}, endpoints[0].Targets)
+	assert.Len(t, endpoints[0].ProviderSpecific, 1)
+	document, _ := endpoints[0].GetProviderSpecificProperty(providerSpecificTrafficPolicyDocument)
+	assert.NotContains(t, document, "\n")
+	assert.Equal(t, "record.zone-1.ext-dns-test-2.teapot.zalan.do", endpoints[1].DNSName)
+
+	// the endpoints with a document are ignored if the traffic policies are not managed
+	p.manageTrafficPolicies = false
+	endpoints, err = p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("policy.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificTrafficPolicyDocument, failoverDocument("1.2.3.4", "1.2.3.5")),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, endpoints)
+}
+
+func TestAWSTrafficPolicyInstances(t *testing.T) {
+	p, stub := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+	p.manageTrafficPolicies = true
+	ctx := context.Background()
+	const zoneID = "/hostedzone/zone-1.ext-dns-test-2.teapot.zalan.do."
+
+	desired, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("policy.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, 60, "1.2.3.4").
+			WithProviderSpecific(providerSpecificTrafficPolicyDocument, failoverDocument("1.2.3.4", "1.2.3.5")),
+	})
+	require.NoError(t, err)
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Create: desired}))
+
+	require.Len(t, stub.trafficPolicyInstances, 1)
+	instance := stub.trafficPolicyInstances["tpi-1"]
+	assert.Equal(t, zoneID, *instance.HostedZoneId)
+	assert.Equal(t, "policy.zone-1.ext-dns-test-2.teapot.zalan.do.", *instance.Name)
+	assert.Equal(t, int64(60), *instance.TTL)
+	assert.Equal(t, "external-dns-zone-1.ext-dns-test-2.teapot.zalan.do.-policy.zone-1.ext-dns-test-2.teapot.zalan.do-A", *stub.trafficPolicies["tp-1"][0].Name)
+
+	// the policy instance is listed instead of the record set it created, and compares to the desired endpoint
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	var listed []*endpoint.Endpoint
+	for _, r := range records {
+		if r.DNSName == "policy.zone-1.ext-dns-test-2.teapot.zalan.do" {
+			listed = append(listed, r)
+		}
+	}
+	require.Len(t, listed, 1)
+	assert.Equal(t, endpoint.Targets{"1.2.3.4", "1.2.3.5"}, listed[0].Targets)
+	assert.Equal(t, endpoint.TTL(60), listed[0].RecordTTL)
+	current, _ := listed[0].GetProviderSpecificProperty(providerSpecificTrafficPolicyDocument)
+	wanted, _ := desired[0].GetProviderSpecificProperty(providerSpecificTrafficPolicyDocument)
+	assert.True(t, p.PropertyValuesEqual(providerSpecificTrafficPolicyDocument, wanted, current))
+
+	// a changed document is a new version of the traffic policy, the previous one being deleted
+	updated, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("policy.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, 60, "1.2.3.4").
+			WithProviderSpecific(providerSpecificTrafficPolicyDocument, failoverDocument("1.2.3.4", "1.2.3.6")),
+	})
+	require.NoError(t, err)
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{UpdateOld: listed, UpdateNew: updated}))
+	require.Len(t, stub.trafficPolicies["tp-1"], 1)
+	assert.Equal(t, int32(2), *stub.trafficPolicies["tp-1"][0].Version)
+	assert.Equal(t, int32(2), *stub.trafficPolicyInstances["tpi-1"].TrafficPolicyVersion)
+
+	// a changed TTL updates the instance only
+	_, err = p.Records(ctx)
+	require.NoError(t, err)
+	longer := updated[0].DeepCopy()
+	longer.RecordTTL = 300
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{UpdateOld: updated, UpdateNew: []*endpoint.Endpoint{longer}}))
+	assert.Equal(t, int64(300), *stub.trafficPolicyInstances["tpi-1"].TTL)
+	assert.Equal(t, int32(2), *stub.trafficPolicyInstances["tpi-1"].TrafficPolicyVersion)
+
+	// the deleted endpoint deletes the instance and the traffic policy
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Delete: []*endpoint.Endpoint{longer}}))
+	assert.Empty(t, stub.trafficPolicyInstances)
+	assert.Empty(t, stub.trafficPolicies)
+	assert.Empty(t, stub.recordSets[zoneID])
+}
+
+func TestAWSTrafficPolicyInstancesNotManaged(t *testing.T) {
+	p, stub := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+	p.manageTrafficPolicies = true
+	ctx := context.Background()
+
+	// the instances of the traffic policies not created by ExternalDNS are listed as records
+	stub.trafficPolicies["tp-other"] = []route53types.TrafficPolicy{{
+		Id: aws.String("tp-other"), Name: aws.String("other"), Version: aws.Int32(1), Document: aws.String(failoverDocument("1.2.3.4", "1.2.3.5")),
+	}}
+	require.NoError(t, stub.applyTrafficPolicyInstance(&route53types.TrafficPolicyInstance{
+		Id:           aws.String("tpi-other"),
+		HostedZoneId: aws.String("/hostedzone/zone-1.ext-dns-test-2.teapot.zalan.do."),
+		Name:         aws.String("other.zone-1.ext-dns-test-2.teapot.zalan.do."),
+		TTL:          aws.Int64(60),
+	}, "tp-other", 1))
+
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	require.True(t, containsRecordWithDNSName(records, "other.zone-1.ext-dns-test-2.teapot.zalan.do"))
+	for _, r := range records {
+		_, ok := r.GetProviderSpecificProperty(providerSpecificTrafficPolicyDocument)
+		assert.False(t, ok, r.DNSName)
+	}
+}
+
+func TestAWSTrafficPolicyInstancesDryRun(t *testing.T) {
+	p, stub := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, true, nil)
+	p.manageTrafficPolicies = true
+
+	desired, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("policy.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificTrafficPolicyDocument, failoverDocument("1.2.3.4", "1.2.3.5")),
+	})
+	require.NoError(t, err)
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{Create: desired}))
+	assert.Empty(t, stub.trafficPolicies)
+	assert.Empty(t, stub.trafficPolicyInstances)
+}
+
+func TestSplitTrafficPolicyChanges(t *testing.T) {
+	policy := func(name string) *endpoint.Endpoint {
+		return endpoint.NewEndpoint(name, endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(providerSpecificTrafficPolicyDocument, failoverDocument("1.2.3.4", "1.2.3.5"))
+	}
+	record := func(name string) *endpoint.Endpoint {
+		return endpoint.NewEndpoint(name, endpoint.RecordTypeA, "1.2.3.4")
+	}
+
+	deleted, ensured, remaining := splitTrafficPolicyChanges(&plan.Changes{
+		Create:    []*endpoint.Endpoint{policy("a"), record("b")},
+		UpdateOld: []*endpoint.Endpoint{policy("c"), policy("d"), record("e"), record("f")},
+		UpdateNew: []*endpoint.Endpoint{policy("c"), record("d"), policy("e"), record("f")},
+		Delete:    []*endpoint.Endpoint{policy("g"), record("h")},
+	})
+	names := func(endpoints []*endpoint.Endpoint) []string {
+		var result []string
+		for _, ep := range endpoints {
+			result = append(result, ep.DNSName)
+		}
+		return result
+	}
+	assert.Equal(t, []string{"d", "g"}, names(deleted))
+	assert.Equal(t, []string{"a", "c", "e"}, names(ensured))
+	assert.Equal(t, []string{"b", "d"}, names(remaining.Create))
+	assert.Equal(t, []string{"f"}, names(remaining.UpdateOld))
+	assert.Equal(t, []string{"f"}, names(remaining.UpdateNew))
+	assert.Equal(t, []string{"e", "h"}, names(remaining.Delete))
+}
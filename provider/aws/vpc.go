@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	log "github.com/sirupsen/logrus"
+)
+
+// ZoneVPCAuto stands for the VPC of the instance ExternalDNS runs on, looked up in the instance metadata.
+const ZoneVPCAuto = "auto"
+
+// InstanceMetadataAPI is the subset of the EC2 instance metadata API looking up the VPC of the instance.
+type InstanceMetadataAPI interface {
+	GetMetadata(ctx context.Context, params *imds.GetMetadataInput, optFns ...func(*imds.Options)) (*imds.GetMetadataOutput, error)
+	GetRegion(ctx context.Context, params *imds.GetRegionInput, optFns ...func(*imds.Options)) (*imds.GetRegionOutput, error)
+}
+
+// ResolveZoneVPCs replaces the auto VPC with the VPC of the instance and its region, so that only the private hosted
+// zones of the VPC of the cluster are managed, and not those of the other environments of the account.
+func ResolveZoneVPCs(ctx context.Context, client InstanceMetadataAPI, vpcs []ZoneVPC) ([]ZoneVPC, error) {
+	var resolved []ZoneVPC
+	for _, vpc := range vpcs {
+		if vpc.ID != ZoneVPCAuto {
+			resolved = append(resolved, vpc)
+			continue
+		}
+		instance, err := instanceVPC(ctx, client)
+		if err != nil {
+			return nil, fmt.Errorf("looking up the VPC of the instance in the instance metadata, specify the VPC instead of %s if the metadata is not reachable: %w", ZoneVPCAuto, err)
+		}
+		log.Infof("Only considering the private hosted zones associated with VPC %s in %s, the VPC of the instance", instance.ID, instance.Region)
+		resolved = append(resolved, instance)
+	}
+	return resolved, nil
+}
+
+// instanceVPC returns the VPC of the primary network interface of the instance.
+func instanceVPC(ctx context.Context, client InstanceMetadataAPI) (ZoneVPC, error) {
+	mac, err := getMetadata(ctx, client, "mac")
+	if err != nil {
+		return ZoneVPC{}, err
+	}
+	id, err := getMetadata(ctx, client, "network/interfaces/macs/"+mac+"/vpc-id")
+	if err != nil {
+		return ZoneVPC{}, err
+	}
+	region, err := client.GetRegion(ctx, &imds.GetRegionInput{})
+	if err != nil {
+		return ZoneVPC{}, err
+	}
+	return ZoneVPC{Region: region.Region, ID: id}, nil
+}
+
+func getMetadata(ctx context.Context, client InstanceMetadataAPI, path string) (string, error) {
+	out, err := client.GetMetadata(ctx, &imds.GetMetadataInput{Path: path})
+	if err != nil {
+		return "", err
+	}
+	defer out.Content.Close()
+	content, err := io.ReadAll(out.Content)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeInstanceMetadata serves the metadata of the paths and the region.
+type fakeInstanceMetadata struct {
+	metadata map[string]string
+	region   string
+	err      error
+}
+
+func (m *fakeInstanceMetadata) GetMetadata(ctx context.Context, params *imds.GetMetadataInput, optFns ...func(*imds.Options)) (*imds.GetMetadataOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	value, ok := m.metadata[params.Path]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &imds.GetMetadataOutput{Content: io.NopCloser(strings.NewReader(value))}, nil
+}
+
+func (m *fakeInstanceMetadata) GetRegion(ctx context.Context, params *imds.GetRegionInput, optFns ...func(*imds.Options)) (*imds.GetRegionOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &imds.GetRegionOutput{Region: m.region}, nil
+}
+
+func TestResolveZoneVPCs(t *testing.T) {
+	metadata := &fakeInstanceMetadata{
+		metadata: map[string]string{
+			"mac": "0e:00:00:00:00:01",
+			"network/interfaces/macs/0e:00:00:00:00:01/vpc-id": "vpc-instance\n",
+		},
+		region: "eu-west-1",
+	}
+
+	vpcs, err := ResolveZoneVPCs(context.Background(), metadata, []ZoneVPC{{ID: "vpc-111"}, {ID: ZoneVPCAuto}})
+	require.NoError(t, err)
+	assert.Equal(t, []ZoneVPC{{ID: "vpc-111"}, {Region: "eu-west-1", ID: "vpc-instance"}}, vpcs)
+
+	// the metadata is not looked up without auto
+	metadata.err = errors.New("unreachable")
+	vpcs, err = ResolveZoneVPCs(context.Background(), metadata, []ZoneVPC{{ID: "vpc-111"}})
+	require.NoError(t, err)
+	assert.Equal(t, []ZoneVPC{{ID: "vpc-111"}}, vpcs)
+
+	_, err = ResolveZoneVPCs(context.Background(), metadata, []ZoneVPC{{ID: ZoneVPCAuto}})
+	assert.ErrorContains(t, err, "unreachable")
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// zoneOwnerTag is the tag of the hosted zones created by ExternalDNS, set to the owner ID.
+const zoneOwnerTag = "external-dns.alpha.kubernetes.io/owner"
+
+// CreateOwnedZone creates the hosted zone tagged with the owner ID, see provider.ZoneCreator. The zone is private and
+// associated with the first VPC of --aws-zone-match-parent-vpc when only the private zones are considered, public otherwise.
+func (p *AWSProvider) CreateOwnedZone(ctx context.Context, zone, ownerID string) error {
+	profile := p.zoneCreationProfile()
+	client := p.clients[profile]
+
+	private := !p.zoneTypeFilter.Match("public")
+	input := &route53.CreateHostedZoneInput{
+		Name:            aws.String(provider.EnsureTrailingDot(zone)),
+		CallerReference: aws.String(fmt.Sprintf("external-dns-%s-%d", zone, time.Now().UnixNano())),
+		HostedZoneConfig: &route53types.HostedZoneConfig{
+			Comment:     aws.String("Managed by ExternalDNS"),
+			PrivateZone: private,
+		},
+	}
+	if private {
+		if len(p.zoneVPCs) == 0 {
+			return fmt.Errorf("creating the private hosted zone %s requires a VPC", zone)
+		}
+		vpc := p.zoneVPCs[0]
+		input.VPC = &route53types.VPC{VPCId: aws.String(vpc.ID), VPCRegion: route53types.VPCRegion(vpcRegion(vpc, client))}
+	}
+
+	if p.dryRun {
+		log.Infof("Would create the hosted zone %s", zone)
+		return nil
+	}
+	out, err := client.CreateHostedZone(ctx, input)
+	if err != nil {
+		return err
+	}
+	p.zonesCache.invalidate()
+
+	_, err = client.ChangeTagsForResource(ctx, &route53.ChangeTagsForResourceInput{
+		ResourceType: route53types.TagResourceTypeHostedzone,
+		ResourceId:   out.HostedZone.Id,
+		AddTags:      []route53types.Tag{{Key: aws.String(zoneOwnerTag), Value: aws.String(ownerID)}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to tag the hosted zone %s: %w", *out.HostedZone.Id, err)
+	}
+	return nil
+}
+
+// DeleteOwnedZone deletes the hosted zone if it is tagged with the owner ID and has no records but its SOA and NS
+// records, see provider.ZoneCreator.
+func (p *AWSProvider) DeleteOwnedZone(ctx context.Context, zone, ownerID string) (bool, error) {
+	zones, err := p.zones(ctx)
+	if err != nil {
+		return false, err
+	}
+	name := provider.EnsureTrailingDot(strings.ToLower(zone))
+	for _, z := range zones {
+		if provider.EnsureTrailingDot(strings.ToLower(*z.zone.Name)) != name {
+			continue
+		}
+		tags, err := p.tagsForZone(ctx, *z.zone.Id, z.profile)
+		if err != nil {
+			return false, err
+		}
+		if owner, ok := tags[zoneOwnerTag]; !ok || owner != ownerID {
+			return false, nil
+		}
+
+		client := p.clients[z.profile]
+		paginator := route53.NewListResourceRecordSetsPaginator(client, &route53.ListResourceRecordSetsInput{
+			HostedZoneId: z.zone.Id,
+			MaxItems:     aws.Int32(route53PageSize),
+		})
+		for paginator.HasMorePages() {
+			resp, err := paginator.NextPage(ctx)
+			if err != nil {
+				return false, err
+			}
+			for _, r := range resp.ResourceRecordSets {
+				apex := strings.EqualFold(provider.EnsureTrailingDot(*r.Name), name)
+				if !apex || (r.Type != route53types.RRTypeSoa && r.Type != route53types.RRTypeNs) {
+					return false, nil
+				}
+			}
+		}
+
+		if p.dryRun {
+			log.Infof("Would delete the hosted zone %s", zone)
+			return false, nil
+		}
+		if _, err := client.DeleteHostedZone(ctx, &route53.DeleteHostedZoneInput{Id: z.zone.Id}); err != nil {
+			return false, err
+		}
+		p.zonesCache.invalidate()
+		return true, nil
+	}
+	return false, nil
+}
+
+// zoneCreationProfile returns the profile of the client creating the hosted zones, the default one if any.
+func (p *AWSProvider) zoneCreationProfile() string {
+	if _, ok := p.clients[defaultAWSProfile]; ok {
+		return defaultAWSProfile
+	}
+	profiles := make([]string, 0, len(p.clients))
+	for profile := range p.clients {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+	return profiles[0]
+}
+
+// vpcRegion returns the region of the VPC, the one of the client if not set.
+func vpcRegion(vpc ZoneVPC, client Route53API) string {
+	if vpc.Region == "" {
+		if c, ok := client.(interface{ Options() route53.Options }); ok {
+			return c.Options().Region
+		}
+	}
+	return vpc.Region
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+func TestAWSCreateAndDeleteOwnedZone(t *testing.T) {
+	ctx := context.Background()
+	p, stub := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+	// the zones are cached
+	_, err := p.ZoneNames(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, p.CreateOwnedZone(ctx, "zone-5.ext-dns-test-2.teapot.zalan.do", "owner"))
+	id := "/hostedzone/zone-5.ext-dns-test-2.teapot.zalan.do."
+	require.Contains(t, stub.zones, id)
+	assert.False(t, stub.zones[id].Config.PrivateZone)
+	assert.Equal(t, []route53types.Tag{{Key: aws.String(zoneOwnerTag), Value: aws.String("owner")}}, stub.zoneTags[id])
+	names, err := p.ZoneNames(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, names, "zone-5.ext-dns-test-2.teapot.zalan.do")
+
+	record := endpoint.NewEndpoint("app.zone-5.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.2.3.4")
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{record}}))
+
+	// a zone with records, of another owner or not created by ExternalDNS is kept
+	deleted, err := p.DeleteOwnedZone(ctx, "zone-5.ext-dns-test-2.teapot.zalan.do", "owner")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	deleted, err = p.DeleteOwnedZone(ctx, "zone-5.ext-dns-test-2.teapot.zalan.do", "other")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	deleted, err = p.DeleteOwnedZone(ctx, "zone-1.ext-dns-test-2.teapot.zalan.do", "")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Delete: []*endpoint.Endpoint{record}}))
+	// the records created with the zone are ignored
+	setAWSRecords(t, p, []route53types.ResourceRecordSet{
+		{Name: aws.String("zone-5.ext-dns-test-2.teapot.zalan.do."), Type: route53types.RRTypeNs, TTL: aws.Int64(172800),
+			ResourceRecords: []route53types.ResourceRecord{{Value: aws.String("ns-1.awsdns.com.")}}},
+	})
+	deleted, err = p.DeleteOwnedZone(ctx, "zone-5.ext-dns-test-2.teapot.zalan.do", "owner")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	assert.NotContains(t, stub.zones, id)
+	names, err = p.ZoneNames(ctx)
+	require.NoError(t, err)
+	assert.NotContains(t, names, "zone-5.ext-dns-test-2.teapot.zalan.do")
+}
+
+func TestAWSCreateOwnedPrivateZone(t *testing.T) {
+	ctx := context.Background()
+	p, stub := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter("private"), defaultEvaluateTargetHealth, false, nil)
+
+	assert.ErrorContains(t, p.CreateOwnedZone(ctx, "zone-5.ext-dns-test-2.teapot.zalan.do", "owner"), "requires a VPC")
+
+	p.zoneVPCs = []ZoneVPC{{Region: "eu-west-1", ID: "vpc-111"}}
+	require.NoError(t, p.CreateOwnedZone(ctx, "zone-5.ext-dns-test-2.teapot.zalan.do", "owner"))
+	assert.True(t, stub.zones["/hostedzone/zone-5.ext-dns-test-2.teapot.zalan.do."].Config.PrivateZone)
+}
@@ -22,6 +22,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -43,6 +44,9 @@ const (
 	sdInstanceAttrIPV4  = "AWS_INSTANCE_IPV4"
 	sdInstanceAttrCname = "AWS_INSTANCE_CNAME"
 	sdInstanceAttrAlias = "AWS_ALIAS_DNS_NAME"
+
+	// sdNamespaceOwnerTag is the tag of the namespaces created by ExternalDNS, set to the owner ID.
+	sdNamespaceOwnerTag = "external-dns.alpha.kubernetes.io/owner"
 )
 
 var (
@@ -64,6 +68,9 @@ type AWSSDClient interface {
 	RegisterInstance(ctx context.Context, params *sd.RegisterInstanceInput, optFns ...func(*sd.Options)) (*sd.RegisterInstanceOutput, error)
 	UpdateService(ctx context.Context, params *sd.UpdateServiceInput, optFns ...func(*sd.Options)) (*sd.UpdateServiceOutput, error)
 	DeleteService(ctx context.Context, params *sd.DeleteServiceInput, optFns ...func(*sd.Options)) (*sd.DeleteServiceOutput, error)
+	CreatePublicDnsNamespace(ctx context.Context, params *sd.CreatePublicDnsNamespaceInput, optFns ...func(*sd.Options)) (*sd.CreatePublicDnsNamespaceOutput, error)
+	CreatePrivateDnsNamespace(ctx context.Context, params *sd.CreatePrivateDnsNamespaceInput, optFns ...func(*sd.Options)) (*sd.CreatePrivateDnsNamespaceOutput, error)
+	GetOperation(ctx context.Context, params *sd.GetOperationInput, optFns ...func(*sd.Options)) (*sd.GetOperationOutput, error)
 }
 
 // AWSSDProvider is an implementation of Provider for AWS Cloud Map.
@@ -79,10 +86,16 @@ type AWSSDProvider struct {
 	cleanEmptyService bool
 	// filter services for removal
 	ownerID string
+	// enables the creation of the namespaces of the domain filter the records require
+	createNamespaces bool
+	// VPC of the created namespaces, which are private if set and public otherwise
+	namespaceVPC string
+	// operations creating namespaces by namespace name
+	namespaceOperations map[string]string
 }
 
 // NewAWSSDProvider initializes a new AWS Cloud Map based Provider.
-func NewAWSSDProvider(domainFilter endpoint.DomainFilter, namespaceType string, dryRun, cleanEmptyService bool, ownerID string, client AWSSDClient) (*AWSSDProvider, error) {
+func NewAWSSDProvider(domainFilter endpoint.DomainFilter, namespaceType string, dryRun, cleanEmptyService, createNamespaces bool, namespaceVPC, ownerID string, client AWSSDClient) (*AWSSDProvider, error) {
 	p := &AWSSDProvider{
 		client:              client,
 		dryRun:              dryRun,
@@ -90,6 +103,9 @@ func NewAWSSDProvider(domainFilter endpoint.DomainFilter, namespaceType string,
 		namespaceTypeFilter: newSdNamespaceFilter(namespaceType),
 		cleanEmptyService:   cleanEmptyService,
 		ownerID:             ownerID,
+		createNamespaces:    createNamespaces,
+		namespaceVPC:        namespaceVPC,
+		namespaceOperations: map[string]string{},
 	}
 
 	return p, nil
@@ -204,6 +220,12 @@ func (p *AWSSDProvider) ApplyChanges(ctx context.Context, changes *plan.Changes)
 		return err
 	}
 
+	if p.createNamespaces {
+		if err := p.createMissingNamespaces(ctx, namespaces, changes.Create); err != nil {
+			return err
+		}
+	}
+
 	// Deletes must be executed first to support update case.
 	// When just list of targets is updated `[1.2.3.4] -> [1.2.3.4, 1.2.3.5]` it is translated to:
 	// ```
@@ -336,6 +358,80 @@ func (p *AWSSDProvider) ListNamespaces(ctx context.Context) ([]*sdtypes.Namespac
 	return namespaces, nil
 }
 
+// createMissingNamespaces creates the namespaces of the domain filter the created records require and which don't exist,
+// tagged with the owner ID. Namespaces are created asynchronously: their records are skipped until they exist, and created
+// by a later synchronization.
+func (p *AWSSDProvider) createMissingNamespaces(ctx context.Context, namespaces []*sdtypes.NamespaceSummary, changes []*endpoint.Endpoint) error {
+	for _, ns := range namespaces {
+		delete(p.namespaceOperations, *ns.Name)
+	}
+
+	for _, c := range changes {
+		nsName, _ := p.parseHostname(strings.TrimSuffix(c.DNSName, "."))
+		if len(matchingNamespaces(nsName, namespaces)) > 0 || !slices.Contains(p.namespaceFilter.Filters, nsName) {
+			continue
+		}
+
+		if operationID, ok := p.namespaceOperations[nsName]; ok {
+			out, err := p.client.GetOperation(ctx, &sd.GetOperationInput{OperationId: aws.String(operationID)})
+			if err != nil {
+				return err
+			}
+			if out.Operation.Status != sdtypes.OperationStatusFail {
+				log.Debugf("Waiting for the creation of the namespace \"%s\" (%s)", nsName, out.Operation.Status)
+				continue
+			}
+			log.Errorf("Failed to create the namespace \"%s\": %s", nsName, aws.ToString(out.Operation.ErrorMessage))
+			delete(p.namespaceOperations, nsName)
+		}
+
+		operationID, err := p.CreateNamespace(ctx, nsName)
+		if err != nil {
+			return err
+		}
+		if operationID != "" {
+			p.namespaceOperations[nsName] = operationID
+		}
+	}
+
+	return nil
+}
+
+// CreateNamespace starts the creation of a namespace, private in the VPC of the namespaces if any and public otherwise.
+// Returns the ID of the operation creating the namespace.
+func (p *AWSSDProvider) CreateNamespace(ctx context.Context, name string) (string, error) {
+	tags := []sdtypes.Tag{{Key: aws.String(sdNamespaceOwnerTag), Value: aws.String(p.ownerID)}}
+
+	if p.namespaceVPC != "" {
+		log.Infof("Creating a new private namespace \"%s\" in \"%s\"", name, p.namespaceVPC)
+		if p.dryRun {
+			return "", nil
+		}
+		out, err := p.client.CreatePrivateDnsNamespace(ctx, &sd.CreatePrivateDnsNamespaceInput{
+			Name: aws.String(name),
+			Vpc:  aws.String(p.namespaceVPC),
+			Tags: tags,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to create the namespace \"%s\": %w", name, err)
+		}
+		return aws.ToString(out.OperationId), nil
+	}
+
+	log.Infof("Creating a new public namespace \"%s\"", name)
+	if p.dryRun {
+		return "", nil
+	}
+	out, err := p.client.CreatePublicDnsNamespace(ctx, &sd.CreatePublicDnsNamespaceInput{
+		Name: aws.String(name),
+		Tags: tags,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the namespace \"%s\": %w", name, err)
+	}
+	return aws.ToString(out.OperationId), nil
+}
+
 // ListServicesByNamespaceID returns list of services in given namespace.
 func (p *AWSSDProvider) ListServicesByNamespaceID(ctx context.Context, namespaceID *string) (map[string]*sdtypes.Service, error) {
 	services := make([]sdtypes.ServiceSummary, 0)
@@ -52,6 +52,13 @@ type AWSSDClientStub struct {
 
 	// map[service_id] => map[inst_id]instance
 	instances map[string]map[string]*sdtypes.Instance
+
+	// map[operation_id]operation of the namespaces being created
+	operations map[string]*sdtypes.Operation
+
+	// map[operation_id]namespace being created, with its tags
+	pendingNamespaces map[string]*sdtypes.Namespace
+	namespaceTags     map[string][]sdtypes.Tag
 }
 
 func (s *AWSSDClientStub) CreateService(ctx context.Context, input *sd.CreateServiceInput, optFns ...func(*sd.Options)) (*sd.CreateServiceOutput, error) {
@@ -203,6 +210,50 @@ func (s *AWSSDClientStub) DeleteService(ctx context.Context, input *sd.DeleteSer
 	return &sd.DeleteServiceOutput{}, nil
 }
 
+func (s *AWSSDClientStub) createNamespace(namespace *sdtypes.Namespace, tags []sdtypes.Tag) string {
+	if s.operations == nil {
+		s.operations = make(map[string]*sdtypes.Operation)
+		s.pendingNamespaces = make(map[string]*sdtypes.Namespace)
+		s.namespaceTags = make(map[string][]sdtypes.Tag)
+	}
+	id := "op-" + strconv.Itoa(len(s.operations))
+	s.operations[id] = &sdtypes.Operation{Id: aws.String(id), Status: sdtypes.OperationStatusSubmitted}
+	s.pendingNamespaces[id] = namespace
+	s.namespaceTags[*namespace.Name] = tags
+	return id
+}
+
+func (s *AWSSDClientStub) CreatePublicDnsNamespace(ctx context.Context, input *sd.CreatePublicDnsNamespaceInput, optFns ...func(*sd.Options)) (*sd.CreatePublicDnsNamespaceOutput, error) {
+	id := s.createNamespace(&sdtypes.Namespace{Name: input.Name, Type: sdtypes.NamespaceTypeDnsPublic}, input.Tags)
+	return &sd.CreatePublicDnsNamespaceOutput{OperationId: aws.String(id)}, nil
+}
+
+func (s *AWSSDClientStub) CreatePrivateDnsNamespace(ctx context.Context, input *sd.CreatePrivateDnsNamespaceInput, optFns ...func(*sd.Options)) (*sd.CreatePrivateDnsNamespaceOutput, error) {
+	if input.Vpc == nil {
+		return nil, errors.New("a VPC is required")
+	}
+	id := s.createNamespace(&sdtypes.Namespace{Name: input.Name, Type: sdtypes.NamespaceTypeDnsPrivate}, input.Tags)
+	return &sd.CreatePrivateDnsNamespaceOutput{OperationId: aws.String(id)}, nil
+}
+
+func (s *AWSSDClientStub) GetOperation(ctx context.Context, input *sd.GetOperationInput, optFns ...func(*sd.Options)) (*sd.GetOperationOutput, error) {
+	op, ok := s.operations[*input.OperationId]
+	if !ok {
+		return nil, errors.New("operation not found")
+	}
+	return &sd.GetOperationOutput{Operation: op}, nil
+}
+
+// completeOperation ends the operation creating a namespace, which then exists unless the operation failed.
+func (s *AWSSDClientStub) completeOperation(id string, status sdtypes.OperationStatus) {
+	s.operations[id].Status = status
+	if status == sdtypes.OperationStatusSuccess {
+		namespace := s.pendingNamespaces[id]
+		namespace.Id = aws.String(id)
+		s.namespaces[id] = namespace
+	}
+}
+
 func newTestAWSSDProvider(api AWSSDClient, domainFilter endpoint.DomainFilter, namespaceTypeFilter, ownerID string) *AWSSDProvider {
 	return &AWSSDProvider{
 		client:              api,
@@ -211,6 +262,7 @@ func newTestAWSSDProvider(api AWSSDClient, domainFilter endpoint.DomainFilter, n
 		namespaceTypeFilter: newSdNamespaceFilter(namespaceTypeFilter),
 		cleanEmptyService:   true,
 		ownerID:             ownerID,
+		namespaceOperations: map[string]string{},
 	}
 }
 
@@ -414,6 +466,61 @@ func TestAWSSDProvider_ApplyChanges(t *testing.T) {
 	assert.Empty(t, endpoints)
 }
 
+func TestAWSSDProvider_ApplyChangesCreatesNamespaces(t *testing.T) {
+	api := &AWSSDClientStub{
+		namespaces: map[string]*sdtypes.Namespace{},
+		services:   make(map[string]map[string]*sdtypes.Service),
+		instances:  make(map[string]map[string]*sdtypes.Instance),
+	}
+
+	provider := newTestAWSSDProvider(api, endpoint.NewDomainFilter([]string{"public.com", "other.com"}), "", "owner-id")
+	provider.createNamespaces = true
+
+	ctx := context.Background()
+	service := &endpoint.Endpoint{DNSName: "service1.public.com", Targets: endpoint.Targets{"1.2.3.4"}, RecordType: endpoint.RecordTypeA, RecordTTL: 60}
+	nested := &endpoint.Endpoint{DNSName: "service1.nested.public.com", Targets: endpoint.Targets{"1.2.3.4"}, RecordType: endpoint.RecordTypeA, RecordTTL: 60}
+
+	// the namespace of the domain filter is created, not the one of the nested record
+	require.NoError(t, provider.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{service, nested}}))
+	require.Len(t, api.operations, 1)
+	assert.Equal(t, "public.com", *api.pendingNamespaces["op-0"].Name)
+	assert.Equal(t, sdtypes.NamespaceTypeDnsPublic, api.pendingNamespaces["op-0"].Type)
+	assert.Equal(t, []sdtypes.Tag{{Key: aws.String(sdNamespaceOwnerTag), Value: aws.String("owner-id")}}, api.namespaceTags["public.com"])
+	assert.Empty(t, api.services)
+
+	// the namespace is not created again while the operation is pending
+	require.NoError(t, provider.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{service}}))
+	assert.Len(t, api.operations, 1)
+
+	// a failed operation is retried
+	api.completeOperation("op-0", sdtypes.OperationStatusFail)
+	require.NoError(t, provider.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{service}}))
+	require.Len(t, api.operations, 2)
+
+	// the record is created once the namespace exists
+	api.completeOperation("op-1", sdtypes.OperationStatusSuccess)
+	require.NoError(t, provider.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{service}}))
+	assert.Len(t, api.operations, 2)
+	assert.Len(t, api.services["op-1"], 1)
+	assert.Empty(t, provider.namespaceOperations)
+}
+
+func TestAWSSDProvider_CreateNamespace(t *testing.T) {
+	api := &AWSSDClientStub{}
+	provider := newTestAWSSDProvider(api, endpoint.NewDomainFilter([]string{}), "", "owner-id")
+	provider.namespaceVPC = "vpc-111"
+
+	id, err := provider.CreateNamespace(context.Background(), "private.com")
+	require.NoError(t, err)
+	assert.Equal(t, sdtypes.NamespaceTypeDnsPrivate, api.pendingNamespaces[id].Type)
+
+	provider.dryRun = true
+	id, err = provider.CreateNamespace(context.Background(), "other.com")
+	require.NoError(t, err)
+	assert.Empty(t, id)
+	assert.Len(t, api.operations, 1)
+}
+
 func TestAWSSDProvider_ListNamespaces(t *testing.T) {
 	namespaces := map[string]*sdtypes.Namespace{
 		"private": {
@@ -20,6 +20,7 @@ package azure
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -45,6 +46,7 @@ type ZonesClient interface {
 // RecordSetsClient is an interface of dns.RecordSetsClient that can be stubbed for testing.
 type RecordSetsClient interface {
 	NewListAllByDNSZonePager(resourceGroupName string, zoneName string, options *dns.RecordSetsClientListAllByDNSZoneOptions) *azcoreruntime.Pager[dns.RecordSetsClientListAllByDNSZoneResponse]
+	NewListByTypePager(resourceGroupName string, zoneName string, recordType dns.RecordType, options *dns.RecordSetsClientListByTypeOptions) *azcoreruntime.Pager[dns.RecordSetsClientListByTypeResponse]
 	Delete(ctx context.Context, resourceGroupName string, zoneName string, relativeRecordSetName string, recordType dns.RecordType, options *dns.RecordSetsClientDeleteOptions) (dns.RecordSetsClientDeleteResponse, error)
 	CreateOrUpdate(ctx context.Context, resourceGroupName string, zoneName string, relativeRecordSetName string, recordType dns.RecordType, parameters dns.RecordSet, options *dns.RecordSetsClientCreateOrUpdateOptions) (dns.RecordSetsClientCreateOrUpdateResponse, error)
 }
@@ -107,47 +109,77 @@ func (p *AzureProvider) Records(ctx context.Context) (endpoints []*endpoint.Endp
 	}
 
 	for _, zone := range zones {
-		pager := p.recordSetsClient.NewListAllByDNSZonePager(p.resourceGroup, *zone.Name, &dns.RecordSetsClientListAllByDNSZoneOptions{Top: nil})
+		recordSets, err := p.recordSets(ctx, *zone.Name)
+		if err != nil {
+			return nil, provider.NewSoftError(fmt.Errorf("failed to fetch dns records: %w", err))
+		}
+		for _, recordSet := range recordSets {
+			if recordSet.Name == nil || recordSet.Type == nil {
+				log.Error("Skipping invalid record set with nil name or type.")
+				continue
+			}
+			recordType := strings.TrimPrefix(*recordSet.Type, "Microsoft.Network/dnszones/")
+			if !p.SupportedRecordType(recordType) {
+				continue
+			}
+			name := formatAzureDNSName(*recordSet.Name, *zone.Name)
+			if len(p.zoneNameFilter.Filters) > 0 && !p.domainFilter.Match(name) {
+				log.Debugf("Skipping return of record %s because it was filtered out by the specified --domain-filter", name)
+				continue
+			}
+			targets := extractAzureTargets(recordSet)
+			if len(targets) == 0 {
+				log.Debugf("Failed to extract targets for '%s' with type '%s'.", name, recordType)
+				continue
+			}
+			var ttl endpoint.TTL
+			if recordSet.Properties.TTL != nil {
+				ttl = endpoint.TTL(*recordSet.Properties.TTL)
+			}
+			ep := endpoint.NewEndpointWithTTL(name, recordType, ttl, targets...)
+			log.Debugf(
+				"Found %s record for '%s' with target '%s'.",
+				ep.RecordType,
+				ep.DNSName,
+				ep.Targets,
+			)
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return endpoints, nil
+}
+
+// recordSets lists the record sets of the zone. If record types are excluded, the record sets of the other
+// supported types are listed type by type, so that the excluded types are never listed.
+func (p *AzureProvider) recordSets(ctx context.Context, zoneName string) ([]*dns.RecordSet, error) {
+	var recordSets []*dns.RecordSet
+	excluded := provider.ExcludedRecordTypes(ctx)
+	if len(excluded) == 0 {
+		pager := p.recordSetsClient.NewListAllByDNSZonePager(p.resourceGroup, zoneName, &dns.RecordSetsClientListAllByDNSZoneOptions{Top: nil})
 		for pager.More() {
 			nextResult, err := pager.NextPage(ctx)
 			if err != nil {
-				return nil, provider.NewSoftError(fmt.Errorf("failed to fetch dns records: %w", err))
+				return nil, err
 			}
-			for _, recordSet := range nextResult.Value {
-				if recordSet.Name == nil || recordSet.Type == nil {
-					log.Error("Skipping invalid record set with nil name or type.")
-					continue
-				}
-				recordType := strings.TrimPrefix(*recordSet.Type, "Microsoft.Network/dnszones/")
-				if !p.SupportedRecordType(recordType) {
-					continue
-				}
-				name := formatAzureDNSName(*recordSet.Name, *zone.Name)
-				if len(p.zoneNameFilter.Filters) > 0 && !p.domainFilter.Match(name) {
-					log.Debugf("Skipping return of record %s because it was filtered out by the specified --domain-filter", name)
-					continue
-				}
-				targets := extractAzureTargets(recordSet)
-				if len(targets) == 0 {
-					log.Debugf("Failed to extract targets for '%s' with type '%s'.", name, recordType)
-					continue
-				}
-				var ttl endpoint.TTL
-				if recordSet.Properties.TTL != nil {
-					ttl = endpoint.TTL(*recordSet.Properties.TTL)
-				}
-				ep := endpoint.NewEndpointWithTTL(name, recordType, ttl, targets...)
-				log.Debugf(
-					"Found %s record for '%s' with target '%s'.",
-					ep.RecordType,
-					ep.DNSName,
-					ep.Targets,
-				)
-				endpoints = append(endpoints, ep)
+			recordSets = append(recordSets, nextResult.Value...)
+		}
+		return recordSets, nil
+	}
+
+	for _, recordType := range dns.PossibleRecordTypeValues() {
+		if !p.SupportedRecordType(string(recordType)) || slices.Contains(excluded, string(recordType)) {
+			continue
+		}
+		pager := p.recordSetsClient.NewListByTypePager(p.resourceGroup, zoneName, recordType, &dns.RecordSetsClientListByTypeOptions{Top: nil})
+		for pager.More() {
+			nextResult, err := pager.NextPage(ctx)
+			if err != nil {
+				return nil, err
 			}
+			recordSets = append(recordSets, nextResult.Value...)
 		}
 	}
-	return endpoints, nil
+	return recordSets, nil
 }
 
 // ApplyChanges applies the given changes.
@@ -390,9 +422,7 @@ func (p *AzureProvider) newRecordSet(endpoint *endpoint.Endpoint) (dns.RecordSet
 				TTL: to.Ptr(ttl),
 				TxtRecords: []*dns.TxtRecord{
 					{
-						Value: []*string{
-							&endpoint.Targets[0],
-						},
+						Value: txtRecordValue(endpoint.Targets[0]),
 					},
 				},
 			},
@@ -457,7 +487,7 @@ func extractAzureTargets(recordSet *dns.RecordSet) []string {
 	if len(txtRecords) > 0 && (txtRecords)[0].Value != nil {
 		values := (txtRecords)[0].Value
 		if len(values) > 0 {
-			return []string{*(values)[0]}
+			return []string{txtRecordTarget(values)}
 		}
 	}
 	return []string{}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	azcoreruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
@@ -56,12 +57,18 @@ type AzurePrivateDNSProvider struct {
 	activeDirectoryAuthorityHost string
 	zonesClient                  PrivateZonesClient
 	recordSetsClient             PrivateRecordSetsClient
+	// virtualNetworkLinksClient links the zones to the virtualNetworkIDs, if any, and virtualNetworks are their
+	// lower-cased IDs. The linkedZones are the times the links of the zones were last reconciled, see MaintainZones.
+	virtualNetworkLinksClient PrivateVirtualNetworkLinksClient
+	virtualNetworkIDs         []string
+	virtualNetworks           map[string]bool
+	linkedZones               map[string]time.Time
 }
 
 // NewAzurePrivateDNSProvider creates a new Azure Private DNS provider.
 //
 // Returns the provider or an error if a provider could not be created.
-func NewAzurePrivateDNSProvider(configFile string, domainFilter endpoint.DomainFilter, zoneNameFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, subscriptionID string, resourceGroup string, userAssignedIdentityClientID string, activeDirectoryAuthorityHost string, dryRun bool) (*AzurePrivateDNSProvider, error) {
+func NewAzurePrivateDNSProvider(configFile string, domainFilter endpoint.DomainFilter, zoneNameFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, subscriptionID string, resourceGroup string, userAssignedIdentityClientID string, activeDirectoryAuthorityHost string, virtualNetworkIDs []string, dryRun bool) (*AzurePrivateDNSProvider, error) {
 	cfg, err := getConfig(configFile, subscriptionID, resourceGroup, userAssignedIdentityClientID, activeDirectoryAuthorityHost)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read Azure config file '%s': %v", configFile, err)
@@ -79,7 +86,7 @@ func NewAzurePrivateDNSProvider(configFile string, domainFilter endpoint.DomainF
 	if err != nil {
 		return nil, err
 	}
-	return &AzurePrivateDNSProvider{
+	p := &AzurePrivateDNSProvider{
 		domainFilter:                 domainFilter,
 		zoneNameFilter:               zoneNameFilter,
 		zoneIDFilter:                 zoneIDFilter,
@@ -89,7 +96,15 @@ func NewAzurePrivateDNSProvider(configFile string, domainFilter endpoint.DomainF
 		activeDirectoryAuthorityHost: cfg.ActiveDirectoryAuthorityHost,
 		zonesClient:                  zonesClient,
 		recordSetsClient:             recordSetsClient,
-	}, nil
+	}
+	if len(virtualNetworkIDs) > 0 {
+		linksClient, err := privatedns.NewVirtualNetworkLinksClient(cfg.SubscriptionID, cred, clientOpts)
+		if err != nil {
+			return nil, err
+		}
+		p.linkVirtualNetworks(virtualNetworkLinksClient{linksClient}, virtualNetworkIDs)
+	}
+	return p, nil
 }
 
 // Records gets the current records.
@@ -398,9 +413,7 @@ func (p *AzurePrivateDNSProvider) newRecordSet(endpoint *endpoint.Endpoint) (pri
 				TTL: to.Ptr(ttl),
 				TxtRecords: []*privatedns.TxtRecord{
 					{
-						Value: []*string{
-							&endpoint.Targets[0],
-						},
+						Value: txtRecordValue(endpoint.Targets[0]),
 					},
 				},
 			},
@@ -457,7 +470,7 @@ func extractAzurePrivateDNSTargets(recordSet *privatedns.RecordSet) []string {
 	if len(txtRecords) > 0 && (txtRecords)[0].Value != nil {
 		values := (txtRecords)[0].Value
 		if len(values) > 0 {
-			return []string{*(values)[0]}
+			return []string{txtRecordTarget(values)}
 		}
 	}
 	return []string{}
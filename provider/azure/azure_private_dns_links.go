@@ -0,0 +1,195 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	azcoreruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	privatedns "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// virtualNetworkLinkTag tags the virtual network links created by ExternalDNS, which are deleted when their
+	// virtual network is no longer configured. The other links are left untouched.
+	virtualNetworkLinkTag      = "managed-by"
+	virtualNetworkLinkTagValue = "external-dns"
+	// maxVirtualNetworkLinkName is the maximum length of the name of a virtual network link.
+	maxVirtualNetworkLinkName = 80
+	// virtualNetworkLinksRecheckInterval is the interval after which the links of a zone are reconciled again,
+	// e.g. to restore a link deleted out of band.
+	virtualNetworkLinksRecheckInterval = time.Hour
+)
+
+// PrivateVirtualNetworkLinksClient is an interface of privatedns.VirtualNetworkLinksClient that can be stubbed for
+// testing, waiting for the completion of the long-running operations.
+type PrivateVirtualNetworkLinksClient interface {
+	NewListPager(resourceGroupName string, privateZoneName string, options *privatedns.VirtualNetworkLinksClientListOptions) *azcoreruntime.Pager[privatedns.VirtualNetworkLinksClientListResponse]
+	CreateOrUpdate(ctx context.Context, resourceGroupName string, privateZoneName string, virtualNetworkLinkName string, parameters privatedns.VirtualNetworkLink) error
+	Delete(ctx context.Context, resourceGroupName string, privateZoneName string, virtualNetworkLinkName string) error
+}
+
+// virtualNetworkLinksClient is the PrivateVirtualNetworkLinksClient of the Azure API.
+type virtualNetworkLinksClient struct {
+	*privatedns.VirtualNetworkLinksClient
+}
+
+func (c virtualNetworkLinksClient) CreateOrUpdate(ctx context.Context, resourceGroupName string, privateZoneName string, virtualNetworkLinkName string, parameters privatedns.VirtualNetworkLink) error {
+	poller, err := c.BeginCreateOrUpdate(ctx, resourceGroupName, privateZoneName, virtualNetworkLinkName, parameters, nil)
+	if err != nil {
+		return err
+	}
+	_, err = poller.PollUntilDone(ctx, nil)
+	return err
+}
+
+func (c virtualNetworkLinksClient) Delete(ctx context.Context, resourceGroupName string, privateZoneName string, virtualNetworkLinkName string) error {
+	poller, err := c.BeginDelete(ctx, resourceGroupName, privateZoneName, virtualNetworkLinkName, nil)
+	if err != nil {
+		return err
+	}
+	_, err = poller.PollUntilDone(ctx, nil)
+	return err
+}
+
+// ParseVirtualNetworkIDs parses the resource IDs of the virtual networks of the comma-separated lists,
+// e.g. "/subscriptions/<id>/resourceGroups/network/providers/Microsoft.Network/virtualNetworks/vnet".
+func ParseVirtualNetworkIDs(specs []string) ([]string, error) {
+	var ids []string
+	for _, spec := range specs {
+		for _, id := range strings.Split(spec, ",") {
+			id = strings.TrimSpace(id)
+			if id == "" {
+				continue
+			}
+			if !strings.Contains(strings.ToLower(id), "/providers/microsoft.network/virtualnetworks/") || strings.HasSuffix(id, "/") {
+				return nil, fmt.Errorf("invalid virtual network %q, expected the resource ID of a virtual network", id)
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// virtualNetworkLinkName returns the name of the link of the virtual network created by ExternalDNS.
+func virtualNetworkLinkName(virtualNetworkID string) string {
+	name := "external-dns-" + virtualNetworkID[strings.LastIndex(virtualNetworkID, "/")+1:]
+	if len(name) > maxVirtualNetworkLinkName {
+		name = name[:maxVirtualNetworkLinkName]
+	}
+	return name
+}
+
+// linkVirtualNetworks enables the links of the zones to the virtual networks with the client.
+func (p *AzurePrivateDNSProvider) linkVirtualNetworks(client PrivateVirtualNetworkLinksClient, virtualNetworkIDs []string) {
+	p.virtualNetworkLinksClient = client
+	p.virtualNetworkIDs = virtualNetworkIDs
+	p.virtualNetworks = map[string]bool{}
+	for _, id := range virtualNetworkIDs {
+		p.virtualNetworks[strings.ToLower(id)] = true
+	}
+	p.linkedZones = map[string]time.Time{}
+}
+
+// MaintainZones links the zones to the configured virtual networks, so that zones created out of band are
+// resolvable from them, and deletes the links created by ExternalDNS to the virtual networks no longer configured.
+// The links of a zone are reconciled when the zone is found, and again once virtualNetworkLinksRecheckInterval
+// has passed; the zones failing to be reconciled are retried by the next call.
+func (p *AzurePrivateDNSProvider) MaintainZones(ctx context.Context) error {
+	if p.virtualNetworkLinksClient == nil {
+		return nil
+	}
+	zones, err := p.zones(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, zone := range zones {
+		if zone.Name == nil || time.Since(p.linkedZones[*zone.Name]) < virtualNetworkLinksRecheckInterval {
+			continue
+		}
+		if err := p.reconcileZoneVirtualNetworkLinks(ctx, *zone.Name); err != nil {
+			errs = append(errs, fmt.Errorf("virtual network links of Azure Private DNS zone '%s': %w", *zone.Name, err))
+			continue
+		}
+		p.linkedZones[*zone.Name] = time.Now()
+	}
+	return errors.Join(errs...)
+}
+
+func (p *AzurePrivateDNSProvider) reconcileZoneVirtualNetworkLinks(ctx context.Context, zone string) error {
+	linked := map[string]bool{}
+	var stale []string
+	pager := p.virtualNetworkLinksClient.NewListPager(p.resourceGroup, zone, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, link := range page.Value {
+			if link.Name == nil || link.Properties == nil || link.Properties.VirtualNetwork == nil || link.Properties.VirtualNetwork.ID == nil {
+				continue
+			}
+			id := strings.ToLower(*link.Properties.VirtualNetwork.ID)
+			linked[id] = true
+			if tag := link.Tags[virtualNetworkLinkTag]; tag != nil && *tag == virtualNetworkLinkTagValue && !p.virtualNetworks[id] {
+				stale = append(stale, *link.Name)
+			}
+		}
+	}
+
+	for _, id := range p.virtualNetworkIDs {
+		if linked[strings.ToLower(id)] {
+			continue
+		}
+		name := virtualNetworkLinkName(id)
+		if p.dryRun {
+			log.Infof("Would link Azure Private DNS zone '%s' to virtual network '%s'.", zone, id)
+			continue
+		}
+		log.Infof("Linking Azure Private DNS zone '%s' to virtual network '%s'.", zone, id)
+		err := p.virtualNetworkLinksClient.CreateOrUpdate(ctx, p.resourceGroup, zone, name, privatedns.VirtualNetworkLink{
+			Location: to.Ptr("global"),
+			Tags:     map[string]*string{virtualNetworkLinkTag: to.Ptr(virtualNetworkLinkTagValue)},
+			Properties: &privatedns.VirtualNetworkLinkProperties{
+				RegistrationEnabled: to.Ptr(false),
+				VirtualNetwork:      &privatedns.SubResource{ID: to.Ptr(id)},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("linking virtual network '%s': %w", id, err)
+		}
+	}
+
+	for _, name := range stale {
+		if p.dryRun {
+			log.Infof("Would delete virtual network link '%s' of Azure Private DNS zone '%s'.", name, zone)
+			continue
+		}
+		log.Infof("Deleting virtual network link '%s' of Azure Private DNS zone '%s'.", name, zone)
+		if err := p.virtualNetworkLinksClient.Delete(ctx, p.resourceGroup, zone, name); err != nil {
+			return fmt.Errorf("deleting virtual network link '%s': %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	azcoreruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	privatedns "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	vnetProd    = "/subscriptions/sub/resourceGroups/network/providers/Microsoft.Network/virtualNetworks/prod"
+	vnetStaging = "/subscriptions/sub/resourceGroups/network/providers/Microsoft.Network/virtualNetworks/staging"
+	vnetLegacy  = "/subscriptions/sub/resourceGroups/network/providers/Microsoft.Network/virtualNetworks/legacy"
+	vnetOther   = "/subscriptions/sub/resourceGroups/network/providers/Microsoft.Network/virtualNetworks/other"
+)
+
+// mockVirtualNetworkLinksClient keeps the virtual network links of the zones in memory.
+type mockVirtualNetworkLinksClient struct {
+	links     map[string][]*privatedns.VirtualNetworkLink
+	listed    []string
+	createErr error
+}
+
+func (c *mockVirtualNetworkLinksClient) NewListPager(resourceGroupName string, privateZoneName string, options *privatedns.VirtualNetworkLinksClientListOptions) *azcoreruntime.Pager[privatedns.VirtualNetworkLinksClientListResponse] {
+	c.listed = append(c.listed, privateZoneName)
+	return azcoreruntime.NewPager(azcoreruntime.PagingHandler[privatedns.VirtualNetworkLinksClientListResponse]{
+		More: func(privatedns.VirtualNetworkLinksClientListResponse) bool { return false },
+		Fetcher: func(context.Context, *privatedns.VirtualNetworkLinksClientListResponse) (privatedns.VirtualNetworkLinksClientListResponse, error) {
+			return privatedns.VirtualNetworkLinksClientListResponse{
+				VirtualNetworkLinkListResult: privatedns.VirtualNetworkLinkListResult{Value: c.links[privateZoneName]},
+			}, nil
+		},
+	})
+}
+
+func (c *mockVirtualNetworkLinksClient) CreateOrUpdate(ctx context.Context, resourceGroupName string, privateZoneName string, virtualNetworkLinkName string, parameters privatedns.VirtualNetworkLink) error {
+	if c.createErr != nil {
+		return c.createErr
+	}
+	parameters.Name = to.Ptr(virtualNetworkLinkName)
+	c.links[privateZoneName] = append(c.links[privateZoneName], &parameters)
+	return nil
+}
+
+func (c *mockVirtualNetworkLinksClient) Delete(ctx context.Context, resourceGroupName string, privateZoneName string, virtualNetworkLinkName string) error {
+	var kept []*privatedns.VirtualNetworkLink
+	for _, link := range c.links[privateZoneName] {
+		if *link.Name != virtualNetworkLinkName {
+			kept = append(kept, link)
+		}
+	}
+	c.links[privateZoneName] = kept
+	return nil
+}
+
+func newVirtualNetworkLink(name, virtualNetworkID string, tags map[string]*string) *privatedns.VirtualNetworkLink {
+	return &privatedns.VirtualNetworkLink{
+		Name:       to.Ptr(name),
+		Tags:       tags,
+		Properties: &privatedns.VirtualNetworkLinkProperties{VirtualNetwork: &privatedns.SubResource{ID: to.Ptr(virtualNetworkID)}},
+	}
+}
+
+// linkedVirtualNetworks returns the names and virtual networks of the links of the zone.
+func linkedVirtualNetworks(links []*privatedns.VirtualNetworkLink) map[string]string {
+	linked := map[string]string{}
+	for _, link := range links {
+		linked[*link.Name] = *link.Properties.VirtualNetwork.ID
+	}
+	return linked
+}
+
+func TestParseVirtualNetworkIDs(t *testing.T) {
+	ids, err := ParseVirtualNetworkIDs([]string{vnetProd + ", " + vnetStaging, ""})
+	require.NoError(t, err)
+	assert.Equal(t, []string{vnetProd, vnetStaging}, ids)
+
+	for _, spec := range []string{"prod", "/subscriptions/sub/resourceGroups/network/providers/Microsoft.Network/virtualNetworks/"} {
+		_, err := ParseVirtualNetworkIDs([]string{spec})
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestVirtualNetworkLinkName(t *testing.T) {
+	assert.Equal(t, "external-dns-prod", virtualNetworkLinkName(vnetProd))
+	assert.Len(t, virtualNetworkLinkName(vnetProd+strings.Repeat("x", 100)), maxVirtualNetworkLinkName)
+}
+
+func TestAzurePrivateDNSReconcileVirtualNetworkLinks(t *testing.T) {
+	for _, dryRun := range []bool{false, true} {
+		linksClient := &mockVirtualNetworkLinksClient{links: map[string][]*privatedns.VirtualNetworkLink{
+			"example.com": {
+				newVirtualNetworkLink("manual", vnetOther, nil),
+				newVirtualNetworkLink("prod", strings.ToUpper(vnetProd), nil),
+				newVirtualNetworkLink("external-dns-legacy", vnetLegacy, map[string]*string{virtualNetworkLinkTag: to.Ptr(virtualNetworkLinkTagValue)}),
+			},
+		}}
+		p, err := newMockedAzurePrivateDNSProvider(endpoint.NewDomainFilter([]string{"example.com", "example.org"}), endpoint.NewDomainFilter([]string{}), provider.NewZoneIDFilter([]string{""}), dryRun, "k8s",
+			[]*privatedns.PrivateZone{
+				createMockPrivateZone("example.com", "/privateDnsZones/example.com"),
+				createMockPrivateZone("example.org", "/privateDnsZones/example.org"),
+			},
+			[]*privatedns.RecordSet{})
+		require.NoError(t, err)
+		p.linkVirtualNetworks(linksClient, []string{vnetProd, vnetStaging})
+
+		// listing the records leaves the links untouched
+		_, err = p.Records(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, linksClient.listed)
+
+		require.NoError(t, p.MaintainZones(context.Background()))
+
+		if dryRun {
+			assert.Len(t, linksClient.links["example.com"], 3)
+			assert.Empty(t, linksClient.links["example.org"])
+			continue
+		}
+		assert.Equal(t, map[string]string{
+			"manual":               vnetOther,
+			"prod":                 strings.ToUpper(vnetProd),
+			"external-dns-staging": vnetStaging,
+		}, linkedVirtualNetworks(linksClient.links["example.com"]))
+		assert.Equal(t, map[string]string{
+			"external-dns-prod":    vnetProd,
+			"external-dns-staging": vnetStaging,
+		}, linkedVirtualNetworks(linksClient.links["example.org"]))
+		for _, link := range linksClient.links["example.org"] {
+			assert.Equal(t, virtualNetworkLinkTagValue, *link.Tags[virtualNetworkLinkTag])
+			assert.False(t, *link.Properties.RegistrationEnabled)
+		}
+
+		// the links of the zones are not reconciled again before the recheck interval
+		require.NoError(t, p.MaintainZones(context.Background()))
+		assert.Equal(t, []string{"example.com", "example.org"}, linksClient.listed)
+
+		// a link deleted out of band is restored once the interval has passed
+		require.NoError(t, linksClient.Delete(context.Background(), "k8s", "example.org", "external-dns-prod"))
+		p.linkedZones["example.org"] = time.Now().Add(-virtualNetworkLinksRecheckInterval)
+		require.NoError(t, p.MaintainZones(context.Background()))
+		assert.Equal(t, []string{"example.com", "example.org", "example.org"}, linksClient.listed)
+		assert.Contains(t, linkedVirtualNetworks(linksClient.links["example.org"]), "external-dns-prod")
+	}
+}
+
+func TestAzurePrivateDNSReconcileVirtualNetworkLinksError(t *testing.T) {
+	linksClient := &mockVirtualNetworkLinksClient{links: map[string][]*privatedns.VirtualNetworkLink{}, createErr: errors.New("forbidden")}
+	p, err := newMockedAzurePrivateDNSProvider(endpoint.NewDomainFilter([]string{"example.com"}), endpoint.NewDomainFilter([]string{}), provider.NewZoneIDFilter([]string{""}), false, "k8s",
+		[]*privatedns.PrivateZone{createMockPrivateZone("example.com", "/privateDnsZones/example.com")},
+		[]*privatedns.RecordSet{})
+	require.NoError(t, err)
+	p.linkVirtualNetworks(linksClient, []string{vnetProd})
+
+	// the failure is returned, and the links are reconciled again by the next call
+	assert.ErrorContains(t, p.MaintainZones(context.Background()), "forbidden")
+	linksClient.createErr = nil
+	require.NoError(t, p.MaintainZones(context.Background()))
+	assert.Equal(t, []string{"example.com", "example.com"}, linksClient.listed)
+	assert.Equal(t, map[string]string{"external-dns-prod": vnetProd}, linkedVirtualNetworks(linksClient.links["example.com"]))
+}
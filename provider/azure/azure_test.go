@@ -63,6 +63,8 @@ func (client *mockZonesClient) NewListByResourceGroupPager(resourceGroupName str
 // and returns static results which are defined per test
 type mockRecordSetsClient struct {
 	pagingHandler    azcoreruntime.PagingHandler[dns.RecordSetsClientListAllByDNSZoneResponse]
+	recordSets       []*dns.RecordSet
+	listedTypes      []dns.RecordType
 	deletedEndpoints []*endpoint.Endpoint
 	updatedEndpoints []*endpoint.Endpoint
 }
@@ -82,6 +84,7 @@ func newMockRecordSetsClient(recordSets []*dns.RecordSet) mockRecordSetsClient {
 	}
 	return mockRecordSetsClient{
 		pagingHandler: pagingHandler,
+		recordSets:    recordSets,
 	}
 }
 
@@ -89,6 +92,28 @@ func (client *mockRecordSetsClient) NewListAllByDNSZonePager(resourceGroupName s
 	return azcoreruntime.NewPager(client.pagingHandler)
 }
 
+func (client *mockRecordSetsClient) NewListByTypePager(resourceGroupName string, zoneName string, recordType dns.RecordType, options *dns.RecordSetsClientListByTypeOptions) *azcoreruntime.Pager[dns.RecordSetsClientListByTypeResponse] {
+	client.listedTypes = append(client.listedTypes, recordType)
+	var recordSets []*dns.RecordSet
+	for _, recordSet := range client.recordSets {
+		if *recordSet.Type == "Microsoft.Network/dnszones/"+string(recordType) {
+			recordSets = append(recordSets, recordSet)
+		}
+	}
+	return azcoreruntime.NewPager(azcoreruntime.PagingHandler[dns.RecordSetsClientListByTypeResponse]{
+		More: func(resp dns.RecordSetsClientListByTypeResponse) bool {
+			return false
+		},
+		Fetcher: func(context.Context, *dns.RecordSetsClientListByTypeResponse) (dns.RecordSetsClientListByTypeResponse, error) {
+			return dns.RecordSetsClientListByTypeResponse{
+				RecordSetListResult: dns.RecordSetListResult{
+					Value: recordSets,
+				},
+			}, nil
+		},
+	})
+}
+
 func (client *mockRecordSetsClient) Delete(ctx context.Context, resourceGroupName string, zoneName string, relativeRecordSetName string, recordType dns.RecordType, options *dns.RecordSetsClientDeleteOptions) (dns.RecordSetsClientDeleteResponse, error) {
 	client.deletedEndpoints = append(
 		client.deletedEndpoints,
@@ -286,6 +311,29 @@ func TestAzureRecord(t *testing.T) {
 	validateAzureEndpoints(t, actual, expected)
 }
 
+func TestAzureRecordExcludedTypes(t *testing.T) {
+	recordSetsClient := newMockRecordSetsClient([]*dns.RecordSet{
+		createMockRecordSet("@", endpoint.RecordTypeA, "123.123.123.122"),
+		createMockRecordSet("@", endpoint.RecordTypeTXT, "heritage=external-dns,external-dns/owner=default"),
+		createMockRecordSetMultiWithTTL("mail", endpoint.RecordTypeMX, 4000, "10 example.com"),
+	})
+	zonesClient := newMockZonesClient([]*dns.Zone{createMockZone("example.com", "/dnszones/example.com")})
+	p := newAzureProvider(endpoint.NewDomainFilter([]string{"example.com"}), endpoint.NewDomainFilter([]string{}), provider.NewZoneIDFilter([]string{""}), true, "k8s", "", "", &zonesClient, &recordSetsClient)
+
+	ctx := context.WithValue(context.Background(), provider.ExcludedRecordTypesContextKey, []string{endpoint.RecordTypeMX, endpoint.RecordTypeNS})
+	actual, err := p.Records(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validateAzureEndpoints(t, actual, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "123.123.123.122"),
+		endpoint.NewEndpoint("example.com", endpoint.RecordTypeTXT, "heritage=external-dns,external-dns/owner=default"),
+	})
+	assert.NotContains(t, recordSetsClient.listedTypes, dns.RecordTypeMX)
+	assert.NotContains(t, recordSetsClient.listedTypes, dns.RecordTypeNS)
+	assert.Contains(t, recordSetsClient.listedTypes, dns.RecordTypeA)
+}
+
 func TestAzureMultiRecord(t *testing.T) {
 	provider, err := newMockedAzureProvider(endpoint.NewDomainFilter([]string{"example.com"}), endpoint.NewDomainFilter([]string{}), provider.NewZoneIDFilter([]string{""}), true, "k8s", "", "",
 		[]*dns.Zone{
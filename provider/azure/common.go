@@ -18,30 +18,45 @@ limitations under the License.
 package azure
 
 import (
-	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	dns "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/dns/armdns"
 	privatedns "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+
+	"sigs.k8s.io/external-dns/endpoint"
 )
 
 // Helper function (shared with test code)
 func parseMxTarget[T dns.MxRecord | privatedns.MxRecord](mxTarget string) (T, error) {
-	targetParts := strings.SplitN(mxTarget, " ", 2)
-	if len(targetParts) != 2 {
-		return T{}, fmt.Errorf("mx target needs to be of form '10 example.com'")
-	}
-
-	preferenceRaw, exchange := targetParts[0], targetParts[1]
-	preference, err := strconv.ParseInt(preferenceRaw, 10, 32)
+	target, err := endpoint.ParseMXTarget(mxTarget)
 	if err != nil {
-		return T{}, fmt.Errorf("invalid preference specified")
+		return T{}, err
 	}
 
 	return T{
-		Preference: to.Ptr(int32(preference)),
-		Exchange:   to.Ptr(exchange),
+		Preference: to.Ptr(int32(target.Preference)),
+		Exchange:   to.Ptr(target.Host),
 	}, nil
 }
+
+// txtRecordValue returns the character strings of the value of a TXT record. Azure DNS rejects strings longer than
+// 255 bytes, so the text of targets with longer strings is split, see endpoint.SplitTXT.
+func txtRecordValue(target string) []*string {
+	txt := endpoint.ParseTXTTarget(target)
+	if !txt.Oversized() {
+		return []*string{to.Ptr(target)}
+	}
+	return to.SliceOfPtrs(endpoint.SplitTXT(txt.Text())...)
+}
+
+// txtRecordTarget returns the target of a TXT record, the concatenation of the character strings of its value.
+func txtRecordTarget(value []*string) string {
+	var b strings.Builder
+	for _, s := range value {
+		if s != nil {
+			b.WriteString(*s)
+		}
+	}
+	return b.String()
+}
@@ -18,6 +18,7 @@ package azure
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
@@ -85,3 +86,16 @@ func Test_parseMxTarget(t *testing.T) {
 		})
 	}
 }
+
+func Test_txtRecordValue(t *testing.T) {
+	assert.Equal(t, []*string{to.Ptr(`"heritage=external-dns"`)}, txtRecordValue(`"heritage=external-dns"`))
+
+	key := "v=DKIM1; k=rsa; p=" + strings.Repeat("A", 392)
+	value := txtRecordValue(key)
+	assert.Equal(t, []*string{to.Ptr(key[:255]), to.Ptr(key[255:])}, value)
+	// the strings are joined when the records are read
+	assert.Equal(t, key, txtRecordTarget(value))
+
+	value = txtRecordValue(`"v=DKIM1; " "` + key[9:] + `"`)
+	assert.Equal(t, []*string{to.Ptr(key[:255]), to.Ptr(key[255:])}, value)
+}
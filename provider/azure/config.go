@@ -18,15 +18,19 @@ package azure
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
+
+	"sigs.k8s.io/external-dns/provider"
 )
 
 // config represents common config items for Azure DNS and Azure Private DNS
@@ -73,6 +77,19 @@ func getConfig(configFile, subscriptionID, resourceGroup, userAssignedIdentityCl
 	return cfg, nil
 }
 
+// userAgentPolicy is a pipeline policy prepending the User-Agent of ExternalDNS to the User-Agent of the SDK,
+// which only accepts an application ID without spaces.
+type userAgentPolicy struct{}
+
+func (userAgentPolicy) Do(req *policy.Request) (*http.Response, error) {
+	ua := provider.UserAgent()
+	if sdk := req.Raw().Header.Get("User-Agent"); sdk != "" {
+		ua += " " + sdk
+	}
+	req.Raw().Header.Set("User-Agent", ua)
+	return req.Next()
+}
+
 // getCredentials retrieves Azure API credentials.
 func getCredentials(cfg config) (azcore.TokenCredential, *arm.ClientOptions, error) {
 	cloudCfg, err := getCloudConfiguration(cfg.Cloud)
@@ -80,11 +97,14 @@ func getCredentials(cfg config) (azcore.TokenCredential, *arm.ClientOptions, err
 		return nil, nil, fmt.Errorf("failed to get cloud configuration: %w", err)
 	}
 	clientOpts := azcore.ClientOptions{
-		Cloud: cloudCfg,
+		Cloud:           cloudCfg,
+		PerCallPolicies: []policy.Policy{userAgentPolicy{}},
 	}
 	armClientOpts := &arm.ClientOptions{
 		ClientOptions: clientOpts,
 	}
+	// the credentials requests go to Entra ID, only the ARM requests are throttled per subscription
+	armClientOpts.PerRetryPolicies = append(armClientOpts.PerRetryPolicies, armThrottling)
 
 	// Try to retrieve token with service principal credentials.
 	// Try to use service principal first, some AKS clusters are in an intermediate state that `UseManagedIdentityExtension` is `true`
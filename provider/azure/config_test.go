@@ -17,12 +17,20 @@ limitations under the License.
 package azure
 
 import (
+	"context"
+	"net/http"
 	"path"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	azcoreruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/provider"
 )
 
 func TestGetCloudConfiguration(t *testing.T) {
@@ -59,3 +67,21 @@ func TestOverrideConfiguration(t *testing.T) {
 	assert.Equal(t, cfg.ResourceGroup, "rg-override")
 	assert.Equal(t, cfg.ActiveDirectoryAuthorityHost, "aad-endpoint-override")
 }
+
+func TestUserAgentPolicy(t *testing.T) {
+	provider.SetUserAgent("ExternalDNS/v0.0.0", "prod", "")
+	defer provider.SetUserAgent("ExternalDNS", "", "")
+
+	pipeline := azcoreruntime.NewPipeline("test", "v0.0.0", azcoreruntime.PipelineOptions{}, &policy.ClientOptions{
+		Transport:       &fakeTransport{status: http.StatusOK},
+		PerCallPolicies: []policy.Policy{userAgentPolicy{}},
+	})
+	req, err := azcoreruntime.NewRequest(context.Background(), http.MethodGet, "https://management.azure.com/subscriptions")
+	require.NoError(t, err)
+	resp, err := pipeline.Do(req)
+	require.NoError(t, err)
+
+	// the User-Agent of the SDK is kept after the User-Agent of ExternalDNS
+	ua := resp.Request.Header.Get("User-Agent")
+	assert.True(t, strings.HasPrefix(ua, "ExternalDNS/v0.0.0 (cluster=prod) azsdk-go-test/v0.0.0"), ua)
+}
@@ -0,0 +1,279 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azcoreruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// managementGroupsAPIVersion is the version of the Azure Resource Manager API listing the descendants of a
+// management group.
+const managementGroupsAPIVersion = "2020-05-01"
+
+// subscriptionProvider is the provider of the zones of a subscription.
+type subscriptionProvider interface {
+	provider.Provider
+	zoneNames(ctx context.Context) ([]string, error)
+}
+
+func (p *AzureProvider) zoneNames(ctx context.Context) ([]string, error) {
+	zones, err := p.zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(zones))
+	for _, zone := range zones {
+		names = append(names, *zone.Name)
+	}
+	return names, nil
+}
+
+func (p *AzurePrivateDNSProvider) zoneNames(ctx context.Context) ([]string, error) {
+	zones, err := p.zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(zones))
+	for _, zone := range zones {
+		names = append(names, *zone.Name)
+	}
+	return names, nil
+}
+
+// MultiSubscriptionProvider manages the zones of several subscriptions with a provider per subscription. The
+// records of the zones are merged, and the changes are applied by the provider of the subscription of their zone.
+// A zone found in several subscriptions is managed in the first of them.
+type MultiSubscriptionProvider struct {
+	provider.BaseProvider
+	subscriptionIDs []string
+	providers       []subscriptionProvider
+}
+
+// NewMultiSubscriptionProvider creates the provider of each subscription with newProvider, which must return an
+// Azure or Azure Private DNS provider.
+func NewMultiSubscriptionProvider(subscriptionIDs []string, newProvider func(subscriptionID string) (provider.Provider, error)) (*MultiSubscriptionProvider, error) {
+	if len(subscriptionIDs) == 0 {
+		return nil, errors.New("no Azure subscription specified")
+	}
+	p := &MultiSubscriptionProvider{subscriptionIDs: subscriptionIDs}
+	for _, id := range subscriptionIDs {
+		sp, err := newProvider(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the provider of the Azure subscription %s: %w", id, err)
+		}
+		subscription, ok := sp.(subscriptionProvider)
+		if !ok {
+			return nil, fmt.Errorf("the provider of the Azure subscription %s does not manage Azure zones", id)
+		}
+		p.providers = append(p.providers, subscription)
+	}
+	log.Infof("Managing the zones of %d Azure subscriptions.", len(subscriptionIDs))
+	return p, nil
+}
+
+// zoneOwners returns the zones of the subscriptions, keyed by zone name, and the index of the provider managing each
+// of them.
+func (p *MultiSubscriptionProvider) zoneOwners(ctx context.Context) (provider.ZoneIDName, map[string]int, map[string]bool, error) {
+	zones := provider.ZoneIDName{}
+	owners := map[string]int{}
+	shared := map[string]bool{}
+	for i, sp := range p.providers {
+		names, err := sp.zoneNames(ctx)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to list the zones of the Azure subscription %s: %w", p.subscriptionIDs[i], err)
+		}
+		for _, name := range names {
+			if owner, ok := owners[name]; ok {
+				if !shared[name] {
+					log.Warnf("The zone %s is found in the Azure subscriptions %s and %s, managing it in %s.", name, p.subscriptionIDs[owner], p.subscriptionIDs[i], p.subscriptionIDs[owner])
+				}
+				shared[name] = true
+				continue
+			}
+			zones.Add(name, name)
+			owners[name] = i
+		}
+	}
+	return zones, owners, shared, nil
+}
+
+// Records returns the records of the zones of all the subscriptions.
+func (p *MultiSubscriptionProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	zones, owners, shared, err := p.zoneOwners(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var endpoints []*endpoint.Endpoint
+	for i, sp := range p.providers {
+		records, err := sp.Records(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, ep := range records {
+			// the records of a zone found in several subscriptions are only read from the subscription managing it
+			if _, zone := zones.FindZone(ep.DNSName); shared[zone] && owners[zone] != i {
+				continue
+			}
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return endpoints, nil
+}
+
+// ApplyChanges applies the changes of the zones of each subscription with its provider.
+func (p *MultiSubscriptionProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	zones, owners, _, err := p.zoneOwners(ctx)
+	if err != nil {
+		return err
+	}
+	routed := make([]*plan.Changes, len(p.providers))
+	for i := range routed {
+		routed[i] = &plan.Changes{}
+	}
+	route := func(endpoints []*endpoint.Endpoint, changesOf func(*plan.Changes) *[]*endpoint.Endpoint) {
+		for _, ep := range endpoints {
+			_, zone := zones.FindZone(ep.DNSName)
+			if zone == "" {
+				log.Infof("Ignoring changes to '%s' because a suitable Azure zone was not found in any subscription.", ep.DNSName)
+				continue
+			}
+			target := changesOf(routed[owners[zone]])
+			*target = append(*target, ep)
+		}
+	}
+	route(changes.Create, func(c *plan.Changes) *[]*endpoint.Endpoint { return &c.Create })
+	route(changes.UpdateOld, func(c *plan.Changes) *[]*endpoint.Endpoint { return &c.UpdateOld })
+	route(changes.UpdateNew, func(c *plan.Changes) *[]*endpoint.Endpoint { return &c.UpdateNew })
+	route(changes.Delete, func(c *plan.Changes) *[]*endpoint.Endpoint { return &c.Delete })
+
+	var errs []error
+	for i, sp := range p.providers {
+		if !routed[i].HasChanges() {
+			continue
+		}
+		if err := sp.ApplyChanges(ctx, routed[i]); err != nil {
+			errs = append(errs, fmt.Errorf("failed to apply the changes of the Azure subscription %s: %w", p.subscriptionIDs[i], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AdjustEndpoints adjusts the endpoints as the providers of the subscriptions do.
+func (p *MultiSubscriptionProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	return p.providers[0].AdjustEndpoints(endpoints)
+}
+
+// GetDomainFilter returns the domain filter of the providers of the subscriptions.
+func (p *MultiSubscriptionProvider) GetDomainFilter() endpoint.DomainFilterInterface {
+	return p.providers[0].GetDomainFilter()
+}
+
+// ParseSubscriptionIDs parses the subscription IDs of the comma-separated lists, ignoring the duplicates.
+func ParseSubscriptionIDs(specs []string) []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, spec := range specs {
+		for _, id := range strings.Split(spec, ",") {
+			id = strings.TrimSpace(id)
+			if id == "" || seen[strings.ToLower(id)] {
+				continue
+			}
+			seen[strings.ToLower(id)] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// DiscoverSubscriptions returns the IDs of the subscriptions of the management group and of its descendant
+// management groups, authenticating with the credentials of the Azure config file.
+func DiscoverSubscriptions(ctx context.Context, configFile, managementGroupID, userAssignedIdentityClientID, activeDirectoryAuthorityHost string) ([]string, error) {
+	cfg, err := getConfig(configFile, "", "", userAssignedIdentityClientID, activeDirectoryAuthorityHost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Azure config file '%s': %v", configFile, err)
+	}
+	cred, clientOpts, err := getCredentials(*cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+	client, err := arm.NewClient("external-dns", "v1.0.0", cred, clientOpts)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := managementGroupSubscriptions(ctx, client, managementGroupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the subscriptions of the Azure management group %s: %w", managementGroupID, err)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no subscription found in the Azure management group %s", managementGroupID)
+	}
+	log.Infof("Found %d subscriptions in the Azure management group %s.", len(ids), managementGroupID)
+	return ids, nil
+}
+
+// managementGroupDescendants is a page of the descendants of a management group.
+type managementGroupDescendants struct {
+	Value []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"value"`
+	NextLink string `json:"nextLink"`
+}
+
+// managementGroupSubscriptions lists the subscriptions among the descendants of the management group.
+func managementGroupSubscriptions(ctx context.Context, client *arm.Client, managementGroupID string) ([]string, error) {
+	next := strings.TrimSuffix(client.Endpoint(), "/") + "/providers/Microsoft.Management/managementGroups/" +
+		url.PathEscape(managementGroupID) + "/descendants?api-version=" + managementGroupsAPIVersion
+	var ids []string
+	for next != "" {
+		req, err := azcoreruntime.NewRequest(ctx, http.MethodGet, next)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Pipeline().Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if !azcoreruntime.HasStatusCode(resp, http.StatusOK) {
+			return nil, azcoreruntime.NewResponseError(resp)
+		}
+		var page managementGroupDescendants
+		if err := azcoreruntime.UnmarshalAsJSON(resp, &page); err != nil {
+			return nil, err
+		}
+		for _, descendant := range page.Value {
+			if strings.HasSuffix(strings.ToLower(descendant.Type), "/subscriptions") {
+				ids = append(ids, descendant.Name)
+			}
+		}
+		next = page.NextLink
+	}
+	return ids, nil
+}
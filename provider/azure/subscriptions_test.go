@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	dns "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/dns/armdns"
+	privatedns "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// newSubscriptionTestProvider creates a provider of the zones of a subscription with the record sets listed in each of
+// its zones.
+func newSubscriptionTestProvider(zones []*dns.Zone, recordSets []*dns.RecordSet) (*AzureProvider, *mockRecordSetsClient) {
+	zonesClient := newMockZonesClient(zones)
+	recordSetsClient := newMockRecordSetsClient(recordSets)
+	p := newAzureProvider(endpoint.NewDomainFilter([]string{}), endpoint.NewDomainFilter([]string{}), provider.NewZoneIDFilter([]string{""}), false, "k8s", "", "", &zonesClient, &recordSetsClient)
+	return p, &recordSetsClient
+}
+
+func newTestMultiSubscriptionProvider(t *testing.T, providers map[string]provider.Provider, subscriptionIDs ...string) *MultiSubscriptionProvider {
+	p, err := NewMultiSubscriptionProvider(subscriptionIDs, func(subscriptionID string) (provider.Provider, error) {
+		return providers[subscriptionID], nil
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func TestMultiSubscriptionProviderRecords(t *testing.T) {
+	prod, _ := newSubscriptionTestProvider(
+		[]*dns.Zone{createMockZone("example.com", "/dnszones/example.com")},
+		[]*dns.RecordSet{createMockRecordSet("web", endpoint.RecordTypeA, "1.2.3.4")},
+	)
+	dev, _ := newSubscriptionTestProvider(
+		[]*dns.Zone{createMockZone("example.org", "/dnszones/example.org"), createMockZone("example.com", "/dnszones/example.com")},
+		[]*dns.RecordSet{createMockRecordSet("api", endpoint.RecordTypeCNAME, "web.example.com")},
+	)
+	p := newTestMultiSubscriptionProvider(t, map[string]provider.Provider{"prod": prod, "dev": dev}, "prod", "dev")
+
+	endpoints, err := p.Records(context.Background())
+	require.NoError(t, err)
+
+	// the zone example.com is found in both subscriptions and only read from the first one
+	validateAzureEndpoints(t, endpoints, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("web.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("api.example.org", endpoint.RecordTypeCNAME, "web.example.com"),
+	})
+}
+
+func TestMultiSubscriptionProviderApplyChanges(t *testing.T) {
+	prod, prodClient := newSubscriptionTestProvider([]*dns.Zone{createMockZone("example.com", "/dnszones/example.com")}, nil)
+	dev, devClient := newSubscriptionTestProvider([]*dns.Zone{
+		createMockZone("example.org", "/dnszones/example.org"),
+		createMockZone("dev.example.com", "/dnszones/dev.example.com"),
+	}, nil)
+	p := newTestMultiSubscriptionProvider(t, map[string]provider.Provider{"prod": prod, "dev": dev}, "prod", "dev")
+
+	err := p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("web.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+			endpoint.NewEndpoint("api.dev.example.com", endpoint.RecordTypeA, "5.6.7.8"),
+			endpoint.NewEndpoint("web.example.net", endpoint.RecordTypeA, "9.9.9.9"),
+		},
+		Delete: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("old.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+		},
+	})
+	require.NoError(t, err)
+
+	validateAzureEndpoints(t, prodClient.updatedEndpoints, []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("web.example.com", endpoint.RecordTypeA, azureRecordTTL, "1.2.3.4"),
+	})
+	assert.Empty(t, prodClient.deletedEndpoints)
+	validateAzureEndpoints(t, devClient.updatedEndpoints, []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("api.dev.example.com", endpoint.RecordTypeA, azureRecordTTL, "5.6.7.8"),
+	})
+	validateAzureEndpoints(t, devClient.deletedEndpoints, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("old.example.org", endpoint.RecordTypeA, ""),
+	})
+}
+
+func TestMultiSubscriptionProviderPrivateDNS(t *testing.T) {
+	zonesClient := newMockPrivateZonesClient([]*privatedns.PrivateZone{createMockPrivateZone("example.internal", "/privatednszones/example.internal")})
+	recordSetsClient := newMockPrivateRecordSectsClient([]*privatedns.RecordSet{createPrivateMockRecordSet("db", endpoint.RecordTypeA, "10.0.0.4")})
+	private := newAzurePrivateDNSProvider(endpoint.NewDomainFilter([]string{}), endpoint.NewDomainFilter([]string{}), provider.NewZoneIDFilter([]string{""}), false, "k8s", &zonesClient, &recordSetsClient)
+	p := newTestMultiSubscriptionProvider(t, map[string]provider.Provider{"network": private}, "network")
+
+	endpoints, err := p.Records(context.Background())
+	require.NoError(t, err)
+	validateAzureEndpoints(t, endpoints, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("db.example.internal", endpoint.RecordTypeA, "10.0.0.4"),
+	})
+}
+
+func TestNewMultiSubscriptionProviderErrors(t *testing.T) {
+	_, err := NewMultiSubscriptionProvider(nil, nil)
+	assert.EqualError(t, err, "no Azure subscription specified")
+
+	_, err = NewMultiSubscriptionProvider([]string{"prod"}, func(string) (provider.Provider, error) {
+		return nil, fmt.Errorf("no credentials provided for Azure API")
+	})
+	assert.EqualError(t, err, "failed to create the provider of the Azure subscription prod: no credentials provided for Azure API")
+
+	_, err = NewMultiSubscriptionProvider([]string{"prod"}, func(string) (provider.Provider, error) {
+		return provider.NewReadOnlyProvider(nil), nil
+	})
+	assert.EqualError(t, err, "the provider of the Azure subscription prod does not manage Azure zones")
+}
+
+func TestParseSubscriptionIDs(t *testing.T) {
+	assert.Equal(t, []string{"sub1", "sub2", "sub3"}, ParseSubscriptionIDs([]string{"sub1, sub2", "SUB1,sub3,"}))
+	assert.Empty(t, ParseSubscriptionIDs([]string{" , "}))
+}
+
+func TestManagementGroupSubscriptions(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/providers/Microsoft.Management/managementGroups/platform/descendants", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "" {
+			fmt.Fprintf(w, `{"value": [
+				{"name": "sub1", "type": "/subscriptions"},
+				{"name": "networking", "type": "Microsoft.Management/managementGroups"}
+			], "nextLink": "%s%s?api-version=%s&page=2"}`, server.URL, r.URL.Path, managementGroupsAPIVersion)
+			return
+		}
+		fmt.Fprint(w, `{"value": [{"name": "sub2", "type": "Microsoft.Management/managementGroups/subscriptions"}]}`)
+	}))
+	defer server.Close()
+
+	client, err := arm.NewClient("external-dns", "v1.0.0", &fake.TokenCredential{}, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Cloud: cloud.Configuration{
+				Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+					cloud.ResourceManager: {Endpoint: server.URL, Audience: "https://management.azure.com"},
+				},
+			},
+			InsecureAllowCredentialWithHTTP: true,
+		},
+	})
+	require.NoError(t, err)
+
+	ids, err := managementGroupSubscriptions(context.Background(), client, "platform")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sub1", "sub2"}, ids)
+}
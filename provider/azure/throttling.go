@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// remainingReadsHeader and remainingWritesHeader are the ARM headers with the number of requests left
+	// before the subscription is throttled.
+	remainingReadsHeader  = "x-ms-ratelimit-remaining-subscription-reads"
+	remainingWritesHeader = "x-ms-ratelimit-remaining-subscription-writes"
+
+	// paceThreshold is the number of remaining requests below which the requests are paced
+	paceThreshold = 100
+	// maxPaceDelay is the delay between requests when no request is left
+	maxPaceDelay = 2 * time.Second
+	// defaultRetryAfter is the delay after a 429 response without Retry-After header
+	defaultRetryAfter = 10 * time.Second
+)
+
+var (
+	remainingRequests = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "azure",
+			Name:      "ratelimit_remaining_requests",
+			Help:      "Number of ARM requests left before the subscription is throttled, by operation.",
+		},
+		[]string{"operation"},
+	)
+	throttledRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "azure",
+			Name:      "throttled_requests_total",
+			Help:      "Number of ARM requests throttled with a 429 response.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(remainingRequests)
+	prometheus.MustRegister(throttledRequests)
+}
+
+// armThrottling is shared by the clients of the Azure providers, since ARM throttles the requests per subscription.
+var armThrottling = newThrottlingPolicy()
+
+// throttlingPolicy is a pipeline policy holding the requests back while ARM throttles them: until the Retry-After
+// of a 429 response has passed, and paced with a delay growing as the remaining requests reported by ARM run out.
+// It runs for every retry, so that the retries of the SDK are held back too.
+type throttlingPolicy struct {
+	mu sync.Mutex
+	// until is the time before which no request is sent
+	until time.Time
+	now   func() time.Time
+}
+
+func newThrottlingPolicy() *throttlingPolicy {
+	return &throttlingPolicy{now: time.Now}
+}
+
+func (t *throttlingPolicy) Do(req *policy.Request) (*http.Response, error) {
+	if err := t.wait(req); err != nil {
+		return nil, err
+	}
+	resp, err := req.Next()
+	if err != nil {
+		return resp, err
+	}
+	t.observe(resp)
+	return resp, nil
+}
+
+// wait blocks until the requests are no longer held back, or the request is canceled.
+func (t *throttlingPolicy) wait(req *policy.Request) error {
+	t.mu.Lock()
+	delay := t.until.Sub(t.now())
+	t.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	log.Debugf("Delaying the Azure request by %s because of ARM throttling", delay)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Raw().Context().Done():
+		return req.Raw().Context().Err()
+	}
+}
+
+// observe holds the next requests back according to the response.
+func (t *throttlingPolicy) observe(resp *http.Response) {
+	var delay time.Duration
+	for operation, header := range map[string]string{"reads": remainingReadsHeader, "writes": remainingWritesHeader} {
+		remaining, err := strconv.Atoi(resp.Header.Get(header))
+		if err != nil {
+			continue
+		}
+		remainingRequests.WithLabelValues(operation).Set(float64(remaining))
+		if remaining < paceThreshold {
+			delay = max(delay, maxPaceDelay*time.Duration(paceThreshold-remaining)/paceThreshold)
+		}
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		throttledRequests.Inc()
+		delay = max(delay, t.retryAfter(resp))
+		log.Warnf("Azure request throttled by ARM, holding the requests back for %s", delay)
+	}
+	if delay <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if until := t.now().Add(delay); until.After(t.until) {
+		t.until = until
+	}
+}
+
+// retryAfter returns the delay of the Retry-After header, in seconds or as an HTTP date.
+func (t *throttlingPolicy) retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return date.Sub(t.now())
+	}
+	return defaultRetryAfter
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	azcoreruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTransport returns a response with the status code and headers.
+type fakeTransport struct {
+	status  int
+	headers map[string]string
+}
+
+func (f *fakeTransport) Do(req *http.Request) (*http.Response, error) {
+	resp := &http.Response{StatusCode: f.status, Header: http.Header{}, Request: req, Body: http.NoBody}
+	for name, value := range f.headers {
+		resp.Header.Set(name, value)
+	}
+	return resp, nil
+}
+
+func sendThrottled(ctx context.Context, t *testing.T, throttling *throttlingPolicy, transport *fakeTransport) error {
+	pipeline := azcoreruntime.NewPipeline("test", "v0.0.0", azcoreruntime.PipelineOptions{}, &policy.ClientOptions{
+		Transport:        transport,
+		PerRetryPolicies: []policy.Policy{throttling},
+		Retry:            policy.RetryOptions{MaxRetries: -1},
+	})
+	req, err := azcoreruntime.NewRequest(ctx, http.MethodGet, "https://management.azure.com/subscriptions")
+	require.NoError(t, err)
+	_, err = pipeline.Do(req)
+	return err
+}
+
+func TestThrottlingPolicyHonorsRetryAfter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	throttling := &throttlingPolicy{now: func() time.Time { return now }}
+	throttled := testutil.ToFloat64(throttledRequests)
+
+	require.NoError(t, sendThrottled(context.Background(), t, throttling, &fakeTransport{status: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "5"}}))
+	assert.Equal(t, now.Add(5*time.Second), throttling.until)
+	assert.Equal(t, throttled+1, testutil.ToFloat64(throttledRequests))
+
+	// a shorter delay does not shorten the hold
+	throttling.observe(&http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"1"}}})
+	assert.Equal(t, now.Add(5*time.Second), throttling.until)
+}
+
+func TestThrottlingPolicyPacesRemainingRequests(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	throttling := &throttlingPolicy{now: func() time.Time { return now }}
+
+	require.NoError(t, sendThrottled(context.Background(), t, throttling, &fakeTransport{status: http.StatusOK, headers: map[string]string{remainingReadsHeader: "11999"}}))
+	assert.True(t, throttling.until.IsZero())
+	assert.Equal(t, 11999.0, testutil.ToFloat64(remainingRequests.WithLabelValues("reads")))
+
+	require.NoError(t, sendThrottled(context.Background(), t, throttling, &fakeTransport{status: http.StatusOK, headers: map[string]string{remainingWritesHeader: "50"}}))
+	assert.Equal(t, now.Add(maxPaceDelay/2), throttling.until)
+	assert.Equal(t, 50.0, testutil.ToFloat64(remainingRequests.WithLabelValues("writes")))
+}
+
+func TestThrottlingPolicyWaitIsCanceled(t *testing.T) {
+	throttling := newThrottlingPolicy()
+	throttling.until = time.Now().Add(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := sendThrottled(ctx, t, throttling, &fakeTransport{status: http.StatusOK})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// OwnershipGroup returns the name of the record the endpoint is applied with: the record owned by an ownership
+// record of the TXT registry, the name of the endpoint otherwise.
+func OwnershipGroup(ep *endpoint.Endpoint) string {
+	if owned := ep.Labels[endpoint.OwnedRecordLabelKey]; owned != "" {
+		return owned
+	}
+	return ep.DNSName
+}
+
+// OwnershipBatches splits the endpoints into batches of at most size endpoints, returned as the indexes of the
+// endpoints, without separating the endpoints of an ownership group, so that a record and its ownership records
+// are always changed in the same batch: a failure between two batches leaves no record without its owner.
+// The groups are batched in the order of their first endpoint. A group larger than size is a batch of its own.
+// If size is not positive, all endpoints are a single batch.
+func OwnershipBatches(endpoints []*endpoint.Endpoint, size int) [][]int {
+	if len(endpoints) == 0 {
+		return nil
+	}
+	var order []string
+	groups := map[string][]int{}
+	for i, ep := range endpoints {
+		group := OwnershipGroup(ep)
+		if _, ok := groups[group]; !ok {
+			order = append(order, group)
+		}
+		groups[group] = append(groups[group], i)
+	}
+
+	var batches [][]int
+	var current []int
+	for _, group := range order {
+		indexes := groups[group]
+		if size > 0 && len(current) > 0 && len(current)+len(indexes) > size {
+			batches = append(batches, current)
+			current = nil
+		}
+		current = append(current, indexes...)
+	}
+	return append(batches, current)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func ownershipRecord(name, owned string) *endpoint.Endpoint {
+	ep := endpoint.NewEndpoint(name, endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=owner\"")
+	ep.Labels[endpoint.OwnedRecordLabelKey] = owned
+	return ep
+}
+
+func TestOwnershipGroup(t *testing.T) {
+	assert.Equal(t, "foo.example.org", OwnershipGroup(endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4")))
+	assert.Equal(t, "foo.example.org", OwnershipGroup(ownershipRecord("a-foo.example.org", "foo.example.org")))
+}
+
+func TestOwnershipBatches(t *testing.T) {
+	endpoints := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("bar.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+		ownershipRecord("a-foo.example.org", "foo.example.org"),
+		ownershipRecord("foo.example.org", "foo.example.org"),
+		ownershipRecord("a-bar.example.org", "bar.example.org"),
+		endpoint.NewEndpoint("baz.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+	}
+
+	for _, tc := range []struct {
+		name     string
+		size     int
+		expected [][]int
+	}{
+		{name: "unlimited", size: 0, expected: [][]int{{0, 2, 3, 1, 4, 5}}},
+		{name: "groups together", size: 5, expected: [][]int{{0, 2, 3, 1, 4}, {5}}},
+		{name: "group per batch", size: 3, expected: [][]int{{0, 2, 3}, {1, 4, 5}}},
+		{name: "group larger than size", size: 2, expected: [][]int{{0, 2, 3}, {1, 4}, {5}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, OwnershipBatches(endpoints, tc.size))
+		})
+	}
+
+	assert.Nil(t, OwnershipBatches(nil, 2))
+}
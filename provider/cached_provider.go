@@ -68,7 +68,16 @@ func NewCachedProvider(provider Provider, refreshDelay time.Duration) *CachedPro
 	}
 }
 
+// Unwrap returns the wrapped provider.
+func (c *CachedProvider) Unwrap() Provider {
+	return c.Provider
+}
+
 func (c *CachedProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	if _, ok := ZoneScope(ctx); ok {
+		// the cache holds the records of all zones
+		return c.Provider.Records(ctx)
+	}
 	if c.needRefresh() {
 		log.Info("Records cache provider: refreshing records list cache")
 		records, err := c.Provider.Records(ctx)
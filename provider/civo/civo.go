@@ -26,7 +26,6 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/external-dns/endpoint"
-	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
 	"sigs.k8s.io/external-dns/plan"
 	"sigs.k8s.io/external-dns/provider"
 )
@@ -86,11 +85,8 @@ func NewCivoProvider(domainFilter endpoint.DomainFilter, dryRun bool) (*CivoProv
 		return nil, err
 	}
 
-	userAgent := &civogo.Component{
-		Name:    "external-dns",
-		Version: externaldns.Version,
-	}
-	civoClient.SetUserAgent(userAgent)
+	// civogo replaces the transport of its client on every request, so the User-Agent is set on the client
+	civoClient.UserAgent = provider.UserAgent() + " " + civoClient.UserAgent
 
 	provider := &CivoProvider{
 		Client:       *civoClient,
@@ -136,7 +132,7 @@ func (p *CivoProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 					name = zone.Name
 				}
 
-				endpoints = append(endpoints, endpoint.NewEndpointWithTTL(name, toUpper, endpoint.TTL(r.TTL), r.Value))
+				endpoints = append(endpoints, endpoint.NewEndpointWithTTL(name, toUpper, endpoint.TTL(r.TTL), recordTarget(r)))
 			}
 		}
 	}
@@ -283,14 +279,8 @@ func processCreateActions(zonesByID map[string]civogo.DNSDomain, recordsByZoneID
 
 			for _, target := range ep.Targets {
 				civoChange.Creates = append(civoChange.Creates, &CivoChangeCreate{
-					Domain: zone,
-					Options: &civogo.DNSRecordConfig{
-						Value:    target,
-						Name:     getStrippedRecordName(zone, *ep),
-						Type:     recordType,
-						Priority: 0,
-						TTL:      int(ep.RecordTTL),
-					},
+					Domain:  zone,
+					Options: newRecordConfig(zone, *ep, recordType, target),
 				})
 			}
 		}
@@ -332,7 +322,7 @@ func processUpdateActions(zonesByID map[string]civogo.DNSDomain, recordsByZoneID
 
 			matchedRecordsByTarget := make(map[string]civogo.DNSRecord)
 			for _, record := range matchedRecords {
-				matchedRecordsByTarget[record.Value] = record
+				matchedRecordsByTarget[recordTarget(record)] = record
 			}
 
 			for _, target := range ep.Targets {
@@ -348,13 +338,7 @@ func processUpdateActions(zonesByID map[string]civogo.DNSDomain, recordsByZoneID
 					civoChange.Updates = append(civoChange.Updates, &CivoChangeUpdate{
 						Domain:       zone,
 						DomainRecord: record,
-						Options: civogo.DNSRecordConfig{
-							Value:    target,
-							Name:     getStrippedRecordName(zone, *ep),
-							Type:     recordType,
-							Priority: 0,
-							TTL:      int(ep.RecordTTL),
-						},
+						Options:      *newRecordConfig(zone, *ep, recordType, target),
 					})
 
 					delete(matchedRecordsByTarget, target)
@@ -369,14 +353,8 @@ func processUpdateActions(zonesByID map[string]civogo.DNSDomain, recordsByZoneID
 					}).Warn("Creating New Target")
 
 					civoChange.Creates = append(civoChange.Creates, &CivoChangeCreate{
-						Domain: zone,
-						Options: &civogo.DNSRecordConfig{
-							Value:    target,
-							Name:     getStrippedRecordName(zone, *ep),
-							Type:     recordType,
-							Priority: 0,
-							TTL:      int(ep.RecordTTL),
-						},
+						Domain:  zone,
+						Options: newRecordConfig(zone, *ep, recordType, target),
 					})
 				}
 			}
@@ -387,7 +365,7 @@ func processUpdateActions(zonesByID map[string]civogo.DNSDomain, recordsByZoneID
 					"zoneID":     zoneID,
 					"dnsName":    ep.DNSName,
 					"recordType": ep.RecordType,
-					"target":     record.Value,
+					"target":     recordTarget(record),
 				}).Warn("Deleting target")
 
 				civoChange.Deletes = append(civoChange.Deletes, &CivoChangeDelete{
@@ -525,6 +503,37 @@ func convertRecordType(recordType string) (civogo.DNSRecordType, error) {
 	}
 }
 
+// newRecordConfig returns the configuration of the record of a target of an endpoint. The priority of an SRV record
+// is the priority of the target, see endpoint.SRVTarget, and its value the weight, port and host of the target.
+func newRecordConfig(zone civogo.DNSDomain, ep endpoint.Endpoint, recordType civogo.DNSRecordType, target string) *civogo.DNSRecordConfig {
+	config := &civogo.DNSRecordConfig{
+		Value:    target,
+		Name:     getStrippedRecordName(zone, ep),
+		Type:     recordType,
+		Priority: 0,
+		TTL:      int(ep.RecordTTL),
+	}
+	if recordType == civogo.DNSRecordTypeSRV {
+		if srv, err := endpoint.ParseSRVTarget(target); err == nil {
+			config.Priority = int(srv.Priority)
+			config.Value = fmt.Sprintf("%d %d %s", srv.Weight, srv.Port, srv.Host)
+		}
+	}
+	return config
+}
+
+// recordTarget returns the target of the endpoint of a record, see newRecordConfig.
+func recordTarget(record civogo.DNSRecord) string {
+	if !strings.EqualFold(string(record.Type), civogo.DNSRecordTypeSRV) {
+		return record.Value
+	}
+	srv, err := endpoint.ParseSRVTarget(fmt.Sprintf("%d %s", record.Priority, record.Value))
+	if err != nil {
+		return record.Value
+	}
+	return srv.String()
+}
+
 func getStrippedRecordName(zone civogo.DNSDomain, ep endpoint.Endpoint) string {
 	if ep.DNSName == zone.Name {
 		return ""
@@ -1020,3 +1020,16 @@ func isEmpty(xs interface{}) bool {
 	}
 	return true
 }
+
+func TestCivoSRVRecordConfig(t *testing.T) {
+	zone := civogo.DNSDomain{Name: "example.com"}
+	ep := endpoint.Endpoint{DNSName: "_sip._tcp.example.com", RecordType: endpoint.RecordTypeSRV, RecordTTL: 300}
+
+	config := newRecordConfig(zone, ep, civogo.DNSRecordTypeSRV, "10 5 5060 sip.example.com")
+	assert.Equal(t, 10, config.Priority)
+	assert.Equal(t, "5 5060 sip.example.com", config.Value)
+
+	record := civogo.DNSRecord{Type: "srv", Value: "5 5060 sip.example.com", Priority: 10}
+	assert.Equal(t, "10 5 5060 sip.example.com", recordTarget(record))
+	assert.Equal(t, "1.2.3.4", recordTarget(civogo.DNSRecord{Type: civogo.DNSRecordTypeA, Value: "1.2.3.4", Priority: 10}))
+}
@@ -18,11 +18,14 @@ package cloudflare
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 
 	cloudflare "github.com/cloudflare/cloudflare-go"
 	log "github.com/sirupsen/logrus"
@@ -68,11 +71,13 @@ type cloudFlareDNS interface {
 	ZoneIDByName(zoneName string) (string, error)
 	ListZones(ctx context.Context, zoneID ...string) ([]cloudflare.Zone, error)
 	ListZonesContext(ctx context.Context, opts ...cloudflare.ReqOption) (cloudflare.ZonesResponse, error)
+	ListZonesPage(ctx context.Context, page, perPage int) ([]cloudflare.Zone, cloudflare.ResultInfo, error)
 	ZoneDetails(ctx context.Context, zoneID string) (cloudflare.Zone, error)
 	ListDNSRecords(ctx context.Context, rc *cloudflare.ResourceContainer, rp cloudflare.ListDNSRecordsParams) ([]cloudflare.DNSRecord, *cloudflare.ResultInfo, error)
 	CreateDNSRecord(ctx context.Context, rc *cloudflare.ResourceContainer, rp cloudflare.CreateDNSRecordParams) (cloudflare.DNSRecord, error)
 	DeleteDNSRecord(ctx context.Context, rc *cloudflare.ResourceContainer, recordID string) error
 	UpdateDNSRecord(ctx context.Context, rc *cloudflare.ResourceContainer, rp cloudflare.UpdateDNSRecordParams) error
+	ExportDNSRecords(ctx context.Context, rc *cloudflare.ResourceContainer, params cloudflare.ExportDNSRecordsParams) (string, error)
 }
 
 type zoneService struct {
@@ -99,6 +104,10 @@ func (z zoneService) ListDNSRecords(ctx context.Context, rc *cloudflare.Resource
 	return z.service.ListDNSRecords(ctx, rc, rp)
 }
 
+func (z zoneService) ExportDNSRecords(ctx context.Context, rc *cloudflare.ResourceContainer, params cloudflare.ExportDNSRecordsParams) (string, error) {
+	return z.service.ExportDNSRecords(ctx, rc, params)
+}
+
 func (z zoneService) UpdateDNSRecord(ctx context.Context, rc *cloudflare.ResourceContainer, rp cloudflare.UpdateDNSRecordParams) error {
 	_, err := z.service.UpdateDNSRecord(ctx, rc, rp)
 	return err
@@ -112,6 +121,22 @@ func (z zoneService) ListZonesContext(ctx context.Context, opts ...cloudflare.Re
 	return z.service.ListZonesContext(ctx, opts...)
 }
 
+// ListZonesPage lists a page of the zones, which ListZonesContext does not allow.
+func (z zoneService) ListZonesPage(ctx context.Context, page, perPage int) ([]cloudflare.Zone, cloudflare.ResultInfo, error) {
+	response, err := z.service.Raw(ctx, http.MethodGet, fmt.Sprintf("/zones?page=%d&per_page=%d", page, perPage), nil, nil)
+	if err != nil {
+		return nil, cloudflare.ResultInfo{}, err
+	}
+	var zones []cloudflare.Zone
+	if err := json.Unmarshal(response.Result, &zones); err != nil {
+		return nil, cloudflare.ResultInfo{}, fmt.Errorf("failed to parse the zones: %w", err)
+	}
+	if response.ResultInfo == nil {
+		return zones, cloudflare.ResultInfo{Page: page, PerPage: perPage, TotalPages: 1}, nil
+	}
+	return zones, *response.ResultInfo, nil
+}
+
 func (z zoneService) ZoneDetails(ctx context.Context, zoneID string) (cloudflare.Zone, error) {
 	return z.service.ZoneDetails(ctx, zoneID)
 }
@@ -126,12 +151,35 @@ type CloudFlareProvider struct {
 	proxiedByDefault  bool
 	DryRun            bool
 	DNSRecordsPerPage int
+	// ExportListingThreshold is the number of records from which zones are listed with the zone export. Zero disables the export.
+	ExportListingThreshold int
+	// ListingWorkers is the number of concurrent requests listing the pages of zones and the records of the zones
+	ListingWorkers int
+	// exportedZones are the record counts of the zones listed with the zone export, guarded by exportedZonesMu
+	exportedZones   map[string]int
+	exportedZonesMu sync.Mutex
+	// loadBalancing manages the load balancers of the zones, and the origin pools and monitors of the account
+	// loadBalancerAccountID. A nil client disables the load balancers.
+	loadBalancing         cloudFlareLoadBalancing
+	loadBalancerAccountID string
+	// customHostnames manages the custom hostnames of the zone customHostnamesZone. A nil client disables the
+	// custom hostnames.
+	customHostnames     cloudFlareCustomHostnames
+	customHostnamesZone string
+	// regionalHostnames manages the regional hostnames of the zones, whose region defaults to regionKey. A nil
+	// client disables the regional hostnames.
+	regionalHostnames cloudFlareRegionalHostnames
+	regionKey         string
+	// tokenVerifier verifies the API token, nil without API token
+	tokenVerifier cloudFlareTokenVerifier
 }
 
 // cloudFlareChange differentiates between ChangActions
 type cloudFlareChange struct {
 	Action         string
 	ResourceRecord cloudflare.DNSRecord
+	// clearComment is true if the update removes the comment of the record
+	clearComment bool
 }
 
 // RecordParamsTypes is a typeset of the possible Record Params that can be passed to cloudflare-go library
@@ -141,13 +189,18 @@ type RecordParamsTypes interface {
 
 // getUpdateDNSRecordParam is a function that returns the appropriate Record Param based on the cloudFlareChange passed in
 func getUpdateDNSRecordParam(cfc cloudFlareChange) cloudflare.UpdateDNSRecordParams {
-	return cloudflare.UpdateDNSRecordParams{
+	params := cloudflare.UpdateDNSRecordParams{
 		Name:    cfc.ResourceRecord.Name,
 		TTL:     cfc.ResourceRecord.TTL,
 		Proxied: cfc.ResourceRecord.Proxied,
 		Type:    cfc.ResourceRecord.Type,
 		Content: cfc.ResourceRecord.Content,
 	}
+	// the comment of a record without comment is kept, unless ExternalDNS set it
+	if cfc.ResourceRecord.Comment != "" || cfc.clearComment {
+		params.Comment = &cfc.ResourceRecord.Comment
+	}
+	return params
 }
 
 // getCreateDNSRecordParam is a function that returns the appropriate Record Param based on the cloudFlareChange passed in
@@ -158,40 +211,104 @@ func getCreateDNSRecordParam(cfc cloudFlareChange) cloudflare.CreateDNSRecordPar
 		Proxied: cfc.ResourceRecord.Proxied,
 		Type:    cfc.ResourceRecord.Type,
 		Content: cfc.ResourceRecord.Content,
+		Comment: cfc.ResourceRecord.Comment,
+	}
+}
+
+// apiOptions returns the options of an API client: the User-Agent of ExternalDNS, and an HTTP client exporting the
+// quota of its token and pacing its requests as the quota runs out. The quota is per token, so every API client has
+// its own.
+func apiOptions() []cloudflare.Option {
+	return []cloudflare.Option{
+		cloudflare.HTTPClient(&http.Client{Transport: provider.NewQuotaTransport("cloudflare", provider.NewAPICallCounter(nil))}),
+		cloudflare.UserAgent(provider.UserAgent()),
 	}
 }
 
+// CloudFlareConfig contains configuration to create a new CloudFlare provider.
+type CloudFlareConfig struct {
+	DomainFilter           endpoint.DomainFilter
+	ZoneIDFilter           provider.ZoneIDFilter
+	ProxiedByDefault       bool
+	DryRun                 bool
+	DNSRecordsPerPage      int
+	ExportListingThreshold int
+	// ZoneTokensFile lists the zones managed with their own API token. The other zones are managed with the
+	// token of the environment, if any.
+	ZoneTokensFile string
+	// LoadBalancerAccountID is the account of the origin pools and monitors of the load balancers serving the
+	// targets of the endpoints annotated for it.
+	LoadBalancerAccountID string
+	// CustomHostnamesZone is the zone of the custom hostnames serving the hostnames annotated for it.
+	CustomHostnamesZone string
+	// RegionalServices manages the hostnames annotated with a region key as regional hostnames, with RegionKey
+	// as the default region key.
+	RegionalServices bool
+	RegionKey        string
+}
+
 // NewCloudFlareProvider initializes a new CloudFlare DNS based Provider.
-func NewCloudFlareProvider(domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, proxiedByDefault bool, dryRun bool, dnsRecordsPerPage int) (*CloudFlareProvider, error) {
+func NewCloudFlareProvider(cfg CloudFlareConfig) (*CloudFlareProvider, error) {
 	// initialize via chosen auth method and returns new API object
 	var (
 		config *cloudflare.API
+		client cloudFlareDNS
 		err    error
 	)
 	if os.Getenv("CF_API_TOKEN") != "" {
-		token := os.Getenv("CF_API_TOKEN")
-		if strings.HasPrefix(token, "file:") {
-			tokenBytes, err := os.ReadFile(strings.TrimPrefix(token, "file:"))
-			if err != nil {
-				return nil, fmt.Errorf("failed to read CF_API_TOKEN from file: %w", err)
-			}
-			token = strings.TrimSpace(string(tokenBytes))
+		var token string
+		if token, err = readToken(os.Getenv("CF_API_TOKEN")); err != nil {
+			return nil, fmt.Errorf("failed to read CF_API_TOKEN from file: %w", err)
 		}
-		config, err = cloudflare.NewWithAPIToken(token)
-	} else {
-		config, err = cloudflare.New(os.Getenv("CF_API_KEY"), os.Getenv("CF_API_EMAIL"))
+		config, err = cloudflare.NewWithAPIToken(token, apiOptions()...)
+	} else if cfg.ZoneTokensFile == "" || os.Getenv("CF_API_KEY") != "" {
+		config, err = cloudflare.New(os.Getenv("CF_API_KEY"), os.Getenv("CF_API_EMAIL"), apiOptions()...)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize cloudflare provider: %v", err)
 	}
+	if config != nil {
+		client = zoneService{config}
+	}
+	if cfg.ZoneTokensFile != "" {
+		if client, err = newZoneTokenClientFromFile(context.Background(), client, cfg.ZoneTokensFile); err != nil {
+			return nil, fmt.Errorf("failed to initialize cloudflare provider: %w", err)
+		}
+	}
 	provider := &CloudFlareProvider{
 		// Client: config,
-		Client:            zoneService{config},
-		domainFilter:      domainFilter,
-		zoneIDFilter:      zoneIDFilter,
-		proxiedByDefault:  proxiedByDefault,
-		DryRun:            dryRun,
-		DNSRecordsPerPage: dnsRecordsPerPage,
+		Client:            client,
+		domainFilter:      cfg.DomainFilter,
+		zoneIDFilter:      cfg.ZoneIDFilter,
+		proxiedByDefault:  cfg.ProxiedByDefault,
+		DryRun:            cfg.DryRun,
+		DNSRecordsPerPage: cfg.DNSRecordsPerPage,
+
+		ExportListingThreshold: cfg.ExportListingThreshold,
+	}
+	if config != nil && os.Getenv("CF_API_TOKEN") != "" {
+		provider.tokenVerifier = config
+	}
+	if cfg.LoadBalancerAccountID != "" {
+		if config == nil {
+			return nil, fmt.Errorf("failed to initialize cloudflare provider: load balancers are managed with the CF_API_TOKEN or CF_API_KEY of the account")
+		}
+		provider.loadBalancing = config
+		provider.loadBalancerAccountID = cfg.LoadBalancerAccountID
+	}
+	if cfg.CustomHostnamesZone != "" {
+		if config == nil {
+			return nil, fmt.Errorf("failed to initialize cloudflare provider: custom hostnames are managed with the CF_API_TOKEN or CF_API_KEY of the account")
+		}
+		provider.customHostnames = config
+		provider.customHostnamesZone = strings.TrimSuffix(cfg.CustomHostnamesZone, ".")
+	}
+	if cfg.RegionalServices {
+		if config == nil {
+			return nil, fmt.Errorf("failed to initialize cloudflare provider: regional hostnames are managed with the CF_API_TOKEN or CF_API_KEY of the account")
+		}
+		provider.regionalHostnames = config
+		provider.regionKey = cfg.RegionKey
 	}
 	return provider, nil
 }
@@ -221,48 +338,57 @@ func (p *CloudFlareProvider) Zones(ctx context.Context) ([]cloudflare.Zone, erro
 	}
 
 	log.Debugln("no zoneIDFilter configured, looking at all zones")
+	return p.listZonePages(ctx)
+}
 
-	zonesResponse, err := p.Client.ListZonesContext(ctx)
+// Records returns the list of records.
+func (p *CloudFlareProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	zones, err := p.Zones(ctx)
 	if err != nil {
-		var apiErr *cloudflare.Error
-		if errors.As(err, &apiErr) {
-			if apiErr.ClientRateLimited() {
-				// Handle rate limit error as a soft error
-				return nil, provider.NewSoftError(err)
-			}
-		}
 		return nil, err
 	}
 
-	for _, zone := range zonesResponse.Result {
-		if !p.domainFilter.Match(zone.Name) {
-			log.Debugf("zone %s not in domain filter", zone.Name)
-			continue
+	var state *loadBalancingState
+	if p.loadBalancing != nil {
+		if state, err = p.listLoadBalancingState(ctx); err != nil {
+			return nil, err
 		}
-		result = append(result, zone)
 	}
 
-	return result, nil
-}
-
-// Records returns the list of records.
-func (p *CloudFlareProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
-	zones, err := p.Zones(ctx)
+	zonesEndpoints, err := p.listZonesEndpoints(ctx, zones)
 	if err != nil {
 		return nil, err
 	}
 
 	endpoints := []*endpoint.Endpoint{}
-	for _, zone := range zones {
-		records, err := p.listDNSRecordsWithAutoPagination(ctx, zone.ID)
-		if err != nil {
-			return nil, err
+	for i, zone := range zones {
+		zoneEndpoints := zonesEndpoints[i]
+		if p.customHostnames != nil && zone.Name == p.customHostnamesZone {
+			for _, ep := range zoneEndpoints {
+				p.restoreCustomHostnameRegistryRecord(ep)
+			}
+			customHostnames, err := p.customHostnameEndpoints(ctx, zone.ID)
+			if err != nil {
+				return nil, err
+			}
+			zoneEndpoints = append(zoneEndpoints, customHostnames...)
 		}
 
-		// As CloudFlare does not support "sets" of targets, but instead returns
-		// a single entry for each name/type/target, we have to group by name
-		// and record to allow the planner to calculate the correct plan. See #992.
-		endpoints = append(endpoints, groupByNameAndType(records)...)
+		if state != nil {
+			loadBalanced, err := p.loadBalancerEndpoints(ctx, zone.ID, state)
+			if err != nil {
+				return nil, err
+			}
+			zoneEndpoints = append(zoneEndpoints, loadBalanced...)
+		}
+		if p.regionalHostnames != nil {
+			keys, err := p.listRegionalHostnames(ctx, zone.ID)
+			if err != nil {
+				return nil, err
+			}
+			setRegionKeys(zoneEndpoints, keys)
+		}
+		endpoints = append(endpoints, zoneEndpoints...)
 	}
 
 	return endpoints, nil
@@ -270,6 +396,16 @@ func (p *CloudFlareProvider) Records(ctx context.Context) ([]*endpoint.Endpoint,
 
 // ApplyChanges applies a given set of changes in a given zone.
 func (p *CloudFlareProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	var customHostnameChanges, loadBalancerChanges, regionalChanges *plan.Changes
+	if p.regionalHostnames != nil {
+		regionalChanges = changes
+	}
+	if p.customHostnames != nil {
+		changes, customHostnameChanges = p.splitCustomHostnameChanges(changes)
+	}
+	if p.loadBalancing != nil {
+		changes, loadBalancerChanges = splitLoadBalancerChanges(changes)
+	}
 	cloudflareChanges := []*cloudFlareChange{}
 
 	for _, endpoint := range changes.Create {
@@ -291,8 +427,13 @@ func (p *CloudFlareProvider) ApplyChanges(ctx context.Context, changes *plan.Cha
 			cloudflareChanges = append(cloudflareChanges, p.newCloudFlareChange(cloudFlareCreate, desired, a))
 		}
 
+		// the comment set from the record-comment annotation is removed with the annotation
+		_, commented := desired.GetProviderSpecificProperty(endpoint.ProviderSpecificRecordComment)
+		_, wasCommented := current.GetProviderSpecificProperty(endpoint.ProviderSpecificRecordComment)
 		for _, a := range leave {
-			cloudflareChanges = append(cloudflareChanges, p.newCloudFlareChange(cloudFlareUpdate, desired, a))
+			change := p.newCloudFlareChange(cloudFlareUpdate, desired, a)
+			change.clearComment = !commented && wasCommented
+			cloudflareChanges = append(cloudflareChanges, change)
 		}
 	}
 
@@ -302,7 +443,23 @@ func (p *CloudFlareProvider) ApplyChanges(ctx context.Context, changes *plan.Cha
 		}
 	}
 
-	return p.submitChanges(ctx, cloudflareChanges)
+	if err := p.submitChanges(ctx, cloudflareChanges); err != nil {
+		return err
+	}
+	if customHostnameChanges != nil {
+		if err := p.submitCustomHostnameChanges(ctx, customHostnameChanges); err != nil {
+			return err
+		}
+	}
+	if loadBalancerChanges != nil {
+		if err := p.submitLoadBalancerChanges(ctx, loadBalancerChanges); err != nil {
+			return err
+		}
+	}
+	if regionalChanges != nil {
+		return p.submitRegionalHostnameChanges(ctx, regionalChanges)
+	}
+	return nil
 }
 
 // submitChanges takes a zone and a collection of Changes and sends them as a single transaction.
@@ -322,7 +479,7 @@ func (p *CloudFlareProvider) submitChanges(ctx context.Context, changes []*cloud
 
 	var failedZones []string
 	for zoneID, changes := range changesByZone {
-		records, err := p.listDNSRecordsWithAutoPagination(ctx, zoneID)
+		records, err := p.listChangedRecords(ctx, zoneID, changes)
 		if err != nil {
 			return fmt.Errorf("could not fetch records from zone, %v", err)
 		}
@@ -399,12 +556,32 @@ func (p *CloudFlareProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]
 			e.RecordTTL = 0
 		}
 		e.SetProviderSpecificProperty(source.CloudflareProxiedKey, strconv.FormatBool(proxied))
+		p.adjustLoadBalancer(e)
+		p.adjustCustomHostname(e)
+		p.adjustRegionalHostname(e)
 
 		adjustedEndpoints = append(adjustedEndpoints, e)
 	}
 	return adjustedEndpoints, nil
 }
 
+// PropertyValuesEqual compares the proxied property as a boolean, missing values defaulting to --cloudflare-proxied,
+// and other provider-specific properties verbatim.
+func (p *CloudFlareProvider) PropertyValuesEqual(name, desired, current string) bool {
+	if name != source.CloudflareProxiedKey {
+		return desired == current
+	}
+	return p.parseProxied(desired) == p.parseProxied(current)
+}
+
+func (p *CloudFlareProvider) parseProxied(value string) bool {
+	proxied, err := strconv.ParseBool(value)
+	if err != nil {
+		return p.proxiedByDefault
+	}
+	return proxied
+}
+
 // changesByZone separates a multi-zone change into a single change per zone.
 func (p *CloudFlareProvider) changesByZone(zones []cloudflare.Zone, changeSet []*cloudFlareChange) map[string][]*cloudFlareChange {
 	changes := make(map[string][]*cloudFlareChange)
@@ -436,42 +613,39 @@ func (p *CloudFlareProvider) getRecordID(records []cloudflare.DNSRecord, record
 	return ""
 }
 
-func (p *CloudFlareProvider) newCloudFlareChange(action string, endpoint *endpoint.Endpoint, target string) *cloudFlareChange {
+func (p *CloudFlareProvider) newCloudFlareChange(action string, ep *endpoint.Endpoint, target string) *cloudFlareChange {
 	ttl := defaultCloudFlareRecordTTL
-	proxied := shouldBeProxied(endpoint, p.proxiedByDefault)
+	proxied := shouldBeProxied(ep, p.proxiedByDefault)
 
-	if endpoint.RecordTTL.IsConfigured() {
-		ttl = int(endpoint.RecordTTL)
+	if ep.RecordTTL.IsConfigured() {
+		ttl = int(ep.RecordTTL)
 	}
+	comment, _ := ep.GetProviderSpecificProperty(endpoint.ProviderSpecificRecordComment)
 
 	return &cloudFlareChange{
 		Action: action,
 		ResourceRecord: cloudflare.DNSRecord{
-			Name:    endpoint.DNSName,
+			Name:    ep.DNSName,
 			TTL:     ttl,
 			Proxied: &proxied,
-			Type:    endpoint.RecordType,
+			Type:    ep.RecordType,
 			Content: target,
+			Comment: comment,
 		},
 	}
 }
 
-// listDNSRecords performs automatic pagination of results on requests to cloudflare.ListDNSRecords with custom per_page values
-func (p *CloudFlareProvider) listDNSRecordsWithAutoPagination(ctx context.Context, zoneID string) ([]cloudflare.DNSRecord, error) {
+// listDNSRecords performs automatic pagination of results on requests to cloudflare.ListDNSRecords with custom per_page values,
+// starting with the page of params if set.
+func (p *CloudFlareProvider) listDNSRecordsWithAutoPagination(ctx context.Context, zoneID string, params cloudflare.ListDNSRecordsParams) ([]cloudflare.DNSRecord, error) {
 	var records []cloudflare.DNSRecord
-	resultInfo := cloudflare.ResultInfo{PerPage: p.DNSRecordsPerPage, Page: 1}
-	params := cloudflare.ListDNSRecordsParams{ResultInfo: resultInfo}
+	if params.Page == 0 {
+		params.ResultInfo = cloudflare.ResultInfo{PerPage: p.DNSRecordsPerPage, Page: 1}
+	}
 	for {
 		pageRecords, resultInfo, err := p.Client.ListDNSRecords(ctx, cloudflare.ZoneIdentifier(zoneID), params)
 		if err != nil {
-			var apiErr *cloudflare.Error
-			if errors.As(err, &apiErr) {
-				if apiErr.ClientRateLimited() {
-					// Handle rate limit error as a soft error
-					return nil, provider.NewSoftError(err)
-				}
-			}
-			return nil, err
+			return nil, softRateLimitError(err)
 		}
 
 		records = append(records, pageRecords...)
@@ -483,6 +657,15 @@ func (p *CloudFlareProvider) listDNSRecordsWithAutoPagination(ctx context.Contex
 	return records, nil
 }
 
+// softRateLimitError handles rate limit errors as soft errors.
+func softRateLimitError(err error) error {
+	var apiErr *cloudflare.Error
+	if errors.As(err, &apiErr) && apiErr.ClientRateLimited() {
+		return provider.NewSoftError(err)
+	}
+	return err
+}
+
 func shouldBeProxied(endpoint *endpoint.Endpoint, proxiedByDefault bool) bool {
 	proxied := proxiedByDefault
 
@@ -529,14 +712,17 @@ func groupByNameAndType(records []cloudflare.DNSRecord) []*endpoint.Endpoint {
 		for i, record := range records {
 			targets[i] = record.Content
 		}
-		endpoints = append(endpoints,
-			endpoint.NewEndpointWithTTL(
-				records[0].Name,
-				records[0].Type,
-				endpoint.TTL(records[0].TTL),
-				targets...).
-				WithProviderSpecific(source.CloudflareProxiedKey, strconv.FormatBool(*records[0].Proxied)),
-		)
+		ep := endpoint.NewEndpointWithTTL(
+			records[0].Name,
+			records[0].Type,
+			endpoint.TTL(records[0].TTL),
+			targets...).
+			WithProviderSpecific(source.CloudflareProxiedKey, strconv.FormatBool(*records[0].Proxied))
+		// only the comments set by ExternalDNS are compared, the others are left to the DNS admins
+		if endpoint.IsRecordComment(records[0].Comment) {
+			ep.WithProviderSpecific(endpoint.ProviderSpecificRecordComment, records[0].Comment)
+		}
+		endpoints = append(endpoints, ep)
 	}
 
 	return endpoints
@@ -22,16 +22,20 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 
 	cloudflare "github.com/cloudflare/cloudflare-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/maxatome/go-testdeep/td"
 	"sigs.k8s.io/external-dns/endpoint"
 	"sigs.k8s.io/external-dns/plan"
 	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/source"
 )
 
 type MockAction struct {
@@ -49,6 +53,11 @@ type mockCloudFlareClient struct {
 	listZonesError        error
 	listZonesContextError error
 	dnsRecordsError       error
+	listRequests          []cloudflare.ListDNSRecordsParams
+	exportRequests        int
+	zonePageRequests      int
+	// mu guards the requests counted by the listing calls, which the provider makes concurrently
+	mu sync.Mutex
 }
 
 var ExampleDomain = []cloudflare.DNSRecord{
@@ -115,15 +124,20 @@ func getDNSRecordFromRecordParams(rp any) cloudflare.DNSRecord {
 			Proxied: params.Proxied,
 			Type:    params.Type,
 			Content: params.Content,
+			Comment: params.Comment,
 		}
 	case cloudflare.UpdateDNSRecordParams:
-		return cloudflare.DNSRecord{
+		record := cloudflare.DNSRecord{
 			Name:    params.Name,
 			TTL:     params.TTL,
 			Proxied: params.Proxied,
 			Type:    params.Type,
 			Content: params.Content,
 		}
+		if params.Comment != nil {
+			record.Comment = *params.Comment
+		}
+		return record
 	default:
 		return cloudflare.DNSRecord{}
 	}
@@ -148,15 +162,22 @@ func (m *mockCloudFlareClient) CreateDNSRecord(ctx context.Context, rc *cloudfla
 }
 
 func (m *mockCloudFlareClient) ListDNSRecords(ctx context.Context, rc *cloudflare.ResourceContainer, rp cloudflare.ListDNSRecordsParams) ([]cloudflare.DNSRecord, *cloudflare.ResultInfo, error) {
+	m.mu.Lock()
+	m.listRequests = append(m.listRequests, rp)
+	m.mu.Unlock()
 	if m.dnsRecordsError != nil {
 		return nil, &cloudflare.ResultInfo{}, m.dnsRecordsError
 	}
 	result := []cloudflare.DNSRecord{}
 	if zone, ok := m.Records[rc.Identifier]; ok {
 		for _, record := range zone {
+			if rp.Name != "" && record.Name != rp.Name {
+				continue
+			}
 			result = append(result, record)
 		}
 	}
+	total := len(result)
 
 	if len(result) == 0 || rp.PerPage == 0 {
 		return result, &cloudflare.ResultInfo{Page: 1, TotalPages: 1, Count: 0, Total: 0}, nil
@@ -184,10 +205,31 @@ func (m *mockCloudFlareClient) ListDNSRecords(ctx context.Context, rc *cloudflar
 		Page:       rp.Page,
 		TotalPages: len(chunks),
 		Count:      len(partialResult),
-		Total:      len(result),
+		Total:      total,
 	}, nil
 }
 
+func (m *mockCloudFlareClient) ExportDNSRecords(ctx context.Context, rc *cloudflare.ResourceContainer, params cloudflare.ExportDNSRecordsParams) (string, error) {
+	m.mu.Lock()
+	m.exportRequests++
+	m.mu.Unlock()
+	if m.dnsRecordsError != nil {
+		return "", m.dnsRecordsError
+	}
+	var bind strings.Builder
+	for _, record := range m.Records[rc.Identifier] {
+		content := record.Content
+		switch record.Type {
+		case endpoint.RecordTypeCNAME, endpoint.RecordTypeNS:
+			content += "."
+		case endpoint.RecordTypeTXT:
+			content = strconv.Quote(content)
+		}
+		fmt.Fprintf(&bind, "%s.\t%d\tIN\t%s\t%s ; cf_tags=cf-proxied:%t\n", record.Name, record.TTL, record.Type, content, record.Proxied != nil && *record.Proxied)
+	}
+	return bind.String(), nil
+}
+
 func (m *mockCloudFlareClient) UpdateDNSRecord(ctx context.Context, rc *cloudflare.ResourceContainer, rp cloudflare.UpdateDNSRecordParams) error {
 	recordData := getDNSRecordFromRecordParams(rp)
 	m.Actions = append(m.Actions, MockAction{
@@ -197,7 +239,11 @@ func (m *mockCloudFlareClient) UpdateDNSRecord(ctx context.Context, rc *cloudfla
 		RecordData: recordData,
 	})
 	if zone, ok := m.Records[rc.Identifier]; ok {
-		if _, ok := zone[rp.ID]; ok {
+		if record, ok := zone[rp.ID]; ok {
+			// like the API, the comment is kept unless the update sets it
+			if rp.Comment == nil {
+				recordData.Comment = record.Comment
+			}
 			zone[rp.ID] = recordData
 		}
 	}
@@ -273,6 +319,28 @@ func (m *mockCloudFlareClient) ListZonesContext(ctx context.Context, opts ...clo
 	}, nil
 }
 
+func (m *mockCloudFlareClient) ListZonesPage(ctx context.Context, page, perPage int) ([]cloudflare.Zone, cloudflare.ResultInfo, error) {
+	if m.listZonesContextError != nil {
+		return nil, cloudflare.ResultInfo{}, m.listZonesContextError
+	}
+	m.mu.Lock()
+	m.zonePageRequests++
+	m.mu.Unlock()
+
+	ids := make([]string, 0, len(m.Zones))
+	for id := range m.Zones {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	totalPages := max((len(ids)+perPage-1)/perPage, 1)
+	ids = ids[min((page-1)*perPage, len(ids)):min(page*perPage, len(ids))]
+	result := make([]cloudflare.Zone, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, cloudflare.Zone{ID: id, Name: m.Zones[id]})
+	}
+	return result, cloudflare.ResultInfo{Page: page, PerPage: perPage, TotalPages: totalPages, Count: len(result), Total: len(m.Zones)}, nil
+}
+
 func (m *mockCloudFlareClient) ZoneDetails(ctx context.Context, zoneID string) (cloudflare.Zone, error) {
 	for id, zoneName := range m.Zones {
 		if zoneID == id {
@@ -433,6 +501,66 @@ func TestCloudflareCustomTTL(t *testing.T) {
 	)
 }
 
+func TestCloudflareRecordComment(t *testing.T) {
+	endpoints := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("txt.bar.com", endpoint.RecordTypeTXT, "heritage=external-dns").
+			WithProviderSpecific(endpoint.ProviderSpecificRecordComment, "ownership record"),
+	}
+
+	AssertActions(t, &CloudFlareProvider{}, endpoints, []MockAction{
+		{
+			Name:   "Create",
+			ZoneId: "001",
+			RecordData: cloudflare.DNSRecord{
+				Type:    endpoint.RecordTypeTXT,
+				Name:    "txt.bar.com",
+				Content: "heritage=external-dns",
+				TTL:     1,
+				Proxied: proxyDisabled,
+				Comment: "ownership record",
+			},
+		},
+	},
+		[]string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+	)
+
+	change := &cloudFlareChange{ResourceRecord: cloudflare.DNSRecord{Name: "txt.bar.com"}}
+	assert.Nil(t, getUpdateDNSRecordParam(*change).Comment, "the comment of a record without comment is kept")
+	change.ResourceRecord.Comment = "ownership record"
+	assert.Equal(t, "ownership record", *getUpdateDNSRecordParam(*change).Comment)
+	change = &cloudFlareChange{ResourceRecord: cloudflare.DNSRecord{Name: "txt.bar.com"}, clearComment: true}
+	assert.Equal(t, "", *getUpdateDNSRecordParam(*change).Comment, "the comment set by ExternalDNS is removed")
+}
+
+func TestCloudflareRecordCommentSync(t *testing.T) {
+	comment := endpoint.NewRecordComment("Shop", "service/default/shop")
+	endpoints := groupByNameAndType([]cloudflare.DNSRecord{
+		{Name: "shop.bar.com", Type: endpoint.RecordTypeA, Content: "1.2.3.4", Proxied: proxyDisabled, Comment: comment},
+		{Name: "admin.bar.com", Type: endpoint.RecordTypeA, Content: "1.2.3.5", Proxied: proxyDisabled, Comment: "set by hand"},
+	})
+	require.Len(t, endpoints, 2)
+	for _, ep := range endpoints {
+		value, ok := ep.GetProviderSpecificProperty(endpoint.ProviderSpecificRecordComment)
+		if ep.DNSName == "shop.bar.com" {
+			assert.Equal(t, comment, value)
+		} else {
+			assert.False(t, ok, "comments not set by ExternalDNS are ignored")
+		}
+	}
+
+	client := NewMockCloudFlareClientWithRecords(map[string][]cloudflare.DNSRecord{
+		"001": {{ID: "1234567890", Name: "shop.bar.com", Type: endpoint.RecordTypeA, Content: "1.2.3.4", TTL: 120, Proxied: proxyDisabled, Comment: comment}},
+	})
+	p := &CloudFlareProvider{Client: client}
+	err := p.ApplyChanges(context.Background(), &plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("shop.bar.com", endpoint.RecordTypeA, 120, "1.2.3.4").
+			WithProviderSpecific(endpoint.ProviderSpecificRecordComment, comment)},
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("shop.bar.com", endpoint.RecordTypeA, 120, "1.2.3.4")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "", client.Records["001"]["1234567890"].Comment)
+}
+
 func TestCloudflareProxiedDefault(t *testing.T) {
 	endpoints := []*endpoint.Endpoint{
 		{
@@ -699,14 +827,23 @@ func TestCloudflareRecords(t *testing.T) {
 	}
 }
 
+func TestCloudflareAPIOptions(t *testing.T) {
+	provider.SetUserAgent("ExternalDNS/v0.0.0", "prod", "")
+	defer provider.SetUserAgent("ExternalDNS", "", "")
+
+	api, err := cloudflare.NewWithAPIToken("abc123def", apiOptions()...)
+	require.NoError(t, err)
+	assert.Equal(t, "ExternalDNS/v0.0.0 (cluster=prod)", api.UserAgent)
+}
+
 func TestCloudflareProvider(t *testing.T) {
 	_ = os.Setenv("CF_API_TOKEN", "abc123def")
-	_, err := NewCloudFlareProvider(
-		endpoint.NewDomainFilter([]string{"bar.com"}),
-		provider.NewZoneIDFilter([]string{""}),
-		false,
-		true,
-		5000)
+	_, err := NewCloudFlareProvider(CloudFlareConfig{
+		DomainFilter:      endpoint.NewDomainFilter([]string{"bar.com"}),
+		ZoneIDFilter:      provider.NewZoneIDFilter([]string{""}),
+		DryRun:            true,
+		DNSRecordsPerPage: 5000,
+	})
 	if err != nil {
 		t.Errorf("should not fail, %s", err)
 	}
@@ -717,12 +854,12 @@ func TestCloudflareProvider(t *testing.T) {
 		t.Errorf("failed to write token file, %s", err)
 	}
 	_ = os.Setenv("CF_API_TOKEN", tokenFile)
-	_, err = NewCloudFlareProvider(
-		endpoint.NewDomainFilter([]string{"bar.com"}),
-		provider.NewZoneIDFilter([]string{""}),
-		false,
-		true,
-		5000)
+	_, err = NewCloudFlareProvider(CloudFlareConfig{
+		DomainFilter:      endpoint.NewDomainFilter([]string{"bar.com"}),
+		ZoneIDFilter:      provider.NewZoneIDFilter([]string{""}),
+		DryRun:            true,
+		DNSRecordsPerPage: 5000,
+	})
 	if err != nil {
 		t.Errorf("should not fail, %s", err)
 	}
@@ -730,24 +867,24 @@ func TestCloudflareProvider(t *testing.T) {
 	_ = os.Unsetenv("CF_API_TOKEN")
 	_ = os.Setenv("CF_API_KEY", "xxxxxxxxxxxxxxxxx")
 	_ = os.Setenv("CF_API_EMAIL", "test@test.com")
-	_, err = NewCloudFlareProvider(
-		endpoint.NewDomainFilter([]string{"bar.com"}),
-		provider.NewZoneIDFilter([]string{""}),
-		false,
-		true,
-		5000)
+	_, err = NewCloudFlareProvider(CloudFlareConfig{
+		DomainFilter:      endpoint.NewDomainFilter([]string{"bar.com"}),
+		ZoneIDFilter:      provider.NewZoneIDFilter([]string{""}),
+		DryRun:            true,
+		DNSRecordsPerPage: 5000,
+	})
 	if err != nil {
 		t.Errorf("should not fail, %s", err)
 	}
 
 	_ = os.Unsetenv("CF_API_KEY")
 	_ = os.Unsetenv("CF_API_EMAIL")
-	_, err = NewCloudFlareProvider(
-		endpoint.NewDomainFilter([]string{"bar.com"}),
-		provider.NewZoneIDFilter([]string{""}),
-		false,
-		true,
-		5000)
+	_, err = NewCloudFlareProvider(CloudFlareConfig{
+		DomainFilter:      endpoint.NewDomainFilter([]string{"bar.com"}),
+		ZoneIDFilter:      provider.NewZoneIDFilter([]string{""}),
+		DryRun:            true,
+		DNSRecordsPerPage: 5000,
+	})
 	if err == nil {
 		t.Errorf("expected to fail")
 	}
@@ -1221,6 +1358,31 @@ func TestProviderPropertiesIdempotency(t *testing.T) {
 	}
 }
 
+func TestCloudFlarePropertyValuesEqual(t *testing.T) {
+	for _, test := range []struct {
+		proxiedByDefault bool
+		name             string
+		desired          string
+		current          string
+		equal            bool
+	}{
+		{name: source.CloudflareProxiedKey, desired: "false", current: "false", equal: true},
+		{name: source.CloudflareProxiedKey, desired: "", current: "false", equal: true},
+		{name: source.CloudflareProxiedKey, desired: "FALSE", current: "false", equal: true},
+		{name: source.CloudflareProxiedKey, desired: "", current: "true"},
+		{name: source.CloudflareProxiedKey, desired: "true", current: "false"},
+		{proxiedByDefault: true, name: source.CloudflareProxiedKey, desired: "", current: "true", equal: true},
+		{proxiedByDefault: true, name: source.CloudflareProxiedKey, desired: "false", current: "true"},
+		{name: "custom/property", desired: "", current: "false"},
+		{name: "custom/property", desired: "false", current: "false", equal: true},
+	} {
+		t.Run(fmt.Sprintf("%s %q %q", test.name, test.desired, test.current), func(t *testing.T) {
+			provider := &CloudFlareProvider{proxiedByDefault: test.proxiedByDefault}
+			assert.Equal(t, test.equal, provider.PropertyValuesEqual(test.name, test.desired, test.current))
+		})
+	}
+}
+
 func TestCloudflareComplexUpdate(t *testing.T) {
 	client := NewMockCloudFlareClientWithRecords(map[string][]cloudflare.DNSRecord{
 		"001": ExampleDomain,
@@ -0,0 +1,262 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	cloudflare "github.com/cloudflare/cloudflare-go"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/source"
+)
+
+// customHostnameRegistryLabel is the label under which the registry TXT records of the custom hostnames, which are
+// outside of the Cloudflare zones, are kept in the zone of the custom hostnames, e.g. the record of
+// a-shop.customer.com is a-shop.customer.com._custom-hostnames.saas.example.com.
+const customHostnameRegistryLabel = "_custom-hostnames"
+
+// cloudFlareCustomHostnames is the subset of the CloudFlare API managing the custom hostnames of a zone, also known
+// as Cloudflare for SaaS.
+type cloudFlareCustomHostnames interface {
+	CustomHostnames(ctx context.Context, zoneID string, page int, filter cloudflare.CustomHostname) ([]cloudflare.CustomHostname, cloudflare.ResultInfo, error)
+	CreateCustomHostname(ctx context.Context, zoneID string, ch cloudflare.CustomHostname) (*cloudflare.CustomHostnameResponse, error)
+	UpdateCustomHostname(ctx context.Context, zoneID string, customHostnameID string, ch cloudflare.CustomHostname) (*cloudflare.CustomHostnameResponse, error)
+	DeleteCustomHostname(ctx context.Context, zoneID string, customHostnameID string) error
+}
+
+// hasCustomHostnameProperty returns true if the endpoint is annotated for a custom hostname. The registry TXT records
+// of a custom hostname share its properties.
+func hasCustomHostnameProperty(ep *endpoint.Endpoint) bool {
+	value, ok := ep.GetProviderSpecificProperty(source.CloudflareCustomHostnameKey)
+	return ok && value == "true"
+}
+
+// isCustomHostname returns true if the endpoint is served by a custom hostname whose origin is the target of the
+// endpoint.
+func isCustomHostname(ep *endpoint.Endpoint) bool {
+	return hasCustomHostnameProperty(ep) && ep.RecordType == endpoint.RecordTypeCNAME
+}
+
+// isCustomHostnameRegistryRecord returns true if the endpoint is a registry TXT record of a custom hostname.
+func isCustomHostnameRegistryRecord(ep *endpoint.Endpoint) bool {
+	return hasCustomHostnameProperty(ep) && ep.RecordType == endpoint.RecordTypeTXT
+}
+
+// adjustCustomHostname sets the properties of the endpoints served by a custom hostname, which is always proxied and
+// has no TTL, and removes the annotation if custom hostnames are not managed or if the endpoint cannot be served
+// by a custom hostname.
+func (p *CloudFlareProvider) adjustCustomHostname(ep *endpoint.Endpoint) {
+	value, ok := ep.GetProviderSpecificProperty(source.CloudflareCustomHostnameKey)
+	switch {
+	case !ok:
+		return
+	case value != "true":
+		ep.DeleteProviderSpecificProperty(source.CloudflareCustomHostnameKey)
+		return
+	case p.customHostnames == nil:
+		log.Warnf("Serving %s with records, custom hostnames are not managed without --cloudflare-custom-hostnames-zone", ep.DNSName)
+		ep.DeleteProviderSpecificProperty(source.CloudflareCustomHostnameKey)
+		return
+	case ep.RecordType != endpoint.RecordTypeCNAME || len(ep.Targets) != 1:
+		log.Warnf("Serving %s %s with records, the origin of a custom hostname is the single target of a CNAME", ep.RecordType, ep.DNSName)
+		ep.DeleteProviderSpecificProperty(source.CloudflareCustomHostnameKey)
+		return
+	}
+	ep.RecordTTL = 0
+	ep.SetProviderSpecificProperty(source.CloudflareProxiedKey, "true")
+	deleteLoadBalancerProperties(ep)
+}
+
+// customHostnameRegistryName returns the name of the record keeping the registry TXT record in the zone of the
+// custom hostnames.
+func (p *CloudFlareProvider) customHostnameRegistryName(name string) string {
+	return strings.TrimSuffix(name, ".") + "." + customHostnameRegistryLabel + "." + p.customHostnamesZone
+}
+
+// restoreCustomHostnameRegistryRecord renames the record of the zone of the custom hostnames keeping a registry TXT
+// record of a custom hostname after the registry TXT record.
+func (p *CloudFlareProvider) restoreCustomHostnameRegistryRecord(ep *endpoint.Endpoint) {
+	name, ok := strings.CutSuffix(ep.DNSName, "."+customHostnameRegistryLabel+"."+p.customHostnamesZone)
+	if !ok || ep.RecordType != endpoint.RecordTypeTXT {
+		return
+	}
+	ep.DNSName = name
+	ep.SetProviderSpecificProperty(source.CloudflareCustomHostnameKey, "true")
+}
+
+// listCustomHostnames lists the custom hostnames of the zone.
+func (p *CloudFlareProvider) listCustomHostnames(ctx context.Context, zoneID string) ([]cloudflare.CustomHostname, error) {
+	var customHostnames []cloudflare.CustomHostname
+	for page := 1; ; page++ {
+		list, resultInfo, err := p.customHostnames.CustomHostnames(ctx, zoneID, page, cloudflare.CustomHostname{})
+		if err != nil {
+			return nil, fmt.Errorf("listing custom hostnames: %w", softRateLimitError(err))
+		}
+		customHostnames = append(customHostnames, list...)
+		if page >= resultInfo.TotalPages {
+			return customHostnames, nil
+		}
+	}
+}
+
+// customHostnameEndpoints returns the endpoints of the custom hostnames of the zone with a custom origin server.
+func (p *CloudFlareProvider) customHostnameEndpoints(ctx context.Context, zoneID string) ([]*endpoint.Endpoint, error) {
+	customHostnames, err := p.listCustomHostnames(ctx, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	var endpoints []*endpoint.Endpoint
+	for _, ch := range customHostnames {
+		if ch.CustomOriginServer == "" {
+			log.Debugf("Skipping custom hostname %s without custom origin server", ch.Hostname)
+			continue
+		}
+		endpoints = append(endpoints, endpoint.NewEndpoint(ch.Hostname, endpoint.RecordTypeCNAME, ch.CustomOriginServer).
+			WithProviderSpecific(source.CloudflareProxiedKey, "true").
+			WithProviderSpecific(source.CloudflareCustomHostnameKey, "true"))
+	}
+	return endpoints, nil
+}
+
+// splitCustomHostnameChanges separates the changes of the endpoints served by custom hostnames from the changes of
+// records, and moves the registry TXT records of the custom hostnames to the zone of the custom hostnames. An update
+// of an endpoint switching between records and a custom hostname deletes the former and creates the latter.
+func (p *CloudFlareProvider) splitCustomHostnameChanges(changes *plan.Changes) (*plan.Changes, *plan.Changes) {
+	records, customHostnames := &plan.Changes{}, &plan.Changes{}
+	record := func(ep *endpoint.Endpoint) *endpoint.Endpoint {
+		if !isCustomHostnameRegistryRecord(ep) {
+			return ep
+		}
+		moved := ep.DeepCopy()
+		moved.DNSName = p.customHostnameRegistryName(ep.DNSName)
+		return moved
+	}
+	split := func(eps []*endpoint.Endpoint, recordsList, customHostnamesList *[]*endpoint.Endpoint) {
+		for _, ep := range eps {
+			if isCustomHostname(ep) {
+				*customHostnamesList = append(*customHostnamesList, ep)
+			} else {
+				*recordsList = append(*recordsList, record(ep))
+			}
+		}
+	}
+	split(changes.Create, &records.Create, &customHostnames.Create)
+	split(changes.Delete, &records.Delete, &customHostnames.Delete)
+	for i, desired := range changes.UpdateNew {
+		current := changes.UpdateOld[i]
+		switch {
+		case isCustomHostname(current) && isCustomHostname(desired):
+			customHostnames.UpdateOld = append(customHostnames.UpdateOld, current)
+			customHostnames.UpdateNew = append(customHostnames.UpdateNew, desired)
+		case isCustomHostname(current):
+			customHostnames.Delete = append(customHostnames.Delete, current)
+			records.Create = append(records.Create, record(desired))
+		case isCustomHostname(desired):
+			records.Delete = append(records.Delete, record(current))
+			customHostnames.Create = append(customHostnames.Create, desired)
+		default:
+			records.UpdateOld = append(records.UpdateOld, record(current))
+			records.UpdateNew = append(records.UpdateNew, record(desired))
+		}
+	}
+	return records, customHostnames
+}
+
+// submitCustomHostnameChanges creates, updates and deletes the custom hostnames of the zone of the custom hostnames,
+// whose origin is the target of their endpoint. The certificates of the created custom hostnames are validated over
+// HTTP once the hostnames point to the zone.
+func (p *CloudFlareProvider) submitCustomHostnameChanges(ctx context.Context, changes *plan.Changes) error {
+	upserts := slices.Concat(changes.Create, changes.UpdateNew)
+	if len(upserts) == 0 && len(changes.Delete) == 0 {
+		return nil
+	}
+	zones, err := p.Zones(ctx)
+	if err != nil {
+		return err
+	}
+	zoneID := ""
+	for _, zone := range zones {
+		if zone.Name == p.customHostnamesZone {
+			zoneID = zone.ID
+		}
+	}
+	if zoneID == "" {
+		return fmt.Errorf("the zone %s of the custom hostnames was not found", p.customHostnamesZone)
+	}
+	list, err := p.listCustomHostnames(ctx, zoneID)
+	if err != nil {
+		return err
+	}
+	current := map[string]cloudflare.CustomHostname{}
+	for _, ch := range list {
+		current[strings.ToLower(ch.Hostname)] = ch
+	}
+
+	var failed []string
+	for _, ep := range changes.Delete {
+		ch, ok := current[strings.ToLower(ep.DNSName)]
+		if !ok {
+			continue
+		}
+		log.Infof("Deleting the custom hostname %s", ep.DNSName)
+		if p.DryRun {
+			continue
+		}
+		if err := p.customHostnames.DeleteCustomHostname(ctx, zoneID, ch.ID); err != nil {
+			log.Errorf("Failed to delete the custom hostname %s: %v", ep.DNSName, err)
+			failed = append(failed, ep.DNSName)
+		}
+	}
+	for _, ep := range upserts {
+		var err error
+		origin := strings.TrimSuffix(ep.Targets[0], ".")
+		ch, exists := current[strings.ToLower(ep.DNSName)]
+		switch {
+		case exists && ch.CustomOriginServer == origin:
+			continue
+		case exists:
+			log.Infof("Updating the origin of the custom hostname %s to %s", ep.DNSName, origin)
+			if !p.DryRun {
+				_, err = p.customHostnames.UpdateCustomHostname(ctx, zoneID, ch.ID, cloudflare.CustomHostname{CustomOriginServer: origin})
+			}
+		default:
+			log.Infof("Creating the custom hostname %s with origin %s", ep.DNSName, origin)
+			if !p.DryRun {
+				_, err = p.customHostnames.CreateCustomHostname(ctx, zoneID, cloudflare.CustomHostname{
+					Hostname:           ep.DNSName,
+					CustomOriginServer: origin,
+					SSL:                &cloudflare.CustomHostnameSSL{Method: "http", Type: "dv"},
+				})
+			}
+		}
+		if err != nil {
+			log.Errorf("Failed to create or update the custom hostname %s: %v", ep.DNSName, err)
+			failed = append(failed, ep.DNSName)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to submit all changes for the following custom hostnames: %v", failed)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	cloudflare "github.com/cloudflare/cloudflare-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/source"
+)
+
+// fakeCustomHostnames keeps the custom hostnames by zone, listed one per page.
+type fakeCustomHostnames struct {
+	customHostnames map[string][]cloudflare.CustomHostname
+	ids             int
+}
+
+func (f *fakeCustomHostnames) CustomHostnames(_ context.Context, zoneID string, page int, _ cloudflare.CustomHostname) ([]cloudflare.CustomHostname, cloudflare.ResultInfo, error) {
+	list := f.customHostnames[zoneID]
+	info := cloudflare.ResultInfo{Page: page, TotalPages: len(list)}
+	if page > len(list) {
+		return nil, info, nil
+	}
+	return list[page-1 : page], info, nil
+}
+
+func (f *fakeCustomHostnames) CreateCustomHostname(_ context.Context, zoneID string, ch cloudflare.CustomHostname) (*cloudflare.CustomHostnameResponse, error) {
+	f.ids++
+	ch.ID = fmt.Sprintf("id-%d", f.ids)
+	f.customHostnames[zoneID] = append(f.customHostnames[zoneID], ch)
+	return &cloudflare.CustomHostnameResponse{Result: ch}, nil
+}
+
+func (f *fakeCustomHostnames) UpdateCustomHostname(_ context.Context, zoneID string, customHostnameID string, ch cloudflare.CustomHostname) (*cloudflare.CustomHostnameResponse, error) {
+	for i, current := range f.customHostnames[zoneID] {
+		if current.ID == customHostnameID {
+			f.customHostnames[zoneID][i].CustomOriginServer = ch.CustomOriginServer
+			return &cloudflare.CustomHostnameResponse{Result: f.customHostnames[zoneID][i]}, nil
+		}
+	}
+	return nil, fmt.Errorf("custom hostname %s not found", customHostnameID)
+}
+
+func (f *fakeCustomHostnames) DeleteCustomHostname(_ context.Context, zoneID string, customHostnameID string) error {
+	f.customHostnames[zoneID] = slices.DeleteFunc(f.customHostnames[zoneID], func(ch cloudflare.CustomHostname) bool {
+		return ch.ID == customHostnameID
+	})
+	return nil
+}
+
+func TestAdjustCustomHostname(t *testing.T) {
+	customHostname := func(recordType string, targets ...string) *endpoint.Endpoint {
+		return endpoint.NewEndpointWithTTL("shop.customer.org", recordType, 300, targets...).
+			WithProviderSpecific(source.CloudflareCustomHostnameKey, "true")
+	}
+
+	p := &CloudFlareProvider{customHostnames: &fakeCustomHostnames{}, customHostnamesZone: "bar.com"}
+	adjusted, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		customHostname(endpoint.RecordTypeCNAME, "origin.bar.com").WithProviderSpecific(source.CloudflareLoadBalancerKey, "true"),
+		customHostname(endpoint.RecordTypeA, "1.2.3.4"),
+		customHostname(endpoint.RecordTypeCNAME, "origin.bar.com").WithProviderSpecific(source.CloudflareCustomHostnameKey, "false"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, endpoint.ProviderSpecific{
+		{Name: source.CloudflareCustomHostnameKey, Value: "true"},
+		{Name: source.CloudflareProxiedKey, Value: "true"},
+	}, adjusted[0].ProviderSpecific)
+	assert.Equal(t, endpoint.TTL(0), adjusted[0].RecordTTL)
+	// only the single target of a CNAME can be the origin of a custom hostname
+	assert.False(t, hasCustomHostnameProperty(adjusted[1]))
+	assert.False(t, hasCustomHostnameProperty(adjusted[2]))
+
+	// custom hostnames are not managed without their zone
+	adjusted, err = (&CloudFlareProvider{}).AdjustEndpoints([]*endpoint.Endpoint{customHostname(endpoint.RecordTypeCNAME, "origin.bar.com")})
+	require.NoError(t, err)
+	assert.False(t, hasCustomHostnameProperty(adjusted[0]))
+}
+
+func TestCloudflareCustomHostnameLifecycle(t *testing.T) {
+	client := NewMockCloudFlareClient()
+	customHostnames := &fakeCustomHostnames{customHostnames: map[string][]cloudflare.CustomHostname{}}
+	p := &CloudFlareProvider{Client: client, customHostnames: customHostnames, customHostnamesZone: "bar.com"}
+	ctx := context.Background()
+
+	desired := endpoint.NewEndpoint("shop.customer.org", endpoint.RecordTypeCNAME, "origin.bar.com").
+		WithProviderSpecific(source.CloudflareCustomHostnameKey, "true")
+	adjusted, err := p.AdjustEndpoints([]*endpoint.Endpoint{desired})
+	require.NoError(t, err)
+	// the registry TXT record of the custom hostname shares its properties
+	ownership := endpoint.NewEndpoint("cname-shop.customer.org", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=default\"")
+	ownership.ProviderSpecific = adjusted[0].ProviderSpecific
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{adjusted[0], ownership}}))
+
+	assert.Equal(t, []cloudflare.CustomHostname{{
+		ID:                 "id-1",
+		Hostname:           "shop.customer.org",
+		CustomOriginServer: "origin.bar.com",
+		SSL:                &cloudflare.CustomHostnameSSL{Method: "http", Type: "dv"},
+	}}, customHostnames.customHostnames["001"])
+	require.Len(t, client.Actions, 1)
+	assert.Equal(t, "Create", client.Actions[0].Name)
+	assert.Equal(t, "001", client.Actions[0].ZoneId)
+	assert.Equal(t, "cname-shop.customer.org._custom-hostnames.bar.com", client.Actions[0].RecordData.Name)
+	// the mock keeps the created records without ID
+	record := client.Records["001"][""]
+	record.ID = "txt-1"
+	client.Records["001"] = map[string]cloudflare.DNSRecord{record.ID: record}
+
+	// the custom hostname is listed as desired, so that no change is planned, and its registry TXT record after it
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	slices.SortFunc(records, func(a, b *endpoint.Endpoint) int { return strings.Compare(a.RecordType, b.RecordType) })
+	assert.True(t, testutils.SameEndpoint(adjusted[0], records[0]), "expected %v, got %v", adjusted[0], records[0])
+	assert.Equal(t, "cname-shop.customer.org", records[1].DNSName)
+	assert.Equal(t, ownership.Targets, records[1].Targets)
+
+	// the origin follows the target
+	updated := endpoint.NewEndpoint("shop.customer.org", endpoint.RecordTypeCNAME, "origin-eu.bar.com").
+		WithProviderSpecific(source.CloudflareCustomHostnameKey, "true")
+	adjusted, err = p.AdjustEndpoints([]*endpoint.Endpoint{updated})
+	require.NoError(t, err)
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{UpdateOld: records[:1], UpdateNew: adjusted}))
+	require.Len(t, customHostnames.customHostnames["001"], 1)
+	assert.Equal(t, "origin-eu.bar.com", customHostnames.customHostnames["001"][0].CustomOriginServer)
+
+	// the custom hostname and its registry TXT record are deleted with the endpoints
+	records, err = p.Records(ctx)
+	require.NoError(t, err)
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Delete: records}))
+	assert.Empty(t, customHostnames.customHostnames["001"])
+	assert.Empty(t, client.Records["001"])
+}
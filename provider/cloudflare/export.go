@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	cloudflare "github.com/cloudflare/cloudflare-go"
+	"github.com/miekg/dns"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const (
+	// cfProxiedTag is the tag in the comments of the zone export telling whether a record is proxied
+	cfProxiedTag = "cf-proxied:true"
+	// cfNameserverSuffix is the suffix of the Cloudflare nameservers, whose NS records the zone export
+	// contains at the apex, unlike the API
+	cfNameserverSuffix = ".ns.cloudflare.com."
+)
+
+// listZoneRecords lists the records of a zone. Zones with at least ExportListingThreshold records are
+// listed with a single request to the zone export endpoint after the first page of the API,
+// instead of one request per page.
+func (p *CloudFlareProvider) listZoneRecords(ctx context.Context, zoneID string) ([]cloudflare.DNSRecord, error) {
+	if p.ExportListingThreshold <= 0 {
+		return p.listDNSRecordsWithAutoPagination(ctx, zoneID, cloudflare.ListDNSRecordsParams{})
+	}
+
+	params := cloudflare.ListDNSRecordsParams{ResultInfo: cloudflare.ResultInfo{PerPage: p.DNSRecordsPerPage, Page: 1}}
+	records, resultInfo, err := p.Client.ListDNSRecords(ctx, cloudflare.ZoneIdentifier(zoneID), params)
+	if err != nil {
+		return nil, softRateLimitError(err)
+	}
+	p.setExportedZone(zoneID, 0)
+	next := resultInfo.Next()
+	if next.Done() {
+		return records, nil
+	}
+	if resultInfo.Total < p.ExportListingThreshold {
+		rest, err := p.listDNSRecordsWithAutoPagination(ctx, zoneID, cloudflare.ListDNSRecordsParams{ResultInfo: next})
+		if err != nil {
+			return nil, err
+		}
+		return append(records, rest...), nil
+	}
+
+	log.Debugf("Listing the %d records of zone %s with the zone export", resultInfo.Total, zoneID)
+	records, err = p.exportDNSRecords(ctx, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	p.setExportedZone(zoneID, resultInfo.Total)
+	return records, nil
+}
+
+// setExportedZone records the record count of a zone listed with the zone export, or that the zone is not
+// exported if zero.
+func (p *CloudFlareProvider) setExportedZone(zoneID string, total int) {
+	p.exportedZonesMu.Lock()
+	defer p.exportedZonesMu.Unlock()
+	if total == 0 {
+		delete(p.exportedZones, zoneID)
+		return
+	}
+	if p.exportedZones == nil {
+		p.exportedZones = map[string]int{}
+	}
+	p.exportedZones[zoneID] = total
+}
+
+// listChangedRecords lists the records the updates and deletions of a zone refer to. The records of zones listed
+// with the zone export are looked up by the names of the changes, unless this takes more requests
+// than listing all records.
+func (p *CloudFlareProvider) listChangedRecords(ctx context.Context, zoneID string, changes []*cloudFlareChange) ([]cloudflare.DNSRecord, error) {
+	var names []string
+	seen := map[string]bool{}
+	for _, change := range changes {
+		if change.Action == cloudFlareCreate || seen[change.ResourceRecord.Name] {
+			continue
+		}
+		seen[change.ResourceRecord.Name] = true
+		names = append(names, change.ResourceRecord.Name)
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	p.exportedZonesMu.Lock()
+	total, exported := p.exportedZones[zoneID]
+	p.exportedZonesMu.Unlock()
+	if !exported || p.DNSRecordsPerPage > 0 && len(names) >= (total+p.DNSRecordsPerPage-1)/p.DNSRecordsPerPage {
+		return p.listDNSRecordsWithAutoPagination(ctx, zoneID, cloudflare.ListDNSRecordsParams{})
+	}
+
+	var records []cloudflare.DNSRecord
+	for _, name := range names {
+		named, err := p.listDNSRecordsWithAutoPagination(ctx, zoneID, cloudflare.ListDNSRecordsParams{Name: name})
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, named...)
+	}
+	return records, nil
+}
+
+// exportDNSRecords lists the records of a zone with the zone export endpoint, which returns them in BIND format
+// without their IDs.
+func (p *CloudFlareProvider) exportDNSRecords(ctx context.Context, zoneID string) ([]cloudflare.DNSRecord, error) {
+	bind, err := p.Client.ExportDNSRecords(ctx, cloudflare.ZoneIdentifier(zoneID), cloudflare.ExportDNSRecordsParams{})
+	if err != nil {
+		return nil, softRateLimitError(err)
+	}
+	records, err := parseZoneExport(bind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the export of zone %s: %w", zoneID, err)
+	}
+	return records, nil
+}
+
+// parseZoneExport converts the records of a zone export to the form returned by the API.
+// Records of types not supported by ExternalDNS are skipped.
+func parseZoneExport(bind string) ([]cloudflare.DNSRecord, error) {
+	var records []cloudflare.DNSRecord
+	zp := dns.NewZoneParser(strings.NewReader(bind), "", "")
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		record := cloudflare.DNSRecord{
+			Name:    strings.TrimSuffix(rr.Header().Name, "."),
+			Type:    dns.TypeToString[rr.Header().Rrtype],
+			TTL:     int(rr.Header().Ttl),
+			Proxied: boolPtr(strings.Contains(zp.Comment(), cfProxiedTag)),
+		}
+		switch rr := rr.(type) {
+		case *dns.A:
+			record.Content = rr.A.String()
+		case *dns.AAAA:
+			record.Content = rr.AAAA.String()
+		case *dns.CNAME:
+			record.Content = strings.TrimSuffix(rr.Target, ".")
+		case *dns.NS:
+			if strings.HasSuffix(rr.Ns, cfNameserverSuffix) {
+				continue
+			}
+			record.Content = strings.TrimSuffix(rr.Ns, ".")
+		case *dns.TXT:
+			record.Content = strings.Join(rr.Txt, "")
+		case *dns.SRV:
+			record.Content = endpoint.SRVTarget{
+				Priority: rr.Priority,
+				Weight:   rr.Weight,
+				Port:     rr.Port,
+				Host:     strings.TrimSuffix(rr.Target, "."),
+			}.String()
+		default:
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := zp.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
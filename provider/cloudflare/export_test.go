@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	cloudflare "github.com/cloudflare/cloudflare-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/source"
+)
+
+func TestParseZoneExport(t *testing.T) {
+	bind := `;;
+;; Domain:     bar.com.
+;; Exported:   2024-05-01 10:00:00
+;;
+;; SOA Record
+bar.com.	3600	IN	SOA	ns1.ns.cloudflare.com. dns.cloudflare.com. 2046870183 10000 2400 604800 3600
+
+;; NS Records
+bar.com.	86400	IN	NS	ns1.ns.cloudflare.com.
+sub.bar.com.	3600	IN	NS	ns1.example.org.
+
+;; A Records
+foo.bar.com.	1	IN	A	1.2.3.4 ; cf_tags=cf-proxied:true
+foo.bar.com.	1	IN	A	2.3.4.5 ; cf_tags=cf-proxied:true
+direct.bar.com.	120	IN	A	3.4.5.6 ; cf_tags=cf-proxied:false
+
+;; CNAME Records
+www.bar.com.	1	IN	CNAME	foo.bar.com. ; cf_tags=cf-proxied:true
+
+;; SRV Records
+_sip._tcp.bar.com.	300	IN	SRV	10 5 5060 sip.bar.com.
+
+;; TXT Records
+foo.bar.com.	1	IN	TXT	"heritage=external-dns,external-dns/owner=default"
+`
+	records, err := parseZoneExport(bind)
+	require.NoError(t, err)
+	assert.Equal(t, []cloudflare.DNSRecord{
+		{Name: "sub.bar.com", Type: "NS", TTL: 3600, Content: "ns1.example.org", Proxied: proxyDisabled},
+		{Name: "foo.bar.com", Type: "A", TTL: 1, Content: "1.2.3.4", Proxied: proxyEnabled},
+		{Name: "foo.bar.com", Type: "A", TTL: 1, Content: "2.3.4.5", Proxied: proxyEnabled},
+		{Name: "direct.bar.com", Type: "A", TTL: 120, Content: "3.4.5.6", Proxied: proxyDisabled},
+		{Name: "www.bar.com", Type: "CNAME", TTL: 1, Content: "foo.bar.com", Proxied: proxyEnabled},
+		{Name: "_sip._tcp.bar.com", Type: "SRV", TTL: 300, Content: "10 5 5060 sip.bar.com", Proxied: proxyDisabled},
+		{Name: "foo.bar.com", Type: "TXT", TTL: 1, Content: "heritage=external-dns,external-dns/owner=default", Proxied: proxyDisabled},
+	}, records)
+
+	_, err = parseZoneExport("foo.bar.com.\t1\tIN\tA\tnot-an-ip\n")
+	assert.Error(t, err)
+}
+
+func largeZoneClient(records int) *mockCloudFlareClient {
+	client := NewMockCloudFlareClient()
+	for i := 0; i < records; i++ {
+		id := fmt.Sprintf("%04d", i)
+		client.Records["001"][id] = cloudflare.DNSRecord{
+			ID:      id,
+			Name:    fmt.Sprintf("host-%s.bar.com", id),
+			Type:    endpoint.RecordTypeA,
+			TTL:     120,
+			Content: "1.2.3.4",
+			Proxied: proxyDisabled,
+		}
+	}
+	return client
+}
+
+func TestCloudflareExportListing(t *testing.T) {
+	client := largeZoneClient(50)
+	provider := &CloudFlareProvider{
+		Client:                 client,
+		DNSRecordsPerPage:      10,
+		ExportListingThreshold: 20,
+	}
+
+	records, err := provider.Records(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, records, 50)
+	assert.Equal(t, 1, client.exportRequests)
+	// the first page of each zone tells the number of records
+	assert.Len(t, client.listRequests, 2)
+
+	// the records to change are looked up by name
+	client.listRequests = nil
+	require.NoError(t, provider.ApplyChanges(context.Background(), &plan.Changes{
+		Delete: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("host-0001.bar.com", endpoint.RecordTypeA, 120, "1.2.3.4").WithProviderSpecific(source.CloudflareProxiedKey, "false"),
+		},
+	}))
+	require.Len(t, client.listRequests, 1)
+	assert.Equal(t, "host-0001.bar.com", client.listRequests[0].Name)
+	assert.Equal(t, []MockAction{{Name: "Delete", ZoneId: "001", RecordId: "0001"}}, client.Actions)
+	assert.NotContains(t, client.Records["001"], "0001")
+
+	// all records are listed if that takes fewer requests
+	client.listRequests = nil
+	var deletes []*endpoint.Endpoint
+	for i := 2; i < 12; i++ {
+		deletes = append(deletes, endpoint.NewEndpointWithTTL(fmt.Sprintf("host-%04d.bar.com", i), endpoint.RecordTypeA, 120, "1.2.3.4"))
+	}
+	require.NoError(t, provider.ApplyChanges(context.Background(), &plan.Changes{Delete: deletes}))
+	assert.Len(t, client.listRequests, 5)
+	for _, request := range client.listRequests {
+		assert.Empty(t, request.Name)
+	}
+}
+
+func TestCloudflareExportListingBelowThreshold(t *testing.T) {
+	client := largeZoneClient(15)
+	provider := &CloudFlareProvider{
+		Client:                 client,
+		DNSRecordsPerPage:      10,
+		ExportListingThreshold: 20,
+	}
+
+	records, err := provider.Records(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, records, 15)
+	assert.Equal(t, 0, client.exportRequests)
+	// two pages of zone 001 and one page of zone 002
+	assert.Len(t, client.listRequests, 3)
+}
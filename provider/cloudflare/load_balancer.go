@@ -0,0 +1,588 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	cloudflare "github.com/cloudflare/cloudflare-go"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/source"
+)
+
+const (
+	// loadBalancerPoolPrefix prefixes the names of the origin pools managed by ExternalDNS, which are followed by
+	// the hostname and the record type of the endpoint, e.g. external-dns-app-example-com-a.
+	loadBalancerPoolPrefix = "external-dns-"
+	// loadBalancerDescription describes the load balancers, pools and monitors managed by ExternalDNS.
+	loadBalancerDescription = "Managed by ExternalDNS"
+)
+
+// loadBalancerRecordTypes are the record types whose targets can be the origins of a load balancer.
+var loadBalancerRecordTypes = []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME}
+
+// loadBalancerSteeringPolicies are the supported steering policies of the load balancers.
+var loadBalancerSteeringPolicies = []string{"off", "random", "geo", "dynamic_latency", "proximity", "least_outstanding_requests", "least_connections"}
+
+// cloudFlareLoadBalancing is the subset of the CloudFlare API managing load balancers. The load balancers belong
+// to zones, and their origin pools and monitors to the account.
+type cloudFlareLoadBalancing interface {
+	ListLoadBalancers(ctx context.Context, rc *cloudflare.ResourceContainer, params cloudflare.ListLoadBalancerParams) ([]cloudflare.LoadBalancer, error)
+	CreateLoadBalancer(ctx context.Context, rc *cloudflare.ResourceContainer, params cloudflare.CreateLoadBalancerParams) (cloudflare.LoadBalancer, error)
+	UpdateLoadBalancer(ctx context.Context, rc *cloudflare.ResourceContainer, params cloudflare.UpdateLoadBalancerParams) (cloudflare.LoadBalancer, error)
+	DeleteLoadBalancer(ctx context.Context, rc *cloudflare.ResourceContainer, loadbalancerID string) error
+	ListLoadBalancerPools(ctx context.Context, rc *cloudflare.ResourceContainer, params cloudflare.ListLoadBalancerPoolParams) ([]cloudflare.LoadBalancerPool, error)
+	CreateLoadBalancerPool(ctx context.Context, rc *cloudflare.ResourceContainer, params cloudflare.CreateLoadBalancerPoolParams) (cloudflare.LoadBalancerPool, error)
+	UpdateLoadBalancerPool(ctx context.Context, rc *cloudflare.ResourceContainer, params cloudflare.UpdateLoadBalancerPoolParams) (cloudflare.LoadBalancerPool, error)
+	DeleteLoadBalancerPool(ctx context.Context, rc *cloudflare.ResourceContainer, poolID string) error
+	ListLoadBalancerMonitors(ctx context.Context, rc *cloudflare.ResourceContainer, params cloudflare.ListLoadBalancerMonitorParams) ([]cloudflare.LoadBalancerMonitor, error)
+	CreateLoadBalancerMonitor(ctx context.Context, rc *cloudflare.ResourceContainer, params cloudflare.CreateLoadBalancerMonitorParams) (cloudflare.LoadBalancerMonitor, error)
+	UpdateLoadBalancerMonitor(ctx context.Context, rc *cloudflare.ResourceContainer, params cloudflare.UpdateLoadBalancerMonitorParams) (cloudflare.LoadBalancerMonitor, error)
+	DeleteLoadBalancerMonitor(ctx context.Context, rc *cloudflare.ResourceContainer, monitorID string) error
+}
+
+// loadBalancerSpec is the load balancer and the monitor of its origins described by the properties of an endpoint.
+type loadBalancerSpec struct {
+	steeringPolicy string
+	monitorType    string
+	monitorPath    string
+	monitorPort    uint16
+	expectedCodes  string
+}
+
+// isLoadBalanced returns true if the targets of the endpoint are served by a load balancer. The registry TXT
+// records of a load balanced hostname, which share its properties, are kept as records.
+func isLoadBalanced(ep *endpoint.Endpoint) bool {
+	value, ok := ep.GetProviderSpecificProperty(source.CloudflareLoadBalancerKey)
+	return ok && value == "true" && slices.Contains(loadBalancerRecordTypes, ep.RecordType)
+}
+
+// parseLoadBalancerSpec returns the load balancer described by the properties of the endpoint, and false if
+// the targets of the endpoint are not served by a load balancer.
+func parseLoadBalancerSpec(ep *endpoint.Endpoint) (loadBalancerSpec, bool, error) {
+	if value, ok := ep.GetProviderSpecificProperty(source.CloudflareLoadBalancerKey); !ok || value != "true" {
+		return loadBalancerSpec{}, false, nil
+	}
+	if !slices.Contains(loadBalancerRecordTypes, ep.RecordType) {
+		return loadBalancerSpec{}, false, fmt.Errorf("the targets of %s records cannot be load balanced", ep.RecordType)
+	}
+	spec := loadBalancerSpec{steeringPolicy: "off", monitorType: "http"}
+	if value, ok := ep.GetProviderSpecificProperty(source.CloudflareLoadBalancerSteeringPolicyKey); ok && value != "" {
+		if !slices.Contains(loadBalancerSteeringPolicies, value) {
+			return loadBalancerSpec{}, false, fmt.Errorf("unsupported steering policy %q, expected one of %s", value, strings.Join(loadBalancerSteeringPolicies, ", "))
+		}
+		spec.steeringPolicy = value
+	}
+	if value, ok := ep.GetProviderSpecificProperty(source.CloudflareLoadBalancerMonitorTypeKey); ok && value != "" {
+		spec.monitorType = strings.ToLower(value)
+	}
+	switch spec.monitorType {
+	case "http":
+		spec.monitorPort = 80
+	case "https":
+		spec.monitorPort = 443
+	case "tcp":
+	default:
+		return loadBalancerSpec{}, false, fmt.Errorf("unsupported monitor type %q, expected http, https or tcp", spec.monitorType)
+	}
+	if value, ok := ep.GetProviderSpecificProperty(source.CloudflareLoadBalancerMonitorPortKey); ok && value != "" {
+		port, err := strconv.ParseUint(value, 10, 16)
+		if err != nil || port == 0 {
+			return loadBalancerSpec{}, false, fmt.Errorf("invalid monitor port %q", value)
+		}
+		spec.monitorPort = uint16(port)
+	}
+	if spec.monitorType == "tcp" {
+		if spec.monitorPort == 0 {
+			return loadBalancerSpec{}, false, fmt.Errorf("the port of TCP monitors is required")
+		}
+		return spec, true, nil
+	}
+	spec.monitorPath, spec.expectedCodes = "/", "2xx"
+	if value, ok := ep.GetProviderSpecificProperty(source.CloudflareLoadBalancerMonitorPathKey); ok && value != "" {
+		spec.monitorPath = "/" + strings.TrimPrefix(value, "/")
+	}
+	if value, ok := ep.GetProviderSpecificProperty(source.CloudflareLoadBalancerExpectedCodesKey); ok && value != "" {
+		spec.expectedCodes = value
+	}
+	return spec, true, nil
+}
+
+// loadBalancerSpecFromMonitor returns the load balancer with the steering policy and the monitor.
+func loadBalancerSpecFromMonitor(steeringPolicy string, monitor cloudflare.LoadBalancerMonitor) loadBalancerSpec {
+	if steeringPolicy == "" {
+		steeringPolicy = "off"
+	}
+	return loadBalancerSpec{
+		steeringPolicy: steeringPolicy,
+		monitorType:    monitor.Type,
+		monitorPath:    monitor.Path,
+		monitorPort:    monitor.Port,
+		expectedCodes:  monitor.ExpectedCodes,
+	}
+}
+
+// setProperties sets the properties of the endpoint describing the load balancer, with their default values.
+func (s loadBalancerSpec) setProperties(ep *endpoint.Endpoint) {
+	ep.SetProviderSpecificProperty(source.CloudflareLoadBalancerKey, "true")
+	ep.SetProviderSpecificProperty(source.CloudflareLoadBalancerSteeringPolicyKey, s.steeringPolicy)
+	ep.SetProviderSpecificProperty(source.CloudflareLoadBalancerMonitorTypeKey, s.monitorType)
+	ep.SetProviderSpecificProperty(source.CloudflareLoadBalancerMonitorPortKey, strconv.Itoa(int(s.monitorPort)))
+	if s.monitorType == "tcp" {
+		ep.DeleteProviderSpecificProperty(source.CloudflareLoadBalancerMonitorPathKey)
+		ep.DeleteProviderSpecificProperty(source.CloudflareLoadBalancerExpectedCodesKey)
+		return
+	}
+	ep.SetProviderSpecificProperty(source.CloudflareLoadBalancerMonitorPathKey, s.monitorPath)
+	ep.SetProviderSpecificProperty(source.CloudflareLoadBalancerExpectedCodesKey, s.expectedCodes)
+}
+
+// monitor returns the monitor of the origins.
+func (s loadBalancerSpec) monitor(poolName string) cloudflare.LoadBalancerMonitor {
+	return cloudflare.LoadBalancerMonitor{
+		Type:          s.monitorType,
+		Description:   poolName,
+		Path:          s.monitorPath,
+		Port:          s.monitorPort,
+		ExpectedCodes: s.expectedCodes,
+	}
+}
+
+// deleteLoadBalancerProperties removes the properties describing a load balancer from the endpoint.
+func deleteLoadBalancerProperties(ep *endpoint.Endpoint) {
+	for _, key := range []string{
+		source.CloudflareLoadBalancerKey, source.CloudflareLoadBalancerSteeringPolicyKey, source.CloudflareLoadBalancerMonitorTypeKey,
+		source.CloudflareLoadBalancerMonitorPathKey, source.CloudflareLoadBalancerMonitorPortKey, source.CloudflareLoadBalancerExpectedCodesKey,
+	} {
+		ep.DeleteProviderSpecificProperty(key)
+	}
+}
+
+// adjustLoadBalancer normalizes the properties describing the load balancer of the endpoint, and removes them
+// if load balancers are not managed or if they are invalid.
+func (p *CloudFlareProvider) adjustLoadBalancer(ep *endpoint.Endpoint) {
+	spec, ok, err := parseLoadBalancerSpec(ep)
+	switch {
+	case err != nil:
+		log.Warnf("Serving the targets of %s with records instead of a load balancer: %v", ep.DNSName, err)
+		deleteLoadBalancerProperties(ep)
+	case !ok:
+		deleteLoadBalancerProperties(ep)
+	case p.loadBalancing == nil:
+		log.Warnf("Serving the targets of %s with records, load balancers are not managed without --cloudflare-load-balancer-account-id", ep.DNSName)
+		deleteLoadBalancerProperties(ep)
+	default:
+		spec.setProperties(ep)
+	}
+}
+
+// loadBalancerPoolName returns the name of the origin pool of the targets of the endpoint.
+func loadBalancerPoolName(hostname, recordType string) string {
+	name := strings.NewReplacer(".", "-", "*", "_").Replace(strings.TrimSuffix(strings.ToLower(hostname), "."))
+	return loadBalancerPoolPrefix + name + "-" + strings.ToLower(recordType)
+}
+
+// loadBalancerPoolRecordType returns the record type of the targets of the pool, or an empty string if the pool
+// is not managed by ExternalDNS.
+func loadBalancerPoolRecordType(poolName string) string {
+	if !strings.HasPrefix(poolName, loadBalancerPoolPrefix) {
+		return ""
+	}
+	suffix := poolName[strings.LastIndex(poolName, "-")+1:]
+	for _, recordType := range loadBalancerRecordTypes {
+		if strings.ToLower(recordType) == suffix {
+			return recordType
+		}
+	}
+	return ""
+}
+
+// loadBalancingState is the origin pools and monitors of the account.
+type loadBalancingState struct {
+	pools    map[string]cloudflare.LoadBalancerPool
+	monitors map[string]cloudflare.LoadBalancerMonitor
+}
+
+// pool returns the pool with the ID.
+func (s *loadBalancingState) pool(id string) (cloudflare.LoadBalancerPool, bool) {
+	for _, pool := range s.pools {
+		if pool.ID == id {
+			return pool, true
+		}
+	}
+	return cloudflare.LoadBalancerPool{}, false
+}
+
+// listLoadBalancingState lists the origin pools managed by ExternalDNS by name, and the monitors by ID.
+func (p *CloudFlareProvider) listLoadBalancingState(ctx context.Context) (*loadBalancingState, error) {
+	account := cloudflare.AccountIdentifier(p.loadBalancerAccountID)
+	pools, err := p.loadBalancing.ListLoadBalancerPools(ctx, account, cloudflare.ListLoadBalancerPoolParams{})
+	if err != nil {
+		return nil, fmt.Errorf("listing load balancer pools: %w", softRateLimitError(err))
+	}
+	monitors, err := p.loadBalancing.ListLoadBalancerMonitors(ctx, account, cloudflare.ListLoadBalancerMonitorParams{})
+	if err != nil {
+		return nil, fmt.Errorf("listing load balancer monitors: %w", softRateLimitError(err))
+	}
+	state := &loadBalancingState{pools: map[string]cloudflare.LoadBalancerPool{}, monitors: map[string]cloudflare.LoadBalancerMonitor{}}
+	for _, pool := range pools {
+		if loadBalancerPoolRecordType(pool.Name) != "" {
+			state.pools[pool.Name] = pool
+		}
+	}
+	for _, monitor := range monitors {
+		state.monitors[monitor.ID] = monitor
+	}
+	return state, nil
+}
+
+// loadBalancerEndpoints returns the endpoints of the pools managed by ExternalDNS of the load balancers of the zone.
+func (p *CloudFlareProvider) loadBalancerEndpoints(ctx context.Context, zoneID string, state *loadBalancingState) ([]*endpoint.Endpoint, error) {
+	loadBalancers, err := p.loadBalancing.ListLoadBalancers(ctx, cloudflare.ZoneIdentifier(zoneID), cloudflare.ListLoadBalancerParams{})
+	if err != nil {
+		return nil, fmt.Errorf("listing load balancers: %w", softRateLimitError(err))
+	}
+	var endpoints []*endpoint.Endpoint
+	for _, lb := range loadBalancers {
+		for _, poolID := range lb.DefaultPools {
+			pool, ok := state.pool(poolID)
+			if !ok || pool.Name != loadBalancerPoolName(lb.Name, loadBalancerPoolRecordType(pool.Name)) {
+				continue
+			}
+			targets := make([]string, 0, len(pool.Origins))
+			for _, origin := range pool.Origins {
+				targets = append(targets, origin.Address)
+			}
+			ep := endpoint.NewEndpointWithTTL(lb.Name, loadBalancerPoolRecordType(pool.Name), endpoint.TTL(lb.TTL), targets...).
+				WithProviderSpecific(source.CloudflareProxiedKey, strconv.FormatBool(lb.Proxied))
+			if lb.Proxied {
+				ep.RecordTTL = 0
+			}
+			loadBalancerSpecFromMonitor(lb.SteeringPolicy, state.monitors[pool.Monitor]).setProperties(ep)
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return endpoints, nil
+}
+
+// splitLoadBalancerChanges separates the changes of the endpoints served by load balancers from the changes of
+// records. An update of an endpoint switching between records and a load balancer deletes the former and creates
+// the latter.
+func splitLoadBalancerChanges(changes *plan.Changes) (*plan.Changes, *plan.Changes) {
+	records, loadBalancers := &plan.Changes{}, &plan.Changes{}
+	split := func(eps []*endpoint.Endpoint, recordsList, loadBalancersList *[]*endpoint.Endpoint) {
+		for _, ep := range eps {
+			if isLoadBalanced(ep) {
+				*loadBalancersList = append(*loadBalancersList, ep)
+			} else {
+				*recordsList = append(*recordsList, ep)
+			}
+		}
+	}
+	split(changes.Create, &records.Create, &loadBalancers.Create)
+	split(changes.Delete, &records.Delete, &loadBalancers.Delete)
+	for i, desired := range changes.UpdateNew {
+		current := changes.UpdateOld[i]
+		switch {
+		case isLoadBalanced(current) && isLoadBalanced(desired):
+			loadBalancers.UpdateOld = append(loadBalancers.UpdateOld, current)
+			loadBalancers.UpdateNew = append(loadBalancers.UpdateNew, desired)
+		case isLoadBalanced(current):
+			loadBalancers.Delete = append(loadBalancers.Delete, current)
+			records.Create = append(records.Create, desired)
+		case isLoadBalanced(desired):
+			records.Delete = append(records.Delete, current)
+			loadBalancers.Create = append(loadBalancers.Create, desired)
+		default:
+			records.UpdateOld = append(records.UpdateOld, current)
+			records.UpdateNew = append(records.UpdateNew, desired)
+		}
+	}
+	return records, loadBalancers
+}
+
+// submitLoadBalancerChanges creates, updates and deletes the origin pools and monitors of the endpoints, and the
+// load balancers of their hostnames referencing the pools.
+func (p *CloudFlareProvider) submitLoadBalancerChanges(ctx context.Context, changes *plan.Changes) error {
+	upserts := slices.Concat(changes.Create, changes.UpdateNew)
+	if len(upserts) == 0 && len(changes.Delete) == 0 {
+		return nil
+	}
+	zones, err := p.Zones(ctx)
+	if err != nil {
+		return err
+	}
+	zoneNames := provider.ZoneIDName{}
+	for _, zone := range zones {
+		zoneNames.Add(zone.ID, zone.Name)
+	}
+	state, err := p.listLoadBalancingState(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the pools of the hostnames to add to their load balancer, and to remove from it
+	added, removed := map[string][]string{}, map[string][]string{}
+	desired := map[string]*endpoint.Endpoint{}
+	var failed []string
+	for _, ep := range changes.Delete {
+		removed[ep.DNSName] = append(removed[ep.DNSName], loadBalancerPoolName(ep.DNSName, ep.RecordType))
+	}
+	for _, ep := range upserts {
+		poolID, err := p.ensureLoadBalancerPool(ctx, state, ep)
+		if err != nil {
+			log.Errorf("Failed to create or update the load balancer pool of %s %s: %v", ep.RecordType, ep.DNSName, err)
+			failed = append(failed, ep.DNSName)
+			continue
+		}
+		added[ep.DNSName] = append(added[ep.DNSName], poolID)
+		desired[ep.DNSName] = ep
+	}
+
+	hostnames := map[string]bool{}
+	for hostname := range added {
+		hostnames[hostname] = true
+	}
+	for hostname := range removed {
+		hostnames[hostname] = true
+	}
+	loadBalancers := map[string][]cloudflare.LoadBalancer{}
+	for hostname := range hostnames {
+		zoneID, _ := zoneNames.FindZone(hostname)
+		if zoneID == "" {
+			log.Debugf("Skipping load balancer %s because no zone matching its hostname was detected", hostname)
+			continue
+		}
+		if _, ok := loadBalancers[zoneID]; !ok {
+			list, err := p.loadBalancing.ListLoadBalancers(ctx, cloudflare.ZoneIdentifier(zoneID), cloudflare.ListLoadBalancerParams{})
+			if err != nil {
+				return fmt.Errorf("listing load balancers: %w", softRateLimitError(err))
+			}
+			loadBalancers[zoneID] = list
+		}
+		if err := p.ensureLoadBalancer(ctx, zoneID, loadBalancers[zoneID], state, hostname, desired[hostname], added[hostname], removed[hostname]); err != nil {
+			log.Errorf("Failed to change the load balancer %s: %v", hostname, err)
+			failed = append(failed, hostname)
+			continue
+		}
+		// the removed pools are no longer referenced
+		for _, name := range removed[hostname] {
+			if err := p.deleteLoadBalancerPool(ctx, state, name); err != nil {
+				log.Errorf("Failed to delete the load balancer pool %s: %v", name, err)
+				failed = append(failed, hostname)
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to submit all changes for the following load balancers: %v", failed)
+	}
+	return nil
+}
+
+// ensureLoadBalancerPool creates or updates the origin pool of the targets of the endpoint and its monitor,
+// and returns the ID of the pool.
+func (p *CloudFlareProvider) ensureLoadBalancerPool(ctx context.Context, state *loadBalancingState, ep *endpoint.Endpoint) (string, error) {
+	spec, _, err := parseLoadBalancerSpec(ep)
+	if err != nil {
+		return "", err
+	}
+	account := cloudflare.AccountIdentifier(p.loadBalancerAccountID)
+	name := loadBalancerPoolName(ep.DNSName, ep.RecordType)
+	current, exists := state.pools[name]
+
+	monitor := spec.monitor(name)
+	if currentMonitor, ok := state.monitors[current.Monitor]; exists && ok {
+		monitor.ID = currentMonitor.ID
+		if loadBalancerMonitorsEqual(currentMonitor, monitor) {
+			monitor = currentMonitor
+		} else {
+			log.Infof("Updating the load balancer monitor of %s", name)
+			if !p.DryRun {
+				if monitor, err = p.loadBalancing.UpdateLoadBalancerMonitor(ctx, account, cloudflare.UpdateLoadBalancerMonitorParams{LoadBalancerMonitor: monitor}); err != nil {
+					return "", fmt.Errorf("updating monitor: %w", err)
+				}
+			}
+		}
+	} else {
+		log.Infof("Creating the load balancer monitor of %s", name)
+		if !p.DryRun {
+			if monitor, err = p.loadBalancing.CreateLoadBalancerMonitor(ctx, account, cloudflare.CreateLoadBalancerMonitorParams{LoadBalancerMonitor: monitor}); err != nil {
+				return "", fmt.Errorf("creating monitor: %w", err)
+			}
+		}
+	}
+	state.monitors[monitor.ID] = monitor
+
+	pool := cloudflare.LoadBalancerPool{
+		ID:          current.ID,
+		Name:        name,
+		Description: loadBalancerDescription,
+		Enabled:     true,
+		Monitor:     monitor.ID,
+	}
+	for _, target := range ep.Targets {
+		pool.Origins = append(pool.Origins, cloudflare.LoadBalancerOrigin{
+			Name:    strings.NewReplacer(".", "-", ":", "-").Replace(target),
+			Address: target,
+			Enabled: true,
+			Weight:  1,
+		})
+	}
+	switch {
+	case exists && loadBalancerPoolsEqual(current, pool):
+		return current.ID, nil
+	case exists:
+		log.Infof("Updating the load balancer pool %s", name)
+		if !p.DryRun {
+			if pool, err = p.loadBalancing.UpdateLoadBalancerPool(ctx, account, cloudflare.UpdateLoadBalancerPoolParams{LoadBalancer: pool}); err != nil {
+				return "", fmt.Errorf("updating pool: %w", err)
+			}
+		}
+	default:
+		log.Infof("Creating the load balancer pool %s", name)
+		if !p.DryRun {
+			if pool, err = p.loadBalancing.CreateLoadBalancerPool(ctx, account, cloudflare.CreateLoadBalancerPoolParams{LoadBalancerPool: pool}); err != nil {
+				return "", fmt.Errorf("creating pool: %w", err)
+			}
+		}
+	}
+	state.pools[name] = pool
+	return pool.ID, nil
+}
+
+// ensureLoadBalancer adds and removes the pools of the load balancer of the hostname, which is created with the first
+// pool and deleted with the last one, and applies the settings of the desired endpoint, if any.
+func (p *CloudFlareProvider) ensureLoadBalancer(ctx context.Context, zoneID string, loadBalancers []cloudflare.LoadBalancer, state *loadBalancingState, hostname string, desired *endpoint.Endpoint, added []string, removed []string) error {
+	zone := cloudflare.ZoneIdentifier(zoneID)
+	var current *cloudflare.LoadBalancer
+	for i := range loadBalancers {
+		if strings.EqualFold(loadBalancers[i].Name, strings.TrimSuffix(hostname, ".")) {
+			current = &loadBalancers[i]
+			break
+		}
+	}
+
+	lb := cloudflare.LoadBalancer{Name: strings.TrimSuffix(hostname, "."), Description: loadBalancerDescription, SteeringPolicy: "off"}
+	if current != nil {
+		lb = *current
+	}
+	var pools []string
+	for _, poolID := range lb.DefaultPools {
+		if pool, ok := state.pool(poolID); !ok || !slices.Contains(removed, pool.Name) {
+			pools = append(pools, poolID)
+		}
+	}
+	for _, poolID := range added {
+		if !slices.Contains(pools, poolID) {
+			pools = append(pools, poolID)
+		}
+	}
+	if desired != nil {
+		spec, _, _ := parseLoadBalancerSpec(desired)
+		lb.SteeringPolicy = spec.steeringPolicy
+		lb.Proxied = shouldBeProxied(desired, p.proxiedByDefault)
+		lb.TTL = 0
+		if desired.RecordTTL.IsConfigured() && !lb.Proxied {
+			lb.TTL = int(desired.RecordTTL)
+		}
+	}
+
+	switch {
+	case len(pools) == 0 && current == nil:
+		return nil
+	case len(pools) == 0:
+		log.Infof("Deleting the load balancer %s", hostname)
+		if p.DryRun {
+			return nil
+		}
+		return p.loadBalancing.DeleteLoadBalancer(ctx, zone, current.ID)
+	case current == nil:
+		log.Infof("Creating the load balancer %s", hostname)
+		lb.DefaultPools, lb.FallbackPool = pools, pools[0]
+		if p.DryRun {
+			return nil
+		}
+		_, err := p.loadBalancing.CreateLoadBalancer(ctx, zone, cloudflare.CreateLoadBalancerParams{LoadBalancer: lb})
+		return err
+	default:
+		if !slices.Contains(pools, lb.FallbackPool) {
+			lb.FallbackPool = pools[0]
+		}
+		lb.DefaultPools = pools
+		log.Infof("Updating the load balancer %s", hostname)
+		if p.DryRun {
+			return nil
+		}
+		_, err := p.loadBalancing.UpdateLoadBalancer(ctx, zone, cloudflare.UpdateLoadBalancerParams{LoadBalancer: lb})
+		return err
+	}
+}
+
+// deleteLoadBalancerPool deletes the origin pool with the name and its monitor, if they exist.
+func (p *CloudFlareProvider) deleteLoadBalancerPool(ctx context.Context, state *loadBalancingState, name string) error {
+	pool, ok := state.pools[name]
+	if !ok {
+		return nil
+	}
+	log.Infof("Deleting the load balancer pool %s", name)
+	if p.DryRun {
+		return nil
+	}
+	account := cloudflare.AccountIdentifier(p.loadBalancerAccountID)
+	if err := p.loadBalancing.DeleteLoadBalancerPool(ctx, account, pool.ID); err != nil {
+		return err
+	}
+	delete(state.pools, name)
+	if monitor, ok := state.monitors[pool.Monitor]; ok && monitor.Description == name {
+		if err := p.loadBalancing.DeleteLoadBalancerMonitor(ctx, account, monitor.ID); err != nil {
+			return fmt.Errorf("deleting monitor: %w", err)
+		}
+		delete(state.monitors, monitor.ID)
+	}
+	return nil
+}
+
+// loadBalancerMonitorsEqual returns true if the monitors check the origins the same way.
+func loadBalancerMonitorsEqual(a, b cloudflare.LoadBalancerMonitor) bool {
+	return a.Type == b.Type && a.Path == b.Path && a.Port == b.Port && a.ExpectedCodes == b.ExpectedCodes
+}
+
+// loadBalancerPoolsEqual returns true if the pools have the same origins and monitor.
+func loadBalancerPoolsEqual(a, b cloudflare.LoadBalancerPool) bool {
+	if a.Monitor != b.Monitor || !a.Enabled || len(a.Origins) != len(b.Origins) {
+		return false
+	}
+	addresses := make([]string, 0, len(a.Origins))
+	for _, origin := range a.Origins {
+		addresses = append(addresses, origin.Address)
+	}
+	for _, origin := range b.Origins {
+		if !slices.Contains(addresses, origin.Address) {
+			return false
+		}
+	}
+	return true
+}
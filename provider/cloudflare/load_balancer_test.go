@@ -0,0 +1,436 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	cloudflare "github.com/cloudflare/cloudflare-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/source"
+)
+
+// fakeLoadBalancing keeps the load balancers by zone, and the pools and monitors of the account.
+type fakeLoadBalancing struct {
+	t             *testing.T
+	loadBalancers map[string][]cloudflare.LoadBalancer
+	pools         []cloudflare.LoadBalancerPool
+	monitors      []cloudflare.LoadBalancerMonitor
+	ids           int
+}
+
+func newFakeLoadBalancing(t *testing.T) *fakeLoadBalancing {
+	return &fakeLoadBalancing{t: t, loadBalancers: map[string][]cloudflare.LoadBalancer{}}
+}
+
+func (f *fakeLoadBalancing) nextID() string {
+	f.ids++
+	return fmt.Sprintf("id-%d", f.ids)
+}
+
+func (f *fakeLoadBalancing) account(rc *cloudflare.ResourceContainer) {
+	assert.Equal(f.t, cloudflare.AccountIdentifier("account"), rc)
+}
+
+func (f *fakeLoadBalancing) ListLoadBalancers(_ context.Context, rc *cloudflare.ResourceContainer, _ cloudflare.ListLoadBalancerParams) ([]cloudflare.LoadBalancer, error) {
+	return f.loadBalancers[rc.Identifier], nil
+}
+
+func (f *fakeLoadBalancing) CreateLoadBalancer(_ context.Context, rc *cloudflare.ResourceContainer, params cloudflare.CreateLoadBalancerParams) (cloudflare.LoadBalancer, error) {
+	lb := params.LoadBalancer
+	lb.ID = f.nextID()
+	f.loadBalancers[rc.Identifier] = append(f.loadBalancers[rc.Identifier], lb)
+	return lb, nil
+}
+
+func (f *fakeLoadBalancing) UpdateLoadBalancer(_ context.Context, rc *cloudflare.ResourceContainer, params cloudflare.UpdateLoadBalancerParams) (cloudflare.LoadBalancer, error) {
+	for i, lb := range f.loadBalancers[rc.Identifier] {
+		if lb.ID == params.LoadBalancer.ID {
+			f.loadBalancers[rc.Identifier][i] = params.LoadBalancer
+			return params.LoadBalancer, nil
+		}
+	}
+	return cloudflare.LoadBalancer{}, fmt.Errorf("load balancer %s not found", params.LoadBalancer.ID)
+}
+
+func (f *fakeLoadBalancing) DeleteLoadBalancer(_ context.Context, rc *cloudflare.ResourceContainer, id string) error {
+	for i, lb := range f.loadBalancers[rc.Identifier] {
+		if lb.ID == id {
+			f.loadBalancers[rc.Identifier] = append(f.loadBalancers[rc.Identifier][:i], f.loadBalancers[rc.Identifier][i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("load balancer %s not found", id)
+}
+
+func (f *fakeLoadBalancing) ListLoadBalancerPools(_ context.Context, rc *cloudflare.ResourceContainer, _ cloudflare.ListLoadBalancerPoolParams) ([]cloudflare.LoadBalancerPool, error) {
+	f.account(rc)
+	return f.pools, nil
+}
+
+func (f *fakeLoadBalancing) CreateLoadBalancerPool(_ context.Context, rc *cloudflare.ResourceContainer, params cloudflare.CreateLoadBalancerPoolParams) (cloudflare.LoadBalancerPool, error) {
+	f.account(rc)
+	pool := params.LoadBalancerPool
+	pool.ID = f.nextID()
+	f.pools = append(f.pools, pool)
+	return pool, nil
+}
+
+func (f *fakeLoadBalancing) UpdateLoadBalancerPool(_ context.Context, rc *cloudflare.ResourceContainer, params cloudflare.UpdateLoadBalancerPoolParams) (cloudflare.LoadBalancerPool, error) {
+	f.account(rc)
+	for i, pool := range f.pools {
+		if pool.ID == params.LoadBalancer.ID {
+			f.pools[i] = params.LoadBalancer
+			return params.LoadBalancer, nil
+		}
+	}
+	return cloudflare.LoadBalancerPool{}, fmt.Errorf("pool %s not found", params.LoadBalancer.ID)
+}
+
+func (f *fakeLoadBalancing) DeleteLoadBalancerPool(_ context.Context, rc *cloudflare.ResourceContainer, id string) error {
+	f.account(rc)
+	for i, pool := range f.pools {
+		if pool.ID == id {
+			f.pools = append(f.pools[:i], f.pools[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("pool %s not found", id)
+}
+
+func (f *fakeLoadBalancing) ListLoadBalancerMonitors(_ context.Context, rc *cloudflare.ResourceContainer, _ cloudflare.ListLoadBalancerMonitorParams) ([]cloudflare.LoadBalancerMonitor, error) {
+	f.account(rc)
+	return f.monitors, nil
+}
+
+func (f *fakeLoadBalancing) CreateLoadBalancerMonitor(_ context.Context, rc *cloudflare.ResourceContainer, params cloudflare.CreateLoadBalancerMonitorParams) (cloudflare.LoadBalancerMonitor, error) {
+	f.account(rc)
+	monitor := params.LoadBalancerMonitor
+	monitor.ID = f.nextID()
+	f.monitors = append(f.monitors, monitor)
+	return monitor, nil
+}
+
+func (f *fakeLoadBalancing) UpdateLoadBalancerMonitor(_ context.Context, rc *cloudflare.ResourceContainer, params cloudflare.UpdateLoadBalancerMonitorParams) (cloudflare.LoadBalancerMonitor, error) {
+	f.account(rc)
+	for i, monitor := range f.monitors {
+		if monitor.ID == params.LoadBalancerMonitor.ID {
+			f.monitors[i] = params.LoadBalancerMonitor
+			return params.LoadBalancerMonitor, nil
+		}
+	}
+	return cloudflare.LoadBalancerMonitor{}, fmt.Errorf("monitor %s not found", params.LoadBalancerMonitor.ID)
+}
+
+func (f *fakeLoadBalancing) DeleteLoadBalancerMonitor(_ context.Context, rc *cloudflare.ResourceContainer, id string) error {
+	f.account(rc)
+	for i, monitor := range f.monitors {
+		if monitor.ID == id {
+			f.monitors = append(f.monitors[:i], f.monitors[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("monitor %s not found", id)
+}
+
+func TestParseLoadBalancerSpec(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		recordType string
+		properties map[string]string
+		expected   loadBalancerSpec
+		ok         bool
+		err        string
+	}{
+		{
+			name:       "not load balanced",
+			recordType: endpoint.RecordTypeA,
+		},
+		{
+			name:       "defaults",
+			recordType: endpoint.RecordTypeA,
+			properties: map[string]string{source.CloudflareLoadBalancerKey: "true"},
+			expected:   loadBalancerSpec{steeringPolicy: "off", monitorType: "http", monitorPath: "/", monitorPort: 80, expectedCodes: "2xx"},
+			ok:         true,
+		},
+		{
+			name:       "https",
+			recordType: endpoint.RecordTypeCNAME,
+			properties: map[string]string{
+				source.CloudflareLoadBalancerKey:               "true",
+				source.CloudflareLoadBalancerSteeringPolicyKey: "geo",
+				source.CloudflareLoadBalancerMonitorTypeKey:    "HTTPS",
+				source.CloudflareLoadBalancerMonitorPathKey:    "healthz",
+				source.CloudflareLoadBalancerExpectedCodesKey:  "200",
+			},
+			expected: loadBalancerSpec{steeringPolicy: "geo", monitorType: "https", monitorPath: "/healthz", monitorPort: 443, expectedCodes: "200"},
+			ok:       true,
+		},
+		{
+			name:       "tcp",
+			recordType: endpoint.RecordTypeAAAA,
+			properties: map[string]string{
+				source.CloudflareLoadBalancerKey:            "true",
+				source.CloudflareLoadBalancerMonitorTypeKey: "tcp",
+				source.CloudflareLoadBalancerMonitorPathKey: "/ignored",
+				source.CloudflareLoadBalancerMonitorPortKey: "5432",
+			},
+			expected: loadBalancerSpec{steeringPolicy: "off", monitorType: "tcp", monitorPort: 5432},
+			ok:       true,
+		},
+		{
+			name:       "tcp without port",
+			recordType: endpoint.RecordTypeA,
+			properties: map[string]string{source.CloudflareLoadBalancerKey: "true", source.CloudflareLoadBalancerMonitorTypeKey: "tcp"},
+			err:        "port of TCP monitors is required",
+		},
+		{
+			name:       "invalid port",
+			recordType: endpoint.RecordTypeA,
+			properties: map[string]string{source.CloudflareLoadBalancerKey: "true", source.CloudflareLoadBalancerMonitorPortKey: "70000"},
+			err:        "invalid monitor port",
+		},
+		{
+			name:       "invalid steering policy",
+			recordType: endpoint.RecordTypeA,
+			properties: map[string]string{source.CloudflareLoadBalancerKey: "true", source.CloudflareLoadBalancerSteeringPolicyKey: "nearest"},
+			err:        "unsupported steering policy",
+		},
+		{
+			name:       "invalid monitor type",
+			recordType: endpoint.RecordTypeA,
+			properties: map[string]string{source.CloudflareLoadBalancerKey: "true", source.CloudflareLoadBalancerMonitorTypeKey: "icmp"},
+			err:        "unsupported monitor type",
+		},
+		{
+			name:       "unsupported record type",
+			recordType: endpoint.RecordTypeTXT,
+			properties: map[string]string{source.CloudflareLoadBalancerKey: "true"},
+			err:        "cannot be load balanced",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ep := endpoint.NewEndpoint("app.bar.com", tc.recordType, "1.2.3.4")
+			for name, value := range tc.properties {
+				ep.SetProviderSpecificProperty(name, value)
+			}
+			spec, ok, err := parseLoadBalancerSpec(ep)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, spec)
+		})
+	}
+}
+
+func TestLoadBalancerPoolName(t *testing.T) {
+	assert.Equal(t, "external-dns-app-bar-com-a", loadBalancerPoolName("App.bar.com.", endpoint.RecordTypeA))
+	assert.Equal(t, "external-dns-_-bar-com-cname", loadBalancerPoolName("*.bar.com", endpoint.RecordTypeCNAME))
+	assert.Equal(t, endpoint.RecordTypeAAAA, loadBalancerPoolRecordType("external-dns-app-bar-com-aaaa"))
+	assert.Equal(t, endpoint.RecordTypeCNAME, loadBalancerPoolRecordType("external-dns-app-bar-com-cname"))
+	assert.Empty(t, loadBalancerPoolRecordType("app-bar-com-a"))
+	assert.Empty(t, loadBalancerPoolRecordType("external-dns-app-bar-com-txt"))
+}
+
+func TestSplitLoadBalancerChanges(t *testing.T) {
+	lb := func(ep *endpoint.Endpoint) *endpoint.Endpoint {
+		return ep.WithProviderSpecific(source.CloudflareLoadBalancerKey, "true")
+	}
+	created := lb(endpoint.NewEndpoint("lb.bar.com", endpoint.RecordTypeA, "1.2.3.4"))
+	record := endpoint.NewEndpoint("www.bar.com", endpoint.RecordTypeA, "1.2.3.4")
+	// the registry TXT records of a load balanced hostname share its properties
+	ownership := lb(endpoint.NewEndpoint("a-lb.bar.com", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=default\""))
+	toLBOld, toLBNew := endpoint.NewEndpoint("to-lb.bar.com", endpoint.RecordTypeA, "1.2.3.4"), lb(endpoint.NewEndpoint("to-lb.bar.com", endpoint.RecordTypeA, "1.2.3.4"))
+	fromLBOld, fromLBNew := lb(endpoint.NewEndpoint("from-lb.bar.com", endpoint.RecordTypeA, "1.2.3.4")), endpoint.NewEndpoint("from-lb.bar.com", endpoint.RecordTypeA, "1.2.3.4")
+	lbOld, lbNew := lb(endpoint.NewEndpoint("lb2.bar.com", endpoint.RecordTypeA, "1.2.3.4")), lb(endpoint.NewEndpoint("lb2.bar.com", endpoint.RecordTypeA, "5.6.7.8"))
+
+	records, loadBalancers := splitLoadBalancerChanges(&plan.Changes{
+		Create:    []*endpoint.Endpoint{created, record, ownership},
+		UpdateOld: []*endpoint.Endpoint{toLBOld, fromLBOld, lbOld},
+		UpdateNew: []*endpoint.Endpoint{toLBNew, fromLBNew, lbNew},
+	})
+	assert.Equal(t, &plan.Changes{
+		Create: []*endpoint.Endpoint{record, ownership, fromLBNew},
+		Delete: []*endpoint.Endpoint{toLBOld},
+	}, records)
+	assert.Equal(t, &plan.Changes{
+		Create:    []*endpoint.Endpoint{created, toLBNew},
+		UpdateOld: []*endpoint.Endpoint{lbOld},
+		UpdateNew: []*endpoint.Endpoint{lbNew},
+		Delete:    []*endpoint.Endpoint{fromLBOld},
+	}, loadBalancers)
+}
+
+func TestCloudflareLoadBalancerLifecycle(t *testing.T) {
+	client := NewMockCloudFlareClient()
+	loadBalancing := newFakeLoadBalancing(t)
+	p := &CloudFlareProvider{Client: client, loadBalancing: loadBalancing, loadBalancerAccountID: "account"}
+	ctx := context.Background()
+
+	desired := endpoint.NewEndpointWithTTL("app.bar.com", endpoint.RecordTypeA, 300, "1.2.3.4", "5.6.7.8").
+		WithProviderSpecific(source.CloudflareLoadBalancerKey, "true").
+		WithProviderSpecific(source.CloudflareLoadBalancerSteeringPolicyKey, "random").
+		WithProviderSpecific(source.CloudflareLoadBalancerMonitorPathKey, "/healthz")
+	adjusted, err := p.AdjustEndpoints([]*endpoint.Endpoint{desired})
+	require.NoError(t, err)
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Create: adjusted}))
+
+	assert.Empty(t, client.Actions)
+	require.Len(t, loadBalancing.monitors, 1)
+	assert.Equal(t, cloudflare.LoadBalancerMonitor{
+		ID: "id-1", Type: "http", Description: "external-dns-app-bar-com-a", Path: "/healthz", Port: 80, ExpectedCodes: "2xx",
+	}, loadBalancing.monitors[0])
+	require.Len(t, loadBalancing.pools, 1)
+	assert.Equal(t, "external-dns-app-bar-com-a", loadBalancing.pools[0].Name)
+	assert.Equal(t, "id-1", loadBalancing.pools[0].Monitor)
+	assert.Equal(t, []cloudflare.LoadBalancerOrigin{
+		{Name: "1-2-3-4", Address: "1.2.3.4", Enabled: true, Weight: 1},
+		{Name: "5-6-7-8", Address: "5.6.7.8", Enabled: true, Weight: 1},
+	}, loadBalancing.pools[0].Origins)
+	require.Len(t, loadBalancing.loadBalancers["001"], 1)
+	lb := loadBalancing.loadBalancers["001"][0]
+	assert.Equal(t, "app.bar.com", lb.Name)
+	assert.Equal(t, []string{"id-2"}, lb.DefaultPools)
+	assert.Equal(t, "id-2", lb.FallbackPool)
+	assert.Equal(t, "random", lb.SteeringPolicy)
+	assert.Equal(t, 300, lb.TTL)
+	assert.False(t, lb.Proxied)
+
+	// the load balancer is listed with the properties of the desired endpoint, so that no change is planned
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	assert.True(t, testutils.SameEndpoints(adjusted, records), "expected %v, got %v", adjusted, records)
+
+	// the origins follow the targets, and a CNAME pool is added to the load balancer of the hostname, whose settings
+	// are shared by its pools
+	updated := endpoint.NewEndpointWithTTL("app.bar.com", endpoint.RecordTypeA, 300, "1.2.3.4").
+		WithProviderSpecific(source.CloudflareLoadBalancerKey, "true").
+		WithProviderSpecific(source.CloudflareLoadBalancerSteeringPolicyKey, "random").
+		WithProviderSpecific(source.CloudflareLoadBalancerMonitorPathKey, "/healthz")
+	cname := endpoint.NewEndpointWithTTL("app.bar.com", endpoint.RecordTypeCNAME, 300, "origin.example.org").
+		WithProviderSpecific(source.CloudflareLoadBalancerKey, "true").
+		WithProviderSpecific(source.CloudflareLoadBalancerSteeringPolicyKey, "random").
+		WithProviderSpecific(source.CloudflareLoadBalancerMonitorTypeKey, "tcp").
+		WithProviderSpecific(source.CloudflareLoadBalancerMonitorPortKey, "8443")
+	adjusted, err = p.AdjustEndpoints([]*endpoint.Endpoint{updated, cname})
+	require.NoError(t, err)
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create:    adjusted[1:],
+		UpdateOld: records,
+		UpdateNew: adjusted[:1],
+	}))
+	require.Len(t, loadBalancing.pools, 2)
+	assert.Equal(t, []cloudflare.LoadBalancerOrigin{{Name: "1-2-3-4", Address: "1.2.3.4", Enabled: true, Weight: 1}}, loadBalancing.pools[0].Origins)
+	assert.Len(t, loadBalancing.monitors, 2)
+	require.Len(t, loadBalancing.loadBalancers["001"], 1)
+	assert.Equal(t, []string{"id-2", loadBalancing.pools[1].ID}, loadBalancing.loadBalancers["001"][0].DefaultPools)
+	assert.Equal(t, "external-dns-app-bar-com-cname", loadBalancing.pools[1].Name)
+
+	records, err = p.Records(ctx)
+	require.NoError(t, err)
+	assert.True(t, testutils.SameEndpoints(adjusted, records), "expected %v, got %v", adjusted, records)
+
+	// the load balancer, pools and monitors are deleted with the endpoints
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Delete: records}))
+	assert.Empty(t, loadBalancing.loadBalancers["001"])
+	assert.Empty(t, loadBalancing.pools)
+	assert.Empty(t, loadBalancing.monitors)
+	assert.Empty(t, client.Actions)
+}
+
+func TestCloudflareLoadBalancerSwitchToRecords(t *testing.T) {
+	client := NewMockCloudFlareClient()
+	loadBalancing := newFakeLoadBalancing(t)
+	p := &CloudFlareProvider{Client: client, loadBalancing: loadBalancing, loadBalancerAccountID: "account"}
+	ctx := context.Background()
+
+	adjusted, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("app.bar.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(source.CloudflareLoadBalancerKey, "true"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Create: adjusted}))
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+
+	adjusted, err = p.AdjustEndpoints([]*endpoint.Endpoint{endpoint.NewEndpoint("app.bar.com", endpoint.RecordTypeA, "1.2.3.4")})
+	require.NoError(t, err)
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{UpdateOld: records, UpdateNew: adjusted}))
+
+	assert.Empty(t, loadBalancing.loadBalancers["001"])
+	assert.Empty(t, loadBalancing.pools)
+	assert.Empty(t, loadBalancing.monitors)
+	require.Len(t, client.Actions, 1)
+	assert.Equal(t, "Create", client.Actions[0].Name)
+	assert.Equal(t, "app.bar.com", client.Actions[0].RecordData.Name)
+}
+
+func TestCloudflareLoadBalancerDryRun(t *testing.T) {
+	loadBalancing := newFakeLoadBalancing(t)
+	p := &CloudFlareProvider{Client: NewMockCloudFlareClient(), loadBalancing: loadBalancing, loadBalancerAccountID: "account", DryRun: true}
+
+	adjusted, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("app.bar.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(source.CloudflareLoadBalancerKey, "true"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{Create: adjusted}))
+	assert.Empty(t, loadBalancing.loadBalancers)
+	assert.Empty(t, loadBalancing.pools)
+	assert.Empty(t, loadBalancing.monitors)
+}
+
+func TestCloudflareAdjustEndpointsLoadBalancer(t *testing.T) {
+	ep := func() *endpoint.Endpoint {
+		return endpoint.NewEndpoint("app.bar.com", endpoint.RecordTypeA, "1.2.3.4").
+			WithProviderSpecific(source.CloudflareLoadBalancerKey, "true").
+			WithProviderSpecific(source.CloudflareLoadBalancerMonitorTypeKey, "https")
+	}
+
+	// the load balancers are not managed without account
+	p := &CloudFlareProvider{}
+	adjusted, err := p.AdjustEndpoints([]*endpoint.Endpoint{ep()})
+	require.NoError(t, err)
+	assert.Equal(t, endpoint.ProviderSpecific{{Name: source.CloudflareProxiedKey, Value: "false"}}, adjusted[0].ProviderSpecific)
+
+	p = &CloudFlareProvider{loadBalancing: newFakeLoadBalancing(t), loadBalancerAccountID: "account"}
+	adjusted, err = p.AdjustEndpoints([]*endpoint.Endpoint{ep()})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, endpoint.ProviderSpecific{
+		{Name: source.CloudflareProxiedKey, Value: "false"},
+		{Name: source.CloudflareLoadBalancerKey, Value: "true"},
+		{Name: source.CloudflareLoadBalancerSteeringPolicyKey, Value: "off"},
+		{Name: source.CloudflareLoadBalancerMonitorTypeKey, Value: "https"},
+		{Name: source.CloudflareLoadBalancerMonitorPortKey, Value: "443"},
+		{Name: source.CloudflareLoadBalancerMonitorPathKey, Value: "/"},
+		{Name: source.CloudflareLoadBalancerExpectedCodesKey, Value: "2xx"},
+	}, adjusted[0].ProviderSpecific)
+
+	// invalid load balancers are served with records
+	invalid := ep().WithProviderSpecific(source.CloudflareLoadBalancerSteeringPolicyKey, "nearest")
+	adjusted, err = p.AdjustEndpoints([]*endpoint.Endpoint{invalid})
+	require.NoError(t, err)
+	assert.Equal(t, endpoint.ProviderSpecific{{Name: source.CloudflareProxiedKey, Value: "false"}}, adjusted[0].ProviderSpecific)
+}
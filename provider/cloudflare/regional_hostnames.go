@@ -0,0 +1,195 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	cloudflare "github.com/cloudflare/cloudflare-go"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/source"
+)
+
+// regionalHostnameRecordTypes are the types of the records whose hostname can be regional.
+var regionalHostnameRecordTypes = []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME}
+
+// cloudFlareRegionalHostnames is the subset of the CloudFlare API managing the regional hostnames of a zone, which
+// restrict the data centers serving the hostnames to a region, with Cloudflare Regional Services.
+type cloudFlareRegionalHostnames interface {
+	ListDataLocalizationRegionalHostnames(ctx context.Context, rc *cloudflare.ResourceContainer, params cloudflare.ListDataLocalizationRegionalHostnamesParams) ([]cloudflare.RegionalHostname, error)
+	CreateDataLocalizationRegionalHostname(ctx context.Context, rc *cloudflare.ResourceContainer, params cloudflare.CreateDataLocalizationRegionalHostnameParams) (cloudflare.RegionalHostname, error)
+	UpdateDataLocalizationRegionalHostname(ctx context.Context, rc *cloudflare.ResourceContainer, params cloudflare.UpdateDataLocalizationRegionalHostnameParams) (cloudflare.RegionalHostname, error)
+	DeleteDataLocalizationRegionalHostname(ctx context.Context, rc *cloudflare.ResourceContainer, hostname string) error
+}
+
+// regionKey returns the region key of the endpoint, empty if its hostname is not regional.
+func regionKey(ep *endpoint.Endpoint) string {
+	if !slices.Contains(regionalHostnameRecordTypes, ep.RecordType) {
+		return ""
+	}
+	key, _ := ep.GetProviderSpecificProperty(source.CloudflareRegionKey)
+	return key
+}
+
+// adjustRegionalHostname sets the region key of the endpoint to the default region key if it has none, and removes
+// it if regional hostnames are not managed or if the hostname of the endpoint cannot be regional.
+func (p *CloudFlareProvider) adjustRegionalHostname(ep *endpoint.Endpoint) {
+	key, ok := ep.GetProviderSpecificProperty(source.CloudflareRegionKey)
+	switch {
+	case !slices.Contains(regionalHostnameRecordTypes, ep.RecordType) || isCustomHostname(ep):
+		ep.DeleteProviderSpecificProperty(source.CloudflareRegionKey)
+	case p.regionalHostnames == nil:
+		if ok {
+			log.Warnf("Serving %s from all regions, regional hostnames are not managed without --cloudflare-regional-services", ep.DNSName)
+		}
+		ep.DeleteProviderSpecificProperty(source.CloudflareRegionKey)
+	case key == "" && p.regionKey != "":
+		ep.SetProviderSpecificProperty(source.CloudflareRegionKey, p.regionKey)
+	case key == "":
+		ep.DeleteProviderSpecificProperty(source.CloudflareRegionKey)
+	}
+}
+
+// listRegionalHostnames returns the region keys of the regional hostnames of the zone, by hostname.
+func (p *CloudFlareProvider) listRegionalHostnames(ctx context.Context, zoneID string) (map[string]string, error) {
+	list, err := p.regionalHostnames.ListDataLocalizationRegionalHostnames(ctx, cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDataLocalizationRegionalHostnamesParams{})
+	if err != nil {
+		return nil, fmt.Errorf("listing regional hostnames: %w", softRateLimitError(err))
+	}
+	keys := make(map[string]string, len(list))
+	for _, rh := range list {
+		keys[strings.ToLower(rh.Hostname)] = rh.RegionKey
+	}
+	return keys, nil
+}
+
+// setRegionKeys sets the region keys of the regional hostnames on the endpoints of the zone.
+func setRegionKeys(endpoints []*endpoint.Endpoint, keys map[string]string) {
+	for _, ep := range endpoints {
+		if key, ok := keys[strings.ToLower(ep.DNSName)]; ok && slices.Contains(regionalHostnameRecordTypes, ep.RecordType) {
+			ep.SetProviderSpecificProperty(source.CloudflareRegionKey, key)
+		}
+	}
+}
+
+// regionalHostnameChanges returns the region keys the hostnames of the changes must have, by hostname, an empty
+// key deleting the regional hostname. A hostname keeping its region key, or still having a record with it, is
+// left unchanged.
+func regionalHostnameChanges(changes *plan.Changes) map[string]string {
+	desired := map[string]string{}
+	kept := map[string]bool{}
+	for _, ep := range changes.Delete {
+		if regionKey(ep) != "" {
+			desired[ep.DNSName] = ""
+		}
+	}
+	for i, current := range changes.UpdateOld {
+		if key := regionKey(current); key != "" && key != regionKey(changes.UpdateNew[i]) {
+			desired[current.DNSName] = ""
+		}
+	}
+	for i, ep := range slices.Concat(changes.Create, changes.UpdateNew) {
+		key := regionKey(ep)
+		if i >= len(changes.Create) && key == regionKey(changes.UpdateOld[i-len(changes.Create)]) {
+			if key != "" {
+				kept[ep.DNSName] = true
+			}
+			continue
+		}
+		if key != "" {
+			desired[ep.DNSName] = key
+		}
+	}
+	for name, key := range desired {
+		if key == "" && kept[name] {
+			delete(desired, name)
+		}
+	}
+	return desired
+}
+
+// submitRegionalHostnameChanges creates, updates and deletes the regional hostnames of the changes, once their
+// records are changed.
+func (p *CloudFlareProvider) submitRegionalHostnameChanges(ctx context.Context, changes *plan.Changes) error {
+	desired := regionalHostnameChanges(changes)
+	if len(desired) == 0 {
+		return nil
+	}
+	zones, err := p.Zones(ctx)
+	if err != nil {
+		return err
+	}
+	zoneIDs := provider.ZoneIDName{}
+	for _, zone := range zones {
+		zoneIDs.Add(zone.ID, zone.Name)
+	}
+	current := map[string]map[string]string{}
+
+	var failed []string
+	for name, key := range desired {
+		zoneID, _ := zoneIDs.FindZone(name)
+		if zoneID == "" {
+			continue
+		}
+		if _, ok := current[zoneID]; !ok {
+			keys, err := p.listRegionalHostnames(ctx, zoneID)
+			if err != nil {
+				return err
+			}
+			current[zoneID] = keys
+		}
+		currentKey, exists := current[zoneID][strings.ToLower(name)]
+		rc := cloudflare.ZoneIdentifier(zoneID)
+		var err error
+		switch {
+		case currentKey == key:
+			continue
+		case key == "":
+			log.Infof("Deleting the regional hostname %s", name)
+			if !p.DryRun {
+				err = p.regionalHostnames.DeleteDataLocalizationRegionalHostname(ctx, rc, name)
+			}
+		case exists:
+			log.Infof("Updating the region of the regional hostname %s to %s", name, key)
+			if !p.DryRun {
+				_, err = p.regionalHostnames.UpdateDataLocalizationRegionalHostname(ctx, rc, cloudflare.UpdateDataLocalizationRegionalHostnameParams{Hostname: name, RegionKey: key})
+			}
+		default:
+			log.Infof("Creating the regional hostname %s in region %s", name, key)
+			if !p.DryRun {
+				_, err = p.regionalHostnames.CreateDataLocalizationRegionalHostname(ctx, rc, cloudflare.CreateDataLocalizationRegionalHostnameParams{Hostname: name, RegionKey: key})
+			}
+		}
+		if err != nil {
+			log.Errorf("Failed to change the regional hostname %s: %v", name, err)
+			failed = append(failed, name)
+		}
+	}
+
+	if len(failed) > 0 {
+		slices.Sort(failed)
+		return fmt.Errorf("failed to submit all changes for the following regional hostnames: %v", failed)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"context"
+	"testing"
+
+	cloudflare "github.com/cloudflare/cloudflare-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/source"
+)
+
+// fakeRegionalHostnames keeps the region keys of the regional hostnames by zone and hostname.
+type fakeRegionalHostnames struct {
+	regionKeys map[string]map[string]string
+}
+
+func (f *fakeRegionalHostnames) ListDataLocalizationRegionalHostnames(_ context.Context, rc *cloudflare.ResourceContainer, _ cloudflare.ListDataLocalizationRegionalHostnamesParams) ([]cloudflare.RegionalHostname, error) {
+	var list []cloudflare.RegionalHostname
+	for hostname, key := range f.regionKeys[rc.Identifier] {
+		list = append(list, cloudflare.RegionalHostname{Hostname: hostname, RegionKey: key})
+	}
+	return list, nil
+}
+
+func (f *fakeRegionalHostnames) CreateDataLocalizationRegionalHostname(_ context.Context, rc *cloudflare.ResourceContainer, params cloudflare.CreateDataLocalizationRegionalHostnameParams) (cloudflare.RegionalHostname, error) {
+	if f.regionKeys[rc.Identifier] == nil {
+		f.regionKeys[rc.Identifier] = map[string]string{}
+	}
+	f.regionKeys[rc.Identifier][params.Hostname] = params.RegionKey
+	return cloudflare.RegionalHostname{Hostname: params.Hostname, RegionKey: params.RegionKey}, nil
+}
+
+func (f *fakeRegionalHostnames) UpdateDataLocalizationRegionalHostname(_ context.Context, rc *cloudflare.ResourceContainer, params cloudflare.UpdateDataLocalizationRegionalHostnameParams) (cloudflare.RegionalHostname, error) {
+	f.regionKeys[rc.Identifier][params.Hostname] = params.RegionKey
+	return cloudflare.RegionalHostname{Hostname: params.Hostname, RegionKey: params.RegionKey}, nil
+}
+
+func (f *fakeRegionalHostnames) DeleteDataLocalizationRegionalHostname(_ context.Context, rc *cloudflare.ResourceContainer, hostname string) error {
+	delete(f.regionKeys[rc.Identifier], hostname)
+	return nil
+}
+
+func TestAdjustRegionalHostname(t *testing.T) {
+	p := &CloudFlareProvider{regionalHostnames: &fakeRegionalHostnames{}, regionKey: "eu"}
+	adjusted, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("web.bar.com", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("api.bar.com", endpoint.RecordTypeCNAME, "web.bar.com").WithProviderSpecific(source.CloudflareRegionKey, "us"),
+		endpoint.NewEndpoint("web.bar.com", endpoint.RecordTypeTXT, "\"heritage=external-dns\"").WithProviderSpecific(source.CloudflareRegionKey, "us"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "eu", regionKey(adjusted[0]))
+	assert.Equal(t, "us", regionKey(adjusted[1]))
+	_, ok := adjusted[2].GetProviderSpecificProperty(source.CloudflareRegionKey)
+	assert.False(t, ok)
+
+	// without default region key, the hostnames without annotation are served from all regions
+	adjusted, err = (&CloudFlareProvider{regionalHostnames: &fakeRegionalHostnames{}}).AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("web.bar.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(source.CloudflareRegionKey, ""),
+	})
+	require.NoError(t, err)
+	_, ok = adjusted[0].GetProviderSpecificProperty(source.CloudflareRegionKey)
+	assert.False(t, ok)
+
+	// regional hostnames are not managed without regional services
+	adjusted, err = (&CloudFlareProvider{}).AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("web.bar.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(source.CloudflareRegionKey, "eu"),
+	})
+	require.NoError(t, err)
+	_, ok = adjusted[0].GetProviderSpecificProperty(source.CloudflareRegionKey)
+	assert.False(t, ok)
+}
+
+func TestRegionalHostnameChanges(t *testing.T) {
+	regional := func(name, recordType, key string) *endpoint.Endpoint {
+		ep := endpoint.NewEndpoint(name, recordType, "1.2.3.4")
+		if key != "" {
+			ep.WithProviderSpecific(source.CloudflareRegionKey, key)
+		}
+		return ep
+	}
+	assert.Equal(t, map[string]string{
+		"new.bar.com":     "eu",
+		"moved.bar.com":   "us",
+		"global.bar.com":  "",
+		"deleted.bar.com": "",
+	}, regionalHostnameChanges(&plan.Changes{
+		Create: []*endpoint.Endpoint{regional("new.bar.com", endpoint.RecordTypeA, "eu"), regional("plain.bar.com", endpoint.RecordTypeA, "")},
+		UpdateOld: []*endpoint.Endpoint{
+			regional("moved.bar.com", endpoint.RecordTypeA, "eu"),
+			regional("global.bar.com", endpoint.RecordTypeA, "eu"),
+			regional("kept.bar.com", endpoint.RecordTypeA, "eu"),
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			regional("moved.bar.com", endpoint.RecordTypeA, "us"),
+			regional("global.bar.com", endpoint.RecordTypeA, ""),
+			regional("kept.bar.com", endpoint.RecordTypeA, "eu"),
+		},
+		Delete: []*endpoint.Endpoint{
+			regional("deleted.bar.com", endpoint.RecordTypeA, "eu"),
+			// the regional hostname is kept for the AAAA record updated with the same region key
+			regional("kept.bar.com", endpoint.RecordTypeAAAA, "eu"),
+		},
+	}))
+}
+
+func TestCloudflareRegionalHostnameLifecycle(t *testing.T) {
+	client := NewMockCloudFlareClientWithRecords(map[string][]cloudflare.DNSRecord{
+		"001": {{ID: "1", Name: "web.bar.com", Type: endpoint.RecordTypeA, TTL: 120, Content: "1.2.3.4", Proxied: proxyDisabled}},
+	})
+	regionalHostnames := &fakeRegionalHostnames{regionKeys: map[string]map[string]string{}}
+	p := &CloudFlareProvider{Client: client, regionalHostnames: regionalHostnames}
+	ctx := context.Background()
+
+	apply := func(changes *plan.Changes) {
+		require.NoError(t, p.ApplyChanges(ctx, changes))
+		// the mock keeps the updated records without ID
+		record := client.Records["001"]["1"]
+		record.ID = "1"
+		client.Records["001"]["1"] = record
+	}
+	calculate := func(desired *endpoint.Endpoint) *plan.Changes {
+		current, err := p.Records(ctx)
+		require.NoError(t, err)
+		adjusted, err := p.AdjustEndpoints([]*endpoint.Endpoint{desired})
+		require.NoError(t, err)
+		return (&plan.Plan{
+			Current:            current,
+			Desired:            adjusted,
+			ManagedRecords:     []string{endpoint.RecordTypeA},
+			PropertyComparator: p.PropertyValuesEqual,
+		}).Calculate().Changes
+	}
+
+	// annotating the hostname with a region key updates the endpoint, which creates the regional hostname
+	changes := calculate(endpoint.NewEndpointWithTTL("web.bar.com", endpoint.RecordTypeA, 120, "1.2.3.4").WithProviderSpecific(source.CloudflareRegionKey, "eu"))
+	require.Len(t, changes.UpdateNew, 1)
+	apply(changes)
+	assert.Equal(t, map[string]string{"web.bar.com": "eu"}, regionalHostnames.regionKeys["001"])
+
+	// the region key is listed, so that no change is planned
+	changes = calculate(endpoint.NewEndpointWithTTL("web.bar.com", endpoint.RecordTypeA, 120, "1.2.3.4").WithProviderSpecific(source.CloudflareRegionKey, "eu"))
+	assert.False(t, changes.HasChanges())
+
+	// flipping the proxied flag of the endpoint updates it, keeping its region
+	changes = calculate(endpoint.NewEndpointWithTTL("web.bar.com", endpoint.RecordTypeA, 120, "1.2.3.4").
+		WithProviderSpecific(source.CloudflareRegionKey, "eu").
+		WithProviderSpecific(source.CloudflareProxiedKey, "true"))
+	require.Len(t, changes.UpdateNew, 1)
+	apply(changes)
+	assert.True(t, *client.Records["001"]["1"].Proxied)
+	assert.Equal(t, map[string]string{"web.bar.com": "eu"}, regionalHostnames.regionKeys["001"])
+
+	// removing the annotation serves the hostname from all regions
+	changes = calculate(endpoint.NewEndpoint("web.bar.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(source.CloudflareProxiedKey, "true"))
+	require.Len(t, changes.UpdateNew, 1)
+	apply(changes)
+	assert.Empty(t, regionalHostnames.regionKeys["001"])
+}
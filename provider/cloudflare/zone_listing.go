@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	cloudflare "github.com/cloudflare/cloudflare-go"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// zonesPerPage is the number of zones listed per request, the maximum allowed by the API.
+const zonesPerPage = 50
+
+// cloudFlareTokenVerifier verifies the API token of the provider.
+type cloudFlareTokenVerifier interface {
+	VerifyAPIToken(ctx context.Context) (cloudflare.APITokenVerifyBody, error)
+}
+
+// listingWorkers returns the number of concurrent requests listing the zones and their records.
+func (p *CloudFlareProvider) listingWorkers() int {
+	return max(p.ListingWorkers, 1)
+}
+
+// listZonePages lists the zones matching the domain filter page by page, the pages after the first one with
+// concurrent requests, keeping only the matching zones of each page.
+func (p *CloudFlareProvider) listZonePages(ctx context.Context) ([]cloudflare.Zone, error) {
+	first, resultInfo, err := p.Client.ListZonesPage(ctx, 1, zonesPerPage)
+	if err != nil {
+		return nil, softRateLimitError(err)
+	}
+	pages := make([][]cloudflare.Zone, max(resultInfo.TotalPages, 1))
+	pages[0] = p.filterZones(first)
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(p.listingWorkers())
+	for page := 2; page <= resultInfo.TotalPages; page++ {
+		g.Go(func() error {
+			zones, _, err := p.Client.ListZonesPage(ctx, page, zonesPerPage)
+			if err != nil {
+				return softRateLimitError(err)
+			}
+			pages[page-1] = p.filterZones(zones)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return slices.Concat(pages...), nil
+}
+
+// filterZones returns the zones matching the domain filter.
+func (p *CloudFlareProvider) filterZones(zones []cloudflare.Zone) []cloudflare.Zone {
+	result := make([]cloudflare.Zone, 0, len(zones))
+	for _, zone := range zones {
+		if !p.domainFilter.Match(zone.Name) {
+			log.Debugf("zone %s not in domain filter", zone.Name)
+			continue
+		}
+		result = append(result, zone)
+	}
+	return result
+}
+
+// listZonesEndpoints lists the records of the zones with concurrent requests, grouped into endpoints by name and
+// type as soon as the records of a zone are listed.
+func (p *CloudFlareProvider) listZonesEndpoints(ctx context.Context, zones []cloudflare.Zone) ([][]*endpoint.Endpoint, error) {
+	endpoints := make([][]*endpoint.Endpoint, len(zones))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(p.listingWorkers())
+	for i, zone := range zones {
+		g.Go(func() error {
+			records, err := p.listZoneRecords(ctx, zone.ID)
+			if err != nil {
+				return err
+			}
+			// As CloudFlare does not support "sets" of targets, but instead returns
+			// a single entry for each name/type/target, we have to group by name
+			// and record to allow the planner to calculate the correct plan. See #992.
+			endpoints[i] = groupByNameAndType(records)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// CheckTokenScope checks that the API token is active, and that it can list the zones of the domain filter and
+// read their records, so that a token with too narrow a scope fails at startup rather than in the middle of a
+// synchronization.
+func (p *CloudFlareProvider) CheckTokenScope(ctx context.Context) error {
+	if p.tokenVerifier != nil {
+		verified, err := p.tokenVerifier.VerifyAPIToken(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to verify the Cloudflare API token: %w", err)
+		}
+		if verified.Status != "active" {
+			return fmt.Errorf("the Cloudflare API token is %s", verified.Status)
+		}
+	}
+	zones, err := p.Zones(ctx)
+	if err != nil {
+		return fmt.Errorf("the Cloudflare API token cannot list the zones: %w", err)
+	}
+
+	var missing []string
+	for _, domain := range p.domainFilter.Filters {
+		domain = strings.TrimPrefix(strings.TrimSuffix(domain, "."), ".")
+		if domain == "" {
+			continue
+		}
+		covered := slices.ContainsFunc(zones, func(zone cloudflare.Zone) bool {
+			return zone.Name == domain || strings.HasSuffix(domain, "."+zone.Name) || strings.HasSuffix(zone.Name, "."+domain)
+		})
+		if !covered {
+			missing = append(missing, domain)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the Cloudflare API token has no access to a zone of the domains %v", missing)
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(p.listingWorkers())
+	errs := make([]error, len(zones))
+	for i, zone := range zones {
+		g.Go(func() error {
+			params := cloudflare.ListDNSRecordsParams{ResultInfo: cloudflare.ResultInfo{PerPage: 1, Page: 1}}
+			if _, _, err := p.Client.ListDNSRecords(ctx, cloudflare.ZoneIdentifier(zone.ID), params); err != nil {
+				errs[i] = fmt.Errorf("the Cloudflare API token cannot read the records of zone %s: %w", zone.Name, err)
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	log.Infof("The Cloudflare API token has access to the records of %d zones", len(zones))
+	return nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	cloudflare "github.com/cloudflare/cloudflare-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// fakeTokenVerifier returns the status of the API token.
+type fakeTokenVerifier struct {
+	status string
+	err    error
+}
+
+func (f *fakeTokenVerifier) VerifyAPIToken(context.Context) (cloudflare.APITokenVerifyBody, error) {
+	return cloudflare.APITokenVerifyBody{Status: f.status}, f.err
+}
+
+// newManyZonesClient returns a client of the zones zone-000.com to zone-<n-1>.com, each with an A record.
+func newManyZonesClient(n int) *mockCloudFlareClient {
+	client := &mockCloudFlareClient{Zones: map[string]string{}, Records: map[string]map[string]cloudflare.DNSRecord{}}
+	for i := range n {
+		id := fmt.Sprintf("%03d", i)
+		name := fmt.Sprintf("zone-%s.com", id)
+		client.Zones[id] = name
+		client.Records[id] = map[string]cloudflare.DNSRecord{
+			id: {ID: id, Name: "www." + name, Type: endpoint.RecordTypeA, TTL: 120, Content: "1.2.3.4", Proxied: proxyDisabled},
+		}
+	}
+	return client
+}
+
+func TestCloudflareListZonePages(t *testing.T) {
+	client := newManyZonesClient(120)
+	p := &CloudFlareProvider{Client: client, ListingWorkers: 4}
+
+	zones, err := p.Zones(context.Background())
+	require.NoError(t, err)
+	require.Len(t, zones, 120)
+	// the zones are kept in the order of the pages
+	for i, zone := range zones {
+		assert.Equal(t, fmt.Sprintf("%03d", i), zone.ID)
+	}
+	assert.Equal(t, 3, client.zonePageRequests)
+
+	// the domain filter applies to each page
+	p.domainFilter = endpoint.NewDomainFilter([]string{"zone-001.com", "zone-119.com"})
+	zones, err = p.Zones(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []cloudflare.Zone{{ID: "001", Name: "zone-001.com"}, {ID: "119", Name: "zone-119.com"}}, zones)
+
+	client.listZonesContextError = errors.New("failed to list zones")
+	_, err = p.Zones(context.Background())
+	assert.EqualError(t, err, "failed to list zones")
+}
+
+func TestCloudflareRecordsManyZones(t *testing.T) {
+	client := newManyZonesClient(120)
+	p := &CloudFlareProvider{Client: client, ListingWorkers: 8}
+
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	require.Len(t, records, 120)
+	assert.Equal(t, "www.zone-000.com", records[0].DNSName)
+	assert.Equal(t, "www.zone-119.com", records[119].DNSName)
+
+	client.dnsRecordsError = errors.New("failed to list records")
+	_, err = p.Records(context.Background())
+	assert.ErrorContains(t, err, "failed to list records")
+}
+
+func TestCloudflareCheckTokenScope(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		verifier        *fakeTokenVerifier
+		domains         []string
+		dnsRecordsError error
+		expectedError   string
+	}{
+		{
+			name:     "active token with access to the zones",
+			verifier: &fakeTokenVerifier{status: "active"},
+			domains:  []string{"bar.com", "foo.com"},
+		},
+		{
+			name:          "expired token",
+			verifier:      &fakeTokenVerifier{status: "expired"},
+			expectedError: "the Cloudflare API token is expired",
+		},
+		{
+			name:          "token verification failure",
+			verifier:      &fakeTokenVerifier{err: errors.New("invalid token")},
+			expectedError: "failed to verify the Cloudflare API token: invalid token",
+		},
+		{
+			name:          "domain without zone",
+			domains:       []string{"bar.com", "baz.com"},
+			expectedError: "the Cloudflare API token has no access to a zone of the domains [baz.com]",
+		},
+		{
+			name:            "records not readable",
+			domains:         []string{"bar.com"},
+			dnsRecordsError: errors.New("permission denied"),
+			expectedError:   "the Cloudflare API token cannot read the records of zone bar.com: permission denied",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewMockCloudFlareClient()
+			client.dnsRecordsError = tc.dnsRecordsError
+			p := &CloudFlareProvider{Client: client, domainFilter: endpoint.NewDomainFilter(tc.domains)}
+			if tc.verifier != nil {
+				p.tokenVerifier = tc.verifier
+			}
+
+			err := p.CheckTokenScope(context.Background())
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
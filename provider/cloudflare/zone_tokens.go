@@ -0,0 +1,267 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	cloudflare "github.com/cloudflare/cloudflare-go"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// zoneToken is an entry of the zone tokens file: an API token and the names of the zones it is used for.
+type zoneToken struct {
+	// Token is the API token, or file:<path> to read it from a file like CF_API_TOKEN
+	Token string   `yaml:"token"`
+	Zones []string `yaml:"zones"`
+}
+
+// readToken returns the token, read from the file when it has the file: prefix.
+func readToken(token string) (string, error) {
+	if !strings.HasPrefix(token, "file:") {
+		return token, nil
+	}
+	tokenBytes, err := os.ReadFile(strings.TrimPrefix(token, "file:"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(tokenBytes)), nil
+}
+
+// loadZoneTokens reads the zone tokens file.
+func loadZoneTokens(path string) ([]zoneToken, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the Cloudflare zone tokens file: %w", err)
+	}
+	var tokens []zoneToken
+	if err := yaml.UnmarshalStrict(content, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse the Cloudflare zone tokens file %s: %w", path, err)
+	}
+	seen := map[string]bool{}
+	for i := range tokens {
+		if tokens[i].Token == "" || len(tokens[i].Zones) == 0 {
+			return nil, fmt.Errorf("entry %d of the Cloudflare zone tokens file must have a token and zones", i)
+		}
+		if tokens[i].Token, err = readToken(tokens[i].Token); err != nil {
+			return nil, fmt.Errorf("failed to read the token of entry %d of the Cloudflare zone tokens file: %w", i, err)
+		}
+		for _, zone := range tokens[i].Zones {
+			if seen[zone] {
+				return nil, fmt.Errorf("zone %s is listed several times in the Cloudflare zone tokens file", zone)
+			}
+			seen[zone] = true
+		}
+	}
+	return tokens, nil
+}
+
+// zoneTokenClient sends the requests for a zone with the client of the token configured for it, and the
+// requests for the other zones with the default client, if any. This allows least-privilege tokens
+// scoped to a few zones instead of a single token with access to the whole account.
+type zoneTokenClient struct {
+	// fallback is the client for the zones without their own token, nil if there is none
+	fallback cloudFlareDNS
+	// zones are the zones with their own token
+	zones []cloudflare.Zone
+	// clients are the clients of the zones with their own token, by zone ID and by zone name
+	clients map[string]cloudFlareDNS
+}
+
+// newZoneTokenClient returns a client using the token clients for their zones. It checks the scopes of the
+// tokens up front: each token must be able to look up its zones and read their records, so that a token with
+// too narrow a scope fails at startup rather than in the middle of a synchronization.
+func newZoneTokenClient(ctx context.Context, fallback cloudFlareDNS, tokens []cloudFlareDNS, tokenZones [][]string) (*zoneTokenClient, error) {
+	c := &zoneTokenClient{fallback: fallback, clients: map[string]cloudFlareDNS{}}
+	for i, client := range tokens {
+		zones, err := client.ListZones(ctx, tokenZones[i]...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the zones of Cloudflare zone token %d: %w", i, err)
+		}
+		byName := map[string]cloudflare.Zone{}
+		for _, zone := range zones {
+			byName[zone.Name] = zone
+		}
+		for _, name := range tokenZones[i] {
+			zone, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("cloudflare zone token %d has no access to zone %s", i, name)
+			}
+			params := cloudflare.ListDNSRecordsParams{ResultInfo: cloudflare.ResultInfo{PerPage: 1, Page: 1}}
+			if _, _, err := client.ListDNSRecords(ctx, cloudflare.ZoneIdentifier(zone.ID), params); err != nil {
+				return nil, fmt.Errorf("cloudflare zone token %d cannot read the records of zone %s: %w", i, name, err)
+			}
+			log.Debugf("Using Cloudflare zone token %d for zone %s", i, name)
+			c.zones = append(c.zones, zone)
+			c.clients[zone.ID] = client
+			c.clients[zone.Name] = client
+		}
+	}
+	return c, nil
+}
+
+// newZoneTokenClientFromFile returns a client using the tokens of the zone tokens file for their zones.
+func newZoneTokenClientFromFile(ctx context.Context, fallback cloudFlareDNS, path string) (*zoneTokenClient, error) {
+	tokens, err := loadZoneTokens(path)
+	if err != nil {
+		return nil, err
+	}
+	clients := make([]cloudFlareDNS, 0, len(tokens))
+	tokenZones := make([][]string, 0, len(tokens))
+	for i, token := range tokens {
+		api, err := cloudflare.NewWithAPIToken(token.Token, apiOptions()...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token in entry %d of the Cloudflare zone tokens file: %w", i, err)
+		}
+		clients = append(clients, zoneService{api})
+		tokenZones = append(tokenZones, token.Zones)
+	}
+	return newZoneTokenClient(ctx, fallback, clients, tokenZones)
+}
+
+// client returns the client for a zone ID or name.
+func (c *zoneTokenClient) client(zone string) (cloudFlareDNS, error) {
+	if client, ok := c.clients[zone]; ok {
+		return client, nil
+	}
+	if c.fallback == nil {
+		return nil, fmt.Errorf("no Cloudflare API token for zone %s", zone)
+	}
+	return c.fallback, nil
+}
+
+// fallbackZones returns the zones of the default client without their own token.
+func (c *zoneTokenClient) fallbackZones(zones []cloudflare.Zone) []cloudflare.Zone {
+	result := make([]cloudflare.Zone, 0, len(zones))
+	for _, zone := range zones {
+		if _, ok := c.clients[zone.ID]; !ok {
+			result = append(result, zone)
+		}
+	}
+	return result
+}
+
+func (c *zoneTokenClient) UserDetails(ctx context.Context) (cloudflare.User, error) {
+	if c.fallback == nil {
+		return cloudflare.User{}, errors.New("no Cloudflare API token for the user details")
+	}
+	return c.fallback.UserDetails(ctx)
+}
+
+func (c *zoneTokenClient) ZoneIDByName(zoneName string) (string, error) {
+	if client, ok := c.clients[zoneName]; ok {
+		return client.ZoneIDByName(zoneName)
+	}
+	if c.fallback == nil {
+		return "", fmt.Errorf("no Cloudflare API token for zone %s", zoneName)
+	}
+	return c.fallback.ZoneIDByName(zoneName)
+}
+
+func (c *zoneTokenClient) ListZones(ctx context.Context, zoneID ...string) ([]cloudflare.Zone, error) {
+	result := append([]cloudflare.Zone{}, c.zones...)
+	if c.fallback == nil {
+		return result, nil
+	}
+	zones, err := c.fallback.ListZones(ctx, zoneID...)
+	if err != nil {
+		return nil, err
+	}
+	return append(result, c.fallbackZones(zones)...), nil
+}
+
+func (c *zoneTokenClient) ListZonesContext(ctx context.Context, opts ...cloudflare.ReqOption) (cloudflare.ZonesResponse, error) {
+	response := cloudflare.ZonesResponse{Result: append([]cloudflare.Zone{}, c.zones...)}
+	if c.fallback == nil {
+		return response, nil
+	}
+	fallbackResponse, err := c.fallback.ListZonesContext(ctx, opts...)
+	if err != nil {
+		return cloudflare.ZonesResponse{}, err
+	}
+	fallbackResponse.Result = append(response.Result, c.fallbackZones(fallbackResponse.Result)...)
+	return fallbackResponse, nil
+}
+
+// ListZonesPage lists the zones with their own token with the first page of the zones of the default client.
+func (c *zoneTokenClient) ListZonesPage(ctx context.Context, page, perPage int) ([]cloudflare.Zone, cloudflare.ResultInfo, error) {
+	var result []cloudflare.Zone
+	if page == 1 {
+		result = append(result, c.zones...)
+	}
+	if c.fallback == nil {
+		return result, cloudflare.ResultInfo{Page: page, PerPage: perPage, TotalPages: 1, Count: len(result), Total: len(c.zones)}, nil
+	}
+	zones, resultInfo, err := c.fallback.ListZonesPage(ctx, page, perPage)
+	if err != nil {
+		return nil, cloudflare.ResultInfo{}, err
+	}
+	return append(result, c.fallbackZones(zones)...), resultInfo, nil
+}
+
+func (c *zoneTokenClient) ZoneDetails(ctx context.Context, zoneID string) (cloudflare.Zone, error) {
+	client, err := c.client(zoneID)
+	if err != nil {
+		return cloudflare.Zone{}, err
+	}
+	return client.ZoneDetails(ctx, zoneID)
+}
+
+func (c *zoneTokenClient) ListDNSRecords(ctx context.Context, rc *cloudflare.ResourceContainer, rp cloudflare.ListDNSRecordsParams) ([]cloudflare.DNSRecord, *cloudflare.ResultInfo, error) {
+	client, err := c.client(rc.Identifier)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client.ListDNSRecords(ctx, rc, rp)
+}
+
+func (c *zoneTokenClient) CreateDNSRecord(ctx context.Context, rc *cloudflare.ResourceContainer, rp cloudflare.CreateDNSRecordParams) (cloudflare.DNSRecord, error) {
+	client, err := c.client(rc.Identifier)
+	if err != nil {
+		return cloudflare.DNSRecord{}, err
+	}
+	return client.CreateDNSRecord(ctx, rc, rp)
+}
+
+func (c *zoneTokenClient) DeleteDNSRecord(ctx context.Context, rc *cloudflare.ResourceContainer, recordID string) error {
+	client, err := c.client(rc.Identifier)
+	if err != nil {
+		return err
+	}
+	return client.DeleteDNSRecord(ctx, rc, recordID)
+}
+
+func (c *zoneTokenClient) UpdateDNSRecord(ctx context.Context, rc *cloudflare.ResourceContainer, rp cloudflare.UpdateDNSRecordParams) error {
+	client, err := c.client(rc.Identifier)
+	if err != nil {
+		return err
+	}
+	return client.UpdateDNSRecord(ctx, rc, rp)
+}
+
+func (c *zoneTokenClient) ExportDNSRecords(ctx context.Context, rc *cloudflare.ResourceContainer, params cloudflare.ExportDNSRecordsParams) (string, error) {
+	client, err := c.client(rc.Identifier)
+	if err != nil {
+		return "", err
+	}
+	return client.ExportDNSRecords(ctx, rc, params)
+}
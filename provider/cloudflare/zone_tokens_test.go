@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cloudflare "github.com/cloudflare/cloudflare-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+func TestLoadZoneTokens(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("def456\n"), 0o600))
+
+	write := func(content string) string {
+		path := filepath.Join(dir, "tokens.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	tokens, err := loadZoneTokens(write("- token: abc123\n  zones: [bar.com]\n- token: file:" + tokenFile + "\n  zones: [foo.com, baz.com]\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []zoneToken{
+		{Token: "abc123", Zones: []string{"bar.com"}},
+		{Token: "def456", Zones: []string{"foo.com", "baz.com"}},
+	}, tokens)
+
+	for content, expected := range map[string]string{
+		"- token: abc123\n":                    "must have a token and zones",
+		"- zones: [bar.com]\n":                 "must have a token and zones",
+		"- token: abc123\n  zone: [bar.com]\n": "failed to parse",
+		"- token: abc\n  zones: [bar.com]\n- token: def\n  zones: [bar.com]\n": "listed several times",
+		"- token: file:/nonexistent\n  zones: [bar.com]\n":                     "failed to read the token of entry 0",
+	} {
+		_, err := loadZoneTokens(write(content))
+		assert.ErrorContains(t, err, expected, content)
+	}
+
+	_, err = loadZoneTokens(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read the Cloudflare zone tokens file")
+}
+
+func TestZoneTokenClient(t *testing.T) {
+	fallback := NewMockCloudFlareClient()
+	scoped := NewMockCloudFlareClient()
+	scoped.Zones = map[string]string{"001": "bar.com"}
+
+	client, err := newZoneTokenClient(context.Background(), fallback, []cloudFlareDNS{scoped}, [][]string{{"bar.com"}})
+	require.NoError(t, err)
+
+	zones, err := client.ListZonesContext(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []cloudflare.Zone{{ID: "001", Name: "bar.com"}, {ID: "002", Name: "foo.com"}}, zones.Result)
+
+	p := &CloudFlareProvider{Client: client}
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("new.bar.com", endpoint.RecordTypeA, "1.2.3.4"),
+			endpoint.NewEndpoint("new.foo.com", endpoint.RecordTypeA, "1.2.3.4"),
+		},
+	}))
+	require.Len(t, scoped.Actions, 1)
+	assert.Equal(t, "001", scoped.Actions[0].ZoneId)
+	require.Len(t, fallback.Actions, 1)
+	assert.Equal(t, "002", fallback.Actions[0].ZoneId)
+}
+
+func TestZoneTokenClientWithoutFallback(t *testing.T) {
+	scoped := NewMockCloudFlareClient()
+
+	client, err := newZoneTokenClient(context.Background(), nil, []cloudFlareDNS{scoped}, [][]string{{"bar.com"}})
+	require.NoError(t, err)
+
+	p := &CloudFlareProvider{Client: client, zoneIDFilter: provider.NewZoneIDFilter([]string{""})}
+	zones, err := p.Zones(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []cloudflare.Zone{{ID: "001", Name: "bar.com"}}, zones)
+
+	_, _, err = client.ListDNSRecords(context.Background(), cloudflare.ZoneIdentifier("002"), cloudflare.ListDNSRecordsParams{})
+	assert.ErrorContains(t, err, "no Cloudflare API token for zone 002")
+}
+
+func TestZoneTokenClientValidatesScopes(t *testing.T) {
+	scoped := NewMockCloudFlareClient()
+	scoped.Zones = map[string]string{"001": "bar.com"}
+
+	_, err := newZoneTokenClient(context.Background(), nil, []cloudFlareDNS{scoped}, [][]string{{"bar.com", "foo.com"}})
+	assert.ErrorContains(t, err, "cloudflare zone token 0 has no access to zone foo.com")
+
+	scoped.dnsRecordsError = errors.New("forbidden")
+	_, err = newZoneTokenClient(context.Background(), nil, []cloudFlareDNS{scoped}, [][]string{{"bar.com"}})
+	assert.ErrorContains(t, err, "cloudflare zone token 0 cannot read the records of zone bar.com: forbidden")
+
+	scoped.listZonesError = errors.New("invalid token")
+	_, err = newZoneTokenClient(context.Background(), nil, []cloudFlareDNS{scoped}, [][]string{{"bar.com"}})
+	assert.ErrorContains(t, err, "failed to list the zones of Cloudflare zone token 0: invalid token")
+}
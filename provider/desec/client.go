@@ -0,0 +1,210 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package desec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	// desecAPIEndpoint is the base URL of the deSEC API
+	desecAPIEndpoint = "https://desec.io/api/v1"
+	desecTimeout     = 30 * time.Second
+	// desecMaxRetries is the number of times a throttled request is retried, after the delay of its Retry-After header
+	desecMaxRetries = 3
+)
+
+// desecDomain is a domain of the deSEC API. The TTL of its RRsets must be at least its minimum TTL.
+type desecDomain struct {
+	Name       string `json:"name"`
+	MinimumTTL int64  `json:"minimum_ttl"`
+}
+
+// desecRRSet is an RRset of the deSEC API. Its subname is relative to the domain, empty for the apex, and its records
+// are in zone file format. An RRset without records is deleted, so Records is never omitted.
+type desecRRSet struct {
+	Subname string   `json:"subname"`
+	Type    string   `json:"type"`
+	TTL     int64    `json:"ttl,omitempty"`
+	Records []string `json:"records"`
+}
+
+// desecAPIError is the error returned by the deSEC API.
+type desecAPIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *desecAPIError) Error() string {
+	return fmt.Sprintf("deSEC API error %d: %s", e.StatusCode, e.Message)
+}
+
+// desecClient is the part of the deSEC API used by the provider, to ease testing.
+type desecClient interface {
+	ListDomains(ctx context.Context) ([]desecDomain, error)
+	ListRRSets(ctx context.Context, domain string) ([]desecRRSet, error)
+	// PatchRRSets creates, updates and deletes RRsets of a domain at once. The changes are applied atomically.
+	PatchRRSets(ctx context.Context, domain string, rrsets []desecRRSet) error
+}
+
+// httpClient calls the deSEC API, authenticated with an API token.
+type httpClient struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+func newHTTPClient(token string) *httpClient {
+	return &httpClient{
+		endpoint: desecAPIEndpoint,
+		token:    token,
+		client:   &http.Client{Timeout: desecTimeout},
+	}
+}
+
+func (c *httpClient) ListDomains(ctx context.Context) ([]desecDomain, error) {
+	var domains []desecDomain
+	err := c.list(ctx, "/domains/", func(body []byte) error {
+		var page []desecDomain
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+		domains = append(domains, page...)
+		return nil
+	})
+	return domains, err
+}
+
+func (c *httpClient) ListRRSets(ctx context.Context, domain string) ([]desecRRSet, error) {
+	var rrsets []desecRRSet
+	err := c.list(ctx, "/domains/"+url.PathEscape(domain)+"/rrsets/", func(body []byte) error {
+		var page []desecRRSet
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+		rrsets = append(rrsets, page...)
+		return nil
+	})
+	return rrsets, err
+}
+
+func (c *httpClient) PatchRRSets(ctx context.Context, domain string, rrsets []desecRRSet) error {
+	_, _, err := c.do(ctx, http.MethodPatch, c.endpoint+"/domains/"+url.PathEscape(domain)+"/rrsets/", rrsets)
+	return err
+}
+
+// list requests the pages of a collection, following the next links of the responses. The empty cursor requests the
+// first page, as collections larger than a page can't be requested at once.
+func (c *httpClient) list(ctx context.Context, path string, decode func([]byte) error) error {
+	next := c.endpoint + path + "?cursor="
+	for next != "" {
+		body, header, err := c.do(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return err
+		}
+		if err := decode(body); err != nil {
+			return err
+		}
+		next = nextLink(header.Get("Link"))
+	}
+	return nil
+}
+
+// do sends a request to the API and returns the body and the header of its response. Throttled requests are retried
+// after the delay of their Retry-After header.
+func (c *httpClient) do(ctx context.Context, method, requestURL string, body interface{}) ([]byte, http.Header, error) {
+	var reqBody []byte
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, nil, err
+		}
+		reqBody = b
+	}
+
+	for retry := 0; ; retry++ {
+		req, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewReader(reqBody))
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Header.Set("Authorization", "Token "+c.token)
+		req.Header.Set("User-Agent", provider.UserAgent())
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && retry < desecMaxRetries {
+			delay, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			select {
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			case <-time.After(time.Duration(delay) * time.Second):
+			}
+			continue
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return nil, nil, &desecAPIError{StatusCode: resp.StatusCode, Message: errorMessage(respBody)}
+		}
+		return respBody, resp.Header, nil
+	}
+}
+
+// nextLink returns the URL of the next page of a Link header, e.g. `<https://desec.io/api/v1/domains/?cursor=a>;
+// rel="next"`, or the empty string for the last page.
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+		if ok && strings.Contains(params, `rel="next"`) {
+			return strings.Trim(strings.TrimSpace(target), "<>")
+		}
+	}
+	return ""
+}
+
+// errorMessage returns the message of an error response of the API, which is either {"detail": ...} or the response
+// itself, e.g. the errors of the fields of the RRsets of a bulk request.
+func errorMessage(body []byte) string {
+	var response struct {
+		Detail string `json:"detail"`
+	}
+	if err := json.Unmarshal(body, &response); err == nil && response.Detail != "" {
+		return response.Detail
+	}
+	return string(bytes.TrimSpace(body))
+}
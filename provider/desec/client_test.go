@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package desec
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/provider"
+)
+
+func newTestHTTPClient(t *testing.T, handler func(server *httptest.Server) http.HandlerFunc) *httpClient {
+	server := httptest.NewUnstartedServer(nil)
+	server.Config.Handler = handler(server)
+	server.Start()
+	t.Cleanup(server.Close)
+	client := newHTTPClient("secret")
+	client.endpoint = server.URL
+	return client
+}
+
+func TestHTTPClientListDomains(t *testing.T) {
+	client := newTestHTTPClient(t, func(server *httptest.Server) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodGet, r.Method)
+			assert.Equal(t, "/domains/", r.URL.Path)
+			assert.Equal(t, "Token secret", r.Header.Get("Authorization"))
+			assert.Equal(t, provider.UserAgent(), r.Header.Get("User-Agent"))
+			switch r.URL.Query().Get("cursor") {
+			case "":
+				w.Header().Set("Link", `<`+server.URL+`/domains/?cursor=>; rel="first", <`+server.URL+`/domains/?cursor=p2>; rel="next"`)
+				_, _ = w.Write([]byte(`[{"name": "example.com", "minimum_ttl": 3600}]`))
+			case "p2":
+				w.Header().Set("Link", `<`+server.URL+`/domains/?cursor=>; rel="first", <`+server.URL+`/domains/?cursor=p1>; rel="prev"`)
+				_, _ = w.Write([]byte(`[{"name": "example.org", "minimum_ttl": 60}]`))
+			}
+		}
+	})
+
+	domains, err := client.ListDomains(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []desecDomain{{Name: "example.com", MinimumTTL: 3600}, {Name: "example.org", MinimumTTL: 60}}, domains)
+}
+
+func TestHTTPClientListRRSets(t *testing.T) {
+	client := newTestHTTPClient(t, func(*httptest.Server) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/domains/example.com/rrsets/", r.URL.Path)
+			_, _ = w.Write([]byte(`[
+				{"domain": "example.com", "subname": "www", "name": "www.example.com.", "type": "A", "records": ["1.2.3.4"], "ttl": 3600},
+				{"domain": "example.com", "subname": "", "name": "example.com.", "type": "TXT", "records": ["\"v=spf1 -all\""], "ttl": 3600}
+			]`))
+		}
+	})
+
+	rrsets, err := client.ListRRSets(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []desecRRSet{
+		{Subname: "www", Type: "A", TTL: 3600, Records: []string{"1.2.3.4"}},
+		{Subname: "", Type: "TXT", TTL: 3600, Records: []string{`"v=spf1 -all"`}},
+	}, rrsets)
+}
+
+func TestHTTPClientPatchRRSets(t *testing.T) {
+	var requests []string
+	client := newTestHTTPClient(t, func(*httptest.Server) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+			_, _ = w.Write([]byte(`[]`))
+		}
+	})
+
+	err := client.PatchRRSets(context.Background(), "example.com", []desecRRSet{
+		{Subname: "www", Type: "A", TTL: 3600, Records: []string{"1.2.3.4"}},
+		{Subname: "old", Type: "A", Records: []string{}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`PATCH /domains/example.com/rrsets/ [{"subname":"www","type":"A","ttl":3600,"records":["1.2.3.4"]},{"subname":"old","type":"A","records":[]}]`,
+	}, requests)
+}
+
+func TestHTTPClientThrottled(t *testing.T) {
+	calls := 0
+	client := newTestHTTPClient(t, func(*httptest.Server) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"detail": "Request was throttled. Expected available in 0 seconds."}`))
+		}
+	})
+
+	err := client.PatchRRSets(context.Background(), "example.com", nil)
+	assert.EqualError(t, err, "deSEC API error 429: Request was throttled. Expected available in 0 seconds.")
+	assert.Equal(t, desecMaxRetries+1, calls)
+}
+
+func TestHTTPClientError(t *testing.T) {
+	for body, expected := range map[string]string{
+		`{"detail": "Invalid token."}`:                                       "deSEC API error 400: Invalid token.",
+		`[{"ttl": ["Ensure this value is greater than or equal to 3600."]}]`: `deSEC API error 400: [{"ttl": ["Ensure this value is greater than or equal to 3600."]}]`,
+	} {
+		client := newTestHTTPClient(t, func(*httptest.Server) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(body))
+			}
+		})
+		_, err := client.ListRRSets(context.Background(), "example.com")
+		assert.EqualError(t, err, expected)
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	assert.Equal(t, "https://desec.io/api/v1/domains/?cursor=b", nextLink(`<https://desec.io/api/v1/domains/?cursor=>; rel="first", <https://desec.io/api/v1/domains/?cursor=b>; rel="next"`))
+	assert.Equal(t, "", nextLink(`<https://desec.io/api/v1/domains/?cursor=>; rel="first", <https://desec.io/api/v1/domains/?cursor=a>; rel="prev"`))
+	assert.Equal(t, "", nextLink(""))
+}
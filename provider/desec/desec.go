@@ -0,0 +1,279 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package desec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	// desecDefaultTTL is the TTL of the RRsets without TTL, deSEC requires one
+	desecDefaultTTL = 3600
+	// desecMaximumTTL is the largest TTL accepted by deSEC
+	desecMaximumTTL = 86400
+)
+
+// DesecProvider is an implementation of Provider for deSEC.
+type DesecProvider struct {
+	provider.BaseProvider
+	client       desecClient
+	domainFilter endpoint.DomainFilter
+	dryRun       bool
+
+	// minimumTTLs are the minimum TTLs of the domains of the last call to Records, see AdjustEndpoints
+	mutex       sync.Mutex
+	minimumTTLs map[string]int64
+}
+
+// NewDesecProvider initializes a new deSEC based Provider, authenticated with the API token in the DESEC_TOKEN
+// environment variable.
+func NewDesecProvider(domainFilter endpoint.DomainFilter, dryRun bool) (*DesecProvider, error) {
+	token, ok := os.LookupEnv("DESEC_TOKEN")
+	if !ok || token == "" {
+		return nil, errors.New("no token found, set the DESEC_TOKEN environment variable to a deSEC API token")
+	}
+	return &DesecProvider{
+		client:       newHTTPClient(token),
+		domainFilter: domainFilter,
+		dryRun:       dryRun,
+	}, nil
+}
+
+// Domains returns the domains of the account matching the domain filter.
+func (p *DesecProvider) Domains(ctx context.Context) ([]desecDomain, error) {
+	result, err := p.client.ListDomains(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domains: %w", err)
+	}
+	var domains []desecDomain
+	for _, domain := range result {
+		if !p.domainFilter.Match(domain.Name) {
+			log.Debugf("Excluding domain %s by filters", domain.Name)
+			continue
+		}
+		domains = append(domains, domain)
+	}
+	return domains, nil
+}
+
+// Records returns the RRsets of the domains. The DNSSEC RRsets, e.g. DNSKEY and CDS, which deSEC manages, are
+// ignored.
+func (p *DesecProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	domains, err := p.Domains(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []*endpoint.Endpoint
+	minimumTTLs := map[string]int64{}
+	for _, domain := range domains {
+		minimumTTLs[domain.Name] = domain.MinimumTTL
+		rrsets, err := p.client.ListRRSets(ctx, domain.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the RRsets of domain %s: %w", domain.Name, err)
+		}
+		for _, rrset := range rrsets {
+			if !supportedRecordType(rrset.Type) {
+				continue
+			}
+			targets := make([]string, len(rrset.Records))
+			for i, record := range rrset.Records {
+				targets[i] = recordTarget(rrset.Type, record)
+			}
+			endpoints = append(endpoints, endpoint.NewEndpointWithTTL(rrsetDNSName(rrset.Subname, domain.Name), rrset.Type, endpoint.TTL(rrset.TTL), targets...))
+		}
+	}
+
+	p.mutex.Lock()
+	p.minimumTTLs = minimumTTLs
+	p.mutex.Unlock()
+	return endpoints, nil
+}
+
+// AdjustEndpoints raises the TTLs lower than the minimum TTL of their domain to the minimum TTL, as deSEC rejects
+// them, and lowers the TTLs higher than the maximum TTL accepted by deSEC. Otherwise the TTLs of the RRsets would
+// never match the desired TTLs, and the RRsets would be updated on every synchronization.
+func (p *DesecProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	p.mutex.Lock()
+	minimumTTLs := p.minimumTTLs
+	p.mutex.Unlock()
+
+	for _, ep := range endpoints {
+		if !ep.RecordTTL.IsConfigured() {
+			continue
+		}
+		ttl := clampTTL(int64(ep.RecordTTL), minimumTTL(minimumTTLs, ep.DNSName))
+		if ttl != int64(ep.RecordTTL) {
+			log.Debugf("Adjusting the TTL of %s %s from %d to %d, the TTL accepted by deSEC", ep.DNSName, ep.RecordType, ep.RecordTTL, ttl)
+			ep.RecordTTL = endpoint.TTL(ttl)
+		}
+	}
+	return endpoints, nil
+}
+
+// ApplyChanges applies the changes of each domain in a single bulk request, which deSEC applies atomically.
+func (p *DesecProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	domains, err := p.Domains(ctx)
+	if err != nil {
+		return err
+	}
+	zoneIDName := provider.ZoneIDName{}
+	domainsByName := map[string]desecDomain{}
+	for _, domain := range domains {
+		zoneIDName.Add(domain.Name, domain.Name)
+		domainsByName[domain.Name] = domain
+	}
+
+	rrsetsByDomain := map[string][]desecRRSet{}
+	for _, change := range changes.RRSets() {
+		name, _ := zoneIDName.FindZone(change.Key.DNSName)
+		if name == "" {
+			log.Debugf("Skipping record %s because no domain matching record DNS Name was detected", change.Key.DNSName)
+			continue
+		}
+		domain := domainsByName[name]
+
+		rrset := desecRRSet{
+			Subname: rrsetSubname(change.Key.DNSName, domain.Name),
+			Type:    change.Key.RecordType,
+			Records: []string{},
+		}
+		if change.New != nil {
+			rrset.TTL = rrsetTTL(change.New, domain)
+			for _, target := range change.New.Targets {
+				rrset.Records = append(rrset.Records, recordValue(change.Key.RecordType, target))
+			}
+		}
+		rrsetsByDomain[domain.Name] = append(rrsetsByDomain[domain.Name], rrset)
+	}
+
+	names := make([]string, 0, len(rrsetsByDomain))
+	for name := range rrsetsByDomain {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rrsets := rrsetsByDomain[name]
+		for _, rrset := range rrsets {
+			log.WithFields(log.Fields{
+				"record":  rrset.Subname,
+				"type":    rrset.Type,
+				"ttl":     rrset.TTL,
+				"records": rrset.Records,
+				"domain":  name,
+			}).Info("Changing RRset")
+		}
+		if p.dryRun {
+			continue
+		}
+		if err := p.client.PatchRRSets(ctx, name, rrsets); err != nil {
+			return fmt.Errorf("failed to change the RRsets of domain %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// rrsetTTL returns the TTL of the RRset of an endpoint, the default TTL if the endpoint has none, within the TTLs
+// accepted for the domain.
+func rrsetTTL(ep *endpoint.Endpoint, domain desecDomain) int64 {
+	ttl := int64(desecDefaultTTL)
+	if ep.RecordTTL.IsConfigured() {
+		ttl = int64(ep.RecordTTL)
+	}
+	return clampTTL(ttl, domain.MinimumTTL)
+}
+
+// clampTTL returns the TTL within the minimum TTL of a domain and the maximum TTL of deSEC.
+func clampTTL(ttl, minimum int64) int64 {
+	if ttl < minimum {
+		return minimum
+	}
+	if ttl > desecMaximumTTL {
+		return desecMaximumTTL
+	}
+	return ttl
+}
+
+// minimumTTL returns the minimum TTL of the closest domain of a DNS name, 0 if the domain is unknown.
+func minimumTTL(minimumTTLs map[string]int64, dnsName string) int64 {
+	for name := strings.TrimSuffix(dnsName, "."); name != ""; {
+		if ttl, ok := minimumTTLs[name]; ok {
+			return ttl
+		}
+		_, name, _ = strings.Cut(name, ".")
+	}
+	return 0
+}
+
+// supportedRecordType returns true for the record types managed by the provider.
+func supportedRecordType(recordType string) bool {
+	return provider.SupportedRecordType(recordType) || recordType == endpoint.RecordTypeMX
+}
+
+// rrsetSubname returns the subname of an RRset relative to its domain, empty for the apex.
+func rrsetSubname(dnsName, domainName string) string {
+	dnsName = strings.TrimSuffix(dnsName, ".")
+	if dnsName == domainName {
+		return ""
+	}
+	return strings.TrimSuffix(dnsName, "."+domainName)
+}
+
+// rrsetDNSName returns the DNS name of an RRset of a domain.
+func rrsetDNSName(subname, domainName string) string {
+	if subname == "" {
+		return domainName
+	}
+	return subname + "." + domainName
+}
+
+// recordValue returns the record of a target in zone file format. Hostnames are fully qualified, and TXT records
+// quoted and split into character strings.
+func recordValue(recordType, target string) string {
+	switch recordType {
+	case endpoint.RecordTypeCNAME, endpoint.RecordTypeNS, endpoint.RecordTypeMX, endpoint.RecordTypeSRV:
+		return provider.EnsureTrailingDot(target)
+	case endpoint.RecordTypeTXT:
+		return endpoint.ParseTXTTarget(target).Chunked().String()
+	default:
+		return target
+	}
+}
+
+// recordTarget returns the target of a record, see recordValue.
+func recordTarget(recordType, value string) string {
+	switch recordType {
+	case endpoint.RecordTypeCNAME, endpoint.RecordTypeNS, endpoint.RecordTypeMX, endpoint.RecordTypeSRV:
+		return strings.TrimSuffix(value, ".")
+	case endpoint.RecordTypeTXT:
+		return endpoint.ParseTXTTarget(value).Text()
+	default:
+		return value
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package desec
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// fakeClient keeps the domains and RRsets in memory.
+type fakeClient struct {
+	domains []desecDomain
+	rrsets  map[string][]desecRRSet
+	// patches are the RRsets of the bulk requests, by domain
+	patches map[string][][]desecRRSet
+	err     error
+}
+
+func (c *fakeClient) ListDomains(context.Context) ([]desecDomain, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.domains, nil
+}
+
+func (c *fakeClient) ListRRSets(_ context.Context, domain string) ([]desecRRSet, error) {
+	return c.rrsets[domain], nil
+}
+
+func (c *fakeClient) PatchRRSets(_ context.Context, domain string, rrsets []desecRRSet) error {
+	if c.patches == nil {
+		c.patches = map[string][][]desecRRSet{}
+	}
+	c.patches[domain] = append(c.patches[domain], rrsets)
+	return nil
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		domains: []desecDomain{
+			{Name: "example.com", MinimumTTL: 3600},
+			{Name: "example.org", MinimumTTL: 60},
+		},
+		rrsets: map[string][]desecRRSet{
+			"example.com": {
+				{Subname: "", Type: "A", TTL: 3600, Records: []string{"1.2.3.4"}},
+				{Subname: "", Type: "NS", TTL: 3600, Records: []string{"ns1.desec.io.", "ns2.desec.org."}},
+				{Subname: "", Type: "DNSKEY", TTL: 3600, Records: []string{"257 3 13 aGVsbG8="}},
+				{Subname: "", Type: "CDS", TTL: 3600, Records: []string{"12345 13 2 abcdef"}},
+				{Subname: "www", Type: "CNAME", TTL: 7200, Records: []string{"example.com."}},
+				{Subname: "txt", Type: "TXT", TTL: 3600, Records: []string{`"heritage=external-dns,external-dns/owner=default"`}},
+			},
+			"example.org": {
+				{Subname: "", Type: "MX", TTL: 60, Records: []string{"10 mail.example.org."}},
+			},
+		},
+	}
+}
+
+func TestDesecRecords(t *testing.T) {
+	p := &DesecProvider{client: newFakeClient()}
+
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("example.com", endpoint.RecordTypeA, 3600, "1.2.3.4"),
+		endpoint.NewEndpointWithTTL("example.com", endpoint.RecordTypeNS, 3600, "ns1.desec.io", "ns2.desec.org"),
+		endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeCNAME, 7200, "example.com"),
+		endpoint.NewEndpointWithTTL("txt.example.com", endpoint.RecordTypeTXT, 3600, "heritage=external-dns,external-dns/owner=default"),
+		endpoint.NewEndpointWithTTL("example.org", endpoint.RecordTypeMX, 60, "10 mail.example.org"),
+	}, records)
+
+	p = &DesecProvider{client: newFakeClient(), domainFilter: endpoint.NewDomainFilter([]string{"example.org"})}
+	records, err = p.Records(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	p = &DesecProvider{client: &fakeClient{err: errors.New("Invalid token.")}}
+	_, err = p.Records(context.Background())
+	assert.EqualError(t, err, "failed to list domains: Invalid token.")
+}
+
+func TestDesecAdjustEndpoints(t *testing.T) {
+	p := &DesecProvider{client: newFakeClient()}
+	_, err := p.Records(context.Background())
+	require.NoError(t, err)
+
+	endpoints, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("app.example.com", endpoint.RecordTypeA, 60, "1.2.3.4"),
+		endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeA, 60, "1.2.3.4"),
+		endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeAAAA, 604800, "2001:db8::1"),
+		endpoint.NewEndpoint("other.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpointWithTTL("app.example.net", endpoint.RecordTypeA, 60, "1.2.3.4"),
+	})
+	require.NoError(t, err)
+	ttls := make([]endpoint.TTL, len(endpoints))
+	for i, ep := range endpoints {
+		ttls[i] = ep.RecordTTL
+	}
+	// the TTLs are raised to the minimum TTL of their domain, and lowered to the maximum TTL of deSEC
+	assert.Equal(t, []endpoint.TTL{3600, 60, desecMaximumTTL, 0, 60}, ttls)
+}
+
+func TestDesecApplyChanges(t *testing.T) {
+	client := newFakeClient()
+	p := &DesecProvider{client: client}
+
+	err := p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeCNAME, 120, "lb.example.net"),
+			endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeTXT, `"`+strings.Repeat("a", 300)+`"`),
+			endpoint.NewEndpoint("app.example.net", endpoint.RecordTypeA, "5.5.5.5"),
+		},
+		UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeCNAME, 7200, "example.com")},
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeCNAME, 60, "app.example.com")},
+		Delete:    []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("example.com", endpoint.RecordTypeA, 3600, "1.2.3.4")},
+	})
+	require.NoError(t, err)
+
+	// the changes of a domain are sent at once, and the domain of example.net doesn't exist
+	assert.Equal(t, map[string][][]desecRRSet{
+		"example.com": {{
+			{Subname: "", Type: "A", Records: []string{}},
+			{Subname: "www", Type: "CNAME", TTL: 3600, Records: []string{"app.example.com."}},
+			{Subname: "app", Type: "TXT", TTL: 3600, Records: []string{`"` + strings.Repeat("a", 255) + `" "` + strings.Repeat("a", 45) + `"`}},
+		}},
+		"example.org": {{
+			{Subname: "app", Type: "CNAME", TTL: 120, Records: []string{"lb.example.net."}},
+		}},
+	}, client.patches)
+}
+
+func TestDesecApplyChangesDryRun(t *testing.T) {
+	client := newFakeClient()
+	p := &DesecProvider{client: client, dryRun: true}
+
+	err := p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "5.5.5.5")},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, client.patches)
+}
+
+func TestNewDesecProvider(t *testing.T) {
+	t.Setenv("DESEC_TOKEN", "")
+	_, err := NewDesecProvider(endpoint.DomainFilter{}, false)
+	assert.EqualError(t, err, "no token found, set the DESEC_TOKEN environment variable to a deSEC API token")
+
+	t.Setenv("DESEC_TOKEN", "secret")
+	p, err := NewDesecProvider(endpoint.DomainFilter{}, true)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", p.client.(*httpClient).token)
+}
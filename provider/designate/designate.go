@@ -147,7 +147,7 @@ func createDesignateServiceClient() (*gophercloud.ServiceClient, error) {
 		ExpectContinueTimeout: 1 * time.Second,
 		TLSClientConfig:       tlsConfig,
 	}
-	authProvider.HTTPClient.Transport = transport
+	authProvider.HTTPClient.Transport = provider.NewUserAgentTransport(transport)
 
 	if err = openstack.Authenticate(authProvider, opts); err != nil {
 		return nil, err
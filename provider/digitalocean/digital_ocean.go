@@ -19,8 +19,8 @@ package digitalocean
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
-	"strconv"
 	"strings"
 
 	"github.com/digitalocean/godo"
@@ -28,7 +28,6 @@ import (
 	"golang.org/x/oauth2"
 
 	"sigs.k8s.io/external-dns/endpoint"
-	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
 	"sigs.k8s.io/external-dns/plan"
 	"sigs.k8s.io/external-dns/provider"
 )
@@ -82,10 +81,11 @@ func NewDigitalOceanProvider(ctx context.Context, domainFilter endpoint.DomainFi
 	if !ok {
 		return nil, fmt.Errorf("no token found")
 	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: provider.NewQuotaTransport("digitalocean", provider.NewAPICallCounter(nil))})
 	oauthClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{
 		AccessToken: token,
 	}))
-	client, err := godo.New(oauthClient, godo.SetUserAgent("ExternalDNS/"+externaldns.Version))
+	client, err := godo.New(oauthClient, godo.SetUserAgent(provider.UserAgent()))
 	if err != nil {
 		return nil, err
 	}
@@ -302,13 +302,12 @@ func makeDomainEditRequest(domain, name, recordType, data string, ttl int) *godo
 	}
 
 	if recordType == endpoint.RecordTypeMX {
-		priority, domain, err := parseMxTarget(data)
+		mx, err := endpoint.ParseMXTarget(data)
 		if err == nil {
-			request.Priority = int(priority)
-			request.Data = provider.EnsureTrailingDot(domain)
+			request.Priority = int(mx.Preference)
+			request.Data = provider.EnsureTrailingDot(mx.Host)
 		} else {
 			log.WithFields(log.Fields{
-				"domain":     domain,
 				"dnsName":    name,
 				"recordType": recordType,
 				"data":       data,
@@ -661,18 +660,3 @@ func (p *DigitalOceanProvider) ApplyChanges(ctx context.Context, planChanges *pl
 
 	return p.submitChanges(ctx, &changes)
 }
-
-func parseMxTarget(mxTarget string) (priority int64, exchange string, err error) {
-	targetParts := strings.SplitN(mxTarget, " ", 2)
-	if len(targetParts) != 2 {
-		return priority, exchange, fmt.Errorf("mx target needs to be of form '10 example.com'")
-	}
-
-	priorityRaw, exchange := targetParts[0], targetParts[1]
-	priority, err = strconv.ParseInt(priorityRaw, 10, 32)
-	if err != nil {
-		return priority, exchange, fmt.Errorf("invalid priority specified")
-	}
-
-	return priority, exchange, nil
-}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dnsimple
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/dnsimple/dnsimple-go/dnsimple"
+	log "github.com/sirupsen/logrus"
+)
+
+// dnsimpleBatch is the body of a request of the batch change API of DNSimple, which creates, updates and deletes
+// records of a zone in a single request.
+type dnsimpleBatch struct {
+	Creates []dnsimple.ZoneRecordAttributes `json:"creates,omitempty"`
+	Updates []dnsimpleBatchUpdate           `json:"updates,omitempty"`
+	Deletes []dnsimpleBatchDelete           `json:"deletes,omitempty"`
+}
+
+type dnsimpleBatchUpdate struct {
+	ID int64 `json:"id"`
+	dnsimple.ZoneRecordAttributes
+}
+
+type dnsimpleBatchDelete struct {
+	ID int64 `json:"id"`
+}
+
+func (b *dnsimpleBatch) len() int {
+	return len(b.Creates) + len(b.Updates) + len(b.Deletes)
+}
+
+// BatchChangeRecords submits a batch of changes of the records of a zone.
+func (z dnsimpleZoneService) BatchChangeRecords(ctx context.Context, accountID string, zoneID string, batch dnsimpleBatch) error {
+	z.limiter.Take()
+	_, err := z.client.Request(ctx, http.MethodPost, fmt.Sprintf("/v2/%s/zones/%s/batch", accountID, zoneID), batch, nil, nil)
+	return err
+}
+
+// dnsimpleRecordKey identifies the records of a zone by name and type.
+type dnsimpleRecordKey struct {
+	name, recordType string
+}
+
+// listZoneRecords lists the records of the zone by name and type.
+func (p *dnsimpleProvider) listZoneRecords(ctx context.Context, zone string) (map[dnsimpleRecordKey][]dnsimple.ZoneRecord, error) {
+	records := map[dnsimpleRecordKey][]dnsimple.ZoneRecord{}
+	page := 1
+	listOptions := &dnsimple.ZoneRecordListOptions{}
+	for {
+		listOptions.Page = &page
+		response, err := p.client.ListRecords(ctx, p.accountID, zone, listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range response.Data {
+			key := dnsimpleRecordKey{record.Name, record.Type}
+			records[key] = append(records[key], record)
+		}
+		page++
+		if page > response.Pagination.TotalPages {
+			return records, nil
+		}
+	}
+}
+
+// findRecord returns the record of the name and type of the attributes with the given content.
+func findRecord(records map[dnsimpleRecordKey][]dnsimple.ZoneRecord, attributes dnsimple.ZoneRecordAttributes, content string) (dnsimple.ZoneRecord, bool) {
+	for _, record := range records[dnsimpleRecordKey{*attributes.Name, attributes.Type}] {
+		if record.Content == content {
+			return record, true
+		}
+	}
+	return dnsimple.ZoneRecord{}, false
+}
+
+// submitBatches submits the changes of each zone with the batch change API, in batches of at most batchSize
+// changes, instead of one request per record. The records to update and delete are looked up in a single listing of
+// their zone.
+func (p *dnsimpleProvider) submitBatches(ctx context.Context, changes []*dnsimpleChange, zones map[string]dnsimple.Zone) error {
+	changesByZone := map[string][]*dnsimpleChange{}
+	var zoneNames []string
+	for _, change := range changes {
+		zone := dnsimpleSuitableZone(change.ResourceRecordSet.Name, zones)
+		if zone == nil {
+			log.Debugf("Skipping record %s because no hosted zone matching record DNS Name was detected", change.ResourceRecordSet.Name)
+			continue
+		}
+		if _, ok := changesByZone[zone.Name]; !ok {
+			zoneNames = append(zoneNames, zone.Name)
+		}
+		changesByZone[zone.Name] = append(changesByZone[zone.Name], change)
+	}
+
+	for _, zone := range zoneNames {
+		var records map[dnsimpleRecordKey][]dnsimple.ZoneRecord
+		batches := []*dnsimpleBatch{{}}
+		for _, change := range changesByZone[zone] {
+			log.Infof("Changing records: %s %v in zone: %s", change.Action, change.ResourceRecordSet, zone)
+			attributes := p.recordAttributes(change, zone)
+
+			if change.Action != dnsimpleCreate && records == nil {
+				var err error
+				if records, err = p.listZoneRecords(ctx, zone); err != nil {
+					return err
+				}
+			}
+			batch := batches[len(batches)-1]
+			if batch.len() >= p.batchSize {
+				batch = &dnsimpleBatch{}
+				batches = append(batches, batch)
+			}
+			switch change.Action {
+			case dnsimpleCreate:
+				batch.Creates = append(batch.Creates, attributes)
+			case dnsimpleUpdate, dnsimpleDelete:
+				record, ok := findRecord(records, attributes, change.CurrentContent)
+				if !ok {
+					return fmt.Errorf("no record id found for %s %s with content %q in zone %s", attributes.Type, *attributes.Name, change.CurrentContent, zone)
+				}
+				if change.Action == dnsimpleUpdate {
+					batch.Updates = append(batch.Updates, dnsimpleBatchUpdate{ID: record.ID, ZoneRecordAttributes: attributes})
+				} else {
+					batch.Deletes = append(batch.Deletes, dnsimpleBatchDelete{ID: record.ID})
+				}
+			}
+		}
+
+		if p.dryRun {
+			continue
+		}
+		for _, batch := range batches {
+			if batch.len() == 0 {
+				continue
+			}
+			if err := p.client.BatchChangeRecords(ctx, p.accountID, zone, *batch); err != nil {
+				return fmt.Errorf("failed to submit a batch of %d changes of zone %s: %w", batch.len(), zone, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dnsimple
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dnsimple/dnsimple-go/dnsimple"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/ratelimit"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func TestDnsimpleApplyChangesBatches(t *testing.T) {
+	mockDNS := &mockDnsimpleZoneServiceInterface{}
+	mockDNS.On("ListZones", mock.Anything, "1", mock.Anything).Return(&dnsimple.ZonesResponse{
+		Response: dnsimple.Response{Pagination: &dnsimple.Pagination{TotalPages: 1}},
+		Data:     []dnsimple.Zone{{ID: 1, Name: "example.com"}, {ID: 2, Name: "example.org"}},
+	}, nil)
+	mockDNS.On("ListRecords", mock.Anything, "1", "example.com", mock.Anything).Return(&dnsimple.ZoneRecordsResponse{
+		Response: dnsimple.Response{Pagination: &dnsimple.Pagination{TotalPages: 1}},
+		Data: []dnsimple.ZoneRecord{
+			{ID: 11, Name: "", Type: "A", Content: "1.2.3.4"},
+			{ID: 12, Name: "api", Type: "A", Content: "1.1.1.1"},
+			{ID: 13, Name: "api", Type: "A", Content: "2.2.2.2"},
+		},
+	}, nil).Once()
+	mockDNS.On("BatchChangeRecords", mock.Anything, "1", mock.Anything, mock.Anything).Return(nil)
+
+	p := &dnsimpleProvider{client: mockDNS, accountID: "1", batchSize: 2}
+	err := p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("web.example.com", endpoint.RecordTypeA, "5.6.7.8"),
+			endpoint.NewEndpointWithTTL("www.example.org", endpoint.RecordTypeCNAME, 60, "example.org"),
+		},
+		UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "1.2.3.4")},
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "4.3.2.1")},
+		Delete:    []*endpoint.Endpoint{endpoint.NewEndpoint("api.example.com", endpoint.RecordTypeA, "2.2.2.2")},
+	})
+	require.NoError(t, err)
+
+	name := func(s string) *string { return &s }
+	// the records of example.com are listed once, and its changes are submitted in batches of two
+	mockDNS.AssertNumberOfCalls(t, "ListRecords", 1)
+	mockDNS.AssertCalled(t, "BatchChangeRecords", mock.Anything, "1", "example.com", dnsimpleBatch{
+		Creates: []dnsimple.ZoneRecordAttributes{{Name: name("web"), Type: "A", Content: "5.6.7.8", TTL: dnsimpleRecordTTL}},
+		Updates: []dnsimpleBatchUpdate{{ID: 11, ZoneRecordAttributes: dnsimple.ZoneRecordAttributes{Name: name(""), Type: "A", Content: "4.3.2.1", TTL: dnsimpleRecordTTL}}},
+	})
+	mockDNS.AssertCalled(t, "BatchChangeRecords", mock.Anything, "1", "example.com", dnsimpleBatch{
+		Deletes: []dnsimpleBatchDelete{{ID: 13}},
+	})
+	mockDNS.AssertCalled(t, "BatchChangeRecords", mock.Anything, "1", "example.org", dnsimpleBatch{
+		Creates: []dnsimple.ZoneRecordAttributes{{Name: name("www"), Type: "CNAME", Content: "example.org", TTL: 60}},
+	})
+	mockDNS.AssertNumberOfCalls(t, "BatchChangeRecords", 3)
+	mockDNS.AssertNotCalled(t, "CreateRecord", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// a record to delete must exist
+	mockDNS.On("ListRecords", mock.Anything, "1", "example.org", mock.Anything).Return(&dnsimple.ZoneRecordsResponse{
+		Response: dnsimple.Response{Pagination: &dnsimple.Pagination{TotalPages: 1}},
+	}, nil)
+	err = p.ApplyChanges(context.Background(), &plan.Changes{
+		Delete: []*endpoint.Endpoint{endpoint.NewEndpoint("old.example.org", endpoint.RecordTypeA, "2.2.2.2")},
+	})
+	assert.EqualError(t, err, `no record id found for A old with content "2.2.2.2" in zone example.org`)
+
+	// a record to update must have the current content, not only the name and type
+	mockDNS.On("ListRecords", mock.Anything, "1", "example.com", mock.Anything).Return(&dnsimple.ZoneRecordsResponse{
+		Response: dnsimple.Response{Pagination: &dnsimple.Pagination{TotalPages: 1}},
+		Data:     []dnsimple.ZoneRecord{{ID: 12, Name: "api", Type: "A", Content: "1.1.1.1"}},
+	}, nil)
+	err = p.ApplyChanges(context.Background(), &plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpoint("api.example.com", endpoint.RecordTypeA, "2.2.2.2")},
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpoint("api.example.com", endpoint.RecordTypeA, "3.3.3.3")},
+	})
+	assert.EqualError(t, err, `no record id found for A api with content "2.2.2.2" in zone example.com`)
+	mockDNS.AssertNumberOfCalls(t, "BatchChangeRecords", 3)
+}
+
+func TestDnsimpleApplyChangesBatchesDryRun(t *testing.T) {
+	mockDNS := &mockDnsimpleZoneServiceInterface{}
+	mockDNS.On("ListZones", mock.Anything, "1", mock.Anything).Return(&dnsimple.ZonesResponse{
+		Response: dnsimple.Response{Pagination: &dnsimple.Pagination{TotalPages: 1}},
+		Data:     []dnsimple.Zone{{ID: 1, Name: "example.com"}},
+	}, nil)
+
+	p := &dnsimpleProvider{client: mockDNS, accountID: "1", batchSize: 100, dryRun: true}
+	err := p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("web.example.com", endpoint.RecordTypeA, "5.6.7.8")},
+	})
+	require.NoError(t, err)
+	mockDNS.AssertNotCalled(t, "BatchChangeRecords", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDnsimpleBatchChangeRecords(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v2/1010/zones/example.com/batch", r.URL.Path)
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"creates": [{"name": "web", "type": "A", "content": "5.6.7.8", "ttl": 3600}],
+			"updates": [{"id": 11, "name": "", "type": "A", "content": "4.3.2.1", "ttl": 60}],
+			"deletes": [{"id": 13}]
+		}`, string(body))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": {}}`))
+	}))
+	defer server.Close()
+
+	client := dnsimple.NewClient(server.Client())
+	client.BaseURL = server.URL
+	service := dnsimpleZoneService{service: client.Zones, client: client, limiter: ratelimit.NewUnlimited()}
+
+	web, apex := "web", ""
+	err := service.BatchChangeRecords(context.Background(), "1010", "example.com", dnsimpleBatch{
+		Creates: []dnsimple.ZoneRecordAttributes{{Name: &web, Type: "A", Content: "5.6.7.8", TTL: 3600}},
+		Updates: []dnsimpleBatchUpdate{{ID: 11, ZoneRecordAttributes: dnsimple.ZoneRecordAttributes{Name: &apex, Type: "A", Content: "4.3.2.1", TTL: 60}}},
+		Deletes: []dnsimpleBatchDelete{{ID: 13}},
+	})
+	require.NoError(t, err)
+}
@@ -19,16 +19,18 @@ package dnsimple
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dnsimple/dnsimple-go/dnsimple"
 	log "github.com/sirupsen/logrus"
+	"go.uber.org/ratelimit"
 	"golang.org/x/oauth2"
 
 	"sigs.k8s.io/external-dns/endpoint"
-	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
 	"sigs.k8s.io/external-dns/plan"
 	"sigs.k8s.io/external-dns/provider"
 )
@@ -50,29 +52,38 @@ type dnsimpleZoneServiceInterface interface {
 	CreateRecord(ctx context.Context, accountID string, zoneID string, recordAttributes dnsimple.ZoneRecordAttributes) (*dnsimple.ZoneRecordResponse, error)
 	DeleteRecord(ctx context.Context, accountID string, zoneID string, recordID int64) (*dnsimple.ZoneRecordResponse, error)
 	UpdateRecord(ctx context.Context, accountID string, zoneID string, recordID int64, recordAttributes dnsimple.ZoneRecordAttributes) (*dnsimple.ZoneRecordResponse, error)
+	BatchChangeRecords(ctx context.Context, accountID string, zoneID string, batch dnsimpleBatch) error
 }
 
 type dnsimpleZoneService struct {
 	service *dnsimple.ZonesService
+	client  *dnsimple.Client
+	// limiter paces the requests to the API
+	limiter ratelimit.Limiter
 }
 
 func (z dnsimpleZoneService) ListZones(ctx context.Context, accountID string, options *dnsimple.ZoneListOptions) (*dnsimple.ZonesResponse, error) {
+	z.limiter.Take()
 	return z.service.ListZones(ctx, accountID, options)
 }
 
 func (z dnsimpleZoneService) ListRecords(ctx context.Context, accountID string, zoneID string, options *dnsimple.ZoneRecordListOptions) (*dnsimple.ZoneRecordsResponse, error) {
+	z.limiter.Take()
 	return z.service.ListRecords(ctx, accountID, zoneID, options)
 }
 
 func (z dnsimpleZoneService) CreateRecord(ctx context.Context, accountID string, zoneID string, recordAttributes dnsimple.ZoneRecordAttributes) (*dnsimple.ZoneRecordResponse, error) {
+	z.limiter.Take()
 	return z.service.CreateRecord(ctx, accountID, zoneID, recordAttributes)
 }
 
 func (z dnsimpleZoneService) DeleteRecord(ctx context.Context, accountID string, zoneID string, recordID int64) (*dnsimple.ZoneRecordResponse, error) {
+	z.limiter.Take()
 	return z.service.DeleteRecord(ctx, accountID, zoneID, recordID)
 }
 
 func (z dnsimpleZoneService) UpdateRecord(ctx context.Context, accountID string, zoneID string, recordID int64, recordAttributes dnsimple.ZoneRecordAttributes) (*dnsimple.ZoneRecordResponse, error) {
+	z.limiter.Take()
 	return z.service.UpdateRecord(ctx, accountID, zoneID, recordID, recordAttributes)
 }
 
@@ -84,11 +95,17 @@ type dnsimpleProvider struct {
 	domainFilter endpoint.DomainFilter
 	zoneIDFilter provider.ZoneIDFilter
 	dryRun       bool
+	// batchSize is the maximum number of changes of a zone submitted in a request of the batch change API, 0
+	// submitting one request per record
+	batchSize int
 }
 
 type dnsimpleChange struct {
 	Action            string
 	ResourceRecordSet dnsimple.ZoneRecord
+	// CurrentContent is the content of the record to update or delete, identifying it among the records of
+	// the same name and type.
+	CurrentContent string
 }
 
 const (
@@ -97,25 +114,34 @@ const (
 	dnsimpleUpdate = "UPDATE"
 )
 
-// NewDnsimpleProvider initializes a new Dnsimple based provider
-func NewDnsimpleProvider(domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, dryRun bool) (provider.Provider, error) {
+// NewDnsimpleProvider initializes a new Dnsimple based provider. The changes are submitted with the batch change API
+// in batches of batchSize changes if it is positive, and the requests are limited to apiRateLimit per second if it is
+// positive.
+func NewDnsimpleProvider(domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, dryRun bool, batchSize int, apiRateLimit float64) (provider.Provider, error) {
 	oauthToken := os.Getenv("DNSIMPLE_OAUTH")
 	if len(oauthToken) == 0 {
 		return nil, fmt.Errorf("no dnsimple oauth token provided")
 	}
 
 	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: oauthToken})
-	tc := oauth2.NewClient(context.Background(), ts)
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: provider.NewAPICallCounter(nil)})
+	tc := oauth2.NewClient(ctx, ts)
 
 	client := dnsimple.NewClient(tc)
-	client.SetUserAgent(fmt.Sprintf("Kubernetes ExternalDNS/%s", externaldns.Version))
+	client.SetUserAgent(provider.UserAgent())
+
+	limiter := ratelimit.NewUnlimited()
+	if apiRateLimit > 0 {
+		limiter = ratelimit.New(1, ratelimit.Per(time.Duration(float64(time.Second)/apiRateLimit)))
+	}
 
 	provider := &dnsimpleProvider{
-		client:       dnsimpleZoneService{service: client.Zones},
+		client:       dnsimpleZoneService{service: client.Zones, client: client, limiter: limiter},
 		identity:     dnsimpleIdentityService{service: client.Identity},
 		domainFilter: domainFilter,
 		zoneIDFilter: zoneIDFilter,
 		dryRun:       dryRun,
+		batchSize:    batchSize,
 	}
 
 	provider.accountID = os.Getenv("DNSIMPLE_ACCOUNT_ID")
@@ -244,10 +270,22 @@ func newDnsimpleChange(action string, e *endpoint.Endpoint) *dnsimpleChange {
 			Content: e.Targets[0],
 			TTL:     ttl,
 		},
+		CurrentContent: e.Targets[0],
 	}
 	return change
 }
 
+// newDnsimpleUpdateChanges returns the changes updating the current records to the desired ones.
+func newDnsimpleUpdateChanges(current, desired []*endpoint.Endpoint) []*dnsimpleChange {
+	changes := make([]*dnsimpleChange, 0, len(desired))
+	for i, e := range desired {
+		change := newDnsimpleChange(dnsimpleUpdate, e)
+		change.CurrentContent = current[i].Targets[0]
+		changes = append(changes, change)
+	}
+	return changes
+}
+
 // newDnsimpleChanges returns a slice of changes based on given action and record
 func newDnsimpleChanges(action string, endpoints []*endpoint.Endpoint) []*dnsimpleChange {
 	changes := make([]*dnsimpleChange, 0, len(endpoints))
@@ -267,6 +305,9 @@ func (p *dnsimpleProvider) submitChanges(ctx context.Context, changes []*dnsimpl
 	if err != nil {
 		return err
 	}
+	if p.batchSize > 0 {
+		return p.submitBatches(ctx, changes, zones)
+	}
 	for _, change := range changes {
 		zone := dnsimpleSuitableZone(change.ResourceRecordSet.Name, zones)
 		if zone == nil {
@@ -276,18 +317,7 @@ func (p *dnsimpleProvider) submitChanges(ctx context.Context, changes []*dnsimpl
 
 		log.Infof("Changing records: %s %v in zone: %s", change.Action, change.ResourceRecordSet, zone.Name)
 
-		if change.ResourceRecordSet.Name == zone.Name {
-			change.ResourceRecordSet.Name = "" // Apex records have an empty name
-		} else {
-			change.ResourceRecordSet.Name = strings.TrimSuffix(change.ResourceRecordSet.Name, fmt.Sprintf(".%s", zone.Name))
-		}
-
-		recordAttributes := dnsimple.ZoneRecordAttributes{
-			Name:    &change.ResourceRecordSet.Name,
-			Type:    change.ResourceRecordSet.Type,
-			Content: change.ResourceRecordSet.Content,
-			TTL:     change.ResourceRecordSet.TTL,
-		}
+		recordAttributes := p.recordAttributes(change, zone.Name)
 
 		if !p.dryRun {
 			switch change.Action {
@@ -320,6 +350,22 @@ func (p *dnsimpleProvider) submitChanges(ctx context.Context, changes []*dnsimpl
 	return nil
 }
 
+// recordAttributes returns the attributes of the record of the change, named relative to its zone.
+func (p *dnsimpleProvider) recordAttributes(change *dnsimpleChange, zone string) dnsimple.ZoneRecordAttributes {
+	if change.ResourceRecordSet.Name == zone {
+		change.ResourceRecordSet.Name = "" // Apex records have an empty name
+	} else {
+		change.ResourceRecordSet.Name = strings.TrimSuffix(change.ResourceRecordSet.Name, fmt.Sprintf(".%s", zone))
+	}
+
+	return dnsimple.ZoneRecordAttributes{
+		Name:    &change.ResourceRecordSet.Name,
+		Type:    change.ResourceRecordSet.Type,
+		Content: change.ResourceRecordSet.Content,
+		TTL:     change.ResourceRecordSet.TTL,
+	}
+}
+
 // GetRecordID returns the record ID for a given record name and zone.
 func (p *dnsimpleProvider) GetRecordID(ctx context.Context, zone string, recordName string) (recordID int64, err error) {
 	page := 1
@@ -364,7 +410,7 @@ func (p *dnsimpleProvider) ApplyChanges(ctx context.Context, changes *plan.Chang
 	combinedChanges := make([]*dnsimpleChange, 0, len(changes.Create)+len(changes.UpdateNew)+len(changes.Delete))
 
 	combinedChanges = append(combinedChanges, newDnsimpleChanges(dnsimpleCreate, changes.Create)...)
-	combinedChanges = append(combinedChanges, newDnsimpleChanges(dnsimpleUpdate, changes.UpdateNew)...)
+	combinedChanges = append(combinedChanges, newDnsimpleUpdateChanges(changes.UpdateOld, changes.UpdateNew)...)
 	combinedChanges = append(combinedChanges, newDnsimpleChanges(dnsimpleDelete, changes.Delete)...)
 
 	return p.submitChanges(ctx, combinedChanges)
@@ -198,6 +198,7 @@ func testDnsimpleProviderApplyChanges(t *testing.T) {
 		{DNSName: "example.example.com", Targets: endpoint.Targets{"target"}, RecordType: endpoint.RecordTypeCNAME},
 		{DNSName: "example.com", Targets: endpoint.Targets{"127.0.0.1"}, RecordType: endpoint.RecordTypeA},
 	}
+	changes.UpdateOld = changes.UpdateNew
 
 	mockProvider.accountID = "1"
 	err := mockProvider.ApplyChanges(context.Background(), changes)
@@ -242,20 +243,20 @@ func testDnsimpleSuitableZone(t *testing.T) {
 
 func TestNewDnsimpleProvider(t *testing.T) {
 	os.Setenv("DNSIMPLE_OAUTH", "xxxxxxxxxxxxxxxxxxxxxxxxxx")
-	_, err := NewDnsimpleProvider(endpoint.NewDomainFilter([]string{"example.com"}), provider.NewZoneIDFilter([]string{""}), true)
+	_, err := NewDnsimpleProvider(endpoint.NewDomainFilter([]string{"example.com"}), provider.NewZoneIDFilter([]string{""}), true, 0, 0)
 	if err == nil {
 		t.Errorf("Expected to fail new provider on bad token")
 	}
 
 	os.Unsetenv("DNSIMPLE_OAUTH")
-	_, err = NewDnsimpleProvider(endpoint.NewDomainFilter([]string{"example.com"}), provider.NewZoneIDFilter([]string{""}), true)
+	_, err = NewDnsimpleProvider(endpoint.NewDomainFilter([]string{"example.com"}), provider.NewZoneIDFilter([]string{""}), true, 0, 0)
 	if err == nil {
 		t.Errorf("Expected to fail new provider on empty token")
 	}
 
 	os.Setenv("DNSIMPLE_OAUTH", "xxxxxxxxxxxxxxxxxxxxxxxxxx")
 	os.Setenv("DNSIMPLE_ACCOUNT_ID", "12345678")
-	providerTypedProvider, err := NewDnsimpleProvider(endpoint.NewDomainFilter([]string{"example.com"}), provider.NewZoneIDFilter([]string{""}), true)
+	providerTypedProvider, err := NewDnsimpleProvider(endpoint.NewDomainFilter([]string{"example.com"}), provider.NewZoneIDFilter([]string{""}), true, 0, 0)
 	dnsimpleTypedProvider := providerTypedProvider.(*dnsimpleProvider)
 	if err != nil {
 		t.Errorf("Unexpected error thrown when testing NewDnsimpleProvider with the DNSIMPLE_ACCOUNT_ID environment variable set")
@@ -345,3 +346,8 @@ func (_m *mockDnsimpleZoneServiceInterface) UpdateRecord(ctx context.Context, ac
 
 	return r0, args.Error(1)
 }
+
+func (_m *mockDnsimpleZoneServiceInterface) BatchChangeRecords(ctx context.Context, accountID string, zoneID string, batch dnsimpleBatch) error {
+	args := _m.Called(ctx, accountID, zoneID, batch)
+	return args.Error(0)
+}
@@ -22,6 +22,7 @@ import (
 
 	egoscale "github.com/exoscale/egoscale/v2"
 	exoapi "github.com/exoscale/egoscale/v2/api"
+	"github.com/hashicorp/go-retryablehttp"
 	log "github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/external-dns/endpoint"
@@ -55,9 +56,14 @@ type ExoscaleOption func(*ExoscaleProvider)
 
 // NewExoscaleProvider returns ExoscaleProvider DNS provider interface implementation
 func NewExoscaleProvider(env, zone, key, secret string, dryRun bool, opts ...ExoscaleOption) (*ExoscaleProvider, error) {
+	// the retries of the default client of egoscale are kept
+	httpClient := retryablehttp.NewClient()
+	httpClient.Logger = nil
+	httpClient.HTTPClient.Transport = provider.NewUserAgentTransport(httpClient.HTTPClient.Transport)
 	client, err := egoscale.NewClient(
 		key,
 		secret,
+		egoscale.ClientOptWithHTTPClient(httpClient.StandardClient()),
 	)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+var (
+	// ErrInjectedFault is the error of the calls failed by a FaultInjectionProvider
+	ErrInjectedFault = errors.New("injected provider fault")
+	// ErrInjectedThrottling is the error of the calls throttled by a FaultInjectionProvider
+	ErrInjectedThrottling = errors.New("injected provider throttling: rate exceeded")
+)
+
+// Faults are the faults injected by a FaultInjectionProvider. The rates are probabilities between 0 and 1.
+type Faults struct {
+	// Latency is added to every call
+	Latency time.Duration
+	// ErrorRate is the rate of calls failing without being made
+	ErrorRate float64
+	// ThrottleRate is the rate of calls rejected as throttled without being made
+	ThrottleRate float64
+	// PartialFailureRate is the rate of calls to ApplyChanges applying only the first half of the changes
+	PartialFailureRate float64
+}
+
+// ParseFaults parses the faults of the comma-separated list of key:value pairs of spec,
+// e.g. "latency:500ms,error-rate:0.1,throttle-rate:0.05,partial-failure-rate:0.2".
+func ParseFaults(spec string) (Faults, error) {
+	var faults Faults
+	for _, pair := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return Faults{}, fmt.Errorf("invalid fault %q, expected key:value", pair)
+		}
+		if key == "latency" {
+			latency, err := time.ParseDuration(value)
+			if err != nil || latency < 0 {
+				return Faults{}, fmt.Errorf("invalid fault latency %q", value)
+			}
+			faults.Latency = latency
+			continue
+		}
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return Faults{}, fmt.Errorf("invalid fault %s %q, expected a rate between 0 and 1", key, value)
+		}
+		switch key {
+		case "error-rate":
+			faults.ErrorRate = rate
+		case "throttle-rate":
+			faults.ThrottleRate = rate
+		case "partial-failure-rate":
+			faults.PartialFailureRate = rate
+		default:
+			return Faults{}, fmt.Errorf("unknown fault %q, expected latency, error-rate, throttle-rate or partial-failure-rate", key)
+		}
+	}
+	return faults, nil
+}
+
+// FaultInjectionProvider is a Provider injecting latency, errors, throttling and partial failures
+// into the calls to Records and ApplyChanges, to rehearse the behavior of ExternalDNS under a degraded provider API.
+// The injected errors are soft errors, like the errors of an unavailable provider API.
+type FaultInjectionProvider struct {
+	Provider
+	Faults Faults
+	// random returns a number in [0, 1), defaults to rand.Float64
+	random func() float64
+}
+
+func NewFaultInjectionProvider(provider Provider, faults Faults) *FaultInjectionProvider {
+	return &FaultInjectionProvider{
+		Provider: provider,
+		Faults:   faults,
+		random:   rand.Float64,
+	}
+}
+
+// Unwrap returns the wrapped provider.
+func (f *FaultInjectionProvider) Unwrap() Provider {
+	return f.Provider
+}
+
+func (f *FaultInjectionProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	if err := f.inject(ctx, "Records"); err != nil {
+		return nil, err
+	}
+	return f.Provider.Records(ctx)
+}
+
+func (f *FaultInjectionProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	if err := f.inject(ctx, "ApplyChanges"); err != nil {
+		return err
+	}
+	if f.random() >= f.Faults.PartialFailureRate {
+		return f.Provider.ApplyChanges(ctx, changes)
+	}
+
+	partial := &plan.Changes{
+		Create: changes.Create[:len(changes.Create)/2],
+		Delete: changes.Delete[:len(changes.Delete)/2],
+	}
+	// the current and desired endpoints of the updates are paired by their index
+	updates := min(len(changes.UpdateOld), len(changes.UpdateNew)) / 2
+	partial.UpdateOld = changes.UpdateOld[:updates]
+	partial.UpdateNew = changes.UpdateNew[:updates]
+	log.Warnf("Injecting a partial failure of ApplyChanges")
+	if err := f.Provider.ApplyChanges(ctx, partial); err != nil {
+		return err
+	}
+	return NewSoftError(fmt.Errorf("%w: only the first half of the changes were applied", ErrInjectedFault))
+}
+
+// inject waits for the latency, and returns an injected error or throttling at their rates.
+func (f *FaultInjectionProvider) inject(ctx context.Context, method string) error {
+	if f.Faults.Latency > 0 {
+		select {
+		case <-time.After(f.Faults.Latency):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if f.random() < f.Faults.ErrorRate {
+		log.Warnf("Injecting a failure of %s", method)
+		return NewSoftError(fmt.Errorf("%s: %w", method, ErrInjectedFault))
+	}
+	if f.random() < f.Faults.ThrottleRate {
+		log.Warnf("Injecting throttling of %s", method)
+		return NewSoftError(fmt.Errorf("%s: %w", method, ErrInjectedThrottling))
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// recordingProvider records the changes applied.
+type recordingProvider struct {
+	BaseProvider
+	applied []*plan.Changes
+}
+
+func (p *recordingProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	return []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4")}, nil
+}
+
+func (p *recordingProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	p.applied = append(p.applied, changes)
+	return nil
+}
+
+func TestParseFaults(t *testing.T) {
+	faults, err := ParseFaults("latency:500ms, error-rate:0.1,throttle-rate:0.05,partial-failure-rate:1")
+	require.NoError(t, err)
+	assert.Equal(t, Faults{Latency: 500 * time.Millisecond, ErrorRate: 0.1, ThrottleRate: 0.05, PartialFailureRate: 1}, faults)
+
+	for _, spec := range []string{"latency", "latency:soon", "latency:-1s", "error-rate:1.5", "error-rate:often", "jitter:1s"} {
+		_, err := ParseFaults(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestFaultInjectionProviderErrors(t *testing.T) {
+	wrapped := &recordingProvider{}
+	p := NewFaultInjectionProvider(wrapped, Faults{ErrorRate: 0.5, ThrottleRate: 0.5})
+
+	p.random = func() float64 { return 0.1 }
+	_, err := p.Records(context.Background())
+	assert.True(t, errors.Is(err, SoftError))
+	assert.True(t, errors.Is(err, ErrInjectedFault))
+
+	// the error is not injected, the throttling is
+	random := []float64{0.9, 0.1}
+	p.random = func() float64 {
+		r := random[0]
+		random = random[1:]
+		return r
+	}
+	err = p.ApplyChanges(context.Background(), &plan.Changes{})
+	assert.True(t, errors.Is(err, SoftError))
+	assert.True(t, errors.Is(err, ErrInjectedThrottling))
+	assert.Empty(t, wrapped.applied)
+
+	p.random = func() float64 { return 0.9 }
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+}
+
+func TestFaultInjectionProviderPartialFailure(t *testing.T) {
+	wrapped := &recordingProvider{}
+	p := NewFaultInjectionProvider(wrapped, Faults{PartialFailureRate: 0.5})
+	p.random = func() float64 { return 0.1 }
+
+	newEndpoints := func(names ...string) []*endpoint.Endpoint {
+		var endpoints []*endpoint.Endpoint
+		for _, name := range names {
+			endpoints = append(endpoints, endpoint.NewEndpoint(name, endpoint.RecordTypeA, "1.2.3.4"))
+		}
+		return endpoints
+	}
+	changes := &plan.Changes{
+		Create:    newEndpoints("a.example.com", "b.example.com"),
+		UpdateOld: newEndpoints("c.example.com", "d.example.com", "e.example.com", "f.example.com"),
+		UpdateNew: newEndpoints("c.example.com", "d.example.com", "e.example.com", "f.example.com"),
+		Delete:    newEndpoints("g.example.com"),
+	}
+	err := p.ApplyChanges(context.Background(), changes)
+	assert.True(t, errors.Is(err, SoftError))
+	assert.True(t, errors.Is(err, ErrInjectedFault))
+	if assert.Len(t, wrapped.applied, 1) {
+		assert.Equal(t, changes.Create[:1], wrapped.applied[0].Create)
+		assert.Equal(t, changes.UpdateOld[:2], wrapped.applied[0].UpdateOld)
+		assert.Equal(t, changes.UpdateNew[:2], wrapped.applied[0].UpdateNew)
+		assert.Empty(t, wrapped.applied[0].Delete)
+	}
+}
+
+func TestFaultInjectionProviderLatency(t *testing.T) {
+	p := NewFaultInjectionProvider(&recordingProvider{}, Faults{Latency: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := p.Records(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
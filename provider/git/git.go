@@ -0,0 +1,270 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	// ModeBranch commits changes directly onto the configured branch.
+	ModeBranch = "branch"
+	// ModePullRequest commits changes onto a review branch, leaving the merge to a human or CI system.
+	ModePullRequest = "pr"
+)
+
+// GitConfig is used for configuring a GitProvider.
+type GitConfig struct {
+	// URL of the remote repository.
+	RepositoryURL string
+	// Branch holding the applied state of the zones.
+	Branch string
+	// Mode is either ModeBranch or ModePullRequest.
+	Mode string
+	// ReviewBranch is the branch that changes are pushed to in ModePullRequest.
+	ReviewBranch string
+	// Path inside the repository where zone files are stored.
+	Path string
+	// WorkDir is the local directory holding the clone.
+	WorkDir string
+	// Zones managed by this provider.
+	Zones []string
+	// AuthorName and AuthorEmail are used for commits.
+	AuthorName  string
+	AuthorEmail string
+	// A filter to apply when looking up and applying records.
+	DomainFilter endpoint.DomainFilter
+	// Do nothing and log what would have changed.
+	DryRun bool
+}
+
+// GitProvider is an implementation of Provider that stores records as YAML
+// zone files in a Git repository, leaving the actual DNS write to another system.
+type GitProvider struct {
+	provider.BaseProvider
+	repo         repository
+	mode         string
+	branch       string
+	reviewBranch string
+	path         string
+	zones        provider.ZoneIDName
+	domainFilter endpoint.DomainFilter
+	dryRun       bool
+	// git operations share a single working tree
+	mutex sync.Mutex
+}
+
+// NewGitProvider initializes a new Git based Provider.
+func NewGitProvider(cfg GitConfig) (*GitProvider, error) {
+	if cfg.RepositoryURL == "" {
+		return nil, errors.New("no git repository specified")
+	}
+	if cfg.Mode != ModeBranch && cfg.Mode != ModePullRequest {
+		return nil, fmt.Errorf("unsupported git mode: %s", cfg.Mode)
+	}
+	if cfg.Mode == ModePullRequest && (cfg.ReviewBranch == "" || cfg.ReviewBranch == cfg.Branch) {
+		return nil, errors.New("git review branch must be set and differ from the base branch")
+	}
+	repo := newCLIRepository(cfg.RepositoryURL, cfg.Branch, cfg.WorkDir, cfg.AuthorName, cfg.AuthorEmail)
+	return newGitProvider(cfg, repo)
+}
+
+func newGitProvider(cfg GitConfig, repo repository) (*GitProvider, error) {
+	zones := provider.ZoneIDName{}
+	for _, z := range cfg.Zones {
+		z = strings.TrimSuffix(z, ".")
+		if z == "" || !cfg.DomainFilter.Match(z) {
+			continue
+		}
+		zones.Add(z, z)
+	}
+	if len(zones) == 0 {
+		return nil, errors.New("no git zones specified or all zones filtered out by the domain filter")
+	}
+	return &GitProvider{
+		repo:         repo,
+		mode:         cfg.Mode,
+		branch:       cfg.Branch,
+		reviewBranch: cfg.ReviewBranch,
+		path:         cfg.Path,
+		zones:        zones,
+		domainFilter: cfg.DomainFilter,
+		dryRun:       cfg.DryRun,
+	}, nil
+}
+
+// Records returns the records stored in the zone files on the base branch.
+func (p *GitProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := p.repo.Sync(ctx); err != nil {
+		return nil, provider.NewSoftError(err)
+	}
+
+	var endpoints []*endpoint.Endpoint
+	for _, zone := range p.sortedZones() {
+		zf, err := readZoneFile(p.zonePath(zone), zone)
+		if err != nil {
+			return nil, err
+		}
+		for _, ep := range zf.endpoints() {
+			if p.domainFilter.Match(ep.DNSName) {
+				endpoints = append(endpoints, ep)
+			}
+		}
+	}
+	return endpoints, nil
+}
+
+// ApplyChanges writes the changes into the zone files, commits and pushes them.
+func (p *GitProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := p.repo.Sync(ctx); err != nil {
+		return provider.NewSoftError(err)
+	}
+
+	zoneFiles := map[string]*zoneFile{}
+	load := func(ep *endpoint.Endpoint) (*zoneFile, error) {
+		_, zone := p.zones.FindZone(ep.DNSName)
+		if zone == "" {
+			log.Debugf("Skipping record %s because no zone was found", ep.DNSName)
+			return nil, nil
+		}
+		if zf, ok := zoneFiles[zone]; ok {
+			return zf, nil
+		}
+		zf, err := readZoneFile(p.zonePath(zone), zone)
+		if err != nil {
+			return nil, err
+		}
+		zoneFiles[zone] = zf
+		return zf, nil
+	}
+
+	for _, ep := range changes.Delete {
+		zf, err := load(ep)
+		if err != nil {
+			return err
+		}
+		if zf != nil {
+			log.Infof("Deleting record %s %s from git zone %s", ep.DNSName, ep.RecordType, zf.Zone)
+			zf.remove(ep)
+		}
+	}
+	for _, ep := range changes.UpdateOld {
+		zf, err := load(ep)
+		if err != nil {
+			return err
+		}
+		if zf != nil {
+			zf.remove(ep)
+		}
+	}
+	for _, eps := range [][]*endpoint.Endpoint{changes.Create, changes.UpdateNew} {
+		for _, ep := range eps {
+			zf, err := load(ep)
+			if err != nil {
+				return err
+			}
+			if zf != nil {
+				log.Infof("Writing record %s %s to git zone %s", ep.DNSName, ep.RecordType, zf.Zone)
+				zf.upsert(ep)
+			}
+		}
+	}
+
+	if p.dryRun {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Join(p.repo.Dir(), p.path), 0o755); err != nil {
+		return err
+	}
+	for zone, zf := range zoneFiles {
+		if err := zf.write(p.zonePath(zone)); err != nil {
+			return err
+		}
+	}
+
+	committed, err := p.repo.Commit(ctx, commitMessage(changes))
+	if err != nil {
+		return provider.NewSoftError(err)
+	}
+	if !committed {
+		log.Debug("No changes to commit to git repository")
+		return nil
+	}
+
+	branch, force := p.branch, false
+	if p.mode == ModePullRequest {
+		// The review branch always holds the full set of pending changes on top of the base branch.
+		branch, force = p.reviewBranch, true
+		if !p.repo.DiffersFrom(ctx, branch) {
+			log.Debugf("Git review branch %s is already up to date", branch)
+			return nil
+		}
+	}
+	if err := p.repo.Push(ctx, branch, force); err != nil {
+		return provider.NewSoftError(err)
+	}
+	log.Infof("Pushed DNS changes to git branch %s", branch)
+	return nil
+}
+
+func (p *GitProvider) zonePath(zone string) string {
+	return filepath.Join(p.repo.Dir(), p.path, zone+".yaml")
+}
+
+func (p *GitProvider) sortedZones() []string {
+	zones := make([]string, 0, len(p.zones))
+	for _, zone := range p.zones {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	return zones
+}
+
+func commitMessage(changes *plan.Changes) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "external-dns: %d create, %d update, %d delete\n\n", len(changes.Create), len(changes.UpdateNew), len(changes.Delete))
+	for _, ep := range changes.Create {
+		fmt.Fprintf(&b, "+ %s %s %s\n", ep.DNSName, ep.RecordType, ep.Targets)
+	}
+	for _, ep := range changes.UpdateNew {
+		fmt.Fprintf(&b, "~ %s %s %s\n", ep.DNSName, ep.RecordType, ep.Targets)
+	}
+	for _, ep := range changes.Delete {
+		fmt.Fprintf(&b, "- %s %s %s\n", ep.DNSName, ep.RecordType, ep.Targets)
+	}
+	return b.String()
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// repository abstracts the git operations needed by the provider so they can be faked in tests.
+type repository interface {
+	// Sync makes the working tree match the tip of the base branch on the remote.
+	Sync(ctx context.Context) error
+	// Dir returns the root of the working tree.
+	Dir() string
+	// Commit stages all changes in the working tree and commits them.
+	// It returns false if there was nothing to commit.
+	Commit(ctx context.Context, message string) (bool, error)
+	// Push pushes HEAD to the given branch on the remote.
+	Push(ctx context.Context, branch string, force bool) error
+	// DiffersFrom reports whether the tree at HEAD differs from the given remote branch.
+	DiffersFrom(ctx context.Context, branch string) bool
+}
+
+// cliRepository implements repository by shelling out to the git binary.
+type cliRepository struct {
+	url         string
+	branch      string
+	dir         string
+	authorName  string
+	authorEmail string
+}
+
+func newCLIRepository(url, branch, dir, authorName, authorEmail string) *cliRepository {
+	return &cliRepository{
+		url:         url,
+		branch:      branch,
+		dir:         dir,
+		authorName:  authorName,
+		authorEmail: authorEmail,
+	}
+}
+
+func (r *cliRepository) Dir() string {
+	return r.dir
+}
+
+func (r *cliRepository) Sync(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Join(r.dir, ".git")); os.IsNotExist(err) {
+		if err := os.MkdirAll(r.dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create git work dir %s: %w", r.dir, err)
+		}
+		if _, err := r.run(ctx, "clone", "--branch", r.branch, r.url, "."); err != nil {
+			return err
+		}
+	}
+	if _, err := r.run(ctx, "fetch", "origin", r.branch); err != nil {
+		return err
+	}
+	if _, err := r.run(ctx, "checkout", "-B", r.branch, "origin/"+r.branch); err != nil {
+		return err
+	}
+	if _, err := r.run(ctx, "reset", "--hard", "origin/"+r.branch); err != nil {
+		return err
+	}
+	_, err := r.run(ctx, "clean", "-fd")
+	return err
+}
+
+func (r *cliRepository) Commit(ctx context.Context, message string) (bool, error) {
+	if _, err := r.run(ctx, "add", "--all"); err != nil {
+		return false, err
+	}
+	status, err := r.run(ctx, "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(status) == "" {
+		return false, nil
+	}
+	_, err = r.run(ctx,
+		"-c", "user.name="+r.authorName,
+		"-c", "user.email="+r.authorEmail,
+		"commit", "--message", message)
+	return err == nil, err
+}
+
+func (r *cliRepository) Push(ctx context.Context, branch string, force bool) error {
+	args := []string{"push"}
+	if force {
+		args = append(args, "--force")
+	}
+	args = append(args, "origin", "HEAD:refs/heads/"+branch)
+	_, err := r.run(ctx, args...)
+	return err
+}
+
+func (r *cliRepository) DiffersFrom(ctx context.Context, branch string) bool {
+	if _, err := r.run(ctx, "fetch", "origin", branch); err != nil {
+		// the branch most likely does not exist yet
+		return true
+	}
+	_, err := r.run(ctx, "diff", "--quiet", "FETCH_HEAD", "HEAD")
+	return err != nil
+}
+
+func (r *cliRepository) run(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	command := r.redact(strings.Join(args, " "))
+	log.Debugf("Running git %s", command)
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", command, err, r.redact(strings.TrimSpace(stderr.String())))
+	}
+	return stdout.String(), nil
+}
+
+// redact replaces the URL of the repository with its redacted URL, so that the credentials of the URL are not
+// logged or returned in errors.
+func (r *cliRepository) redact(s string) string {
+	redacted := redactURL(r.url)
+	if redacted == r.url {
+		return s
+	}
+	return strings.ReplaceAll(s, r.url, redacted)
+}
+
+// redactURL returns the URL with the password of its userinfo, or the username alone, e.g. a token, masked.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+	if _, ok := u.User.Password(); ok {
+		return u.Redacted()
+	}
+	u.User = url.User("xxxxx")
+	return u.String()
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// zoneFile is the on-disk representation of all records of a single zone.
+type zoneFile struct {
+	Zone    string       `yaml:"zone"`
+	Records []zoneRecord `yaml:"records"`
+}
+
+// zoneRecord is the on-disk representation of a single record set.
+type zoneRecord struct {
+	Name             string            `yaml:"name"`
+	Type             string            `yaml:"type"`
+	SetIdentifier    string            `yaml:"setIdentifier,omitempty"`
+	TTL              int64             `yaml:"ttl,omitempty"`
+	Targets          []string          `yaml:"targets"`
+	ProviderSpecific map[string]string `yaml:"providerSpecific,omitempty"`
+}
+
+func readZoneFile(path, zone string) (*zoneFile, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &zoneFile{Zone: zone}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	zf := &zoneFile{}
+	if err := yaml.Unmarshal(data, zf); err != nil {
+		return nil, fmt.Errorf("failed to parse zone file %s: %w", path, err)
+	}
+	zf.Zone = zone
+	return zf, nil
+}
+
+func (zf *zoneFile) write(path string) error {
+	sort.Slice(zf.Records, func(i, j int) bool {
+		if zf.Records[i].Name != zf.Records[j].Name {
+			return zf.Records[i].Name < zf.Records[j].Name
+		}
+		if zf.Records[i].Type != zf.Records[j].Type {
+			return zf.Records[i].Type < zf.Records[j].Type
+		}
+		return zf.Records[i].SetIdentifier < zf.Records[j].SetIdentifier
+	})
+	data, err := yaml.Marshal(zf)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (zf *zoneFile) endpoints() []*endpoint.Endpoint {
+	endpoints := make([]*endpoint.Endpoint, 0, len(zf.Records))
+	for _, r := range zf.Records {
+		ep := endpoint.NewEndpointWithTTL(r.Name, r.Type, endpoint.TTL(r.TTL), r.Targets...).WithSetIdentifier(r.SetIdentifier)
+		keys := make([]string, 0, len(r.ProviderSpecific))
+		for k := range r.ProviderSpecific {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ep.WithProviderSpecific(k, r.ProviderSpecific[k])
+		}
+		endpoints = append(endpoints, ep)
+	}
+	return endpoints
+}
+
+// upsert adds or replaces the record set matching the endpoint key.
+func (zf *zoneFile) upsert(ep *endpoint.Endpoint) {
+	record := zoneRecord{
+		Name:          ep.DNSName,
+		Type:          ep.RecordType,
+		SetIdentifier: ep.SetIdentifier,
+		TTL:           int64(ep.RecordTTL),
+		Targets:       append([]string(nil), ep.Targets...),
+	}
+	if len(ep.ProviderSpecific) > 0 {
+		record.ProviderSpecific = make(map[string]string, len(ep.ProviderSpecific))
+		for _, ps := range ep.ProviderSpecific {
+			record.ProviderSpecific[ps.Name] = ps.Value
+		}
+	}
+	for i, r := range zf.Records {
+		if r.matches(ep) {
+			zf.Records[i] = record
+			return
+		}
+	}
+	zf.Records = append(zf.Records, record)
+}
+
+// remove deletes the record set matching the endpoint key.
+func (zf *zoneFile) remove(ep *endpoint.Endpoint) {
+	for i, r := range zf.Records {
+		if r.matches(ep) {
+			zf.Records = append(zf.Records[:i], zf.Records[i+1:]...)
+			return
+		}
+	}
+}
+
+func (r zoneRecord) matches(ep *endpoint.Endpoint) bool {
+	return r.Name == ep.DNSName && r.Type == ep.RecordType && r.SetIdentifier == ep.SetIdentifier
+}
@@ -30,7 +30,7 @@ import (
 
 	"golang.org/x/time/rate"
 
-	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/provider"
 )
 
 // DefaultTimeout api requests after 180s
@@ -121,7 +121,7 @@ func NewClient(useOTE bool, apiKey, apiSecret string) (*Client, error) {
 		APIKey:      apiKey,
 		APISecret:   apiSecret,
 		APIEndPoint: endpoint,
-		Client:      &http.Client{},
+		Client:      &http.Client{Transport: provider.NewQuotaTransport("godaddy", provider.NewAPICallCounter(nil))},
 		// Add one token every second
 		Ratelimiter: rate.NewLimiter(rate.Every(time.Second), 60),
 		Timeout:     DefaultTimeout,
@@ -212,7 +212,7 @@ func (c *Client) NewRequest(method, path string, reqBody interface{}, needAuth b
 	}
 	req.Header.Set("Authorization", fmt.Sprintf("sso-key %s:%s", c.APIKey, c.APISecret))
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", "ExternalDNS/"+externaldns.Version)
+	req.Header.Set("User-Agent", provider.UserAgent())
 
 	// Send the request with requested timeout
 	c.Client.Timeout = c.Timeout
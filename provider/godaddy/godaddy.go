@@ -50,11 +50,11 @@ const domainsURI = "/v1/domains?statuses=ACTIVE,PENDING_DNS_ACTIVE"
 var ErrRecordToMutateNotFound = errors.New("record to mutate not found in current zone")
 
 type gdClient interface {
-	Patch(string, interface{}, interface{}) error
-	Post(string, interface{}, interface{}) error
-	Put(string, interface{}, interface{}) error
-	Get(string, interface{}) error
-	Delete(string, interface{}) error
+	PatchWithContext(context.Context, string, interface{}, interface{}) error
+	PostWithContext(context.Context, string, interface{}, interface{}) error
+	PutWithContext(context.Context, string, interface{}, interface{}) error
+	GetWithContext(context.Context, string, interface{}) error
+	DeleteWithContext(context.Context, string, interface{}) error
 }
 
 // GDProvider declare GoDaddy provider
@@ -152,11 +152,11 @@ func NewGoDaddyProvider(ctx context.Context, domainFilter endpoint.DomainFilter,
 	}, nil
 }
 
-func (p *GDProvider) zones() ([]string, error) {
+func (p *GDProvider) zones(ctx context.Context) ([]string, error) {
 	zones := []gdZone{}
 	filteredZones := []string{}
 
-	if err := p.client.Get(domainsURI, &zones); err != nil {
+	if err := p.client.GetWithContext(ctx, domainsURI, &zones); err != nil {
 		return nil, err
 	}
 
@@ -174,7 +174,7 @@ func (p *GDProvider) zones() ([]string, error) {
 
 func (p *GDProvider) zonesRecords(ctx context.Context, all bool) ([]string, []gdRecords, error) {
 	var allRecords []gdRecords
-	zones, err := p.zones()
+	zones, err := p.zones(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -226,7 +226,7 @@ func (p *GDProvider) records(ctx *context.Context, zone string, all bool) (*gdRe
 
 	log.Debugf("GoDaddy: Getting records for %s", zone)
 
-	if err := p.client.Get(fmt.Sprintf("/v1/domains/%s/records", zone), &recordsIds); err != nil {
+	if err := p.client.GetWithContext(*ctx, fmt.Sprintf("/v1/domains/%s/records", zone), &recordsIds); err != nil {
 		return nil, err
 	}
 
@@ -333,7 +333,7 @@ func (p *GDProvider) appendChange(action int, endpoints []*endpoint.Endpoint, al
 	return allChanges
 }
 
-func (p *GDProvider) changeAllRecords(endpoints []gdEndpoint, zoneRecords []*gdRecords) error {
+func (p *GDProvider) changeAllRecords(ctx context.Context, endpoints []gdEndpoint, zoneRecords []*gdRecords) error {
 	zoneNameIDMapper := gdZoneIDName{}
 
 	for _, zoneRecord := range zoneRecords {
@@ -358,7 +358,7 @@ func (p *GDProvider) changeAllRecords(endpoints []gdEndpoint, zoneRecords []*gdR
 
 			e.endpoint.RecordTTL = endpoint.TTL(maxOf(gdMinimalTTL, int64(e.endpoint.RecordTTL)))
 
-			if err := zoneRecord.applyEndpoint(e.action, p.client, *e.endpoint, dnsName, p.DryRun); err != nil {
+			if err := zoneRecord.applyEndpoint(ctx, e.action, p.client, *e.endpoint, dnsName, p.DryRun); err != nil {
 				log.Errorf("Unable to apply change %s on record %s type %s, %v", actionNames[e.action], dnsName, e.endpoint.RecordType, err)
 
 				return err
@@ -386,61 +386,34 @@ func (p *GDProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) er
 		changedZoneRecords[i] = &records[i]
 	}
 
-	var allChanges []gdEndpoint
-
-	allChanges = p.appendChange(gdDelete, changes.Delete, allChanges)
-
-	iOldSkip := make(map[int]bool)
-	iNewSkip := make(map[int]bool)
-
-	for iOld, recOld := range changes.UpdateOld {
-		for iNew, recNew := range changes.UpdateNew {
-			if recOld.DNSName == recNew.DNSName && recOld.RecordType == recNew.RecordType {
-				ReplaceEndpoints := []*endpoint.Endpoint{recNew}
-				allChanges = p.appendChange(gdReplace, ReplaceEndpoints, allChanges)
-				iOldSkip[iOld] = true
-				iNewSkip[iNew] = true
-				break
-			}
-		}
-	}
-
-	for iOld, recOld := range changes.UpdateOld {
-		_, found := iOldSkip[iOld]
-		if found {
-			continue
-		}
-		for iNew, recNew := range changes.UpdateNew {
-			_, found := iNewSkip[iNew]
-			if found {
-				continue
-			}
-
-			if recOld.DNSName != recNew.DNSName {
-				continue
-			}
-
-			DeleteEndpoints := []*endpoint.Endpoint{recOld}
-			CreateEndpoints := []*endpoint.Endpoint{recNew}
-			allChanges = p.appendChange(gdDelete, DeleteEndpoints, allChanges)
-			allChanges = p.appendChange(gdCreate, CreateEndpoints, allChanges)
-
-			break
+	// the record sets are deleted first, then replaced and created
+	var deletes, replaces, creates []*endpoint.Endpoint
+	for _, change := range changes.RRSets() {
+		switch {
+		case change.IsUpdate():
+			replaces = append(replaces, change.New)
+		case change.IsCreate():
+			creates = append(creates, change.New)
+		default:
+			deletes = append(deletes, change.Old)
 		}
 	}
 
-	allChanges = p.appendChange(gdCreate, changes.Create, allChanges)
+	var allChanges []gdEndpoint
+	allChanges = p.appendChange(gdDelete, deletes, allChanges)
+	allChanges = p.appendChange(gdReplace, replaces, allChanges)
+	allChanges = p.appendChange(gdCreate, creates, allChanges)
 
 	log.Infof("GoDaddy: %d changes will be done", len(allChanges))
 
-	if err = p.changeAllRecords(allChanges, changedZoneRecords); err != nil {
+	if err = p.changeAllRecords(ctx, allChanges, changedZoneRecords); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (p *gdRecords) addRecord(client gdClient, endpoint endpoint.Endpoint, dnsName string, dryRun bool) error {
+func (p *gdRecords) addRecord(ctx context.Context, client gdClient, endpoint endpoint.Endpoint, dnsName string, dryRun bool) error {
 	var response GDErrorResponse
 	for _, target := range endpoint.Targets {
 		change := gdRecordField{
@@ -456,7 +429,7 @@ func (p *gdRecords) addRecord(client gdClient, endpoint endpoint.Endpoint, dnsNa
 		log.Debugf("GoDaddy: Add an entry %s to zone %s", change.String(), p.zone)
 		if dryRun {
 			log.Infof("[DryRun] - Add record %s.%s of type %s %s", change.Name, p.zone, change.Type, toString(change))
-		} else if err := client.Patch(fmt.Sprintf("/v1/domains/%s/records", p.zone), []gdRecordField{change}, &response); err != nil {
+		} else if err := client.PatchWithContext(ctx, fmt.Sprintf("/v1/domains/%s/records", p.zone), []gdRecordField{change}, &response); err != nil {
 			log.Errorf("Add record %s.%s of type %s failed: %s", change.Name, p.zone, change.Type, response)
 
 			return err
@@ -466,7 +439,7 @@ func (p *gdRecords) addRecord(client gdClient, endpoint endpoint.Endpoint, dnsNa
 	return nil
 }
 
-func (p *gdRecords) replaceRecord(client gdClient, endpoint endpoint.Endpoint, dnsName string, dryRun bool) error {
+func (p *gdRecords) replaceRecord(ctx context.Context, client gdClient, endpoint endpoint.Endpoint, dnsName string, dryRun bool) error {
 	changed := []gdReplaceRecordField{}
 	records := []string{}
 
@@ -505,7 +478,7 @@ func (p *gdRecords) replaceRecord(client gdClient, endpoint endpoint.Endpoint, d
 	}
 
 	log.Debugf("Replace record %s.%s of type %s %s", dnsName, p.zone, endpoint.RecordType, records)
-	if err := client.Put(fmt.Sprintf("/v1/domains/%s/records/%s/%s", p.zone, endpoint.RecordType, dnsName), changed, &response); err != nil {
+	if err := client.PutWithContext(ctx, fmt.Sprintf("/v1/domains/%s/records/%s/%s", p.zone, endpoint.RecordType, dnsName), changed, &response); err != nil {
 		log.Errorf("Replace record %s.%s of type %s failed: %v", dnsName, p.zone, endpoint.RecordType, response)
 
 		return err
@@ -515,7 +488,7 @@ func (p *gdRecords) replaceRecord(client gdClient, endpoint endpoint.Endpoint, d
 }
 
 // Remove one record from the record list
-func (p *gdRecords) deleteRecord(client gdClient, endpoint endpoint.Endpoint, dnsName string, dryRun bool) error {
+func (p *gdRecords) deleteRecord(ctx context.Context, client gdClient, endpoint endpoint.Endpoint, dnsName string, dryRun bool) error {
 	records := []string{}
 
 	for _, target := range endpoint.Targets {
@@ -553,7 +526,7 @@ func (p *gdRecords) deleteRecord(client gdClient, endpoint endpoint.Endpoint, dn
 	}
 
 	var response GDErrorResponse
-	if err := client.Delete(fmt.Sprintf("/v1/domains/%s/records/%s/%s", p.zone, endpoint.RecordType, dnsName), &response); err != nil {
+	if err := client.DeleteWithContext(ctx, fmt.Sprintf("/v1/domains/%s/records/%s/%s", p.zone, endpoint.RecordType, dnsName), &response); err != nil {
 		log.Errorf("Delete record %s.%s of type %s failed: %v", dnsName, p.zone, endpoint.RecordType, response)
 
 		return err
@@ -562,14 +535,14 @@ func (p *gdRecords) deleteRecord(client gdClient, endpoint endpoint.Endpoint, dn
 	return nil
 }
 
-func (p *gdRecords) applyEndpoint(action int, client gdClient, endpoint endpoint.Endpoint, dnsName string, dryRun bool) error {
+func (p *gdRecords) applyEndpoint(ctx context.Context, action int, client gdClient, endpoint endpoint.Endpoint, dnsName string, dryRun bool) error {
 	switch action {
 	case gdCreate:
-		return p.addRecord(client, endpoint, dnsName, dryRun)
+		return p.addRecord(ctx, client, endpoint, dnsName, dryRun)
 	case gdReplace:
-		return p.replaceRecord(client, endpoint, dnsName, dryRun)
+		return p.replaceRecord(ctx, client, endpoint, dnsName, dryRun)
 	case gdDelete:
-		return p.deleteRecord(client, endpoint, dnsName, dryRun)
+		return p.deleteRecord(ctx, client, endpoint, dnsName, dryRun)
 	}
 
 	return nil
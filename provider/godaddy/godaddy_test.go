@@ -46,41 +46,41 @@ var (
 	zoneNameExampleNet string = "example.net"
 )
 
-func (c *mockGoDaddyClient) Post(endpoint string, input interface{}, output interface{}) error {
+func (c *mockGoDaddyClient) PostWithContext(ctx context.Context, endpoint string, input interface{}, output interface{}) error {
 	log.Infof("POST: %s - %v", endpoint, input)
-	stub := c.Called(endpoint, input)
+	stub := c.MethodCalled("Post", endpoint, input)
 	data, _ := json.Marshal(stub.Get(0))
 	json.Unmarshal(data, output)
 	return stub.Error(1)
 }
 
-func (c *mockGoDaddyClient) Patch(endpoint string, input interface{}, output interface{}) error {
+func (c *mockGoDaddyClient) PatchWithContext(ctx context.Context, endpoint string, input interface{}, output interface{}) error {
 	log.Infof("PATCH: %s - %v", endpoint, input)
-	stub := c.Called(endpoint, input)
+	stub := c.MethodCalled("Patch", endpoint, input)
 	data, _ := json.Marshal(stub.Get(0))
 	json.Unmarshal(data, output)
 	return stub.Error(1)
 }
 
-func (c *mockGoDaddyClient) Put(endpoint string, input interface{}, output interface{}) error {
+func (c *mockGoDaddyClient) PutWithContext(ctx context.Context, endpoint string, input interface{}, output interface{}) error {
 	log.Infof("PUT: %s - %v", endpoint, input)
-	stub := c.Called(endpoint, input)
+	stub := c.MethodCalled("Put", endpoint, input)
 	data, _ := json.Marshal(stub.Get(0))
 	json.Unmarshal(data, output)
 	return stub.Error(1)
 }
 
-func (c *mockGoDaddyClient) Get(endpoint string, output interface{}) error {
+func (c *mockGoDaddyClient) GetWithContext(ctx context.Context, endpoint string, output interface{}) error {
 	log.Infof("GET: %s", endpoint)
-	stub := c.Called(endpoint)
+	stub := c.MethodCalled("Get", endpoint)
 	data, _ := json.Marshal(stub.Get(0))
 	json.Unmarshal(data, output)
 	return stub.Error(1)
 }
 
-func (c *mockGoDaddyClient) Delete(endpoint string, output interface{}) error {
+func (c *mockGoDaddyClient) DeleteWithContext(ctx context.Context, endpoint string, output interface{}) error {
 	log.Infof("DELETE: %s", endpoint)
-	stub := c.Called(endpoint)
+	stub := c.MethodCalled("Delete", endpoint)
 	data, _ := json.Marshal(stub.Get(0))
 	json.Unmarshal(data, output)
 	return stub.Error(1)
@@ -104,7 +104,7 @@ func TestGoDaddyZones(t *testing.T) {
 		},
 	}, nil).Once()
 
-	domains, err := provider.zones()
+	domains, err := provider.zones(context.Background())
 
 	assert.NoError(err)
 	assert.Contains(domains, "example.com")
@@ -114,7 +114,7 @@ func TestGoDaddyZones(t *testing.T) {
 
 	// Error on getting zones
 	client.On("Get", domainsURI).Return(nil, ErrAPIDown).Once()
-	domains, err = provider.zones()
+	domains, err = provider.zones(context.Background())
 	assert.Error(err)
 	assert.Nil(domains)
 	client.AssertExpectations(t)
@@ -379,6 +379,43 @@ func TestGoDaddyChange(t *testing.T) {
 	client.AssertExpectations(t)
 }
 
+func TestGoDaddyChangeReplacesRecordSet(t *testing.T) {
+	client := newMockGoDaddyClient(t)
+	provider := &GDProvider{
+		client: client,
+	}
+
+	changes := plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("godaddy.example.net", endpoint.RecordTypeA, gdMinimalTTL, "203.0.113.43", "203.0.113.44"),
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("godaddy.example.net", endpoint.RecordTypeA, gdMinimalTTL, "203.0.113.44", "203.0.113.45"),
+		},
+	}
+
+	client.On("Get", domainsURI).Return([]gdZone{
+		{
+			Domain: zoneNameExampleNet,
+		},
+	}, nil).Once()
+
+	client.On("Get", "/v1/domains/example.net/records").Return([]gdRecordField{
+		{Name: "godaddy", Type: "A", TTL: gdMinimalTTL, Data: "203.0.113.43"},
+		{Name: "godaddy", Type: "A", TTL: gdMinimalTTL, Data: "203.0.113.44"},
+	}, nil).Once()
+
+	// all targets are replaced with a single request
+	client.On("Put", "/v1/domains/example.net/records/A/godaddy", []gdReplaceRecordField{
+		{Data: "203.0.113.44", TTL: gdMinimalTTL},
+		{Data: "203.0.113.45", TTL: gdMinimalTTL},
+	}).Return(nil, nil).Once()
+
+	assert.NoError(t, provider.ApplyChanges(context.TODO(), &changes))
+
+	client.AssertExpectations(t)
+}
+
 const (
 	operationFailedTestErrCode = "GD500"
 	operationFailedTestReason  = "Could not apply request"
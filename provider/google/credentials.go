@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	dns "google.golang.org/api/dns/v1"
+)
+
+// newCredentials returns the credentials of the credentials JSON, else of the credentials file, else the application
+// default credentials. The credentials are a service account key or the external account configuration of a workload
+// identity federation, exchanging the tokens of another identity provider, e.g. AWS or the OIDC tokens of a Kubernetes
+// service account, for Google tokens, so that clusters outside GCP need no service account key.
+// The credentials file is loaded again when it changes, e.g. when a mounted secret is rotated.
+func newCredentials(ctx context.Context, credentialsFile, credentialsJSON string) (*google.Credentials, error) {
+	switch {
+	case credentialsJSON != "":
+		return credentialsFromJSON(ctx, []byte(credentialsJSON), "the credentials JSON")
+	case credentialsFile != "":
+		source := &fileTokenSource{ctx: ctx, path: credentialsFile}
+		creds, err := source.load()
+		if err != nil {
+			return nil, err
+		}
+		return &google.Credentials{ProjectID: creds.ProjectID, TokenSource: source, JSON: creds.JSON}, nil
+	default:
+		return google.FindDefaultCredentials(ctx, dns.NdevClouddnsReadwriteScope)
+	}
+}
+
+func credentialsFromJSON(ctx context.Context, data []byte, origin string) (*google.Credentials, error) {
+	var file struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing the Google credentials of %s: %w", origin, err)
+	}
+	creds, err := google.CredentialsFromJSON(ctx, data, dns.NdevClouddnsReadwriteScope)
+	if err != nil {
+		return nil, fmt.Errorf("loading the Google credentials of %s: %w", origin, err)
+	}
+	log.Infof("Using the Google credentials of type %s of %s", file.Type, origin)
+	return creds, nil
+}
+
+// fileTokenSource is a token source of the credentials of a file, which loads the file again when it changed
+// since it was loaded, as a token is requested when the previous one expires.
+type fileTokenSource struct {
+	ctx  context.Context
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	source  oauth2.TokenSource
+}
+
+// load loads the credentials file.
+func (s *fileTokenSource) load() (*google.Credentials, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("reading the Google credentials file: %w", err)
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("reading the Google credentials file: %w", err)
+	}
+	creds, err := credentialsFromJSON(s.ctx, data, s.path)
+	if err != nil {
+		return nil, err
+	}
+	s.modTime, s.source = info.ModTime(), creds.TokenSource
+	return creds, nil
+}
+
+// Token returns a token of the credentials, loading the credentials file again if it changed. The credentials
+// loaded last are used if the file cannot be loaded.
+func (s *fileTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if info, err := os.Stat(s.path); err == nil && !info.ModTime().Equal(s.modTime) {
+		if _, err := s.load(); err != nil {
+			log.Errorf("Keeping the previous Google credentials: %v", err)
+		}
+	}
+	return s.source.Token()
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package google
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCredentialsFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestNewCredentials(t *testing.T) {
+	for _, tt := range []struct {
+		name              string
+		content           string
+		expectedProjectID string
+		expectedError     string
+	}{
+		{
+			name: "external account of an OIDC token file",
+			content: `{
+  "type": "external_account",
+  "audience": "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/pool/providers/provider",
+  "subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+  "token_url": "https://sts.googleapis.com/v1/token",
+  "credential_source": {"file": "/var/run/secrets/tokens/gcp-token"}
+}`,
+		},
+		{
+			name: "external account of AWS",
+			content: `{
+  "type": "external_account",
+  "audience": "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/pool/providers/aws",
+  "subject_token_type": "urn:ietf:params:aws:token-type:aws4_request",
+  "token_url": "https://sts.googleapis.com/v1/token",
+  "service_account_impersonation_url": "https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/external-dns@project.iam.gserviceaccount.com:generateAccessToken",
+  "credential_source": {
+    "environment_id": "aws1",
+    "region_url": "http://169.254.169.254/latest/meta-data/placement/availability-zone",
+    "url": "http://169.254.169.254/latest/meta-data/iam/security-credentials",
+    "regional_cred_verification_url": "https://sts.{region}.amazonaws.com?Action=GetCallerIdentity&Version=2011-06-15"
+  }
+}`,
+		},
+		{
+			name: "service account key",
+			content: `{
+  "type": "service_account",
+  "project_id": "project",
+  "private_key_id": "id",
+  "private_key": "key",
+  "client_email": "external-dns@project.iam.gserviceaccount.com",
+  "token_uri": "https://oauth2.googleapis.com/token"
+}`,
+			expectedProjectID: "project",
+		},
+		{
+			name:          "invalid JSON",
+			content:       `type: external_account`,
+			expectedError: "parsing the Google credentials of",
+		},
+		{
+			name:          "unknown type",
+			content:       `{"type": "unknown"}`,
+			expectedError: "loading the Google credentials of",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, err := newCredentials(context.Background(), writeCredentialsFile(t, tt.content), "")
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, creds.TokenSource)
+			assert.Equal(t, tt.expectedProjectID, creds.ProjectID)
+		})
+	}
+}
+
+func TestNewCredentialsMissingFile(t *testing.T) {
+	_, err := newCredentials(context.Background(), filepath.Join(t.TempDir(), "missing.json"), "")
+	assert.ErrorContains(t, err, "reading the Google credentials file")
+}
+
+func TestNewCredentialsJSON(t *testing.T) {
+	creds, err := newCredentials(context.Background(), "", `{
+  "type": "external_account",
+  "audience": "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/pool/providers/provider",
+  "subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+  "token_url": "https://sts.googleapis.com/v1/token",
+  "credential_source": {"file": "/var/run/secrets/tokens/gcp-token"}
+}`)
+	require.NoError(t, err)
+	assert.NotNil(t, creds.TokenSource)
+
+	_, err = newCredentials(context.Background(), "", "{")
+	assert.ErrorContains(t, err, "parsing the Google credentials of the credentials JSON")
+}
+
+func TestFileTokenSourceReload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		w.Header().Set("Content-Type", "application/json")
+		// the access token is the subject token of the credentials file
+		fmt.Fprintf(w, `{"access_token": %q, "issued_token_type": "urn:ietf:params:oauth:token-type:access_token", "token_type": "Bearer", "expires_in": 3600}`, r.Form.Get("subject_token"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	credentials := func(token string) string {
+		path := filepath.Join(dir, token)
+		require.NoError(t, os.WriteFile(path, []byte(token), 0o600))
+		return fmt.Sprintf(`{
+  "type": "external_account",
+  "audience": "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/pool/providers/provider",
+  "subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+  "token_url": %q,
+  "credential_source": {"file": %q}
+}`, server.URL, path)
+	}
+	path := writeCredentialsFile(t, credentials("first"))
+
+	source := &fileTokenSource{ctx: context.Background(), path: path}
+	_, err := source.load()
+	require.NoError(t, err)
+	token, err := source.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "first", token.AccessToken)
+
+	require.NoError(t, os.WriteFile(path, []byte(credentials("second")), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	token, err = source.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "second", token.AccessToken)
+
+	// the credentials loaded last are kept when the file is invalid
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute)))
+	token, err = source.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "second", token.AccessToken)
+}
@@ -26,7 +26,7 @@ import (
 	"cloud.google.com/go/compute/metadata"
 	"github.com/linki/instrumented_http"
 	log "github.com/sirupsen/logrus"
-	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2"
 	dns "google.golang.org/api/dns/v1"
 	googleapi "google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
@@ -120,17 +120,22 @@ type GoogleProvider struct {
 	managedZonesClient managedZonesServiceInterface
 	// A client for managing change sets
 	changesClient changesServiceInterface
+	// The record sets with a routing policy as of the last listing of the records, by name and type
+	routingRecordSets map[string]*dns.ResourceRecordSet
 	// The context parameter to be passed for gcloud API calls.
 	ctx context.Context
 }
 
 // NewGoogleProvider initializes a new Google CloudDNS based Provider.
-func NewGoogleProvider(ctx context.Context, project string, domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, batchChangeSize int, batchChangeInterval time.Duration, zoneVisibility string, dryRun bool) (*GoogleProvider, error) {
-	gcloud, err := google.DefaultClient(ctx, dns.NdevClouddnsReadwriteScope)
+func NewGoogleProvider(ctx context.Context, project, credentialsFile, credentialsJSON string, domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, batchChangeSize int, batchChangeInterval time.Duration, zoneVisibility string, dryRun bool) (*GoogleProvider, error) {
+	creds, err := newCredentials(ctx, credentialsFile, credentialsJSON)
 	if err != nil {
 		return nil, err
 	}
+	// the token source refreshes the tokens when they expire
+	gcloud := oauth2.NewClient(ctx, creds.TokenSource)
 
+	gcloud.Transport = provider.NewUserAgentTransport(gcloud.Transport)
 	gcloud = instrumented_http.NewClient(gcloud, &instrumented_http.Callbacks{
 		PathProcessor: func(path string) string {
 			parts := strings.Split(path, "/")
@@ -143,6 +148,10 @@ func NewGoogleProvider(ctx context.Context, project string, domainFilter endpoin
 		return nil, err
 	}
 
+	if project == "" && creds.ProjectID != "" {
+		log.Infof("Google project of the credentials: %s", creds.ProjectID)
+		project = creds.ProjectID
+	}
 	if project == "" {
 		mProject, mErr := metadata.ProjectIDWithContext(ctx)
 		if mErr != nil {
@@ -215,11 +224,17 @@ func (p *GoogleProvider) Records(ctx context.Context) (endpoints []*endpoint.End
 		return nil, err
 	}
 
+	routingRecordSets := map[string]*dns.ResourceRecordSet{}
 	f := func(resp *dns.ResourceRecordSetsListResponse) error {
 		for _, r := range resp.Rrsets {
 			if !p.SupportedRecordType(r.Type) {
 				continue
 			}
+			if r.RoutingPolicy != nil {
+				routingRecordSets[routingRecordSetKey(r.Name, r.Type)] = r
+				endpoints = append(endpoints, routingEndpoints(r)...)
+				continue
+			}
 			endpoints = append(endpoints, endpoint.NewEndpointWithTTL(r.Name, r.Type, endpoint.TTL(r.Ttl), r.Rrdatas...))
 		}
 
@@ -231,22 +246,36 @@ func (p *GoogleProvider) Records(ctx context.Context) (endpoints []*endpoint.End
 			return nil, provider.NewSoftError(fmt.Errorf("failed to list records in zone %s: %w", z.Name, err))
 		}
 	}
+	p.routingRecordSets = routingRecordSets
 
 	return endpoints, nil
 }
 
 // ApplyChanges applies a given set of changes in a given zone.
 func (p *GoogleProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
-	change := &dns.Change{}
+	// the endpoints with a routing policy change the record sets of their name and type
+	routed, other, err := p.routingChanges(changes)
+	if err != nil {
+		return err
+	}
+	change := &dns.Change{Additions: routed.Additions, Deletions: routed.Deletions}
 
-	change.Additions = append(change.Additions, p.newFilteredRecords(changes.Create)...)
+	// the ownership records of the TXT registry are batched with the records they own
+	groups := map[string]string{}
+	for _, endpoints := range [][]*endpoint.Endpoint{changes.Create, changes.UpdateNew, changes.UpdateOld, changes.Delete} {
+		for _, ep := range endpoints {
+			groups[provider.EnsureTrailingDot(ep.DNSName)] = provider.EnsureTrailingDot(provider.OwnershipGroup(ep))
+		}
+	}
+
+	change.Additions = append(change.Additions, p.newFilteredRecords(other.Create)...)
 
-	change.Additions = append(change.Additions, p.newFilteredRecords(changes.UpdateNew)...)
-	change.Deletions = append(change.Deletions, p.newFilteredRecords(changes.UpdateOld)...)
+	change.Additions = append(change.Additions, p.newFilteredRecords(other.UpdateNew)...)
+	change.Deletions = append(change.Deletions, p.newFilteredRecords(other.UpdateOld)...)
 
-	change.Deletions = append(change.Deletions, p.newFilteredRecords(changes.Delete)...)
+	change.Deletions = append(change.Deletions, p.newFilteredRecords(other.Delete)...)
 
-	return p.submitChange(ctx, change)
+	return p.submitChange(ctx, change, groups)
 }
 
 // SupportedRecordType returns true if the record type is supported by the provider
@@ -273,7 +302,7 @@ func (p *GoogleProvider) newFilteredRecords(endpoints []*endpoint.Endpoint) []*d
 }
 
 // submitChange takes a zone and a Change and sends it to Google.
-func (p *GoogleProvider) submitChange(ctx context.Context, change *dns.Change) error {
+func (p *GoogleProvider) submitChange(ctx context.Context, change *dns.Change, groups map[string]string) error {
 	if len(change.Additions) == 0 && len(change.Deletions) == 0 {
 		log.Info("All records are already up to date")
 		return nil
@@ -288,7 +317,7 @@ func (p *GoogleProvider) submitChange(ctx context.Context, change *dns.Change) e
 	changes := separateChange(zones, change)
 
 	for zone, change := range changes {
-		for batch, c := range batchChange(change, p.batchChangeSize) {
+		for batch, c := range batchChange(change, p.batchChangeSize, groups) {
 			log.Infof("Change zone: %v batch #%d", zone, batch)
 			for _, del := range c.Deletions {
 				log.Infof("Del records: %s %s %s %d", del.Name, del.Type, del.Rrdatas, del.Ttl)
@@ -312,8 +341,9 @@ func (p *GoogleProvider) submitChange(ctx context.Context, change *dns.Change) e
 	return nil
 }
 
-// batchChange separates a zone in multiple transaction.
-func batchChange(change *dns.Change, batchSize int) []*dns.Change {
+// batchChange separates a zone in multiple transaction. The record sets of a group, keyed by the name of the
+// record sets in groups, are always in the same transaction; a record set not in groups is a group of its own.
+func batchChange(change *dns.Change, batchSize int, groups map[string]string) []*dns.Change {
 	changes := []*dns.Change{}
 
 	if batchSize == 0 {
@@ -325,23 +355,30 @@ func batchChange(change *dns.Change, batchSize int) []*dns.Change {
 		deletions []*dns.ResourceRecordSet
 	}
 
+	groupOf := func(name string) string {
+		if group, ok := groups[name]; ok {
+			return group
+		}
+		return name
+	}
+
 	changesByName := map[string]*dnsChange{}
 
 	for _, a := range change.Additions {
-		change, ok := changesByName[a.Name]
+		change, ok := changesByName[groupOf(a.Name)]
 		if !ok {
 			change = &dnsChange{}
-			changesByName[a.Name] = change
+			changesByName[groupOf(a.Name)] = change
 		}
 
 		change.additions = append(change.additions, a)
 	}
 
 	for _, a := range change.Deletions {
-		change, ok := changesByName[a.Name]
+		change, ok := changesByName[groupOf(a.Name)]
 		if !ok {
 			change = &dnsChange{}
-			changesByName[a.Name] = change
+			changesByName[groupOf(a.Name)] = change
 		}
 
 		change.deletions = append(change.deletions, a)
@@ -422,27 +459,36 @@ func separateChange(zones map[string]*dns.ManagedZone, change *dns.Change) map[s
 	return changes
 }
 
+// newRrdata returns the record data of a target of a record type, with the hosts of CNAME, MX and SRV targets
+// ending with a dot. Cloud DNS takes TXT records as quoted character strings of at most 255 bytes each.
+func newRrdata(recordType, target string) string {
+	switch recordType {
+	case endpoint.RecordTypeCNAME:
+		return provider.EnsureTrailingDot(target)
+	case endpoint.RecordTypeMX:
+		if mx, err := endpoint.ParseMXTarget(target); err == nil {
+			mx.Host = provider.EnsureTrailingDot(mx.Host)
+			return mx.String()
+		}
+	case endpoint.RecordTypeSRV:
+		if srv, err := endpoint.ParseSRVTarget(target); err == nil {
+			srv.Host = provider.EnsureTrailingDot(srv.Host)
+			return srv.String()
+		}
+	case endpoint.RecordTypeTXT:
+		return endpoint.ChunkTXTTarget(target)
+	}
+	return target
+}
+
 // newRecord returns a RecordSet based on the given endpoint.
 func newRecord(ep *endpoint.Endpoint) *dns.ResourceRecordSet {
 	// TODO(linki): works around appending a trailing dot to TXT records. I think
 	// we should go back to storing DNS names with a trailing dot internally. This
 	// way we can use it has is here and trim it off if it exists when necessary.
 	targets := make([]string, len(ep.Targets))
-	copy(targets, []string(ep.Targets))
-	if ep.RecordType == endpoint.RecordTypeCNAME {
-		targets[0] = provider.EnsureTrailingDot(targets[0])
-	}
-
-	if ep.RecordType == endpoint.RecordTypeMX {
-		for i, mxRecord := range ep.Targets {
-			targets[i] = provider.EnsureTrailingDot(mxRecord)
-		}
-	}
-
-	if ep.RecordType == endpoint.RecordTypeSRV {
-		for i, srvRecord := range ep.Targets {
-			targets[i] = provider.EnsureTrailingDot(srvRecord)
-		}
+	for i, target := range ep.Targets {
+		targets[i] = newRrdata(ep.RecordType, target)
 	}
 
 	// no annotation results in a Ttl of 0, default to 300 for backwards-compatibility
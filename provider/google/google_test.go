@@ -483,6 +483,13 @@ func TestNewFilteredRecords(t *testing.T) {
 	})
 }
 
+func TestNewRecordLongTXT(t *testing.T) {
+	key := "v=DKIM1; k=rsa; p=" + strings.Repeat("A", 392)
+
+	record := newRecord(endpoint.NewEndpoint("dkim._domainkey.zone-1.ext-dns-test-2.gcp.zalan.do", endpoint.RecordTypeTXT, key, `"heritage=external-dns"`))
+	assert.Equal(t, []string{`"` + key[:255] + `" "` + key[255:] + `"`, `"heritage=external-dns"`}, record.Rrdatas)
+}
+
 func TestSeparateChanges(t *testing.T) {
 	change := &dns.Change{
 		Additions: []*dns.ResourceRecordSet{
@@ -546,7 +553,7 @@ func TestGoogleBatchChangeSet(t *testing.T) {
 		})
 	}
 
-	batchCs := batchChange(cs, googleDefaultBatchChangeSize)
+	batchCs := batchChange(cs, googleDefaultBatchChangeSize, nil)
 
 	require.Equal(t, 1, len(batchCs))
 
@@ -571,7 +578,7 @@ func TestGoogleBatchChangeSetExceeding(t *testing.T) {
 		})
 	}
 
-	batchCs := batchChange(cs, testLimit)
+	batchCs := batchChange(cs, testLimit, nil)
 
 	require.Equal(t, expectedBatchCount, len(batchCs))
 
@@ -603,11 +610,32 @@ func TestGoogleBatchChangeSetExceedingNameChange(t *testing.T) {
 		Ttl:  20,
 	})
 
-	batchCs := batchChange(cs, testLimit)
+	batchCs := batchChange(cs, testLimit, nil)
 
 	require.Equal(t, 0, len(batchCs))
 }
 
+func TestGoogleBatchChangeSetOwnershipGroups(t *testing.T) {
+	cs := &dns.Change{
+		Additions: []*dns.ResourceRecordSet{
+			{Name: "host-1.example.org.", Type: endpoint.RecordTypeA},
+			{Name: "host-2.example.org.", Type: endpoint.RecordTypeA},
+			{Name: "txt-host-1.example.org.", Type: endpoint.RecordTypeTXT},
+			{Name: "txt-host-2.example.org.", Type: endpoint.RecordTypeTXT},
+		},
+	}
+	groups := map[string]string{
+		"txt-host-1.example.org.": "host-1.example.org.",
+		"txt-host-2.example.org.": "host-2.example.org.",
+	}
+
+	batchCs := batchChange(cs, 2, groups)
+
+	require.Len(t, batchCs, 2)
+	assert.Equal(t, []*dns.ResourceRecordSet{cs.Additions[0], cs.Additions[2]}, batchCs[0].Additions)
+	assert.Equal(t, []*dns.ResourceRecordSet{cs.Additions[1], cs.Additions[3]}, batchCs[1].Additions)
+}
+
 func TestSoftErrListZonesConflict(t *testing.T) {
 	p := newGoogleProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.gcp.zalan.do."}), provider.NewZoneIDFilter([]string{}), false, []*endpoint.Endpoint{}, provider.NewSoftError(fmt.Errorf("failed to list zones")), nil)
 
@@ -817,7 +845,7 @@ func clearGoogleRecords(t *testing.T, provider *GoogleProvider, zone string) {
 	provider.resourceRecordSetsClient.List(provider.project, zone).Pages(context.Background(), func(resp *dns.ResourceRecordSetsListResponse) error {
 		for _, r := range resp.Rrsets {
 			switch r.Type {
-			case endpoint.RecordTypeA, endpoint.RecordTypeCNAME:
+			case endpoint.RecordTypeA, endpoint.RecordTypeCNAME, endpoint.RecordTypeTXT:
 				recordSets = append(recordSets, r)
 			}
 		}
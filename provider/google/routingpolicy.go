@@ -0,0 +1,269 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package google
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	dns "google.golang.org/api/dns/v1"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	// providerSpecificWeight is the property of the weight of the targets of an endpoint in the weighted round robin
+	// routing policy of its record set.
+	providerSpecificWeight = "google/weight"
+	// providerSpecificLocation is the property of the region of the targets of an endpoint in the geolocation routing
+	// policy of its record set, e.g. us-east1.
+	providerSpecificLocation = "google/location"
+	// setIdentifierPrefix prefixes the value keeping the set identifier of the endpoint of an item of a weighted
+	// round robin TXT record set, as Cloud DNS keeps none.
+	setIdentifierPrefix = "external-dns/set-identifier="
+)
+
+// Cloud DNS keeps a single record set per name and type, whose routing policy holds the targets of all the endpoints
+// of the name and type with a routing policy. As Cloud DNS keeps no set identifier, the set identifier of an endpoint
+// is the location of its item of a geolocation policy, and the targets of its item of a weighted round robin policy,
+// but for TXT records, e.g. the ownership records of the TXT registry, which keep it in an additional value.
+
+// hasRoutingPolicy returns whether the targets of the endpoint are an item of the routing policy of its record set.
+func hasRoutingPolicy(ep *endpoint.Endpoint) bool {
+	_, weighted := ep.GetProviderSpecificProperty(providerSpecificWeight)
+	_, geo := ep.GetProviderSpecificProperty(providerSpecificLocation)
+	return weighted || geo
+}
+
+// wrrSetIdentifier returns the set identifier of the targets of an item of a weighted round robin policy.
+func wrrSetIdentifier(targets endpoint.Targets) string {
+	sorted := slices.Clone([]string(targets))
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// AdjustEndpoints sets the set identifiers of the endpoints with a routing policy as they are read from their
+// record sets, and normalizes their routing policy properties.
+func (p *GoogleProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	for _, ep := range endpoints {
+		adjustRoutingPolicy(ep)
+	}
+	return endpoints, nil
+}
+
+func adjustRoutingPolicy(ep *endpoint.Endpoint) {
+	location, geo := ep.GetProviderSpecificProperty(providerSpecificLocation)
+	weight, weighted := ep.GetProviderSpecificProperty(providerSpecificWeight)
+	if geo && weighted {
+		log.Warnf("Ignoring the weight of %s, which has a location", ep.DNSName)
+		ep.DeleteProviderSpecificProperty(providerSpecificWeight)
+		weighted = false
+	}
+	switch {
+	case geo:
+		location = strings.ToLower(location)
+		ep.SetProviderSpecificProperty(providerSpecificLocation, location)
+		ep.SetIdentifier = location
+	case weighted:
+		value, err := strconv.ParseFloat(weight, 64)
+		if err != nil || value < 0 {
+			log.Warnf("Ignoring the invalid weight %q of %s", weight, ep.DNSName)
+			ep.DeleteProviderSpecificProperty(providerSpecificWeight)
+			return
+		}
+		ep.SetProviderSpecificProperty(providerSpecificWeight, strconv.FormatFloat(value, 'f', -1, 64))
+		if ep.RecordType != endpoint.RecordTypeTXT {
+			ep.SetIdentifier = wrrSetIdentifier(ep.Targets)
+		}
+	}
+}
+
+// routingEndpoints returns the endpoints of the items of the routing policy of the record set.
+func routingEndpoints(r *dns.ResourceRecordSet) []*endpoint.Endpoint {
+	var endpoints []*endpoint.Endpoint
+	if r.RoutingPolicy.Geo != nil {
+		for _, item := range r.RoutingPolicy.Geo.Items {
+			ep := endpoint.NewEndpointWithTTL(r.Name, r.Type, endpoint.TTL(r.Ttl), item.Rrdatas...).
+				WithSetIdentifier(item.Location).
+				WithProviderSpecific(providerSpecificLocation, item.Location)
+			endpoints = append(endpoints, ep)
+		}
+	}
+	if r.RoutingPolicy.Wrr != nil {
+		for _, item := range r.RoutingPolicy.Wrr.Items {
+			var targets []string
+			setIdentifier := ""
+			for _, value := range item.Rrdatas {
+				if id, ok := strings.CutPrefix(strings.Trim(value, `"`), setIdentifierPrefix); ok && r.Type == endpoint.RecordTypeTXT {
+					setIdentifier = id
+					continue
+				}
+				targets = append(targets, value)
+			}
+			ep := endpoint.NewEndpointWithTTL(r.Name, r.Type, endpoint.TTL(r.Ttl), targets...).
+				WithProviderSpecific(providerSpecificWeight, strconv.FormatFloat(item.Weight, 'f', -1, 64))
+			if r.Type != endpoint.RecordTypeTXT {
+				setIdentifier = wrrSetIdentifier(ep.Targets)
+			}
+			endpoints = append(endpoints, ep.WithSetIdentifier(setIdentifier))
+		}
+	}
+	return endpoints
+}
+
+// routingRecordSetKey is the key of the record set of the name and type.
+func routingRecordSetKey(name, recordType string) string {
+	return provider.EnsureTrailingDot(name) + " " + recordType
+}
+
+// routingChanges returns the changes of the record sets of the changed endpoints with a routing policy, replacing
+// the record sets listed last with the record sets of their current items and the changed items. The changes of
+// the other endpoints are returned unchanged.
+func (p *GoogleProvider) routingChanges(changes *plan.Changes) (*dns.Change, *plan.Changes, error) {
+	type recordSetChange struct {
+		name, recordType string
+		items            map[string]*endpoint.Endpoint
+	}
+	recordSets := map[string]*recordSetChange{}
+	recordSetOf := func(ep *endpoint.Endpoint) *recordSetChange {
+		key := routingRecordSetKey(ep.DNSName, ep.RecordType)
+		if change, ok := recordSets[key]; ok {
+			return change
+		}
+		change := &recordSetChange{name: provider.EnsureTrailingDot(ep.DNSName), recordType: ep.RecordType, items: map[string]*endpoint.Endpoint{}}
+		if current, ok := p.routingRecordSets[key]; ok {
+			for _, item := range routingEndpoints(current) {
+				change.items[item.SetIdentifier] = item
+			}
+		}
+		recordSets[key] = change
+		return change
+	}
+
+	other := &plan.Changes{}
+	split := func(endpoints []*endpoint.Endpoint, others *[]*endpoint.Endpoint, apply func(*recordSetChange, *endpoint.Endpoint)) {
+		for _, ep := range endpoints {
+			if !hasRoutingPolicy(ep) {
+				*others = append(*others, ep)
+			} else if p.domainFilter.Match(ep.DNSName) {
+				apply(recordSetOf(ep), ep)
+			}
+		}
+	}
+	remove := func(change *recordSetChange, ep *endpoint.Endpoint) { delete(change.items, ep.SetIdentifier) }
+	add := func(change *recordSetChange, ep *endpoint.Endpoint) { change.items[ep.SetIdentifier] = ep }
+	split(changes.Delete, &other.Delete, remove)
+	split(changes.UpdateOld, &other.UpdateOld, remove)
+	split(changes.UpdateNew, &other.UpdateNew, add)
+	split(changes.Create, &other.Create, add)
+
+	result := &dns.Change{}
+	keys := make([]string, 0, len(recordSets))
+	for key := range recordSets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		change := recordSets[key]
+		current := p.routingRecordSets[key]
+		desired, err := newRoutingRecordSet(change.name, change.recordType, current, change.items)
+		if err != nil {
+			return nil, nil, err
+		}
+		if current != nil {
+			result.Deletions = append(result.Deletions, current)
+		}
+		if desired != nil {
+			result.Additions = append(result.Additions, desired)
+		}
+	}
+	return result, other, nil
+}
+
+// newRoutingRecordSet returns the record set of the items, with the smallest TTL of the items and keeping the
+// health checks of the routing policy of the current record set, or nil if there is no item.
+func newRoutingRecordSet(name, recordType string, current *dns.ResourceRecordSet, items map[string]*endpoint.Endpoint) (*dns.ResourceRecordSet, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	policy := &dns.RRSetRoutingPolicy{}
+	if current != nil && current.RoutingPolicy != nil {
+		policy.HealthCheck = current.RoutingPolicy.HealthCheck
+	}
+	var ttl int64
+	for _, id := range ids {
+		ep := items[id]
+		record := newRecord(ep)
+		if ttl == 0 || record.Ttl < ttl {
+			ttl = record.Ttl
+		}
+		if location, ok := ep.GetProviderSpecificProperty(providerSpecificLocation); ok {
+			if policy.Wrr != nil {
+				return nil, fmt.Errorf("the %s record %s mixes geolocation and weighted round robin routing policies", recordType, name)
+			}
+			if policy.Geo == nil {
+				policy.Geo = &dns.RRSetRoutingPolicyGeoPolicy{}
+				if current != nil && current.RoutingPolicy != nil && current.RoutingPolicy.Geo != nil {
+					policy.Geo.EnableFencing = current.RoutingPolicy.Geo.EnableFencing
+				}
+			}
+			policy.Geo.Items = append(policy.Geo.Items, &dns.RRSetRoutingPolicyGeoPolicyGeoPolicyItem{
+				Location: location,
+				Rrdatas:  record.Rrdatas,
+			})
+			continue
+		}
+		if policy.Geo != nil {
+			return nil, fmt.Errorf("the %s record %s mixes geolocation and weighted round robin routing policies", recordType, name)
+		}
+		if policy.Wrr == nil {
+			policy.Wrr = &dns.RRSetRoutingPolicyWrrPolicy{}
+		}
+		weight, _ := ep.GetProviderSpecificProperty(providerSpecificWeight)
+		value, err := strconv.ParseFloat(weight, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight %q of the %s record %s: %w", weight, recordType, name, err)
+		}
+		rrdatas := record.Rrdatas
+		if recordType == endpoint.RecordTypeTXT {
+			rrdatas = append(rrdatas, strconv.Quote(setIdentifierPrefix+ep.SetIdentifier))
+		}
+		policy.Wrr.Items = append(policy.Wrr.Items, &dns.RRSetRoutingPolicyWrrPolicyWrrPolicyItem{
+			Weight:          value,
+			Rrdatas:         rrdatas,
+			ForceSendFields: []string{"Weight"},
+		})
+	}
+	return &dns.ResourceRecordSet{
+		Name:          name,
+		Type:          recordType,
+		Ttl:           ttl,
+		RoutingPolicy: policy,
+	}, nil
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package google
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dns "google.golang.org/api/dns/v1"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+func TestAdjustRoutingPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		name                  string
+		endpoint              *endpoint.Endpoint
+		expectedSetIdentifier string
+		expectedProperties    endpoint.ProviderSpecific
+	}{
+		{
+			name:     "no routing policy",
+			endpoint: endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "1.2.3.4").WithSetIdentifier("a"),
+
+			expectedSetIdentifier: "a",
+		},
+		{
+			name: "weighted",
+			endpoint: endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "5.6.7.8", "1.2.3.4").
+				WithProviderSpecific(providerSpecificWeight, "50.0"),
+			expectedSetIdentifier: "1.2.3.4,5.6.7.8",
+			expectedProperties:    endpoint.ProviderSpecific{{Name: providerSpecificWeight, Value: "50"}},
+		},
+		{
+			name: "weighted TXT keeps its set identifier",
+			endpoint: endpoint.NewEndpoint("example.com", endpoint.RecordTypeTXT, "heritage").WithSetIdentifier("cluster-a").
+				WithProviderSpecific(providerSpecificWeight, "1"),
+			expectedSetIdentifier: "cluster-a",
+			expectedProperties:    endpoint.ProviderSpecific{{Name: providerSpecificWeight, Value: "1"}},
+		},
+		{
+			name: "invalid weight",
+			endpoint: endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "1.2.3.4").WithSetIdentifier("a").
+				WithProviderSpecific(providerSpecificWeight, "-1"),
+			expectedSetIdentifier: "a",
+			expectedProperties:    endpoint.ProviderSpecific{},
+		},
+		{
+			name: "geolocation",
+			endpoint: endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "1.2.3.4").
+				WithProviderSpecific(providerSpecificLocation, "US-East1"),
+			expectedSetIdentifier: "us-east1",
+			expectedProperties:    endpoint.ProviderSpecific{{Name: providerSpecificLocation, Value: "us-east1"}},
+		},
+		{
+			name: "geolocation ignores the weight",
+			endpoint: endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "1.2.3.4").
+				WithProviderSpecific(providerSpecificLocation, "europe-west1").
+				WithProviderSpecific(providerSpecificWeight, "10"),
+			expectedSetIdentifier: "europe-west1",
+			expectedProperties:    endpoint.ProviderSpecific{{Name: providerSpecificLocation, Value: "europe-west1"}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			adjustRoutingPolicy(tt.endpoint)
+			assert.Equal(t, tt.expectedSetIdentifier, tt.endpoint.SetIdentifier)
+			assert.ElementsMatch(t, tt.expectedProperties, tt.endpoint.ProviderSpecific)
+		})
+	}
+}
+
+func TestGoogleApplyChangesWeightedRoundRobin(t *testing.T) {
+	ctx := context.Background()
+	p := newGoogleProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.gcp.zalan.do."}), provider.NewZoneIDFilter([]string{""}), false, nil, nil, nil)
+	name := "wrr.zone-1.ext-dns-test-2.gcp.zalan.do"
+
+	clusterA, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL(name, endpoint.RecordTypeA, 300, "1.1.1.1").WithProviderSpecific(providerSpecificWeight, "1"),
+		endpoint.NewEndpointWithTTL(name, endpoint.RecordTypeTXT, 300, "\"heritage=external-dns,external-dns/owner=a\"").WithSetIdentifier("a").
+			WithProviderSpecific(providerSpecificWeight, "1"),
+	})
+	require.NoError(t, err)
+	_, err = p.Records(ctx)
+	require.NoError(t, err)
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Create: clusterA}))
+
+	// another cluster adds its items to the record sets of the first one
+	clusterB, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL(name, endpoint.RecordTypeA, 300, "2.2.2.2").WithProviderSpecific(providerSpecificWeight, "3"),
+		endpoint.NewEndpointWithTTL(name, endpoint.RecordTypeTXT, 300, "\"heritage=external-dns,external-dns/owner=b\"").WithSetIdentifier("b").
+			WithProviderSpecific(providerSpecificWeight, "3"),
+	})
+	require.NoError(t, err)
+	_, err = p.Records(ctx)
+	require.NoError(t, err)
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Create: clusterB}))
+
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	validateEndpoints(t, records, append(clusterA, clusterB...))
+
+	recordSet := testRecords[zoneKey(p.project, "zone-1-ext-dns-test-2-gcp-zalan-do")][recordKey(endpoint.RecordTypeTXT, name+".")]
+	require.NotNil(t, recordSet)
+	assert.Equal(t, []*dns.RRSetRoutingPolicyWrrPolicyWrrPolicyItem{
+		{Weight: 1, Rrdatas: []string{"\"heritage=external-dns,external-dns/owner=a\"", "\"external-dns/set-identifier=a\""}, ForceSendFields: []string{"Weight"}},
+		{Weight: 3, Rrdatas: []string{"\"heritage=external-dns,external-dns/owner=b\"", "\"external-dns/set-identifier=b\""}, ForceSendFields: []string{"Weight"}},
+	}, recordSet.RoutingPolicy.Wrr.Items)
+
+	// the first cluster removes its items
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Delete: clusterA}))
+	records, err = p.Records(ctx)
+	require.NoError(t, err)
+	validateEndpoints(t, records, clusterB)
+
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Delete: clusterB}))
+	records, err = p.Records(ctx)
+	require.NoError(t, err)
+	validateEndpoints(t, records, []*endpoint.Endpoint{})
+}
+
+func TestGoogleApplyChangesGeolocation(t *testing.T) {
+	ctx := context.Background()
+	p := newGoogleProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.gcp.zalan.do."}), provider.NewZoneIDFilter([]string{""}), false, nil, nil, nil)
+	name := "geo.zone-2.ext-dns-test-2.gcp.zalan.do"
+
+	desired, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL(name, endpoint.RecordTypeA, 300, "1.1.1.1").WithProviderSpecific(providerSpecificLocation, "us-east1"),
+		endpoint.NewEndpointWithTTL(name, endpoint.RecordTypeA, 60, "2.2.2.2").WithProviderSpecific(providerSpecificLocation, "europe-west1"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Create: desired}))
+
+	recordSet := testRecords[zoneKey(p.project, "zone-2-ext-dns-test-2-gcp-zalan-do")][recordKey(endpoint.RecordTypeA, name+".")]
+	require.NotNil(t, recordSet)
+	assert.Equal(t, int64(60), recordSet.Ttl)
+	assert.Equal(t, []*dns.RRSetRoutingPolicyGeoPolicyGeoPolicyItem{
+		{Location: "europe-west1", Rrdatas: []string{"2.2.2.2"}},
+		{Location: "us-east1", Rrdatas: []string{"1.1.1.1"}},
+	}, recordSet.RoutingPolicy.Geo.Items)
+
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+	for _, record := range records {
+		location, ok := record.GetProviderSpecificProperty(providerSpecificLocation)
+		assert.True(t, ok)
+		assert.Equal(t, location, record.SetIdentifier)
+		assert.Equal(t, endpoint.TTL(60), record.RecordTTL)
+	}
+
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Delete: records}))
+	records, err = p.Records(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestGoogleApplyChangesMixedRoutingPolicies(t *testing.T) {
+	p := newGoogleProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.gcp.zalan.do."}), provider.NewZoneIDFilter([]string{""}), false, nil, nil, nil)
+	name := "mixed.zone-1.ext-dns-test-2.gcp.zalan.do"
+
+	desired, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint(name, endpoint.RecordTypeA, "1.1.1.1").WithProviderSpecific(providerSpecificLocation, "us-east1"),
+		endpoint.NewEndpoint(name, endpoint.RecordTypeA, "2.2.2.2").WithProviderSpecific(providerSpecificWeight, "1"),
+	})
+	require.NoError(t, err)
+	assert.ErrorContains(t, p.ApplyChanges(context.Background(), &plan.Changes{Create: desired}), "mixes geolocation and weighted round robin")
+}
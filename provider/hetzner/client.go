@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	// hetznerAPIEndpoint is the base URL of the Hetzner DNS API
+	hetznerAPIEndpoint = "https://dns.hetzner.com/api/v1"
+	// hetznerPageSize is the number of zones or records requested per page
+	hetznerPageSize = 100
+	hetznerTimeout  = 30 * time.Second
+)
+
+// hetznerZone is a zone of the Hetzner DNS API.
+type hetznerZone struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	TTL  int64  `json:"ttl"`
+}
+
+// hetznerRecord is a record of the Hetzner DNS API. Its name is relative to the zone, "@" for the apex. TTL is nil
+// for records with the default TTL of their zone.
+type hetznerRecord struct {
+	ID     string `json:"id,omitempty"`
+	ZoneID string `json:"zone_id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	TTL    *int64 `json:"ttl,omitempty"`
+}
+
+type hetznerPagination struct {
+	Page     int `json:"page"`
+	LastPage int `json:"last_page"`
+}
+
+type hetznerMeta struct {
+	Pagination hetznerPagination `json:"pagination"`
+}
+
+// hetznerAPIError is the error returned by the Hetzner DNS API.
+type hetznerAPIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *hetznerAPIError) Error() string {
+	return fmt.Sprintf("hetzner API error %d: %s", e.StatusCode, e.Message)
+}
+
+// hetznerClient is the part of the Hetzner DNS API used by the provider, to ease testing.
+type hetznerClient interface {
+	ListZones(ctx context.Context, page int) ([]hetznerZone, hetznerPagination, error)
+	ListRecords(ctx context.Context, zoneID string, page int) ([]hetznerRecord, hetznerPagination, error)
+	CreateRecord(ctx context.Context, record hetznerRecord) error
+	UpdateRecord(ctx context.Context, record hetznerRecord) error
+	DeleteRecord(ctx context.Context, id string) error
+}
+
+// httpClient calls the Hetzner DNS API, authenticated with an API token.
+type httpClient struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+func newHTTPClient(token string) *httpClient {
+	return &httpClient{
+		endpoint: hetznerAPIEndpoint,
+		token:    token,
+		client:   &http.Client{Timeout: hetznerTimeout},
+	}
+}
+
+func (c *httpClient) ListZones(ctx context.Context, page int) ([]hetznerZone, hetznerPagination, error) {
+	query := url.Values{"page": {strconv.Itoa(page)}, "per_page": {strconv.Itoa(hetznerPageSize)}}
+	var response struct {
+		Zones []hetznerZone `json:"zones"`
+		Meta  hetznerMeta   `json:"meta"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/zones?"+query.Encode(), nil, &response); err != nil {
+		return nil, hetznerPagination{}, err
+	}
+	return response.Zones, response.Meta.Pagination, nil
+}
+
+func (c *httpClient) ListRecords(ctx context.Context, zoneID string, page int) ([]hetznerRecord, hetznerPagination, error) {
+	query := url.Values{"zone_id": {zoneID}, "page": {strconv.Itoa(page)}, "per_page": {strconv.Itoa(hetznerPageSize)}}
+	var response struct {
+		Records []hetznerRecord `json:"records"`
+		Meta    hetznerMeta     `json:"meta"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/records?"+query.Encode(), nil, &response); err != nil {
+		return nil, hetznerPagination{}, err
+	}
+	return response.Records, response.Meta.Pagination, nil
+}
+
+func (c *httpClient) CreateRecord(ctx context.Context, record hetznerRecord) error {
+	return c.do(ctx, http.MethodPost, "/records", record, nil)
+}
+
+func (c *httpClient) UpdateRecord(ctx context.Context, record hetznerRecord) error {
+	return c.do(ctx, http.MethodPut, "/records/"+url.PathEscape(record.ID), record, nil)
+}
+
+func (c *httpClient) DeleteRecord(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/records/"+url.PathEscape(id), nil, nil)
+}
+
+// do sends a request to the API and decodes the response into result, unless result is nil.
+func (c *httpClient) do(ctx context.Context, method, path string, body, result interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Auth-API-Token", c.token)
+	req.Header.Set("User-Agent", provider.UserAgent())
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &hetznerAPIError{StatusCode: resp.StatusCode, Message: errorMessage(respBody)}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, result)
+}
+
+// errorMessage returns the message of an error response of the API, which is either {"error": {"message": ...}} or
+// {"message": ...}, or the response itself.
+func errorMessage(body []byte) string {
+	var response struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &response); err == nil {
+		if response.Error.Message != "" {
+			return response.Error.Message
+		}
+		if response.Message != "" {
+			return response.Message
+		}
+	}
+	return string(bytes.TrimSpace(body))
+}
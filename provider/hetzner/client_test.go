@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/provider"
+)
+
+func newTestHTTPClient(t *testing.T, handler http.HandlerFunc) *httpClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client := newHTTPClient("secret")
+	client.endpoint = server.URL
+	return client
+}
+
+func TestHTTPClientListZones(t *testing.T) {
+	client := newTestHTTPClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/zones", r.URL.Path)
+		assert.Equal(t, "2", r.URL.Query().Get("page"))
+		assert.Equal(t, "secret", r.Header.Get("Auth-API-Token"))
+		assert.Equal(t, provider.UserAgent(), r.Header.Get("User-Agent"))
+		_, _ = w.Write([]byte(`{
+			"zones": [{"id": "z1", "name": "example.com", "ttl": 86400}],
+			"meta": {"pagination": {"page": 2, "per_page": 100, "last_page": 3, "total_entries": 201}}
+		}`))
+	})
+
+	zones, pagination, err := client.ListZones(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []hetznerZone{{ID: "z1", Name: "example.com", TTL: 86400}}, zones)
+	assert.Equal(t, hetznerPagination{Page: 2, LastPage: 3}, pagination)
+}
+
+func TestHTTPClientListRecords(t *testing.T) {
+	client := newTestHTTPClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/records", r.URL.Path)
+		assert.Equal(t, "z1", r.URL.Query().Get("zone_id"))
+		_, _ = w.Write([]byte(`{
+			"records": [
+				{"id": "r1", "zone_id": "z1", "name": "www", "type": "A", "value": "1.2.3.4", "ttl": 60},
+				{"id": "r2", "zone_id": "z1", "name": "@", "type": "TXT", "value": "v=spf1 -all"}
+			],
+			"meta": {"pagination": {"page": 1, "per_page": 100, "last_page": 1, "total_entries": 2}}
+		}`))
+	})
+
+	records, _, err := client.ListRecords(context.Background(), "z1", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []hetznerRecord{
+		{ID: "r1", ZoneID: "z1", Name: "www", Type: "A", Value: "1.2.3.4", TTL: ttl(60)},
+		{ID: "r2", ZoneID: "z1", Name: "@", Type: "TXT", Value: "v=spf1 -all"},
+	}, records)
+}
+
+func TestHTTPClientChangeRecords(t *testing.T) {
+	var requests []string
+	client := newTestHTTPClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	require.NoError(t, client.CreateRecord(context.Background(), hetznerRecord{ZoneID: "z1", Name: "www", Type: "A", Value: "1.2.3.4"}))
+	require.NoError(t, client.UpdateRecord(context.Background(), hetznerRecord{ID: "r1", ZoneID: "z1", Name: "www", Type: "A", Value: "1.2.3.4", TTL: ttl(60)}))
+	require.NoError(t, client.DeleteRecord(context.Background(), "r1"))
+	assert.Equal(t, []string{
+		`POST /records {"zone_id":"z1","name":"www","type":"A","value":"1.2.3.4"}`,
+		`PUT /records/r1 {"id":"r1","zone_id":"z1","name":"www","type":"A","value":"1.2.3.4","ttl":60}`,
+		`DELETE /records/r1 `,
+	}, requests)
+}
+
+func TestHTTPClientError(t *testing.T) {
+	for body, expected := range map[string]string{
+		`{"error": {"message": "zone not found", "code": 404}}`: "hetzner API error 404: zone not found",
+		`{"message": "Invalid authentication credentials"}`:     "hetzner API error 404: Invalid authentication credentials",
+		"not found\n": "hetzner API error 404: not found",
+	} {
+		client := newTestHTTPClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(body))
+		})
+		err := client.DeleteRecord(context.Background(), "r1")
+		assert.EqualError(t, err, expected)
+	}
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// HetznerProvider is an implementation of Provider for Hetzner DNS.
+type HetznerProvider struct {
+	provider.BaseProvider
+	client       hetznerClient
+	domainFilter endpoint.DomainFilter
+	zoneIDFilter provider.ZoneIDFilter
+	dryRun       bool
+}
+
+// NewHetznerProvider initializes a new Hetzner DNS based Provider, authenticated with the API token in the
+// HETZNER_TOKEN environment variable.
+func NewHetznerProvider(domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, dryRun bool) (*HetznerProvider, error) {
+	token, ok := os.LookupEnv("HETZNER_TOKEN")
+	if !ok || token == "" {
+		return nil, errors.New("no token found, set the HETZNER_TOKEN environment variable to a Hetzner DNS API token")
+	}
+	return &HetznerProvider{
+		client:       newHTTPClient(token),
+		domainFilter: domainFilter,
+		zoneIDFilter: zoneIDFilter,
+		dryRun:       dryRun,
+	}, nil
+}
+
+// Zones returns the zones of the account matching the domain and zone ID filters.
+func (p *HetznerProvider) Zones(ctx context.Context) ([]hetznerZone, error) {
+	var zones []hetznerZone
+	for page := 1; ; page++ {
+		result, pagination, err := p.client.ListZones(ctx, page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list zones: %w", err)
+		}
+		for _, zone := range result {
+			if !p.domainFilter.Match(zone.Name) || !p.zoneIDFilter.Match(zone.ID) {
+				log.Debugf("Excluding zone %s (%s) by filters", zone.Name, zone.ID)
+				continue
+			}
+			zones = append(zones, zone)
+		}
+		if page >= pagination.LastPage {
+			return zones, nil
+		}
+	}
+}
+
+// Records returns the records of the zones, with the targets of the records of the same name and type in a single
+// endpoint.
+func (p *HetznerProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	zones, err := p.Zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []*endpoint.Endpoint
+	for _, zone := range zones {
+		records, err := p.records(ctx, zone)
+		if err != nil {
+			return nil, err
+		}
+		byKey := map[string]*endpoint.Endpoint{}
+		for _, record := range records {
+			if !supportedRecordType(record.Type) {
+				continue
+			}
+			name := recordDNSName(record.Name, zone.Name)
+			target := recordTarget(record.Type, record.Value)
+			key := name + "/" + record.Type
+			if ep, ok := byKey[key]; ok {
+				ep.Targets = append(ep.Targets, target)
+				continue
+			}
+			var ttl endpoint.TTL
+			if record.TTL != nil {
+				ttl = endpoint.TTL(*record.TTL)
+			}
+			ep := endpoint.NewEndpointWithTTL(name, record.Type, ttl, target)
+			byKey[key] = ep
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return endpoints, nil
+}
+
+// records returns all records of a zone.
+func (p *HetznerProvider) records(ctx context.Context, zone hetznerZone) ([]hetznerRecord, error) {
+	var records []hetznerRecord
+	for page := 1; ; page++ {
+		result, pagination, err := p.client.ListRecords(ctx, zone.ID, page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the records of zone %s: %w", zone.Name, err)
+		}
+		records = append(records, result...)
+		if page >= pagination.LastPage {
+			return records, nil
+		}
+	}
+}
+
+// ApplyChanges applies the changes record set by record set. The records of a zone are listed once, when a record
+// set of the zone is updated or deleted, to find the IDs of its records. Records whose value and TTL don't change are
+// left untouched.
+func (p *HetznerProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	zones, err := p.Zones(ctx)
+	if err != nil {
+		return err
+	}
+	zoneIDName := provider.ZoneIDName{}
+	zonesByID := map[string]hetznerZone{}
+	for _, zone := range zones {
+		zoneIDName.Add(zone.ID, zone.Name)
+		zonesByID[zone.ID] = zone
+	}
+
+	recordsByZone := map[string][]hetznerRecord{}
+	for _, change := range changes.RRSets() {
+		zoneID, _ := zoneIDName.FindZone(change.Key.DNSName)
+		if zoneID == "" {
+			log.Debugf("Skipping record %s because no hosted zone matching record DNS Name was detected", change.Key.DNSName)
+			continue
+		}
+		zone := zonesByID[zoneID]
+
+		var current []hetznerRecord
+		if !change.IsCreate() {
+			records, ok := recordsByZone[zoneID]
+			if !ok {
+				if records, err = p.records(ctx, zone); err != nil {
+					return err
+				}
+				recordsByZone[zoneID] = records
+			}
+			name := recordName(change.Key.DNSName, zone.Name)
+			for _, record := range records {
+				if record.Name == name && record.Type == change.Key.RecordType {
+					current = append(current, record)
+				}
+			}
+		}
+
+		if err := p.applyRRSetChange(ctx, zone, change, current); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyRRSetChange replaces the current records of a record set by the records of its desired targets. Current
+// records are updated in place, rather than deleted and created, when their value or TTL changes.
+func (p *HetznerProvider) applyRRSetChange(ctx context.Context, zone hetznerZone, change *plan.RRSetChange, current []hetznerRecord) error {
+	var desired []hetznerRecord
+	if change.New != nil {
+		var ttl *int64
+		if change.New.RecordTTL.IsConfigured() {
+			ttl = new(int64)
+			*ttl = int64(change.New.RecordTTL)
+		}
+		for _, target := range change.New.Targets {
+			desired = append(desired, hetznerRecord{
+				ZoneID: zone.ID,
+				Name:   recordName(change.Key.DNSName, zone.Name),
+				Type:   change.Key.RecordType,
+				Value:  recordValue(change.Key.RecordType, target),
+				TTL:    ttl,
+			})
+		}
+	}
+
+	// the records whose value is desired are kept, the others are reused for the new values or deleted
+	var creates []hetznerRecord
+	unchanged := map[int]bool{}
+	for _, record := range desired {
+		i := -1
+		for j, c := range current {
+			if !unchanged[j] && sameValue(record.Type, c.Value, record.Value) {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			creates = append(creates, record)
+			continue
+		}
+		unchanged[i] = true
+		if ttlChanged(current[i].TTL, record.TTL) {
+			record.ID = current[i].ID
+			if err := p.submit(ctx, "Updating", record, p.client.UpdateRecord); err != nil {
+				return err
+			}
+		}
+	}
+	var obsolete []hetznerRecord
+	for i, record := range current {
+		if !unchanged[i] {
+			obsolete = append(obsolete, record)
+		}
+	}
+
+	for i, record := range creates {
+		if i < len(obsolete) {
+			record.ID = obsolete[i].ID
+			if err := p.submit(ctx, "Updating", record, p.client.UpdateRecord); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := p.submit(ctx, "Creating", record, p.client.CreateRecord); err != nil {
+			return err
+		}
+	}
+	for i := len(creates); i < len(obsolete); i++ {
+		err := p.submit(ctx, "Deleting", obsolete[i], func(ctx context.Context, record hetznerRecord) error {
+			return p.client.DeleteRecord(ctx, record.ID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// submit logs the change of a record and submits it, unless running in dry-run mode.
+func (p *HetznerProvider) submit(ctx context.Context, action string, record hetznerRecord, apply func(context.Context, hetznerRecord) error) error {
+	fields := log.Fields{
+		"record": record.Name,
+		"type":   record.Type,
+		"value":  record.Value,
+		"zoneID": record.ZoneID,
+	}
+	log.WithFields(fields).Infof("%s record", action)
+	if p.dryRun {
+		return nil
+	}
+	if err := apply(ctx, record); err != nil {
+		return fmt.Errorf("failed to change the %s record %s of zone %s: %w", record.Type, record.Name, record.ZoneID, err)
+	}
+	return nil
+}
+
+// supportedRecordType returns true for the record types managed by the provider.
+func supportedRecordType(recordType string) bool {
+	return provider.SupportedRecordType(recordType) || recordType == endpoint.RecordTypeMX
+}
+
+// recordName returns the name of a record relative to its zone, "@" for the apex.
+func recordName(dnsName, zoneName string) string {
+	dnsName = strings.TrimSuffix(dnsName, ".")
+	if dnsName == zoneName {
+		return "@"
+	}
+	return strings.TrimSuffix(dnsName, "."+zoneName)
+}
+
+// recordDNSName returns the DNS name of a record of a zone.
+func recordDNSName(name, zoneName string) string {
+	if name == "@" {
+		return zoneName
+	}
+	return name + "." + zoneName
+}
+
+// recordValue returns the value of a record of a target. Hostnames are fully qualified, as values without a trailing
+// dot are relative to the zone.
+func recordValue(recordType, target string) string {
+	switch recordType {
+	case endpoint.RecordTypeCNAME, endpoint.RecordTypeNS, endpoint.RecordTypeMX, endpoint.RecordTypeSRV:
+		return provider.EnsureTrailingDot(target)
+	default:
+		return target
+	}
+}
+
+// recordTarget returns the target of a record value, see recordValue.
+func recordTarget(recordType, value string) string {
+	switch recordType {
+	case endpoint.RecordTypeCNAME, endpoint.RecordTypeNS, endpoint.RecordTypeMX, endpoint.RecordTypeSRV:
+		return strings.TrimSuffix(value, ".")
+	default:
+		return value
+	}
+}
+
+func sameValue(recordType, a, b string) bool {
+	return endpoint.CanonicalTarget(recordType, a) == endpoint.CanonicalTarget(recordType, b)
+}
+
+// ttlChanged returns true if the desired TTL is set and differs from the current one. Records without a desired TTL
+// keep their TTL.
+func ttlChanged(current, desired *int64) bool {
+	return desired != nil && (current == nil || *current != *desired)
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+func ttl(seconds int64) *int64 {
+	return &seconds
+}
+
+// fakeClient keeps the zones and records in memory, and returns them one per page.
+type fakeClient struct {
+	zones   []hetznerZone
+	records []hetznerRecord
+	// requests are the changes of records, e.g. "PUT r1 www A 1.2.3.4"
+	requests []string
+	nextID   int
+	err      error
+}
+
+func (c *fakeClient) ListZones(_ context.Context, page int) ([]hetznerZone, hetznerPagination, error) {
+	if c.err != nil {
+		return nil, hetznerPagination{}, c.err
+	}
+	if page > len(c.zones) {
+		return nil, hetznerPagination{Page: page, LastPage: len(c.zones)}, nil
+	}
+	return c.zones[page-1 : page], hetznerPagination{Page: page, LastPage: len(c.zones)}, nil
+}
+
+func (c *fakeClient) ListRecords(_ context.Context, zoneID string, page int) ([]hetznerRecord, hetznerPagination, error) {
+	var records []hetznerRecord
+	for _, record := range c.records {
+		if record.ZoneID == zoneID {
+			records = append(records, record)
+		}
+	}
+	if page > len(records) {
+		return nil, hetznerPagination{Page: page, LastPage: len(records)}, nil
+	}
+	return records[page-1 : page], hetznerPagination{Page: page, LastPage: len(records)}, nil
+}
+
+func (c *fakeClient) CreateRecord(_ context.Context, record hetznerRecord) error {
+	c.nextID++
+	record.ID = fmt.Sprintf("new%d", c.nextID)
+	c.records = append(c.records, record)
+	c.requests = append(c.requests, request("POST", record))
+	return nil
+}
+
+func (c *fakeClient) UpdateRecord(_ context.Context, record hetznerRecord) error {
+	for i := range c.records {
+		if c.records[i].ID == record.ID {
+			c.records[i] = record
+		}
+	}
+	c.requests = append(c.requests, request("PUT", record))
+	return nil
+}
+
+func (c *fakeClient) DeleteRecord(_ context.Context, id string) error {
+	for i, record := range c.records {
+		if record.ID == id {
+			c.records = append(c.records[:i], c.records[i+1:]...)
+			c.requests = append(c.requests, request("DELETE", record))
+			return nil
+		}
+	}
+	return errors.New("record not found")
+}
+
+func request(method string, record hetznerRecord) string {
+	s := fmt.Sprintf("%s %s %s %s %s", method, record.ID, record.Name, record.Type, record.Value)
+	if record.TTL != nil {
+		s += fmt.Sprintf(" %d", *record.TTL)
+	}
+	return s
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		zones: []hetznerZone{
+			{ID: "z1", Name: "example.com", TTL: 86400},
+			{ID: "z2", Name: "example.org", TTL: 86400},
+		},
+		records: []hetznerRecord{
+			{ID: "r1", ZoneID: "z1", Name: "@", Type: "A", Value: "1.2.3.4"},
+			{ID: "r2", ZoneID: "z1", Name: "www", Type: "CNAME", Value: "example.com.", TTL: ttl(300)},
+			{ID: "r3", ZoneID: "z1", Name: "api", Type: "A", Value: "1.1.1.1", TTL: ttl(60)},
+			{ID: "r4", ZoneID: "z1", Name: "api", Type: "A", Value: "2.2.2.2", TTL: ttl(60)},
+			{ID: "r5", ZoneID: "z1", Name: "@", Type: "SOA", Value: "hydrogen.ns.hetzner.com. dns.hetzner.com. 1 86400 10800 3600000 3600"},
+			{ID: "r6", ZoneID: "z2", Name: "@", Type: "MX", Value: "10 mail.example.org."},
+		},
+	}
+}
+
+func TestHetznerRecords(t *testing.T) {
+	p := &HetznerProvider{client: newFakeClient()}
+
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeCNAME, 300, "example.com"),
+		endpoint.NewEndpointWithTTL("api.example.com", endpoint.RecordTypeA, 60, "1.1.1.1", "2.2.2.2"),
+		endpoint.NewEndpoint("example.org", endpoint.RecordTypeMX, "10 mail.example.org"),
+	}, records)
+}
+
+func TestHetznerZonesFilters(t *testing.T) {
+	p := &HetznerProvider{client: newFakeClient(), domainFilter: endpoint.NewDomainFilter([]string{"example.org"})}
+	zones, err := p.Zones(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []hetznerZone{{ID: "z2", Name: "example.org", TTL: 86400}}, zones)
+
+	p = &HetznerProvider{client: newFakeClient(), zoneIDFilter: provider.NewZoneIDFilter([]string{"z1"})}
+	zones, err = p.Zones(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []hetznerZone{{ID: "z1", Name: "example.com", TTL: 86400}}, zones)
+
+	p = &HetznerProvider{client: &fakeClient{err: errors.New("unauthorized")}}
+	_, err = p.Records(context.Background())
+	assert.EqualError(t, err, "failed to list zones: unauthorized")
+}
+
+func TestHetznerApplyChanges(t *testing.T) {
+	client := newFakeClient()
+	p := &HetznerProvider{client: client}
+
+	err := p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeCNAME, 120, "lb.example.net"),
+			endpoint.NewEndpoint("app.example.net", endpoint.RecordTypeA, "5.5.5.5"),
+		},
+		UpdateOld: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("api.example.com", endpoint.RecordTypeA, 60, "1.1.1.1", "2.2.2.2"),
+			endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeCNAME, 300, "example.com"),
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("api.example.com", endpoint.RecordTypeA, 60, "1.1.1.1", "3.3.3.3"),
+			endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeCNAME, 600, "example.com"),
+		},
+		Delete: []*endpoint.Endpoint{endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "1.2.3.4")},
+	})
+	require.NoError(t, err)
+
+	// unchanged records are kept, changed ones are updated in place, and the zone of example.net doesn't exist
+	assert.Equal(t, []string{
+		"DELETE r1 @ A 1.2.3.4",
+		"PUT r4 api A 3.3.3.3 60",
+		"PUT r2 www CNAME example.com. 600",
+		"POST new1 app CNAME lb.example.net. 120",
+	}, client.requests)
+
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	sort.Slice(records, func(i, j int) bool { return records[i].DNSName < records[j].DNSName })
+	assert.Equal(t, []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("api.example.com", endpoint.RecordTypeA, 60, "1.1.1.1", "3.3.3.3"),
+		endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeCNAME, 120, "lb.example.net"),
+		endpoint.NewEndpoint("example.org", endpoint.RecordTypeMX, "10 mail.example.org"),
+		endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeCNAME, 600, "example.com"),
+	}, records)
+}
+
+func TestHetznerApplyChangesTargets(t *testing.T) {
+	client := newFakeClient()
+	p := &HetznerProvider{client: client}
+
+	// targets are added and removed without touching the kept ones
+	err := p.ApplyChanges(context.Background(), &plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("api.example.com", endpoint.RecordTypeA, 60, "1.1.1.1", "2.2.2.2")},
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("api.example.com", endpoint.RecordTypeA, 60, "2.2.2.2", "3.3.3.3", "4.4.4.4")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"PUT r3 api A 3.3.3.3 60",
+		"POST new1 api A 4.4.4.4 60",
+	}, client.requests)
+
+	client.requests = nil
+	err = p.ApplyChanges(context.Background(), &plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("api.example.com", endpoint.RecordTypeA, 60, "2.2.2.2", "3.3.3.3", "4.4.4.4")},
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("api.example.com", endpoint.RecordTypeA, 60, "4.4.4.4")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"DELETE r3 api A 3.3.3.3 60",
+		"DELETE r4 api A 2.2.2.2 60",
+	}, sorted(client.requests))
+}
+
+func TestHetznerApplyChangesDryRun(t *testing.T) {
+	client := newFakeClient()
+	p := &HetznerProvider{client: client, dryRun: true}
+
+	err := p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "5.5.5.5")},
+		Delete: []*endpoint.Endpoint{endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "1.2.3.4")},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, client.requests)
+}
+
+func TestNewHetznerProvider(t *testing.T) {
+	t.Setenv("HETZNER_TOKEN", "")
+	_, err := NewHetznerProvider(endpoint.DomainFilter{}, provider.ZoneIDFilter{}, false)
+	assert.EqualError(t, err, "no token found, set the HETZNER_TOKEN environment variable to a Hetzner DNS API token")
+
+	t.Setenv("HETZNER_TOKEN", "secret")
+	p, err := NewHetznerProvider(endpoint.DomainFilter{}, provider.ZoneIDFilter{}, true)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", p.client.(*httpClient).token)
+}
+
+func sorted(s []string) []string {
+	sort.Strings(s)
+	return s
+}
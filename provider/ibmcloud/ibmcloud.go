@@ -19,6 +19,7 @@ package ibmcloud
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"reflect"
 	"strconv"
@@ -214,6 +215,13 @@ func getConfig(configFile string) (*ibmcloudConfig, error) {
 	return cfg, nil
 }
 
+// newHTTPClient returns the HTTP client of the IBM Cloud services, sending the User-Agent of ExternalDNS.
+func newHTTPClient() *http.Client {
+	client := core.DefaultHTTPClient()
+	client.Transport = provider.NewUserAgentTransport(client.Transport)
+	return client
+}
+
 func (c *ibmcloudConfig) Validate(authenticator core.Authenticator, domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter) (ibmcloudService, bool, error) {
 	var service ibmcloudService
 	isPrivate := false
@@ -246,6 +254,7 @@ func (c *ibmcloudConfig) Validate(authenticator core.Authenticator, domainFilter
 		if c.Endpoint != "" {
 			service.publicZonesService.SetServiceURL(c.Endpoint)
 		}
+		service.publicZonesService.Service.SetHTTPClient(newHTTPClient())
 
 		zonesResp, _, err := service.publicZonesService.ListZones(&zonesv1.ListZonesOptions{})
 		if err != nil {
@@ -279,6 +288,7 @@ func (c *ibmcloudConfig) Validate(authenticator core.Authenticator, domainFilter
 		if c.Endpoint != "" {
 			service.publicRecordsService.SetServiceURL(c.Endpoint)
 		}
+		service.publicRecordsService.Service.SetHTTPClient(newHTTPClient())
 	case strings.Contains(crn.ServiceName, "dns-svcs"):
 		isPrivate = true
 		// Private DNS service
@@ -291,6 +301,7 @@ func (c *ibmcloudConfig) Validate(authenticator core.Authenticator, domainFilter
 		if c.Endpoint != "" {
 			service.privateDNSService.SetServiceURL(c.Endpoint)
 		}
+		service.privateDNSService.Service.SetHTTPClient(newHTTPClient())
 	default:
 		return service, isPrivate, fmt.Errorf("IBM Cloud instance crn is not provided or invalid dns crn : %s", c.CRN)
 	}
@@ -19,7 +19,10 @@ package inmemory
 import (
 	"context"
 	"errors"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -51,6 +54,10 @@ type InMemoryProvider struct {
 	filter         *filter
 	OnApplyChanges func(ctx context.Context, changes *plan.Changes)
 	OnRecords      func()
+	// file persists the zones and their records, if set
+	file string
+	// simulator simulates the behavior of the API of the zones, if set
+	simulator *simulator
 }
 
 // InMemoryOption allows to extend in-memory provider
@@ -121,17 +128,59 @@ func (im *InMemoryProvider) Zones() map[string]string {
 	return im.filter.Zones(im.client.Zones())
 }
 
+// ZoneNames returns the names of the zones, see provider.ZoneLister
+func (im *InMemoryProvider) ZoneNames(ctx context.Context) ([]string, error) {
+	names := make([]string, 0)
+	for _, zoneName := range im.Zones() {
+		names = append(names, zoneName)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ZoneVersion returns the number of changes applied to the zone, see provider.ZoneVersioner
+func (im *InMemoryProvider) ZoneVersion(ctx context.Context, zone string) (string, error) {
+	version, ok := im.client.version(zone)
+	if !ok {
+		return "", ErrZoneNotFound
+	}
+	return strconv.FormatInt(version, 10), nil
+}
+
+// CreateOwnedZone adds the zone owned by the owner ID, see provider.ZoneCreator
+func (im *InMemoryProvider) CreateOwnedZone(ctx context.Context, zone, ownerID string) error {
+	return im.client.createOwnedZone(zone, ownerID)
+}
+
+// DeleteOwnedZone deletes the zone if the owner ID owns it and it has no records, see provider.ZoneCreator
+func (im *InMemoryProvider) DeleteOwnedZone(ctx context.Context, zone, ownerID string) (bool, error) {
+	deleted := im.client.deleteOwnedZone(zone, ownerID)
+	if deleted && im.file != "" {
+		return true, im.save()
+	}
+	return deleted, nil
+}
+
 // Records returns the list of endpoints
 func (im *InMemoryProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
 	defer im.OnRecords()
 
 	endpoints := make([]*endpoint.Endpoint, 0)
+	scope, scoped := provider.ZoneScope(ctx)
 
-	for zoneID := range im.Zones() {
+	for zoneID, zoneName := range im.Zones() {
+		if scoped && zoneName != scope {
+			continue
+		}
 		records, err := im.client.Records(zoneID)
 		if err != nil {
 			return nil, err
 		}
+		if im.simulator != nil {
+			if records, err = im.simulator.list(ctx, zoneName, records); err != nil {
+				return nil, err
+			}
+		}
 
 		endpoints = append(endpoints, copyEndpoints(records)...)
 	}
@@ -183,6 +232,17 @@ func (im *InMemoryProvider) ApplyChanges(ctx context.Context, changes *plan.Chan
 		perZoneChanges[zoneID].Delete = append(perZoneChanges[zoneID].Delete, ep)
 	}
 
+	if im.simulator != nil {
+		for zoneID, change := range perZoneChanges {
+			if !change.HasChanges() {
+				continue
+			}
+			if err := im.simulator.request(ctx, zones[zoneID], "applying changes"); err != nil {
+				return err
+			}
+		}
+	}
+
 	for zoneID := range perZoneChanges {
 		change := &plan.Changes{
 			Create:    perZoneChanges[zoneID].Create,
@@ -190,12 +250,26 @@ func (im *InMemoryProvider) ApplyChanges(ctx context.Context, changes *plan.Chan
 			UpdateOld: perZoneChanges[zoneID].UpdateOld,
 			Delete:    perZoneChanges[zoneID].Delete,
 		}
+		var before []*endpoint.Endpoint
+		if im.simulator != nil && change.HasChanges() {
+			records, err := im.client.Records(zoneID)
+			if err != nil {
+				return err
+			}
+			before = copyEndpoints(records)
+		}
 		err := im.client.ApplyChanges(ctx, zoneID, change)
 		if err != nil {
 			return err
 		}
+		if before != nil {
+			im.simulator.applied(zones[zoneID], before)
+		}
 	}
 
+	if im.file != "" {
+		return im.save()
+	}
 	return nil
 }
 
@@ -244,14 +318,22 @@ func (f *filter) EndpointZoneID(endpoint *endpoint.Endpoint, zones map[string]st
 type zone map[endpoint.EndpointKey]*endpoint.Endpoint
 
 type inMemoryClient struct {
+	// mu guards the zones and versions, which are also read by the state handler
+	mu    sync.RWMutex
 	zones map[string]zone
+	// versions count the changes applied to the zones
+	versions map[string]int64
+	// owners are the owner IDs of the zones created with CreateOwnedZone
+	owners map[string]string
 }
 
 func newInMemoryClient() *inMemoryClient {
-	return &inMemoryClient{map[string]zone{}}
+	return &inMemoryClient{zones: map[string]zone{}, versions: map[string]int64{}, owners: map[string]string{}}
 }
 
 func (c *inMemoryClient) Records(zone string) ([]*endpoint.Endpoint, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	if _, ok := c.zones[zone]; !ok {
 		return nil, ErrZoneNotFound
 	}
@@ -264,6 +346,8 @@ func (c *inMemoryClient) Records(zone string) ([]*endpoint.Endpoint, error) {
 }
 
 func (c *inMemoryClient) Zones() map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	zones := map[string]string{}
 	for zone := range c.zones {
 		zones[zone] = zone
@@ -272,15 +356,51 @@ func (c *inMemoryClient) Zones() map[string]string {
 }
 
 func (c *inMemoryClient) CreateZone(zone string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if _, ok := c.zones[zone]; ok {
 		return ErrZoneAlreadyExists
 	}
 	c.zones[zone] = map[endpoint.EndpointKey]*endpoint.Endpoint{}
+	c.versions[zone] = 0
+
+	return nil
+}
 
+func (c *inMemoryClient) createOwnedZone(zone, ownerID string) error {
+	if err := c.CreateZone(zone); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.owners[zone] = ownerID
 	return nil
 }
 
+func (c *inMemoryClient) deleteOwnedZone(zone, ownerID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	records, ok := c.zones[zone]
+	owner, owned := c.owners[zone]
+	if !ok || !owned || owner != ownerID || len(records) > 0 {
+		return false
+	}
+	delete(c.zones, zone)
+	delete(c.versions, zone)
+	delete(c.owners, zone)
+	return true
+}
+
+func (c *inMemoryClient) version(zone string) (int64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	version, ok := c.versions[zone]
+	return version, ok
+}
+
 func (c *inMemoryClient) ApplyChanges(ctx context.Context, zoneID string, changes *plan.Changes) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if err := c.validateChangeBatch(zoneID, changes); err != nil {
 		return err
 	}
@@ -293,6 +413,9 @@ func (c *inMemoryClient) ApplyChanges(ctx context.Context, zoneID string, change
 	for _, deleteEndpoint := range changes.Delete {
 		delete(c.zones[zoneID], deleteEndpoint.Key())
 	}
+	if changes.HasChanges() {
+		c.versions[zoneID]++
+	}
 	return nil
 }
 
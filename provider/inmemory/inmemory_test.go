@@ -19,6 +19,7 @@ package inmemory
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -398,7 +399,7 @@ func testInMemoryValidateChangeBatch(t *testing.T) {
 		},
 	} {
 		t.Run(ti.title, func(t *testing.T) {
-			c := &inMemoryClient{}
+			c := newInMemoryClient()
 			c.zones = ti.init
 			ichanges := &plan.Changes{
 				Create:    ti.changes.Create,
@@ -545,7 +546,7 @@ func testInMemoryApplyChanges(t *testing.T) {
 	} {
 		t.Run(ti.title, func(t *testing.T) {
 			im := NewInMemoryProvider()
-			c := &inMemoryClient{}
+			c := newInMemoryClient()
 			c.zones = getInitData()
 			im.client = c
 
@@ -586,3 +587,99 @@ func makeZone(s ...string) map[endpoint.EndpointKey]*endpoint.Endpoint {
 
 	return output
 }
+
+func TestInMemoryZoneScope(t *testing.T) {
+	im := NewInMemoryProvider(InMemoryInitZones([]string{"sub.example.com", "example.com"}))
+	require.NoError(t, im.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.1.1.1"),
+			endpoint.NewEndpoint("foo.sub.example.com", endpoint.RecordTypeA, "2.2.2.2"),
+		},
+	}))
+
+	zones, err := im.ZoneNames(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com", "sub.example.com"}, zones)
+
+	records, err := im.Records(context.WithValue(context.Background(), provider.ZoneScopeContextKey, "sub.example.com"))
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "foo.sub.example.com", records[0].DNSName)
+
+	records, err = im.Records(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+}
+
+func TestInMemoryZoneVersion(t *testing.T) {
+	im := NewInMemoryProvider(InMemoryInitZones([]string{"sub.example.com", "example.com"}))
+	version, err := im.ZoneVersion(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "0", version)
+
+	require.NoError(t, im.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.1.1.1")},
+	}))
+	version, err = im.ZoneVersion(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "1", version)
+	version, err = im.ZoneVersion(context.Background(), "sub.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "0", version, "zones without changes keep their version")
+
+	_, err = im.ZoneVersion(context.Background(), "example.org")
+	assert.ErrorIs(t, err, ErrZoneNotFound)
+}
+
+func TestInMemoryZoneVersionWrapped(t *testing.T) {
+	im := NewInMemoryProvider(InMemoryInitZones([]string{"example.com"}))
+	var p provider.Provider = provider.NewZoneCreatingProvider(im, im, im, endpoint.NewDomainFilter([]string{"example.com"}), "owner", false)
+	p = provider.NewReadOnlyProvider(p)
+	p = provider.NewTimeoutProvider(p, time.Minute)
+	p = provider.NewCachedProvider(p, time.Minute)
+
+	// the wrappers, e.g. for --dry-run, do not implement ZoneVersion
+	_, ok := p.(provider.ZoneVersioner)
+	assert.False(t, ok)
+
+	versioner, ok := provider.As[provider.ZoneVersioner](p)
+	require.True(t, ok)
+	require.NoError(t, im.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.1.1.1")},
+	}))
+	version, err := versioner.ZoneVersion(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "1", version)
+}
+
+func TestInMemoryOwnedZones(t *testing.T) {
+	ctx := context.Background()
+	im := NewInMemoryProvider(InMemoryInitZones([]string{"example.com"}))
+
+	require.NoError(t, im.CreateOwnedZone(ctx, "example.org", "owner"))
+	assert.ErrorIs(t, im.CreateOwnedZone(ctx, "example.com", "owner"), ErrZoneAlreadyExists)
+	names, err := im.ZoneNames(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com", "example.org"}, names)
+
+	record := endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	require.NoError(t, im.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{record}}))
+	deleted, err := im.DeleteOwnedZone(ctx, "example.org", "owner")
+	require.NoError(t, err)
+	assert.False(t, deleted, "the zone has records")
+
+	require.NoError(t, im.ApplyChanges(ctx, &plan.Changes{Delete: []*endpoint.Endpoint{record}}))
+	deleted, err = im.DeleteOwnedZone(ctx, "example.org", "other")
+	require.NoError(t, err)
+	assert.False(t, deleted, "the zone is owned by another owner")
+	deleted, err = im.DeleteOwnedZone(ctx, "example.com", "")
+	require.NoError(t, err)
+	assert.False(t, deleted, "the zone was not created with an owner")
+	deleted, err = im.DeleteOwnedZone(ctx, "example.org", "owner")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	names, err = im.ZoneNames(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com"}, names)
+}
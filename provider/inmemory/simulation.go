@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// ZoneSimulation is the simulated behavior of the API of a zone.
+type ZoneSimulation struct {
+	// Latency is added to every request, i.e. to every page of records listed and to the changes applied to the zone
+	Latency time.Duration
+	// PageSize is the number of records listed per request, all of them if zero
+	PageSize int
+	// ThrottleRate is the rate of requests rejected as throttled, between 0 and 1
+	ThrottleRate float64
+	// PropagationDelay is the time after which the changes applied to the zone are listed, the records listed
+	// before being those of the zone before the changes
+	PropagationDelay time.Duration
+}
+
+// Simulation is the simulated behavior of the API of the zones.
+type Simulation struct {
+	// Default is the behavior of the zones without their own
+	Default ZoneSimulation
+	// Zones are the behaviors of the zones by zone name
+	Zones map[string]ZoneSimulation
+}
+
+// ParseSimulation parses the simulated behaviors of the specs, each a comma-separated list of key:value pairs
+// optionally prefixed with the zone name and "=", e.g. "latency:20ms,page-size:100" for all zones and
+// "example.org=latency:200ms,throttle-rate:0.1,propagation-delay:30s" for a zone. The behavior of a zone
+// overrides the keys it sets on the behavior of all zones.
+func ParseSimulation(specs []string) (Simulation, error) {
+	sim := Simulation{Zones: map[string]ZoneSimulation{}}
+	zoneSpecs := map[string][]string{}
+	for _, spec := range specs {
+		zone, pairs, ok := strings.Cut(spec, "=")
+		if !ok {
+			if err := parseZoneSimulation(&sim.Default, spec); err != nil {
+				return Simulation{}, err
+			}
+			continue
+		}
+		zone = strings.TrimSuffix(strings.TrimSpace(zone), ".")
+		if zone == "" {
+			return Simulation{}, fmt.Errorf("invalid simulation %q, expected a zone name before =", spec)
+		}
+		zoneSpecs[zone] = append(zoneSpecs[zone], pairs)
+	}
+	for zone, pairs := range zoneSpecs {
+		zoneSim := sim.Default
+		for _, spec := range pairs {
+			if err := parseZoneSimulation(&zoneSim, spec); err != nil {
+				return Simulation{}, fmt.Errorf("zone %s: %w", zone, err)
+			}
+		}
+		sim.Zones[zone] = zoneSim
+	}
+	return sim, nil
+}
+
+func parseZoneSimulation(sim *ZoneSimulation, spec string) error {
+	for _, pair := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return fmt.Errorf("invalid simulation %q, expected key:value", pair)
+		}
+		switch key {
+		case "latency", "propagation-delay":
+			duration, err := time.ParseDuration(value)
+			if err != nil || duration < 0 {
+				return fmt.Errorf("invalid simulation %s %q", key, value)
+			}
+			if key == "latency" {
+				sim.Latency = duration
+			} else {
+				sim.PropagationDelay = duration
+			}
+		case "page-size":
+			size, err := strconv.Atoi(value)
+			if err != nil || size < 0 {
+				return fmt.Errorf("invalid simulation page-size %q", value)
+			}
+			sim.PageSize = size
+		case "throttle-rate":
+			rate, err := strconv.ParseFloat(value, 64)
+			if err != nil || rate < 0 || rate > 1 {
+				return fmt.Errorf("invalid simulation throttle-rate %q, expected a rate between 0 and 1", value)
+			}
+			sim.ThrottleRate = rate
+		default:
+			return fmt.Errorf("unknown simulation %q, expected latency, page-size, throttle-rate or propagation-delay", key)
+		}
+	}
+	return nil
+}
+
+// InMemoryWithSimulation simulates the behavior of the API of the zones, for load tests of the controller
+func InMemoryWithSimulation(sim Simulation) InMemoryOption {
+	return func(p *InMemoryProvider) {
+		p.simulator = newSimulator(sim)
+	}
+}
+
+// pendingChange is a change applied to a zone and not yet listed.
+type pendingChange struct {
+	// listedAt is the time from which the change is listed
+	listedAt time.Time
+	// before are the records of the zone before the change
+	before []*endpoint.Endpoint
+}
+
+// simulator simulates the behavior of the API of the zones.
+type simulator struct {
+	Simulation
+	// mu guards the pending changes
+	mu sync.Mutex
+	// pending are the changes not yet listed by zone name, oldest first
+	pending map[string][]pendingChange
+	// now returns the current time, defaults to time.Now
+	now func() time.Time
+	// random returns a number in [0, 1), defaults to rand.Float64
+	random func() float64
+}
+
+func newSimulator(sim Simulation) *simulator {
+	return &simulator{
+		Simulation: sim,
+		pending:    map[string][]pendingChange{},
+		now:        time.Now,
+		random:     rand.Float64,
+	}
+}
+
+func (s *simulator) zone(zoneName string) ZoneSimulation {
+	if sim, ok := s.Zones[zoneName]; ok {
+		return sim
+	}
+	return s.Default
+}
+
+// request waits for the latency of the zone, and returns a throttling error at its throttle rate.
+func (s *simulator) request(ctx context.Context, zoneName, method string) error {
+	sim := s.zone(zoneName)
+	if sim.Latency > 0 {
+		select {
+		case <-time.After(sim.Latency):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if s.random() < sim.ThrottleRate {
+		log.Debugf("Simulating throttling of %s of zone %s", method, zoneName)
+		return provider.NewSoftError(fmt.Errorf("%s of zone %s: %w", method, zoneName, provider.ErrInjectedThrottling))
+	}
+	return nil
+}
+
+// list returns the records of the zone listed page by page, which are the records before the changes still
+// propagating.
+func (s *simulator) list(ctx context.Context, zoneName string, records []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	records = s.propagated(zoneName, records)
+	pageSize := s.zone(zoneName).PageSize
+	if pageSize == 0 {
+		pageSize = max(len(records), 1)
+	}
+	for listed := 0; listed == 0 || listed < len(records); listed += pageSize {
+		if err := s.request(ctx, zoneName, "listing records"); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// propagated returns the records of the zone before the oldest change still propagating, or the current records.
+func (s *simulator) propagated(zoneName string, current []*endpoint.Endpoint) []*endpoint.Endpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	pending := s.pending[zoneName]
+	for len(pending) > 0 && !now.Before(pending[0].listedAt) {
+		pending = pending[1:]
+	}
+	s.pending[zoneName] = pending
+	if len(pending) > 0 {
+		return pending[0].before
+	}
+	return current
+}
+
+// applied delays the listing of the change applied to the zone, whose records were before before the change.
+func (s *simulator) applied(zoneName string, before []*endpoint.Endpoint) {
+	delay := s.zone(zoneName).PropagationDelay
+	if delay == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending[zoneName] = append(s.pending[zoneName], pendingChange{listedAt: s.now().Add(delay), before: before})
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+func TestParseSimulation(t *testing.T) {
+	sim, err := ParseSimulation([]string{
+		"example.org.=throttle-rate:0.5,propagation-delay:30s",
+		"latency:20ms,page-size:100",
+		"example.org=page-size:10",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Simulation{
+		Default: ZoneSimulation{Latency: 20 * time.Millisecond, PageSize: 100},
+		Zones: map[string]ZoneSimulation{
+			"example.org": {Latency: 20 * time.Millisecond, PageSize: 10, ThrottleRate: 0.5, PropagationDelay: 30 * time.Second},
+		},
+	}, sim)
+
+	for _, spec := range []string{"latency", "latency:soon", "page-size:-1", "throttle-rate:2", "propagation-delay:-1s", "jitter:1ms", "=latency:1ms", "example.org=page-size:x"} {
+		_, err := ParseSimulation([]string{spec})
+		assert.Error(t, err, spec)
+	}
+}
+
+// newSimulatedProvider returns a provider of the zones with the records, whose behavior is simulated at the given
+// time, and the number of requests made to the simulated API.
+func newSimulatedProvider(t *testing.T, sim Simulation, now *time.Time, records map[string]int) (*InMemoryProvider, *int) {
+	im := NewInMemoryProvider(InMemoryWithSimulation(sim))
+	for zone, count := range records {
+		require.NoError(t, im.CreateZone(zone))
+		changes := &plan.Changes{}
+		for i := 0; i < count; i++ {
+			changes.Create = append(changes.Create, endpoint.NewEndpoint(fmt.Sprintf("web-%d.%s", i, zone), endpoint.RecordTypeA, "1.2.3.4"))
+		}
+		require.NoError(t, im.client.ApplyChanges(context.Background(), zone, changes))
+	}
+	requests := 0
+	im.simulator.now = func() time.Time { return *now }
+	im.simulator.random = func() float64 {
+		requests++
+		return 0.5
+	}
+	return im, &requests
+}
+
+func TestSimulationPagination(t *testing.T) {
+	now := time.Now()
+	im, requests := newSimulatedProvider(t, Simulation{
+		Default: ZoneSimulation{PageSize: 10},
+		Zones:   map[string]ZoneSimulation{"example.com": {PageSize: 0}},
+	}, &now, map[string]int{"example.org": 25, "example.com": 25, "example.net": 0})
+
+	records, err := im.Records(context.WithValue(context.Background(), provider.ZoneScopeContextKey, "example.org"))
+	require.NoError(t, err)
+	assert.Len(t, records, 25)
+	assert.Equal(t, 3, *requests)
+
+	// a zone without page size is listed in a single request, like an empty zone
+	*requests = 0
+	records, err = im.Records(context.WithValue(context.Background(), provider.ZoneScopeContextKey, "example.com"))
+	require.NoError(t, err)
+	assert.Len(t, records, 25)
+	_, err = im.Records(context.WithValue(context.Background(), provider.ZoneScopeContextKey, "example.net"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, *requests)
+}
+
+func TestSimulationThrottling(t *testing.T) {
+	now := time.Now()
+	im, _ := newSimulatedProvider(t, Simulation{
+		Zones: map[string]ZoneSimulation{"example.org": {ThrottleRate: 0.6}},
+	}, &now, map[string]int{"example.org": 1, "example.com": 1})
+
+	_, err := im.Records(context.Background())
+	require.ErrorIs(t, err, provider.SoftError)
+	assert.ErrorIs(t, err, provider.ErrInjectedThrottling)
+	_, err = im.Records(context.WithValue(context.Background(), provider.ZoneScopeContextKey, "example.com"))
+	require.NoError(t, err)
+
+	// the changes of a throttled zone are not applied, nor those of the other zones
+	err = im.ApplyChanges(context.Background(), &plan.Changes{Create: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("api.example.com", endpoint.RecordTypeA, "5.6.7.8"),
+		endpoint.NewEndpoint("api.example.org", endpoint.RecordTypeA, "5.6.7.8"),
+	}})
+	require.ErrorIs(t, err, provider.ErrInjectedThrottling)
+	records, err := im.client.Records("example.com")
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+}
+
+func TestSimulationLatency(t *testing.T) {
+	im := NewInMemoryProvider(InMemoryInitZones([]string{"example.org"}), InMemoryWithSimulation(Simulation{
+		Default: ZoneSimulation{Latency: time.Hour},
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := im.Records(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestSimulationPropagationDelay(t *testing.T) {
+	now := time.Now()
+	im, _ := newSimulatedProvider(t, Simulation{
+		Default: ZoneSimulation{PropagationDelay: time.Minute},
+	}, &now, map[string]int{"example.org": 1})
+	ctx := context.Background()
+
+	require.NoError(t, im.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("api.example.org", endpoint.RecordTypeA, "5.6.7.8"),
+	}}))
+	now = now.Add(30 * time.Second)
+	require.NoError(t, im.ApplyChanges(ctx, &plan.Changes{Delete: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("web-0.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+	}}))
+
+	names := func() []string {
+		records, err := im.Records(ctx)
+		require.NoError(t, err)
+		var names []string
+		for _, ep := range records {
+			names = append(names, ep.DNSName)
+		}
+		return names
+	}
+	// the changes are listed one minute after they were applied
+	assert.Equal(t, []string{"web-0.example.org"}, names())
+	now = now.Add(30 * time.Second)
+	assert.ElementsMatch(t, []string{"web-0.example.org", "api.example.org"}, names())
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, []string{"api.example.org"}, names())
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// state is the content of the state file and of the state handler: the records of each zone,
+// sorted by name, type and set identifier.
+type state map[string][]*endpoint.Endpoint
+
+// InMemoryWithFile persists the zones and their records to a JSON file after every change applied.
+// The file is read by Restore.
+func InMemoryWithFile(path string) InMemoryOption {
+	return func(p *InMemoryProvider) {
+		p.file = path
+	}
+}
+
+// Restore adds the zones and records of the file set with InMemoryWithFile, replacing the records of
+// the zones already created. A missing file is ignored, so that the first run starts with empty zones.
+func (im *InMemoryProvider) Restore() error {
+	if im.file == "" {
+		return nil
+	}
+	data, err := os.ReadFile(im.file)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Infof("No inmemory state found at %s", im.file)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading the inmemory state: %w", err)
+	}
+	var s state
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("decoding the inmemory state %s: %w", im.file, err)
+	}
+	im.client.restore(s)
+	log.Infof("Restored %d zones from the inmemory state %s", len(s), im.file)
+	return nil
+}
+
+// save writes the state to the file, replacing it atomically.
+func (im *InMemoryProvider) save() error {
+	data, err := json.MarshalIndent(im.client.state(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding the inmemory state: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(im.file), filepath.Base(im.file)+".tmp-")
+	if err != nil {
+		return fmt.Errorf("creating the inmemory state: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("writing the inmemory state: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing the inmemory state: %w", err)
+	}
+	if err := os.Rename(f.Name(), im.file); err != nil {
+		return fmt.Errorf("replacing the inmemory state: %w", err)
+	}
+	return nil
+}
+
+// StateHandler returns an HTTP handler serving the zones and their records as JSON,
+// in the format of the file set with InMemoryWithFile.
+func (im *InMemoryProvider) StateHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(im.client.state()); err != nil {
+			log.Warnf("Failed to write the inmemory state: %v", err)
+		}
+	})
+}
+
+func (c *inMemoryClient) state() state {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	s := state{}
+	for name, z := range c.zones {
+		records := make([]*endpoint.Endpoint, 0, len(z))
+		for _, record := range z {
+			records = append(records, record)
+		}
+		sort.Slice(records, func(i, j int) bool {
+			if records[i].DNSName != records[j].DNSName {
+				return records[i].DNSName < records[j].DNSName
+			}
+			if records[i].RecordType != records[j].RecordType {
+				return records[i].RecordType < records[j].RecordType
+			}
+			return records[i].SetIdentifier < records[j].SetIdentifier
+		})
+		s[name] = records
+	}
+	return s
+}
+
+func (c *inMemoryClient) restore(s state) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, records := range s {
+		z := zone{}
+		for _, record := range records {
+			if record.Labels == nil {
+				record.Labels = endpoint.NewLabels()
+			}
+			z[record.Key()] = record
+		}
+		c.zones[name] = z
+		if _, ok := c.versions[name]; !ok {
+			c.versions[name] = 0
+		}
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func TestInMemoryStateIsPersisted(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "state.json")
+	im := NewInMemoryProvider(InMemoryInitZones([]string{"example.org"}), InMemoryWithFile(file))
+	require.NoError(t, im.Restore())
+
+	record := endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4")
+	record.Labels[endpoint.OwnerLabelKey] = "default"
+	require.NoError(t, im.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{record, endpoint.NewEndpoint("bar.example.org", endpoint.RecordTypeA, "5.6.7.8")},
+	}))
+
+	restored := NewInMemoryProvider(InMemoryInitZones([]string{"example.org", "example.com"}), InMemoryWithFile(file))
+	require.NoError(t, restored.Restore())
+	assert.Equal(t, map[string]string{"example.org": "example.org", "example.com": "example.com"}, restored.Zones())
+	records, err := restored.Records(context.Background())
+	require.NoError(t, err)
+	assert.True(t, testutils.SameEndpoints(records, []*endpoint.Endpoint{record, endpoint.NewEndpoint("bar.example.org", endpoint.RecordTypeA, "5.6.7.8")}))
+
+	var s state
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &s))
+	if assert.Len(t, s["example.org"], 2) {
+		// the records are sorted for deterministic files
+		assert.Equal(t, "bar.example.org", s["example.org"][0].DNSName)
+		assert.Equal(t, "default", s["example.org"][1].Labels[endpoint.OwnerLabelKey])
+	}
+}
+
+func TestInMemoryRestoreWithoutFile(t *testing.T) {
+	im := NewInMemoryProvider(InMemoryWithFile(filepath.Join(t.TempDir(), "missing.json")))
+	assert.NoError(t, im.Restore())
+	assert.Empty(t, im.Zones())
+
+	invalid := filepath.Join(t.TempDir(), "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte("{"), 0o600))
+	assert.Error(t, NewInMemoryProvider(InMemoryWithFile(invalid)).Restore())
+}
+
+func TestInMemoryStateHandler(t *testing.T) {
+	im := NewInMemoryProvider(InMemoryInitZones([]string{"example.org"}))
+	require.NoError(t, im.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4")},
+	}))
+
+	rec := httptest.NewRecorder()
+	im.StateHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/inmemory/state", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var s state
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &s))
+	if assert.Len(t, s["example.org"], 1) {
+		assert.Equal(t, "foo.example.org", s["example.org"][0].DNSName)
+	}
+
+	rec = httptest.NewRecorder()
+	im.StateHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/inmemory/state", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
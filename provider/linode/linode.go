@@ -23,9 +23,11 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/linode/linodego"
 	log "github.com/sirupsen/logrus"
+	"go.uber.org/ratelimit"
 	"golang.org/x/oauth2"
 
 	"sigs.k8s.io/external-dns/endpoint"
@@ -76,8 +78,40 @@ type LinodeChangeDelete struct {
 	DomainRecord linodego.DomainRecord
 }
 
-// NewLinodeProvider initializes a new Linode DNS based Provider.
-func NewLinodeProvider(domainFilter endpoint.DomainFilter, dryRun bool, appVersion string) (*LinodeProvider, error) {
+// rateLimitedClient paces the requests of a LinodeDomainClient.
+type rateLimitedClient struct {
+	LinodeDomainClient
+	limiter ratelimit.Limiter
+}
+
+func (c rateLimitedClient) ListDomainRecords(ctx context.Context, domainID int, opts *linodego.ListOptions) ([]linodego.DomainRecord, error) {
+	c.limiter.Take()
+	return c.LinodeDomainClient.ListDomainRecords(ctx, domainID, opts)
+}
+
+func (c rateLimitedClient) ListDomains(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Domain, error) {
+	c.limiter.Take()
+	return c.LinodeDomainClient.ListDomains(ctx, opts)
+}
+
+func (c rateLimitedClient) CreateDomainRecord(ctx context.Context, domainID int, domainrecord linodego.DomainRecordCreateOptions) (*linodego.DomainRecord, error) {
+	c.limiter.Take()
+	return c.LinodeDomainClient.CreateDomainRecord(ctx, domainID, domainrecord)
+}
+
+func (c rateLimitedClient) DeleteDomainRecord(ctx context.Context, domainID int, id int) error {
+	c.limiter.Take()
+	return c.LinodeDomainClient.DeleteDomainRecord(ctx, domainID, id)
+}
+
+func (c rateLimitedClient) UpdateDomainRecord(ctx context.Context, domainID int, id int, domainrecord linodego.DomainRecordUpdateOptions) (*linodego.DomainRecord, error) {
+	c.limiter.Take()
+	return c.LinodeDomainClient.UpdateDomainRecord(ctx, domainID, id, domainrecord)
+}
+
+// NewLinodeProvider initializes a new Linode DNS based Provider. The requests are limited to apiRateLimit per second
+// if it is positive.
+func NewLinodeProvider(domainFilter endpoint.DomainFilter, dryRun bool, apiRateLimit float64) (*LinodeProvider, error) {
 	token, ok := os.LookupEnv("LINODE_TOKEN")
 	if !ok {
 		return nil, fmt.Errorf("no token found")
@@ -88,14 +122,20 @@ func NewLinodeProvider(domainFilter endpoint.DomainFilter, dryRun bool, appVersi
 	oauth2Client := &http.Client{
 		Transport: &oauth2.Transport{
 			Source: tokenSource,
+			Base:   provider.NewAPICallCounter(nil),
 		},
 	}
 
 	linodeClient := linodego.NewClient(oauth2Client)
-	linodeClient.SetUserAgent(fmt.Sprintf("ExternalDNS/%s linodego/%s", appVersion, linodego.Version))
+	linodeClient.SetUserAgent(fmt.Sprintf("%s linodego/%s", provider.UserAgent(), linodego.Version))
+
+	var client LinodeDomainClient = &linodeClient
+	if apiRateLimit > 0 {
+		client = rateLimitedClient{LinodeDomainClient: client, limiter: ratelimit.New(1, ratelimit.Per(time.Duration(float64(time.Second)/apiRateLimit)))}
+	}
 
 	provider := &LinodeProvider{
-		Client:       &linodeClient,
+		Client:       client,
 		domainFilter: domainFilter,
 		DryRun:       dryRun,
 	}
@@ -137,7 +177,7 @@ func (p *LinodeProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, err
 					name = zone.Domain
 				}
 
-				endpoints = append(endpoints, endpoint.NewEndpointWithTTL(name, string(r.Type), endpoint.TTL(r.TTLSec), r.Target))
+				endpoints = append(endpoints, endpoint.NewEndpointWithTTL(name, string(r.Type), endpoint.TTL(r.TTLSec), recordTarget(r)))
 			}
 		}
 	}
@@ -261,6 +301,56 @@ func getPriority() *int {
 	return &priority
 }
 
+// newRecordOptions returns the options of the record of a target of an endpoint. The target of an SRV record is the
+// host of the target of the endpoint, and its priority, weight and port are those of the target, see
+// endpoint.SRVTarget.
+func newRecordOptions(zone linodego.Domain, ep endpoint.Endpoint, recordType linodego.DomainRecordType, target string) linodego.DomainRecordCreateOptions {
+	options := linodego.DomainRecordCreateOptions{
+		Target:   target,
+		Name:     getStrippedRecordName(zone, ep),
+		Type:     recordType,
+		Weight:   getWeight(recordType),
+		Port:     getPort(),
+		Priority: getPriority(),
+		TTLSec:   int(ep.RecordTTL),
+	}
+	if recordType == linodego.RecordTypeSRV {
+		if srv, err := endpoint.ParseSRVTarget(target); err == nil {
+			priority, weight, port := int(srv.Priority), int(srv.Weight), int(srv.Port)
+			options.Target = srv.Host
+			options.Priority = &priority
+			options.Weight = &weight
+			options.Port = &port
+		}
+	}
+	return options
+}
+
+// recordTarget returns the target of the endpoint of a record, see newRecordOptions.
+func recordTarget(record linodego.DomainRecord) string {
+	if record.Type != linodego.RecordTypeSRV {
+		return record.Target
+	}
+	return endpoint.SRVTarget{
+		Priority: uint16(record.Priority),
+		Weight:   uint16(record.Weight),
+		Port:     uint16(record.Port),
+		Host:     record.Target,
+	}.String()
+}
+
+// recordUpToDate returns whether the record already has the target, TTL and, for SRV records, the priority,
+// weight and port of the update. The other record types have no priority, weight or port to compare.
+func recordUpToDate(record linodego.DomainRecord, options linodego.DomainRecordUpdateOptions) bool {
+	if record.Target != options.Target || record.TTLSec != options.TTLSec {
+		return false
+	}
+	if options.Type != linodego.RecordTypeSRV {
+		return true
+	}
+	return record.Priority == *options.Priority && record.Weight == *options.Weight && record.Port == *options.Port
+}
+
 // ApplyChanges applies a given set of changes in a given zone.
 func (p *LinodeProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
 	recordsByZoneID := make(map[string][]linodego.DomainRecord)
@@ -279,20 +369,25 @@ func (p *LinodeProvider) ApplyChanges(ctx context.Context, changes *plan.Changes
 		zonesByID[strconv.Itoa(z.ID)] = z
 	}
 
-	// Fetch records for each zone
+	createsByZone := endpointsByZone(zoneNameIDMapper, changes.Create)
+	updatesByZone := endpointsByZone(zoneNameIDMapper, changes.UpdateNew)
+	deletesByZone := endpointsByZone(zoneNameIDMapper, changes.Delete)
+
+	// Fetch records only for the zones with changes
 	for _, zone := range zones {
+		zoneID := strconv.Itoa(zone.ID)
+		if len(createsByZone[zoneID]) == 0 && len(updatesByZone[zoneID]) == 0 && len(deletesByZone[zoneID]) == 0 {
+			continue
+		}
+
 		records, err := p.fetchRecords(ctx, zone.ID)
 		if err != nil {
 			return err
 		}
 
-		recordsByZoneID[strconv.Itoa(zone.ID)] = append(recordsByZoneID[strconv.Itoa(zone.ID)], records...)
+		recordsByZoneID[zoneID] = append(recordsByZoneID[zoneID], records...)
 	}
 
-	createsByZone := endpointsByZone(zoneNameIDMapper, changes.Create)
-	updatesByZone := endpointsByZone(zoneNameIDMapper, changes.UpdateNew)
-	deletesByZone := endpointsByZone(zoneNameIDMapper, changes.Delete)
-
 	var linodeCreates []LinodeChangeCreate
 	var linodeUpdates []LinodeChangeUpdate
 	var linodeDeletes []LinodeChangeDelete
@@ -331,16 +426,8 @@ func (p *LinodeProvider) ApplyChanges(ctx context.Context, changes *plan.Changes
 
 			for _, target := range ep.Targets {
 				linodeCreates = append(linodeCreates, LinodeChangeCreate{
-					Domain: zone,
-					Options: linodego.DomainRecordCreateOptions{
-						Target:   target,
-						Name:     getStrippedRecordName(zone, ep),
-						Type:     recordType,
-						Weight:   getWeight(recordType),
-						Port:     getPort(),
-						Priority: getPriority(),
-						TTLSec:   int(ep.RecordTTL),
-					},
+					Domain:  zone,
+					Options: newRecordOptions(zone, ep, recordType, target),
 				})
 			}
 		}
@@ -380,11 +467,15 @@ func (p *LinodeProvider) ApplyChanges(ctx context.Context, changes *plan.Changes
 			matchedRecordsByTarget := make(map[string]linodego.DomainRecord)
 
 			for _, record := range matchedRecords {
-				matchedRecordsByTarget[record.Target] = record
+				matchedRecordsByTarget[recordTarget(record)] = record
 			}
 
 			for _, target := range ep.Targets {
-				if record, ok := matchedRecordsByTarget[target]; ok {
+				options := linodego.DomainRecordUpdateOptions(newRecordOptions(zone, ep, recordType, target))
+				if record, ok := matchedRecordsByTarget[target]; ok && recordUpToDate(record, options) {
+					// The record is already up to date, skip the request
+					delete(matchedRecordsByTarget, target)
+				} else if ok {
 					log.WithFields(log.Fields{
 						"zoneID":     zoneID,
 						"dnsName":    ep.DNSName,
@@ -396,15 +487,7 @@ func (p *LinodeProvider) ApplyChanges(ctx context.Context, changes *plan.Changes
 					linodeUpdates = append(linodeUpdates, LinodeChangeUpdate{
 						Domain:       zone,
 						DomainRecord: record,
-						Options: linodego.DomainRecordUpdateOptions{
-							Target:   target,
-							Name:     getStrippedRecordName(zone, ep),
-							Type:     recordType,
-							Weight:   getWeight(recordType),
-							Port:     getPort(),
-							Priority: getPriority(),
-							TTLSec:   int(ep.RecordTTL),
-						},
+						Options:      options,
 					})
 
 					delete(matchedRecordsByTarget, target)
@@ -419,16 +502,8 @@ func (p *LinodeProvider) ApplyChanges(ctx context.Context, changes *plan.Changes
 					}).Warn("Creating New Target")
 
 					linodeCreates = append(linodeCreates, LinodeChangeCreate{
-						Domain: zone,
-						Options: linodego.DomainRecordCreateOptions{
-							Target:   target,
-							Name:     getStrippedRecordName(zone, ep),
-							Type:     recordType,
-							Weight:   getWeight(recordType),
-							Port:     getPort(),
-							Priority: getPriority(),
-							TTLSec:   int(ep.RecordTTL),
-						},
+						Domain:  zone,
+						Options: newRecordOptions(zone, ep, recordType, target),
 					})
 				}
 			}
@@ -440,7 +515,7 @@ func (p *LinodeProvider) ApplyChanges(ctx context.Context, changes *plan.Changes
 					"dnsName":    ep.DNSName,
 					"zoneName":   zone.Domain,
 					"recordType": ep.RecordType,
-					"target":     record.Target,
+					"target":     recordTarget(record),
 				}).Warn("Deleting Target")
 
 				linodeDeletes = append(linodeDeletes, LinodeChangeDelete{
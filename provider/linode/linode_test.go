@@ -25,6 +25,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/ratelimit"
 
 	"sigs.k8s.io/external-dns/endpoint"
 	"sigs.k8s.io/external-dns/plan"
@@ -145,11 +146,11 @@ func TestLinodeConvertRecordType(t *testing.T) {
 
 func TestNewLinodeProvider(t *testing.T) {
 	_ = os.Setenv("LINODE_TOKEN", "xxxxxxxxxxxxxxxxx")
-	_, err := NewLinodeProvider(endpoint.NewDomainFilter([]string{"ext-dns-test.zalando.to."}), true, "1.0")
+	_, err := NewLinodeProvider(endpoint.NewDomainFilter([]string{"ext-dns-test.zalando.to."}), true, 0)
 	require.NoError(t, err)
 
 	_ = os.Unsetenv("LINODE_TOKEN")
-	_, err = NewLinodeProvider(endpoint.NewDomainFilter([]string{"ext-dns-test.zalando.to."}), true, "1.0")
+	_, err = NewLinodeProvider(endpoint.NewDomainFilter([]string{"ext-dns-test.zalando.to."}), true, 0)
 	require.Error(t, err)
 }
 
@@ -419,18 +420,7 @@ func TestLinodeApplyChangesTargetAdded(t *testing.T) {
 		mock.Anything,
 	).Return([]linodego.DomainRecord{{ID: 11, Name: "", Type: "A", Target: "targetA"}}, nil).Once()
 
-	// Apply Actions
-	mockDomainClient.On(
-		"UpdateDomainRecord",
-		mock.Anything,
-		1,
-		11,
-		linodego.DomainRecordUpdateOptions{
-			Type: "A", Name: "", Target: "targetA",
-			Priority: getPriority(), Weight: getWeight(linodego.RecordTypeA), Port: getPort(),
-		},
-	).Return(&linodego.DomainRecord{}, nil).Once()
-
+	// Apply Actions, the record of targetA being up to date
 	mockDomainClient.On(
 		"CreateDomainRecord",
 		mock.Anything,
@@ -478,18 +468,7 @@ func TestLinodeApplyChangesTargetRemoved(t *testing.T) {
 		mock.Anything,
 	).Return([]linodego.DomainRecord{{ID: 11, Name: "", Type: "A", Target: "targetA"}, {ID: 12, Type: "A", Name: "", Target: "targetB"}}, nil).Once()
 
-	// Apply Actions
-	mockDomainClient.On(
-		"UpdateDomainRecord",
-		mock.Anything,
-		1,
-		12,
-		linodego.DomainRecordUpdateOptions{
-			Type: "A", Name: "", Target: "targetB",
-			Priority: getPriority(), Weight: getWeight(linodego.RecordTypeA), Port: getPort(),
-		},
-	).Return(&linodego.DomainRecord{}, nil).Once()
-
+	// Apply Actions, the record of targetB being up to date
 	mockDomainClient.On(
 		"DeleteDomainRecord",
 		mock.Anything,
@@ -527,15 +506,94 @@ func TestLinodeApplyChangesNoChanges(t *testing.T) {
 		mock.Anything,
 	).Return([]linodego.Domain{{Domain: "example.com", ID: 1}}, nil).Once()
 
+	err := provider.ApplyChanges(context.Background(), &plan.Changes{})
+	require.NoError(t, err)
+
+	// the records of the zones without changes are not fetched
+	mockDomainClient.AssertExpectations(t)
+	mockDomainClient.AssertNotCalled(t, "ListDomainRecords", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLinodeApplyChangesRateLimited(t *testing.T) {
+	mockDomainClient := MockDomainClient{}
+
+	provider := &LinodeProvider{
+		Client:       rateLimitedClient{LinodeDomainClient: &mockDomainClient, limiter: ratelimit.New(1000)},
+		domainFilter: endpoint.NewDomainFilter([]string{}),
+		DryRun:       false,
+	}
+
+	mockDomainClient.On(
+		"ListDomains",
+		mock.Anything,
+		mock.Anything,
+	).Return([]linodego.Domain{{Domain: "example.com", ID: 1}, {Domain: "example.org", ID: 2}}, nil).Once()
+
 	mockDomainClient.On(
 		"ListDomainRecords",
 		mock.Anything,
 		1,
 		mock.Anything,
-	).Return([]linodego.DomainRecord{{ID: 11, Name: "", Type: "A", Target: "targetA"}}, nil).Once()
+	).Return([]linodego.DomainRecord{{ID: 11, Name: "", Type: "A", Target: "targetA", TTLSec: 300}}, nil).Once()
 
-	err := provider.ApplyChanges(context.Background(), &plan.Changes{})
+	mockDomainClient.On(
+		"UpdateDomainRecord",
+		mock.Anything,
+		1,
+		11,
+		linodego.DomainRecordUpdateOptions{
+			Type: "A", Name: "", Target: "targetA", TTLSec: 600,
+			Priority: getPriority(), Weight: getWeight(linodego.RecordTypeA), Port: getPort(),
+		},
+	).Return(&linodego.DomainRecord{}, nil).Once()
+
+	err := provider.ApplyChanges(context.Background(), &plan.Changes{
+		// the TTL of the record changes
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("example.com", "A", 600, "targetA")},
+	})
 	require.NoError(t, err)
 
 	mockDomainClient.AssertExpectations(t)
+	mockDomainClient.AssertNotCalled(t, "ListDomainRecords", mock.Anything, 2, mock.Anything)
+}
+
+func TestLinodeRecordUpToDate(t *testing.T) {
+	srv := linodego.DomainRecordUpdateOptions{
+		Type: linodego.RecordTypeSRV, Target: "target", TTLSec: 300,
+		Priority: getPriority(), Weight: getWeight(linodego.RecordTypeSRV), Port: getPort(),
+	}
+	record := linodego.DomainRecord{Type: linodego.RecordTypeSRV, Target: "target", TTLSec: 300, Priority: 0, Weight: 1, Port: 0}
+	assert.True(t, recordUpToDate(record, srv))
+
+	for _, changed := range []func(*linodego.DomainRecord){
+		func(r *linodego.DomainRecord) { r.Target = "other" },
+		func(r *linodego.DomainRecord) { r.TTLSec = 600 },
+		func(r *linodego.DomainRecord) { r.Priority = 10 },
+		func(r *linodego.DomainRecord) { r.Weight = 5 },
+		func(r *linodego.DomainRecord) { r.Port = 8080 },
+	} {
+		outdated := record
+		changed(&outdated)
+		assert.False(t, recordUpToDate(outdated, srv), "%+v", outdated)
+	}
+
+	// the priority, weight and port of the other record types are not compared
+	a := srv
+	a.Type = linodego.RecordTypeA
+	assert.True(t, recordUpToDate(linodego.DomainRecord{Type: linodego.RecordTypeA, Target: "target", TTLSec: 300}, a))
+}
+
+func TestLinodeSRVRecordOptions(t *testing.T) {
+	zone := linodego.Domain{Domain: "example.com"}
+	ep := endpoint.Endpoint{DNSName: "_sip._tcp.example.com", RecordType: endpoint.RecordTypeSRV, RecordTTL: 300}
+
+	options := newRecordOptions(zone, ep, linodego.RecordTypeSRV, "10 5 5060 sip.example.com")
+	assert.Equal(t, "sip.example.com", options.Target)
+	assert.Equal(t, 10, *options.Priority)
+	assert.Equal(t, 5, *options.Weight)
+	assert.Equal(t, 5060, *options.Port)
+
+	record := linodego.DomainRecord{Type: linodego.RecordTypeSRV, Target: "sip.example.com", Priority: 10, Weight: 5, Port: 5060}
+	assert.Equal(t, "10 5 5060 sip.example.com", recordTarget(record))
+	assert.Equal(t, "1.2.3.4", recordTarget(linodego.DomainRecord{Type: linodego.RecordTypeA, Target: "1.2.3.4", Port: 80}))
 }
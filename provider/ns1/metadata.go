@@ -0,0 +1,445 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ns1
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	api "gopkg.in/ns1/ns1-go.v2/rest"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/data"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/dns"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/filter"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const (
+	// ns1UpKey is the property of the up/down status of the answers of the endpoint: true, false, or feed
+	// to take it from the data feed of the endpoint.
+	ns1UpKey = "ns1/up"
+	// ns1GeoregionKey is the property of the comma-separated georegions of the answers of the endpoint, e.g. US-EAST.
+	ns1GeoregionKey = "ns1/georegion"
+	// ns1CountryKey is the property of the comma-separated ISO 3166 country codes of the answers of the endpoint.
+	ns1CountryKey = "ns1/country"
+	// ns1PriorityKey is the property of the priority of the answers of the endpoint, the lowest being served first.
+	ns1PriorityKey = "ns1/priority"
+
+	// ns1UpFeed is the value of the up/down status taken from the data feed of the endpoint.
+	ns1UpFeed = "feed"
+	// ns1SetIdentifierNote prefixes the note of the answers of an endpoint with a set identifier.
+	ns1SetIdentifierNote = "external-dns set-identifier="
+	// ns1FeedLabelPrefix prefixes the labels of the data feeds managed by ExternalDNS.
+	ns1FeedLabelPrefix = "external-dns:"
+)
+
+// ns1Georegions are the georegions of the answers known to NS1.
+var ns1Georegions = []string{"US-EAST", "US-CENTRAL", "US-WEST", "EUROPE", "ASIAPAC", "SOUTH-AMERICA", "AFRICA"}
+
+// ns1MetadataKeys are the properties describing the metadata of the answers of an endpoint.
+var ns1MetadataKeys = []string{ns1UpKey, ns1GeoregionKey, ns1CountryKey, ns1PriorityKey}
+
+// hasMetadata returns true if the answers of the endpoint have metadata or must be told from the answers of the
+// other endpoints of the record by their set identifier.
+func hasMetadata(ep *endpoint.Endpoint) bool {
+	if ep.SetIdentifier != "" {
+		return true
+	}
+	for _, key := range ns1MetadataKeys {
+		if _, ok := ep.GetProviderSpecificProperty(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMetadata returns the metadata of the answers of the endpoint described by its properties, except the
+// up/down status taken from a data feed, which is set with the feed.
+func parseMetadata(ep *endpoint.Endpoint) (*data.Meta, error) {
+	meta := &data.Meta{}
+	if value, ok := ep.GetProviderSpecificProperty(ns1UpKey); ok {
+		switch value {
+		case "true", "false":
+			meta.Up = value == "true"
+		case ns1UpFeed:
+		default:
+			return nil, fmt.Errorf("invalid %s %q, expected true, false or %s", ns1UpKey, value, ns1UpFeed)
+		}
+	}
+	if value, ok := ep.GetProviderSpecificProperty(ns1GeoregionKey); ok {
+		regions := splitList(value)
+		for _, region := range regions {
+			if !slices.Contains(ns1Georegions, region) {
+				return nil, fmt.Errorf("invalid %s %q, expected %s", ns1GeoregionKey, region, strings.Join(ns1Georegions, ", "))
+			}
+		}
+		meta.Georegion = regions
+	}
+	if value, ok := ep.GetProviderSpecificProperty(ns1CountryKey); ok {
+		countries := splitList(value)
+		for _, country := range countries {
+			if len(country) != 2 {
+				return nil, fmt.Errorf("invalid %s %q, expected ISO 3166 alpha-2 codes", ns1CountryKey, country)
+			}
+		}
+		meta.Country = countries
+	}
+	if value, ok := ep.GetProviderSpecificProperty(ns1PriorityKey); ok {
+		priority, err := strconv.Atoi(value)
+		if err != nil || priority < 0 {
+			return nil, fmt.Errorf("invalid %s %q, expected a non-negative integer", ns1PriorityKey, value)
+		}
+		meta.Priority = priority
+	}
+	if ep.SetIdentifier != "" {
+		meta.Note = ns1SetIdentifierNote + ep.SetIdentifier
+	}
+	return meta, nil
+}
+
+// splitList returns the upper case values of the comma-separated list.
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.ToUpper(strings.TrimSpace(v)); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// usesFeed returns true if the up/down status of the answers of the endpoint is taken from its data feed.
+func usesFeed(ep *endpoint.Endpoint) bool {
+	value, ok := ep.GetProviderSpecificProperty(ns1UpKey)
+	return ok && value == ns1UpFeed
+}
+
+// feedLabel returns the label of the data feed of the endpoint, which the health checkers publish its status with.
+func feedLabel(ep *endpoint.Endpoint) string {
+	label := ns1FeedLabelPrefix + strings.TrimSuffix(ep.DNSName, ".") + ":" + ep.RecordType
+	if ep.SetIdentifier != "" {
+		label += ":" + ep.SetIdentifier
+	}
+	return label
+}
+
+// adjustMetadata normalizes the properties describing the metadata of the answers of the endpoint, and removes them
+// if they are invalid, or if the up/down status is taken from a feed without data source.
+func (p *NS1Provider) adjustMetadata(ep *endpoint.Endpoint) {
+	meta, err := parseMetadata(ep)
+	if err != nil {
+		log.Warnf("Ignoring the NS1 metadata of %s: %v", ep.DNSName, err)
+		for _, key := range ns1MetadataKeys {
+			ep.DeleteProviderSpecificProperty(key)
+		}
+		return
+	}
+	if usesFeed(ep) && p.dataSourceID == "" {
+		log.Warnf("Ignoring the up/down feed of %s, data feeds are not managed without --ns1-data-source-id", ep.DNSName)
+		ep.DeleteProviderSpecificProperty(ns1UpKey)
+	}
+	if regions, ok := meta.Georegion.([]string); ok {
+		ep.SetProviderSpecificProperty(ns1GeoregionKey, strings.Join(regions, ","))
+	}
+	if countries, ok := meta.Country.([]string); ok {
+		ep.SetProviderSpecificProperty(ns1CountryKey, strings.Join(countries, ","))
+	}
+	if priority, ok := meta.Priority.(int); ok {
+		ep.SetProviderSpecificProperty(ns1PriorityKey, strconv.Itoa(priority))
+	}
+}
+
+// metadataEndpoints returns the endpoints of the answers of the record, grouped by the set identifier in their note.
+func metadataEndpoints(record *dns.Record, feeds map[string]string) []*endpoint.Endpoint {
+	var endpoints []*endpoint.Endpoint
+	bySetIdentifier := map[string]*endpoint.Endpoint{}
+	for _, answer := range record.Answers {
+		meta := answer.Meta
+		if meta == nil {
+			meta = &data.Meta{}
+		}
+		setIdentifier := answerSetIdentifier(answer)
+		if ep, ok := bySetIdentifier[setIdentifier]; ok {
+			ep.Targets = append(ep.Targets, strings.Join(answer.Rdata, " "))
+			continue
+		}
+		ep := endpoint.NewEndpointWithTTL(record.Domain, record.Type, endpoint.TTL(record.TTL), strings.Join(answer.Rdata, " ")).
+			WithSetIdentifier(setIdentifier)
+		setMetadataProperties(ep, meta, feeds)
+		bySetIdentifier[setIdentifier] = ep
+		endpoints = append(endpoints, ep)
+	}
+	return endpoints
+}
+
+// answerSetIdentifier returns the set identifier of the endpoint of the answer, from its note.
+func answerSetIdentifier(answer *dns.Answer) string {
+	if answer.Meta == nil {
+		return ""
+	}
+	if note, ok := answer.Meta.Note.(string); ok && strings.HasPrefix(note, ns1SetIdentifierNote) {
+		return strings.TrimPrefix(note, ns1SetIdentifierNote)
+	}
+	return ""
+}
+
+// setMetadataProperties sets the properties of the endpoint describing the metadata of its answers. The up/down
+// status taken from a data feed managed by ExternalDNS is described as such.
+func setMetadataProperties(ep *endpoint.Endpoint, meta *data.Meta, feeds map[string]string) {
+	switch up := meta.Up.(type) {
+	case bool:
+		ep.SetProviderSpecificProperty(ns1UpKey, strconv.FormatBool(up))
+	case map[string]interface{}:
+		if feedID, ok := up["feed"].(string); ok && feeds[feedID] == feedLabel(ep) {
+			ep.SetProviderSpecificProperty(ns1UpKey, ns1UpFeed)
+		}
+	case data.FeedPtr:
+		if feeds[up.FeedID] == feedLabel(ep) {
+			ep.SetProviderSpecificProperty(ns1UpKey, ns1UpFeed)
+		}
+	}
+	if values := metaStrings(meta.Georegion); len(values) > 0 {
+		ep.SetProviderSpecificProperty(ns1GeoregionKey, strings.Join(values, ","))
+	}
+	if values := metaStrings(meta.Country); len(values) > 0 {
+		ep.SetProviderSpecificProperty(ns1CountryKey, strings.Join(values, ","))
+	}
+	switch priority := meta.Priority.(type) {
+	case int:
+		ep.SetProviderSpecificProperty(ns1PriorityKey, strconv.Itoa(priority))
+	case float64:
+		ep.SetProviderSpecificProperty(ns1PriorityKey, strconv.Itoa(int(priority)))
+	}
+}
+
+// metaStrings returns the strings of a metadata value, as set or as decoded from JSON.
+func metaStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// metadataFilters returns the filter chain serving the answers depending on their metadata: the answers down are
+// removed, the answers are sorted by proximity or priority, and the first one is served.
+func metadataFilters(answers []*dns.Answer) []*filter.Filter {
+	var up, country, georegion, priority bool
+	for _, answer := range answers {
+		if answer.Meta == nil {
+			continue
+		}
+		up = up || answer.Meta.Up != nil
+		country = country || answer.Meta.Country != nil
+		georegion = georegion || answer.Meta.Georegion != nil
+		priority = priority || answer.Meta.Priority != nil
+	}
+	filters := []*filter.Filter{}
+	if up {
+		filters = append(filters, filter.NewUp())
+	}
+	if priority {
+		filters = append(filters, filter.NewPriority())
+	}
+	if country {
+		filters = append(filters, filter.NewGeotargetCountry())
+	}
+	if georegion {
+		filters = append(filters, filter.NewGeotargetRegional())
+	}
+	if country || georegion {
+		filters = append(filters, filter.NewSelFirstN(1))
+	}
+	return filters
+}
+
+// ns1RecordKey identifies the record of the endpoints of a name and type in a zone.
+type ns1RecordKey struct {
+	zone       string
+	domain     string
+	recordType string
+}
+
+// ns1RecordChanges are the endpoints of a record removed and added by the changes.
+type ns1RecordChanges struct {
+	removed []*endpoint.Endpoint
+	added   []*endpoint.Endpoint
+}
+
+// submitMetadataChanges replaces the answers of the endpoints removed from the record by the answers of the endpoints
+// added to it, with their metadata, and creates the data feeds of the added endpoints and deletes the feeds of the
+// removed ones.
+func (p *NS1Provider) submitMetadataChanges(key ns1RecordKey, changes *ns1RecordChanges, feeds *ns1Feeds) error {
+	current, _, err := p.client.GetRecord(key.zone, key.domain, key.recordType)
+	exists := err == nil
+	if err != nil && !errors.Is(err, api.ErrRecordMissing) {
+		return err
+	}
+	record := dns.NewRecord(key.zone, key.domain, key.recordType, map[string]string{}, []string{})
+	if exists {
+		record.Answers = current.Answers
+		record.TTL = current.TTL
+	}
+
+	removed := map[string]bool{}
+	for _, ep := range changes.removed {
+		removed[ep.SetIdentifier] = true
+	}
+	for _, ep := range changes.added {
+		removed[ep.SetIdentifier] = true
+	}
+	answers := make([]*dns.Answer, 0, len(record.Answers))
+	for _, answer := range record.Answers {
+		if !removed[answerSetIdentifier(answer)] {
+			answers = append(answers, answer)
+		}
+	}
+
+	labels := map[string]bool{}
+	for _, ep := range changes.added {
+		meta, err := parseMetadata(ep)
+		if err != nil {
+			return err
+		}
+		if usesFeed(ep) {
+			label := feedLabel(ep)
+			labels[label] = true
+			feedID, err := feeds.ensure(label)
+			if err != nil {
+				return fmt.Errorf("creating the data feed %s: %w", label, err)
+			}
+			meta.Up = data.FeedPtr{FeedID: feedID}
+		}
+		for _, target := range ep.Targets {
+			answer := dns.NewAnswer(strings.Split(target, " "))
+			copied := *meta
+			answer.Meta = &copied
+			answers = append(answers, answer)
+		}
+		record.TTL = p.recordTTL(ep)
+	}
+	record.Answers = answers
+	record.Filters = metadataFilters(answers)
+
+	logFields := log.Fields{
+		"record": record.Domain,
+		"type":   record.Type,
+		"ttl":    record.TTL,
+		"zone":   key.zone,
+	}
+	if !p.dryRun {
+		switch {
+		case len(answers) == 0 && exists:
+			log.WithFields(logFields).WithField("action", ns1Delete).Info("Changing record.")
+			_, err = p.client.DeleteRecord(key.zone, key.domain, key.recordType)
+		case len(answers) == 0:
+		case exists:
+			log.WithFields(logFields).WithField("action", ns1Update).Info("Changing record.")
+			_, err = p.client.UpdateRecord(record)
+		default:
+			log.WithFields(logFields).WithField("action", ns1Create).Info("Changing record.")
+			_, err = p.client.CreateRecord(record)
+		}
+		if err != nil {
+			return err
+		}
+	} else {
+		log.WithFields(logFields).Info("Changing record.")
+	}
+
+	// the feeds no longer referenced are deleted once the record is changed
+	for _, ep := range changes.removed {
+		if label := feedLabel(ep); usesFeed(ep) && !labels[label] {
+			if err := feeds.delete(label); err != nil {
+				return fmt.Errorf("deleting the data feed %s: %w", label, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ns1Feeds are the data feeds of the data source, listed once by label.
+type ns1Feeds struct {
+	p      *NS1Provider
+	byID   map[string]string
+	labels map[string]string
+}
+
+// listFeeds returns the data feeds managed by ExternalDNS of the data source, by ID, if there is a data source.
+func (p *NS1Provider) listFeeds() (*ns1Feeds, error) {
+	feeds := &ns1Feeds{p: p, byID: map[string]string{}, labels: map[string]string{}}
+	if p.dataSourceID == "" {
+		return feeds, nil
+	}
+	list, _, err := p.client.ListFeeds(p.dataSourceID)
+	if err != nil {
+		return nil, fmt.Errorf("listing the data feeds of the data source %s: %w", p.dataSourceID, err)
+	}
+	for _, feed := range list {
+		if label, ok := feed.Config["label"].(string); ok && strings.HasPrefix(label, ns1FeedLabelPrefix) {
+			feeds.byID[feed.ID] = label
+			feeds.labels[label] = feed.ID
+		}
+	}
+	return feeds, nil
+}
+
+// ensure returns the ID of the data feed with the label, which is created if it doesn't exist.
+func (f *ns1Feeds) ensure(label string) (string, error) {
+	if id, ok := f.labels[label]; ok {
+		return id, nil
+	}
+	log.Infof("Creating the NS1 data feed %s", label)
+	feed := data.NewFeed(label, data.Config{"label": label})
+	if !f.p.dryRun {
+		if _, err := f.p.client.CreateFeed(f.p.dataSourceID, feed); err != nil {
+			return "", err
+		}
+	}
+	f.byID[feed.ID] = label
+	f.labels[label] = feed.ID
+	return feed.ID, nil
+}
+
+// delete deletes the data feed with the label, if it exists.
+func (f *ns1Feeds) delete(label string) error {
+	id, ok := f.labels[label]
+	if !ok {
+		return nil
+	}
+	log.Infof("Deleting the NS1 data feed %s", label)
+	if !f.p.dryRun {
+		if _, err := f.p.client.DeleteFeed(f.p.dataSourceID, id); err != nil {
+			return err
+		}
+	}
+	delete(f.labels, label)
+	delete(f.byID, id)
+	return nil
+}
@@ -0,0 +1,271 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ns1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "gopkg.in/ns1/ns1-go.v2/rest"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/data"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/dns"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/filter"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// fakeNS1MetadataClient keeps the records and the data feeds of the zone foo.com.
+type fakeNS1MetadataClient struct {
+	records map[string]*dns.Record
+	feeds   map[string]*data.Feed
+	nextID  int
+}
+
+func newFakeNS1MetadataClient() *fakeNS1MetadataClient {
+	return &fakeNS1MetadataClient{records: map[string]*dns.Record{}, feeds: map[string]*data.Feed{}}
+}
+
+func (c *fakeNS1MetadataClient) CreateRecord(r *dns.Record) (*http.Response, error) {
+	if _, ok := c.records[r.Domain+"/"+r.Type]; ok {
+		return nil, api.ErrRecordExists
+	}
+	c.records[r.Domain+"/"+r.Type] = r
+	return nil, nil
+}
+
+func (c *fakeNS1MetadataClient) DeleteRecord(zone string, domain string, t string) (*http.Response, error) {
+	if _, ok := c.records[domain+"/"+t]; !ok {
+		return nil, api.ErrRecordMissing
+	}
+	delete(c.records, domain+"/"+t)
+	return nil, nil
+}
+
+func (c *fakeNS1MetadataClient) UpdateRecord(r *dns.Record) (*http.Response, error) {
+	if _, ok := c.records[r.Domain+"/"+r.Type]; !ok {
+		return nil, api.ErrRecordMissing
+	}
+	c.records[r.Domain+"/"+r.Type] = r
+	return nil, nil
+}
+
+func (c *fakeNS1MetadataClient) GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error) {
+	r, ok := c.records[domain+"/"+t]
+	if !ok {
+		return nil, nil, api.ErrRecordMissing
+	}
+	return r, nil, nil
+}
+
+func (c *fakeNS1MetadataClient) GetZone(zone string) (*dns.Zone, *http.Response, error) {
+	z := &dns.Zone{Zone: "foo.com", ID: "12345678910111213141516a"}
+	for _, r := range c.records {
+		tier := json.Number("1")
+		if len(r.Filters) > 0 {
+			tier = "3"
+		}
+		var shortAns []string
+		for _, a := range r.Answers {
+			shortAns = append(shortAns, strings.Join(a.Rdata, " "))
+		}
+		z.Records = append(z.Records, &dns.ZoneRecord{Domain: r.Domain, Type: r.Type, TTL: r.TTL, Tier: tier, ShortAns: shortAns})
+	}
+	return z, nil, nil
+}
+
+func (c *fakeNS1MetadataClient) ListZones() ([]*dns.Zone, *http.Response, error) {
+	return []*dns.Zone{{Zone: "foo.com", ID: "12345678910111213141516a"}}, nil, nil
+}
+
+func (c *fakeNS1MetadataClient) ListFeeds(sourceID string) ([]*data.Feed, *http.Response, error) {
+	feeds := make([]*data.Feed, 0, len(c.feeds))
+	for _, feed := range c.feeds {
+		feeds = append(feeds, feed)
+	}
+	return feeds, nil, nil
+}
+
+func (c *fakeNS1MetadataClient) CreateFeed(sourceID string, feed *data.Feed) (*http.Response, error) {
+	c.nextID++
+	feed.ID = fmt.Sprintf("feed-%d", c.nextID)
+	feed.SourceID = sourceID
+	c.feeds[feed.ID] = feed
+	return nil, nil
+}
+
+func (c *fakeNS1MetadataClient) DeleteFeed(sourceID string, feedID string) (*http.Response, error) {
+	if _, ok := c.feeds[feedID]; !ok {
+		return nil, errors.New("feed not found")
+	}
+	delete(c.feeds, feedID)
+	return nil, nil
+}
+
+func newMetadataTestProvider(client NS1DomainClient) *NS1Provider {
+	return &NS1Provider{
+		client:       client,
+		domainFilter: endpoint.NewDomainFilter([]string{"foo.com."}),
+		zoneIDFilter: provider.NewZoneIDFilter([]string{""}),
+		dataSourceID: "source",
+	}
+}
+
+func TestNS1ParseMetadata(t *testing.T) {
+	ep := endpoint.NewEndpoint("a.foo.com", endpoint.RecordTypeA, "1.1.1.1").
+		WithSetIdentifier("east").
+		WithProviderSpecific(ns1UpKey, "false").
+		WithProviderSpecific(ns1GeoregionKey, "us-east, us-central").
+		WithProviderSpecific(ns1CountryKey, "us").
+		WithProviderSpecific(ns1PriorityKey, "2")
+	meta, err := parseMetadata(ep)
+	require.NoError(t, err)
+	assert.Equal(t, false, meta.Up)
+	assert.Equal(t, []string{"US-EAST", "US-CENTRAL"}, meta.Georegion)
+	assert.Equal(t, []string{"US"}, meta.Country)
+	assert.Equal(t, 2, meta.Priority)
+	assert.Equal(t, "external-dns set-identifier=east", meta.Note)
+
+	meta, err = parseMetadata(endpoint.NewEndpoint("a.foo.com", endpoint.RecordTypeA, "1.1.1.1").WithProviderSpecific(ns1UpKey, ns1UpFeed))
+	require.NoError(t, err)
+	assert.Nil(t, meta.Up)
+
+	for key, value := range map[string]string{
+		ns1UpKey:        "maybe",
+		ns1GeoregionKey: "MARS",
+		ns1CountryKey:   "USA",
+		ns1PriorityKey:  "-1",
+	} {
+		_, err := parseMetadata(endpoint.NewEndpoint("a.foo.com", endpoint.RecordTypeA, "1.1.1.1").WithProviderSpecific(key, value))
+		assert.Error(t, err, key)
+	}
+}
+
+func TestNS1AdjustEndpoints(t *testing.T) {
+	p := newMetadataTestProvider(newFakeNS1MetadataClient())
+	endpoints, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("a.foo.com", endpoint.RecordTypeA, "1.1.1.1").
+			WithProviderSpecific(ns1GeoregionKey, "us-east , europe").
+			WithProviderSpecific(ns1PriorityKey, "01"),
+		endpoint.NewEndpoint("b.foo.com", endpoint.RecordTypeA, "1.1.1.1").
+			WithProviderSpecific(ns1UpKey, "true").
+			WithProviderSpecific(ns1CountryKey, "Mars"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, endpoint.ProviderSpecific{
+		{Name: ns1GeoregionKey, Value: "US-EAST,EUROPE"},
+		{Name: ns1PriorityKey, Value: "1"},
+	}, endpoints[0].ProviderSpecific)
+	// the invalid metadata is ignored altogether
+	assert.Empty(t, endpoints[1].ProviderSpecific)
+
+	// the feeds are not managed without data source
+	p.dataSourceID = ""
+	endpoints, err = p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("a.foo.com", endpoint.RecordTypeA, "1.1.1.1").WithProviderSpecific(ns1UpKey, ns1UpFeed),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, endpoints[0].ProviderSpecific)
+}
+
+func TestNS1MetadataFilters(t *testing.T) {
+	assert.Empty(t, metadataFilters([]*dns.Answer{dns.NewAnswer([]string{"1.1.1.1"})}))
+
+	answers := []*dns.Answer{
+		{Rdata: []string{"1.1.1.1"}, Meta: &data.Meta{Up: true, Priority: 1}},
+		{Rdata: []string{"2.2.2.2"}, Meta: &data.Meta{Up: data.FeedPtr{FeedID: "feed"}, Priority: 2}},
+	}
+	assert.Equal(t, []*filter.Filter{filter.NewUp(), filter.NewPriority()}, metadataFilters(answers))
+
+	answers = []*dns.Answer{
+		{Rdata: []string{"1.1.1.1"}, Meta: &data.Meta{Georegion: []string{"US-EAST"}}},
+		{Rdata: []string{"2.2.2.2"}, Meta: &data.Meta{Country: []string{"DE"}}},
+	}
+	assert.Equal(t, []*filter.Filter{filter.NewGeotargetCountry(), filter.NewGeotargetRegional(), filter.NewSelFirstN(1)}, metadataFilters(answers))
+}
+
+func TestNS1ApplyMetadataChanges(t *testing.T) {
+	client := newFakeNS1MetadataClient()
+	p := newMetadataTestProvider(client)
+	ctx := context.Background()
+
+	east := endpoint.NewEndpointWithTTL("a.foo.com", endpoint.RecordTypeA, 60, "1.1.1.1", "1.1.1.2").
+		WithSetIdentifier("east").
+		WithProviderSpecific(ns1UpKey, ns1UpFeed).
+		WithProviderSpecific(ns1GeoregionKey, "US-EAST")
+	west := endpoint.NewEndpointWithTTL("a.foo.com", endpoint.RecordTypeA, 60, "2.2.2.2").
+		WithSetIdentifier("west").
+		WithProviderSpecific(ns1UpKey, "true").
+		WithProviderSpecific(ns1GeoregionKey, "US-WEST")
+	plain := endpoint.NewEndpointWithTTL("b.foo.com", endpoint.RecordTypeA, 60, "3.3.3.3")
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{east, west, plain}}))
+
+	record := client.records["a.foo.com/A"]
+	require.NotNil(t, record)
+	assert.Len(t, record.Answers, 3)
+	assert.Equal(t, 60, record.TTL)
+	assert.Equal(t, []*filter.Filter{filter.NewUp(), filter.NewGeotargetRegional(), filter.NewSelFirstN(1)}, record.Filters)
+	require.Len(t, client.feeds, 1)
+	for id, feed := range client.feeds {
+		assert.Equal(t, "external-dns:a.foo.com:A:east", feed.Config["label"])
+		assert.Equal(t, data.FeedPtr{FeedID: id}, record.Answers[0].Meta.Up)
+	}
+	assert.Empty(t, client.records["b.foo.com/A"].Filters)
+
+	// the endpoints are listed back with their set identifiers and properties
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*endpoint.Endpoint{east, west, plain}, records)
+
+	// the answers of the other set identifiers are kept, and the feeds no longer used are deleted
+	westUp := west.DeepCopy().WithProviderSpecific(ns1UpKey, "false")
+	eastUp := east.DeepCopy().WithProviderSpecific(ns1UpKey, "true")
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{west, east},
+		UpdateNew: []*endpoint.Endpoint{westUp, eastUp},
+	}))
+	record = client.records["a.foo.com/A"]
+	assert.Len(t, record.Answers, 3)
+	assert.Empty(t, client.feeds)
+	assert.Equal(t, []*filter.Filter{filter.NewUp(), filter.NewGeotargetRegional(), filter.NewSelFirstN(1)}, record.Filters)
+
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Delete: []*endpoint.Endpoint{eastUp}}))
+	assert.Len(t, client.records["a.foo.com/A"].Answers, 1)
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Delete: []*endpoint.Endpoint{westUp}}))
+	assert.NotContains(t, client.records, "a.foo.com/A")
+}
+
+func TestNS1ApplyMetadataChangesDryRun(t *testing.T) {
+	client := newFakeNS1MetadataClient()
+	p := newMetadataTestProvider(client)
+	p.dryRun = true
+
+	ep := endpoint.NewEndpoint("a.foo.com", endpoint.RecordTypeA, "1.1.1.1").
+		WithSetIdentifier("east").
+		WithProviderSpecific(ns1UpKey, ns1UpFeed)
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{Create: []*endpoint.Endpoint{ep}}))
+	assert.Empty(t, client.records)
+	assert.Empty(t, client.feeds)
+}
@@ -22,10 +22,12 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
 	api "gopkg.in/ns1/ns1-go.v2/rest"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/data"
 	"gopkg.in/ns1/ns1-go.v2/rest/model/dns"
 
 	"sigs.k8s.io/external-dns/endpoint"
@@ -51,6 +53,10 @@ type NS1DomainClient interface {
 	UpdateRecord(r *dns.Record) (*http.Response, error)
 	GetZone(zone string) (*dns.Zone, *http.Response, error)
 	ListZones() ([]*dns.Zone, *http.Response, error)
+	GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error)
+	ListFeeds(sourceID string) ([]*data.Feed, *http.Response, error)
+	CreateFeed(sourceID string, feed *data.Feed) (*http.Response, error)
+	DeleteFeed(sourceID string, feedID string) (*http.Response, error)
 }
 
 // NS1DomainService wraps the API and fulfills the NS1DomainClient interface
@@ -83,6 +89,26 @@ func (n NS1DomainService) ListZones() ([]*dns.Zone, *http.Response, error) {
 	return n.service.Zones.List()
 }
 
+// GetRecord wraps the Get method of the API's Record service
+func (n NS1DomainService) GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error) {
+	return n.service.Records.Get(zone, domain, t)
+}
+
+// ListFeeds wraps the List method of the API's DataFeeds service
+func (n NS1DomainService) ListFeeds(sourceID string) ([]*data.Feed, *http.Response, error) {
+	return n.service.DataFeeds.List(sourceID)
+}
+
+// CreateFeed wraps the Create method of the API's DataFeeds service
+func (n NS1DomainService) CreateFeed(sourceID string, feed *data.Feed) (*http.Response, error) {
+	return n.service.DataFeeds.Create(sourceID, feed)
+}
+
+// DeleteFeed wraps the Delete method of the API's DataFeeds service
+func (n NS1DomainService) DeleteFeed(sourceID string, feedID string) (*http.Response, error) {
+	return n.service.DataFeeds.Delete(sourceID, feedID)
+}
+
 // NS1Config passes cli args to the NS1Provider
 type NS1Config struct {
 	DomainFilter  endpoint.DomainFilter
@@ -91,6 +117,8 @@ type NS1Config struct {
 	NS1IgnoreSSL  bool
 	DryRun        bool
 	MinTTLSeconds int
+	// DataSourceID is the ID of the NS1 API data source the data feeds of the answers are created in
+	DataSourceID string
 }
 
 // NS1Provider is the NS1 provider
@@ -101,6 +129,7 @@ type NS1Provider struct {
 	zoneIDFilter  provider.ZoneIDFilter
 	dryRun        bool
 	minTTLSeconds int
+	dataSourceID  string
 }
 
 // NewNS1Provider creates a new NS1 Provider
@@ -113,7 +142,7 @@ func newNS1ProviderWithHTTPClient(config NS1Config, client *http.Client) (*NS1Pr
 	if !ok {
 		return nil, fmt.Errorf("NS1_APIKEY environment variable is not set")
 	}
-	clientArgs := []func(*api.Client){api.SetAPIKey(token)}
+	clientArgs := []func(*api.Client){api.SetAPIKey(token), api.SetUserAgent(provider.UserAgent())}
 	if config.NS1Endpoint != "" {
 		log.Infof("ns1-endpoint flag is set, targeting endpoint at %s", config.NS1Endpoint)
 		clientArgs = append(clientArgs, api.SetEndpoint(config.NS1Endpoint))
@@ -140,7 +169,9 @@ func newNS1ProviderWithHTTPClient(config NS1Config, client *http.Client) (*NS1Pr
 		client:        NS1DomainService{apiClient},
 		domainFilter:  config.DomainFilter,
 		zoneIDFilter:  config.ZoneIDFilter,
+		dryRun:        config.DryRun,
 		minTTLSeconds: config.MinTTLSeconds,
+		dataSourceID:  config.DataSourceID,
 	}
 	return provider, nil
 }
@@ -152,6 +183,11 @@ func (p *NS1Provider) Records(ctx context.Context) ([]*endpoint.Endpoint, error)
 		return nil, err
 	}
 
+	feeds, err := p.listFeeds()
+	if err != nil {
+		return nil, err
+	}
+
 	var endpoints []*endpoint.Endpoint
 
 	for _, zone := range zones {
@@ -162,7 +198,17 @@ func (p *NS1Provider) Records(ctx context.Context) ([]*endpoint.Endpoint, error)
 		}
 
 		for _, record := range zoneData.Records {
-			if provider.SupportedRecordType(record.Type) {
+			if !provider.SupportedRecordType(record.Type) {
+				continue
+			}
+			// the records of higher tiers have metadata, which is listed with the record
+			if record.Tier != "" && record.Tier != "1" {
+				full, _, err := p.client.GetRecord(zone.Zone, record.Domain, record.Type)
+				if err != nil {
+					return nil, err
+				}
+				endpoints = append(endpoints, metadataEndpoints(full, feeds.byID)...)
+			} else {
 				endpoints = append(endpoints, endpoint.NewEndpointWithTTL(
 					record.Domain,
 					record.Type,
@@ -183,17 +229,21 @@ func (p *NS1Provider) ns1BuildRecord(zoneName string, change *ns1Change) *dns.Re
 	for _, v := range change.Endpoint.Targets {
 		record.AddAnswer(dns.NewAnswer(strings.Split(v, " ")))
 	}
-	// set default ttl, but respect minTTLSeconds
+	record.TTL = p.recordTTL(change.Endpoint)
+
+	return record
+}
+
+// recordTTL returns the TTL of the endpoint, or the default ttl respecting minTTLSeconds
+func (p *NS1Provider) recordTTL(ep *endpoint.Endpoint) int {
+	if ep.RecordTTL.IsConfigured() {
+		return int(ep.RecordTTL)
+	}
 	ttl := ns1DefaultTTL
 	if p.minTTLSeconds > ttl {
 		ttl = p.minTTLSeconds
 	}
-	if change.Endpoint.RecordTTL.IsConfigured() {
-		ttl = int(change.Endpoint.RecordTTL)
-	}
-	record.TTL = ttl
-
-	return record
+	return ttl
 }
 
 // ns1SubmitChanges takes an array of changes and sends them to NS1
@@ -278,14 +328,108 @@ type ns1Change struct {
 }
 
 // ApplyChanges applies a given set of changes in a given zone.
+// The records of the endpoints with metadata or set identifiers are changed as a whole, since their answers are
+// shared by several endpoints.
 func (p *NS1Provider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	metadataChanges, changes := splitMetadataChanges(changes)
+
 	combinedChanges := make([]*ns1Change, 0, len(changes.Create)+len(changes.UpdateNew)+len(changes.Delete))
 
 	combinedChanges = append(combinedChanges, newNS1Changes(ns1Create, changes.Create)...)
 	combinedChanges = append(combinedChanges, newNS1Changes(ns1Update, changes.UpdateNew)...)
 	combinedChanges = append(combinedChanges, newNS1Changes(ns1Delete, changes.Delete)...)
 
-	return p.ns1SubmitChanges(combinedChanges)
+	if err := p.ns1SubmitChanges(combinedChanges); err != nil {
+		return err
+	}
+	if len(metadataChanges) == 0 {
+		return nil
+	}
+
+	zones, err := p.zonesFiltered()
+	if err != nil {
+		return err
+	}
+	zoneNameIDMapper := provider.ZoneIDName{}
+	for _, z := range zones {
+		zoneNameIDMapper.Add(z.Zone, z.Zone)
+	}
+	feeds, err := p.listFeeds()
+	if err != nil {
+		return err
+	}
+	for key, recordChanges := range metadataChanges {
+		zone, _ := zoneNameIDMapper.FindZone(key.domain)
+		if zone == "" {
+			log.Debugf("Skipping record %s because no hosted zone matching record DNS Name was detected", key.domain)
+			continue
+		}
+		key.zone = zone
+		if err := p.submitMetadataChanges(key, recordChanges, feeds); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AdjustEndpoints normalizes the properties describing the metadata of the answers of the endpoints.
+func (p *NS1Provider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	for _, ep := range endpoints {
+		p.adjustMetadata(ep)
+	}
+	return endpoints, nil
+}
+
+// splitMetadataChanges separates the changes of the records with endpoints with metadata or set identifiers, by name
+// and type, from the other changes.
+func splitMetadataChanges(changes *plan.Changes) (map[ns1RecordKey]*ns1RecordChanges, *plan.Changes) {
+	keys := map[ns1RecordKey]bool{}
+	for _, ep := range slices.Concat(changes.Create, changes.UpdateOld, changes.UpdateNew, changes.Delete) {
+		if hasMetadata(ep) {
+			keys[ns1RecordKey{domain: ep.DNSName, recordType: ep.RecordType}] = true
+		}
+	}
+	metadataChanges := map[ns1RecordKey]*ns1RecordChanges{}
+	recordChanges := func(ep *endpoint.Endpoint) *ns1RecordChanges {
+		key := ns1RecordKey{domain: ep.DNSName, recordType: ep.RecordType}
+		if !keys[key] {
+			return nil
+		}
+		if _, ok := metadataChanges[key]; !ok {
+			metadataChanges[key] = &ns1RecordChanges{}
+		}
+		return metadataChanges[key]
+	}
+
+	other := &plan.Changes{}
+	for _, ep := range changes.Create {
+		if c := recordChanges(ep); c != nil {
+			c.added = append(c.added, ep)
+		} else {
+			other.Create = append(other.Create, ep)
+		}
+	}
+	for i, ep := range changes.UpdateNew {
+		if c := recordChanges(ep); c != nil {
+			c.added = append(c.added, ep)
+			if i < len(changes.UpdateOld) {
+				c.removed = append(c.removed, changes.UpdateOld[i])
+			}
+		} else {
+			other.UpdateNew = append(other.UpdateNew, ep)
+			if i < len(changes.UpdateOld) {
+				other.UpdateOld = append(other.UpdateOld, changes.UpdateOld[i])
+			}
+		}
+	}
+	for _, ep := range changes.Delete {
+		if c := recordChanges(ep); c != nil {
+			c.removed = append(c.removed, ep)
+		} else {
+			other.Delete = append(other.Delete, ep)
+		}
+	}
+	return metadataChanges, other
 }
 
 // newNS1Changes returns a collection of Changes based on the given records and action.
@@ -27,6 +27,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	api "gopkg.in/ns1/ns1-go.v2/rest"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/data"
 	"gopkg.in/ns1/ns1-go.v2/rest/model/dns"
 
 	"sigs.k8s.io/external-dns/endpoint"
@@ -50,6 +51,22 @@ func (m *MockNS1DomainClient) UpdateRecord(r *dns.Record) (*http.Response, error
 	return nil, nil
 }
 
+func (m *MockNS1DomainClient) GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error) {
+	return nil, nil, api.ErrRecordMissing
+}
+
+func (m *MockNS1DomainClient) ListFeeds(sourceID string) ([]*data.Feed, *http.Response, error) {
+	return nil, nil, nil
+}
+
+func (m *MockNS1DomainClient) CreateFeed(sourceID string, feed *data.Feed) (*http.Response, error) {
+	return nil, nil
+}
+
+func (m *MockNS1DomainClient) DeleteFeed(sourceID string, feedID string) (*http.Response, error) {
+	return nil, nil
+}
+
 func (m *MockNS1DomainClient) GetZone(zone string) (*dns.Zone, *http.Response, error) {
 	r := &dns.ZoneRecord{
 		Domain:   "test.foo.com",
@@ -93,6 +110,22 @@ func (m *MockNS1GetZoneFail) UpdateRecord(r *dns.Record) (*http.Response, error)
 	return nil, nil
 }
 
+func (m *MockNS1GetZoneFail) GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error) {
+	return nil, nil, api.ErrRecordMissing
+}
+
+func (m *MockNS1GetZoneFail) ListFeeds(sourceID string) ([]*data.Feed, *http.Response, error) {
+	return nil, nil, nil
+}
+
+func (m *MockNS1GetZoneFail) CreateFeed(sourceID string, feed *data.Feed) (*http.Response, error) {
+	return nil, nil
+}
+
+func (m *MockNS1GetZoneFail) DeleteFeed(sourceID string, feedID string) (*http.Response, error) {
+	return nil, nil
+}
+
 func (m *MockNS1GetZoneFail) GetZone(zone string) (*dns.Zone, *http.Response, error) {
 	return nil, nil, api.ErrZoneMissing
 }
@@ -119,6 +152,22 @@ func (m *MockNS1ListZonesFail) UpdateRecord(r *dns.Record) (*http.Response, erro
 	return nil, nil
 }
 
+func (m *MockNS1ListZonesFail) GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error) {
+	return nil, nil, api.ErrRecordMissing
+}
+
+func (m *MockNS1ListZonesFail) ListFeeds(sourceID string) ([]*data.Feed, *http.Response, error) {
+	return nil, nil, nil
+}
+
+func (m *MockNS1ListZonesFail) CreateFeed(sourceID string, feed *data.Feed) (*http.Response, error) {
+	return nil, nil
+}
+
+func (m *MockNS1ListZonesFail) DeleteFeed(sourceID string, feedID string) (*http.Response, error) {
+	return nil, nil
+}
+
 func (m *MockNS1ListZonesFail) GetZone(zone string) (*dns.Zone, *http.Response, error) {
 	return &dns.Zone{}, nil, nil
 }
@@ -156,8 +205,9 @@ func TestNewNS1Provider(t *testing.T) {
 		ZoneIDFilter: provider.NewZoneIDFilter([]string{""}),
 		DryRun:       false,
 	}
-	_, err := NewNS1Provider(testNS1Config)
+	p, err := NewNS1Provider(testNS1Config)
 	require.NoError(t, err)
+	assert.Equal(t, provider.UserAgent(), p.client.(NS1DomainService).service.UserAgent)
 
 	_ = os.Unsetenv("NS1_APIKEY")
 	_, err = NewNS1Provider(testNS1Config)
@@ -19,6 +19,7 @@ package oci
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -94,7 +95,6 @@ func LoadOCIConfig(path string) (*OCIConfig, error) {
 
 // NewOCIProvider initializes a new OCI DNS based Provider.
 func NewOCIProvider(cfg OCIConfig, domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, zoneScope string, dryRun bool) (*OCIProvider, error) {
-	var client ociDNSClient
 	var err error
 	var configProvider common.ConfigurationProvider
 	if cfg.Auth.UseInstancePrincipal && cfg.Auth.UseWorkloadIdentity {
@@ -128,13 +128,16 @@ func NewOCIProvider(cfg OCIConfig, domainFilter endpoint.DomainFilter, zoneIDFil
 		)
 	}
 
-	client, err = dns.NewDnsClientWithConfigurationProvider(configProvider)
+	dnsClient, err := dns.NewDnsClientWithConfigurationProvider(configProvider)
 	if err != nil {
 		return nil, fmt.Errorf("initializing OCI DNS API client: %w", err)
 	}
+	if httpClient, ok := dnsClient.HTTPClient.(*http.Client); ok {
+		httpClient.Transport = provider.NewUserAgentTransport(httpClient.Transport)
+	}
 
 	return &OCIProvider{
-		client:       client,
+		client:       dnsClient,
 		cfg:          cfg,
 		domainFilter: domainFilter,
 		zoneIDFilter: zoneIDFilter,
@@ -30,7 +30,6 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"sigs.k8s.io/external-dns/endpoint"
-	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
 	"sigs.k8s.io/external-dns/plan"
 	"sigs.k8s.io/external-dns/provider"
 
@@ -108,7 +107,7 @@ func NewOVHProvider(ctx context.Context, domainFilter endpoint.DomainFilter, end
 		return nil, err
 	}
 
-	client.UserAgent = externaldns.Version
+	client.UserAgent = provider.UserAgent()
 
 	// TODO: Add Dry Run support
 	if dryRun {
@@ -57,6 +57,8 @@ const (
 	retryLimit = 3
 	// time in milliseconds
 	retryAfterTime = 250 * time.Millisecond
+	// commentAccount is the account of the rrset comments set by ExternalDNS, the other comments are left untouched
+	commentAccount = "external-dns"
 )
 
 // PDNSConfig is comprised of the fields necessary to create a new PDNSProvider
@@ -127,26 +129,26 @@ func stringifyHTTPResponseBody(r *http.Response) (body string) {
 // PDNSAPIProvider : Interface used and extended by the PDNSAPIClient struct as
 // well as mock APIClients used in testing
 type PDNSAPIProvider interface {
-	ListZones() ([]pgo.Zone, *http.Response, error)
+	ListZones(ctx context.Context) ([]pgo.Zone, *http.Response, error)
 	PartitionZones(zones []pgo.Zone) ([]pgo.Zone, []pgo.Zone)
-	ListZone(zoneID string) (pgo.Zone, *http.Response, error)
-	PatchZone(zoneID string, zoneStruct pgo.Zone) (*http.Response, error)
+	ListZone(ctx context.Context, zoneID string) (pgo.Zone, *http.Response, error)
+	PatchZone(ctx context.Context, zoneID string, zoneStruct pgo.Zone) (*http.Response, error)
 }
 
 // PDNSAPIClient : Struct that encapsulates all the PowerDNS specific implementation details
 type PDNSAPIClient struct {
 	dryRun       bool
 	serverID     string
-	authCtx      context.Context
+	apiKey       pgo.APIKey
 	client       *pgo.APIClient
 	domainFilter endpoint.DomainFilter
 }
 
 // ListZones : Method returns all enabled zones from PowerDNS
 // ref: https://doc.powerdns.com/authoritative/http-api/zone.html#get--servers-server_id-zones
-func (c *PDNSAPIClient) ListZones() (zones []pgo.Zone, resp *http.Response, err error) {
+func (c *PDNSAPIClient) ListZones(ctx context.Context) (zones []pgo.Zone, resp *http.Response, err error) {
 	for i := 0; i < retryLimit; i++ {
-		zones, resp, err = c.client.ZonesApi.ListZones(c.authCtx, c.serverID)
+		zones, resp, err = c.client.ZonesApi.ListZones(c.authContext(ctx), c.serverID)
 		if err != nil {
 			log.Debugf("Unable to fetch zones %v", err)
 			log.Debugf("Retrying ListZones() ... %d", i)
@@ -160,6 +162,11 @@ func (c *PDNSAPIClient) ListZones() (zones []pgo.Zone, resp *http.Response, err
 	return zones, resp, err
 }
 
+// authContext returns the context of a request authenticated with the API key.
+func (c *PDNSAPIClient) authContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, pgo.ContextAPIKey, c.apiKey)
+}
+
 // PartitionZones : Method returns a slice of zones that adhere to the domain filter and a slice of ones that does not adhere to the filter
 func (c *PDNSAPIClient) PartitionZones(zones []pgo.Zone) (filteredZones []pgo.Zone, residualZones []pgo.Zone) {
 	if c.domainFilter.IsConfigured() {
@@ -178,9 +185,9 @@ func (c *PDNSAPIClient) PartitionZones(zones []pgo.Zone) (filteredZones []pgo.Zo
 
 // ListZone : Method returns the details of a specific zone from PowerDNS
 // ref: https://doc.powerdns.com/authoritative/http-api/zone.html#get--servers-server_id-zones-zone_id
-func (c *PDNSAPIClient) ListZone(zoneID string) (zone pgo.Zone, resp *http.Response, err error) {
+func (c *PDNSAPIClient) ListZone(ctx context.Context, zoneID string) (zone pgo.Zone, resp *http.Response, err error) {
 	for i := 0; i < retryLimit; i++ {
-		zone, resp, err = c.client.ZonesApi.ListZone(c.authCtx, c.serverID, zoneID)
+		zone, resp, err = c.client.ZonesApi.ListZone(c.authContext(ctx), c.serverID, zoneID)
 		if err != nil {
 			log.Debugf("Unable to fetch zone %v", err)
 			log.Debugf("Retrying ListZone() ... %d", i)
@@ -196,9 +203,9 @@ func (c *PDNSAPIClient) ListZone(zoneID string) (zone pgo.Zone, resp *http.Respo
 
 // PatchZone : Method used to update the contents of a particular zone from PowerDNS
 // ref: https://doc.powerdns.com/authoritative/http-api/zone.html#patch--servers-server_id-zones-zone_id
-func (c *PDNSAPIClient) PatchZone(zoneID string, zoneStruct pgo.Zone) (resp *http.Response, err error) {
+func (c *PDNSAPIClient) PatchZone(ctx context.Context, zoneID string, zoneStruct pgo.Zone) (resp *http.Response, err error) {
 	for i := 0; i < retryLimit; i++ {
-		resp, err = c.client.ZonesApi.PatchZone(c.authCtx, c.serverID, zoneID, zoneStruct)
+		resp, err = c.client.ZonesApi.PatchZone(c.authContext(ctx), c.serverID, zoneID, zoneStruct)
 		if err != nil {
 			log.Debugf("Unable to patch zone %v", err)
 			log.Debugf("Retrying PatchZone() ... %d", i)
@@ -238,6 +245,7 @@ func NewPDNSProvider(ctx context.Context, config PDNSConfig) (*PDNSProvider, err
 
 	pdnsClientConfig := pgo.NewConfiguration()
 	pdnsClientConfig.BasePath = config.Server + apiBase
+	pdnsClientConfig.UserAgent = provider.UserAgent()
 	if err := config.TLSConfig.setHTTPClient(pdnsClientConfig); err != nil {
 		return nil, err
 	}
@@ -246,7 +254,7 @@ func NewPDNSProvider(ctx context.Context, config PDNSConfig) (*PDNSProvider, err
 		client: &PDNSAPIClient{
 			dryRun:       config.DryRun,
 			serverID:     config.ServerID,
-			authCtx:      context.WithValue(ctx, pgo.ContextAPIKey, pgo.APIKey{Key: config.APIKey}),
+			apiKey:       pgo.APIKey{Key: config.APIKey},
 			client:       pgo.NewAPIClient(pdnsClientConfig),
 			domainFilter: config.DomainFilter,
 		},
@@ -268,12 +276,18 @@ func (p *PDNSProvider) convertRRSetToEndpoints(rr pgo.RrSet) (endpoints []*endpo
 	if rr.Type_ == "ALIAS" {
 		rrType_ = "CNAME"
 	}
-	endpoints = append(endpoints, endpoint.NewEndpointWithTTL(rr.Name, rrType_, endpoint.TTL(rr.Ttl), targets...))
+	ep := endpoint.NewEndpointWithTTL(rr.Name, rrType_, endpoint.TTL(rr.Ttl), targets...)
+	for _, comment := range rr.Comments {
+		if comment.Account == commentAccount && comment.Content != "" {
+			ep.WithProviderSpecific(endpoint.ProviderSpecificRecordComment, comment.Content)
+		}
+	}
+	endpoints = append(endpoints, ep)
 	return endpoints, nil
 }
 
 // ConvertEndpointsToZones marshals endpoints into pdns compatible Zone structs
-func (p *PDNSProvider) ConvertEndpointsToZones(eps []*endpoint.Endpoint, changetype pdnsChangeType) (zonelist []pgo.Zone, _ error) {
+func (p *PDNSProvider) ConvertEndpointsToZones(ctx context.Context, eps []*endpoint.Endpoint, changetype pdnsChangeType) (zonelist []pgo.Zone, _ error) {
 	zonelist = []pgo.Zone{}
 	endpoints := make([]*endpoint.Endpoint, len(eps))
 	copy(endpoints, eps)
@@ -288,7 +302,7 @@ func (p *PDNSProvider) ConvertEndpointsToZones(eps []*endpoint.Endpoint, changet
 			return endpoints[i].DNSName < endpoints[j].DNSName
 		})
 
-	zones, _, err := p.client.ListZones()
+	zones, _, err := p.client.ListZones(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -344,6 +358,10 @@ func (p *PDNSProvider) ConvertEndpointsToZones(eps []*endpoint.Endpoint, changet
 					} else {
 						rrset.Ttl = int32(ep.RecordTTL)
 					}
+					// an empty comment replaces the comment of an rrset whose record-comment annotation was removed
+					if comment, ok := ep.GetProviderSpecificProperty(endpoint.ProviderSpecificRecordComment); ok {
+						rrset.Comments = []pgo.Comment{{Content: comment, Account: commentAccount}}
+					}
 				}
 
 				zone.Rrsets = append(zone.Rrsets, rrset)
@@ -387,8 +405,8 @@ func (p *PDNSProvider) ConvertEndpointsToZones(eps []*endpoint.Endpoint, changet
 }
 
 // mutateRecords takes a list of endpoints and creates, replaces or deletes them based on the changetype
-func (p *PDNSProvider) mutateRecords(endpoints []*endpoint.Endpoint, changetype pdnsChangeType) error {
-	zonelist, err := p.ConvertEndpointsToZones(endpoints, changetype)
+func (p *PDNSProvider) mutateRecords(ctx context.Context, endpoints []*endpoint.Endpoint, changetype pdnsChangeType) error {
+	zonelist, err := p.ConvertEndpointsToZones(ctx, endpoints, changetype)
 	if err != nil {
 		return err
 	}
@@ -399,7 +417,7 @@ func (p *PDNSProvider) mutateRecords(endpoints []*endpoint.Endpoint, changetype
 		} else {
 			log.Debugf("Struct for PatchZone:\n%s", string(jso))
 		}
-		resp, err := p.client.PatchZone(zone.Id, zone)
+		resp, err := p.client.PatchZone(ctx, zone.Id, zone)
 		if err != nil {
 			log.Debugf("PDNS API response: %s", stringifyHTTPResponseBody(resp))
 			return err
@@ -410,14 +428,14 @@ func (p *PDNSProvider) mutateRecords(endpoints []*endpoint.Endpoint, changetype
 
 // Records returns all DNS records controlled by the configured PDNS server (for all zones)
 func (p *PDNSProvider) Records(ctx context.Context) (endpoints []*endpoint.Endpoint, _ error) {
-	zones, _, err := p.client.ListZones()
+	zones, _, err := p.client.ListZones(ctx)
 	if err != nil {
 		return nil, err
 	}
 	filteredZones, _ := p.client.PartitionZones(zones)
 
 	for _, zone := range filteredZones {
-		z, _, err := p.client.ListZone(zone.Id)
+		z, _, err := p.client.ListZone(ctx, zone.Id)
 		if err != nil {
 			log.Warnf("Unable to fetch Records")
 			return nil, err
@@ -451,7 +469,7 @@ func (p *PDNSProvider) ApplyChanges(ctx context.Context, changes *plan.Changes)
 	// prevent unnecessary logging
 	if len(changes.Create) > 0 {
 		// "Replacing" non-existent records creates them
-		err := p.mutateRecords(changes.Create, PdnsReplace)
+		err := p.mutateRecords(ctx, changes.Create, PdnsReplace)
 		if err != nil {
 			return err
 		}
@@ -466,11 +484,18 @@ func (p *PDNSProvider) ApplyChanges(ctx context.Context, changes *plan.Changes)
 		log.Debugf("UPDATE-OLD (ignored): %+v", change)
 	}
 
-	for _, change := range changes.UpdateNew {
+	updateNew := make([]*endpoint.Endpoint, len(changes.UpdateNew))
+	for i, change := range changes.UpdateNew {
 		log.Infof("UPDATE-NEW: %+v", change)
+		updateNew[i] = change
+		// REPLACE keeps the comments of an rrset without comments, so the comment set by ExternalDNS is emptied
+		_, commented := change.GetProviderSpecificProperty(endpoint.ProviderSpecificRecordComment)
+		if _, wasCommented := changes.UpdateOld[i].GetProviderSpecificProperty(endpoint.ProviderSpecificRecordComment); wasCommented && !commented {
+			updateNew[i] = change.DeepCopy().WithProviderSpecific(endpoint.ProviderSpecificRecordComment, "")
+		}
 	}
-	if len(changes.UpdateNew) > 0 {
-		err := p.mutateRecords(changes.UpdateNew, PdnsReplace)
+	if len(updateNew) > 0 {
+		err := p.mutateRecords(ctx, updateNew, PdnsReplace)
 		if err != nil {
 			return err
 		}
@@ -481,7 +506,7 @@ func (p *PDNSProvider) ApplyChanges(ctx context.Context, changes *plan.Changes)
 		log.Infof("DELETE: %+v", change)
 	}
 	if len(changes.Delete) > 0 {
-		err := p.mutateRecords(changes.Delete, PdnsDelete)
+		err := p.mutateRecords(ctx, changes.Delete, PdnsDelete)
 		if err != nil {
 			return err
 		}
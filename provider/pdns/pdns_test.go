@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"net/http/httptest"
 	"regexp"
 	"strings"
 	"testing"
@@ -29,6 +30,8 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
 )
 
 // FIXME: What do we do about labels?
@@ -590,42 +593,42 @@ var (
 
 	DomainFilterEmptyClient = &PDNSAPIClient{
 		dryRun:       false,
-		authCtx:      context.WithValue(context.Background(), pgo.ContextAPIKey, pgo.APIKey{Key: "TEST-API-KEY"}),
+		apiKey:       pgo.APIKey{Key: "TEST-API-KEY"},
 		client:       pgo.NewAPIClient(pgo.NewConfiguration()),
 		domainFilter: DomainFilterListEmpty,
 	}
 
 	DomainFilterSingleClient = &PDNSAPIClient{
 		dryRun:       false,
-		authCtx:      context.WithValue(context.Background(), pgo.ContextAPIKey, pgo.APIKey{Key: "TEST-API-KEY"}),
+		apiKey:       pgo.APIKey{Key: "TEST-API-KEY"},
 		client:       pgo.NewAPIClient(pgo.NewConfiguration()),
 		domainFilter: DomainFilterListSingle,
 	}
 
 	DomainFilterChildSingleClient = &PDNSAPIClient{
 		dryRun:       false,
-		authCtx:      context.WithValue(context.Background(), pgo.ContextAPIKey, pgo.APIKey{Key: "TEST-API-KEY"}),
+		apiKey:       pgo.APIKey{Key: "TEST-API-KEY"},
 		client:       pgo.NewAPIClient(pgo.NewConfiguration()),
 		domainFilter: DomainFilterChildListSingle,
 	}
 
 	DomainFilterMultipleClient = &PDNSAPIClient{
 		dryRun:       false,
-		authCtx:      context.WithValue(context.Background(), pgo.ContextAPIKey, pgo.APIKey{Key: "TEST-API-KEY"}),
+		apiKey:       pgo.APIKey{Key: "TEST-API-KEY"},
 		client:       pgo.NewAPIClient(pgo.NewConfiguration()),
 		domainFilter: DomainFilterListMultiple,
 	}
 
 	DomainFilterChildMultipleClient = &PDNSAPIClient{
 		dryRun:       false,
-		authCtx:      context.WithValue(context.Background(), pgo.ContextAPIKey, pgo.APIKey{Key: "TEST-API-KEY"}),
+		apiKey:       pgo.APIKey{Key: "TEST-API-KEY"},
 		client:       pgo.NewAPIClient(pgo.NewConfiguration()),
 		domainFilter: DomainFilterChildListMultiple,
 	}
 
 	RegexDomainFilterClient = &PDNSAPIClient{
 		dryRun:       false,
-		authCtx:      context.WithValue(context.Background(), pgo.ContextAPIKey, pgo.APIKey{Key: "TEST-API-KEY"}),
+		apiKey:       pgo.APIKey{Key: "TEST-API-KEY"},
 		client:       pgo.NewAPIClient(pgo.NewConfiguration()),
 		domainFilter: RegexDomainFilter,
 	}
@@ -635,7 +638,7 @@ var (
 // API that returns a zone with multiple record types
 type PDNSAPIClientStub struct{}
 
-func (c *PDNSAPIClientStub) ListZones() ([]pgo.Zone, *http.Response, error) {
+func (c *PDNSAPIClientStub) ListZones(ctx context.Context) ([]pgo.Zone, *http.Response, error) {
 	return []pgo.Zone{ZoneMixed}, nil, nil
 }
 
@@ -643,11 +646,11 @@ func (c *PDNSAPIClientStub) PartitionZones(zones []pgo.Zone) ([]pgo.Zone, []pgo.
 	return zones, nil
 }
 
-func (c *PDNSAPIClientStub) ListZone(zoneID string) (pgo.Zone, *http.Response, error) {
+func (c *PDNSAPIClientStub) ListZone(ctx context.Context, zoneID string) (pgo.Zone, *http.Response, error) {
 	return ZoneMixed, nil, nil
 }
 
-func (c *PDNSAPIClientStub) PatchZone(zoneID string, zoneStruct pgo.Zone) (*http.Response, error) {
+func (c *PDNSAPIClientStub) PatchZone(ctx context.Context, zoneID string, zoneStruct pgo.Zone) (*http.Response, error) {
 	return nil, nil
 }
 
@@ -658,7 +661,7 @@ type PDNSAPIClientStubEmptyZones struct {
 	patchedZones []pgo.Zone
 }
 
-func (c *PDNSAPIClientStubEmptyZones) ListZones() ([]pgo.Zone, *http.Response, error) {
+func (c *PDNSAPIClientStubEmptyZones) ListZones(ctx context.Context) ([]pgo.Zone, *http.Response, error) {
 	return []pgo.Zone{ZoneEmpty, ZoneEmptyLong, ZoneEmpty2}, nil, nil
 }
 
@@ -666,7 +669,7 @@ func (c *PDNSAPIClientStubEmptyZones) PartitionZones(zones []pgo.Zone) ([]pgo.Zo
 	return zones, nil
 }
 
-func (c *PDNSAPIClientStubEmptyZones) ListZone(zoneID string) (pgo.Zone, *http.Response, error) {
+func (c *PDNSAPIClientStubEmptyZones) ListZone(ctx context.Context, zoneID string) (pgo.Zone, *http.Response, error) {
 	if strings.Contains(zoneID, "example.com") {
 		return ZoneEmpty, nil, nil
 	} else if strings.Contains(zoneID, "mock.test") {
@@ -677,7 +680,7 @@ func (c *PDNSAPIClientStubEmptyZones) ListZone(zoneID string) (pgo.Zone, *http.R
 	return pgo.Zone{}, nil, nil
 }
 
-func (c *PDNSAPIClientStubEmptyZones) PatchZone(zoneID string, zoneStruct pgo.Zone) (*http.Response, error) {
+func (c *PDNSAPIClientStubEmptyZones) PatchZone(ctx context.Context, zoneID string, zoneStruct pgo.Zone) (*http.Response, error) {
 	c.patchedZones = append(c.patchedZones, zoneStruct)
 	return nil, nil
 }
@@ -690,7 +693,7 @@ type PDNSAPIClientStubPatchZoneFailure struct {
 }
 
 // Just overwrite the PatchZone method to introduce a failure
-func (c *PDNSAPIClientStubPatchZoneFailure) PatchZone(zoneID string, zoneStruct pgo.Zone) (*http.Response, error) {
+func (c *PDNSAPIClientStubPatchZoneFailure) PatchZone(ctx context.Context, zoneID string, zoneStruct pgo.Zone) (*http.Response, error) {
 	return nil, errors.New("Generic PDNS Error")
 }
 
@@ -702,7 +705,7 @@ type PDNSAPIClientStubListZoneFailure struct {
 }
 
 // Just overwrite the ListZone method to introduce a failure
-func (c *PDNSAPIClientStubListZoneFailure) ListZone(zoneID string) (pgo.Zone, *http.Response, error) {
+func (c *PDNSAPIClientStubListZoneFailure) ListZone(ctx context.Context, zoneID string) (pgo.Zone, *http.Response, error) {
 	return pgo.Zone{}, nil, errors.New("Generic PDNS Error")
 }
 
@@ -714,7 +717,7 @@ type PDNSAPIClientStubListZonesFailure struct {
 }
 
 // Just overwrite the ListZones method to introduce a failure
-func (c *PDNSAPIClientStubListZonesFailure) ListZones() ([]pgo.Zone, *http.Response, error) {
+func (c *PDNSAPIClientStubListZonesFailure) ListZones(ctx context.Context) ([]pgo.Zone, *http.Response, error) {
 	return []pgo.Zone{}, nil, errors.New("Generic PDNS Error")
 }
 
@@ -725,11 +728,11 @@ type PDNSAPIClientStubPartitionZones struct {
 	PDNSAPIClientStubEmptyZones
 }
 
-func (c *PDNSAPIClientStubPartitionZones) ListZones() ([]pgo.Zone, *http.Response, error) {
+func (c *PDNSAPIClientStubPartitionZones) ListZones(ctx context.Context) ([]pgo.Zone, *http.Response, error) {
 	return []pgo.Zone{ZoneEmpty, ZoneEmptyLong, ZoneEmpty2, ZoneEmptySimilar}, nil, nil
 }
 
-func (c *PDNSAPIClientStubPartitionZones) ListZone(zoneID string) (pgo.Zone, *http.Response, error) {
+func (c *PDNSAPIClientStubPartitionZones) ListZone(ctx context.Context, zoneID string) (pgo.Zone, *http.Response, error) {
 	if strings.Contains(zoneID, "example.com") {
 		return ZoneEmpty, nil, nil
 	} else if strings.Contains(zoneID, "mock.test") {
@@ -792,6 +795,28 @@ func (suite *NewPDNSProviderTestSuite) TestPDNSProviderCreate() {
 	assert.Nil(suite.T(), err, "Regular case should raise no error")
 }
 
+func (suite *NewPDNSProviderTestSuite) TestPDNSProviderUserAgent() {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	p, err := NewPDNSProvider(
+		context.Background(),
+		PDNSConfig{
+			Server:       server.URL,
+			APIKey:       "foo",
+			DomainFilter: endpoint.NewDomainFilter([]string{""}),
+		})
+	suite.Require().NoError(err)
+	_, _, err = p.client.ListZones(context.Background())
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), provider.UserAgent(), userAgent)
+}
+
 func (suite *NewPDNSProviderTestSuite) TestPDNSProviderCreateTLS() {
 	newProvider := func(TLSConfig TLSConfig) error {
 		_, err := NewPDNSProvider(
@@ -888,7 +913,7 @@ func (suite *NewPDNSProviderTestSuite) TestPDNSRecords() {
 }
 
 func (suite *NewPDNSProviderTestSuite) TestPDNSConvertEndpointsToZones() {
-	// Function definition: ConvertEndpointsToZones(endpoints []*endpoint.Endpoint, changetype pdnsChangeType) (zonelist []pgo.Zone, _ error)
+	// Function definition: ConvertEndpointsToZones(ctx context.Context, endpoints []*endpoint.Endpoint, changetype pdnsChangeType) (zonelist []pgo.Zone, _ error)
 
 	// Create a new provider to run tests against
 	p := &PDNSProvider{
@@ -896,42 +921,42 @@ func (suite *NewPDNSProviderTestSuite) TestPDNSConvertEndpointsToZones() {
 	}
 
 	// Check inserting endpoints from a single zone
-	zlist, err := p.ConvertEndpointsToZones(endpointsSimpleRecord, PdnsReplace)
+	zlist, err := p.ConvertEndpointsToZones(context.Background(), endpointsSimpleRecord, PdnsReplace)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []pgo.Zone{ZoneEmptyToSimplePatch}, zlist)
 
 	// Check deleting endpoints from a single zone
-	zlist, err = p.ConvertEndpointsToZones(endpointsSimpleRecord, PdnsDelete)
+	zlist, err = p.ConvertEndpointsToZones(context.Background(), endpointsSimpleRecord, PdnsDelete)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []pgo.Zone{ZoneEmptyToSimpleDelete}, zlist)
 
 	// Check endpoints from multiple zones #1
-	zlist, err = p.ConvertEndpointsToZones(endpointsMultipleZones, PdnsReplace)
+	zlist, err = p.ConvertEndpointsToZones(context.Background(), endpointsMultipleZones, PdnsReplace)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []pgo.Zone{ZoneEmptyToSimplePatch, ZoneEmptyToSimplePatch2}, zlist)
 
 	// Check endpoints from multiple zones #2
-	zlist, err = p.ConvertEndpointsToZones(endpointsMultipleZones2, PdnsReplace)
+	zlist, err = p.ConvertEndpointsToZones(context.Background(), endpointsMultipleZones2, PdnsReplace)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []pgo.Zone{ZoneEmptyToSimplePatch, ZoneEmptyToSimplePatch3}, zlist)
 
 	// Check endpoints from multiple zones where some endpoints which don't exist
-	zlist, err = p.ConvertEndpointsToZones(endpointsMultipleZonesWithNoExist, PdnsReplace)
+	zlist, err = p.ConvertEndpointsToZones(context.Background(), endpointsMultipleZonesWithNoExist, PdnsReplace)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []pgo.Zone{ZoneEmptyToSimplePatch}, zlist)
 
 	// Check endpoints from a zone that does not exist
-	zlist, err = p.ConvertEndpointsToZones(endpointsNonexistantZone, PdnsReplace)
+	zlist, err = p.ConvertEndpointsToZones(context.Background(), endpointsNonexistantZone, PdnsReplace)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []pgo.Zone{}, zlist)
 
 	// Check endpoints that match multiple zones (one longer than other), is assigned to the right zone
-	zlist, err = p.ConvertEndpointsToZones(endpointsLongRecord, PdnsReplace)
+	zlist, err = p.ConvertEndpointsToZones(context.Background(), endpointsLongRecord, PdnsReplace)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []pgo.Zone{ZoneEmptyToLongPatch}, zlist)
 
 	// Check endpoints of type CNAME always have their target records end with a dot.
-	zlist, err = p.ConvertEndpointsToZones(endpointsMixedRecords, PdnsReplace)
+	zlist, err = p.ConvertEndpointsToZones(context.Background(), endpointsMixedRecords, PdnsReplace)
 	assert.Nil(suite.T(), err)
 
 	for _, z := range zlist {
@@ -945,7 +970,7 @@ func (suite *NewPDNSProviderTestSuite) TestPDNSConvertEndpointsToZones() {
 	}
 
 	// Check endpoints of type CNAME are converted to ALIAS on the domain apex
-	zlist, err = p.ConvertEndpointsToZones(endpointsApexRecords, PdnsReplace)
+	zlist, err = p.ConvertEndpointsToZones(context.Background(), endpointsApexRecords, PdnsReplace)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []pgo.Zone{ZoneEmptyToApexPatch}, zlist)
 }
@@ -957,46 +982,46 @@ func (suite *NewPDNSProviderTestSuite) TestPDNSConvertEndpointsToZonesPartitionZ
 	}
 
 	// Check inserting endpoints from a single zone which is specified in DomainFilter
-	zlist, err := p.ConvertEndpointsToZones(endpointsSimpleRecord, PdnsReplace)
+	zlist, err := p.ConvertEndpointsToZones(context.Background(), endpointsSimpleRecord, PdnsReplace)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []pgo.Zone{ZoneEmptyToSimplePatch}, zlist)
 
 	// Check deleting endpoints from a single zone which is specified in DomainFilter
-	zlist, err = p.ConvertEndpointsToZones(endpointsSimpleRecord, PdnsDelete)
+	zlist, err = p.ConvertEndpointsToZones(context.Background(), endpointsSimpleRecord, PdnsDelete)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []pgo.Zone{ZoneEmptyToSimpleDelete}, zlist)
 
 	// Check endpoints from multiple zones # which one is specified in DomainFilter and one is not
-	zlist, err = p.ConvertEndpointsToZones(endpointsMultipleZones, PdnsReplace)
+	zlist, err = p.ConvertEndpointsToZones(context.Background(), endpointsMultipleZones, PdnsReplace)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []pgo.Zone{ZoneEmptyToSimplePatch}, zlist)
 
 	// Check endpoints from multiple zones where some endpoints which don't exist and one that does
 	// and is part of DomainFilter
-	zlist, err = p.ConvertEndpointsToZones(endpointsMultipleZonesWithNoExist, PdnsReplace)
+	zlist, err = p.ConvertEndpointsToZones(context.Background(), endpointsMultipleZonesWithNoExist, PdnsReplace)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []pgo.Zone{ZoneEmptyToSimplePatch}, zlist)
 
 	// Check endpoints from a zone that does not exist
-	zlist, err = p.ConvertEndpointsToZones(endpointsNonexistantZone, PdnsReplace)
+	zlist, err = p.ConvertEndpointsToZones(context.Background(), endpointsNonexistantZone, PdnsReplace)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []pgo.Zone{}, zlist)
 
 	// Check endpoints that match multiple zones (one longer than other), is assigned to the right zone when the longer
 	// zone is not part of the DomainFilter
-	zlist, err = p.ConvertEndpointsToZones(endpointsMultipleZonesWithLongRecordNotInDomainFilter, PdnsReplace)
+	zlist, err = p.ConvertEndpointsToZones(context.Background(), endpointsMultipleZonesWithLongRecordNotInDomainFilter, PdnsReplace)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []pgo.Zone{ZoneEmptyToSimplePatchLongRecordIgnoredInDomainFilter}, zlist)
 
 	// Check endpoints that match multiple zones (one longer than other and one is very similar)
 	// is assigned to the right zone when the similar zone is not part of the DomainFilter
-	zlist, err = p.ConvertEndpointsToZones(endpointsMultipleZonesWithSimilarRecordNotInDomainFilter, PdnsReplace)
+	zlist, err = p.ConvertEndpointsToZones(context.Background(), endpointsMultipleZonesWithSimilarRecordNotInDomainFilter, PdnsReplace)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []pgo.Zone{ZoneEmptyToSimplePatch}, zlist)
 }
 
 func (suite *NewPDNSProviderTestSuite) TestPDNSmutateRecords() {
-	// Function definition: mutateRecords(endpoints []*endpoint.Endpoint, changetype pdnsChangeType) error
+	// Function definition: mutateRecords(ctx context.Context, endpoints []*endpoint.Endpoint, changetype pdnsChangeType) error
 
 	// Create a new provider to run tests against
 	c := &PDNSAPIClientStubEmptyZones{}
@@ -1005,7 +1030,7 @@ func (suite *NewPDNSProviderTestSuite) TestPDNSmutateRecords() {
 	}
 
 	// Check inserting endpoints from a single zone
-	err := p.mutateRecords(endpointsSimpleRecord, pdnsChangeType("REPLACE"))
+	err := p.mutateRecords(context.Background(), endpointsSimpleRecord, pdnsChangeType("REPLACE"))
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []pgo.Zone{ZoneEmptyToSimplePatch}, c.patchedZones)
 
@@ -1013,7 +1038,7 @@ func (suite *NewPDNSProviderTestSuite) TestPDNSmutateRecords() {
 	c.patchedZones = []pgo.Zone{}
 
 	// Check deleting endpoints from a single zone
-	err = p.mutateRecords(endpointsSimpleRecord, pdnsChangeType("DELETE"))
+	err = p.mutateRecords(context.Background(), endpointsSimpleRecord, pdnsChangeType("DELETE"))
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []pgo.Zone{ZoneEmptyToSimpleDelete}, c.patchedZones)
 
@@ -1022,10 +1047,59 @@ func (suite *NewPDNSProviderTestSuite) TestPDNSmutateRecords() {
 		client: &PDNSAPIClientStubPatchZoneFailure{},
 	}
 	// Check inserting endpoints from a single zone
-	err = p.mutateRecords(endpointsSimpleRecord, pdnsChangeType("REPLACE"))
+	err = p.mutateRecords(context.Background(), endpointsSimpleRecord, pdnsChangeType("REPLACE"))
 	assert.NotNil(suite.T(), err)
 }
 
+func (suite *NewPDNSProviderTestSuite) TestPDNSRecordComments() {
+	c := &PDNSAPIClientStubEmptyZones{}
+	p := &PDNSProvider{
+		client: c,
+	}
+	comment := endpoint.NewRecordComment("Shop", "service/default/shop")
+
+	// Only the comments of ExternalDNS are reported
+	rrset := RRSetSimpleARecord
+	rrset.Comments = []pgo.Comment{{Content: "set by hand", Account: "admin"}, {Content: comment, Account: commentAccount}}
+	eps, err := p.convertRRSetToEndpoints(rrset)
+	assert.Nil(suite.T(), err)
+	value, ok := eps[0].GetProviderSpecificProperty(endpoint.ProviderSpecificRecordComment)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), comment, value)
+
+	rrset.Comments = []pgo.Comment{{Content: "set by hand", Account: "admin"}}
+	eps, err = p.convertRRSetToEndpoints(rrset)
+	assert.Nil(suite.T(), err)
+	_, ok = eps[0].GetProviderSpecificProperty(endpoint.ProviderSpecificRecordComment)
+	assert.False(suite.T(), ok)
+
+	// The comment is set, then emptied when the record-comment annotation is removed
+	commented := endpoint.NewEndpointWithTTL("example.com", endpoint.RecordTypeA, endpoint.TTL(300), "8.8.8.8").
+		WithProviderSpecific(endpoint.ProviderSpecificRecordComment, comment)
+	err = p.ApplyChanges(context.Background(), &plan.Changes{Create: []*endpoint.Endpoint{commented}})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []pgo.Comment{{Content: comment, Account: commentAccount}}, c.patchedZones[0].Rrsets[0].Comments)
+
+	c.patchedZones = nil
+	uncommented := endpoint.NewEndpointWithTTL("example.com", endpoint.RecordTypeA, endpoint.TTL(300), "8.8.8.8")
+	err = p.ApplyChanges(context.Background(), &plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{commented},
+		UpdateNew: []*endpoint.Endpoint{uncommented},
+	})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []pgo.Comment{{Content: "", Account: commentAccount}}, c.patchedZones[0].Rrsets[0].Comments)
+	assert.Empty(suite.T(), uncommented.ProviderSpecific, "the desired endpoint is not modified")
+
+	// Rrsets without comment keep their comments
+	c.patchedZones = nil
+	err = p.ApplyChanges(context.Background(), &plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{uncommented},
+		UpdateNew: []*endpoint.Endpoint{uncommented},
+	})
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), c.patchedZones[0].Rrsets[0].Comments)
+}
+
 func (suite *NewPDNSProviderTestSuite) TestPDNSClientPartitionZones() {
 	zoneList := []pgo.Zone{
 		ZoneEmpty,
@@ -33,6 +33,7 @@ import (
 	"golang.org/x/net/html"
 
 	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/provider"
 )
 
 // piholeAPI declares the "API" actions performed against the Pihole server.
@@ -66,11 +67,11 @@ func newPiholeClient(cfg PiholeConfig) (piholeAPI, error) {
 	// Setup an HTTP client using the cookiejar
 	httpClient := &http.Client{
 		Jar: jar,
-		Transport: &http.Transport{
+		Transport: provider.NewUserAgentTransport(&http.Transport{
 			TLSClientConfig: &tls.Config{
 				InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
 			},
-		},
+		}),
 	}
 	cl := instrumented_http.NewClient(httpClient, &instrumented_http.Callbacks{})
 
@@ -26,6 +26,8 @@ import (
 	"github.com/Yamashou/gqlgenc/clientv2"
 	"github.com/pluralsh/gqlclient"
 	"github.com/pluralsh/gqlclient/pkg/utils"
+
+	"sigs.k8s.io/external-dns/provider"
 )
 
 type authedTransport struct {
@@ -39,9 +41,9 @@ func (t *authedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 }
 
 type Client interface {
-	DnsRecords() ([]*DnsRecord, error)
-	CreateRecord(record *DnsRecord) (*DnsRecord, error)
-	DeleteRecord(name, ttype string) error
+	DnsRecords(ctx context.Context) ([]*DnsRecord, error)
+	CreateRecord(ctx context.Context, record *DnsRecord) (*DnsRecord, error)
+	DeleteRecord(ctx context.Context, name, ttype string) error
 }
 
 type Config struct {
@@ -52,7 +54,6 @@ type Config struct {
 }
 
 type client struct {
-	ctx          context.Context
 	pluralClient *gqlclient.Client
 	config       *Config
 }
@@ -71,12 +72,11 @@ func NewClient(conf *Config) (Client, error) {
 	httpClient := http.Client{
 		Transport: &authedTransport{
 			key:     conf.Token,
-			wrapped: http.DefaultTransport,
+			wrapped: provider.NewUserAgentTransport(nil),
 		},
 	}
 	endpoint := base + "/gql"
 	return &client{
-		ctx:          context.Background(),
 		pluralClient: gqlclient.NewClient(&httpClient, endpoint, &clientv2.Options{}),
 		config:       conf,
 	}, nil
@@ -93,8 +93,8 @@ func (c *Config) BaseUrl() (string, error) {
 	return host, nil
 }
 
-func (client *client) DnsRecords() ([]*DnsRecord, error) {
-	resp, err := client.pluralClient.GetDNSRecords(client.ctx, client.config.Cluster, gqlclient.Provider(strings.ToUpper(client.config.Provider)))
+func (client *client) DnsRecords(ctx context.Context) ([]*DnsRecord, error) {
+	resp, err := client.pluralClient.GetDNSRecords(ctx, client.config.Cluster, gqlclient.Provider(strings.ToUpper(client.config.Provider)))
 	if err != nil {
 		return nil, err
 	}
@@ -113,7 +113,7 @@ func (client *client) DnsRecords() ([]*DnsRecord, error) {
 	return records, nil
 }
 
-func (client *client) CreateRecord(record *DnsRecord) (*DnsRecord, error) {
+func (client *client) CreateRecord(ctx context.Context, record *DnsRecord) (*DnsRecord, error) {
 	provider := gqlclient.Provider(strings.ToUpper(client.config.Provider))
 	cluster := client.config.Cluster
 	attr := gqlclient.DNSRecordAttributes{
@@ -126,7 +126,7 @@ func (client *client) CreateRecord(record *DnsRecord) (*DnsRecord, error) {
 		attr.Records = append(attr.Records, &record)
 	}
 
-	resp, err := client.pluralClient.CreateDNSRecord(client.ctx, cluster, provider, attr)
+	resp, err := client.pluralClient.CreateDNSRecord(ctx, cluster, provider, attr)
 	if err != nil {
 		return nil, err
 	}
@@ -138,8 +138,8 @@ func (client *client) CreateRecord(record *DnsRecord) (*DnsRecord, error) {
 	}, nil
 }
 
-func (client *client) DeleteRecord(name, ttype string) error {
-	if _, err := client.pluralClient.DeleteDNSRecord(client.ctx, name, gqlclient.DNSRecordType(ttype)); err != nil {
+func (client *client) DeleteRecord(ctx context.Context, name, ttype string) error {
+	if _, err := client.pluralClient.DeleteDNSRecord(ctx, name, gqlclient.DNSRecordType(ttype)); err != nil {
 		return err
 	}
 
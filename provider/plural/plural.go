@@ -70,8 +70,8 @@ func NewPluralProvider(cluster, provider string) (*PluralProvider, error) {
 	return prov, nil
 }
 
-func (p *PluralProvider) Records(_ context.Context) (endpoints []*endpoint.Endpoint, err error) {
-	records, err := p.Client.DnsRecords()
+func (p *PluralProvider) Records(ctx context.Context) (endpoints []*endpoint.Endpoint, err error) {
+	records, err := p.Client.DnsRecords(ctx)
 	if err != nil {
 		return
 	}
@@ -87,7 +87,7 @@ func (p *PluralProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*end
 	return endpoints, nil
 }
 
-func (p *PluralProvider) ApplyChanges(_ context.Context, diffs *plan.Changes) error {
+func (p *PluralProvider) ApplyChanges(ctx context.Context, diffs *plan.Changes) error {
 	var changes []*RecordChange
 	for _, endpoint := range diffs.Create {
 		changes = append(changes, makeChange(CreateAction, endpoint.Targets, endpoint))
@@ -101,7 +101,7 @@ func (p *PluralProvider) ApplyChanges(_ context.Context, diffs *plan.Changes) er
 		changes = append(changes, makeChange(DeleteAction, []string{}, deleted))
 	}
 
-	return p.applyChanges(changes)
+	return p.applyChanges(ctx, changes)
 }
 
 func makeChange(change string, target []string, endpoint *endpoint.Endpoint) *RecordChange {
@@ -115,7 +115,7 @@ func makeChange(change string, target []string, endpoint *endpoint.Endpoint) *Re
 	}
 }
 
-func (p *PluralProvider) applyChanges(changes []*RecordChange) error {
+func (p *PluralProvider) applyChanges(ctx context.Context, changes []*RecordChange) error {
 	for _, change := range changes {
 		logFields := log.Fields{
 			"name":   change.Record.Name,
@@ -125,13 +125,13 @@ func (p *PluralProvider) applyChanges(changes []*RecordChange) error {
 		log.WithFields(logFields).Info("Changing record.")
 
 		if change.Action == CreateAction {
-			_, err := p.Client.CreateRecord(change.Record)
+			_, err := p.Client.CreateRecord(ctx, change.Record)
 			if err != nil {
 				return err
 			}
 		}
 		if change.Action == DeleteAction {
-			if err := p.Client.DeleteRecord(change.Record.Name, change.Record.Type); err != nil {
+			if err := p.Client.DeleteRecord(ctx, change.Record.Name, change.Record.Type); err != nil {
 				return err
 			}
 		}
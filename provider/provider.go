@@ -72,6 +72,92 @@ func (k *contextKey) String() string { return "provider context value " + k.name
 // type []*endpoint.Endpoint.
 var RecordsContextKey = &contextKey{"records"}
 
+// ZoneScopeContextKey is a context key. If it is set during Records, providers
+// implementing ZoneLister only list the records of the zone with the associated
+// name. The associated value will be of type string.
+var ZoneScopeContextKey = &contextKey{"zone-scope"}
+
+// ExcludedRecordTypesContextKey is a context key. If it is set during Records, providers
+// able to list the records of some types only may skip listing the records of the associated types,
+// which will be dropped anyway. The associated value will be of type []string.
+var ExcludedRecordTypesContextKey = &contextKey{"excluded-record-types"}
+
+// ZoneLister is implemented by providers that can list the records of a single zone,
+// which lets the controller synchronize the zones one by one instead of waiting for
+// the listing of all zones. See ZoneScopeContextKey.
+type ZoneLister interface {
+	// ZoneNames returns the names of the zones managed by the provider, without trailing dot.
+	ZoneNames(ctx context.Context) ([]string, error)
+}
+
+// ZoneVersioner is implemented by ZoneLister providers that can tell whether the records of a zone changed
+// with fewer API requests than listing them, e.g. from the serial of the zone.
+type ZoneVersioner interface {
+	// ZoneVersion returns an opaque version of the records of the zone, which changes whenever they change.
+	ZoneVersion(ctx context.Context, zone string) (string, error)
+}
+
+// ZoneCreator is implemented by ZoneLister providers that can create the missing zones, see ZoneCreatingProvider.
+type ZoneCreator interface {
+	// CreateOwnedZone creates the zone with the given name, without trailing dot, recording that the owner ID owns it.
+	CreateOwnedZone(ctx context.Context, zone, ownerID string) error
+	// DeleteOwnedZone deletes the zone with the given name if the owner ID owns it and it has no records but the ones
+	// created with the zone, and returns whether the zone was deleted.
+	DeleteOwnedZone(ctx context.Context, zone, ownerID string) (bool, error)
+}
+
+// ZoneMaintainer is implemented by providers managing resources of the zones besides their records, e.g. the links of
+// the Azure private zones to virtual networks, which are left untouched by Records and ApplyChanges.
+type ZoneMaintainer interface {
+	// MaintainZones reconciles the resources of the zones, rechecking each zone periodically.
+	MaintainZones(ctx context.Context) error
+}
+
+// ZoneScope returns the name of the zone the records are listed for, if Records is scoped to a zone.
+func ZoneScope(ctx context.Context) (string, bool) {
+	zone, ok := ctx.Value(ZoneScopeContextKey).(string)
+	return zone, ok && zone != ""
+}
+
+// ExcludedRecordTypes returns the record types the provider may skip listing, see ExcludedRecordTypesContextKey.
+func ExcludedRecordTypes(ctx context.Context) []string {
+	types, _ := ctx.Value(ExcludedRecordTypesContextKey).([]string)
+	return types
+}
+
+// PropertyComparer is implemented by providers that know which differences between the provider-specific
+// properties of the desired endpoints and of the records they return are meaningful, e.g. because they
+// populate properties the desired endpoints don't set with default values.
+type PropertyComparer interface {
+	// PropertyValuesEqual returns true if the desired and current values of the provider-specific property are
+	// equivalent. A property missing on either side has the empty value.
+	PropertyValuesEqual(name, desired, current string) bool
+}
+
+// Wrapper is implemented by the providers wrapping another provider, e.g. CachedProvider. A wrapper does not
+// implement the optional interfaces of the provider it wraps, such as PropertyComparer, see As.
+type Wrapper interface {
+	// Unwrap returns the wrapped provider.
+	Unwrap() Provider
+}
+
+// As returns the first provider of the chain of wrapped providers implementing T, e.g. the PropertyComparer
+// of a provider wrapped by ReadOnlyProvider.
+func As[T any](p Provider) (T, bool) {
+	for p != nil {
+		if t, ok := p.(T); ok {
+			return t, true
+		}
+		w, ok := p.(Wrapper)
+		if !ok {
+			break
+		}
+		p = w.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
 // EnsureTrailingDot ensures that the hostname receives a trailing dot if it hasn't already.
 func EnsureTrailingDot(hostname string) string {
 	if net.ParseIP(hostname) != nil {
@@ -20,6 +20,7 @@ import (
 	"io"
 	"os"
 	"testing"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -54,3 +55,27 @@ func TestDifference(t *testing.T) {
 	assert.Equal(t, remove, []string{"foo"})
 	assert.Equal(t, leave, []string{"bar"})
 }
+
+func TestAs(t *testing.T) {
+	comparer := &testProviderFunc{
+		propertyValuesEqual: func(name string, previous string, current string) bool {
+			return true
+		},
+	}
+	var wrapped Provider = comparer
+	wrapped = NewReadOnlyProvider(wrapped)
+	wrapped = NewFaultInjectionProvider(wrapped, Faults{})
+	wrapped = NewRecordTypeExclusionProvider(wrapped, []string{"TXT"})
+	wrapped = NewTimeoutProvider(wrapped, time.Minute)
+	wrapped = NewCachedProvider(wrapped, time.Minute)
+
+	_, ok := wrapped.(PropertyComparer)
+	assert.False(t, ok)
+
+	found, ok := As[PropertyComparer](wrapped)
+	assert.True(t, ok)
+	assert.Same(t, comparer, found)
+
+	_, ok = As[ZoneVersioner](wrapped)
+	assert.False(t, ok)
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// quotaEpochThreshold tells the reset headers in seconds since the epoch, e.g. DigitalOcean's, from those in
+	// seconds until the reset
+	quotaEpochThreshold = 1_000_000_000
+	// maxQuotaPaceDelay is the delay between requests when the reset of the quota is unknown
+	maxQuotaPaceDelay = 2 * time.Second
+)
+
+var (
+	apiQuotaRemaining = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "provider",
+			Name:      "api_quota_remaining",
+			Help:      "Number of requests left in the current rate-limit window of the DNS provider API, as reported by the API.",
+		},
+		[]string{"provider"},
+	)
+	apiQuotaLimit = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "provider",
+			Name:      "api_quota_limit",
+			Help:      "Number of requests allowed per rate-limit window of the DNS provider API, as reported by the API.",
+		},
+		[]string{"provider"},
+	)
+	apiQuotaPacedRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "provider",
+			Name:      "api_quota_paced_requests_total",
+			Help:      "Number of requests to the DNS provider API delayed because the remaining quota dropped below the headroom.",
+		},
+		[]string{"provider"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(apiQuotaRemaining, apiQuotaLimit, apiQuotaPacedRequests)
+}
+
+// apiQuotaHeadroom is the fraction of the quota below which the requests are paced, see SetAPIQuotaHeadroom.
+var apiQuotaHeadroom = 0.1
+
+// SetAPIQuotaHeadroom sets the fraction of the rate-limit quota of the DNS provider API below which the requests of
+// the clients using a QuotaTransport are paced. Zero disables the pacing, the quota is still exported.
+// The headroom is shared by all the QuotaTransports of the process, including those of other embedded instances.
+func SetAPIQuotaHeadroom(headroom float64) {
+	apiQuotaHeadroom = headroom
+}
+
+// QuotaTransport is a http.RoundTripper reading the rate-limit headers of the responses of the DNS provider API.
+// It exports the remaining quota, and spreads the requests over the rest of the rate-limit window once the remaining
+// quota drops below the headroom, instead of running into 429 responses.
+type QuotaTransport struct {
+	http.RoundTripper
+	provider string
+
+	mu sync.Mutex
+	// until is the time before which no request is sent
+	until time.Time
+	now   func() time.Time
+}
+
+// NewQuotaTransport wraps the transport of a provider's API client.
+// If transport is nil, http.DefaultTransport is used.
+func NewQuotaTransport(provider string, transport http.RoundTripper) *QuotaTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &QuotaTransport{RoundTripper: transport, provider: provider, now: time.Now}
+}
+
+func (t *QuotaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.wait(req); err != nil {
+		return nil, err
+	}
+	resp, err := t.RoundTripper.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	t.observe(resp)
+	return resp, nil
+}
+
+// wait blocks until the requests are no longer paced, or the request is canceled.
+func (t *QuotaTransport) wait(req *http.Request) error {
+	t.mu.Lock()
+	delay := t.until.Sub(t.now())
+	t.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	apiQuotaPacedRequests.WithLabelValues(t.provider).Inc()
+	log.Debugf("Delaying the %s request by %s because the API quota is running out", t.provider, delay)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
+
+// observe exports the quota of the response, and paces the next requests if the quota is running out.
+func (t *QuotaTransport) observe(resp *http.Response) {
+	quota, ok := parseAPIQuota(resp.Header, t.now())
+	if !ok {
+		return
+	}
+	apiQuotaRemaining.WithLabelValues(t.provider).Set(float64(quota.remaining))
+	if quota.limit > 0 {
+		apiQuotaLimit.WithLabelValues(t.provider).Set(float64(quota.limit))
+	}
+	if apiQuotaHeadroom <= 0 || quota.limit <= 0 || float64(quota.remaining) >= apiQuotaHeadroom*float64(quota.limit) {
+		return
+	}
+
+	// the remaining requests are spread over the rest of the window
+	delay := maxQuotaPaceDelay
+	if quota.reset > 0 {
+		delay = quota.reset / time.Duration(quota.remaining+1)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if until := t.now().Add(delay); until.After(t.until) {
+		t.until = until
+	}
+}
+
+// apiQuota is the rate-limit quota reported by a response of the DNS provider API.
+type apiQuota struct {
+	limit     int64
+	remaining int64
+	// reset is the time until the window of the quota is reset, 0 if unknown
+	reset time.Duration
+}
+
+// parseAPIQuota returns the quota of the rate-limit headers of a response:
+//   - RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset, e.g. DigitalOcean's, or with the X- prefix, with
+//     the reset in seconds until the reset or since the epoch
+//   - RateLimit and RateLimit-Policy structured fields, e.g. Cloudflare's `"default";r=50;t=30` and
+//     `"default";q=1200;w=300`
+func parseAPIQuota(header http.Header, now time.Time) (apiQuota, bool) {
+	for _, prefix := range []string{"RateLimit-", "X-RateLimit-"} {
+		remaining, err := strconv.ParseInt(header.Get(prefix+"Remaining"), 10, 64)
+		if err != nil {
+			continue
+		}
+		quota := apiQuota{remaining: remaining}
+		quota.limit, _ = strconv.ParseInt(header.Get(prefix+"Limit"), 10, 64)
+		if reset, err := strconv.ParseInt(header.Get(prefix+"Reset"), 10, 64); err == nil {
+			if reset > quotaEpochThreshold {
+				quota.reset = time.Unix(reset, 0).Sub(now)
+			} else {
+				quota.reset = time.Duration(reset) * time.Second
+			}
+		}
+		return quota, true
+	}
+
+	params := structuredFieldParams(header.Get("RateLimit"))
+	remaining, err := strconv.ParseInt(params["r"], 10, 64)
+	if err != nil {
+		return apiQuota{}, false
+	}
+	quota := apiQuota{remaining: remaining}
+	if reset, err := strconv.ParseInt(params["t"], 10, 64); err == nil {
+		quota.reset = time.Duration(reset) * time.Second
+	}
+	quota.limit, _ = strconv.ParseInt(structuredFieldParams(header.Get("RateLimit-Policy"))["q"], 10, 64)
+	return quota, true
+}
+
+// structuredFieldParams returns the parameters of the first item of a structured field, e.g. r and t of
+// `"default";r=50;t=30`.
+func structuredFieldParams(field string) map[string]string {
+	item, _, _ := strings.Cut(field, ",")
+	params := map[string]string{}
+	for _, param := range strings.Split(item, ";")[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		params[key] = value
+	}
+	return params
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// quotaResponder returns a response with the headers.
+type quotaResponder map[string]string
+
+func (r quotaResponder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Request: req, Body: http.NoBody}
+	for name, value := range r {
+		resp.Header.Set(name, value)
+	}
+	return resp, nil
+}
+
+func TestParseAPIQuota(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	for _, tc := range []struct {
+		name     string
+		header   map[string]string
+		expected apiQuota
+		ok       bool
+	}{
+		{
+			name:     "digitalocean",
+			header:   map[string]string{"RateLimit-Limit": "5000", "RateLimit-Remaining": "4990", "RateLimit-Reset": "1700000060"},
+			expected: apiQuota{limit: 5000, remaining: 4990, reset: time.Minute},
+			ok:       true,
+		},
+		{
+			name:     "x-prefix with reset in seconds",
+			header:   map[string]string{"X-RateLimit-Limit": "60", "X-RateLimit-Remaining": "3", "X-RateLimit-Reset": "20"},
+			expected: apiQuota{limit: 60, remaining: 3, reset: 20 * time.Second},
+			ok:       true,
+		},
+		{
+			name:     "cloudflare",
+			header:   map[string]string{"RateLimit": `"default";r=50;t=30`, "RateLimit-Policy": `"default";q=1200;w=300`},
+			expected: apiQuota{limit: 1200, remaining: 50, reset: 30 * time.Second},
+			ok:       true,
+		},
+		{
+			name:     "remaining only",
+			header:   map[string]string{"RateLimit-Remaining": "10"},
+			expected: apiQuota{remaining: 10},
+			ok:       true,
+		},
+		{
+			name:   "no headers",
+			header: map[string]string{"Retry-After": "5"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			header := http.Header{}
+			for name, value := range tc.header {
+				header.Set(name, value)
+			}
+			quota, ok := parseAPIQuota(header, now)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, quota)
+		})
+	}
+}
+
+func TestQuotaTransport(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	transport := NewQuotaTransport("test", nil)
+	transport.now = func() time.Time { return now }
+	client := &http.Client{Transport: transport}
+
+	// plenty of headroom
+	transport.RoundTripper = quotaResponder{"RateLimit-Limit": "100", "RateLimit-Remaining": "90", "RateLimit-Reset": "30"}
+	resp, err := client.Get("https://api.example.com/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.True(t, transport.until.IsZero())
+	assert.Equal(t, 90.0, testutil.ToFloat64(apiQuotaRemaining.WithLabelValues("test")))
+	assert.Equal(t, 100.0, testutil.ToFloat64(apiQuotaLimit.WithLabelValues("test")))
+
+	// below the headroom, the remaining requests are spread over the rest of the window
+	transport.RoundTripper = quotaResponder{"RateLimit-Limit": "100", "RateLimit-Remaining": "5", "RateLimit-Reset": "30"}
+	resp, err = client.Get("https://api.example.com/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, now.Add(5*time.Second), transport.until)
+	assert.Equal(t, 5.0, testutil.ToFloat64(apiQuotaRemaining.WithLabelValues("test")))
+
+	// the pacing can be disabled
+	defer SetAPIQuotaHeadroom(apiQuotaHeadroom)
+	SetAPIQuotaHeadroom(0)
+	transport.until = time.Time{}
+	resp, err = client.Get("https://api.example.com/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.True(t, transport.until.IsZero())
+}
+
+func TestQuotaTransportWaitIsCanceled(t *testing.T) {
+	transport := NewQuotaTransport("test", quotaResponder{})
+	transport.until = time.Now().Add(time.Hour)
+	paced := testutil.ToFloat64(apiQuotaPacedRequests.WithLabelValues("test"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.example.com/", nil)
+	require.NoError(t, err)
+	_, err = transport.RoundTrip(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, paced+1, testutil.ToFloat64(apiQuotaPacedRequests.WithLabelValues("test")))
+}
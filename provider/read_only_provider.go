@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+var (
+	dryRunChangesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "provider",
+			Name:      "dry_run_changes_total",
+			Help:      "Number of changes held back from the provider in dry-run mode, by action.",
+		},
+		[]string{"action"},
+	)
+
+	registerReadOnlyProviderMetrics = sync.Once{}
+)
+
+// ReadOnlyProvider is a Provider never passing the changes to the wrapped provider, used with --dry-run.
+// The providers skip applying the changes in dry-run mode themselves, but a bug in a provider must not
+// change the records while the users believe they preview the changes: the changes are logged and
+// counted instead.
+type ReadOnlyProvider struct {
+	Provider
+}
+
+func NewReadOnlyProvider(provider Provider) *ReadOnlyProvider {
+	registerReadOnlyProviderMetrics.Do(func() {
+		prometheus.MustRegister(dryRunChangesTotal)
+	})
+	return &ReadOnlyProvider{Provider: provider}
+}
+
+// Unwrap returns the wrapped provider.
+func (r *ReadOnlyProvider) Unwrap() Provider {
+	return r.Provider
+}
+
+func (r *ReadOnlyProvider) ApplyChanges(_ context.Context, changes *plan.Changes) error {
+	log.Debug("Read-only provider: not applying the changes in dry-run mode")
+	logDryRun("create", changes.Create)
+	logDryRun("update", changes.UpdateNew)
+	logDryRun("delete", changes.Delete)
+	return nil
+}
+
+func logDryRun(action string, endpoints []*endpoint.Endpoint) {
+	for _, ep := range endpoints {
+		log.Infof("Dry run: would %s %s %s %s", action, ep.RecordType, ep.DNSName, ep.Targets)
+	}
+	dryRunChangesTotal.WithLabelValues(action).Add(float64(len(endpoints)))
+}
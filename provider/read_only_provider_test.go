@@ -0,0 +1,51 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func TestReadOnlyProvider(t *testing.T) {
+	wrapped := &excludedTypesProvider{}
+	p := NewReadOnlyProvider(wrapped)
+
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	created := testutil.ToFloat64(dryRunChangesTotal.WithLabelValues("create"))
+	deleted := testutil.ToFloat64(dryRunChangesTotal.WithLabelValues("delete"))
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+			endpoint.NewEndpoint("bar.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+		},
+		Delete: []*endpoint.Endpoint{endpoint.NewEndpoint("baz.example.com", endpoint.RecordTypeA, "1.2.3.4")},
+	}))
+	assert.Empty(t, wrapped.applied)
+	assert.Equal(t, created+2, testutil.ToFloat64(dryRunChangesTotal.WithLabelValues("create")))
+	assert.Equal(t, deleted+1, testutil.ToFloat64(dryRunChangesTotal.WithLabelValues("delete")))
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// RecordTypeExclusionProvider is a Provider hiding the records of the excluded types, so that they are neither
+// cached, nor seen by the registry and the plan, nor changed. Providers able to list the records of some types only
+// skip listing the excluded types, see ExcludedRecordTypesContextKey.
+type RecordTypeExclusionProvider struct {
+	Provider
+	Excluded []string
+}
+
+func NewRecordTypeExclusionProvider(provider Provider, excluded []string) *RecordTypeExclusionProvider {
+	return &RecordTypeExclusionProvider{
+		Provider: provider,
+		Excluded: excluded,
+	}
+}
+
+// Unwrap returns the wrapped provider.
+func (e *RecordTypeExclusionProvider) Unwrap() Provider {
+	return e.Provider
+}
+
+func (e *RecordTypeExclusionProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	records, err := e.Provider.Records(context.WithValue(ctx, ExcludedRecordTypesContextKey, e.Excluded))
+	if err != nil {
+		return nil, err
+	}
+	return e.filter(records), nil
+}
+
+func (e *RecordTypeExclusionProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	filtered := &plan.Changes{
+		Create: e.filter(changes.Create),
+		Delete: e.filter(changes.Delete),
+	}
+	// the current and desired endpoints of the updates are paired by their index
+	for i := range min(len(changes.UpdateOld), len(changes.UpdateNew)) {
+		if e.excluded(changes.UpdateOld[i]) || e.excluded(changes.UpdateNew[i]) {
+			log.Warnf("Skipping the update of %s %s of an excluded record type", changes.UpdateNew[i].RecordType, changes.UpdateNew[i].DNSName)
+			continue
+		}
+		filtered.UpdateOld = append(filtered.UpdateOld, changes.UpdateOld[i])
+		filtered.UpdateNew = append(filtered.UpdateNew, changes.UpdateNew[i])
+	}
+	return e.Provider.ApplyChanges(ctx, filtered)
+}
+
+func (e *RecordTypeExclusionProvider) excluded(ep *endpoint.Endpoint) bool {
+	return slices.Contains(e.Excluded, ep.RecordType)
+}
+
+func (e *RecordTypeExclusionProvider) filter(endpoints []*endpoint.Endpoint) []*endpoint.Endpoint {
+	var filtered []*endpoint.Endpoint
+	for _, ep := range endpoints {
+		if e.excluded(ep) {
+			log.Debugf("Skipping %s %s of an excluded record type", ep.RecordType, ep.DNSName)
+			continue
+		}
+		filtered = append(filtered, ep)
+	}
+	return filtered
+}
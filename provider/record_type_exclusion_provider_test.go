@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// excludedTypesProvider records the excluded record types of the context.
+type excludedTypesProvider struct {
+	recordingProvider
+	excluded []string
+}
+
+func (p *excludedTypesProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	p.excluded = ExcludedRecordTypes(ctx)
+	return []*endpoint.Endpoint{
+		endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("example.com", endpoint.RecordTypeNS, "ns1.example.com"),
+	}, nil
+}
+
+func TestRecordTypeExclusionProvider(t *testing.T) {
+	wrapped := &excludedTypesProvider{}
+	p := NewRecordTypeExclusionProvider(wrapped, []string{endpoint.RecordTypeNS})
+
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{endpoint.RecordTypeNS}, wrapped.excluded)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, endpoint.RecordTypeA, records[0].RecordType)
+	}
+
+	a := endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4")
+	ns := endpoint.NewEndpoint("example.com", endpoint.RecordTypeNS, "ns1.example.com")
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{
+		Create:    []*endpoint.Endpoint{a, ns},
+		UpdateOld: []*endpoint.Endpoint{ns, a},
+		UpdateNew: []*endpoint.Endpoint{ns, a},
+		Delete:    []*endpoint.Endpoint{ns},
+	}))
+	if assert.Len(t, wrapped.applied, 1) {
+		assert.Equal(t, []*endpoint.Endpoint{a}, wrapped.applied[0].Create)
+		assert.Equal(t, []*endpoint.Endpoint{a}, wrapped.applied[0].UpdateOld)
+		assert.Equal(t, []*endpoint.Endpoint{a}, wrapped.applied[0].UpdateNew)
+		assert.Empty(t, wrapped.applied[0].Delete)
+	}
+}
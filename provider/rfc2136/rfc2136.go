@@ -192,7 +192,8 @@ OuterLoop:
 			rrValues = []string{rr.(*dns.AAAA).AAAA.String()}
 			rrType = "AAAA"
 		case dns.TypeTXT:
-			rrValues = (rr.(*dns.TXT).Txt)
+			// the character strings of a record are the chunks of a single target, not one target each
+			rrValues = []string{strings.Join(rr.(*dns.TXT).Txt, "")}
 			rrType = "TXT"
 		case dns.TypeNS:
 			rrValues = []string{rr.(*dns.NS).Ns}
@@ -314,7 +315,7 @@ func (r rfc2136Provider) ApplyChanges(ctx context.Context, changes *plan.Changes
 
 	var errors []error
 
-	for c, chunk := range chunkBy(changes.Create, r.batchChangeSize) {
+	for c, batch := range provider.OwnershipBatches(changes.Create, r.batchChangeSize) {
 		log.Debugf("Processing batch %d of create changes", c)
 
 		m := make(map[string]*dns.Msg)
@@ -323,7 +324,8 @@ func (r rfc2136Provider) ApplyChanges(ctx context.Context, changes *plan.Changes
 			z = dns.Fqdn(z)
 			m[z] = new(dns.Msg)
 		}
-		for _, ep := range chunk {
+		for _, i := range batch {
+			ep := changes.Create[i]
 			if !r.domainFilter.Match(ep.DNSName) {
 				log.Debugf("Skipping record %s because it was filtered out by the specified --domain-filter", ep.DNSName)
 				continue
@@ -352,7 +354,7 @@ func (r rfc2136Provider) ApplyChanges(ctx context.Context, changes *plan.Changes
 		}
 	}
 
-	for c, chunk := range chunkBy(changes.UpdateNew, r.batchChangeSize) {
+	for c, batch := range provider.OwnershipBatches(changes.UpdateNew, r.batchChangeSize) {
 		log.Debugf("Processing batch %d of update changes", c)
 
 		m := make(map[string]*dns.Msg)
@@ -362,7 +364,8 @@ func (r rfc2136Provider) ApplyChanges(ctx context.Context, changes *plan.Changes
 			m[z] = new(dns.Msg)
 		}
 
-		for i, ep := range chunk {
+		for _, i := range batch {
+			ep := changes.UpdateNew[i]
 			if !r.domainFilter.Match(ep.DNSName) {
 				log.Debugf("Skipping record %s because it was filtered out by the specified --domain-filter", ep.DNSName)
 				continue
@@ -391,7 +394,7 @@ func (r rfc2136Provider) ApplyChanges(ctx context.Context, changes *plan.Changes
 		}
 	}
 
-	for c, chunk := range chunkBy(changes.Delete, r.batchChangeSize) {
+	for c, batch := range provider.OwnershipBatches(changes.Delete, r.batchChangeSize) {
 		log.Debugf("Processing batch %d of delete changes", c)
 
 		m := make(map[string]*dns.Msg)
@@ -400,7 +403,8 @@ func (r rfc2136Provider) ApplyChanges(ctx context.Context, changes *plan.Changes
 			z = dns.Fqdn(z)
 			m[z] = new(dns.Msg)
 		}
-		for _, ep := range chunk {
+		for _, i := range batch {
+			ep := changes.Delete[i]
 			if !r.domainFilter.Match(ep.DNSName) {
 				log.Debugf("Skipping record %s because it was filtered out by the specified --domain-filter", ep.DNSName)
 				continue
@@ -453,7 +457,7 @@ func (r rfc2136Provider) AddRecord(m *dns.Msg, ep *endpoint.Endpoint) error {
 	}
 
 	for _, target := range ep.Targets {
-		newRR := fmt.Sprintf("%s %d %s %s", ep.DNSName, ttl, ep.RecordType, target)
+		newRR := fmt.Sprintf("%s %d %s %s", ep.DNSName, ttl, ep.RecordType, rrData(ep.RecordType, target))
 		log.Infof("Adding RR: %s", newRR)
 
 		rr, err := dns.NewRR(newRR)
@@ -470,7 +474,7 @@ func (r rfc2136Provider) AddRecord(m *dns.Msg, ep *endpoint.Endpoint) error {
 func (r rfc2136Provider) RemoveRecord(m *dns.Msg, ep *endpoint.Endpoint) error {
 	log.Debugf("RemoveRecord.ep=%s", ep)
 	for _, target := range ep.Targets {
-		newRR := fmt.Sprintf("%s %d %s %s", ep.DNSName, ep.RecordTTL, ep.RecordType, target)
+		newRR := fmt.Sprintf("%s %d %s %s", ep.DNSName, ep.RecordTTL, ep.RecordType, rrData(ep.RecordType, target))
 		log.Infof("Removing RR: %s", newRR)
 
 		rr, err := dns.NewRR(newRR)
@@ -484,6 +488,15 @@ func (r rfc2136Provider) RemoveRecord(m *dns.Msg, ep *endpoint.Endpoint) error {
 	return nil
 }
 
+// rrData returns the target in zone file format. TXT targets are quoted so that their text is kept as a single
+// record split into character strings of at most 255 bytes, instead of a character string per word.
+func rrData(recordType, target string) string {
+	if recordType != endpoint.RecordTypeTXT {
+		return target
+	}
+	return endpoint.ParseTXTTarget(target).Chunked().String()
+}
+
 func (r rfc2136Provider) SendMessage(msg *dns.Msg) error {
 	if r.dryRun {
 		log.Debugf("SendMessage.skipped")
@@ -531,22 +544,6 @@ func (r rfc2136Provider) SendMessage(msg *dns.Msg) error {
 	return nil
 }
 
-func chunkBy(slice []*endpoint.Endpoint, chunkSize int) [][]*endpoint.Endpoint {
-	var chunks [][]*endpoint.Endpoint
-
-	for i := 0; i < len(slice); i += chunkSize {
-		end := i + chunkSize
-
-		if end > len(slice) {
-			end = len(slice)
-		}
-
-		chunks = append(chunks, slice[i:end])
-	}
-
-	return chunks
-}
-
 func findMsgZone(ep *endpoint.Endpoint, zoneNames []string) string {
 	for _, zone := range zoneNames {
 		if strings.HasSuffix(ep.DNSName, zone) {
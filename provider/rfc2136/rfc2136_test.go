@@ -30,6 +30,7 @@ import (
 	"github.com/miekg/dns"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"sigs.k8s.io/external-dns/endpoint"
 	"sigs.k8s.io/external-dns/plan"
@@ -209,6 +210,41 @@ func TestRfc2136GetRecordsMultipleTargets(t *testing.T) {
 	assert.Equal(t, 0, len(recs[0].ProviderSpecific), "expected no provider specific config")
 }
 
+// TestRfc2136GetRecordsLongTXT simulates a TXT record split into several character strings.
+func TestRfc2136GetRecordsLongTXT(t *testing.T) {
+	key := "v=DKIM1; k=rsa; p=" + strings.Repeat("A", 392)
+	stub := newStub()
+	err := stub.setOutput([]string{
+		fmt.Sprintf(`dkim._domainkey.foo.com 3600 IN TXT "%s" "%s"`, key[:255], key[255:]),
+	})
+	assert.NoError(t, err)
+
+	provider, err := createRfc2136StubProvider(stub)
+	assert.NoError(t, err)
+
+	recs, err := provider.Records(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, len(recs), "expected single record")
+	assert.Equal(t, endpoint.Targets{key}, recs[0].Targets, "expected a single target")
+}
+
+func TestRfc2136LongTXTCreation(t *testing.T) {
+	key := "v=DKIM1; k=rsa; p=" + strings.Repeat("A", 392)
+	stub := newStub()
+	provider, err := createRfc2136StubProvider(stub)
+	assert.NoError(t, err)
+
+	err = provider.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("dkim._domainkey.foo.com", endpoint.RecordTypeTXT, key)},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, len(stub.createMsgs))
+	txt := stub.createMsgs[0].Ns[0].(*dns.TXT)
+	assert.Equal(t, []string{key[:255], key[255:]}, txt.Txt, "expected the text split into character strings of 255 bytes")
+}
+
 func TestRfc2136PTRCreation(t *testing.T) {
 	stub := newStub()
 	provider, err := createRfc2136StubProviderWithReverse(stub)
@@ -747,22 +783,35 @@ func TestRfc2136ApplyChangesWithUpdate(t *testing.T) {
 	assert.True(t, strings.Contains(stub.updateMsgs[1].String(), "boom"))
 }
 
-func TestChunkBy(t *testing.T) {
-	var records []*endpoint.Endpoint
+func TestRfc2136ApplyChangesKeepsOwnershipRecordsInBatch(t *testing.T) {
+	stub := newStub()
+	provider, err := NewRfc2136Provider("", 0, nil, false, "key", "secret", "hmac-sha512", true, endpoint.DomainFilter{}, false, 300*time.Second, false, false, "", "", "", 2, TLSConfig{}, stub)
+	assert.NoError(t, err)
 
-	for i := 0; i < 10; i++ {
-		records = append(records, &endpoint.Endpoint{
-			DNSName:    "v1.foo.com",
-			RecordType: "A",
-			Targets:    []string{"1.1.2.2"},
-			RecordTTL:  endpoint.TTL(400),
-		})
+	txt := func(name, owned string) *endpoint.Endpoint {
+		ep := endpoint.NewEndpoint(name, endpoint.RecordTypeTXT, "heritage=external-dns,external-dns/owner=owner")
+		ep.Labels[endpoint.OwnedRecordLabelKey] = owned
+		return ep
 	}
-
-	chunks := chunkBy(records, 2)
-	if len(chunks) != 5 {
-		t.Errorf("incorrect number of chunks returned")
+	p := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("v1.foo.com", endpoint.RecordTypeA, "1.1.1.1"),
+			endpoint.NewEndpoint("v2.foo.com", endpoint.RecordTypeA, "2.2.2.2"),
+			txt("a-v1.foo.com", "v1.foo.com"),
+			txt("a-v2.foo.com", "v2.foo.com"),
+		},
 	}
+
+	err = provider.ApplyChanges(context.Background(), p)
+	assert.NoError(t, err)
+
+	// one message per line of the two messages
+	require.Len(t, stub.createMsgs, 4)
+	assert.Contains(t, stub.createMsgs[0].String(), "1.1.1.1")
+	assert.Contains(t, stub.createMsgs[0].String(), "a-v1.foo.com")
+	assert.NotContains(t, stub.createMsgs[0].String(), "2.2.2.2")
+	assert.Contains(t, stub.createMsgs[2].String(), "2.2.2.2")
+	assert.Contains(t, stub.createMsgs[2].String(), "a-v2.foo.com")
 }
 
 func contains(arr []*endpoint.Endpoint, name string) bool {
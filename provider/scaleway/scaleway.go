@@ -28,7 +28,6 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/external-dns/endpoint"
-	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
 	"sigs.k8s.io/external-dns/plan"
 	"sigs.k8s.io/external-dns/provider"
 )
@@ -80,7 +79,7 @@ func NewScalewayProvider(ctx context.Context, domainFilter endpoint.DomainFilter
 	scwClient, err := scw.NewClient(
 		scw.WithProfile(p),
 		scw.WithEnv(),
-		scw.WithUserAgent("ExternalDNS/"+externaldns.Version),
+		scw.WithUserAgent(provider.UserAgent()),
 		scw.WithDefaultPageSize(uint32(defaultPageSize)),
 	)
 	if err != nil {
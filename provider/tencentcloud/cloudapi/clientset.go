@@ -25,6 +25,8 @@ import (
 	dnspod "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/dnspod/v20210323"
 	privatedns "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/privatedns/v20201028"
 	"go.uber.org/ratelimit"
+
+	"sigs.k8s.io/external-dns/provider"
 )
 
 type TencentClientSetService interface {
@@ -44,12 +46,14 @@ func NewTencentClientSetService(region string, rate int, secretId string, secret
 		privatednsProf.HttpProfile.Endpoint = "privatedns.internal.tencentcloudapi.com"
 	}
 	p.privateDnsClient, _ = privatedns.NewClient(cred, region, privatednsProf)
+	p.privateDnsClient.WithHttpTransport(provider.NewUserAgentTransport(nil))
 
 	dnsPodProf := profile.NewClientProfile()
 	if !internetEndpoint {
 		dnsPodProf.HttpProfile.Endpoint = "dnspod.internal.tencentcloudapi.com"
 	}
 	p.dnsPodClient, _ = dnspod.NewClient(cred, region, dnsPodProf)
+	p.dnsPodClient.WithHttpTransport(provider.NewUserAgentTransport(nil))
 
 	return p
 }
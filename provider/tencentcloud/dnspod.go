@@ -17,6 +17,7 @@ limitations under the License.
 package tencentcloud
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -31,8 +32,8 @@ import (
 
 // DnsPod For Public Dns
 
-func (p *TencentCloudProvider) dnsRecords() ([]*endpoint.Endpoint, error) {
-	recordsList, err := p.recordsForDNS()
+func (p *TencentCloudProvider) dnsRecords(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	recordsList, err := p.recordsForDNS(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -52,15 +53,15 @@ func (p *TencentCloudProvider) dnsRecords() ([]*endpoint.Endpoint, error) {
 	return endpoints, nil
 }
 
-func (p *TencentCloudProvider) recordsForDNS() (map[uint64]*RecordListGroup, error) {
-	domainList, err := p.getDomainList()
+func (p *TencentCloudProvider) recordsForDNS(ctx context.Context) (map[uint64]*RecordListGroup, error) {
+	domainList, err := p.getDomainList(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	recordListGroup := make(map[uint64]*RecordListGroup, 0)
 	for _, domain := range domainList {
-		records, err := p.getDomainRecordList(*domain.Name)
+		records, err := p.getDomainRecordList(ctx, *domain.Name)
 		if err != nil {
 			return nil, err
 		}
@@ -77,8 +78,9 @@ func (p *TencentCloudProvider) recordsForDNS() (map[uint64]*RecordListGroup, err
 	return recordListGroup, nil
 }
 
-func (p *TencentCloudProvider) getDomainList() ([]*dnspod.DomainListItem, error) {
+func (p *TencentCloudProvider) getDomainList(ctx context.Context) ([]*dnspod.DomainListItem, error) {
 	request := dnspod.NewDescribeDomainListRequest()
+	request.SetContext(ctx)
 	request.Offset = common.Int64Ptr(0)
 	request.Limit = common.Int64Ptr(3000)
 
@@ -106,8 +108,9 @@ func (p *TencentCloudProvider) getDomainList() ([]*dnspod.DomainListItem, error)
 	return domainList, nil
 }
 
-func (p *TencentCloudProvider) getDomainRecordList(domain string) ([]*dnspod.RecordListItem, error) {
+func (p *TencentCloudProvider) getDomainRecordList(ctx context.Context, domain string) ([]*dnspod.RecordListItem, error) {
 	request := dnspod.NewDescribeRecordListRequest()
+	request.SetContext(ctx)
 	request.Domain = common.StringPtr(domain)
 	request.Offset = common.Uint64Ptr(0)
 	request.Limit = common.Uint64Ptr(3000)
@@ -138,8 +141,8 @@ type RecordListGroup struct {
 	RecordList []*dnspod.RecordListItem
 }
 
-func (p *TencentCloudProvider) applyChangesForDNS(changes *plan.Changes) error {
-	recordsGroupMap, err := p.recordsForDNS()
+func (p *TencentCloudProvider) applyChangesForDNS(ctx context.Context, changes *plan.Changes) error {
+	recordsGroupMap, err := p.recordsForDNS(ctx)
 	if err != nil {
 		return err
 	}
@@ -175,7 +178,7 @@ func (p *TencentCloudProvider) applyChangesForDNS(changes *plan.Changes) error {
 		}
 	}
 
-	if err := p.deleteRecords(deleteEndpoints); err != nil {
+	if err := p.deleteRecords(ctx, deleteEndpoints); err != nil {
 		return err
 	}
 
@@ -191,13 +194,13 @@ func (p *TencentCloudProvider) applyChangesForDNS(changes *plan.Changes) error {
 			}
 		}
 	}
-	if err := p.createRecord(recordsGroupMap, createEndpoints); err != nil {
+	if err := p.createRecord(ctx, recordsGroupMap, createEndpoints); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (p *TencentCloudProvider) createRecord(zoneMap map[uint64]*RecordListGroup, endpointsMap map[string][]*endpoint.Endpoint) error {
+func (p *TencentCloudProvider) createRecord(ctx context.Context, zoneMap map[uint64]*RecordListGroup, endpointsMap map[string][]*endpoint.Endpoint) error {
 	for zoneId, endpoints := range endpointsMap {
 		zoneIdString, _ := strconv.ParseUint(zoneId, 10, 64)
 		domain := zoneMap[zoneIdString]
@@ -206,7 +209,7 @@ func (p *TencentCloudProvider) createRecord(zoneMap map[uint64]*RecordListGroup,
 				if endpoint.RecordType == "TXT" && strings.HasPrefix(target, `"heritage=`) {
 					target = strings.Trim(target, `"`)
 				}
-				if err := p.createRecords(domain.Domain, endpoint, target); err != nil {
+				if err := p.createRecords(ctx, domain.Domain, endpoint, target); err != nil {
 					return err
 				}
 			}
@@ -215,8 +218,9 @@ func (p *TencentCloudProvider) createRecord(zoneMap map[uint64]*RecordListGroup,
 	return nil
 }
 
-func (p *TencentCloudProvider) createRecords(domain *dnspod.DomainListItem, endpoint *endpoint.Endpoint, target string) error {
+func (p *TencentCloudProvider) createRecords(ctx context.Context, domain *dnspod.DomainListItem, endpoint *endpoint.Endpoint, target string) error {
 	request := dnspod.NewCreateRecordRequest()
+	request.SetContext(ctx)
 
 	request.Domain = common.StringPtr(*domain.Name)
 	request.RecordType = common.StringPtr(endpoint.RecordType)
@@ -233,20 +237,21 @@ func (p *TencentCloudProvider) createRecords(domain *dnspod.DomainListItem, endp
 	return nil
 }
 
-func (p *TencentCloudProvider) deleteRecords(RecordIdsMap map[string][]uint64) error {
+func (p *TencentCloudProvider) deleteRecords(ctx context.Context, RecordIdsMap map[string][]uint64) error {
 	for domain, recordIds := range RecordIdsMap {
 		if len(recordIds) == 0 {
 			continue
 		}
-		if err := p.deleteRecord(domain, recordIds); err != nil {
+		if err := p.deleteRecord(ctx, domain, recordIds); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (p *TencentCloudProvider) deleteRecord(domain string, recordIds []uint64) error {
+func (p *TencentCloudProvider) deleteRecord(ctx context.Context, domain string, recordIds []uint64) error {
 	request := dnspod.NewDeleteRecordRequest()
+	request.SetContext(ctx)
 	request.Domain = common.StringPtr(domain)
 
 	for _, recordId := range recordIds {
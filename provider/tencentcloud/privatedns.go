@@ -17,6 +17,7 @@ limitations under the License.
 package tencentcloud
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -30,8 +31,8 @@ import (
 
 // PrivateZone For Internal Dns
 
-func (p *TencentCloudProvider) privateZoneRecords() ([]*endpoint.Endpoint, error) {
-	privateZones, err := p.recordForPrivateZone()
+func (p *TencentCloudProvider) privateZoneRecords(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	privateZones, err := p.recordForPrivateZone(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -51,15 +52,15 @@ func (p *TencentCloudProvider) privateZoneRecords() ([]*endpoint.Endpoint, error
 	return endpoints, nil
 }
 
-func (p *TencentCloudProvider) recordForPrivateZone() (map[string]*PrivateZoneRecordListGroup, error) {
-	privateZones, err := p.getPrivateZones()
+func (p *TencentCloudProvider) recordForPrivateZone(ctx context.Context) (map[string]*PrivateZoneRecordListGroup, error) {
+	privateZones, err := p.getPrivateZones(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	recordListGroup := make(map[string]*PrivateZoneRecordListGroup, 0)
 	for _, zone := range privateZones {
-		records, err := p.getPrivateZoneRecords(*zone.ZoneId)
+		records, err := p.getPrivateZoneRecords(ctx, *zone.ZoneId)
 		if err != nil {
 			return nil, err
 		}
@@ -78,7 +79,7 @@ func (p *TencentCloudProvider) recordForPrivateZone() (map[string]*PrivateZoneRe
 	return recordListGroup, nil
 }
 
-func (p *TencentCloudProvider) getPrivateZones() ([]*privatedns.PrivateZone, error) {
+func (p *TencentCloudProvider) getPrivateZones(ctx context.Context) ([]*privatedns.PrivateZone, error) {
 	filters := make([]*privatedns.Filter, 1)
 	filters[0] = &privatedns.Filter{
 		Name: common.StringPtr("Vpc"),
@@ -99,6 +100,7 @@ func (p *TencentCloudProvider) getPrivateZones() ([]*privatedns.PrivateZone, err
 	}
 
 	request := privatedns.NewDescribePrivateZoneListRequest()
+	request.SetContext(ctx)
 	request.Filters = filters
 	request.Offset = common.Int64Ptr(0)
 	request.Limit = common.Int64Ptr(100)
@@ -127,8 +129,9 @@ func (p *TencentCloudProvider) getPrivateZones() ([]*privatedns.PrivateZone, err
 	return privateZonesFilter, nil
 }
 
-func (p *TencentCloudProvider) getPrivateZoneRecords(zoneId string) ([]*privatedns.PrivateZoneRecord, error) {
+func (p *TencentCloudProvider) getPrivateZoneRecords(ctx context.Context, zoneId string) ([]*privatedns.PrivateZoneRecord, error) {
 	request := privatedns.NewDescribePrivateZoneRecordListRequest()
+	request.SetContext(ctx)
 	request.ZoneId = common.StringPtr(zoneId)
 	request.Offset = common.Int64Ptr(0)
 	request.Limit = common.Int64Ptr(100)
@@ -155,8 +158,8 @@ type PrivateZoneRecordListGroup struct {
 }
 
 // Returns nil if the operation was successful or an error if the operation failed.
-func (p *TencentCloudProvider) applyChangesForPrivateZone(changes *plan.Changes) error {
-	zoneGroups, err := p.recordForPrivateZone()
+func (p *TencentCloudProvider) applyChangesForPrivateZone(ctx context.Context, changes *plan.Changes) error {
+	zoneGroups, err := p.recordForPrivateZone(ctx)
 	if err != nil {
 		return err
 	}
@@ -164,7 +167,7 @@ func (p *TencentCloudProvider) applyChangesForPrivateZone(changes *plan.Changes)
 	// In PrivateDns Service. A Zone has at least one record. The last rule cannot be deleted.
 	for _, zoneGroup := range zoneGroups {
 		if !containsBaseRecord(zoneGroup.RecordList) {
-			err := p.createPrivateZoneRecord(zoneGroup.Zone, &endpoint.Endpoint{
+			err := p.createPrivateZoneRecord(ctx, zoneGroup.Zone, &endpoint.Endpoint{
 				DNSName:    *zoneGroup.Zone.Domain,
 				RecordType: "TXT",
 			}, "tencent_provider_record")
@@ -204,7 +207,7 @@ func (p *TencentCloudProvider) applyChangesForPrivateZone(changes *plan.Changes)
 		}
 	}
 
-	if err := p.deletePrivateZoneRecords(deleteEndpoints); err != nil {
+	if err := p.deletePrivateZoneRecords(ctx, deleteEndpoints); err != nil {
 		return err
 	}
 
@@ -220,7 +223,7 @@ func (p *TencentCloudProvider) applyChangesForPrivateZone(changes *plan.Changes)
 			}
 		}
 	}
-	if err := p.createPrivateZoneRecords(zoneGroups, createEndpoints); err != nil {
+	if err := p.createPrivateZoneRecords(ctx, zoneGroups, createEndpoints); err != nil {
 		return err
 	}
 	return nil
@@ -235,7 +238,7 @@ func containsBaseRecord(records []*privatedns.PrivateZoneRecord) bool {
 	return false
 }
 
-func (p *TencentCloudProvider) createPrivateZoneRecords(zoneGroups map[string]*PrivateZoneRecordListGroup, endpointsMap map[string][]*endpoint.Endpoint) error {
+func (p *TencentCloudProvider) createPrivateZoneRecords(ctx context.Context, zoneGroups map[string]*PrivateZoneRecordListGroup, endpointsMap map[string][]*endpoint.Endpoint) error {
 	for zoneId, endpoints := range endpointsMap {
 		zoneGroup := zoneGroups[zoneId]
 		for _, endpoint := range endpoints {
@@ -243,7 +246,7 @@ func (p *TencentCloudProvider) createPrivateZoneRecords(zoneGroups map[string]*P
 				if endpoint.RecordType == "TXT" && strings.HasPrefix(target, "\"heritage=") {
 					target = strings.Trim(target, "\"")
 				}
-				if err := p.createPrivateZoneRecord(zoneGroup.Zone, endpoint, target); err != nil {
+				if err := p.createPrivateZoneRecord(ctx, zoneGroup.Zone, endpoint, target); err != nil {
 					return err
 				}
 			}
@@ -252,20 +255,21 @@ func (p *TencentCloudProvider) createPrivateZoneRecords(zoneGroups map[string]*P
 	return nil
 }
 
-func (p *TencentCloudProvider) deletePrivateZoneRecords(zoneRecordIdsMap map[string][]string) error {
+func (p *TencentCloudProvider) deletePrivateZoneRecords(ctx context.Context, zoneRecordIdsMap map[string][]string) error {
 	for zoneId, zoneRecordIds := range zoneRecordIdsMap {
 		if len(zoneRecordIds) == 0 {
 			continue
 		}
-		if err := p.deletePrivateZoneRecord(zoneId, zoneRecordIds); err != nil {
+		if err := p.deletePrivateZoneRecord(ctx, zoneId, zoneRecordIds); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (p *TencentCloudProvider) createPrivateZoneRecord(zone *privatedns.PrivateZone, endpoint *endpoint.Endpoint, target string) error {
+func (p *TencentCloudProvider) createPrivateZoneRecord(ctx context.Context, zone *privatedns.PrivateZone, endpoint *endpoint.Endpoint, target string) error {
 	request := privatedns.NewCreatePrivateZoneRecordRequest()
+	request.SetContext(ctx)
 	request.ZoneId = common.StringPtr(*zone.ZoneId)
 	request.RecordType = common.StringPtr(endpoint.RecordType)
 	request.RecordValue = common.StringPtr(target)
@@ -280,13 +284,14 @@ func (p *TencentCloudProvider) createPrivateZoneRecord(zone *privatedns.PrivateZ
 	return nil
 }
 
-func (p *TencentCloudProvider) deletePrivateZoneRecord(zoneId string, zoneRecordIds []string) error {
+func (p *TencentCloudProvider) deletePrivateZoneRecord(ctx context.Context, zoneId string, zoneRecordIds []string) error {
 	recordIds := make([]*string, len(zoneRecordIds))
 	for index, recordId := range zoneRecordIds {
 		recordIds[index] = common.StringPtr(recordId)
 	}
 
 	request := privatedns.NewDeletePrivateZoneRecordRequest()
+	request.SetContext(ctx)
 	request.ZoneId = common.StringPtr(zoneId)
 	request.RecordIdSet = recordIds
 
@@ -84,9 +84,9 @@ type tencentCloudConfig struct {
 
 func (p *TencentCloudProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
 	if p.privateZone {
-		return p.privateZoneRecords()
+		return p.privateZoneRecords(ctx)
 	}
-	return p.dnsRecords()
+	return p.dnsRecords(ctx)
 }
 
 func (p *TencentCloudProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
@@ -97,9 +97,9 @@ func (p *TencentCloudProvider) ApplyChanges(ctx context.Context, changes *plan.C
 	log.Infof("apply changes. %s", cloudapi.JsonWrapper(changes))
 
 	if p.privateZone {
-		return p.applyChangesForPrivateZone(changes)
+		return p.applyChangesForPrivateZone(ctx, changes)
 	}
-	return p.applyChangesForDNS(changes)
+	return p.applyChangesForDNS(ctx, changes)
 }
 
 func getSubDomain(domain string, endpoint *endpoint.Endpoint) string {
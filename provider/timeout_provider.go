@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+var (
+	timeoutsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "provider",
+			Name:      "timeouts_total",
+			Help:      "Number of calls to the provider exceeding the provider timeout.",
+		},
+		[]string{
+			"method",
+		},
+	)
+
+	registerTimeoutProviderMetrics = sync.Once{}
+)
+
+// TimeoutProvider is a Provider enforcing a deadline on the calls to Records and ApplyChanges.
+// The deadline is propagated with the context. Calls to Records of providers ignoring the context
+// are abandoned after the timeout, so that they cannot hang a synchronization, while calls to
+// ApplyChanges are always waited for: abandoning a write in flight would let the next
+// synchronization calculate its plan while the changes are still being applied.
+type TimeoutProvider struct {
+	Provider
+	Timeout time.Duration
+}
+
+func NewTimeoutProvider(provider Provider, timeout time.Duration) *TimeoutProvider {
+	registerTimeoutProviderMetrics.Do(func() {
+		prometheus.MustRegister(timeoutsTotal)
+	})
+	return &TimeoutProvider{
+		Provider: provider,
+		Timeout:  timeout,
+	}
+}
+
+// Unwrap returns the wrapped provider.
+func (t *TimeoutProvider) Unwrap() Provider {
+	return t.Provider
+}
+
+func (t *TimeoutProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	return withTimeout(ctx, t.Timeout, "records", t.Provider.Records)
+}
+
+func (t *TimeoutProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	ctx, cancel := context.WithTimeout(ctx, t.Timeout)
+	defer cancel()
+
+	err := t.Provider.ApplyChanges(ctx, changes)
+	if err != nil && ctx.Err() != nil {
+		timeoutsTotal.WithLabelValues("apply_changes").Inc()
+		return NewSoftError(fmt.Errorf("provider call apply_changes interrupted: %w", errors.Join(ctx.Err(), err)))
+	}
+	return err
+}
+
+// withTimeout runs the call with a deadline and returns a SoftError once the deadline is exceeded
+// or the context is canceled, even if the call does not return.
+func withTimeout[T any](ctx context.Context, timeout time.Duration, method string, call func(context.Context) (T, error)) (T, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := call(ctx)
+		done <- result{value: value, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		timeoutsTotal.WithLabelValues(method).Inc()
+		var zero T
+		return zero, NewSoftError(fmt.Errorf("provider call %s abandoned: %w", method, ctx.Err()))
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// hangingProvider blocks until released, ignoring the context.
+type hangingProvider struct {
+	BaseProvider
+	release chan struct{}
+	records []*endpoint.Endpoint
+}
+
+func (p *hangingProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	<-p.release
+	return p.records, nil
+}
+
+func (p *hangingProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	<-p.release
+	return nil
+}
+
+func TestTimeoutProvider(t *testing.T) {
+	hanging := &hangingProvider{
+		release: make(chan struct{}),
+		records: []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4")},
+	}
+	p := NewTimeoutProvider(hanging, 50*time.Millisecond)
+
+	_, err := p.Records(context.Background())
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, SoftError))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// canceling the context abandons the call immediately
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = p.Records(ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	close(hanging.release)
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, hanging.records, records)
+}
+
+func TestTimeoutProviderWaitsForChanges(t *testing.T) {
+	hanging := &hangingProvider{release: make(chan struct{})}
+	p := NewTimeoutProvider(hanging, 10*time.Millisecond)
+
+	// the changes of providers ignoring the context are never abandoned in flight
+	done := make(chan error)
+	go func() {
+		done <- p.ApplyChanges(context.Background(), &plan.Changes{})
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("ApplyChanges returned before the changes were applied: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(hanging.release)
+	assert.NoError(t, <-done)
+
+	// providers interrupted by the deadline return a retryable error
+	var deadline time.Time
+	p = NewTimeoutProvider(&contextCheckingProvider{deadline: &deadline, wait: true}, 10*time.Millisecond)
+	err := p.ApplyChanges(context.Background(), &plan.Changes{})
+	assert.True(t, errors.Is(err, SoftError))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.WithinDuration(t, time.Now(), deadline, time.Second)
+}
+
+func TestTimeoutProviderPropagatesDeadline(t *testing.T) {
+	var deadline time.Time
+	p := NewTimeoutProvider(&contextCheckingProvider{deadline: &deadline}, time.Minute)
+
+	_, err := p.Records(context.Background())
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+}
+
+type contextCheckingProvider struct {
+	BaseProvider
+	deadline *time.Time
+	// wait makes ApplyChanges wait for the deadline
+	wait bool
+}
+
+func (p *contextCheckingProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	*p.deadline, _ = ctx.Deadline()
+	return nil, nil
+}
+
+func (p *contextCheckingProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	*p.deadline, _ = ctx.Deadline()
+	if p.wait {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return nil
+}
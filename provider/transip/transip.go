@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -70,6 +71,7 @@ func NewTransIPProvider(accountName, privateKeyFile string, domainFilter endpoin
 		AccountName:    accountName,
 		PrivateKeyPath: privateKeyFile,
 		Mode:           apiMode,
+		HTTPClient:     &http.Client{Transport: provider.NewUserAgentTransport(nil)},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("could not setup TransIP API client: %w", err)
@@ -127,6 +127,7 @@ func NewUltraDNSProvider(domainFilter endpoint.DomainFilter, dryRun bool) (*Ultr
 	if err != nil {
 		return nil, fmt.Errorf("connection cannot be established")
 	}
+	client.HTTPClient.Transport = provider.NewUserAgentTransport(client.HTTPClient.Transport)
 
 	provider := &UltraDNSProvider{
 		client:       *client,
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// userAgent is the User-Agent of the requests to the DNS provider APIs, see SetUserAgent.
+var userAgent = struct {
+	product string
+	cluster string
+	ownerID string
+}{product: "ExternalDNS"}
+
+// SetUserAgent sets the User-Agent of the requests to the DNS provider APIs, which providers must set before creating their API clients.
+// The product is e.g. ExternalDNS/v0.15.0. The cluster name and the owner ID attribute the requests to an instance and are omitted if empty.
+// The User-Agent is shared by the process: with several instances embedded in a binary, the clients created after the
+// last call use its attribution.
+func SetUserAgent(product, cluster, ownerID string) {
+	userAgent.product = product
+	userAgent.cluster = cluster
+	userAgent.ownerID = ownerID
+}
+
+// UserAgent returns the User-Agent of the requests to the DNS provider APIs, e.g. "ExternalDNS/v0.15.0 (cluster=prod; owner=prod-eu)".
+func UserAgent() string {
+	var attribution []string
+	if userAgent.cluster != "" {
+		attribution = append(attribution, "cluster="+userAgent.cluster)
+	}
+	if userAgent.ownerID != "" {
+		attribution = append(attribution, "owner="+userAgent.ownerID)
+	}
+	if len(attribution) == 0 {
+		return userAgent.product
+	}
+	return fmt.Sprintf("%s (%s)", userAgent.product, strings.Join(attribution, "; "))
+}
+
+// UserAgentAttribution returns the cluster name and owner ID of the User-Agent, for clients that build the User-Agent from key-value pairs.
+func UserAgentAttribution() (cluster, ownerID string) {
+	return userAgent.cluster, userAgent.ownerID
+}
+
+// UserAgentTransport is a http.RoundTripper setting the User-Agent of the requests to the DNS provider API,
+// for API clients that do not support setting it.
+type UserAgentTransport struct {
+	http.RoundTripper
+}
+
+// NewUserAgentTransport wraps the transport of a provider's API client.
+// If transport is nil, http.DefaultTransport is used.
+func NewUserAgentTransport(transport http.RoundTripper) *UserAgentTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &UserAgentTransport{RoundTripper: transport}
+}
+
+func (t *UserAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", UserAgent())
+	return t.RoundTripper.RoundTrip(req)
+}
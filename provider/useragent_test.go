@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserAgent(t *testing.T) {
+	defer SetUserAgent("ExternalDNS", "", "")
+
+	assert.Equal(t, "ExternalDNS", UserAgent())
+
+	SetUserAgent("ExternalDNS/v1.0.0", "", "")
+	assert.Equal(t, "ExternalDNS/v1.0.0", UserAgent())
+
+	SetUserAgent("ExternalDNS/v1.0.0", "", "prod-eu")
+	assert.Equal(t, "ExternalDNS/v1.0.0 (owner=prod-eu)", UserAgent())
+
+	SetUserAgent("ExternalDNS/v1.0.0", "prod", "prod-eu")
+	assert.Equal(t, "ExternalDNS/v1.0.0 (cluster=prod; owner=prod-eu)", UserAgent())
+	cluster, ownerID := UserAgentAttribution()
+	assert.Equal(t, "prod", cluster)
+	assert.Equal(t, "prod-eu", ownerID)
+}
+
+func TestUserAgentTransport(t *testing.T) {
+	defer SetUserAgent("ExternalDNS", "", "")
+	SetUserAgent("ExternalDNS/v1.0.0", "prod", "")
+
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("User-Agent")
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("User-Agent", "sdk/1.0")
+	resp, err := (&http.Client{Transport: NewUserAgentTransport(nil)}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "ExternalDNS/v1.0.0 (cluster=prod)", received)
+	assert.Equal(t, "sdk/1.0", req.Header.Get("User-Agent"))
+}
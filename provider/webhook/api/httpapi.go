@@ -17,7 +17,6 @@ limitations under the License.
 package api
 
 import (
-	"context"
 	"encoding/json"
 	"net"
 	"net/http"
@@ -42,7 +41,7 @@ type WebhookServer struct {
 func (p *WebhookServer) RecordsHandler(w http.ResponseWriter, req *http.Request) {
 	switch req.Method {
 	case http.MethodGet:
-		records, err := p.Provider.Records(context.Background())
+		records, err := p.Provider.Records(req.Context())
 		if err != nil {
 			log.Errorf("Failed to get Records: %v", err)
 			w.WriteHeader(http.StatusInternalServerError)
@@ -61,7 +60,7 @@ func (p *WebhookServer) RecordsHandler(w http.ResponseWriter, req *http.Request)
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
-		err := p.Provider.ApplyChanges(context.Background(), &changes)
+		err := p.Provider.ApplyChanges(req.Context(), &changes)
 		if err != nil {
 			log.Errorf("Failed to apply changes: %v", err)
 			w.WriteHeader(http.StatusInternalServerError)
@@ -118,7 +118,7 @@ func NewWebhookProvider(u string) (*WebhookProvider, error) {
 	}
 	req.Header.Set(acceptHeader, webhookapi.MediaTypeFormatAndVersion)
 
-	client := &http.Client{}
+	client := &http.Client{Transport: provider.NewUserAgentTransport(nil)}
 	var resp *http.Response
 	err = backoff.Retry(func() error {
 		resp, err = client.Do(req)
@@ -163,7 +163,7 @@ func (p WebhookProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, err
 	recordsRequestsGauge.Inc()
 	u := p.remoteServerURL.JoinPath("records").String()
 
-	req, err := http.NewRequest("GET", u, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
 		recordsErrorsGauge.Inc()
 		log.Debugf("Failed to create request: %s", err.Error())
@@ -209,7 +209,7 @@ func (p WebhookProvider) ApplyChanges(ctx context.Context, changes *plan.Changes
 		return err
 	}
 
-	req, err := http.NewRequest("POST", u, b)
+	req, err := http.NewRequestWithContext(ctx, "POST", u, b)
 	if err != nil {
 		applyChangesErrorsGauge.Inc()
 		log.Debugf("Failed to create request: %s", err.Error())
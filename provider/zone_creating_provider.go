@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// ZoneCreatingProvider is a Provider creating the missing zones of the domain filter which the created records
+// require, used with --create-missing-zones. With --delete-empty-zones, the zones it created are deleted once
+// their records are deleted.
+type ZoneCreatingProvider struct {
+	Provider
+	creator     ZoneCreator
+	lister      ZoneLister
+	domains     []string
+	ownerID     string
+	deleteEmpty bool
+}
+
+func NewZoneCreatingProvider(provider Provider, creator ZoneCreator, lister ZoneLister, domainFilter endpoint.DomainFilter, ownerID string, deleteEmpty bool) *ZoneCreatingProvider {
+	var domains []string
+	for _, domain := range domainFilter.Filters {
+		// a filter starting with a dot matches the subdomains only, it is not a zone
+		if !strings.HasPrefix(domain, ".") {
+			domains = append(domains, domain)
+		}
+	}
+	return &ZoneCreatingProvider{
+		Provider:    provider,
+		creator:     creator,
+		lister:      lister,
+		domains:     domains,
+		ownerID:     ownerID,
+		deleteEmpty: deleteEmpty,
+	}
+}
+
+// Unwrap returns the wrapped provider.
+func (z *ZoneCreatingProvider) Unwrap() Provider {
+	return z.Provider
+}
+
+func (z *ZoneCreatingProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	if len(changes.Create) > 0 && len(z.domains) > 0 {
+		zones, err := z.lister.ZoneNames(ctx)
+		if err != nil {
+			return err
+		}
+		for _, ep := range changes.Create {
+			if ZoneOf(ep.DNSName, zones) != "" {
+				continue
+			}
+			zone := ZoneOf(ep.DNSName, z.domains)
+			if zone == "" {
+				continue
+			}
+			log.Infof("Creating the missing zone %s of %s", zone, ep.DNSName)
+			if err := z.creator.CreateOwnedZone(ctx, zone, z.ownerID); err != nil {
+				return fmt.Errorf("failed to create the zone %s: %w", zone, err)
+			}
+			zones = append(zones, zone)
+		}
+	}
+
+	if err := z.Provider.ApplyChanges(ctx, changes); err != nil {
+		return err
+	}
+
+	if z.deleteEmpty && len(changes.Delete) > 0 {
+		zones, err := z.lister.ZoneNames(ctx)
+		if err != nil {
+			return err
+		}
+		checked := map[string]bool{}
+		for _, ep := range changes.Delete {
+			zone := ZoneOf(ep.DNSName, zones)
+			if zone == "" || checked[zone] || ZoneOf(zone, z.domains) != zone {
+				continue
+			}
+			checked[zone] = true
+			deleted, err := z.creator.DeleteOwnedZone(ctx, zone, z.ownerID)
+			if err != nil {
+				log.Warnf("Failed to delete the zone %s: %v", zone, err)
+			} else if deleted {
+				log.Infof("Deleted the empty zone %s", zone)
+			}
+		}
+	}
+	return nil
+}
+
+// ZoneOf returns the longest of the zones the name is in, or an empty string.
+func ZoneOf(name string, zones []string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	match := ""
+	for _, zone := range zones {
+		zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+		if (name == zone || strings.HasSuffix(name, "."+zone)) && len(zone) > len(match) {
+			match = zone
+		}
+	}
+	return match
+}
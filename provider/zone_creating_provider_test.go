@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// zonesProvider is a ZoneCreator provider recording the changes applied and the zones created and deleted.
+type zonesProvider struct {
+	BaseProvider
+	zones    []string
+	owners   map[string]string
+	applied  []*plan.Changes
+	deleted  []string
+	createFn func(zone string) error
+}
+
+func (p *zonesProvider) Records(context.Context) ([]*endpoint.Endpoint, error) {
+	return nil, nil
+}
+
+func (p *zonesProvider) ApplyChanges(_ context.Context, changes *plan.Changes) error {
+	p.applied = append(p.applied, changes)
+	return nil
+}
+
+func (p *zonesProvider) ZoneNames(context.Context) ([]string, error) {
+	return p.zones, nil
+}
+
+func (p *zonesProvider) CreateOwnedZone(_ context.Context, zone, ownerID string) error {
+	if p.createFn != nil {
+		if err := p.createFn(zone); err != nil {
+			return err
+		}
+	}
+	p.zones = append(p.zones, zone)
+	p.owners[zone] = ownerID
+	return nil
+}
+
+func (p *zonesProvider) DeleteOwnedZone(_ context.Context, zone, ownerID string) (bool, error) {
+	if p.owners[zone] != ownerID {
+		return false, nil
+	}
+	p.zones = slices.DeleteFunc(p.zones, func(z string) bool { return z == zone })
+	p.deleted = append(p.deleted, zone)
+	return true, nil
+}
+
+func TestZoneCreatingProvider(t *testing.T) {
+	ctx := context.Background()
+	wrapped := &zonesProvider{zones: []string{"example.com"}, owners: map[string]string{}}
+	domainFilter := endpoint.NewDomainFilter([]string{"example.com", "example.org", "team.example.net", ".example.io"})
+	p := NewZoneCreatingProvider(wrapped, wrapped, wrapped, domainFilter, "owner", true)
+
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+			endpoint.NewEndpoint("app.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+			endpoint.NewEndpoint("www.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+			endpoint.NewEndpoint("app.team.example.net", endpoint.RecordTypeA, "1.2.3.4"),
+			endpoint.NewEndpoint("app.example.io", endpoint.RecordTypeA, "1.2.3.4"),
+		},
+	}
+	require.NoError(t, p.ApplyChanges(ctx, changes))
+	assert.Equal(t, []string{"example.com", "example.org", "team.example.net"}, wrapped.zones)
+	assert.Equal(t, map[string]string{"example.org": "owner", "team.example.net": "owner"}, wrapped.owners)
+	require.Len(t, wrapped.applied, 1)
+	assert.Same(t, changes, wrapped.applied[0])
+
+	// the zones created with the owner ID are deleted once their records are deleted
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Delete: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+			endpoint.NewEndpoint("app.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+			endpoint.NewEndpoint("www.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+		},
+	}))
+	assert.Equal(t, []string{"example.org"}, wrapped.deleted)
+	assert.Equal(t, []string{"example.com", "team.example.net"}, wrapped.zones)
+
+	// the zones are kept without --delete-empty-zones
+	p = NewZoneCreatingProvider(wrapped, wrapped, wrapped, domainFilter, "owner", false)
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Delete: []*endpoint.Endpoint{endpoint.NewEndpoint("app.team.example.net", endpoint.RecordTypeA, "1.2.3.4")},
+	}))
+	assert.Equal(t, []string{"example.org"}, wrapped.deleted)
+}
+
+func TestZoneCreatingProviderError(t *testing.T) {
+	wrapped := &zonesProvider{owners: map[string]string{}, createFn: func(string) error { return errors.New("denied") }}
+	p := NewZoneCreatingProvider(wrapped, wrapped, wrapped, endpoint.NewDomainFilter([]string{"example.com"}), "owner", false)
+
+	err := p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.2.3.4")},
+	})
+	assert.ErrorContains(t, err, "failed to create the zone example.com: denied")
+	assert.Empty(t, wrapped.applied)
+}
@@ -0,0 +1,331 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	// apexPartLabelKey and apexRecordsLabelKey are the labels of the values of an ownership record: the index of the
+	// value and its part of the compressed list of the owned records.
+	apexPartLabelKey    = "part"
+	apexRecordsLabelKey = "records"
+
+	// apexMaxValueLength is the maximum length of a TXT value, with its quotes.
+	apexMaxValueLength = 255
+)
+
+// ApexTXTRegistry implements the registry interface with a single ownership TXT record per zone and owner, named
+// <owner ID>.<name>.<zone>, listing all the records of the zone the owner owns, instead of an ownership TXT record
+// per record. The list is compressed and split into the values of the TXT record.
+type ApexTXTRegistry struct {
+	provider provider.Provider
+	zones    provider.ZoneLister
+	ownerID  string
+	name     string
+
+	// the zones, and the records owned by us and our ownership record by zone, as of the last listing of the zones
+	zoneNames []string
+	owned     map[string]sets.Set[string]
+	ownership map[string]*endpoint.Endpoint
+}
+
+// NewApexTXTRegistry returns a new ApexTXTRegistry object. The owner ID must be a DNS label, as it names the
+// ownership records.
+func NewApexTXTRegistry(provider provider.Provider, zones provider.ZoneLister, ownerID, name string) (*ApexTXTRegistry, error) {
+	if ownerID == "" {
+		return nil, errors.New("owner id cannot be empty")
+	}
+	if errs := validation.IsDNS1123Label(ownerID); len(errs) > 0 {
+		return nil, fmt.Errorf("owner id %q must be a DNS label: %s", ownerID, strings.Join(errs, ", "))
+	}
+	if name == "" {
+		return nil, errors.New("the name of the ownership records cannot be empty")
+	}
+	return &ApexTXTRegistry{
+		provider:  provider,
+		zones:     zones,
+		ownerID:   ownerID,
+		name:      name,
+		owned:     map[string]sets.Set[string]{},
+		ownership: map[string]*endpoint.Endpoint{},
+	}, nil
+}
+
+func (im *ApexTXTRegistry) GetDomainFilter() endpoint.DomainFilterInterface {
+	return im.provider.GetDomainFilter()
+}
+
+func (im *ApexTXTRegistry) OwnerID() string {
+	return im.ownerID
+}
+
+// Records returns the records of the provider but the ownership records, labeled with the owners the ownership
+// records list them for.
+func (im *ApexTXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	records, err := im.provider.Records(ctx)
+	if err != nil {
+		return nil, err
+	}
+	zoneNames, err := im.zones.ZoneNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	listed := zoneNames
+	if zone, ok := provider.ZoneScope(ctx); ok {
+		listed = []string{zone}
+	}
+
+	owners := map[string]string{}
+	owned := map[string]sets.Set[string]{}
+	ownership := map[string]*endpoint.Endpoint{}
+	var result []*endpoint.Endpoint
+	for _, record := range records {
+		if record.RecordType == endpoint.RecordTypeTXT {
+			if owner, zone, ok := im.ownershipRecordOf(record.DNSName, zoneNames); ok {
+				keys, err := decodeApexOwnership(record.Targets)
+				if err != nil {
+					log.Warnf("Ignoring the invalid ownership record %s: %v", record.DNSName, err)
+					continue
+				}
+				for key := range keys {
+					owners[key] = owner
+				}
+				if owner == im.ownerID {
+					owned[zone], ownership[zone] = keys, record
+				}
+				continue
+			}
+		}
+		result = append(result, record)
+	}
+
+	for _, record := range result {
+		if owner, ok := owners[apexKey(record)]; ok {
+			if record.Labels == nil {
+				record.Labels = endpoint.NewLabels()
+			}
+			record.Labels[endpoint.OwnerLabelKey] = owner
+		}
+	}
+	for _, zone := range listed {
+		im.owned[zone] = owned[zone]
+		if ownership[zone] != nil {
+			im.ownership[zone] = ownership[zone]
+		} else {
+			delete(im.ownership, zone)
+		}
+	}
+	im.zoneNames = zoneNames
+	return result, nil
+}
+
+// ApplyChanges applies the changes with the changes of the ownership records of the zones of the changed records.
+func (im *ApexTXTRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	owned := map[string]sets.Set[string]{}
+	track := func(records []*endpoint.Endpoint, own bool) {
+		for _, record := range records {
+			zone := provider.ZoneOf(record.DNSName, im.zoneNames)
+			if zone == "" {
+				log.Warnf("The zone of %s is unknown, its ownership is not recorded", record.DNSName)
+				continue
+			}
+			if owned[zone] == nil {
+				owned[zone] = sets.New[string]()
+				if im.owned[zone] != nil {
+					owned[zone] = im.owned[zone].Clone()
+				}
+			}
+			if own {
+				owned[zone].Insert(apexKey(record))
+			} else {
+				owned[zone].Delete(apexKey(record))
+			}
+		}
+	}
+	track(changes.Delete, false)
+	track(changes.UpdateOld, false)
+	track(changes.UpdateNew, true)
+	track(changes.Create, true)
+
+	filteredChanges := &plan.Changes{
+		Create:    slices.Clone(changes.Create),
+		UpdateOld: slices.Clone(changes.UpdateOld),
+		UpdateNew: slices.Clone(changes.UpdateNew),
+		Delete:    slices.Clone(changes.Delete),
+	}
+	ownership := map[string]*endpoint.Endpoint{}
+	for zone, keys := range owned {
+		if keys.Equal(im.owned[zone]) || (keys.Len() == 0 && im.owned[zone] == nil) {
+			continue
+		}
+		current := im.ownership[zone]
+		if keys.Len() == 0 {
+			if current != nil {
+				filteredChanges.Delete = append(filteredChanges.Delete, current)
+			}
+			ownership[zone] = nil
+			continue
+		}
+		values, err := im.encodeOwnership(keys)
+		if err != nil {
+			return err
+		}
+		desired := endpoint.NewEndpoint(im.ownerID+"."+im.name+"."+zone, endpoint.RecordTypeTXT, values...)
+		if current == nil {
+			filteredChanges.Create = append(filteredChanges.Create, desired)
+		} else {
+			desired.RecordTTL = current.RecordTTL
+			desired.ProviderSpecific = current.ProviderSpecific
+			filteredChanges.UpdateOld = append(filteredChanges.UpdateOld, current)
+			filteredChanges.UpdateNew = append(filteredChanges.UpdateNew, desired)
+		}
+		ownership[zone] = desired
+	}
+
+	if err := im.provider.ApplyChanges(ctx, filteredChanges); err != nil {
+		return err
+	}
+	for zone, record := range ownership {
+		im.owned[zone] = owned[zone]
+		if record != nil {
+			im.ownership[zone] = record
+		} else {
+			delete(im.ownership, zone)
+		}
+	}
+	return nil
+}
+
+// AdjustEndpoints modifies the endpoints as needed by the specific provider
+func (im *ApexTXTRegistry) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	return im.provider.AdjustEndpoints(endpoints)
+}
+
+// ownershipRecordOf returns the owner and the zone of an ownership record.
+func (im *ApexTXTRegistry) ownershipRecordOf(name string, zoneNames []string) (string, string, bool) {
+	for _, zone := range zoneNames {
+		owner, ok := strings.CutSuffix(name, "."+im.name+"."+zone)
+		if ok && owner != "" && !strings.Contains(owner, ".") {
+			return owner, zone, true
+		}
+	}
+	return "", "", false
+}
+
+// encodeOwnership returns the values of the ownership record of the owned records.
+func (im *ApexTXTRegistry) encodeOwnership(keys sets.Set[string]) ([]string, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(strings.Join(sets.List(keys), "\n"))); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	records := base64.RawURLEncoding.EncodeToString(buf.Bytes())
+
+	// the longest value without records leaves room for the part of the records of each value
+	size := apexMaxValueLength - len(endpoint.Labels{
+		endpoint.OwnerLabelKey: im.ownerID,
+		apexPartLabelKey:       "9999",
+		apexRecordsLabelKey:    "",
+	}.SerializePlain(true))
+	var values []string
+	for part := 0; len(records) > 0; part++ {
+		n := min(size, len(records))
+		values = append(values, endpoint.Labels{
+			endpoint.OwnerLabelKey: im.ownerID,
+			apexPartLabelKey:       strconv.Itoa(part),
+			apexRecordsLabelKey:    records[:n],
+		}.SerializePlain(true))
+		records = records[n:]
+	}
+	return values, nil
+}
+
+// decodeApexOwnership returns the owned records of the values of an ownership record.
+func decodeApexOwnership(values []string) (sets.Set[string], error) {
+	parts := make(map[int]string, len(values))
+	for _, value := range values {
+		labels, err := endpoint.NewLabelsFromStringPlain(value)
+		if err != nil {
+			return nil, err
+		}
+		part, err := strconv.Atoi(labels[apexPartLabelKey])
+		if err != nil {
+			return nil, fmt.Errorf("invalid part %q", labels[apexPartLabelKey])
+		}
+		parts[part] = labels[apexRecordsLabelKey]
+	}
+	indexes := make([]int, 0, len(parts))
+	for part := range parts {
+		indexes = append(indexes, part)
+	}
+	sort.Ints(indexes)
+	var records strings.Builder
+	for i, part := range indexes {
+		if i != part {
+			return nil, fmt.Errorf("missing part %d", i)
+		}
+		records.WriteString(parts[part])
+	}
+
+	compressed, err := base64.RawURLEncoding.DecodeString(records.String())
+	if err != nil {
+		return nil, err
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	keys := sets.New[string]()
+	for _, key := range strings.Split(string(content), "\n") {
+		if key != "" {
+			keys.Insert(key)
+		}
+	}
+	return keys, nil
+}
+
+// apexKey is the key of a record in the list of the owned records of an ownership record.
+func apexKey(record *endpoint.Endpoint) string {
+	return strings.TrimSpace(record.DNSName + " " + record.RecordType + " " + record.SetIdentifier)
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+)
+
+func newApexTestProvider(t *testing.T, zones ...string) *inmemory.InMemoryProvider {
+	p := inmemory.NewInMemoryProvider()
+	for _, zone := range zones {
+		require.NoError(t, p.CreateZone(zone))
+	}
+	return p
+}
+
+// apexOwnershipOf returns the owned records of the ownership record of the name, nil if there is none.
+func apexOwnershipOf(t *testing.T, p provider.Provider, name string) sets.Set[string] {
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	for _, record := range records {
+		if record.DNSName == name && record.RecordType == endpoint.RecordTypeTXT {
+			keys, err := decodeApexOwnership(record.Targets)
+			require.NoError(t, err)
+			return keys
+		}
+	}
+	return nil
+}
+
+func TestNewApexTXTRegistry(t *testing.T) {
+	p := newApexTestProvider(t)
+	for _, ownerID := range []string{"", "Owner", "owner.example", "owner_1"} {
+		_, err := NewApexTXTRegistry(p, p, ownerID, "_external-dns")
+		assert.Error(t, err, ownerID)
+	}
+	_, err := NewApexTXTRegistry(p, p, "owner", "")
+	assert.Error(t, err)
+
+	r, err := NewApexTXTRegistry(p, p, "owner", "_external-dns")
+	require.NoError(t, err)
+	assert.Equal(t, "owner", r.OwnerID())
+	assert.Equal(t, p.GetDomainFilter(), r.GetDomainFilter())
+}
+
+func TestApexTXTRegistryRecords(t *testing.T) {
+	p := newApexTestProvider(t, "example.org", "example.com")
+	ours, err := NewApexTXTRegistry(p, p, "owner", "_external-dns")
+	require.NoError(t, err)
+	theirs, err := NewApexTXTRegistry(p, p, "other", "_external-dns")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = ours.Records(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ours.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("weighted.example.org", endpoint.RecordTypeCNAME, "lb.example.net").WithSetIdentifier("blue"),
+	}}))
+	_, err = theirs.Records(ctx)
+	require.NoError(t, err)
+	require.NoError(t, theirs.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("bar.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+	}}))
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("unowned.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+	}}))
+
+	records, err := ours.Records(ctx)
+	require.NoError(t, err)
+	owners := map[string]string{}
+	for _, record := range records {
+		owners[apexKey(record)] = record.Labels[endpoint.OwnerLabelKey]
+	}
+	assert.Equal(t, map[string]string{
+		"foo.example.org A":               "owner",
+		"example.com A":                   "owner",
+		"weighted.example.org CNAME blue": "owner",
+		"bar.example.org A":               "other",
+		"unowned.example.org A":           "",
+	}, owners, "the ownership records must be hidden")
+
+	assert.Equal(t, sets.New("foo.example.org A", "weighted.example.org CNAME blue"), apexOwnershipOf(t, p, "owner._external-dns.example.org"))
+	assert.Equal(t, sets.New("example.com A"), apexOwnershipOf(t, p, "owner._external-dns.example.com"))
+	assert.Equal(t, sets.New("bar.example.org A"), apexOwnershipOf(t, p, "other._external-dns.example.org"))
+}
+
+func TestApexTXTRegistryApplyChanges(t *testing.T) {
+	p := newApexTestProvider(t, "example.org")
+	r, err := NewApexTXTRegistry(p, p, "owner", "_external-dns")
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, err = r.Records(ctx)
+	require.NoError(t, err)
+
+	owned := func(name, target string) *endpoint.Endpoint {
+		ep := endpoint.NewEndpoint(name, endpoint.RecordTypeA, target)
+		ep.Labels[endpoint.OwnerLabelKey] = "owner"
+		return ep
+	}
+	foo := owned("foo.example.org", "1.2.3.4")
+	bar := owned("bar.example.org", "1.2.3.4")
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{foo, bar}}))
+	assert.Equal(t, sets.New("foo.example.org A", "bar.example.org A"), apexOwnershipOf(t, p, "owner._external-dns.example.org"))
+
+	// an update keeps the ownership, without listing the records in between
+	newFoo := owned("foo.example.org", "5.6.7.8")
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{UpdateOld: []*endpoint.Endpoint{foo}, UpdateNew: []*endpoint.Endpoint{newFoo}}))
+	assert.Equal(t, sets.New("foo.example.org A", "bar.example.org A"), apexOwnershipOf(t, p, "owner._external-dns.example.org"))
+
+	_, err = r.Records(ctx)
+	require.NoError(t, err)
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{Delete: []*endpoint.Endpoint{bar}}))
+	assert.Equal(t, sets.New("foo.example.org A"), apexOwnershipOf(t, p, "owner._external-dns.example.org"))
+
+	// the ownership record is deleted with the last owned record
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{Delete: []*endpoint.Endpoint{newFoo}}))
+	assert.Nil(t, apexOwnershipOf(t, p, "owner._external-dns.example.org"))
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestApexTXTRegistryManyRecords(t *testing.T) {
+	p := newApexTestProvider(t, "example.org")
+	r, err := NewApexTXTRegistry(p, p, "owner", "_external-dns")
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, err = r.Records(ctx)
+	require.NoError(t, err)
+
+	var creates []*endpoint.Endpoint
+	for i := 0; i < 2000; i++ {
+		creates = append(creates, endpoint.NewEndpoint(fmt.Sprintf("service-%d.example.org", i), endpoint.RecordTypeA, "1.2.3.4"))
+	}
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{Create: creates}))
+
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, len(creates)+1, "a single ownership record must own all the records")
+	for _, record := range records {
+		if record.RecordType == endpoint.RecordTypeTXT {
+			for _, value := range record.Targets {
+				assert.LessOrEqual(t, len(value), apexMaxValueLength)
+			}
+		}
+	}
+	assert.Equal(t, len(creates), apexOwnershipOf(t, p, "owner._external-dns.example.org").Len())
+
+	owned, err := r.Records(ctx)
+	require.NoError(t, err)
+	require.Len(t, owned, len(creates))
+	for _, record := range owned {
+		assert.True(t, record.IsOwnedBy("owner"), record.DNSName)
+	}
+}
+
+func TestApexTXTRegistryZoneScope(t *testing.T) {
+	p := newApexTestProvider(t, "example.org", "example.com")
+	r, err := NewApexTXTRegistry(p, p, "owner", "_external-dns")
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, err = r.Records(ctx)
+	require.NoError(t, err)
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+	}}))
+
+	// listing a single zone keeps the ownership of the other zones
+	scoped := context.WithValue(ctx, provider.ZoneScopeContextKey, "example.com")
+	_, err = r.Records(scoped)
+	require.NoError(t, err)
+	require.NoError(t, r.ApplyChanges(scoped, &plan.Changes{Create: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("bar.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+	}}))
+	assert.Equal(t, sets.New("foo.example.com A", "bar.example.com A"), apexOwnershipOf(t, p, "owner._external-dns.example.com"))
+	assert.Equal(t, sets.New("foo.example.org A"), apexOwnershipOf(t, p, "owner._external-dns.example.org"))
+}
+
+func TestDecodeApexOwnershipInvalid(t *testing.T) {
+	for _, values := range [][]string{
+		{`"heritage=external-dns,external-dns/part=x,external-dns/records=abc"`},
+		{`"heritage=external-dns,external-dns/part=1,external-dns/records=abc"`},
+		{`"heritage=external-dns,external-dns/part=0,external-dns/records=!!"`},
+		{`"heritage=other,external-dns/part=0"`},
+	} {
+		_, err := decodeApexOwnership(values)
+		assert.Error(t, err, values)
+	}
+}
@@ -1,3 +1,5 @@
+//go:build !select_providers || provider_aws || provider_awssd
+
 /*
 Copyright 2023 The Kubernetes Authors.
 
@@ -18,8 +20,12 @@ package registry
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -60,6 +66,10 @@ type DynamoDBRegistry struct {
 	// cache the dynamodb records owned by us.
 	labels         map[endpoint.EndpointKey]endpoint.Labels
 	orphanedLabels sets.Set[endpoint.EndpointKey]
+	// hashes are the provider-specific hashes of the dynamodb records in the current schema version
+	hashes map[endpoint.EndpointKey]string
+	// migrations are the records found by the last listing whose dynamodb records are in a previous schema version
+	migrations map[endpoint.EndpointKey]*endpoint.Endpoint
 
 	// cache the records in memory and update on an interval instead.
 	recordsCache            []*endpoint.Endpoint
@@ -69,6 +79,13 @@ type DynamoDBRegistry struct {
 
 const dynamodbAttributeMigrate = "dynamodb/needs-migration"
 
+// dynamodbSchemaVersion is the version of the attributes of the dynamodb records, in the "v" attribute.
+// Version 1 has the key "k", the owner "o" and the labels "l" only. Version 2 adds typed attributes
+// for the queries of the table: the DNS name "n", the record type "t", the set identifier "s",
+// the resource "r" and the hash "h" of the provider-specific properties of the record.
+// The labels are kept, so that the previous versions of ExternalDNS read the records of version 2.
+const dynamodbSchemaVersion = 2
+
 // DynamoDB allows a maximum batch size of 25 items.
 var dynamodbMaxBatchSize uint8 = 25
 
@@ -138,11 +155,15 @@ func (im *DynamoDBRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint,
 	endpoints := make([]*endpoint.Endpoint, 0, len(records))
 	labelMap := map[endpoint.EndpointKey]endpoint.Labels{}
 	txtRecordsMap := map[endpoint.EndpointKey]*endpoint.Endpoint{}
+	im.migrations = map[endpoint.EndpointKey]*endpoint.Endpoint{}
 	for _, record := range records {
 		key := record.Key()
 		if labels := im.labels[key]; labels != nil {
 			record.Labels = labels
 			orphanedLabels.Delete(key)
+			if _, ok := im.hashes[key]; !ok {
+				im.migrations[key] = record
+			}
 		} else {
 			record.Labels = endpoint.NewLabels()
 
@@ -222,7 +243,7 @@ func (im *DynamoDBRegistry) ApplyChanges(ctx context.Context, changes *plan.Chan
 	filteredChanges := &plan.Changes{
 		Create:    changes.Create,
 		UpdateNew: endpoint.FilterEndpointsByOwnerID(im.ownerID, changes.UpdateNew),
-		UpdateOld: endpoint.FilterEndpointsByOwnerID(im.ownerID, changes.UpdateOld),
+		UpdateOld: endpoint.FilterEndpointsClaimableBy(im.ownerID, changes.UpdateOld),
 		Delete:    endpoint.FilterEndpointsByOwnerID(im.ownerID, changes.Delete),
 	}
 
@@ -236,10 +257,10 @@ func (im *DynamoDBRegistry) ApplyChanges(ctx context.Context, changes *plan.Chan
 		key := r.Key()
 		oldLabels := im.labels[key]
 		if oldLabels == nil {
-			statements = im.appendInsert(statements, key, r.Labels)
+			statements = im.appendInsert(statements, key, r.Labels, r.ProviderSpecific)
 		} else {
 			im.orphanedLabels.Delete(key)
-			statements = im.appendUpdate(statements, key, oldLabels, r.Labels)
+			statements = im.appendUpdate(statements, key, oldLabels, r.Labels, r.ProviderSpecific)
 		}
 
 		im.labels[key] = r.Labels
@@ -250,6 +271,7 @@ func (im *DynamoDBRegistry) ApplyChanges(ctx context.Context, changes *plan.Chan
 
 	for _, r := range filteredChanges.Delete {
 		delete(im.labels, r.Key())
+		delete(im.hashes, r.Key())
 		if im.cacheInterval > 0 {
 			im.removeFromCache(r)
 		}
@@ -257,7 +279,12 @@ func (im *DynamoDBRegistry) ApplyChanges(ctx context.Context, changes *plan.Chan
 
 	oldLabels := make(map[endpoint.EndpointKey]endpoint.Labels, len(filteredChanges.UpdateOld))
 	needMigration := map[endpoint.EndpointKey]bool{}
+	adopted := map[endpoint.EndpointKey]bool{}
 	for _, r := range filteredChanges.UpdateOld {
+		if r.IsAdoptedBy(im.ownerID) {
+			// an adopted record has no ownership record yet
+			adopted[r.Key()] = true
+		}
 		oldLabels[r.Key()] = r.Labels
 
 		if _, ok := r.GetProviderSpecificProperty(dynamodbAttributeMigrate); ok {
@@ -273,11 +300,13 @@ func (im *DynamoDBRegistry) ApplyChanges(ctx context.Context, changes *plan.Chan
 	for _, r := range filteredChanges.UpdateNew {
 		key := r.Key()
 		if needMigration[key] {
-			statements = im.appendInsert(statements, key, r.Labels)
+			statements = im.appendInsert(statements, key, r.Labels, r.ProviderSpecific)
 			// Invalidate the records cache so the next sync deletes the TXT ownership record
 			im.recordsCache = nil
+		} else if adopted[key] {
+			statements = im.appendInsert(statements, key, r.Labels, r.ProviderSpecific)
 		} else {
-			statements = im.appendUpdate(statements, key, oldLabels[key], r.Labels)
+			statements = im.appendUpdate(statements, key, oldLabels[key], r.Labels, r.ProviderSpecific)
 		}
 
 		// add new version of record to caches
@@ -314,7 +343,7 @@ func (im *DynamoDBRegistry) ApplyChanges(ctx context.Context, changes *plan.Chan
 			context = fmt.Sprintf("inserting dynamodb record %q", record)
 		} else {
 			var record string
-			if err := attributevalue.Unmarshal(request.Parameters[1], &record); err != nil {
+			if err := attributevalue.Unmarshal(request.Parameters[len(request.Parameters)-1], &record); err != nil {
 				return fmt.Errorf("inserting dynamodb record: %w", err)
 			}
 			context = fmt.Sprintf("updating dynamodb record %q", record)
@@ -345,6 +374,7 @@ func (im *DynamoDBRegistry) ApplyChanges(ctx context.Context, changes *plan.Chan
 	for r := range im.orphanedLabels {
 		statements = im.appendDelete(statements, r)
 		delete(im.labels, r)
+		delete(im.hashes, r)
 	}
 	im.orphanedLabels = nil
 	return im.executeStatements(ctx, statements, func(request dynamodbtypes.BatchStatementRequest, response dynamodbtypes.BatchStatementResponse) error {
@@ -357,6 +387,96 @@ func (im *DynamoDBRegistry) ApplyChanges(ctx context.Context, changes *plan.Chan
 	})
 }
 
+// CollectGarbage deletes the items of the owner ID whose records no longer exist. The table is read again,
+// so that the items left by a crash of another instance with the same owner ID are deleted as well.
+// The records have no ownership records without an item, as their items are inserted before they are created.
+func (im *DynamoDBRegistry) CollectGarbage(ctx context.Context) (*GarbageReport, error) {
+	if err := im.readLabels(ctx); err != nil {
+		return nil, err
+	}
+	records, err := im.provider.Records(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orphanedLabels := sets.KeySet(im.labels)
+	for _, record := range records {
+		orphanedLabels.Delete(record.Key())
+	}
+
+	report := &GarbageReport{}
+	statements := make([]dynamodbtypes.BatchStatementRequest, 0, len(orphanedLabels))
+	keys := orphanedLabels.UnsortedList()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].DNSName < keys[j].DNSName || keys[i].DNSName == keys[j].DNSName && keys[i].RecordType < keys[j].RecordType
+	})
+	for _, key := range keys {
+		log.Infof("Deleting orphaned dynamodb record of missing record %s %s", key.RecordType, key.DNSName)
+		ep := endpoint.NewEndpoint(key.DNSName, key.RecordType).WithSetIdentifier(key.SetIdentifier)
+		for k, v := range im.labels[key] {
+			ep.Labels[k] = v
+		}
+		report.Removed = append(report.Removed, ep)
+		statements = im.appendDelete(statements, key)
+		delete(im.labels, key)
+		delete(im.hashes, key)
+	}
+	im.orphanedLabels = nil
+	if len(statements) == 0 {
+		return report, nil
+	}
+	im.recordsCache = nil
+	err = im.executeStatements(ctx, statements, func(request dynamodbtypes.BatchStatementRequest, response dynamodbtypes.BatchStatementResponse) error {
+		im.labels = nil
+		record, err := fromDynamoKey(request.Parameters[0])
+		if err != nil {
+			return fmt.Errorf("deleting dynamodb record: %w", err)
+		}
+		return fmt.Errorf("deleting dynamodb record %q: %s: %s", record, response.Error.Code, *response.Error.Message)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Migrate updates the dynamodb records of the records found by the last listing that are in a previous schema
+// version to the current one, and returns the number of records migrated and left to migrate.
+func (im *DynamoDBRegistry) Migrate(ctx context.Context) (int, int, error) {
+	if len(im.migrations) == 0 {
+		return 0, 0, nil
+	}
+	keys := make([]endpoint.EndpointKey, 0, len(im.migrations))
+	for key := range im.migrations {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].DNSName < keys[j].DNSName || keys[i].DNSName == keys[j].DNSName && keys[i].RecordType < keys[j].RecordType
+	})
+	statements := make([]dynamodbtypes.BatchStatementRequest, 0, len(keys))
+	for _, key := range keys {
+		labels := im.labels[key]
+		if labels == nil {
+			// deleted since the listing
+			continue
+		}
+		statements = im.appendUpdate(statements, key, labels, labels, im.migrations[key].ProviderSpecific)
+	}
+	err := im.executeStatements(ctx, statements, func(request dynamodbtypes.BatchStatementRequest, response dynamodbtypes.BatchStatementResponse) error {
+		im.labels = nil
+		var record string
+		if err := attributevalue.Unmarshal(request.Parameters[len(request.Parameters)-1], &record); err != nil {
+			return fmt.Errorf("migrating dynamodb record: %w", err)
+		}
+		return fmt.Errorf("migrating dynamodb record %q: %s: %s", record, response.Error.Code, *response.Error.Message)
+	})
+	if err != nil {
+		return 0, len(keys), err
+	}
+	im.migrations = nil
+	return len(statements), 0, nil
+}
+
 // AdjustEndpoints modifies the endpoints as needed by the specific provider.
 func (im *DynamoDBRegistry) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
 	return im.provider.AdjustEndpoints(endpoints)
@@ -391,13 +511,14 @@ func (im *DynamoDBRegistry) readLabels(ctx context.Context) error {
 	}
 
 	labels := map[endpoint.EndpointKey]endpoint.Labels{}
+	hashes := map[endpoint.EndpointKey]string{}
 	scanPaginator := dynamodb.NewScanPaginator(im.dynamodbAPI, &dynamodb.ScanInput{
 		TableName:        aws.String(im.table),
 		FilterExpression: aws.String("o = :ownerval"),
 		ExpressionAttributeValues: map[string]dynamodbtypes.AttributeValue{
 			":ownerval": &dynamodbtypes.AttributeValueMemberS{Value: im.ownerID},
 		},
-		ProjectionExpression: aws.String("k,l"),
+		ProjectionExpression: aws.String("k,l,v,r,h"),
 		ConsistentRead:       aws.Bool(true),
 	})
 	for scanPaginator.HasMorePages() {
@@ -416,13 +537,63 @@ func (im *DynamoDBRegistry) readLabels(ctx context.Context) error {
 			}
 
 			labels[k] = l
+			if hash, ok := fromDynamoSchema(item, l); ok {
+				hashes[k] = hash
+			}
 		}
 	}
 
 	im.labels = labels
+	im.hashes = hashes
 	return nil
 }
 
+// fromDynamoSchema returns the provider-specific hash of the item if it is in the current schema version.
+// An item updated by a previous version of ExternalDNS keeps the version but not the resource of its labels,
+// and needs to be migrated again.
+func fromDynamoSchema(item map[string]dynamodbtypes.AttributeValue, labels endpoint.Labels) (string, bool) {
+	var version int
+	var resource, hash string
+	if v, ok := item["v"]; !ok || attributevalue.Unmarshal(v, &version) != nil || version < dynamodbSchemaVersion {
+		return "", false
+	}
+	if r, ok := item["r"]; !ok || attributevalue.Unmarshal(r, &resource) != nil || resource != labels[endpoint.ResourceLabelKey] {
+		return "", false
+	}
+	if h, ok := item["h"]; ok {
+		if attributevalue.Unmarshal(h, &hash) != nil {
+			return "", false
+		}
+	}
+	return hash, true
+}
+
+// providerSpecificHash returns the hash of the provider-specific properties, empty without properties.
+func providerSpecificHash(properties endpoint.ProviderSpecific) string {
+	if len(properties) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(properties))
+	for _, property := range properties {
+		pairs = append(pairs, property.Name+"="+property.Value)
+	}
+	sort.Strings(pairs)
+	sum := sha256.Sum256([]byte(strings.Join(pairs, "\n")))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// toDynamoSchema returns the typed attributes of the current schema version, in the order of the statements.
+func toDynamoSchema(key endpoint.EndpointKey, labels endpoint.Labels, hash string) []dynamodbtypes.AttributeValue {
+	return []dynamodbtypes.AttributeValue{
+		&dynamodbtypes.AttributeValueMemberN{Value: strconv.Itoa(dynamodbSchemaVersion)},
+		&dynamodbtypes.AttributeValueMemberS{Value: key.DNSName},
+		&dynamodbtypes.AttributeValueMemberS{Value: key.RecordType},
+		&dynamodbtypes.AttributeValueMemberS{Value: key.SetIdentifier},
+		&dynamodbtypes.AttributeValueMemberS{Value: labels[endpoint.ResourceLabelKey]},
+		&dynamodbtypes.AttributeValueMemberS{Value: hash},
+	}
+}
+
 func fromDynamoKey(key dynamodbtypes.AttributeValue) (endpoint.EndpointKey, error) {
 	var ep string
 	if err := attributevalue.Unmarshal(key, &ep); err != nil {
@@ -462,22 +633,27 @@ func toDynamoLabels(labels endpoint.Labels) dynamodbtypes.AttributeValue {
 	return &dynamodbtypes.AttributeValueMemberM{Value: labelMap}
 }
 
-func (im *DynamoDBRegistry) appendInsert(statements []dynamodbtypes.BatchStatementRequest, key endpoint.EndpointKey, new endpoint.Labels) []dynamodbtypes.BatchStatementRequest {
+func (im *DynamoDBRegistry) appendInsert(statements []dynamodbtypes.BatchStatementRequest, key endpoint.EndpointKey, new endpoint.Labels, properties endpoint.ProviderSpecific) []dynamodbtypes.BatchStatementRequest {
+	hash := providerSpecificHash(properties)
+	if im.hashes != nil {
+		im.hashes[key] = hash
+	}
 	return append(statements, dynamodbtypes.BatchStatementRequest{
-		Statement:      aws.String(fmt.Sprintf("INSERT INTO %q VALUE {'k':?, 'o':?, 'l':?}", im.table)),
+		Statement:      aws.String(fmt.Sprintf("INSERT INTO %q VALUE {'k':?, 'o':?, 'l':?, 'v':?, 'n':?, 't':?, 's':?, 'r':?, 'h':?}", im.table)),
 		ConsistentRead: aws.Bool(true),
-		Parameters: []dynamodbtypes.AttributeValue{
+		Parameters: append([]dynamodbtypes.AttributeValue{
 			toDynamoKey(key),
 			&dynamodbtypes.AttributeValueMemberS{
 				Value: im.ownerID,
 			},
 			toDynamoLabels(new),
-		},
+		}, toDynamoSchema(key, new, hash)...),
 	})
 }
 
-func (im *DynamoDBRegistry) appendUpdate(statements []dynamodbtypes.BatchStatementRequest, key endpoint.EndpointKey, old endpoint.Labels, new endpoint.Labels) []dynamodbtypes.BatchStatementRequest {
-	if len(old) == len(new) {
+func (im *DynamoDBRegistry) appendUpdate(statements []dynamodbtypes.BatchStatementRequest, key endpoint.EndpointKey, old endpoint.Labels, new endpoint.Labels, properties endpoint.ProviderSpecific) []dynamodbtypes.BatchStatementRequest {
+	hash := providerSpecificHash(properties)
+	if current, ok := im.hashes[key]; ok && current == hash && len(old) == len(new) {
 		equal := true
 		for k, v := range old {
 			if newV, exists := new[k]; !exists || v != newV {
@@ -490,12 +666,13 @@ func (im *DynamoDBRegistry) appendUpdate(statements []dynamodbtypes.BatchStateme
 		}
 	}
 
+	if im.hashes != nil {
+		im.hashes[key] = hash
+	}
+	parameters := append([]dynamodbtypes.AttributeValue{toDynamoLabels(new)}, toDynamoSchema(key, new, hash)...)
 	return append(statements, dynamodbtypes.BatchStatementRequest{
-		Statement: aws.String(fmt.Sprintf("UPDATE %q SET \"l\"=? WHERE \"k\"=?", im.table)),
-		Parameters: []dynamodbtypes.AttributeValue{
-			toDynamoLabels(new),
-			toDynamoKey(key),
-		},
+		Statement:  aws.String(fmt.Sprintf("UPDATE %q SET \"l\"=? SET \"v\"=? SET \"n\"=? SET \"t\"=? SET \"s\"=? SET \"r\"=? SET \"h\"=? WHERE \"k\"=?", im.table)),
+		Parameters: append(parameters, toDynamoKey(key)),
 	})
 }
 
@@ -533,7 +710,7 @@ func (im *DynamoDBRegistry) executeStatements(ctx context.Context, statements []
 				op, _, _ := strings.Cut(*request.Statement, " ")
 				var key string
 				if op == "UPDATE" {
-					if err := attributevalue.Unmarshal(request.Parameters[1], &key); err != nil {
+					if err := attributevalue.Unmarshal(request.Parameters[len(request.Parameters)-1], &key); err != nil {
 						return err
 					}
 				} else {
@@ -1,3 +1,5 @@
+//go:build !select_providers || provider_aws || provider_awssd
+
 /*
 Copyright 2023 The Kubernetes Authors.
 
@@ -1071,6 +1073,27 @@ func TestDynamoDBRegistryApplyChanges(t *testing.T) {
 }
 
 // DynamoDBAPIStub is a minimal implementation of DynamoDBAPI, used primarily for unit testing.
+func TestDynamoDBRegistryCollectGarbage(t *testing.T) {
+	api, p := newDynamoDBAPIStub(t, &DynamoDBStubConfig{
+		ExpectDelete: sets.New("quux.test-zone.example.org#A#set-2"),
+	})
+	// the items are deleted without changing the records
+	api.changesApplied = true
+
+	r, err := NewDynamoDBRegistry(p, "test-owner", api, "test-table", "", "", "", []string{}, []string{}, nil, time.Hour)
+	require.NoError(t, err)
+
+	report, err := r.CollectGarbage(context.Background())
+	require.NoError(t, err)
+	require.Len(t, report.Removed, 1)
+	assert.Equal(t, "quux.test-zone.example.org", report.Removed[0].DNSName)
+	assert.Equal(t, endpoint.RecordTypeA, report.Removed[0].RecordType)
+	assert.Equal(t, "set-2", report.Removed[0].SetIdentifier)
+	assert.Equal(t, "ingress/default/quux-ingress", report.Removed[0].Labels[endpoint.ResourceLabelKey])
+	assert.Empty(t, report.Repaired)
+	assert.Empty(t, api.stubConfig.ExpectDelete)
+}
+
 type DynamoDBStub struct {
 	t                *testing.T
 	stubConfig       *DynamoDBStubConfig
@@ -1084,6 +1107,8 @@ type DynamoDBStubConfig struct {
 	ExpectUpdate      map[string]map[string]string
 	ExpectUpdateError map[string]dynamodbtypes.BatchStatementErrorCodeEnum
 	ExpectDelete      sets.Set[string]
+	// LegacyItems are the keys of the items scanned in the schema version 1
+	LegacyItems sets.Set[string]
 }
 
 type wrappedProvider struct {
@@ -1148,9 +1173,9 @@ func (r *DynamoDBStub) Scan(ctx context.Context, input *dynamodb.ScanInput, opts
 	var owner string
 	assert.Nil(r.t, attributevalue.Unmarshal(input.ExpressionAttributeValues[":ownerval"], &owner))
 	assert.Equal(r.t, "test-owner", owner)
-	assert.Equal(r.t, "k,l", *input.ProjectionExpression)
+	assert.Equal(r.t, "k,l,v,r,h", *input.ProjectionExpression)
 	assert.True(r.t, *input.ConsistentRead)
-	return &dynamodb.ScanOutput{
+	output := &dynamodb.ScanOutput{
 		Items: []map[string]dynamodbtypes.AttributeValue{
 			{
 				"k": &dynamodbtypes.AttributeValueMemberS{Value: "bar.test-zone.example.org#CNAME#"},
@@ -1177,7 +1202,34 @@ func (r *DynamoDBStub) Scan(ctx context.Context, input *dynamodb.ScanInput, opts
 				}},
 			},
 		},
-	}, nil
+	}
+	for _, item := range output.Items {
+		var key string
+		assert.Nil(r.t, attributevalue.Unmarshal(item["k"], &key))
+		if r.stubConfig != nil && r.stubConfig.LegacyItems.Has(key) {
+			continue
+		}
+		item["v"] = &dynamodbtypes.AttributeValueMemberN{Value: "2"}
+		item["r"] = item["l"].(*dynamodbtypes.AttributeValueMemberM).Value[endpoint.ResourceLabelKey]
+		item["h"] = &dynamodbtypes.AttributeValueMemberS{Value: ""}
+	}
+	return output, nil
+}
+
+func (r *DynamoDBStub) assertSchema(key string, labels map[string]string, parameters []dynamodbtypes.AttributeValue) {
+	var version int
+	var name, recordType, setIdentifier, resource, hash string
+	require.Len(r.t, parameters, 6)
+	assert.Nil(r.t, attributevalue.Unmarshal(parameters[0], &version))
+	assert.Nil(r.t, attributevalue.Unmarshal(parameters[1], &name))
+	assert.Nil(r.t, attributevalue.Unmarshal(parameters[2], &recordType))
+	assert.Nil(r.t, attributevalue.Unmarshal(parameters[3], &setIdentifier))
+	assert.Nil(r.t, attributevalue.Unmarshal(parameters[4], &resource))
+	assert.Nil(r.t, attributevalue.Unmarshal(parameters[5], &hash))
+	assert.Equal(r.t, 2, version, "schema version for key %q", key)
+	assert.Equal(r.t, key, name+"#"+recordType+"#"+setIdentifier, "typed key attributes for key %q", key)
+	assert.Equal(r.t, labels[endpoint.ResourceLabelKey], resource, "resource for key %q", key)
+	assert.Empty(r.t, hash, "provider-specific hash for key %q", key)
 }
 
 func (r *DynamoDBStub) BatchExecuteStatement(context context.Context, input *dynamodb.BatchExecuteStatementInput, option ...func(*dynamodb.Options)) (*dynamodb.BatchExecuteStatementOutput, error) {
@@ -1204,7 +1256,7 @@ func (r *DynamoDBStub) BatchExecuteStatement(context context.Context, input *dyn
 
 			responses = append(responses, dynamodbtypes.BatchStatementResponse{})
 
-		case "INSERT INTO \"test-table\" VALUE {'k':?, 'o':?, 'l':?}":
+		case "INSERT INTO \"test-table\" VALUE {'k':?, 'o':?, 'l':?, 'v':?, 'n':?, 't':?, 's':?, 'r':?, 'h':?}":
 			assert.False(r.t, r.changesApplied, "unexpected insert after provider changes")
 
 			var key string
@@ -1231,6 +1283,7 @@ func (r *DynamoDBStub) BatchExecuteStatement(context context.Context, input *dyn
 			var labels map[string]string
 			err := attributevalue.Unmarshal(statement.Parameters[2], &labels)
 			assert.Nil(r.t, err)
+			r.assertSchema(key, labels, statement.Parameters[3:])
 
 			for label, value := range labels {
 				expectedValue, found := expectedLabels[label]
@@ -1245,11 +1298,11 @@ func (r *DynamoDBStub) BatchExecuteStatement(context context.Context, input *dyn
 
 			responses = append(responses, dynamodbtypes.BatchStatementResponse{})
 
-		case "UPDATE \"test-table\" SET \"l\"=? WHERE \"k\"=?":
+		case "UPDATE \"test-table\" SET \"l\"=? SET \"v\"=? SET \"n\"=? SET \"t\"=? SET \"s\"=? SET \"r\"=? SET \"h\"=? WHERE \"k\"=?":
 			assert.False(r.t, r.changesApplied, "unexpected update after provider changes")
 
 			var key string
-			assert.Nil(r.t, attributevalue.Unmarshal(statement.Parameters[1], &key))
+			assert.Nil(r.t, attributevalue.Unmarshal(statement.Parameters[len(statement.Parameters)-1], &key))
 			if code, exists := r.stubConfig.ExpectUpdateError[key]; exists {
 				delete(r.stubConfig.ExpectInsertError, key)
 				responses = append(responses, dynamodbtypes.BatchStatementResponse{
@@ -1267,6 +1320,7 @@ func (r *DynamoDBStub) BatchExecuteStatement(context context.Context, input *dyn
 
 			var labels map[string]string
 			assert.Nil(r.t, attributevalue.Unmarshal(statement.Parameters[0], &labels))
+			r.assertSchema(key, labels, statement.Parameters[1:len(statement.Parameters)-1])
 
 			for label, value := range labels {
 				expectedValue, found := expectedLabels[label]
@@ -1290,3 +1344,63 @@ func (r *DynamoDBStub) BatchExecuteStatement(context context.Context, input *dyn
 		Responses: responses,
 	}, nil
 }
+
+func TestDynamoDBRegistryMigrate(t *testing.T) {
+	api, p := newDynamoDBAPIStub(t, &DynamoDBStubConfig{
+		LegacyItems: sets.New("bar.test-zone.example.org#CNAME#", "baz.test-zone.example.org#A#set-1", "quux.test-zone.example.org#A#set-2"),
+		ExpectUpdate: map[string]map[string]string{
+			"bar.test-zone.example.org#CNAME#": {
+				endpoint.ResourceLabelKey: "ingress/default/my-ingress",
+			},
+			"baz.test-zone.example.org#A#set-1": {
+				endpoint.ResourceLabelKey: "ingress/default/my-ingress",
+			},
+		},
+	})
+
+	r, err := NewDynamoDBRegistry(p, "test-owner", api, "test-table", "", "", "", []string{}, []string{}, nil, time.Hour)
+	require.NoError(t, err)
+
+	migrated, remaining, err := r.Migrate(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, migrated, "nothing to migrate before the records are listed")
+	assert.Zero(t, remaining)
+
+	_, err = r.Records(context.Background())
+	require.NoError(t, err)
+
+	// the orphaned legacy item of quux is left to the garbage collection
+	migrated, remaining, err = r.Migrate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, migrated)
+	assert.Zero(t, remaining)
+	assert.Empty(t, api.stubConfig.ExpectUpdate)
+
+	_, err = r.Records(context.Background())
+	require.NoError(t, err)
+
+	migrated, remaining, err = r.Migrate(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, migrated)
+	assert.Zero(t, remaining)
+}
+
+func TestDynamoDBRegistryMigrateError(t *testing.T) {
+	api, p := newDynamoDBAPIStub(t, &DynamoDBStubConfig{
+		LegacyItems: sets.New("bar.test-zone.example.org#CNAME#"),
+		ExpectUpdateError: map[string]dynamodbtypes.BatchStatementErrorCodeEnum{
+			"bar.test-zone.example.org#CNAME#": dynamodbtypes.BatchStatementErrorCodeEnumThrottlingError,
+		},
+	})
+
+	r, err := NewDynamoDBRegistry(p, "test-owner", api, "test-table", "", "", "", []string{}, []string{}, nil, time.Hour)
+	require.NoError(t, err)
+
+	_, err = r.Records(context.Background())
+	require.NoError(t, err)
+
+	migrated, remaining, err := r.Migrate(context.Background())
+	require.EqualError(t, err, `migrating dynamodb record "bar.test-zone.example.org#CNAME#": ThrottlingError: testing error`)
+	assert.Zero(t, migrated)
+	assert.Equal(t, 1, remaining)
+}
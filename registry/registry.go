@@ -34,3 +34,27 @@ type Registry interface {
 	GetDomainFilter() endpoint.DomainFilterInterface
 	OwnerID() string
 }
+
+// GarbageCollector is implemented by the registries whose ownership records can outlive the records they own,
+// e.g. after a crash between the changes of a record and of its ownership records.
+type GarbageCollector interface {
+	// CollectGarbage removes the ownership records of the owner ID whose records no longer exist,
+	// repairs the missing ownership records of the records of the owner ID, and reports what was cleaned.
+	CollectGarbage(ctx context.Context) (*GarbageReport, error)
+}
+
+// Migrator is implemented by the registries migrating their ownership records to another naming scheme
+// over successive synchronizations.
+type Migrator interface {
+	// Migrate migrates the next ownership records of the records found by the last listing, and returns
+	// the number of records whose ownership records were migrated and left to migrate.
+	Migrate(ctx context.Context) (migrated, remaining int, err error)
+}
+
+// GarbageReport lists the ownership records cleaned by a garbage collection.
+type GarbageReport struct {
+	// Removed are the ownership records whose records no longer exist
+	Removed []*endpoint.Endpoint
+	// Repaired are the ownership records created for the records missing them
+	Repaired []*endpoint.Endpoint
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// registrySnapshot is the content of the snapshot file.
+type registrySnapshot struct {
+	OwnerID string               `json:"ownerID"`
+	Time    time.Time            `json:"time"`
+	Records []*endpoint.Endpoint `json:"records"`
+}
+
+// SnapshotRegistry persists the records of its wrapped registry to a file, e.g. on a persistent volume,
+// after every listing. After a restart, the first listing returns the records of the snapshot
+// while the records are listed in the background, so that the first synchronization doesn't wait
+// for the listing of all records.
+type SnapshotRegistry struct {
+	Registry
+	path   string
+	maxAge time.Duration
+
+	mu sync.Mutex
+	// snapshot holds the records loaded from the file until they are returned
+	snapshot []*endpoint.Endpoint
+	// refresh is closed when the background listing is done
+	refresh        chan struct{}
+	refreshRecords []*endpoint.Endpoint
+	refreshErr     error
+}
+
+// NewSnapshotRegistry returns a SnapshotRegistry wrapping the registry. The snapshot of the file is used
+// if it was taken by the same owner less than maxAge ago, 0 meaning any age.
+func NewSnapshotRegistry(registry Registry, path string, maxAge time.Duration) *SnapshotRegistry {
+	r := &SnapshotRegistry{
+		Registry: registry,
+		path:     path,
+		maxAge:   maxAge,
+	}
+	r.snapshot = r.load()
+	return r
+}
+
+// load reads the snapshot file, returning nil if it is missing, invalid or outdated.
+func (r *SnapshotRegistry) load() []*endpoint.Endpoint {
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Infof("No records snapshot found at %s", r.path)
+		return nil
+	}
+	if err != nil {
+		log.Warnf("Failed to read the records snapshot: %v", err)
+		return nil
+	}
+	var snapshot registrySnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		log.Warnf("Ignoring the invalid records snapshot %s: %v", r.path, err)
+		return nil
+	}
+	if snapshot.OwnerID != r.OwnerID() {
+		log.Infof("Ignoring the records snapshot of owner %q", snapshot.OwnerID)
+		return nil
+	}
+	if r.maxAge > 0 && time.Since(snapshot.Time) > r.maxAge {
+		log.Infof("Ignoring the records snapshot taken at %s", snapshot.Time.Format(time.RFC3339))
+		return nil
+	}
+	for _, ep := range snapshot.Records {
+		if ep.Labels == nil {
+			ep.Labels = endpoint.NewLabels()
+		}
+	}
+	log.Infof("Loaded %d records from the snapshot taken at %s", len(snapshot.Records), snapshot.Time.Format(time.RFC3339))
+	return snapshot.Records
+}
+
+// save writes the records to the snapshot file, replacing it atomically.
+func (r *SnapshotRegistry) save(records []*endpoint.Endpoint) error {
+	data, err := json.Marshal(registrySnapshot{OwnerID: r.OwnerID(), Time: time.Now(), Records: records})
+	if err != nil {
+		return fmt.Errorf("encoding the records snapshot: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".tmp-")
+	if err != nil {
+		return fmt.Errorf("creating the records snapshot: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("writing the records snapshot: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing the records snapshot: %w", err)
+	}
+	if err := os.Rename(f.Name(), r.path); err != nil {
+		return fmt.Errorf("replacing the records snapshot: %w", err)
+	}
+	return nil
+}
+
+// Records returns the records of the snapshot the first time it is called, and starts listing the records
+// in the background. The next call returns the records listed in the background.
+// The records listed for a single zone are neither read from nor written to the snapshot.
+func (r *SnapshotRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	if _, ok := provider.ZoneScope(ctx); ok {
+		return r.Registry.Records(ctx)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.snapshot != nil {
+		records := r.snapshot
+		r.snapshot = nil
+		r.refresh = make(chan struct{})
+		go r.backgroundRecords(context.WithoutCancel(ctx), r.refresh)
+		log.Info("Using the records snapshot, listing the records in the background")
+		return records, nil
+	}
+	if r.refresh != nil {
+		if err := r.waitRefresh(ctx); err != nil {
+			return nil, err
+		}
+		records, err := r.refreshRecords, r.refreshErr
+		r.refreshRecords, r.refreshErr = nil, nil
+		return records, err
+	}
+	return r.records(ctx)
+}
+
+// ApplyChanges applies the changes once the background listing is done, whose records don't include the changes.
+func (r *SnapshotRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.refresh != nil {
+		if err := r.waitRefresh(ctx); err != nil {
+			return err
+		}
+		r.refreshRecords, r.refreshErr = nil, nil
+	}
+	return r.Registry.ApplyChanges(ctx, changes)
+}
+
+// waitRefresh waits for the background listing to be done. It must be called with the lock held.
+func (r *SnapshotRegistry) waitRefresh(ctx context.Context) error {
+	select {
+	case <-r.refresh:
+		r.refresh = nil
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *SnapshotRegistry) backgroundRecords(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	r.refreshRecords, r.refreshErr = r.records(ctx)
+	if r.refreshErr != nil {
+		log.Warnf("Failed to list the records in the background: %v", r.refreshErr)
+	}
+}
+
+// records lists the records of the wrapped registry and saves them to the snapshot.
+func (r *SnapshotRegistry) records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	records, err := r.Registry.Records(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.save(records); err != nil {
+		log.Warnf("Failed to save the records snapshot: %v", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+)
+
+var _ Registry = &SnapshotRegistry{}
+
+func newSnapshotTestRegistry(t *testing.T, p provider.Provider, path string, maxAge time.Duration) *SnapshotRegistry {
+	r, err := NewTXTRegistry(p, TXTConfig{
+		OwnerID:            "owner",
+		CacheInterval:      time.Hour,
+		ManagedRecordTypes: []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+	})
+	require.NoError(t, err)
+	return NewSnapshotRegistry(r, path, maxAge)
+}
+
+func TestSnapshotRegistry(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone("org"))
+	first := []*endpoint.Endpoint{endpoint.NewEndpoint("first.example.org", endpoint.RecordTypeA, "1.2.3.4")}
+	require.NoError(t, newSnapshotTestRegistry(t, p, path, 0).ApplyChanges(ctx, &plan.Changes{Create: first}))
+
+	// without a snapshot, the records are listed and saved
+	r := newSnapshotTestRegistry(t, p, path, 0)
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	assert.True(t, testutils.SameEndpoints(first, records))
+	assert.FileExists(t, path)
+
+	// after a restart, the records of the snapshot are returned first, and then the records listed in the background
+	second := []*endpoint.Endpoint{endpoint.NewEndpoint("second.example.org", endpoint.RecordTypeA, "2.3.4.5")}
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{Create: second}))
+	r = newSnapshotTestRegistry(t, p, path, 0)
+	records, err = r.Records(ctx)
+	require.NoError(t, err)
+	assert.True(t, testutils.SameEndpoints(first, records))
+	records, err = r.Records(ctx)
+	require.NoError(t, err)
+	assert.True(t, testutils.SameEndpoints(append(first, second...), records))
+
+	// the records of a zone are listed without the snapshot
+	r = newSnapshotTestRegistry(t, p, path, 0)
+	records, err = r.Records(context.WithValue(ctx, provider.ZoneScopeContextKey, "org"))
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+	records, err = r.Records(ctx)
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+	// wait for the background listing, which saves the snapshot
+	_, err = r.Records(ctx)
+	require.NoError(t, err)
+}
+
+func TestSnapshotRegistryApplyChangesDropsBackgroundRecords(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone("org"))
+	_, err := newSnapshotTestRegistry(t, p, path, 0).Records(ctx)
+	require.NoError(t, err)
+
+	r := newSnapshotTestRegistry(t, p, path, 0)
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+	created := []*endpoint.Endpoint{endpoint.NewEndpoint("created.example.org", endpoint.RecordTypeA, "1.2.3.4")}
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{Create: created}))
+	records, err = r.Records(ctx)
+	require.NoError(t, err)
+	assert.True(t, testutils.SameEndpoints(created, records))
+}
+
+func TestSnapshotRegistryIgnoresSnapshot(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		snapshot registrySnapshot
+		maxAge   time.Duration
+	}{
+		{
+			name:     "other owner",
+			snapshot: registrySnapshot{OwnerID: "other", Time: time.Now()},
+		},
+		{
+			name:     "outdated",
+			snapshot: registrySnapshot{OwnerID: "owner", Time: time.Now().Add(-2 * time.Hour)},
+			maxAge:   time.Hour,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "snapshot.json")
+			tc.snapshot.Records = []*endpoint.Endpoint{endpoint.NewEndpoint("stale.example.org", endpoint.RecordTypeA, "1.2.3.4")}
+			data, err := json.Marshal(tc.snapshot)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(path, data, 0o600))
+
+			p := inmemory.NewInMemoryProvider()
+			require.NoError(t, p.CreateZone("org"))
+			records, err := newSnapshotTestRegistry(t, p, path, tc.maxAge).Records(context.Background())
+			require.NoError(t, err)
+			assert.Empty(t, records)
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	assert.Nil(t, newSnapshotTestRegistry(t, inmemory.NewInMemoryProvider(), path, 0).snapshot)
+}
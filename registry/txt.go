@@ -18,7 +18,11 @@ package registry
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -31,7 +35,11 @@ import (
 
 const (
 	recordTemplate              = "%{record_type}"
+	hashTemplate                = "%{hash}"
 	providerSpecificForceUpdate = "txt/force-update"
+
+	// hashLength is the number of hexadecimal digits of the hash of the name rendered by hashTemplate
+	hashLength = 8
 )
 
 // TXTRegistry implements registry interface with ownership implemented via associated TXT records
@@ -56,39 +64,114 @@ type TXTRegistry struct {
 	// encrypt text records
 	txtEncryptEnabled bool
 	txtEncryptAESKey  []byte
+
+	// the TTL and comment of the TXT records, if set
+	recordTTL     endpoint.TTL
+	recordComment string
+
+	// maps the names of the TXT records of the scheme migrated from, nil if not migrating
+	migrateFrom nameMapper
+	// migrations are the ownership records to migrate, by key of the record they own, found by the last listing
+	migrations map[endpoint.EndpointKey]*txtMigration
+}
+
+// TXTMigration is the naming scheme of the TXT records a TXT registry migrates the ownership records from.
+type TXTMigration struct {
+	Prefix string
+	Suffix string
+}
+
+// TXTConfig contains configuration to create a new TXT registry.
+type TXTConfig struct {
+	// Prefix and Suffix are added to the names of the TXT records, they are mutually exclusive
+	Prefix string
+	Suffix string
+	// OwnerID refers to the owner id of the current instance
+	OwnerID       string
+	CacheInterval time.Duration
+	// WildcardReplacement replaces the asterisk in the names of the TXT records of wildcard records
+	WildcardReplacement string
+	ManagedRecordTypes  []string
+	ExcludeRecordTypes  []string
+	// EncryptEnabled encrypts the TXT records with EncryptAESKey, a key of 32 bytes
+	EncryptEnabled bool
+	EncryptAESKey  []byte
+	// MigrateFrom is the naming scheme the ownership records are migrated from, nil if not migrating
+	MigrateFrom *TXTMigration
+	// TTL is the TTL of the TXT records, the default TTL of the provider if not set
+	TTL endpoint.TTL
+	// Comment is the comment of the TXT records, on the providers supporting record comments
+	Comment string
+	// OwnerDomains are the subdomains the TXT records of the records of their parent domain are
+	// consolidated in, e.g. _owner.example.com
+	OwnerDomains []string
+}
+
+// txtMigration are the changes migrating the ownership records of a record to the naming scheme of the registry.
+type txtMigration struct {
+	// create are the TXT records of the scheme of the registry missing
+	create []*endpoint.Endpoint
+	// delete are the TXT records of the scheme migrated from
+	delete []*endpoint.Endpoint
 }
 
+// txtMigrationBatchSize is the maximum number of records whose ownership records are migrated per synchronization.
+const txtMigrationBatchSize = 100
+
 // NewTXTRegistry returns new TXTRegistry object
-func NewTXTRegistry(provider provider.Provider, txtPrefix, txtSuffix, ownerID string, cacheInterval time.Duration, txtWildcardReplacement string, managedRecordTypes, excludeRecordTypes []string, txtEncryptEnabled bool, txtEncryptAESKey []byte) (*TXTRegistry, error) {
-	if ownerID == "" {
+func NewTXTRegistry(provider provider.Provider, cfg TXTConfig) (*TXTRegistry, error) {
+	if cfg.OwnerID == "" {
 		return nil, errors.New("owner id cannot be empty")
 	}
+	txtEncryptAESKey := cfg.EncryptAESKey
 	if len(txtEncryptAESKey) == 0 {
 		txtEncryptAESKey = nil
 	} else if len(txtEncryptAESKey) != 32 {
 		return nil, errors.New("the AES Encryption key must have a length of 32 bytes")
 	}
-	if txtEncryptEnabled && txtEncryptAESKey == nil {
+	if cfg.EncryptEnabled && txtEncryptAESKey == nil {
 		return nil, errors.New("the AES Encryption key must be set when TXT record encryption is enabled")
 	}
 
-	if len(txtPrefix) > 0 && len(txtSuffix) > 0 {
+	if len(cfg.Prefix) > 0 && len(cfg.Suffix) > 0 {
 		return nil, errors.New("txt-prefix and txt-suffix are mutual exclusive")
 	}
+	if strings.Count(strings.ToLower(cfg.Prefix+cfg.Suffix), hashTemplate) > 1 {
+		return nil, errors.New("the %{hash} template can be used once in txt-prefix or txt-suffix")
+	}
 
-	mapper := newaffixNameMapper(txtPrefix, txtSuffix, txtWildcardReplacement)
+	var mapper nameMapper = newaffixNameMapper(cfg.Prefix, cfg.Suffix, cfg.WildcardReplacement)
+	if len(cfg.OwnerDomains) > 0 {
+		ownerMapper, err := newOwnerDomainNameMapper(mapper, cfg.OwnerDomains)
+		if err != nil {
+			return nil, err
+		}
+		mapper = ownerMapper
+	}
 
-	return &TXTRegistry{
+	registry := &TXTRegistry{
 		provider:            provider,
-		ownerID:             ownerID,
+		ownerID:             cfg.OwnerID,
 		mapper:              mapper,
-		cacheInterval:       cacheInterval,
-		wildcardReplacement: txtWildcardReplacement,
-		managedRecordTypes:  managedRecordTypes,
-		excludeRecordTypes:  excludeRecordTypes,
-		txtEncryptEnabled:   txtEncryptEnabled,
+		cacheInterval:       cfg.CacheInterval,
+		wildcardReplacement: cfg.WildcardReplacement,
+		managedRecordTypes:  cfg.ManagedRecordTypes,
+		excludeRecordTypes:  cfg.ExcludeRecordTypes,
+		txtEncryptEnabled:   cfg.EncryptEnabled,
 		txtEncryptAESKey:    txtEncryptAESKey,
-	}, nil
+		recordTTL:           cfg.TTL,
+		recordComment:       cfg.Comment,
+	}
+	if migrateFrom := cfg.MigrateFrom; migrateFrom != nil {
+		if len(migrateFrom.Prefix) > 0 && len(migrateFrom.Suffix) > 0 {
+			return nil, errors.New("the prefix and suffix migrated from are mutual exclusive")
+		}
+		if strings.EqualFold(migrateFrom.Prefix, cfg.Prefix) && strings.EqualFold(migrateFrom.Suffix, cfg.Suffix) && len(cfg.OwnerDomains) == 0 {
+			return nil, errors.New("the prefix and suffix migrated from must differ from txt-prefix and txt-suffix")
+		}
+		registry.migrateFrom = newaffixNameMapper(migrateFrom.Prefix, migrateFrom.Suffix, cfg.WildcardReplacement)
+	}
+	return registry, nil
 }
 
 func getSupportedTypes() []string {
@@ -107,9 +190,12 @@ func (im *TXTRegistry) OwnerID() string {
 // If TXT records was created previously to indicate ownership its corresponding value
 // will be added to the endpoints Labels map
 func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	// The cache holds the records of all zones, it is bypassed when listing a single zone.
+	_, scoped := provider.ZoneScope(ctx)
+
 	// If we have the zones cached AND we have refreshed the cache since the
 	// last given interval, then just use the cached results.
-	if im.recordsCache != nil && time.Since(im.recordsCacheRefreshTime) < im.cacheInterval {
+	if !scoped && im.recordsCache != nil && time.Since(im.recordsCacheRefreshTime) < im.cacheInterval {
 		log.Debug("Using cached records.")
 		return im.recordsCache, nil
 	}
@@ -123,6 +209,9 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 
 	labelMap := map[endpoint.EndpointKey]endpoint.Labels{}
 	txtRecordsMap := map[string]struct{}{}
+	// the labels and the TXT records of this owner in the naming scheme migrated from
+	migratedLabelMap := map[endpoint.EndpointKey]endpoint.Labels{}
+	migratedTXTs := map[endpoint.EndpointKey][]*endpoint.Endpoint{}
 
 	for _, record := range records {
 		if record.RecordType != endpoint.RecordTypeTXT {
@@ -144,40 +233,41 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 
 		endpointName, recordType := im.mapper.toEndpointName(record.DNSName)
 		key := endpoint.EndpointKey{
-			DNSName:       endpointName,
+			DNSName:       endpoint.CanonicalDNSName(endpointName),
 			RecordType:    recordType,
 			SetIdentifier: record.SetIdentifier,
 		}
 		labelMap[key] = labels
-		txtRecordsMap[record.DNSName] = struct{}{}
+		txtRecordsMap[endpoint.CanonicalDNSName(record.DNSName)] = struct{}{}
+
+		if im.migrateFrom != nil {
+			if endpointName, recordType := im.migrateFrom.toEndpointName(record.DNSName); endpointName != "" {
+				key := endpoint.EndpointKey{
+					DNSName:       endpoint.CanonicalDNSName(endpointName),
+					RecordType:    recordType,
+					SetIdentifier: record.SetIdentifier,
+				}
+				migratedLabelMap[key] = labels
+				if labels[endpoint.OwnerLabelKey] == im.ownerID {
+					migratedTXTs[key] = append(migratedTXTs[key], record)
+				}
+			}
+		}
 	}
 
+	if !scoped || im.migrations == nil {
+		im.migrations = map[endpoint.EndpointKey]*txtMigration{}
+	}
 	for _, ep := range endpoints {
 		if ep.Labels == nil {
 			ep.Labels = endpoint.NewLabels()
 		}
-		dnsNameSplit := strings.Split(ep.DNSName, ".")
-		// If specified, replace a leading asterisk in the generated txt record name with some other string
-		if im.wildcardReplacement != "" && dnsNameSplit[0] == "*" {
-			dnsNameSplit[0] = im.wildcardReplacement
-		}
-		dnsName := strings.Join(dnsNameSplit, ".")
-		key := endpoint.EndpointKey{
-			DNSName:       dnsName,
-			RecordType:    ep.RecordType,
-			SetIdentifier: ep.SetIdentifier,
-		}
-
-		// AWS Alias records have "new" format encoded as type "cname"
-		if isAlias, found := ep.GetProviderSpecificProperty("alias"); found && isAlias == "true" && ep.RecordType == endpoint.RecordTypeA {
-			key.RecordType = endpoint.RecordTypeCNAME
-		}
 
-		// Handle both new and old registry format with the preference for the new one
-		labels, labelsExist := labelMap[key]
-		if !labelsExist && ep.RecordType != endpoint.RecordTypeAAAA {
-			key.RecordType = ""
-			labels, labelsExist = labelMap[key]
+		// Handle both new and old registry format with the preference for the new one,
+		// and the naming scheme migrated from if the record has no TXT record in the current one
+		labels, labelsExist := im.ownershipLabels(labelMap, ep)
+		if !labelsExist && im.migrateFrom != nil {
+			labels, labelsExist = im.ownershipLabels(migratedLabelMap, ep)
 		}
 		if labelsExist {
 			for k, v := range labels {
@@ -185,14 +275,24 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 			}
 		}
 
+		if im.migrateFrom != nil && ep.Labels[endpoint.OwnerLabelKey] == im.ownerID {
+			if migration := im.newMigration(ep, migratedTXTs, txtRecordsMap); migration != nil {
+				// the missing TXT records are created by the migration
+				im.migrations[ep.Key()] = migration
+				continue
+			}
+		}
+
 		// Handle the migration of TXT records created before the new format (introduced in v0.12.0).
 		// The migration is done for the TXT records owned by this instance only.
 		if len(txtRecordsMap) > 0 && ep.Labels[endpoint.OwnerLabelKey] == im.ownerID {
 			if plan.IsManagedRecord(ep.RecordType, im.managedRecordTypes, im.excludeRecordTypes) {
 				// Get desired TXT records and detect the missing ones
-				desiredTXTs := im.generateTXTRecord(ep)
+				canonical := *ep
+				canonical.DNSName = endpoint.CanonicalDNSName(ep.DNSName)
+				desiredTXTs := im.generateTXTRecord(&canonical)
 				for _, desiredTXT := range desiredTXTs {
-					if _, exists := txtRecordsMap[desiredTXT.DNSName]; !exists {
+					if _, exists := txtRecordsMap[endpoint.CanonicalDNSName(desiredTXT.DNSName)]; !exists {
 						ep.WithProviderSpecific(providerSpecificForceUpdate, "true")
 					}
 				}
@@ -201,7 +301,7 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 	}
 
 	// Update the cache.
-	if im.cacheInterval > 0 {
+	if !scoped && im.cacheInterval > 0 {
 		im.recordsCache = endpoints
 		im.recordsCacheRefreshTime = time.Now()
 	}
@@ -209,6 +309,146 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 	return endpoints, nil
 }
 
+// ownershipLabels returns the labels of the TXT records owning the record in the new format, or else in the old format.
+func (im *TXTRegistry) ownershipLabels(labelMap map[endpoint.EndpointKey]endpoint.Labels, ep *endpoint.Endpoint) (endpoint.Labels, bool) {
+	key := im.ownershipKey(ep)
+	labels, labelsExist := labelMap[key]
+	if !labelsExist && ep.RecordType != endpoint.RecordTypeAAAA {
+		key.RecordType = ""
+		labels, labelsExist = labelMap[key]
+	}
+	return labels, labelsExist
+}
+
+// newMigration returns the changes migrating the TXT records owning the record from the naming scheme migrated from,
+// nil if the record has no TXT record in that scheme. The TXT records with the name of a TXT record of the current
+// scheme are kept.
+func (im *TXTRegistry) newMigration(ep *endpoint.Endpoint, migratedTXTs map[endpoint.EndpointKey][]*endpoint.Endpoint, txtRecordsMap map[string]struct{}) *txtMigration {
+	key := im.ownershipKey(ep)
+	txts := migratedTXTs[key]
+	if ep.RecordType != endpoint.RecordTypeAAAA {
+		key.RecordType = ""
+		txts = append(txts, migratedTXTs[key]...)
+	}
+	if len(txts) == 0 {
+		return nil
+	}
+
+	canonical := *ep
+	canonical.DNSName = endpoint.CanonicalDNSName(ep.DNSName)
+	desired := map[string]struct{}{}
+	migration := &txtMigration{}
+	for _, txt := range im.generateTXTRecord(&canonical) {
+		desired[endpoint.CanonicalDNSName(txt.DNSName)] = struct{}{}
+		if _, exists := txtRecordsMap[endpoint.CanonicalDNSName(txt.DNSName)]; !exists {
+			migration.create = append(migration.create, txt)
+		}
+	}
+	for _, txt := range txts {
+		if _, ok := desired[endpoint.CanonicalDNSName(txt.DNSName)]; ok {
+			continue
+		}
+		migrated := *txt
+		migrated.Labels = endpoint.Labels{endpoint.OwnedRecordLabelKey: canonical.DNSName}
+		migration.delete = append(migration.delete, &migrated)
+	}
+	if len(migration.create) == 0 && len(migration.delete) == 0 {
+		return nil
+	}
+	return migration
+}
+
+// Migrate migrates the TXT records owning up to txtMigrationBatchSize records found by the last listing from
+// the naming scheme migrated from, and returns the number of records migrated and left to migrate.
+func (im *TXTRegistry) Migrate(ctx context.Context) (int, int, error) {
+	if len(im.migrations) == 0 {
+		return 0, 0, nil
+	}
+	keys := make([]endpoint.EndpointKey, 0, len(im.migrations))
+	for key := range im.migrations {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].DNSName != keys[j].DNSName {
+			return keys[i].DNSName < keys[j].DNSName
+		}
+		if keys[i].RecordType != keys[j].RecordType {
+			return keys[i].RecordType < keys[j].RecordType
+		}
+		return keys[i].SetIdentifier < keys[j].SetIdentifier
+	})
+	// the records of a name are migrated together, as they share the TXT records in the old format
+	n := min(len(keys), txtMigrationBatchSize)
+	for n < len(keys) && keys[n].DNSName == keys[n-1].DNSName {
+		n++
+	}
+	batch := keys[:n]
+
+	changes := &plan.Changes{}
+	deleted := map[endpoint.EndpointKey]bool{}
+	for _, key := range batch {
+		migration := im.migrations[key]
+		changes.Create = append(changes.Create, migration.create...)
+		for _, txt := range migration.delete {
+			// the TXT records in the old format are shared by the records of a name
+			if !deleted[txt.Key()] {
+				deleted[txt.Key()] = true
+				changes.Delete = append(changes.Delete, txt)
+			}
+		}
+	}
+
+	// when caching is enabled, disable the provider from using the cache
+	if im.cacheInterval > 0 {
+		ctx = context.WithValue(ctx, provider.RecordsContextKey, nil)
+	}
+	if err := im.provider.ApplyChanges(ctx, changes); err != nil {
+		return 0, len(keys), err
+	}
+	for _, key := range batch {
+		delete(im.migrations, key)
+	}
+	return len(batch), len(keys) - len(batch), nil
+}
+
+// partitionTXTs splits the TXT records into the ones with the name of one of the others and the rest.
+func partitionTXTs(txts, others []*endpoint.Endpoint) (in, out []*endpoint.Endpoint) {
+	names := map[string]struct{}{}
+	for _, other := range others {
+		names[endpoint.CanonicalDNSName(other.DNSName)] = struct{}{}
+	}
+	for _, txt := range txts {
+		if _, ok := names[endpoint.CanonicalDNSName(txt.DNSName)]; ok {
+			in = append(in, txt)
+		} else {
+			out = append(out, txt)
+		}
+	}
+	return in, out
+}
+
+// ownershipKey returns the key of the TXT records owning the record in the new format.
+func (im *TXTRegistry) ownershipKey(ep *endpoint.Endpoint) endpoint.EndpointKey {
+	// The names of the records and the TXT records are compared in canonical form,
+	// as providers may return them e.g. in upper case or with an escaped wildcard.
+	dnsNameSplit := strings.Split(endpoint.CanonicalDNSName(ep.DNSName), ".")
+	// If specified, replace a leading asterisk in the generated txt record name with some other string
+	if im.wildcardReplacement != "" && dnsNameSplit[0] == "*" {
+		dnsNameSplit[0] = im.wildcardReplacement
+	}
+	key := endpoint.EndpointKey{
+		DNSName:       strings.Join(dnsNameSplit, "."),
+		RecordType:    ep.RecordType,
+		SetIdentifier: ep.SetIdentifier,
+	}
+
+	// AWS Alias records have "new" format encoded as type "cname"
+	if isAlias, found := ep.GetProviderSpecificProperty("alias"); found && isAlias == "true" && ep.RecordType == endpoint.RecordTypeA {
+		key.RecordType = endpoint.RecordTypeCNAME
+	}
+	return key
+}
+
 // generateTXTRecord generates both "old" and "new" TXT records.
 // Once we decide to drop old format we need to drop toTXTName() and rename toNewTXTName
 func (im *TXTRegistry) generateTXTRecord(r *endpoint.Endpoint) []*endpoint.Endpoint {
@@ -220,7 +460,7 @@ func (im *TXTRegistry) generateTXTRecord(r *endpoint.Endpoint) []*endpoint.Endpo
 		if txt != nil {
 			txt.WithSetIdentifier(r.SetIdentifier)
 			txt.Labels[endpoint.OwnedRecordLabelKey] = r.DNSName
-			txt.ProviderSpecific = r.ProviderSpecific
+			im.setRecordOptions(txt, r)
 			endpoints = append(endpoints, txt)
 		}
 	}
@@ -234,40 +474,61 @@ func (im *TXTRegistry) generateTXTRecord(r *endpoint.Endpoint) []*endpoint.Endpo
 	if txtNew != nil {
 		txtNew.WithSetIdentifier(r.SetIdentifier)
 		txtNew.Labels[endpoint.OwnedRecordLabelKey] = r.DNSName
-		txtNew.ProviderSpecific = r.ProviderSpecific
+		im.setRecordOptions(txtNew, r)
 		endpoints = append(endpoints, txtNew)
 	}
 
 	return endpoints
 }
 
+// setRecordOptions sets the provider-specific properties of the record, the TTL and the comment of the TXT record.
+func (im *TXTRegistry) setRecordOptions(txt, r *endpoint.Endpoint) {
+	txt.ProviderSpecific = r.ProviderSpecific
+	txt.RecordTTL = im.recordTTL
+	if im.recordComment != "" {
+		// the properties of the record are copied, not to comment the record itself
+		txt.ProviderSpecific = append(endpoint.ProviderSpecific{}, r.ProviderSpecific...)
+		txt.SetProviderSpecificProperty(endpoint.ProviderSpecificRecordComment, im.recordComment)
+	}
+}
+
 // ApplyChanges updates dns provider with the changes
 // for each created/deleted record it will also take into account TXT records for creation/deletion
 func (im *TXTRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
 	filteredChanges := &plan.Changes{
-		Create:    changes.Create,
 		UpdateNew: endpoint.FilterEndpointsByOwnerID(im.ownerID, changes.UpdateNew),
-		UpdateOld: endpoint.FilterEndpointsByOwnerID(im.ownerID, changes.UpdateOld),
-		Delete:    endpoint.FilterEndpointsByOwnerID(im.ownerID, changes.Delete),
+		UpdateOld: endpoint.FilterEndpointsClaimableBy(im.ownerID, changes.UpdateOld),
 	}
-	for _, r := range filteredChanges.Create {
+	// The TXT records are next to the records they own, labeled with them, so that the providers batching the
+	// changes keep them in the same batch: the TXT records are created before and deleted after their records,
+	// so that a provider applying the changes one by one never leaves a record without its owner.
+	for _, r := range changes.Create {
 		if r.Labels == nil {
 			r.Labels = make(map[string]string)
 		}
 		r.Labels[endpoint.OwnerLabelKey] = im.ownerID
 
 		filteredChanges.Create = append(filteredChanges.Create, im.generateTXTRecord(r)...)
+		filteredChanges.Create = append(filteredChanges.Create, r)
 
 		if im.cacheInterval > 0 {
 			im.addToCache(r)
 		}
 	}
 
-	for _, r := range filteredChanges.Delete {
+	for _, r := range endpoint.FilterEndpointsByOwnerID(im.ownerID, changes.Delete) {
 		// when we delete TXT records for which value has changed (due to new label) this would still work because
 		// !!! TXT record value is uniquely generated from the Labels of the endpoint. Hence old TXT record can be uniquely reconstructed
 		// !!! After migration to the new TXT registry format we can drop records in old format here!!!
-		filteredChanges.Delete = append(filteredChanges.Delete, im.generateTXTRecord(r)...)
+		filteredChanges.Delete = append(filteredChanges.Delete, r)
+		txts := im.generateTXTRecord(r)
+		if migration := im.migrations[r.Key()]; migration != nil {
+			// the TXT records of a record not migrated yet are in the scheme migrated from
+			_, existing := partitionTXTs(txts, migration.create)
+			txts = append(existing, migration.delete...)
+			delete(im.migrations, r.Key())
+		}
+		filteredChanges.Delete = append(filteredChanges.Delete, txts...)
 
 		if im.cacheInterval > 0 {
 			im.removeFromCache(r)
@@ -275,10 +536,22 @@ func (im *TXTRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes)
 	}
 
 	// make sure TXT records are consistently updated as well
+	adopted := map[endpoint.EndpointKey]bool{}
 	for _, r := range filteredChanges.UpdateOld {
+		if r.IsAdoptedBy(im.ownerID) {
+			// an adopted record has no TXT record yet
+			adopted[r.Key()] = true
+			continue
+		}
 		// when we updateOld TXT records for which value has changed (due to new label) this would still work because
 		// !!! TXT record value is uniquely generated from the Labels of the endpoint. Hence old TXT record can be uniquely reconstructed
-		filteredChanges.UpdateOld = append(filteredChanges.UpdateOld, im.generateTXTRecord(r)...)
+		txts := im.generateTXTRecord(r)
+		if migration := im.migrations[r.Key()]; migration != nil {
+			// the missing TXT records are created, and the TXT records in the scheme migrated from deleted
+			_, txts = partitionTXTs(txts, migration.create)
+			filteredChanges.Delete = append(filteredChanges.Delete, migration.delete...)
+		}
+		filteredChanges.UpdateOld = append(filteredChanges.UpdateOld, txts...)
 		// remove old version of record from cache
 		if im.cacheInterval > 0 {
 			im.removeFromCache(r)
@@ -287,7 +560,16 @@ func (im *TXTRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes)
 
 	// make sure TXT records are consistently updated as well
 	for _, r := range filteredChanges.UpdateNew {
-		filteredChanges.UpdateNew = append(filteredChanges.UpdateNew, im.generateTXTRecord(r)...)
+		if adopted[r.Key()] {
+			filteredChanges.Create = append(filteredChanges.Create, im.generateTXTRecord(r)...)
+		} else if migration := im.migrations[r.Key()]; migration != nil {
+			missing, existing := partitionTXTs(im.generateTXTRecord(r), migration.create)
+			filteredChanges.Create = append(filteredChanges.Create, missing...)
+			filteredChanges.UpdateNew = append(filteredChanges.UpdateNew, existing...)
+			delete(im.migrations, r.Key())
+		} else {
+			filteredChanges.UpdateNew = append(filteredChanges.UpdateNew, im.generateTXTRecord(r)...)
+		}
 		// add new version of record to cache
 		if im.cacheInterval > 0 {
 			im.addToCache(r)
@@ -301,6 +583,111 @@ func (im *TXTRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes)
 	return im.provider.ApplyChanges(ctx, filteredChanges)
 }
 
+// CollectGarbage deletes the TXT records of the owner ID whose records no longer exist, and creates the
+// missing TXT records of the records of the owner ID, e.g. the TXT record in the new format of a record
+// whose TXT record in the old format survived a crash.
+func (im *TXTRegistry) CollectGarbage(ctx context.Context) (*GarbageReport, error) {
+	records, err := im.provider.Records(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints, owned []*endpoint.Endpoint
+	labelMap := map[endpoint.EndpointKey]endpoint.Labels{}
+	txtNames := map[string]struct{}{}
+	for _, record := range records {
+		if record.RecordType != endpoint.RecordTypeTXT {
+			endpoints = append(endpoints, record)
+			continue
+		}
+		labels, err := endpoint.NewLabelsFromString(record.Targets[0], im.txtEncryptAESKey)
+		if err == endpoint.ErrInvalidHeritage {
+			endpoints = append(endpoints, record)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		txtNames[endpoint.CanonicalDNSName(record.DNSName)] = struct{}{}
+		if labels[endpoint.OwnerLabelKey] != im.ownerID {
+			continue
+		}
+		endpointName, recordType := im.mapper.toEndpointName(record.DNSName)
+		key := endpoint.EndpointKey{
+			DNSName:       endpoint.CanonicalDNSName(endpointName),
+			RecordType:    recordType,
+			SetIdentifier: record.SetIdentifier,
+		}
+		owned = append(owned, record)
+		labelMap[key] = labels
+	}
+
+	report := &GarbageReport{}
+	// The TXT records are compared by name rather than by the record they own, as the type of the record
+	// can only be told from the name of the TXT records of some types: a TXT record is kept if it has the
+	// name of a TXT record in the old or the new format of an existing record.
+	expected := map[endpoint.EndpointKey]bool{}
+	for _, ep := range endpoints {
+		key := im.ownershipKey(ep)
+		names := []string{im.mapper.toTXTName(key.DNSName), im.mapper.toNewTXTName(key.DNSName, key.RecordType)}
+		if im.migrateFrom != nil {
+			// the TXT records not migrated yet are deleted by the migration
+			names = append(names, im.migrateFrom.toTXTName(key.DNSName), im.migrateFrom.toNewTXTName(key.DNSName, key.RecordType))
+		}
+		for _, name := range names {
+			expected[endpoint.EndpointKey{DNSName: endpoint.CanonicalDNSName(name), SetIdentifier: ep.SetIdentifier}] = true
+		}
+
+		labels, found := labelMap[key]
+		if !found && ep.RecordType != endpoint.RecordTypeAAAA {
+			key.RecordType = ""
+			labels, found = labelMap[key]
+		}
+		if !found || !plan.IsManagedRecord(ep.RecordType, im.managedRecordTypes, im.excludeRecordTypes) {
+			continue
+		}
+		if im.migrations[ep.Key()] != nil {
+			// the missing TXT records are created by the migration
+			continue
+		}
+		canonical := *ep
+		canonical.DNSName = endpoint.CanonicalDNSName(ep.DNSName)
+		canonical.Labels = labels
+		for _, txt := range im.generateTXTRecord(&canonical) {
+			if _, ok := txtNames[endpoint.CanonicalDNSName(txt.DNSName)]; !ok {
+				log.Infof("Creating missing ownership record %s of record %s %s", txt.DNSName, ep.RecordType, ep.DNSName)
+				txtNames[endpoint.CanonicalDNSName(txt.DNSName)] = struct{}{}
+				report.Repaired = append(report.Repaired, txt)
+			}
+		}
+	}
+
+	for _, txt := range owned {
+		if expected[endpoint.EndpointKey{DNSName: endpoint.CanonicalDNSName(txt.DNSName), SetIdentifier: txt.SetIdentifier}] {
+			continue
+		}
+		endpointName, _ := im.mapper.toEndpointName(txt.DNSName)
+		log.Infof("Deleting orphaned ownership record %s of missing record %s", txt.DNSName, endpointName)
+		removed := *txt
+		removed.Labels = endpoint.NewLabels()
+		for k, v := range txt.Labels {
+			removed.Labels[k] = v
+		}
+		removed.Labels[endpoint.OwnedRecordLabelKey] = endpointName
+		report.Removed = append(report.Removed, &removed)
+	}
+
+	if len(report.Removed) == 0 && len(report.Repaired) == 0 {
+		return report, nil
+	}
+	// the cached records are outdated
+	im.recordsCache = nil
+	if err := im.provider.ApplyChanges(ctx, &plan.Changes{Create: report.Repaired, Delete: report.Removed}); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
 // AdjustEndpoints modifies the endpoints as needed by the specific provider
 func (im *TXTRegistry) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
 	return im.provider.AdjustEndpoints(endpoints)
@@ -322,12 +709,132 @@ type affixNameMapper struct {
 	prefix              string
 	suffix              string
 	wildcardReplacement string
+	// upperRecordType and upperHash render the templates in upper case, when written in upper case in the affix
+	upperRecordType bool
+	upperHash       bool
 }
 
 var _ nameMapper = affixNameMapper{}
 
 func newaffixNameMapper(prefix, suffix, wildcardReplacement string) affixNameMapper {
-	return affixNameMapper{prefix: strings.ToLower(prefix), suffix: strings.ToLower(suffix), wildcardReplacement: strings.ToLower(wildcardReplacement)}
+	return affixNameMapper{
+		prefix:              strings.ToLower(prefix),
+		suffix:              strings.ToLower(suffix),
+		wildcardReplacement: strings.ToLower(wildcardReplacement),
+		upperRecordType:     strings.Contains(prefix+suffix, strings.ToUpper(recordTemplate)),
+		upperHash:           strings.Contains(prefix+suffix, strings.ToUpper(hashTemplate)),
+	}
+}
+
+// ownerDomainNameMapper consolidates the TXT records of the records of the parent domains of the owner domains
+// in the owner domains: the TXT record a-foo.bar.example.com of the affix mapper is a-foo.bar._owner.example.com
+// with the owner domain _owner.example.com.
+type ownerDomainNameMapper struct {
+	nameMapper
+	// ownerDomains are the owner domains, longest parent domain first
+	ownerDomains []ownerDomain
+}
+
+type ownerDomain struct {
+	// name is the name of the owner domain, e.g. _owner.example.com
+	name string
+	// parent is the parent domain of the owner domain, e.g. example.com
+	parent string
+}
+
+var _ nameMapper = ownerDomainNameMapper{}
+
+func newOwnerDomainNameMapper(mapper nameMapper, names []string) (ownerDomainNameMapper, error) {
+	m := ownerDomainNameMapper{nameMapper: mapper}
+	for _, name := range names {
+		name = strings.TrimSuffix(strings.ToLower(name), ".")
+		label, parent, ok := strings.Cut(name, ".")
+		if !ok || label == "" || !strings.Contains(parent, ".") {
+			return m, fmt.Errorf("invalid owner domain %q, expected a subdomain of a domain, e.g. _owner.example.com", name)
+		}
+		m.ownerDomains = append(m.ownerDomains, ownerDomain{name: name, parent: parent})
+	}
+	sort.SliceStable(m.ownerDomains, func(i, j int) bool {
+		return len(m.ownerDomains[i].parent) > len(m.ownerDomains[j].parent)
+	})
+	return m, nil
+}
+
+// consolidate moves the TXT record of the affix mapper into the owner domain of its longest parent domain, if any.
+func (m ownerDomainNameMapper) consolidate(txtName string) string {
+	for _, d := range m.ownerDomains {
+		if base, ok := strings.CutSuffix(txtName, "."+d.parent); ok {
+			return base + "." + d.name
+		}
+	}
+	return txtName
+}
+
+func (m ownerDomainNameMapper) toTXTName(endpointDNSName string) string {
+	return m.consolidate(m.nameMapper.toTXTName(endpointDNSName))
+}
+
+func (m ownerDomainNameMapper) toNewTXTName(endpointDNSName, recordType string) string {
+	return m.consolidate(m.nameMapper.toNewTXTName(endpointDNSName, recordType))
+}
+
+// toEndpointName maps the TXT records of the owner domains back to the TXT records of the affix mapper. The name
+// must be the name consolidated for the record, otherwise the TXT record doesn't belong to the registry.
+func (m ownerDomainNameMapper) toEndpointName(txtDNSName string) (string, string) {
+	name := strings.ToLower(txtDNSName)
+	for _, d := range m.ownerDomains {
+		if base, ok := strings.CutSuffix(name, "."+d.name); ok {
+			name = base + "." + d.parent
+			break
+		}
+	}
+	endpointName, recordType := m.nameMapper.toEndpointName(name)
+	if endpointName == "" {
+		return "", ""
+	}
+	expected := m.toTXTName(endpointName)
+	if recordType != "" {
+		expected = m.toNewTXTName(endpointName, recordType)
+	}
+	if !strings.EqualFold(expected, txtDNSName) {
+		return "", ""
+	}
+	return endpointName, recordType
+}
+
+// nameHash returns the hash of the name rendered by hashTemplate: the first digits of the SHA-256 of the
+// name in lower case, without trailing dot.
+func nameHash(name string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSuffix(strings.ToLower(name), ".")))
+	return hex.EncodeToString(sum[:])[:hashLength]
+}
+
+// trimAffix removes the affix from the start of the name if prefix is true, from its end otherwise.
+// The hash template of the affix matches any hash, which is checked by toEndpointName.
+func trimAffix(name, affix string, prefix bool) (string, bool) {
+	before, after, hashed := strings.Cut(affix, hashTemplate)
+	if !hashed {
+		if prefix {
+			return strings.CutPrefix(name, affix)
+		}
+		return strings.CutSuffix(name, affix)
+	}
+	isHash := func(s string) bool {
+		_, err := hex.DecodeString(s)
+		return err == nil
+	}
+	if prefix {
+		rest, ok := strings.CutPrefix(name, before)
+		if !ok || len(rest) < hashLength || !isHash(rest[:hashLength]) {
+			return "", false
+		}
+		return strings.CutPrefix(rest[hashLength:], after)
+	}
+	rest, ok := strings.CutSuffix(name, after)
+	if !ok || len(rest) < hashLength || !isHash(rest[len(rest)-hashLength:]) {
+		return "", false
+	}
+	return strings.CutSuffix(rest[:len(rest)-hashLength], before)
 }
 
 // extractRecordTypeDefaultPosition extracts record type from the default position
@@ -354,12 +861,16 @@ func (pr affixNameMapper) dropAffixExtractType(name string) (baseName, recordTyp
 			iPrefix := strings.ReplaceAll(prefix, recordTemplate, tLower)
 			iSuffix := strings.ReplaceAll(suffix, recordTemplate, tLower)
 
-			if pr.isPrefix() && strings.HasPrefix(name, iPrefix) {
-				return strings.TrimPrefix(name, iPrefix), t
+			if pr.isPrefix() {
+				if baseName, ok := trimAffix(name, iPrefix, true); ok {
+					return baseName, t
+				}
 			}
 
-			if pr.isSuffix() && strings.HasSuffix(name, iSuffix) {
-				return strings.TrimSuffix(name, iSuffix), t
+			if pr.isSuffix() {
+				if baseName, ok := trimAffix(name, iSuffix, false); ok {
+					return baseName, t
+				}
 			}
 		}
 
@@ -368,12 +879,16 @@ func (pr affixNameMapper) dropAffixExtractType(name string) (baseName, recordTyp
 		suffix = pr.dropAffixTemplate(suffix)
 	}
 
-	if pr.isPrefix() && strings.HasPrefix(name, prefix) {
-		return extractRecordTypeDefaultPosition(strings.TrimPrefix(name, prefix))
+	if pr.isPrefix() {
+		if baseName, ok := trimAffix(name, prefix, true); ok {
+			return extractRecordTypeDefaultPosition(baseName)
+		}
 	}
 
-	if pr.isSuffix() && strings.HasSuffix(name, suffix) {
-		return extractRecordTypeDefaultPosition(strings.TrimSuffix(name, suffix))
+	if pr.isSuffix() {
+		if baseName, ok := trimAffix(name, suffix, false); ok {
+			return extractRecordTypeDefaultPosition(baseName)
+		}
 	}
 
 	return "", ""
@@ -396,7 +911,7 @@ func (pr affixNameMapper) toEndpointName(txtDNSName string) (endpointName string
 
 	// drop prefix
 	if pr.isPrefix() {
-		return pr.dropAffixExtractType(lowerDNSName)
+		endpointName, recordType = pr.dropAffixExtractType(lowerDNSName)
 	}
 
 	// drop suffix
@@ -406,20 +921,31 @@ func (pr affixNameMapper) toEndpointName(txtDNSName string) (endpointName string
 		domainWithSuffix := strings.Join(DNSName[:1+dc], ".")
 
 		r, rType := pr.dropAffixExtractType(domainWithSuffix)
-		return r + "." + DNSName[1+dc], rType
+		endpointName, recordType = r+"."+DNSName[1+dc], rType
 	}
-	return "", ""
+
+	// the hash must be the hash of the name, otherwise the TXT record doesn't belong to the registry
+	if endpointName != "" && strings.Contains(pr.prefix+pr.suffix, hashTemplate) {
+		expected := pr.toTXTName(endpointName)
+		if recordType != "" {
+			expected = pr.toNewTXTName(endpointName, recordType)
+		}
+		if !strings.EqualFold(expected, lowerDNSName) {
+			return "", ""
+		}
+	}
+	return endpointName, recordType
 }
 
 func (pr affixNameMapper) toTXTName(endpointDNSName string) string {
 	DNSName := strings.SplitN(endpointDNSName, ".", 2)
 
-	prefix := pr.dropAffixTemplate(pr.prefix)
-	suffix := pr.dropAffixTemplate(pr.suffix)
 	// If specified, replace a leading asterisk in the generated txt record name with some other string
 	if pr.wildcardReplacement != "" && DNSName[0] == "*" {
 		DNSName[0] = pr.wildcardReplacement
 	}
+	prefix := pr.renderHash(pr.dropAffixTemplate(pr.prefix), strings.Join(DNSName, "."))
+	suffix := pr.renderHash(pr.dropAffixTemplate(pr.suffix), strings.Join(DNSName, "."))
 
 	if len(DNSName) < 2 {
 		return prefix + DNSName[0] + suffix
@@ -439,24 +965,39 @@ func (pr affixNameMapper) recordTypeInAffix() bool {
 
 func (pr affixNameMapper) normalizeAffixTemplate(afix, recordType string) string {
 	if strings.Contains(afix, recordTemplate) {
+		if pr.upperRecordType {
+			recordType = strings.ToUpper(recordType)
+		}
 		return strings.ReplaceAll(afix, recordTemplate, recordType)
 	}
 	return afix
 }
 
+// renderHash replaces the hash template of the affix with the hash of the name.
+func (pr affixNameMapper) renderHash(afix, name string) string {
+	if !strings.Contains(afix, hashTemplate) {
+		return afix
+	}
+	hash := nameHash(name)
+	if pr.upperHash {
+		hash = strings.ToUpper(hash)
+	}
+	return strings.ReplaceAll(afix, hashTemplate, hash)
+}
+
 func (pr affixNameMapper) toNewTXTName(endpointDNSName, recordType string) string {
 	DNSName := strings.SplitN(endpointDNSName, ".", 2)
 	recordType = strings.ToLower(recordType)
 	recordT := recordType + "-"
 
-	prefix := pr.normalizeAffixTemplate(pr.prefix, recordType)
-	suffix := pr.normalizeAffixTemplate(pr.suffix, recordType)
-
 	// If specified, replace a leading asterisk in the generated txt record name with some other string
 	if pr.wildcardReplacement != "" && DNSName[0] == "*" {
 		DNSName[0] = pr.wildcardReplacement
 	}
 
+	prefix := pr.renderHash(pr.normalizeAffixTemplate(pr.prefix, recordType), strings.Join(DNSName, "."))
+	suffix := pr.renderHash(pr.normalizeAffixTemplate(pr.suffix, recordType), strings.Join(DNSName, "."))
+
 	if !pr.recordTypeInAffix() {
 		DNSName[0] = recordT + DNSName[0]
 	}
@@ -46,20 +46,39 @@ func TestTXTRegistry(t *testing.T) {
 
 func testTXTRegistryNew(t *testing.T) {
 	p := inmemory.NewInMemoryProvider()
-	_, err := NewTXTRegistry(p, "txt", "", "", time.Hour, "", []string{}, []string{}, false, nil)
+	_, err := NewTXTRegistry(p, TXTConfig{
+		Prefix:        "txt",
+		CacheInterval: time.Hour,
+	})
 	require.Error(t, err)
 
-	_, err = NewTXTRegistry(p, "", "txt", "", time.Hour, "", []string{}, []string{}, false, nil)
+	_, err = NewTXTRegistry(p, TXTConfig{
+		Suffix:        "txt",
+		CacheInterval: time.Hour,
+	})
 	require.Error(t, err)
 
-	r, err := NewTXTRegistry(p, "txt", "", "owner", time.Hour, "", []string{}, []string{}, false, nil)
+	r, err := NewTXTRegistry(p, TXTConfig{
+		Prefix:        "txt",
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+	})
 	require.NoError(t, err)
 	assert.Equal(t, p, r.provider)
 
-	r, err = NewTXTRegistry(p, "", "txt", "owner", time.Hour, "", []string{}, []string{}, false, nil)
+	r, err = NewTXTRegistry(p, TXTConfig{
+		Suffix:        "txt",
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+	})
 	require.NoError(t, err)
 
-	_, err = NewTXTRegistry(p, "txt", "txt", "owner", time.Hour, "", []string{}, []string{}, false, nil)
+	_, err = NewTXTRegistry(p, TXTConfig{
+		Prefix:        "txt",
+		Suffix:        "txt",
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+	})
 	require.Error(t, err)
 
 	_, ok := r.mapper.(affixNameMapper)
@@ -68,16 +87,32 @@ func testTXTRegistryNew(t *testing.T) {
 	assert.Equal(t, p, r.provider)
 
 	aesKey := []byte(";k&l)nUC/33:{?d{3)54+,AD?]SX%yh^")
-	_, err = NewTXTRegistry(p, "", "", "owner", time.Hour, "", []string{}, []string{}, false, nil)
+	_, err = NewTXTRegistry(p, TXTConfig{
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+	})
 	require.NoError(t, err)
 
-	_, err = NewTXTRegistry(p, "", "", "owner", time.Hour, "", []string{}, []string{}, false, aesKey)
+	_, err = NewTXTRegistry(p, TXTConfig{
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+		EncryptAESKey: aesKey,
+	})
 	require.NoError(t, err)
 
-	_, err = NewTXTRegistry(p, "", "", "owner", time.Hour, "", []string{}, []string{}, true, nil)
+	_, err = NewTXTRegistry(p, TXTConfig{
+		OwnerID:        "owner",
+		CacheInterval:  time.Hour,
+		EncryptEnabled: true,
+	})
 	require.Error(t, err)
 
-	r, err = NewTXTRegistry(p, "", "", "owner", time.Hour, "", []string{}, []string{}, true, aesKey)
+	r, err = NewTXTRegistry(p, TXTConfig{
+		OwnerID:        "owner",
+		CacheInterval:  time.Hour,
+		EncryptEnabled: true,
+		EncryptAESKey:  aesKey,
+	})
 	require.NoError(t, err)
 
 	_, ok = r.mapper.(affixNameMapper)
@@ -215,13 +250,23 @@ func testTXTRegistryRecordsPrefixed(t *testing.T) {
 		},
 	}
 
-	r, _ := NewTXTRegistry(p, "txt.", "", "owner", time.Hour, "wc", []string{}, []string{}, false, nil)
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		Prefix:              "txt.",
+		OwnerID:             "owner",
+		CacheInterval:       time.Hour,
+		WildcardReplacement: "wc",
+	})
 	records, _ := r.Records(ctx)
 
 	assert.True(t, testutils.SameEndpoints(records, expectedRecords))
 
 	// Ensure prefix is case-insensitive
-	r, _ = NewTXTRegistry(p, "TxT.", "", "owner", time.Hour, "wc", []string{}, []string{}, false, nil)
+	r, _ = NewTXTRegistry(p, TXTConfig{
+		Prefix:              "TxT.",
+		OwnerID:             "owner",
+		CacheInterval:       time.Hour,
+		WildcardReplacement: "wc",
+	})
 	records, _ = r.Records(ctx)
 
 	assert.True(t, testutils.SameEndpoints(records, expectedRecords))
@@ -340,13 +385,21 @@ func testTXTRegistryRecordsSuffixed(t *testing.T) {
 		},
 	}
 
-	r, _ := NewTXTRegistry(p, "", "-txt", "owner", time.Hour, "", []string{}, []string{}, false, nil)
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		Suffix:        "-txt",
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+	})
 	records, _ := r.Records(ctx)
 
 	assert.True(t, testutils.SameEndpoints(records, expectedRecords))
 
 	// Ensure prefix is case-insensitive
-	r, _ = NewTXTRegistry(p, "", "-TxT", "owner", time.Hour, "", []string{}, []string{}, false, nil)
+	r, _ = NewTXTRegistry(p, TXTConfig{
+		Suffix:        "-TxT",
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+	})
 	records, _ = r.Records(ctx)
 
 	assert.True(t, testutils.SameEndpointLabels(records, expectedRecords))
@@ -457,7 +510,10 @@ func testTXTRegistryRecordsNoPrefix(t *testing.T) {
 		},
 	}
 
-	r, _ := NewTXTRegistry(p, "", "", "owner", time.Hour, "", []string{}, []string{}, false, nil)
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+	})
 	records, _ := r.Records(ctx)
 
 	assert.True(t, testutils.SameEndpoints(records, expectedRecords))
@@ -484,12 +540,22 @@ func testTXTRegistryRecordsPrefixedTemplated(t *testing.T) {
 		},
 	}
 
-	r, _ := NewTXTRegistry(p, "txt-%{record_type}.", "", "owner", time.Hour, "wc", []string{}, []string{}, false, nil)
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		Prefix:              "txt-%{record_type}.",
+		OwnerID:             "owner",
+		CacheInterval:       time.Hour,
+		WildcardReplacement: "wc",
+	})
 	records, _ := r.Records(ctx)
 
 	assert.True(t, testutils.SameEndpoints(records, expectedRecords))
 
-	r, _ = NewTXTRegistry(p, "TxT-%{record_type}.", "", "owner", time.Hour, "wc", []string{}, []string{}, false, nil)
+	r, _ = NewTXTRegistry(p, TXTConfig{
+		Prefix:              "TxT-%{record_type}.",
+		OwnerID:             "owner",
+		CacheInterval:       time.Hour,
+		WildcardReplacement: "wc",
+	})
 	records, _ = r.Records(ctx)
 
 	assert.True(t, testutils.SameEndpoints(records, expectedRecords))
@@ -516,12 +582,22 @@ func testTXTRegistryRecordsSuffixedTemplated(t *testing.T) {
 		},
 	}
 
-	r, _ := NewTXTRegistry(p, "", "txt%{record_type}", "owner", time.Hour, "wc", []string{}, []string{}, false, nil)
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		Suffix:              "txt%{record_type}",
+		OwnerID:             "owner",
+		CacheInterval:       time.Hour,
+		WildcardReplacement: "wc",
+	})
 	records, _ := r.Records(ctx)
 
 	assert.True(t, testutils.SameEndpoints(records, expectedRecords))
 
-	r, _ = NewTXTRegistry(p, "", "TxT%{record_type}", "owner", time.Hour, "wc", []string{}, []string{}, false, nil)
+	r, _ = NewTXTRegistry(p, TXTConfig{
+		Suffix:              "TxT%{record_type}",
+		OwnerID:             "owner",
+		CacheInterval:       time.Hour,
+		WildcardReplacement: "wc",
+	})
 	records, _ = r.Records(ctx)
 
 	assert.True(t, testutils.SameEndpoints(records, expectedRecords))
@@ -564,7 +640,11 @@ func testTXTRegistryApplyChangesWithPrefix(t *testing.T) {
 			newEndpointWithOwner("txt.cname-multiple.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, "").WithSetIdentifier("test-set-2"),
 		},
 	})
-	r, _ := NewTXTRegistry(p, "txt.", "", "owner", time.Hour, "", []string{}, []string{}, false, nil)
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		Prefix:        "txt.",
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+	})
 
 	changes := &plan.Changes{
 		Create: []*endpoint.Endpoint{
@@ -653,7 +733,11 @@ func testTXTRegistryApplyChangesWithTemplatedPrefix(t *testing.T) {
 	p.ApplyChanges(ctx, &plan.Changes{
 		Create: []*endpoint.Endpoint{},
 	})
-	r, _ := NewTXTRegistry(p, "prefix%{record_type}.", "", "owner", time.Hour, "", []string{}, []string{}, false, nil)
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		Prefix:        "prefix%{record_type}.",
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+	})
 	changes := &plan.Changes{
 		Create: []*endpoint.Endpoint{
 			newEndpointWithOwnerResource("new-record-1.test-zone.example.org", "new-loadbalancer-1.lb.com", endpoint.RecordTypeCNAME, "", "ingress/default/my-ingress"),
@@ -696,7 +780,11 @@ func testTXTRegistryApplyChangesWithTemplatedSuffix(t *testing.T) {
 	p.OnApplyChanges = func(ctx context.Context, got *plan.Changes) {
 		assert.Equal(t, ctxEndpoints, ctx.Value(provider.RecordsContextKey))
 	}
-	r, _ := NewTXTRegistry(p, "", "-%{record_type}suffix", "owner", time.Hour, "", []string{}, []string{}, false, nil)
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		Suffix:        "-%{record_type}suffix",
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+	})
 	changes := &plan.Changes{
 		Create: []*endpoint.Endpoint{
 			newEndpointWithOwnerResource("new-record-1.test-zone.example.org", "new-loadbalancer-1.lb.com", endpoint.RecordTypeCNAME, "", "ingress/default/my-ingress"),
@@ -761,7 +849,12 @@ func testTXTRegistryApplyChangesWithSuffix(t *testing.T) {
 			newEndpointWithOwner("cname-multiple-txt.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, "").WithSetIdentifier("test-set-2"),
 		},
 	})
-	r, _ := NewTXTRegistry(p, "", "-txt", "owner", time.Hour, "wildcard", []string{}, []string{}, false, nil)
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		Suffix:              "-txt",
+		OwnerID:             "owner",
+		CacheInterval:       time.Hour,
+		WildcardReplacement: "wildcard",
+	})
 
 	changes := &plan.Changes{
 		Create: []*endpoint.Endpoint{
@@ -865,7 +958,10 @@ func testTXTRegistryApplyChangesNoPrefix(t *testing.T) {
 			newEndpointWithOwner("cname-foobar.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
 		},
 	})
-	r, _ := NewTXTRegistry(p, "", "", "owner", time.Hour, "", []string{}, []string{}, false, nil)
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+	})
 
 	changes := &plan.Changes{
 		Create: []*endpoint.Endpoint{
@@ -1028,7 +1124,12 @@ func testTXTRegistryMissingRecordsNoPrefix(t *testing.T) {
 		},
 	}
 
-	r, _ := NewTXTRegistry(p, "", "", "owner", time.Hour, "wc", []string{endpoint.RecordTypeCNAME, endpoint.RecordTypeA, endpoint.RecordTypeNS}, []string{}, false, nil)
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		OwnerID:             "owner",
+		CacheInterval:       time.Hour,
+		WildcardReplacement: "wc",
+		ManagedRecordTypes:  []string{endpoint.RecordTypeCNAME, endpoint.RecordTypeA, endpoint.RecordTypeNS},
+	})
 	records, _ := r.Records(ctx)
 
 	assert.True(t, testutils.SameEndpoints(records, expectedRecords))
@@ -1138,12 +1239,179 @@ func testTXTRegistryMissingRecordsWithPrefix(t *testing.T) {
 		},
 	}
 
-	r, _ := NewTXTRegistry(p, "txt.", "", "owner", time.Hour, "wc", []string{endpoint.RecordTypeCNAME, endpoint.RecordTypeA, endpoint.RecordTypeNS, endpoint.RecordTypeTXT}, []string{}, false, nil)
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		Prefix:              "txt.",
+		OwnerID:             "owner",
+		CacheInterval:       time.Hour,
+		WildcardReplacement: "wc",
+		ManagedRecordTypes:  []string{endpoint.RecordTypeCNAME, endpoint.RecordTypeA, endpoint.RecordTypeNS, endpoint.RecordTypeTXT},
+	})
 	records, _ := r.Records(ctx)
 
 	assert.True(t, testutils.SameEndpoints(records, expectedRecords))
 }
 
+func TestTXTRegistryCollectGarbage(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	owner := "\"heritage=external-dns,external-dns/owner=owner\""
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("kept.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("txt.kept.test-zone.example.org", owner, endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("txt.a-kept.test-zone.example.org", owner, endpoint.RecordTypeTXT, ""),
+			// the type of the records owned by TXT records in the new format can't be told from their name for some types
+			newEndpointWithOwner("mail.test-zone.example.org", "10 mx.example.org", endpoint.RecordTypeMX, ""),
+			newEndpointWithOwner("txt.mail.test-zone.example.org", owner, endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("txt.mx-mail.test-zone.example.org", owner, endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("txt.gone.test-zone.example.org", owner, endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("txt.a-gone.test-zone.example.org", owner, endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("txt.othergone.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=otherowner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("repaired.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("txt.repaired.test-zone.example.org", owner, endpoint.RecordTypeTXT, ""),
+		},
+	}))
+	r, err := NewTXTRegistry(p, TXTConfig{
+		Prefix:             "txt.",
+		OwnerID:            "owner",
+		CacheInterval:      time.Hour,
+		ManagedRecordTypes: []string{endpoint.RecordTypeA, endpoint.RecordTypeMX},
+	})
+	require.NoError(t, err)
+
+	names := func(endpoints []*endpoint.Endpoint) []string {
+		names := []string{}
+		for _, ep := range endpoints {
+			names = append(names, ep.DNSName)
+		}
+		return names
+	}
+	report, err := r.CollectGarbage(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"txt.gone.test-zone.example.org", "txt.a-gone.test-zone.example.org"}, names(report.Removed))
+	for _, removed := range report.Removed {
+		assert.Equal(t, "gone.test-zone.example.org", removed.Labels[endpoint.OwnedRecordLabelKey])
+	}
+	assert.Equal(t, []string{"txt.a-repaired.test-zone.example.org"}, names(report.Repaired))
+
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"kept.test-zone.example.org",
+		"txt.kept.test-zone.example.org",
+		"txt.a-kept.test-zone.example.org",
+		"mail.test-zone.example.org",
+		"txt.mail.test-zone.example.org",
+		"txt.mx-mail.test-zone.example.org",
+		"txt.othergone.test-zone.example.org",
+		"repaired.test-zone.example.org",
+		"txt.repaired.test-zone.example.org",
+		"txt.a-repaired.test-zone.example.org",
+	}, names(records))
+
+	report, err = r.CollectGarbage(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, report.Removed)
+	assert.Empty(t, report.Repaired)
+}
+
+func TestTXTRegistryMigrate(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	owner := "\"heritage=external-dns,external-dns/owner=owner\""
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("migrated.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("migrated.test-zone.example.org", owner, endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("a-migrated.test-zone.example.org", owner, endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("deleted.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("deleted.test-zone.example.org", owner, endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("a-deleted.test-zone.example.org", owner, endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("other.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("a-other.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=otherowner\"", endpoint.RecordTypeTXT, ""),
+		},
+	}))
+
+	_, err := NewTXTRegistry(p, TXTConfig{
+		Prefix:             "txt.",
+		OwnerID:            "owner",
+		CacheInterval:      time.Hour,
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		MigrateFrom:        &TXTMigration{Prefix: "txt."},
+	})
+	require.Error(t, err)
+	_, err = NewTXTRegistry(p, TXTConfig{
+		Prefix:             "txt.",
+		OwnerID:            "owner",
+		CacheInterval:      time.Hour,
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		MigrateFrom:        &TXTMigration{Prefix: "old.", Suffix: "-old"},
+	})
+	require.Error(t, err)
+	r, err := NewTXTRegistry(p, TXTConfig{
+		Prefix:             "txt.",
+		OwnerID:            "owner",
+		CacheInterval:      time.Hour,
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		MigrateFrom:        &TXTMigration{},
+	})
+	require.NoError(t, err)
+
+	owners := func(endpoints []*endpoint.Endpoint) map[string]string {
+		owners := map[string]string{}
+		for _, ep := range endpoints {
+			owners[ep.DNSName+"/"+ep.RecordType] = ep.Labels[endpoint.OwnerLabelKey]
+		}
+		return owners
+	}
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	// the records are owned under the naming scheme migrated from
+	assert.Equal(t, map[string]string{
+		"migrated.test-zone.example.org/A": "owner",
+		"deleted.test-zone.example.org/A":  "owner",
+		"other.test-zone.example.org/A":    "otherowner",
+	}, owners(records))
+
+	// the ownership records of a deleted record are deleted in the naming scheme migrated from
+	for _, record := range records {
+		if record.DNSName == "deleted.test-zone.example.org" {
+			require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{Delete: []*endpoint.Endpoint{record}}))
+		}
+	}
+	migrated, remaining, err := r.Migrate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, migrated)
+	assert.Equal(t, 0, remaining)
+
+	names := []string{}
+	providerRecords, err := p.Records(ctx)
+	require.NoError(t, err)
+	for _, ep := range providerRecords {
+		names = append(names, ep.DNSName+"/"+ep.RecordType)
+	}
+	assert.ElementsMatch(t, []string{
+		"migrated.test-zone.example.org/A",
+		"txt.migrated.test-zone.example.org/TXT",
+		"txt.a-migrated.test-zone.example.org/TXT",
+		"other.test-zone.example.org/A",
+		"a-other.test-zone.example.org/TXT",
+	}, names)
+
+	records, err = r.Records(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"migrated.test-zone.example.org/A": "owner",
+		"other.test-zone.example.org/A":    "otherowner",
+	}, owners(records))
+	migrated, remaining, err = r.Migrate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, migrated)
+	assert.Equal(t, 0, remaining)
+}
+
 func TestCacheMethods(t *testing.T) {
 	cache := []*endpoint.Endpoint{
 		newEndpointWithOwner("thing.com", "1.2.3.4", "A", "owner"),
@@ -1391,6 +1659,27 @@ func TestToEndpointNameNewTXT(t *testing.T) {
 			recordType: "A",
 			txtDomain:  "example.fooa.bar.com",
 		},
+		{
+			name:       "hashed prefix",
+			mapper:     newaffixNameMapper("%{record_type}-%{hash}.", "", ""),
+			domain:     "foo.example.com",
+			recordType: "CNAME",
+			txtDomain:  "cname-45a335de.foo.example.com",
+		},
+		{
+			name:       "hashed suffix",
+			mapper:     newaffixNameMapper("", "-%{hash}", ""),
+			domain:     "foo.example.com",
+			recordType: "A",
+			txtDomain:  "a-foo-45a335de.example.com",
+		},
+		{
+			name:       "upper case templates",
+			mapper:     newaffixNameMapper("_%{RECORD_TYPE}-%{HASH}.", "", ""),
+			domain:     "foo.example.com",
+			recordType: "AAAA",
+			txtDomain:  "_AAAA-45A335DE.foo.example.com",
+		},
 	}
 
 	for _, tc := range tests {
@@ -1404,6 +1693,97 @@ func TestToEndpointNameNewTXT(t *testing.T) {
 	}
 }
 
+func TestToEndpointNameHashMismatch(t *testing.T) {
+	mapper := newaffixNameMapper("%{record_type}-%{hash}.", "", "")
+
+	domain, recordType := mapper.toEndpointName("a-45a335de.foo.example.com")
+	assert.Equal(t, "foo.example.com", domain)
+	assert.Equal(t, endpoint.RecordTypeA, recordType)
+
+	// the hash of another name or no hash
+	for _, txtDomain := range []string{"a-a379a6f6.foo.example.com", "a-zzzzzzzz.foo.example.com", "a-foo.example.com"} {
+		domain, _ := mapper.toEndpointName(txtDomain)
+		assert.Empty(t, domain, txtDomain)
+	}
+
+	_, err := NewTXTRegistry(inmemory.NewInMemoryProvider(), TXTConfig{
+		Prefix:        "%{hash}-%{hash}.",
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+	})
+	assert.Error(t, err)
+}
+
+func TestOwnerDomainNameMapper(t *testing.T) {
+	mapper, err := newOwnerDomainNameMapper(newaffixNameMapper("", "", ""), []string{"_owner.example.com", "_owner.sub.example.com."})
+	require.NoError(t, err)
+
+	assert.Equal(t, "a-foo.bar._owner.example.com", mapper.toNewTXTName("foo.bar.example.com", endpoint.RecordTypeA))
+	assert.Equal(t, "cname-foo._owner.sub.example.com", mapper.toNewTXTName("foo.sub.example.com", endpoint.RecordTypeCNAME))
+	assert.Equal(t, "foo._owner.example.com", mapper.toTXTName("foo.example.com"))
+	// the records of other domains are not consolidated
+	assert.Equal(t, "a-foo.example.org", mapper.toNewTXTName("foo.example.org", endpoint.RecordTypeA))
+
+	for txtName, expected := range map[string][2]string{
+		"a-foo.bar._owner.example.com":     {"foo.bar.example.com", endpoint.RecordTypeA},
+		"CNAME-foo._owner.sub.example.com": {"foo.sub.example.com", endpoint.RecordTypeCNAME},
+		"foo._owner.example.com":           {"foo.example.com", ""},
+		"a-foo.example.org":                {"foo.example.org", endpoint.RecordTypeA},
+		// not in the owner domain of the record
+		"a-foo.bar.example.com":        {"", ""},
+		"a-foo.sub._owner.example.com": {"", ""},
+	} {
+		endpointName, recordType := mapper.toEndpointName(txtName)
+		assert.Equal(t, expected, [2]string{endpointName, recordType}, txtName)
+	}
+
+	_, err = newOwnerDomainNameMapper(newaffixNameMapper("", "", ""), []string{"example.com"})
+	assert.Error(t, err)
+}
+
+func TestTXTRegistryRecordOptions(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	r, err := NewTXTRegistry(p, TXTConfig{
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+		TTL:           3600,
+		Comment:       "ownership record",
+		OwnerDomains:  []string{"_owner.test-zone.example.org"},
+	})
+	require.NoError(t, err)
+
+	record := newEndpointWithOwner("foo.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "").
+		WithProviderSpecific("alias", "false")
+	record.RecordTTL = 60
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{record}}))
+	// the record itself is not commented
+	_, commented := record.GetProviderSpecificProperty(endpoint.ProviderSpecificRecordComment)
+	assert.False(t, commented)
+
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	txts := map[string]*endpoint.Endpoint{}
+	for _, ep := range records {
+		if ep.RecordType == endpoint.RecordTypeTXT {
+			txts[ep.DNSName] = ep
+		}
+	}
+	require.Len(t, txts, 2)
+	for _, name := range []string{"foo._owner.test-zone.example.org", "a-foo._owner.test-zone.example.org"} {
+		require.Contains(t, txts, name)
+		assert.Equal(t, endpoint.TTL(3600), txts[name].RecordTTL)
+		comment, _ := txts[name].GetProviderSpecificProperty(endpoint.ProviderSpecificRecordComment)
+		assert.Equal(t, "ownership record", comment)
+	}
+
+	records, err = r.Records(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "owner", records[0].Labels[endpoint.OwnerLabelKey])
+}
+
 func TestNewTXTScheme(t *testing.T) {
 	p := inmemory.NewInMemoryProvider()
 	p.CreateZone(testZone)
@@ -1426,7 +1806,10 @@ func TestNewTXTScheme(t *testing.T) {
 			newEndpointWithOwner("cname-foobar.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
 		},
 	})
-	r, _ := NewTXTRegistry(p, "", "", "owner", time.Hour, "", []string{}, []string{}, false, nil)
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+	})
 
 	changes := &plan.Changes{
 		Create: []*endpoint.Endpoint{
@@ -1502,7 +1885,10 @@ func TestGenerateTXT(t *testing.T) {
 	}
 	p := inmemory.NewInMemoryProvider()
 	p.CreateZone(testZone)
-	r, _ := NewTXTRegistry(p, "", "", "owner", time.Hour, "", []string{}, []string{}, false, nil)
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+	})
 	gotTXT := r.generateTXTRecord(record)
 	assert.Equal(t, expectedTXT, gotTXT)
 }
@@ -1521,7 +1907,10 @@ func TestGenerateTXTForAAAA(t *testing.T) {
 	}
 	p := inmemory.NewInMemoryProvider()
 	p.CreateZone(testZone)
-	r, _ := NewTXTRegistry(p, "", "", "owner", time.Hour, "", []string{}, []string{}, false, nil)
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+	})
 	gotTXT := r.generateTXTRecord(record)
 	assert.Equal(t, expectedTXT, gotTXT)
 }
@@ -1538,7 +1927,10 @@ func TestFailGenerateTXT(t *testing.T) {
 	expectedTXT := []*endpoint.Endpoint{}
 	p := inmemory.NewInMemoryProvider()
 	p.CreateZone(testZone)
-	r, _ := NewTXTRegistry(p, "", "", "owner", time.Hour, "", []string{}, []string{}, false, nil)
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		OwnerID:       "owner",
+		CacheInterval: time.Hour,
+	})
 	gotTXT := r.generateTXTRecord(cnameRecord)
 	assert.Equal(t, expectedTXT, gotTXT)
 }
@@ -1556,7 +1948,13 @@ func TestTXTRegistryApplyChangesEncrypt(t *testing.T) {
 		},
 	})
 
-	r, _ := NewTXTRegistry(p, "txt.", "", "owner", time.Hour, "", []string{}, []string{}, true, []byte("12345678901234567890123456789012"))
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		Prefix:         "txt.",
+		OwnerID:        "owner",
+		CacheInterval:  time.Hour,
+		EncryptEnabled: true,
+		EncryptAESKey:  []byte("12345678901234567890123456789012"),
+	})
 	records, _ := r.Records(ctx)
 	changes := &plan.Changes{
 		Delete: records,
@@ -1602,7 +2000,11 @@ func TestMultiClusterDifferentRecordTypeOwnership(t *testing.T) {
 		},
 	})
 
-	r, _ := NewTXTRegistry(p, "_owner.", "", "bar", time.Hour, "", []string{}, []string{}, false, nil)
+	r, _ := NewTXTRegistry(p, TXTConfig{
+		Prefix:        "_owner.",
+		OwnerID:       "bar",
+		CacheInterval: time.Hour,
+	})
 	records, _ := r.Records(ctx)
 
 	// new cluster has same ingress host as other cluster and uses CNAME ingress address
@@ -1646,6 +2048,70 @@ func TestMultiClusterDifferentRecordTypeOwnership(t *testing.T) {
 	}
 }
 
+func TestTXTRegistryAdopt(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("adopted.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+		},
+	}))
+
+	r, err := NewTXTRegistry(p, TXTConfig{
+		OwnerID:            "owner",
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+	})
+	require.NoError(t, err)
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+
+	desired := newEndpointWithOwner("adopted.test-zone.example.org", "5.6.7.8", endpoint.RecordTypeA, "")
+	desired.Labels[endpoint.AdoptLabelKey] = "true"
+	changes := (&plan.Plan{
+		Policies:       []plan.Policy{&plan.SyncPolicy{}},
+		Current:        records,
+		Desired:        []*endpoint.Endpoint{desired},
+		ManagedRecords: []string{endpoint.RecordTypeA},
+		OwnerID:        "owner",
+	}).Calculate().Changes
+	require.NoError(t, r.ApplyChanges(ctx, changes))
+
+	records, err = r.Records(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, endpoint.Targets{"5.6.7.8"}, records[0].Targets)
+	assert.Equal(t, endpoint.Labels{endpoint.OwnerLabelKey: "owner"}, records[0].Labels)
+}
+
+func TestTXTRegistryRecordsCanonicalNames(t *testing.T) {
+	// provider returning the names in upper case, with trailing dots and an escaped wildcard
+	p := newInMemoryProvider([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("App.Test-Zone.example.org.", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("APP.test-zone.example.org.", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=owner\""),
+		endpoint.NewEndpoint("A-app.test-zone.example.org.", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=owner\""),
+		endpoint.NewEndpoint(`\052.test-zone.example.org`, endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("wildcard.test-zone.example.org", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=owner\""),
+		endpoint.NewEndpoint("a-wildcard.test-zone.example.org", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=owner\""),
+	}, nil)
+
+	r, err := NewTXTRegistry(p, TXTConfig{
+		OwnerID:             "owner",
+		WildcardReplacement: "wildcard",
+		ManagedRecordTypes:  []string{endpoint.RecordTypeA},
+	})
+	require.NoError(t, err)
+	records, err := r.Records(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, records, 2)
+	for _, record := range records {
+		assert.Equal(t, "owner", record.Labels[endpoint.OwnerLabelKey], record.DNSName)
+		_, forceUpdate := record.GetProviderSpecificProperty(providerSpecificForceUpdate)
+		assert.False(t, forceUpdate, "%s must not be migrated", record.DNSName)
+	}
+}
+
 /**
 
 helper methods
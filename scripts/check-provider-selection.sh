@@ -0,0 +1,47 @@
+#!/usr/bin/env bash
+
+# Copyright 2024 The Kubernetes Authors.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Checks that a binary built with the select_providers build tag links no provider besides the ones selected,
+# and that a binary with a single provider stays below MAX_SIZE_PERCENT of the size of the binary with all of them.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+MAX_SIZE_PERCENT="${MAX_SIZE_PERCENT:-70}"
+PROVIDER="${PROVIDER:-aws}"
+
+# the inmemory provider is always built, and the zone type filter of the provider package uses the types of Route53
+linked=$(go list -deps -tags select_providers . | grep -E '^(sigs\.k8s\.io/external-dns/provider/|github\.com/aws/aws-sdk-go-v2/service/|github\.com/Azure/)' \
+  | grep -vE '^(sigs\.k8s\.io/external-dns/provider/inmemory|github\.com/aws/aws-sdk-go-v2/service/route53/types)$' || true)
+if [ -n "${linked}" ]; then
+  echo "a binary built without providers links the packages of providers:"
+  echo "${linked}"
+  exit 1
+fi
+
+dir=$(mktemp -d)
+trap 'rm -rf "${dir}"' EXIT
+CGO_ENABLED=0 go build -ldflags "-w -s" -o "${dir}/all" .
+CGO_ENABLED=0 go build -ldflags "-w -s" -tags "select_providers,provider_${PROVIDER}" -o "${dir}/selected" .
+all=$(wc -c < "${dir}/all")
+selected=$(wc -c < "${dir}/selected")
+percent=$(( selected * 100 / all ))
+echo "a binary with the ${PROVIDER} provider is ${selected} bytes, ${percent}% of the ${all} bytes of a binary with all providers"
+if [ "${percent}" -gt "${MAX_SIZE_PERCENT}" ]; then
+  echo "the binary with the ${PROVIDER} provider exceeds ${MAX_SIZE_PERCENT}% of the binary with all providers"
+  exit 1
+fi
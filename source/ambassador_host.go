@@ -167,7 +167,7 @@ func (sc *ambassadorHostSource) Endpoints(ctx context.Context) ([]*endpoint.Endp
 			continue
 		}
 		if len(hostEndpoints) == 0 {
-			log.Debugf("No endpoints could be generated from Host %s", fullname)
+			countSkipped(ctx, skipReasonNoHostname).Debugf("No endpoints could be generated from Host %s", fullname)
 			continue
 		}
 
@@ -0,0 +1,47 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// OwnerIDAuto is the owner ID that is replaced by the identity of the cluster.
+	OwnerIDAuto = "auto"
+	// The annotation on the kube-system namespace used for overriding the detected cluster identity
+	ownerIDAnnotationKey = "external-dns.alpha.kubernetes.io/owner-id"
+	// The namespace identifying the cluster, as it exists for the whole lifetime of a cluster
+	clusterIdentityNamespace = "kube-system"
+)
+
+// ClusterOwnerID returns a stable owner ID unique to the cluster, derived from the UID of the kube-system namespace.
+// The owner-id annotation on the namespace takes precedence, e.g. to keep the owner ID of a recreated cluster.
+func ClusterOwnerID(ctx context.Context, kubeClient kubernetes.Interface) (string, error) {
+	ns, err := kubeClient.CoreV1().Namespaces().Get(ctx, clusterIdentityNamespace, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to detect the cluster identity from the %s namespace: %w", clusterIdentityNamespace, err)
+	}
+	if ownerID := ns.Annotations[ownerIDAnnotationKey]; ownerID != "" {
+		return ownerID, nil
+	}
+	return "cluster-" + string(ns.UID), nil
+}
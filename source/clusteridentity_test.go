@@ -0,0 +1,45 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestClusterOwnerID(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := ClusterOwnerID(ctx, fake.NewSimpleClientset())
+	assert.Error(t, err)
+
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system", UID: "3f1e5a7c-0b1d-4e55-9b1a-0c7d5e0b2a11"}}
+	ownerID, err := ClusterOwnerID(ctx, fake.NewSimpleClientset(ns))
+	require.NoError(t, err)
+	assert.Equal(t, "cluster-3f1e5a7c-0b1d-4e55-9b1a-0c7d5e0b2a11", ownerID)
+
+	ns.Annotations = map[string]string{ownerIDAnnotationKey: "blue"}
+	ownerID, err = ClusterOwnerID(ctx, fake.NewSimpleClientset(ns))
+	require.NoError(t, err)
+	assert.Equal(t, "blue", ownerID)
+}
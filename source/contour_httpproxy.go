@@ -137,8 +137,12 @@ func (sc *httpProxySource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint,
 		// Check controller annotation to see if we are responsible.
 		controller, ok := hp.Annotations[controllerAnnotationKey]
 		if ok && controller != controllerAnnotationValue {
-			log.Debugf("Skipping HTTPProxy %s/%s because controller value does not match, found: %s, required: %s",
-				hp.Namespace, hp.Name, controller, controllerAnnotationValue)
+			countSkipped(ctx, skipReasonControllerMismatch).WithFields(log.Fields{
+				"filter":   controllerAnnotationKey,
+				"found":    controller,
+				"required": controllerAnnotationValue,
+			}).Debugf("Skipping HTTPProxy %s/%s because controller value does not match",
+				hp.Namespace, hp.Name)
 			continue
 		}
 
@@ -162,7 +166,7 @@ func (sc *httpProxySource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint,
 		}
 
 		if len(hpEndpoints) == 0 {
-			log.Debugf("No endpoints could be generated from HTTPProxy %s/%s", hp.Namespace, hp.Name)
+			countSkipped(ctx, skipReasonNoHostname).Debugf("No endpoints could be generated from HTTPProxy %s/%s", hp.Namespace, hp.Name)
 			continue
 		}
 
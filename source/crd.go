@@ -48,6 +48,7 @@ type crdSource struct {
 	codec            runtime.ParameterCodec
 	annotationFilter string
 	labelSelector    labels.Selector
+	internalTargets  []string
 	informer         *cache.SharedInformer
 }
 
@@ -108,12 +109,13 @@ func NewCRDClientForAPIVersionKind(client kubernetes.Interface, kubeConfig, apiS
 }
 
 // NewCRDSource creates a new crdSource with the given config.
-func NewCRDSource(crdClient rest.Interface, namespace, kind string, annotationFilter string, labelSelector labels.Selector, scheme *runtime.Scheme, startInformer bool) (Source, error) {
+func NewCRDSource(crdClient rest.Interface, namespace, kind string, annotationFilter string, labelSelector labels.Selector, scheme *runtime.Scheme, startInformer bool, internalTargets []string) (Source, error) {
 	sourceCrd := crdSource{
 		crdResource:      strings.ToLower(kind) + "s",
 		namespace:        namespace,
 		annotationFilter: annotationFilter,
 		labelSelector:    labelSelector,
+		internalTargets:  internalTargets,
 		crdClient:        crdClient,
 		codec:            runtime.NewParameterCodec(scheme),
 	}
@@ -211,6 +213,8 @@ func (cs *crdSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error
 			crdEndpoints = append(crdEndpoints, ep)
 		}
 
+		crdEndpoints = append(crdEndpoints, cs.endpointsFromInternalHostnames(&dnsEndpoint)...)
+
 		cs.setResourceLabel(&dnsEndpoint, crdEndpoints)
 		endpoints = append(endpoints, crdEndpoints...)
 
@@ -229,6 +233,23 @@ func (cs *crdSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error
 	return endpoints, nil
 }
 
+// endpointsFromInternalHostnames returns the endpoints of the internal-hostname annotation of the DNSEndpoint.
+// Their targets must be given by the internal-target annotation or the configured internal targets.
+func (cs *crdSource) endpointsFromInternalHostnames(crd *endpoint.DNSEndpoint) []*endpoint.Endpoint {
+	if _, ok := crd.Annotations[internalHostnameAnnotationKey]; !ok {
+		return nil
+	}
+	resource := fmt.Sprintf("crd/%s/%s", crd.Namespace, crd.Name)
+	targets := getInternalTargets(crd.Annotations, cs.internalTargets, nil)
+	if len(targets) == 0 {
+		log.Warnf("Skipping the internal hostnames of %s without internal targets", resource)
+		return nil
+	}
+	ttl := getTTLFromAnnotations(crd.Annotations, resource)
+	providerSpecific, setIdentifier := getProviderSpecificAnnotations(crd.Annotations)
+	return endpointsForInternalHostnames(crd.Annotations, targets, ttl, providerSpecific, setIdentifier, resource)
+}
+
 func (cs *crdSource) setResourceLabel(crd *endpoint.DNSEndpoint, endpoints []*endpoint.Endpoint) {
 	for _, ep := range endpoints {
 		ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("crd/%s/%s", crd.ObjectMeta.Namespace, crd.ObjectMeta.Name)
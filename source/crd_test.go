@@ -487,7 +487,7 @@ func testCRDSourceEndpoints(t *testing.T) {
 			// So don't start the informer during testing.
 			startInformer := false
 
-			cs, err := NewCRDSource(restClient, ti.namespace, ti.kind, ti.annotationFilter, labelSelector, scheme, startInformer)
+			cs, err := NewCRDSource(restClient, ti.namespace, ti.kind, ti.annotationFilter, labelSelector, scheme, startInformer, nil)
 			require.NoError(t, err)
 
 			receivedEndpoints, err := cs.Endpoints(context.Background())
@@ -511,6 +511,76 @@ func testCRDSourceEndpoints(t *testing.T) {
 	}
 }
 
+func TestCRDSourceInternalHostname(t *testing.T) {
+	t.Parallel()
+
+	apiVersion := "test.k8s.io/v1alpha1"
+	specEndpoints := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("abc.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+	}
+
+	for _, tc := range []struct {
+		title           string
+		annotations     map[string]string
+		internalTargets []string
+		expected        []*endpoint.Endpoint
+	}{
+		{
+			title: "internal-target annotation",
+			annotations: map[string]string{
+				internalHostnameAnnotationKey: "abc.internal.example.org",
+				internalTargetAnnotationKey:   "10.0.0.10",
+			},
+			internalTargets: []string{"internal-lb.example.org"},
+			expected: []*endpoint.Endpoint{
+				endpoint.NewEndpoint("abc.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+				endpoint.NewEndpoint("abc.internal.example.org", endpoint.RecordTypeA, "10.0.0.10"),
+			},
+		},
+		{
+			title: "configured internal targets",
+			annotations: map[string]string{
+				internalHostnameAnnotationKey: "abc.internal.example.org",
+			},
+			internalTargets: []string{"internal-lb.example.org"},
+			expected: []*endpoint.Endpoint{
+				endpoint.NewEndpoint("abc.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+				endpoint.NewEndpoint("abc.internal.example.org", endpoint.RecordTypeCNAME, "internal-lb.example.org"),
+			},
+		},
+		{
+			title: "no internal targets",
+			annotations: map[string]string{
+				internalHostnameAnnotationKey: "abc.internal.example.org",
+			},
+			expected: []*endpoint.Endpoint{
+				endpoint.NewEndpoint("abc.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			restClient := fakeRESTClient(specEndpoints, apiVersion, "DNSEndpoint", "foo", "test", tc.annotations, nil, t)
+			groupVersion, err := schema.ParseGroupVersion(apiVersion)
+			require.NoError(t, err)
+			scheme := runtime.NewScheme()
+			require.NoError(t, addKnownTypes(scheme, groupVersion))
+
+			cs, err := NewCRDSource(restClient, "foo", "DNSEndpoint", "", labels.Everything(), scheme, false, tc.internalTargets)
+			require.NoError(t, err)
+
+			endpoints, err := cs.Endpoints(context.Background())
+			require.NoError(t, err)
+			for _, ep := range tc.expected {
+				ep.Labels[endpoint.ResourceLabelKey] = "crd/foo/test"
+			}
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
 func validateCRDResource(t *testing.T, src Source, expectError bool) {
 	cs := src.(*crdSource)
 	result, err := cs.List(context.Background(), &metav1.ListOptions{})
@@ -0,0 +1,204 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// failoverTargetPlaceholder is replaced by each target of the endpoint in a failover query.
+const failoverTargetPlaceholder = "$target"
+
+// failoverQueryTimeout bounds the evaluation of a single failover query.
+const failoverQueryTimeout = 10 * time.Second
+
+var (
+	failoverQueryErrorsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "source",
+			Name:      "failover_query_errors_total",
+			Help:      "Number of failover queries that could not be evaluated.",
+		},
+	)
+	failoverEndpoints = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "source",
+			Name:      "failover_endpoints",
+			Help:      "Number of endpoints with unhealthy targets removed or replaced by failover targets.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(failoverQueryErrorsTotal)
+	prometheus.MustRegister(failoverEndpoints)
+}
+
+// FailoverEvaluator evaluates failover queries.
+type FailoverEvaluator interface {
+	// Unhealthy returns true if the query indicates that the origin is unhealthy.
+	Unhealthy(ctx context.Context, query string) (bool, error)
+}
+
+// failoverSource is a Source that removes unhealthy targets of endpoints annotated with a failover query,
+// or replaces them with the failover targets if no healthy target is left.
+type failoverSource struct {
+	source    Source
+	evaluator FailoverEvaluator
+}
+
+// NewFailoverSource creates a new failoverSource wrapping the provided Source.
+// If evaluator is nil, failover queries are ignored.
+func NewFailoverSource(source Source, evaluator FailoverEvaluator) Source {
+	return &failoverSource{source: source, evaluator: evaluator}
+}
+
+// Endpoints collects endpoints from its wrapped source and applies the failover queries.
+// Targets are kept if a query cannot be evaluated or if all targets are unhealthy and no failover targets are set.
+func (fs *failoverSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := fs.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	failovers := 0
+	for _, ep := range endpoints {
+		query, ok := ep.GetProviderSpecificProperty(FailoverQueryKey)
+		failoverTargets, _ := ep.GetProviderSpecificProperty(FailoverTargetsKey)
+		// the properties are only meant for this source and must not reach the provider
+		ep.DeleteProviderSpecificProperty(FailoverQueryKey)
+		ep.DeleteProviderSpecificProperty(FailoverTargetsKey)
+		if !ok || fs.evaluator == nil {
+			continue
+		}
+
+		healthy := fs.healthyTargets(ctx, ep, query)
+		if len(healthy) == len(ep.Targets) {
+			continue
+		}
+
+		switch {
+		case len(healthy) > 0:
+			log.Infof("Removing unhealthy targets from %s, keeping %v", ep.DNSName, healthy)
+			ep.Targets = healthy
+		case failoverTargets != "":
+			log.Infof("Switching %s to failover targets %s", ep.DNSName, failoverTargets)
+			ep.Targets = splitTargets(failoverTargets)
+		default:
+			log.Warnf("All targets of %s are unhealthy and no failover targets are set, keeping targets", ep.DNSName)
+			continue
+		}
+		failovers++
+	}
+	failoverEndpoints.Set(float64(failovers))
+
+	return endpoints, nil
+}
+
+// healthyTargets evaluates the query once per target if it references the target placeholder,
+// otherwise the query decides on all targets of the endpoint at once.
+func (fs *failoverSource) healthyTargets(ctx context.Context, ep *endpoint.Endpoint, query string) endpoint.Targets {
+	if !strings.Contains(query, failoverTargetPlaceholder) {
+		if fs.unhealthy(ctx, ep, query) {
+			return nil
+		}
+		return ep.Targets
+	}
+
+	healthy := endpoint.Targets{}
+	for _, target := range ep.Targets {
+		if !fs.unhealthy(ctx, ep, strings.ReplaceAll(query, failoverTargetPlaceholder, target)) {
+			healthy = append(healthy, target)
+		}
+	}
+	return healthy
+}
+
+func (fs *failoverSource) unhealthy(ctx context.Context, ep *endpoint.Endpoint, query string) bool {
+	unhealthy, err := fs.evaluator.Unhealthy(ctx, query)
+	if err != nil {
+		failoverQueryErrorsTotal.Inc()
+		log.Warnf("Failed to evaluate failover query for %s, assuming it is healthy: %v", ep.DNSName, err)
+		return false
+	}
+	return unhealthy
+}
+
+func (fs *failoverSource) AddEventHandler(ctx context.Context, handler func()) {
+	fs.source.AddEventHandler(ctx, handler)
+}
+
+func splitTargets(targets string) endpoint.Targets {
+	var result endpoint.Targets
+	for _, t := range strings.Split(strings.ReplaceAll(targets, " ", ""), ",") {
+		if t != "" {
+			result = append(result, strings.TrimSuffix(t, "."))
+		}
+	}
+	return result
+}
+
+// PrometheusEvaluator evaluates failover queries as PromQL expressions.
+// Like an alerting rule, an expression indicates an unhealthy origin if it returns any sample.
+type PrometheusEvaluator struct {
+	api promv1.API
+}
+
+// NewPrometheusEvaluator creates a PrometheusEvaluator querying the Prometheus server at address.
+func NewPrometheusEvaluator(address string) (*PrometheusEvaluator, error) {
+	client, err := api.NewClient(api.Config{Address: address})
+	if err != nil {
+		return nil, err
+	}
+	return &PrometheusEvaluator{api: promv1.NewAPI(client)}, nil
+}
+
+// Unhealthy returns true if the query returns at least one sample, or a non-zero scalar.
+func (e *PrometheusEvaluator) Unhealthy(ctx context.Context, query string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, failoverQueryTimeout)
+	defer cancel()
+
+	result, warnings, err := e.api.Query(ctx, query, time.Now())
+	if err != nil {
+		return false, err
+	}
+	for _, w := range warnings {
+		log.Debugf("Failover query %q: %s", query, w)
+	}
+
+	switch v := result.(type) {
+	case model.Vector:
+		return len(v) > 0, nil
+	case *model.Scalar:
+		return v.Value != 0, nil
+	default:
+		return false, fmt.Errorf("unsupported result type %s of failover query %q", result.Type(), query)
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// fakeEvaluator reports the queries in unhealthy as unhealthy and fails for the queries in failing.
+type fakeEvaluator struct {
+	unhealthy map[string]bool
+	failing   map[string]bool
+}
+
+func (e *fakeEvaluator) Unhealthy(ctx context.Context, query string) (bool, error) {
+	if e.failing[query] {
+		return false, errors.New("query failed")
+	}
+	return e.unhealthy[query], nil
+}
+
+func TestFailoverSource(t *testing.T) {
+	evaluator := &fakeEvaluator{
+		unhealthy: map[string]bool{
+			"down":                             true,
+			`probe_success{ip="1.1.1.1"} == 0`: true,
+		},
+		failing: map[string]bool{"broken": true},
+	}
+
+	for _, tc := range []struct {
+		title     string
+		query     string
+		failover  string
+		targets   endpoint.Targets
+		evaluator FailoverEvaluator
+		expected  endpoint.Targets
+	}{
+		{
+			title:     "no query",
+			targets:   endpoint.Targets{"1.1.1.1"},
+			evaluator: evaluator,
+			expected:  endpoint.Targets{"1.1.1.1"},
+		},
+		{
+			title:     "healthy",
+			query:     "up",
+			failover:  "9.9.9.9",
+			targets:   endpoint.Targets{"1.1.1.1"},
+			evaluator: evaluator,
+			expected:  endpoint.Targets{"1.1.1.1"},
+		},
+		{
+			title:     "unhealthy switches to failover targets",
+			query:     "down",
+			failover:  "9.9.9.9, 8.8.8.8",
+			targets:   endpoint.Targets{"1.1.1.1"},
+			evaluator: evaluator,
+			expected:  endpoint.Targets{"9.9.9.9", "8.8.8.8"},
+		},
+		{
+			title:     "unhealthy without failover targets keeps targets",
+			query:     "down",
+			targets:   endpoint.Targets{"1.1.1.1"},
+			evaluator: evaluator,
+			expected:  endpoint.Targets{"1.1.1.1"},
+		},
+		{
+			title:     "per target query removes unhealthy target",
+			query:     `probe_success{ip="$target"} == 0`,
+			failover:  "9.9.9.9",
+			targets:   endpoint.Targets{"1.1.1.1", "2.2.2.2"},
+			evaluator: evaluator,
+			expected:  endpoint.Targets{"2.2.2.2"},
+		},
+		{
+			title:     "failing query is considered healthy",
+			query:     "broken",
+			failover:  "9.9.9.9",
+			targets:   endpoint.Targets{"1.1.1.1"},
+			evaluator: evaluator,
+			expected:  endpoint.Targets{"1.1.1.1"},
+		},
+		{
+			title:    "no evaluator",
+			query:    "down",
+			failover: "9.9.9.9",
+			targets:  endpoint.Targets{"1.1.1.1"},
+			expected: endpoint.Targets{"1.1.1.1"},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			ep := endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, tc.targets...)
+			if tc.query != "" {
+				ep = ep.WithProviderSpecific(FailoverQueryKey, tc.query)
+			}
+			if tc.failover != "" {
+				ep = ep.WithProviderSpecific(FailoverTargetsKey, tc.failover)
+			}
+
+			endpoints, err := NewFailoverSource(NewEchoSource([]*endpoint.Endpoint{ep}), tc.evaluator).Endpoints(context.Background())
+			require.NoError(t, err)
+			require.Len(t, endpoints, 1)
+			assert.Equal(t, tc.expected, endpoints[0].Targets)
+			assert.Empty(t, endpoints[0].ProviderSpecific)
+		})
+	}
+}
+
+func TestPrometheusEvaluator(t *testing.T) {
+	results := map[string]string{
+		"empty":  `{"resultType":"vector","result":[]}`,
+		"vector": `{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"1"]}]}`,
+		"zero":   `{"resultType":"scalar","result":[1700000000,"0"]}`,
+		"one":    `{"resultType":"scalar","result":[1700000000,"1"]}`,
+		"matrix": `{"resultType":"matrix","result":[]}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		result, ok := results[r.Form.Get("query")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"status":"error","errorType":"bad_data","error":"parse error"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"success","data":%s}`, result)
+	}))
+	defer server.Close()
+
+	evaluator, err := NewPrometheusEvaluator(server.URL)
+	require.NoError(t, err)
+
+	for query, expected := range map[string]bool{"empty": false, "vector": true, "zero": false, "one": true} {
+		unhealthy, err := evaluator.Unhealthy(context.Background(), query)
+		require.NoError(t, err, query)
+		assert.Equal(t, expected, unhealthy, query)
+	}
+
+	_, err = evaluator.Unhealthy(context.Background(), "matrix")
+	assert.Error(t, err)
+	_, err = evaluator.Unhealthy(context.Background(), "invalid")
+	assert.Error(t, err)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net/netip"
+	"slices"
 	"sort"
 	"strings"
 	"text/template"
@@ -46,7 +47,7 @@ const (
 	gatewayGroup = "gateway.networking.k8s.io"
 	gatewayKind  = "Gateway"
 	// gatewayAPIDualstackAnnotationKey is the annotation used for determining if a Gateway Route is dualstack
-	gatewayAPIDualstackAnnotationKey = "external-dns.alpha.kubernetes.io/dualstack"
+	gatewayAPIDualstackAnnotationKey = dualstackAnnotationKey
 	// gatewayAPIDualstackAnnotationValue is the value of the Gateway Route dualstack annotation that indicates it is dualstack
 	gatewayAPIDualstackAnnotationValue = "true"
 )
@@ -62,6 +63,8 @@ type gatewayRoute interface {
 	Protocol() v1.ProtocolType
 	// RouteStatus returns the route's common status.
 	RouteStatus() v1.RouteStatus
+	// BackendRefs returns the backends of the route's rules.
+	BackendRefs() []v1.BackendObjectReference
 }
 
 type newGatewayRouteInformerFunc func(informers.SharedInformerFactory) gatewayRouteInformer
@@ -96,11 +99,13 @@ type gatewayRouteSource struct {
 	rtAnnotations labels.Selector
 	rtInformer    gatewayRouteInformer
 
-	nsInformer coreinformers.NamespaceInformer
+	nsInformer  coreinformers.NamespaceInformer
+	svcInformer coreinformers.ServiceInformer
 
 	fqdnTemplate             *template.Template
 	combineFQDNAnnotation    bool
 	ignoreHostnameAnnotation bool
+	internalTargets          []string
 }
 
 func newGatewayRouteSource(clients ClientGenerator, config *Config, kind string, newInformerFn newGatewayRouteInformerFunc) (Source, error) {
@@ -147,6 +152,8 @@ func newGatewayRouteSource(clients ClientGenerator, config *Config, kind string,
 	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, 0)
 	nsInformer := kubeInformerFactory.Core().V1().Namespaces() // TODO: Namespace informer should be shared across gateway sources.
 	nsInformer.Informer()                                      // Register with factory before starting.
+	svcInformer := kubeInformerFactory.Core().V1().Services()  // The backend Services provide the cluster IPs of the internal hostnames.
+	svcInformer.Informer()                                     // Register with factory before starting.
 
 	informerFactory.Start(wait.NeverStop)
 	kubeInformerFactory.Start(wait.NeverStop)
@@ -175,11 +182,13 @@ func newGatewayRouteSource(clients ClientGenerator, config *Config, kind string,
 		rtAnnotations: rtAnnotations,
 		rtInformer:    rtInformer,
 
-		nsInformer: nsInformer,
+		nsInformer:  nsInformer,
+		svcInformer: svcInformer,
 
 		fqdnTemplate:             tmpl,
 		combineFQDNAnnotation:    config.CombineFQDNAndAnnotation,
 		ignoreHostnameAnnotation: config.IgnoreHostnameAnnotation,
+		internalTargets:          config.InternalTargets,
 	}
 	return src, nil
 }
@@ -218,8 +227,12 @@ func (src *gatewayRouteSource) Endpoints(ctx context.Context) ([]*endpoint.Endpo
 
 		// Check controller annotation to see if we are responsible.
 		if v, ok := annots[controllerAnnotationKey]; ok && v != controllerAnnotationValue {
-			log.Debugf("Skipping %s %s/%s because controller value does not match, found: %s, required: %s",
-				src.rtKind, meta.Namespace, meta.Name, v, controllerAnnotationValue)
+			countSkipped(ctx, skipReasonControllerMismatch).WithFields(log.Fields{
+				"filter":   controllerAnnotationKey,
+				"found":    v,
+				"required": controllerAnnotationValue,
+			}).Debugf("Skipping %s %s/%s because controller value does not match",
+				src.rtKind, meta.Namespace, meta.Name)
 			continue
 		}
 
@@ -228,24 +241,54 @@ func (src *gatewayRouteSource) Endpoints(ctx context.Context) ([]*endpoint.Endpo
 		if err != nil {
 			return nil, err
 		}
-		if len(hostTargets) == 0 {
-			log.Debugf("No endpoints could be generated from %s %s/%s", src.rtKind, meta.Namespace, meta.Name)
+		resource := fmt.Sprintf("%s/%s/%s", kind, meta.Namespace, meta.Name)
+		providerSpecific, setIdentifier := getProviderSpecificAnnotations(annots)
+		ttl := getTTLFromAnnotations(annots, resource)
+		var internalEndpoints []*endpoint.Endpoint
+		if _, ok := annots[internalHostnameAnnotationKey]; ok && !src.ignoreHostnameAnnotation {
+			internalEndpoints = endpointsForInternalHostnames(annots, src.internalTargetsOf(rt), ttl, providerSpecific, setIdentifier, resource)
+		}
+		if len(hostTargets) == 0 && len(internalEndpoints) == 0 {
+			countSkipped(ctx, skipReasonNoHostname).Debugf("No endpoints could be generated from %s %s/%s", src.rtKind, meta.Namespace, meta.Name)
 			continue
 		}
 
 		// Create endpoints from hostnames and targets.
-		resource := fmt.Sprintf("%s/%s/%s", kind, meta.Namespace, meta.Name)
-		providerSpecific, setIdentifier := getProviderSpecificAnnotations(annots)
-		ttl := getTTLFromAnnotations(annots, resource)
 		for host, targets := range hostTargets {
 			endpoints = append(endpoints, endpointsForHostname(host, targets, ttl, providerSpecific, setIdentifier, resource)...)
 		}
+		endpoints = append(endpoints, internalEndpoints...)
 		setDualstackLabel(rt, endpoints)
 		log.Debugf("Endpoints generated from %s %s/%s: %v", src.rtKind, meta.Namespace, meta.Name, endpoints)
 	}
 	return endpoints, nil
 }
 
+// internalTargetsOf returns the targets of the internal hostnames of the route,
+// falling back to the cluster IPs of its backend Services.
+func (src *gatewayRouteSource) internalTargetsOf(rt gatewayRoute) endpoint.Targets {
+	meta := rt.Metadata()
+	if targets := getInternalTargets(meta.Annotations, src.internalTargets, nil); len(targets) > 0 {
+		return targets
+	}
+	var targets endpoint.Targets
+	for _, ref := range rt.BackendRefs() {
+		if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != "Service") {
+			continue
+		}
+		namespace := meta.Namespace
+		if ref.Namespace != nil {
+			namespace = string(*ref.Namespace)
+		}
+		for _, ip := range clusterIPsOfServices(src.svcInformer.Lister(), namespace, []string{string(ref.Name)}) {
+			if !slices.Contains(targets, ip) {
+				targets = append(targets, ip)
+			}
+		}
+	}
+	return targets
+}
+
 func namespacedName(namespace, name string) types.NamespacedName {
 	return types.NamespacedName{Namespace: namespace, Name: name}
 }
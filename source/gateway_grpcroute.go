@@ -38,6 +38,15 @@ func (rt *gatewayGRPCRoute) Metadata() *metav1.ObjectMeta { return &rt.route.Obj
 func (rt *gatewayGRPCRoute) Hostnames() []v1.Hostname     { return rt.route.Spec.Hostnames }
 func (rt *gatewayGRPCRoute) Protocol() v1.ProtocolType    { return v1.HTTPSProtocolType }
 func (rt *gatewayGRPCRoute) RouteStatus() v1.RouteStatus  { return rt.route.Status.RouteStatus }
+func (rt *gatewayGRPCRoute) BackendRefs() []v1.BackendObjectReference {
+	var refs []v1.BackendObjectReference
+	for _, rule := range rt.route.Spec.Rules {
+		for _, ref := range rule.BackendRefs {
+			refs = append(refs, ref.BackendObjectReference)
+		}
+	}
+	return refs
+}
 
 type gatewayGRPCRouteInformer struct {
 	informers_v1.GRPCRouteInformer
@@ -39,6 +39,15 @@ func (rt *gatewayHTTPRoute) Metadata() *metav1.ObjectMeta { return &rt.route.Obj
 func (rt *gatewayHTTPRoute) Hostnames() []v1.Hostname     { return rt.route.Spec.Hostnames }
 func (rt *gatewayHTTPRoute) Protocol() v1.ProtocolType    { return v1.HTTPProtocolType }
 func (rt *gatewayHTTPRoute) RouteStatus() v1.RouteStatus  { return rt.route.Status.RouteStatus }
+func (rt *gatewayHTTPRoute) BackendRefs() []v1.BackendObjectReference {
+	var refs []v1.BackendObjectReference
+	for _, rule := range rt.route.Spec.Rules {
+		for _, ref := range rule.BackendRefs {
+			refs = append(refs, ref.BackendObjectReference)
+		}
+	}
+	return refs
+}
 
 type gatewayHTTPRouteInformer struct {
 	informers_v1beta1.HTTPRouteInformer
@@ -131,6 +131,7 @@ func TestGatewayHTTPRouteSourceEndpoints(t *testing.T) {
 		return v
 	}
 	hostnames := func(names ...v1.Hostname) []v1.Hostname { return names }
+	backendNamespace := v1.Namespace("backend")
 
 	tests := []struct {
 		title      string
@@ -138,6 +139,7 @@ func TestGatewayHTTPRouteSourceEndpoints(t *testing.T) {
 		namespaces []*corev1.Namespace
 		gateways   []*v1beta1.Gateway
 		routes     []*v1beta1.HTTPRoute
+		services   []*corev1.Service
 		endpoints  []*endpoint.Endpoint
 	}{
 		{
@@ -1119,6 +1121,79 @@ func TestGatewayHTTPRouteSourceEndpoints(t *testing.T) {
 				newTestEndpoint("test.example.internal", "A", "4.3.2.1", "2.3.4.5"),
 			},
 		},
+		{
+			title:      "InternalHostnameClusterIP",
+			config:     Config{},
+			namespaces: namespaces("default", "backend"),
+			gateways: []*v1beta1.Gateway{{
+				ObjectMeta: objectMeta("default", "test"),
+				Spec: v1.GatewaySpec{
+					Listeners: []v1.Listener{{Protocol: v1.HTTPProtocolType}},
+				},
+				Status: gatewayStatus("1.2.3.4"),
+			}},
+			routes: []*v1beta1.HTTPRoute{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "default",
+					Annotations: map[string]string{
+						internalHostnameAnnotationKey: "test.internal.example.internal",
+					},
+				},
+				Spec: v1.HTTPRouteSpec{
+					Hostnames: hostnames("test.example.internal"),
+					Rules: []v1.HTTPRouteRule{{
+						BackendRefs: []v1.HTTPBackendRef{
+							{BackendRef: v1.BackendRef{BackendObjectReference: v1.BackendObjectReference{Name: "web"}}},
+							{BackendRef: v1.BackendRef{BackendObjectReference: v1.BackendObjectReference{
+								Name:      "api",
+								Namespace: &backendNamespace,
+							}}},
+						},
+					}},
+				},
+				Status: httpRouteStatus(gwParentRef("default", "test")),
+			}},
+			services: []*corev1.Service{
+				{ObjectMeta: objectMeta("default", "web"), Spec: corev1.ServiceSpec{ClusterIP: "10.0.0.10"}},
+				{ObjectMeta: objectMeta("backend", "api"), Spec: corev1.ServiceSpec{ClusterIP: "10.0.0.20"}},
+			},
+			endpoints: []*endpoint.Endpoint{
+				newTestEndpoint("test.example.internal", "A", "1.2.3.4"),
+				newTestEndpoint("test.internal.example.internal", "A", "10.0.0.10", "10.0.0.20"),
+			},
+		},
+		{
+			title: "InternalHostnameInternalTargets",
+			config: Config{
+				InternalTargets: []string{"internal-lb.example.internal"},
+			},
+			namespaces: namespaces("default"),
+			gateways: []*v1beta1.Gateway{{
+				ObjectMeta: objectMeta("default", "test"),
+				Spec: v1.GatewaySpec{
+					Listeners: []v1.Listener{{Protocol: v1.HTTPProtocolType}},
+				},
+				Status: gatewayStatus("1.2.3.4"),
+			}},
+			routes: []*v1beta1.HTTPRoute{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "default",
+					Annotations: map[string]string{
+						internalHostnameAnnotationKey: "test.internal.example.internal",
+					},
+				},
+				Spec: v1.HTTPRouteSpec{
+					Hostnames: hostnames("test.example.internal"),
+				},
+				Status: httpRouteStatus(gwParentRef("default", "test")),
+			}},
+			endpoints: []*endpoint.Endpoint{
+				newTestEndpoint("test.example.internal", "A", "1.2.3.4"),
+				newTestEndpoint("test.internal.example.internal", "CNAME", "internal-lb.example.internal"),
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -1141,6 +1216,10 @@ func TestGatewayHTTPRouteSourceEndpoints(t *testing.T) {
 				_, err := kubeClient.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{})
 				require.NoError(t, err, "failed to create Namespace")
 			}
+			for _, svc := range tt.services {
+				_, err := kubeClient.CoreV1().Services(svc.Namespace).Create(ctx, svc, metav1.CreateOptions{})
+				require.NoError(t, err, "failed to create Service")
+			}
 
 			clients := new(MockClientGenerator)
 			clients.On("GatewayClient").Return(gwClient, nil)
@@ -39,6 +39,15 @@ func (rt *gatewayTCPRoute) Metadata() *metav1.ObjectMeta { return &rt.route.Obje
 func (rt *gatewayTCPRoute) Hostnames() []v1.Hostname     { return nil }
 func (rt *gatewayTCPRoute) Protocol() v1.ProtocolType    { return v1.TCPProtocolType }
 func (rt *gatewayTCPRoute) RouteStatus() v1.RouteStatus  { return rt.route.Status.RouteStatus }
+func (rt *gatewayTCPRoute) BackendRefs() []v1.BackendObjectReference {
+	var refs []v1.BackendObjectReference
+	for _, rule := range rt.route.Spec.Rules {
+		for _, ref := range rule.BackendRefs {
+			refs = append(refs, ref.BackendObjectReference)
+		}
+	}
+	return refs
+}
 
 type gatewayTCPRouteInformer struct {
 	informers_v1a2.TCPRouteInformer
@@ -39,6 +39,15 @@ func (rt *gatewayTLSRoute) Metadata() *metav1.ObjectMeta { return &rt.route.Obje
 func (rt *gatewayTLSRoute) Hostnames() []v1.Hostname     { return rt.route.Spec.Hostnames }
 func (rt *gatewayTLSRoute) Protocol() v1.ProtocolType    { return v1.TLSProtocolType }
 func (rt *gatewayTLSRoute) RouteStatus() v1.RouteStatus  { return rt.route.Status.RouteStatus }
+func (rt *gatewayTLSRoute) BackendRefs() []v1.BackendObjectReference {
+	var refs []v1.BackendObjectReference
+	for _, rule := range rt.route.Spec.Rules {
+		for _, ref := range rule.BackendRefs {
+			refs = append(refs, ref.BackendObjectReference)
+		}
+	}
+	return refs
+}
 
 type gatewayTLSRouteInformer struct {
 	informers_v1a2.TLSRouteInformer
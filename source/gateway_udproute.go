@@ -39,6 +39,15 @@ func (rt *gatewayUDPRoute) Metadata() *metav1.ObjectMeta { return &rt.route.Obje
 func (rt *gatewayUDPRoute) Hostnames() []v1.Hostname     { return nil }
 func (rt *gatewayUDPRoute) Protocol() v1.ProtocolType    { return v1.UDPProtocolType }
 func (rt *gatewayUDPRoute) RouteStatus() v1.RouteStatus  { return rt.route.Status.RouteStatus }
+func (rt *gatewayUDPRoute) BackendRefs() []v1.BackendObjectReference {
+	var refs []v1.BackendObjectReference
+	for _, rule := range rt.route.Spec.Rules {
+		for _, ref := range rule.BackendRefs {
+			refs = append(refs, ref.BackendObjectReference)
+		}
+	}
+	return refs
+}
 
 type gatewayUDPRouteInformer struct {
 	informers_v1a2.UDPRouteInformer
@@ -0,0 +1,253 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// gslbHintPrefix is the first label of the TXT records holding the hints of a cluster.
+const gslbHintPrefix = "_gslb-"
+
+// GSLBConfig is used for configuring a gslbSource.
+type GSLBConfig struct {
+	// Cluster is the unique name of the cluster of this instance.
+	Cluster string
+	// LeaseDuration is the time after which the hints of a cluster that stopped publishing are ignored.
+	LeaseDuration time.Duration
+	// WeightProperty is the provider specific property holding the weight of a record, e.g. aws/weight.
+	WeightProperty string
+}
+
+// gslbHint is published by every cluster for each of its GSLB hostnames.
+type gslbHint struct {
+	cluster    string
+	recordType string
+	targets    endpoint.Targets
+	ttl        endpoint.TTL
+	latency    int
+	expires    time.Time
+}
+
+func (h gslbHint) String() string {
+	return fmt.Sprintf("cluster=%s,type=%s,targets=%s,ttl=%d,latency=%d,expires=%d",
+		h.cluster, h.recordType, strings.Join(h.targets, ";"), h.ttl, h.latency, h.expires.Unix())
+}
+
+func parseGSLBHint(value string) (gslbHint, error) {
+	h := gslbHint{}
+	for _, field := range strings.Split(strings.Trim(value, "\""), ",") {
+		key, val, _ := strings.Cut(field, "=")
+		var err error
+		switch key {
+		case "cluster":
+			h.cluster = val
+		case "type":
+			h.recordType = val
+		case "targets":
+			if val != "" {
+				h.targets = strings.Split(val, ";")
+			}
+		case "ttl":
+			var ttl int64
+			ttl, err = strconv.ParseInt(val, 10, 64)
+			h.ttl = endpoint.TTL(ttl)
+		case "latency":
+			h.latency, err = strconv.Atoi(val)
+		case "expires":
+			var expires int64
+			expires, err = strconv.ParseInt(val, 10, 64)
+			h.expires = time.Unix(expires, 0)
+		}
+		if err != nil {
+			return gslbHint{}, fmt.Errorf("invalid %s in GSLB hint %q: %w", key, value, err)
+		}
+	}
+	if h.cluster == "" || h.recordType == "" {
+		return gslbHint{}, fmt.Errorf("incomplete GSLB hint %q", value)
+	}
+	return h, nil
+}
+
+// sameAs compares the hints ignoring the expiry.
+func (h gslbHint) sameAs(o gslbHint) bool {
+	return h.cluster == o.cluster && h.recordType == o.recordType && h.targets.Same(o.targets) && h.ttl == o.ttl && h.latency == o.latency
+}
+
+// gslbSource is a Source that coordinates the records of GSLB hostnames across clusters.
+// Every cluster publishes its targets and latency hint per hostname as TXT record,
+// and the live cluster with the lowest name computes weighted records from all hints.
+// All clusters must use the same owner ID, so that they can take over from each other.
+type gslbSource struct {
+	source Source
+	cfg    GSLBConfig
+}
+
+// NewGSLBSource creates a new gslbSource wrapping the provided Source.
+// If no cluster is configured, the GSLB annotations are ignored.
+func NewGSLBSource(source Source, cfg GSLBConfig) Source {
+	return &gslbSource{source: source, cfg: cfg}
+}
+
+// Endpoints collects endpoints from its wrapped source and replaces the GSLB endpoints by
+// the hints of this cluster, the hints of all other live clusters and the weighted records.
+func (gs *gslbSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := gs.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current, _ := ctx.Value(provider.RecordsContextKey).([]*endpoint.Endpoint)
+	now := time.Now()
+
+	// hints by hostname and cluster, as currently published
+	hints := map[string]map[string]gslbHint{}
+	live := map[string]bool{gs.cfg.Cluster: true}
+	for _, ep := range current {
+		if ep.RecordType != endpoint.RecordTypeTXT || !strings.HasPrefix(ep.DNSName, gslbHintPrefix) || len(ep.Targets) == 0 {
+			continue
+		}
+		hint, err := parseGSLBHint(ep.Targets[0])
+		if err != nil {
+			log.Warnf("Ignoring GSLB hint %s: %v", ep.DNSName, err)
+			continue
+		}
+		if hint.cluster != gs.cfg.Cluster && !hint.expires.After(now) {
+			log.Debugf("Ignoring expired GSLB hint %s", ep.DNSName)
+			continue
+		}
+		_, hostname, _ := strings.Cut(ep.DNSName, ".")
+		if hints[hostname] == nil {
+			hints[hostname] = map[string]gslbHint{}
+		}
+		hints[hostname][hint.cluster] = hint
+		live[hint.cluster] = true
+	}
+
+	result := []*endpoint.Endpoint{}
+	own := map[string]bool{}
+	for _, ep := range endpoints {
+		gslb, _ := ep.GetProviderSpecificProperty(GSLBKey)
+		latency, _ := ep.GetProviderSpecificProperty(GSLBLatencyKey)
+		ep.DeleteProviderSpecificProperty(GSLBKey)
+		ep.DeleteProviderSpecificProperty(GSLBLatencyKey)
+		if gslb != "true" || gs.cfg.Cluster == "" {
+			result = append(result, ep)
+			continue
+		}
+
+		hint := gslbHint{cluster: gs.cfg.Cluster, recordType: ep.RecordType, targets: ep.Targets, ttl: ep.RecordTTL, expires: now.Add(gs.cfg.LeaseDuration)}
+		if latency != "" {
+			if hint.latency, err = strconv.Atoi(latency); err != nil {
+				log.Warnf("Ignoring invalid GSLB latency %q of %s", latency, ep.DNSName)
+			}
+		}
+		// renew the lease only after half of it has passed, to avoid updating the hint on every synchronization
+		if published, ok := hints[ep.DNSName][gs.cfg.Cluster]; ok && published.sameAs(hint) && published.expires.Sub(now) > gs.cfg.LeaseDuration/2 {
+			hint.expires = published.expires
+		}
+		if hints[ep.DNSName] == nil {
+			hints[ep.DNSName] = map[string]gslbHint{}
+		}
+		hints[ep.DNSName][gs.cfg.Cluster] = hint
+		own[ep.DNSName] = true
+	}
+
+	if gs.cfg.Cluster == "" {
+		return result, nil
+	}
+
+	leader := gs.cfg.Cluster
+	for cluster := range live {
+		if cluster < leader {
+			leader = cluster
+		}
+	}
+
+	for hostname, clusters := range hints {
+		// hints of this cluster for hostnames it no longer serves are dropped
+		if !own[hostname] {
+			delete(clusters, gs.cfg.Cluster)
+		}
+		if len(clusters) == 0 {
+			continue
+		}
+		for _, hint := range clusters {
+			result = append(result, endpoint.NewEndpoint(gslbHintPrefix+hint.cluster+"."+hostname, endpoint.RecordTypeTXT, hint.String()))
+		}
+		if leader == gs.cfg.Cluster {
+			result = append(result, gs.weightedEndpoints(hostname, clusters)...)
+		} else {
+			result = append(result, keepWeightedEndpoints(hostname, current)...)
+		}
+	}
+
+	return result, nil
+}
+
+// weightedEndpoints returns one record per cluster, weighted inversely proportional to the cluster's latency.
+func (gs *gslbSource) weightedEndpoints(hostname string, clusters map[string]gslbHint) []*endpoint.Endpoint {
+	names := make([]string, 0, len(clusters))
+	inverseSum := 0.0
+	for name, hint := range clusters {
+		if len(hint.targets) == 0 {
+			continue
+		}
+		names = append(names, name)
+		inverseSum += 1 / math.Max(float64(hint.latency), 1)
+	}
+	sort.Strings(names)
+
+	endpoints := make([]*endpoint.Endpoint, 0, len(names))
+	for _, name := range names {
+		hint := clusters[name]
+		weight := int64(math.Max(math.Round(100/math.Max(float64(hint.latency), 1)/inverseSum), 1))
+		endpoints = append(endpoints, endpoint.NewEndpointWithTTL(hostname, hint.recordType, hint.ttl, hint.targets...).
+			WithSetIdentifier(name).
+			WithProviderSpecific(gs.cfg.WeightProperty, strconv.FormatInt(weight, 10)))
+	}
+	return endpoints
+}
+
+// keepWeightedEndpoints returns the weighted records currently published by the leader,
+// so that they are not deleted by the other clusters sharing the owner ID.
+func keepWeightedEndpoints(hostname string, current []*endpoint.Endpoint) []*endpoint.Endpoint {
+	var endpoints []*endpoint.Endpoint
+	for _, ep := range current {
+		if ep.DNSName != hostname || ep.SetIdentifier == "" || ep.RecordType == endpoint.RecordTypeTXT {
+			continue
+		}
+		kept := endpoint.NewEndpointWithTTL(ep.DNSName, ep.RecordType, ep.RecordTTL, ep.Targets...).WithSetIdentifier(ep.SetIdentifier)
+		kept.ProviderSpecific = append(endpoint.ProviderSpecific{}, ep.ProviderSpecific...)
+		endpoints = append(endpoints, kept)
+	}
+	return endpoints
+}
+
+func (gs *gslbSource) AddEventHandler(ctx context.Context, handler func()) {
+	gs.source.AddEventHandler(ctx, handler)
+}
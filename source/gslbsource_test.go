@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+func gslbEndpoint(latency string, targets ...string) *endpoint.Endpoint {
+	ep := endpoint.NewEndpointWithTTL("app.example.com", endpoint.RecordTypeA, 60, targets...).WithProviderSpecific(GSLBKey, "true")
+	if latency != "" {
+		ep = ep.WithProviderSpecific(GSLBLatencyKey, latency)
+	}
+	return ep
+}
+
+func hintRecord(hint gslbHint) *endpoint.Endpoint {
+	return endpoint.NewEndpoint(gslbHintPrefix+hint.cluster+".app.example.com", endpoint.RecordTypeTXT, "\""+hint.String()+"\"")
+}
+
+func gslbEndpoints(t *testing.T, cluster string, desired, current []*endpoint.Endpoint) map[string]*endpoint.Endpoint {
+	t.Helper()
+	src := NewGSLBSource(NewEchoSource(desired), GSLBConfig{Cluster: cluster, LeaseDuration: 10 * time.Minute, WeightProperty: "aws/weight"})
+	ctx := context.WithValue(context.Background(), provider.RecordsContextKey, current)
+	endpoints, err := src.Endpoints(ctx)
+	require.NoError(t, err)
+
+	byKey := map[string]*endpoint.Endpoint{}
+	for _, ep := range endpoints {
+		byKey[ep.DNSName+"/"+ep.RecordType+"/"+ep.SetIdentifier] = ep
+	}
+	return byKey
+}
+
+func TestParseGSLBHint(t *testing.T) {
+	hint := gslbHint{cluster: "eu", recordType: "A", targets: endpoint.Targets{"1.1.1.1", "2.2.2.2"}, ttl: 60, latency: 20, expires: time.Unix(1700000000, 0)}
+	parsed, err := parseGSLBHint("\"" + hint.String() + "\"")
+	require.NoError(t, err)
+	assert.Equal(t, hint, parsed)
+
+	_, err = parseGSLBHint("cluster=eu")
+	assert.Error(t, err)
+	_, err = parseGSLBHint("cluster=eu,type=A,latency=fast")
+	assert.Error(t, err)
+}
+
+func TestGSLBSourceLeader(t *testing.T) {
+	now := time.Now()
+	us := gslbHint{cluster: "us", recordType: "A", targets: endpoint.Targets{"2.2.2.2"}, ttl: 60, latency: 60, expires: now.Add(time.Minute)}
+	expired := gslbHint{cluster: "asia", recordType: "A", targets: endpoint.Targets{"3.3.3.3"}, ttl: 60, expires: now.Add(-time.Minute)}
+
+	endpoints := gslbEndpoints(t, "eu",
+		[]*endpoint.Endpoint{
+			gslbEndpoint("20", "1.1.1.1"),
+			endpoint.NewEndpoint("other.example.com", endpoint.RecordTypeA, "4.4.4.4"),
+		},
+		[]*endpoint.Endpoint{hintRecord(us), hintRecord(expired)},
+	)
+
+	require.Len(t, endpoints, 5)
+	assert.Contains(t, endpoints, "other.example.com/A/")
+	assert.Contains(t, endpoints, "_gslb-eu.app.example.com/TXT/")
+	assert.Contains(t, endpoints, "_gslb-us.app.example.com/TXT/")
+	assert.NotContains(t, endpoints, "_gslb-asia.app.example.com/TXT/")
+	assert.NotContains(t, endpoints, "app.example.com/A/")
+
+	eu := endpoints["app.example.com/A/eu"]
+	require.NotNil(t, eu)
+	assert.Equal(t, endpoint.Targets{"1.1.1.1"}, eu.Targets)
+	assert.Equal(t, endpoint.TTL(60), eu.RecordTTL)
+	weight, _ := eu.GetProviderSpecificProperty("aws/weight")
+	assert.Equal(t, "75", weight)
+
+	usEp := endpoints["app.example.com/A/us"]
+	require.NotNil(t, usEp)
+	assert.Equal(t, endpoint.Targets{"2.2.2.2"}, usEp.Targets)
+	weight, _ = usEp.GetProviderSpecificProperty("aws/weight")
+	assert.Equal(t, "25", weight)
+}
+
+func TestGSLBSourceFollower(t *testing.T) {
+	now := time.Now()
+	eu := gslbHint{cluster: "eu", recordType: "A", targets: endpoint.Targets{"1.1.1.1"}, ttl: 60, expires: now.Add(time.Minute)}
+	weighted := endpoint.NewEndpointWithTTL("app.example.com", endpoint.RecordTypeA, 60, "1.1.1.1").WithSetIdentifier("eu").WithProviderSpecific("aws/weight", "50")
+
+	endpoints := gslbEndpoints(t, "us",
+		[]*endpoint.Endpoint{gslbEndpoint("", "2.2.2.2")},
+		[]*endpoint.Endpoint{hintRecord(eu), weighted},
+	)
+
+	// the follower publishes its hint and keeps the records of the leader
+	require.Len(t, endpoints, 3)
+	assert.Contains(t, endpoints, "_gslb-eu.app.example.com/TXT/")
+	assert.Contains(t, endpoints, "_gslb-us.app.example.com/TXT/")
+	kept := endpoints["app.example.com/A/eu"]
+	require.NotNil(t, kept)
+	assert.Equal(t, endpoint.Targets{"1.1.1.1"}, kept.Targets)
+	weight, _ := kept.GetProviderSpecificProperty("aws/weight")
+	assert.Equal(t, "50", weight)
+}
+
+func TestGSLBSourceLeaseRenewal(t *testing.T) {
+	now := time.Now()
+	published := gslbHint{cluster: "eu", recordType: "A", targets: endpoint.Targets{"1.1.1.1"}, ttl: 60, expires: now.Add(8 * time.Minute).Truncate(time.Second)}
+
+	// the published hint is kept while more than half of the lease is left
+	endpoints := gslbEndpoints(t, "eu", []*endpoint.Endpoint{gslbEndpoint("", "1.1.1.1")}, []*endpoint.Endpoint{hintRecord(published)})
+	assert.Equal(t, endpoint.Targets{published.String()}, endpoints["_gslb-eu.app.example.com/TXT/"].Targets)
+
+	published.expires = now.Add(2 * time.Minute).Truncate(time.Second)
+	endpoints = gslbEndpoints(t, "eu", []*endpoint.Endpoint{gslbEndpoint("", "1.1.1.1")}, []*endpoint.Endpoint{hintRecord(published)})
+	assert.NotEqual(t, endpoint.Targets{published.String()}, endpoints["_gslb-eu.app.example.com/TXT/"].Targets)
+
+	// the hint is removed once the hostname is no longer served
+	endpoints = gslbEndpoints(t, "eu", nil, []*endpoint.Endpoint{hintRecord(published)})
+	assert.Empty(t, endpoints)
+}
+
+func TestGSLBSourceDisabled(t *testing.T) {
+	endpoints := gslbEndpoints(t, "", []*endpoint.Endpoint{gslbEndpoint("20", "1.1.1.1")}, nil)
+
+	require.Len(t, endpoints, 1)
+	ep := endpoints["app.example.com/A/"]
+	require.NotNil(t, ep)
+	assert.Empty(t, ep.ProviderSpecific)
+}
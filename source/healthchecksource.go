@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/pkg/healthcheck"
+)
+
+// HealthProber reports the health of probed targets.
+type HealthProber interface {
+	Healthy(check healthcheck.Check, hostname, target string) bool
+}
+
+// healthCheckSource is a Source that withholds targets failing the health check annotated on their resource.
+type healthCheckSource struct {
+	source Source
+	prober HealthProber
+}
+
+// NewHealthCheckSource creates a new healthCheckSource wrapping the provided Source.
+// If prober is nil, health checks are ignored.
+func NewHealthCheckSource(source Source, prober HealthProber) Source {
+	return &healthCheckSource{source: source, prober: prober}
+}
+
+// Endpoints collects endpoints from its wrapped source and removes unhealthy targets.
+// If all targets are unhealthy, the failover targets are used if set, otherwise all targets are kept.
+func (hs *healthCheckSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := hs.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ep := range endpoints {
+		value, ok := ep.GetProviderSpecificProperty(HealthCheckKey)
+		// the property is only meant for this source and must not reach the provider
+		ep.DeleteProviderSpecificProperty(HealthCheckKey)
+		if !ok || hs.prober == nil {
+			continue
+		}
+		check, err := healthcheck.ParseCheck(value)
+		if err != nil {
+			log.Warnf("Ignoring invalid health check of %s: %v", ep.DNSName, err)
+			continue
+		}
+
+		healthy := endpoint.Targets{}
+		for _, target := range ep.Targets {
+			if hs.prober.Healthy(check, ep.DNSName, target) {
+				healthy = append(healthy, target)
+			}
+		}
+		if len(healthy) == len(ep.Targets) {
+			continue
+		}
+
+		if len(healthy) > 0 {
+			log.Infof("Withholding unhealthy targets of %s, keeping %v", ep.DNSName, healthy)
+			ep.Targets = healthy
+		} else if failoverTargets, ok := ep.GetProviderSpecificProperty(FailoverTargetsKey); ok && failoverTargets != "" {
+			log.Infof("Switching %s to failover targets %s", ep.DNSName, failoverTargets)
+			ep.Targets = splitTargets(failoverTargets)
+		} else {
+			log.Warnf("All targets of %s are unhealthy and no failover targets are set, keeping targets", ep.DNSName)
+		}
+	}
+
+	return endpoints, nil
+}
+
+func (hs *healthCheckSource) AddEventHandler(ctx context.Context, handler func()) {
+	hs.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/pkg/healthcheck"
+)
+
+// fakeProber reports the targets in unhealthy as unhealthy.
+type fakeProber struct {
+	unhealthy map[string]bool
+	checks    []healthcheck.Check
+}
+
+func (p *fakeProber) Healthy(check healthcheck.Check, hostname, target string) bool {
+	p.checks = append(p.checks, check)
+	return !p.unhealthy[target]
+}
+
+func TestHealthCheckSource(t *testing.T) {
+	for _, tc := range []struct {
+		title    string
+		check    string
+		failover string
+		targets  endpoint.Targets
+		expected endpoint.Targets
+	}{
+		{
+			title:    "no health check",
+			targets:  endpoint.Targets{"1.1.1.1", "2.2.2.2"},
+			expected: endpoint.Targets{"1.1.1.1", "2.2.2.2"},
+		},
+		{
+			title:    "unhealthy target is withheld",
+			check:    "https://:8443/healthz",
+			targets:  endpoint.Targets{"1.1.1.1", "2.2.2.2"},
+			expected: endpoint.Targets{"2.2.2.2"},
+		},
+		{
+			title:    "all unhealthy switches to failover targets",
+			check:    "tcp://:443",
+			failover: "9.9.9.9",
+			targets:  endpoint.Targets{"1.1.1.1", "3.3.3.3"},
+			expected: endpoint.Targets{"9.9.9.9"},
+		},
+		{
+			title:    "all unhealthy without failover targets keeps targets",
+			check:    "tcp://:443",
+			targets:  endpoint.Targets{"1.1.1.1", "3.3.3.3"},
+			expected: endpoint.Targets{"1.1.1.1", "3.3.3.3"},
+		},
+		{
+			title:    "invalid check is ignored",
+			check:    "udp://:53",
+			targets:  endpoint.Targets{"1.1.1.1"},
+			expected: endpoint.Targets{"1.1.1.1"},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			ep := endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, tc.targets...)
+			if tc.check != "" {
+				ep = ep.WithProviderSpecific(HealthCheckKey, tc.check)
+			}
+			if tc.failover != "" {
+				ep = ep.WithProviderSpecific(FailoverTargetsKey, tc.failover)
+			}
+
+			prober := &fakeProber{unhealthy: map[string]bool{"1.1.1.1": true, "3.3.3.3": true}}
+			endpoints, err := NewHealthCheckSource(NewEchoSource([]*endpoint.Endpoint{ep}), prober).Endpoints(context.Background())
+			require.NoError(t, err)
+			require.Len(t, endpoints, 1)
+			assert.Equal(t, tc.expected, endpoints[0].Targets)
+			_, ok := endpoints[0].GetProviderSpecificProperty(HealthCheckKey)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestHealthCheckSourceWithoutProber(t *testing.T) {
+	ep := endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.1.1.1").WithProviderSpecific(HealthCheckKey, "tcp://:443")
+
+	endpoints, err := NewHealthCheckSource(NewEchoSource([]*endpoint.Endpoint{ep}), nil).Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, endpoint.Targets{"1.1.1.1"}, endpoints[0].Targets)
+	assert.Empty(t, endpoints[0].ProviderSpecific)
+}
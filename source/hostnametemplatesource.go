@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// hostnameOverride holds the TTL and targets of a hostname given in the hostname-overrides annotation.
+type hostnameOverride struct {
+	TTL     *int64   `json:"ttl,omitempty"`
+	Targets []string `json:"targets,omitempty"`
+}
+
+// hostnameTemplateData is the data of the templates in the hostname annotations, taken from the resource label.
+type hostnameTemplateData struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// hostnameTemplateSource is a Source that expands the templates in the hostnames of the hostname annotation,
+// e.g. `{{ .Name }}.example.com`, and applies the per-hostname TTL and targets of the hostname-overrides annotation.
+type hostnameTemplateSource struct {
+	source Source
+}
+
+// NewHostnameTemplateSource creates a new hostnameTemplateSource wrapping the provided Source.
+func NewHostnameTemplateSource(source Source) Source {
+	return &hostnameTemplateSource{source: source}
+}
+
+// Endpoints collects endpoints from its wrapped source, expands their hostnames and applies the overrides.
+func (hs *hostnameTemplateSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := hs.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	overridden := map[string]bool{}
+	for _, ep := range endpoints {
+		resource := ep.Labels[endpoint.ResourceLabelKey]
+		data := templateDataFromResource(resource)
+		if strings.Contains(ep.DNSName, "{{") {
+			hostname, err := executeHostnameTemplate(ep.DNSName, data)
+			if err != nil {
+				log.Warnf("Skipping hostname %s of %s: %v", ep.DNSName, resource, err)
+				continue
+			}
+			ep.DNSName = hostname
+		}
+
+		annotation, ok := ep.GetProviderSpecificProperty(HostnameOverridesKey)
+		if !ok {
+			result = append(result, ep)
+			continue
+		}
+		ep.DeleteProviderSpecificProperty(HostnameOverridesKey)
+		override, err := findHostnameOverride(annotation, ep.DNSName, data)
+		if err != nil {
+			log.Warnf("Ignoring the %s annotation of %s: %v", HostnameOverridesKey, resource, err)
+		}
+		if override == nil {
+			result = append(result, ep)
+			continue
+		}
+
+		ttl := ep.RecordTTL
+		if override.TTL != nil {
+			ttl = endpoint.TTL(*override.TTL)
+		}
+		if len(override.Targets) == 0 {
+			ep.RecordTTL = ttl
+			result = append(result, ep)
+			continue
+		}
+		// the records of the hostname are replaced by those of the overridden targets, once per resource
+		key := resource + "/" + ep.DNSName + "/" + ep.SetIdentifier
+		if overridden[key] {
+			continue
+		}
+		overridden[key] = true
+		result = append(result, endpointsForHostname(ep.DNSName, override.Targets, ttl, ep.ProviderSpecific, ep.SetIdentifier, resource)...)
+	}
+	return result, nil
+}
+
+func (hs *hostnameTemplateSource) AddEventHandler(ctx context.Context, handler func()) {
+	hs.source.AddEventHandler(ctx, handler)
+}
+
+// templateDataFromResource parses a resource label of the form kind/namespace/name, or kind/name for cluster scoped resources.
+func templateDataFromResource(resource string) hostnameTemplateData {
+	parts := strings.SplitN(resource, "/", 3)
+	switch len(parts) {
+	case 3:
+		return hostnameTemplateData{Kind: parts[0], Namespace: parts[1], Name: parts[2]}
+	case 2:
+		return hostnameTemplateData{Kind: parts[0], Name: parts[1]}
+	default:
+		return hostnameTemplateData{}
+	}
+}
+
+func executeHostnameTemplate(text string, data hostnameTemplateData) (string, error) {
+	tmpl, err := parseTemplate(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing template: %w", err)
+	}
+	tmpl.Option("missingkey=error")
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("executing template: %w", err)
+	}
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(buf.String())), "."), nil
+}
+
+// findHostnameOverride returns the override of the hostname in the annotation, whose keys may be templates.
+func findHostnameOverride(annotation, hostname string, data hostnameTemplateData) (*hostnameOverride, error) {
+	var overrides map[string]hostnameOverride
+	if err := json.Unmarshal([]byte(annotation), &overrides); err != nil {
+		return nil, fmt.Errorf("parsing JSON: %w", err)
+	}
+	hostname = strings.TrimSuffix(strings.ToLower(hostname), ".")
+	for key, override := range overrides {
+		if strings.Contains(key, "{{") {
+			var err error
+			if key, err = executeHostnameTemplate(key, data); err != nil {
+				return nil, err
+			}
+		}
+		if strings.TrimSuffix(strings.ToLower(key), ".") == hostname {
+			return &override, nil
+		}
+	}
+	return nil, nil
+}
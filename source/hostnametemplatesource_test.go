@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+var _ Source = &hostnameTemplateSource{}
+
+func TestHostnameTemplateSource(t *testing.T) {
+	withResource := func(ep *endpoint.Endpoint, resource string) *endpoint.Endpoint {
+		ep.Labels[endpoint.ResourceLabelKey] = resource
+		return ep
+	}
+	overrides := `{"{{.Name}}.example.org": {"ttl": 60, "targets": ["5.6.7.8", "2001:db8::1"]}, "{{.Name}}.example.net": {"ttl": 120}}`
+
+	for _, tt := range []struct {
+		name     string
+		input    []*endpoint.Endpoint
+		expected []*endpoint.Endpoint
+	}{
+		{
+			name: "hostnames are expanded",
+			input: []*endpoint.Endpoint{
+				withResource(endpoint.NewEndpoint("{{.Name}}.example.com", endpoint.RecordTypeA, "1.2.3.4"), "ingress/default/foo"),
+				withResource(endpoint.NewEndpoint("{{.Name}}.{{.Namespace}}.example.org", endpoint.RecordTypeA, "1.2.3.4"), "service/prod/bar"),
+				withResource(endpoint.NewEndpoint("{{.Kind}}-{{.Name}}.example.com", endpoint.RecordTypeA, "1.2.3.4"), "node/Node1"),
+				withResource(endpoint.NewEndpoint("plain.example.com", endpoint.RecordTypeA, "1.2.3.4"), "ingress/default/foo"),
+			},
+			expected: []*endpoint.Endpoint{
+				withResource(endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4"), "ingress/default/foo"),
+				withResource(endpoint.NewEndpoint("bar.prod.example.org", endpoint.RecordTypeA, "1.2.3.4"), "service/prod/bar"),
+				withResource(endpoint.NewEndpoint("node-node1.example.com", endpoint.RecordTypeA, "1.2.3.4"), "node/Node1"),
+				withResource(endpoint.NewEndpoint("plain.example.com", endpoint.RecordTypeA, "1.2.3.4"), "ingress/default/foo"),
+			},
+		},
+		{
+			name: "invalid templates are skipped",
+			input: []*endpoint.Endpoint{
+				withResource(endpoint.NewEndpoint("{{.Missing}}.example.com", endpoint.RecordTypeA, "1.2.3.4"), "ingress/default/foo"),
+				withResource(endpoint.NewEndpoint("{{.Name.example.com", endpoint.RecordTypeA, "1.2.3.4"), "ingress/default/foo"),
+			},
+			expected: []*endpoint.Endpoint{},
+		},
+		{
+			name: "overrides are applied",
+			input: []*endpoint.Endpoint{
+				withResource(endpoint.NewEndpoint("{{.Name}}.example.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(HostnameOverridesKey, overrides), "ingress/default/foo"),
+				withResource(endpoint.NewEndpoint("{{.Name}}.example.org", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(HostnameOverridesKey, overrides), "ingress/default/foo"),
+				withResource(endpoint.NewEndpoint("{{.Name}}.example.org", endpoint.RecordTypeCNAME, "lb.example.com").WithProviderSpecific(HostnameOverridesKey, overrides), "ingress/default/foo"),
+				withResource(endpoint.NewEndpoint("foo.example.net", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(HostnameOverridesKey, overrides), "ingress/default/foo"),
+			},
+			expected: []*endpoint.Endpoint{
+				withResource(endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4"), "ingress/default/foo"),
+				withResource(endpoint.NewEndpointWithTTL("foo.example.org", endpoint.RecordTypeA, 60, "5.6.7.8"), "ingress/default/foo"),
+				withResource(endpoint.NewEndpointWithTTL("foo.example.org", endpoint.RecordTypeAAAA, 60, "2001:db8::1"), "ingress/default/foo"),
+				withResource(endpoint.NewEndpointWithTTL("foo.example.net", endpoint.RecordTypeA, 120, "1.2.3.4"), "ingress/default/foo"),
+			},
+		},
+		{
+			name: "invalid overrides are ignored",
+			input: []*endpoint.Endpoint{
+				withResource(endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(HostnameOverridesKey, "{"), "ingress/default/foo"),
+			},
+			expected: []*endpoint.Endpoint{
+				withResource(endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.4"), "ingress/default/foo"),
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoints, err := NewHostnameTemplateSource(NewEchoSource(tt.input)).Endpoints(context.Background())
+			require.NoError(t, err)
+			for _, ep := range endpoints {
+				assert.Empty(t, ep.ProviderSpecific)
+				ep.ProviderSpecific = nil
+			}
+			assert.True(t, testutils.SameEndpoints(tt.expected, endpoints), "expected %v, got %v", tt.expected, endpoints)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/idna"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// idnaProfile converts hostnames for lookups, but allows underscores, e.g. in _sip._tcp.bücher.example.com.
+var idnaProfile = idna.New(idna.MapForLookup(), idna.BidiRule(), idna.StrictDomainName(false))
+
+// idnSource is a Source that converts the internationalized hostnames of its wrapped source to punycode,
+// e.g. bücher.example.com to xn--bcher-kva.example.com, as required by the DNS providers.
+// The original hostname is kept in the unicode-hostname label.
+type idnSource struct {
+	source Source
+}
+
+// NewIDNSource creates a new idnSource wrapping the provided Source.
+func NewIDNSource(source Source) Source {
+	return &idnSource{source: source}
+}
+
+// Endpoints collects endpoints from its wrapped source and converts their hostnames and hostname targets to punycode.
+// Endpoints whose hostname is not a valid internationalized domain name are skipped.
+func (is *idnSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := is.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if !isASCII(ep.DNSName) {
+			hostname, err := toPunycode(ep.DNSName)
+			if err != nil {
+				countSkippedEndpoint(ep, skipReasonInvalidHostname).Warnf("Skipping endpoint %s of %s: invalid internationalized domain name: %v", ep.DNSName, ep.Labels[endpoint.ResourceLabelKey], err)
+				continue
+			}
+			log.Debugf("Converted hostname %s of %s to %s", ep.DNSName, ep.Labels[endpoint.ResourceLabelKey], hostname)
+			if ep.Labels == nil {
+				ep.Labels = endpoint.NewLabels()
+			}
+			ep.Labels[endpoint.UnicodeHostnameLabelKey] = ep.DNSName
+			ep.DNSName = hostname
+		}
+		if !hasHostnameTargets(ep.RecordType) {
+			result = append(result, ep)
+			continue
+		}
+		valid := true
+		for i, target := range ep.Targets {
+			if isASCII(target) {
+				continue
+			}
+			if ep.Targets[i], err = toPunycode(target); err != nil {
+				countSkippedEndpoint(ep, skipReasonInvalidTarget).WithField("found", target).Warnf("Skipping endpoint %s of %s: invalid internationalized domain name %s: %v", ep.DNSName, ep.Labels[endpoint.ResourceLabelKey], target, err)
+				valid = false
+				break
+			}
+		}
+		if valid {
+			result = append(result, ep)
+		}
+	}
+	return result, nil
+}
+
+func (is *idnSource) AddEventHandler(ctx context.Context, handler func()) {
+	is.source.AddEventHandler(ctx, handler)
+}
+
+// hasHostnameTargets returns true if the targets of the record type are hostnames.
+func hasHostnameTargets(recordType string) bool {
+	switch recordType {
+	case endpoint.RecordTypeCNAME, endpoint.RecordTypeNS, endpoint.RecordTypePTR:
+		return true
+	default:
+		return false
+	}
+}
+
+// toPunycode converts an internationalized hostname to punycode, keeping a leading wildcard and a trailing dot.
+func toPunycode(hostname string) (string, error) {
+	name, wildcard := strings.CutPrefix(hostname, "*.")
+	name, fqdn := strings.CutSuffix(name, ".")
+	ascii, err := idnaProfile.ToASCII(name)
+	if err != nil {
+		return "", err
+	}
+	if wildcard {
+		ascii = "*." + ascii
+	}
+	if fqdn {
+		ascii += "."
+	}
+	return ascii, nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestIDNSource(t *testing.T) {
+	endpoints := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "192.0.2.1"),
+		endpoint.NewEndpoint("Bücher.example.com", endpoint.RecordTypeA, "192.0.2.2"),
+		endpoint.NewEndpoint("*.münchen.example.com", endpoint.RecordTypeCNAME, "lb.bücher.example.com"),
+		endpoint.NewEndpoint("_sip._tcp.bücher.example.com", endpoint.RecordTypeSRV, "10 5 5060 sip.example.com"),
+		endpoint.NewEndpoint("invalid‍.example.com", endpoint.RecordTypeA, "192.0.2.3"),
+		endpoint.NewEndpoint("cname.example.com", endpoint.RecordTypeCNAME, "invalid‍.example.com"),
+	}
+
+	result, err := NewIDNSource(NewEchoSource(endpoints)).Endpoints(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result, 4)
+
+	assert.Equal(t, "app.example.com", result[0].DNSName)
+	assert.NotContains(t, result[0].Labels, endpoint.UnicodeHostnameLabelKey)
+
+	assert.Equal(t, "xn--bcher-kva.example.com", result[1].DNSName)
+	assert.Equal(t, "Bücher.example.com", result[1].Labels[endpoint.UnicodeHostnameLabelKey])
+
+	assert.Equal(t, "*.xn--mnchen-3ya.example.com", result[2].DNSName)
+	assert.Equal(t, endpoint.Targets{"lb.xn--bcher-kva.example.com"}, result[2].Targets)
+
+	assert.Equal(t, "_sip._tcp.xn--bcher-kva.example.com", result[3].DNSName)
+	assert.Equal(t, endpoint.Targets{"10 5 5060 sip.example.com"}, result[3].Targets)
+}
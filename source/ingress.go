@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	kubeinformers "k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
 	netinformers "k8s.io/client-go/informers/networking/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
@@ -62,13 +63,15 @@ type ingressSource struct {
 	combineFQDNAnnotation    bool
 	ignoreHostnameAnnotation bool
 	ingressInformer          netinformers.IngressInformer
+	serviceInformer          coreinformers.ServiceInformer
+	internalTargets          []string
 	ignoreIngressTLSSpec     bool
 	ignoreIngressRulesSpec   bool
 	labelSelector            labels.Selector
 }
 
 // NewIngressSource creates a new ingressSource with the given config.
-func NewIngressSource(ctx context.Context, kubeClient kubernetes.Interface, namespace, annotationFilter string, fqdnTemplate string, combineFqdnAnnotation bool, ignoreHostnameAnnotation bool, ignoreIngressTLSSpec bool, ignoreIngressRulesSpec bool, labelSelector labels.Selector, ingressClassNames []string) (Source, error) {
+func NewIngressSource(ctx context.Context, kubeClient kubernetes.Interface, namespace, annotationFilter string, fqdnTemplate string, combineFqdnAnnotation bool, ignoreHostnameAnnotation bool, ignoreIngressTLSSpec bool, ignoreIngressRulesSpec bool, labelSelector labels.Selector, ingressClassNames []string, internalTargets []string) (Source, error) {
 	tmpl, err := parseTemplate(fqdnTemplate)
 	if err != nil {
 		return nil, err
@@ -93,6 +96,8 @@ func NewIngressSource(ctx context.Context, kubeClient kubernetes.Interface, name
 	// Set resync period to 0, to prevent processing when nothing has changed.
 	informerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0, kubeinformers.WithNamespace(namespace))
 	ingressInformer := informerFactory.Networking().V1().Ingresses()
+	// The backend Services provide the cluster IPs of the internal hostnames.
+	serviceInformer := informerFactory.Core().V1().Services()
 
 	// Add default resource event handlers to properly initialize informer.
 	ingressInformer.Informer().AddEventHandler(
@@ -101,6 +106,7 @@ func NewIngressSource(ctx context.Context, kubeClient kubernetes.Interface, name
 			},
 		},
 	)
+	serviceInformer.Informer() // Register with factory before starting.
 
 	informerFactory.Start(ctx.Done())
 
@@ -118,6 +124,8 @@ func NewIngressSource(ctx context.Context, kubeClient kubernetes.Interface, name
 		combineFQDNAnnotation:    combineFqdnAnnotation,
 		ignoreHostnameAnnotation: ignoreHostnameAnnotation,
 		ingressInformer:          ingressInformer,
+		serviceInformer:          serviceInformer,
+		internalTargets:          internalTargets,
 		ignoreIngressTLSSpec:     ignoreIngressTLSSpec,
 		ignoreIngressRulesSpec:   ignoreIngressRulesSpec,
 		labelSelector:            labelSelector,
@@ -148,8 +156,12 @@ func (sc *ingressSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, e
 		// Check controller annotation to see if we are responsible.
 		controller, ok := ing.Annotations[controllerAnnotationKey]
 		if ok && controller != controllerAnnotationValue {
-			log.Debugf("Skipping ingress %s/%s because controller value does not match, found: %s, required: %s",
-				ing.Namespace, ing.Name, controller, controllerAnnotationValue)
+			countSkipped(ctx, skipReasonControllerMismatch).WithFields(log.Fields{
+				"filter":   controllerAnnotationKey,
+				"found":    controller,
+				"required": controllerAnnotationValue,
+			}).Debugf("Skipping ingress %s/%s because controller value does not match",
+				ing.Namespace, ing.Name)
 			continue
 		}
 
@@ -165,8 +177,12 @@ func (sc *ingressSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, e
 			ingEndpoints = append(ingEndpoints, iEndpoints...)
 		}
 
+		if !sc.ignoreHostnameAnnotation {
+			ingEndpoints = append(ingEndpoints, sc.endpointsFromInternalHostnames(ing)...)
+		}
+
 		if len(ingEndpoints) == 0 {
-			log.Debugf("No endpoints could be generated from ingress %s/%s", ing.Namespace, ing.Name)
+			countSkipped(ctx, skipReasonNoHostname).Debugf("No endpoints could be generated from ingress %s/%s", ing.Namespace, ing.Name)
 			continue
 		}
 
@@ -206,6 +222,42 @@ func (sc *ingressSource) endpointsFromTemplate(ing *networkv1.Ingress) ([]*endpo
 	return endpoints, nil
 }
 
+// endpointsFromInternalHostnames returns the endpoints of the internal-hostname annotation of the ingress,
+// falling back to the cluster IPs of its backend Services.
+func (sc *ingressSource) endpointsFromInternalHostnames(ing *networkv1.Ingress) []*endpoint.Endpoint {
+	if _, ok := ing.Annotations[internalHostnameAnnotationKey]; !ok {
+		return nil
+	}
+	resource := fmt.Sprintf("ingress/%s/%s", ing.Namespace, ing.Name)
+	ttl := getTTLFromAnnotations(ing.Annotations, resource)
+	providerSpecific, setIdentifier := getProviderSpecificAnnotations(ing.Annotations)
+
+	targets := getInternalTargets(ing.Annotations, sc.internalTargets, nil)
+	if len(targets) == 0 {
+		targets = clusterIPsOfServices(sc.serviceInformer.Lister(), ing.Namespace, ingressBackendServices(ing))
+	}
+	return endpointsForInternalHostnames(ing.Annotations, targets, ttl, providerSpecific, setIdentifier, resource)
+}
+
+// ingressBackendServices returns the names of the Services of the default backend and the rules of the ingress.
+func ingressBackendServices(ing *networkv1.Ingress) []string {
+	var names []string
+	if backend := ing.Spec.DefaultBackend; backend != nil && backend.Service != nil {
+		names = append(names, backend.Service.Name)
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service != nil {
+				names = append(names, path.Backend.Service.Name)
+			}
+		}
+	}
+	return names
+}
+
 // filterByAnnotations filters a list of ingresses by a given annotation selector.
 func (sc *ingressSource) filterByAnnotations(ingresses []*networkv1.Ingress) ([]*networkv1.Ingress, error) {
 	selector, err := getLabelSelector(sc.annotationFilter)
@@ -276,7 +328,7 @@ func (sc *ingressSource) filterByIngressClass(ingresses []*networkv1.Ingress) ([
 
 func (sc *ingressSource) setDualstackLabel(ingress *networkv1.Ingress, endpoints []*endpoint.Endpoint) {
 	val, ok := ingress.Annotations[ALBDualstackAnnotationKey]
-	if ok && val == ALBDualstackAnnotationValue {
+	if (ok && val == ALBDualstackAnnotationValue) || ingress.Annotations[dualstackAnnotationKey] == "true" {
 		log.Debugf("Adding dualstack label to ingress %s/%s.", ingress.Namespace, ingress.Name)
 		for _, ep := range endpoints {
 			ep.Labels[endpoint.DualstackLabelKey] = "true"
@@ -23,6 +23,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	v1 "k8s.io/api/core/v1"
 	networkv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -66,6 +67,7 @@ func (suite *IngressSuite) SetupTest() {
 		false,
 		labels.Everything(),
 		[]string{},
+		nil,
 	)
 	suite.NoError(err, "should initialize ingress source")
 }
@@ -162,6 +164,7 @@ func TestNewIngressSource(t *testing.T) {
 				false,
 				labels.Everything(),
 				ti.ingressClassNames,
+				nil,
 			)
 			if ti.expectError {
 				assert.Error(t, err)
@@ -1416,6 +1419,7 @@ func testIngressEndpoints(t *testing.T) {
 				ti.ignoreIngressRulesSpec,
 				ti.ingressLabelSelector,
 				ti.ingressClassNames,
+				nil,
 			)
 			// Informer cache has all of the ingresses. Retrieve and validate their endpoints.
 			res, err := source.Endpoints(context.Background())
@@ -1429,6 +1433,97 @@ func testIngressEndpoints(t *testing.T) {
 	}
 }
 
+func TestIngressSourceInternalHostname(t *testing.T) {
+	t.Parallel()
+
+	webEndpoint := func(dnsName, recordType, target string) *endpoint.Endpoint {
+		ep := endpoint.NewEndpoint(dnsName, recordType, target)
+		ep.Labels[endpoint.ResourceLabelKey] = "ingress/default/web"
+		return ep
+	}
+
+	for _, tc := range []struct {
+		title           string
+		annotations     map[string]string
+		internalTargets []string
+		expected        []*endpoint.Endpoint
+	}{
+		{
+			title: "cluster IP of the backend service",
+			annotations: map[string]string{
+				internalHostnameAnnotationKey: "web.internal.example.org",
+			},
+			expected: []*endpoint.Endpoint{
+				webEndpoint("web.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+				webEndpoint("web.internal.example.org", endpoint.RecordTypeA, "10.0.0.10"),
+			},
+		},
+		{
+			title: "configured internal targets",
+			annotations: map[string]string{
+				internalHostnameAnnotationKey: "web.internal.example.org",
+			},
+			internalTargets: []string{"internal-lb.example.org"},
+			expected: []*endpoint.Endpoint{
+				webEndpoint("web.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+				webEndpoint("web.internal.example.org", endpoint.RecordTypeCNAME, "internal-lb.example.org"),
+			},
+		},
+		{
+			title: "internal-target annotation",
+			annotations: map[string]string{
+				internalHostnameAnnotationKey: "web.internal.example.org",
+				internalTargetAnnotationKey:   "10.1.0.1",
+			},
+			internalTargets: []string{"internal-lb.example.org"},
+			expected: []*endpoint.Endpoint{
+				webEndpoint("web.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+				webEndpoint("web.internal.example.org", endpoint.RecordTypeA, "10.1.0.1"),
+			},
+		},
+		{
+			title: "no internal-hostname annotation",
+			expected: []*endpoint.Endpoint{
+				webEndpoint("web.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			fakeClient := fake.NewSimpleClientset()
+			_, err := fakeClient.CoreV1().Services("default").Create(context.Background(), &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+				Spec:       v1.ServiceSpec{ClusterIP: "10.0.0.10"},
+			}, metav1.CreateOptions{})
+			require.NoError(t, err)
+
+			ing := fakeIngress{
+				name:        "web",
+				namespace:   "default",
+				dnsnames:    []string{"web.example.org"},
+				ips:         []string{"1.2.3.4"},
+				annotations: tc.annotations,
+			}.Ingress()
+			ing.Spec.Rules[0].HTTP = &networkv1.HTTPIngressRuleValue{
+				Paths: []networkv1.HTTPIngressPath{{
+					Backend: networkv1.IngressBackend{Service: &networkv1.IngressServiceBackend{Name: "web"}},
+				}},
+			}
+			_, err = fakeClient.NetworkingV1().Ingresses("default").Create(context.Background(), ing, metav1.CreateOptions{})
+			require.NoError(t, err)
+
+			source, err := NewIngressSource(context.TODO(), fakeClient, "", "", "", false, false, false, false, labels.Everything(), nil, tc.internalTargets)
+			require.NoError(t, err)
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
 // ingress specific helper functions
 type fakeIngress struct {
 	dnsnames         []string
@@ -141,8 +141,12 @@ func (sc *gatewaySource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, e
 		// Check controller annotation to see if we are responsible.
 		controller, ok := gateway.Annotations[controllerAnnotationKey]
 		if ok && controller != controllerAnnotationValue {
-			log.Debugf("Skipping gateway %s/%s because controller value does not match, found: %s, required: %s",
-				gateway.Namespace, gateway.Name, controller, controllerAnnotationValue)
+			countSkipped(ctx, skipReasonControllerMismatch).WithFields(log.Fields{
+				"filter":   controllerAnnotationKey,
+				"found":    controller,
+				"required": controllerAnnotationValue,
+			}).Debugf("Skipping gateway %s/%s because controller value does not match",
+				gateway.Namespace, gateway.Name)
 			continue
 		}
 
@@ -166,7 +170,7 @@ func (sc *gatewaySource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, e
 		}
 
 		if len(gwHostnames) == 0 {
-			log.Debugf("No hostnames could be generated from gateway %s/%s", gateway.Namespace, gateway.Name)
+			countSkipped(ctx, skipReasonNoHostname).Debugf("No hostnames could be generated from gateway %s/%s", gateway.Namespace, gateway.Name)
 			continue
 		}
 
@@ -176,7 +180,7 @@ func (sc *gatewaySource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, e
 		}
 
 		if len(gwEndpoints) == 0 {
-			log.Debugf("No endpoints could be generated from gateway %s/%s", gateway.Namespace, gateway.Name)
+			countSkipped(ctx, skipReasonNoHostname).Debugf("No endpoints could be generated from gateway %s/%s", gateway.Namespace, gateway.Name)
 			continue
 		}
 
@@ -139,8 +139,12 @@ func (sc *virtualServiceSource) Endpoints(ctx context.Context) ([]*endpoint.Endp
 		// Check controller annotation to see if we are responsible.
 		controller, ok := virtualService.Annotations[controllerAnnotationKey]
 		if ok && controller != controllerAnnotationValue {
-			log.Debugf("Skipping VirtualService %s/%s because controller value does not match, found: %s, required: %s",
-				virtualService.Namespace, virtualService.Name, controller, controllerAnnotationValue)
+			countSkipped(ctx, skipReasonControllerMismatch).WithFields(log.Fields{
+				"filter":   controllerAnnotationKey,
+				"found":    controller,
+				"required": controllerAnnotationValue,
+			}).Debugf("Skipping VirtualService %s/%s because controller value does not match",
+				virtualService.Namespace, virtualService.Name)
 			continue
 		}
 
@@ -164,7 +168,7 @@ func (sc *virtualServiceSource) Endpoints(ctx context.Context) ([]*endpoint.Endp
 		}
 
 		if len(gwEndpoints) == 0 {
-			log.Debugf("No endpoints could be generated from VirtualService %s/%s", virtualService.Namespace, virtualService.Name)
+			countSkipped(ctx, skipReasonNoHostname).Debugf("No endpoints could be generated from VirtualService %s/%s", virtualService.Namespace, virtualService.Name)
 			continue
 		}
 
@@ -145,7 +145,7 @@ func (sc *kongTCPIngressSource) Endpoints(ctx context.Context) ([]*endpoint.Endp
 			return nil, err
 		}
 		if len(ingressEndpoints) == 0 {
-			log.Debugf("No endpoints could be generated from Host %s", fullname)
+			countSkipped(ctx, skipReasonNoHostname).Debugf("No endpoints could be generated from Host %s", fullname)
 			continue
 		}
 
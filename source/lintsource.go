@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/endpoint/validation"
+)
+
+var lintWarnings = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "external_dns",
+		Subsystem: "source",
+		Name:      "lint_warnings",
+		Help:      "Number of warnings about endpoints likely rejected by the provider, by reason.",
+	},
+	[]string{"reason"},
+)
+
+func init() {
+	prometheus.MustRegister(lintWarnings)
+}
+
+// lintSource is a Source that warns about the endpoints of its wrapped source that the provider likely rejects,
+// e.g. because of invalid hostnames or targets. The endpoints are passed on unchanged.
+type lintSource struct {
+	source   Source
+	linter   *validation.Linter
+	reported map[string]bool
+}
+
+// NewLintSource creates a new lintSource wrapping the provided Source.
+func NewLintSource(source Source, linter *validation.Linter) Source {
+	return &lintSource{source: source, linter: linter, reported: map[string]bool{}}
+}
+
+// Endpoints collects endpoints from its wrapped source and lints them.
+// Each warning is logged once, until the endpoint is fixed.
+func (ls *lintSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ls.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reported := map[string]bool{}
+	counts := map[string]float64{}
+	for _, ep := range endpoints {
+		for _, w := range ls.linter.Lint(ep) {
+			counts[w.Reason]++
+			resource := ep.Labels[endpoint.ResourceLabelKey]
+			key := resource + "/" + ep.DNSName + "/" + ep.RecordType + "/" + w.String()
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+			entry := log.WithFields(log.Fields{
+				"provider": ls.linter.Provider(),
+				"reason":   w.Reason,
+				"resource": resource,
+				"record":   ep.DNSName,
+				"type":     ep.RecordType,
+			})
+			if ls.reported[key] {
+				entry.Debugf("Endpoint likely rejected by the provider: %s", w)
+			} else {
+				entry.Warnf("Endpoint likely rejected by the provider: %s", w)
+			}
+		}
+	}
+	ls.reported = reported
+
+	lintWarnings.Reset()
+	for reason, count := range counts {
+		lintWarnings.WithLabelValues(reason).Set(count)
+	}
+	return endpoints, nil
+}
+
+func (ls *lintSource) AddEventHandler(ctx context.Context, handler func()) {
+	ls.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/endpoint/validation"
+)
+
+func TestLintSource(t *testing.T) {
+	endpoints := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "192.0.2.1"),
+		endpoint.NewEndpoint("app!.example.com", endpoint.RecordTypeA, "192.0.2.2"),
+		endpoint.NewEndpointWithTTL("lb.example.com", endpoint.RecordTypeCNAME, 30, "192.0.2.3"),
+	}
+	src := NewLintSource(NewEchoSource(endpoints), validation.NewLinter("cloudflare"))
+
+	result, err := src.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, endpoints, result, "endpoints must be passed on unchanged")
+
+	assert.InDelta(t, 1, testutil.ToFloat64(lintWarnings.WithLabelValues(validation.ReasonLabelCharset)), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(lintWarnings.WithLabelValues(validation.ReasonInvalidTarget)), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(lintWarnings.WithLabelValues(validation.ReasonTTLOutOfRange)), 0)
+
+	src = NewLintSource(NewEchoSource(endpoints[:1]), validation.NewLinter("cloudflare"))
+	_, err = src.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, testutil.CollectAndCount(lintWarnings), "warnings of fixed endpoints must be cleared")
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// The reasons for skipping endpoints in the external_dns_source_skipped_endpoints_total metric.
+const (
+	skipReasonNoHostname         = endpoint.SkipReasonNoHostname
+	skipReasonFiltered           = endpoint.SkipReasonFiltered
+	skipReasonInvalidHostname    = endpoint.SkipReasonInvalidHostname
+	skipReasonInvalidTarget      = endpoint.SkipReasonInvalidTarget
+	skipReasonControllerMismatch = endpoint.SkipReasonControllerMismatch
+)
+
+// unknownSourceName is the source of skipped endpoints not attributed to a source.
+const unknownSourceName = "unknown"
+
+var (
+	sourceEndpoints = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "source",
+			Name:      "endpoints",
+			Help:      "Number of endpoints produced by each source.",
+		},
+		[]string{"source"},
+	)
+	sourceSkippedEndpoints = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "source",
+			Name:      "skipped_endpoints_total",
+			Help:      "Number of endpoints skipped by source and reason, counted at every synchronization.",
+		},
+		[]string{"source", "reason"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(sourceEndpoints)
+	prometheus.MustRegister(sourceSkippedEndpoints)
+}
+
+// sourceNameKey is the context key of the name of the source collecting the endpoints.
+type sourceNameKey struct{}
+
+// resourceSources remembers the source producing the endpoints of each resource, so that the endpoints
+// skipped by the sources wrapping the sources are counted for the source producing them.
+type resourceSources struct {
+	mu        sync.RWMutex
+	resources map[string]map[string]bool
+}
+
+var endpointSources = &resourceSources{resources: map[string]map[string]bool{}}
+
+func (rs *resourceSources) set(name string, endpoints []*endpoint.Endpoint) {
+	resources := make(map[string]bool, len(endpoints))
+	for _, ep := range endpoints {
+		if resource := ep.Labels[endpoint.ResourceLabelKey]; resource != "" {
+			resources[resource] = true
+		}
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.resources[name] = resources
+}
+
+func (rs *resourceSources) source(ep *endpoint.Endpoint) string {
+	resource := ep.Labels[endpoint.ResourceLabelKey]
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	for name, resources := range rs.resources {
+		if resources[resource] {
+			return name
+		}
+	}
+	return unknownSourceName
+}
+
+// countSkipped counts a resource skipped by the source collecting the endpoints with the context,
+// and returns the log entry to tell why.
+func countSkipped(ctx context.Context, reason string) *log.Entry {
+	name, ok := ctx.Value(sourceNameKey{}).(string)
+	if !ok {
+		name = unknownSourceName
+	}
+	sourceSkippedEndpoints.WithLabelValues(name, reason).Inc()
+	return endpoint.Skipped(reason).WithField("source", name)
+}
+
+// countSkippedEndpoint counts an endpoint skipped after it was produced, for the source that produced it,
+// and returns the log entry to tell why.
+func countSkippedEndpoint(ep *endpoint.Endpoint, reason string) *log.Entry {
+	name := endpointSources.source(ep)
+	sourceSkippedEndpoints.WithLabelValues(name, reason).Inc()
+	return endpoint.Skipped(reason).WithFields(log.Fields{
+		"source":   name,
+		"endpoint": ep.DNSName,
+		"resource": ep.Labels[endpoint.ResourceLabelKey],
+	})
+}
+
+// metricsSource is a Source that exposes the number of endpoints produced by its wrapped source under its name,
+// and lets the wrapped source count the resources it skips.
+type metricsSource struct {
+	name   string
+	source Source
+}
+
+// NewMetricsSource creates a new metricsSource wrapping the provided Source named after its --source flag.
+func NewMetricsSource(name string, source Source) Source {
+	return &metricsSource{name: name, source: source}
+}
+
+// Endpoints collects endpoints from its wrapped source and counts them.
+func (ms *metricsSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ms.source.Endpoints(context.WithValue(ctx, sourceNameKey{}, ms.name))
+	if err != nil {
+		return nil, err
+	}
+	sourceEndpoints.WithLabelValues(ms.name).Set(float64(len(endpoints)))
+	endpointSources.set(ms.name, endpoints)
+	return endpoints, nil
+}
+
+func (ms *metricsSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// skippingSource is a Source skipping a resource before returning its endpoints.
+type skippingSource struct {
+	Source
+}
+
+func (s *skippingSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	countSkipped(ctx, skipReasonNoHostname)
+	return s.Source.Endpoints(ctx)
+}
+
+func TestMetricsSource(t *testing.T) {
+	withResource := func(ep *endpoint.Endpoint, resource string) *endpoint.Endpoint {
+		ep.Labels[endpoint.ResourceLabelKey] = resource
+		return ep
+	}
+	services := []*endpoint.Endpoint{
+		withResource(endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeA, "10.0.0.1"), "service/default/a"),
+		withResource(endpoint.NewEndpoint("b.example.com", endpoint.RecordTypeA, "192.0.2.1"), "service/default/b"),
+	}
+	ingresses := []*endpoint.Endpoint{
+		withResource(endpoint.NewEndpoint("c.example.com", endpoint.RecordTypeA, "10.0.0.2"), "ingress/default/c"),
+	}
+	src := NewTargetFilterSource(NewMultiSource([]Source{
+		NewMetricsSource("test-service", &skippingSource{NewEchoSource(services)}),
+		NewMetricsSource("test-ingress", NewEchoSource(ingresses)),
+	}, nil), endpoint.NewTargetNetFilterWithExclusions(nil, []string{"10.0.0.0/8"}))
+
+	result, err := src.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, result, 1)
+
+	assert.InDelta(t, 2, testutil.ToFloat64(sourceEndpoints.WithLabelValues("test-service")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(sourceEndpoints.WithLabelValues("test-ingress")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(sourceSkippedEndpoints.WithLabelValues("test-service", skipReasonNoHostname)), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(sourceSkippedEndpoints.WithLabelValues("test-service", skipReasonFiltered)), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(sourceSkippedEndpoints.WithLabelValues("test-ingress", skipReasonFiltered)), 0)
+
+	// endpoints of resources unknown to the sources
+	unknown := testutil.ToFloat64(sourceSkippedEndpoints.WithLabelValues(unknownSourceName, skipReasonInvalidTarget))
+	countSkippedEndpoint(endpoint.NewEndpoint("d.example.com", endpoint.RecordTypeA, "10.0.0.3"), skipReasonInvalidTarget)
+	assert.InDelta(t, unknown+1, testutil.ToFloat64(sourceSkippedEndpoints.WithLabelValues(unknownSourceName, skipReasonInvalidTarget)), 0)
+}
@@ -97,8 +97,12 @@ func (ns *nodeSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, erro
 		// Check controller annotation to see if we are responsible.
 		controller, ok := node.Annotations[controllerAnnotationKey]
 		if ok && controller != controllerAnnotationValue {
-			log.Debugf("Skipping node %s because controller value does not match, found: %s, required: %s",
-				node.Name, controller, controllerAnnotationValue)
+			countSkipped(ctx, skipReasonControllerMismatch).WithFields(log.Fields{
+				"filter":   controllerAnnotationKey,
+				"found":    controller,
+				"required": controllerAnnotationValue,
+			}).Debugf("Skipping node %s because controller value does not match",
+				node.Name)
 			continue
 		}
 
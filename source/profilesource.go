@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// FilterProfile is a named set of filters restricting the endpoints of the sources bound to it.
+type FilterProfile struct {
+	// Domains are the domains of the endpoints, all domains if empty.
+	Domains []string
+	// ExcludeDomains are the subdomains of Domains excluded.
+	ExcludeDomains []string
+	// RecordTypes are the record types of the endpoints, all record types if empty.
+	RecordTypes []string
+}
+
+// ParseFilterProfiles parses filter profiles of the form name:key=value[,key=value...], where the keys are
+// domain, exclude-domain and record-type, and can be repeated.
+func ParseFilterProfiles(specs []string) (map[string]FilterProfile, error) {
+	profiles := map[string]FilterProfile{}
+	for _, spec := range specs {
+		name, filters, ok := strings.Cut(spec, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid filter profile %q, expected name:key=value[,key=value...]", spec)
+		}
+		if _, exists := profiles[name]; exists {
+			return nil, fmt.Errorf("filter profile %q defined more than once", name)
+		}
+		profile := FilterProfile{}
+		for _, filter := range strings.Split(filters, ",") {
+			key, value, ok := strings.Cut(filter, "=")
+			value = strings.TrimSpace(value)
+			if !ok || value == "" {
+				return nil, fmt.Errorf("invalid filter %q of filter profile %q, expected key=value", filter, name)
+			}
+			switch strings.TrimSpace(key) {
+			case "domain":
+				profile.Domains = append(profile.Domains, value)
+			case "exclude-domain":
+				profile.ExcludeDomains = append(profile.ExcludeDomains, value)
+			case "record-type":
+				profile.RecordTypes = append(profile.RecordTypes, strings.ToUpper(value))
+			default:
+				return nil, fmt.Errorf("unknown filter %q of filter profile %q, expected domain, exclude-domain or record-type", key, name)
+			}
+		}
+		profiles[name] = profile
+	}
+	return profiles, nil
+}
+
+// ParseSourceFilterProfiles parses bindings of sources to the filter profiles of the form source=profile,
+// as comma-separated lists, and returns the profile of each source.
+func ParseSourceFilterProfiles(specs []string, profiles map[string]FilterProfile) (map[string]string, error) {
+	bindings := map[string]string{}
+	for _, spec := range specs {
+		for _, binding := range strings.Split(spec, ",") {
+			source, profile, ok := strings.Cut(binding, "=")
+			source, profile = strings.TrimSpace(source), strings.TrimSpace(profile)
+			if !ok || source == "" || profile == "" {
+				return nil, fmt.Errorf("invalid binding %q, expected source=profile", binding)
+			}
+			if _, exists := profiles[profile]; !exists {
+				return nil, fmt.Errorf("source %q is bound to the undefined filter profile %q", source, profile)
+			}
+			if bound, exists := bindings[source]; exists && bound != profile {
+				return nil, fmt.Errorf("source %q is bound to the filter profiles %q and %q", source, bound, profile)
+			}
+			bindings[source] = profile
+		}
+	}
+	return bindings, nil
+}
+
+// profileSource is a Source that removes the endpoints of its wrapped source not matching a filter profile.
+type profileSource struct {
+	source       Source
+	name         string
+	domainFilter endpoint.DomainFilter
+	recordTypes  []string
+}
+
+// NewProfileSource creates a new profileSource wrapping the provided Source with the named filter profile.
+func NewProfileSource(source Source, name string, profile FilterProfile) Source {
+	return &profileSource{
+		source:       source,
+		name:         name,
+		domainFilter: endpoint.NewDomainFilterWithExclusions(profile.Domains, profile.ExcludeDomains),
+		recordTypes:  profile.RecordTypes,
+	}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns those matching the filter profile.
+func (ps *profileSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ps.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if !ps.domainFilter.Match(ep.DNSName) {
+			countSkippedEndpoint(ep, skipReasonFiltered).WithFields(log.Fields{
+				"filter":  "filter-profile",
+				"profile": ps.name,
+			}).Debug("Skipping endpoint because its domain is not in the filter profile of the source")
+			continue
+		}
+		if len(ps.recordTypes) > 0 && !slices.Contains(ps.recordTypes, ep.RecordType) {
+			countSkippedEndpoint(ep, skipReasonFiltered).WithFields(log.Fields{
+				"filter":  "filter-profile",
+				"profile": ps.name,
+			}).Debug("Skipping endpoint because its record type is not in the filter profile of the source")
+			continue
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+func (ps *profileSource) AddEventHandler(ctx context.Context, handler func()) {
+	ps.source.AddEventHandler(ctx, handler)
+}
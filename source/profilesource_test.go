@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestParseFilterProfiles(t *testing.T) {
+	profiles, err := ParseFilterProfiles([]string{
+		"infra:domain=infra.example.com,record-type=a,record-type=AAAA",
+		"apps: domain=example.com, exclude-domain=infra.example.com",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]FilterProfile{
+		"infra": {Domains: []string{"infra.example.com"}, RecordTypes: []string{"A", "AAAA"}},
+		"apps":  {Domains: []string{"example.com"}, ExcludeDomains: []string{"infra.example.com"}},
+	}, profiles)
+
+	for _, tc := range []struct {
+		specs    []string
+		expected string
+	}{
+		{[]string{"infra"}, `invalid filter profile "infra", expected name:key=value[,key=value...]`},
+		{[]string{":domain=example.com"}, `invalid filter profile ":domain=example.com", expected name:key=value[,key=value...]`},
+		{[]string{"infra:domain"}, `invalid filter "domain" of filter profile "infra", expected key=value`},
+		{[]string{"infra:zone-type=private"}, `unknown filter "zone-type" of filter profile "infra", expected domain, exclude-domain or record-type`},
+		{[]string{"infra:domain=a.com", "infra:domain=b.com"}, `filter profile "infra" defined more than once`},
+	} {
+		_, err := ParseFilterProfiles(tc.specs)
+		assert.EqualError(t, err, tc.expected)
+	}
+}
+
+func TestParseSourceFilterProfiles(t *testing.T) {
+	profiles := map[string]FilterProfile{"infra": {}, "apps": {}}
+
+	bindings, err := ParseSourceFilterProfiles([]string{"node=infra,ingress=apps", "service=apps", "node=infra"}, profiles)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"node": "infra", "ingress": "apps", "service": "apps"}, bindings)
+
+	for _, tc := range []struct {
+		specs    []string
+		expected string
+	}{
+		{[]string{"node"}, `invalid binding "node", expected source=profile`},
+		{[]string{"node=other"}, `source "node" is bound to the undefined filter profile "other"`},
+		{[]string{"node=infra", "node=apps"}, `source "node" is bound to the filter profiles "infra" and "apps"`},
+	} {
+		_, err := ParseSourceFilterProfiles(tc.specs, profiles)
+		assert.EqualError(t, err, tc.expected)
+	}
+}
+
+func TestProfileSource(t *testing.T) {
+	endpoints := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("node-1.infra.example.com", endpoint.RecordTypeA, "10.0.0.1"),
+		endpoint.NewEndpoint("node-1.infra.example.com", endpoint.RecordTypeAAAA, "fd00::1"),
+		endpoint.NewEndpoint("node-1.infra.example.com", endpoint.RecordTypeTXT, "text"),
+		endpoint.NewEndpoint("node-1.legacy.infra.example.com", endpoint.RecordTypeA, "10.0.0.2"),
+		endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "10.0.0.3"),
+	}
+	src := NewProfileSource(NewEchoSource(endpoints), "infra", FilterProfile{
+		Domains:        []string{"infra.example.com"},
+		ExcludeDomains: []string{"legacy.infra.example.com"},
+		RecordTypes:    []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA},
+	})
+
+	result, err := src.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, endpoints[:2], result)
+
+	// an empty profile keeps all the endpoints
+	result, err = NewProfileSource(NewEchoSource(endpoints), "all", FilterProfile{}).Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, endpoints, result)
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// recordCommentSource is a Source that sets the comment of the records of the resources with the record-comment
+// annotation to the text of the annotation followed by a reference to the resource, see endpoint.NewRecordComment.
+type recordCommentSource struct {
+	source Source
+}
+
+// NewRecordCommentSource creates a new recordCommentSource wrapping the provided Source.
+func NewRecordCommentSource(source Source) Source {
+	return &recordCommentSource{source: source}
+}
+
+// Endpoints collects endpoints from its wrapped source and sets the comment of those with the record-comment annotation.
+func (rs *recordCommentSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := rs.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ep := range endpoints {
+		text, ok := ep.GetProviderSpecificProperty(RecordCommentKey)
+		if !ok {
+			continue
+		}
+		ep.DeleteProviderSpecificProperty(RecordCommentKey)
+		ep.SetProviderSpecificProperty(endpoint.ProviderSpecificRecordComment, endpoint.NewRecordComment(text, ep.Labels[endpoint.ResourceLabelKey]))
+	}
+	return endpoints, nil
+}
+
+func (rs *recordCommentSource) AddEventHandler(ctx context.Context, handler func()) {
+	rs.source.AddEventHandler(ctx, handler)
+}
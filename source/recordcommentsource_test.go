@@ -0,0 +1,52 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestRecordCommentSource(t *testing.T) {
+	annotated := endpoint.NewEndpoint("shop.example.com", endpoint.RecordTypeA, "192.0.2.1").
+		WithProviderSpecific(RecordCommentKey, "Frontend of the shop")
+	annotated.Labels[endpoint.ResourceLabelKey] = "service/shop/frontend"
+	// a comment set in a DNSEndpoint is kept as is
+	commented := endpoint.NewEndpoint("api.example.com", endpoint.RecordTypeA, "192.0.2.2").
+		WithProviderSpecific(endpoint.ProviderSpecificRecordComment, "API")
+	plain := endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "192.0.2.3")
+
+	result, err := NewRecordCommentSource(NewEchoSource([]*endpoint.Endpoint{annotated, commented, plain})).Endpoints(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+
+	assert.Equal(t, endpoint.ProviderSpecific{
+		{Name: endpoint.ProviderSpecificRecordComment, Value: "Frontend of the shop (external-dns: service/shop/frontend)"},
+	}, result[0].ProviderSpecific)
+	assert.Equal(t, endpoint.ProviderSpecific{{Name: endpoint.ProviderSpecificRecordComment, Value: "API"}}, result[1].ProviderSpecific)
+	assert.Empty(t, result[2].ProviderSpecific)
+}
+
+func TestGetProviderSpecificAnnotationsRecordComment(t *testing.T) {
+	providerSpecific, _ := getProviderSpecificAnnotations(map[string]string{RecordCommentKey: "Frontend of the shop"})
+	assert.Equal(t, endpoint.ProviderSpecific{{Name: RecordCommentKey, Value: "Frontend of the shop"}}, providerSpecific)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// releaseSource is a Source that marks endpoints annotated with release-to as handed over to a different owner.
+// The label is persisted by the registry, so that the new owner can claim the records.
+// It also marks endpoints annotated with adopt as taking over the existing records without owner.
+type releaseSource struct {
+	source Source
+}
+
+// NewReleaseSource creates a new releaseSource wrapping the provided Source.
+func NewReleaseSource(source Source) Source {
+	return &releaseSource{source: source}
+}
+
+// Endpoints collects endpoints from its wrapped source and moves the release-to and adopt annotations into the labels.
+func (rs *releaseSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := rs.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ep := range endpoints {
+		if adopt, ok := ep.GetProviderSpecificProperty(AdoptKey); ok {
+			ep.DeleteProviderSpecificProperty(AdoptKey)
+			if adopt == "true" {
+				if ep.Labels == nil {
+					ep.Labels = endpoint.NewLabels()
+				}
+				ep.Labels[endpoint.AdoptLabelKey] = "true"
+			}
+		}
+
+		releaseTo, ok := ep.GetProviderSpecificProperty(ReleaseToKey)
+		if !ok {
+			continue
+		}
+		ep.DeleteProviderSpecificProperty(ReleaseToKey)
+		if releaseTo == "" {
+			continue
+		}
+		if ep.Labels == nil {
+			ep.Labels = endpoint.NewLabels()
+		}
+		ep.Labels[endpoint.ReleaseToLabelKey] = releaseTo
+	}
+
+	return endpoints, nil
+}
+
+func (rs *releaseSource) AddEventHandler(ctx context.Context, handler func()) {
+	rs.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestReleaseSource(t *testing.T) {
+	endpoints, err := NewReleaseSource(NewEchoSource([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("released.example.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(ReleaseToKey, "green"),
+		endpoint.NewEndpoint("empty.example.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(ReleaseToKey, ""),
+		endpoint.NewEndpoint("kept.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+	})).Endpoints(context.Background())
+	require.NoError(t, err)
+	require.Len(t, endpoints, 3)
+
+	assert.Equal(t, "green", endpoints[0].Labels[endpoint.ReleaseToLabelKey])
+	assert.Empty(t, endpoints[0].ProviderSpecific)
+	assert.NotContains(t, endpoints[1].Labels, endpoint.ReleaseToLabelKey)
+	assert.Empty(t, endpoints[1].ProviderSpecific)
+	assert.NotContains(t, endpoints[2].Labels, endpoint.ReleaseToLabelKey)
+}
+
+func TestReleaseSourceAdopt(t *testing.T) {
+	endpoints, err := NewReleaseSource(NewEchoSource([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("adopted.example.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(AdoptKey, "true"),
+		endpoint.NewEndpoint("false.example.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(AdoptKey, "false"),
+	})).Endpoints(context.Background())
+	require.NoError(t, err)
+	require.Len(t, endpoints, 2)
+
+	assert.Equal(t, "true", endpoints[0].Labels[endpoint.AdoptLabelKey])
+	assert.Empty(t, endpoints[0].ProviderSpecific)
+	assert.NotContains(t, endpoints[1].Labels, endpoint.AdoptLabelKey)
+	assert.Empty(t, endpoints[1].ProviderSpecific)
+}
@@ -36,6 +36,13 @@ import (
 	"sigs.k8s.io/external-dns/endpoint"
 )
 
+const (
+	// NLBDualstackAnnotationKey is the annotation used for determining if an NLB service is dualstack
+	NLBDualstackAnnotationKey = "service.beta.kubernetes.io/aws-load-balancer-ip-address-type"
+	// NLBDualstackAnnotationValue is the value of the NLB dualstack annotation that indicates it is dualstack
+	NLBDualstackAnnotationValue = "dualstack"
+)
+
 // serviceSource is an implementation of Source for Kubernetes service objects.
 // It will find all services that are under our jurisdiction, i.e. annotated
 // desired hostname and matching or no controller annotation. For each of the
@@ -161,8 +168,12 @@ func (sc *serviceSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, e
 		// Check controller annotation to see if we are responsible.
 		controller, ok := svc.Annotations[controllerAnnotationKey]
 		if ok && controller != controllerAnnotationValue {
-			log.Debugf("Skipping service %s/%s because controller value does not match, found: %s, required: %s",
-				svc.Namespace, svc.Name, controller, controllerAnnotationValue)
+			countSkipped(ctx, skipReasonControllerMismatch).WithFields(log.Fields{
+				"filter":   controllerAnnotationKey,
+				"found":    controller,
+				"required": controllerAnnotationValue,
+			}).Debugf("Skipping service %s/%s because controller value does not match",
+				svc.Namespace, svc.Name)
 			continue
 		}
 
@@ -191,12 +202,13 @@ func (sc *serviceSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, e
 		}
 
 		if len(svcEndpoints) == 0 {
-			log.Debugf("No endpoints could be generated from service %s/%s", svc.Namespace, svc.Name)
+			countSkipped(ctx, skipReasonNoHostname).Debugf("No endpoints could be generated from service %s/%s", svc.Namespace, svc.Name)
 			continue
 		}
 
 		log.Debugf("Endpoints generated from service: %s/%s: %v", svc.Namespace, svc.Name, svcEndpoints)
 		sc.setResourceLabel(svc, svcEndpoints)
+		sc.setDualstackLabel(svc, svcEndpoints)
 		endpoints = append(endpoints, svcEndpoints...)
 	}
 
@@ -277,7 +289,7 @@ func (sc *serviceSource) extractHeadlessEndpoints(svc *v1.Service, hostname stri
 		for _, address := range addresses {
 			// find pod for this address
 			if address.TargetRef == nil || address.TargetRef.APIVersion != "" || address.TargetRef.Kind != "Pod" {
-				log.Debugf("Skipping address because its target is not a pod: %v", address)
+				endpoint.Skipped(endpoint.SkipReasonNotPod).WithField("resource", "service/"+svc.Namespace+"/"+svc.Name).Debugf("Skipping address because its target is not a pod: %v", address)
 				continue
 			}
 			var pod *v1.Pod
@@ -459,6 +471,16 @@ func (sc *serviceSource) setResourceLabel(service *v1.Service, endpoints []*endp
 	}
 }
 
+func (sc *serviceSource) setDualstackLabel(service *v1.Service, endpoints []*endpoint.Endpoint) {
+	val, ok := service.Annotations[NLBDualstackAnnotationKey]
+	if (ok && val == NLBDualstackAnnotationValue) || service.Annotations[dualstackAnnotationKey] == "true" {
+		log.Debugf("Adding dualstack label to service %s/%s.", service.Namespace, service.Name)
+		for _, ep := range endpoints {
+			ep.Labels[endpoint.DualstackLabelKey] = "true"
+		}
+	}
+}
+
 func (sc *serviceSource) generateEndpoints(svc *v1.Service, hostname string, providerSpecific endpoint.ProviderSpecific, setIdentifier string, useClusterIP bool) (endpoints []*endpoint.Endpoint) {
 	hostname = strings.TrimSuffix(hostname, ".")
 
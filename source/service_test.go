@@ -50,7 +50,7 @@ func (suite *ServiceSuite) SetupTest() {
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace:   "default",
 			Name:        "foo-with-targets",
-			Annotations: map[string]string{},
+			Annotations: map[string]string{dualstackAnnotationKey: "true"},
 		},
 		Status: v1.ServiceStatus{
 			LoadBalancer: v1.LoadBalancerStatus{
@@ -90,6 +90,13 @@ func (suite *ServiceSuite) TestResourceLabelIsSet() {
 	}
 }
 
+func (suite *ServiceSuite) TestDualstackLabelIsSet() {
+	endpoints, _ := suite.sc.Endpoints(context.Background())
+	for _, ep := range endpoints {
+		suite.Equal("true", ep.Labels[endpoint.DualstackLabelKey], "should set dualstack label to true")
+	}
+}
+
 func TestServiceSource(t *testing.T) {
 	t.Parallel()
 
@@ -30,10 +30,12 @@ import (
 	"unicode"
 
 	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	corelisters "k8s.io/client-go/listers/core/v1"
 
 	"sigs.k8s.io/external-dns/endpoint"
 )
@@ -60,6 +62,10 @@ const (
 	controllerAnnotationValue = "dns-controller"
 	// The annotation used for defining the desired hostname
 	internalHostnameAnnotationKey = "external-dns.alpha.kubernetes.io/internal-hostname"
+	// The annotation used for defining the targets of the internal hostnames
+	internalTargetAnnotationKey = "external-dns.alpha.kubernetes.io/internal-target"
+	// The annotation used for creating AAAA alias records alongside the A alias records, e.g. for dualstack load balancers
+	dualstackAnnotationKey = "external-dns.alpha.kubernetes.io/dualstack"
 )
 
 const (
@@ -71,8 +77,41 @@ const (
 const (
 	// The annotation used for determining if traffic will go through Cloudflare
 	CloudflareProxiedKey = "external-dns.alpha.kubernetes.io/cloudflare-proxied"
+	// The annotation used for serving the targets with a Cloudflare Load Balancer instead of records
+	CloudflareLoadBalancerKey = "external-dns.alpha.kubernetes.io/cloudflare-load-balancer"
+	// The annotations used for configuring the Cloudflare Load Balancer and the monitor of its origins
+	CloudflareLoadBalancerSteeringPolicyKey = "external-dns.alpha.kubernetes.io/cloudflare-load-balancer-steering-policy"
+	CloudflareLoadBalancerMonitorTypeKey    = "external-dns.alpha.kubernetes.io/cloudflare-load-balancer-monitor-type"
+	CloudflareLoadBalancerMonitorPathKey    = "external-dns.alpha.kubernetes.io/cloudflare-load-balancer-monitor-path"
+	CloudflareLoadBalancerMonitorPortKey    = "external-dns.alpha.kubernetes.io/cloudflare-load-balancer-monitor-port"
+	CloudflareLoadBalancerExpectedCodesKey  = "external-dns.alpha.kubernetes.io/cloudflare-load-balancer-expected-codes"
+	// The annotation used for serving a hostname outside of the Cloudflare zones with a Cloudflare custom hostname
+	CloudflareCustomHostnameKey = "external-dns.alpha.kubernetes.io/cloudflare-custom-hostname"
+	// The annotation used for restricting the hostname to the Cloudflare data centers of a region, with Cloudflare Regional Services
+	CloudflareRegionKey = "external-dns.alpha.kubernetes.io/cloudflare-region-key"
 
 	SetIdentifierKey = "external-dns.alpha.kubernetes.io/set-identifier"
+
+	// The annotation used for defining a query that indicates an unhealthy origin, evaluated by the failover source
+	FailoverQueryKey = "external-dns.alpha.kubernetes.io/failover-query"
+	// The annotation used for defining the targets to use when all targets are unhealthy
+	FailoverTargetsKey = "external-dns.alpha.kubernetes.io/failover-targets"
+	// The annotation used for defining how targets are actively probed, evaluated by the health check source
+	HealthCheckKey = "external-dns.alpha.kubernetes.io/health-check"
+	// The annotation used for marking the resource's hostnames as load balanced across clusters by the GSLB source
+	GSLBKey = "external-dns.alpha.kubernetes.io/gslb"
+	// The annotation used for defining the latency hint of this cluster in milliseconds, used for weighting by the GSLB source
+	GSLBLatencyKey = "external-dns.alpha.kubernetes.io/gslb-latency"
+	// The annotation used for handing the resource's records over to a different owner ID
+	ReleaseToKey = "external-dns.alpha.kubernetes.io/release-to"
+	// The annotation used for taking over existing records of the resource's hostnames that have no owner
+	AdoptKey = "external-dns.alpha.kubernetes.io/adopt"
+	// The annotation used for defining the TTL and targets of some of the resource's hostnames as a JSON object
+	HostnameOverridesKey = "external-dns.alpha.kubernetes.io/hostname-overrides"
+	// The annotation used for synchronizing the resource's records more often than the interval, e.g. for frequently changing targets
+	RefreshIntervalKey = "external-dns.alpha.kubernetes.io/refresh-interval"
+	// The annotation used for defining the comment of the resource's records, on the providers supporting record comments
+	RecordCommentKey = "external-dns.alpha.kubernetes.io/record-comment"
 )
 
 const (
@@ -202,6 +241,16 @@ func getProviderSpecificAnnotations(annotations map[string]string) (endpoint.Pro
 			Value: "true",
 		})
 	}
+	for _, key := range []string{FailoverQueryKey, FailoverTargetsKey, HealthCheckKey, GSLBKey, GSLBLatencyKey, ReleaseToKey, AdoptKey, HostnameOverridesKey, RefreshIntervalKey, RecordCommentKey,
+		CloudflareLoadBalancerKey, CloudflareLoadBalancerSteeringPolicyKey, CloudflareLoadBalancerMonitorTypeKey, CloudflareLoadBalancerMonitorPathKey,
+		CloudflareLoadBalancerMonitorPortKey, CloudflareLoadBalancerExpectedCodesKey, CloudflareCustomHostnameKey, CloudflareRegionKey} {
+		if v, exists := annotations[key]; exists {
+			providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
+				Name:  key,
+				Value: v,
+			})
+		}
+	}
 	setIdentifier := ""
 	for k, v := range annotations {
 		if k == SetIdentifierKey {
@@ -212,12 +261,24 @@ func getProviderSpecificAnnotations(annotations map[string]string) (endpoint.Pro
 				Name:  fmt.Sprintf("aws/%s", attr),
 				Value: v,
 			})
+		} else if strings.HasPrefix(k, "external-dns.alpha.kubernetes.io/google-") {
+			attr := strings.TrimPrefix(k, "external-dns.alpha.kubernetes.io/google-")
+			providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
+				Name:  fmt.Sprintf("google/%s", attr),
+				Value: v,
+			})
 		} else if strings.HasPrefix(k, "external-dns.alpha.kubernetes.io/scw-") {
 			attr := strings.TrimPrefix(k, "external-dns.alpha.kubernetes.io/scw-")
 			providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
 				Name:  fmt.Sprintf("scw/%s", attr),
 				Value: v,
 			})
+		} else if strings.HasPrefix(k, "external-dns.alpha.kubernetes.io/ns1-") {
+			attr := strings.TrimPrefix(k, "external-dns.alpha.kubernetes.io/ns1-")
+			providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
+				Name:  fmt.Sprintf("ns1/%s", attr),
+				Value: v,
+			})
 		} else if strings.HasPrefix(k, "external-dns.alpha.kubernetes.io/ibmcloud-") {
 			attr := strings.TrimPrefix(k, "external-dns.alpha.kubernetes.io/ibmcloud-")
 			providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
@@ -239,10 +300,59 @@ func getProviderSpecificAnnotations(annotations map[string]string) (endpoint.Pro
 // getTargetsFromTargetAnnotation gets endpoints from optional "target" annotation.
 // Returns empty endpoints array if none are found.
 func getTargetsFromTargetAnnotation(annotations map[string]string) endpoint.Targets {
+	return getTargetsFromAnnotation(annotations, targetAnnotationKey)
+}
+
+// getInternalTargets returns the targets of the internal hostnames of a resource: the targets of the
+// internal-target annotation, else the configured internal targets, e.g. of an internal load balancer,
+// else the cluster IPs of the resource's Services.
+func getInternalTargets(annotations map[string]string, internalTargets []string, clusterIPs endpoint.Targets) endpoint.Targets {
+	if targets := getTargetsFromAnnotation(annotations, internalTargetAnnotationKey); len(targets) > 0 {
+		return targets
+	}
+	if len(internalTargets) > 0 {
+		return internalTargets
+	}
+	return clusterIPs
+}
+
+// endpointsForInternalHostnames returns the endpoints of the hostnames of the internal-hostname annotation.
+func endpointsForInternalHostnames(annotations map[string]string, targets endpoint.Targets, ttl endpoint.TTL, providerSpecific endpoint.ProviderSpecific, setIdentifier string, resource string) []*endpoint.Endpoint {
+	var endpoints []*endpoint.Endpoint
+	for _, hostname := range getInternalHostnamesFromAnnotations(annotations) {
+		endpoints = append(endpoints, endpointsForHostname(hostname, targets, ttl, providerSpecific, setIdentifier, resource)...)
+	}
+	return endpoints
+}
+
+// clusterIPsOfServices returns the cluster IPs of the named Services of a namespace,
+// skipping headless and missing Services.
+func clusterIPsOfServices(lister corelisters.ServiceLister, namespace string, names []string) endpoint.Targets {
+	var targets endpoint.Targets
+	seen := map[string]bool{}
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		svc, err := lister.Services(namespace).Get(name)
+		if err != nil {
+			log.Debugf("Skipping cluster IP of service %s/%s: %v", namespace, name, err)
+			continue
+		}
+		if svc.Spec.ClusterIP == "" || svc.Spec.ClusterIP == v1.ClusterIPNone {
+			continue
+		}
+		targets = append(targets, svc.Spec.ClusterIP)
+	}
+	return targets
+}
+
+func getTargetsFromAnnotation(annotations map[string]string, key string) endpoint.Targets {
 	var targets endpoint.Targets
 
-	// Get the desired hostname of the ingress from the annotation.
-	targetAnnotation, exists := annotations[targetAnnotationKey]
+	// Get the desired targets of the resource from the annotation.
+	targetAnnotation, exists := annotations[key]
 	if exists && targetAnnotation != "" {
 		// splits the hostname annotation and removes the trailing periods
 		targetsList := strings.Split(strings.Replace(targetAnnotation, " ", "", -1), ",")
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"sigs.k8s.io/external-dns/endpoint"
 )
@@ -96,3 +97,27 @@ func TestSuitableType(t *testing.T) {
 		}
 	}
 }
+
+func TestAnnotationFilterExpressions(t *testing.T) {
+	annotations := map[string]string{"kubernetes.io/ingress.class": "public", "team": "payments"}
+	for _, tc := range []struct {
+		filter   string
+		expected bool
+	}{
+		{"kubernetes.io/ingress.class=public", true},
+		{"kubernetes.io/ingress.class in (public,dmz)", true},
+		{"kubernetes.io/ingress.class notin (public,dmz)", false},
+		{"team", true},
+		{"!team", false},
+		{"owner", false},
+		{"!owner", true},
+		{"team in (payments),kubernetes.io/ingress.class notin (internal)", true},
+		{"team in (payments),owner", false},
+	} {
+		t.Run(tc.filter, func(t *testing.T) {
+			selector, err := getLabelSelector(tc.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, matchLabelSelector(selector, annotations))
+		})
+	}
+}
@@ -23,10 +23,15 @@ import (
 
 	cfclient "github.com/cloudfoundry-community/go-cfclient"
 	openshift "github.com/openshift/client-go/route/clientset/versioned"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	istioclient "istio.io/client-go/pkg/clientset/versioned"
 	istiofake "istio.io/client-go/pkg/clientset/versioned/fake"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
@@ -219,3 +224,56 @@ func (suite *ByNamesTestSuite) TestIstioClientFails() {
 func TestByNames(t *testing.T) {
 	suite.Run(t, new(ByNamesTestSuite))
 }
+
+func TestParseSourceAnnotationFilters(t *testing.T) {
+	filters, err := ParseSourceAnnotationFilters([]string{"ingress=kubernetes.io/ingress.class in (public,dmz)", " service =team,!internal"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"ingress": "kubernetes.io/ingress.class in (public,dmz)", "service": "team,!internal"}, filters)
+
+	for _, specs := range [][]string{
+		{"kubernetes.io/ingress.class in (public)"},
+		{"=team"},
+		{"ingress=kubernetes.io/ingress.class in ("},
+		{"ingress=team=a", "ingress=team=b"},
+	} {
+		_, err := ParseSourceAnnotationFilters(specs)
+		assert.Error(t, err, "%v", specs)
+	}
+}
+
+func TestBuildWithConfigSourceAnnotationFilter(t *testing.T) {
+	kubeClient := fakeKube.NewSimpleClientset()
+	for name, team := range map[string]string{"node-a": "a", "node-b": "b", "node-c": "c"} {
+		node := &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{"team": team}},
+			Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}}},
+		}
+		_, err := kubeClient.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	mockClientGenerator := new(MockClientGenerator)
+	mockClientGenerator.On("KubeClient").Return(kubeClient, nil)
+
+	for _, tc := range []struct {
+		title     string
+		overrides map[string]string
+		expected  []string
+	}{
+		{title: "shared filter", expected: []string{"node-a"}},
+		{title: "override", overrides: map[string]string{"node": "team notin (a)"}, expected: []string{"node-b", "node-c"}},
+		{title: "override of another source", overrides: map[string]string{"service": "team in (b,c)"}, expected: []string{"node-a"}},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			cfg := &Config{AnnotationFilter: "team=a", SourceAnnotationFilters: tc.overrides, LabelFilter: labels.Everything()}
+			src, err := BuildWithConfig(context.Background(), "node", mockClientGenerator, cfg)
+			require.NoError(t, err)
+			endpoints, err := src.Endpoints(context.Background())
+			require.NoError(t, err)
+			var names []string
+			for _, ep := range endpoints {
+				names = append(names, ep.DNSName)
+			}
+			assert.ElementsMatch(t, tc.expected, names)
+		})
+	}
+}
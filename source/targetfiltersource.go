@@ -56,7 +56,10 @@ func (ms *targetFilterSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoi
 
 		// If all targets are filtered out, skip the endpoint.
 		if len(filteredTargets) == 0 {
-			log.WithField("endpoint", ep).Debugf("Skipping endpoint because all targets were filtered out")
+			countSkippedEndpoint(ep, skipReasonFiltered).WithFields(log.Fields{
+				"filter": "target-net-filter",
+				"found":  ep.Targets.String(),
+			}).Debug("Skipping endpoint because all targets were filtered out")
 			continue
 		}
 
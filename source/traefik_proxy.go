@@ -174,42 +174,42 @@ func (ts *traefikSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, e
 	var endpoints []*endpoint.Endpoint
 
 	if ts.ingressRouteInformer != nil {
-		ingressRouteEndpoints, err := ts.ingressRouteEndpoints()
+		ingressRouteEndpoints, err := ts.ingressRouteEndpoints(ctx)
 		if err != nil {
 			return nil, err
 		}
 		endpoints = append(endpoints, ingressRouteEndpoints...)
 	}
 	if ts.oldIngressRouteInformer != nil {
-		oldIngressRouteEndpoints, err := ts.oldIngressRouteEndpoints()
+		oldIngressRouteEndpoints, err := ts.oldIngressRouteEndpoints(ctx)
 		if err != nil {
 			return nil, err
 		}
 		endpoints = append(endpoints, oldIngressRouteEndpoints...)
 	}
 	if ts.ingressRouteTcpInformer != nil {
-		ingressRouteTcpEndpoints, err := ts.ingressRouteTCPEndpoints()
+		ingressRouteTcpEndpoints, err := ts.ingressRouteTCPEndpoints(ctx)
 		if err != nil {
 			return nil, err
 		}
 		endpoints = append(endpoints, ingressRouteTcpEndpoints...)
 	}
 	if ts.oldIngressRouteTcpInformer != nil {
-		oldIngressRouteTcpEndpoints, err := ts.oldIngressRouteTCPEndpoints()
+		oldIngressRouteTcpEndpoints, err := ts.oldIngressRouteTCPEndpoints(ctx)
 		if err != nil {
 			return nil, err
 		}
 		endpoints = append(endpoints, oldIngressRouteTcpEndpoints...)
 	}
 	if ts.ingressRouteUdpInformer != nil {
-		ingressRouteUdpEndpoints, err := ts.ingressRouteUDPEndpoints()
+		ingressRouteUdpEndpoints, err := ts.ingressRouteUDPEndpoints(ctx)
 		if err != nil {
 			return nil, err
 		}
 		endpoints = append(endpoints, ingressRouteUdpEndpoints...)
 	}
 	if ts.oldIngressRouteUdpInformer != nil {
-		oldIngressRouteUdpEndpoints, err := ts.oldIngressRouteUDPEndpoints()
+		oldIngressRouteUdpEndpoints, err := ts.oldIngressRouteUDPEndpoints(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -224,7 +224,7 @@ func (ts *traefikSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, e
 }
 
 // ingressRouteEndpoints extracts endpoints from all IngressRoute objects
-func (ts *traefikSource) ingressRouteEndpoints() ([]*endpoint.Endpoint, error) {
+func (ts *traefikSource) ingressRouteEndpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
 	var endpoints []*endpoint.Endpoint
 
 	irs, err := ts.ingressRouteInformer.Lister().ByNamespace(ts.namespace).List(labels.Everything())
@@ -264,7 +264,7 @@ func (ts *traefikSource) ingressRouteEndpoints() ([]*endpoint.Endpoint, error) {
 			return nil, err
 		}
 		if len(ingressEndpoints) == 0 {
-			log.Debugf("No endpoints could be generated from Host %s", fullname)
+			countSkipped(ctx, skipReasonNoHostname).Debugf("No endpoints could be generated from Host %s", fullname)
 			continue
 		}
 
@@ -277,7 +277,7 @@ func (ts *traefikSource) ingressRouteEndpoints() ([]*endpoint.Endpoint, error) {
 }
 
 // ingressRouteTCPEndpoints extracts endpoints from all IngressRouteTCP objects
-func (ts *traefikSource) ingressRouteTCPEndpoints() ([]*endpoint.Endpoint, error) {
+func (ts *traefikSource) ingressRouteTCPEndpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
 	var endpoints []*endpoint.Endpoint
 
 	irs, err := ts.ingressRouteTcpInformer.Lister().ByNamespace(ts.namespace).List(labels.Everything())
@@ -317,7 +317,7 @@ func (ts *traefikSource) ingressRouteTCPEndpoints() ([]*endpoint.Endpoint, error
 			return nil, err
 		}
 		if len(ingressEndpoints) == 0 {
-			log.Debugf("No endpoints could be generated from Host %s", fullname)
+			countSkipped(ctx, skipReasonNoHostname).Debugf("No endpoints could be generated from Host %s", fullname)
 			continue
 		}
 
@@ -330,7 +330,7 @@ func (ts *traefikSource) ingressRouteTCPEndpoints() ([]*endpoint.Endpoint, error
 }
 
 // ingressRouteUDPEndpoints extracts endpoints from all IngressRouteUDP objects
-func (ts *traefikSource) ingressRouteUDPEndpoints() ([]*endpoint.Endpoint, error) {
+func (ts *traefikSource) ingressRouteUDPEndpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
 	var endpoints []*endpoint.Endpoint
 
 	irs, err := ts.ingressRouteUdpInformer.Lister().ByNamespace(ts.namespace).List(labels.Everything())
@@ -370,7 +370,7 @@ func (ts *traefikSource) ingressRouteUDPEndpoints() ([]*endpoint.Endpoint, error
 			return nil, err
 		}
 		if len(ingressEndpoints) == 0 {
-			log.Debugf("No endpoints could be generated from Host %s", fullname)
+			countSkipped(ctx, skipReasonNoHostname).Debugf("No endpoints could be generated from Host %s", fullname)
 			continue
 		}
 
@@ -383,7 +383,7 @@ func (ts *traefikSource) ingressRouteUDPEndpoints() ([]*endpoint.Endpoint, error
 }
 
 // oldIngressRouteEndpoints extracts endpoints from all IngressRoute objects
-func (ts *traefikSource) oldIngressRouteEndpoints() ([]*endpoint.Endpoint, error) {
+func (ts *traefikSource) oldIngressRouteEndpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
 	var endpoints []*endpoint.Endpoint
 
 	irs, err := ts.oldIngressRouteInformer.Lister().ByNamespace(ts.namespace).List(labels.Everything())
@@ -423,7 +423,7 @@ func (ts *traefikSource) oldIngressRouteEndpoints() ([]*endpoint.Endpoint, error
 			return nil, err
 		}
 		if len(ingressEndpoints) == 0 {
-			log.Debugf("No endpoints could be generated from Host %s", fullname)
+			countSkipped(ctx, skipReasonNoHostname).Debugf("No endpoints could be generated from Host %s", fullname)
 			continue
 		}
 
@@ -436,7 +436,7 @@ func (ts *traefikSource) oldIngressRouteEndpoints() ([]*endpoint.Endpoint, error
 }
 
 // oldIngressRouteTCPEndpoints extracts endpoints from all IngressRouteTCP objects
-func (ts *traefikSource) oldIngressRouteTCPEndpoints() ([]*endpoint.Endpoint, error) {
+func (ts *traefikSource) oldIngressRouteTCPEndpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
 	var endpoints []*endpoint.Endpoint
 
 	irs, err := ts.oldIngressRouteTcpInformer.Lister().ByNamespace(ts.namespace).List(labels.Everything())
@@ -476,7 +476,7 @@ func (ts *traefikSource) oldIngressRouteTCPEndpoints() ([]*endpoint.Endpoint, er
 			return nil, err
 		}
 		if len(ingressEndpoints) == 0 {
-			log.Debugf("No endpoints could be generated from Host %s", fullname)
+			countSkipped(ctx, skipReasonNoHostname).Debugf("No endpoints could be generated from Host %s", fullname)
 			continue
 		}
 
@@ -489,7 +489,7 @@ func (ts *traefikSource) oldIngressRouteTCPEndpoints() ([]*endpoint.Endpoint, er
 }
 
 // oldIngressRouteUDPEndpoints extracts endpoints from all IngressRouteUDP objects
-func (ts *traefikSource) oldIngressRouteUDPEndpoints() ([]*endpoint.Endpoint, error) {
+func (ts *traefikSource) oldIngressRouteUDPEndpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
 	var endpoints []*endpoint.Endpoint
 
 	irs, err := ts.oldIngressRouteUdpInformer.Lister().ByNamespace(ts.namespace).List(labels.Everything())
@@ -529,7 +529,7 @@ func (ts *traefikSource) oldIngressRouteUDPEndpoints() ([]*endpoint.Endpoint, er
 			return nil, err
 		}
 		if len(ingressEndpoints) == 0 {
-			log.Debugf("No endpoints could be generated from Host %s", fullname)
+			countSkipped(ctx, skipReasonNoHostname).Debugf("No endpoints could be generated from Host %s", fullname)
 			continue
 		}
 
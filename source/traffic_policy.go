@@ -0,0 +1,423 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	kubeinformers "k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// trafficPolicyDocumentProperty is the provider specific property of the Route53 traffic policy document of the
+// endpoints of the TrafficPolicy objects.
+const trafficPolicyDocumentProperty = "aws/traffic-policy-document"
+
+var trafficPolicyGroupVersionResource = schema.GroupVersionResource{
+	Group:    "externaldns.k8s.io",
+	Version:  "v1alpha1",
+	Resource: "trafficpolicies",
+}
+
+// trafficPolicySource is an implementation of Source for TrafficPolicy objects, describing the Route53 traffic
+// policy of a hostname with a document templated with the targets of the cluster.
+type trafficPolicySource struct {
+	annotationFilter      string
+	trafficPolicyInformer kubeinformers.GenericInformer
+	serviceInformer       coreinformers.ServiceInformer
+	namespace             string
+	unstructuredConverter *unstructuredConverter
+}
+
+// NewTrafficPolicySource creates a new trafficPolicySource with the given config.
+func NewTrafficPolicySource(ctx context.Context, dynamicKubeClient dynamic.Interface, kubeClient kubernetes.Interface, namespace string, annotationFilter string) (Source, error) {
+	// Use shared informers to listen for add/update/delete of TrafficPolicies and Services in the specified namespace.
+	// Set resync period to 0, to prevent processing when nothing has changed.
+	informerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicKubeClient, 0, namespace, nil)
+	trafficPolicyInformer := informerFactory.ForResource(trafficPolicyGroupVersionResource)
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0, kubeinformers.WithNamespace(namespace))
+	serviceInformer := kubeInformerFactory.Core().V1().Services()
+
+	// Add default resource event handlers to properly initialize informer.
+	trafficPolicyInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+			},
+		},
+	)
+	serviceInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+			},
+		},
+	)
+
+	informerFactory.Start(ctx.Done())
+	kubeInformerFactory.Start(ctx.Done())
+
+	// wait for the local cache to be populated.
+	if err := waitForDynamicCacheSync(context.Background(), informerFactory); err != nil {
+		return nil, err
+	}
+	if err := waitForCacheSync(context.Background(), kubeInformerFactory); err != nil {
+		return nil, err
+	}
+
+	uc, err := newTrafficPolicyUnstructuredConverter()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to setup Unstructured Converter")
+	}
+
+	return &trafficPolicySource{
+		annotationFilter:      annotationFilter,
+		trafficPolicyInformer: trafficPolicyInformer,
+		serviceInformer:       serviceInformer,
+		namespace:             namespace,
+		unstructuredConverter: uc,
+	}, nil
+}
+
+// Endpoints returns an endpoint for each TrafficPolicy, with the type and the targets of its rendered document.
+// Retrieves all TrafficPolicies in the source's namespace(s).
+func (sc *trafficPolicySource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	objects, err := sc.trafficPolicyInformer.Lister().ByNamespace(sc.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	var trafficPolicies []*TrafficPolicy
+	for _, obj := range objects {
+		unstructuredObj, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, errors.New("could not convert")
+		}
+
+		trafficPolicy := &TrafficPolicy{}
+		if err := sc.unstructuredConverter.scheme.Convert(unstructuredObj, trafficPolicy, nil); err != nil {
+			return nil, err
+		}
+		trafficPolicies = append(trafficPolicies, trafficPolicy)
+	}
+
+	trafficPolicies, err = sc.filterByAnnotations(trafficPolicies)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to filter TrafficPolicies")
+	}
+
+	var endpoints []*endpoint.Endpoint
+	for _, trafficPolicy := range trafficPolicies {
+		fullname := fmt.Sprintf("%s/%s", trafficPolicy.Namespace, trafficPolicy.Name)
+		if trafficPolicy.Spec.Hostname == "" {
+			countSkipped(ctx, skipReasonNoHostname).Debugf("No endpoints could be generated from TrafficPolicy %s", fullname)
+			continue
+		}
+
+		ep, err := sc.endpointFromTrafficPolicy(trafficPolicy)
+		if err != nil {
+			log.Warnf("Skipping TrafficPolicy %s: %v", fullname, err)
+			continue
+		}
+		if ep == nil {
+			countSkipped(ctx, skipReasonNoHostname).Debugf("No endpoints could be generated from TrafficPolicy %s, its targets have no address", fullname)
+			continue
+		}
+
+		log.Debugf("Endpoints generated from TrafficPolicy: %s: %v", fullname, ep)
+		endpoints = append(endpoints, ep)
+	}
+
+	return endpoints, nil
+}
+
+// filterByAnnotations filters a list of TrafficPolicies by a given annotation selector.
+func (sc *trafficPolicySource) filterByAnnotations(trafficPolicies []*TrafficPolicy) ([]*TrafficPolicy, error) {
+	labelSelector, err := metav1.ParseToLabelSelector(sc.annotationFilter)
+	if err != nil {
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	// empty filter returns original list
+	if selector.Empty() {
+		return trafficPolicies, nil
+	}
+
+	filteredList := []*TrafficPolicy{}
+
+	for _, trafficPolicy := range trafficPolicies {
+		// convert the TrafficPolicy's annotations to an equivalent label selector
+		annotations := labels.Set(trafficPolicy.Annotations)
+
+		// include TrafficPolicy if its annotations match the selector
+		if selector.Matches(annotations) {
+			filteredList = append(filteredList, trafficPolicy)
+		}
+	}
+
+	return filteredList, nil
+}
+
+// endpointFromTrafficPolicy returns the endpoint of the TrafficPolicy, with its document rendered with the addresses
+// of its targets. It returns nil if one of its targets has no address yet.
+func (sc *trafficPolicySource) endpointFromTrafficPolicy(trafficPolicy *TrafficPolicy) (*endpoint.Endpoint, error) {
+	targets := map[string][]string{}
+	for name, target := range trafficPolicy.Spec.Targets {
+		addresses, err := sc.targetAddresses(trafficPolicy.Namespace, target)
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", name, err)
+		}
+		if len(addresses) == 0 {
+			return nil, nil
+		}
+		targets[name] = addresses
+	}
+
+	document, recordType, values, err := renderTrafficPolicyDocument(trafficPolicy.Spec.Document, trafficPolicy.Spec.Hostname, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	ep := endpoint.NewEndpointWithTTL(trafficPolicy.Spec.Hostname, recordType, endpoint.TTL(trafficPolicy.Spec.TTL), values...).
+		WithProviderSpecific(trafficPolicyDocumentProperty, document)
+	ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("trafficpolicy/%s/%s", trafficPolicy.Namespace, trafficPolicy.Name)
+	return ep, nil
+}
+
+// targetAddresses returns the addresses of a target: its addresses, or the load balancer addresses of its Service.
+func (sc *trafficPolicySource) targetAddresses(namespace string, target trafficPolicyTarget) ([]string, error) {
+	if target.Service == "" {
+		return target.Addresses, nil
+	}
+	svc, err := sc.serviceInformer.Lister().Services(namespace).Get(target.Service)
+	if err != nil {
+		return nil, err
+	}
+	var addresses []string
+	for _, lb := range svc.Status.LoadBalancer.Ingress {
+		if lb.IP != "" {
+			addresses = append(addresses, lb.IP)
+		}
+		if lb.Hostname != "" {
+			addresses = append(addresses, lb.Hostname)
+		}
+	}
+	return addresses, nil
+}
+
+// renderTrafficPolicyDocument renders the document template with the hostname and the addresses of the targets by
+// name, and returns the compacted document, its record type and the values of its endpoints, which are the targets
+// of the record.
+func renderTrafficPolicyDocument(text, hostname string, targets map[string][]string) (string, string, []string, error) {
+	tmpl, err := template.New("document").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to parse the document template: %w", err)
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, struct {
+		Hostname string
+		Targets  map[string][]string
+	}{hostname, targets}); err != nil {
+		return "", "", nil, fmt.Errorf("failed to render the document: %w", err)
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, rendered.Bytes()); err != nil {
+		return "", "", nil, fmt.Errorf("the rendered document is not valid JSON: %w", err)
+	}
+	var document struct {
+		RecordType string `json:"RecordType"`
+		Endpoints  map[string]struct {
+			Value string `json:"Value"`
+		} `json:"Endpoints"`
+	}
+	if err := json.Unmarshal(compact.Bytes(), &document); err != nil {
+		return "", "", nil, fmt.Errorf("the rendered document is not a JSON object: %w", err)
+	}
+	if document.RecordType == "" {
+		return "", "", nil, errors.New("the rendered document has no RecordType")
+	}
+	var values endpoint.Targets
+	for _, ep := range document.Endpoints {
+		if ep.Value != "" && !slices.Contains(values, ep.Value) {
+			values = append(values, ep.Value)
+		}
+	}
+	if len(values) == 0 {
+		return "", "", nil, errors.New("the rendered document has no endpoint value")
+	}
+	sort.Sort(values)
+	return compact.String(), strings.ToUpper(document.RecordType), values, nil
+}
+
+func (sc *trafficPolicySource) AddEventHandler(ctx context.Context, handler func()) {
+	log.Debug("Adding event handler for TrafficPolicy")
+
+	// Right now there is no way to remove event handler from informer, see:
+	// https://github.com/kubernetes/kubernetes/issues/79610
+	sc.trafficPolicyInformer.Informer().AddEventHandler(eventHandlerFunc(handler))
+	sc.serviceInformer.Informer().AddEventHandler(eventHandlerFunc(handler))
+}
+
+// newTrafficPolicyUnstructuredConverter returns a new unstructuredConverter initialized
+func newTrafficPolicyUnstructuredConverter() (*unstructuredConverter, error) {
+	uc := &unstructuredConverter{
+		scheme: runtime.NewScheme(),
+	}
+
+	// Add the core types we need
+	uc.scheme.AddKnownTypes(trafficPolicyGroupVersionResource.GroupVersion(), &TrafficPolicy{}, &TrafficPolicyList{})
+	if err := scheme.AddToScheme(uc.scheme); err != nil {
+		return nil, err
+	}
+
+	return uc, nil
+}
+
+// TrafficPolicy describes the Route53 traffic policy of a hostname, with a document templated with the addresses of
+// targets of the cluster.
+type TrafficPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec trafficPolicySpec `json:"spec,omitempty"`
+}
+
+type TrafficPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TrafficPolicy `json:"items"`
+}
+
+type trafficPolicySpec struct {
+	// Hostname is the name of the record of the policy instance.
+	Hostname string `json:"hostname"`
+	// TTL is the TTL of the records created by the policy instance.
+	TTL int64 `json:"ttl,omitempty"`
+	// Document is the template of the traffic policy document, rendered with the Hostname and the addresses of the
+	// Targets by name, e.g. {{ index .Targets "primary" 0 | json }}.
+	Document string `json:"document"`
+	// Targets are the targets referred to by the document by name.
+	Targets map[string]trafficPolicyTarget `json:"targets,omitempty"`
+}
+
+type trafficPolicyTarget struct {
+	// Service is the name of a Service of the namespace, whose load balancer addresses are the addresses of the target.
+	Service string `json:"service,omitempty"`
+	// Addresses are the addresses of the target, without Service.
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+func (in *trafficPolicyTarget) DeepCopyInto(out *trafficPolicyTarget) {
+	*out = *in
+	if in.Addresses != nil {
+		out.Addresses = make([]string, len(in.Addresses))
+		copy(out.Addresses, in.Addresses)
+	}
+}
+
+func (in *trafficPolicySpec) DeepCopyInto(out *trafficPolicySpec) {
+	*out = *in
+	if in.Targets != nil {
+		out.Targets = make(map[string]trafficPolicyTarget, len(in.Targets))
+		for name, target := range in.Targets {
+			var copied trafficPolicyTarget
+			target.DeepCopyInto(&copied)
+			out.Targets[name] = copied
+		}
+	}
+}
+
+func (in *TrafficPolicy) DeepCopyInto(out *TrafficPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+func (in *TrafficPolicy) DeepCopy() *TrafficPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *TrafficPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+func (in *TrafficPolicyList) DeepCopyInto(out *TrafficPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TrafficPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+func (in *TrafficPolicyList) DeepCopy() *TrafficPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *TrafficPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
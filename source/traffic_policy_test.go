@@ -0,0 +1,189 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeDynamic "k8s.io/client-go/dynamic/fake"
+	fakeKube "k8s.io/client-go/kubernetes/fake"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// This is a compile-time validation that trafficPolicySource is a Source.
+var _ Source = &trafficPolicySource{}
+
+const failoverDocumentTemplate = `{
+  "AWSPolicyFormatVersion": "2015-10-01",
+  "RecordType": "A",
+  "StartRule": "failover",
+  "Endpoints": {
+    "primary": {"Type": "value", "Value": {{ index .Targets "primary" 0 | json }}},
+    "secondary": {"Type": "value", "Value": {{ index .Targets "secondary" 0 | json }}}
+  },
+  "Rules": {
+    "failover": {
+      "RuleType": "failover",
+      "Primary": {"EndpointReference": "primary"},
+      "Secondary": {"EndpointReference": "secondary"}
+    }
+  }
+}`
+
+func TestTrafficPolicyEndpoints(t *testing.T) {
+	t.Parallel()
+
+	newTrafficPolicy := func(name string, annotations map[string]string, spec trafficPolicySpec) TrafficPolicy {
+		return TrafficPolicy{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: trafficPolicyGroupVersionResource.GroupVersion().String(),
+				Kind:       "TrafficPolicy",
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+			Spec:       spec,
+		}
+	}
+
+	for _, tt := range []struct {
+		title         string
+		trafficPolicy TrafficPolicy
+		expected      []*endpoint.Endpoint
+	}{
+		{
+			title: "document templated with the addresses of a Service and static addresses",
+			trafficPolicy: newTrafficPolicy("failover", nil, trafficPolicySpec{
+				Hostname: "www.example.com",
+				TTL:      60,
+				Document: failoverDocumentTemplate,
+				Targets: map[string]trafficPolicyTarget{
+					"primary":   {Service: "web"},
+					"secondary": {Addresses: []string{"10.0.0.2"}},
+				},
+			}),
+			expected: []*endpoint.Endpoint{
+				{
+					DNSName:    "www.example.com",
+					RecordType: endpoint.RecordTypeA,
+					RecordTTL:  60,
+					Targets:    endpoint.Targets{"10.0.0.1", "10.0.0.2"},
+					Labels:     endpoint.Labels{endpoint.ResourceLabelKey: "trafficpolicy/default/failover"},
+					ProviderSpecific: endpoint.ProviderSpecific{{
+						Name:  trafficPolicyDocumentProperty,
+						Value: `{"AWSPolicyFormatVersion":"2015-10-01","RecordType":"A","StartRule":"failover","Endpoints":{"primary":{"Type":"value","Value":"10.0.0.1"},"secondary":{"Type":"value","Value":"10.0.0.2"}},"Rules":{"failover":{"RuleType":"failover","Primary":{"EndpointReference":"primary"},"Secondary":{"EndpointReference":"secondary"}}}}`,
+					}},
+				},
+			},
+		},
+		{
+			title: "Service without load balancer address",
+			trafficPolicy: newTrafficPolicy("pending", nil, trafficPolicySpec{
+				Hostname: "www.example.com",
+				Document: failoverDocumentTemplate,
+				Targets: map[string]trafficPolicyTarget{
+					"primary":   {Service: "pending"},
+					"secondary": {Addresses: []string{"10.0.0.2"}},
+				},
+			}),
+		},
+		{
+			title: "invalid document",
+			trafficPolicy: newTrafficPolicy("invalid", nil, trafficPolicySpec{
+				Hostname: "www.example.com",
+				Document: `{"RecordType": "A", "Endpoints": {"a": {"Value": {{ index .Targets "a" 0 }}}}}`,
+				Targets: map[string]trafficPolicyTarget{
+					"a": {Addresses: []string{"10.0.0.1"}},
+				},
+			}),
+		},
+		{
+			title: "annotation filter mismatch",
+			trafficPolicy: newTrafficPolicy("filtered", map[string]string{"team": "other"}, trafficPolicySpec{
+				Hostname: "www.example.com",
+				Document: `{"RecordType": "A", "StartEndpoint": "a", "Endpoints": {"a": {"Type": "value", "Value": "10.0.0.1"}}}`,
+			}),
+		},
+	} {
+		tt := tt
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+
+			fakeKubernetesClient := fakeKube.NewSimpleClientset(
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{
+						Ingress: []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+					}},
+				},
+				&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default"}},
+			)
+			scheme := runtime.NewScheme()
+			scheme.AddKnownTypes(trafficPolicyGroupVersionResource.GroupVersion(), &TrafficPolicy{}, &TrafficPolicyList{})
+			fakeDynamicClient := fakeDynamic.NewSimpleDynamicClient(scheme)
+
+			obj := unstructured.Unstructured{}
+			trafficPolicyAsJSON, err := json.Marshal(tt.trafficPolicy)
+			require.NoError(t, err)
+			require.NoError(t, obj.UnmarshalJSON(trafficPolicyAsJSON))
+			_, err = fakeDynamicClient.Resource(trafficPolicyGroupVersionResource).Namespace("default").Create(context.Background(), &obj, metav1.CreateOptions{})
+			require.NoError(t, err)
+
+			source, err := NewTrafficPolicySource(context.TODO(), fakeDynamicClient, fakeKubernetesClient, "default", "team notin (other)")
+			require.NoError(t, err)
+
+			count := &unstructured.UnstructuredList{}
+			for len(count.Items) < 1 {
+				count, _ = fakeDynamicClient.Resource(trafficPolicyGroupVersionResource).Namespace("default").List(context.Background(), metav1.ListOptions{})
+			}
+
+			endpoints, err := source.Endpoints(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, endpoints)
+		})
+	}
+}
+
+func TestRenderTrafficPolicyDocument(t *testing.T) {
+	document, recordType, values, err := renderTrafficPolicyDocument(
+		`{"RecordType": "cname", "StartEndpoint": "lb", "Endpoints": {"lb": {"Type": "elastic-load-balancer", "Value": {{ index .Targets "lb" 0 | json }}}}, "Comment": {{ .Hostname | json }}}`,
+		"www.example.com",
+		map[string][]string{"lb": {"lb.elb.amazonaws.com"}},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, `{"RecordType":"cname","StartEndpoint":"lb","Endpoints":{"lb":{"Type":"elastic-load-balancer","Value":"lb.elb.amazonaws.com"}},"Comment":"www.example.com"}`, document)
+	assert.Equal(t, endpoint.RecordTypeCNAME, recordType)
+	assert.Equal(t, []string{"lb.elb.amazonaws.com"}, values)
+
+	for _, text := range []string{
+		`{{ index .Targets "missing" 0 }}`,
+		`{"RecordType": "A"`,
+		`{"Endpoints": {"a": {"Value": "10.0.0.1"}}}`,
+		`{"RecordType": "A", "Endpoints": {}}`,
+		`{{ .Unknown }}`,
+	} {
+		_, _, _, err := renderTrafficPolicyDocument(text, "www.example.com", map[string][]string{})
+		assert.Error(t, err, text)
+	}
+}
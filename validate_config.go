@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns/validation"
+	"sigs.k8s.io/external-dns/pkg/externaldnsrun"
+	"sigs.k8s.io/external-dns/pkg/preflight"
+)
+
+// validateConfigCommand is the name of the subcommand checking the flags without running ExternalDNS.
+const validateConfigCommand = "validate-config"
+
+// validateConfig checks the flags of args, and with --preflight-check, that the provider can list the zones
+// and read the records with the configured credentials. Every problem is printed to out.
+// It returns the exit code, which is 1 if any problem was found.
+func validateConfig(ctx context.Context, args []string, out io.Writer) int {
+	cfg := externaldns.NewConfig()
+	if err := cfg.ParseFlags(args); err != nil {
+		fmt.Fprintf(out, "error: %v\n", err)
+		return 1
+	}
+
+	problems := validation.CheckConfig(cfg)
+	if len(problems) == 0 && cfg.PreflightCheck {
+		// the provider is queried by the preflight check only, records are never written
+		log.SetLevel(log.WarnLevel)
+		p, err := externaldnsrun.BuildProvider(ctx, cfg, externaldnsrun.BuildDomainFilter(cfg), nil)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("failed to create the %s provider: %w", cfg.Provider, err))
+		} else if err := preflight.Check(ctx, p, false); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	for _, problem := range problems {
+		fmt.Fprintf(out, "error: %v\n", problem)
+	}
+	if len(problems) > 0 {
+		return 1
+	}
+	fmt.Fprintln(out, "configuration is valid")
+	return 0
+}